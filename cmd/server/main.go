@@ -2,15 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/aionmcp/aionmcp/internal/core"
+	"github.com/aionmcp/aionmcp/pkg/importer"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
@@ -21,6 +24,8 @@ type ConfigOverrides struct {
 	HTTPPort   int
 	GRPCPort   int
 	LogLevel   string
+	Transport  string
+	Demo       bool
 }
 
 func main() {
@@ -32,6 +37,12 @@ func main() {
 		httpPort    = flag.Int("http-port", 0, "HTTP server port (overrides config)")
 		grpcPort    = flag.Int("grpc-port", 0, "gRPC server port (overrides config)")
 		logLevel    = flag.String("log-level", "", "Log level (debug, info, warn, error)")
+		transport   = flag.String("transport", "", "Transport to serve: http (HTTP and gRPC) or stdio (overrides config)")
+		demoMode    = flag.Bool("demo", false, "Boot with bundled sample specs backed by in-process mock upstreams")
+		dashboard   = flag.Bool("grafana-dashboard", false, "Print Grafana dashboard JSON for the exported metrics and exit")
+		previewSpec = flag.String("preview-spec", "", "Print the tool manifest importing this spec would produce, without registering it, and exit")
+		specType    = flag.String("spec-type", "openapi", "Spec type for -preview-spec: openapi, graphql, asyncapi or grpc")
+		specID      = flag.String("spec-id", "", "Source ID for -preview-spec (defaults to the file name without extension)")
 	)
 	flag.Parse()
 
@@ -49,6 +60,8 @@ func main() {
 		fmt.Println()
 		fmt.Println("Usage:")
 		fmt.Println("  aionmcp [flags]")
+		fmt.Println("  aionmcp [flags] selftest [-target url] [-api-key key] [-timeout duration]")
+		fmt.Println("  aionmcp [flags] restore snapshot.db")
 		fmt.Println()
 		fmt.Println("Flags:")
 		flag.PrintDefaults()
@@ -68,11 +81,57 @@ func main() {
 		HTTPPort:   *httpPort,
 		GRPCPort:   *grpcPort,
 		LogLevel:   *logLevel,
+		Transport:  *transport,
+		Demo:       *demoMode,
 	}
 	if err := initConfig(overrides); err != nil {
 		log.Fatalf("Failed to initialize configuration: %v", err)
 	}
 
+	// Handle dashboard flag once the configuration names the SLO
+	if *dashboard {
+		data, err := core.GrafanaDashboard()
+		if err != nil {
+			log.Fatalf("Failed to generate Grafana dashboard: %v", err)
+		}
+		fmt.Println(string(data))
+		os.Exit(0)
+	}
+
+	// Handle spec preview once the configuration sets naming and synthetic tools
+	if *previewSpec != "" {
+		id := *specID
+		if id == "" {
+			id = strings.TrimSuffix(filepath.Base(*previewSpec), filepath.Ext(*previewSpec))
+		}
+		preview, err := core.PreviewSpec(context.Background(), importer.SpecSource{
+			ID:   id,
+			Type: importer.SpecType(*specType),
+			Path: *previewSpec,
+		})
+		if err != nil {
+			log.Fatalf("Failed to preview spec: %v", err)
+		}
+		data, err := json.MarshalIndent(preview, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode spec preview: %v", err)
+		}
+		fmt.Println(string(data))
+		os.Exit(0)
+	}
+
+	// Handle the selftest command once the configuration is loaded, so a
+	// temporary instance boots the way the server would
+	if flag.Arg(0) == "selftest" {
+		os.Exit(runSelfTest(flag.Args()[1:], *logLevel != ""))
+	}
+
+	// Handle the restore command, which replaces the configured storage's
+	// data while the server is stopped
+	if flag.Arg(0) == "restore" {
+		os.Exit(runRestore(flag.Args()[1:]))
+	}
+
 	// Initialize logger
 	logger, err := initLogger()
 	if err != nil {
@@ -108,14 +167,103 @@ func main() {
 		cancel()
 	}()
 
-	// Run server
-	if err := server.Run(ctx); err != nil {
-		logger.Fatal("Server failed", zap.Error(err))
+	// Reload TLS certificates on SIGHUP
+	go func() {
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		for {
+			select {
+			case <-hupChan:
+				server.ReloadTLS()
+			case <-ctx.Done():
+				signal.Stop(hupChan)
+				return
+			}
+		}
+	}()
+
+	// Run server on the selected transport
+	switch viper.GetString("server.transport") {
+	case "stdio":
+		if err := server.RunStdio(ctx, os.Stdin, os.Stdout); err != nil {
+			logger.Fatal("Stdio transport failed", zap.Error(err))
+		}
+	case "http", "":
+		if err := server.Run(ctx); err != nil {
+			logger.Fatal("Server failed", zap.Error(err))
+		}
+	default:
+		logger.Fatal("Unsupported transport", zap.String("transport", viper.GetString("server.transport")))
 	}
 
 	logger.Info("AionMCP server shutdown complete")
 }
 
+// runSelfTest runs the selftest command and returns its exit code. Server
+// logs are discarded unless a log level was requested, so they do not
+// interleave with the report.
+func runSelfTest(args []string, verbose bool) int {
+	flags := flag.NewFlagSet("selftest", flag.ExitOnError)
+	target := flags.String("target", "", "Base URL of a running instance on this host (defaults to booting a temporary one)")
+	apiKey := flags.String("api-key", "", "API key for a target that requires authentication")
+	timeout := flags.Duration("timeout", core.DefaultSelfTestTimeout, "Time allowed for each check")
+	flags.Parse(args)
+
+	logger := zap.NewNop()
+	if verbose {
+		var err error
+		if logger, err = initLogger(); err != nil {
+			log.Fatalf("Failed to initialize logger: %v", err)
+		}
+		defer logger.Sync()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := core.RunSelfTest(ctx, core.SelfTestOptions{
+		Target:  *target,
+		APIKey:  *apiKey,
+		Timeout: *timeout,
+	}, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Self-test could not run: %v\n", err)
+		return 1
+	}
+	report.Write(os.Stdout)
+	if !report.Passed() {
+		return 1
+	}
+	return 0
+}
+
+// runRestore restores learning storage from a snapshot file, or from
+// standard input when the file is "-", and returns the exit code
+func runRestore(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: aionmcp [flags] restore snapshot.db")
+		return 2
+	}
+
+	snapshot := os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open snapshot: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		snapshot = file
+	}
+
+	if err := core.RestoreStorage(context.Background(), snapshot, zap.NewNop()); err != nil {
+		fmt.Fprintf(os.Stderr, "Restore failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Restored %s learning storage at %s from %s\n", viper.GetString("storage.type"), viper.GetString("storage.path"), args[0])
+	return 0
+}
+
 func initConfig(overrides ConfigOverrides) error {
 	// Use custom config file if provided
 	if overrides.ConfigFile != "" {
@@ -130,19 +278,304 @@ func initConfig(overrides ConfigOverrides) error {
 	// Set defaults
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.grpc_port", 9090)
+	viper.SetDefault("server.grpc.reflection", true)
+	viper.SetDefault("server.grpc.keepalive_time_ms", 60000)
+	viper.SetDefault("server.grpc.keepalive_timeout_ms", 20000)
+	viper.SetDefault("server.grpc.keepalive_min_time_ms", 15000)
+	viper.SetDefault("server.grpc.max_connection_idle_ms", 0)
+	viper.SetDefault("server.grpc.max_recv_msg_size", 0)
+	viper.SetDefault("server.transport", "http")
+
+	// TLS defaults (setting cert and key serves HTTP and gRPC over TLS; client_ca enables mutual TLS)
+	viper.SetDefault("server.tls.cert", "")
+	viper.SetDefault("server.tls.key", "")
+	viper.SetDefault("server.tls.client_ca", "")
+	viper.SetDefault("server.tls.client_auth", "require") // require or optional
+
+	// CORS defaults (empty allowed_origins disables CORS; empty methods and headers use built-in lists)
+	viper.SetDefault("server.cors.allowed_origins", []string{})
+	viper.SetDefault("server.cors.allowed_methods", []string{})
+	viper.SetDefault("server.cors.allowed_headers", []string{})
+	viper.SetDefault("server.cors.exposed_headers", []string{})
+	viper.SetDefault("server.cors.allow_credentials", false)
+	viper.SetDefault("server.cors.max_age_seconds", 600)
+
+	// Security header defaults (hsts_max_age_seconds is only sent over TLS; 0 omits it)
+	viper.SetDefault("server.security_headers.enabled", true)
+	viper.SetDefault("server.security_headers.content_security_policy", "default-src 'none'; frame-ancestors 'none'")
+	viper.SetDefault("server.security_headers.frame_options", "DENY")
+	viper.SetDefault("server.security_headers.referrer_policy", "no-referrer")
+	viper.SetDefault("server.security_headers.hsts_max_age_seconds", 31536000)
 	viper.SetDefault("mcp.protocol_version", "1.0")
-	viper.SetDefault("storage.type", "boltdb")
+	viper.SetDefault("storage.type", "boltdb") // boltdb, sqlite or memory
+
+	// Clock defaults (simulated runs sessions, retention and schedules faster than real time)
+	viper.SetDefault("clock.mode", "real") // real or simulated
+	viper.SetDefault("clock.simulation.start", "") // RFC 3339; empty starts at the current time
+	viper.SetDefault("clock.simulation.speed", 60.0)
 	viper.SetDefault("storage.path", "./data/aionmcp.db")
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.format", "json")
+	viper.SetDefault("server.read_only", false)
+	viper.SetDefault("server.read_only_reason", "")
+	viper.SetDefault("server.read_only_workspaces", []string{})
+	viper.SetDefault("demo.enabled", false)
+
+	// API key authentication defaults (disabled keeps every endpoint open)
+	viper.SetDefault("auth.api_keys.enabled", false)
+	viper.SetDefault("auth.api_keys.path", "./data/apikeys.db")
+	viper.SetDefault("auth.api_keys.bootstrap_key", "")
+	viper.SetDefault("auth.api_keys.onboarding_ttl_hours", 720)
+	viper.SetDefault("auth.oidc.issuer", "")
+	viper.SetDefault("auth.oidc.audience", "")
+	viper.SetDefault("auth.oidc.jwks_url", "")
+	viper.SetDefault("auth.oidc.agent_id_claim", "sub")
+	viper.SetDefault("auth.oidc.agent_name_claim", "name")
+	viper.SetDefault("auth.oidc.roles_claim", "roles")
+	viper.SetDefault("auth.oidc.role_scopes", map[string][]string{
+		"admin": {"admin"},
+		"agent": {"agents:register", "tools:invoke"},
+	})
+	viper.SetDefault("auth.oidc.clock_skew_seconds", 60)
+	viper.SetDefault("auth.oidc.jwks_refresh_minutes", 60)
+
+	// Role-based tool access defaults (roles come from API keys or the OIDC roles claim)
+	viper.SetDefault("rbac.enabled", false)
+	viper.SetDefault("rbac.path", "./data/rbac.db")
+	viper.SetDefault("rbac.default_roles", []string{}) // Held by callers without roles
+	viper.SetDefault("rbac.roles", []map[string]interface{}{})
 	
 	// Learning engine defaults
 	viper.SetDefault("learning.enabled", true)
-	viper.SetDefault("learning.sample_rate", 1.0)
+	viper.SetDefault("learning.sample_rate", 1.0)           // With 0, only failures are recorded while always_sample_errors is set
+	viper.SetDefault("learning.always_sample_errors", true) // Failures bypass sampling, also while it is reduced under load
 	viper.SetDefault("learning.retention_days", 30)
 	viper.SetDefault("learning.async_processing", true)
 	viper.SetDefault("learning.include_successful", true)
 
+	// Learning record redaction defaults: values of object keys matching the fields globs
+	// (case-insensitive, at any depth) and of the JSONPath paths are masked before tool
+	// inputs and outputs are recorded; skip_payload_tools never have them recorded
+	viper.SetDefault("learning.redaction.fields", []string{
+		"*password*", "*secret*", "*token*", "*api_key*", "*apikey*",
+		"*authorization*", "*cookie*", "*credential*", "*private_key*",
+	})
+	viper.SetDefault("learning.redaction.paths", []string{})
+	viper.SetDefault("learning.redaction.skip_payload_tools", []string{})
+
+	// Cross-workspace insight sharing defaults (aggregation-only shares only k-anonymous aggregates)
+	viper.SetDefault("learning.insights.aggregation_only", false)
+	viper.SetDefault("learning.insights.min_workspaces", 5)
+	viper.SetDefault("learning.insights.min_executions", 50)
+
+	// Insight priority defaults (insights about tools that sessions invoked recently list first)
+	viper.SetDefault("learning.insights.usage_weighting", true)
+	viper.SetDefault("learning.insights.active_window_minutes", 15)
+
+	// Learning load shedding defaults (pressure reduces sampling and defers analysis)
+	viper.SetDefault("learning.shedding.enabled", true)
+	viper.SetDefault("learning.shedding.queue_size", 1024)
+	viper.SetDefault("learning.shedding.workers", 4)
+	viper.SetDefault("learning.shedding.latency_target_ms", 50)
+	viper.SetDefault("learning.shedding.min_sample_rate", 0.01)
+	viper.SetDefault("learning.shedding.defer_analysis_at", 0.5)
+	viper.SetDefault("learning.recommendations.window_hours", 168)
+
+	// Learning retention defaults (old executions become hourly aggregates, then storage is compacted)
+	viper.SetDefault("learning.retention.interval_minutes", 60) // 0 disables the retention job
+	viper.SetDefault("learning.retention.downsample", true)
+	viper.SetDefault("learning.retention.compact", true)
+	viper.SetDefault("learning.remediation.auto_apply", false) // Remediations wait for approval
+	viper.SetDefault("learning.remediation.min_priority", "critical")
+	viper.SetDefault("learning.remediation.actions", []string{"disable_tool", "increase_timeout"})
+	viper.SetDefault("learning.remediation.max_timeout_ms", 120000)
+
+	// Backup defaults (scheduled snapshots go to backup.directory and/or backup.s3.bucket)
+	viper.SetDefault("backup.interval_minutes", 0) // 0 disables scheduled backups
+	viper.SetDefault("backup.directory", "")
+	viper.SetDefault("backup.keep", 7)
+	viper.SetDefault("backup.s3.endpoint", "")
+	viper.SetDefault("backup.s3.region", "us-east-1")
+	viper.SetDefault("backup.s3.bucket", "")
+	viper.SetDefault("backup.s3.prefix", "")
+	viper.SetDefault("backup.s3.access_key_id", "")
+	viper.SetDefault("backup.s3.secret_access_key", "")
+	viper.SetDefault("backup.s3.path_style", false)
+	viper.SetDefault("notifications.min_priority", "high")
+	viper.SetDefault("notifications.dedup_minutes", 60)
+	viper.SetDefault("notifications.max_per_hour", 20) // 0 is unlimited
+	viper.SetDefault("notifications.webhook.url", "")
+	viper.SetDefault("notifications.slack.webhook_url", "")
+	viper.SetDefault("notifications.email.smtp_addr", "")
+	viper.SetDefault("notifications.email.username", "")
+	viper.SetDefault("notifications.email.password", "")
+	viper.SetDefault("notifications.email.from", "")
+	viper.SetDefault("notifications.email.to", []string{})
+
+	// Agent session limit defaults (0 disables a limit)
+	viper.SetDefault("agent.limits.requests_per_minute", 0)
+	viper.SetDefault("agent.limits.max_concurrent", 0)
+	viper.SetDefault("agent.limits.max_invocations", 0)
+	viper.SetDefault("agent.limits.budget_ms", 0)
+
+	// Recent tool usage kept per agent session
+	viper.SetDefault("agent.history.size", 20)
+	viper.SetDefault("agent.history.path", "./data/session_history.db")
+
+	// Expired agent sessions stay resumable for the grace window (0 disables
+	// resumption); an empty secret signs tokens with a random per-process key
+	viper.SetDefault("agent.resumption.grace_seconds", 600)
+	viper.SetDefault("agent.resumption.secret", "")
+
+	// Daily agent quota defaults (0 disables a quota); API keys may carry their own
+	viper.SetDefault("agent.quotas.session.max_invocations_per_day", 0)
+	viper.SetDefault("agent.quotas.session.max_cost_per_day", 0)
+	viper.SetDefault("agent.quotas.default_tool_cost", 1)
+
+	// Async invocation worker pool defaults
+	viper.SetDefault("agent.async.workers", 4)
+	viper.SetDefault("agent.async.queue_size", 100)
+	viper.SetDefault("agent.async.retention_minutes", 60)
+
+	// Agent event long-polling and stream health check defaults: streams whose client
+	// is gone, or that keep a full buffer for stream_stall_timeout_seconds, are closed
+	viper.SetDefault("agent.events.log_size", 1024)
+	viper.SetDefault("agent.events.path", "./data/events.db")
+	viper.SetDefault("agent.events.max_poll_wait_seconds", 60)
+	viper.SetDefault("agent.events.stream_check_interval_seconds", 30)
+	viper.SetDefault("agent.events.stream_stall_timeout_seconds", 120)
+
+	// Server-side bounds on agent-requested retry policies
+	viper.SetDefault("agent.retry.max_retries", 5)
+	viper.SetDefault("agent.retry.max_delay_ms", 30000)
+
+	// Learning payload capture: agents opt in to full capture at registration
+	viper.SetDefault("agent.telemetry.default_capture_level", "metadata")
+	viper.SetDefault("agent.telemetry.max_capture_level", "full")
+
+	// Agent protocol negotiation: versions below min_version are refused, and agents
+	// that declare no supported protocols are assumed to speak default_version
+	viper.SetDefault("agent.protocol.min_version", "MCP/1.0")
+	viper.SetDefault("agent.protocol.default_version", "MCP/1.0")
+
+	// Result encodings agents may prefer over JSON; JSON is always offered
+	viper.SetDefault("agent.result_formats", []string{"json", "msgpack", "cbor"})
+
+	// Admin session taps: payloads are streamed with secrets redacted
+	viper.SetDefault("agent.tap.include_payloads", true)
+	viper.SetDefault("agent.tap.redact_keys", []string{})
+
+	// Per-source upstream protection defaults (0 disables a limit)
+	viper.SetDefault("importer.source_limits.max_concurrent", 0)
+	viper.SetDefault("importer.source_limits.requests_per_second", 0)
+	viper.SetDefault("importer.source_limits.queue_timeout_ms", 10000)
+	viper.SetDefault("importer.naming.case", "")
+	viper.SetDefault("importer.naming.verb_noun", false)
+	viper.SetDefault("importer.naming.max_length", 0)
+	viper.SetDefault("importer.naming.reserved", []string{})
+	viper.SetDefault("importer.collision_policy", "newest_wins")
+	viper.SetDefault("importer.persistence.enabled", true)
+	viper.SetDefault("importer.persistence.path", "./data/sources.db")
+	viper.SetDefault("importer.quota.threshold", 0.1)
+	viper.SetDefault("importer.quota.max_wait_ms", 5000)
+	viper.SetDefault("importer.quota.exhaustion_window_hours", 24)
+	viper.SetDefault("importer.quota.insight_after", 3)
+	viper.SetDefault("importer.synthetic.enabled", false)
+	viper.SetDefault("importer.synthetic.max_count", 100)
+
+	// Remote spec download defaults: specs over max_bytes are rejected, and a negative
+	// max_redirects refuses redirects
+	viper.SetDefault("importer.fetch.max_bytes", 10485760)
+	viper.SetDefault("importer.fetch.max_redirects", 5)
+	viper.SetDefault("importer.fetch.timeout_seconds", 30)
+
+	// GraphQL defaults: generated tools select scalar fields plus this many levels of
+	// nested objects
+	viper.SetDefault("importer.graphql.selection_depth", 1)
+
+	// AsyncAPI broker connection defaults
+	viper.SetDefault("importer.asyncapi.connect_timeout_seconds", 10)
+
+	// Remote spec polling defaults: sources without poll_interval_seconds are only
+	// polled when default_interval_seconds is positive
+	viper.SetDefault("importer.poll.default_interval_seconds", 0)
+	viper.SetDefault("importer.poll.check_interval_seconds", 15)
+
+	// Tool schema validation defaults (per-tool overrides under validation.tools)
+	viper.SetDefault("validation.input", true)
+	viper.SetDefault("validation.output", false)
+
+	// Tool invocation timeout defaults (0 disables; per-tool overrides under tools.timeouts)
+	viper.SetDefault("tools.timeout_ms", 30000)
+
+	// Adaptive tuning defaults: every interval_seconds, tools with min_samples executions in
+	// the last window_minutes get a timeout of latency_multiplier times their p99 latency,
+	// bounded by min_timeout_ms and max_timeout_ms, and a retry budget of up to max_retries
+	// sized by their transient error rate
+	viper.SetDefault("tools.adaptive.enabled", false)
+	viper.SetDefault("tools.adaptive.interval_seconds", 300)
+	viper.SetDefault("tools.adaptive.window_minutes", 1440)
+	viper.SetDefault("tools.adaptive.min_samples", 20)
+	viper.SetDefault("tools.adaptive.latency_multiplier", 3.0)
+	viper.SetDefault("tools.adaptive.min_timeout_ms", 1000)
+	viper.SetDefault("tools.adaptive.max_timeout_ms", 60000)
+	viper.SetDefault("tools.adaptive.max_retries", 2)
+	viper.SetDefault("tools.adaptive.retry_delay_ms", 200)
+	viper.SetDefault("tools.adaptive.max_retry_delay_ms", 5000)
+
+	// Watchdog defaults: invocations still running after max_runtime_ms are force-cancelled
+	// whatever their timeout (0 disables), and insight_after hangs of one tool within
+	// hang_window_ms raise an insight
+	viper.SetDefault("tools.watchdog.max_runtime_ms", 300000)
+	viper.SetDefault("tools.watchdog.check_interval_ms", 1000)
+	viper.SetDefault("tools.watchdog.hang_window_ms", 3600000)
+	viper.SetDefault("tools.watchdog.insight_after", 3)
+
+	// Context variable defaults: parameter templates such as {{ .env.TENANT_ID }} resolve
+	// from context_vars.variables, each optionally scoped to a workspace
+	viper.SetDefault("context_vars.enabled", false)
+
+	// Circuit breaker defaults (0 disables; per-tool thresholds under tools.circuit_breaker.tools)
+	viper.SetDefault("tools.circuit_breaker.failure_threshold", 5)
+	viper.SetDefault("tools.circuit_breaker.open_timeout_ms", 30000)
+
+	// Workflow defaults (YAML or JSON workflow definitions loaded at startup)
+	viper.SetDefault("workflows.path", "./workflows")
+
+	// Result cache defaults for idempotent tools (per-tool TTLs under cache.tools; empty path keeps results in memory only)
+	viper.SetDefault("cache.enabled", false)
+	viper.SetDefault("cache.ttl_ms", 60000)
+	viper.SetDefault("cache.max_entries", 1000)
+	viper.SetDefault("cache.path", "")
+
+	// Invocation log (SIEM stream) defaults
+	viper.SetDefault("invocation_log.enabled", false)
+	viper.SetDefault("invocation_log.format", "jsonl")
+	viper.SetDefault("invocation_log.path", "./data/invocations.jsonl")
+	viper.SetDefault("invocation_log.syslog.network", "udp")
+	viper.SetDefault("invocation_log.http.timeout_seconds", 5)
+	viper.SetDefault("invocation_log.buffer_size", 1024)
+	viper.SetDefault("audit.enabled", false)
+	viper.SetDefault("audit.path", "./data/audit.db")
+
+	// Readiness probe defaults (/readyz fails once the learning queue is this full)
+	viper.SetDefault("health.readiness.learning_queue_threshold", 0.9)
+
+	// Debug listener defaults (pprof, goroutine dumps, GC stats and internal state; admin scope when auth is enabled)
+	viper.SetDefault("debug.enabled", false)
+	viper.SetDefault("debug.address", "127.0.0.1:6060")
+
+	// Prometheus metrics defaults (the Grafana dashboard charts the error budget of availability_target over budget_window)
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.dashboard.title", "AionMCP")
+	viper.SetDefault("metrics.dashboard.uid", "aionmcp")
+	viper.SetDefault("metrics.dashboard.availability_target", 0.99)
+	viper.SetDefault("metrics.dashboard.budget_window", "30d")
+
+	// Generated documentation defaults (empty timezone uses the server's local zone)
+	viper.SetDefault("docs.locale", "en-US")
+	viper.SetDefault("docs.timezone", "")
+
 	// Allow environment variable overrides
 	viper.AutomaticEnv()
 	viper.SetEnvPrefix("AIONMCP")
@@ -157,6 +590,12 @@ func initConfig(overrides ConfigOverrides) error {
 	if overrides.LogLevel != "" {
 		viper.Set("log.level", overrides.LogLevel)
 	}
+	if overrides.Transport != "" {
+		viper.Set("server.transport", overrides.Transport)
+	}
+	if overrides.Demo {
+		viper.Set("demo.enabled", true)
+	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		// Config file not found, use defaults
@@ -197,6 +636,11 @@ func initLogger() (*zap.Logger, error) {
 }
 
 func ensureDataDirectory() error {
+	// In-memory storage writes nothing to disk
+	if viper.GetString("storage.type") == "memory" {
+		return nil
+	}
+
 	dataPath := viper.GetString("storage.path")
 	if dataPath == "" {
 		dataPath = "./data/aionmcp.db"
@@ -376,13 +376,14 @@ func (h *APIHandler) GetSupportedTypes(c *gin.Context) {
 		DocumentTypeReflection,
 		DocumentTypeReadme,
 		DocumentTypeArchitecture,
+		DocumentTypeToolChangelog,
 	}
 
 	typeInfo := make(map[DocumentType]interface{})
 	for _, docType := range types {
 		typeInfo[docType] = map[string]interface{}{
 			"supported_formats": []string{"markdown"},
-			"auto_scheduling":   docType != DocumentTypeArchitecture,
+			"auto_scheduling":   docType != DocumentTypeArchitecture && docType != DocumentTypeToolChangelog,
 			"description":       h.getTypeDescription(docType),
 		}
 	}
@@ -391,6 +392,7 @@ func (h *APIHandler) GetSupportedTypes(c *gin.Context) {
 		"supported_types": types,
 		"type_info":       typeInfo,
 		"total_types":     len(types),
+		"supported_locales": SupportedLocales(),
 	})
 }
 
@@ -405,6 +407,8 @@ func (h *APIHandler) getTypeDescription(docType DocumentType) string {
 		return "Auto-updating README with current project status and metrics"
 	case DocumentTypeArchitecture:
 		return "Architecture documentation with system overview and components"
+	case DocumentTypeToolChangelog:
+		return "Tool catalog changelog generated from specification reload diffs"
 	default:
 		return "Custom document type"
 	}
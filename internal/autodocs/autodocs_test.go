@@ -2,11 +2,25 @@ package autodocs
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/aionmcp/aionmcp/pkg/types"
 )
 
+// staticToolChanges serves a fixed tool change history for tests
+type staticToolChanges map[string][]importer.ToolCatalogDiff
+
+func (s staticToolChanges) GetCatalogChanges(sourceID string) []importer.ToolCatalogDiff {
+	return s[sourceID]
+}
+
 // TestDocumentationSystem tests the core documentation functionality
 func TestDocumentationSystem(t *testing.T) {
 	// Setup
@@ -94,6 +108,50 @@ func TestDocumentationSystem(t *testing.T) {
 		t.Logf("✅ README generated: %d bytes", result.ContentLength)
 	})
 
+	t.Run("Tool Changelog Generation", func(t *testing.T) {
+		input := func(required []any, props map[string]any) map[string]any {
+			return map[string]any{"input": map[string]any{"type": "object", "properties": props, "required": required}}
+		}
+		before := []types.ToolMetadata{
+			{Name: "list_pets", Schema: input(nil, map[string]any{"limit": map[string]any{"type": "integer"}})},
+			{Name: "delete_pet"},
+		}
+		after := []types.ToolMetadata{
+			{Name: "list_pets", Schema: input([]any{"owner"}, map[string]any{
+				"limit": map[string]any{"type": "string"},
+				"owner": map[string]any{"type": "string"},
+			})},
+			{Name: "create_pet"},
+		}
+
+		diff := importer.DiffToolCatalogs("petstore", before, after)
+		if len(diff.Changes) != 3 || !diff.HasBreakingChanges() {
+			t.Fatalf("Unexpected diff: %+v", diff.Changes)
+		}
+
+		engine.RegisterGenerator(NewToolChangelogGenerator(staticToolChanges{"petstore": {diff}}))
+		result, err := engine.Generate(GenerationRequest{
+			Type:       DocumentTypeToolChangelog,
+			OutputPath: filepath.Join("test_output", "tool_changelog.md"),
+			SourceID:   "petstore",
+		})
+		if err != nil {
+			t.Fatalf("Tool changelog generation failed: %v", err)
+		}
+		if !result.Success {
+			t.Fatalf("Tool changelog generation was not successful: %s", result.Error)
+		}
+
+		content := RenderToolChangelog("petstore", []importer.ToolCatalogDiff{diff}, mustFormatter(t, DefaultLocale, "UTC"))
+		for _, expected := range []string{"Breaking Changes", "`delete_pet`", "`create_pet`", "required input parameter \"owner\" added"} {
+			if !strings.Contains(content, expected) {
+				t.Errorf("Tool changelog missing %q", expected)
+			}
+		}
+
+		t.Logf("✅ Tool changelog generated: %d bytes", result.ContentLength)
+	})
+
 	t.Run("Generate All Documents", func(t *testing.T) {
 		results, err := engine.GenerateAll()
 		if err != nil {
@@ -138,6 +196,65 @@ func TestDocumentationSystem(t *testing.T) {
 	})
 }
 
+// TestFormatter tests locale and timezone aware formatting
+func TestFormatter(t *testing.T) {
+	moment := time.Date(2025, time.March, 4, 22, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		locale   string
+		timezone string
+		check    func(f *Formatter) (string, string)
+	}{
+		{"en-US", "UTC", func(f *Formatter) (string, string) { return f.ShortDate(moment), "03/04/2025" }},
+		{"en-US", "UTC", func(f *Formatter) (string, string) { return f.LongDate(moment), "March 4, 2025" }},
+		{"en-US", "UTC", func(f *Formatter) (string, string) { return f.Time(moment), "10:30 PM" }},
+		{"en-US", "UTC", func(f *Formatter) (string, string) { return f.Integer(1234567), "1,234,567" }},
+		{"en-US", "UTC", func(f *Formatter) (string, string) { return f.Percent(97.26, 1), "97.3%" }},
+		{"de-DE", "Europe/Berlin", func(f *Formatter) (string, string) { return f.LongDate(moment), "4. März 2025" }},
+		{"de-DE", "Europe/Berlin", func(f *Formatter) (string, string) { return f.Time(moment), "23:30" }},
+		{"de-DE", "Europe/Berlin", func(f *Formatter) (string, string) { return f.Decimal(-1234.5, 1), "-1.234,5" }},
+		{"de-DE", "Europe/Berlin", func(f *Formatter) (string, string) { return f.Percent(97.26, 1), "97,3\u00a0%" }},
+		{"de-DE", "Europe/Berlin", func(f *Formatter) (string, string) { return f.Duration(1500 * time.Millisecond), "1,5s" }},
+		{"ja-JP", "Asia/Tokyo", func(f *Formatter) (string, string) { return f.LongDate(moment), "2025年3月5日" }},
+		{"ja-JP", "Asia/Tokyo", func(f *Formatter) (string, string) { return f.Weekday(moment), "水曜日" }},
+	}
+
+	for _, tc := range cases {
+		got, want := tc.check(mustFormatter(t, tc.locale, tc.timezone))
+		if got != want {
+			t.Errorf("%s/%s: got %q, want %q", tc.locale, tc.timezone, got, want)
+		}
+	}
+
+	if _, err := NewFormatter("xx-XX", ""); err == nil {
+		t.Error("Expected error for unsupported locale")
+	}
+	if _, err := NewFormatter("", "Mars/Olympus"); err == nil {
+		t.Error("Expected error for unknown timezone")
+	}
+
+	generator := NewToolChangelogGenerator(staticToolChanges{})
+	err := generator.Validate(GenerationRequest{
+		Type:       DocumentTypeToolChangelog,
+		OutputPath: filepath.Join("test_output", "tool_changelog.md"),
+		SourceID:   "petstore",
+		Locale:     "xx-XX",
+	})
+	if err == nil {
+		t.Error("Expected validation error for unsupported locale")
+	}
+}
+
+// mustFormatter creates a formatter or fails the test
+func mustFormatter(t *testing.T, locale, timezone string) *Formatter {
+	t.Helper()
+	format, err := NewFormatter(locale, timezone)
+	if err != nil {
+		t.Fatalf("Failed to create formatter: %v", err)
+	}
+	return format
+}
+
 // TestDataSources tests data source functionality
 func TestDataSources(t *testing.T) {
 	projectRoot := "../../"
@@ -187,6 +304,32 @@ func TestDataSources(t *testing.T) {
 		t.Logf("✅ Learning snapshot: %d executions, %.1f%% success rate",
 			snapshot.TotalExecutions, snapshot.SuccessRate*100)
 	})
+
+	t.Run("Learning API Percentiles", func(t *testing.T) {
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"total_executions": 10, "average_latency": 40000000, "p50_latency": 20000000,
+				"p95_latency": 150000000, "p99_latency": 300000000,
+				"top_tools": [{"name": "search", "execution_count": 10, "p95_latency": 150000000}]}`)
+		}))
+		defer api.Close()
+
+		snapshot, err := NewLearningDataSource(projectRoot, api.URL).GetLearningSnapshot()
+		if err != nil {
+			t.Fatalf("Failed to get learning snapshot: %v", err)
+		}
+		if snapshot.P50Latency != 20*time.Millisecond || snapshot.P95Latency != 150*time.Millisecond || snapshot.P99Latency != 300*time.Millisecond {
+			t.Errorf("Unexpected latency percentiles: %v / %v / %v", snapshot.P50Latency, snapshot.P95Latency, snapshot.P99Latency)
+		}
+		if len(snapshot.TopTools) != 1 || snapshot.TopTools[0].P95Latency != 150*time.Millisecond {
+			t.Errorf("Unexpected tool percentiles: %+v", snapshot.TopTools)
+		}
+
+		var content strings.Builder
+		NewReflectionGenerator(NewLearningDataSource(projectRoot, "")).generatePerformanceAnalysis(&content, snapshot, mustFormatter(t, DefaultLocale, "UTC"))
+		if !strings.Contains(content.String(), "- **Tail Response Time**: 150.0ms p95, 300.0ms p99") {
+			t.Errorf("Performance analysis missing tail latency:\n%s", content.String())
+		}
+	})
 }
 
 // BenchmarkDocumentGeneration benchmarks document generation performance
@@ -243,3 +386,61 @@ func ExampleEngine_Generate() {
 	fmt.Printf("Changelog generated: %s (%d bytes)\n",
 		result.OutputPath, result.ContentLength)
 }
+
+// recordingGenerator records the requests it is asked to generate
+type recordingGenerator struct {
+	requests []GenerationRequest
+}
+
+func (g *recordingGenerator) Generate(request GenerationRequest) (*GenerationResult, error) {
+	g.requests = append(g.requests, request)
+	return &GenerationResult{Type: request.Type, OutputPath: request.OutputPath, Success: true}, nil
+}
+
+func (g *recordingGenerator) GetSupportedTypes() []DocumentType {
+	return []DocumentType{DocumentTypeArchitecture}
+}
+
+func (g *recordingGenerator) Validate(GenerationRequest) error {
+	return nil
+}
+
+// TestScheduleClock tests that schedules and date ranges follow the engine clock
+func TestScheduleClock(t *testing.T) {
+	// A Wednesday afternoon
+	fake := clock.NewFake(time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC))
+	config := DefaultEngineConfig()
+	config.Clock = fake
+	engine := NewEngineWithConfig(t.TempDir(), NewLearningDataSource(t.TempDir(), ""), config)
+	generator := &recordingGenerator{}
+	engine.RegisterGenerator(generator)
+
+	if err := engine.ScheduleGeneration(DocumentTypeArchitecture, "weekly"); err != nil {
+		t.Fatalf("Failed to schedule generation: %v", err)
+	}
+	jobs := engine.GetScheduledJobs()
+	if len(jobs) != 1 || !jobs[0].NextRun.Equal(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected the job to run next Monday at midnight, got %+v", jobs)
+	}
+
+	if err := engine.ProcessScheduledJobs(); err != nil {
+		t.Fatalf("Processing scheduled jobs failed: %v", err)
+	}
+	if len(generator.requests) != 0 {
+		t.Fatalf("Job ran before it was due")
+	}
+
+	fake.Advance(5 * 24 * time.Hour)
+	if err := engine.ProcessScheduledJobs(); err != nil {
+		t.Fatalf("Processing scheduled jobs failed: %v", err)
+	}
+	if len(generator.requests) != 1 {
+		t.Fatalf("Expected the due job to run once, got %d runs", len(generator.requests))
+	}
+	if dateRange := generator.requests[0].DateRange; dateRange == nil || !dateRange.EndDate.Equal(fake.Now()) {
+		t.Errorf("Expected the date range to end at the clock's time, got %+v", dateRange)
+	}
+	if next := engine.GetScheduledJobs()[0].NextRun; !next.Equal(time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the job to be rescheduled a week later, got %v", next)
+	}
+}
@@ -45,6 +45,11 @@ func (c *ChangelogGenerator) Generate(request GenerationRequest) (*GenerationRes
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
+	format, err := NewFormatter(request.Locale, request.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
 	// Determine date range
 	dateRange := DateRange{
 		StartDate: time.Now().AddDate(0, -1, 0), // Default to last month
@@ -75,7 +80,7 @@ func (c *ChangelogGenerator) Generate(request GenerationRequest) (*GenerationRes
 	}
 
 	// Generate changelog content
-	content, metadata, err := c.generateChangelog(commits, projectInfo, dateRange)
+	content, metadata, err := c.generateChangelog(commits, projectInfo, dateRange, format)
 	if err != nil {
 		return &GenerationResult{
 			Type:    request.Type,
@@ -122,11 +127,15 @@ func (c *ChangelogGenerator) Validate(request GenerationRequest) error {
 		return fmt.Errorf("unsupported format: %s (only markdown supported)", request.Format)
 	}
 
+	if _, err := NewFormatter(request.Locale, request.Timezone); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // generateChangelog creates the changelog content
-func (c *ChangelogGenerator) generateChangelog(commits []GitCommit, projectInfo map[string]interface{}, dateRange DateRange) (string, *DocumentMetadata, error) {
+func (c *ChangelogGenerator) generateChangelog(commits []GitCommit, projectInfo map[string]interface{}, dateRange DateRange, format *Formatter) (string, *DocumentMetadata, error) {
 	var content strings.Builder
 
 	// Header
@@ -136,16 +145,16 @@ func (c *ChangelogGenerator) generateChangelog(commits []GitCommit, projectInfo
 	content.WriteString("and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).\n\n")
 
 	// Auto-generation notice
-	content.WriteString(fmt.Sprintf("*This changelog was automatically generated on %s*\n\n", time.Now().Format("2006-01-02 15:04:05")))
+	content.WriteString(fmt.Sprintf("*This changelog was automatically generated on %s*\n\n", format.Timestamp(time.Now())))
 
 	if len(commits) == 0 {
 		content.WriteString("## No changes in the specified date range\n\n")
 		content.WriteString(fmt.Sprintf("Date range: %s to %s\n\n",
-			dateRange.StartDate.Format("2006-01-02"),
-			dateRange.EndDate.Format("2006-01-02")))
+			format.ShortDate(dateRange.StartDate),
+			format.ShortDate(dateRange.EndDate)))
 	} else {
 		// Group commits by date (daily entries)
-		dailyCommits := c.groupCommitsByDate(commits)
+		dailyCommits := c.groupCommitsByDate(commits, format)
 
 		// Sort dates in descending order
 		var dates []string
@@ -159,11 +168,11 @@ func (c *ChangelogGenerator) generateChangelog(commits []GitCommit, projectInfo
 		// Generate entries for each date
 		for _, date := range dates {
 			dayCommits := dailyCommits[date]
-			c.generateDayEntry(&content, date, dayCommits)
+			c.generateDayEntry(&content, date, dayCommits, format)
 		}
 
 		// Summary section
-		c.generateSummary(&content, commits, dateRange)
+		c.generateSummary(&content, commits, dateRange, format)
 	}
 
 	// Metadata
@@ -189,12 +198,13 @@ func (c *ChangelogGenerator) generateChangelog(commits []GitCommit, projectInfo
 	return content.String(), metadata, nil
 }
 
-// groupCommitsByDate groups commits by their date
-func (c *ChangelogGenerator) groupCommitsByDate(commits []GitCommit) map[string][]GitCommit {
+// groupCommitsByDate groups commits by their date in the formatter's timezone.
+// Keys stay ISO dates so they sort chronologically.
+func (c *ChangelogGenerator) groupCommitsByDate(commits []GitCommit, format *Formatter) map[string][]GitCommit {
 	dailyCommits := make(map[string][]GitCommit)
 
 	for _, commit := range commits {
-		date := commit.Date.Format("2006-01-02")
+		date := commit.Date.In(format.location).Format("2006-01-02")
 		dailyCommits[date] = append(dailyCommits[date], commit)
 	}
 
@@ -202,14 +212,14 @@ func (c *ChangelogGenerator) groupCommitsByDate(commits []GitCommit) map[string]
 }
 
 // generateDayEntry generates a changelog entry for a specific day
-func (c *ChangelogGenerator) generateDayEntry(content *strings.Builder, date string, commits []GitCommit) {
+func (c *ChangelogGenerator) generateDayEntry(content *strings.Builder, date string, commits []GitCommit, format *Formatter) {
 	// Parse date for better formatting
-	parsedDate, err := time.Parse("2006-01-02", date)
+	parsedDate, err := time.ParseInLocation("2006-01-02", date, format.location)
 	if err != nil {
 		parsedDate = time.Now()
 	}
 	
-	content.WriteString(fmt.Sprintf("## %s (%s)\n\n", parsedDate.Format("2006-01-02"), parsedDate.Weekday().String()))
+	content.WriteString(fmt.Sprintf("## %s (%s)\n\n", format.ShortDate(parsedDate), format.Weekday(parsedDate)))
 	
 	// Categorize commits
 	categories := c.categorizeCommits(commits)
@@ -331,15 +341,15 @@ func (c *ChangelogGenerator) writeCommitEntry(content *strings.Builder, commit G
 }
 
 // generateSummary generates a summary section
-func (c *ChangelogGenerator) generateSummary(content *strings.Builder, commits []GitCommit, dateRange DateRange) {
+func (c *ChangelogGenerator) generateSummary(content *strings.Builder, commits []GitCommit, dateRange DateRange, format *Formatter) {
 	content.WriteString("## Summary\n\n")
 
 	// Basic statistics
 	content.WriteString(fmt.Sprintf("**Period:** %s to %s\n\n",
-		dateRange.StartDate.Format("2006-01-02"),
-		dateRange.EndDate.Format("2006-01-02")))
+		format.ShortDate(dateRange.StartDate),
+		format.ShortDate(dateRange.EndDate)))
 
-	content.WriteString(fmt.Sprintf("**Total commits:** %d\n\n", len(commits)))
+	content.WriteString(fmt.Sprintf("**Total commits:** %s\n\n", format.Integer(int64(len(commits)))))
 
 	// Category breakdown
 	categories := c.categorizeCommits(commits)
@@ -404,10 +414,14 @@ func (c *ChangelogGenerator) generateSummary(content *strings.Builder, commits [
 
 	// Code statistics
 	content.WriteString(fmt.Sprintf("\n**Code changes:**\n"))
-	content.WriteString(fmt.Sprintf("- Files changed: %d\n", totalFiles))
-	content.WriteString(fmt.Sprintf("- Lines added: +%d\n", totalInsertions))
-	content.WriteString(fmt.Sprintf("- Lines removed: -%d\n", totalDeletions))
-	content.WriteString(fmt.Sprintf("- Net change: %+d lines\n\n", totalInsertions-totalDeletions))
+	content.WriteString(fmt.Sprintf("- Files changed: %s\n", format.Integer(int64(totalFiles))))
+	content.WriteString(fmt.Sprintf("- Lines added: +%s\n", format.Integer(int64(totalInsertions))))
+	content.WriteString(fmt.Sprintf("- Lines removed: -%s\n", format.Integer(int64(totalDeletions))))
+	netChange := format.Integer(int64(totalInsertions - totalDeletions))
+	if totalInsertions >= totalDeletions {
+		netChange = "+" + netChange
+	}
+	content.WriteString(fmt.Sprintf("- Net change: %s lines\n\n", netChange))
 }
 
 // writeToFile writes content to the specified file path
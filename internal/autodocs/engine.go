@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
 )
 
 const (
@@ -23,6 +25,18 @@ type EngineConfig struct {
 	// MaxHistoryEntries is the maximum number of generation results to keep in history.
 	// When the limit is reached, older entries are removed. Use 0 for default (100 entries).
 	MaxHistoryEntries int
+
+	// Locale selects how dates and numbers are written in generated documents,
+	// e.g. "de-DE". Requests without a locale use this value (default DefaultLocale).
+	Locale string
+
+	// Timezone is the IANA timezone used for dates in generated documents.
+	// Empty means the server's local timezone.
+	Timezone string
+
+	// Clock tells the time for schedules and date ranges. Nil selects the
+	// system clock.
+	Clock clock.Clock
 }
 
 // DefaultEngineConfig returns the default engine configuration
@@ -30,6 +44,7 @@ func DefaultEngineConfig() *EngineConfig {
 	return &EngineConfig{
 		WeekStartDay:      time.Monday,
 		MaxHistoryEntries: DefaultMaxHistoryEntries,
+		Locale:            DefaultLocale,
 	}
 }
 
@@ -69,6 +84,9 @@ func NewEngineWithConfig(projectRoot string, dataSource DataSource, config *Engi
 	if config.MaxHistoryEntries <= 0 {
 		config.MaxHistoryEntries = DefaultMaxHistoryEntries
 	}
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
 	
 	engine := &Engine{
 		generators:    make(map[DocumentType]Generator),
@@ -87,6 +105,11 @@ func NewEngineWithConfig(projectRoot string, dataSource DataSource, config *Engi
 	return engine
 }
 
+// now returns the current time of the engine's clock
+func (e *Engine) now() time.Time {
+	return e.config.Clock.Now()
+}
+
 // RegisterGenerator adds a new document generator
 func (e *Engine) RegisterGenerator(generator Generator) error {
 	e.mu.Lock()
@@ -120,6 +143,14 @@ func (e *Engine) Generate(request GenerationRequest) (*GenerationResult, error)
 		request.Format = "markdown"
 	}
 
+	// Apply the engine's locale and timezone unless the request overrides them
+	if request.Locale == "" {
+		request.Locale = e.config.Locale
+	}
+	if request.Timezone == "" {
+		request.Timezone = e.config.Timezone
+	}
+
 	// Generate the document
 	result, err := generator.Generate(request)
 	if err != nil {
@@ -137,6 +168,10 @@ func (e *Engine) GenerateAll() ([]GenerationResult, error) {
 	e.mu.RLock()
 	docTypes := make([]DocumentType, 0, len(e.generators))
 	for docType := range e.generators {
+		// Tool changelogs are generated per spec source on reload
+		if docType == DocumentTypeToolChangelog {
+			continue
+		}
 		docTypes = append(docTypes, docType)
 	}
 	e.mu.RUnlock()
@@ -158,12 +193,12 @@ func (e *Engine) GenerateAll() ([]GenerationResult, error) {
 		case DocumentTypeChangelog:
 			// Last 30 days for changelog
 			request.DateRange = &DateRange{
-				StartDate: time.Now().AddDate(0, 0, -30),
-				EndDate:   time.Now(),
+				StartDate: e.now().AddDate(0, 0, -30),
+				EndDate:   e.now(),
 			}
 		case DocumentTypeReflection:
 			// Today for reflection
-			today := time.Now()
+			today := e.now()
 			startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
 			request.DateRange = &DateRange{
 				StartDate: startOfDay,
@@ -179,7 +214,7 @@ func (e *Engine) GenerateAll() ([]GenerationResult, error) {
 				Type:        docType,
 				Success:     false,
 				Error:       err.Error(),
-				GeneratedAt: time.Now(),
+				GeneratedAt: e.now(),
 			}
 		}
 
@@ -250,8 +285,11 @@ func (e *Engine) GetGenerationHistory(limit int) ([]GenerationResult, error) {
 func (e *Engine) GenerateDaily() ([]GenerationResult, error) {
 	var results []GenerationResult
 
-	// Generate daily reflection
-	today := time.Now()
+	// Generate daily reflection for today in the configured timezone
+	today := e.now()
+	if format, err := NewFormatter(e.config.Locale, e.config.Timezone); err == nil {
+		today = today.In(format.location)
+	}
 	reflectionDate := today.Format("2006-01-02")
 	reflectionPath := filepath.Join(e.projectRoot, "docs", "reflections", reflectionDate+".md")
 
@@ -294,8 +332,8 @@ func (e *Engine) GenerateWeekly() ([]GenerationResult, error) {
 	var results []GenerationResult
 
 	// Generate weekly changelog
-	weekAgo := time.Now().AddDate(0, 0, -7)
-	now := time.Now()
+	weekAgo := e.now().AddDate(0, 0, -7)
+	now := e.now()
 
 	changelogRequest := GenerationRequest{
 		Type:       DocumentTypeChangelog,
@@ -317,12 +355,30 @@ func (e *Engine) GenerateWeekly() ([]GenerationResult, error) {
 	return results, nil
 }
 
+// GenerateToolChangelog regenerates the tool catalog changelog for a spec source
+func (e *Engine) GenerateToolChangelog(sourceID string) (*GenerationResult, error) {
+	request := GenerationRequest{
+		Type:        DocumentTypeToolChangelog,
+		OutputPath:  filepath.Join(e.projectRoot, "docs", "tools", sourceID+"_changelog.md"),
+		SourceID:    sourceID,
+		IncludeData: true,
+		Format:      "markdown",
+	}
+
+	result, err := e.Generate(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tool changelog for %s: %w", sourceID, err)
+	}
+
+	return result, nil
+}
+
 // ProcessScheduledJobs runs any scheduled documentation generation jobs
 func (e *Engine) ProcessScheduledJobs() error {
 	e.mu.RLock()
 	jobs := make([]*ScheduledJob, 0, len(e.scheduledJobs))
 	for _, job := range e.scheduledJobs {
-		if job.Active && time.Now().After(job.NextRun) {
+		if job.Active && e.now().After(job.NextRun) {
 			jobs = append(jobs, job)
 		}
 	}
@@ -340,7 +396,7 @@ func (e *Engine) ProcessScheduledJobs() error {
 		// Set appropriate date range based on schedule
 		switch job.Schedule {
 		case "daily":
-			today := time.Now()
+			today := e.now()
 			startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
 			request.DateRange = &DateRange{
 				StartDate: startOfDay,
@@ -348,13 +404,13 @@ func (e *Engine) ProcessScheduledJobs() error {
 			}
 		case "weekly":
 			request.DateRange = &DateRange{
-				StartDate: time.Now().AddDate(0, 0, -7),
-				EndDate:   time.Now(),
+				StartDate: e.now().AddDate(0, 0, -7),
+				EndDate:   e.now(),
 			}
 		case "monthly":
 			request.DateRange = &DateRange{
-				StartDate: time.Now().AddDate(0, -1, 0),
-				EndDate:   time.Now(),
+				StartDate: e.now().AddDate(0, -1, 0),
+				EndDate:   e.now(),
 			}
 		}
 
@@ -431,7 +487,7 @@ func (e *Engine) GetStats() map[string]interface{} {
 
 	// Recent generation statistics
 	recent := make(map[DocumentType]int)
-	cutoff := time.Now().AddDate(0, 0, -7) // Last 7 days
+	cutoff := e.now().AddDate(0, 0, -7) // Last 7 days
 
 	for _, result := range e.history {
 		if result.GeneratedAt.After(cutoff) {
@@ -461,7 +517,7 @@ func (e *Engine) getDefaultOutputPath(docType DocumentType) string {
 	case DocumentTypeChangelog:
 		return filepath.Join(e.projectRoot, "docs", "changelog.md")
 	case DocumentTypeReflection:
-		date := time.Now().Format("2006-01-02")
+		date := e.now().Format("2006-01-02")
 		return filepath.Join(e.projectRoot, "docs", "reflections", date+".md")
 	case DocumentTypeReadme:
 		return filepath.Join(e.projectRoot, "README.md")
@@ -474,7 +530,7 @@ func (e *Engine) getDefaultOutputPath(docType DocumentType) string {
 
 // parseSchedule parses a schedule string and returns the next run time
 func (e *Engine) parseSchedule(schedule string) (time.Time, error) {
-	now := time.Now()
+	now := e.now()
 
 	switch schedule {
 	case "daily":
@@ -87,6 +87,9 @@ func (l *LearningDataSource) fetchLearningData() (*LearningSnapshot, error) {
 		TotalExecutions int              `json:"total_executions"`
 		SuccessRate     float64          `json:"success_rate"`
 		AverageLatency  int64            `json:"average_latency"` // nanoseconds
+		P50Latency      int64            `json:"p50_latency"`     // nanoseconds
+		P95Latency      int64            `json:"p95_latency"`     // nanoseconds
+		P99Latency      int64            `json:"p99_latency"`     // nanoseconds
 		ErrorBreakdown  map[string]int   `json:"error_breakdown"`
 		TopTools        []ToolUsageInfo  `json:"top_tools"`
 		RecentPatterns  []PatternSummary `json:"recent_patterns"`
@@ -103,6 +106,9 @@ func (l *LearningDataSource) fetchLearningData() (*LearningSnapshot, error) {
 		TotalExecutions: stats.TotalExecutions,
 		SuccessRate:     stats.SuccessRate,
 		AvgLatency:      time.Duration(stats.AverageLatency),
+		P50Latency:      time.Duration(stats.P50Latency),
+		P95Latency:      time.Duration(stats.P95Latency),
+		P99Latency:      time.Duration(stats.P99Latency),
 		TopTools:        stats.TopTools,
 		ErrorBreakdown:  stats.ErrorBreakdown,
 		RecentPatterns:  stats.RecentPatterns,
@@ -119,12 +125,18 @@ func (l *LearningDataSource) getMockLearningSnapshot() *LearningSnapshot {
 		TotalExecutions: 42,
 		SuccessRate:     0.97,
 		AvgLatency:      250 * time.Millisecond,
+		P50Latency:      190 * time.Millisecond,
+		P95Latency:      620 * time.Millisecond,
+		P99Latency:      910 * time.Millisecond,
 		TopTools: []ToolUsageInfo{
 			{
 				Name:           "openapi.petstore.listPets",
 				ExecutionCount: 25,
 				SuccessRate:    0.96,
 				AvgLatency:     180 * time.Millisecond,
+				P50Latency:     150 * time.Millisecond,
+				P95Latency:     420 * time.Millisecond,
+				P99Latency:     640 * time.Millisecond,
 				LastUsed:       time.Now().Add(-2 * time.Hour),
 			},
 			{
@@ -132,6 +144,9 @@ func (l *LearningDataSource) getMockLearningSnapshot() *LearningSnapshot {
 				ExecutionCount: 15,
 				SuccessRate:    1.0,
 				AvgLatency:     120 * time.Millisecond,
+				P50Latency:     110 * time.Millisecond,
+				P95Latency:     230 * time.Millisecond,
+				P99Latency:     310 * time.Millisecond,
 				LastUsed:       time.Now().Add(-1 * time.Hour),
 			},
 			{
@@ -139,6 +154,9 @@ func (l *LearningDataSource) getMockLearningSnapshot() *LearningSnapshot {
 				ExecutionCount: 8,
 				SuccessRate:    0.875,
 				AvgLatency:     350 * time.Millisecond,
+				P50Latency:     240 * time.Millisecond,
+				P95Latency:     880 * time.Millisecond,
+				P99Latency:     1200 * time.Millisecond,
 				LastUsed:       time.Now().Add(-30 * time.Minute),
 			},
 		},
@@ -318,6 +336,8 @@ func (l *LearningDataSource) GetHealthStatus() (map[string]interface{}, error) {
 		"status":            status,
 		"success_rate":      snapshot.SuccessRate,
 		"avg_latency_ms":    float64(snapshot.AvgLatency) / float64(time.Millisecond),
+		"p95_latency_ms":    float64(snapshot.P95Latency) / float64(time.Millisecond),
+		"p99_latency_ms":    float64(snapshot.P99Latency) / float64(time.Millisecond),
 		"total_executions":  snapshot.TotalExecutions,
 		"active_insights":   len(snapshot.ActiveInsights),
 		"critical_insights": l.countInsightsByPriority(snapshot.ActiveInsights, "critical"),
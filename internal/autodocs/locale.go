@@ -0,0 +1,239 @@
+package autodocs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used when neither the request nor the engine configures one
+const DefaultLocale = "en-US"
+
+// localeFormat describes how one locale writes dates, times and numbers
+type localeFormat struct {
+	shortDate    string // Go layout for numeric dates
+	time         string // Go layout for times of day
+	longDate     func(t time.Time, months []string) string
+	months       []string // January..December
+	weekdays     []string // Sunday..Saturday
+	decimalSep   string
+	groupSep     string
+	percentSpace bool // Whether a space separates a number from the percent sign
+}
+
+var englishMonths = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+var englishWeekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// locales lists the supported locales, keyed by BCP 47 tag
+var locales = map[string]localeFormat{
+	"en-US": {
+		shortDate: "01/02/2006",
+		time:      "3:04 PM",
+		longDate: func(t time.Time, months []string) string {
+			return fmt.Sprintf("%s %d, %d", months[t.Month()-1], t.Day(), t.Year())
+		},
+		months:     englishMonths,
+		weekdays:   englishWeekdays,
+		decimalSep: ".",
+		groupSep:   ",",
+	},
+	"en-GB": {
+		shortDate: "02/01/2006",
+		time:      "15:04",
+		longDate: func(t time.Time, months []string) string {
+			return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+		},
+		months:     englishMonths,
+		weekdays:   englishWeekdays,
+		decimalSep: ".",
+		groupSep:   ",",
+	},
+	"de-DE": {
+		shortDate: "02.01.2006",
+		time:      "15:04",
+		longDate: func(t time.Time, months []string) string {
+			return fmt.Sprintf("%d. %s %d", t.Day(), months[t.Month()-1], t.Year())
+		},
+		months:       []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		weekdays:     []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		decimalSep:   ",",
+		groupSep:     ".",
+		percentSpace: true,
+	},
+	"fr-FR": {
+		shortDate: "02/01/2006",
+		time:      "15:04",
+		longDate: func(t time.Time, months []string) string {
+			return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+		},
+		months:       []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		weekdays:     []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		decimalSep:   ",",
+		groupSep:     "\u202f", // Narrow no-break space
+		percentSpace: true,
+	},
+	"es-ES": {
+		shortDate: "02/01/2006",
+		time:      "15:04",
+		longDate: func(t time.Time, months []string) string {
+			return fmt.Sprintf("%d de %s de %d", t.Day(), months[t.Month()-1], t.Year())
+		},
+		months:       []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		weekdays:     []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		decimalSep:   ",",
+		groupSep:     ".",
+		percentSpace: true,
+	},
+	"ja-JP": {
+		shortDate: "2006/01/02",
+		time:      "15:04",
+		longDate: func(t time.Time, _ []string) string {
+			return fmt.Sprintf("%d年%d月%d日", t.Year(), int(t.Month()), t.Day())
+		},
+		months:     englishMonths,
+		weekdays:   []string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		decimalSep: ".",
+		groupSep:   ",",
+	},
+}
+
+// SupportedLocales returns the locale tags accepted in generation requests
+func SupportedLocales() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Formatter renders dates, durations and numbers in generated documents for
+// a locale and timezone. Machine-readable values (file names, metadata) keep
+// ISO formats and do not go through a Formatter.
+type Formatter struct {
+	locale   localeFormat
+	location *time.Location
+}
+
+// NewFormatter creates a formatter. An empty locale selects DefaultLocale and
+// an empty timezone selects the server's local timezone.
+func NewFormatter(locale, timezone string) (*Formatter, error) {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	format, exists := locales[locale]
+	if !exists {
+		return nil, fmt.Errorf("unsupported locale: %s", locale)
+	}
+
+	location := time.Local
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", timezone, err)
+		}
+		location = loaded
+	}
+
+	return &Formatter{locale: format, location: location}, nil
+}
+
+// ShortDate formats a numeric date, e.g. 01/02/2006 for en-US
+func (f *Formatter) ShortDate(t time.Time) string {
+	return t.In(f.location).Format(f.locale.shortDate)
+}
+
+// LongDate formats a date with the month spelled out, e.g. January 2, 2006 for en-US
+func (f *Formatter) LongDate(t time.Time) string {
+	return f.locale.longDate(t.In(f.location), f.locale.months)
+}
+
+// Time formats a time of day
+func (f *Formatter) Time(t time.Time) string {
+	return t.In(f.location).Format(f.locale.time)
+}
+
+// DateTime formats a short date and time of day
+func (f *Formatter) DateTime(t time.Time) string {
+	return f.ShortDate(t) + " " + f.Time(t)
+}
+
+// Timestamp formats a short date, time of day and timezone abbreviation
+func (f *Formatter) Timestamp(t time.Time) string {
+	return f.DateTime(t) + " " + t.In(f.location).Format("MST")
+}
+
+// Weekday returns the localized name of the day of the week
+func (f *Formatter) Weekday(t time.Time) string {
+	return f.locale.weekdays[t.In(f.location).Weekday()]
+}
+
+// Integer formats an integer with group separators
+func (f *Formatter) Integer(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	return sign + f.group(digits)
+}
+
+// Decimal formats a number with the given number of fraction digits
+func (f *Formatter) Decimal(v float64, precision int) string {
+	formatted := strconv.FormatFloat(math.Abs(v), 'f', precision, 64)
+	whole, fraction, _ := strings.Cut(formatted, ".")
+
+	result := f.group(whole)
+	if fraction != "" {
+		result += f.locale.decimalSep + fraction
+	}
+	if v < 0 && strings.Trim(formatted, "0.") != "" {
+		result = "-" + result
+	}
+	return result
+}
+
+// Percent formats a value that is already a percentage (0-100)
+func (f *Formatter) Percent(v float64, precision int) string {
+	if f.locale.percentSpace {
+		return f.Decimal(v, precision) + "\u00a0%" // No-break space
+	}
+	return f.Decimal(v, precision) + "%"
+}
+
+// Duration formats a duration in the largest unit that keeps it readable
+func (f *Formatter) Duration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return f.Decimal(float64(d)/float64(time.Millisecond), 1) + "ms"
+	case d < time.Minute:
+		return f.Decimal(d.Seconds(), 1) + "s"
+	case d < time.Hour:
+		return f.Decimal(d.Minutes(), 1) + "min"
+	default:
+		return f.Decimal(d.Hours(), 1) + "h"
+	}
+}
+
+// group inserts group separators into a string of digits
+func (f *Formatter) group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var builder strings.Builder
+	lead := len(digits) % 3
+	if lead > 0 {
+		builder.WriteString(digits[:lead])
+	}
+	for i := lead; i < len(digits); i += 3 {
+		if builder.Len() > 0 {
+			builder.WriteString(f.locale.groupSep)
+		}
+		builder.WriteString(digits[i : i+3])
+	}
+	return builder.String()
+}
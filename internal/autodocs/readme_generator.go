@@ -34,6 +34,11 @@ func (r *ReadmeGenerator) Generate(request GenerationRequest) (*GenerationResult
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
+	format, err := NewFormatter(request.Locale, request.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
 	// Get current data
 	projectInfo, err := r.dataSource.GetProjectInfo()
 	if err != nil {
@@ -72,7 +77,7 @@ func (r *ReadmeGenerator) Generate(request GenerationRequest) (*GenerationResult
 	}
 
 	// Generate new README content
-	content, metadata, err := r.generateReadme(projectInfo, learningSnapshot, commits, existingContent)
+	content, metadata, err := r.generateReadme(projectInfo, learningSnapshot, commits, existingContent, format)
 	if err != nil {
 		return &GenerationResult{
 			Type:    request.Type,
@@ -119,11 +124,15 @@ func (r *ReadmeGenerator) Validate(request GenerationRequest) error {
 		return fmt.Errorf("unsupported format: %s (only markdown supported)", request.Format)
 	}
 
+	if _, err := NewFormatter(request.Locale, request.Timezone); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // generateReadme creates the README content
-func (r *ReadmeGenerator) generateReadme(projectInfo map[string]interface{}, learning *LearningSnapshot, commits []GitCommit, existing string) (string, *DocumentMetadata, error) {
+func (r *ReadmeGenerator) generateReadme(projectInfo map[string]interface{}, learning *LearningSnapshot, commits []GitCommit, existing string, format *Formatter) (string, *DocumentMetadata, error) {
 	var content strings.Builder
 
 	// Preserve manual sections while updating automatic ones
@@ -139,7 +148,7 @@ func (r *ReadmeGenerator) generateReadme(projectInfo map[string]interface{}, lea
 	r.generateDescription(&content)
 
 	// Status section (auto-updated)
-	r.generateStatus(&content, projectInfo, learning, commits)
+	r.generateStatus(&content, projectInfo, learning, commits, format)
 
 	// Features section (preserve manual content)
 	if preserved, exists := preservedSections["features"]; exists {
@@ -169,10 +178,10 @@ func (r *ReadmeGenerator) generateReadme(projectInfo map[string]interface{}, lea
 	}
 
 	// Recent Activity (auto-updated)
-	r.generateRecentActivity(&content, commits, learning)
+	r.generateRecentActivity(&content, commits, learning, format)
 
 	// Performance Stats (auto-updated)
-	r.generatePerformanceStats(&content, learning)
+	r.generatePerformanceStats(&content, learning, format)
 
 	// Installation section (preserve manual content)
 	if preserved, exists := preservedSections["installation"]; exists {
@@ -229,7 +238,7 @@ func (r *ReadmeGenerator) generateReadme(projectInfo map[string]interface{}, lea
 	}
 
 	// Footer
-	r.generateFooter(&content)
+	r.generateFooter(&content, format)
 
 	// Metadata
 	metadata := &DocumentMetadata{
@@ -368,7 +377,7 @@ func (r *ReadmeGenerator) generateDescription(content *strings.Builder) {
 }
 
 // generateStatus creates status section
-func (r *ReadmeGenerator) generateStatus(content *strings.Builder, projectInfo map[string]interface{}, learning *LearningSnapshot, commits []GitCommit) {
+func (r *ReadmeGenerator) generateStatus(content *strings.Builder, projectInfo map[string]interface{}, learning *LearningSnapshot, commits []GitCommit, format *Formatter) {
 	content.WriteString("## 📊 Project Status\n\n")
 	content.WriteString("<!-- AUTO-GENERATED STATUS -->\n")
 
@@ -387,7 +396,7 @@ func (r *ReadmeGenerator) generateStatus(content *strings.Builder, projectInfo m
 	content.WriteString(fmt.Sprintf("**System Health**: %d/100 (%s)\n\n", healthScore, healthStatus))
 
 	// Active tools
-	content.WriteString(fmt.Sprintf("**Active Tools**: %d\n\n", len(learning.TopTools)))
+	content.WriteString(fmt.Sprintf("**Active Tools**: %s\n\n", format.Integer(int64(len(learning.TopTools)))))
 
 	// Recent activity
 	recentCommits := 0
@@ -396,7 +405,7 @@ func (r *ReadmeGenerator) generateStatus(content *strings.Builder, projectInfo m
 			recentCommits++
 		}
 	}
-	content.WriteString(fmt.Sprintf("**Commits (7 days)**: %d\n\n", recentCommits))
+	content.WriteString(fmt.Sprintf("**Commits (7 days)**: %s\n\n", format.Integer(int64(recentCommits))))
 
 	content.WriteString("*Status updated automatically*\n")
 	content.WriteString("<!-- END AUTO-GENERATED STATUS -->\n\n")
@@ -464,7 +473,7 @@ func (r *ReadmeGenerator) generateArchitecture(content *strings.Builder) {
 }
 
 // generateRecentActivity creates recent activity section
-func (r *ReadmeGenerator) generateRecentActivity(content *strings.Builder, commits []GitCommit, learning *LearningSnapshot) {
+func (r *ReadmeGenerator) generateRecentActivity(content *strings.Builder, commits []GitCommit, learning *LearningSnapshot, format *Formatter) {
 	content.WriteString("## 📈 Recent Activity\n\n")
 	content.WriteString("<!-- AUTO-GENERATED ACTIVITY -->\n")
 
@@ -484,9 +493,9 @@ func (r *ReadmeGenerator) generateRecentActivity(content *strings.Builder, commi
 			timeAgo := time.Since(commit.Date)
 			var timeStr string
 			if timeAgo.Hours() < 24 {
-				timeStr = fmt.Sprintf("%.0fh ago", timeAgo.Hours())
+				timeStr = fmt.Sprintf("%sh ago", format.Decimal(timeAgo.Hours(), 0))
 			} else {
-				timeStr = fmt.Sprintf("%.0fd ago", timeAgo.Hours()/24)
+				timeStr = fmt.Sprintf("%sd ago", format.Decimal(timeAgo.Hours()/24, 0))
 			}
 
 			content.WriteString(fmt.Sprintf("- [`%s`](../../commit/%s) %s *(%s)*\n",
@@ -516,7 +525,7 @@ func (r *ReadmeGenerator) generateRecentActivity(content *strings.Builder, commi
 			content.WriteString(fmt.Sprintf("⚡ **%d High Priority** optimizations identified\n\n", highCount))
 		}
 
-		content.WriteString(fmt.Sprintf("📊 Total insights: %d\n\n", len(learning.ActiveInsights)))
+		content.WriteString(fmt.Sprintf("📊 Total insights: %s\n\n", format.Integer(int64(len(learning.ActiveInsights)))))
 	}
 
 	content.WriteString("*Activity updated automatically*\n")
@@ -524,7 +533,7 @@ func (r *ReadmeGenerator) generateRecentActivity(content *strings.Builder, commi
 }
 
 // generatePerformanceStats creates performance statistics section
-func (r *ReadmeGenerator) generatePerformanceStats(content *strings.Builder, learning *LearningSnapshot) {
+func (r *ReadmeGenerator) generatePerformanceStats(content *strings.Builder, learning *LearningSnapshot, format *Formatter) {
 	content.WriteString("## ⚡ Performance Statistics\n\n")
 	content.WriteString("<!-- AUTO-GENERATED PERFORMANCE -->\n")
 
@@ -540,7 +549,7 @@ func (r *ReadmeGenerator) generatePerformanceStats(content *strings.Builder, lea
 	if successRate < 90 {
 		successStatus = "🔴 Needs Improvement"
 	}
-	content.WriteString(fmt.Sprintf("| Success Rate | %.1f%% | %s |\n", successRate, successStatus))
+	content.WriteString(fmt.Sprintf("| Success Rate | %s | %s |\n", format.Percent(successRate, 1), successStatus))
 
 	// Average latency
 	if learning.AvgLatency > 0 {
@@ -552,14 +561,29 @@ func (r *ReadmeGenerator) generatePerformanceStats(content *strings.Builder, lea
 		if latencyMs > 500 {
 			latencyStatus = "🔴 Slow"
 		}
-		content.WriteString(fmt.Sprintf("| Avg Latency | %.1fms | %s |\n", latencyMs, latencyStatus))
+		content.WriteString(fmt.Sprintf("| Avg Latency | %s | %s |\n", format.Duration(learning.AvgLatency), latencyStatus))
+	}
+
+	// Tail latency, which the average hides
+	if learning.P99Latency > 0 {
+		content.WriteString(fmt.Sprintf("| p50 Latency | %s | 📊 Median |\n", format.Duration(learning.P50Latency)))
+		p99Ms := float64(learning.P99Latency) / float64(time.Millisecond)
+		tailStatus := "🟢 Fast"
+		if p99Ms > 500 {
+			tailStatus = "🟡 Good"
+		}
+		if p99Ms > 2000 {
+			tailStatus = "🔴 Slow"
+		}
+		content.WriteString(fmt.Sprintf("| p95 / p99 Latency | %s / %s | %s |\n",
+			format.Duration(learning.P95Latency), format.Duration(learning.P99Latency), tailStatus))
 	}
 
 	// Total executions
-	content.WriteString(fmt.Sprintf("| Total Executions | %d | 📊 Tracking |\n", learning.TotalExecutions))
+	content.WriteString(fmt.Sprintf("| Total Executions | %s | 📊 Tracking |\n", format.Integer(int64(learning.TotalExecutions))))
 
 	// Active tools
-	content.WriteString(fmt.Sprintf("| Active Tools | %d | 🔧 Running |\n", len(learning.TopTools)))
+	content.WriteString(fmt.Sprintf("| Active Tools | %s | 🔧 Running |\n", format.Integer(int64(len(learning.TopTools)))))
 
 	content.WriteString("\n*Statistics updated in real-time*\n")
 	content.WriteString("<!-- END AUTO-GENERATED PERFORMANCE -->\n\n")
@@ -648,9 +672,9 @@ func (r *ReadmeGenerator) generateLicense(content *strings.Builder) {
 }
 
 // generateFooter creates footer
-func (r *ReadmeGenerator) generateFooter(content *strings.Builder) {
+func (r *ReadmeGenerator) generateFooter(content *strings.Builder, format *Formatter) {
 	content.WriteString("---\n\n")
-	content.WriteString(fmt.Sprintf("*README last updated: %s*\n", time.Now().Format("2006-01-02 15:04:05 MST")))
+	content.WriteString(fmt.Sprintf("*README last updated: %s*\n", format.Timestamp(time.Now())))
 	content.WriteString("\n*This README is automatically updated with current project status and metrics.*\n")
 }
 
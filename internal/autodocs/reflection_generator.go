@@ -26,11 +26,17 @@ func (r *ReflectionGenerator) Generate(request GenerationRequest) (*GenerationRe
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	// Determine the reflection date (default to today)
+	format, err := NewFormatter(request.Locale, request.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// Determine the reflection date (default to today) in the configured timezone
 	reflectionDate := time.Now()
 	if request.DateRange != nil {
 		reflectionDate = request.DateRange.StartDate
 	}
+	reflectionDate = reflectionDate.In(format.location)
 
 	// Get learning snapshot and project info
 	learningSnapshot, err := r.dataSource.GetLearningSnapshot()
@@ -65,7 +71,7 @@ func (r *ReflectionGenerator) Generate(request GenerationRequest) (*GenerationRe
 	}
 
 	// Generate reflection content
-	content, metadata, err := r.generateReflection(reflectionDate, learningSnapshot, projectInfo, commits)
+	content, metadata, err := r.generateReflection(reflectionDate, learningSnapshot, projectInfo, commits, format)
 	if err != nil {
 		return &GenerationResult{
 			Type:    request.Type,
@@ -112,34 +118,38 @@ func (r *ReflectionGenerator) Validate(request GenerationRequest) error {
 		return fmt.Errorf("unsupported format: %s (only markdown supported)", request.Format)
 	}
 
+	if _, err := NewFormatter(request.Locale, request.Timezone); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // generateReflection creates the reflection document content
-func (r *ReflectionGenerator) generateReflection(date time.Time, learning *LearningSnapshot, projectInfo map[string]interface{}, commits []GitCommit) (string, *DocumentMetadata, error) {
+func (r *ReflectionGenerator) generateReflection(date time.Time, learning *LearningSnapshot, projectInfo map[string]interface{}, commits []GitCommit, format *Formatter) (string, *DocumentMetadata, error) {
 	var content strings.Builder
 
 	// Header
-	content.WriteString(fmt.Sprintf("# Daily Reflection - %s\n\n", date.Format("January 2, 2006")))
-	content.WriteString(fmt.Sprintf("*Generated automatically at %s*\n\n", time.Now().Format("15:04:05 MST")))
+	content.WriteString(fmt.Sprintf("# Daily Reflection - %s\n\n", format.LongDate(date)))
+	content.WriteString(fmt.Sprintf("*Generated automatically at %s*\n\n", format.Timestamp(time.Now())))
 
 	// Executive Summary
-	r.generateExecutiveSummary(&content, learning, commits)
+	r.generateExecutiveSummary(&content, learning, commits, format)
 
 	// Development Activity
-	r.generateDevelopmentActivity(&content, commits, projectInfo)
+	r.generateDevelopmentActivity(&content, commits, projectInfo, format)
 
 	// Learning Insights
 	r.generateLearningInsights(&content, learning)
 
 	// Performance Analysis
-	r.generatePerformanceAnalysis(&content, learning)
+	r.generatePerformanceAnalysis(&content, learning, format)
 
 	// Error Analysis
-	r.generateErrorAnalysis(&content, learning)
+	r.generateErrorAnalysis(&content, learning, format)
 
 	// Tool Usage Patterns
-	r.generateToolUsagePatterns(&content, learning)
+	r.generateToolUsagePatterns(&content, learning, format)
 
 	// Recommendations
 	r.generateRecommendations(&content, learning, commits)
@@ -171,22 +181,25 @@ func (r *ReflectionGenerator) generateReflection(date time.Time, learning *Learn
 }
 
 // generateExecutiveSummary creates an executive summary
-func (r *ReflectionGenerator) generateExecutiveSummary(content *strings.Builder, learning *LearningSnapshot, commits []GitCommit) {
+func (r *ReflectionGenerator) generateExecutiveSummary(content *strings.Builder, learning *LearningSnapshot, commits []GitCommit, format *Formatter) {
 	content.WriteString("## 📊 Executive Summary\n\n")
 
 	// Key metrics
 	content.WriteString("### Key Metrics\n\n")
-	content.WriteString(fmt.Sprintf("- **Total Executions**: %d\n", learning.TotalExecutions))
-	content.WriteString(fmt.Sprintf("- **Success Rate**: %.1f%%\n", learning.SuccessRate*100))
+	content.WriteString(fmt.Sprintf("- **Total Executions**: %s\n", format.Integer(int64(learning.TotalExecutions))))
+	content.WriteString(fmt.Sprintf("- **Success Rate**: %s\n", format.Percent(learning.SuccessRate*100, 1)))
 
 	if learning.AvgLatency > 0 {
-		latencyMs := float64(learning.AvgLatency) / float64(time.Millisecond)
-		content.WriteString(fmt.Sprintf("- **Average Latency**: %.1fms\n", latencyMs))
+		content.WriteString(fmt.Sprintf("- **Average Latency**: %s\n", format.Duration(learning.AvgLatency)))
+	}
+	if learning.P99Latency > 0 {
+		content.WriteString(fmt.Sprintf("- **Latency p50/p95/p99**: %s / %s / %s\n",
+			format.Duration(learning.P50Latency), format.Duration(learning.P95Latency), format.Duration(learning.P99Latency)))
 	}
 
-	content.WriteString(fmt.Sprintf("- **Commits Today**: %d\n", len(commits)))
-	content.WriteString(fmt.Sprintf("- **Active Insights**: %d\n", len(learning.ActiveInsights)))
-	content.WriteString(fmt.Sprintf("- **Patterns Detected**: %d\n\n", len(learning.RecentPatterns)))
+	content.WriteString(fmt.Sprintf("- **Commits Today**: %s\n", format.Integer(int64(len(commits)))))
+	content.WriteString(fmt.Sprintf("- **Active Insights**: %s\n", format.Integer(int64(len(learning.ActiveInsights)))))
+	content.WriteString(fmt.Sprintf("- **Patterns Detected**: %s\n\n", format.Integer(int64(len(learning.RecentPatterns)))))
 
 	// Overall health assessment
 	healthScore := CalculateHealthScore(learning)
@@ -211,7 +224,7 @@ func (r *ReflectionGenerator) generateExecutiveSummary(content *strings.Builder,
 }
 
 // generateDevelopmentActivity creates development activity section
-func (r *ReflectionGenerator) generateDevelopmentActivity(content *strings.Builder, commits []GitCommit, projectInfo map[string]interface{}) {
+func (r *ReflectionGenerator) generateDevelopmentActivity(content *strings.Builder, commits []GitCommit, projectInfo map[string]interface{}, format *Formatter) {
 	content.WriteString("## 💻 Development Activity\n\n")
 
 	if len(commits) == 0 {
@@ -233,12 +246,16 @@ func (r *ReflectionGenerator) generateDevelopmentActivity(content *strings.Build
 	}
 
 	content.WriteString(fmt.Sprintf("### Commit Summary\n\n"))
-	content.WriteString(fmt.Sprintf("- **Commits**: %d\n", len(commits)))
-	content.WriteString(fmt.Sprintf("- **Files Changed**: %d\n", totalFiles))
-	content.WriteString(fmt.Sprintf("- **Lines Added**: +%d\n", totalInsertions))
-	content.WriteString(fmt.Sprintf("- **Lines Removed**: -%d\n", totalDeletions))
-	content.WriteString(fmt.Sprintf("- **Net Change**: %+d lines\n", totalInsertions-totalDeletions))
-	content.WriteString(fmt.Sprintf("- **Active Contributors**: %d\n\n", len(authors)))
+	netChange := format.Integer(int64(totalInsertions - totalDeletions))
+	if totalInsertions >= totalDeletions {
+		netChange = "+" + netChange
+	}
+	content.WriteString(fmt.Sprintf("- **Commits**: %s\n", format.Integer(int64(len(commits)))))
+	content.WriteString(fmt.Sprintf("- **Files Changed**: %s\n", format.Integer(int64(totalFiles))))
+	content.WriteString(fmt.Sprintf("- **Lines Added**: +%s\n", format.Integer(int64(totalInsertions))))
+	content.WriteString(fmt.Sprintf("- **Lines Removed**: -%s\n", format.Integer(int64(totalDeletions))))
+	content.WriteString(fmt.Sprintf("- **Net Change**: %s lines\n", netChange))
+	content.WriteString(fmt.Sprintf("- **Active Contributors**: %s\n\n", format.Integer(int64(len(authors)))))
 
 	// Recent commits
 	content.WriteString("### Recent Commits\n\n")
@@ -250,11 +267,11 @@ func (r *ReflectionGenerator) generateDevelopmentActivity(content *strings.Build
 		content.WriteString(fmt.Sprintf("- **%s** ([`%s`](../../commit/%s))\n",
 			commit.Subject, commit.ShortHash, commit.Hash))
 		content.WriteString(fmt.Sprintf("  *%s at %s*\n",
-			commit.Author, commit.Date.Format("15:04")))
+			commit.Author, format.Time(commit.Date)))
 
 		if commit.ChangedFiles > 0 {
-			content.WriteString(fmt.Sprintf("  %d files, +%d -%d lines\n",
-				commit.ChangedFiles, commit.Insertions, commit.Deletions))
+			content.WriteString(fmt.Sprintf("  %s files, +%s -%s lines\n",
+				format.Integer(int64(commit.ChangedFiles)), format.Integer(int64(commit.Insertions)), format.Integer(int64(commit.Deletions))))
 		}
 		content.WriteString("\n")
 	}
@@ -322,7 +339,7 @@ func (r *ReflectionGenerator) generateLearningInsights(content *strings.Builder,
 }
 
 // generatePerformanceAnalysis creates performance analysis section
-func (r *ReflectionGenerator) generatePerformanceAnalysis(content *strings.Builder, learning *LearningSnapshot) {
+func (r *ReflectionGenerator) generatePerformanceAnalysis(content *strings.Builder, learning *LearningSnapshot, format *Formatter) {
 	content.WriteString("## ⚡ Performance Analysis\n\n")
 
 	if learning.AvgLatency == 0 {
@@ -332,7 +349,11 @@ func (r *ReflectionGenerator) generatePerformanceAnalysis(content *strings.Build
 
 	latencyMs := float64(learning.AvgLatency) / float64(time.Millisecond)
 
-	content.WriteString(fmt.Sprintf("- **Average Response Time**: %.1fms\n", latencyMs))
+	content.WriteString(fmt.Sprintf("- **Average Response Time**: %s\n", format.Duration(learning.AvgLatency)))
+	if learning.P99Latency > 0 {
+		content.WriteString(fmt.Sprintf("- **Median Response Time**: %s\n", format.Duration(learning.P50Latency)))
+		content.WriteString(fmt.Sprintf("- **Tail Response Time**: %s p95, %s p99\n", format.Duration(learning.P95Latency), format.Duration(learning.P99Latency)))
+	}
 
 	// Performance assessment
 	var perfAssessment string
@@ -361,16 +382,20 @@ func (r *ReflectionGenerator) generatePerformanceAnalysis(content *strings.Build
 				break
 			}
 
-			toolLatencyMs := float64(tool.AvgLatency) / float64(time.Millisecond)
-			content.WriteString(fmt.Sprintf("- **%s**: %.1fms avg (%.1f%% success)\n",
-				tool.Name, toolLatencyMs, tool.SuccessRate*100))
+			if tool.P95Latency > 0 {
+				content.WriteString(fmt.Sprintf("- **%s**: %s avg, %s p95 (%s success)\n",
+					tool.Name, format.Duration(tool.AvgLatency), format.Duration(tool.P95Latency), format.Percent(tool.SuccessRate*100, 1)))
+				continue
+			}
+			content.WriteString(fmt.Sprintf("- **%s**: %s avg (%s success)\n",
+				tool.Name, format.Duration(tool.AvgLatency), format.Percent(tool.SuccessRate*100, 1)))
 		}
 		content.WriteString("\n")
 	}
 }
 
 // generateErrorAnalysis creates error analysis section
-func (r *ReflectionGenerator) generateErrorAnalysis(content *strings.Builder, learning *LearningSnapshot) {
+func (r *ReflectionGenerator) generateErrorAnalysis(content *strings.Builder, learning *LearningSnapshot, format *Formatter) {
 	content.WriteString("## 🐛 Error Analysis\n\n")
 
 	if len(learning.ErrorBreakdown) == 0 {
@@ -383,12 +408,12 @@ func (r *ReflectionGenerator) generateErrorAnalysis(content *strings.Builder, le
 		totalErrors += count
 	}
 
-	content.WriteString(fmt.Sprintf("**Total Errors**: %d\n\n", totalErrors))
+	content.WriteString(fmt.Sprintf("**Total Errors**: %s\n\n", format.Integer(int64(totalErrors))))
 
 	content.WriteString("### Error Breakdown\n\n")
 	for errorType, count := range learning.ErrorBreakdown {
 		percentage := float64(count) / float64(totalErrors) * 100
-		content.WriteString(fmt.Sprintf("- **%s**: %d (%.1f%%)\n", errorType, count, percentage))
+		content.WriteString(fmt.Sprintf("- **%s**: %s (%s)\n", errorType, format.Integer(int64(count)), format.Percent(percentage, 1)))
 	}
 	content.WriteString("\n")
 
@@ -403,15 +428,15 @@ func (r *ReflectionGenerator) generateErrorAnalysis(content *strings.Builder, le
 	if len(errorPatterns) > 0 {
 		content.WriteString("### Error Patterns\n\n")
 		for _, pattern := range errorPatterns {
-			content.WriteString(fmt.Sprintf("- **%s** (seen %d times)\n", pattern.Description, pattern.Frequency))
+			content.WriteString(fmt.Sprintf("- **%s** (seen %s times)\n", pattern.Description, format.Integer(int64(pattern.Frequency))))
 			content.WriteString(fmt.Sprintf("  *First seen: %s, Last seen: %s*\n\n",
-				pattern.FirstSeen.Format("Jan 2 15:04"), pattern.LastSeen.Format("Jan 2 15:04")))
+				format.DateTime(pattern.FirstSeen), format.DateTime(pattern.LastSeen)))
 		}
 	}
 }
 
 // generateToolUsagePatterns creates tool usage patterns section
-func (r *ReflectionGenerator) generateToolUsagePatterns(content *strings.Builder, learning *LearningSnapshot) {
+func (r *ReflectionGenerator) generateToolUsagePatterns(content *strings.Builder, learning *LearningSnapshot, format *Formatter) {
 	content.WriteString("## 🔧 Tool Usage Patterns\n\n")
 
 	if len(learning.TopTools) == 0 {
@@ -432,10 +457,10 @@ func (r *ReflectionGenerator) generateToolUsagePatterns(content *strings.Builder
 		}
 
 		usagePercentage := float64(tool.ExecutionCount) / float64(totalExecutions) * 100
-		content.WriteString(fmt.Sprintf("- **%s**: %d executions (%.1f%%)\n",
-			tool.Name, tool.ExecutionCount, usagePercentage))
-		content.WriteString(fmt.Sprintf("  Success Rate: %.1f%%, Last Used: %s\n\n",
-			tool.SuccessRate*100, tool.LastUsed.Format("Jan 2 15:04")))
+		content.WriteString(fmt.Sprintf("- **%s**: %s executions (%s)\n",
+			tool.Name, format.Integer(int64(tool.ExecutionCount)), format.Percent(usagePercentage, 1)))
+		content.WriteString(fmt.Sprintf("  Success Rate: %s, Last Used: %s\n\n",
+			format.Percent(tool.SuccessRate*100, 1), format.DateTime(tool.LastUsed)))
 	}
 
 	// Usage patterns
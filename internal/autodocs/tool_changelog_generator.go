@@ -0,0 +1,163 @@
+package autodocs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/importer"
+)
+
+// ToolChangeSource provides the recorded tool catalog changes for spec sources
+type ToolChangeSource interface {
+	GetCatalogChanges(sourceID string) []importer.ToolCatalogDiff
+}
+
+// ToolChangelogGenerator generates tool catalog changelogs from spec reload diffs
+type ToolChangelogGenerator struct {
+	changes ToolChangeSource
+}
+
+// NewToolChangelogGenerator creates a new tool changelog generator
+func NewToolChangelogGenerator(changes ToolChangeSource) *ToolChangelogGenerator {
+	return &ToolChangelogGenerator{
+		changes: changes,
+	}
+}
+
+// Generate creates a tool changelog document for the requested source
+func (t *ToolChangelogGenerator) Generate(request GenerationRequest) (*GenerationResult, error) {
+	if err := t.Validate(request); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	format, err := NewFormatter(request.Locale, request.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	diffs := t.changes.GetCatalogChanges(request.SourceID)
+	content := RenderToolChangelog(request.SourceID, diffs, format)
+
+	// Write to file
+	if err := WriteToFile(request.OutputPath, content); err != nil {
+		return &GenerationResult{
+			Type:    request.Type,
+			Success: false,
+			Error:   fmt.Sprintf("failed to write file: %v", err),
+		}, nil
+	}
+
+	metadata := &DocumentMetadata{
+		Version:     "1.0",
+		GeneratedAt: time.Now(),
+		DataSources: []string{"importer"},
+		Tags: map[string]string{
+			"source_id": request.SourceID,
+			"reloads":   fmt.Sprintf("%d", len(diffs)),
+		},
+	}
+
+	return &GenerationResult{
+		Type:          request.Type,
+		OutputPath:    request.OutputPath,
+		Success:       true,
+		GeneratedAt:   time.Now(),
+		ContentLength: len(content),
+		Metadata:      metadata,
+	}, nil
+}
+
+// GetSupportedTypes returns the document types this generator supports
+func (t *ToolChangelogGenerator) GetSupportedTypes() []DocumentType {
+	return []DocumentType{DocumentTypeToolChangelog}
+}
+
+// Validate checks if the generation request is valid
+func (t *ToolChangelogGenerator) Validate(request GenerationRequest) error {
+	if request.Type != DocumentTypeToolChangelog {
+		return fmt.Errorf("unsupported document type: %s", request.Type)
+	}
+
+	if request.SourceID == "" {
+		return fmt.Errorf("source ID is required")
+	}
+
+	if request.OutputPath == "" {
+		return fmt.Errorf("output path is required")
+	}
+
+	if request.Format != "" && request.Format != "markdown" {
+		return fmt.Errorf("unsupported format: %s (only markdown supported)", request.Format)
+	}
+
+	if _, err := NewFormatter(request.Locale, request.Timezone); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenderToolChangelog renders the tool catalog changelog for a source, newest reload first
+func RenderToolChangelog(sourceID string, diffs []importer.ToolCatalogDiff, format *Formatter) string {
+	var content strings.Builder
+
+	content.WriteString(fmt.Sprintf("# Tool Changelog: %s\n\n", sourceID))
+	content.WriteString("Tool catalog changes detected when this specification was reloaded.\n\n")
+	content.WriteString(fmt.Sprintf("*This changelog was automatically generated on %s*\n\n", format.Timestamp(time.Now())))
+
+	if len(diffs) == 0 {
+		content.WriteString("## No tool changes recorded\n\n")
+		return content.String()
+	}
+
+	for i := len(diffs) - 1; i >= 0; i-- {
+		writeToolCatalogDiff(&content, diffs[i], format)
+	}
+
+	return content.String()
+}
+
+// writeToolCatalogDiff writes the entry for a single reload
+func writeToolCatalogDiff(content *strings.Builder, diff importer.ToolCatalogDiff, format *Formatter) {
+	content.WriteString(fmt.Sprintf("## %s\n\n", format.DateTime(diff.Timestamp)))
+
+	var breaking, added, removed, changed []importer.ToolChange
+	for _, change := range diff.Changes {
+		switch {
+		case change.Breaking && change.Kind != importer.ToolChangeRemoved:
+			breaking = append(breaking, change)
+		case change.Kind == importer.ToolChangeAdded:
+			added = append(added, change)
+		case change.Kind == importer.ToolChangeRemoved:
+			removed = append(removed, change)
+		default:
+			changed = append(changed, change)
+		}
+	}
+
+	sections := []struct {
+		title   string
+		changes []importer.ToolChange
+	}{
+		{"💥 Breaking Changes", breaking},
+		{"🗑️ Removed Tools", removed},
+		{"✨ Added Tools", added},
+		{"🔧 Changed Tools", changed},
+	}
+
+	for _, section := range sections {
+		if len(section.changes) == 0 {
+			continue
+		}
+
+		content.WriteString(fmt.Sprintf("### %s\n\n", section.title))
+		for _, change := range section.changes {
+			content.WriteString(fmt.Sprintf("- `%s`\n", change.ToolName))
+			for _, detail := range change.Details {
+				content.WriteString(fmt.Sprintf("  - %s\n", detail))
+			}
+		}
+		content.WriteString("\n")
+	}
+}
@@ -8,10 +8,11 @@ import (
 type DocumentType string
 
 const (
-	DocumentTypeChangelog    DocumentType = "changelog"
-	DocumentTypeReflection   DocumentType = "reflection"
-	DocumentTypeReadme       DocumentType = "readme"
-	DocumentTypeArchitecture DocumentType = "architecture"
+	DocumentTypeChangelog     DocumentType = "changelog"
+	DocumentTypeReflection    DocumentType = "reflection"
+	DocumentTypeReadme        DocumentType = "readme"
+	DocumentTypeArchitecture  DocumentType = "architecture"
+	DocumentTypeToolChangelog DocumentType = "tool_changelog"
 )
 
 // GenerationRequest represents a request to generate documentation
@@ -20,7 +21,10 @@ type GenerationRequest struct {
 	OutputPath  string       `json:"output_path"`
 	DateRange   *DateRange   `json:"date_range,omitempty"`
 	IncludeData bool         `json:"include_data"`
-	Format      string       `json:"format"` // markdown, html, json
+	Format      string       `json:"format"`              // markdown, html, json
+	SourceID    string       `json:"source_id,omitempty"` // Spec source for tool changelogs
+	Locale      string       `json:"locale,omitempty"`    // BCP 47 tag for dates and numbers, e.g. de-DE
+	Timezone    string       `json:"timezone,omitempty"`  // IANA name, e.g. Europe/Berlin
 }
 
 // DateRange specifies a time range for documentation generation
@@ -64,6 +68,9 @@ type LearningSnapshot struct {
 	TotalExecutions int              `json:"total_executions"`
 	SuccessRate     float64          `json:"success_rate"`
 	AvgLatency      time.Duration    `json:"avg_latency"`
+	P50Latency      time.Duration    `json:"p50_latency"`
+	P95Latency      time.Duration    `json:"p95_latency"`
+	P99Latency      time.Duration    `json:"p99_latency"`
 	TopTools        []ToolUsageInfo  `json:"top_tools"`
 	ErrorBreakdown  map[string]int   `json:"error_breakdown"`
 	RecentPatterns  []PatternSummary `json:"recent_patterns"`
@@ -77,6 +84,9 @@ type ToolUsageInfo struct {
 	ExecutionCount int           `json:"execution_count"`
 	SuccessRate    float64       `json:"success_rate"`
 	AvgLatency     time.Duration `json:"avg_latency"`
+	P50Latency     time.Duration `json:"p50_latency"`
+	P95Latency     time.Duration `json:"p95_latency"`
+	P99Latency     time.Duration `json:"p99_latency"`
 	LastUsed       time.Time     `json:"last_used"`
 }
 
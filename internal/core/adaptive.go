@@ -0,0 +1,239 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// DefaultAdaptiveLatencyMultiplier is the headroom adapted timeouts leave over a tool's p99 latency
+const DefaultAdaptiveLatencyMultiplier = 3.0
+
+const (
+	// retryTargetSuccess is the share of invocations retry budgets aim to
+	// complete despite transient failures, assuming they are independent
+	retryTargetSuccess = 0.999
+
+	// maxRetriedErrorRate is the transient error rate above which a tool gets
+	// no retries; they would mostly multiply the load on a struggling upstream
+	maxRetriedErrorRate = 0.5
+)
+
+// AdaptiveConfig bounds the timeouts and retry budgets derived from the
+// recent latency and errors of each tool
+type AdaptiveConfig struct {
+	Interval          time.Duration // How often tools are profiled; zero disables adaptive tuning
+	Window            time.Duration // How far back executions are profiled
+	MinSamples        int64         // Executions a tool needs in the window to be tuned
+	LatencyMultiplier float64
+	MinTimeout        time.Duration
+	MaxTimeout        time.Duration
+	MaxRetries        int // Zero disables adaptive retries
+}
+
+// RetryBackoff sets the wait between adaptive retries, which doubles after
+// every attempt
+type RetryBackoff struct {
+	Initial time.Duration
+	Max     time.Duration // Upstreams asking for a longer wait are not retried
+}
+
+// adaptiveConfig reads the adaptive tuning configured under tools.adaptive
+func adaptiveConfig() (AdaptiveConfig, RetryBackoff, error) {
+	config := AdaptiveConfig{
+		Window:            time.Duration(viper.GetInt("tools.adaptive.window_minutes")) * time.Minute,
+		MinSamples:        viper.GetInt64("tools.adaptive.min_samples"),
+		LatencyMultiplier: viper.GetFloat64("tools.adaptive.latency_multiplier"),
+		MinTimeout:        time.Duration(viper.GetInt64("tools.adaptive.min_timeout_ms")) * time.Millisecond,
+		MaxTimeout:        time.Duration(viper.GetInt64("tools.adaptive.max_timeout_ms")) * time.Millisecond,
+		MaxRetries:        viper.GetInt("tools.adaptive.max_retries"),
+	}
+	backoff := RetryBackoff{
+		Initial: time.Duration(viper.GetInt64("tools.adaptive.retry_delay_ms")) * time.Millisecond,
+		Max:     time.Duration(viper.GetInt64("tools.adaptive.max_retry_delay_ms")) * time.Millisecond,
+	}
+	if !viper.GetBool("tools.adaptive.enabled") {
+		return config, backoff, nil
+	}
+	config.Interval = time.Duration(viper.GetInt("tools.adaptive.interval_seconds")) * time.Second
+
+	switch {
+	case config.Interval <= 0:
+		return config, backoff, fmt.Errorf("tools.adaptive.interval_seconds must be positive")
+	case config.Window <= 0:
+		return config, backoff, fmt.Errorf("tools.adaptive.window_minutes must be positive")
+	case config.MinTimeout <= 0 || config.MaxTimeout < config.MinTimeout:
+		return config, backoff, fmt.Errorf("tools.adaptive.min_timeout_ms must be positive and at most max_timeout_ms")
+	case config.MaxRetries < 0:
+		return config, backoff, fmt.Errorf("tools.adaptive.max_retries must not be negative")
+	}
+	if config.LatencyMultiplier < 1 {
+		config.LatencyMultiplier = DefaultAdaptiveLatencyMultiplier
+	}
+	if config.MinSamples < 1 {
+		config.MinSamples = 1
+	}
+	return config, backoff, nil
+}
+
+// timeoutFor adapts a timeout to a tool's p99 latency within the configured bounds
+func (c AdaptiveConfig) timeoutFor(p99 time.Duration) time.Duration {
+	timeout := time.Duration(float64(p99) * c.LatencyMultiplier)
+	return min(max(timeout, c.MinTimeout), c.MaxTimeout)
+}
+
+// retriesFor sizes a retry budget so that retryTargetSuccess of invocations
+// complete at the given transient error rate
+func (c AdaptiveConfig) retriesFor(rate float64) int {
+	if c.MaxRetries <= 0 || rate <= 0 || rate > maxRetriedErrorRate {
+		return 0
+	}
+	attempts := int(math.Ceil(math.Log(1-retryTargetSuccess) / math.Log(rate)))
+	return min(max(attempts-1, 0), c.MaxRetries)
+}
+
+// adaptiveTuner periodically derives the timeout and retry budget of every
+// tool from its recent executions
+type adaptiveTuner struct {
+	registry *ToolRegistry
+	engine   *selflearn.Engine
+	config   AdaptiveConfig
+	clock    clock.Clock
+	logger   *zap.Logger
+}
+
+// newAdaptiveTuner creates the tuner configured under tools.adaptive, or
+// returns nil when adaptive tuning is disabled
+func newAdaptiveTuner(registry *ToolRegistry, engine *selflearn.Engine, c clock.Clock, logger *zap.Logger) (*adaptiveTuner, error) {
+	config, backoff, err := adaptiveConfig()
+	if err != nil || config.Interval <= 0 {
+		return nil, err
+	}
+	registry.SetRetryBackoff(backoff)
+	return &adaptiveTuner{registry: registry, engine: engine, config: config, clock: c, logger: logger}, nil
+}
+
+// Run tunes the tools right away and then every interval until ctx is done
+func (a *adaptiveTuner) Run(ctx context.Context) {
+	ticker := a.clock.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := a.tune(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Warn("Adaptive tuning failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// tune profiles the executions in the window and replaces the tunings of
+// the registry. Tools with too few executions keep the configured timeout
+// and get no retries.
+func (a *adaptiveTuner) tune(ctx context.Context) error {
+	now := a.clock.Now().UTC()
+	profiles, err := a.engine.GetToolProfiles(ctx, now.Add(-a.config.Window))
+	if err != nil {
+		return err
+	}
+	tunings := make(map[string]*types.AdaptiveTuning, len(profiles))
+	for name, profile := range profiles {
+		if profile.Executions < a.config.MinSamples {
+			continue
+		}
+		tunings[name] = &types.AdaptiveTuning{
+			TimeoutMs:          a.config.timeoutFor(profile.P99Latency).Milliseconds(),
+			MaxRetries:         a.config.retriesFor(profile.TransientErrorRate()),
+			P99LatencyMs:       float64(profile.P99Latency) / float64(time.Millisecond),
+			TransientErrorRate: profile.TransientErrorRate(),
+			Samples:            profile.Executions,
+			UpdatedAt:          now,
+		}
+	}
+	a.registry.SetAdaptiveTuning(tunings)
+	a.logger.Debug("Adapted tool timeouts and retries", zap.Int("tools", len(tunings)))
+	return nil
+}
+
+// adaptiveTuningOf returns the tuning of a tool as reported in its metadata,
+// or nil when it has none
+func (r *ToolRegistry) adaptiveTuningOf(name string) *types.AdaptiveTuning {
+	tuning := r.adaptive[name]
+	if tuning == nil {
+		return nil
+	}
+	reported := *tuning
+	_, reported.TimeoutOverridden = r.timeouts.Tools[name]
+	return &reported
+}
+
+// adaptiveTool reports its tuning in its metadata and retries transient
+// failures within its retry budget
+type adaptiveTool struct {
+	types.Tool
+	tuning  *types.AdaptiveTuning
+	backoff RetryBackoff
+}
+
+// Metadata returns the tool metadata with the adaptive tuning
+func (t *adaptiveTool) Metadata() types.ToolMetadata {
+	metadata := t.Tool.Metadata()
+	metadata.Adaptive = t.tuning
+	return metadata
+}
+
+// Execute runs the tool, retrying upstream failures that are expected to
+// clear. Attempts that time out are only retried for idempotent tools.
+func (t *adaptiveTool) Execute(ctx context.Context, input any) (any, error) {
+	wait := t.backoff.Initial
+	for attempt := 0; ; attempt++ {
+		result, err := types.Execute(ctx, t.Tool, input)
+		if err == nil || attempt >= t.tuning.MaxRetries || ctx.Err() != nil || !t.retryable(err) {
+			return result, err
+		}
+
+		delay := min(wait, t.backoff.Max)
+		if retryable, ok := types.AsRetryable(err); ok && retryable.RetryAfter > delay {
+			if retryable.RetryAfter > t.backoff.Max {
+				return result, err
+			}
+			delay = retryable.RetryAfter
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		}
+		wait *= 2
+	}
+}
+
+// retryable reports whether a failed attempt may succeed when repeated
+func (t *adaptiveTool) retryable(err error) bool {
+	if retryable, ok := types.AsRetryable(err); ok {
+		// Local throttling such as quotas does not clear within a retry
+		return retryable.StatusCode != 0
+	}
+	var timeout *types.TimeoutError
+	return errors.As(err, &timeout) && t.Tool.Metadata().Idempotent
+}
+
+// withAdaptiveTuning applies a tool's adaptive tuning, if any
+func withAdaptiveTuning(tool Tool, tuning *types.AdaptiveTuning, backoff RetryBackoff) Tool {
+	if tuning == nil {
+		return tool
+	}
+	return &adaptiveTool{Tool: tool, tuning: tuning, backoff: backoff}
+}
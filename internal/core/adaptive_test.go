@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// transientTool fails with err until it has been called failures times
+type transientTool struct {
+	flakyTool
+	err        error
+	failures   int32
+	idempotent bool
+}
+
+func (t *transientTool) Execute(ctx context.Context, input any) (any, error) {
+	if t.calls.Add(1) <= t.failures {
+		return nil, t.err
+	}
+	return t.TestTool.Execute(ctx, input)
+}
+
+func (t *transientTool) Metadata() types.ToolMetadata {
+	metadata := t.flakyTool.Metadata()
+	metadata.Idempotent = t.idempotent
+	return metadata
+}
+
+func TestToolRegistry_AdaptiveTuning(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	registry.SetTimeouts(TimeoutConfig{Default: time.Second})
+	registry.SetRetryBackoff(RetryBackoff{Initial: time.Millisecond, Max: 50 * time.Millisecond})
+	unavailable := &types.RetryableError{StatusCode: http.StatusServiceUnavailable, Reason: "upstream returned 503"}
+	register := func(name string, err error, failures int32) *transientTool {
+		tool := &transientTool{flakyTool: flakyTool{TestTool: TestTool{name: name}}, err: err, failures: failures}
+		require.NoError(t, registry.Register(tool))
+		return tool
+	}
+	flaky := register("openapi.flaky.get", unavailable, 2)
+	broken := register("openapi.broken.get", errors.New("invalid parameter"), 2)
+	throttled := register("openapi.throttled.get", &types.RetryableError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}, 2)
+	quota := register("openapi.quota.get", &types.RetryableError{Reason: "quota exhausted"}, 2)
+	untuned := register("openapi.untuned.get", unavailable, 2)
+
+	tuning := func(retries int) *types.AdaptiveTuning {
+		return &types.AdaptiveTuning{TimeoutMs: 200, MaxRetries: retries, Samples: 100}
+	}
+	invoke := func(name string, input map[string]any) (any, error) {
+		tool, err := registry.Get(name)
+		require.NoError(t, err)
+		return tool.Execute(context.Background(), input)
+	}
+	registry.SetAdaptiveTuning(map[string]*types.AdaptiveTuning{
+		"openapi.flaky.get":     tuning(2),
+		"openapi.broken.get":    tuning(2),
+		"openapi.throttled.get": tuning(2),
+		"openapi.quota.get":     tuning(2),
+	})
+
+	// Transient upstream failures are retried within the budget
+	_, err := invoke("openapi.flaky.get", map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), flaky.calls.Load())
+
+	// Other failures, waits longer than allowed and local throttling are not
+	for _, tool := range []*transientTool{broken, throttled, quota, untuned} {
+		_, err := invoke(tool.Name(), map[string]any{})
+		assert.Error(t, err, tool.Name())
+		assert.Equal(t, int32(1), tool.calls.Load(), tool.Name())
+	}
+
+	// A budget smaller than the failures gives up
+	flaky.calls.Store(0)
+	registry.SetAdaptiveTuning(map[string]*types.AdaptiveTuning{"openapi.flaky.get": tuning(1)})
+	_, err = invoke("openapi.flaky.get", map[string]any{})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, int32(2), flaky.calls.Load())
+
+	// The adapted timeout applies unless a per-tool timeout is configured
+	assert.Equal(t, 200*time.Millisecond, registry.ToolTimeout("openapi.flaky.get"))
+	assert.Equal(t, time.Second, registry.ToolTimeout("openapi.broken.get"))
+	for _, metadata := range registry.ListTools() {
+		if metadata.Name == "openapi.flaky.get" {
+			require.NotNil(t, metadata.Adaptive)
+			assert.Equal(t, 1, metadata.Adaptive.MaxRetries)
+			assert.False(t, metadata.Adaptive.TimeoutOverridden)
+		}
+	}
+	registry.SetToolTimeout("openapi.flaky.get", 5*time.Second)
+	assert.Equal(t, 5*time.Second, registry.ToolTimeout("openapi.flaky.get"))
+	tool, err := registry.Get("openapi.flaky.get")
+	require.NoError(t, err)
+	assert.True(t, tool.Metadata().Adaptive.TimeoutOverridden)
+
+	// Attempts that time out are only retried for idempotent tools
+	slow := &transientTool{flakyTool: flakyTool{TestTool: TestTool{name: "openapi.slow.get"}}, err: &types.TimeoutError{Tool: "openapi.slow.get"}, failures: 1}
+	require.NoError(t, registry.Register(slow))
+	registry.SetAdaptiveTuning(map[string]*types.AdaptiveTuning{"openapi.slow.get": tuning(1)})
+	_, err = invoke("openapi.slow.get", map[string]any{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	slow.calls.Store(0)
+	slow.idempotent = true
+	_, err = invoke("openapi.slow.get", map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), slow.calls.Load())
+
+	// Budgets aim for 99.9% success and skip tools that mostly fail
+	config := AdaptiveConfig{MaxRetries: 3, LatencyMultiplier: 3, MinTimeout: time.Second, MaxTimeout: time.Minute}
+	assert.Equal(t, 0, config.retriesFor(0))
+	assert.Equal(t, 1, config.retriesFor(0.01))
+	assert.Equal(t, 2, config.retriesFor(0.1))
+	assert.Equal(t, 3, config.retriesFor(0.3))
+	assert.Equal(t, 0, config.retriesFor(0.6))
+	assert.Equal(t, time.Second, config.timeoutFor(100*time.Millisecond))
+	assert.Equal(t, 6*time.Second, config.timeoutFor(2*time.Second))
+	assert.Equal(t, time.Minute, config.timeoutFor(time.Hour))
+}
+
+func TestServerAdaptiveTuning(t *testing.T) {
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	viper.Set("tools.timeout_ms", 30000)
+	viper.Set("tools.adaptive.enabled", true)
+	viper.Set("tools.adaptive.interval_seconds", 300)
+	viper.Set("tools.adaptive.window_minutes", 60)
+	viper.Set("tools.adaptive.min_samples", 4)
+	viper.Set("tools.adaptive.latency_multiplier", 3)
+	viper.Set("tools.adaptive.min_timeout_ms", 100)
+	viper.Set("tools.adaptive.max_timeout_ms", 10000)
+	viper.Set("tools.adaptive.max_retries", 2)
+	defer viper.Reset()
+
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC))
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{Clock: fake})
+	require.NoError(t, err)
+	defer server.Close()
+
+	// echo is slow but reliable; one in four status calls fails on the network
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, server.learningEngine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, 400*time.Millisecond))
+		var err error
+		if i == 0 {
+			err = errors.New("connection refused")
+		}
+		require.NoError(t, server.learningEngine.RecordExecution(ctx, "status", "builtin", nil, nil, err, 10*time.Millisecond))
+	}
+	require.NoError(t, server.learningEngine.RecordExecution(ctx, "openapi.rare.get", "openapi", nil, nil, nil, time.Second))
+	require.Eventually(t, func() bool {
+		stats, err := server.learningEngine.GetStats(ctx)
+		require.NoError(t, err)
+		return stats.TotalExecutions == 9
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, server.adaptive.tune(ctx))
+
+	// Timeouts follow the p99 latency within the bounds; retries the transient error rate
+	assert.Equal(t, 1200*time.Millisecond, server.toolRegistry.ToolTimeout("echo"))
+	assert.Equal(t, 100*time.Millisecond, server.toolRegistry.ToolTimeout("status"))
+	assert.Equal(t, 30*time.Second, server.toolRegistry.ToolTimeout("openapi.rare.get"), "too few samples to adapt")
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/mcp/tools", nil))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var listed struct {
+		Tools []types.ToolMetadata `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+	adaptive := map[string]*types.AdaptiveTuning{}
+	for _, tool := range listed.Tools {
+		adaptive[tool.Name] = tool.Adaptive
+	}
+	require.NotNil(t, adaptive["echo"])
+	assert.Equal(t, int64(1200), adaptive["echo"].TimeoutMs)
+	assert.Equal(t, 0, adaptive["echo"].MaxRetries)
+	assert.Equal(t, 400.0, adaptive["echo"].P99LatencyMs)
+	require.NotNil(t, adaptive["status"])
+	assert.Equal(t, 2, adaptive["status"].MaxRetries)
+	assert.Equal(t, 0.25, adaptive["status"].TransientErrorRate)
+	assert.Equal(t, int64(4), adaptive["status"].Samples)
+	assert.True(t, fake.Now().Equal(adaptive["status"].UpdatedAt))
+
+	// Executions that left the window no longer count
+	fake.Advance(2 * time.Hour)
+	require.NoError(t, server.adaptive.tune(ctx))
+	assert.Equal(t, 30*time.Second, server.toolRegistry.ToolTimeout("echo"))
+
+	viper.Set("tools.adaptive.max_timeout_ms", 50)
+	_, err = NewServerWithOptions(zap.NewNop(), ServerOptions{Clock: fake})
+	assert.ErrorContains(t, err, "max_timeout_ms")
+}
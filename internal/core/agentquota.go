@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/spf13/viper"
+)
+
+// ToolCostOverride sets what invocations of matching tools count against
+// daily cost quotas
+type ToolCostOverride struct {
+	Tool string  `mapstructure:"tool"` // Tool name or glob pattern
+	Cost float64 `mapstructure:"cost"`
+}
+
+// quotaConfig reads the default session quota and the tool costs configured
+// under agent.quotas
+func quotaConfig() (agent.DailyQuota, agent.ToolCost, error) {
+	session := agent.DailyQuota{
+		MaxInvocationsPerDay: viper.GetInt64("agent.quotas.session.max_invocations_per_day"),
+		MaxCostPerDay:        viper.GetFloat64("agent.quotas.session.max_cost_per_day"),
+	}
+	if session.MaxInvocationsPerDay < 0 || session.MaxCostPerDay < 0 {
+		return session, nil, fmt.Errorf("agent.quotas.session limits must not be negative")
+	}
+
+	var overrides []ToolCostOverride
+	if err := viper.UnmarshalKey("agent.quotas.tool_costs", &overrides); err != nil {
+		return session, nil, fmt.Errorf("invalid tool cost configuration: %w", err)
+	}
+	for _, override := range overrides {
+		if _, err := path.Match(override.Tool, ""); err != nil || override.Tool == "" {
+			return session, nil, fmt.Errorf("invalid tool cost pattern %q", override.Tool)
+		}
+		if override.Cost < 0 {
+			return session, nil, fmt.Errorf("cost of %s must not be negative", override.Tool)
+		}
+	}
+	// Tools can be made free with a "*" override; a zero default costs 1
+	defaultCost := viper.GetFloat64("agent.quotas.default_tool_cost")
+	if defaultCost < 0 {
+		return session, nil, fmt.Errorf("agent.quotas.default_tool_cost must not be negative")
+	}
+	if defaultCost == 0 {
+		defaultCost = 1
+	}
+	return session, toolCost(overrides, defaultCost), nil
+}
+
+// apiKeyQuota returns the quota of the API key a session authenticated with,
+// which its other sessions share
+func apiKeyQuota(keys *apikey.Store) agent.IdentityQuota {
+	return func(identity agent.Identity) (agent.DailyQuota, bool) {
+		id, ok := strings.CutPrefix(identity.Subject, apiKeySubjectPrefix)
+		if !ok {
+			return agent.DailyQuota{}, false
+		}
+		key, err := keys.Get(id)
+		if err != nil || key.Quota == nil {
+			return agent.DailyQuota{}, false
+		}
+		return agent.DailyQuota(*key.Quota), true
+	}
+}
+
+// toolCost prices tools by the first override naming them exactly, then the
+// first whose pattern matches, then the default
+func toolCost(overrides []ToolCostOverride, defaultCost float64) agent.ToolCost {
+	return func(tool types.ToolMetadata) float64 {
+		for _, override := range overrides {
+			if override.Tool == tool.Name {
+				return override.Cost
+			}
+		}
+		for _, override := range overrides {
+			if matched, _ := path.Match(override.Tool, tool.Name); matched {
+				return override.Cost
+			}
+		}
+		return defaultCost
+	}
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AliasOverlay gives a tool an alternate name through configuration, so
+// prompts written against an old name keep working after a rename
+type AliasOverlay struct {
+	Alias string `mapstructure:"alias"`
+	Tool  string `mapstructure:"tool"`
+}
+
+// ToolAlias reports an alias and how often callers still use it
+type ToolAlias struct {
+	Alias      string     `json:"alias"`
+	Tool       string     `json:"tool"`
+	Registered bool       `json:"registered"` // Whether the tool it resolves to is registered
+	Uses       int64      `json:"uses"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// toolAlias is an alternate name of a tool
+type toolAlias struct {
+	tool      string
+	uses      int64
+	lastUsed  time.Time
+	createdAt time.Time
+}
+
+// toolAliases resolves alternate tool names and counts their use. It has its
+// own lock so that lookups under the registry's read lock can record usage.
+type toolAliases struct {
+	mu      sync.Mutex
+	aliases map[string]*toolAlias // alias -> target
+}
+
+// newToolAliases creates an empty alias table
+func newToolAliases() *toolAliases {
+	return &toolAliases{aliases: make(map[string]*toolAlias)}
+}
+
+// set points an alias at a tool, keeping its usage when it is repointed
+func (a *toolAliases) set(alias, tool string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if existing, ok := a.aliases[alias]; ok {
+		existing.tool = tool
+		return
+	}
+	a.aliases[alias] = &toolAlias{tool: tool, createdAt: time.Now()}
+}
+
+// remove deletes an alias, reporting whether it existed
+func (a *toolAliases) remove(alias string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, exists := a.aliases[alias]
+	delete(a.aliases, alias)
+	return exists
+}
+
+// target returns the tool an alias points at without counting a use
+func (a *toolAliases) target(alias string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if entry, ok := a.aliases[alias]; ok {
+		return entry.tool, true
+	}
+	return "", false
+}
+
+// resolve returns the tool an alias points at and counts the use
+func (a *toolAliases) resolve(alias string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.aliases[alias]
+	if !ok {
+		return "", false
+	}
+	entry.uses++
+	entry.lastUsed = time.Now()
+	return entry.tool, true
+}
+
+// of lists the aliases of a tool, sorted
+func (a *toolAliases) of(tool string) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var names []string
+	for alias, entry := range a.aliases {
+		if entry.tool == tool {
+			names = append(names, alias)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// list reports every alias, sorted by alias
+func (a *toolAliases) list() []ToolAlias {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	aliases := make([]ToolAlias, 0, len(a.aliases))
+	for alias, entry := range a.aliases {
+		reported := ToolAlias{Alias: alias, Tool: entry.tool, Uses: entry.uses, CreatedAt: entry.createdAt}
+		if !entry.lastUsed.IsZero() {
+			lastUsed := entry.lastUsed
+			reported.LastUsedAt = &lastUsed
+		}
+		aliases = append(aliases, reported)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Alias < aliases[j].Alias })
+	return aliases
+}
+
+// AddAlias gives a tool an alternate name that Get resolves transparently.
+// The tool need not be registered yet, so aliases survive spec reloads. An
+// alias may not shadow a registered tool or point at another alias.
+func (r *ToolRegistry) AddAlias(alias, tool string) error {
+	if alias == "" || tool == "" {
+		return fmt.Errorf("alias and tool name cannot be empty")
+	}
+	if alias == tool {
+		return fmt.Errorf("alias %s cannot point at itself", alias)
+	}
+
+	r.mu.RLock()
+	_, shadows := r.tools[alias]
+	r.mu.RUnlock()
+	if shadows {
+		return fmt.Errorf("alias %s is the name of a registered tool", alias)
+	}
+	if _, chained := r.aliases.target(tool); chained {
+		return fmt.Errorf("alias %s cannot point at alias %s", alias, tool)
+	}
+	if targets := r.aliases.of(alias); len(targets) > 0 {
+		return fmt.Errorf("%s is the target of alias %s", alias, targets[0])
+	}
+	r.aliases.set(alias, tool)
+
+	r.logger.Info("Tool alias added",
+		zap.String("alias", alias),
+		zap.String("tool", tool))
+	return nil
+}
+
+// RemoveAlias retires an alias, reporting whether it existed
+func (r *ToolRegistry) RemoveAlias(alias string) bool {
+	removed := r.aliases.remove(alias)
+	if removed {
+		r.logger.Info("Tool alias removed", zap.String("alias", alias))
+	}
+	return removed
+}
+
+// ListAliases reports every alias with how often it has been used, so old
+// names can be retired once callers have moved on
+func (r *ToolRegistry) ListAliases() []ToolAlias {
+	aliases := r.aliases.list()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range aliases {
+		_, aliases[i].Registered = r.tools[aliases[i].Tool]
+	}
+	return aliases
+}
+
+// setupAliasRoutes mounts alias administration, which requires the admin scope
+func (s *Server) setupAliasRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin/aliases")
+
+	admin.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"aliases": s.toolRegistry.ListAliases()})
+	})
+
+	admin.PUT("/:alias", func(c *gin.Context) {
+		var req struct {
+			Tool string `json:"tool" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		alias := c.Param("alias")
+		c.Set(auditTargetKey, alias)
+		if err := s.toolRegistry.AddAlias(alias, req.Tool); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"alias": alias, "tool": req.Tool})
+	})
+
+	admin.DELETE("/:alias", func(c *gin.Context) {
+		alias := c.Param("alias")
+		c.Set(auditTargetKey, alias)
+		if !s.toolRegistry.RemoveAlias(alias) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("alias %s not found", alias)})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestToolRegistry_Aliases(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	require.NoError(t, registry.Register(&TestTool{name: "openapi.petstore.list_pets", source: "openapi"}))
+
+	require.NoError(t, registry.AddAlias("openapi.petstore.listPets", "openapi.petstore.list_pets"))
+	assert.Error(t, registry.AddAlias("openapi.petstore.list_pets", "echo"), "aliases may not shadow tools")
+	assert.Error(t, registry.AddAlias("pets", "openapi.petstore.listPets"), "aliases may not chain")
+	assert.Error(t, registry.AddAlias("openapi.petstore.list_pets", "openapi.petstore.list_pets"))
+
+	// Aliases resolve to the tool, and listings name them
+	tool, err := registry.Get("openapi.petstore.listPets")
+	require.NoError(t, err)
+	assert.Equal(t, "openapi.petstore.list_pets", tool.Name())
+	output, err := tool.Execute(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "openapi.petstore.list_pets", output.(map[string]any)["tool"])
+	for _, metadata := range registry.ListTools() {
+		if metadata.Name == "openapi.petstore.list_pets" {
+			assert.Equal(t, []string{"openapi.petstore.listPets"}, metadata.Aliases)
+		}
+	}
+
+	// Only lookups through the alias count as uses
+	_, err = registry.Get("openapi.petstore.list_pets")
+	require.NoError(t, err)
+	aliases := registry.ListAliases()
+	require.Len(t, aliases, 1)
+	assert.Equal(t, int64(1), aliases[0].Uses)
+	assert.True(t, aliases[0].Registered)
+	require.NotNil(t, aliases[0].LastUsedAt)
+
+	// Aliases outlive reloads of the tool they point at
+	require.NoError(t, registry.Unregister("openapi.petstore.list_pets"))
+	_, err = registry.Get("openapi.petstore.listPets")
+	assert.Error(t, err)
+	assert.False(t, registry.ListAliases()[0].Registered)
+	require.NoError(t, registry.Register(&TestTool{name: "openapi.petstore.list_pets", source: "openapi"}))
+	_, err = registry.Get("openapi.petstore.listPets")
+	assert.NoError(t, err)
+
+	assert.True(t, registry.RemoveAlias("openapi.petstore.listPets"))
+	assert.False(t, registry.RemoveAlias("openapi.petstore.listPets"))
+	_, err = registry.Get("openapi.petstore.listPets")
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/audit"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// auditTargetKey is the gin context key a handler sets to name what it acted
+// on when the route has no ID parameter
+const auditTargetKey = "audit_target"
+
+// Audit query bounds
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditedSpecRoutes maps spec routes to the actions they audit
+var auditedSpecRoutes = map[string]string{
+	"POST /api/v1/specs/":                     audit.ActionSpecImport,
+	"POST /api/v1/specs/:id/reload":           audit.ActionSpecReload,
+	"DELETE /api/v1/specs/:id":                audit.ActionSpecRemove,
+	"POST /api/v1/specs/groups/:group/reload": audit.ActionSpecReload,
+	"DELETE /api/v1/specs/groups/:group":      audit.ActionSpecRemove,
+}
+
+// openAuditLog opens the audit log when audit.enabled is set
+func openAuditLog(c clock.Clock, logger *zap.Logger) (*audit.Log, error) {
+	if !viper.GetBool("audit.enabled") {
+		return nil, nil
+	}
+	path := viper.GetString("audit.path")
+	if path == "" {
+		path = "./data/audit.db"
+	}
+	var log *audit.Log
+	if inMemoryStorage() {
+		path = storageTypeMemory
+		log = audit.OpenMemory()
+	} else {
+		var err error
+		if log, err = audit.Open(path); err != nil {
+			return nil, err
+		}
+	}
+	log.SetClock(c)
+
+	logger.Info("Audit log enabled", zap.String("path", path))
+	return log, nil
+}
+
+// auditActor identifies the caller of a request, preferring its authenticated identity
+func auditActor(ctx context.Context, transport, name, address string) audit.Actor {
+	actor := audit.Actor{Type: transport, Name: name, Address: address}
+	if identity, ok := agent.IdentityFromContext(ctx); ok {
+		actor.Subject = identity.Subject
+		if actor.Name == "" {
+			actor.Name = identity.AgentName
+		}
+	}
+	return actor
+}
+
+// recordAudit appends an entry to the audit log, logging failures
+func recordAudit(auditLog *audit.Log, event audit.Event, logger *zap.Logger) {
+	if err := auditLog.Record(event); err != nil {
+		logger.Error("Failed to record audit event",
+			zap.String("action", event.Action),
+			zap.String("target", event.Target),
+			zap.Error(err))
+	}
+}
+
+// auditInvocation records who invoked a tool over a transport with which parameters
+func auditInvocation(auditLog *audit.Log, actor audit.Actor, toolName, sourceType string, parameters any, err error, logger *zap.Logger) {
+	if auditLog == nil {
+		return
+	}
+	event := audit.Event{
+		Category: audit.CategoryTool,
+		Action:   audit.ActionToolInvoke,
+		Actor:    actor,
+		Target:   toolName,
+		Params:   parameters,
+		Outcome:  audit.OutcomeSuccess,
+		Details:  map[string]string{"transport": actor.Type, "source": sourceType},
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Error = err.Error()
+	}
+	recordAudit(auditLog, event, logger)
+}
+
+// auditRequests records spec imports, reloads and removals and every change
+// made through the admin API, including tool status changes, once the
+// request completes. It runs after authentication so the caller's identity
+// is known.
+func auditRequests(auditLog *audit.Log, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		method, route := c.Request.Method, c.FullPath()
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions || route == "" {
+			return
+		}
+		event := audit.Event{Actor: auditActor(c.Request.Context(), "http", "", c.ClientIP())}
+		if action, ok := auditedSpecRoutes[method+" "+route]; ok {
+			event.Category, event.Action = audit.CategorySpec, action
+			event.Target = c.Param("id")
+			if group := c.Param("group"); group != "" {
+				event.Target = "group:" + group
+			}
+		} else if strings.HasPrefix(route, "/api/v1/admin/") || strings.HasPrefix(route, "/api/v1/agents/admin/") || route == toolStatusRoute ||
+			route == applyRemediationRoute || route == rejectRemediationRoute {
+			event.Category, event.Action = audit.CategoryAdmin, method+" "+route
+			event.Target = c.Request.URL.Path
+		} else {
+			return
+		}
+		if target := c.GetString(auditTargetKey); target != "" {
+			event.Target = target
+		}
+
+		status := c.Writer.Status()
+		event.Outcome = audit.OutcomeSuccess
+		if status >= http.StatusBadRequest {
+			event.Outcome = audit.OutcomeFailure
+		}
+		event.Details = map[string]string{"status": strconv.Itoa(status)}
+		recordAudit(auditLog, event, logger)
+	}
+}
+
+// setupAuditRoutes mounts audit log queries, which require the admin scope
+func (s *Server) setupAuditRoutes(router *gin.Engine) {
+	router.GET("/api/v1/admin/audit", func(c *gin.Context) {
+		filter := audit.Filter{
+			Actor:    c.Query("actor"),
+			Category: audit.Category(c.Query("category")),
+			Action:   c.Query("action"),
+			Limit:    defaultAuditLimit,
+		}
+		for name, bound := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+			if value := c.Query(name); value != "" {
+				parsed, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be an RFC 3339 time", name)})
+					return
+				}
+				*bound = parsed
+			}
+		}
+		if value := c.Query("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > maxAuditLimit {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit)})
+				return
+			}
+			filter.Limit = limit
+		}
+
+		events, err := s.audit.Query(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"events": events, "count": len(events)})
+	})
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/aionmcp/aionmcp/pkg/audit"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerAuditLog(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("auth.api_keys.enabled", true)
+	viper.Set("auth.api_keys.bootstrap_key", "audit-admin-key")
+	viper.Set("audit.enabled", true)
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	call := func(method, path, body string) (int, string) {
+		request, err := http.NewRequest(method, httpServer.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set(apikey.Header, "audit-admin-key")
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, string(data)
+	}
+	query := func(params string) []audit.Event {
+		code, body := call("GET", "/api/v1/admin/audit?"+params, "")
+		require.Equal(t, http.StatusOK, code, body)
+		var page struct {
+			Events []audit.Event `json:"events"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &page))
+		return page.Events
+	}
+
+	code, _ := call("POST", "/api/v1/mcp/tools/echo/invoke", `{"message": "audited"}`)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = call("POST", "/api/v1/specs/", `{"id": "missing", "type": "openapi", "path": "/nonexistent/spec.yaml"}`)
+	require.Equal(t, http.StatusInternalServerError, code)
+	code, _ = call("PUT", "/api/v1/admin/read-only", `{"enabled": false}`)
+	require.Equal(t, http.StatusOK, code)
+	session, err := server.agentServer.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "planner"})
+	require.NoError(t, err)
+	_, err = server.agentServer.UnregisterAgent(context.Background(), &agentpb.UnregisterAgentRequest{SessionId: session.SessionId})
+	require.NoError(t, err)
+
+	// Invocations record who called the tool with what
+	invocations := query("category=tool")
+	require.Len(t, invocations, 1)
+	assert.Equal(t, "echo", invocations[0].Target)
+	assert.Equal(t, "bootstrap", invocations[0].Actor.Name)
+	assert.True(t, strings.HasPrefix(invocations[0].Actor.Subject, "apikey:"), invocations[0].Actor.Subject)
+	assert.Equal(t, map[string]any{"message": "audited"}, invocations[0].Params)
+
+	imports := query("action=spec.import")
+	require.Len(t, imports, 1)
+	assert.Equal(t, "missing", imports[0].Target)
+	assert.Equal(t, audit.OutcomeFailure, imports[0].Outcome)
+
+	admin := query("category=admin")
+	require.Len(t, admin, 1)
+	assert.Equal(t, "PUT /api/v1/admin/read-only", admin[0].Action)
+
+	sessions := query("actor=" + session.SessionId)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, audit.ActionSessionUnregister, sessions[0].Action)
+	assert.Equal(t, audit.ActionSessionRegister, sessions[1].Action)
+
+	// Queries honour time ranges and limits, and reject bad bounds
+	assert.Len(t, query("actor=bootstrap"), 3)
+	assert.Len(t, query("limit=2"), 2)
+	assert.Empty(t, query("since="+time.Now().Add(time.Hour).UTC().Format(time.RFC3339)))
+	code, _ = call("GET", "/api/v1/admin/audit?since=yesterday", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
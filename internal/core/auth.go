@@ -0,0 +1,527 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/aionmcp/aionmcp/pkg/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// principalContextKey is the gin context key holding the authenticated principal
+const principalContextKey = "principal"
+
+// authenticatorContextKey is the gin context key holding the authenticator,
+// set while authentication is enabled
+const authenticatorContextKey = "authenticator"
+
+// rpcPathPrefix is where the agent RPC gateway is mounted
+const rpcPathPrefix = "/api/v1/rpc/"
+
+// errAuthUnavailable is returned when credentials cannot be checked, e.g.
+// because the OIDC provider is unreachable
+var errAuthUnavailable = errors.New("authentication provider unavailable")
+
+// authenticator checks the credentials of protected requests: API keys and,
+// when an issuer is configured, OIDC bearer tokens
+type authenticator struct {
+	keys   *apikey.Store  // Nil while API keys are disabled
+	oidc   *oidc.Verifier // Nil while no OIDC issuer is configured
+	claims oidcClaims
+	logger *zap.Logger
+}
+
+// oidcClaims maps token claims to agent identity and scopes
+type oidcClaims struct {
+	AgentID    string                    // Claim holding the agent ID; empty lets agents choose theirs
+	AgentName  string                    // Claim holding the agent name
+	Roles      string                    // Claim, or dotted path, holding the roles
+	RoleScopes map[string][]apikey.Scope // Lower-cased role to granted scopes
+}
+
+// principal is an authenticated caller
+type principal struct {
+	Identity agent.Identity
+	KeyID    string // Set for API keys
+	Scopes   []apikey.Scope
+}
+
+// allows reports whether the principal holds a scope
+func (p principal) allows(scope apikey.Scope) bool {
+	return apikey.Key{Scopes: p.Scopes}.Allows(scope)
+}
+
+// scopeError is returned when a principal lacks the scope an operation requires
+type scopeError struct {
+	Subject string
+	Scope   apikey.Scope
+}
+
+func (e *scopeError) Error() string {
+	return fmt.Sprintf("%s lacks the %q scope", e.Subject, e.Scope)
+}
+
+// newAuthenticator sets up the configured credential types, returning nil
+// when authentication is disabled
+func newAuthenticator(logger *zap.Logger) (*authenticator, error) {
+	keys, err := openAPIKeys(logger)
+	if err != nil {
+		return nil, err
+	}
+	issuer := viper.GetString("auth.oidc.issuer")
+	if keys == nil && issuer == "" {
+		return nil, nil
+	}
+
+	auth := &authenticator{keys: keys, logger: logger}
+	if issuer != "" {
+		fail := func(err error) (*authenticator, error) {
+			if keys != nil {
+				keys.Close()
+			}
+			return nil, err
+		}
+		verifier, err := oidc.NewVerifier(oidc.Config{
+			Issuer:          issuer,
+			Audience:        viper.GetString("auth.oidc.audience"),
+			JWKSURL:         viper.GetString("auth.oidc.jwks_url"),
+			ClockSkew:       time.Duration(viper.GetInt("auth.oidc.clock_skew_seconds")) * time.Second,
+			RefreshInterval: time.Duration(viper.GetInt("auth.oidc.jwks_refresh_minutes")) * time.Minute,
+		}, nil)
+		if err != nil {
+			return fail(err)
+		}
+		roleScopes := make(map[string][]apikey.Scope)
+		for role, names := range viper.GetStringMapStringSlice("auth.oidc.role_scopes") {
+			scopes, err := apikey.ParseScopes(names)
+			if err != nil {
+				return fail(fmt.Errorf("invalid scopes for OIDC role %q: %w", role, err))
+			}
+			roleScopes[strings.ToLower(role)] = scopes
+		}
+		auth.oidc = verifier
+		auth.claims = oidcClaims{
+			AgentID:    viper.GetString("auth.oidc.agent_id_claim"),
+			AgentName:  viper.GetString("auth.oidc.agent_name_claim"),
+			Roles:      viper.GetString("auth.oidc.roles_claim"),
+			RoleScopes: roleScopes,
+		}
+		logger.Info("OIDC authentication enabled", zap.String("issuer", issuer), zap.Int("roles", len(roleScopes)))
+	}
+	return auth, nil
+}
+
+// openAPIKeys opens the API key store when authentication is enabled. A
+// bootstrap admin key is imported from configuration or, for an empty store,
+// generated and logged once.
+func openAPIKeys(logger *zap.Logger) (*apikey.Store, error) {
+	if !viper.GetBool("auth.api_keys.enabled") {
+		return nil, nil
+	}
+	path := viper.GetString("auth.api_keys.path")
+	if path == "" {
+		path = "./data/apikeys.db"
+	}
+	var store *apikey.Store
+	if inMemoryStorage() {
+		path = storageTypeMemory
+		store = apikey.OpenMemory()
+	} else {
+		var err error
+		if store, err = apikey.Open(path); err != nil {
+			return nil, err
+		}
+	}
+
+	adminScopes := []apikey.Scope{apikey.ScopeAdmin}
+	if token := viper.GetString("auth.api_keys.bootstrap_key"); token != "" {
+		if _, err := store.Import("bootstrap", token, adminScopes, 0); err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to import bootstrap API key: %w", err)
+		}
+	} else if store.Count() == 0 {
+		key, token, err := store.Create("bootstrap", adminScopes, 0)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to create bootstrap API key: %w", err)
+		}
+		logger.Warn("Generated bootstrap admin API key; store it now, it is not shown again",
+			zap.String("key_id", key.ID),
+			zap.String("api_key", token))
+	}
+
+	logger.Info("API key authentication enabled", zap.String("path", path), zap.Int("keys", store.Count()))
+	return store, nil
+}
+
+// apiKeySubjectPrefix starts the subject of identities authenticated by API key
+const apiKeySubjectPrefix = "apikey:"
+
+// authenticate resolves a credential to its principal. Bearer tokens shaped
+// like JWTs are validated against the OIDC issuer; anything else is an API key.
+func (a *authenticator) authenticate(ctx context.Context, credential string) (principal, error) {
+	if credential == "" {
+		return principal{}, apikey.ErrMissingKey
+	}
+	if a.oidc != nil && oidc.LooksLikeJWT(credential) {
+		token, err := a.oidc.Verify(ctx, credential)
+		if err != nil {
+			if !errors.Is(err, oidc.ErrInvalidToken) {
+				a.logger.Warn("OIDC token validation failed", zap.Error(err))
+				return principal{}, errAuthUnavailable
+			}
+			return principal{}, err
+		}
+		return a.claims.principal(token), nil
+	}
+	if a.keys == nil {
+		return principal{}, apikey.ErrInvalidKey
+	}
+	key, err := a.keys.Authenticate(credential)
+	if err != nil {
+		return principal{}, err
+	}
+	return principal{
+		Identity: agent.Identity{Subject: apiKeySubjectPrefix + key.ID, AgentName: key.Name, Roles: key.Roles},
+		KeyID:    key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// principal maps a validated token to an agent identity and scopes
+func (m oidcClaims) principal(token *oidc.Token) principal {
+	identity := agent.Identity{Subject: token.Subject}
+	if m.AgentID != "" {
+		identity.AgentID = token.Claims.String(m.AgentID)
+	}
+	if m.AgentName != "" {
+		identity.AgentName = token.Claims.String(m.AgentName)
+	}
+	var scopes []apikey.Scope
+	for _, role := range token.Claims.Strings(m.Roles) {
+		identity.Roles = append(identity.Roles, role)
+		scopes = append(scopes, m.RoleScopes[strings.ToLower(role)]...)
+	}
+	return principal{Identity: identity, Scopes: scopes}
+}
+
+// authorize authenticates a credential and checks it grants a scope
+func (a *authenticator) authorize(ctx context.Context, credential string, scope apikey.Scope) (principal, error) {
+	caller, err := a.authenticate(ctx, credential)
+	if err != nil {
+		return principal{}, err
+	}
+	if !caller.allows(scope) {
+		return caller, &scopeError{Subject: caller.Identity.Subject, Scope: scope}
+	}
+	return caller, nil
+}
+
+// routeScope returns the scope a route requires, if any
+func routeScope(method, path string) (apikey.Scope, bool) {
+	switch {
+	case strings.HasPrefix(path, "/api/v1/admin/"), strings.HasPrefix(path, "/api/v1/agents/admin/"), strings.HasPrefix(path, debugPathPrefix):
+		return apikey.ScopeAdmin, true
+	case method == http.MethodPost && path == "/api/v1/specs/groups/:group/onboarding":
+		// Bundles may carry a newly issued credential
+		return apikey.ScopeAdmin, true
+	case method == http.MethodPatch && path == toolStatusRoute:
+		// Disabling a tool takes it away from every client
+		return apikey.ScopeAdmin, true
+	case method == http.MethodPost && (path == applyRemediationRoute || path == rejectRemediationRoute):
+		// Remediations disable tools and change timeouts
+		return apikey.ScopeAdmin, true
+	case method != http.MethodGet && strings.HasPrefix(path, "/api/v1/specs/"):
+		// Imports, previews and validation fetch arbitrary paths and URLs, and
+		// the other spec changes alter the tool catalog
+		return apikey.ScopeAdmin, true
+	case method != http.MethodGet && (path == "/api/v1/workflows" || strings.HasPrefix(path, "/api/v1/workflows/")):
+		return apikey.ScopeAdmin, true
+	case method == http.MethodPost && path == "/api/v1/learning/analyze":
+		return apikey.ScopeAdmin, true
+	case method == http.MethodPost && path == "/api/v1/agents/register":
+		return apikey.ScopeAgentsRegister, true
+	case method == http.MethodPost && (path == "/api/v1/agents/:session_id/tools/:tool_name/invoke" || path == "/api/v1/mcp/tools/:name/invoke"):
+		return apikey.ScopeToolsInvoke, true
+	case path == "/mcp" || path == "/mcp/ws":
+		// JSON-RPC clients call tools through tools/call
+		return apikey.ScopeToolsInvoke, true
+	case strings.HasPrefix(path, rpcPathPrefix):
+		return rpcScope("/" + strings.TrimPrefix(path, rpcPathPrefix))
+	}
+	return "", false
+}
+
+// rpcScope returns the scope an agent RPC, given by its full method name,
+// requires, if any
+func rpcScope(fullMethod string) (apikey.Scope, bool) {
+	switch fullMethod[strings.LastIndex(fullMethod, "/")+1:] {
+	case "RegisterAgent":
+		return apikey.ScopeAgentsRegister, true
+	case "InvokeTool":
+		return apikey.ScopeToolsInvoke, true
+	case "TerminateSession":
+		return apikey.ScopeAdmin, true
+	}
+	return "", false
+}
+
+// authMiddleware rejects requests to protected routes without credentials
+// granting the route's scope
+func authMiddleware(auth *authenticator, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(authenticatorContextKey, auth)
+		scope, protected := routeScope(c.Request.Method, c.FullPath())
+		if !protected {
+			// Public routes still learn who calls them, so that tool
+			// listings follow the caller's roles
+			if credential := apikey.FromRequest(c.Request); credential != "" {
+				if caller, err := auth.authenticate(c.Request.Context(), credential); err == nil {
+					identify(c, caller)
+				}
+			}
+			c.Next()
+			return
+		}
+		caller, err := auth.authorize(c.Request.Context(), apikey.FromRequest(c.Request), scope)
+		if err != nil {
+			rejectCredentials(c, err, scope, logger)
+			return
+		}
+		identify(c, caller)
+		c.Next()
+	}
+}
+
+// requireScope checks that the caller holds a scope on routes whose scope
+// depends on the request, such as live smoke tests. It passes while
+// authentication is disabled and otherwise aborts the request when it fails.
+func requireScope(c *gin.Context, scope apikey.Scope) bool {
+	value, enabled := c.Get(authenticatorContextKey)
+	if !enabled {
+		return true
+	}
+	auth := value.(*authenticator)
+	caller, err := auth.authorize(c.Request.Context(), apikey.FromRequest(c.Request), scope)
+	if err != nil {
+		rejectCredentials(c, err, scope, auth.logger)
+		return false
+	}
+	identify(c, caller)
+	return true
+}
+
+// rejectCredentials aborts a request whose credentials do not grant a scope
+func rejectCredentials(c *gin.Context, err error, scope apikey.Scope, logger *zap.Logger) {
+	var denied *scopeError
+	status := http.StatusUnauthorized
+	switch {
+	case errors.As(err, &denied):
+		status = http.StatusForbidden
+	case errors.Is(err, errAuthUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		c.Header("WWW-Authenticate", `Bearer realm="aionmcp"`)
+	}
+	logger.Debug("Request credentials rejected",
+		zap.String("path", c.Request.URL.Path),
+		zap.String("scope", string(scope)),
+		zap.Error(err))
+	c.AbortWithStatusJSON(status, gin.H{"error": err.Error(), "scope": scope})
+}
+
+// identify attaches the authenticated caller to a request
+func identify(c *gin.Context, caller principal) {
+	c.Set(principalContextKey, caller)
+	c.Request = c.Request.WithContext(agent.WithIdentity(c.Request.Context(), caller.Identity))
+}
+
+// authorizeRPC checks the credentials of a gRPC call against its method's
+// scope, returning the context to continue the call with
+func authorizeRPC(ctx context.Context, auth *authenticator, fullMethod string) (context.Context, error) {
+	scope, protected := rpcScope(fullMethod)
+	if !protected {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	caller, err := auth.authorize(ctx, apikey.FromMetadata(md), scope)
+	var denied *scopeError
+	switch {
+	case err == nil:
+		return agent.WithIdentity(ctx, caller.Identity), nil
+	case errors.As(err, &denied):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, errAuthUnavailable):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	default:
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+}
+
+// authenticatedStream carries the caller's identity into a streaming RPC
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// agentInterceptors returns the interceptors agent RPCs pass through, on the
+// gRPC server and the HTTP gateway alike. When auth is set they protect RPCs
+// the way authMiddleware protects routes.
+func agentInterceptors(auth *authenticator) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	if auth == nil {
+		return nil, nil
+	}
+	authorizeUnary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorizeRPC(ctx, auth, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	authorizeStream := func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorizeRPC(stream.Context(), auth, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+	return []grpc.UnaryServerInterceptor{authorizeUnary}, []grpc.StreamServerInterceptor{authorizeStream}
+}
+
+// apiKeyIssuer issues onboarding credentials as API keys
+type apiKeyIssuer struct {
+	store *apikey.Store
+	ttl   time.Duration
+}
+
+// IssueOnboardingCredential creates a key letting a new agent register and
+// invoke tools
+func (i apiKeyIssuer) IssueOnboardingCredential(agentName string, _ []string) (*OnboardingCredential, error) {
+	scopes := []apikey.Scope{apikey.ScopeAgentsRegister, apikey.ScopeToolsInvoke}
+	key, token, err := i.store.Create("onboarding: "+agentName, scopes, i.ttl)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(scopes))
+	for n, scope := range scopes {
+		names[n] = string(scope)
+	}
+	return &OnboardingCredential{Header: apikey.Header, Value: token, Scopes: names, ExpiresAt: key.ExpiresAt}, nil
+}
+
+// setupAPIKeyRoutes mounts API key administration, which requires the admin scope
+func (s *Server) setupAPIKeyRoutes(router *gin.Engine) {
+	keys := router.Group("/api/v1/admin/apikeys")
+
+	keys.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"keys": s.apiKeys.List()})
+	})
+
+	keys.GET("/:id", func(c *gin.Context) {
+		key, err := s.apiKeys.Get(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, key)
+	})
+
+	// The response is the only time the key is revealed
+	keys.POST("", requireWritable(s.readOnly), func(c *gin.Context) {
+		var request struct {
+			Name             string        `json:"name" binding:"required"`
+			Scopes           []string      `json:"scopes"`
+			Roles            []string      `json:"roles"`              // Roles deciding which tools the key reaches
+			Quota            *apikey.Quota `json:"quota"`              // Daily usage cap across the key's sessions
+			ExpiresInSeconds int64         `json:"expires_in_seconds"` // Zero never expires
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		scopes, err := apikey.ParseScopes(request.Scopes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if request.ExpiresInSeconds < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_seconds must not be negative"})
+			return
+		}
+
+		key, token, err := s.apiKeys.Create(request.Name, scopes, time.Duration(request.ExpiresInSeconds)*time.Second)
+		if err == nil && len(request.Roles) > 0 {
+			key, err = s.apiKeys.SetRoles(key.ID, request.Roles)
+		}
+		if err == nil && request.Quota != nil {
+			key, err = s.apiKeys.SetQuota(key.ID, request.Quota)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		s.logger.Info("API key created",
+			zap.String("key_id", key.ID),
+			zap.String("name", key.Name),
+			zap.Any("scopes", key.Scopes),
+			zap.Strings("roles", key.Roles))
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusCreated, gin.H{"key": key, "api_key": token})
+	})
+
+	// A null quota lifts the key's daily cap
+	keys.PUT("/:id/quota", requireWritable(s.readOnly), func(c *gin.Context) {
+		var request struct {
+			Quota *apikey.Quota `json:"quota"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		key, err := s.apiKeys.SetQuota(c.Param("id"), request.Quota)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, apikey.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		s.logger.Info("API key quota updated", zap.String("key_id", key.ID), zap.Any("quota", key.Quota))
+		c.JSON(http.StatusOK, key)
+	})
+
+	keys.DELETE("/:id", requireWritable(s.readOnly), func(c *gin.Context) {
+		id := c.Param("id")
+		if caller, ok := c.Get(principalContextKey); ok && caller.(principal).KeyID == id {
+			c.JSON(http.StatusConflict, gin.H{"error": "an API key cannot revoke itself"})
+			return
+		}
+		if err := s.apiKeys.Revoke(id); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, apikey.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		s.logger.Warn("API key revoked", zap.String("key_id", id))
+		c.JSON(http.StatusOK, gin.H{"revoked": id})
+	})
+}
@@ -0,0 +1,288 @@
+package core
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/aionmcp/aionmcp/pkg/oidc"
+	"github.com/aionmcp/aionmcp/pkg/readonly"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerRouteScopes(t *testing.T) {
+	t.Chdir(t.TempDir())
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("auth.api_keys.enabled", true)
+	viper.Set("auth.api_keys.bootstrap_key", "scopes-admin-key")
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	call := func(method, path, key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(apikey.Header, key)
+		}
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	response := call(http.MethodPost, "/api/v1/admin/apikeys", "scopes-admin-key", `{"name": "invoker", "scopes": ["tools:invoke"]}`)
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var created struct {
+		APIKey string `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &created))
+
+	// Spec, workflow and analysis changes need the admin scope
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/specs/"},
+		{http.MethodPost, "/api/v1/specs/preview"},
+		{http.MethodPost, "/api/v1/specs/validate"},
+		{http.MethodPost, "/api/v1/specs/petstore/reload"},
+		{http.MethodPost, "/api/v1/specs/petstore/poll"},
+		{http.MethodDelete, "/api/v1/specs/petstore"},
+		{http.MethodPost, "/api/v1/specs/groups/billing/reload"},
+		{http.MethodDelete, "/api/v1/specs/groups/billing"},
+		{http.MethodPost, "/api/v1/specs/groups/billing/watch"},
+		{http.MethodDelete, "/api/v1/specs/groups/billing/watch"},
+		{http.MethodPost, "/api/v1/workflows"},
+		{http.MethodDelete, "/api/v1/workflows/nightly"},
+		{http.MethodPost, "/api/v1/learning/analyze"},
+	} {
+		name := route.method + " " + route.path
+		assert.Equal(t, http.StatusUnauthorized, call(route.method, route.path, "", "").Code, name)
+		assert.Equal(t, http.StatusForbidden, call(route.method, route.path, created.APIKey, "").Code, name)
+	}
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/api/v1/learning/analyze", "scopes-admin-key", "").Code)
+
+	// Live smoke tests run the tool, so they need tools:invoke; the other modes do not
+	smoke := "/api/v1/mcp/tools/echo/smoke"
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, smoke, "", `{"mode": "live"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, smoke, "aion_bogus", `{"mode": "live"}`).Code)
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, smoke, created.APIKey, `{"mode": "live"}`).Code)
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, smoke, "", `{"mode": "dry_run"}`).Code)
+
+	response = call(http.MethodPost, "/api/v1/admin/apikeys", "scopes-admin-key", `{"name": "registrar", "scopes": ["agents:register"]}`)
+	require.Equal(t, http.StatusCreated, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &created))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, smoke, created.APIKey, `{"mode": "live"}`).Code)
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	store, err := apikey.Open(filepath.Join(t.TempDir(), "keys.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	_, admin, err := store.Create("admin", []apikey.Scope{apikey.ScopeAdmin}, 0)
+	require.NoError(t, err)
+	_, invoker, err := store.Create("invoker", []apikey.Scope{apikey.ScopeToolsInvoke}, 0)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	auth := &authenticator{keys: store, logger: zap.NewNop()}
+	router.Use(authMiddleware(auth, zap.NewNop()))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/health", ok)
+	router.POST("/api/v1/agents/register", ok)
+	router.POST("/api/v1/agents/:session_id/tools/:tool_name/invoke", ok)
+	router.GET("/api/v1/admin/read-only", ok)
+	router.POST("/api/v1/rpc/aionmcp.agent.v1.AgentService/InvokeTool", ok)
+	router.POST("/api/v1/rpc/aionmcp.agent.v1.AgentService/ListTools", ok)
+	router.POST("/api/v1/rpc/aionmcp.agent.v1.AgentService/TerminateSession", ok)
+	server := &Server{apiKeys: store, readOnly: readonly.NewMode(), logger: zap.NewNop()}
+	server.setupAPIKeyRoutes(router)
+
+	call := func(method, path, key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(apikey.Header, key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusOK, call("GET", "/api/v1/health", "", "").Code)
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/rpc/aionmcp.agent.v1.AgentService/ListTools", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("POST", "/api/v1/agents/register", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("POST", "/api/v1/agents/s1/tools/echo/invoke", "aion_bogus", "").Code)
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/agents/s1/tools/echo/invoke", invoker, "").Code)
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/rpc/aionmcp.agent.v1.AgentService/InvokeTool", invoker, "").Code)
+	assert.Equal(t, http.StatusForbidden, call("POST", "/api/v1/agents/register", invoker, "").Code)
+	assert.Equal(t, http.StatusForbidden, call("GET", "/api/v1/admin/read-only", invoker, "").Code)
+	assert.Equal(t, http.StatusForbidden, call("POST", "/api/v1/rpc/aionmcp.agent.v1.AgentService/TerminateSession", invoker, "").Code)
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/rpc/aionmcp.agent.v1.AgentService/TerminateSession", admin, "").Code)
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/agents/register", admin, "").Code)
+
+	// Admins manage keys; the token is only returned on creation
+	assert.Equal(t, http.StatusForbidden, call("GET", "/api/v1/admin/apikeys", invoker, "").Code)
+	assert.Equal(t, http.StatusBadRequest, call("POST", "/api/v1/admin/apikeys", admin, `{"name": "x", "scopes": ["root"]}`).Code)
+	response := call("POST", "/api/v1/admin/apikeys", admin, `{"name": "registrar", "scopes": ["agents:register"], "expires_in_seconds": 3600}`)
+	require.Equal(t, http.StatusCreated, response.Code)
+	var created struct {
+		Key    apikey.Key `json:"key"`
+		APIKey string     `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &created))
+	assert.NotNil(t, created.Key.ExpiresAt)
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/agents/register", created.APIKey, "").Code)
+
+	response = call("GET", "/api/v1/admin/apikeys", admin, "")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 3, strings.Count(response.Body.String(), `"prefix"`))
+	assert.NotContains(t, response.Body.String(), created.APIKey)
+
+	assert.Equal(t, http.StatusOK, call("DELETE", "/api/v1/admin/apikeys/"+created.Key.ID, admin, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("POST", "/api/v1/agents/register", created.APIKey, "").Code)
+	assert.Equal(t, http.StatusNotFound, call("DELETE", "/api/v1/admin/apikeys/"+created.Key.ID, admin, "").Code)
+
+	// gRPC calls carry the key in metadata
+	ctx := context.Background()
+	invoke := "/aionmcp.agent.v1.AgentService/InvokeTool"
+	rpcCode := func(ctx context.Context, method string) codes.Code {
+		_, err := authorizeRPC(ctx, auth, method)
+		return status.Code(err)
+	}
+	assert.Equal(t, codes.Unauthenticated, rpcCode(ctx, invoke))
+	withKey := metadata.NewIncomingContext(ctx, metadata.Pairs(apikey.MetadataKey, invoker))
+	assert.Equal(t, codes.OK, rpcCode(withKey, invoke))
+	assert.Equal(t, codes.PermissionDenied, rpcCode(withKey, "/aionmcp.agent.v1.AgentService/RegisterAgent"))
+	assert.Equal(t, codes.OK, rpcCode(ctx, "/aionmcp.agent.v1.AgentService/HeartBeat"))
+
+	// Onboarding credentials let new agents register and invoke tools
+	credential, err := apiKeyIssuer{store: store, ttl: time.Hour}.IssueOnboardingCredential("Billing Bot", []string{"echo"})
+	require.NoError(t, err)
+	assert.Equal(t, apikey.Header, credential.Header)
+	assert.NotNil(t, credential.ExpiresAt)
+	_, err = store.Authorize(credential.Value, apikey.ScopeAgentsRegister)
+	assert.NoError(t, err)
+	_, err = store.Authorize(credential.Value, apikey.ScopeAdmin)
+	assert.Error(t, err)
+}
+
+func TestOIDCAuth(t *testing.T) {
+	signer, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(signer.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signer.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+	issue := func(claims map[string]interface{}) string {
+		claims["iss"], claims["aud"], claims["exp"] = "https://idp.example", "aionmcp", time.Now().Add(time.Hour).Unix()
+		head, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+		body, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(head) + "." + base64.RawURLEncoding.EncodeToString(body)
+		sum := sha256.Sum256([]byte(signed))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, signer, crypto.SHA256, sum[:])
+		require.NoError(t, err)
+		return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	verifier, err := oidc.NewVerifier(oidc.Config{Issuer: "https://idp.example", Audience: "aionmcp", JWKSURL: jwks.URL}, nil)
+	require.NoError(t, err)
+	logger := zap.NewNop()
+	auth := &authenticator{oidc: verifier, logger: logger, claims: oidcClaims{
+		AgentID:   "agent_id",
+		AgentName: "name",
+		Roles:     "realm_access.roles",
+		RoleScopes: map[string][]apikey.Scope{
+			"agent": {apikey.ScopeAgentsRegister, apikey.ScopeToolsInvoke},
+			"admin": {apikey.ScopeAdmin},
+		},
+	}}
+	agentToken := issue(map[string]interface{}{
+		"sub": "svc-billing", "agent_id": "billing-bot", "name": "Billing Bot",
+		"realm_access": map[string]interface{}{"roles": []string{"Agent"}},
+	})
+	rolelessToken := issue(map[string]interface{}{"sub": "someone"})
+
+	// Roles map to route scopes
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(authMiddleware(auth, logger))
+	var seen agent.Identity
+	router.POST("/api/v1/agents/register", func(c *gin.Context) {
+		seen, _ = agent.IdentityFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	router.GET("/api/v1/admin/read-only", func(c *gin.Context) { c.Status(http.StatusOK) })
+	call := func(method, path, token string) int {
+		request := httptest.NewRequest(method, path, nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/agents/register", agentToken))
+	assert.Equal(t, agent.Identity{Subject: "svc-billing", AgentID: "billing-bot", AgentName: "Billing Bot", Roles: []string{"Agent"}}, seen)
+	assert.Equal(t, http.StatusForbidden, call("GET", "/api/v1/admin/read-only", agentToken))
+	assert.Equal(t, http.StatusForbidden, call("POST", "/api/v1/agents/register", rolelessToken))
+	assert.Equal(t, http.StatusUnauthorized, call("POST", "/api/v1/agents/register", agentToken[:len(agentToken)-4]+"AAAA"))
+	// Opaque tokens are API keys, which are disabled here
+	assert.Equal(t, http.StatusUnauthorized, call("POST", "/api/v1/agents/register", "aion_abc"))
+
+	// gRPC registrations are bound to the token's agent
+	agentServer := agent.NewAgentServer(logger, NewToolRegistry(logger))
+	grpcServer, _ := newGRPCServer(GRPCConfig{KeepaliveTime: time.Minute, KeepaliveTimeout: time.Second}, agentServer, auth, logger)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = grpcServer.Serve(listener) }()
+	defer grpcServer.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := agentpb.NewAgentServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+agentToken)
+
+	_, err = client.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "other-bot"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	session, err := client.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{})
+	require.NoError(t, err)
+	agentStatus, err := client.GetAgentStatus(ctx, &agentpb.GetAgentStatusRequest{SessionId: session.SessionId})
+	require.NoError(t, err)
+	assert.Equal(t, "billing-bot", agentStatus.SessionInfo.AgentId)
+	assert.Equal(t, "Billing Bot", agentStatus.SessionInfo.AgentName)
+
+	// An unreachable provider is not the caller's fault
+	jwks.Close()
+	down, err := oidc.NewVerifier(oidc.Config{Issuer: "https://idp.example", Audience: "aionmcp", JWKSURL: jwks.URL}, nil)
+	require.NoError(t, err)
+	auth.oidc = down
+	assert.Equal(t, http.StatusServiceUnavailable, call("POST", "/api/v1/agents/register", agentToken))
+}
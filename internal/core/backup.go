@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/backup"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// BackupRun reports a scheduled backup
+type BackupRun struct {
+	StartedAt time.Time `json:"started_at"`
+	Name      string    `json:"name"`
+	Bytes     int64     `json:"bytes"`
+	Targets   []string  `json:"targets"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BackupSchedule periodically stores snapshots of learning storage in
+// a directory or an S3-compatible bucket
+type BackupSchedule struct {
+	engine   *selflearn.Engine
+	targets  []backup.Target
+	interval time.Duration
+	clock    clock.Clock
+	logger   *zap.Logger
+	mu       sync.Mutex
+	lastRun  *BackupRun
+}
+
+// backupTargets returns the targets configured under backup
+func backupTargets() ([]backup.Target, error) {
+	var targets []backup.Target
+	if dir := viper.GetString("backup.directory"); dir != "" {
+		targets = append(targets, backup.Directory{Path: dir, Keep: viper.GetInt("backup.keep")})
+	}
+	if bucket := viper.GetString("backup.s3.bucket"); bucket != "" {
+		s3, err := backup.NewS3(backup.S3Config{
+			Endpoint:        viper.GetString("backup.s3.endpoint"),
+			Region:          viper.GetString("backup.s3.region"),
+			Bucket:          bucket,
+			Prefix:          viper.GetString("backup.s3.prefix"),
+			AccessKeyID:     viper.GetString("backup.s3.access_key_id"),
+			SecretAccessKey: viper.GetString("backup.s3.secret_access_key"),
+			PathStyle:       viper.GetBool("backup.s3.path_style"),
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, s3)
+	}
+	return targets, nil
+}
+
+// newBackupSchedule creates the schedule configured by backup.interval_minutes,
+// or returns nil when scheduled backups are disabled
+func newBackupSchedule(engine *selflearn.Engine, c clock.Clock, logger *zap.Logger) (*BackupSchedule, error) {
+	interval := time.Duration(viper.GetInt("backup.interval_minutes")) * time.Minute
+	if interval <= 0 {
+		return nil, nil
+	}
+	targets, err := backupTargets()
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("backup.interval_minutes requires backup.directory or backup.s3.bucket")
+	}
+	return &BackupSchedule{engine: engine, targets: targets, interval: interval, clock: c, logger: logger}, nil
+}
+
+// Run stores a snapshot every interval until ctx ends
+func (b *BackupSchedule) Run(ctx context.Context) {
+	ticker := b.clock.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			b.BackupNow(ctx)
+		}
+	}
+}
+
+// BackupNow writes a snapshot to a temporary file and stores it in every
+// target. A failing target does not keep the snapshot from the others.
+func (b *BackupSchedule) BackupNow(ctx context.Context) BackupRun {
+	run := BackupRun{StartedAt: b.clock.Now(), Name: backup.SnapshotName(b.clock.Now()), Targets: []string{}}
+	err := b.backup(ctx, &run)
+	if err != nil {
+		run.Error = err.Error()
+		b.logger.Error("Scheduled backup failed", zap.String("name", run.Name), zap.Error(err))
+	} else {
+		b.logger.Info("Scheduled backup completed",
+			zap.String("name", run.Name),
+			zap.Int64("bytes", run.Bytes),
+			zap.Strings("targets", run.Targets))
+	}
+
+	b.mu.Lock()
+	b.lastRun = &run
+	b.mu.Unlock()
+	return run
+}
+
+func (b *BackupSchedule) backup(ctx context.Context, run *BackupRun) error {
+	file, err := os.CreateTemp("", "aionmcp-backup-*.db")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if run.Bytes, err = b.engine.Backup(ctx, file); err != nil {
+		return err
+	}
+	var failures []string
+	for _, target := range b.targets {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := target.Store(ctx, run.Name, file, run.Bytes); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", target, err))
+			continue
+		}
+		run.Targets = append(run.Targets, target.String())
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Status reports the schedule and its last run
+func (b *BackupSchedule) Status() gin.H {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	targets := make([]string, len(b.targets))
+	for i, target := range b.targets {
+		targets[i] = target.String()
+	}
+	return gin.H{
+		"interval_minutes": int(b.interval.Minutes()),
+		"targets":          targets,
+		"last_run":         b.lastRun,
+	}
+}
+
+// setupBackupRoutes mounts backup and restore of learning storage, which
+// require the admin scope. Only learning data is covered: API keys, roles,
+// audit events, session history, agent events and spec sources keep their
+// own databases. Spec sources are left out on purpose, as sources.db holds
+// their credentials unmasked.
+func (s *Server) setupBackupRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin")
+
+	// Stream a consistent snapshot; executions are still recorded meanwhile
+	admin.POST("/backup", func(c *gin.Context) {
+		name := backup.SnapshotName(time.Now())
+		written, err := s.learningEngine.Backup(c.Request.Context(), &snapshotWriter{c: c, name: name})
+		if errors.Is(err, selflearn.ErrBackupUnsupported) {
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			if !c.Writer.Written() {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to back up learning storage"})
+			}
+			// Otherwise headers are already sent; the truncated body is all the client gets
+			s.logger.Error("Failed to back up learning storage", zap.Int64("bytes", written), zap.Error(err))
+			return
+		}
+		s.logger.Info("Backed up learning storage", zap.String("name", name), zap.Int64("bytes", written))
+	})
+
+	// Replace learning data with a snapshot sent as the request body
+	admin.POST("/restore", func(c *gin.Context) {
+		body := &countingReader{r: c.Request.Body}
+		err := s.learningEngine.Restore(c.Request.Context(), body)
+		if errors.Is(err, selflearn.ErrBackupUnsupported) {
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, selflearn.ErrInvalidSnapshot) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			s.logger.Error("Failed to restore learning storage", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore learning storage"})
+			return
+		}
+		s.logger.Warn("Restored learning storage from snapshot", zap.Int64("bytes", body.n))
+		c.JSON(http.StatusOK, gin.H{"restored": true, "bytes": body.n})
+	})
+
+	// Report scheduled backups
+	admin.GET("/backups", func(c *gin.Context) {
+		if s.backups == nil {
+			c.JSON(http.StatusOK, gin.H{"enabled": false})
+			return
+		}
+		status := s.backups.Status()
+		status["enabled"] = true
+		c.JSON(http.StatusOK, status)
+	})
+}
+
+// snapshotWriter sends the snapshot headers on the first write, so a backup
+// that fails before writing anything can still answer with a JSON error
+type snapshotWriter struct {
+	c    *gin.Context
+	name string
+}
+
+func (w *snapshotWriter) Write(p []byte) (int, error) {
+	if !w.c.Writer.Written() {
+		w.c.Header("Content-Type", "application/octet-stream")
+		w.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.name))
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// RestoreStorage replaces the data of the configured learning storage with
+// a snapshot, for restoring while the server is stopped
+func RestoreStorage(ctx context.Context, snapshot io.Reader, logger *zap.Logger) error {
+	storageType := viper.GetString("storage.type")
+	if storageType == "" {
+		storageType = storageTypeBolt
+	}
+	storage, err := learning.OpenStorage(storageType, learning.StorageConfig{
+		Path:   viper.GetString("storage.path"),
+		Logger: logger,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open learning storage (is the server still running?): %w", err)
+	}
+	defer storage.Close()
+
+	backuper, ok := storage.(selflearn.Backuper)
+	if !ok {
+		return selflearn.ErrBackupUnsupported
+	}
+	return backuper.Restore(ctx, snapshot)
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerLearningBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	viper.Set("backup.interval_minutes", 60)
+	viper.Set("backup.directory", "backups")
+	viper.Set("backup.keep", 2)
+	defer viper.Reset()
+
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC))
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{Clock: fake})
+	require.NoError(t, err)
+	defer server.Close()
+	call := func(method, path string, body io.Reader) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(method, path, body))
+		return recorder
+	}
+	invoke := func() {
+		require.Equal(t, http.StatusOK, call(http.MethodPost, "/api/v1/mcp/tools/echo/invoke", strings.NewReader(`{"message": "hi"}`)).Code)
+	}
+	executions := func() int64 {
+		stats, err := server.learningEngine.GetStats(context.Background())
+		require.NoError(t, err)
+		return stats.TotalExecutions
+	}
+
+	invoke()
+	invoke()
+	require.Eventually(t, func() bool { return executions() == 2 }, time.Second, 10*time.Millisecond)
+	backup := call(http.MethodPost, "/api/v1/admin/backup", nil)
+	require.Equal(t, http.StatusOK, backup.Code)
+	assert.Equal(t, "application/octet-stream", backup.Header().Get("Content-Type"))
+	assert.Contains(t, backup.Header().Get("Content-Disposition"), `filename="aionmcp-`)
+	snapshot := backup.Body.Bytes()
+
+	// Restoring the snapshot brings back the data as it was
+	invoke()
+	require.Eventually(t, func() bool { return executions() == 3 }, time.Second, 10*time.Millisecond)
+	restore := call(http.MethodPost, "/api/v1/admin/restore", bytes.NewReader(snapshot))
+	require.Equal(t, http.StatusOK, restore.Code, restore.Body.String())
+	assert.JSONEq(t, fmt.Sprintf(`{"restored": true, "bytes": %d}`, len(snapshot)), restore.Body.String())
+	assert.Equal(t, int64(2), executions())
+	invoke()
+	require.Eventually(t, func() bool { return executions() == 3 }, time.Second, 10*time.Millisecond)
+
+	// Anything else is refused without touching the data
+	restore = call(http.MethodPost, "/api/v1/admin/restore", strings.NewReader("not a database"))
+	assert.Equal(t, http.StatusBadRequest, restore.Code)
+	assert.Equal(t, int64(3), executions())
+
+	// Scheduled backups land in the directory
+	require.Eventually(t, func() bool {
+		fake.Advance(time.Hour)
+		entries, err := os.ReadDir(filepath.Join(dir, "backups"))
+		return err == nil && len(entries) > 0
+	}, time.Second, 10*time.Millisecond)
+	var status struct {
+		Enabled bool       `json:"enabled"`
+		Targets []string   `json:"targets"`
+		LastRun *BackupRun `json:"last_run"`
+	}
+	require.Eventually(t, func() bool {
+		require.NoError(t, json.Unmarshal(call(http.MethodGet, "/api/v1/admin/backups", nil).Body.Bytes(), &status))
+		return status.LastRun != nil
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, []string{"directory:backups"}, status.Targets)
+	assert.Empty(t, status.LastRun.Error)
+	assert.Equal(t, []string{"directory:backups"}, status.LastRun.Targets)
+}
+
+func TestServerLearningBackupUnsupported(t *testing.T) {
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	defer viper.Reset()
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	for _, path := range []string{"/api/v1/admin/backup", "/api/v1/admin/restore"} {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader("")))
+		assert.Equal(t, http.StatusNotImplemented, recorder.Code, path)
+	}
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/admin/backups", nil))
+	assert.JSONEq(t, `{"enabled": false}`, recorder.Body.String())
+
+	// A schedule without a target is a configuration error
+	viper.Set("backup.interval_minutes", 60)
+	_, err = NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	assert.ErrorContains(t, err, "backup.directory")
+}
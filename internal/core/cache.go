@@ -0,0 +1,342 @@
+package core
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/invocationlog"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultResultCacheEntries bounds the in-memory result cache
+	DefaultResultCacheEntries = 1000
+
+	// resultCacheBucket holds persisted results
+	resultCacheBucket = "tool_results"
+)
+
+// ResultCacheConfig configures caching of idempotent tool results
+type ResultCacheConfig struct {
+	TTL        time.Duration            // How long results are served from the cache
+	MaxEntries int                      // Bound of the in-memory LRU; zero selects the default
+	Tools      map[string]time.Duration // Per-tool TTL overrides; zero disables caching of a tool
+	Path       string                   // Optional BoltDB file persisting results across restarts
+}
+
+// ToolCacheTTL overrides the cache TTL of one tool
+type ToolCacheTTL struct {
+	Tool  string `mapstructure:"tool"`
+	TTLMs int64  `mapstructure:"ttl_ms"`
+}
+
+// ResultCacheStats reports how often cached results were served
+type ResultCacheStats struct {
+	Entries  int                              `json:"entries"`
+	Hits     int64                            `json:"hits"`
+	Misses   int64                            `json:"misses"`
+	HitRatio float64                          `json:"hit_ratio"`
+	Tools    map[string]*ResultCacheToolStats `json:"tools,omitempty"`
+}
+
+// ResultCacheToolStats reports the cache hits and misses of one tool
+type ResultCacheToolStats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// cachedResult is a result as stored in memory and on disk
+type cachedResult struct {
+	Result    json.RawMessage `json:"result"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// cacheEntry is an element of the LRU list
+type cacheEntry struct {
+	key   string
+	value cachedResult
+}
+
+// ResultCache serves repeated invocations of idempotent tools from an
+// in-memory LRU, optionally backed by BoltDB
+type ResultCache struct {
+	mu      sync.Mutex
+	config  ResultCacheConfig
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
+	stats   map[string]*ResultCacheToolStats
+	db      *bolt.DB // Nil without persistence
+	logger  *zap.Logger
+}
+
+// NewResultCache creates a result cache, opening its BoltDB file if configured
+func NewResultCache(config ResultCacheConfig, logger *zap.Logger) (*ResultCache, error) {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultResultCacheEntries
+	}
+	cache := &ResultCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		stats:   make(map[string]*ResultCacheToolStats),
+		logger:  logger,
+	}
+
+	if config.Path != "" {
+		if err := os.MkdirAll(filepath.Dir(config.Path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		db, err := bolt.Open(config.Path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open result cache: %w", err)
+		}
+		if err := db.Update(pruneExpiredResults); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize result cache: %w", err)
+		}
+		cache.db = db
+	}
+	return cache, nil
+}
+
+// pruneExpiredResults creates the results bucket and drops expired results
+func pruneExpiredResults(tx *bolt.Tx) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(resultCacheBucket))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var expired [][]byte
+	err = bucket.ForEach(func(key, data []byte) error {
+		var value cachedResult
+		if json.Unmarshal(data, &value) != nil || now.After(value.ExpiresAt) {
+			expired = append(expired, append([]byte(nil), key...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the BoltDB file, if any
+func (c *ResultCache) Close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// ttlFor resolves how long results of a tool are cached
+func (c *ResultCache) ttlFor(name string) time.Duration {
+	if ttl, ok := c.config.Tools[name]; ok {
+		return ttl
+	}
+	return c.config.TTL
+}
+
+// get returns the cached result for a key, decoded afresh for every caller
+func (c *ResultCache) get(tool, key string) (any, bool) {
+	c.mu.Lock()
+	stats := c.toolStats(tool)
+	value, found := c.lookup(key)
+	if found {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+	c.mu.Unlock()
+
+	if !found {
+		return nil, false
+	}
+	var result any
+	if err := json.Unmarshal(value.Result, &result); err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+// lookup finds an unexpired result in memory or on disk; the caller holds the lock
+func (c *ResultCache) lookup(key string) (cachedResult, bool) {
+	now := time.Now()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		if now.Before(entry.value.ExpiresAt) {
+			c.order.MoveToFront(element)
+			return entry.value, true
+		}
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+
+	if c.db == nil {
+		return cachedResult{}, false
+	}
+	var value cachedResult
+	var found bool
+	err := c.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(resultCacheBucket)).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		found = now.Before(value.ExpiresAt)
+		return nil
+	})
+	if err != nil {
+		c.logger.Warn("Failed to read persisted tool result", zap.Error(err))
+		return cachedResult{}, false
+	}
+	if found {
+		c.remember(key, value)
+	}
+	return value, found
+}
+
+// put caches a result for ttl
+func (c *ResultCache) put(key string, result any, ttl time.Duration) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	value := cachedResult{Result: data, ExpiresAt: time.Now().Add(ttl)}
+
+	c.mu.Lock()
+	c.remember(key, value)
+	c.mu.Unlock()
+
+	if c.db == nil {
+		return
+	}
+	stored, err := json.Marshal(value)
+	if err == nil {
+		err = c.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket([]byte(resultCacheBucket)).Put([]byte(key), stored)
+		})
+	}
+	if err != nil {
+		c.logger.Warn("Failed to persist tool result", zap.Error(err))
+	}
+}
+
+// remember stores a result in the LRU, evicting the least recently used
+// results beyond the bound; the caller holds the lock
+func (c *ResultCache) remember(key string, value cachedResult) {
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value})
+	for c.order.Len() > c.config.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// toolStats returns the counters of a tool; the caller holds the lock
+func (c *ResultCache) toolStats(tool string) *ResultCacheToolStats {
+	stats, ok := c.stats[tool]
+	if !ok {
+		stats = &ResultCacheToolStats{}
+		c.stats[tool] = stats
+	}
+	return stats
+}
+
+// Stats reports the cache hit ratios overall and per tool
+func (c *ResultCache) Stats() ResultCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := ResultCacheStats{
+		Entries: c.order.Len(),
+		Tools:   make(map[string]*ResultCacheToolStats, len(c.stats)),
+	}
+	for tool, toolStats := range c.stats {
+		copied := *toolStats
+		copied.HitRatio = hitRatio(copied.Hits, copied.Misses)
+		stats.Tools[tool] = &copied
+		stats.Hits += copied.Hits
+		stats.Misses += copied.Misses
+	}
+	stats.HitRatio = hitRatio(stats.Hits, stats.Misses)
+	return stats
+}
+
+// hitRatio is the share of lookups served from the cache
+func hitRatio(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// cacheableResult reports whether a result reflects a successful upstream
+// call. Upstream errors returned as results are not cached.
+func cacheableResult(result any) bool {
+	fields, ok := result.(map[string]any)
+	if !ok {
+		return true
+	}
+	if errors, ok := fields["errors"]; ok && errors != nil {
+		return false
+	}
+	if statusCode, ok := fields["status_code"].(int); ok && statusCode >= 400 {
+		return false
+	}
+	return true
+}
+
+// cachedTool serves repeated invocations from the result cache
+type cachedTool struct {
+	types.Tool
+	cache *ResultCache
+	ttl   time.Duration
+}
+
+// Execute returns a cached result for the same parameters or runs the tool and caches its result
+func (t *cachedTool) Execute(ctx context.Context, input any) (any, error) {
+	name := t.Name()
+	key := name + "|" + invocationlog.HashParams(input)
+	if result, ok := t.cache.get(name, key); ok {
+		return result, nil
+	}
+
+	result, err := t.Tool.Execute(ctx, input)
+	if err == nil && cacheableResult(result) {
+		t.cache.put(key, result, t.ttl)
+	}
+	return result, err
+}
+
+// withCache caches the results of a tool that declares itself idempotent
+func withCache(tool Tool, cache *ResultCache) Tool {
+	if cache == nil {
+		return tool
+	}
+	ttl := cache.ttlFor(tool.Name())
+	if ttl <= 0 || !tool.Metadata().Idempotent {
+		return tool
+	}
+	return &cachedTool{Tool: tool, cache: cache, ttl: ttl}
+}
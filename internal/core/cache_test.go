@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// idempotentTool counts its calls and declares whether it is idempotent
+type idempotentTool struct {
+	flakyTool
+	idempotent bool
+}
+
+func (t *idempotentTool) Metadata() types.ToolMetadata {
+	metadata := t.flakyTool.Metadata()
+	metadata.Idempotent = t.idempotent
+	return metadata
+}
+
+func TestToolRegistry_ResultCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	newRegistry := func(config ResultCacheConfig) (*ToolRegistry, *ResultCache) {
+		registry := NewToolRegistry(zap.NewNop())
+		cache, err := NewResultCache(config, zap.NewNop())
+		require.NoError(t, err)
+		registry.SetResultCache(cache)
+		return registry, cache
+	}
+	invoke := func(registry *ToolRegistry, name string, input map[string]any) (any, error) {
+		tool, err := registry.Get(name)
+		require.NoError(t, err)
+		return tool.Execute(context.Background(), input)
+	}
+
+	registry, cache := newRegistry(ResultCacheConfig{
+		TTL:   time.Minute,
+		Tools: map[string]time.Duration{"openapi.users.getShort": 50 * time.Millisecond},
+		Path:  path,
+	})
+	reader := &idempotentTool{flakyTool: flakyTool{TestTool: TestTool{name: "openapi.users.get"}}, idempotent: true}
+	short := &idempotentTool{flakyTool: flakyTool{TestTool: TestTool{name: "openapi.users.getShort"}}, idempotent: true}
+	writer := &idempotentTool{flakyTool: flakyTool{TestTool: TestTool{name: "openapi.users.create"}}}
+	for _, tool := range []Tool{reader, short, writer} {
+		require.NoError(t, registry.Register(tool))
+	}
+
+	// Repeated invocations with the same parameters are served from cache
+	first, err := invoke(registry, "openapi.users.get", map[string]any{"id": "1"})
+	require.NoError(t, err)
+	second, err := invoke(registry, "openapi.users.get", map[string]any{"id": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, first.(map[string]any)["result"], second.(map[string]any)["result"])
+	assert.Equal(t, int32(1), reader.calls.Load())
+
+	_, err = invoke(registry, "openapi.users.get", map[string]any{"id": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), reader.calls.Load())
+
+	// Failures are not cached
+	reader.failing.Store(true)
+	_, err = invoke(registry, "openapi.users.get", map[string]any{"id": "3"})
+	assert.Error(t, err)
+	reader.failing.Store(false)
+	_, err = invoke(registry, "openapi.users.get", map[string]any{"id": "3"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), reader.calls.Load())
+
+	// Tools that do not declare idempotency are always invoked
+	for i := 0; i < 2; i++ {
+		_, err = invoke(registry, "openapi.users.create", map[string]any{"id": "1"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), writer.calls.Load())
+
+	// Results expire after the tool's TTL
+	_, err = invoke(registry, "openapi.users.getShort", map[string]any{})
+	require.NoError(t, err)
+	time.Sleep(60 * time.Millisecond)
+	_, err = invoke(registry, "openapi.users.getShort", map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), short.calls.Load())
+
+	stats := registry.ResultCacheStats()
+	require.NotNil(t, stats)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(6), stats.Misses)
+	assert.InDelta(t, 0.2, stats.Tools["openapi.users.get"].HitRatio, 0.001)
+	assert.NotContains(t, stats.Tools, "openapi.users.create")
+	require.NoError(t, cache.Close())
+
+	// Persisted results survive a restart
+	registry, cache = newRegistry(ResultCacheConfig{TTL: time.Minute, Path: path})
+	defer cache.Close()
+	restarted := &idempotentTool{flakyTool: flakyTool{TestTool: TestTool{name: "openapi.users.get"}}, idempotent: true}
+	require.NoError(t, registry.Register(restarted))
+	_, err = invoke(registry, "openapi.users.get", map[string]any{"id": "2"})
+	require.NoError(t, err)
+	assert.Zero(t, restarted.calls.Load())
+
+	// The in-memory LRU is bounded
+	lru, err := NewResultCache(ResultCacheConfig{TTL: time.Minute, MaxEntries: 2}, zap.NewNop())
+	require.NoError(t, err)
+	for _, key := range []string{"a", "b", "c"} {
+		lru.put(key, key, time.Minute)
+	}
+	assert.Equal(t, 2, lru.Stats().Entries)
+	_, found := lru.get("tool", "a")
+	assert.False(t, found)
+	_, found = lru.get("tool", "c")
+	assert.True(t, found)
+
+	// Disabling the cache removes its stats
+	registry.SetResultCache(nil)
+	assert.Nil(t, registry.ResultCacheStats())
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aionmcp/aionmcp/pkg/capabilities"
+	"github.com/aionmcp/aionmcp/pkg/messaging"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// serverFeatures describes the server-wide features the agent service
+// advertises alongside its own
+func serverFeatures(auth *authenticator, serving *serverTLS, resultCache *ResultCache, watchdog *Watchdog) map[string]capabilities.Feature {
+	features := map[string]capabilities.Feature{
+		"api_key_auth": {Enabled: auth != nil && auth.keys != nil},
+		"oidc_auth":    {Enabled: auth != nil && auth.oidc != nil},
+		"tls":          {Enabled: serving != nil},
+		"grpc": {
+			Enabled: true,
+			Options: map[string]string{"reflection": strconv.FormatBool(viper.GetBool("server.grpc.reflection"))},
+			Limits: map[string]int64{
+				"max_recv_msg_size":     viper.GetInt64("server.grpc.max_recv_msg_size"),
+				"keepalive_min_time_ms": viper.GetInt64("server.grpc.keepalive_min_time_ms"),
+			},
+		},
+		"mcp_jsonrpc": {
+			Enabled: true,
+			Version: mcpProtocolVersions[0],
+			Options: map[string]string{"protocol_versions": strings.Join(mcpProtocolVersions, ",")},
+		},
+		"result_cache": {Enabled: resultCache != nil},
+		"learning": {
+			Enabled: viper.GetBool("learning.enabled"),
+			Options: map[string]string{
+				"aggregation_only": strconv.FormatBool(viper.GetBool("learning.insights.aggregation_only")),
+				"usage_weighting":  strconv.FormatBool(viper.GetBool("learning.insights.usage_weighting")),
+			},
+		},
+		"invocation_log": {Enabled: viper.GetBool("invocation_log.enabled")},
+		"audit_log":      {Enabled: viper.GetBool("audit.enabled")},
+		"metrics":        {Enabled: viper.GetBool("metrics.enabled"), Options: map[string]string{"path": metricsPath}},
+		"workflows":      {Enabled: true},
+		"watchdog":       {Enabled: watchdog != nil},
+		"cors":           {Enabled: len(viper.GetStringSlice("server.cors.allowed_origins")) > 0},
+		"tool_aliases":   {Enabled: true},
+		"context_vars":   {Enabled: viper.GetBool("context_vars.enabled")},
+		"spec_polling": {
+			Enabled: true,
+			Limits: map[string]int64{
+				"default_interval_seconds": viper.GetInt64("importer.poll.default_interval_seconds"),
+				"check_interval_seconds":   viper.GetInt64("importer.poll.check_interval_seconds"),
+			},
+		},
+		"asyncapi_messaging": {
+			Enabled: true,
+			Options: map[string]string{"protocols": strings.Join([]string{
+				messaging.ProtocolAMQP, messaging.ProtocolKafka, messaging.ProtocolMQTT, messaging.ProtocolWebSocket,
+			}, ",")},
+			Limits: map[string]int64{"connect_timeout_seconds": viper.GetInt64("importer.asyncapi.connect_timeout_seconds")},
+		},
+	}
+	if auth != nil && auth.oidc != nil {
+		features["oidc_auth"] = capabilities.Feature{
+			Enabled: true,
+			Options: map[string]string{"issuer": viper.GetString("auth.oidc.issuer")},
+		}
+	}
+	if serving != nil {
+		features["tls"] = capabilities.Feature{
+			Enabled: true,
+			Options: map[string]string{
+				"mutual_tls":  strconv.FormatBool(serving.clientCAFile != ""),
+				"client_auth": serving.clientAuth.String(),
+			},
+		}
+	}
+	if resultCache != nil {
+		features["result_cache"] = capabilities.Feature{
+			Enabled: true,
+			Limits: map[string]int64{
+				"ttl_ms":      resultCache.config.TTL.Milliseconds(),
+				"max_entries": int64(resultCache.config.MaxEntries),
+			},
+		}
+	}
+	if watchdog != nil {
+		features["watchdog"] = capabilities.Feature{
+			Enabled: true,
+			Limits:  map[string]int64{"max_runtime_ms": watchdog.config.MaxRuntime.Milliseconds()},
+		}
+	}
+	return features
+}
+
+// setupCapabilityRoutes mounts feature discovery. It is public so clients
+// can learn how to authenticate before they hold credentials.
+func (s *Server) setupCapabilityRoutes(router *gin.Engine) {
+	router.GET("/api/v1/capabilities", func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.agentServer.Capabilities())
+	})
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"go.uber.org/zap"
+)
+
+// DefaultCircuitOpenTimeout is how long an open circuit rejects invocations before a probe
+const DefaultCircuitOpenTimeout = 30 * time.Second
+
+// CircuitBreakerConfig configures the breakers that stop invoking failing tools
+type CircuitBreakerConfig struct {
+	FailureThreshold int            // Consecutive failures that open a circuit; zero disables breakers
+	OpenTimeout      time.Duration  // How long a circuit stays open before a probe
+	Tools            map[string]int // Per-tool failure thresholds keyed by tool name
+}
+
+// ToolCircuitBreaker overrides the failure threshold for one tool
+type ToolCircuitBreaker struct {
+	Tool             string `mapstructure:"tool"`
+	FailureThreshold int    `mapstructure:"failure_threshold"`
+}
+
+// thresholdFor resolves the failure threshold of a tool
+func (c CircuitBreakerConfig) thresholdFor(name string) int {
+	if threshold, ok := c.Tools[name]; ok {
+		return threshold
+	}
+	return c.FailureThreshold
+}
+
+// circuitBreaker tracks the consecutive failures of one tool
+type circuitBreaker struct {
+	mu          sync.Mutex
+	tool        string
+	threshold   int
+	openTimeout time.Duration
+	logger      *zap.Logger
+	state       types.CircuitState
+	failures    int
+	openedAt    time.Time
+	probing     bool // A half-open probe is in flight
+}
+
+// allow admits an invocation, reporting whether it is the probe of a half-open circuit
+func (b *circuitBreaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case types.CircuitOpen:
+		if wait := time.Until(b.openedAt.Add(b.openTimeout)); wait > 0 {
+			return false, &types.CircuitOpenError{Tool: b.tool, RetryAfter: wait}
+		}
+		b.state = types.CircuitHalfOpen
+		fallthrough
+	case types.CircuitHalfOpen:
+		if b.probing {
+			return false, &types.CircuitOpenError{Tool: b.tool}
+		}
+		b.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// record updates the breaker with the outcome of an admitted invocation
+func (b *circuitBreaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+	if err != nil && !countsAsFailure(err) {
+		return
+	}
+
+	if err == nil {
+		if b.state != types.CircuitClosed {
+			b.logger.Info("Circuit breaker closed", zap.String("tool", b.tool))
+		}
+		b.state = types.CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if probe || (b.state == types.CircuitClosed && b.failures >= b.threshold) {
+		b.state = types.CircuitOpen
+		b.openedAt = time.Now()
+		b.logger.Warn("Circuit breaker opened",
+			zap.String("tool", b.tool),
+			zap.Int("consecutive_failures", b.failures),
+			zap.Duration("open_timeout", b.openTimeout),
+			zap.Error(err))
+	}
+}
+
+// status reports the current state of the breaker
+func (b *circuitBreaker) status() *types.CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := &types.CircuitStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		FailureThreshold:    b.threshold,
+	}
+	if b.state != types.CircuitClosed {
+		openedAt := b.openedAt
+		probeAt := openedAt.Add(b.openTimeout)
+		status.OpenedAt = &openedAt
+		status.ProbeAt = &probeAt
+	}
+	return status
+}
+
+// countsAsFailure reports whether an error says something about the tool's
+// health. Callers giving up, invalid parameters and local throttling do not.
+func countsAsFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var validationErr *schema.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Subject == "input" {
+		return false
+	}
+	if retryable, ok := types.AsRetryable(err); ok && retryable.StatusCode == 0 {
+		return false
+	}
+	return true
+}
+
+// circuitBreakers holds the breakers of all tools, created on first use
+type circuitBreakers struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	breakers map[string]*circuitBreaker
+	logger   *zap.Logger
+}
+
+// newCircuitBreakers creates an empty set of breakers, disabled until configured
+func newCircuitBreakers(logger *zap.Logger) *circuitBreakers {
+	return &circuitBreakers{
+		breakers: make(map[string]*circuitBreaker),
+		logger:   logger,
+	}
+}
+
+// configure replaces the configuration and resets all breakers
+func (c *circuitBreakers) configure(config CircuitBreakerConfig) {
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = DefaultCircuitOpenTimeout
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config = config
+	c.breakers = make(map[string]*circuitBreaker)
+}
+
+// forTool returns the breaker of a tool, or nil when the tool has none
+func (c *circuitBreakers) forTool(name string) *circuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if breaker, ok := c.breakers[name]; ok {
+		return breaker
+	}
+	threshold := c.config.thresholdFor(name)
+	if threshold <= 0 {
+		return nil
+	}
+	breaker := &circuitBreaker{
+		tool:        name,
+		threshold:   threshold,
+		openTimeout: c.config.OpenTimeout,
+		logger:      c.logger,
+		state:       types.CircuitClosed,
+	}
+	c.breakers[name] = breaker
+	return breaker
+}
+
+// remove forgets the breaker of a tool
+func (c *circuitBreakers) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.breakers, name)
+}
+
+// circuitTool fails fast while its circuit is open
+type circuitTool struct {
+	types.Tool
+	breaker *circuitBreaker
+}
+
+// Execute invokes the tool if the breaker admits it and records the outcome
+func (t *circuitTool) Execute(ctx context.Context, input any) (any, error) {
+	probe, err := t.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	result, err := t.Tool.Execute(ctx, input)
+	t.breaker.record(probe, err)
+	return result, err
+}
+
+// Metadata returns the tool metadata with the circuit state
+func (t *circuitTool) Metadata() types.ToolMetadata {
+	metadata := t.Tool.Metadata()
+	metadata.Circuit = t.breaker.status()
+	return metadata
+}
+
+// withCircuitBreaker guards a tool with its breaker, if any
+func withCircuitBreaker(tool Tool, breaker *circuitBreaker) Tool {
+	if breaker == nil {
+		return tool
+	}
+	return &circuitTool{Tool: tool, breaker: breaker}
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// flakyTool fails while its failing flag is set
+type flakyTool struct {
+	TestTool
+	failing atomic.Bool
+	calls   atomic.Int32
+}
+
+func (t *flakyTool) Execute(ctx context.Context, input any) (any, error) {
+	t.calls.Add(1)
+	if t.failing.Load() {
+		return nil, errors.New("upstream returned 500 Internal Server Error")
+	}
+	return t.TestTool.Execute(ctx, input)
+}
+
+func TestToolRegistry_CircuitBreakers(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	flaky := &flakyTool{TestTool: TestTool{name: "openapi.flaky.get"}}
+	require.NoError(t, registry.Register(flaky))
+	registry.SetCircuitBreakers(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond})
+
+	invoke := func() error {
+		tool, err := registry.Get("openapi.flaky.get")
+		require.NoError(t, err)
+		_, err = tool.Execute(context.Background(), map[string]any{})
+		return err
+	}
+	circuit := func() *types.CircuitStatus {
+		tool, err := registry.Get("openapi.flaky.get")
+		require.NoError(t, err)
+		return tool.Metadata().Circuit
+	}
+
+	// Consecutive failures open the circuit, which then fails fast
+	flaky.failing.Store(true)
+	assert.Error(t, invoke())
+	assert.Equal(t, types.CircuitClosed, circuit().State)
+	assert.Error(t, invoke())
+	assert.Equal(t, types.CircuitOpen, circuit().State)
+
+	err := invoke()
+	var openErr *types.CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, "openapi.flaky.get", openErr.Tool)
+	retryable, ok := types.AsRetryable(err)
+	require.True(t, ok)
+	assert.Positive(t, retryable.RetryAfter)
+	assert.Equal(t, int32(2), flaky.calls.Load())
+
+	// The circuit state is listed with the tool
+	for _, metadata := range registry.ListTools() {
+		if metadata.Name == "openapi.flaky.get" {
+			require.NotNil(t, metadata.Circuit)
+			assert.Equal(t, types.CircuitOpen, metadata.Circuit.State)
+			assert.NotNil(t, metadata.Circuit.ProbeAt)
+		}
+	}
+
+	// A failed probe reopens the circuit
+	time.Sleep(60 * time.Millisecond)
+	assert.Error(t, invoke())
+	assert.Equal(t, int32(3), flaky.calls.Load())
+	assert.Equal(t, types.CircuitOpen, circuit().State)
+
+	// A successful probe closes it
+	time.Sleep(60 * time.Millisecond)
+	flaky.failing.Store(false)
+	assert.NoError(t, invoke())
+	assert.Equal(t, types.CircuitClosed, circuit().State)
+	assert.Zero(t, circuit().ConsecutiveFailures)
+
+	// Cancelled calls and local throttling say nothing about the tool's health
+	assert.True(t, countsAsFailure(errors.New("boom")))
+	assert.False(t, countsAsFailure(fmt.Errorf("call: %w", context.Canceled)))
+	assert.False(t, countsAsFailure(&types.RetryableError{RetryAfter: time.Second, Reason: "quota exhausted"}))
+
+	// A zero per-tool threshold disables the breaker of that tool
+	flaky.failing.Store(true)
+	registry.SetCircuitBreakers(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		Tools:            map[string]int{"openapi.flaky.get": 0},
+	})
+	assert.Error(t, invoke())
+	assert.Error(t, invoke())
+	assert.Nil(t, circuit())
+	assert.Equal(t, int32(6), flaky.calls.Load())
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// DifferenceKind describes how a value differs between two results
+type DifferenceKind string
+
+const (
+	DifferenceAdded   DifferenceKind = "added"   // Present only in the right result
+	DifferenceRemoved DifferenceKind = "removed" // Present only in the left result
+	DifferenceChanged DifferenceKind = "changed"
+)
+
+// ValueDifference is a single entry of a structured JSON diff
+type ValueDifference struct {
+	Path  string         `json:"path"` // JSON pointer (RFC 6901) to the differing value
+	Kind  DifferenceKind `json:"kind"`
+	Left  any            `json:"left,omitempty"`
+	Right any            `json:"right,omitempty"`
+}
+
+// ToolComparisonRequest is the body of the tool compare endpoint
+type ToolComparisonRequest struct {
+	LeftTool   string         `json:"left_tool" binding:"required"`
+	RightTool  string         `json:"right_tool" binding:"required"`
+	Parameters map[string]any `json:"parameters"`
+	DryRun     bool           `json:"dry_run"`
+}
+
+// ToolComparisonSide holds the outcome of invoking one side of a comparison
+type ToolComparisonSide struct {
+	Tool       string `json:"tool"`
+	Source     string `json:"source"`
+	Result     any    `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// ToolComparison is the response of the tool compare endpoint
+type ToolComparison struct {
+	Left        ToolComparisonSide `json:"left"`
+	Right       ToolComparisonSide `json:"right"`
+	DryRun      bool               `json:"dry_run"`
+	Identical   bool               `json:"identical"`
+	Differences []ValueDifference  `json:"differences"`
+}
+
+// compareTools invokes both tools with the same parameters concurrently and
+// diffs their results. In dry-run mode the tools are resolved but not executed.
+func compareTools(ctx context.Context, left, right types.Tool, request ToolComparisonRequest) ToolComparison {
+	comparison := ToolComparison{
+		Left:        ToolComparisonSide{Tool: left.Name(), Source: toolSourceType(left)},
+		Right:       ToolComparisonSide{Tool: right.Name(), Source: toolSourceType(right)},
+		DryRun:      request.DryRun,
+		Differences: []ValueDifference{},
+	}
+	if request.DryRun {
+		return comparison
+	}
+
+	var wg sync.WaitGroup
+	for _, side := range []struct {
+		tool types.Tool
+		out  *ToolComparisonSide
+	}{{left, &comparison.Left}, {right, &comparison.Right}} {
+		wg.Add(1)
+		go func(tool types.Tool, out *ToolComparisonSide) {
+			defer wg.Done()
+			start := time.Now()
+			result, err := tool.Execute(ctx, request.Parameters)
+			out.DurationMs = time.Since(start).Milliseconds()
+			if err != nil {
+				out.Error = err.Error()
+				return
+			}
+			out.Result = normalizeJSON(result)
+		}(side.tool, side.out)
+	}
+	wg.Wait()
+
+	comparison.Differences = diffJSON("", comparison.Left.Result, comparison.Right.Result, comparison.Differences)
+	comparison.Identical = len(comparison.Differences) == 0 && comparison.Left.Error == comparison.Right.Error
+
+	return comparison
+}
+
+// toolSourceType returns the tool source, defaulting to builtin
+func toolSourceType(tool types.Tool) string {
+	if source := tool.Metadata().Source; source != "" {
+		return source
+	}
+	return "builtin"
+}
+
+// normalizeJSON round-trips a value through JSON so results from different
+// tools compare by their wire representation rather than their Go types
+func normalizeJSON(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return string(data)
+	}
+	return normalized
+}
+
+// diffJSON appends the differences between two normalized JSON values
+func diffJSON(path string, left, right any, diffs []ValueDifference) []ValueDifference {
+	switch l := left.(type) {
+	case map[string]any:
+		r, ok := right.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make(map[string]struct{}, len(l)+len(r))
+		for key := range l {
+			keys[key] = struct{}{}
+		}
+		for key := range r {
+			keys[key] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			childPath := path + "/" + pointerEscaper.Replace(key)
+			lv, inLeft := l[key]
+			rv, inRight := r[key]
+			switch {
+			case !inLeft:
+				diffs = append(diffs, ValueDifference{Path: childPath, Kind: DifferenceAdded, Right: rv})
+			case !inRight:
+				diffs = append(diffs, ValueDifference{Path: childPath, Kind: DifferenceRemoved, Left: lv})
+			default:
+				diffs = diffJSON(childPath, lv, rv, diffs)
+			}
+		}
+		return diffs
+
+	case []any:
+		r, ok := right.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(l), len(r)); i++ {
+			childPath := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(l):
+				diffs = append(diffs, ValueDifference{Path: childPath, Kind: DifferenceAdded, Right: r[i]})
+			case i >= len(r):
+				diffs = append(diffs, ValueDifference{Path: childPath, Kind: DifferenceRemoved, Left: l[i]})
+			default:
+				diffs = diffJSON(childPath, l[i], r[i], diffs)
+			}
+		}
+		return diffs
+	}
+
+	if !reflect.DeepEqual(left, right) {
+		diffs = append(diffs, ValueDifference{Path: path, Kind: DifferenceChanged, Left: left, Right: right})
+	}
+	return diffs
+}
+
+// pointerEscaper escapes keys for use in a JSON pointer
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareTools(t *testing.T) {
+	left := &TestTool{name: "pets-v1", source: "openapi"}
+	right := &TestTool{name: "pets-v2", source: "openapi"}
+	request := ToolComparisonRequest{
+		LeftTool:   left.name,
+		RightTool:  right.name,
+		Parameters: map[string]any{"id": 7},
+	}
+
+	comparison := compareTools(context.Background(), left, right, request)
+	assert.False(t, comparison.Identical)
+	assert.Equal(t, []ValueDifference{
+		{Path: "/tool", Kind: DifferenceChanged, Left: "pets-v1", Right: "pets-v2"},
+	}, comparison.Differences)
+
+	// Dry-run resolves both tools without executing them
+	request.DryRun = true
+	comparison = compareTools(context.Background(), left, right, request)
+	assert.True(t, comparison.DryRun)
+	assert.Nil(t, comparison.Left.Result)
+	assert.Empty(t, comparison.Differences)
+
+	diffs := diffJSON("", map[string]any{"a/b": []any{1.0, 2.0}}, map[string]any{"a/b": []any{1.0}, "c": true}, nil)
+	assert.Equal(t, []ValueDifference{
+		{Path: "/a~1b/1", Kind: DifferenceRemoved, Left: 2.0},
+		{Path: "/c", Kind: DifferenceAdded, Right: true},
+	}, diffs)
+}
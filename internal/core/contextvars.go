@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aionmcp/aionmcp/pkg/contextvars"
+	"github.com/aionmcp/aionmcp/pkg/readonly"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// loadContextVars builds the context variable store from the variables
+// configured under context_vars.variables
+func loadContextVars(configured []contextvars.Variable) (*contextvars.Store, error) {
+	store := contextvars.NewStore()
+	for _, variable := range configured {
+		if err := store.Set(variable); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// templatedTool resolves context variable templates in its parameters
+type templatedTool struct {
+	types.Tool
+	vars *contextvars.Store
+}
+
+// Execute renders the parameter templates with the variables of the
+// invocation's workspace before running the tool
+func (t *templatedTool) Execute(ctx context.Context, input any) (any, error) {
+	resolved, err := t.vars.Resolve(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("tool %s: %w", t.Name(), err)
+	}
+	return types.Execute(ctx, t.Tool, resolved)
+}
+
+// withContextVars wraps a tool so its parameter templates are resolved
+func withContextVars(tool Tool, vars *contextvars.Store) Tool {
+	if vars == nil {
+		return tool
+	}
+	return &templatedTool{Tool: tool, vars: vars}
+}
+
+// SetContextVars resolves parameter templates such as {{ .env.TENANT_ID }}
+// in invocations of tools returned by Get. A nil store disables templating.
+func (r *ToolRegistry) SetContextVars(vars *contextvars.Store) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contextVars = vars
+}
+
+// contextVarsWorkspace makes the request's workspace available to tool
+// invocations, so they resolve that workspace's context variables
+func contextVarsWorkspace(c *gin.Context) {
+	if workspace := c.GetHeader(readonly.WorkspaceHeader); workspace != "" {
+		c.Request = c.Request.WithContext(contextvars.WithWorkspace(c.Request.Context(), workspace))
+	}
+	c.Next()
+}
+
+// invocationEnvironment lets REST and JSON-RPC callers select the
+// environment of the tool's source with the X-AionMCP-Environment header
+func invocationEnvironment(c *gin.Context) {
+	if environment := c.GetHeader(types.EnvironmentHeader); environment != "" {
+		c.Request = c.Request.WithContext(types.WithInvocationContext(c.Request.Context(), map[string]string{types.ContextEnvironment: environment}))
+	}
+	c.Next()
+}
+
+// setupContextVarRoutes mounts context variable administration, which
+// requires the admin scope. Secret values are never returned.
+func (s *Server) setupContextVarRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin/context-vars")
+
+	admin.GET("", func(c *gin.Context) {
+		workspace := c.Query("workspace")
+		c.JSON(http.StatusOK, gin.H{"workspace": workspace, "variables": s.contextVars.List(workspace)})
+	})
+
+	admin.PUT("/:name", func(c *gin.Context) {
+		var req struct {
+			Value     string `json:"value"`
+			Secret    bool   `json:"secret"`
+			Workspace string `json:"workspace"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		variable := contextvars.Variable{Name: c.Param("name"), Value: req.Value, Secret: req.Secret, Workspace: req.Workspace}
+		c.Set(auditTargetKey, variable.Name)
+		if err := s.contextVars.Set(variable); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		s.logger.Info("Context variable set",
+			zap.String("name", variable.Name),
+			zap.String("workspace", variable.Workspace),
+			zap.Bool("secret", variable.Secret))
+
+		if variable.Secret {
+			variable.Value = contextvars.MaskedValue
+		}
+		c.JSON(http.StatusOK, variable)
+	})
+
+	admin.DELETE("/:name", func(c *gin.Context) {
+		name := c.Param("name")
+		workspace := c.Query("workspace")
+		c.Set(auditTargetKey, name)
+		if !s.contextVars.Delete(workspace, name) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("context variable %s not found", name)})
+			return
+		}
+		s.logger.Info("Context variable deleted",
+			zap.String("name", name),
+			zap.String("workspace", workspace))
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/contextvars"
+	"github.com/aionmcp/aionmcp/pkg/readonly"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerContextVars(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("context_vars.enabled", true)
+	viper.Set("context_vars.variables", []map[string]any{
+		{"name": "TENANT_ID", "value": "acme"},
+		{"name": "TENANT_ID", "value": "globex", "workspace": "team-b"},
+		{"name": "API_TOKEN", "value": "s3cret", "secret": true},
+	})
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	handler := server.Handler()
+
+	serve := func(method, path, workspace, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		if workspace != "" {
+			request.Header.Set(readonly.WorkspaceHeader, workspace)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	invoke := func(workspace, body string) (int, map[string]any) {
+		response := serve(http.MethodPost, "/api/v1/mcp/tools/echo/invoke", workspace, body)
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &decoded))
+		return response.Code, decoded
+	}
+
+	// Templates resolve server-side, nested ones included, with workspace overrides
+	code, result := invoke("", `{"tenant": "{{ .env.TENANT_ID }}", "auth": {"token": "Bearer {{ .env.API_TOKEN }}"}, "ids": ["{{ .env.TENANT_ID }}-1"]}`)
+	require.Equal(t, http.StatusOK, code)
+	echoed := result["result"].(map[string]any)["echo"].(map[string]any)
+	assert.Equal(t, "acme", echoed["tenant"])
+	assert.Equal(t, "Bearer s3cret", echoed["auth"].(map[string]any)["token"])
+	assert.Equal(t, []any{"acme-1"}, echoed["ids"])
+
+	code, result = invoke("team-b", `{"tenant": "{{ .env.TENANT_ID }}", "scope": "{{ .workspace }}"}`)
+	require.Equal(t, http.StatusOK, code)
+	echoed = result["result"].(map[string]any)["echo"].(map[string]any)
+	assert.Equal(t, "globex", echoed["tenant"])
+	assert.Equal(t, "team-b", echoed["scope"])
+
+	// Undefined variables fail the invocation instead of reaching the tool
+	code, result = invoke("", `{"region": "{{ .env.REGION }}"}`)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Contains(t, fmt.Sprint(result["error"]), "REGION")
+
+	// Administration masks secrets and scopes variables to workspaces
+	response := serve(http.MethodPut, "/api/v1/admin/context-vars/REGION", "", `{"value": "eu-west-1", "workspace": "team-b"}`)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/api/v1/admin/context-vars/not-valid", "", `{"value": "x"}`).Code)
+
+	var listing struct {
+		Variables []contextvars.Variable `json:"variables"`
+	}
+	response = serve(http.MethodGet, "/api/v1/admin/context-vars?workspace=team-b", "", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &listing))
+	require.Len(t, listing.Variables, 3)
+	assert.Equal(t, "API_TOKEN", listing.Variables[0].Name)
+	assert.Equal(t, contextvars.MaskedValue, listing.Variables[0].Value)
+	assert.Equal(t, "eu-west-1", listing.Variables[1].Value)
+	assert.Equal(t, "globex", listing.Variables[2].Value)
+
+	code, result = invoke("team-b", `{"region": "{{ .env.REGION }}"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "eu-west-1", result["result"].(map[string]any)["echo"].(map[string]any)["region"])
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/api/v1/admin/context-vars/REGION?workspace=team-b", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/api/v1/admin/context-vars/REGION?workspace=team-b", "", "").Code)
+}
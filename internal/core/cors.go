@@ -0,0 +1,168 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// CORSConfig lets browser-based dashboards and agent UIs on other origins
+// call the REST API
+type CORSConfig struct {
+	AllowedOrigins   []string      // Exact origins such as "https://dash.example.com", or "*"; empty disables CORS
+	AllowedMethods   []string      // Methods allowed in cross-origin requests
+	AllowedHeaders   []string      // Request headers browsers may send
+	ExposedHeaders   []string      // Response headers scripts may read
+	AllowCredentials bool          // Let browsers send cookies and credentials
+	MaxAge           time.Duration // How long browsers may cache a preflight response
+}
+
+// SecurityHeadersConfig sets the standard security headers on every response
+type SecurityHeadersConfig struct {
+	Enabled               bool
+	ContentSecurityPolicy string
+	FrameOptions          string
+	ReferrerPolicy        string
+	HSTSMaxAge            time.Duration // Sent only over TLS; zero omits Strict-Transport-Security
+}
+
+// loadCORSConfig reads the CORS settings under server.cors
+func loadCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   viper.GetStringSlice("server.cors.allowed_origins"),
+		AllowedMethods:   viper.GetStringSlice("server.cors.allowed_methods"),
+		AllowedHeaders:   viper.GetStringSlice("server.cors.allowed_headers"),
+		ExposedHeaders:   viper.GetStringSlice("server.cors.exposed_headers"),
+		AllowCredentials: viper.GetBool("server.cors.allow_credentials"),
+		MaxAge:           time.Duration(viper.GetInt("server.cors.max_age_seconds")) * time.Second,
+	}
+}
+
+// loadSecurityHeadersConfig reads the security header settings under server.security_headers
+func loadSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		Enabled:               viper.GetBool("server.security_headers.enabled"),
+		ContentSecurityPolicy: viper.GetString("server.security_headers.content_security_policy"),
+		FrameOptions:          viper.GetString("server.security_headers.frame_options"),
+		ReferrerPolicy:        viper.GetString("server.security_headers.referrer_policy"),
+		HSTSMaxAge:            time.Duration(viper.GetInt("server.security_headers.hsts_max_age_seconds")) * time.Second,
+	}
+}
+
+// defaultCORSMethods are allowed when no methods are configured
+var defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// defaultCORSHeaders are allowed when no request headers are configured
+var defaultCORSHeaders = []string{"Content-Type", "Authorization", apikey.Header, "Last-Event-ID"}
+
+// defaultExposedHeaders are exposed when none are configured
+var defaultExposedHeaders = []string{"Retry-After", "WWW-Authenticate", "MCP-Protocol-Version", "Content-Disposition"}
+
+// allowsOrigin reports whether the configuration accepts an origin
+func (c CORSConfig) allowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsMiddleware answers preflight requests and marks responses readable by
+// the allowed origins. Requests from other origins are served without CORS
+// headers, so browsers keep their responses from scripts.
+func corsMiddleware(config CORSConfig) gin.HandlerFunc {
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	exposed := config.ExposedHeaders
+	if len(exposed) == 0 {
+		exposed = defaultExposedHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(exposed, ", ")
+	wildcard := false
+	for _, allowed := range config.AllowedOrigins {
+		wildcard = wildcard || allowed == "*"
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if !config.allowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Credentialed requests need the origin echoed rather than a wildcard
+		if wildcard && !config.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		c.Header("Access-Control-Allow-Headers", allowHeaders)
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// securityHeaders sets the standard security headers on every response.
+// Strict-Transport-Security is only sent when the server serves TLS.
+func securityHeaders(config SecurityHeadersConfig, tls bool) gin.HandlerFunc {
+	hsts := ""
+	if tls && config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(config.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if config.FrameOptions != "" {
+			header.Set("X-Frame-Options", config.FrameOptions)
+		}
+		if config.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", config.ReferrerPolicy)
+		}
+		if config.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", config.ContentSecurityPolicy)
+		}
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerCORS(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("server.cors.allowed_origins", []string{"https://dash.example.com"})
+	viper.Set("server.cors.allow_credentials", true)
+	viper.Set("server.cors.max_age_seconds", 600)
+	viper.Set("server.security_headers.enabled", true)
+	viper.Set("server.security_headers.frame_options", "DENY")
+	viper.Set("server.security_headers.hsts_max_age_seconds", 31536000)
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	handler := server.Handler()
+
+	serve := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/api/v1/health", nil)
+		if origin != "" {
+			request.Header.Set("Origin", origin)
+		}
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Preflights from allowed origins are answered without reaching the routes
+	preflight := serve(http.MethodOptions, "https://dash.example.com", map[string]string{
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "X-API-Key",
+	})
+	assert.Equal(t, http.StatusNoContent, preflight.Code)
+	assert.Equal(t, "https://dash.example.com", preflight.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", preflight.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, preflight.Header().Get("Access-Control-Allow-Methods"), "POST")
+	assert.Contains(t, preflight.Header().Get("Access-Control-Allow-Headers"), apikey.Header)
+	assert.Equal(t, "600", preflight.Header().Get("Access-Control-Max-Age"))
+
+	response := serve(http.MethodGet, "https://dash.example.com", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "https://dash.example.com", response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, response.Header().Get("Access-Control-Expose-Headers"), "Retry-After")
+	assert.Contains(t, response.Header().Values("Vary"), "Origin")
+
+	// Other origins get no CORS headers, and their preflights are refused
+	response = serve(http.MethodGet, "https://evil.example.com", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, response.Header().Get("Access-Control-Allow-Origin"))
+	preflight = serve(http.MethodOptions, "https://evil.example.com", map[string]string{"Access-Control-Request-Method": "POST"})
+	assert.Equal(t, http.StatusForbidden, preflight.Code)
+
+	// Security headers are set on every response; HSTS only over TLS
+	response = serve(http.MethodGet, "", nil)
+	assert.Equal(t, "nosniff", response.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", response.Header().Get("X-Frame-Options"))
+	assert.Empty(t, response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, response.Header().Get("Strict-Transport-Security"))
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimedebug "runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// debugPathPrefix is the prefix of every route on the debug listener
+const debugPathPrefix = "/debug/"
+
+// DebugState is a snapshot of the server's internal state
+type DebugState struct {
+	Timestamp          time.Time          `json:"timestamp"`
+	Uptime             string             `json:"uptime"`
+	Goroutines         int                `json:"goroutines"`
+	Tools              int                `json:"tools"`
+	Agent              agent.RuntimeState `json:"agent"`
+	SessionsBy         map[string]int     `json:"sessions_by_status"`
+	EventSubscribers   int                `json:"event_subscribers"` // Dashboards streaming /api/v1/events
+	Handlers           HandlerState       `json:"registry_handlers"`
+	RunningInvocations int                `json:"running_invocations,omitempty"` // Tracked by the watchdog
+}
+
+// HandlerState reports the registry's event handler semaphore
+type HandlerState struct {
+	Running  int `json:"running"`
+	Capacity int `json:"capacity"`
+}
+
+// GCState reports memory and garbage collector statistics
+type GCState struct {
+	NumGC         uint32    `json:"num_gc"`
+	LastGC        time.Time `json:"last_gc"`
+	PauseTotal    string    `json:"pause_total"`
+	RecentPauses  []string  `json:"recent_pauses"` // Newest first
+	HeapAlloc     uint64    `json:"heap_alloc_bytes"`
+	HeapInuse     uint64    `json:"heap_inuse_bytes"`
+	HeapObjects   uint64    `json:"heap_objects"`
+	Sys           uint64    `json:"sys_bytes"`
+	NextGC        uint64    `json:"next_gc_bytes"`
+	GCCPUFraction float64   `json:"gc_cpu_fraction"`
+	MemoryLimit   int64     `json:"memory_limit_bytes"`
+}
+
+// newDebugServer builds the optional debug listener serving pprof profiles,
+// goroutine dumps, GC statistics and a snapshot of internal state. It
+// requires the admin scope whenever authentication is enabled.
+func (s *Server) newDebugServer(auth *authenticator) *http.Server {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	if auth != nil {
+		router.Use(authMiddleware(auth, s.logger))
+	}
+	started := time.Now()
+
+	// net/http/pprof serves named profiles from its index handler
+	router.GET("/debug/pprof/*profile", func(c *gin.Context) {
+		switch strings.TrimPrefix(c.Param("profile"), "/") {
+		case "cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Index(c.Writer, c.Request)
+		}
+	})
+	router.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+
+	// Full stack traces of every goroutine
+	router.GET("/debug/goroutines", func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		buffer := make([]byte, 1<<20)
+		for {
+			n := runtime.Stack(buffer, true)
+			if n < len(buffer) {
+				c.Writer.Write(buffer[:n])
+				return
+			}
+			buffer = make([]byte, 2*len(buffer))
+		}
+	})
+
+	router.GET("/debug/gc", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gcState())
+	})
+
+	router.GET("/debug/state", func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.debugState(started))
+	})
+
+	address := viper.GetString("debug.address")
+	if address == "" {
+		address = "127.0.0.1:6060"
+	}
+	return &http.Server{Addr: address, Handler: router}
+}
+
+// debugState snapshots the server's internal state
+func (s *Server) debugState(started time.Time) DebugState {
+	running, capacity := s.toolRegistry.HandlerUsage()
+	state := DebugState{
+		Timestamp:        time.Now().UTC(),
+		Uptime:           time.Since(started).Round(time.Second).String(),
+		Goroutines:       runtime.NumGoroutine(),
+		Tools:            s.toolRegistry.Count(),
+		Agent:            s.agentServer.RuntimeState(),
+		SessionsBy:       s.agentServer.SessionCounts(),
+		EventSubscribers: s.events.count(),
+		Handlers:         HandlerState{Running: running, Capacity: capacity},
+	}
+	if s.watchdog != nil {
+		state.RunningInvocations = len(s.watchdog.Status().Running)
+	}
+	return state
+}
+
+// gcState reads the current memory and garbage collector statistics
+func gcState() GCState {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	var gc runtimedebug.GCStats
+	runtimedebug.ReadGCStats(&gc)
+
+	state := GCState{
+		NumGC:         memory.NumGC,
+		LastGC:        gc.LastGC,
+		PauseTotal:    gc.PauseTotal.String(),
+		RecentPauses:  []string{},
+		HeapAlloc:     memory.HeapAlloc,
+		HeapInuse:     memory.HeapInuse,
+		HeapObjects:   memory.HeapObjects,
+		Sys:           memory.Sys,
+		NextGC:        memory.NextGC,
+		GCCPUFraction: memory.GCCPUFraction,
+		MemoryLimit:   runtimedebug.SetMemoryLimit(-1), // A negative limit only reads the current one
+	}
+	for i := 0; i < len(gc.Pause) && i < 10; i++ {
+		state.RecentPauses = append(state.RecentPauses, gc.Pause[i].String())
+	}
+	return state
+}
+
+// serveDebug runs the debug listener until it is shut down
+func (s *Server) serveDebug() {
+	s.logger.Warn("Debug listener enabled; keep it off public networks",
+		zap.String("address", s.debugServer.Addr))
+	if err := s.debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		s.logger.Error("Debug listener failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerDebugListener(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("auth.api_keys.enabled", true)
+	viper.Set("auth.api_keys.bootstrap_key", "debug-admin-key")
+	viper.Set("debug.enabled", true)
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	require.NotNil(t, server.debugServer)
+	debugServer := httptest.NewServer(server.debugServer.Handler)
+	defer debugServer.Close()
+
+	get := func(path, key string) (int, string) {
+		request, err := http.NewRequest("GET", debugServer.URL+path, nil)
+		require.NoError(t, err)
+		if key != "" {
+			request.Header.Set(apikey.Header, key)
+		}
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, string(data)
+	}
+
+	// Every debug route requires the admin scope
+	for _, path := range []string{"/debug/pprof/", "/debug/goroutines", "/debug/gc", "/debug/state"} {
+		code, _ := get(path, "")
+		assert.Equal(t, http.StatusUnauthorized, code, path)
+	}
+
+	_, err = server.agentServer.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "planner"})
+	require.NoError(t, err)
+	code, body := get("/debug/state", "debug-admin-key")
+	require.Equal(t, http.StatusOK, code, body)
+	var state DebugState
+	require.NoError(t, json.Unmarshal([]byte(body), &state))
+	assert.Equal(t, 1, state.Agent.Sessions)
+	assert.Equal(t, 1, state.SessionsBy["active"])
+	assert.Equal(t, DefaultMaxConcurrentHandlers, state.Handlers.Capacity)
+	assert.Positive(t, state.Goroutines)
+	assert.Positive(t, state.Tools)
+
+	code, body = get("/debug/pprof/", "debug-admin-key")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "goroutine")
+	code, body = get("/debug/pprof/heap?debug=1", "debug-admin-key")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "heap profile")
+	code, body = get("/debug/goroutines", "debug-admin-key")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "goroutine ")
+
+	code, body = get("/debug/gc", "debug-admin-key")
+	require.Equal(t, http.StatusOK, code)
+	var gc GCState
+	require.NoError(t, json.Unmarshal([]byte(body), &gc))
+	assert.Positive(t, gc.HeapAlloc)
+
+	// The debug routes are not served on the main listener
+	mainServer := httptest.NewServer(server.Handler())
+	defer mainServer.Close()
+	response, err := http.Get(mainServer.URL + "/debug/state")
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// DeprecationOverlay declares or refines the deprecation of a tool through
+// configuration, for specs that cannot be changed or lack the detail
+type DeprecationOverlay struct {
+	Tool        string `mapstructure:"tool"`
+	Since       string `mapstructure:"since"`  // RFC 3339 or YYYY-MM-DD
+	Sunset      string `mapstructure:"sunset"` // RFC 3339 or YYYY-MM-DD
+	Replacement string `mapstructure:"replacement"`
+	Reason      string `mapstructure:"reason"`
+}
+
+// parseDeprecationOverlays converts configured overlays into deprecations keyed by tool name
+func parseDeprecationOverlays(overlays []DeprecationOverlay) (map[string]*types.Deprecation, error) {
+	deprecations := make(map[string]*types.Deprecation, len(overlays))
+	for _, overlay := range overlays {
+		if overlay.Tool == "" {
+			return nil, fmt.Errorf("deprecation overlay without a tool name")
+		}
+		deprecation := &types.Deprecation{Replacement: overlay.Replacement, Reason: overlay.Reason}
+		var err error
+		if overlay.Since != "" {
+			if deprecation.Since, err = types.ParseDeprecationDate(overlay.Since); err != nil {
+				return nil, fmt.Errorf("deprecation of %s: %w", overlay.Tool, err)
+			}
+		}
+		if overlay.Sunset != "" {
+			if deprecation.Sunset, err = types.ParseDeprecationDate(overlay.Sunset); err != nil {
+				return nil, fmt.Errorf("deprecation of %s: %w", overlay.Tool, err)
+			}
+		}
+		deprecations[overlay.Tool] = deprecation
+	}
+	return deprecations, nil
+}
+
+// deprecatedTool reports the overlaid deprecation in a tool's metadata
+type deprecatedTool struct {
+	types.Tool
+	deprecation *types.Deprecation
+}
+
+// Metadata returns the tool metadata with the merged deprecation
+func (t *deprecatedTool) Metadata() types.ToolMetadata {
+	metadata := t.Tool.Metadata()
+	metadata.Deprecation = metadata.Deprecation.Merge(t.deprecation)
+	return metadata
+}
+
+// withDeprecation applies a configured deprecation to a tool, if any
+func withDeprecation(tool Tool, deprecation *types.Deprecation) Tool {
+	if deprecation == nil {
+		return tool
+	}
+	return &deprecatedTool{Tool: tool, deprecation: deprecation}
+}
+
+// setDeprecationHeaders announces a deprecated tool on a REST response
+func setDeprecationHeaders(header http.Header, metadata ToolMetadata) {
+	if metadata.Deprecation == nil {
+		return
+	}
+	successor := ""
+	if metadata.Deprecation.Replacement != "" {
+		successor = "/api/v1/mcp/tools/" + metadata.Deprecation.Replacement
+	}
+	for name, values := range metadata.Deprecation.Headers(successor) {
+		header[name] = values
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestToolRegistry_Deprecations(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	require.NoError(t, registry.Register(&TestTool{name: "openapi.petstore.listPets", source: "openapi"}))
+	require.NoError(t, registry.Register(&TestTool{name: "openapi.petstore.searchPets", source: "openapi"}))
+
+	_, err := parseDeprecationOverlays([]DeprecationOverlay{{Tool: "openapi.petstore.listPets", Sunset: "next week"}})
+	assert.Error(t, err)
+
+	deprecations, err := parseDeprecationOverlays([]DeprecationOverlay{{
+		Tool:        "openapi.petstore.listPets",
+		Sunset:      "2025-06-30",
+		Replacement: "openapi.petstore.searchPets",
+	}})
+	require.NoError(t, err)
+	registry.SetDeprecations(deprecations)
+
+	// The overlay shows up in tool metadata and listings
+	tool, err := registry.Get("openapi.petstore.listPets")
+	require.NoError(t, err)
+	deprecation := tool.Metadata().Deprecation
+	require.NotNil(t, deprecation)
+	assert.Equal(t, "openapi.petstore.searchPets", deprecation.Replacement)
+	for _, metadata := range registry.ListTools() {
+		assert.Equal(t, metadata.Name == "openapi.petstore.listPets", metadata.Deprecation != nil, metadata.Name)
+	}
+
+	header := http.Header{}
+	setDeprecationHeaders(header, tool.Metadata())
+	assert.Equal(t, "true", header.Get("Deprecation"))
+	assert.Equal(t, "Mon, 30 Jun 2025 00:00:00 GMT", header.Get("Sunset"))
+	assert.Equal(t, `</api/v1/mcp/tools/openapi.petstore.searchPets>; rel="successor-version"`, header.Get("Link"))
+
+	// Tools that are not deprecated get no headers
+	replacement, err := registry.Get("openapi.petstore.searchPets")
+	require.NoError(t, err)
+	header = http.Header{}
+	setDeprecationHeaders(header, replacement.Metadata())
+	assert.Empty(t, header)
+}
@@ -0,0 +1,267 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// EventInsightGenerated is streamed for each insight produced by the learning engine
+	EventInsightGenerated = "insight_generated"
+
+	// EventSpecReloaded is streamed when polling finds a changed remote spec and reloads it
+	EventSpecReloaded = "spec_reloaded"
+
+	// eventBufferSize bounds the events queued for one subscriber. A subscriber
+	// that falls further behind misses events rather than slowing publishers.
+	eventBufferSize = 256
+
+	// eventKeepAliveInterval is how often an idle stream sends a comment so
+	// proxies do not close the connection
+	eventKeepAliveInterval = 15 * time.Second
+)
+
+// streamEventTypes lists the event types accepted in subscription filters
+var streamEventTypes = map[string]bool{
+	string(ToolEventAdded):   true,
+	string(ToolEventRemoved): true,
+	string(ToolEventUpdated): true,
+	EventInsightGenerated:    true,
+	EventSpecReloaded:        true,
+}
+
+// StreamEvent is a tool registry or learning event delivered to subscribers
+type StreamEvent struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	Source    string      `json:"source,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// EventFilter selects the events a subscriber receives. Empty sets match everything.
+type EventFilter struct {
+	Types   map[string]bool
+	Sources map[string]bool
+}
+
+// Matches reports whether an event passes the filter
+func (f EventFilter) Matches(event StreamEvent) bool {
+	if len(f.Types) > 0 && !f.Types[event.Type] {
+		return false
+	}
+	if len(f.Sources) > 0 && !f.Sources[event.Source] {
+		return false
+	}
+	return true
+}
+
+// eventSubscriber is one open event stream
+type eventSubscriber struct {
+	filter  EventFilter
+	events  chan StreamEvent
+	dropped int
+}
+
+// eventHub fans tool registry and learning events out to stream subscribers
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[int]*eventSubscriber
+	nextID      int
+	sequence    uint64
+	logger      *zap.Logger
+}
+
+// newEventHub creates an event hub with no subscribers
+func newEventHub(logger *zap.Logger) *eventHub {
+	return &eventHub{
+		subscribers: make(map[int]*eventSubscriber),
+		nextID:      1,
+		logger:      logger,
+	}
+}
+
+// subscribe registers a subscriber and returns its ID and event channel
+func (h *eventHub) subscribe(filter EventFilter) (int, <-chan StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	subscriber := &eventSubscriber{
+		filter: filter,
+		events: make(chan StreamEvent, eventBufferSize),
+	}
+	h.subscribers[id] = subscriber
+	return id, subscriber.events
+}
+
+// count returns the number of subscribers
+func (h *eventHub) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// unsubscribe removes a subscriber and closes its channel
+func (h *eventHub) unsubscribe(id int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscriber, exists := h.subscribers[id]
+	if !exists {
+		return
+	}
+	delete(h.subscribers, id)
+	close(subscriber.events)
+
+	if subscriber.dropped > 0 {
+		h.logger.Warn("Event subscriber missed events",
+			zap.Int("subscriber_id", id),
+			zap.Int("dropped", subscriber.dropped))
+	}
+}
+
+// publish assigns the event an ID and delivers it to matching subscribers
+// without blocking
+func (h *eventHub) publish(eventType, source string, timestamp time.Time, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sequence++
+	event := StreamEvent{
+		ID:        h.sequence,
+		Type:      eventType,
+		Source:    source,
+		Timestamp: timestamp,
+		Data:      data,
+	}
+
+	for _, subscriber := range h.subscribers {
+		if !subscriber.filter.Matches(event) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			subscriber.dropped++
+		}
+	}
+}
+
+// publishToolEvent is a ToolRegistryEventHandler
+func (h *eventHub) publishToolEvent(event ToolRegistryEvent) {
+	source := toolSourceID(event.ToolName)
+	if source == "" {
+		source = event.Metadata.Source
+	}
+	h.publish(string(event.Type), source, event.Timestamp, event)
+}
+
+// publishInsights is a selflearn.InsightHandler
+func (h *eventHub) publishInsights(insights []selflearn.Insight) {
+	for _, insight := range insights {
+		h.publish(EventInsightGenerated, toolSourceID(insight.Metadata["tool_name"]), insight.CreatedAt, insight)
+	}
+}
+
+// publishSpecReload is an importer.SpecReloadHandler
+func (h *eventHub) publishSpecReload(reload importer.SpecReload) {
+	h.publish(EventSpecReloaded, reload.SourceID, reload.Timestamp, reload)
+}
+
+// toolSourceID extracts the spec source ID from an imported tool name
+// (<type>.<source>.<operation>). Other names have no source ID.
+func toolSourceID(toolName string) string {
+	parts := strings.SplitN(toolName, ".", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[1]
+}
+
+// parseEventFilter builds a filter from the comma-separated type and source
+// query parameters
+func parseEventFilter(types, sources string) (EventFilter, error) {
+	filter := EventFilter{
+		Types:   splitFilterValues(types),
+		Sources: splitFilterValues(sources),
+	}
+	for eventType := range filter.Types {
+		if !streamEventTypes[eventType] {
+			return filter, fmt.Errorf("unknown event type: %s", eventType)
+		}
+	}
+	return filter, nil
+}
+
+// splitFilterValues splits a comma-separated list into a set
+func splitFilterValues(list string) map[string]bool {
+	values := make(map[string]bool)
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values[value] = true
+		}
+	}
+	return values
+}
+
+// setupEventRoutes mounts the Server-Sent Events stream of tool registry
+// changes and learning insights
+func (s *Server) setupEventRoutes(router *gin.Engine) {
+	router.GET("/api/v1/events", func(c *gin.Context) {
+		filter, err := parseEventFilter(c.Query("type"), c.Query("source"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		id, events := s.events.subscribe(filter)
+		defer s.events.unsubscribe(id)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		keepAlive := time.NewTicker(eventKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-s.serverCtx.Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			case event := <-events:
+				data, err := json.Marshal(event)
+				if err != nil {
+					s.logger.Warn("Failed to encode stream event",
+						zap.String("type", event.Type),
+						zap.Error(err))
+					continue
+				}
+				if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			}
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestEventHub(t *testing.T) {
+	logger := zap.NewNop()
+	registry := NewToolRegistry(logger)
+	hub := newEventHub(logger)
+	registry.AddEventHandler(hub.publishToolEvent)
+
+	filter, err := parseEventFilter("tool_added, insight_generated", "petstore")
+	assert.NoError(t, err)
+	id, events := hub.subscribe(filter)
+	defer hub.unsubscribe(id)
+
+	// Other sources and event types are filtered out
+	assert.NoError(t, registry.Register(&TestTool{name: "openapi.blog.listPosts"}))
+	assert.NoError(t, registry.Register(&TestTool{name: "openapi.petstore.getPet"}))
+	assert.NoError(t, registry.Unregister("openapi.petstore.getPet"))
+
+	select {
+	case event := <-events:
+		assert.Equal(t, string(ToolEventAdded), event.Type)
+		assert.Equal(t, "petstore", event.Source)
+		assert.Equal(t, "openapi.petstore.getPet", event.Data.(ToolRegistryEvent).ToolName)
+	case <-time.After(time.Second):
+		t.Fatal("Expected a tool_added event")
+	}
+
+	hub.publishInsights([]selflearn.Insight{{
+		ID:       "insight-1",
+		Type:     selflearn.InsightTypePerformance,
+		Metadata: map[string]string{"tool_name": "openapi.petstore.getPet"},
+	}})
+	select {
+	case event := <-events:
+		assert.Equal(t, EventInsightGenerated, event.Type)
+		assert.Equal(t, "insight-1", event.Data.(selflearn.Insight).ID)
+	case <-time.After(time.Second):
+		t.Fatal("Expected an insight_generated event")
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("Unexpected event: %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = parseEventFilter("tool_exploded", "")
+	assert.Error(t, err)
+}
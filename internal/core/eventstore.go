@@ -0,0 +1,28 @@
+package core
+
+import (
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/eventstore"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// openEventStore opens the store persisting the agent event log, returning
+// nil without agent.events.path or with in-memory storage, in which case
+// events are kept in memory only
+func openEventStore(logger *zap.Logger) (*eventstore.Store, error) {
+	path := viper.GetString("agent.events.path")
+	if path == "" || inMemoryStorage() {
+		return nil, nil
+	}
+	size := viper.GetInt("agent.events.log_size")
+	if size <= 0 {
+		size = agent.DefaultEventLogSize
+	}
+	store, err := eventstore.Open(path, size)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Agent events persisted", zap.String("path", path), zap.Int("size", size))
+	return store, nil
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCConfig configures the gRPC listener
+type GRPCConfig struct {
+	Reflection bool // Expose the server reflection service, e.g. for grpcurl
+
+	// Keepalive pings idle connections every KeepaliveTime and drops those
+	// that do not answer within KeepaliveTimeout. Clients may ping at most
+	// every KeepaliveMinTime.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	KeepaliveMinTime time.Duration
+
+	MaxConnectionIdle time.Duration // Zero keeps idle connections open
+	MaxRecvMsgSize    int           // Bytes; zero keeps the gRPC default of 4 MiB
+
+	TLS *tls.Config // Nil serves plaintext
+}
+
+// loadGRPCConfig reads the gRPC settings from the server.grpc configuration
+func loadGRPCConfig() GRPCConfig {
+	return GRPCConfig{
+		Reflection:        viper.GetBool("server.grpc.reflection"),
+		KeepaliveTime:     time.Duration(viper.GetInt("server.grpc.keepalive_time_ms")) * time.Millisecond,
+		KeepaliveTimeout:  time.Duration(viper.GetInt("server.grpc.keepalive_timeout_ms")) * time.Millisecond,
+		KeepaliveMinTime:  time.Duration(viper.GetInt("server.grpc.keepalive_min_time_ms")) * time.Millisecond,
+		MaxConnectionIdle: time.Duration(viper.GetInt("server.grpc.max_connection_idle_ms")) * time.Millisecond,
+		MaxRecvMsgSize:    viper.GetInt("server.grpc.max_recv_msg_size"),
+	}
+}
+
+// newGRPCServer creates the gRPC server serving the agent service alongside
+// the standard health service and, when enabled, reflection. Agent RPCs
+// require credentials when auth is set.
+func newGRPCServer(config GRPCConfig, agentServer *agent.AgentServer, auth *authenticator, logger *zap.Logger) (*grpc.Server, *health.Server) {
+	options := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:              config.KeepaliveTime,
+			Timeout:           config.KeepaliveTimeout,
+			MaxConnectionIdle: config.MaxConnectionIdle,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime: config.KeepaliveMinTime,
+			// Agents hold sessions open between invocations
+			PermitWithoutStream: true,
+		}),
+	}
+	if config.MaxRecvMsgSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(config.MaxRecvMsgSize))
+	}
+	if config.TLS != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(config.TLS)))
+	}
+	unary, stream := agentInterceptors(auth)
+	options = append(options, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+
+	grpcServer := grpc.NewServer(options...)
+	agentpb.RegisterAgentServiceServer(grpcServer, agentServer)
+
+	// Services report NOT_SERVING until the listener is up
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(agentpb.AgentService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	if config.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	logger.Debug("gRPC server configured",
+		zap.Bool("reflection", config.Reflection),
+		zap.Duration("keepalive_time", config.KeepaliveTime),
+		zap.Duration("keepalive_timeout", config.KeepaliveTimeout))
+	return grpcServer, healthServer
+}
+
+// setGRPCServing updates the health status of every gRPC service
+func (s *Server) setGRPCServing(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	s.grpcHealth.SetServingStatus("", status)
+	s.grpcHealth.SetServingStatus(agentpb.AgentService_ServiceDesc.ServiceName, status)
+}
+
+// stopGRPC drains the gRPC server. Streams still open when ctx ends, such
+// as event subscriptions, are closed forcibly.
+func (s *Server) stopGRPC(ctx context.Context) {
+	// Tell load balancers to move traffic away before connections drain
+	s.grpcHealth.Shutdown()
+	s.grpcListening.Store(false)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.logger.Warn("gRPC graceful shutdown timed out, closing remaining streams")
+		s.grpcServer.Stop()
+		<-stopped
+	}
+}
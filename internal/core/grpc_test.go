@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCServer(t *testing.T) {
+	logger := zap.NewNop()
+	registry := NewToolRegistry(logger)
+	require.NoError(t, registry.Register(&TestTool{name: "test-tool", description: "Test"}))
+	agentServer := agent.NewAgentServer(logger, registry)
+
+	grpcServer, grpcHealth := newGRPCServer(GRPCConfig{Reflection: true, KeepaliveTime: time.Minute, KeepaliveTimeout: time.Second}, agentServer, nil, logger)
+	server := &Server{logger: logger, grpcServer: grpcServer, grpcHealth: grpcHealth}
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = grpcServer.Serve(listener) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx := context.Background()
+
+	// Health follows the listener
+	healthClient := healthpb.NewHealthClient(conn)
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		response, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return response.Status
+	}
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+	server.setGRPCServing(true)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check("aionmcp.agent.v1.AgentService"))
+
+	// The agent service answers
+	client := agentpb.NewAgentServiceClient(conn)
+	session, err := client.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Agent"})
+	require.NoError(t, err)
+	tools, err := client.ListTools(ctx, &agentpb.ListToolsRequest{SessionId: session.SessionId})
+	require.NoError(t, err)
+	assert.Equal(t, int32(registry.Count()), tools.TotalCount)
+
+	// Reflection lists the services
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	reflected, err := stream.Recv()
+	require.NoError(t, err)
+	var services []string
+	for _, service := range reflected.GetListServicesResponse().Service {
+		services = append(services, service.Name)
+	}
+	assert.Contains(t, services, "aionmcp.agent.v1.AgentService")
+	assert.Contains(t, services, "grpc.health.v1.Health")
+	require.NoError(t, stream.CloseSend())
+
+	// Shutdown reports NOT_SERVING and closes lingering streams
+	stopCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	server.stopGRPC(stopCtx)
+	_, err = client.ListTools(ctx, &agentpb.ListToolsRequest{SessionId: session.SessionId})
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// DefaultLearningQueueThreshold is the share of the learning queue that may
+// fill before the server reports itself not ready
+const DefaultLearningQueueThreshold = 0.9
+
+// readinessCheckTimeout bounds each dependency check of a readiness probe
+const readinessCheckTimeout = 2 * time.Second
+
+// ComponentStatus is the state of one dependency checked by the readiness probe
+type ComponentStatus string
+
+const (
+	ComponentUp   ComponentStatus = "up"
+	ComponentDown ComponentStatus = "down"
+)
+
+// ComponentHealth reports one dependency checked by the readiness probe
+type ComponentHealth struct {
+	Status  ComponentStatus `json:"status"`
+	Message string          `json:"message,omitempty"`
+}
+
+// Readiness is the result of the readiness probe
+type Readiness struct {
+	Ready      bool                       `json:"ready"`
+	Timestamp  time.Time                  `json:"timestamp"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// up builds the result of a working component
+func up(message string) ComponentHealth {
+	return ComponentHealth{Status: ComponentUp, Message: message}
+}
+
+// down builds the result of a failing component
+func down(message string) ComponentHealth {
+	return ComponentHealth{Status: ComponentDown, Message: message}
+}
+
+// Readiness checks that the dependencies serving traffic need are working:
+// learning storage accepts writes, the gRPC listener is up, the spec file
+// watcher is running and the learning queue is not saturated
+func (s *Server) Readiness(ctx context.Context) Readiness {
+	readiness := Readiness{
+		Ready:      true,
+		Timestamp:  time.Now().UTC(),
+		Components: make(map[string]ComponentHealth, 4),
+	}
+
+	storage := up(viper.GetString("storage.type"))
+	checkCtx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	if err := s.learningEngine.CheckStorage(checkCtx); err != nil {
+		storage = down(fmt.Sprintf("storage is not writable: %v", err))
+	}
+	cancel()
+	readiness.Components["storage"] = storage
+
+	if s.grpcListening.Load() {
+		readiness.Components["grpc"] = up(fmt.Sprintf("listening on port %d", viper.GetInt("server.grpc_port")))
+	} else {
+		readiness.Components["grpc"] = down("gRPC listener is not serving")
+	}
+
+	if s.fileWatcher != nil && s.fileWatcher.Running() {
+		readiness.Components["file_watcher"] = up(fmt.Sprintf("watching %d files", len(s.fileWatcher.GetWatchedFiles())))
+	} else {
+		readiness.Components["file_watcher"] = down("spec file watcher has stopped")
+	}
+
+	threshold := viper.GetFloat64("health.readiness.learning_queue_threshold")
+	if threshold <= 0 {
+		threshold = DefaultLearningQueueThreshold
+	}
+	pressure := s.learningEngine.PressureStatus()
+	queue := fmt.Sprintf("%d of %d queued", pressure.QueueDepth, pressure.QueueCapacity)
+	if pressure.QueueCapacity > 0 && float64(pressure.QueueDepth) >= threshold*float64(pressure.QueueCapacity) {
+		readiness.Components["learning_queue"] = down("learning queue is saturated: " + queue)
+	} else {
+		readiness.Components["learning_queue"] = up(queue)
+	}
+
+	for _, component := range readiness.Components {
+		readiness.Ready = readiness.Ready && component.Status == ComponentUp
+	}
+	return readiness
+}
+
+// setupProbeRoutes mounts the liveness and readiness probes. Liveness only
+// shows the process serves HTTP; readiness checks its dependencies and
+// answers 503 while any is down, so orchestrators stop routing traffic to it.
+func (s *Server) setupProbeRoutes(router *gin.Engine) {
+	started := time.Now()
+
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "alive",
+			"timestamp": time.Now().Unix(),
+			"uptime":    time.Since(started).Round(time.Second).String(),
+		})
+	})
+
+	router.GET("/readyz", func(c *gin.Context) {
+		readiness := s.Readiness(c.Request.Context())
+		status := http.StatusOK
+		if !readiness.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, readiness)
+	})
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerProbes(t *testing.T) {
+	viper.Set("storage.type", "boltdb")
+	viper.Set("storage.path", filepath.Join(t.TempDir(), "aionmcp.db"))
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	probe := func(path string) (int, Readiness) {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var readiness Readiness
+		if path == "/readyz" {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &readiness))
+		}
+		return recorder.Code, readiness
+	}
+
+	code, _ := probe("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+
+	// Not ready until the gRPC listener serves
+	code, readiness := probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, ComponentDown, readiness.Components["grpc"].Status)
+	assert.Equal(t, ComponentUp, readiness.Components["storage"].Status)
+	assert.Equal(t, ComponentUp, readiness.Components["file_watcher"].Status)
+	assert.Equal(t, ComponentUp, readiness.Components["learning_queue"].Status)
+
+	server.grpcListening.Store(true)
+	code, readiness = probe("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, readiness.Ready)
+
+	// A stopped file watcher makes the server unready, while it stays alive
+	server.fileWatcher.Stop()
+	assert.Eventually(t, func() bool { return !server.fileWatcher.Running() }, time.Second, 10*time.Millisecond)
+	code, readiness = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, ComponentDown, readiness.Components["file_watcher"].Status)
+	code, _ = probe("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// nameSet is a set of tool names
+type nameSet map[string]struct{}
+
+// toolIndex maps tags, sources and types to the names of the tools that
+// carry them, so queries touch only the tools they can match, and keeps the
+// names in order so listings need not sort every tool
+type toolIndex struct {
+	names   []string // Every indexed name, sorted
+	tags    map[string]nameSet
+	sources map[string]nameSet
+	types   map[string]nameSet
+	entries map[string]indexEntry // What each tool is indexed under
+}
+
+// indexEntry records the keys a tool is indexed under
+type indexEntry struct {
+	tags       []string
+	source     string
+	sourceType string
+}
+
+func newToolIndex() *toolIndex {
+	return &toolIndex{
+		tags:    make(map[string]nameSet),
+		sources: make(map[string]nameSet),
+		types:   make(map[string]nameSet),
+		entries: make(map[string]indexEntry),
+	}
+}
+
+// add indexes a tool, replacing any earlier entry for its name
+func (x *toolIndex) add(name, sourceID string, metadata types.ToolMetadata) {
+	x.remove(name)
+	entry := indexEntry{tags: metadata.Tags, source: sourceID, sourceType: metadata.Source}
+	for _, tag := range entry.tags {
+		insertName(x.tags, tag, name)
+	}
+	insertName(x.sources, entry.source, name)
+	insertName(x.types, entry.sourceType, name)
+	x.entries[name] = entry
+
+	i := sort.SearchStrings(x.names, name)
+	x.names = append(x.names, "")
+	copy(x.names[i+1:], x.names[i:])
+	x.names[i] = name
+}
+
+// remove drops a tool from the index
+func (x *toolIndex) remove(name string) {
+	entry, exists := x.entries[name]
+	if !exists {
+		return
+	}
+	for _, tag := range entry.tags {
+		deleteName(x.tags, tag, name)
+	}
+	deleteName(x.sources, entry.source, name)
+	deleteName(x.types, entry.sourceType, name)
+	delete(x.entries, name)
+
+	i := sort.SearchStrings(x.names, name)
+	x.names = append(x.names[:i], x.names[i+1:]...)
+}
+
+// bySource returns the names of the tools a source registered
+func (x *toolIndex) bySource(sourceID string) nameSet {
+	return x.sources[sourceID]
+}
+
+// candidates narrows a query to the tools its tags, sources and types
+// allow. It reports false when the query sets none of them, so every tool is
+// a candidate. Candidates still need to be matched against the query.
+func (x *toolIndex) candidates(query types.ToolQuery) (nameSet, bool) {
+	var sets []nameSet
+	for _, tag := range query.Tags {
+		sets = append(sets, x.tags[tag])
+	}
+	if len(query.Sources) > 0 {
+		sets = append(sets, union(x.sources, query.Sources))
+	}
+	if len(query.Types) > 0 {
+		sets = append(sets, union(x.types, query.Types))
+	}
+	if len(sets) == 0 {
+		return nil, false
+	}
+
+	// Intersect starting from the smallest set
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	result := make(nameSet, len(sets[0]))
+	for name := range sets[0] {
+		result[name] = struct{}{}
+	}
+	for _, set := range sets[1:] {
+		for name := range result {
+			if _, exists := set[name]; !exists {
+				delete(result, name)
+			}
+		}
+	}
+	return result, true
+}
+
+// union collects the names indexed under any of keys
+func union(index map[string]nameSet, keys []string) nameSet {
+	if len(keys) == 1 {
+		return index[keys[0]]
+	}
+	result := make(nameSet)
+	for _, key := range keys {
+		for name := range index[key] {
+			result[name] = struct{}{}
+		}
+	}
+	return result
+}
+
+func insertName(index map[string]nameSet, key, name string) {
+	set, exists := index[key]
+	if !exists {
+		set = make(nameSet)
+		index[key] = set
+	}
+	set[name] = struct{}{}
+}
+
+func deleteName(index map[string]nameSet, key, name string) {
+	set := index[key]
+	delete(set, name)
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+// FindTools returns the tools that match a query and are not disabled,
+// sorted by name. Tags, sources and types are looked up in the registry's
+// index, so narrow queries stay fast with many tools registered.
+func (r *ToolRegistry) FindTools(query types.ToolQuery) []ToolMetadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := r.index.names
+	if candidates, indexed := r.index.candidates(query); indexed {
+		names = make([]string, 0, len(candidates))
+		for name := range candidates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	tools := make([]ToolMetadata, 0)
+	for _, name := range names {
+		tool, exists := r.tools[name]
+		if !exists || r.disabled(name) {
+			continue
+		}
+		if metadata := r.listedMetadata(name, tool); query.Matches(metadata, r.sources[name]) {
+			tools = append(tools, metadata)
+		}
+	}
+	return tools
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// taggedTool is a TestTool with its own tags
+type taggedTool struct {
+	TestTool
+	tags []string
+}
+
+func (t *taggedTool) Metadata() types.ToolMetadata {
+	metadata := t.TestTool.Metadata()
+	metadata.Tags = t.tags
+	return metadata
+}
+
+func TestToolRegistry_FindTools(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	require.NoError(t, registry.RegisterBatch([]Tool{
+		&taggedTool{TestTool{name: "openapi.billing.list", source: "openapi"}, []string{"payments", "read"}},
+		&taggedTool{TestTool{name: "openapi.billing.refund", source: "openapi"}, []string{"payments"}},
+	}, "billing"))
+	require.NoError(t, registry.RegisterWithSource(&taggedTool{TestTool{name: "graphql.users.get", source: "graphql"}, []string{"read"}}, "users", "1.0.0"))
+	names := func(query types.ToolQuery) []string {
+		var names []string
+		for _, metadata := range registry.FindTools(query) {
+			names = append(names, metadata.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"openapi.billing.list"}, names(types.ToolQuery{Tags: []string{"payments", "read"}}))
+	assert.Equal(t, []string{"graphql.users.get", "openapi.billing.list"}, names(types.ToolQuery{Tags: []string{"read"}}))
+	assert.Equal(t, []string{"graphql.users.get", "openapi.billing.list", "openapi.billing.refund"}, names(types.ToolQuery{Sources: []string{"billing", "users"}}))
+	assert.Equal(t, []string{"graphql.users.get"}, names(types.ToolQuery{Types: []string{"graphql"}}))
+	assert.Equal(t, []string{"openapi.billing.refund"}, names(types.ToolQuery{Sources: []string{"billing"}, Name: "*.refund"}))
+	assert.Empty(t, names(types.ToolQuery{Tags: []string{"payments"}, Types: []string{"graphql"}}))
+	assert.Len(t, registry.FindTools(types.ToolQuery{}), registry.Count())
+
+	// Statuses follow deprecations, and disabled tools are never found
+	_, err := registry.SetToolStatus("openapi.billing.refund", ToolStatusDeprecated, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openapi.billing.refund"}, names(types.ToolQuery{Statuses: []string{types.ToolStatusDeprecated}}))
+	_, err = registry.SetToolStatus("openapi.billing.list", ToolStatusDisabled, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openapi.billing.refund"}, names(types.ToolQuery{Tags: []string{"payments"}}))
+
+	// The index follows re-registration and removal
+	require.NoError(t, registry.RegisterWithSource(&taggedTool{TestTool{name: "graphql.users.get", source: "graphql"}, []string{"admin"}}, "users", "1.1.0"))
+	assert.Empty(t, names(types.ToolQuery{Tags: []string{"read"}}))
+	require.NoError(t, registry.UnregisterBySource("billing"))
+	assert.Empty(t, names(types.ToolQuery{Tags: []string{"payments"}}))
+	assert.NotContains(t, registry.GetToolSources(), "billing")
+	assert.Equal(t, []string{"graphql.users.get"}, names(types.ToolQuery{Sources: []string{"users"}}))
+}
+
+func TestServerToolFilters(t *testing.T) {
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	defer viper.Reset()
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	require.NoError(t, server.toolRegistry.RegisterBatch([]Tool{
+		&taggedTool{TestTool{name: "openapi.billing.list", source: "openapi"}, []string{"payments", "read"}},
+		&taggedTool{TestTool{name: "openapi.billing.refund", source: "openapi"}, []string{"payments"}},
+	}, "billing"))
+	list := func(query string) (int, []string) {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/mcp/tools?"+query, nil))
+		var response struct {
+			Tools []ToolMetadata `json:"tools"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		var names []string
+		for _, tool := range response.Tools {
+			names = append(names, tool.Name)
+		}
+		return recorder.Code, names
+	}
+
+	code, names := list("tag=payments,read")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"openapi.billing.list"}, names)
+	_, names = list("source=billing&name=*.refund&status=available")
+	assert.Equal(t, []string{"openapi.billing.refund"}, names)
+	_, names = list("type=openapi&type=graphql")
+	assert.Len(t, names, 2)
+	code, _ = list("status=retired")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = list("updated_after=yesterday")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,376 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/contextvars"
+	"github.com/aionmcp/aionmcp/pkg/invocationlog"
+	"go.uber.org/zap"
+)
+
+// maxRPCMessageSize bounds a single JSON-RPC message or batch on any transport
+const maxRPCMessageSize = 10 * 1024 * 1024
+
+// mcpProtocolVersions lists the MCP protocol revisions this server speaks, newest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// rpcRequest is an incoming JSON-RPC 2.0 request or notification
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is an outgoing JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// mcpClientInfo identifies the client that opened an MCP session
+type mcpClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// rpcHandler implements the MCP JSON-RPC 2.0 methods independently of the
+// transport carrying them. One handler serves one client connection, so it
+// remembers the protocol version negotiated during initialize.
+type rpcHandler struct {
+	server    *Server
+	transport string // Reported in invocation logs: stdio, http or websocket
+	workspace string // Read-only workspace the connection belongs to
+	address   string // Client address, when the transport has one
+
+	mu              sync.RWMutex
+	protocolVersion string
+	clientInfo      mcpClientInfo
+}
+
+// newRPCHandler creates a JSON-RPC handler for one client connection
+func newRPCHandler(server *Server, transport string) *rpcHandler {
+	return &rpcHandler{
+		server:          server,
+		transport:       transport,
+		protocolVersion: mcpProtocolVersions[0],
+	}
+}
+
+// ProtocolVersion returns the protocol version negotiated with the client
+func (h *rpcHandler) ProtocolVersion() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.protocolVersion
+}
+
+// HandleMessage processes a single JSON-RPC message or batch and returns the
+// encoded response, or nil when nothing must be sent back (notifications only)
+func (h *rpcHandler) HandleMessage(ctx context.Context, data []byte) []byte {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return h.handleBatch(ctx, data)
+	}
+
+	response := h.handleRequest(ctx, data)
+	if response == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return h.encodeInternalError(response.ID, err)
+	}
+	return encoded
+}
+
+// handleBatch processes a JSON-RPC batch; responses keep the request order
+func (h *rpcHandler) handleBatch(ctx context.Context, data []byte) []byte {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		encoded, _ := json.Marshal(newRPCErrorResponse(nil, rpcParseError, "parse error"))
+		return encoded
+	}
+	if len(messages) == 0 {
+		encoded, _ := json.Marshal(newRPCErrorResponse(nil, rpcInvalidRequest, "invalid request: empty batch"))
+		return encoded
+	}
+
+	responses := make([]*rpcResponse, 0, len(messages))
+	for _, message := range messages {
+		if response := h.handleRequest(ctx, message); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(responses)
+	if err != nil {
+		return h.encodeInternalError(nil, err)
+	}
+	return encoded
+}
+
+// handleRequest processes one JSON-RPC request; notifications produce no response
+func (h *rpcHandler) handleRequest(ctx context.Context, data []byte) *rpcResponse {
+	var request rpcRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		// A valid JSON value of the wrong shape is an invalid request, not a parse error
+		if json.Valid(data) {
+			return newRPCErrorResponse(nil, rpcInvalidRequest, "invalid request")
+		}
+		return newRPCErrorResponse(nil, rpcParseError, "parse error")
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" || !validRPCID(request.ID) || !validRPCParams(request.Params) {
+		return newRPCErrorResponse(request.ID, rpcInvalidRequest, "invalid request")
+	}
+
+	result, rpcErr := h.dispatch(ctx, request)
+
+	// Notifications (no id) never receive a response
+	if len(request.ID) == 0 {
+		return nil
+	}
+
+	response := &rpcResponse{JSONRPC: "2.0", ID: request.ID}
+	if rpcErr != nil {
+		response.Error = rpcErr
+	} else {
+		response.Result = result
+	}
+	return response
+}
+
+// dispatch routes a request to its MCP method handler
+func (h *rpcHandler) dispatch(ctx context.Context, request rpcRequest) (any, *rpcError) {
+	switch request.Method {
+	case "initialize":
+		return h.initialize(request.Params)
+
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		tools := h.server.access.filter(ctx, h.server.toolRegistry.ListTools())
+		result := make([]map[string]any, 0, len(tools))
+		for _, metadata := range tools {
+			inputSchema, _ := metadata.Schema["input"].(map[string]any)
+			if inputSchema == nil {
+				inputSchema = map[string]any{"type": "object"}
+			}
+			result = append(result, map[string]any{
+				"name":        metadata.Name,
+				"description": metadata.Description,
+				"inputSchema": inputSchema,
+			})
+		}
+		return map[string]any{"tools": result}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(request.Params, &params); err != nil || params.Name == "" {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "tools/call requires a tool name"}
+		}
+		return h.callTool(ctx, params.Name, params.Arguments)
+
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s", request.Method)}
+	}
+}
+
+// initialize negotiates the protocol version and advertises server capabilities.
+// A client asking for an unsupported version is offered the newest supported one.
+func (h *rpcHandler) initialize(raw json.RawMessage) (any, *rpcError) {
+	var params struct {
+		ProtocolVersion string        `json:"protocolVersion"`
+		ClientInfo      mcpClientInfo `json:"clientInfo"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "invalid initialize params"}
+		}
+	}
+
+	version := mcpProtocolVersions[0]
+	for _, supported := range mcpProtocolVersions {
+		if params.ProtocolVersion == supported {
+			version = supported
+			break
+		}
+	}
+
+	h.mu.Lock()
+	h.protocolVersion = version
+	h.clientInfo = params.ClientInfo
+	h.mu.Unlock()
+
+	h.server.logger.Info("MCP client initialized",
+		zap.String("transport", h.transport),
+		zap.String("client", params.ClientInfo.Name),
+		zap.String("requested_version", params.ProtocolVersion),
+		zap.String("protocol_version", version))
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]any{
+				"listChanged": false,
+			},
+		},
+		"serverInfo": map[string]any{
+			"name":    "aionmcp",
+			"version": "0.1.0",
+		},
+	}, nil
+}
+
+// callTool executes a tool and wraps the outcome in an MCP tool result
+func (h *rpcHandler) callTool(ctx context.Context, name string, arguments map[string]any) (any, *rpcError) {
+	s := h.server
+
+	if err := s.readOnly.Check(h.workspace); err != nil {
+		return toolCallResult(err.Error(), true), nil
+	}
+
+	// Tools hidden from the caller's roles are reported as missing
+	tool, err := s.toolRegistry.Get(name)
+	if err != nil || !s.access.allowsInContext(ctx, tool.Metadata()) {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("tool not found: %s", name)}
+	}
+
+	startTime := time.Now()
+	result, err := tool.Execute(contextvars.WithWorkspace(ctx, h.workspace), arguments)
+	duration := time.Since(startTime)
+
+	sourceType := toolSourceType(tool)
+	recordCtx := selflearn.WithWorkspace(ctx, h.workspace)
+	if recordErr := s.learningEngine.RecordExecution(recordCtx, name, sourceType, arguments, result, err, duration); recordErr != nil {
+		s.logger.Warn("Failed to record execution for learning",
+			zap.String("tool", name),
+			zap.Error(recordErr))
+	}
+
+	h.mu.RLock()
+	clientName := h.clientInfo.Name
+	h.mu.RUnlock()
+	auditInvocation(s.audit, auditActor(ctx, h.transport, clientName, h.address), name, sourceType, arguments, err, s.logger)
+
+	if s.invocationLog != nil {
+		record := invocationlog.Record{
+			Actor:      invocationlog.Actor{Type: h.transport, ID: h.transport, Name: clientName, Address: h.address},
+			Tool:       name,
+			ToolSource: sourceType,
+			Transport:  h.transport,
+			ParamsHash: invocationlog.HashParams(arguments),
+			Outcome:    invocationlog.OutcomeSuccess,
+			LatencyMs:  duration.Milliseconds(),
+		}
+		if h.address != "" {
+			record.Actor.ID = h.address
+		}
+		if err != nil {
+			record.Outcome = invocationlog.OutcomeFailure
+			record.Error = err.Error()
+		}
+		s.invocationLog.Record(record)
+	}
+
+	if err != nil {
+		s.logger.Error("Tool execution failed",
+			zap.String("tool", name),
+			zap.Duration("duration", duration),
+			zap.Error(err))
+		return toolCallResult(err.Error(), true), nil
+	}
+
+	text, err := json.Marshal(result)
+	if err != nil {
+		return nil, &rpcError{Code: rpcInternalError, Message: "failed to encode tool result"}
+	}
+	return toolCallResult(string(text), false), nil
+}
+
+// encodeInternalError encodes an internal error response after a failed marshal
+func (h *rpcHandler) encodeInternalError(id json.RawMessage, err error) []byte {
+	h.server.logger.Error("Failed to encode JSON-RPC response", zap.Error(err))
+	encoded, _ := json.Marshal(newRPCErrorResponse(id, rpcInternalError, "internal error"))
+	return encoded
+}
+
+// newRPCErrorResponse builds an error response for the given request id
+func newRPCErrorResponse(id json.RawMessage, code int, message string) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: idOrNull(id), Error: &rpcError{Code: code, Message: message}}
+}
+
+// toolCallResult builds the MCP content envelope for a tool result
+func toolCallResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]any{
+			{"type": "text", "text": text},
+		},
+		"isError": isError,
+	}
+}
+
+// validRPCID reports whether an id is absent, a string, a number or null
+func validRPCID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return true
+	}
+	switch id[0] {
+	case '{', '[', 't', 'f':
+		return false
+	}
+	return true
+}
+
+// validRPCParams reports whether params are absent, null, an object or an array
+func validRPCParams(params json.RawMessage) bool {
+	if len(params) == 0 {
+		return true
+	}
+	switch params[0] {
+	case '{', '[', 'n':
+		return true
+	}
+	return false
+}
+
+// idOrNull returns the request id, or JSON null when it is missing
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
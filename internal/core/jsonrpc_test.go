@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRPCHandler(t *testing.T) {
+	logger := zap.NewNop()
+	server := &Server{logger: logger, toolRegistry: NewToolRegistry(logger)}
+	handler := newRPCHandler(server, "stdio")
+
+	call := func(message string) map[string]any {
+		response := handler.HandleMessage(context.Background(), []byte(message))
+		if response == nil {
+			return nil
+		}
+		var decoded map[string]any
+		assert.NoError(t, json.Unmarshal(response, &decoded))
+		return decoded
+	}
+
+	// Supported protocol versions are echoed back, unknown ones get the newest
+	response := call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test"}}}`)
+	assert.Nil(t, response["error"])
+	assert.Equal(t, "2024-11-05", response["result"].(map[string]any)["protocolVersion"])
+	assert.Equal(t, "2024-11-05", handler.ProtocolVersion())
+
+	response = call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+	assert.Equal(t, mcpProtocolVersions[0], response["result"].(map[string]any)["protocolVersion"])
+
+	response = call(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Nil(t, response["error"])
+	assert.Len(t, response["result"].(map[string]any)["tools"], 2)
+
+	// Notifications are never answered
+	assert.Nil(t, call(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+
+	response = call(`{"jsonrpc":"2.0","id":"x","method":"resources/list"}`)
+	assert.Equal(t, float64(rpcMethodNotFound), response["error"].(map[string]any)["code"])
+
+	response = call(`not json`)
+	assert.Equal(t, float64(rpcParseError), response["error"].(map[string]any)["code"])
+
+	response = call(`{"jsonrpc":"2.0","id":{"a":1},"method":"ping"}`)
+	assert.Equal(t, float64(rpcInvalidRequest), response["error"].(map[string]any)["code"])
+
+	// Batches answer every request in order and skip notifications
+	batch := handler.HandleMessage(context.Background(), []byte(`[
+		{"jsonrpc":"2.0","id":1,"method":"ping"},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":2,"method":"unknown"}
+	]`))
+	var responses []map[string]any
+	assert.NoError(t, json.Unmarshal(batch, &responses))
+	assert.Len(t, responses, 2)
+	assert.Equal(t, float64(1), responses[0]["id"])
+	assert.Equal(t, float64(2), responses[1]["id"])
+
+	assert.Nil(t, handler.HandleMessage(context.Background(), []byte(`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)))
+
+	response = call(`[]`)
+	assert.Equal(t, float64(rpcInvalidRequest), response["error"].(map[string]any)["code"])
+}
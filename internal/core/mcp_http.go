@@ -0,0 +1,81 @@
+package core
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/readonly"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+// setupMCPRoutes mounts the MCP JSON-RPC 2.0 endpoint over HTTP and WebSocket.
+// HTTP requests are stateless; a WebSocket connection keeps one session.
+func (s *Server) setupMCPRoutes(router *gin.Engine) {
+	// One JSON-RPC message or batch per POST
+	router.POST("/mcp", func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRPCMessageSize))
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+
+		handler := newRPCHandler(s, "http")
+		handler.workspace = c.GetHeader(readonly.WorkspaceHeader)
+		handler.address = c.ClientIP()
+
+		response := handler.HandleMessage(c.Request.Context(), body)
+		if response == nil {
+			// Only notifications were sent
+			c.Status(http.StatusAccepted)
+			return
+		}
+		c.Header("MCP-Protocol-Version", handler.ProtocolVersion())
+		c.Data(http.StatusOK, "application/json", response)
+	})
+
+	// One JSON-RPC message or batch per text frame
+	wsServer := websocket.Server{
+		// Accept clients without an Origin header (non-browser MCP clients)
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			conn.MaxPayloadBytes = maxRPCMessageSize
+
+			handler := newRPCHandler(s, "websocket")
+			handler.workspace = conn.Request().Header.Get(readonly.WorkspaceHeader)
+			handler.address = conn.Request().RemoteAddr
+
+			// Messages outlive the upgrade request but keep its caller's identity
+			ctx := s.serverCtx
+			if identity, ok := agent.IdentityFromContext(conn.Request().Context()); ok {
+				ctx = agent.WithIdentity(ctx, identity)
+			}
+
+			s.logger.Info("MCP WebSocket client connected", zap.String("address", handler.address))
+			for {
+				var message []byte
+				if err := websocket.Message.Receive(conn, &message); err != nil {
+					if err != io.EOF {
+						s.logger.Warn("MCP WebSocket receive failed", zap.Error(err))
+					}
+					return
+				}
+
+				response := handler.HandleMessage(ctx, message)
+				if response == nil {
+					continue
+				}
+				if err := websocket.Message.Send(conn, string(response)); err != nil {
+					s.logger.Warn("MCP WebSocket send failed", zap.Error(err))
+					return
+				}
+			}
+		},
+	}
+	router.GET("/mcp/ws", func(c *gin.Context) {
+		wsServer.ServeHTTP(c.Writer, c.Request)
+	})
+}
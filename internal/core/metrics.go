@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/aionmcp/aionmcp/pkg/metrics"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// metricsPath is where Prometheus scrapes the server
+const metricsPath = "/metrics"
+
+// instrumentedTool records the outcome and latency of each invocation
+type instrumentedTool struct {
+	types.Tool
+	metrics *metrics.Metrics
+	source  string
+}
+
+// Execute runs the tool and records the invocation
+func (t *instrumentedTool) Execute(ctx context.Context, input any) (any, error) {
+	start := time.Now()
+	result, err := t.Tool.Execute(ctx, input)
+	t.metrics.ObserveInvocation(t.Name(), t.source, time.Since(start), err)
+	return result, err
+}
+
+// withMetrics wraps a tool so its invocations are recorded
+func withMetrics(tool Tool, m *metrics.Metrics, source string) Tool {
+	if m == nil {
+		return tool
+	}
+	return &instrumentedTool{Tool: tool, metrics: m, source: source}
+}
+
+// SetMetrics records the invocations of tools returned by Get and exports
+// the number of registered tools. Nil metrics disable recording.
+func (r *ToolRegistry) SetMetrics(m *metrics.Metrics) {
+	r.mu.Lock()
+	r.metrics = m
+	r.mu.Unlock()
+	if m == nil {
+		return
+	}
+	m.Collect(metrics.RegisteredTools, func(emit metrics.Emit) {
+		r.mu.RLock()
+		counts := make(map[string]int)
+		for name := range r.tools {
+			counts[r.sources[name]]++
+		}
+		r.mu.RUnlock()
+		for source, count := range counts {
+			emit(float64(count), source)
+		}
+	})
+}
+
+// newMetrics creates the server metrics, exporting session, event stream
+// and importer state on each scrape. It returns nil while metrics.enabled is off.
+func newMetrics(agentServer *agent.AgentServer, importerManager *importer.ImporterManager) *metrics.Metrics {
+	if !viper.GetBool("metrics.enabled") {
+		return nil
+	}
+	m := metrics.New()
+	m.Collect(metrics.AgentSessions, func(emit metrics.Emit) {
+		for status, count := range agentServer.SessionCounts() {
+			emit(float64(count), status)
+		}
+	})
+	m.Collect(metrics.AgentEventStreams, func(emit metrics.Emit) {
+		for _, stats := range agentServer.EventStreamStats() {
+			emit(float64(stats.Streams), stats.SessionID)
+		}
+	})
+	m.Collect(metrics.AgentEventsBuffered, func(emit metrics.Emit) {
+		for _, stats := range agentServer.EventStreamStats() {
+			emit(float64(stats.Buffered), stats.SessionID)
+		}
+	})
+	m.Collect(metrics.AgentEventsDropped, func(emit metrics.Emit) {
+		for _, stats := range agentServer.EventStreamStats() {
+			emit(float64(stats.Dropped), stats.SessionID)
+		}
+	})
+	m.Collect(metrics.AgentEventStreamsReaped, func(emit metrics.Emit) {
+		emit(float64(agentServer.EventStreamsReaped()))
+	})
+	m.Collect(metrics.ImporterSources, func(emit metrics.Emit) {
+		counts := make(map[string]int)
+		for _, source := range importerManager.ListSources() {
+			counts[string(source.Type)]++
+		}
+		for specType, count := range counts {
+			emit(float64(count), specType)
+		}
+	})
+	m.Collect(metrics.ImporterThrottled, func(emit metrics.Emit) {
+		for _, stats := range importerManager.ListThrottleStats() {
+			emit(float64(stats.Throttled), stats.SourceID)
+		}
+	})
+	m.Collect(metrics.ImporterRejected, func(emit metrics.Emit) {
+		for _, stats := range importerManager.ListThrottleStats() {
+			emit(float64(stats.Rejected), stats.SourceID)
+		}
+	})
+	m.Collect(metrics.ImporterQuotasExhausted, func(emit metrics.Emit) {
+		emit(float64(importerManager.GetQuotaSummary().Exhausted))
+	})
+	return m
+}
+
+// dashboardConfig reads the Grafana dashboard settings of metrics.dashboard
+func dashboardConfig() metrics.DashboardConfig {
+	return metrics.DashboardConfig{
+		Title:              viper.GetString("metrics.dashboard.title"),
+		UID:                viper.GetString("metrics.dashboard.uid"),
+		AvailabilityTarget: viper.GetFloat64("metrics.dashboard.availability_target"),
+		BudgetWindow:       viper.GetString("metrics.dashboard.budget_window"),
+	}
+}
+
+// GrafanaDashboard returns Grafana dashboard JSON charting the metrics the
+// server exports, configured by metrics.dashboard
+func GrafanaDashboard() ([]byte, error) {
+	return metrics.DashboardJSON(dashboardConfig())
+}
+
+// setupMetricsRoutes mounts the Prometheus scrape endpoint and the matching
+// Grafana dashboard
+func (s *Server) setupMetricsRoutes(router *gin.Engine) {
+	router.GET(metricsPath, gin.WrapH(s.metrics.Handler()))
+	router.GET("/api/v1/metrics/dashboard", func(c *gin.Context) {
+		c.JSON(http.StatusOK, metrics.NewDashboard(dashboardConfig()))
+	})
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerMetrics(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("metrics.enabled", true)
+	viper.Set("metrics.dashboard.availability_target", 0.999)
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	response, err := http.Post(httpServer.URL+"/api/v1/mcp/tools/echo/invoke", "application/json", strings.NewReader(`{"message": "hi"}`))
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	_, err = server.agentServer.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Agent"})
+	require.NoError(t, err)
+
+	response, err = http.Get(httpServer.URL + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	exposition := string(body)
+	assert.Contains(t, exposition, `aionmcp_tool_invocations_total{outcome="success",source="builtin",tool="echo"} 1`)
+	assert.Contains(t, exposition, `aionmcp_tool_invocation_duration_seconds_count{source="builtin",tool="echo"} 1`)
+	assert.Contains(t, exposition, `aionmcp_registered_tools{source="builtin"}`)
+	assert.Contains(t, exposition, `aionmcp_agent_sessions{status="active"} 1`)
+	assert.Contains(t, exposition, "aionmcp_importer_quotas_exhausted 0")
+
+	// The dashboard follows the configured availability target
+	response, err = http.Get(httpServer.URL + "/api/v1/metrics/dashboard")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	var dashboard struct {
+		Panels []struct {
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&dashboard))
+	var budget string
+	for _, panel := range dashboard.Panels {
+		if panel.Title == "Error budget remaining" {
+			budget = panel.Targets[0].Expr
+		}
+	}
+	assert.True(t, strings.HasSuffix(budget, "/ 0.001"), budget)
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// insightNotifier pushes new insights of at least a minimum priority to
+// the configured notification sinks
+type insightNotifier struct {
+	dispatcher  *notify.Dispatcher
+	minPriority selflearn.Priority
+}
+
+// notificationSinks returns the sinks configured under notifications
+func notificationSinks() []notify.Sink {
+	var sinks []notify.Sink
+	if url := viper.GetString("notifications.webhook.url"); url != "" {
+		sinks = append(sinks, &notify.Webhook{URL: url, Headers: viper.GetStringMapString("notifications.webhook.headers")})
+	}
+	if url := viper.GetString("notifications.slack.webhook_url"); url != "" {
+		sinks = append(sinks, &notify.Slack{WebhookURL: url})
+	}
+	if addr := viper.GetString("notifications.email.smtp_addr"); addr != "" {
+		sinks = append(sinks, &notify.Email{
+			Addr:     addr,
+			Username: viper.GetString("notifications.email.username"),
+			Password: viper.GetString("notifications.email.password"),
+			From:     viper.GetString("notifications.email.from"),
+			To:       viper.GetStringSlice("notifications.email.to"),
+		})
+	}
+	return sinks
+}
+
+// newInsightNotifier creates the notifier configured under notifications, or
+// returns nil when no sink is configured
+func newInsightNotifier(c clock.Clock, logger *zap.Logger) (*insightNotifier, error) {
+	sinks := notificationSinks()
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	minPriority := selflearn.Priority(viper.GetString("notifications.min_priority"))
+	switch minPriority {
+	case "":
+		minPriority = selflearn.PriorityHigh
+	case selflearn.PriorityLow, selflearn.PriorityMedium, selflearn.PriorityHigh, selflearn.PriorityCritical:
+	default:
+		return nil, fmt.Errorf("unknown notifications.min_priority: %s", minPriority)
+	}
+
+	dispatcher := notify.NewDispatcher(sinks, notify.DispatcherConfig{
+		DedupWindow: time.Duration(viper.GetInt("notifications.dedup_minutes")) * time.Minute,
+		MaxPerHour:  viper.GetInt("notifications.max_per_hour"),
+		Clock:       c,
+	}, logger)
+	return &insightNotifier{dispatcher: dispatcher, minPriority: minPriority}, nil
+}
+
+// notifyInsights is a selflearn.InsightHandler. Insights about the same
+// problem share a key, so regenerating one does not notify again within
+// the dedup window.
+func (n *insightNotifier) notifyInsights(insights []selflearn.Insight) {
+	for _, insight := range insights {
+		if !insight.Priority.AtLeast(n.minPriority) {
+			continue
+		}
+		toolName := insight.Metadata["tool_name"]
+		message := insight.Description
+		if insight.Suggestion != "" {
+			message += "\n\nSuggestion: " + insight.Suggestion
+		}
+		fields := map[string]string{"insight_id": insight.ID, "type": string(insight.Type)}
+		if toolName != "" {
+			fields["tool"] = toolName
+		}
+		n.dispatcher.Notify(notify.Notification{
+			Key:      strings.Join([]string{string(insight.Type), toolName, insight.Title}, "\x00"),
+			Severity: string(insight.Priority),
+			Title:    insight.Title,
+			Message:  message,
+			Fields:   fields,
+			Time:     insight.CreatedAt,
+		})
+	}
+}
+
+// setupNotificationRoutes mounts the status and test of insight
+// notifications, which require the admin scope
+func (s *Server) setupNotificationRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin/notifications")
+
+	// Report the sinks and what happened to notifications so far
+	admin.GET("", func(c *gin.Context) {
+		if s.notifier == nil {
+			c.JSON(http.StatusOK, gin.H{"enabled": false})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"enabled":      true,
+			"sinks":        s.notifier.dispatcher.Sinks(),
+			"min_priority": s.notifier.minPriority,
+			"stats":        s.notifier.dispatcher.Stats(),
+		})
+	})
+
+	// Send a test notification to every sink, subject to the rate limit
+	admin.POST("/test", func(c *gin.Context) {
+		if s.notifier == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "no notification sinks are configured"})
+			return
+		}
+		now := time.Now().UTC()
+		queued := s.notifier.dispatcher.Notify(notify.Notification{
+			Key:      "test\x00" + now.Format(time.RFC3339Nano),
+			Severity: "info",
+			Title:    "AionMCP test notification",
+			Message:  "Insight notifications reach this channel.",
+			Time:     now,
+		})
+		if !queued {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "notification was not queued; see stats"})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"queued": true})
+	})
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/notify"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerInsightNotifications(t *testing.T) {
+	var mu sync.Mutex
+	var webhook []notify.Notification
+	var slack []string
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/slack" {
+			var message struct {
+				Text string `json:"text"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+			slack = append(slack, message.Text)
+			return
+		}
+		var notification notify.Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		webhook = append(webhook, notification)
+	}))
+	defer service.Close()
+	received := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(webhook), len(slack)
+	}
+
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("notifications.min_priority", "high")
+	viper.Set("notifications.dedup_minutes", 60)
+	viper.Set("notifications.webhook.url", service.URL+"/hook")
+	viper.Set("notifications.slack.webhook_url", service.URL+"/slack")
+	defer viper.Reset()
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Low-priority insights and repeats of one problem are not pushed
+	ctx := context.Background()
+	critical := selflearn.Insight{
+		Type:        selflearn.InsightTypeReliability,
+		Priority:    selflearn.PriorityCritical,
+		Title:       "echo fails often",
+		Description: "Half of the calls fail",
+		Suggestion:  "Check the upstream",
+		Metadata:    map[string]string{"tool_name": "echo"},
+	}
+	require.NoError(t, server.learningEngine.RecordInsight(ctx, critical))
+	require.NoError(t, server.learningEngine.RecordInsight(ctx, critical))
+	require.NoError(t, server.learningEngine.RecordInsight(ctx, selflearn.Insight{
+		Type:     selflearn.InsightTypeOptimization,
+		Priority: selflearn.PriorityLow,
+		Title:    "echo could be cached",
+	}))
+	require.Eventually(t, func() bool {
+		hooks, messages := received()
+		return hooks == 1 && messages == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, "critical", webhook[0].Severity)
+	assert.Equal(t, "echo fails often", webhook[0].Title)
+	assert.Equal(t, "Half of the calls fail\n\nSuggestion: Check the upstream", webhook[0].Message)
+	assert.Equal(t, "echo", webhook[0].Fields["tool"])
+	assert.Contains(t, slack[0], "*[CRITICAL] echo fails often*")
+	mu.Unlock()
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/admin/notifications/test", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	require.Eventually(t, func() bool {
+		hooks, _ := received()
+		return hooks == 2
+	}, time.Second, 10*time.Millisecond)
+
+	var status struct {
+		Enabled     bool                   `json:"enabled"`
+		Sinks       []string               `json:"sinks"`
+		MinPriority string                 `json:"min_priority"`
+		Stats       notify.DispatcherStats `json:"stats"`
+	}
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/admin/notifications", nil))
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.True(t, status.Enabled)
+	assert.Len(t, status.Sinks, 2)
+	assert.Equal(t, "high", status.MinPriority)
+	assert.Equal(t, int64(1), status.Stats.Deduplicated)
+
+	// An unknown priority is a configuration error
+	viper.Set("notifications.min_priority", "urgent")
+	_, err = NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	assert.ErrorContains(t, err, "notifications.min_priority")
+}
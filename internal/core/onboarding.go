@@ -0,0 +1,305 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// CredentialIssuer mints the scoped credential handed to a new agent in its
+// onboarding bundle
+type CredentialIssuer interface {
+	IssueOnboardingCredential(agentName string, tools []string) (*OnboardingCredential, error)
+}
+
+// OnboardingCredential is a credential included in an onboarding bundle
+type OnboardingCredential struct {
+	Header    string     `json:"header"` // Request header that carries the credential
+	Value     string     `json:"value"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// OnboardingRequest is the body of the onboarding bundle endpoint
+type OnboardingRequest struct {
+	AgentName       string `json:"agent_name"`
+	IncludeOptional bool   `json:"include_optional"` // Fill optional parameters in examples
+}
+
+// OnboardingManifestTool is one tool in an onboarding manifest
+type OnboardingManifestTool struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Version     string             `json:"version,omitempty"`
+	InputSchema map[string]any     `json:"input_schema,omitempty"`
+	Deprecation *types.Deprecation `json:"deprecation,omitempty"`
+}
+
+// OnboardingManifest lists the tools a new agent may use
+type OnboardingManifest struct {
+	Group       string                   `json:"group"`
+	GeneratedAt time.Time                `json:"generated_at"`
+	Tools       []OnboardingManifestTool `json:"tools"`
+}
+
+// OnboardingExample is a ready-to-send invocation of one tool
+type OnboardingExample struct {
+	Tool       string         `json:"tool"`
+	Parameters map[string]any `json:"parameters"`
+	Curl       string         `json:"curl"`
+}
+
+// OnboardingClientConfig is the client configuration file of a bundle
+type OnboardingClientConfig struct {
+	RESTURL     string                `json:"rest_url"`
+	GRPCAddress string                `json:"grpc_address"`
+	Agent       OnboardingAgent       `json:"agent"`
+	Tools       []string              `json:"tools"`
+	Credential  *OnboardingCredential `json:"credential,omitempty"` // Nil when the server requires none
+}
+
+// OnboardingAgent holds the registration fields of a new agent
+type OnboardingAgent struct {
+	AgentID   string `json:"agent_id"`
+	AgentName string `json:"agent_name"`
+}
+
+// onboardingBundle holds everything needed to render an onboarding zip
+type onboardingBundle struct {
+	group       string
+	request     OnboardingRequest
+	tools       []types.Tool
+	restURL     string
+	grpcAddress string
+	credential  *OnboardingCredential
+	generatedAt time.Time
+}
+
+// zip renders the bundle as a zip archive under a single top-level directory
+func (b onboardingBundle) zip() ([]byte, error) {
+	sort.Slice(b.tools, func(i, j int) bool { return b.tools[i].Name() < b.tools[j].Name() })
+
+	manifest := OnboardingManifest{Group: b.group, GeneratedAt: b.generatedAt, Tools: make([]OnboardingManifestTool, 0, len(b.tools))}
+	examples := make([]OnboardingExample, 0, len(b.tools))
+	names := make([]string, 0, len(b.tools))
+	for _, tool := range b.tools {
+		metadata := tool.Metadata()
+		input, _ := metadata.Schema["input"].(map[string]any)
+		manifest.Tools = append(manifest.Tools, OnboardingManifestTool{
+			Name:        metadata.Name,
+			Description: metadata.Description,
+			Version:     metadata.Version,
+			InputSchema: input,
+			Deprecation: metadata.Deprecation,
+		})
+		parameters := exampleParameters(tool, b.request.IncludeOptional)
+		examples = append(examples, OnboardingExample{
+			Tool:       metadata.Name,
+			Parameters: parameters,
+			Curl:       b.curl(metadata.Name, parameters),
+		})
+		names = append(names, metadata.Name)
+	}
+
+	client := OnboardingClientConfig{
+		RESTURL:     b.restURL,
+		GRPCAddress: b.grpcAddress,
+		Agent: OnboardingAgent{
+			AgentID:   onboardingSlug(b.request.AgentName),
+			AgentName: b.request.AgentName,
+		},
+		Tools:      names,
+		Credential: b.credential,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	root := "aionmcp-onboarding-" + onboardingSlug(b.group) + "/"
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"manifest.json", manifest},
+		{"examples.json", examples},
+		{"aionmcp-client.json", client},
+	}
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", file.name, err)
+		}
+		if err := writeZipFile(archive, root+file.name, b.generatedAt, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeZipFile(archive, root+"README.md", b.generatedAt, []byte(b.readme())); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish onboarding bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// curl renders an agent invocation as a curl command; $SESSION_ID comes from registration
+func (b onboardingBundle) curl(tool string, parameters map[string]any) string {
+	parametersJSON, _ := json.Marshal(parameters)
+	body, _ := json.Marshal(map[string]any{"parameters_json": string(parametersJSON)})
+	command := fmt.Sprintf("curl -X POST %s/api/v1/agents/$SESSION_ID/tools/%s/invoke -H 'Content-Type: application/json'", b.restURL, tool)
+	if b.credential != nil {
+		command += fmt.Sprintf(" -H '%s: $AIONMCP_CREDENTIAL'", b.credential.Header)
+	}
+	return command + fmt.Sprintf(" -d '%s'", strings.ReplaceAll(string(body), "'", `'\''`))
+}
+
+// readme explains how to use the bundle
+func (b onboardingBundle) readme() string {
+	var readme strings.Builder
+	fmt.Fprintf(&readme, "# AionMCP onboarding: %s\n\n", b.group)
+	fmt.Fprintf(&readme, "Generated %s for agent %q with %d tools.\n\n", b.generatedAt.Format(time.RFC3339), b.request.AgentName, len(b.tools))
+	readme.WriteString("- `manifest.json` lists the tools and their input schemas\n")
+	readme.WriteString("- `examples.json` has an example invocation per tool\n")
+	readme.WriteString("- `aionmcp-client.json` is the client configuration\n\n")
+	if b.credential != nil {
+		fmt.Fprintf(&readme, "Send the credential from `aionmcp-client.json` in the `%s` header. Keep it secret: it is not shown again.\n\n", b.credential.Header)
+	} else {
+		readme.WriteString("The server does not require authentication, so the bundle contains no credential.\n\n")
+	}
+	readme.WriteString("Register the agent, then set `SESSION_ID` to the returned `session_id` to run the examples:\n\n")
+	fmt.Fprintf(&readme, "```bash\ncurl -X POST %s/api/v1/agents/register -H 'Content-Type: application/json' -d '{\"agent_id\": %q, \"agent_name\": %q}'\n```\n",
+		b.restURL, onboardingSlug(b.request.AgentName), b.request.AgentName)
+	return readme.String()
+}
+
+// writeZipFile adds one file to an archive
+func writeZipFile(archive *zip.Writer, name string, modified time.Time, data []byte) error {
+	writer, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to onboarding bundle: %w", name, err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to onboarding bundle: %w", name, err)
+	}
+	return nil
+}
+
+// onboardingSlug turns a name into a lowercase identifier safe for IDs and file names
+func onboardingSlug(name string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			slug.WriteRune(r)
+		default:
+			slug.WriteRune('-')
+		}
+	}
+	return slug.String()
+}
+
+// requestBaseURL reconstructs the URL clients used to reach the server
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	return scheme + "://" + r.Host
+}
+
+// setupOnboardingRoutes mounts the endpoint that generates onboarding bundles
+// for new agents of a spec source group
+func (s *Server) setupOnboardingRoutes(router *gin.Engine) {
+	router.POST("/api/v1/specs/groups/:group/onboarding", func(c *gin.Context) {
+		groupName := c.Param("group")
+		if _, exists := s.importerManager.GetGroup(groupName); !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+
+		var request OnboardingRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+				return
+			}
+		}
+		if request.AgentName == "" {
+			request.AgentName = groupName + "-agent"
+		}
+
+		var tools []types.Tool
+		var names []string
+		for _, metadata := range s.toolRegistry.ListTools() {
+			if s.importerManager.GetToolGroup(metadata.Name) != groupName {
+				continue
+			}
+			tool, err := s.toolRegistry.Get(metadata.Name)
+			if err != nil {
+				continue
+			}
+			tools = append(tools, tool)
+			names = append(names, metadata.Name)
+		}
+		sort.Strings(names)
+
+		bundle := onboardingBundle{
+			group:       groupName,
+			request:     request,
+			tools:       tools,
+			restURL:     requestBaseURL(c.Request),
+			grpcAddress: net.JoinHostPort(hostOnly(c.Request.Host), fmt.Sprint(viper.GetInt("server.grpc_port"))),
+			generatedAt: time.Now().UTC(),
+		}
+
+		// Issuing a credential changes server state
+		if s.credentials != nil {
+			if !checkWritable(c, s.readOnly) {
+				return
+			}
+			credential, err := s.credentials.IssueOnboardingCredential(request.AgentName, names)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to issue credential: %v", err)})
+				return
+			}
+			bundle.credential = credential
+		}
+
+		data, err := bundle.zip()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		s.logger.Info("Onboarding bundle generated",
+			zap.String("group", groupName),
+			zap.String("agent_name", request.AgentName),
+			zap.Int("tools", len(tools)),
+			zap.Bool("credential_issued", bundle.credential != nil))
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="aionmcp-onboarding-%s.zip"`, onboardingSlug(groupName)))
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "application/zip", data)
+	})
+}
+
+// hostOnly strips the port from a host[:port] value
+func hostOnly(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return hostport
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOnboardingBundle(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	echo, err := registry.Get("echo")
+	require.NoError(t, err)
+	status, err := registry.Get("status")
+	require.NoError(t, err)
+
+	bundle := onboardingBundle{
+		group:       "Payments Team",
+		request:     OnboardingRequest{AgentName: "Billing Bot", IncludeOptional: true},
+		tools:       []types.Tool{status, echo},
+		restURL:     "http://aionmcp.internal:8080",
+		grpcAddress: "aionmcp.internal:9090",
+		credential:  &OnboardingCredential{Header: "X-API-Key", Value: "secret-key", Scopes: []string{"tools:invoke"}},
+		generatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	data, err := bundle.zip()
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		files[file.Name] = content
+	}
+	root := "aionmcp-onboarding-payments-team/"
+	assert.Len(t, files, 4)
+	assert.Contains(t, files, root+"README.md")
+
+	var manifest OnboardingManifest
+	require.NoError(t, json.Unmarshal(files[root+"manifest.json"], &manifest))
+	require.Len(t, manifest.Tools, 2)
+	assert.Equal(t, "echo", manifest.Tools[0].Name)
+	assert.NotEmpty(t, manifest.Tools[0].InputSchema)
+
+	// Examples fill parameters and authenticate with the credential
+	var examples []OnboardingExample
+	require.NoError(t, json.Unmarshal(files[root+"examples.json"], &examples))
+	require.Len(t, examples, 2)
+	assert.Contains(t, examples[0].Parameters, "message")
+	assert.Contains(t, examples[0].Curl, "http://aionmcp.internal:8080/api/v1/agents/$SESSION_ID/tools/echo/invoke")
+	assert.Contains(t, examples[0].Curl, "X-API-Key: $AIONMCP_CREDENTIAL")
+
+	var client OnboardingClientConfig
+	require.NoError(t, json.Unmarshal(files[root+"aionmcp-client.json"], &client))
+	assert.Equal(t, "billing-bot", client.Agent.AgentID)
+	assert.Equal(t, "aionmcp.internal:9090", client.GRPCAddress)
+	assert.Equal(t, []string{"echo", "status"}, client.Tools)
+	require.NotNil(t, client.Credential)
+	assert.Equal(t, "secret-key", client.Credential.Value)
+
+	// Without authentication the bundle says so instead of carrying a credential
+	bundle.credential = nil
+	data, err = bundle.zip()
+	require.NoError(t, err)
+	archive, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	for _, file := range archive.File {
+		if file.Name == root+"README.md" {
+			reader, err := file.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "does not require authentication")
+		}
+	}
+}
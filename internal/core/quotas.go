@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"go.uber.org/zap"
+)
+
+// quotaInsightRecorder returns a QuotaExhaustedHandler that raises an insight
+// once a quota has been exhausted threshold times within the exhaustion window
+func quotaInsightRecorder(learningEngine *selflearn.Engine, threshold int, logger *zap.Logger) importer.QuotaExhaustedHandler {
+	return func(status importer.QuotaStatus) {
+		logger.Warn("Upstream quota exhausted",
+			zap.String("source_id", status.SourceID),
+			zap.String("credential", status.Credential),
+			zap.Int64("limit", status.Limit),
+			zap.Time("reset_at", status.ResetAt))
+
+		// Only the exhaustion that crosses the threshold raises an insight
+		if threshold <= 0 || status.RecentExhaustions != threshold {
+			return
+		}
+		if err := learningEngine.RecordInsight(context.Background(), quotaInsight(status)); err != nil {
+			logger.Error("Failed to record quota insight",
+				zap.String("source_id", status.SourceID),
+				zap.Error(err))
+		}
+	}
+}
+
+// quotaInsight describes an upstream quota that is routinely exhausted
+func quotaInsight(status importer.QuotaStatus) selflearn.Insight {
+	return selflearn.Insight{
+		Type:     selflearn.InsightTypeConfiguration,
+		Priority: selflearn.PriorityHigh,
+		Title:    fmt.Sprintf("Upstream quota routinely exhausted for %s", status.SourceID),
+		Description: fmt.Sprintf("The %s quota of source %s (credential %s) has run out %d times recently",
+			formatQuotaLimit(status.Limit), status.SourceID, status.Credential, status.RecentExhaustions),
+		Suggestion: "Request a higher quota, spread calls across credentials or lower the source's requests_per_second limit",
+		Evidence: []string{
+			fmt.Sprintf("Exhaustions since tracking began: %d", status.Exhaustions),
+			fmt.Sprintf("Calls rejected while exhausted: %d", status.Rejected),
+			fmt.Sprintf("Calls paced near exhaustion: %d", status.Paced),
+		},
+		Metadata: map[string]string{
+			"source_id":          status.SourceID,
+			"credential":         status.Credential,
+			"limit":              strconv.FormatInt(status.Limit, 10),
+			"recent_exhaustions": strconv.Itoa(status.RecentExhaustions),
+		},
+	}
+}
+
+// formatQuotaLimit describes a quota limit, which some upstreams do not report
+func formatQuotaLimit(limit int64) string {
+	if limit <= 0 {
+		return "upstream"
+	}
+	return fmt.Sprintf("%d-call", limit)
+}
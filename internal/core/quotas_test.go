@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAgentDailyQuotas(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("auth.api_keys.enabled", true)
+	viper.Set("auth.api_keys.bootstrap_key", "quota-admin-key")
+	viper.Set("agent.quotas.tool_costs", []map[string]interface{}{{"tool": "ech*", "cost": 3}})
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	call := func(method, path, body string) (int, string) {
+		request, err := http.NewRequest(method, httpServer.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set(apikey.Header, "quota-admin-key")
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, string(data)
+	}
+
+	code, body := call("POST", "/api/v1/admin/apikeys", `{"name": "metered", "scopes": ["tools:invoke"], "quota": {"max_cost_per_day": 5}}`)
+	require.Equal(t, http.StatusCreated, code)
+	var created struct {
+		Key apikey.Key `json:"key"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+	require.NotNil(t, created.Key.Quota)
+	assert.Equal(t, 5.0, created.Key.Quota.MaxCostPerDay)
+
+	// Sessions of the key share its quota at the configured tool costs
+	ctx := agent.WithIdentity(context.Background(), agent.Identity{Subject: apiKeySubjectPrefix + created.Key.ID})
+	session, err := server.agentServer.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "metered", AgentName: "Metered"})
+	require.NoError(t, err)
+	invoke := &agentpb.InvokeToolRequest{SessionId: session.SessionId, ToolName: "echo", ParametersJson: `{"message": "hi"}`}
+	_, err = server.agentServer.InvokeTool(ctx, invoke)
+	require.NoError(t, err)
+	_, err = server.agentServer.InvokeTool(ctx, invoke)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	heartbeat, err := server.agentServer.HeartBeat(ctx, &agentpb.HeartBeatRequest{SessionId: session.SessionId})
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, heartbeat.Limits.CostRemainingToday)
+
+	// Lifting the key's quota applies to its running sessions
+	code, _ = call("PUT", "/api/v1/admin/apikeys/"+created.Key.ID+"/quota", `{"quota": {"max_cost_per_day": -1}}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = call("PUT", "/api/v1/admin/apikeys/"+created.Key.ID+"/quota", `{"quota": null}`)
+	require.Equal(t, http.StatusOK, code)
+	_, err = server.agentServer.InvokeTool(ctx, invoke)
+	assert.NoError(t, err)
+
+	// Operators can cap a single session
+	code, body = call("PUT", "/api/v1/agents/admin/sessions/"+session.SessionId+"/quota", `{"quota": {"max_invocations_per_day": 2}}`)
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Contains(t, body, `"invocations_remaining_today":"0"`)
+	_, err = server.agentServer.InvokeTool(ctx, invoke)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	code, _ = call("PUT", "/api/v1/agents/admin/sessions/unknown/quota", `{"quota": null}`)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	viper.Set("agent.quotas.tool_costs", []map[string]interface{}{{"tool": "[", "cost": 1}})
+	_, err = NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	assert.Error(t, err)
+}
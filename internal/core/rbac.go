@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/rbac"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// toolAccess decides which tools callers see and invoke by their roles.
+// Callers without roles, such as unauthenticated or stdio clients, hold the
+// default roles. A nil toolAccess allows every tool.
+type toolAccess struct {
+	roles    *rbac.Store
+	defaults []string
+	registry *ToolRegistry
+}
+
+// openToolAccess opens the role store and seeds it with configured roles,
+// returning nil while RBAC is disabled
+func openToolAccess(registry *ToolRegistry, logger *zap.Logger) (*toolAccess, error) {
+	if !viper.GetBool("rbac.enabled") {
+		return nil, nil
+	}
+
+	var configured []rbac.Role
+	if err := viper.UnmarshalKey("rbac.roles", &configured); err != nil {
+		return nil, fmt.Errorf("invalid rbac.roles: %w", err)
+	}
+
+	path := viper.GetString("rbac.path")
+	if path == "" {
+		path = "./data/rbac.db"
+	}
+	var store *rbac.Store
+	if inMemoryStorage() {
+		path = storageTypeMemory
+		store = rbac.OpenMemory()
+	} else {
+		var err error
+		if store, err = rbac.Open(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Configured roles replace stored roles of the same name on every start
+	for _, role := range configured {
+		if _, err := store.Put(role); err != nil {
+			store.Close()
+			return nil, fmt.Errorf("invalid role %q in rbac.roles: %w", role.Name, err)
+		}
+	}
+
+	logger.Info("Role-based tool access enabled",
+		zap.String("path", path),
+		zap.Int("roles", len(store.List())),
+		zap.Strings("default_roles", viper.GetStringSlice("rbac.default_roles")))
+	return &toolAccess{roles: store, defaults: viper.GetStringSlice("rbac.default_roles"), registry: registry}, nil
+}
+
+// allows reports whether holders of roles may use a tool registered from source
+func (a *toolAccess) allows(roles []string, tool ToolMetadata, source string) bool {
+	if a == nil {
+		return true
+	}
+	if len(roles) == 0 {
+		roles = a.defaults
+	}
+	return a.roles.Allowed(roles, rbac.Tool{Name: tool.Name, Source: source, Tags: tool.Tags})
+}
+
+// allowsInContext reports whether the caller of a request may use a tool
+func (a *toolAccess) allowsInContext(ctx context.Context, tool ToolMetadata) bool {
+	if a == nil {
+		return true
+	}
+	source, _ := a.registry.GetSource(tool.Name)
+	return a.allows(rolesInContext(ctx), tool, source)
+}
+
+// tenantScope returns the tenants whose learning data the caller of a
+// request sees
+func (a *toolAccess) tenantScope(ctx context.Context) selflearn.TenantScope {
+	if a == nil {
+		return selflearn.TenantScope{}
+	}
+	roles := rolesInContext(ctx)
+	if len(roles) == 0 {
+		roles = a.defaults
+	}
+	tenants, restricted := a.roles.Tenants(roles)
+	return selflearn.TenantScope{Restricted: restricted, Tenants: tenants}
+}
+
+// rolesInContext returns the roles of the caller of a request
+func rolesInContext(ctx context.Context) []string {
+	if identity, ok := agent.IdentityFromContext(ctx); ok {
+		return identity.Roles
+	}
+	return nil
+}
+
+// filter returns the tools the caller of a request may use
+func (a *toolAccess) filter(ctx context.Context, tools []ToolMetadata) []ToolMetadata {
+	if a == nil {
+		return tools
+	}
+	allowed := make([]ToolMetadata, 0, len(tools))
+	for _, tool := range tools {
+		if a.allowsInContext(ctx, tool) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// close releases the role store
+func (a *toolAccess) close() error {
+	if a == nil {
+		return nil
+	}
+	return a.roles.Close()
+}
+
+// setupRoleRoutes mounts role administration, which requires the admin scope
+func (s *Server) setupRoleRoutes(router *gin.Engine) {
+	roles := router.Group("/api/v1/admin/rbac/roles")
+
+	roles.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"roles": s.access.roles.List(), "default_roles": s.access.defaults})
+	})
+
+	roles.GET("/:name", func(c *gin.Context) {
+		role, err := s.access.roles.Get(c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, role)
+	})
+
+	// Creates or replaces a role; the path names it
+	roles.PUT("/:name", requireWritable(s.readOnly), func(c *gin.Context) {
+		var role rbac.Role
+		if err := c.ShouldBindJSON(&role); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		role.Name = c.Param("name")
+
+		stored, err := s.access.roles.Put(role)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		s.logger.Info("Role updated",
+			zap.String("role", stored.Name),
+			zap.Int("allow_rules", len(stored.Allow)),
+			zap.Int("deny_rules", len(stored.Deny)))
+		c.JSON(http.StatusOK, stored)
+	})
+
+	roles.DELETE("/:name", requireWritable(s.readOnly), func(c *gin.Context) {
+		name := c.Param("name")
+		if err := s.access.roles.Delete(name); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, rbac.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		s.logger.Warn("Role deleted", zap.String("role", name))
+		c.JSON(http.StatusOK, gin.H{"deleted": name})
+	})
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRoleTenantScopes(t *testing.T) {
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	viper.Set("auth.api_keys.enabled", true)
+	viper.Set("auth.api_keys.bootstrap_key", "tenant-admin-key")
+	viper.Set("rbac.enabled", true)
+	viper.Set("rbac.default_roles", []string{"operator"})
+	viper.Set("rbac.roles", []map[string]interface{}{
+		{"name": "operator", "allow": []map[string]interface{}{{}}},
+		{"name": "billing-team", "allow": []map[string]interface{}{{}}, "tenants": []string{"billing"}},
+	})
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	handler := server.Handler()
+	call := func(method, path, key, body string) (int, string) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(apikey.Header, key)
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code, recorder.Body.String()
+	}
+
+	code, body := call("POST", "/api/v1/admin/apikeys", "tenant-admin-key", `{"name": "billing", "scopes": ["tools:invoke"], "roles": ["billing-team"]}`)
+	require.Equal(t, http.StatusCreated, code)
+	var created struct {
+		APIKey string `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+
+	// Learning endpoints scope stats, insights and exports to the tenants of
+	// the caller's roles
+	ctx := context.Background()
+	engine := server.learningEngine
+	require.NoError(t, engine.RecordExecution(selflearn.WithTenant(ctx, "billing"), "echo", "builtin", nil, nil, nil, time.Millisecond))
+	require.NoError(t, engine.RecordExecution(selflearn.WithTenant(ctx, "iam"), "status", "builtin", nil, nil, errors.New("denied"), time.Millisecond))
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	require.Eventually(t, func() bool {
+		stats, err := engine.GetStats(ctx)
+		return err == nil && stats.TotalExecutions == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, engine.RecordInsight(ctx, selflearn.Insight{ID: "insight_iam", Type: selflearn.InsightTypeOptimization, Priority: selflearn.PriorityHigh, Metadata: map[string]string{"tool_name": "status", "tenant": "iam"}}))
+	require.NoError(t, engine.RecordInsight(ctx, selflearn.Insight{ID: "insight_echo", Type: selflearn.InsightTypeOptimization, Priority: selflearn.PriorityHigh, Metadata: map[string]string{"tool_name": "echo"}}))
+	require.NoError(t, engine.RecordInsight(ctx, selflearn.Insight{ID: "insight_system", Type: selflearn.InsightTypeConfiguration, Priority: selflearn.PriorityHigh, Metadata: map[string]string{"source_type": "system_stats"}}))
+
+	// Roles without tenants see every tenant
+	var stats selflearn.LearningStats
+	code, body = call("GET", "/api/v1/learning/stats", "tenant-admin-key", "")
+	require.Equal(t, http.StatusOK, code)
+	require.NoError(t, json.Unmarshal([]byte(body), &stats))
+	assert.EqualValues(t, 3, stats.TotalExecutions)
+	assert.Len(t, stats.ActiveInsights, 3)
+
+	// Restricted roles see their tenants and shared data only
+	code, body = call("GET", "/api/v1/learning/stats", created.APIKey, "")
+	require.Equal(t, http.StatusOK, code)
+	stats = selflearn.LearningStats{}
+	require.NoError(t, json.Unmarshal([]byte(body), &stats))
+	assert.EqualValues(t, 2, stats.TotalExecutions)
+	require.Len(t, stats.ActiveInsights, 1)
+	assert.Equal(t, "insight_echo", stats.ActiveInsights[0].ID)
+
+	code, body = call("GET", "/api/v1/learning/insights", created.APIKey, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "insight_echo")
+	assert.NotContains(t, body, "insight_iam")
+	assert.NotContains(t, body, "insight_system")
+
+	code, body = call("GET", "/api/v1/learning/executions/export?columns=tool,outcome", created.APIKey, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, strings.Count(body, "echo"))
+	assert.NotContains(t, body, "status")
+}
+
+func TestRoleBasedToolAccess(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("auth.api_keys.enabled", true)
+	viper.Set("auth.api_keys.bootstrap_key", "rbac-admin-key")
+	viper.Set("rbac.enabled", true)
+	viper.Set("rbac.default_roles", []string{"operator"})
+	viper.Set("rbac.roles", []map[string]interface{}{
+		{"name": "operator", "allow": []map[string]interface{}{{}}},
+		{"name": "tester", "allow": []map[string]interface{}{{"tags": []string{"test"}}}, "deny": []map[string]interface{}{{"tools": []string{"status"}}}},
+	})
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	call := func(method, path, key, body string) (int, string) {
+		request, err := http.NewRequest(method, httpServer.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set(apikey.Header, key)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, string(data)
+	}
+
+	// Callers without roles hold the default roles
+	code, body := call("GET", "/api/v1/mcp/tools", "rbac-admin-key", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"status"`)
+
+	// Roles are managed by admins and assigned to keys
+	code, _ = call("PUT", "/api/v1/admin/rbac/roles/status-only", "rbac-admin-key", `{"allow": [{"tools": ["sta*"], "sources": ["builtin"]}]}`)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = call("PUT", "/api/v1/admin/rbac/roles/broken", "rbac-admin-key", `{"allow": [{"tools": ["["]}]}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, body = call("GET", "/api/v1/admin/rbac/roles", "rbac-admin-key", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, strings.Count(body, `"updated_at"`))
+
+	code, body = call("POST", "/api/v1/admin/apikeys", "rbac-admin-key", `{"name": "monitor", "scopes": ["tools:invoke"], "roles": ["status-only"]}`)
+	require.Equal(t, http.StatusCreated, code)
+	var created struct {
+		Key    apikey.Key `json:"key"`
+		APIKey string     `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+	assert.Equal(t, []string{"status-only"}, created.Key.Roles)
+	code, _ = call("GET", "/api/v1/admin/rbac/roles", created.APIKey, "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// REST and JSON-RPC listings and invocations honour the key's roles
+	code, body = call("GET", "/api/v1/mcp/tools", created.APIKey, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"status"`)
+	assert.NotContains(t, body, `"echo"`)
+	code, _ = call("POST", "/api/v1/mcp/tools/status/invoke", created.APIKey, `{}`)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = call("POST", "/api/v1/mcp/tools/echo/invoke", created.APIKey, `{}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = call("POST", "/api/v1/mcp/tools/status/smoke", created.APIKey, `{"mode": "live"}`)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = call("POST", "/api/v1/mcp/tools/echo/smoke", created.APIKey, `{"mode": "live"}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, body = call("POST", "/api/v1/admin/apikeys", "rbac-admin-key", `{"name": "restricted-admin", "scopes": ["admin"], "roles": ["status-only"]}`)
+	require.Equal(t, http.StatusCreated, code)
+	var restricted struct {
+		APIKey string `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &restricted))
+	code, _ = call("POST", "/api/v1/admin/tools/compare", restricted.APIKey, `{"left_tool": "status", "right_tool": "echo", "dry_run": true}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = call("POST", "/api/v1/admin/tools/compare", restricted.APIKey, `{"left_tool": "status", "right_tool": "status", "dry_run": true}`)
+	assert.Equal(t, http.StatusOK, code)
+	code, body = call("POST", "/mcp", created.APIKey, `{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, body, `"echo"`)
+	code, body = call("POST", "/mcp", created.APIKey, `{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "echo"}}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "tool not found")
+
+	// Agent sessions carry the roles of the identity that registered them
+	ctx := agent.WithIdentity(context.Background(), agent.Identity{Subject: "svc", Roles: []string{"Tester"}})
+	session, err := server.agentServer.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "tester", AgentName: "Tester"})
+	require.NoError(t, err)
+	require.Len(t, session.AvailableTools, 1)
+	assert.Equal(t, "echo", session.AvailableTools[0].Name)
+	_, err = server.agentServer.GetTool(ctx, &agentpb.GetToolRequest{SessionId: session.SessionId, ToolName: "status"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.agentServer.InvokeTool(ctx, &agentpb.InvokeToolRequest{SessionId: session.SessionId, ToolName: "status", ParametersJson: "{}"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Deleting a role withdraws the access it granted
+	code, _ = call("DELETE", "/api/v1/admin/rbac/roles/status-only", "rbac-admin-key", "")
+	require.Equal(t, http.StatusOK, code)
+	code, _ = call("POST", "/api/v1/mcp/tools/status/invoke", created.APIKey, `{}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = call("DELETE", "/api/v1/admin/rbac/roles/status-only", "rbac-admin-key", "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
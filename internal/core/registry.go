@@ -1,10 +1,13 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/contextvars"
+	"github.com/aionmcp/aionmcp/pkg/metrics"
 	"github.com/aionmcp/aionmcp/pkg/types"
 	"go.uber.org/zap"
 )
@@ -58,6 +61,19 @@ type ToolRegistry struct {
 	nextHandlerID    int
 	logger           *zap.Logger
 	handlerSemaphore chan struct{} // Limits concurrent event handler executions
+	validation       ValidationConfig
+	timeouts         TimeoutConfig
+	adaptive         map[string]*types.AdaptiveTuning // Learned timeouts and retry budgets by tool name
+	retryBackoff     RetryBackoff
+	deprecations     map[string]*types.Deprecation // Configured overlays by tool name
+	aliases          *toolAliases
+	statuses         map[string]ToolState // Disabled and deprecated tools by name
+	index            *toolIndex
+	circuits         *circuitBreakers
+	cache            *ResultCache       // Nil while result caching is disabled
+	watchdog         *Watchdog          // Nil while the watchdog is disabled
+	metrics          *metrics.Metrics   // Nil while metrics are disabled
+	contextVars      *contextvars.Store // Nil while parameter templating is disabled
 }
 
 // NewToolRegistry creates a new tool registry with dynamic capabilities
@@ -70,6 +86,11 @@ func NewToolRegistry(logger *zap.Logger) *ToolRegistry {
 		nextHandlerID:    1,
 		logger:           logger,
 		handlerSemaphore: make(chan struct{}, DefaultMaxConcurrentHandlers),
+		validation:       DefaultValidationConfig(),
+		circuits:         newCircuitBreakers(logger),
+		aliases:          newToolAliases(),
+		statuses:         make(map[string]ToolState),
+		index:            newToolIndex(),
 	}
 
 	// Register built-in tools for iteration 0
@@ -105,6 +126,7 @@ func (r *ToolRegistry) RegisterWithSource(tool Tool, sourceID, version string) e
 	r.tools[name] = tool
 	r.versions[name] = version
 	r.sources[name] = sourceID
+	r.index.add(name, sourceID, tool.Metadata())
 
 	r.logger.Info("Tool registered",
 		zap.String("tool", name),
@@ -153,6 +175,7 @@ func (r *ToolRegistry) RegisterBatch(tools []Tool, sourceID string) error {
 		r.tools[name] = tool
 		r.versions[name] = metadata.Version
 		r.sources[name] = sourceID
+		r.index.add(name, sourceID, metadata)
 
 		events = append(events, ToolRegistryEvent{
 			Type:      eventType,
@@ -181,10 +204,8 @@ func (r *ToolRegistry) UnregisterBySource(sourceID string) error {
 	r.mu.Lock()
 
 	var removedTools []string
-	for name, source := range r.sources {
-		if source == sourceID {
-			removedTools = append(removedTools, name)
-		}
+	for name := range r.index.bySource(sourceID) {
+		removedTools = append(removedTools, name)
 	}
 
 	var events []ToolRegistryEvent
@@ -193,6 +214,8 @@ func (r *ToolRegistry) UnregisterBySource(sourceID string) error {
 		delete(r.tools, name)
 		delete(r.versions, name)
 		delete(r.sources, name)
+		r.index.remove(name)
+		r.circuits.remove(name)
 
 		r.logger.Info("Tool unregistered by source",
 			zap.String("tool", name),
@@ -234,6 +257,8 @@ func (r *ToolRegistry) Unregister(name string) error {
 	delete(r.tools, name)
 	delete(r.versions, name)
 	delete(r.sources, name)
+	r.index.remove(name)
+	r.circuits.remove(name)
 
 	r.logger.Info("Tool unregistered", zap.String("tool", name))
 
@@ -252,32 +277,173 @@ func (r *ToolRegistry) Unregister(name string) error {
 	return nil
 }
 
-// Get retrieves a tool by name
+// SetValidation configures schema validation of tools returned by Get
+func (r *ToolRegistry) SetValidation(config ValidationConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validation = config
+}
+
+// SetTimeouts configures the invocation timeouts of tools returned by Get
+func (r *ToolRegistry) SetTimeouts(config TimeoutConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeouts = config
+}
+
+// SetToolTimeout overrides the invocation timeout of one tool
+func (r *ToolRegistry) SetToolTimeout(name string, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tools := make(map[string]time.Duration, len(r.timeouts.Tools)+1)
+	for tool, override := range r.timeouts.Tools {
+		tools[tool] = override
+	}
+	tools[name] = timeout
+	r.timeouts.Tools = tools
+}
+
+// ToolTimeout returns the effective invocation timeout of a tool; zero means none
+func (r *ToolRegistry) ToolTimeout(name string) time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.timeoutOf(name)
+}
+
+// timeoutOf resolves the timeout of a tool: a configured per-tool timeout,
+// else the adapted one, else the global default
+func (r *ToolRegistry) timeoutOf(name string) time.Duration {
+	if timeout, ok := r.timeouts.Tools[name]; ok {
+		return timeout
+	}
+	if tuning := r.adaptive[name]; tuning != nil && tuning.TimeoutMs > 0 {
+		return time.Duration(tuning.TimeoutMs) * time.Millisecond
+	}
+	return r.timeouts.Default
+}
+
+// SetAdaptiveTuning replaces the learned timeouts and retry budgets of tools
+// returned by Get, keyed by tool name
+func (r *ToolRegistry) SetAdaptiveTuning(tunings map[string]*types.AdaptiveTuning) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adaptive = tunings
+}
+
+// SetRetryBackoff configures the wait between adaptive retries
+func (r *ToolRegistry) SetRetryBackoff(backoff RetryBackoff) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retryBackoff = backoff
+}
+
+// SetDeprecations configures deprecations that overlay those declared by specs
+func (r *ToolRegistry) SetDeprecations(deprecations map[string]*types.Deprecation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deprecations = deprecations
+}
+
+// SetCircuitBreakers configures the circuit breakers of tools returned by Get,
+// resetting the state of all circuits
+func (r *ToolRegistry) SetCircuitBreakers(config CircuitBreakerConfig) {
+	r.circuits.configure(config)
+}
+
+// SetResultCache serves repeated invocations of idempotent tools returned by
+// Get from cache. A nil cache disables caching.
+func (r *ToolRegistry) SetResultCache(cache *ResultCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = cache
+}
+
+// SetWatchdog force-cancels invocations of tools returned by Get that run
+// past the watchdog's maximum runtime. A nil watchdog disables it.
+func (r *ToolRegistry) SetWatchdog(watchdog *Watchdog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.watchdog = watchdog
+}
+
+// ResultCacheStats reports the result cache hit ratios, or nil while caching is disabled
+func (r *ToolRegistry) ResultCacheStats() *ResultCacheStats {
+	r.mu.RLock()
+	cache := r.cache
+	r.mu.RUnlock()
+	if cache == nil {
+		return nil
+	}
+	stats := cache.Stats()
+	return &stats
+}
+
+// Get retrieves a tool by name or alias. Invocations through the returned tool are
+// validated against its input and output schemas, bounded by the tool's
+// timeout and the watchdog's maximum runtime, retried within its adaptive
+// retry budget, guarded by its circuit breaker
+// and served from the result cache as configured, parameter templates are
+// resolved from the context variables, and its metadata includes
+// any configured deprecation, the adaptive tuning and the circuit state. Invocations are recorded in the server metrics.
+// Tools an operator disabled return an error wrapping ErrToolDisabled.
 func (r *ToolRegistry) Get(name string) (Tool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	tool, exists := r.tools[name]
 	if !exists {
-		return nil, fmt.Errorf("tool '%s' not found", name)
+		target, aliased := r.aliases.resolve(name)
+		if tool, exists = r.tools[target]; !aliased || !exists {
+			return nil, fmt.Errorf("tool '%s' not found", name)
+		}
+		name = target
+	}
+	if r.disabled(name) {
+		return nil, fmt.Errorf("tool '%s': %w", name, ErrToolDisabled)
 	}
 
-	return tool, nil
+	tool = withDeprecation(tool, r.deprecationOf(name))
+	tool = withTimeout(tool, r.timeoutOf(name))
+	tool = withAdaptiveTuning(tool, r.adaptiveTuningOf(name), r.retryBackoff)
+	tool = withWatchdog(tool, r.watchdog)
+	tool = withCircuitBreaker(tool, r.circuits.forTool(name))
+	tool = withCache(tool, r.cache)
+	tool = withValidation(tool, r.validation)
+	tool = withContextVars(tool, r.contextVars)
+	return withMetrics(tool, r.metrics, r.sources[name]), nil
 }
 
-// ListTools returns metadata for all registered tools
+// ListTools returns metadata for all registered tools that are not disabled
 func (r *ToolRegistry) ListTools() []ToolMetadata {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	tools := make([]ToolMetadata, 0, len(r.tools))
-	for _, tool := range r.tools {
-		tools = append(tools, tool.Metadata())
+	for name, tool := range r.tools {
+		if r.disabled(name) {
+			continue
+		}
+		tools = append(tools, r.listedMetadata(name, tool))
 	}
 
 	return tools
 }
 
+// listedMetadata returns the metadata of a tool with the overlays applied by Get
+func (r *ToolRegistry) listedMetadata(name string, tool Tool) ToolMetadata {
+	tool = withDeprecation(tool, r.deprecationOf(name))
+	tool = withAdaptiveTuning(tool, r.adaptiveTuningOf(name), r.retryBackoff)
+	metadata := withCircuitBreaker(tool, r.circuits.forTool(name)).Metadata()
+	metadata.Aliases = r.aliases.of(name)
+	return metadata
+}
+
+// HandlerUsage reports how many event handlers are running and how many may
+// run at once
+func (r *ToolRegistry) HandlerUsage() (running, capacity int) {
+	return len(r.handlerSemaphore), cap(r.handlerSemaphore)
+}
+
 // Count returns the number of registered tools
 func (r *ToolRegistry) Count() int {
 	r.mu.RLock()
@@ -309,17 +475,15 @@ func (r *ToolRegistry) GetSource(name string) (string, error) {
 	return source, nil
 }
 
-// ListToolsBySource returns tools from a specific source
+// ListToolsBySource returns the tools from a specific source that are not disabled
 func (r *ToolRegistry) ListToolsBySource(sourceID string) []ToolMetadata {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var tools []ToolMetadata
-	for name, source := range r.sources {
-		if source == sourceID {
-			if tool, exists := r.tools[name]; exists {
-				tools = append(tools, tool.Metadata())
-			}
+	for name := range r.index.bySource(sourceID) {
+		if tool, exists := r.tools[name]; exists && !r.disabled(name) {
+			tools = append(tools, r.listedMetadata(name, tool))
 		}
 	}
 	return tools
@@ -330,13 +494,8 @@ func (r *ToolRegistry) GetToolSources() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	sourceSet := make(map[string]bool)
-	for _, source := range r.sources {
-		sourceSet[source] = true
-	}
-
-	sources := make([]string, 0, len(sourceSet))
-	for source := range sourceSet {
+	sources := make([]string, 0, len(r.index.sources))
+	for source := range r.index.sources {
 		sources = append(sources, source)
 	}
 	return sources
@@ -465,7 +624,7 @@ func (t *EchoTool) Description() string {
 	return "Echoes back the input message for testing purposes"
 }
 
-func (t *EchoTool) Execute(input any) (any, error) {
+func (t *EchoTool) Execute(ctx context.Context, input any) (any, error) {
 	return map[string]any{
 		"echo":      input,
 		"timestamp": time.Now().Unix(),
@@ -509,7 +668,7 @@ func (t *StatusTool) Description() string {
 	return "Returns information about the tool registry and server status"
 }
 
-func (t *StatusTool) Execute(input any) (any, error) {
+func (t *StatusTool) Execute(ctx context.Context, input any) (any, error) {
 	return map[string]any{
 		"tool_count": t.registry.Count(),
 		"timestamp":  time.Now().Unix(),
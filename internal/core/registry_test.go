@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
@@ -27,7 +28,7 @@ func (t *TestTool) Description() string {
 	return t.description
 }
 
-func (t *TestTool) Execute(input any) (any, error) {
+func (t *TestTool) Execute(ctx context.Context, input any) (any, error) {
 	return map[string]any{
 		"tool":   t.name,
 		"input":  input,
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxRemediatedTimeout caps the timeouts increase_timeout remediations set
+const DefaultMaxRemediatedTimeout = 2 * time.Minute
+
+// Routes of the remediation approval queue, which require the admin scope
+const (
+	applyRemediationRoute  = "/api/v1/learning/insights/:id/apply"
+	rejectRemediationRoute = "/api/v1/learning/insights/:id/reject"
+)
+
+// errRemediationNotPending is returned when applying remediations that were
+// already applied or rejected
+var errRemediationNotPending = errors.New("remediations are not pending")
+
+// RemediationConfig controls automatic remediation. Remediations are always
+// queued for approval; with AutoApply those of insights of at least
+// MinPriority whose actions are all in Actions are applied right away.
+type RemediationConfig struct {
+	AutoApply   bool
+	MinPriority selflearn.Priority
+	Actions     map[selflearn.RemediationAction]bool
+	MaxTimeout  time.Duration // Zero selects DefaultMaxRemediatedTimeout
+}
+
+// remediator applies the remediations insights suggest
+type remediator struct {
+	registry *ToolRegistry
+	engine   *selflearn.Engine
+	config   RemediationConfig
+	logger   *zap.Logger
+}
+
+// newRemediator creates the remediator configured under learning.remediation
+func newRemediator(registry *ToolRegistry, engine *selflearn.Engine, logger *zap.Logger) (*remediator, error) {
+	config := RemediationConfig{
+		AutoApply:   viper.GetBool("learning.remediation.auto_apply"),
+		MinPriority: selflearn.Priority(viper.GetString("learning.remediation.min_priority")),
+		Actions:     make(map[selflearn.RemediationAction]bool),
+		MaxTimeout:  time.Duration(viper.GetInt("learning.remediation.max_timeout_ms")) * time.Millisecond,
+	}
+	switch config.MinPriority {
+	case "":
+		config.MinPriority = selflearn.PriorityCritical
+	case selflearn.PriorityLow, selflearn.PriorityMedium, selflearn.PriorityHigh, selflearn.PriorityCritical:
+	default:
+		return nil, fmt.Errorf("unknown learning.remediation.min_priority: %s", config.MinPriority)
+	}
+	for _, action := range viper.GetStringSlice("learning.remediation.actions") {
+		switch selflearn.RemediationAction(action) {
+		case selflearn.RemediationDisableTool, selflearn.RemediationIncreaseTimeout:
+			config.Actions[selflearn.RemediationAction(action)] = true
+		default:
+			return nil, fmt.Errorf("unknown remediation action in learning.remediation.actions: %s", action)
+		}
+	}
+	if config.MaxTimeout <= 0 {
+		config.MaxTimeout = DefaultMaxRemediatedTimeout
+	}
+	return &remediator{registry: registry, engine: engine, config: config, logger: logger}, nil
+}
+
+// autoApply is a selflearn.InsightHandler that applies the remediations of
+// new insights when automatic remediation allows them
+func (r *remediator) autoApply(insights []selflearn.Insight) {
+	if !r.config.AutoApply {
+		return
+	}
+	for _, insight := range insights {
+		if len(insight.Remediations) == 0 || !insight.Priority.AtLeast(r.config.MinPriority) || !r.allowed(insight.Remediations) {
+			continue
+		}
+		if _, err := r.apply(context.Background(), insight.ID, "auto"); err != nil {
+			r.logger.Warn("Automatic remediation failed", zap.String("insight_id", insight.ID), zap.Error(err))
+		}
+	}
+}
+
+// allowed reports whether every remediation may be applied automatically
+func (r *remediator) allowed(remediations []selflearn.Remediation) bool {
+	for _, remediation := range remediations {
+		if !r.config.Actions[remediation.Action] {
+			return false
+		}
+	}
+	return true
+}
+
+// apply carries out the pending or failed remediations of an insight and
+// records the outcome. The first failing remediation stops the rest.
+func (r *remediator) apply(ctx context.Context, id, approvedBy string) (selflearn.Insight, error) {
+	insight, err := r.engine.GetInsight(ctx, id)
+	if err != nil {
+		return insight, err
+	}
+	if len(insight.Remediations) == 0 {
+		return insight, fmt.Errorf("insight %s suggests no remediations: %w", id, errRemediationNotPending)
+	}
+	if insight.RemediationStatus != selflearn.RemediationPending && insight.RemediationStatus != selflearn.RemediationFailed {
+		return insight, fmt.Errorf("insight %s remediations are %s: %w", id, insight.RemediationStatus, errRemediationNotPending)
+	}
+
+	var done []string
+	for _, remediation := range insight.Remediations {
+		result, err := r.applyOne(remediation, insight)
+		if err != nil {
+			note := fmt.Sprintf("%s failed: %v", remediation.Action, err)
+			if updated, updateErr := r.engine.SetRemediationStatus(ctx, id, selflearn.RemediationFailed, note); updateErr == nil {
+				insight = updated
+			}
+			return insight, errors.New(note)
+		}
+		done = append(done, result)
+	}
+
+	note := strings.Join(done, "; ") + " (approved by " + approvedBy + ")"
+	r.logger.Info("Applied remediations",
+		zap.String("insight_id", id),
+		zap.String("approved_by", approvedBy),
+		zap.Strings("changes", done))
+	return r.engine.SetRemediationStatus(ctx, id, selflearn.RemediationApplied, note)
+}
+
+// applyOne carries out one remediation and describes the change
+func (r *remediator) applyOne(remediation selflearn.Remediation, insight selflearn.Insight) (string, error) {
+	switch remediation.Action {
+	case selflearn.RemediationDisableTool:
+		reason := fmt.Sprintf("Remediation of insight %s: %s", insight.ID, insight.Title)
+		if _, err := r.registry.SetToolStatus(remediation.Tool, ToolStatusDisabled, reason, nil); err != nil {
+			return "", err
+		}
+		return "disabled " + remediation.Tool, nil
+
+	case selflearn.RemediationIncreaseTimeout:
+		current := r.registry.ToolTimeout(remediation.Tool)
+		if current <= 0 {
+			return "", fmt.Errorf("tool %s has no timeout to increase", remediation.Tool)
+		}
+		if current >= r.config.MaxTimeout {
+			return "", fmt.Errorf("timeout of %s is already at the maximum of %s", remediation.Tool, r.config.MaxTimeout)
+		}
+		factor := remediation.Factor
+		if factor <= 1 {
+			factor = selflearn.DefaultTimeoutFactor
+		}
+		timeout := min(time.Duration(float64(current)*factor), r.config.MaxTimeout)
+		r.registry.SetToolTimeout(remediation.Tool, timeout)
+		return fmt.Sprintf("raised timeout of %s from %s to %s", remediation.Tool, current, timeout), nil
+	}
+	return "", fmt.Errorf("unsupported remediation action: %s", remediation.Action)
+}
+
+// setupRemediationRoutes mounts the remediation approval queue
+func (s *Server) setupRemediationRoutes(router *gin.Engine) {
+	// List insights by remediation status, pending by default
+	router.GET("/api/v1/learning/remediations", func(c *gin.Context) {
+		status := selflearn.RemediationStatus(c.DefaultQuery("status", string(selflearn.RemediationPending)))
+		insights, err := s.learningEngine.GetRemediations(c.Request.Context(), status, 100)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get remediations"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"insights":   s.learningEngine.InsightsForTenants(insights, s.access.tenantScope(c.Request.Context())),
+			"auto_apply": s.remediator.config.AutoApply,
+		})
+	})
+
+	// Approve and apply an insight's remediations
+	router.POST(applyRemediationRoute, func(c *gin.Context) {
+		id := c.Param("id")
+		c.Set(auditTargetKey, id)
+		if _, err := s.learningEngine.GetInsight(c.Request.Context(), id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("insight not found: %s", id)})
+			return
+		}
+		insight, err := s.remediator.apply(c.Request.Context(), id, "api")
+		if errors.Is(err, errRemediationNotPending) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "insight": insight})
+			return
+		}
+		c.JSON(http.StatusOK, insight)
+	})
+
+	// Decline an insight's pending remediations
+	router.POST(rejectRemediationRoute, func(c *gin.Context) {
+		id := c.Param("id")
+		c.Set(auditTargetKey, id)
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		insight, err := s.learningEngine.GetInsight(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("insight not found: %s", id)})
+			return
+		}
+		if len(insight.Remediations) == 0 || insight.RemediationStatus == selflearn.RemediationApplied {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("insight %s has no remediations to reject", id)})
+			return
+		}
+		insight, err = s.learningEngine.SetRemediationStatus(c.Request.Context(), id, selflearn.RemediationRejected, req.Reason)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject remediations"})
+			return
+		}
+		c.JSON(http.StatusOK, insight)
+	})
+}
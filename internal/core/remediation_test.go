@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerRemediation(t *testing.T) {
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("tools.timeout_ms", 1000)
+	viper.Set("learning.remediation.max_timeout_ms", 1500)
+	defer viper.Reset()
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx := context.Background()
+	record := func(id string, priority selflearn.Priority, remediation selflearn.Remediation) {
+		require.NoError(t, server.learningEngine.RecordInsight(ctx, selflearn.Insight{
+			ID:                id,
+			Type:              selflearn.InsightTypeReliability,
+			Priority:          priority,
+			Title:             "echo needs attention",
+			Metadata:          map[string]string{"tool_name": "echo"},
+			Remediations:      []selflearn.Remediation{remediation},
+			RemediationStatus: selflearn.RemediationPending,
+		}))
+	}
+	post := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+		return recorder
+	}
+	record("insight_timeout", selflearn.PriorityHigh, selflearn.Remediation{Action: selflearn.RemediationIncreaseTimeout, Tool: "echo", Factor: 2})
+	record("insight_disable", selflearn.PriorityCritical, selflearn.Remediation{Action: selflearn.RemediationDisableTool, Tool: "echo"})
+	record("insight_rejected", selflearn.PriorityHigh, selflearn.Remediation{Action: selflearn.RemediationDisableTool, Tool: "echo"})
+
+	// Remediations wait for approval unless auto_apply is set
+	var queue struct {
+		Insights []selflearn.Insight `json:"insights"`
+	}
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/learning/remediations", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &queue))
+	assert.Len(t, queue.Insights, 3)
+	assert.Equal(t, 1*time.Second, server.toolRegistry.ToolTimeout("echo"))
+
+	// Timeouts are raised up to the maximum
+	recorder = post("/api/v1/learning/insights/insight_timeout/apply")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var applied selflearn.Insight
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &applied))
+	assert.Equal(t, selflearn.RemediationApplied, applied.RemediationStatus)
+	assert.Contains(t, applied.RemediationNote, "raised timeout of echo from 1s to 1.5s")
+	assert.Equal(t, 1500*time.Millisecond, server.toolRegistry.ToolTimeout("echo"))
+	assert.Equal(t, http.StatusConflict, post("/api/v1/learning/insights/insight_timeout/apply").Code)
+
+	recorder = post("/api/v1/learning/insights/insight_rejected/reject")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, http.StatusConflict, post("/api/v1/learning/insights/insight_rejected/apply").Code)
+	assert.Equal(t, http.StatusNotFound, post("/api/v1/learning/insights/missing/apply").Code)
+
+	require.Equal(t, http.StatusOK, post("/api/v1/learning/insights/insight_disable/apply").Code)
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/mcp/tools/echo/invoke", strings.NewReader(`{"message": "hi"}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/learning/remediations?status=applied", nil))
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &queue))
+	assert.Len(t, queue.Insights, 2)
+}
+
+func TestServerAutoRemediation(t *testing.T) {
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("learning.remediation.auto_apply", true)
+	viper.Set("learning.remediation.min_priority", "critical")
+	viper.Set("learning.remediation.actions", []string{"disable_tool"})
+	defer viper.Reset()
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx := context.Background()
+	disable := []selflearn.Remediation{{Action: selflearn.RemediationDisableTool, Tool: "echo"}}
+	for id, priority := range map[string]selflearn.Priority{"insight_high": selflearn.PriorityHigh, "insight_critical": selflearn.PriorityCritical} {
+		require.NoError(t, server.learningEngine.RecordInsight(ctx, selflearn.Insight{
+			ID:                id,
+			Type:              selflearn.InsightTypeReliability,
+			Priority:          priority,
+			Title:             "echo fails",
+			Metadata:          map[string]string{"tool_name": "echo"},
+			Remediations:      disable,
+			RemediationStatus: selflearn.RemediationPending,
+		}))
+	}
+
+	// Only remediations of insights of at least the minimum priority are applied
+	critical, err := server.learningEngine.GetInsight(ctx, "insight_critical")
+	require.NoError(t, err)
+	assert.Equal(t, selflearn.RemediationApplied, critical.RemediationStatus)
+	assert.Contains(t, critical.RemediationNote, "approved by auto")
+	high, err := server.learningEngine.GetInsight(ctx, "insight_high")
+	require.NoError(t, err)
+	assert.Equal(t, selflearn.RemediationPending, high.RemediationStatus)
+	statuses := server.toolRegistry.ToolStatuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, ToolStatusDisabled, statuses[0].Status)
+
+	viper.Set("learning.remediation.actions", []string{"restart_everything"})
+	_, err = NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	assert.ErrorContains(t, err, "restart_everything")
+}
@@ -0,0 +1,600 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/demo"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultSelfTestTimeout bounds each self-test check
+	DefaultSelfTestTimeout = 30 * time.Second
+
+	// selfTestAgentID identifies the synthetic agent the self-test registers
+	selfTestAgentID = "aionmcp-selftest"
+
+	// selfTestOperation is the bundled petstore operation the self-test invokes
+	selfTestOperation = "listPets"
+
+	// selfTestParameters are the parameters it is invoked with, as JSON
+	selfTestParameters = `{"limit": 1}`
+
+	// selfTestPollInterval is how often the self-test re-checks state that
+	// settles asynchronously
+	selfTestPollInterval = 100 * time.Millisecond
+)
+
+// SelfTestOptions configures a self-test run
+type SelfTestOptions struct {
+	Target  string        // Base URL of a running instance on this host; empty boots a temporary one
+	APIKey  string        // Sent with every request when the target requires authentication
+	Timeout time.Duration // Bounds each check; zero selects DefaultSelfTestTimeout
+}
+
+// SelfTestCheck is the outcome of one self-test check
+type SelfTestCheck struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"` // A check it depends on failed
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// SelfTestReport is the outcome of a self-test run
+type SelfTestReport struct {
+	Target    string          `json:"target"`
+	Temporary bool            `json:"temporary"` // The target was booted for the run
+	Checks    []SelfTestCheck `json:"checks"`
+}
+
+// Passed reports whether every check passed
+func (r *SelfTestReport) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Write prints the report as a pass/fail table followed by a summary line
+func (r *SelfTestReport) Write(w io.Writer) {
+	target := r.Target
+	if r.Temporary {
+		target += " (temporary instance)"
+	}
+	fmt.Fprintf(w, "AionMCP self-test against %s\n\n", target)
+
+	width := 0
+	for _, check := range r.Checks {
+		if len(check.Name) > width {
+			width = len(check.Name)
+		}
+	}
+
+	var passed, failed, skipped int
+	for _, check := range r.Checks {
+		result := "PASS"
+		switch {
+		case check.Skipped:
+			result = "SKIP"
+			skipped++
+		case !check.Passed:
+			result = "FAIL"
+			failed++
+		default:
+			passed++
+		}
+		fmt.Fprintf(w, "  %s  %-*s  %8s  %s\n", result, width, check.Name, check.Duration.Round(time.Millisecond), check.Detail)
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+}
+
+// RunSelfTest verifies an installation end to end: it imports the bundled
+// petstore spec, registers a synthetic agent, invokes a tool synchronously
+// and asynchronously, follows the registry and agent event streams, and
+// checks that executions reach the learning engine and that documentation
+// is generated for the spec. Everything it creates is removed afterwards.
+//
+// Without a target a temporary instance is booted from the current
+// configuration with in-memory storage, so stored data is left untouched. A
+// target must run on this host, because the bundled spec and its mock
+// upstream are served from the self-test process.
+func RunSelfTest(ctx context.Context, options SelfTestOptions, logger *zap.Logger) (*SelfTestReport, error) {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultSelfTestTimeout
+	}
+
+	report := &SelfTestReport{Target: strings.TrimSuffix(options.Target, "/")}
+	if report.Target == "" {
+		viper.Set("storage.type", storageTypeMemory)
+		server, err := NewServerWithOptions(logger, ServerOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary instance: %w", err)
+		}
+		defer server.Close()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen for temporary instance: %w", err)
+		}
+		httpServer := &http.Server{Handler: server.Handler(), ReadHeaderTimeout: 10 * time.Second}
+		go httpServer.Serve(listener)
+		defer httpServer.Close()
+
+		report.Target = "http://" + listener.Addr().String()
+		report.Temporary = true
+	}
+
+	env, err := demo.Start(logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start bundled spec upstream: %w", err)
+	}
+	defer env.Close()
+
+	var source importer.SpecSource
+	for _, candidate := range env.Sources() {
+		if candidate.Type == importer.SpecTypeOpenAPI {
+			source = candidate
+		}
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate self-test source ID: %w", err)
+	}
+	// A unique ID keeps the run from colliding with specs already imported
+	source.ID = "selftest-petstore-" + hex.EncodeToString(suffix)
+
+	st := &selfTest{
+		ctx:     ctx,
+		options: options,
+		client:  &http.Client{},
+		baseURL: report.Target,
+		source:  source,
+		report:  report,
+	}
+	st.run()
+	return report, nil
+}
+
+// selfTest carries the state threaded between the checks of one run
+type selfTest struct {
+	ctx     context.Context
+	options SelfTestOptions
+	client  *http.Client
+	baseURL string
+	source  importer.SpecSource
+	report  *SelfTestReport
+	blocked string // Name of the failed check later checks depend on
+
+	toolEvents <-chan string // Types of registry events streamed for the source
+	stopEvents context.CancelFunc
+	imported   bool
+	sessionID  string
+	toolName   string
+	executions int64 // Learning engine executions before the run invoked anything
+}
+
+// run performs the checks in order. A failed check that later ones depend
+// on skips them, but cleanup always runs.
+func (st *selfTest) run() {
+	st.check("health", true, st.checkHealth)
+	st.check("import spec", true, st.checkImport)
+	st.check("registry event stream", false, st.checkToolEvents)
+	st.check("register agent", true, st.checkRegister)
+	st.check("list tools", true, st.checkListTools)
+	st.check("invoke tool", false, st.checkInvoke)
+	st.check("async invocation", false, st.checkAsyncInvoke)
+	st.check("agent event stream", false, st.checkAgentEvents)
+	st.check("learning recording", false, st.checkLearning)
+	st.check("doc generation", false, st.checkDocs)
+
+	st.blocked = ""
+	st.check("cleanup", false, st.cleanup)
+}
+
+// check runs one check under the per-check timeout and records its outcome
+func (st *selfTest) check(name string, required bool, fn func(ctx context.Context) (string, error)) {
+	if st.blocked != "" {
+		st.report.Checks = append(st.report.Checks, SelfTestCheck{
+			Name:    name,
+			Skipped: true,
+			Detail:  fmt.Sprintf("skipped because %s failed", st.blocked),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(st.ctx, st.options.Timeout)
+	defer cancel()
+
+	started := time.Now()
+	detail, err := fn(ctx)
+	check := SelfTestCheck{Name: name, Passed: err == nil, Detail: detail, Duration: time.Since(started)}
+	if err != nil {
+		check.Detail = err.Error()
+		if required {
+			st.blocked = name
+		}
+	}
+	st.report.Checks = append(st.report.Checks, check)
+}
+
+// checkHealth confirms the target answers its liveness probe, and records the
+// learning engine's execution count to compare against later
+func (st *selfTest) checkHealth(ctx context.Context) (string, error) {
+	if err := st.do(ctx, http.MethodGet, "/healthz", nil, http.StatusOK, nil); err != nil {
+		return "", err
+	}
+	executions, err := st.learnedExecutions(ctx)
+	if err != nil {
+		return "", err
+	}
+	st.executions = executions
+	return "server is alive", nil
+}
+
+// checkImport subscribes to registry events for the source, then imports it
+func (st *selfTest) checkImport(ctx context.Context) (string, error) {
+	if err := st.subscribeToolEvents(); err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Result struct {
+			Tools []json.RawMessage `json:"tools"`
+		} `json:"result"`
+	}
+	body := map[string]interface{}{
+		"id":          st.source.ID,
+		"type":        st.source.Type,
+		"path":        st.source.Path,
+		"name":        st.source.Name,
+		"description": st.source.Description,
+	}
+	if err := st.do(ctx, http.MethodPost, "/api/v1/specs/", body, http.StatusCreated, &resp); err != nil {
+		return "", err
+	}
+	st.imported = true
+	if len(resp.Result.Tools) == 0 {
+		return "", fmt.Errorf("importing %s produced no tools", st.source.ID)
+	}
+	return fmt.Sprintf("%d tools from %s", len(resp.Result.Tools), st.source.ID), nil
+}
+
+// subscribeToolEvents opens the registry event stream for the source and
+// forwards the type of each event it delivers
+func (st *selfTest) subscribeToolEvents() error {
+	ctx, cancel := context.WithCancel(st.ctx)
+	query := url.Values{"type": {string(ToolEventAdded)}, "source": {st.source.ID}}
+	req, err := st.request(ctx, http.MethodGet, "/api/v1/events?"+query.Encode(), nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	resp, err := st.client.Do(req)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		cancel()
+		return selfTestResponseError(resp)
+	}
+
+	events := make(chan string, eventBufferSize)
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if eventType, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+				select {
+				case events <- eventType:
+				default:
+				}
+			}
+		}
+	}()
+	st.toolEvents, st.stopEvents = events, cancel
+	return nil
+}
+
+// checkToolEvents waits for the import to be announced on the event stream
+func (st *selfTest) checkToolEvents(ctx context.Context) (string, error) {
+	defer st.stopEvents()
+	select {
+	case eventType, ok := <-st.toolEvents:
+		if !ok {
+			return "", errors.New("event stream closed before a tool_added event arrived")
+		}
+		return fmt.Sprintf("received %s", eventType), nil
+	case <-ctx.Done():
+		return "", errors.New("no tool_added event was streamed for the imported spec")
+	}
+}
+
+// checkRegister registers the synthetic agent
+func (st *selfTest) checkRegister(ctx context.Context) (string, error) {
+	var resp struct {
+		SessionID string `json:"session_id"`
+	}
+	body := map[string]interface{}{
+		"agent_id":   selfTestAgentID,
+		"agent_name": "AionMCP Self-Test",
+		"capabilities": map[string]interface{}{
+			"supports_streaming":        true,
+			"supports_async_invocation": true,
+		},
+	}
+	if err := st.do(ctx, http.MethodPost, "/api/v1/agents/register", body, http.StatusCreated, &resp); err != nil {
+		return "", err
+	}
+	if resp.SessionID == "" {
+		return "", errors.New("registration returned no session ID")
+	}
+	st.sessionID = resp.SessionID
+	return "session " + resp.SessionID, nil
+}
+
+// checkListTools finds the tool for the invoked operation among those the
+// agent can see. Naming strategies may change the case of the operation.
+func (st *selfTest) checkListTools(ctx context.Context) (string, error) {
+	var resp struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := st.do(ctx, http.MethodGet, st.sessionPath("/tools?page_size=1000"), nil, http.StatusOK, &resp); err != nil {
+		return "", err
+	}
+	operation := strings.ToLower(selfTestOperation)
+	for _, tool := range resp.Tools {
+		normalized := strings.ToLower(strings.ReplaceAll(tool.Name, "_", ""))
+		if strings.Contains(tool.Name, st.source.ID) && strings.HasSuffix(normalized, operation) {
+			st.toolName = tool.Name
+			return fmt.Sprintf("%s among %d tools", tool.Name, len(resp.Tools)), nil
+		}
+	}
+	return "", fmt.Errorf("no tool for %s operation %s is visible to the agent", st.source.ID, selfTestOperation)
+}
+
+// checkInvoke invokes the tool synchronously
+func (st *selfTest) checkInvoke(ctx context.Context) (string, error) {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	body := map[string]interface{}{"parameters_json": selfTestParameters}
+	if err := st.do(ctx, http.MethodPost, st.sessionPath("/tools/"+st.toolName+"/invoke"), body, http.StatusOK, &resp); err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+// checkAsyncInvoke submits an async invocation and polls it to completion
+func (st *selfTest) checkAsyncInvoke(ctx context.Context) (string, error) {
+	var submitted struct {
+		InvocationID string `json:"invocation_id"`
+	}
+	body := map[string]interface{}{
+		"parameters_json": selfTestParameters,
+		"options":         map[string]interface{}{"async": true},
+	}
+	if err := st.do(ctx, http.MethodPost, st.sessionPath("/tools/"+st.toolName+"/invoke"), body, http.StatusAccepted, &submitted); err != nil {
+		return "", err
+	}
+
+	var status string
+	err := pollUntil(ctx, func() (bool, error) {
+		var resp struct {
+			Status string `json:"status"`
+		}
+		if err := st.do(ctx, http.MethodGet, st.sessionPath("/invocations/"+submitted.InvocationID), nil, http.StatusOK, &resp); err != nil {
+			return false, err
+		}
+		status = resp.Status
+		return !strings.HasSuffix(status, "_PENDING") && !strings.HasSuffix(status, "_RUNNING"), nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("invocation %s did not complete: %w", submitted.InvocationID, err)
+	}
+	if !strings.HasSuffix(status, "_SUCCESS") {
+		return "", fmt.Errorf("invocation %s finished with %s", submitted.InvocationID, status)
+	}
+	return fmt.Sprintf("invocation %s: %s", submitted.InvocationID, status), nil
+}
+
+// checkAgentEvents reads the session's event stream for the invocations
+func (st *selfTest) checkAgentEvents(ctx context.Context) (string, error) {
+	var resp struct {
+		Events []struct {
+			Type string `json:"type"`
+		} `json:"events"`
+	}
+	if err := st.do(ctx, http.MethodGet, st.sessionPath("/events?cursor=0"), nil, http.StatusOK, &resp); err != nil {
+		return "", err
+	}
+	invocations := 0
+	for _, event := range resp.Events {
+		if strings.HasSuffix(event.Type, "_TOOL_INVOCATION") {
+			invocations++
+		}
+	}
+	if invocations == 0 {
+		return "", fmt.Errorf("none of %d session events reported a tool invocation", len(resp.Events))
+	}
+	return fmt.Sprintf("%d invocation events", invocations), nil
+}
+
+// checkLearning waits for the invocations to be recorded by the learning engine
+func (st *selfTest) checkLearning(ctx context.Context) (string, error) {
+	var executions int64
+	err := pollUntil(ctx, func() (bool, error) {
+		var err error
+		executions, err = st.learnedExecutions(ctx)
+		return executions > st.executions, err
+	})
+	if err != nil {
+		return "", fmt.Errorf("no executions were recorded: %w", err)
+	}
+	return fmt.Sprintf("%d executions recorded", executions-st.executions), nil
+}
+
+// checkDocs renders the tool changelog generated for the spec
+func (st *selfTest) checkDocs(ctx context.Context) (string, error) {
+	var resp struct {
+		Content string `json:"content"`
+	}
+	if err := st.do(ctx, http.MethodGet, "/api/v1/specs/"+url.PathEscape(st.source.ID)+"/changelog", nil, http.StatusOK, &resp); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(resp.Content) == "" {
+		return "", errors.New("generated changelog is empty")
+	}
+	return fmt.Sprintf("%d byte changelog", len(resp.Content)), nil
+}
+
+// cleanup unregisters the agent and removes the imported spec
+func (st *selfTest) cleanup(ctx context.Context) (string, error) {
+	if st.stopEvents != nil {
+		st.stopEvents()
+	}
+
+	var errs []error
+	var removed []string
+	if st.sessionID != "" {
+		if err := st.do(ctx, http.MethodDelete, st.sessionPath(""), nil, http.StatusOK, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to unregister agent: %w", err))
+		} else {
+			removed = append(removed, "agent")
+		}
+	}
+	if st.imported {
+		if err := st.do(ctx, http.MethodDelete, "/api/v1/specs/"+url.PathEscape(st.source.ID), nil, http.StatusNoContent, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove spec: %w", err))
+		} else {
+			removed = append(removed, "spec")
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return "", err
+	}
+	if len(removed) == 0 {
+		return "nothing to remove", nil
+	}
+	return "removed " + strings.Join(removed, " and "), nil
+}
+
+// learnedExecutions returns the number of executions the learning engine recorded
+func (st *selfTest) learnedExecutions(ctx context.Context) (int64, error) {
+	var stats struct {
+		TotalExecutions int64 `json:"total_executions"`
+	}
+	if err := st.do(ctx, http.MethodGet, "/api/v1/learning/stats", nil, http.StatusOK, &stats); err != nil {
+		return 0, err
+	}
+	return stats.TotalExecutions, nil
+}
+
+// sessionPath returns the path of a resource of the agent session
+func (st *selfTest) sessionPath(path string) string {
+	return "/api/v1/agents/" + url.PathEscape(st.sessionID) + path
+}
+
+// request builds a request to the target, authenticated when an API key is set
+func (st *selfTest) request(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, st.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if st.options.APIKey != "" {
+		req.Header.Set(apikey.Header, st.options.APIKey)
+	}
+	return req, nil
+}
+
+// do sends a request and decodes the response into out, which may be nil,
+// failing unless the target answers with the expected status
+func (st *selfTest) do(ctx context.Context, method, path string, body interface{}, expected int, out interface{}) error {
+	req, err := st.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := st.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		return fmt.Errorf("%s %s: %w", method, path, selfTestResponseError(resp))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
+
+// selfTestResponseError describes an unexpected response, including the error the
+// target reported
+func selfTestResponseError(resp *http.Response) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body) == nil && body.Error != "" {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body.Error)
+	}
+	return fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// pollUntil calls done until it reports true, fails, or the context ends
+func pollUntil(ctx context.Context, done func() (bool, error)) error {
+	ticker := time.NewTicker(selfTestPollInterval)
+	defer ticker.Stop()
+	for {
+		finished, err := done()
+		if err != nil {
+			return err
+		}
+		if finished {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
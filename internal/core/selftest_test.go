@@ -0,0 +1,57 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	viper.Set("storage.type", "file")
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	viper.Set("learning.include_successful", true)
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	defer viper.Reset()
+
+	// Without a target a temporary instance with in-memory storage is booted
+	report, err := RunSelfTest(context.Background(), SelfTestOptions{Timeout: 10 * time.Second}, zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, report.Temporary)
+	assert.Equal(t, storageTypeMemory, viper.GetString("storage.type"))
+	for _, check := range report.Checks {
+		assert.True(t, check.Passed, "%s: %s", check.Name, check.Detail)
+	}
+	require.True(t, report.Passed())
+	require.Len(t, report.Checks, 11)
+	assert.Equal(t, "cleanup", report.Checks[10].Name)
+	assert.Equal(t, "removed agent and spec", report.Checks[10].Detail)
+
+	var output bytes.Buffer
+	report.Write(&output)
+	assert.Contains(t, output.String(), "(temporary instance)")
+	assert.Contains(t, output.String(), "11 passed, 0 failed, 0 skipped")
+
+	// A failed required check skips the checks that depend on it
+	target := httptest.NewServer(http.NotFoundHandler())
+	defer target.Close()
+	report, err = RunSelfTest(context.Background(), SelfTestOptions{Target: target.URL + "/", Timeout: time.Second}, zap.NewNop())
+	require.NoError(t, err)
+	assert.False(t, report.Passed())
+	assert.Equal(t, target.URL, report.Target)
+	assert.Contains(t, report.Checks[0].Detail, "unexpected status 404")
+	assert.True(t, report.Checks[1].Skipped)
+	assert.Equal(t, "nothing to remove", report.Checks[10].Detail)
+	output.Reset()
+	report.Write(&output)
+	assert.Contains(t, output.String(), "1 passed, 1 failed, 9 skipped")
+}
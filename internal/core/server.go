@@ -2,20 +2,41 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/aionmcp/aionmcp/internal/autodocs"
+	"github.com/aionmcp/aionmcp/internal/demo"
 	"github.com/aionmcp/aionmcp/pkg/agent"
 	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/aionmcp/aionmcp/pkg/audit"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/contextvars"
+	"github.com/aionmcp/aionmcp/pkg/eventstore"
 	"github.com/aionmcp/aionmcp/internal/selflearn"
 	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/aionmcp/aionmcp/pkg/invocationlog"
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"github.com/aionmcp/aionmcp/pkg/messaging"
+	"github.com/aionmcp/aionmcp/pkg/metrics"
+	"github.com/aionmcp/aionmcp/pkg/readonly"
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/aionmcp/aionmcp/pkg/sessionhistory"
+	"github.com/aionmcp/aionmcp/pkg/types"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 // Server represents the main AionMCP server
@@ -23,68 +44,609 @@ type Server struct {
 	logger          *zap.Logger
 	httpServer      *http.Server
 	grpcServer      *grpc.Server
+	grpcHealth      *health.Server
+	grpcListening   atomic.Bool // Whether the gRPC listener is serving
 	toolRegistry    *ToolRegistry
 	importerManager *importer.ImporterManager
 	fileWatcher     *importer.FileWatcher
+	specPoller      *importer.SpecPoller
+	messaging       *messaging.Pool // Broker connections of AsyncAPI tools
 	agentServer     *agent.AgentServer
 	agentAPI        *agent.AgentAPI
 	learningEngine  *selflearn.Engine
+	invocationLog   *invocationlog.Exporter
+	audit           *audit.Log       // Nil while the audit log is disabled
+	resultCache     *ResultCache     // Nil while result caching is disabled
+	watchdog        *Watchdog        // Nil while the watchdog is disabled
+	backups         *BackupSchedule  // Nil while scheduled backups are disabled
+	notifier        *insightNotifier // Nil while no notification sink is configured
+	remediator      *remediator
+	adaptive        *adaptiveTuner // Nil while adaptive tuning is disabled
+	contextVars     *contextvars.Store
+	readOnly        *readonly.Mode
+	demo            *demo.Environment // Non-nil in demo mode
+	sourceStore     *importer.SourceStore // Nil while imported sources are not persisted
+	sessionHistory  *sessionhistory.Store
+	eventStore      *eventstore.Store // Nil while agent events are not persisted
+	events          *eventHub
+	credentials     CredentialIssuer // Nil while the server requires no authentication
+	apiKeys         *apikey.Store    // Nil while API key authentication is disabled
+	access          *toolAccess      // Nil while role-based tool access is disabled
+	tls             *serverTLS       // Nil while serving plaintext
+	debugServer     *http.Server     // Nil while the debug listener is disabled
+	metrics         *metrics.Metrics // Nil while metrics are disabled
+	workflows       *workflowCatalog
 	shutdown        chan struct{}
 	wg              sync.WaitGroup
 	serverCtx       context.Context // Server-scoped context for background operations
 	cancelFunc      context.CancelFunc
 }
 
+// Storage types selected by storage.type
+const (
+	storageTypeBolt   = selflearn.StorageBolt
+	storageTypeMemory = learning.StorageMemory
+)
+
+// ServerOptions customise a server beyond its configuration, for embedding
+// it in integration tests
+type ServerOptions struct {
+	Clock clock.Clock // Drives sessions, learning, docs schedules and API key expiry; nil selects the clock.mode clock
+}
+
+// Clock modes selected by clock.mode
+const (
+	clockModeReal      = "real"
+	clockModeSimulated = "simulated"
+)
+
+// configuredClock returns the clock selected by clock.mode: the system clock,
+// or a simulated clock starting at clock.simulation.start and running
+// clock.simulation.speed times faster than real time
+func configuredClock(logger *zap.Logger) (clock.Clock, error) {
+	switch mode := viper.GetString("clock.mode"); mode {
+	case clockModeReal, "":
+		return clock.Real{}, nil
+	case clockModeSimulated:
+		start := time.Now()
+		if value := viper.GetString("clock.simulation.start"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid clock.simulation.start: %w", err)
+			}
+			start = parsed
+		}
+		speed := viper.GetFloat64("clock.simulation.speed")
+		if speed <= 0 {
+			return nil, fmt.Errorf("clock.simulation.speed must be positive, got %v", speed)
+		}
+		logger.Warn("Running on a simulated clock",
+			zap.Time("start", start),
+			zap.Float64("speed", speed))
+		return clock.NewSimulated(start, speed), nil
+	default:
+		return nil, fmt.Errorf("unsupported clock mode %q", mode)
+	}
+}
+
+// inMemoryStorage reports whether storage.type keeps all state in memory
+func inMemoryStorage() bool {
+	return viper.GetString("storage.type") == storageTypeMemory
+}
+
 // NewServer creates a new AionMCP server instance
 func NewServer(logger *zap.Logger) (*Server, error) {
+	return NewServerWithOptions(logger, ServerOptions{})
+}
+
+// NewServerWithOptions creates a server with a custom clock. Together with
+// storage.type=memory it runs entirely in-process, leaving nothing on disk.
+func NewServerWithOptions(logger *zap.Logger, options ServerOptions) (_ *Server, err error) {
+	// Release everything opened so far, newest first, when construction fails
+	var closers []func() error
+	cleanup := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+
+	if options.Clock == nil {
+		configured, err := configuredClock(logger)
+		if err != nil {
+			return nil, err
+		}
+		options.Clock = configured
+	}
+
+	// Serve HTTP and gRPC over TLS, optionally verifying client certificates
+	serving, err := loadServerTLS(logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+
 	// Initialize tool registry
 	registry := NewToolRegistry(logger)
 
+	// Validate invocations against tool schemas
+	var toolValidation []ToolValidation
+	if err := viper.UnmarshalKey("validation.tools", &toolValidation); err != nil {
+		return nil, fmt.Errorf("invalid validation configuration: %w", err)
+	}
+	validation := ValidationConfig{
+		Input:  viper.GetBool("validation.input"),
+		Output: viper.GetBool("validation.output"),
+		Tools:  make(map[string]ToolValidation, len(toolValidation)),
+	}
+	for _, override := range toolValidation {
+		validation.Tools[override.Name] = override
+	}
+	registry.SetValidation(validation)
+
+	// Deprecations declared in configuration overlay the spec deprecated flags
+	var deprecationOverlays []DeprecationOverlay
+	if err := viper.UnmarshalKey("tools.deprecations", &deprecationOverlays); err != nil {
+		return nil, fmt.Errorf("invalid deprecation configuration: %w", err)
+	}
+	deprecations, err := parseDeprecationOverlays(deprecationOverlays)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deprecation configuration: %w", err)
+	}
+	registry.SetDeprecations(deprecations)
+
+	// Alternate names keep prompts written against renamed tools working
+	var aliasOverlays []AliasOverlay
+	if err := viper.UnmarshalKey("tools.aliases", &aliasOverlays); err != nil {
+		return nil, fmt.Errorf("invalid alias configuration: %w", err)
+	}
+	for _, overlay := range aliasOverlays {
+		if err := registry.AddAlias(overlay.Alias, overlay.Tool); err != nil {
+			return nil, fmt.Errorf("invalid alias configuration: %w", err)
+		}
+	}
+
+	// Resolve parameter templates from workspace context variables so agents
+	// need not carry base IDs, tenant codes and secrets
+	var configuredVars []contextvars.Variable
+	if err := viper.UnmarshalKey("context_vars.variables", &configuredVars); err != nil {
+		return nil, fmt.Errorf("invalid context variable configuration: %w", err)
+	}
+	contextVars, err := loadContextVars(configuredVars)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context variable configuration: %w", err)
+	}
+	if viper.GetBool("context_vars.enabled") {
+		registry.SetContextVars(contextVars)
+	}
+
+	// Bound tool invocations so hung upstreams release their callers
+	var toolTimeouts []ToolTimeout
+	if err := viper.UnmarshalKey("tools.timeouts", &toolTimeouts); err != nil {
+		return nil, fmt.Errorf("invalid timeout configuration: %w", err)
+	}
+	timeouts := TimeoutConfig{
+		Default: time.Duration(viper.GetInt64("tools.timeout_ms")) * time.Millisecond,
+		Tools:   make(map[string]time.Duration, len(toolTimeouts)),
+	}
+	for _, override := range toolTimeouts {
+		timeouts.Tools[override.Tool] = time.Duration(override.TimeoutMs) * time.Millisecond
+	}
+	registry.SetTimeouts(timeouts)
+
+	// Stop invoking tools that keep failing until a probe succeeds
+	var toolBreakers []ToolCircuitBreaker
+	if err := viper.UnmarshalKey("tools.circuit_breaker.tools", &toolBreakers); err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker configuration: %w", err)
+	}
+	circuitBreakers := CircuitBreakerConfig{
+		FailureThreshold: viper.GetInt("tools.circuit_breaker.failure_threshold"),
+		OpenTimeout:      time.Duration(viper.GetInt64("tools.circuit_breaker.open_timeout_ms")) * time.Millisecond,
+		Tools:            make(map[string]int, len(toolBreakers)),
+	}
+	for _, override := range toolBreakers {
+		circuitBreakers.Tools[override.Tool] = override.FailureThreshold
+	}
+	registry.SetCircuitBreakers(circuitBreakers)
+
+	// Serve repeated invocations of idempotent tools from cache
+	var resultCache *ResultCache
+	if viper.GetBool("cache.enabled") {
+		var toolTTLs []ToolCacheTTL
+		if err := viper.UnmarshalKey("cache.tools", &toolTTLs); err != nil {
+			return nil, fmt.Errorf("invalid result cache configuration: %w", err)
+		}
+		cacheConfig := ResultCacheConfig{
+			TTL:        time.Duration(viper.GetInt64("cache.ttl_ms")) * time.Millisecond,
+			MaxEntries: viper.GetInt("cache.max_entries"),
+			Tools:      make(map[string]time.Duration, len(toolTTLs)),
+			Path:       viper.GetString("cache.path"),
+		}
+		if inMemoryStorage() {
+			cacheConfig.Path = ""
+		}
+		for _, override := range toolTTLs {
+			cacheConfig.Tools[override.Tool] = time.Duration(override.TTLMs) * time.Millisecond
+		}
+		resultCache, err = NewResultCache(cacheConfig, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create result cache: %w", err)
+		}
+		closers = append(closers, resultCache.Close)
+		registry.SetResultCache(resultCache)
+	}
+
+	// Capture levels agents may negotiate for learning payloads
+	var telemetry agent.TelemetryPolicy
+	for key, level := range map[string]*agent.CaptureLevel{
+		"agent.telemetry.default_capture_level": &telemetry.Default,
+		"agent.telemetry.max_capture_level":     &telemetry.Max,
+	} {
+		parsed, err := agent.ParseCaptureLevel(viper.GetString(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		*level = parsed
+	}
+
+	// Agent protocol versions sessions may negotiate
+	protocols := agent.DefaultProtocolPolicy()
+	for key, version := range map[string]*agent.Protocol{
+		"agent.protocol.min_version":     &protocols.Min,
+		"agent.protocol.default_version": &protocols.Default,
+	} {
+		if value := viper.GetString(key); value != "" {
+			parsed, err := agent.ParseProtocol(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			*version = parsed
+		}
+	}
+
+	// Result encodings sessions may negotiate through preferred_formats
+	var resultFormats []agent.ResultFormat
+	for _, name := range viper.GetStringSlice("agent.result_formats") {
+		format, err := agent.ParseResultFormat(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid agent.result_formats: %w", err)
+		}
+		resultFormats = append(resultFormats, format)
+	}
+
 	// Initialize importer manager
-	importerManager := importer.NewImporterManager(registry)
+	importerManager := newImporterManager(registry)
 
-	// Register importers
-	importerManager.RegisterImporter(importer.NewOpenAPIImporter())
-	importerManager.RegisterImporter(importer.NewGraphQLImporter())
-	importerManager.RegisterImporter(importer.NewAsyncAPIImporter())
+	// Share broker connections between the AsyncAPI tools of every source
+	messagingPool := messaging.NewPool(messaging.PoolConfig{
+		ConnectTimeout: time.Duration(viper.GetInt("importer.asyncapi.connect_timeout_seconds")) * time.Second,
+	})
+	importerManager.SetMessagingPool(messagingPool)
+	closers = append(closers, messagingPool.Close)
+
+	// Initialize read-only mode for maintenance windows
+	readOnly := readonly.NewMode()
+	readOnly.SetClock(options.Clock)
+	readOnlyReason := viper.GetString("server.read_only_reason")
+	if viper.GetBool("server.read_only") {
+		readOnly.Set("", true, readOnlyReason)
+	}
+	for _, workspace := range viper.GetStringSlice("server.read_only_workspaces") {
+		readOnly.Set(workspace, true, readOnlyReason)
+	}
+
+	// Spec imports and reloads (including file watcher reloads) honour server-wide read-only mode
+	importerManager.SetWriteGuard(func() error {
+		return readOnly.Check("")
+	})
+
+	// Regenerate the tool changelog whenever a spec reload changes tools
+	docsConfig := autodocs.DefaultEngineConfig()
+	if locale := viper.GetString("docs.locale"); locale != "" {
+		docsConfig.Locale = locale
+	}
+	docsConfig.Timezone = viper.GetString("docs.timezone")
+	docsConfig.Clock = options.Clock
+	docsFormat, err := autodocs.NewFormatter(docsConfig.Locale, docsConfig.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docs configuration: %w", err)
+	}
+	docsEngine := autodocs.NewEngineWithConfig(".", autodocs.NewGitDataSource("."), docsConfig)
+	docsEngine.RegisterGenerator(autodocs.NewToolChangelogGenerator(importerManager))
+	importerManager.OnCatalogChange(func(diff importer.ToolCatalogDiff) {
+		logger.Info("Tool catalog changed",
+			zap.String("source_id", diff.SourceID),
+			zap.Int("changes", len(diff.Changes)),
+			zap.Bool("breaking", diff.HasBreakingChanges()))
+
+		result, err := docsEngine.GenerateToolChangelog(diff.SourceID)
+		if err != nil {
+			logger.Error("Failed to generate tool changelog",
+				zap.String("source_id", diff.SourceID),
+				zap.Error(err))
+			return
+		}
+		if !result.Success {
+			logger.Warn("Tool changelog generation unsuccessful",
+				zap.String("source_id", diff.SourceID),
+				zap.String("error", result.Error))
+		}
+	})
 
 	// Initialize file watcher
 	fileWatcher, err := importer.NewFileWatcher(importerManager, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file watcher: %w", err)
 	}
+	closers = append(closers, func() error {
+		fileWatcher.Stop()
+		return nil
+	})
+
+	// In demo mode, import the bundled specs backed by in-process mock upstreams
+	var demoEnv *demo.Environment
+	if viper.GetBool("demo.enabled") {
+		demoEnv, err = demo.Start(logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start demo environment: %w", err)
+		}
+		closers = append(closers, demoEnv.Close)
+		for _, source := range demoEnv.Sources() {
+			result, err := importerManager.ImportSpec(context.Background(), source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import demo spec %s: %w", source.ID, err)
+			}
+			logger.Info("Imported demo spec",
+				zap.String("source_id", source.ID),
+				zap.Int("tools", len(result.Tools)))
+		}
+	}
+
+	// Register composite workflow tools defined in YAML
+	workflows := newWorkflowCatalog(registry, logger)
+	if dir := viper.GetString("workflows.path"); dir != "" {
+		loaded, err := workflows.loadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workflows: %w", err)
+		}
+		if loaded > 0 {
+			logger.Info("Loaded workflows", zap.String("path", dir), zap.Int("workflows", loaded))
+		}
+	}
+
+	// Initialize the SIEM invocation log, kept separate from application logs
+	var invocationLog *invocationlog.Exporter
+	if viper.GetBool("invocation_log.enabled") {
+		logConfig := invocationlog.DefaultConfig()
+		logConfig.Format = invocationlog.Format(viper.GetString("invocation_log.format"))
+		logConfig.Path = viper.GetString("invocation_log.path")
+		logConfig.SyslogNetwork = viper.GetString("invocation_log.syslog.network")
+		logConfig.SyslogAddress = viper.GetString("invocation_log.syslog.address")
+		logConfig.HTTPURL = viper.GetString("invocation_log.http.url")
+		logConfig.HTTPTimeout = time.Duration(viper.GetInt("invocation_log.http.timeout_seconds")) * time.Second
+		logConfig.BufferSize = viper.GetInt("invocation_log.buffer_size")
+		var redactionRules []struct {
+			Field  string `mapstructure:"field"`
+			Action string `mapstructure:"action"`
+		}
+		if err := viper.UnmarshalKey("invocation_log.redact", &redactionRules); err != nil {
+			return nil, fmt.Errorf("invalid invocation log redaction rules: %w", err)
+		}
+		for _, rule := range redactionRules {
+			logConfig.Redaction[rule.Field] = invocationlog.RedactionAction(rule.Action)
+		}
+
+		invocationLog, err = invocationlog.NewExporter(logConfig, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create invocation log exporter: %w", err)
+		}
+		closers = append(closers, invocationLog.Close)
+	}
 
-	// Initialize agent server and API
-	agentServer := agent.NewAgentServer(logger, registry)
-	agentAPI := agent.NewAgentAPI(logger, registry, agentServer)
 	// Initialize self-learning engine
 	learningConfig := selflearn.DefaultCollectionConfig()
 	learningConfig.Enabled = viper.GetBool("learning.enabled")
 	if learningConfig.Enabled {
-		if sampleRate := viper.GetFloat64("learning.sample_rate"); sampleRate > 0 {
-			learningConfig.SampleRate = sampleRate
+		if viper.IsSet("learning.sample_rate") {
+			learningConfig.SampleRate = viper.GetFloat64("learning.sample_rate")
+			if learningConfig.SampleRate < 0 || learningConfig.SampleRate > 1 {
+				return nil, fmt.Errorf("learning.sample_rate must be between 0 and 1, got %g", learningConfig.SampleRate)
+			}
+		}
+		if viper.IsSet("learning.always_sample_errors") {
+			learningConfig.AlwaysSampleErrors = viper.GetBool("learning.always_sample_errors")
 		}
 		if retentionDays := viper.GetInt("learning.retention_days"); retentionDays > 0 {
 			learningConfig.RetentionPeriod = time.Duration(retentionDays) * 24 * time.Hour
 		}
 	}
 
-	// Create learning storage
-	storagePath := viper.GetString("storage.path")
-	if storagePath == "" {
-		storagePath = "./data/aionmcp.db"
+	// Create learning storage with the driver registered for storage.type
+	storageType := viper.GetString("storage.type")
+	if storageType == "" {
+		storageType = storageTypeBolt
 	}
-	learningStorage, err := selflearn.NewBoltStorage(storagePath, logger)
+	learningStorage, err := learning.OpenStorage(storageType, learning.StorageConfig{
+		Path:   viper.GetString("storage.path"),
+		Logger: logger,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create learning storage: %w", err)
 	}
+	closers = append(closers, learningStorage.Close)
+	if storageType == storageTypeMemory {
+		logger.Info("Using in-memory storage; learning data and API keys are discarded on shutdown")
+	}
 
-	// Create learning engine (ensure storage cleanup on error)
+	// Create learning engine, which closes the storage it is given
 	learningEngine := selflearn.NewEngine(learningConfig, learningStorage, logger)
 	if learningEngine == nil {
-		learningStorage.Close()
 		return nil, fmt.Errorf("failed to create learning engine")
 	}
+	closers[len(closers)-1] = learningEngine.Close
+	learningEngine.SetClock(options.Clock)
+
+	// Keep the insights of workspaces sharing this instance apart
+	learningEngine.SetAggregation(selflearn.AggregationConfig{
+		Enabled:       viper.GetBool("learning.insights.aggregation_only"),
+		MinWorkspaces: viper.GetInt("learning.insights.min_workspaces"),
+		MinExecutions: viper.GetInt("learning.insights.min_executions"),
+	})
+
+	// Shed learning work rather than slow invocations when storage falls behind
+	learningEngine.SetShedding(selflearn.SheddingConfig{
+		Enabled:         viper.GetBool("learning.shedding.enabled"),
+		QueueSize:       viper.GetInt("learning.shedding.queue_size"),
+		Workers:         viper.GetInt("learning.shedding.workers"),
+		LatencyTarget:   time.Duration(viper.GetInt("learning.shedding.latency_target_ms")) * time.Millisecond,
+		MinSampleRate:   viper.GetFloat64("learning.shedding.min_sample_rate"),
+		DeferAnalysisAt: viper.GetFloat64("learning.shedding.defer_analysis_at"),
+	})
+	learningEngine.SetRecommendationWindow(time.Duration(viper.GetInt("learning.recommendations.window_hours")) * time.Hour)
+
+	// Keep secrets and personal data in tool payloads out of execution records
+	if err := learningEngine.SetRedaction(selflearn.RedactionConfig{
+		Fields:       viper.GetStringSlice("learning.redaction.fields"),
+		Paths:        viper.GetStringSlice("learning.redaction.paths"),
+		SkipPayloads: viper.GetStringSlice("learning.redaction.skip_payload_tools"),
+	}); err != nil {
+		return nil, fmt.Errorf("invalid learning redaction configuration: %w", err)
+	}
+	// Executions of imported tools belong to the tenant of their spec source
+	learningEngine.SetTenantResolver(importerManager.GetToolTenant)
+
+	// Downsample executions past learning.retention_days into hourly aggregates
+	retentionInterval := time.Duration(viper.GetInt("learning.retention.interval_minutes")) * time.Minute
+	learningEngine.SetRetention(selflearn.RetentionConfig{
+		Interval:   retentionInterval,
+		Downsample: viper.GetBool("learning.retention.downsample"),
+		Compact:    viper.GetBool("learning.retention.compact"),
+	})
+
+	// Store snapshots of learning storage in a directory or S3-compatible bucket
+	backups, err := newBackupSchedule(learningEngine, options.Clock, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up backups: %w", err)
+	}
+
+	// Push new high-priority insights to webhook, Slack and email sinks
+	notifier, err := newInsightNotifier(options.Clock, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up notifications: %w", err)
+	}
+	if notifier != nil {
+		learningEngine.OnInsights(notifier.notifyInsights)
+	}
+
+	// Apply the remediations insights suggest once approved, or right away
+	// with learning.remediation.auto_apply
+	remediator, err := newRemediator(registry, learningEngine, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up remediation: %w", err)
+	}
+	learningEngine.OnInsights(remediator.autoApply)
+
+	// Adapt tool timeouts and retry budgets to their recent latency and errors
+	adaptive, err := newAdaptiveTuner(registry, learningEngine, options.Clock, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adaptive tuning configuration: %w", err)
+	}
+
+	// Fan tool registry changes and new insights out to event stream subscribers
+	events := newEventHub(logger)
+	registry.AddEventHandler(events.publishToolEvent)
+	learningEngine.OnInsights(events.publishInsights)
+
+	// Poll specs imported from URLs and reload them when they change, announcing
+	// each reload to event stream subscribers
+	specPoller := importer.NewSpecPoller(importerManager, importer.SpecPollerConfig{
+		DefaultInterval: time.Duration(viper.GetInt("importer.poll.default_interval_seconds")) * time.Second,
+		CheckInterval:   time.Duration(viper.GetInt("importer.poll.check_interval_seconds")) * time.Second,
+		Clock:           options.Clock,
+	}, logger)
+	specPoller.OnReload(events.publishSpecReload)
+
+	// Raise an insight when an upstream quota keeps running out
+	importerManager.OnQuotaExhausted(quotaInsightRecorder(learningEngine, viper.GetInt("importer.quota.insight_after"), logger))
+
+	// Force-cancel invocations that hang past the hard maximum runtime and
+	// raise an insight when a tool keeps hanging
+	var watchdog *Watchdog
+	if maxRuntime := time.Duration(viper.GetInt64("tools.watchdog.max_runtime_ms")) * time.Millisecond; maxRuntime > 0 {
+		watchdog = NewWatchdog(WatchdogConfig{
+			MaxRuntime:    maxRuntime,
+			CheckInterval: time.Duration(viper.GetInt64("tools.watchdog.check_interval_ms")) * time.Millisecond,
+			HangWindow:    time.Duration(viper.GetInt64("tools.watchdog.hang_window_ms")) * time.Millisecond,
+		}, options.Clock, logger)
+		watchdog.OnStuck(stuckInsightRecorder(learningEngine, viper.GetInt("tools.watchdog.insight_after"), logger))
+		registry.SetWatchdog(watchdog)
+	}
+
+	// Set up API keys and OIDC tokens when agent and admin endpoints require authentication
+	auth, err := newAuthenticator(logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up authentication: %w", err)
+	}
+	var apiKeys *apikey.Store
+	if auth != nil {
+		apiKeys = auth.keys
+	}
+	if apiKeys != nil {
+		apiKeys.SetClock(options.Clock)
+		closers = append(closers, apiKeys.Close)
+	}
+
+	// Cap the daily invocations and cost of sessions and API keys
+	sessionQuota, toolCost, err := quotaConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid quota configuration: %w", err)
+	}
+	toolScopes, err := toolScopeConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit the tools callers see and invoke to those their roles allow
+	access, err := openToolAccess(registry, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up role-based access: %w", err)
+	}
+	closers = append(closers, access.close)
+
+	// Record who invoked tools, changed specs, ran sessions and administered the server
+	auditLog, err := openAuditLog(options.Clock, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	closers = append(closers, auditLog.Close)
+
+	// Import the specs of earlier runs again so their tools survive restarts
+	sourceStore, err := openSourceStore(importerManager, fileWatcher, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source store: %w", err)
+	}
+	if sourceStore != nil {
+		closers = append(closers, sourceStore.Close)
+	}
+
+	// Keep the recent tool usage of agent sessions across restarts
+	sessionHistory, err := openSessionHistory(logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session history: %w", err)
+	}
+	closers = append(closers, sessionHistory.Close)
+
+	// Keep broadcast agent events for replay across restarts
+	eventStore, err := openEventStore(logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event store: %w", err)
+	}
+	if eventStore != nil {
+		closers = append(closers, eventStore.Close)
+	}
 
 	// Create HTTP server with Gin
 	gin.SetMode(gin.ReleaseMode)
@@ -104,48 +666,337 @@ func NewServer(logger *zap.Logger) (*Server, error) {
 		)
 	})
 
+	// Browser clients on other origins are answered before credentials are checked
+	if headers := loadSecurityHeadersConfig(); headers.Enabled {
+		router.Use(securityHeaders(headers, serving != nil))
+	}
+	if cors := loadCORSConfig(); len(cors.AllowedOrigins) > 0 {
+		router.Use(corsMiddleware(cors))
+	}
+
+	// Protected routes require credentials granting their scope
+	if auth != nil {
+		router.Use(authMiddleware(auth, logger))
+	}
+	if auditLog != nil {
+		router.Use(auditRequests(auditLog, logger))
+	}
+	router.Use(contextVarsWorkspace)
+	router.Use(invocationEnvironment)
+
 	// Create server-scoped context for background operations
 	serverCtx, cancelFunc := context.WithCancel(context.Background())
+	closers = append(closers, func() error {
+		cancelFunc()
+		return nil
+	})
+	if watchdog != nil {
+		go watchdog.Run(serverCtx)
+	}
+	if retentionInterval > 0 {
+		go learningEngine.RunRetentionSchedule(serverCtx)
+	}
+	if backups != nil {
+		go backups.Run(serverCtx)
+	}
+	if notifier != nil {
+		go notifier.dispatcher.Run(serverCtx)
+	}
+	if adaptive != nil {
+		go adaptive.Run(serverCtx)
+	}
+
+	// Initialize agent server and API
+	agentConfig := agent.DefaultAgentServerConfig()
+	agentConfig.SessionLimits = agent.SessionLimits{
+		RequestsPerMinute: viper.GetInt("agent.limits.requests_per_minute"),
+		MaxConcurrent:     viper.GetInt("agent.limits.max_concurrent"),
+		MaxInvocations:    viper.GetInt64("agent.limits.max_invocations"),
+		BudgetMs:          viper.GetInt64("agent.limits.budget_ms"),
+	}
+	agentConfig.SessionQuota = sessionQuota
+	agentConfig.ToolCost = toolCost
+	agentConfig.ToolScopes = toolScopes
+	if apiKeys != nil {
+		agentConfig.IdentityQuota = apiKeyQuota(apiKeys)
+	}
+	agentConfig.InvocationLog = invocationLog
+	agentConfig.Audit = auditLog
+	agentConfig.Clock = options.Clock
+	agentConfig.ReadOnly = readOnly
+	agentConfig.Executions = &learningRecorder{ctx: serverCtx, engine: learningEngine}
+	agentConfig.UsageHistory = sessionHistory
+	agentConfig.EventStore = eventStore
+	agentConfig.Resumption = agent.ResumptionConfig{
+		Grace:  time.Duration(viper.GetInt("agent.resumption.grace_seconds")) * time.Second,
+		Secret: []byte(viper.GetString("agent.resumption.secret")),
+	}
+	agentConfig.Recommender = &learningRecorder{ctx: serverCtx, engine: learningEngine}
+	agentConfig.Telemetry = telemetry
+	agentConfig.Protocols = protocols
+	agentConfig.ResultFormats = resultFormats
+	if access != nil {
+		agentConfig.ToolAccess = access.allows
+	}
+	agentConfig.Tap = agent.TapConfig{
+		IncludePayloads: viper.GetBool("agent.tap.include_payloads"),
+		RedactKeys:      viper.GetStringSlice("agent.tap.redact_keys"),
+	}
+	agentConfig.MaxRetries = viper.GetInt("agent.retry.max_retries")
+	agentConfig.MaxRetryDelay = time.Duration(viper.GetInt64("agent.retry.max_delay_ms")) * time.Millisecond
+	agentConfig.AsyncWorkers = viper.GetInt("agent.async.workers")
+	agentConfig.AsyncQueueSize = viper.GetInt("agent.async.queue_size")
+	agentConfig.AsyncJobRetention = time.Duration(viper.GetInt("agent.async.retention_minutes")) * time.Minute
+	agentConfig.EventLogSize = viper.GetInt("agent.events.log_size")
+	agentConfig.MaxPollWait = time.Duration(viper.GetInt("agent.events.max_poll_wait_seconds")) * time.Second
+	agentConfig.StreamCheckInterval = time.Duration(viper.GetInt("agent.events.stream_check_interval_seconds")) * time.Second
+	agentConfig.StreamStallTimeout = time.Duration(viper.GetInt("agent.events.stream_stall_timeout_seconds")) * time.Second
+	agentConfig.Features = serverFeatures(auth, serving, resultCache, watchdog)
+	agentServer := agent.NewAgentServerWithConfig(logger, registry, agentConfig)
+	closers = append(closers, func() error {
+		agentServer.Close()
+		return nil
+	})
+	agentAPI := agent.NewAgentAPI(logger, registry, agentServer)
+	agentAPI.Gateway().SetInterceptors(agentInterceptors(auth))
+
+	// Export Prometheus metrics of invocations, sessions and importers
+	serverMetrics := newMetrics(agentServer, importerManager)
+	registry.SetMetrics(serverMetrics)
+
+	// List insights about tools agents are using right now first
+	if viper.GetBool("learning.insights.usage_weighting") {
+		learningEngine.SetToolActivity(agentServer.ActiveToolSessions,
+			time.Duration(viper.GetInt("learning.insights.active_window_minutes"))*time.Minute)
+	}
+
+	// Pre-register a long-lived demo agent session so the agent API can be tried right away
+	if demoEnv != nil {
+		session, err := agentServer.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+			AgentId:               demo.AgentID,
+			AgentName:             demo.AgentName,
+			AgentVersion:          "0.1.0",
+			SessionTimeoutSeconds: 24 * 60 * 60,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to register demo agent: %w", err)
+		}
+		logger.Info("Demo mode ready",
+			zap.String("upstream_url", demoEnv.UpstreamURL()),
+			zap.String("session_id", session.SessionId))
+	}
 
 	// Setup HTTP routes
-	setupHTTPRoutes(router, registry, importerManager, fileWatcher, agentAPI, learningEngine, logger, serverCtx)
+	setupHTTPRoutes(router, registry, importerManager, fileWatcher, agentAPI, learningEngine, invocationLog, auditLog, readOnly, access, docsFormat, logger, serverCtx)
 
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", viper.GetInt("server.port")),
 		Handler: router,
 	}
+	grpcConfig := loadGRPCConfig()
+	if serving != nil {
+		httpServer.TLSConfig = serving.Config()
+		grpcConfig.TLS = serving.Config()
+	}
 
 	// Create gRPC server and register agent service
-	grpcServer := grpc.NewServer()
-	agentpb.RegisterAgentServiceServer(grpcServer, agentServer)
+	grpcServer, grpcHealth := newGRPCServer(grpcConfig, agentServer, auth, logger)
 
-	return &Server{
+	server := &Server{
 		logger:          logger,
 		httpServer:      httpServer,
 		grpcServer:      grpcServer,
+		grpcHealth:      grpcHealth,
 		toolRegistry:    registry,
 		importerManager: importerManager,
 		fileWatcher:     fileWatcher,
+		specPoller:      specPoller,
+		messaging:       messagingPool,
 		agentServer:     agentServer,
 		agentAPI:        agentAPI,
 		learningEngine:  learningEngine,
+		invocationLog:   invocationLog,
+		audit:           auditLog,
+		resultCache:     resultCache,
+		watchdog:        watchdog,
+		backups:         backups,
+		notifier:        notifier,
+		remediator:      remediator,
+		adaptive:        adaptive,
+		contextVars:     contextVars,
+		readOnly:        readOnly,
+		apiKeys:         apiKeys,
+		access:          access,
+		tls:             serving,
+		metrics:         serverMetrics,
+		demo:            demoEnv,
+		sourceStore:     sourceStore,
+		sessionHistory:  sessionHistory,
+		eventStore:      eventStore,
+		events:          events,
+		workflows:       workflows,
 		shutdown:        make(chan struct{}),
 		serverCtx:       serverCtx,
 		cancelFunc:      cancelFunc,
-	}, nil
+	}
+
+	// Serve every agent RPC over HTTP with the gRPC message shapes
+	agentAPI.Gateway().RegisterRoutes(router.Group("/api/v1"))
+
+	// Mount the MCP JSON-RPC endpoint, which shares its handler with the stdio transport
+	server.setupMCPRoutes(router)
+
+	// Stream tool registry and learning events to dashboards
+	server.setupEventRoutes(router)
+
+	// Generate onboarding bundles for new agents
+	server.setupOnboardingRoutes(router)
+
+	// Describe the enabled features to clients
+	server.setupCapabilityRoutes(router)
+
+	// Report liveness and readiness to orchestrators
+	server.setupProbeRoutes(router)
+
+	// Serve metrics to Prometheus
+	if serverMetrics != nil {
+		server.setupMetricsRoutes(router)
+	}
+
+	// Query the audit log
+	if auditLog != nil {
+		server.setupAuditRoutes(router)
+	}
+
+	// Report running and stuck invocations
+	if watchdog != nil {
+		server.setupWatchdogRoutes(router)
+	}
+
+	// Keep old tool names working and report their use
+	server.setupAliasRoutes(router)
+
+	// Disable and deprecate tools without removing their sources
+	server.setupToolStatusRoutes(router)
+
+	// Manage the context variables parameter templates reference
+	server.setupContextVarRoutes(router)
+
+	// Back up and restore learning storage
+	server.setupBackupRoutes(router)
+
+	// Report and test insight notifications
+	server.setupNotificationRoutes(router)
+
+	// Approve or reject the remediations insights suggest
+	server.setupRemediationRoutes(router)
+
+	// Define composite workflow tools
+	server.setupWorkflowRoutes(router)
+
+	// Report and trigger polling of specs imported from URLs
+	server.setupSpecPollRoutes(router)
+
+	// Manage API keys, which also serve as onboarding credentials
+	if apiKeys != nil {
+		server.credentials = apiKeyIssuer{
+			store: apiKeys,
+			ttl:   time.Duration(viper.GetInt("auth.api_keys.onboarding_ttl_hours")) * time.Hour,
+		}
+		server.setupAPIKeyRoutes(router)
+	}
+
+	// Manage the roles deciding which tools callers reach
+	if access != nil {
+		server.setupRoleRoutes(router)
+	}
+
+	// Serve profiles and internal state on a separate listener for diagnosing production issues
+	if viper.GetBool("debug.enabled") {
+		server.debugServer = server.newDebugServer(auth)
+	}
+
+	return server, nil
+}
+
+// newImporterManager creates an importer manager for every supported spec
+// type, configured by the importer settings
+func newImporterManager(registry importer.ToolRegistry) *importer.ImporterManager {
+	manager := importer.NewImporterManager(registry)
+
+	// Default upstream protection for sources that do not declare their own limits
+	manager.SetDefaultSourceLimits(importer.SourceLimits{
+		MaxConcurrent:     viper.GetInt("importer.source_limits.max_concurrent"),
+		RequestsPerSecond: viper.GetFloat64("importer.source_limits.requests_per_second"),
+		QueueTimeoutMs:    viper.GetInt64("importer.source_limits.queue_timeout_ms"),
+	})
+
+	// Name tools of sources that do not declare their own strategy
+	manager.SetDefaultNaming(importer.NamingStrategy{
+		Case:      importer.NameCase(viper.GetString("importer.naming.case")),
+		VerbNoun:  viper.GetBool("importer.naming.verb_noun"),
+		MaxLength: viper.GetInt("importer.naming.max_length"),
+		Reserved:  viper.GetStringSlice("importer.naming.reserved"),
+	})
+
+	// Decide what imports do with tool names another source already registers
+	manager.SetCollisionPolicy(importer.CollisionPolicy(viper.GetString("importer.collision_policy")))
+
+	// Pace calls as upstream quotas reported in rate limit headers run out
+	manager.SetQuotaPolicy(importer.QuotaPolicy{
+		Threshold:        viper.GetFloat64("importer.quota.threshold"),
+		MaxWait:          time.Duration(viper.GetInt64("importer.quota.max_wait_ms")) * time.Millisecond,
+		ExhaustionWindow: time.Duration(viper.GetInt("importer.quota.exhaustion_window_hours")) * time.Hour,
+	})
+
+	// Offer fake data generators for the named schemas of imported specs
+	manager.SetSynthetic(importer.SyntheticConfig{
+		Enabled:  viper.GetBool("importer.synthetic.enabled"),
+		MaxCount: viper.GetInt("importer.synthetic.max_count"),
+	})
+
+	// Bound downloads of specs imported from URLs
+	manager.SetFetchPolicy(importer.FetchPolicy{
+		MaxBytes:     viper.GetInt64("importer.fetch.max_bytes"),
+		MaxRedirects: viper.GetInt("importer.fetch.max_redirects"),
+		Timeout:      time.Duration(viper.GetInt("importer.fetch.timeout_seconds")) * time.Second,
+	})
+
+	// Register importers
+	manager.RegisterImporter(importer.NewOpenAPIImporter())
+	graphQL := importer.NewGraphQLImporter()
+	graphQL.SetSelectionDepth(viper.GetInt("importer.graphql.selection_depth"))
+	manager.RegisterImporter(graphQL)
+	manager.RegisterImporter(importer.NewAsyncAPIImporter())
+	manager.RegisterImporter(importer.NewGRPCImporter())
+	return manager
+}
+
+// PreviewSpec returns the tool manifest an import of a specification would
+// produce under the configured importer settings, without starting a server
+func PreviewSpec(ctx context.Context, source importer.SpecSource) (*importer.SpecPreview, error) {
+	return newImporterManager(nil).PreviewSpec(ctx, source)
 }
 
 // Run starts the server and blocks until context is cancelled
 func (s *Server) Run(ctx context.Context) error {
 	s.logger.Info("Starting AionMCP server",
 		zap.String("http_port", s.httpServer.Addr),
-		zap.Int("grpc_port", viper.GetInt("server.grpc_port")))
+		zap.Int("grpc_port", viper.GetInt("server.grpc_port")),
+		zap.Bool("tls", s.tls != nil))
 
 	// Start HTTP server
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if s.tls != nil {
+			// The certificate comes from the TLS configuration
+			err = s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = s.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			s.logger.Error("HTTP server failed", zap.Error(err))
 		}
 	}()
@@ -161,11 +1012,23 @@ func (s *Server) Run(ctx context.Context) error {
 			return
 		}
 
+		s.setGRPCServing(true)
+		s.grpcListening.Store(true)
+		defer s.grpcListening.Store(false)
 		if err := s.grpcServer.Serve(lis); err != nil {
 			s.logger.Error("gRPC server failed", zap.Error(err))
 		}
 	}()
 
+	// Start the debug listener
+	if s.debugServer != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveDebug()
+		}()
+	}
+
 	s.logger.Info("AionMCP server started successfully")
 
 	// Wait for shutdown signal
@@ -184,11 +1047,18 @@ func (s *Server) Run(ctx context.Context) error {
 		s.logger.Error("Failed to shutdown HTTP server", zap.Error(err))
 	}
 
+	// Shutdown the debug listener
+	if s.debugServer != nil {
+		if err := s.debugServer.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error("Failed to shutdown debug listener", zap.Error(err))
+		}
+	}
+
 	// Shutdown gRPC server
-	s.grpcServer.GracefulStop()
+	s.stopGRPC(shutdownCtx)
 
-	// Stop file watcher
-	s.fileWatcher.Stop()
+	// Stop file watcher and flush background sinks
+	s.shutdownBackground()
 
 	// Wait for all goroutines to finish
 	s.wg.Wait()
@@ -196,37 +1066,406 @@ func (s *Server) Run(ctx context.Context) error {
 	return nil
 }
 
-// setupHTTPRoutes configures HTTP API routes
-func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager *importer.ImporterManager, fileWatcher *importer.FileWatcher, agentAPI *agent.AgentAPI, learningEngine *selflearn.Engine, logger *zap.Logger, serverCtx context.Context) {
-	api := router.Group("/api/v1")
+// Handler returns the HTTP handler, for serving the API in-process with
+// httptest instead of Run
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
+// Close stops background operations and releases storage of a server that
+// was used through Handler rather than Run
+func (s *Server) Close() {
+	s.shutdownBackground()
+}
+
+// shutdownBackground stops background operations shared by all transports
+func (s *Server) shutdownBackground() {
+	s.cancelFunc()
+
+	// Stop async invocations before the logs they write to are closed
+	s.agentServer.Close()
+
+	// Stop file watcher
+	s.fileWatcher.Stop()
+
+	// Stop polling remote specs
+	s.specPoller.Stop()
+
+	// Disconnect from message brokers
+	if err := s.messaging.Close(); err != nil {
+		s.logger.Error("Failed to close message broker connections", zap.Error(err))
+	}
+
+	// Flush the invocation log
+	if s.invocationLog != nil {
+		if err := s.invocationLog.Close(); err != nil {
+			s.logger.Error("Failed to close invocation log", zap.Error(err))
+		}
+	}
+
+	// Release the persisted result cache
+	if s.resultCache != nil {
+		if err := s.resultCache.Close(); err != nil {
+			s.logger.Error("Failed to close result cache", zap.Error(err))
+		}
+	}
+
+	// Release the API key store
+	if s.apiKeys != nil {
+		if err := s.apiKeys.Close(); err != nil {
+			s.logger.Error("Failed to close API key store", zap.Error(err))
+		}
+	}
+
+	// Release the role store
+	if err := s.access.close(); err != nil {
+		s.logger.Error("Failed to close role store", zap.Error(err))
+	}
+
+	// Release the audit log
+	if err := s.audit.Close(); err != nil {
+		s.logger.Error("Failed to close audit log", zap.Error(err))
+	}
+
+	// Release the store of imported sources
+	if s.sourceStore != nil {
+		if err := s.sourceStore.Close(); err != nil {
+			s.logger.Error("Failed to close source store", zap.Error(err))
+		}
+	}
+
+	// Release the session history
+	if err := s.sessionHistory.Close(); err != nil {
+		s.logger.Error("Failed to close session history", zap.Error(err))
+	}
+
+	// Release the agent event store
+	if s.eventStore != nil {
+		if err := s.eventStore.Close(); err != nil {
+			s.logger.Error("Failed to close event store", zap.Error(err))
+		}
+	}
+
+	// Flush and release learning storage
+	if err := s.learningEngine.Close(); err != nil {
+		s.logger.Error("Failed to close learning storage", zap.Error(err))
+	}
+
+	// Stop the demo upstream
+	if s.demo != nil {
+		if err := s.demo.Close(); err != nil {
+			s.logger.Error("Failed to stop demo environment", zap.Error(err))
+		}
+	}
+}
+
+// respondGroupOperation logs a bulk group operation and writes its per-source
+// results. The response is 207 Multi-Status when only some sources succeeded.
+func respondGroupOperation(c *gin.Context, logger *zap.Logger, operation, group string, results []importer.GroupOperationResult) {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+			logger.Warn("Group operation failed for specification",
+				zap.String("operation", operation),
+				zap.String("group", group),
+				zap.String("source_id", result.SourceID),
+				zap.String("error", result.Error))
+		}
+	}
+
+	logger.Info("Group operation completed",
+		zap.String("operation", operation),
+		zap.String("group", group),
+		zap.Int("sources", len(results)),
+		zap.Int("failed", failed))
+
+	status := http.StatusOK
+	switch {
+	case failed == len(results):
+		status = http.StatusInternalServerError
+	case failed > 0:
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"group":     group,
+		"operation": operation,
+		"results":   results,
+		"failed":    failed,
+	})
+}
+
+// recordHTTPInvocation exports a REST tool invocation to the invocation log, if enabled
+func recordHTTPInvocation(invocationLog *invocationlog.Exporter, auditLog *audit.Log, c *gin.Context, toolName, sourceType string, parameters any, err error, duration time.Duration, logger *zap.Logger) {
+	auditInvocation(auditLog, auditActor(c.Request.Context(), "http", "", c.ClientIP()), toolName, sourceType, parameters, err, logger)
+	if invocationLog == nil {
+		return
+	}
+
+	record := invocationlog.Record{
+		Actor: invocationlog.Actor{
+			Type:    "http",
+			ID:      c.ClientIP(),
+			Address: c.ClientIP(),
+		},
+		Tool:       toolName,
+		ToolSource: sourceType,
+		Transport:  "http",
+		ParamsHash: invocationlog.HashParams(parameters),
+		Outcome:    invocationlog.OutcomeSuccess,
+		LatencyMs:  duration.Milliseconds(),
+	}
+	if err != nil {
+		record.Outcome = invocationlog.OutcomeFailure
+		record.Error = err.Error()
+	}
+	invocationLog.Record(record)
+}
+
+// learningRecorder feeds agent tool executions into the learning engine
+type learningRecorder struct {
+	ctx    context.Context // Server-scoped, so records outlive the gRPC request
+	engine *selflearn.Engine
+}
+
+// RecordAgentExecution implements agent.ExecutionRecorder
+func (r *learningRecorder) RecordAgentExecution(_ context.Context, execution agent.AgentExecution) error {
+	ctx := selflearn.WithSessionID(r.ctx, execution.SessionID)
+	ctx = selflearn.WithRequestID(ctx, execution.InvocationID)
+	ctx = selflearn.WithAgent(ctx, execution.AgentID, execution.AgentName)
+	ctx = selflearn.WithAttempt(ctx, execution.Attempt)
+	ctx = selflearn.WithWorkspace(ctx, execution.Workspace)
+	ctx = selflearn.WithTenant(ctx, execution.Tenant)
+
+	sourceType := execution.SourceType
+	if sourceType == "" {
+		sourceType = "builtin"
+	}
+
+	return r.engine.RecordExecution(ctx, execution.ToolName, sourceType, execution.Input, execution.Output, execution.Err, execution.Duration)
+}
+
+// RecommendTools implements agent.ToolRecommender from learned tool usage
+func (r *learningRecorder) RecommendTools(ctx context.Context, afterTool string, limit int) ([]agent.ToolRecommendation, error) {
+	learned, err := r.engine.RecommendTools(ctx, afterTool, limit)
+	if err != nil {
+		return nil, err
+	}
+	recommendations := make([]agent.ToolRecommendation, len(learned))
+	for i, recommendation := range learned {
+		recommendations[i] = agent.ToolRecommendation(recommendation)
+	}
+	return recommendations, nil
+}
+
+// requireWritable rejects write operations while the server or the request's
+// workspace is read-only
+func requireWritable(readOnly *readonly.Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checkWritable(c, readOnly) {
+			c.Next()
+		}
+	}
+}
+
+// checkWritable aborts the request with 503 Service Unavailable and returns
+// false while the server or the request's workspace is read-only
+func checkWritable(c *gin.Context, readOnly *readonly.Mode) bool {
+	if err := readOnly.Check(c.GetHeader(readonly.WorkspaceHeader)); err != nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":     err.Error(),
+			"read_only": true,
+		})
+		return false
+	}
+	return true
+}
+
+// setupHTTPRoutes configures HTTP API routes
+func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager *importer.ImporterManager, fileWatcher *importer.FileWatcher, agentAPI *agent.AgentAPI, learningEngine *selflearn.Engine, invocationLog *invocationlog.Exporter, auditLog *audit.Log, readOnly *readonly.Mode, access *toolAccess, docsFormat *autodocs.Formatter, logger *zap.Logger, serverCtx context.Context) {
+	api := router.Group("/api/v1")
+
+	writable := requireWritable(readOnly)
+
+	// Health check
+	api.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "healthy",
+			"timestamp": time.Now().Unix(),
+			"version":   "0.1.0",
+			"iteration": "4",
+		})
+	})
+
+	// Agent integration routes
+	agentAPI.RegisterRoutes(api)
+
+	// Read-only mode administration
+	admin := api.Group("/admin")
+
+	admin.GET("/read-only", func(c *gin.Context) {
+		c.JSON(http.StatusOK, readOnly.Status())
+	})
+
+	admin.PUT("/read-only", func(c *gin.Context) {
+		var request struct {
+			Enabled   bool   `json:"enabled"`
+			Workspace string `json:"workspace"`
+			Reason    string `json:"reason"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		readOnly.Set(request.Workspace, request.Enabled, request.Reason)
+
+		logger.Warn("Read-only mode changed",
+			zap.Bool("enabled", request.Enabled),
+			zap.String("workspace", request.Workspace),
+			zap.String("reason", request.Reason))
+
+		c.JSON(http.StatusOK, readOnly.Status())
+	})
+
+	// Invoke two tools with the same parameters and diff their results
+	admin.POST("/tools/compare", writable, func(c *gin.Context) {
+		var request ToolComparisonRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		left, err := registry.Get(request.LeftTool)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("tool not found: %s", request.LeftTool)})
+			return
+		}
+		right, err := registry.Get(request.RightTool)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("tool not found: %s", request.RightTool)})
+			return
+		}
+		for _, tool := range []types.Tool{left, right} {
+			if !access.allowsInContext(c.Request.Context(), tool.Metadata()) {
+				c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("roles do not allow tool %s", tool.Name())})
+				return
+			}
+		}
+
+		comparison := compareTools(c.Request.Context(), left, right, request)
+
+		if !request.DryRun {
+			for _, side := range []ToolComparisonSide{comparison.Left, comparison.Right} {
+				var sideErr error
+				if side.Error != "" {
+					sideErr = fmt.Errorf("%s", side.Error)
+				}
+				recordHTTPInvocation(invocationLog, auditLog, c, side.Tool, side.Source, request.Parameters, sideErr, time.Duration(side.DurationMs)*time.Millisecond, logger)
+			}
+		}
+
+		logger.Info("Tools compared",
+			zap.String("left_tool", request.LeftTool),
+			zap.String("right_tool", request.RightTool),
+			zap.Bool("dry_run", request.DryRun),
+			zap.Int("differences", len(comparison.Differences)))
 
-	// Health check
-	api.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "healthy",
-			"timestamp": time.Now().Unix(),
-			"version":   "0.1.0",
-			"iteration": "4",
-		})
+		c.JSON(http.StatusOK, comparison)
 	})
 
-	// Agent integration routes
-	agentAPI.RegisterRoutes(api)
-
 	// MCP endpoints
 	mcp := api.Group("/mcp")
 
-	// List available tools
+	// List available tools, filtered by tag, source, type, status and name
 	mcp.GET("/tools", func(c *gin.Context) {
-		tools := registry.ListTools()
+		query, err := types.ParseToolQuery(c.Request.URL.Query())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tools := access.filter(c.Request.Context(), registry.FindTools(query))
+		if group := c.Query("group"); group != "" {
+			grouped := make([]ToolMetadata, 0, len(tools))
+			for _, tool := range tools {
+				if importerManager.GetToolGroup(tool.Name) == group {
+					grouped = append(grouped, tool)
+				}
+			}
+			tools = grouped
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"protocol": viper.GetString("mcp.protocol_version"),
 			"tools":    tools,
 		})
 	})
 
+	// Example parameters generated from a tool's input schema
+	mcp.GET("/tools/:name/example", func(c *gin.Context) {
+		tool, err := registry.Get(c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("tool not found: %s", c.Param("name"))})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"tool":       tool.Name(),
+			"parameters": exampleParameters(tool, c.Query("include_optional") == "true"),
+		})
+	})
+
+	// Smoke test a tool with generated parameters. Dry-run and mock modes
+	// never reach the upstream; live mode executes the tool.
+	mcp.POST("/tools/:name/smoke", func(c *gin.Context) {
+		tool, err := registry.Get(c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("tool not found: %s", c.Param("name"))})
+			return
+		}
+
+		var request SmokeTestRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+				return
+			}
+		}
+
+		if request.Mode == SmokeModeLive {
+			// Live smoke tests execute the tool like an invocation
+			if !requireScope(c, apikey.ScopeToolsInvoke) || !checkWritable(c, readOnly) {
+				return
+			}
+		}
+		if !access.allowsInContext(c.Request.Context(), tool.Metadata()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("roles do not allow tool %s", tool.Name())})
+			return
+		}
+
+		result, err := runSmokeTest(c.Request.Context(), tool, request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if result.Mode == SmokeModeLive && len(result.MissingRequired) == 0 {
+			var smokeErr error
+			if result.Error != "" {
+				smokeErr = fmt.Errorf("%s", result.Error)
+			}
+			recordHTTPInvocation(invocationLog, auditLog, c, result.Tool, toolSourceType(tool), result.Parameters, smokeErr, time.Duration(result.DurationMs)*time.Millisecond, logger)
+		}
+
+		logger.Info("Tool smoke tested",
+			zap.String("tool", result.Tool),
+			zap.String("mode", string(result.Mode)),
+			zap.Bool("passed", result.Passed))
+
+		c.JSON(http.StatusOK, result)
+	})
+
 	// Tool invocation endpoint
-	mcp.POST("/tools/:name/invoke", func(c *gin.Context) {
+	mcp.POST("/tools/:name/invoke", writable, func(c *gin.Context) {
 		toolName := c.Param("name")
 		startTime := time.Now()
 		
@@ -238,47 +1477,76 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 
 		// Get tool from registry
 		tool, err := registry.Get(toolName)
+		if errors.Is(err, ErrToolDisabled) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("tool is disabled: %s", toolName)})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("tool not found: %s", toolName)})
 			return
 		}
+		if !access.allowsInContext(c.Request.Context(), tool.Metadata()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("roles do not allow tool %s", toolName)})
+			return
+		}
+		setDeprecationHeaders(c.Writer.Header(), tool.Metadata())
 
 		// Execute tool and measure duration
-		result, err := tool.Execute(request)
+		result, err := tool.Execute(c.Request.Context(), request)
 		duration := time.Since(startTime)
 
-		// Record execution for learning (async, non-blocking)
-		// Capture all variables before goroutine to avoid race conditions
-		execErr := err
+		// Record execution for learning. The engine queues it for its writers,
+		// dropping it when the queue is full, so this never blocks.
 		metadata := tool.Metadata()
 		sourceType := "builtin"
 		if metadata.Source != "" {
 			sourceType = metadata.Source
 		}
-		
-		// Pass all captured variables as parameters to make dependencies explicit
-		go func(ctx context.Context, engine *selflearn.Engine, log *zap.Logger, tn, st string, req, res interface{}, execErr error, dur time.Duration) {
-			// Record the execution using server-scoped context
-			if recordErr := engine.RecordExecution(
-				ctx,
-				tn,
-				st,
-				req,
-				res,
-				execErr,
-				dur,
-			); recordErr != nil {
-				log.Warn("Failed to record execution for learning",
-					zap.String("tool", tn),
-					zap.Error(recordErr))
-			}
-		}(serverCtx, learningEngine, logger, toolName, sourceType, request, result, execErr, duration)
+		recordCtx := selflearn.WithWorkspace(serverCtx, c.GetHeader(readonly.WorkspaceHeader))
+		if recordErr := learningEngine.RecordExecution(recordCtx, toolName, sourceType, request, result, err, duration); recordErr != nil {
+			logger.Warn("Failed to record execution for learning",
+				zap.String("tool", toolName),
+				zap.Error(recordErr))
+		}
+
+		recordHTTPInvocation(invocationLog, auditLog, c, toolName, sourceType, request, err, duration, logger)
 
 		if err != nil {
 			logger.Error("Tool execution failed",
 				zap.String("tool", toolName),
 				zap.Duration("duration", duration),
 				zap.Error(err))
+			if errors.Is(err, context.DeadlineExceeded) {
+				c.JSON(http.StatusGatewayTimeout, gin.H{"error": err.Error()})
+				return
+			}
+			var validationErr *schema.ValidationError
+			if errors.As(err, &validationErr) {
+				// Invalid parameters are the caller's fault, an invalid result the upstream's
+				statusCode := http.StatusBadRequest
+				if validationErr.Subject == "output" {
+					statusCode = http.StatusBadGateway
+				}
+				c.JSON(statusCode, gin.H{
+					"error":      err.Error(),
+					"violations": validationErr.Errors,
+				})
+				return
+			}
+			if retryable, ok := types.AsRetryable(err); ok {
+				statusCode := http.StatusServiceUnavailable
+				if retryable.RateLimited() {
+					statusCode = http.StatusTooManyRequests
+				}
+				if retryable.RetryAfter > 0 {
+					c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(retryable.RetryAfter.Seconds())), 10))
+				}
+				c.JSON(statusCode, gin.H{
+					"error":          err.Error(),
+					"retry_after_ms": retryable.RetryAfter.Milliseconds(),
+				})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -299,38 +1567,64 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 	// List specification sources
 	specs.GET("/", func(c *gin.Context) {
 		sources := importerManager.ListSources()
+		if group := c.Query("group"); group != "" {
+			sources = importerManager.ListSourcesInGroup(group)
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"sources": sources,
 		})
 	})
 
 	// Import a new specification
-	specs.POST("/", func(c *gin.Context) {
+	specs.POST("/", writable, func(c *gin.Context) {
 		var req struct {
-			ID          string            `json:"id" binding:"required"`
-			Type        string            `json:"type" binding:"required"`
-			Path        string            `json:"path" binding:"required"`
-			Name        string            `json:"name"`
-			Description string            `json:"description"`
-			Metadata    map[string]string `json:"metadata"`
-			EnableWatch bool              `json:"enable_watch"`
+			ID          string                   `json:"id" binding:"required"`
+			Type        string                   `json:"type" binding:"required"`
+			Path        string                   `json:"path" binding:"required"`
+			Name        string                   `json:"name"`
+			Description string                   `json:"description"`
+			Group       string                   `json:"group"`
+			Tenant      string                   `json:"tenant"`
+			Metadata    map[string]string        `json:"metadata"`
+			EnableWatch bool                     `json:"enable_watch"`
+			Limits      *importer.SourceLimits   `json:"limits"`
+			Naming      *importer.NamingStrategy `json:"naming"`
+			Auth        *importer.SpecAuth       `json:"auth"`
+			PollSeconds int                      `json:"poll_interval_seconds"`
+			Credentials map[string]*importer.APICredential `json:"credentials"`
+			Environments map[string]*importer.SourceEnvironment `json:"environments"`
+			Environment  string                                 `json:"environment"`
+			Namespace       string                   `json:"namespace"`
+			CollisionPolicy importer.CollisionPolicy `json:"collision_policy"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		c.Set(auditTargetKey, req.ID)
 
 		// Create spec source
 		source := importer.SpecSource{
-			ID:          req.ID,
-			Type:        importer.SpecType(req.Type),
-			Path:        req.Path,
-			Name:        req.Name,
-			Description: req.Description,
-			Metadata:    req.Metadata,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			ID:                  req.ID,
+			Type:                importer.SpecType(req.Type),
+			Path:                req.Path,
+			Name:                req.Name,
+			Description:         req.Description,
+			Group:               req.Group,
+			Tenant:              req.Tenant,
+			Metadata:            req.Metadata,
+			Limits:              req.Limits,
+			Naming:              req.Naming,
+			Auth:                req.Auth,
+			Credentials:         req.Credentials,
+			Environments:        req.Environments,
+			Environment:         req.Environment,
+			Namespace:           req.Namespace,
+			CollisionPolicy:     req.CollisionPolicy,
+			PollIntervalSeconds: req.PollSeconds,
+			CreatedAt:           time.Now(),
+			UpdatedAt:           time.Now(),
 		}
 
 		// Import the specification
@@ -363,6 +1657,92 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 		})
 	})
 
+	// Dry-run an import: return the tool manifest it would produce without
+	// registering anything, so spec authors can iterate on naming quickly
+	specs.POST("/preview", func(c *gin.Context) {
+		var req struct {
+			ID          string                   `json:"id" binding:"required"`
+			Type        string                   `json:"type" binding:"required"`
+			Path        string                   `json:"path" binding:"required"`
+			Name        string                   `json:"name"`
+			Description string                   `json:"description"`
+			Group       string                   `json:"group"`
+			Metadata    map[string]string        `json:"metadata"`
+			Naming      *importer.NamingStrategy `json:"naming"`
+			Auth        *importer.SpecAuth       `json:"auth"`
+			Environments map[string]*importer.SourceEnvironment `json:"environments"`
+			Environment  string                                 `json:"environment"`
+			Namespace       string                   `json:"namespace"`
+			CollisionPolicy importer.CollisionPolicy `json:"collision_policy"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		preview, err := importerManager.PreviewSpec(c.Request.Context(), importer.SpecSource{
+			ID:          req.ID,
+			Type:        importer.SpecType(req.Type),
+			Path:        req.Path,
+			Name:        req.Name,
+			Description: req.Description,
+			Group:       req.Group,
+			Metadata:    req.Metadata,
+			Naming:      req.Naming,
+			Auth:        req.Auth,
+			Environments: req.Environments,
+			Environment:  req.Environment,
+			Namespace:       req.Namespace,
+			CollisionPolicy: req.CollisionPolicy,
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"preview": preview})
+	})
+
+	// Lint a specification without importing it: missing operationIds,
+	// unresolved references and tool names that would collide
+	specs.POST("/validate", func(c *gin.Context) {
+		var req struct {
+			ID           string                                 `json:"id" binding:"required"`
+			Type         string                                 `json:"type" binding:"required"`
+			Path         string                                 `json:"path" binding:"required"`
+			Metadata     map[string]string                      `json:"metadata"`
+			Naming       *importer.NamingStrategy               `json:"naming"`
+			Auth         *importer.SpecAuth                     `json:"auth"`
+			Credentials  map[string]*importer.APICredential     `json:"credentials"`
+			Environments map[string]*importer.SourceEnvironment `json:"environments"`
+			Environment  string                                 `json:"environment"`
+			Namespace       string                   `json:"namespace"`
+			CollisionPolicy importer.CollisionPolicy `json:"collision_policy"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validation, err := importerManager.ValidateSpec(c.Request.Context(), importer.SpecSource{
+			ID:           req.ID,
+			Type:         importer.SpecType(req.Type),
+			Path:         req.Path,
+			Metadata:     req.Metadata,
+			Naming:       req.Naming,
+			Auth:         req.Auth,
+			Credentials:  req.Credentials,
+			Environments: req.Environments,
+			Environment:  req.Environment,
+			Namespace:       req.Namespace,
+			CollisionPolicy: req.CollisionPolicy,
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"validation": validation})
+	})
+
 	// Get specification details
 	specs.GET("/:id", func(c *gin.Context) {
 		sourceID := c.Param("id")
@@ -372,14 +1752,72 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"source":      source,
 			"is_watching": fileWatcher.IsWatching(sourceID),
+		}
+		if stats, throttled := importerManager.GetThrottleStats(sourceID); throttled {
+			response["throttle"] = stats
+		}
+		if quotas := importerManager.GetQuotas(sourceID); len(quotas) > 0 {
+			response["quotas"] = quotas
+		}
+
+		c.JSON(http.StatusOK, response)
+	})
+
+	// Preview the tool names a naming strategy gives a source; without a body
+	// the source's current strategy is previewed
+	specs.POST("/:id/naming/preview", func(c *gin.Context) {
+		var strategy *importer.NamingStrategy
+		if c.Request.ContentLength != 0 {
+			strategy = &importer.NamingStrategy{}
+			if err := c.ShouldBindJSON(strategy); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		sourceID := c.Param("id")
+		if _, exists := importerManager.GetSource(sourceID); !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "specification not found"})
+			return
+		}
+		previews, err := importerManager.PreviewNaming(c.Request.Context(), sourceID, strategy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		changed := 0
+		for _, preview := range previews {
+			if preview.Changed {
+				changed++
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"source_id": sourceID,
+			"tools":     previews,
+			"changed":   changed,
+		})
+	})
+
+	// Upstream throttling statistics for all rate or concurrency limited sources
+	specs.GET("/throttling", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"sources": importerManager.ListThrottleStats(),
+		})
+	})
+
+	// Upstream quotas reported in rate limit response headers
+	specs.GET("/quotas", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"quotas":  importerManager.ListQuotas(),
+			"summary": importerManager.GetQuotaSummary(),
 		})
 	})
 
 	// Reload a specification
-	specs.POST("/:id/reload", func(c *gin.Context) {
+	specs.POST("/:id/reload", writable, func(c *gin.Context) {
 		sourceID := c.Param("id")
 
 		result, err := importerManager.ReloadSpec(c.Request.Context(), sourceID)
@@ -400,8 +1838,33 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 		})
 	})
 
+	// Get the tool catalog changelog for a specification
+	specs.GET("/:id/changelog", func(c *gin.Context) {
+		sourceID := c.Param("id")
+		if _, exists := importerManager.GetSource(sourceID); !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "specification not found"})
+			return
+		}
+
+		changes := importerManager.GetCatalogChanges(sourceID)
+		breaking := false
+		for _, diff := range changes {
+			if diff.HasBreakingChanges() {
+				breaking = true
+				break
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"source_id":    sourceID,
+			"changes":      changes,
+			"has_breaking": breaking,
+			"content":      autodocs.RenderToolChangelog(sourceID, changes, docsFormat),
+		})
+	})
+
 	// Remove a specification
-	specs.DELETE("/:id", func(c *gin.Context) {
+	specs.DELETE("/:id", writable, func(c *gin.Context) {
 		sourceID := c.Param("id")
 
 		// Stop watching if enabled
@@ -428,6 +1891,118 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 		c.JSON(http.StatusNoContent, nil)
 	})
 
+	// Spec source groups
+	groups := specs.Group("/groups")
+
+	// List groups with their sources and tool counts
+	groups.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"groups": importerManager.ListGroups(),
+		})
+	})
+
+	// Get group metrics
+	groups.GET("/:group", func(c *gin.Context) {
+		group, exists := importerManager.GetGroup(c.Param("group"))
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+
+		watching := 0
+		for _, sourceID := range group.SourceIDs {
+			if fileWatcher.IsWatching(sourceID) {
+				watching++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"group":    group,
+			"sources":  importerManager.ListSourcesInGroup(group.Name),
+			"watching": watching,
+		})
+	})
+
+	// Reload every specification in a group
+	groups.POST("/:group/reload", writable, func(c *gin.Context) {
+		groupName := c.Param("group")
+		if _, exists := importerManager.GetGroup(groupName); !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+
+		results := importerManager.ReloadGroup(c.Request.Context(), groupName)
+		respondGroupOperation(c, logger, "reload", groupName, results)
+	})
+
+	// Remove every specification in a group
+	groups.DELETE("/:group", writable, func(c *gin.Context) {
+		groupName := c.Param("group")
+		if _, exists := importerManager.GetGroup(groupName); !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+
+		for _, source := range importerManager.ListSourcesInGroup(groupName) {
+			if fileWatcher.IsWatching(source.ID) {
+				if err := fileWatcher.UnwatchSpec(source.ID); err != nil {
+					logger.Warn("Failed to stop watching specification",
+						zap.String("source_id", source.ID),
+						zap.Error(err))
+				}
+			}
+		}
+
+		results := importerManager.RemoveGroup(c.Request.Context(), groupName)
+		respondGroupOperation(c, logger, "remove", groupName, results)
+	})
+
+	// Enable file watching for every specification in a group
+	groups.POST("/:group/watch", writable, func(c *gin.Context) {
+		groupName := c.Param("group")
+		sources := importerManager.ListSourcesInGroup(groupName)
+		if len(sources) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+
+		results := make([]importer.GroupOperationResult, 0, len(sources))
+		for _, source := range sources {
+			result := importer.GroupOperationResult{SourceID: source.ID, Success: true}
+			if !fileWatcher.IsWatching(source.ID) {
+				if err := fileWatcher.WatchSpec(source); err != nil {
+					result.Success = false
+					result.Error = err.Error()
+				}
+			}
+			results = append(results, result)
+		}
+		respondGroupOperation(c, logger, "watch", groupName, results)
+	})
+
+	// Disable file watching for every specification in a group
+	groups.DELETE("/:group/watch", writable, func(c *gin.Context) {
+		groupName := c.Param("group")
+		sources := importerManager.ListSourcesInGroup(groupName)
+		if len(sources) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+
+		results := make([]importer.GroupOperationResult, 0, len(sources))
+		for _, source := range sources {
+			result := importer.GroupOperationResult{SourceID: source.ID, Success: true}
+			if fileWatcher.IsWatching(source.ID) {
+				if err := fileWatcher.UnwatchSpec(source.ID); err != nil {
+					result.Success = false
+					result.Error = err.Error()
+				}
+			}
+			results = append(results, result)
+		}
+		respondGroupOperation(c, logger, "unwatch", groupName, results)
+	})
+
 	// List supported specification types
 	specs.GET("/types", func(c *gin.Context) {
 		types := importerManager.GetSupportedTypes()
@@ -441,12 +2016,18 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 
 	// Get overall learning statistics
 	learning.GET("/stats", func(c *gin.Context) {
-		stats, err := learningEngine.GetStats(c.Request.Context())
+		stats, err := learningEngine.GetStatsForTenants(c.Request.Context(), access.tenantScope(c.Request.Context()))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get learning stats"})
 			return
 		}
-		c.JSON(http.StatusOK, stats)
+		workspace := c.GetHeader(readonly.WorkspaceHeader)
+		stats.RecentPatterns = learningEngine.VisiblePatterns(stats.RecentPatterns, workspace)
+		stats.ActiveInsights = learningEngine.VisibleInsights(stats.ActiveInsights, workspace)
+		c.JSON(http.StatusOK, struct {
+			selflearn.LearningStats
+			Cache *ResultCacheStats `json:"cache,omitempty"` // Nil while result caching is disabled
+		}{stats, registry.ResultCacheStats()})
 	})
 
 	// Get insights
@@ -467,7 +2048,8 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get insights"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"insights": insights})
+		insights = learningEngine.InsightsForTenants(insights, access.tenantScope(c.Request.Context()))
+		c.JSON(http.StatusOK, gin.H{"insights": learningEngine.VisibleInsights(insights, c.GetHeader(readonly.WorkspaceHeader))})
 	})
 
 	// Get patterns
@@ -481,7 +2063,8 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tool patterns"})
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"patterns": patterns})
+			patterns = learningEngine.PatternsForTenants(patterns, access.tenantScope(c.Request.Context()))
+			c.JSON(http.StatusOK, gin.H{"patterns": learningEngine.VisiblePatterns(patterns, c.GetHeader(readonly.WorkspaceHeader))})
 			return
 		}
 
@@ -490,7 +2073,8 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get patterns"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"patterns": patterns})
+		patterns = learningEngine.PatternsForTenants(patterns, access.tenantScope(c.Request.Context()))
+		c.JSON(http.StatusOK, gin.H{"patterns": learningEngine.VisiblePatterns(patterns, c.GetHeader(readonly.WorkspaceHeader))})
 	})
 
 	// Get tool-specific insights
@@ -501,11 +2085,20 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tool insights"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"tool_name": toolName, "insights": insights})
+		insights = learningEngine.InsightsForTenants(insights, access.tenantScope(c.Request.Context()))
+		c.JSON(http.StatusOK, gin.H{"tool_name": toolName, "insights": learningEngine.VisibleInsights(insights, c.GetHeader(readonly.WorkspaceHeader))})
 	})
 
 	// Trigger manual analysis
 	learning.POST("/analyze", func(c *gin.Context) {
+		// Under pressure analysis waits unless explicitly forced
+		if c.Query("force") != "true" && learningEngine.DeferAnalysis() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":    "Pattern analysis deferred while learning is under pressure; retry later or pass force=true",
+				"shedding": learningEngine.PressureStatus(),
+			})
+			return
+		}
 		patterns, err := learningEngine.AnalyzePatterns(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze patterns"})
@@ -523,9 +2116,150 @@ func setupHTTPRoutes(router *gin.Engine, registry *ToolRegistry, importerManager
 		})
 	})
 
+	// Export per-invocation records for offline analysis, streamed as CSV or Parquet.
+	// from and to are accepted as aliases of start and end.
+	exportExecutions := func(c *gin.Context) {
+		options := selflearn.ExportOptions{
+			Format:    selflearn.ExportFormat(c.DefaultQuery("format", string(selflearn.ExportFormatCSV))),
+			SessionID: c.Query("session_id"),
+			ToolName:  c.Query("tool"),
+			Tenants:   access.tenantScope(c.Request.Context()),
+			End:       time.Now(),
+		}
+		options.Start = options.End.Add(-24 * time.Hour)
+
+		if start := c.DefaultQuery("from", c.Query("start")); start != "" {
+			parsed, err := time.Parse(time.RFC3339, start)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC3339 timestamp"})
+				return
+			}
+			options.Start = parsed
+		}
+		if end := c.DefaultQuery("to", c.Query("end")); end != "" {
+			parsed, err := time.Parse(time.RFC3339, end)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC3339 timestamp"})
+				return
+			}
+			options.End = parsed
+		}
+		if columns := c.Query("columns"); columns != "" {
+			options.Columns = strings.Split(columns, ",")
+		}
+
+		// Validate before streaming so errors can still be reported as JSON
+		if err := options.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contentType := "text/csv"
+		if options.Format == selflearn.ExportFormatParquet {
+			contentType = "application/vnd.apache.parquet"
+		}
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"executions-%s.%s\"", options.End.UTC().Format("20060102T150405Z"), options.Format))
+		c.Status(http.StatusOK)
+
+		rows, err := learningEngine.ExportExecutions(c.Request.Context(), c.Writer, options)
+		if err != nil {
+			// Headers are already sent; the truncated body is all the client gets
+			logger.Error("Failed to export executions",
+				zap.Int("rows", rows),
+				zap.Error(err))
+			return
+		}
+		logger.Info("Exported executions",
+			zap.String("format", string(options.Format)),
+			zap.Int("rows", rows))
+	}
+	learning.GET("/executions/export", exportExecutions)
+	learning.GET("/export", exportExecutions)
+
+	// Hourly aggregates retention kept of removed executions
+	learning.GET("/aggregates", func(c *gin.Context) {
+		end := time.Now()
+		start := end.Add(-7 * 24 * time.Hour)
+		if value := c.Query("start"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "start must be an RFC3339 timestamp"})
+				return
+			}
+			start = parsed
+		}
+		if value := c.Query("end"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "end must be an RFC3339 timestamp"})
+				return
+			}
+			end = parsed
+		}
+
+		aggregates, err := learningEngine.GetHourlyAggregates(c.Request.Context(), c.Query("tool"), start, end)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get hourly aggregates"})
+			return
+		}
+		aggregates = learningEngine.AggregatesForTenants(aggregates, access.tenantScope(c.Request.Context()))
+		c.JSON(http.StatusOK, gin.H{"aggregates": aggregates})
+	})
+
+	// Bucketed metrics for dashboards, from executions and hourly aggregates
+	learning.GET("/timeseries", func(c *gin.Context) {
+		options := selflearn.TimeSeriesOptions{
+			ToolName: c.Query("tool"),
+			Metric:   selflearn.TimeSeriesMetric(c.DefaultQuery("metric", string(selflearn.TimeSeriesThroughput))),
+			Tenants:  access.tenantScope(c.Request.Context()),
+			End:      time.Now(),
+		}
+		options.Start = options.End.Add(-24 * time.Hour)
+
+		step, err := time.ParseDuration(c.DefaultQuery("step", "5m"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "step must be a duration such as 5m or 1h"})
+			return
+		}
+		options.Step = step
+		if start := c.DefaultQuery("from", c.Query("start")); start != "" {
+			parsed, err := time.Parse(time.RFC3339, start)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC3339 timestamp"})
+				return
+			}
+			options.Start = parsed
+		}
+		if end := c.DefaultQuery("to", c.Query("end")); end != "" {
+			parsed, err := time.Parse(time.RFC3339, end)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC3339 timestamp"})
+				return
+			}
+			options.End = parsed
+		}
+		if err := options.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		series, err := learningEngine.GetTimeSeries(c.Request.Context(), options)
+		if err != nil {
+			logger.Error("Failed to compute time series", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute time series"})
+			return
+		}
+		c.JSON(http.StatusOK, series)
+	})
+
 	// Get/update learning configuration
 	learning.GET("/config", func(c *gin.Context) {
-		config := learningEngine.GetConfig()
-		c.JSON(http.StatusOK, config)
+		c.JSON(http.StatusOK, struct {
+			selflearn.CollectionConfig
+			Shedding  selflearn.PressureStatus  `json:"shedding"`
+			Retention *selflearn.RetentionRun   `json:"last_retention"`
+			Redaction selflearn.RedactionConfig `json:"redaction"`
+		}{learningEngine.GetConfig(), learningEngine.PressureStatus(), learningEngine.LastRetentionRun(), learningEngine.Redaction()})
 	})
 }
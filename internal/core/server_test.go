@@ -0,0 +1,407 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/aionmcp/aionmcp/pkg/apikey"
+	"github.com/aionmcp/aionmcp/pkg/capabilities"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerLearningConfig(t *testing.T) {
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 0.0)
+	defer viper.Reset()
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	// A zero sample rate reaches the engine, which still records failures
+	ctx := context.Background()
+	require.NoError(t, server.learningEngine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/learning/config", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var config struct {
+		selflearn.CollectionConfig
+		Shedding selflearn.PressureStatus `json:"shedding"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &config))
+	assert.Zero(t, config.SampleRate)
+	assert.True(t, config.AlwaysSampleErrors)
+	assert.Equal(t, int64(1), config.Shedding.SampledOut)
+
+	viper.Set("learning.always_sample_errors", false)
+	quiet, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer quiet.Close()
+	assert.False(t, quiet.learningEngine.GetConfig().AlwaysSampleErrors)
+
+	viper.Set("learning.sample_rate", 1.5)
+	_, err = NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	assert.ErrorContains(t, err, "learning.sample_rate")
+}
+
+func TestInMemoryServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	viper.Set("auth.api_keys.enabled", true)
+	viper.Set("auth.api_keys.bootstrap_key", "integration-admin-key")
+	defer viper.Reset()
+
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{Clock: fake})
+	require.NoError(t, err)
+	defer server.Close()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	call := func(method, path, key, body string) *http.Response {
+		request, err := http.NewRequest(method, httpServer.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set(apikey.Header, key)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		t.Cleanup(func() { response.Body.Close() })
+		return response
+	}
+
+	// Tools run and are learned from
+	response := call("POST", "/api/v1/mcp/tools/echo/invoke", "integration-admin-key", `{"message": "hi"}`)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Eventually(t, func() bool {
+		var stats selflearn.LearningStats
+		response := call("GET", "/api/v1/learning/stats", "", "")
+		return json.NewDecoder(response.Body).Decode(&stats) == nil && stats.TotalExecutions == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Key expiry follows the injected clock
+	response = call("POST", "/api/v1/admin/apikeys", "integration-admin-key", `{"name": "ci", "scopes": ["tools:invoke"], "expires_in_seconds": 60}`)
+	require.Equal(t, http.StatusCreated, response.StatusCode)
+	var created struct {
+		APIKey string `json:"api_key"`
+	}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&created))
+	assert.Equal(t, http.StatusOK, call("POST", "/api/v1/mcp/tools/echo/invoke", created.APIKey, `{"message": "hi"}`).StatusCode)
+	fake.Advance(2 * time.Minute)
+	assert.Equal(t, http.StatusUnauthorized, call("POST", "/api/v1/mcp/tools/echo/invoke", created.APIKey, `{"message": "hi"}`).StatusCode)
+
+	// Clients discover the enabled features without credentials
+	response = call("GET", "/api/v1/capabilities", "", "")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var features capabilities.Matrix
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&features))
+	assert.Equal(t, agent.ServerVersion, features.ServerVersion)
+	assert.True(t, features.Features["api_key_auth"].Enabled)
+	assert.False(t, features.Features["oidc_auth"].Enabled)
+	assert.True(t, features.Features["learning"].Enabled)
+	assert.Equal(t, mcpProtocolVersions[0], features.Features["mcp_jsonrpc"].Version)
+	assert.True(t, features.Features["tool_execution"].Enabled)
+
+	// Learning retention follows the injected clock too
+	fake.Advance(31 * 24 * time.Hour)
+	require.NoError(t, server.learningEngine.RunMaintenance(context.Background()))
+	stats, err := server.learningEngine.GetStats(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalExecutions)
+
+	// Nothing reached the disk
+	entries, err := os.ReadDir(".")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestConfiguredClock(t *testing.T) {
+	defer viper.Reset()
+	logger := zap.NewNop()
+
+	c, err := configuredClock(logger)
+	require.NoError(t, err)
+	assert.IsType(t, clock.Real{}, c)
+
+	viper.Set("clock.mode", "simulated")
+	viper.Set("clock.simulation.start", "2030-01-01T00:00:00Z")
+	viper.Set("clock.simulation.speed", 1000.0)
+	c, err = configuredClock(logger)
+	require.NoError(t, err)
+	require.IsType(t, &clock.Simulated{}, c)
+	assert.Equal(t, 1000.0, c.(*clock.Simulated).Speed())
+	assert.Equal(t, 2030, c.Now().Year())
+
+	viper.Set("clock.simulation.speed", 0)
+	_, err = configuredClock(logger)
+	assert.Error(t, err)
+	viper.Set("clock.simulation.speed", 10)
+	viper.Set("clock.simulation.start", "tomorrow")
+	_, err = configuredClock(logger)
+	assert.Error(t, err)
+	viper.Set("clock.mode", "sundial")
+	_, err = configuredClock(logger)
+	assert.Error(t, err)
+}
+
+func TestSpecPreview(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("server.read_only", true)
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	tools := server.toolRegistry.Count()
+
+	preview := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/specs/preview", strings.NewReader(body)))
+		return recorder
+	}
+
+	// The manifest is returned even in read-only mode, and nothing is registered
+	response := preview(`{"id": "pets", "type": "openapi", "path": "../../examples/specs/petstore.yaml", "naming": {"case": "snake"}}`)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	var body struct {
+		Preview importer.SpecPreview `json:"preview"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	require.NotEmpty(t, body.Preview.Tools)
+	names := make([]string, len(body.Preview.Tools))
+	for i, tool := range body.Preview.Tools {
+		names[i] = tool.Name
+	}
+	assert.Contains(t, names, "openapi.pets.list_pets")
+	assert.Equal(t, tools, server.toolRegistry.Count())
+	assert.Empty(t, server.importerManager.ListSources())
+
+	response = preview(`{"id": "pets", "type": "openapi", "path": "../../examples/specs/missing.yaml"}`)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestSpecValidate(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("server.read_only", true)
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	validate := func(body string) (int, importer.SpecValidation) {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/specs/validate", strings.NewReader(body)))
+		var response struct {
+			Validation importer.SpecValidation `json:"validation"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+		return recorder.Code, response.Validation
+	}
+
+	// Specs are linted in read-only mode without being imported
+	code, validation := validate(`{"id": "pets", "type": "openapi", "path": "../../examples/specs/petstore.yaml"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, validation.Valid, validation.Errors)
+	assert.NotZero(t, validation.ToolCount)
+	assert.Empty(t, server.importerManager.ListSources())
+
+	// Invalid specs are reported, not refused
+	code, validation = validate(`{"id": "pets", "type": "openapi", "path": "../../examples/specs/missing.yaml"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, validation.Valid)
+	require.NotEmpty(t, validation.Errors)
+	assert.Equal(t, importer.LintInvalidSpec, validation.Errors[0].Code)
+
+	code, _ = validate(`{"id": "pets", "type": "wsdl", "path": "service.wsdl"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestServerLearningRetention(t *testing.T) {
+	t.Chdir(t.TempDir())
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	viper.Set("learning.retention_days", 30)
+	viper.Set("learning.retention.interval_minutes", 60)
+	viper.Set("learning.retention.downsample", true)
+	viper.Set("learning.retention.compact", true)
+	defer viper.Reset()
+
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{Clock: fake})
+	require.NoError(t, err)
+	defer server.Close()
+	stats := func() selflearn.LearningStats {
+		stats, err := server.learningEngine.GetStats(context.Background())
+		require.NoError(t, err)
+		return stats
+	}
+	get := func(path string, body interface{}) {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), body))
+	}
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/mcp/tools/echo/invoke", strings.NewReader(`{"message": "hi"}`)))
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+	require.Eventually(t, func() bool { return stats().TotalExecutions == 2 }, time.Second, 10*time.Millisecond)
+
+	// Past the retention period the scheduled job folds the executions into an hourly aggregate
+	fake.Advance(31 * 24 * time.Hour)
+	require.Eventually(t, func() bool {
+		fake.Advance(time.Hour)
+		run := server.learningEngine.LastRetentionRun()
+		return run != nil && run.Compacted && stats().TotalExecutions == 0
+	}, time.Second, 10*time.Millisecond)
+
+	var config struct {
+		LastRetention selflearn.RetentionRun `json:"last_retention"`
+	}
+	get("/api/v1/learning/config", &config)
+	assert.True(t, config.LastRetention.Compacted)
+	assert.Empty(t, config.LastRetention.Error)
+
+	var response struct {
+		Aggregates []selflearn.HourlyAggregate `json:"aggregates"`
+	}
+	get("/api/v1/learning/aggregates?tool=echo&start=2026-03-01T00:00:00Z&end=2026-03-02T00:00:00Z", &response)
+	require.Len(t, response.Aggregates, 1)
+	assert.Equal(t, "echo", response.Aggregates[0].ToolName)
+	assert.True(t, start.Equal(response.Aggregates[0].Hour))
+	assert.Equal(t, int64(2), response.Aggregates[0].Executions)
+	assert.Equal(t, int64(2), response.Aggregates[0].Successes)
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/learning/aggregates?start=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestServerExecutionsExport(t *testing.T) {
+	t.Chdir(t.TempDir())
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	defer viper.Reset()
+
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC))
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{Clock: fake})
+	require.NoError(t, err)
+	defer server.Close()
+
+	invoke := func(tool, body string) {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/mcp/tools/"+tool+"/invoke", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	recorded := func(executions int64) func() bool {
+		return func() bool {
+			stats, err := server.learningEngine.GetStats(context.Background())
+			require.NoError(t, err)
+			return stats.TotalExecutions == executions
+		}
+	}
+	invoke("echo", `{"message": "hi"}`)
+	invoke("status", `{}`)
+	require.Eventually(t, recorded(2), time.Second, 10*time.Millisecond)
+	fake.Advance(2 * time.Hour)
+	invoke("echo", `{"message": "later"}`)
+	require.Eventually(t, recorded(3), time.Second, 10*time.Millisecond)
+
+	// Only echo executions inside the time range are exported
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/api/v1/learning/executions/export?format=csv&from=2026-03-01T11:00:00Z&to=2026-03-01T13:00:00Z&tool=echo&columns=tool,outcome", nil))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "tool,outcome\necho,success\n", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/learning/executions/export?from=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestServerLearningTimeSeries(t *testing.T) {
+	t.Chdir(t.TempDir())
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("learning.enabled", true)
+	viper.Set("learning.sample_rate", 1.0)
+	defer viper.Reset()
+
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 12, 1, 0, 0, time.UTC))
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{Clock: fake})
+	require.NoError(t, err)
+	defer server.Close()
+
+	recorded := func(executions int64) func() bool {
+		return func() bool {
+			stats, err := server.learningEngine.GetStats(context.Background())
+			require.NoError(t, err)
+			return stats.TotalExecutions == executions
+		}
+	}
+	invoke := func(tool string) {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/mcp/tools/"+tool+"/invoke", strings.NewReader(`{"message": "hi"}`)))
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	invoke("echo")
+	invoke("echo")
+	invoke("status")
+	require.Eventually(t, recorded(3), time.Second, 10*time.Millisecond)
+	fake.Advance(10 * time.Minute)
+	invoke("echo")
+	require.Eventually(t, recorded(4), time.Second, 10*time.Millisecond)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	// Buckets start at step boundaries and empty ones are kept for plotting
+	recorder := get("/api/v1/learning/timeseries?tool=echo&metric=throughput&step=5m&from=2026-03-01T12:00:00Z&to=2026-03-01T12:14:00Z")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var series selflearn.TimeSeries
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &series))
+	assert.Equal(t, selflearn.TimeSeriesThroughput, series.Metric)
+	assert.Equal(t, 5*time.Minute, series.Step)
+	require.Len(t, series.Points, 3)
+	for i, value := range []float64{2, 0, 1} {
+		assert.True(t, time.Date(2026, time.March, 1, 12, 5*i, 0, 0, time.UTC).Equal(series.Points[i].Time))
+		assert.Equal(t, value, series.Points[i].Value, "point %d", i)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/learning/timeseries?metric=p42").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/learning/timeseries?step=soon").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/learning/timeseries?step=1s").Code)
+}
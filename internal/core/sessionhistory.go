@@ -0,0 +1,24 @@
+package core
+
+import (
+	"github.com/aionmcp/aionmcp/pkg/sessionhistory"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// openSessionHistory opens the store of recent tool usage per agent session.
+// Without agent.history.path, or with in-memory storage, histories are kept
+// in memory only.
+func openSessionHistory(logger *zap.Logger) (*sessionhistory.Store, error) {
+	size := viper.GetInt("agent.history.size")
+	path := viper.GetString("agent.history.path")
+	if path == "" || inMemoryStorage() {
+		return sessionhistory.OpenMemory(size), nil
+	}
+	store, err := sessionhistory.Open(path, size)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Session history persisted", zap.String("path", path))
+	return store, nil
+}
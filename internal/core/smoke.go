@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// SmokeMode selects how far a smoke test goes
+type SmokeMode string
+
+const (
+	SmokeModeDryRun SmokeMode = "dry_run" // Generate parameters only
+	SmokeModeMock   SmokeMode = "mock"    // Also synthesize a result from the output schema
+	SmokeModeLive   SmokeMode = "live"    // Execute the tool with the generated parameters
+)
+
+// SmokeTestRequest is the body of the tool smoke test endpoint
+type SmokeTestRequest struct {
+	Mode            SmokeMode      `json:"mode"`
+	Parameters      map[string]any `json:"parameters"` // Override generated values
+	IncludeOptional bool           `json:"include_optional"`
+}
+
+// SmokeTestResult is the response of the tool smoke test endpoint
+type SmokeTestResult struct {
+	Tool            string         `json:"tool"`
+	Mode            SmokeMode      `json:"mode"`
+	Parameters      map[string]any `json:"parameters"`
+	MissingRequired []string       `json:"missing_required,omitempty"`
+	Result          any            `json:"result,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	DurationMs      int64          `json:"duration_ms"`
+	Passed          bool           `json:"passed"`
+}
+
+// exampleParameters generates parameters for a tool from its input schema
+func exampleParameters(tool types.Tool, includeOptional bool) map[string]any {
+	input, _ := tool.Metadata().Schema["input"].(map[string]any)
+	parameters, ok := schema.Example(input, schema.ExampleOptions{IncludeOptional: includeOptional}).(map[string]any)
+	if !ok {
+		return map[string]any{}
+	}
+	return parameters
+}
+
+// runSmokeTest generates parameters for a tool, applies the overrides and,
+// depending on the mode, synthesizes or produces a result
+func runSmokeTest(ctx context.Context, tool types.Tool, request SmokeTestRequest) (SmokeTestResult, error) {
+	if request.Mode == "" {
+		request.Mode = SmokeModeDryRun
+	}
+	switch request.Mode {
+	case SmokeModeDryRun, SmokeModeMock, SmokeModeLive:
+	default:
+		return SmokeTestResult{}, fmt.Errorf("unknown smoke test mode: %s", request.Mode)
+	}
+
+	metadata := tool.Metadata()
+	parameters := exampleParameters(tool, request.IncludeOptional)
+	for name, value := range request.Parameters {
+		parameters[name] = value
+	}
+
+	result := SmokeTestResult{
+		Tool:       tool.Name(),
+		Mode:       request.Mode,
+		Parameters: parameters,
+	}
+
+	// Overrides may null out required values
+	input, _ := metadata.Schema["input"].(map[string]any)
+	for _, name := range schema.RequiredFields(input) {
+		if value, exists := parameters[name]; !exists || value == nil {
+			result.MissingRequired = append(result.MissingRequired, name)
+		}
+	}
+	if len(result.MissingRequired) > 0 {
+		result.Error = fmt.Sprintf("missing required parameters: %v", result.MissingRequired)
+		return result, nil
+	}
+
+	switch request.Mode {
+	case SmokeModeMock:
+		output, _ := metadata.Schema["output"].(map[string]any)
+		result.Result = schema.Example(output, schema.ExampleOptions{IncludeOptional: true})
+	case SmokeModeLive:
+		start := time.Now()
+		output, err := tool.Execute(ctx, parameters)
+		result.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			result.Error = err.Error()
+			return result, nil
+		}
+		result.Result = output
+	}
+
+	result.Passed = true
+	return result, nil
+}
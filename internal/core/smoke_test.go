@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// schemaTool is a TestTool that publishes input and output schemas
+type schemaTool struct {
+	TestTool
+	schema map[string]any
+}
+
+func (t *schemaTool) Metadata() types.ToolMetadata {
+	metadata := t.TestTool.Metadata()
+	metadata.Schema = t.schema
+	return metadata
+}
+
+func TestRunSmokeTest(t *testing.T) {
+	tool := &schemaTool{
+		TestTool: TestTool{name: "openapi.petstore.listPets", source: "openapi"},
+		schema: map[string]any{
+			"input": map[string]any{
+				"type":     "object",
+				"required": []string{"status"},
+				"properties": map[string]any{
+					"status": map[string]any{"type": "string", "enum": []any{"available", "pending"}},
+					"limit":  map[string]any{"type": "integer"},
+				},
+			},
+			"output": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"status_code": map[string]any{"type": "integer"}},
+			},
+		},
+	}
+
+	// Dry run only generates parameters
+	result, err := runSmokeTest(context.Background(), tool, SmokeTestRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, SmokeModeDryRun, result.Mode)
+	assert.Equal(t, map[string]any{"status": "available"}, result.Parameters)
+	assert.Nil(t, result.Result)
+	assert.True(t, result.Passed)
+
+	// Mock mode synthesizes a result from the output schema
+	result, err = runSmokeTest(context.Background(), tool, SmokeTestRequest{Mode: SmokeModeMock, IncludeOptional: true})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"status": "available", "limit": int64(1)}, result.Parameters)
+	assert.Equal(t, map[string]any{"status_code": int64(1)}, result.Result)
+
+	// Live mode executes with the overrides applied
+	result, err = runSmokeTest(context.Background(), tool, SmokeTestRequest{Mode: SmokeModeLive, Parameters: map[string]any{"status": "pending"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Result.(map[string]any)["input"].(map[string]any)["status"])
+	assert.True(t, result.Passed)
+
+	// Overrides that drop a required value fail without executing
+	result, err = runSmokeTest(context.Background(), tool, SmokeTestRequest{Mode: SmokeModeLive, Parameters: map[string]any{"status": nil}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status"}, result.MissingRequired)
+	assert.Nil(t, result.Result)
+	assert.False(t, result.Passed)
+
+	_, err = runSmokeTest(context.Background(), tool, SmokeTestRequest{Mode: "chaos"})
+	assert.Error(t, err)
+}
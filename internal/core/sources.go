@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// openSourceStore opens the store of imported sources and imports the
+// sources of earlier runs again, watching the files that were watched. It
+// returns nil while persistence is disabled, with in-memory storage and in
+// demo mode, whose specs point at upstreams that only live for one run.
+func openSourceStore(manager *importer.ImporterManager, watcher *importer.FileWatcher, logger *zap.Logger) (*importer.SourceStore, error) {
+	if !viper.GetBool("importer.persistence.enabled") || inMemoryStorage() || viper.GetBool("demo.enabled") {
+		return nil, nil
+	}
+	path := viper.GetString("importer.persistence.path")
+	if path == "" {
+		path = "./data/sources.db"
+	}
+	store, err := importer.OpenSourceStore(path)
+	if err != nil {
+		return nil, err
+	}
+	manager.SetSourceStore(store)
+
+	results, err := manager.RestoreSources(context.Background())
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	restored := 0
+	for _, result := range results {
+		if !result.Success {
+			logger.Warn("Failed to restore specification",
+				zap.String("source_id", result.SourceID),
+				zap.String("error", result.Error))
+			continue
+		}
+		restored++
+	}
+	if err := watcher.RestoreWatches(); err != nil {
+		logger.Warn("Failed to restore file watching", zap.Error(err))
+	}
+
+	logger.Info("Restored imported specifications",
+		zap.String("path", path),
+		zap.Int("restored", restored),
+		zap.Int("failed", len(results)-restored))
+	return store, nil
+}
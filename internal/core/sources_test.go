@@ -0,0 +1,45 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerWarmStart(t *testing.T) {
+	dir := t.TempDir()
+	viper.Set("storage.type", "boltdb")
+	viper.Set("storage.path", filepath.Join(dir, "aionmcp.db"))
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("importer.persistence.enabled", true)
+	viper.Set("importer.persistence.path", filepath.Join(dir, "sources.db"))
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/specs/",
+		strings.NewReader(`{"id": "pets", "type": "openapi", "path": "../../examples/specs/petstore.yaml", "naming": {"case": "snake"}}`)))
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	server.Close()
+
+	// A restarted server imports the spec again with its settings, even in read-only mode
+	viper.Set("server.read_only", true)
+	server, err = NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	source, exists := server.importerManager.GetSource("pets")
+	require.True(t, exists)
+	assert.Equal(t, importer.NameCaseSnake, source.Naming.Case)
+	_, err = server.toolRegistry.Get("openapi.pets.list_pets")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// setupSpecPollRoutes mounts the polling status of specs imported from URLs
+// and lets clients check a spec for changes without waiting for its interval
+func (s *Server) setupSpecPollRoutes(router *gin.Engine) {
+	specs := router.Group("/api/v1/specs")
+
+	specs.GET("/polling", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"sources": s.specPoller.Status()})
+	})
+
+	specs.POST("/:id/poll", requireWritable(s.readOnly), func(c *gin.Context) {
+		sourceID := c.Param("id")
+		c.Set(auditTargetKey, sourceID)
+		if _, exists := s.importerManager.GetSource(sourceID); !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "specification not found"})
+			return
+		}
+
+		reloaded, err := s.specPoller.Check(c.Request.Context(), sourceID)
+		if err != nil {
+			s.logger.Warn("Failed to poll specification",
+				zap.String("source_id", sourceID),
+				zap.Error(err))
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"source_id": sourceID, "reloaded": reloaded})
+	})
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServerSpecPolling(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	defer viper.Reset()
+
+	var mu sync.Mutex
+	version := "1.0.0"
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, `{"openapi": "3.0.3", "info": {"title": "Inventory", "version": %q},
+			"paths": {"/items": {"get": {"operationId": "listItems", "responses": {"200": {"description": "ok"}}}}}}`, version)
+	}))
+	defer upstream.Close()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	handler := server.Handler()
+	_, reloads := server.events.subscribe(EventFilter{Types: map[string]bool{EventSpecReloaded: true}})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	poll := func() bool {
+		response := serve(http.MethodPost, "/api/v1/specs/inventory/poll", "")
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		var decoded struct {
+			Reloaded bool `json:"reloaded"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &decoded))
+		return decoded.Reloaded
+	}
+
+	response := serve(http.MethodPost, "/api/v1/specs/", fmt.Sprintf(`{"id": "inventory", "type": "openapi", "path": %q, "poll_interval_seconds": 300}`, upstream.URL+"/openapi.json"))
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+
+	// The first poll records the baseline and unchanged specs are left alone
+	assert.False(t, poll())
+	assert.False(t, poll())
+
+	// A changed spec is reloaded and streamed to subscribers
+	mu.Lock()
+	version = "1.1.0"
+	mu.Unlock()
+	assert.True(t, poll())
+	select {
+	case event := <-reloads:
+		assert.Equal(t, "inventory", event.Source)
+	case <-time.After(time.Second):
+		t.Fatal("spec reload was not streamed")
+	}
+
+	response = serve(http.MethodGet, "/api/v1/specs/polling", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	var status struct {
+		Sources []importer.PollStatus `json:"sources"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &status))
+	require.Len(t, status.Sources, 1)
+	assert.Equal(t, 300, status.Sources[0].IntervalSeconds)
+	assert.Equal(t, int64(3), status.Sources[0].Checks)
+	assert.Equal(t, int64(1), status.Sources[0].Reloads)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/api/v1/specs/missing/poll", "").Code)
+}
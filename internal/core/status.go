@@ -0,0 +1,162 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ToolStatus is the operator-set status of a tool
+type ToolStatus string
+
+const (
+	ToolStatusEnabled    ToolStatus = "enabled"    // Listed and invocable, the default
+	ToolStatusDisabled   ToolStatus = "disabled"   // Hidden from listings and rejected on invoke
+	ToolStatusDeprecated ToolStatus = "deprecated" // Invocable, announced as deprecated
+)
+
+// toolStatusRoute is where operators change the status of a tool
+const toolStatusRoute = "/api/v1/mcp/tools/:name"
+
+// ErrToolDisabled is returned by Get for tools an operator disabled
+var ErrToolDisabled = errors.New("tool is disabled")
+
+// ToolState reports the status an operator gave a tool
+type ToolState struct {
+	Tool        string             `json:"tool"`
+	Status      ToolStatus         `json:"status"`
+	Reason      string             `json:"reason,omitempty"`
+	Deprecation *types.Deprecation `json:"deprecation,omitempty"` // Set for deprecated tools
+	Registered  bool               `json:"registered"`            // Whether the tool is registered now
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// SetToolStatus changes the status of a registered tool. The status is kept
+// by name, so it survives spec reloads. Deprecated tools announce the given
+// deprecation on top of any configured one; enabling a tool clears both.
+func (r *ToolRegistry) SetToolStatus(name string, status ToolStatus, reason string, deprecation *types.Deprecation) (ToolState, error) {
+	switch status {
+	case ToolStatusEnabled, ToolStatusDisabled, ToolStatusDeprecated:
+	default:
+		return ToolState{}, fmt.Errorf("invalid tool status %q: must be enabled, disabled or deprecated", status)
+	}
+
+	r.mu.Lock()
+	tool, exists := r.tools[name]
+	if !exists {
+		r.mu.Unlock()
+		return ToolState{}, fmt.Errorf("tool '%s' not found", name)
+	}
+	state := ToolState{Tool: name, Status: status, Reason: reason, Registered: true, UpdatedAt: time.Now()}
+	if status == ToolStatusDeprecated {
+		if deprecation == nil {
+			deprecation = &types.Deprecation{}
+		}
+		if deprecation.Reason == "" {
+			deprecation.Reason = reason
+		}
+		state.Deprecation = deprecation
+	}
+	if status == ToolStatusEnabled {
+		delete(r.statuses, name)
+	} else {
+		r.statuses[name] = state
+	}
+	event := ToolRegistryEvent{
+		Type:      ToolEventUpdated,
+		ToolName:  name,
+		Metadata:  r.listedMetadata(name, tool),
+		Timestamp: state.UpdatedAt,
+	}
+	r.mu.Unlock()
+
+	r.logger.Info("Tool status changed",
+		zap.String("tool", name),
+		zap.String("status", string(status)),
+		zap.String("reason", reason))
+	r.emitEvent(event)
+	return state, nil
+}
+
+// ToolStatuses reports the tools an operator disabled or deprecated, sorted by name
+func (r *ToolRegistry) ToolStatuses() []ToolState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	states := make([]ToolState, 0, len(r.statuses))
+	for name, state := range r.statuses {
+		_, state.Registered = r.tools[name]
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Tool < states[j].Tool })
+	return states
+}
+
+// disabled reports whether an operator disabled a tool. Callers hold the lock.
+func (r *ToolRegistry) disabled(name string) bool {
+	return r.statuses[name].Status == ToolStatusDisabled
+}
+
+// deprecationOf merges the configured deprecation of a tool with the one its
+// status declares. Callers hold the lock.
+func (r *ToolRegistry) deprecationOf(name string) *types.Deprecation {
+	state, exists := r.statuses[name]
+	if !exists || state.Deprecation == nil {
+		return r.deprecations[name]
+	}
+	return r.deprecations[name].Merge(state.Deprecation)
+}
+
+// setupToolStatusRoutes mounts tool status management, which requires the
+// admin scope. Statuses can be changed in read-only mode, to pull a tool out
+// of rotation during maintenance.
+func (s *Server) setupToolStatusRoutes(router *gin.Engine) {
+	router.PATCH(toolStatusRoute, func(c *gin.Context) {
+		var req struct {
+			Status      ToolStatus `json:"status" binding:"required"`
+			Reason      string     `json:"reason"`
+			Sunset      string     `json:"sunset"` // RFC 3339 or YYYY-MM-DD, for deprecated tools
+			Replacement string     `json:"replacement"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		name := c.Param("name")
+		c.Set(auditTargetKey, name)
+
+		var deprecation *types.Deprecation
+		if req.Status == ToolStatusDeprecated {
+			now := time.Now().UTC()
+			deprecation = &types.Deprecation{Since: &now, Replacement: req.Replacement}
+			if req.Sunset != "" {
+				sunset, err := types.ParseDeprecationDate(req.Sunset)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				deprecation.Sunset = sunset
+			}
+		}
+
+		if _, err := s.toolRegistry.GetSource(name); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("tool not found: %s", name)})
+			return
+		}
+		state, err := s.toolRegistry.SetToolStatus(name, req.Status, req.Reason, deprecation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, state)
+	})
+
+	router.GET("/api/v1/admin/tools/status", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tools": s.toolRegistry.ToolStatuses()})
+	})
+}
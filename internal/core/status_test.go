@@ -0,0 +1,124 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestToolRegistry_ToolStatus(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	require.NoError(t, registry.Register(&TestTool{name: "openapi.petstore.list_pets", source: "openapi"}))
+	require.NoError(t, registry.AddAlias("openapi.petstore.listPets", "openapi.petstore.list_pets"))
+	listed := func() bool {
+		for _, metadata := range registry.ListTools() {
+			if metadata.Name == "openapi.petstore.list_pets" {
+				return true
+			}
+		}
+		return false
+	}
+
+	_, err := registry.SetToolStatus("openapi.petstore.missing", ToolStatusDisabled, "", nil)
+	assert.ErrorContains(t, err, "not found")
+	_, err = registry.SetToolStatus("openapi.petstore.list_pets", "paused", "", nil)
+	assert.ErrorContains(t, err, `invalid tool status "paused"`)
+
+	// Disabled tools are hidden and rejected, also through their aliases
+	_, err = registry.SetToolStatus("openapi.petstore.list_pets", ToolStatusDisabled, "upstream outage", nil)
+	require.NoError(t, err)
+	assert.False(t, listed())
+	assert.Empty(t, registry.ListToolsBySource("openapi"))
+	_, err = registry.Get("openapi.petstore.list_pets")
+	assert.ErrorIs(t, err, ErrToolDisabled)
+	_, err = registry.Get("openapi.petstore.listPets")
+	assert.ErrorIs(t, err, ErrToolDisabled)
+
+	// The status outlives reloads of the tool
+	require.NoError(t, registry.Unregister("openapi.petstore.list_pets"))
+	assert.False(t, registry.ToolStatuses()[0].Registered)
+	require.NoError(t, registry.Register(&TestTool{name: "openapi.petstore.list_pets", source: "openapi"}))
+	_, err = registry.Get("openapi.petstore.list_pets")
+	assert.ErrorIs(t, err, ErrToolDisabled)
+
+	// Deprecated tools stay invocable and announce the deprecation
+	_, err = registry.SetToolStatus("openapi.petstore.list_pets", ToolStatusDeprecated, "use search", &types.Deprecation{Replacement: "openapi.petstore.search_pets"})
+	require.NoError(t, err)
+	assert.True(t, listed())
+	tool, err := registry.Get("openapi.petstore.list_pets")
+	require.NoError(t, err)
+	require.NotNil(t, tool.Metadata().Deprecation)
+	assert.Equal(t, "use search", tool.Metadata().Deprecation.Reason)
+	assert.Equal(t, "openapi.petstore.search_pets", tool.Metadata().Deprecation.Replacement)
+
+	// Enabling a tool clears its status
+	_, err = registry.SetToolStatus("openapi.petstore.list_pets", ToolStatusEnabled, "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, registry.ToolStatuses())
+	tool, err = registry.Get("openapi.petstore.list_pets")
+	require.NoError(t, err)
+	assert.Nil(t, tool.Metadata().Deprecation)
+}
+
+func TestServerToolStatus(t *testing.T) {
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("storage.type", storageTypeMemory)
+	viper.Set("server.read_only", true)
+	defer viper.Reset()
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	require.NoError(t, server.toolRegistry.Register(&TestTool{name: "openapi.petstore.list_pets", source: "openapi"}))
+	patch := func(tool, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPatch, "/api/v1/mcp/tools/"+tool, strings.NewReader(body)))
+		return recorder
+	}
+
+	// Statuses change in read-only mode, so tools can be pulled during maintenance
+	recorder := patch("openapi.petstore.list_pets", `{"status": "disabled", "reason": "upstream outage"}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, http.StatusNotFound, patch("openapi.petstore.missing", `{"status": "disabled"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch("openapi.petstore.list_pets", `{"status": "paused"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch("openapi.petstore.list_pets", `{"status": "deprecated", "sunset": "soon"}`).Code)
+
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/admin/tools/status", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var statuses struct {
+		Tools []ToolState `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &statuses))
+	require.Len(t, statuses.Tools, 1)
+	assert.Equal(t, ToolStatusDisabled, statuses.Tools[0].Status)
+	assert.Equal(t, "upstream outage", statuses.Tools[0].Reason)
+
+	// Invoking a disabled tool is rejected as unavailable
+	viper.Set("server.read_only", false)
+	writable, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer writable.Close()
+	server = writable
+	require.NoError(t, server.toolRegistry.Register(&TestTool{name: "openapi.petstore.list_pets", source: "openapi"}))
+	require.Equal(t, http.StatusOK, patch("openapi.petstore.list_pets", `{"status": "disabled"}`).Code)
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/mcp/tools/openapi.petstore.list_pets/invoke", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, recorder.Body.String())
+
+	// Deprecating sets the sunset and replacement
+	recorder = patch("openapi.petstore.list_pets", `{"status": "deprecated", "sunset": "2030-01-31", "replacement": "openapi.petstore.search_pets"}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	tool, err := server.toolRegistry.Get("openapi.petstore.list_pets")
+	require.NoError(t, err)
+	require.NotNil(t, tool.Metadata().Deprecation.Sunset)
+	assert.Equal(t, "openapi.petstore.search_pets", tool.Metadata().Deprecation.Replacement)
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// stdioTransport serves MCP over newline-delimited JSON-RPC on stdin/stdout,
+// which is how desktop MCP clients launch local servers
+type stdioTransport struct {
+	handler *rpcHandler
+	out     io.Writer
+	outMu   sync.Mutex
+}
+
+// RunStdio serves MCP over the given reader and writer until ctx is cancelled
+// or the input is closed. Logs must not be written to out.
+func (s *Server) RunStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	transport := &stdioTransport{handler: newRPCHandler(s, "stdio"), out: out}
+
+	s.logger.Info("Starting AionMCP stdio transport")
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), maxRPCMessageSize)
+		for scanner.Scan() {
+			line := make([]byte, len(scanner.Bytes()))
+			copy(line, scanner.Bytes())
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	defer s.shutdownBackground()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Shutting down AionMCP stdio transport")
+			return nil
+		case err := <-readErr:
+			if err != nil {
+				return fmt.Errorf("failed to read stdio input: %w", err)
+			}
+			s.logger.Info("Stdio input closed, shutting down")
+			return nil
+		case line := <-lines:
+			if len(line) == 0 {
+				continue
+			}
+			if response := transport.handler.HandleMessage(ctx, line); response != nil {
+				if err := transport.write(response); err != nil {
+					return fmt.Errorf("failed to write stdio response: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// write sends one encoded response as a single line
+func (t *stdioTransport) write(response []byte) error {
+	t.outMu.Lock()
+	defer t.outMu.Unlock()
+	_, err := t.out.Write(append(response, '\n'))
+	return err
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// TimeoutConfig bounds how long tool invocations may run
+type TimeoutConfig struct {
+	Default time.Duration            // Zero disables the global timeout
+	Tools   map[string]time.Duration // Per-tool overrides keyed by tool name
+}
+
+// ToolTimeout overrides the global timeout for one tool
+type ToolTimeout struct {
+	Tool      string `mapstructure:"tool"`
+	TimeoutMs int64  `mapstructure:"timeout_ms"`
+}
+
+// timedTool cancels invocations that run past the tool's timeout
+type timedTool struct {
+	types.Tool
+	timeout time.Duration
+}
+
+// Execute runs the tool with the timeout applied to the caller's context
+func (t *timedTool) Execute(ctx context.Context, input any) (any, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	return types.Execute(ctx, t.Tool, input)
+}
+
+// withTimeout wraps a tool so its invocations are bounded by a timeout; zero disables it
+func withTimeout(tool Tool, timeout time.Duration) Tool {
+	if timeout <= 0 {
+		return tool
+	}
+	return &timedTool{Tool: tool, timeout: timeout}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// blockingTool is a TestTool that runs until its context ends
+type blockingTool struct {
+	TestTool
+}
+
+func (t *blockingTool) Execute(ctx context.Context, input any) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestToolRegistry_Timeouts(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	require.NoError(t, registry.Register(&blockingTool{TestTool{name: "openapi.slow.wait"}}))
+
+	registry.SetTimeouts(TimeoutConfig{Default: 20 * time.Millisecond})
+	tool, err := registry.Get("openapi.slow.wait")
+	require.NoError(t, err)
+	_, err = tool.Execute(context.Background(), map[string]any{})
+	var timeoutErr *types.TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "openapi.slow.wait", timeoutErr.Tool)
+
+	// Per-tool overrides win; cancelling the caller's context still ends the call
+	registry.SetTimeouts(TimeoutConfig{
+		Default: 20 * time.Millisecond,
+		Tools:   map[string]time.Duration{"openapi.slow.wait": 0},
+	})
+	tool, err = registry.Get("openapi.slow.wait")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = tool.Execute(ctx, map[string]any{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Built-in tools finish well within the timeout
+	registry.SetTimeouts(TimeoutConfig{Default: time.Second})
+	echo, err := registry.Get("echo")
+	require.NoError(t, err)
+	_, err = echo.Execute(context.Background(), map[string]any{"message": "hi"})
+	assert.NoError(t, err)
+}
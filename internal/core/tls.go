@@ -0,0 +1,148 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Client certificate policies selected by server.tls.client_auth
+const (
+	clientAuthRequire  = "require"  // Reject clients without a certificate signed by client_ca
+	clientAuthOptional = "optional" // Verify certificates clients present, but accept clients without one
+)
+
+// serverTLS serves the HTTP and gRPC listeners over TLS. The certificate and
+// client CAs are read from files and can be reloaded while serving; new
+// handshakes pick up the reloaded files.
+type serverTLS struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string // Empty disables client certificate verification
+	clientAuth   tls.ClientAuthType
+	logger       *zap.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// loadServerTLS reads the server.tls configuration, returning nil while no
+// certificate is configured
+func loadServerTLS(logger *zap.Logger) (*serverTLS, error) {
+	certFile := viper.GetString("server.tls.cert")
+	keyFile := viper.GetString("server.tls.key")
+	clientCAFile := viper.GetString("server.tls.client_ca")
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, errors.New("server.tls.client_ca requires server.tls.cert and server.tls.key")
+		}
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("server.tls.cert and server.tls.key must be set together")
+	}
+
+	clientAuth := tls.NoClientCert
+	if clientCAFile != "" {
+		switch mode := viper.GetString("server.tls.client_auth"); mode {
+		case clientAuthRequire, "":
+			clientAuth = tls.RequireAndVerifyClientCert
+		case clientAuthOptional:
+			clientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("unsupported server.tls.client_auth %q", mode)
+		}
+	}
+
+	serving := &serverTLS{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		clientAuth:   clientAuth,
+		logger:       logger,
+	}
+	if err := serving.Reload(); err != nil {
+		return nil, err
+	}
+	logger.Info("TLS enabled",
+		zap.String("cert", certFile),
+		zap.Bool("mutual_tls", clientCAFile != ""),
+		zap.String("client_auth", clientAuth.String()))
+	return serving, nil
+}
+
+// Reload reads the certificate, key and client CAs again. On failure the
+// files in use so far are kept.
+func (t *serverTLS) Reload() error {
+	cert, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if t.clientCAFile != "" {
+		data, err := os.ReadFile(t.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in client CA %s", t.clientCAFile)
+		}
+	}
+
+	t.mu.Lock()
+	t.cert = &cert
+	t.clientCAs = clientCAs
+	t.mu.Unlock()
+	return nil
+}
+
+// Config returns the TLS configuration of a listener. It resolves the
+// current certificate and client CAs on every handshake.
+func (t *serverTLS) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: t.getCertificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			t.mu.RLock()
+			defer t.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*t.cert},
+				ClientCAs:    t.clientCAs,
+				ClientAuth:   t.clientAuth,
+				// gRPC requires HTTP/2; HTTP clients may use either
+				NextProtos: []string{"h2", "http/1.1"},
+			}, nil
+		},
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
+
+// getCertificate returns the current certificate
+func (t *serverTLS) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.cert, nil
+}
+
+// ReloadTLS reloads the TLS certificate and client CAs, e.g. on SIGHUP. It
+// is a no-op while TLS is disabled.
+func (s *Server) ReloadTLS() error {
+	if s.tls == nil {
+		return nil
+	}
+	if err := s.tls.Reload(); err != nil {
+		s.logger.Error("TLS reload failed; keeping the current certificate", zap.Error(err))
+		return err
+	}
+	s.logger.Info("TLS certificate reloaded", zap.String("cert", s.tls.certFile))
+	return nil
+}
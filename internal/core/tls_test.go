@@ -0,0 +1,194 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testCertificate issues a certificate for template, self-signed when parent
+// is nil, and writes it and its key as PEM files named after name into dir
+func testCertificate(t *testing.T, dir, name string, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+"-key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, key
+}
+
+func TestServerTLS(t *testing.T) {
+	defer viper.Reset()
+	logger := zap.NewNop()
+	dir := t.TempDir()
+
+	ca, caKey := testCertificate(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	serverCert := func(serial int64) {
+		testCertificate(t, dir, "server", &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}, ca, caKey)
+	}
+	serverCert(2)
+	testCertificate(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "agent-1"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+	require.NoError(t, err)
+
+	// Plaintext until a certificate is configured
+	serving, err := loadServerTLS(logger)
+	require.NoError(t, err)
+	assert.Nil(t, serving)
+	viper.Set("server.tls.client_ca", filepath.Join(dir, "ca.pem"))
+	_, err = loadServerTLS(logger)
+	assert.Error(t, err)
+	viper.Set("server.tls.cert", filepath.Join(dir, "server.pem"))
+	_, err = loadServerTLS(logger)
+	assert.Error(t, err)
+	viper.Set("server.tls.key", filepath.Join(dir, "server-key.pem"))
+	viper.Set("server.tls.client_auth", "sometimes")
+	_, err = loadServerTLS(logger)
+	assert.Error(t, err)
+
+	viper.Set("server.tls.client_auth", "require")
+	serving, err = loadServerTLS(logger)
+	require.NoError(t, err)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serving.Config())
+	require.NoError(t, err)
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	})}
+	go func() { _ = httpServer.Serve(listener) }()
+	defer httpServer.Close()
+	url := "https://" + listener.Addr().String()
+
+	get := func(certificates ...tls.Certificate) (*http.Response, string, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certificates},
+			DisableKeepAlives: true,
+		}}
+		response, err := client.Get(url)
+		if err != nil {
+			return nil, "", err
+		}
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		return response, string(body), err
+	}
+
+	// Mutual TLS identifies the client and rejects clients without a certificate
+	response, body, err := get(clientCert)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", body)
+	assert.Equal(t, int64(2), response.TLS.PeerCertificates[0].SerialNumber.Int64())
+	_, _, err = get()
+	assert.Error(t, err)
+
+	// Reloading serves the new certificate to new connections
+	serverCert(4)
+	server := &Server{logger: logger, tls: serving}
+	require.NoError(t, server.ReloadTLS())
+	response, _, err = get(clientCert)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), response.TLS.PeerCertificates[0].SerialNumber.Int64())
+
+	// A failed reload keeps the current certificate
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server.pem"), []byte("garbage"), 0o600))
+	assert.Error(t, server.ReloadTLS())
+	response, _, err = get(clientCert)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), response.TLS.PeerCertificates[0].SerialNumber.Int64())
+
+	// gRPC verifies clients against the same CAs
+	registry := NewToolRegistry(logger)
+	agentServer := agent.NewAgentServer(logger, registry)
+	grpcServer, _ := newGRPCServer(GRPCConfig{KeepaliveTime: time.Minute, KeepaliveTimeout: time.Second, TLS: serving.Config()}, agentServer, nil, logger)
+	grpcListener := bufconn.Listen(1 << 20)
+	go func() { _ = grpcServer.Serve(grpcListener) }()
+	defer grpcServer.Stop()
+	dial := func(certificates ...tls.Certificate) agentpb.AgentServiceClient {
+		conn, err := grpc.NewClient("passthrough:///bufconn",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return grpcListener.DialContext(ctx) }),
+			grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: certificates})))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return agentpb.NewAgentServiceClient(conn)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = dial(clientCert).RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Agent"})
+	assert.NoError(t, err)
+	_, err = dial().RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "agent-2", AgentName: "Agent"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Optional client authentication accepts clients without a certificate
+	serverCert(5)
+	viper.Set("server.tls.client_auth", "optional")
+	optional, err := loadServerTLS(logger)
+	require.NoError(t, err)
+	optionalListener, err := tls.Listen("tcp", "127.0.0.1:0", optional.Config())
+	require.NoError(t, err)
+	optionalServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, len(r.TLS.PeerCertificates))
+	})}
+	go func() { _ = optionalServer.Serve(optionalListener) }()
+	defer optionalServer.Close()
+	url = "https://" + optionalListener.Addr().String()
+	_, body, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, "0", body)
+	_, body, err = get(clientCert)
+	require.NoError(t, err)
+	assert.Equal(t, "1", body)
+}
@@ -0,0 +1,27 @@
+package core
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	"github.com/spf13/viper"
+)
+
+// toolScopeConfig reads the tool scopes imposed on agent sessions by agent
+// ID under agent.tool_scopes
+func toolScopeConfig() ([]agent.ToolScopePolicy, error) {
+	var policies []agent.ToolScopePolicy
+	if err := viper.UnmarshalKey("agent.tool_scopes", &policies); err != nil {
+		return nil, fmt.Errorf("invalid tool scope configuration: %w", err)
+	}
+	for _, policy := range policies {
+		if _, err := path.Match(policy.AgentID, ""); err != nil || policy.AgentID == "" {
+			return nil, fmt.Errorf("invalid agent_id pattern %q in agent.tool_scopes", policy.AgentID)
+		}
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("tool scope of %s: %w", policy.AgentID, err)
+		}
+	}
+	return policies, nil
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestAgentToolScopes(t *testing.T) {
+	viper.Set("storage.type", "memory")
+	viper.Set("agent.telemetry.default_capture_level", "metadata")
+	viper.Set("agent.telemetry.max_capture_level", "full")
+	viper.Set("agent.tool_scopes", []map[string]interface{}{{"agent_id": "echo-*", "allow": []string{"echo"}}})
+	defer viper.Reset()
+
+	server, err := NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	require.NoError(t, err)
+	defer server.Close()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	response, err := http.Post(httpServer.URL+"/api/v1/agents/register", "application/json",
+		strings.NewReader(`{"agent_id": "echo-bot", "agent_name": "Echo Bot"}`))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusCreated, response.StatusCode)
+	var registered agentpb.RegisterAgentResponse
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(body, &registered))
+	require.Len(t, registered.AvailableTools, 1)
+	assert.Equal(t, "echo", registered.AvailableTools[0].Name)
+
+	viper.Set("agent.tool_scopes", []map[string]interface{}{{"agent_id": "echo-*", "deny": []string{"[echo"}}})
+	_, err = NewServerWithOptions(zap.NewNop(), ServerOptions{})
+	assert.ErrorContains(t, err, `invalid tool pattern "[echo"`)
+}
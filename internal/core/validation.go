@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// ValidationConfig controls schema validation of tool invocations
+type ValidationConfig struct {
+	Input  bool                      // Reject parameters that fail the input schema
+	Output bool                      // Fail invocations whose result fails the output schema
+	Tools  map[string]ToolValidation // Per-tool overrides keyed by tool name
+}
+
+// ToolValidation overrides the global validation settings for one tool.
+// Nil fields inherit the global setting.
+type ToolValidation struct {
+	Name   string `mapstructure:"name" json:"name"`
+	Input  *bool  `mapstructure:"input" json:"input,omitempty"`
+	Output *bool  `mapstructure:"output" json:"output,omitempty"`
+}
+
+// DefaultValidationConfig validates inputs but not outputs
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{Input: true}
+}
+
+// forTool resolves the effective input and output settings for a tool
+func (c ValidationConfig) forTool(name string) (input, output bool) {
+	input, output = c.Input, c.Output
+	if override, ok := c.Tools[name]; ok {
+		if override.Input != nil {
+			input = *override.Input
+		}
+		if override.Output != nil {
+			output = *override.Output
+		}
+	}
+	return input, output
+}
+
+// validatedTool checks invocations of a tool against its published schemas
+type validatedTool struct {
+	types.Tool
+	input  map[string]interface{}
+	output map[string]interface{}
+}
+
+// Execute validates the parameters, runs the tool and validates its result
+func (t *validatedTool) Execute(ctx context.Context, input any) (any, error) {
+	if t.input != nil {
+		if err := schema.Validate("input", t.input, normalizeJSON(input)); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := t.Tool.Execute(ctx, input)
+	if err != nil || t.output == nil {
+		return result, err
+	}
+	if err := schema.Validate("output", t.output, normalizeJSON(result)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// withValidation wraps a tool so its schemas are enforced according to the
+// configuration. Tools without a schema for an enabled direction are left as is.
+func withValidation(tool Tool, config ValidationConfig) Tool {
+	validateInput, validateOutput := config.forTool(tool.Name())
+	if !validateInput && !validateOutput {
+		return tool
+	}
+
+	wrapped := &validatedTool{Tool: tool}
+	schemas := tool.Metadata().Schema
+	if validateInput {
+		wrapped.input, _ = schemas["input"].(map[string]interface{})
+	}
+	if validateOutput {
+		wrapped.output, _ = schemas["output"].(map[string]interface{})
+	}
+	if wrapped.input == nil && wrapped.output == nil {
+		return tool
+	}
+	return wrapped
+}
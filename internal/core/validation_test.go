@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestToolRegistry_Validation(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	tool := &schemaTool{
+		TestTool: TestTool{name: "openapi.petstore.listPets", source: "openapi"},
+		schema: map[string]any{
+			"input": map[string]any{
+				"type":     "object",
+				"required": []string{"status"},
+				"properties": map[string]any{
+					"status": map[string]any{"type": "string", "enum": []any{"available", "pending"}},
+					"limit":  map[string]any{"type": "integer", "maximum": 100.0},
+				},
+			},
+			"output": map[string]any{
+				"type":     "object",
+				"required": []string{"status_code"},
+			},
+		},
+	}
+	require.NoError(t, registry.Register(tool))
+
+	// Inputs are validated by default, with one error per failing field
+	wrapped, err := registry.Get(tool.Name())
+	require.NoError(t, err)
+	_, err = wrapped.Execute(context.Background(), map[string]any{"limit": 500.0})
+	var validationErr *schema.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "input", validationErr.Subject)
+	assert.Equal(t, []schema.FieldError{
+		{Field: "/status", Message: "is required"},
+		{Field: "/limit", Message: "must be <= 100"},
+	}, validationErr.Errors)
+
+	result, err := wrapped.Execute(context.Background(), map[string]any{"status": "pending"})
+	assert.NoError(t, err)
+	assert.Equal(t, "success", result.(map[string]any)["result"])
+
+	// Output validation is opt-in; TestTool results have no status_code
+	registry.SetValidation(ValidationConfig{Input: true, Output: true})
+	wrapped, err = registry.Get(tool.Name())
+	require.NoError(t, err)
+	_, err = wrapped.Execute(context.Background(), map[string]any{"status": "pending"})
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "output", validationErr.Subject)
+
+	// Per-tool overrides win over the global settings
+	disabled := false
+	registry.SetValidation(ValidationConfig{
+		Input:  true,
+		Output: true,
+		Tools:  map[string]ToolValidation{tool.Name(): {Input: &disabled, Output: &disabled}},
+	})
+	wrapped, err = registry.Get(tool.Name())
+	require.NoError(t, err)
+	assert.Same(t, tool, wrapped)
+	_, err = wrapped.Execute(context.Background(), map[string]any{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,328 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DefaultWatchdogCheckInterval is how often the watchdog looks for stuck invocations
+const DefaultWatchdogCheckInterval = time.Second
+
+// WatchdogConfig configures the watchdog that force-cancels stuck invocations
+type WatchdogConfig struct {
+	MaxRuntime    time.Duration // Hard limit on every invocation; zero disables the watchdog
+	CheckInterval time.Duration // How often running invocations are checked
+	HangWindow    time.Duration // Window over which a tool's recent forced cancellations are counted
+}
+
+// StuckExecution describes an invocation the watchdog force-cancelled
+type StuckExecution struct {
+	Tool          string
+	StartedAt     time.Time
+	Elapsed       time.Duration
+	RecentHangs   int // Forced cancellations of the tool within the hang window, this one included
+	TotalHangs    int
+	ExceededLimit time.Duration
+}
+
+// StuckHandler is called after the watchdog force-cancels an invocation
+type StuckHandler func(stuck StuckExecution)
+
+// RunningInvocation is an invocation the watchdog is tracking
+type RunningInvocation struct {
+	ID        uint64    `json:"id"`
+	Tool      string    `json:"tool"`
+	StartedAt time.Time `json:"started_at"`
+	ElapsedMs int64     `json:"elapsed_ms"`
+}
+
+// ToolHangs summarizes the forced cancellations of one tool
+type ToolHangs struct {
+	Tool          string    `json:"tool"`
+	Total         int       `json:"total"`
+	Recent        int       `json:"recent"` // Within the hang window
+	LastHangAt    time.Time `json:"last_hang_at"`
+	LastElapsedMs int64     `json:"last_elapsed_ms"`
+}
+
+// WatchdogStatus reports running invocations and the tools that have hung
+type WatchdogStatus struct {
+	MaxRuntimeMs int64               `json:"max_runtime_ms"`
+	Running      []RunningInvocation `json:"running"`
+	Hangs        []ToolHangs         `json:"hangs"`
+}
+
+// trackedInvocation is an invocation in flight
+type trackedInvocation struct {
+	tool      string
+	startedAt time.Time
+	cancel    context.CancelCauseFunc
+	cancelled bool
+}
+
+// toolHangHistory records when a tool's invocations were force-cancelled
+type toolHangHistory struct {
+	total       int
+	recent      []time.Time // Within the hang window, oldest first
+	lastAt      time.Time
+	lastElapsed time.Duration
+}
+
+// Watchdog force-cancels invocations that run past a hard maximum runtime,
+// even when neither the caller nor the tool timeout bounds them
+type Watchdog struct {
+	config   WatchdogConfig
+	clock    clock.Clock
+	logger   *zap.Logger
+	mu       sync.Mutex
+	running  map[uint64]*trackedInvocation
+	nextID   uint64
+	hangs    map[string]*toolHangHistory
+	handlers []StuckHandler
+}
+
+// NewWatchdog creates a watchdog. Call Run to start checking invocations.
+func NewWatchdog(config WatchdogConfig, c clock.Clock, logger *zap.Logger) *Watchdog {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultWatchdogCheckInterval
+	}
+	if c == nil {
+		c = clock.Real{}
+	}
+	return &Watchdog{
+		config:  config,
+		clock:   c,
+		logger:  logger,
+		running: make(map[uint64]*trackedInvocation),
+		hangs:   make(map[string]*toolHangHistory),
+	}
+}
+
+// OnStuck registers a handler called for every force-cancelled invocation
+func (w *Watchdog) OnStuck(handler StuckHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Run checks running invocations every check interval until ctx ends
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := w.clock.NewTicker(w.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			w.check()
+		}
+	}
+}
+
+// track starts watching an invocation, returning its ID
+func (w *Watchdog) track(tool string, cancel context.CancelCauseFunc) uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.nextID++
+	w.running[w.nextID] = &trackedInvocation{tool: tool, startedAt: w.clock.Now(), cancel: cancel}
+	return w.nextID
+}
+
+// untrack stops watching a finished invocation
+func (w *Watchdog) untrack(id uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.running, id)
+}
+
+// check force-cancels the invocations that have run past the maximum runtime
+func (w *Watchdog) check() {
+	now := w.clock.Now()
+	var stuck []StuckExecution
+
+	w.mu.Lock()
+	for _, invocation := range w.running {
+		elapsed := now.Sub(invocation.startedAt)
+		if invocation.cancelled || elapsed < w.config.MaxRuntime {
+			continue
+		}
+		invocation.cancelled = true
+		invocation.cancel(&types.StuckExecutionError{Tool: invocation.tool, Elapsed: elapsed, MaxRuntime: w.config.MaxRuntime})
+
+		history := w.recordHang(invocation.tool, now, elapsed)
+		stuck = append(stuck, StuckExecution{
+			Tool:          invocation.tool,
+			StartedAt:     invocation.startedAt,
+			Elapsed:       elapsed,
+			RecentHangs:   len(history.recent),
+			TotalHangs:    history.total,
+			ExceededLimit: w.config.MaxRuntime,
+		})
+	}
+	handlers := make([]StuckHandler, len(w.handlers))
+	copy(handlers, w.handlers)
+	w.mu.Unlock()
+
+	for _, execution := range stuck {
+		w.logger.Warn("Watchdog force-cancelled stuck tool invocation",
+			zap.String("tool", execution.Tool),
+			zap.Duration("elapsed", execution.Elapsed),
+			zap.Duration("max_runtime", execution.ExceededLimit),
+			zap.Int("recent_hangs", execution.RecentHangs))
+		for _, handler := range handlers {
+			handler(execution)
+		}
+	}
+}
+
+// recordHang adds a forced cancellation to a tool's history. Callers hold w.mu.
+func (w *Watchdog) recordHang(tool string, now time.Time, elapsed time.Duration) *toolHangHistory {
+	history, ok := w.hangs[tool]
+	if !ok {
+		history = &toolHangHistory{}
+		w.hangs[tool] = history
+	}
+	history.total++
+	history.lastAt = now
+	history.lastElapsed = elapsed
+	history.recent = append(w.recentHangs(history, now), now)
+	return history
+}
+
+// recentHangs drops hangs that fell out of the hang window. Callers hold w.mu.
+func (w *Watchdog) recentHangs(history *toolHangHistory, now time.Time) []time.Time {
+	if w.config.HangWindow <= 0 {
+		return history.recent
+	}
+	cutoff := now.Add(-w.config.HangWindow)
+	kept := history.recent[:0]
+	for _, at := range history.recent {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	return kept
+}
+
+// Status reports the invocations in flight, longest running first, and the
+// tools that have hung, most recent hangs first
+func (w *Watchdog) Status() WatchdogStatus {
+	now := w.clock.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := WatchdogStatus{
+		MaxRuntimeMs: w.config.MaxRuntime.Milliseconds(),
+		Running:      make([]RunningInvocation, 0, len(w.running)),
+		Hangs:        make([]ToolHangs, 0, len(w.hangs)),
+	}
+	for id, invocation := range w.running {
+		status.Running = append(status.Running, RunningInvocation{
+			ID:        id,
+			Tool:      invocation.tool,
+			StartedAt: invocation.startedAt,
+			ElapsedMs: now.Sub(invocation.startedAt).Milliseconds(),
+		})
+	}
+	sort.Slice(status.Running, func(i, j int) bool {
+		return status.Running[i].StartedAt.Before(status.Running[j].StartedAt)
+	})
+
+	for tool, history := range w.hangs {
+		history.recent = w.recentHangs(history, now)
+		status.Hangs = append(status.Hangs, ToolHangs{
+			Tool:          tool,
+			Total:         history.total,
+			Recent:        len(history.recent),
+			LastHangAt:    history.lastAt,
+			LastElapsedMs: history.lastElapsed.Milliseconds(),
+		})
+	}
+	sort.Slice(status.Hangs, func(i, j int) bool {
+		if status.Hangs[i].Recent != status.Hangs[j].Recent {
+			return status.Hangs[i].Recent > status.Hangs[j].Recent
+		}
+		return status.Hangs[i].Tool < status.Hangs[j].Tool
+	})
+	return status
+}
+
+// watchedTool registers its invocations with the watchdog so stuck ones are
+// force-cancelled
+type watchedTool struct {
+	types.Tool
+	watchdog *Watchdog
+}
+
+// Execute runs the tool under a context the watchdog can cancel
+func (t *watchedTool) Execute(ctx context.Context, input any) (any, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	id := t.watchdog.track(t.Name(), cancel)
+	defer t.watchdog.untrack(id)
+	return types.Execute(ctx, t.Tool, input)
+}
+
+// withWatchdog wraps a tool so its invocations are watched, if a watchdog is configured
+func withWatchdog(tool Tool, watchdog *Watchdog) Tool {
+	if watchdog == nil {
+		return tool
+	}
+	return &watchedTool{Tool: tool, watchdog: watchdog}
+}
+
+// stuckInsightRecorder returns a StuckHandler that raises an insight once a
+// tool has been force-cancelled threshold times within the hang window
+func stuckInsightRecorder(learningEngine *selflearn.Engine, threshold int, logger *zap.Logger) StuckHandler {
+	return func(stuck StuckExecution) {
+		// Only the hang that crosses the threshold raises an insight
+		if threshold <= 0 || stuck.RecentHangs != threshold {
+			return
+		}
+		if err := learningEngine.RecordInsight(context.Background(), stuckInsight(stuck)); err != nil {
+			logger.Error("Failed to record hanging tool insight",
+				zap.String("tool", stuck.Tool),
+				zap.Error(err))
+		}
+	}
+}
+
+// stuckInsight describes a tool whose invocations chronically hang
+func stuckInsight(stuck StuckExecution) selflearn.Insight {
+	return selflearn.Insight{
+		Type:     selflearn.InsightTypeReliability,
+		Priority: selflearn.PriorityHigh,
+		Title:    fmt.Sprintf("Tool %s chronically hangs", stuck.Tool),
+		Description: fmt.Sprintf("The watchdog has force-cancelled %d invocations of %s recently for running past the %s maximum runtime",
+			stuck.RecentHangs, stuck.Tool, stuck.ExceededLimit),
+		Suggestion: "Check the upstream's health, give the tool a shorter timeout under tools.timeouts so callers fail fast, or disable it until it responds again",
+		Evidence: []string{
+			fmt.Sprintf("Forced cancellations since startup: %d", stuck.TotalHangs),
+			fmt.Sprintf("Latest invocation cancelled after: %s", stuck.Elapsed.Round(time.Millisecond)),
+		},
+		Metadata: map[string]string{
+			"tool_name":      stuck.Tool,
+			"recent_hangs":   strconv.Itoa(stuck.RecentHangs),
+			"max_runtime_ms": strconv.FormatInt(stuck.ExceededLimit.Milliseconds(), 10),
+		},
+	}
+}
+
+// setupWatchdogRoutes mounts the report of running and stuck invocations,
+// which requires the admin scope
+func (s *Server) setupWatchdogRoutes(router *gin.Engine) {
+	router.GET("/api/v1/admin/watchdog", func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.watchdog.Status())
+	})
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// hungTool is a TestTool that ignores its context and never returns until released
+type hungTool struct {
+	TestTool
+	release chan struct{}
+}
+
+func (t *hungTool) Execute(ctx context.Context, input any) (any, error) {
+	<-t.release
+	return nil, nil
+}
+
+func TestToolRegistry_Watchdog(t *testing.T) {
+	registry := NewToolRegistry(zap.NewNop())
+	hung := &hungTool{TestTool: TestTool{name: "openapi.stuck.get"}, release: make(chan struct{})}
+	defer close(hung.release)
+	require.NoError(t, registry.Register(hung))
+
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC))
+	watchdog := NewWatchdog(WatchdogConfig{MaxRuntime: time.Minute, HangWindow: time.Hour}, fake, zap.NewNop())
+	var stuck []StuckExecution
+	watchdog.OnStuck(func(execution StuckExecution) { stuck = append(stuck, execution) })
+	registry.SetWatchdog(watchdog)
+
+	// No timeout applies, so only the watchdog ends the invocation
+	hang := func() error {
+		tool, err := registry.Get("openapi.stuck.get")
+		require.NoError(t, err)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := tool.Execute(context.Background(), map[string]any{})
+			errs <- err
+		}()
+		require.Eventually(t, func() bool { return len(watchdog.Status().Running) == 1 }, time.Second, time.Millisecond)
+
+		watchdog.check()
+		assert.Len(t, watchdog.Status().Running, 1, "invocations within the maximum runtime keep running")
+		fake.Advance(time.Minute)
+		watchdog.check()
+		select {
+		case err := <-errs:
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("stuck invocation was not cancelled")
+			return nil
+		}
+	}
+
+	err := hang()
+	var stuckErr *types.StuckExecutionError
+	require.ErrorAs(t, err, &stuckErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "openapi.stuck.get", stuckErr.Tool)
+	assert.Equal(t, time.Minute, stuckErr.MaxRuntime)
+	require.Len(t, stuck, 1)
+	assert.Equal(t, 1, stuck[0].RecentHangs)
+
+	// Hangs outside the window no longer count as recent
+	require.ErrorAs(t, hang(), &stuckErr)
+	fake.Advance(2 * time.Hour)
+	require.ErrorAs(t, hang(), &stuckErr)
+	require.Len(t, stuck, 3)
+	assert.Equal(t, 2, stuck[1].RecentHangs)
+	assert.Equal(t, 1, stuck[2].RecentHangs)
+	assert.Equal(t, 3, stuck[2].TotalHangs)
+
+	status := watchdog.Status()
+	assert.Empty(t, status.Running)
+	require.Len(t, status.Hangs, 1)
+	assert.Equal(t, ToolHangs{Tool: "openapi.stuck.get", Total: 3, Recent: 1, LastHangAt: fake.Now(), LastElapsedMs: time.Minute.Milliseconds()}, status.Hangs[0])
+
+	// The insight names the chronically hanging tool
+	insight := stuckInsight(stuck[1])
+	assert.Equal(t, selflearn.InsightTypeReliability, insight.Type)
+	assert.Equal(t, "openapi.stuck.get", insight.Metadata["tool_name"])
+	assert.Equal(t, "2", insight.Metadata["recent_hangs"])
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aionmcp/aionmcp/pkg/workflow"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxWorkflowSize bounds a workflow definition submitted over the API
+const maxWorkflowSize = 1 << 20
+
+// workflowCatalog holds the workflows registered as tools
+type workflowCatalog struct {
+	mu        sync.RWMutex
+	registry  *ToolRegistry
+	workflows map[string]*workflow.Tool // By workflow name
+	logger    *zap.Logger
+}
+
+// newWorkflowCatalog creates an empty catalog registering into registry
+func newWorkflowCatalog(registry *ToolRegistry, logger *zap.Logger) *workflowCatalog {
+	return &workflowCatalog{
+		registry:  registry,
+		workflows: make(map[string]*workflow.Tool),
+		logger:    logger,
+	}
+}
+
+// put compiles a definition and registers it as a tool, replacing any
+// workflow of the same name. It reports whether a workflow was replaced.
+func (c *workflowCatalog) put(definition workflow.Definition) (*workflow.Tool, bool, error) {
+	tool, err := workflow.New(definition, c.registry)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, replaced := c.workflows[definition.Name]
+	if source, err := c.registry.GetSource(tool.Name()); err == nil && source != workflow.SourceType {
+		return nil, false, fmt.Errorf("tool %s is already registered by source %s", tool.Name(), source)
+	}
+	if err := c.registry.RegisterWithSource(tool, workflow.SourceType, tool.Metadata().Version); err != nil {
+		return nil, false, err
+	}
+	c.workflows[definition.Name] = tool
+
+	c.logger.Info("Workflow registered",
+		zap.String("workflow", definition.Name),
+		zap.String("tool", tool.Name()),
+		zap.Int("steps", len(definition.Steps)),
+		zap.Bool("replaced", replaced))
+	return tool, replaced, nil
+}
+
+// remove unregisters a workflow, reporting whether it existed
+func (c *workflowCatalog) remove(name string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tool, exists := c.workflows[name]
+	if !exists {
+		return false, nil
+	}
+	if err := c.registry.Unregister(tool.Name()); err != nil {
+		return true, err
+	}
+	delete(c.workflows, name)
+	return true, nil
+}
+
+// get returns a workflow by name
+func (c *workflowCatalog) get(name string) (*workflow.Tool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	tool, exists := c.workflows[name]
+	return tool, exists
+}
+
+// list returns the workflows sorted by name
+func (c *workflowCatalog) list() []*workflow.Tool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tools := make([]*workflow.Tool, 0, len(c.workflows))
+	for _, tool := range c.workflows {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name() < tools[j].Name() })
+	return tools
+}
+
+// loadDir registers the workflows defined in the YAML and JSON files of a
+// directory. A missing directory defines no workflows.
+func (c *workflowCatalog) loadDir(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read workflow directory: %w", err)
+	}
+
+	loaded := 0
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		definition, err := workflow.LoadFile(path)
+		if err != nil {
+			return loaded, fmt.Errorf("%s: %w", path, err)
+		}
+		if _, _, err := c.put(definition); err != nil {
+			return loaded, fmt.Errorf("%s: %w", path, err)
+		}
+		loaded++
+	}
+	return loaded, nil
+}
+
+// workflowResponse describes a registered workflow
+type workflowResponse struct {
+	Tool       string              `json:"tool"`
+	Definition workflow.Definition `json:"definition"`
+}
+
+func newWorkflowResponse(tool *workflow.Tool) workflowResponse {
+	return workflowResponse{Tool: tool.Name(), Definition: tool.Definition()}
+}
+
+// setupWorkflowRoutes mounts the endpoints that define composite workflow tools
+func (s *Server) setupWorkflowRoutes(router *gin.Engine) {
+	workflows := router.Group("/api/v1/workflows")
+
+	workflows.GET("", func(c *gin.Context) {
+		tools := s.workflows.list()
+		response := make([]workflowResponse, 0, len(tools))
+		for _, tool := range tools {
+			response = append(response, newWorkflowResponse(tool))
+		}
+		c.JSON(http.StatusOK, gin.H{"workflows": response, "count": len(response)})
+	})
+
+	workflows.GET("/:name", func(c *gin.Context) {
+		tool, exists := s.workflows.get(c.Param("name"))
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found"})
+			return
+		}
+		c.JSON(http.StatusOK, newWorkflowResponse(tool))
+	})
+
+	// Define or replace a workflow from a JSON or YAML body
+	workflows.POST("", requireWritable(s.readOnly), func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWorkflowSize+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		if len(body) > maxWorkflowSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "workflow definition too large"})
+			return
+		}
+		definition, err := workflow.Parse(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		tool, replaced, err := s.workflows.put(definition)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		status := http.StatusCreated
+		if replaced {
+			status = http.StatusOK
+		}
+		c.JSON(status, newWorkflowResponse(tool))
+	})
+
+	workflows.DELETE("/:name", requireWritable(s.readOnly), func(c *gin.Context) {
+		name := c.Param("name")
+		existed, err := s.workflows.remove(name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !existed {
+			c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "workflow removed", "workflow": name})
+	})
+}
@@ -0,0 +1,145 @@
+// Package demo boots a self-contained demo environment: bundled OpenAPI,
+// GraphQL and AsyncAPI specs whose upstreams are served in-process, so the
+// full import-and-invoke flow can be explored without external services.
+package demo
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"go.uber.org/zap"
+)
+
+const (
+	// AgentID identifies the agent session pre-registered in demo mode
+	AgentID = "demo-agent"
+
+	// AgentName is the display name of the demo agent
+	AgentName = "Demo Agent"
+
+	// upstreamHostPlaceholder is replaced in bundled specs with the mock upstream address
+	upstreamHostPlaceholder = "{{UPSTREAM_HOST}}"
+)
+
+//go:embed specs
+var bundledSpecs embed.FS
+
+// Environment is a running demo: the mock upstream and the specs pointing at it
+type Environment struct {
+	logger   *zap.Logger
+	server   *http.Server
+	specsDir string
+	baseURL  string
+	sources  []importer.SpecSource
+}
+
+// Start launches the mock upstream on a loopback port and writes the bundled
+// specs, rewritten to target it, to a temporary directory
+func Start(logger *zap.Logger) (*Environment, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for demo upstream: %w", err)
+	}
+	host := listener.Addr().String()
+
+	specsDir, err := os.MkdirTemp("", "aionmcp-demo-")
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to create demo spec directory: %w", err)
+	}
+
+	env := &Environment{
+		logger:   logger,
+		server:   &http.Server{Handler: newUpstream(), ReadHeaderTimeout: 10 * time.Second},
+		specsDir: specsDir,
+		baseURL:  "http://" + host,
+	}
+
+	if err := env.writeSpecs(host); err != nil {
+		listener.Close()
+		os.RemoveAll(specsDir)
+		return nil, err
+	}
+
+	go func() {
+		if err := env.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Demo upstream failed", zap.Error(err))
+		}
+	}()
+
+	logger.Info("Demo upstream started",
+		zap.String("url", env.baseURL),
+		zap.String("specs_dir", specsDir))
+
+	return env, nil
+}
+
+// writeSpecs materializes the bundled specs and builds their sources
+func (e *Environment) writeSpecs(host string) error {
+	specs := []struct {
+		file        string
+		specType    importer.SpecType
+		id          string
+		name        string
+		description string
+		metadata    map[string]string
+	}{
+		{"petstore.yaml", importer.SpecTypeOpenAPI, "demo-petstore", "Demo Petstore", "Petstore REST API backed by an in-memory mock", nil},
+		{"blog.graphql", importer.SpecTypeGraphQL, "demo-blog", "Demo Blog", "Blog GraphQL API backed by canned responses", map[string]string{"endpoint": e.baseURL + "/graphql"}},
+		{"user-events.json", importer.SpecTypeAsyncAPI, "demo-user-events", "Demo User Events", "User event channels relayed by an in-memory WebSocket hub", nil},
+	}
+
+	for _, spec := range specs {
+		content, err := bundledSpecs.ReadFile("specs/" + spec.file)
+		if err != nil {
+			return fmt.Errorf("failed to read bundled spec %s: %w", spec.file, err)
+		}
+		content = []byte(strings.ReplaceAll(string(content), upstreamHostPlaceholder, host))
+
+		path := filepath.Join(e.specsDir, spec.file)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write demo spec %s: %w", spec.file, err)
+		}
+
+		e.sources = append(e.sources, importer.SpecSource{
+			ID:          spec.id,
+			Type:        spec.specType,
+			Path:        path,
+			Name:        spec.name,
+			Description: spec.description,
+			Metadata:    spec.metadata,
+		})
+	}
+	return nil
+}
+
+// Sources returns the spec sources to import
+func (e *Environment) Sources() []importer.SpecSource {
+	return e.sources
+}
+
+// UpstreamURL returns the base URL of the mock upstream
+func (e *Environment) UpstreamURL() string {
+	return e.baseURL
+}
+
+// Close stops the mock upstream and removes the generated specs
+func (e *Environment) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := e.server.Shutdown(ctx)
+	if removeErr := os.RemoveAll(e.specsDir); removeErr != nil && err == nil {
+		err = removeErr
+	}
+	return err
+}
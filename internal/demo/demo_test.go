@@ -0,0 +1,89 @@
+package demo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/importer"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mapRegistry is a minimal importer.ToolRegistry for tests
+type mapRegistry map[string]types.Tool
+
+func (r mapRegistry) Register(tool types.Tool) error {
+	r[tool.Name()] = tool
+	return nil
+}
+
+func (r mapRegistry) Unregister(name string) error {
+	delete(r, name)
+	return nil
+}
+
+func TestDemoEnvironment(t *testing.T) {
+	env, err := Start(zap.NewNop())
+	require.NoError(t, err)
+	defer env.Close()
+
+	registry := mapRegistry{}
+	manager := importer.NewImporterManager(registry)
+	manager.RegisterImporter(importer.NewOpenAPIImporter())
+	manager.RegisterImporter(importer.NewGraphQLImporter())
+	manager.RegisterImporter(importer.NewAsyncAPIImporter())
+
+	assert.Len(t, env.Sources(), 3)
+	for _, source := range env.Sources() {
+		_, err := manager.ImportSpec(context.Background(), source)
+		require.NoError(t, err, source.ID)
+	}
+
+	// OpenAPI tools reach the in-memory petstore
+	tool, exists := registry["openapi.demo-petstore.getPet"]
+	require.True(t, exists)
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"petId": 1})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, 200, response["status_code"])
+	assert.Equal(t, "Rex", response["body"].(map[string]interface{})["name"])
+
+	// GraphQL tools reach the canned blog endpoint
+	tool, exists = registry["graphql.demo-blog.query_user"]
+	require.True(t, exists)
+	result, err = tool.Execute(context.Background(), map[string]interface{}{"id": "42"})
+	require.NoError(t, err)
+	data := result.(map[string]interface{})["data"].(map[string]interface{})
+	assert.Equal(t, "42", data["user"].(map[string]interface{})["id"])
+
+	// AsyncAPI tools publish to and subscribe from the WebSocket hub
+	subscribe, exists := registry["asyncapi.demo-user-events.subscribe_user_register"]
+	require.True(t, exists)
+	publish, exists := registry["asyncapi.demo-user-events.publish_user_register"]
+	require.True(t, exists)
+
+	received := make(chan interface{}, 1)
+	go func() {
+		result, err := subscribe.Execute(context.Background(), map[string]interface{}{"timeout": float64(5), "max_messages": float64(1)})
+		assert.NoError(t, err)
+		received <- result
+	}()
+	// Publish until the subscriber has connected and received a message
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	var subscription interface{}
+	for subscription == nil {
+		select {
+		case subscription = <-received:
+		case <-ticker.C:
+			_, err := publish.Execute(context.Background(), map[string]interface{}{"payload": map[string]interface{}{"userId": "u1"}})
+			require.NoError(t, err)
+		}
+	}
+	messages := subscription.(map[string]interface{})["messages"].([]map[string]interface{})
+	require.Len(t, messages, 1)
+	assert.Equal(t, "u1", messages[0]["payload"].(map[string]interface{})["userId"])
+}
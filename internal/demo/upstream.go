@@ -0,0 +1,336 @@
+package demo
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// pet mirrors the Pet schema of the bundled petstore spec
+type pet struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// petStore is the in-memory backend of the demo petstore
+type petStore struct {
+	mu     sync.Mutex
+	pets   map[int64]pet
+	nextID int64
+}
+
+// newUpstream builds the mock upstream serving the bundled specs
+func newUpstream() http.Handler {
+	store := &petStore{
+		pets: map[int64]pet{
+			1: {ID: 1, Name: "Rex", Category: "dog", Status: "available", Tags: []string{"friendly"}},
+			2: {ID: 2, Name: "Whiskers", Category: "cat", Status: "available"},
+			3: {ID: 3, Name: "Tweety", Category: "bird", Status: "sold"},
+		},
+		nextID: 4,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /petstore/pets", store.list)
+	mux.HandleFunc("POST /petstore/pets", store.create)
+	mux.HandleFunc("GET /petstore/pets/{petId}", store.get)
+	mux.HandleFunc("PUT /petstore/pets/{petId}", store.update)
+	mux.HandleFunc("DELETE /petstore/pets/{petId}", store.delete)
+	mux.HandleFunc("POST /graphql", serveGraphQL)
+	mux.HandleFunc("GET /events/", (&eventHub{connections: make(map[string]map[*eventConnection]bool)}).serve)
+	return mux
+}
+
+func (s *petStore) list(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 {
+		limit = value
+	}
+	category := r.URL.Query().Get("category")
+
+	s.mu.Lock()
+	pets := make([]pet, 0, len(s.pets))
+	for _, p := range s.pets {
+		if category == "" || p.Category == category {
+			pets = append(pets, p)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(pets, func(i, j int) bool { return pets[i].ID < pets[j].ID })
+	if len(pets) > limit {
+		pets = pets[:limit]
+	}
+	writeJSON(w, http.StatusOK, pets)
+}
+
+func (s *petStore) create(w http.ResponseWriter, r *http.Request) {
+	var p pet
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil || p.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+	if p.Status == "" {
+		p.Status = "available"
+	}
+
+	s.mu.Lock()
+	p.ID = s.nextID
+	s.nextID++
+	s.pets[p.ID] = p
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (s *petStore) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := petID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	p, exists := s.pets[id]
+	s.mu.Unlock()
+
+	if !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "pet not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *petStore) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := petID(w, r)
+	if !ok {
+		return
+	}
+	var p pet
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil || p.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+	p.ID = id
+
+	s.mu.Lock()
+	_, exists := s.pets[id]
+	if exists {
+		s.pets[id] = p
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "pet not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *petStore) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := petID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	_, exists := s.pets[id]
+	delete(s.pets, id)
+	s.mu.Unlock()
+
+	if !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "pet not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// petID parses the petId path parameter, answering 400 when it is invalid
+func petID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("petId"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "petId must be an integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+// serveGraphQL answers blog schema operations with canned data for the
+// requested root field
+func serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeGraphQLError(w, "invalid request body")
+		return
+	}
+
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		writeGraphQLError(w, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok || operation.SelectionSet == nil {
+			continue
+		}
+		for _, selection := range operation.SelectionSet.Selections {
+			if field, ok := selection.(*ast.Field); ok {
+				data[field.Name.Value] = cannedBlogData(field.Name.Value, request.Variables)
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+// cannedBlogData returns a fixed response for a blog schema root field
+func cannedBlogData(field string, variables map[string]interface{}) interface{} {
+	user := map[string]interface{}{
+		"__typename": "User",
+		"id":         "1",
+		"username":   "ada",
+		"email":      "ada@example.com",
+		"fullName":   "Ada Lovelace",
+		"active":     true,
+		"createdAt":  "2025-01-01T00:00:00Z",
+	}
+	post := map[string]interface{}{
+		"__typename": "Post",
+		"id":         "1",
+		"title":      "Notes on the Analytical Engine",
+		"content":    "The engine weaves algebraic patterns.",
+		"author":     user,
+		"createdAt":  "2025-01-02T00:00:00Z",
+		"published":  true,
+	}
+	if id, ok := variables["id"].(string); ok {
+		user["id"] = id
+		post["id"] = id
+	}
+
+	switch field {
+	case "user", "createUser", "updateUser":
+		return user
+	case "users":
+		return []interface{}{user}
+	case "post", "createPost", "updatePost":
+		return post
+	case "posts":
+		return []interface{}{post}
+	case "addComment":
+		return map[string]interface{}{
+			"__typename": "Comment",
+			"id":         "1",
+			"content":    variables["content"],
+			"author":     user,
+			"createdAt":  time.Now().UTC().Format(time.RFC3339),
+		}
+	case "deleteUser", "deletePost":
+		return true
+	case "serverStats":
+		return map[string]interface{}{
+			"__typename":   "ServerStats",
+			"userCount":    1,
+			"postCount":    1,
+			"commentCount": 0,
+			"uptime":       "demo",
+			"version":      "demo",
+		}
+	default:
+		return nil
+	}
+}
+
+func writeGraphQLError(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
+
+// writeJSON writes a JSON response. The content type carries no charset
+// parameter because the OpenAPI tool matches it exactly.
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// eventUpgrader accepts WebSocket connections to the event hub from any origin
+var eventUpgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// eventHub is the broker of the bundled AsyncAPI spec: a message sent on a
+// channel path is relayed to the other connections on that path
+type eventHub struct {
+	mu          sync.Mutex
+	connections map[string]map[*eventConnection]bool // path -> connections
+}
+
+// eventConnection serializes the writes to a hub connection
+type eventConnection struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (h *eventHub) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := eventUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	connection := &eventConnection{conn: conn}
+	path := r.URL.Path
+
+	h.mu.Lock()
+	if h.connections[path] == nil {
+		h.connections[path] = make(map[*eventConnection]bool)
+	}
+	h.connections[path][connection] = true
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.connections[path], connection)
+		h.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		messageType, payload, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		h.broadcast(path, connection, messageType, payload)
+	}
+}
+
+// broadcast relays a message to the connections on a path other than its sender
+func (h *eventHub) broadcast(path string, sender *eventConnection, messageType int, payload []byte) {
+	h.mu.Lock()
+	recipients := make([]*eventConnection, 0, len(h.connections[path]))
+	for connection := range h.connections[path] {
+		if connection != sender {
+			recipients = append(recipients, connection)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, recipient := range recipients {
+		recipient.mu.Lock()
+		recipient.conn.WriteMessage(messageType, payload)
+		recipient.mu.Unlock()
+	}
+}
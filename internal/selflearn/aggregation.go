@@ -0,0 +1,281 @@
+package selflearn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultWorkspace labels executions recorded without a workspace
+const DefaultWorkspace = "default"
+
+// Pattern and insight metadata keys that scope them to a workspace
+const (
+	MetadataWorkspace  = "workspace"  // Workspace a pattern or insight was computed for
+	MetadataScope      = "scope"      // ScopeWorkspace or ScopeGlobal
+	MetadataWorkspaces = "workspaces" // Number of workspaces behind a global aggregate
+)
+
+// Scopes of patterns and insights in aggregation-only mode
+const (
+	ScopeWorkspace = "workspace"
+	ScopeGlobal    = "global"
+)
+
+// AggregationConfig controls what shared instances learn across workspaces.
+// In aggregation-only mode patterns are computed per workspace, and global
+// patterns are only derived from aggregates that are k-anonymous: they span
+// at least MinWorkspaces workspaces and MinExecutions executions.
+type AggregationConfig struct {
+	Enabled       bool `json:"enabled"`
+	MinWorkspaces int  `json:"min_workspaces"` // k
+	MinExecutions int  `json:"min_executions"`
+}
+
+// DefaultAggregationConfig returns the default configuration, which shares
+// insights without restriction
+func DefaultAggregationConfig() AggregationConfig {
+	return AggregationConfig{
+		MinWorkspaces: 5,
+		MinExecutions: 50,
+	}
+}
+
+// executionWorkspace returns the workspace an execution was recorded for
+func executionWorkspace(record ExecutionRecord) string {
+	if workspace, ok := record.Context[MetadataWorkspace].(string); ok && workspace != "" {
+		return workspace
+	}
+	return DefaultWorkspace
+}
+
+// errorAggregate accumulates the failures of one tool with one error type
+type errorAggregate struct {
+	count      int
+	firstSeen  time.Time
+	lastSeen   time.Time
+	workspaces map[string]bool
+}
+
+// toolAggregate accumulates the executions of one tool
+type toolAggregate struct {
+	name         string
+	executions   int
+	successes    int
+	totalLatency time.Duration
+	firstSeen    time.Time
+	lastSeen     time.Time
+	errors       map[string]*errorAggregate // Error type -> failures
+	workspaces   map[string]bool
+}
+
+// scopeAggregate accumulates the executions of a workspace, or of all workspaces
+type scopeAggregate struct {
+	executions   int
+	totalLatency time.Duration
+	tools        map[string]*toolAggregate
+}
+
+func newScopeAggregate() *scopeAggregate {
+	return &scopeAggregate{tools: make(map[string]*toolAggregate)}
+}
+
+// add accounts an execution of a workspace to the aggregate
+func (s *scopeAggregate) add(workspace string, record ExecutionRecord) {
+	s.executions++
+	s.totalLatency += record.Duration
+
+	tool, exists := s.tools[record.ToolName]
+	if !exists {
+		tool = &toolAggregate{
+			name:       record.ToolName,
+			firstSeen:  record.Timestamp,
+			errors:     make(map[string]*errorAggregate),
+			workspaces: make(map[string]bool),
+		}
+		s.tools[record.ToolName] = tool
+	}
+	tool.executions++
+	tool.totalLatency += record.Duration
+	tool.workspaces[workspace] = true
+	tool.firstSeen, tool.lastSeen = widen(tool.firstSeen, tool.lastSeen, record.Timestamp)
+	if record.Success {
+		tool.successes++
+		return
+	}
+
+	group, exists := tool.errors[record.ErrorType]
+	if !exists {
+		group = &errorAggregate{firstSeen: record.Timestamp, workspaces: make(map[string]bool)}
+		tool.errors[record.ErrorType] = group
+	}
+	group.count++
+	group.workspaces[workspace] = true
+	group.firstSeen, group.lastSeen = widen(group.firstSeen, group.lastSeen, record.Timestamp)
+}
+
+// widen extends a time range to include t
+func widen(first, last, t time.Time) (time.Time, time.Time) {
+	if t.Before(first) {
+		first = t
+	}
+	if t.After(last) {
+		last = t
+	}
+	return first, last
+}
+
+// analyzeWorkspacePatterns computes the patterns of each workspace from its
+// own executions, and global patterns from k-anonymous aggregates only
+func (a *Analyzer) analyzeWorkspacePatterns(ctx context.Context) ([]Pattern, error) {
+	endTime := a.clock.Now()
+	startTime := endTime.Add(-24 * time.Hour) // Last 24 hours
+
+	global := newScopeAggregate()
+	workspaces := make(map[string]*scopeAggregate)
+	err := a.storage.IterateExecutions(ctx, startTime, endTime, func(record ExecutionRecord) error {
+		workspace := executionWorkspace(record)
+		scope, exists := workspaces[workspace]
+		if !exists {
+			scope = newScopeAggregate()
+			workspaces[workspace] = scope
+		}
+		scope.add(workspace, record)
+		global.add(workspace, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executions: %w", err)
+	}
+
+	names := make([]string, 0, len(workspaces))
+	for name := range workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var patterns []Pattern
+	for _, name := range names {
+		patterns = append(patterns, a.scopePatterns(workspaces[name], 0, 0, map[string]string{
+			MetadataScope:     ScopeWorkspace,
+			MetadataWorkspace: name,
+		})...)
+	}
+	patterns = append(patterns, a.scopePatterns(global, a.aggregation.MinWorkspaces, a.aggregation.MinExecutions, map[string]string{
+		MetadataScope: ScopeGlobal,
+	})...)
+	return patterns, nil
+}
+
+// scopePatterns derives error, performance and usage patterns from an
+// aggregate. Tools and error groups seen in fewer than minWorkspaces
+// workspaces or minExecutions executions are left out.
+func (a *Analyzer) scopePatterns(scope *scopeAggregate, minWorkspaces, minExecutions int, metadata map[string]string) []Pattern {
+	if scope.executions == 0 {
+		return nil
+	}
+	averageLatency := scope.totalLatency / time.Duration(scope.executions)
+
+	names := make([]string, 0, len(scope.tools))
+	for name := range scope.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var patterns []Pattern
+	newPattern := func(pattern Pattern, workspaces int, fields map[string]string) {
+		if metadata[MetadataScope] == ScopeGlobal {
+			fields[MetadataWorkspaces] = strconv.Itoa(workspaces)
+		}
+		pattern.ID = a.generatePatternID()
+		pattern.Metadata = make(map[string]string, len(fields)+len(metadata))
+		for key, value := range metadata {
+			pattern.Metadata[key] = value
+		}
+		for key, value := range fields {
+			pattern.Metadata[key] = value
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	for _, name := range names {
+		tool := scope.tools[name]
+		if len(tool.workspaces) < minWorkspaces || tool.executions < minExecutions {
+			continue
+		}
+
+		errorTypes := make([]string, 0, len(tool.errors))
+		for errorType := range tool.errors {
+			errorTypes = append(errorTypes, errorType)
+		}
+		sort.Strings(errorTypes)
+		for _, errorType := range errorTypes {
+			group := tool.errors[errorType]
+			if group.count < 3 || len(group.workspaces) < minWorkspaces { // Same threshold as analyzeErrorPatterns
+				continue
+			}
+			newPattern(Pattern{
+				Type:        PatternTypeError,
+				Description: fmt.Sprintf("Recurring %s errors in %s tool", errorType, name),
+				Frequency:   group.count,
+				Confidence:  a.calculateConfidence(group.count, scope.executions),
+				FirstSeen:   group.firstSeen,
+				LastSeen:    group.lastSeen,
+			}, len(group.workspaces), map[string]string{
+				"tool_name":  name,
+				"error_type": errorType,
+			})
+		}
+
+		toolLatency := tool.totalLatency / time.Duration(tool.executions)
+		if toolLatency > averageLatency*2 {
+			newPattern(Pattern{
+				Type:        PatternTypePerformance,
+				Description: fmt.Sprintf("Tool %s shows consistently slow performance", name),
+				Frequency:   tool.executions,
+				Confidence:  0.8,
+				FirstSeen:   tool.firstSeen,
+				LastSeen:    tool.lastSeen,
+			}, len(tool.workspaces), map[string]string{
+				"tool_name":       name,
+				"average_latency": toolLatency.String(),
+				"execution_count": strconv.Itoa(tool.executions),
+				"success_rate":    fmt.Sprintf("%.2f", float64(tool.successes)/float64(tool.executions)),
+			})
+		}
+
+		if usagePercentage := float64(tool.executions) / float64(scope.executions) * 100; usagePercentage > 50 {
+			newPattern(Pattern{
+				Type:        PatternTypeUsage,
+				Description: fmt.Sprintf("Tool %s dominates usage with %.1f%% of all executions", name, usagePercentage),
+				Frequency:   tool.executions,
+				Confidence:  0.9,
+				FirstSeen:   tool.firstSeen,
+				LastSeen:    tool.lastSeen,
+			}, len(tool.workspaces), map[string]string{
+				"tool_name":        name,
+				"usage_percentage": fmt.Sprintf("%.1f", usagePercentage),
+				"execution_count":  strconv.Itoa(tool.executions),
+			})
+		}
+	}
+	return patterns
+}
+
+// scopeInsight copies the scope of the pattern an insight was derived from
+func scopeInsight(insight *Insight, pattern Pattern) {
+	for _, key := range []string{MetadataScope, MetadataWorkspace, MetadataWorkspaces} {
+		if value, ok := pattern.Metadata[key]; ok {
+			insight.Metadata[key] = value
+		}
+	}
+}
+
+// visibleTo reports whether a workspace may see a pattern or insight with the
+// given metadata. Only patterns and insights of other workspaces are hidden.
+func visibleTo(metadata map[string]string, workspace string) bool {
+	owner, scoped := metadata[MetadataWorkspace]
+	return !scoped || owner == workspace
+}
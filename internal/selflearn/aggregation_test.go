@@ -0,0 +1,98 @@
+package selflearn
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestEngine creates an engine over memory storage that records
+// executions synchronously, timestamped by a fake clock starting at now
+func newTestEngine(t *testing.T, now time.Time) (*Engine, *learning.MemoryStorage, *clock.Fake) {
+	storage := learning.NewMemoryStorage()
+	config := DefaultCollectionConfig()
+	config.AsyncProcessing = false
+	engine := NewEngine(config, storage, zap.NewNop())
+	t.Cleanup(func() { engine.Close() })
+	fake := clock.NewFake(now)
+	engine.SetClock(fake)
+	return engine, storage, fake
+}
+
+func TestAggregationOnly(t *testing.T) {
+	storage, err := NewBoltStorage(filepath.Join(t.TempDir(), "learning.db"), zap.NewNop())
+	require.NoError(t, err)
+	config := DefaultCollectionConfig()
+	config.AsyncProcessing = false
+	engine := NewEngine(config, storage, zap.NewNop())
+	defer engine.Close()
+	engine.SetAggregation(AggregationConfig{Enabled: true, MinWorkspaces: 3, MinExecutions: 5})
+
+	ctx := context.Background()
+	record := func(workspace, tool string, times int) {
+		for i := 0; i < times; i++ {
+			require.NoError(t, engine.RecordExecution(WithWorkspace(ctx, workspace), tool, "openapi",
+				nil, nil, errors.New("connection refused"), time.Millisecond))
+		}
+	}
+	// One workspace fails often; three share a tool that fails now and then
+	record("acme", "openapi.billing.charge", 5)
+	for _, workspace := range []string{"w1", "w2", "w3"} {
+		record(workspace, "openapi.maps.geocode", 2)
+	}
+
+	patterns, err := engine.AnalyzePatterns(ctx)
+	require.NoError(t, err)
+	var acmeErrors, globalErrors []Pattern
+	for _, pattern := range patterns {
+		if pattern.Type != PatternTypeError {
+			continue
+		}
+		switch pattern.Metadata[MetadataScope] {
+		case ScopeWorkspace:
+			assert.Equal(t, "acme", pattern.Metadata[MetadataWorkspace])
+			acmeErrors = append(acmeErrors, pattern)
+		case ScopeGlobal:
+			globalErrors = append(globalErrors, pattern)
+		}
+	}
+
+	// The single-workspace failures stay with their workspace
+	require.Len(t, acmeErrors, 1)
+	assert.Equal(t, "openapi.billing.charge", acmeErrors[0].Metadata["tool_name"])
+
+	// Only the failures spread across k workspaces are shared, without naming them
+	require.Len(t, globalErrors, 1)
+	assert.Equal(t, "openapi.maps.geocode", globalErrors[0].Metadata["tool_name"])
+	assert.Equal(t, 6, globalErrors[0].Frequency)
+	assert.Equal(t, "3", globalErrors[0].Metadata[MetadataWorkspaces])
+	assert.NotContains(t, globalErrors[0].Metadata, MetadataWorkspace)
+
+	// Each workspace sees its own and the global patterns and insights only
+	insights, err := engine.GenerateInsights(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, insights)
+	for _, workspace := range []string{"acme", "w1"} {
+		for _, pattern := range engine.VisiblePatterns(patterns, workspace) {
+			if owner, scoped := pattern.Metadata[MetadataWorkspace]; scoped {
+				assert.Equal(t, workspace, owner)
+			}
+		}
+		visible := engine.VisibleInsights(insights, workspace)
+		assert.NotEmpty(t, visible)
+		assert.Less(t, len(visible), len(insights))
+		for _, insight := range visible {
+			if owner, scoped := insight.Metadata[MetadataWorkspace]; scoped {
+				assert.Equal(t, workspace, owner)
+			}
+		}
+	}
+}
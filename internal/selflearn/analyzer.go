@@ -7,13 +7,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/clock"
 	"go.uber.org/zap"
 )
 
 // Analyzer performs pattern analysis on execution data
 type Analyzer struct {
-	storage Storage
-	logger  *zap.Logger
+	storage     Storage
+	logger      *zap.Logger
+	aggregation AggregationConfig
+	clock       clock.Clock // Ends the analysis window
 }
 
 // NewAnalyzer creates a new pattern analyzer
@@ -21,6 +24,7 @@ func NewAnalyzer(storage Storage, logger *zap.Logger) *Analyzer {
 	return &Analyzer{
 		storage: storage,
 		logger:  logger,
+		clock:   clock.Real{},
 	}
 }
 
@@ -30,6 +34,15 @@ func (a *Analyzer) AnalyzePatterns(ctx context.Context) ([]Pattern, error) {
 
 	var patterns []Pattern
 
+	if a.aggregation.Enabled {
+		// Keep workspaces apart and share only k-anonymous aggregates
+		workspacePatterns, err := a.analyzeWorkspacePatterns(ctx)
+		if err != nil {
+			a.logger.Error("Failed to analyze workspace patterns", zap.Error(err))
+		}
+		return a.storePatterns(ctx, workspacePatterns), nil
+	}
+
 	// Analyze error patterns
 	errorPatterns, err := a.analyzeErrorPatterns(ctx)
 	if err != nil {
@@ -54,7 +67,11 @@ func (a *Analyzer) AnalyzePatterns(ctx context.Context) ([]Pattern, error) {
 		patterns = append(patterns, usagePatterns...)
 	}
 
-	// Store discovered patterns
+	return a.storePatterns(ctx, patterns), nil
+}
+
+// storePatterns stores discovered patterns
+func (a *Analyzer) storePatterns(ctx context.Context, patterns []Pattern) []Pattern {
 	for _, pattern := range patterns {
 		if err := a.storage.StorePattern(ctx, pattern); err != nil {
 			a.logger.Error("Failed to store pattern", 
@@ -64,13 +81,13 @@ func (a *Analyzer) AnalyzePatterns(ctx context.Context) ([]Pattern, error) {
 	}
 
 	a.logger.Info("Pattern analysis completed", zap.Int("patterns_found", len(patterns)))
-	return patterns, nil
+	return patterns
 }
 
 // analyzeErrorPatterns identifies common error patterns
 func (a *Analyzer) analyzeErrorPatterns(ctx context.Context) ([]Pattern, error) {
 	// Get recent executions with errors
-	endTime := time.Now()
+	endTime := a.clock.Now()
 	startTime := endTime.Add(-24 * time.Hour) // Last 24 hours
 	
 	executions, err := a.storage.GetExecutionsByTimeRange(ctx, startTime, endTime, 1000)
@@ -0,0 +1,40 @@
+package selflearn
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBackupUnsupported is returned when the storage cannot back up or restore
+var ErrBackupUnsupported = errors.New("storage does not support backup and restore")
+
+// ErrInvalidSnapshot is returned when restoring data that is not a snapshot
+// of the storage
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// Backuper is implemented by storage backends that can write a consistent
+// snapshot of their data and replace their data with one
+type Backuper interface {
+	Backup(ctx context.Context, w io.Writer) (int64, error)
+	Restore(ctx context.Context, r io.Reader) error
+}
+
+// Backup writes a consistent snapshot of the learning storage to w,
+// returning the number of bytes written
+func (e *Engine) Backup(ctx context.Context, w io.Writer) (int64, error) {
+	backuper, ok := e.storage.(Backuper)
+	if !ok {
+		return 0, ErrBackupUnsupported
+	}
+	return backuper.Backup(ctx, w)
+}
+
+// Restore replaces the learning storage's data with a snapshot written by Backup
+func (e *Engine) Restore(ctx context.Context, r io.Reader) error {
+	backuper, ok := e.storage.(Backuper)
+	if !ok {
+		return ErrBackupUnsupported
+	}
+	return backuper.Restore(ctx, r)
+}
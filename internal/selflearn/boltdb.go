@@ -1,32 +1,46 @@
 package selflearn
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/learning"
 	bolt "go.etcd.io/bbolt"
 	"go.uber.org/zap"
 )
 
 // BoltStorage implements Storage interface using BoltDB
 type BoltStorage struct {
+	mu     sync.RWMutex // Held exclusively while compaction replaces db
 	db     *bolt.DB
+	closed bool
 	logger *zap.Logger
+	clock  clock.Clock // Judges retention
 }
 
 // Bucket names for different data types
 const (
-	ExecutionsBucket = "executions"
-	PatternsBucket   = "patterns"
-	InsightsBucket   = "insights"
-	StatsBucket      = "stats"
+	ExecutionsBucket       = "executions"
+	ExecutionsByToolBucket = "executions_by_tool"
+	AggregatesBucket       = "hourly_aggregates"
+	PatternsBucket         = "patterns"
+	InsightsBucket         = "insights"
+	StatsBucket            = "stats"
 )
 
+// toolIndexKey marks in the stats bucket that the executions of a database
+// have been indexed by tool, so older databases are indexed once on open
+const toolIndexKey = "executions_by_tool_indexed"
+
 // NewBoltStorage creates a new BoltDB storage instance
 func NewBoltStorage(dbPath string, logger *zap.Logger) (*BoltStorage, error) {
 	// Ensure directory exists
@@ -44,6 +58,7 @@ func NewBoltStorage(dbPath string, logger *zap.Logger) (*BoltStorage, error) {
 	storage := &BoltStorage{
 		db:     db,
 		logger: logger,
+		clock:  clock.Real{},
 	}
 
 	// Initialize buckets
@@ -51,14 +66,33 @@ func NewBoltStorage(dbPath string, logger *zap.Logger) (*BoltStorage, error) {
 		db.Close()
 		return nil, fmt.Errorf("failed to initialize buckets: %w", err)
 	}
+	if err := storage.indexExecutionsByTool(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to index executions by tool: %w", err)
+	}
 
 	return storage, nil
 }
 
-// initBuckets creates the required buckets if they don't exist
+// view runs a read-only transaction
+func (s *BoltStorage) view(fn func(tx *bolt.Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.View(fn)
+}
+
+// update runs a read-write transaction
+func (s *BoltStorage) update(fn func(tx *bolt.Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.Update(fn)
+}
+
+// initBuckets creates the required buckets if they don't exist. Callers
+// hold s.mu or have not shared the storage yet.
 func (s *BoltStorage) initBuckets() error {
 	return s.db.Update(func(tx *bolt.Tx) error {
-		buckets := []string{ExecutionsBucket, PatternsBucket, InsightsBucket, StatsBucket}
+		buckets := []string{ExecutionsBucket, ExecutionsByToolBucket, AggregatesBucket, PatternsBucket, InsightsBucket, StatsBucket}
 		for _, bucket := range buckets {
 			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
 				return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
@@ -68,6 +102,52 @@ func (s *BoltStorage) initBuckets() error {
 	})
 }
 
+// indexExecutionsByTool builds the tool index of a database written before
+// it existed. Records stored later are indexed as they are written. Callers
+// hold s.mu or have not shared the storage yet.
+func (s *BoltStorage) indexExecutionsByTool() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		stats := tx.Bucket([]byte(StatsBucket))
+		if stats.Get([]byte(toolIndexKey)) != nil {
+			return nil
+		}
+
+		index := tx.Bucket([]byte(ExecutionsByToolBucket))
+		indexed := 0
+		cursor := tx.Bucket([]byte(ExecutionsBucket)).Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var record ExecutionRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				continue // Cleanup removes invalid records
+			}
+			if err := index.Put(toolIndexEntry(record.ToolName, k), nil); err != nil {
+				return err
+			}
+			indexed++
+		}
+		if indexed > 0 {
+			s.logger.Info("Indexed existing executions by tool", zap.Int("records", indexed))
+		}
+		return stats.Put([]byte(toolIndexKey), []byte(s.clock.Now().UTC().Format(time.RFC3339Nano)))
+	})
+}
+
+// toolIndexPrefix starts the index entries of a tool
+func toolIndexPrefix(toolName string) []byte {
+	return append([]byte(toolName), 0)
+}
+
+// toolIndexEntry indexes an execution key under its tool. Entries of a tool
+// are contiguous and in time order, and end with the execution key.
+func toolIndexEntry(toolName string, key []byte) []byte {
+	return append(toolIndexPrefix(toolName), key...)
+}
+
+// executionKey orders records by time, then ID
+func executionKey(record ExecutionRecord) string {
+	return fmt.Sprintf("%d_%s", record.Timestamp.Unix(), record.ID)
+}
+
 // StoreExecution stores an execution record
 func (s *BoltStorage) StoreExecution(ctx context.Context, record ExecutionRecord) error {
 	data, err := json.Marshal(record)
@@ -75,15 +155,18 @@ func (s *BoltStorage) StoreExecution(ctx context.Context, record ExecutionRecord
 		return fmt.Errorf("failed to marshal execution record: %w", err)
 	}
 
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(ExecutionsBucket))
 		if bucket == nil {
 			return fmt.Errorf("executions bucket not found")
 		}
 
 		// Use timestamp + ID as key for time-based ordering
-		key := fmt.Sprintf("%d_%s", record.Timestamp.Unix(), record.ID)
-		return bucket.Put([]byte(key), data)
+		key := []byte(executionKey(record))
+		if err := bucket.Put(key, data); err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(ExecutionsByToolBucket)).Put(toolIndexEntry(record.ToolName, key), nil)
 	})
 }
 
@@ -91,7 +174,7 @@ func (s *BoltStorage) StoreExecution(ctx context.Context, record ExecutionRecord
 func (s *BoltStorage) GetExecution(ctx context.Context, id string) (ExecutionRecord, error) {
 	var record ExecutionRecord
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(ExecutionsBucket))
 		if bucket == nil {
 			return fmt.Errorf("executions bucket not found")
@@ -115,31 +198,44 @@ func (s *BoltStorage) GetExecution(ctx context.Context, id string) (ExecutionRec
 	return record, err
 }
 
-// GetExecutionsByTool retrieves execution records for a specific tool
+// GetExecutionsByTool retrieves execution records for a specific tool,
+// newest first, reading only that tool's entries in the tool index
 func (s *BoltStorage) GetExecutionsByTool(ctx context.Context, toolName string, limit int) ([]ExecutionRecord, error) {
 	var records []ExecutionRecord
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(ExecutionsBucket))
 		if bucket == nil {
 			return fmt.Errorf("executions bucket not found")
 		}
+		if limit <= 0 {
+			return nil
+		}
 
-		cursor := bucket.Cursor()
-		count := 0
+		prefix := toolIndexPrefix(toolName)
+		cursor := tx.Bucket([]byte(ExecutionsByToolBucket)).Cursor()
+
+		// Entries of the next tool name start after the prefix's last byte is
+		// incremented; step back from there to this tool's newest entry
+		end := append([]byte(toolName), 1)
+		k, _ := cursor.Seek(end)
+		if k == nil {
+			k, _ = cursor.Last()
+		} else {
+			k, _ = cursor.Prev()
+		}
 
-		// Iterate in reverse order (newest first)
-		for k, v := cursor.Last(); k != nil && count < limit; k, v = cursor.Prev() {
+		for ; k != nil && bytes.HasPrefix(k, prefix) && len(records) < limit; k, _ = cursor.Prev() {
+			data := bucket.Get(k[len(prefix):])
+			if data == nil {
+				continue // Left behind by a record Cleanup removed
+			}
 			var record ExecutionRecord
-			if err := json.Unmarshal(v, &record); err != nil {
+			if err := json.Unmarshal(data, &record); err != nil {
 				s.logger.Warn("Failed to unmarshal execution record", zap.Error(err))
 				continue
 			}
-
-			if record.ToolName == toolName {
-				records = append(records, record)
-				count++
-			}
+			records = append(records, record)
 		}
 
 		return nil
@@ -152,7 +248,7 @@ func (s *BoltStorage) GetExecutionsByTool(ctx context.Context, toolName string,
 func (s *BoltStorage) GetExecutionsByTimeRange(ctx context.Context, start, end time.Time, limit int) ([]ExecutionRecord, error) {
 	var records []ExecutionRecord
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(ExecutionsBucket))
 		if bucket == nil {
 			return fmt.Errorf("executions bucket not found")
@@ -197,108 +293,73 @@ func (s *BoltStorage) GetExecutionsByTimeRange(ctx context.Context, start, end t
 	return records, err
 }
 
-// GetExecutionStats calculates and returns learning statistics
-func (s *BoltStorage) GetExecutionStats(ctx context.Context) (LearningStats, error) {
-	stats := LearningStats{
-		ErrorBreakdown: make(map[string]int),
-		TopTools:       []ToolStat{},
-		LastUpdated:    time.Now().UTC(),
-	}
-
-	err := s.db.View(func(tx *bolt.Tx) error {
+// IterateExecutions calls fn for every execution record in the time range, oldest
+// first, without loading the range into memory. Iteration stops at the first error.
+func (s *BoltStorage) IterateExecutions(ctx context.Context, start, end time.Time, fn func(ExecutionRecord) error) error {
+	return s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(ExecutionsBucket))
 		if bucket == nil {
 			return fmt.Errorf("executions bucket not found")
 		}
 
-		toolStats := make(map[string]*ToolStat)
-		var totalDuration time.Duration
-		var successCount int64
-
 		cursor := bucket.Cursor()
-		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
-			var record ExecutionRecord
-			if err := json.Unmarshal(v, &record); err != nil {
-				continue
+		startKey := []byte(fmt.Sprintf("%d_", start.Unix()))
+		endTimestamp := end.Unix()
+
+		for k, v := cursor.Seek(startKey); k != nil; k, v = cursor.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
 
-			stats.TotalExecutions++
-			totalDuration += record.Duration
+			var keyTimestamp int64
+			if _, err := fmt.Sscanf(string(k), "%d_", &keyTimestamp); err != nil {
+				s.logger.Warn("Failed to parse timestamp from key", zap.String("key", string(k)))
+				continue
+			}
+			if keyTimestamp > endTimestamp {
+				break
+			}
 
-			if record.Success {
-				successCount++
-			} else {
-				stats.ErrorBreakdown[record.ErrorType]++
+			var record ExecutionRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				s.logger.Warn("Failed to unmarshal execution record", zap.Error(err))
+				continue
 			}
 
-			// Update tool statistics
-			if toolStat, exists := toolStats[record.ToolName]; exists {
-				toolStat.ExecutionCount++
-				if record.Success {
-					toolStat.SuccessCount++
-				} else {
-					toolStat.FailureCount++
-				}
-				// Calculate success rate from counts to avoid floating-point errors
-				toolStat.SuccessRate = float64(toolStat.SuccessCount) / float64(toolStat.ExecutionCount)
-				// Use incremental mean formula for numerically stable running average
-				prevAvg := float64(toolStat.AverageLatency.Nanoseconds())
-				newVal := float64(record.Duration.Nanoseconds())
-				n := float64(toolStat.ExecutionCount)
-				toolStat.AverageLatency = time.Duration(prevAvg + (newVal-prevAvg)/n)
-				// Track first and last used times
-				if record.Timestamp.Before(toolStat.FirstUsed) {
-					toolStat.FirstUsed = record.Timestamp
-				}
-				if record.Timestamp.After(toolStat.LastUsed) {
-					toolStat.LastUsed = record.Timestamp
-				}
-			} else {
-				successCount := int64(0)
-				failureCount := int64(0)
-				successRate := 0.0
-				if record.Success {
-					successCount = 1
-					successRate = 1.0
-				} else {
-					failureCount = 1
-				}
-				toolStats[record.ToolName] = &ToolStat{
-					Name:           record.ToolName,
-					ExecutionCount: 1,
-					SuccessCount:   successCount,
-					FailureCount:   failureCount,
-					SuccessRate:    successRate,
-					AverageLatency: record.Duration,
-					FirstUsed:      record.Timestamp,
-					LastUsed:       record.Timestamp,
+			if !record.Timestamp.Before(start) && !record.Timestamp.After(end) {
+				if err := fn(record); err != nil {
+					return err
 				}
 			}
 		}
 
-		// Calculate overall statistics
-		if stats.TotalExecutions > 0 {
-			stats.SuccessRate = float64(successCount) / float64(stats.TotalExecutions)
-			stats.AverageLatency = totalDuration / time.Duration(stats.TotalExecutions)
-		}
+		return nil
+	})
+}
+
+// GetExecutionStats calculates and returns learning statistics
+func (s *BoltStorage) GetExecutionStats(ctx context.Context) (LearningStats, error) {
+	accumulator := learning.NewStatsAccumulator()
 
-		// Convert tool stats to slice and sort by execution count
-		for _, stat := range toolStats {
-			stats.TopTools = append(stats.TopTools, *stat)
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(ExecutionsBucket))
+		if bucket == nil {
+			return fmt.Errorf("executions bucket not found")
 		}
-		sort.Slice(stats.TopTools, func(i, j int) bool {
-			return stats.TopTools[i].ExecutionCount > stats.TopTools[j].ExecutionCount
-		})
 
-		// Limit to top 10 tools
-		if len(stats.TopTools) > 10 {
-			stats.TopTools = stats.TopTools[:10]
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var record ExecutionRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				continue
+			}
+			accumulator.Add(record)
 		}
 
 		return nil
 	})
 
-	return stats, err
+	return accumulator.Stats(), err
 }
 
 // StorePattern stores a pattern
@@ -308,7 +369,7 @@ func (s *BoltStorage) StorePattern(ctx context.Context, pattern Pattern) error {
 		return fmt.Errorf("failed to marshal pattern: %w", err)
 	}
 
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(PatternsBucket))
 		return bucket.Put([]byte(pattern.ID), data)
 	})
@@ -318,7 +379,7 @@ func (s *BoltStorage) StorePattern(ctx context.Context, pattern Pattern) error {
 func (s *BoltStorage) GetPattern(ctx context.Context, id string) (Pattern, error) {
 	var pattern Pattern
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(PatternsBucket))
 		data := bucket.Get([]byte(id))
 		if data == nil {
@@ -334,7 +395,7 @@ func (s *BoltStorage) GetPattern(ctx context.Context, id string) (Pattern, error
 func (s *BoltStorage) GetPatterns(ctx context.Context, patternType PatternType, limit int) ([]Pattern, error) {
 	var patterns []Pattern
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(PatternsBucket))
 		cursor := bucket.Cursor()
 		count := 0
@@ -364,7 +425,7 @@ func (s *BoltStorage) UpdatePattern(ctx context.Context, pattern Pattern) error
 
 // DeletePattern deletes a pattern
 func (s *BoltStorage) DeletePattern(ctx context.Context, id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(PatternsBucket))
 		return bucket.Delete([]byte(id))
 	})
@@ -377,7 +438,7 @@ func (s *BoltStorage) StoreInsight(ctx context.Context, insight Insight) error {
 		return fmt.Errorf("failed to marshal insight: %w", err)
 	}
 
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(InsightsBucket))
 		return bucket.Put([]byte(insight.ID), data)
 	})
@@ -387,7 +448,7 @@ func (s *BoltStorage) StoreInsight(ctx context.Context, insight Insight) error {
 func (s *BoltStorage) GetInsight(ctx context.Context, id string) (Insight, error) {
 	var insight Insight
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(InsightsBucket))
 		data := bucket.Get([]byte(id))
 		if data == nil {
@@ -403,7 +464,7 @@ func (s *BoltStorage) GetInsight(ctx context.Context, id string) (Insight, error
 func (s *BoltStorage) GetInsights(ctx context.Context, insightType InsightType, limit int) ([]Insight, error) {
 	var insights []Insight
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(InsightsBucket))
 		cursor := bucket.Cursor()
 		count := 0
@@ -430,7 +491,7 @@ func (s *BoltStorage) GetInsights(ctx context.Context, insightType InsightType,
 func (s *BoltStorage) GetInsightsByPriority(ctx context.Context, priority Priority, limit int) ([]Insight, error) {
 	var insights []Insight
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err := s.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(InsightsBucket))
 		cursor := bucket.Cursor()
 		count := 0
@@ -460,12 +521,17 @@ func (s *BoltStorage) UpdateInsight(ctx context.Context, insight Insight) error
 
 // DeleteInsight deletes an insight
 func (s *BoltStorage) DeleteInsight(ctx context.Context, id string) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(InsightsBucket))
 		return bucket.Delete([]byte(id))
 	})
 }
 
+// SetClock replaces the clock retention is judged by
+func (s *BoltStorage) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // Cleanup removes old records based on retention period.
 // For large datasets, keys are collected during cursor iteration and then deleted
 // in a separate loop to avoid modifying the bucket during cursor iteration,
@@ -474,13 +540,15 @@ func (s *BoltStorage) DeleteInsight(ctx context.Context, id string) error {
 // operations (millions of records), consider implementing batched deletion to
 // reduce peak memory usage.
 func (s *BoltStorage) Cleanup(ctx context.Context, retentionPeriod time.Duration) error {
-	cutoff := time.Now().Add(-retentionPeriod)
+	cutoff := s.clock.Now().Add(-retentionPeriod)
 	
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(ExecutionsBucket))
+		index := tx.Bucket([]byte(ExecutionsByToolBucket))
 		cursor := bucket.Cursor()
 		
 		var keysToDelete [][]byte
+		var entriesToDelete [][]byte
 		
 		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
 			var record ExecutionRecord
@@ -493,15 +561,21 @@ func (s *BoltStorage) Cleanup(ctx context.Context, retentionPeriod time.Duration
 			if record.Timestamp.Before(cutoff) {
 				// Copy key before appending since cursor keys are only valid during iteration
 				keysToDelete = append(keysToDelete, copyKey(k))
+				entriesToDelete = append(entriesToDelete, toolIndexEntry(record.ToolName, k))
 			}
 		}
 		
-		// Delete old records
+		// Delete old records and their index entries
 		for _, key := range keysToDelete {
 			if err := bucket.Delete(key); err != nil {
 				s.logger.Warn("Failed to delete old record", zap.Error(err))
 			}
 		}
+		for _, entry := range entriesToDelete {
+			if err := index.Delete(entry); err != nil {
+				s.logger.Warn("Failed to delete tool index entry", zap.Error(err))
+			}
+		}
 		
 		s.logger.Info("Cleanup completed", zap.Int("deleted_records", len(keysToDelete)))
 		return nil
@@ -513,8 +587,295 @@ func copyKey(k []byte) []byte {
 	return append([]byte(nil), k...)
 }
 
+// Downsample folds executions older than the retention period into hourly
+// aggregates and removes them with their tool index entries
+func (s *BoltStorage) Downsample(ctx context.Context, retentionPeriod time.Duration) (int, error) {
+	cutoff := s.clock.Now().Add(-retentionPeriod)
+	removed := 0
+
+	err := s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(ExecutionsBucket))
+		index := tx.Bucket([]byte(ExecutionsByToolBucket))
+		aggregatesBucket := tx.Bucket([]byte(AggregatesBucket))
+		aggregates := make(learning.HourlyAggregates)
+		var keysToDelete, entriesToDelete [][]byte
+
+		// Keys start with the timestamp, so old executions come first
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var keyTimestamp int64
+			if _, err := fmt.Sscanf(string(k), "%d_", &keyTimestamp); err == nil && keyTimestamp > cutoff.Unix() {
+				break
+			}
+			var record ExecutionRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				keysToDelete = append(keysToDelete, copyKey(k))
+				continue
+			}
+			if !record.Timestamp.Before(cutoff) {
+				continue
+			}
+			aggregates.Add(record)
+			keysToDelete = append(keysToDelete, copyKey(k))
+			entriesToDelete = append(entriesToDelete, toolIndexEntry(record.ToolName, k))
+			removed++
+		}
+
+		for key, aggregate := range aggregates {
+			if data := aggregatesBucket.Get([]byte(key)); data != nil {
+				var stored HourlyAggregate
+				if err := json.Unmarshal(data, &stored); err == nil {
+					aggregate.Merge(stored)
+				}
+			}
+			data, err := json.Marshal(aggregate)
+			if err != nil {
+				return fmt.Errorf("failed to marshal hourly aggregate: %w", err)
+			}
+			if err := aggregatesBucket.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		for _, key := range keysToDelete {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		for _, entry := range entriesToDelete {
+			if err := index.Delete(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("Downsampling completed", zap.Int("downsampled_records", removed))
+	return removed, nil
+}
+
+// GetHourlyAggregates returns the hourly aggregates of hours starting in the
+// time range, oldest first. An empty tool name selects every tool.
+func (s *BoltStorage) GetHourlyAggregates(ctx context.Context, toolName string, start, end time.Time) ([]HourlyAggregate, error) {
+	aggregates := []HourlyAggregate{}
+
+	err := s.view(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(AggregatesBucket)).Cursor()
+		k, v := cursor.First()
+		if toolName != "" {
+			k, v = cursor.Seek([]byte(learning.AggregateKey(toolName, start.UTC().Truncate(time.Hour))))
+		}
+		for ; k != nil; k, v = cursor.Next() {
+			tool, hour, found := bytes.Cut(k, []byte{0})
+			if !found {
+				continue
+			}
+			if toolName != "" && string(tool) != toolName {
+				break
+			}
+			seconds, err := strconv.ParseInt(string(hour), 10, 64)
+			if err != nil {
+				continue
+			}
+			if at := time.Unix(seconds, 0); at.Before(start) || at.After(end) {
+				continue
+			}
+			var aggregate HourlyAggregate
+			if err := json.Unmarshal(v, &aggregate); err != nil {
+				s.logger.Warn("Failed to unmarshal hourly aggregate", zap.Error(err))
+				continue
+			}
+			aggregates = append(aggregates, aggregate)
+		}
+		return nil
+	})
+
+	learning.SortAggregates(aggregates)
+	return aggregates, err
+}
+
+// Compact rewrites the database into a new file without the free pages
+// left by removed records, then replaces the old file with it. Other
+// operations wait until the compacted database is open.
+func (s *BoltStorage) Compact(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return bolt.ErrDatabaseNotOpen
+	}
+
+	path := s.db.Path()
+	compactPath := path + ".compact"
+	before, _ := os.Stat(path)
+	if err := os.Remove(compactPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	compacted, err := bolt.Open(compactPath, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return fmt.Errorf("failed to create compacted database: %w", err)
+	}
+	if err := bolt.Compact(compacted, s.db, compactTxMaxSize); err != nil {
+		compacted.Close()
+		os.Remove(compactPath)
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
+	if err := compacted.Close(); err != nil {
+		os.Remove(compactPath)
+		return err
+	}
+
+	if err := s.replaceFile(compactPath); err != nil {
+		return fmt.Errorf("failed to replace database with compacted copy: %w", err)
+	}
+
+	if after, err := os.Stat(path); err == nil && before != nil {
+		s.logger.Info("Compaction completed",
+			zap.Int64("size_before", before.Size()),
+			zap.Int64("size_after", after.Size()))
+	}
+	return nil
+}
+
+// replaceFile swaps the database file for replacement and reopens it,
+// keeping the old database if the swap fails. Callers hold s.mu.
+func (s *BoltStorage) replaceFile(replacement string) error {
+	path := s.db.Path()
+	if err := s.db.Close(); err != nil {
+		os.Remove(replacement)
+		return err
+	}
+	renameErr := os.Rename(replacement, path)
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		s.closed = true
+		return fmt.Errorf("failed to reopen database: %w", err)
+	}
+	s.db = db
+	if renameErr != nil {
+		os.Remove(replacement)
+		return renameErr
+	}
+	return nil
+}
+
+// Backup writes a consistent snapshot of the database to w. Executions are
+// still recorded while it runs.
+func (s *BoltStorage) Backup(ctx context.Context, w io.Writer) (int64, error) {
+	var written int64
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		written, err = tx.WriteTo(w)
+		return err
+	})
+	return written, err
+}
+
+// Restore replaces the database with a snapshot written by Backup. The
+// snapshot is written next to the database and checked before it replaces
+// anything. Other operations wait until the restored database is open.
+func (s *BoltStorage) Restore(ctx context.Context, r io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return bolt.ErrDatabaseNotOpen
+	}
+
+	restorePath := s.db.Path() + ".restore"
+	if err := writeFile(restorePath, r); err != nil {
+		os.Remove(restorePath)
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := checkSnapshot(restorePath); err != nil {
+		os.Remove(restorePath)
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if err := s.replaceFile(restorePath); err != nil {
+		return fmt.Errorf("failed to replace database with snapshot: %w", err)
+	}
+
+	// Snapshots of older databases lack newer buckets and the tool index
+	if err := s.initBuckets(); err != nil {
+		return err
+	}
+	if err := s.indexExecutionsByTool(); err != nil {
+		return err
+	}
+	s.logger.Info("Restored database from snapshot", zap.String("path", s.db.Path()))
+	return nil
+}
+
+// writeFile writes r to a new file at path and syncs it to disk
+func writeFile(path string, r io.Reader) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// checkSnapshot verifies a file is a consistent BoltDB database holding
+// learning data
+func checkSnapshot(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		var checkErr error
+		for err := range tx.Check() {
+			if checkErr == nil {
+				checkErr = err
+			}
+		}
+		if checkErr != nil {
+			return checkErr
+		}
+		if tx.Bucket([]byte(ExecutionsBucket)) == nil {
+			return fmt.Errorf("no %s bucket", ExecutionsBucket)
+		}
+		return nil
+	})
+}
+
+// compactTxMaxSize bounds the size of each transaction copying data into a
+// compacted database
+const compactTxMaxSize = 64 << 20
+
+// healthCheckKey is the key the writability check updates in the stats bucket
+const healthCheckKey = "health_check"
+
+// CheckWritable verifies the database accepts writes by recording the time
+// of the check
+func (s *BoltStorage) CheckWritable(ctx context.Context) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(StatsBucket))
+		if bucket == nil {
+			return fmt.Errorf("bucket %s not found", StatsBucket)
+		}
+		return bucket.Put([]byte(healthCheckKey), []byte(s.clock.Now().UTC().Format(time.RFC3339Nano)))
+	})
+}
+
 // Close closes the BoltDB connection
 func (s *BoltStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
 	return s.db.Close()
 }
 
@@ -8,8 +8,11 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/clock"
 	"go.uber.org/zap"
 )
 
@@ -19,6 +22,28 @@ type Collector struct {
 	storage     Storage
 	logger      *zap.Logger
 	piiPatterns []*regexp.Regexp // Pre-compiled PII patterns for performance
+	pressure    *pressureMonitor
+	clock       clock.Clock // Timestamps records
+	redactor    atomic.Pointer[redactor]
+
+	// Asynchronous executions wait in a bounded queue for a fixed pool of
+	// writers, so a slow store backs up the queue rather than piling up
+	// goroutines
+	queueMu   sync.RWMutex
+	queueOnce sync.Once
+	queue     chan pendingExecution
+	closed    bool
+	drained   chan struct{}
+}
+
+// pendingExecution is an execution waiting in the queue to become a record
+type pendingExecution struct {
+	execCtx   ExecutionContext
+	input     interface{}
+	output    interface{}
+	err       error
+	duration  time.Duration
+	timestamp time.Time
 }
 
 // NewCollector creates a new feedback collector
@@ -31,12 +56,17 @@ func NewCollector(config CollectionConfig, storage Storage, logger *zap.Logger)
 		regexp.MustCompile(`\b\d{3}-\d{3}-\d{4}\b`),                                   // phone
 	}
 	
-	return &Collector{
+	collector := &Collector{
 		config:      config,
 		storage:     storage,
 		logger:      logger,
 		piiPatterns: piiPatterns,
+		pressure:    newPressureMonitor(DefaultSheddingConfig()),
+		clock:       clock.Real{},
+		drained:     make(chan struct{}),
 	}
+	collector.redactor.Store(&redactor{})
+	return collector
 }
 
 // ExecutionContext holds context information for a tool execution
@@ -55,8 +85,10 @@ func (c *Collector) CollectExecution(ctx context.Context, execCtx ExecutionConte
 		return nil
 	}
 
-	// Apply sampling rate
-	if !c.shouldSample() {
+	// Apply sampling rate, reduced while storage is under pressure. Failures
+	// are rare and worth more to analysis, so they can bypass sampling.
+	if !(err != nil && c.config.AlwaysSampleErrors) && !c.shouldSample(c.sampleRate()) {
+		c.pressure.sampledOut.Add(1)
 		return nil
 	}
 
@@ -65,32 +97,146 @@ func (c *Collector) CollectExecution(ctx context.Context, execCtx ExecutionConte
 		return nil
 	}
 
-	record := c.createExecutionRecord(execCtx, input, output, err, duration)
-
 	if c.config.AsyncProcessing {
-		// Process asynchronously to avoid blocking tool execution
+		// Sanitizing and storing happen on the writers, so the caller never waits
+		c.enqueue(pendingExecution{
+			execCtx:   execCtx,
+			input:     input,
+			output:    output,
+			err:       err,
+			duration:  duration,
+			timestamp: c.clock.Now().UTC(),
+		})
+		return nil
+	}
+
+	// Synchronous processing
+	return c.store(ctx, c.createExecutionRecord(execCtx, input, output, err, duration, c.clock.Now().UTC()))
+}
+
+// store writes a record, tracking the write latency
+func (c *Collector) store(ctx context.Context, record ExecutionRecord) error {
+	start := time.Now()
+	err := c.storage.StoreExecution(ctx, record)
+	c.pressure.observeStore(time.Since(start))
+	return err
+}
+
+// storeAsync writes a record outside the invocation, logging failures
+func (c *Collector) storeAsync(record ExecutionRecord) {
+	if err := c.store(context.Background(), record); err != nil {
+		c.logger.Error("Failed to store execution record",
+			zap.String("record_id", record.ID),
+			zap.Error(err))
+	}
+}
+
+// enqueue hands an execution to the writers without blocking, dropping it
+// when the queue is full
+func (c *Collector) enqueue(execution pendingExecution) {
+	c.queueOnce.Do(c.startQueue)
+
+	c.queueMu.RLock()
+	defer c.queueMu.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.queue <- execution:
+	default:
+		if dropped := c.pressure.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+			c.logger.Warn("Learning queue full, dropping execution records",
+				zap.Int("queue_size", cap(c.queue)),
+				zap.Int64("dropped_records", dropped))
+		}
+	}
+}
+
+// startQueue creates the record queue and starts its writers
+func (c *Collector) startQueue() {
+	config := c.pressure.getConfig()
+	size, workers := config.QueueSize, config.Workers
+	if size <= 0 {
+		size = DefaultSheddingConfig().QueueSize
+	}
+	if workers <= 0 {
+		workers = DefaultSheddingConfig().Workers
+	}
+	c.queueMu.Lock()
+	c.queue = make(chan pendingExecution, size)
+	c.queueMu.Unlock()
+
+	var writers sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		writers.Add(1)
 		go func() {
-			if storeErr := c.storage.StoreExecution(context.Background(), record); storeErr != nil {
-				c.logger.Error("Failed to store execution record",
-					zap.String("record_id", record.ID),
-					zap.Error(storeErr))
+			defer writers.Done()
+			for execution := range c.queue {
+				c.storeAsync(c.createExecutionRecord(execution.execCtx, execution.input, execution.output, execution.err, execution.duration, execution.timestamp))
 			}
 		}()
-		return nil
 	}
+	go func() {
+		writers.Wait()
+		close(c.drained)
+	}()
+}
 
-	// Synchronous processing
-	return c.storage.StoreExecution(ctx, record)
+// sampleRate returns the configured sample rate reduced by the current pressure
+func (c *Collector) sampleRate() float64 {
+	depth, capacity := c.queueLevel()
+	return c.pressure.sampleRate(c.config.SampleRate, c.pressure.pressure(depth, capacity))
+}
+
+// queueLevel returns the depth and capacity of the record queue
+func (c *Collector) queueLevel() (int, int) {
+	c.queueMu.RLock()
+	defer c.queueMu.RUnlock()
+	if c.queue == nil {
+		return 0, c.pressure.getConfig().QueueSize
+	}
+	return len(c.queue), cap(c.queue)
+}
+
+// SetShedding configures load shedding. The queue size and writers only
+// take effect before the first asynchronous record.
+func (c *Collector) SetShedding(config SheddingConfig) {
+	c.pressure.setConfig(config)
+}
+
+// PressureStatus reports the pressure signals and the resulting shedding
+func (c *Collector) PressureStatus() PressureStatus {
+	depth, capacity := c.queueLevel()
+	return c.pressure.status(depth, capacity, c.config.SampleRate)
+}
+
+// Close stops accepting records and waits for queued ones to be stored
+func (c *Collector) Close() {
+	c.queueMu.Lock()
+	if c.closed {
+		c.queueMu.Unlock()
+		return
+	}
+	c.closed = true
+	started := c.queue != nil
+	if started {
+		close(c.queue)
+	}
+	c.queueMu.Unlock()
+
+	if started {
+		<-c.drained
+	}
 }
 
 // createExecutionRecord creates an execution record from the provided data
-func (c *Collector) createExecutionRecord(execCtx ExecutionContext, input interface{}, output interface{}, err error, duration time.Duration) ExecutionRecord {
+func (c *Collector) createExecutionRecord(execCtx ExecutionContext, input interface{}, output interface{}, err error, duration time.Duration, timestamp time.Time) ExecutionRecord {
 	recordID := c.generateID()
 	
 	record := ExecutionRecord{
 		ID:         recordID,
 		ToolName:   execCtx.ToolName,
-		Timestamp:  time.Now().UTC(),
+		Timestamp:  timestamp,
 		Duration:   duration,
 		Success:    err == nil,
 		SourceType: execCtx.SourceType,
@@ -113,11 +259,11 @@ func (c *Collector) createExecutionRecord(execCtx ExecutionContext, input interf
 		}
 	}
 
-	// Process input/output if enabled
-	if c.config.IncludeInputOutput {
-		record.Input = c.sanitizeData(input, c.config.MaxInputSize)
+	// Process input/output if enabled, redacted before anything is kept
+	if redactor := c.redactor.Load(); c.config.IncludeInputOutput && redactor.recordsPayloads(execCtx.ToolName) {
+		record.Input = c.sanitizeData(redactor.redact(input), c.config.MaxInputSize)
 		if output != nil {
-			record.Output = c.sanitizeData(output, c.config.MaxOutputSize)
+			record.Output = c.sanitizeData(redactor.redact(output), c.config.MaxOutputSize)
 		}
 	}
 
@@ -131,11 +277,11 @@ func (c *Collector) createExecutionRecord(execCtx ExecutionContext, input interf
 }
 
 // shouldSample determines if this execution should be sampled based on the sample rate
-func (c *Collector) shouldSample() bool {
-	if c.config.SampleRate >= 1.0 {
+func (c *Collector) shouldSample(sampleRate float64) bool {
+	if sampleRate >= 1.0 {
 		return true
 	}
-	if c.config.SampleRate <= 0.0 {
+	if sampleRate <= 0.0 {
 		return false
 	}
 
@@ -148,7 +294,7 @@ func (c *Collector) shouldSample() bool {
 	// Convert 4 bytes to uint32 and normalize to [0, 1)
 	randomUint := uint32(randomBytes[0]) | uint32(randomBytes[1])<<8 | uint32(randomBytes[2])<<16 | uint32(randomBytes[3])<<24
 	randomValue := float64(randomUint) / float64(1<<32)
-	return randomValue < c.config.SampleRate
+	return randomValue < sampleRate
 }
 
 // classifyError attempts to classify the error into predefined types
@@ -285,7 +431,8 @@ func (c *Collector) UpdateConfig(config CollectionConfig) {
 	c.config = config
 	c.logger.Info("Collector configuration updated",
 		zap.Bool("enabled", config.Enabled),
-		zap.Float64("sample_rate", config.SampleRate))
+		zap.Float64("sample_rate", config.SampleRate),
+		zap.Bool("always_sample_errors", config.AlwaysSampleErrors))
 }
 
 // GetConfig returns the current collector configuration
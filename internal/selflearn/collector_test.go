@@ -0,0 +1,48 @@
+package selflearn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSampling(t *testing.T) {
+	ctx := context.Background()
+	newEngine := func(alwaysSampleErrors bool) *Engine {
+		config := DefaultCollectionConfig()
+		config.AsyncProcessing = false
+		config.SampleRate = 0
+		config.AlwaysSampleErrors = alwaysSampleErrors
+		engine := NewEngine(config, learning.NewMemoryStorage(), zap.NewNop())
+		t.Cleanup(func() { engine.Close() })
+		return engine
+	}
+
+	// A zero sample rate is honoured, but failures are still recorded
+	engine := newEngine(true)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	}
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, errors.New("connection refused"), time.Millisecond))
+	stats, err := engine.GetStats(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.TotalExecutions)
+	assert.Zero(t, stats.SuccessRate)
+	assert.Equal(t, int64(5), engine.PressureStatus().SampledOut)
+	assert.Zero(t, engine.GetConfig().SampleRate)
+	assert.True(t, engine.GetConfig().AlwaysSampleErrors)
+
+	// Without AlwaysSampleErrors, failures are sampled like the rest
+	engine = newEngine(false)
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, errors.New("connection refused"), time.Millisecond))
+	stats, err = engine.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalExecutions)
+	assert.Equal(t, int64(1), engine.PressureStatus().SampledOut)
+}
@@ -0,0 +1,26 @@
+package selflearn
+
+import "github.com/aionmcp/aionmcp/pkg/learning"
+
+// Names of the storage drivers this package registers
+const (
+	StorageBolt   = "boltdb"
+	StorageSQLite = "sqlite"
+)
+
+func init() {
+	learning.RegisterStorage(StorageBolt, learning.StorageDriverFunc(func(config learning.StorageConfig) (Storage, error) {
+		path := config.Path
+		if path == "" {
+			path = "./data/aionmcp.db"
+		}
+		return NewBoltStorage(path, config.Logger)
+	}))
+	learning.RegisterStorage(StorageSQLite, learning.StorageDriverFunc(func(config learning.StorageConfig) (Storage, error) {
+		path := config.Path
+		if path == "" {
+			path = "./data/aionmcp.sqlite"
+		}
+		return NewSQLiteStorage(path, config.Logger)
+	}))
+}
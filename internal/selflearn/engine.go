@@ -2,8 +2,11 @@ package selflearn
 
 import (
 	"context"
+	"io"
+	"sync"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/clock"
 	"go.uber.org/zap"
 )
 
@@ -14,17 +17,65 @@ const (
 	contextKeySessionID  contextKey = "session_id"
 	contextKeyRequestID  contextKey = "request_id"
 	contextKeyUserAgent  contextKey = "user_agent"
+	contextKeyAgentID    contextKey = "agent_id"
+	contextKeyAgentName  contextKey = "agent_name"
+	contextKeyAttempt    contextKey = "attempt"
+	contextKeyWorkspace  contextKey = "workspace"
+	contextKeyTenant     contextKey = "tenant"
 )
 
+// WithSessionID attaches the invoking session to a context passed to RecordExecution
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, contextKeySessionID, sessionID)
+}
+
+// WithRequestID attaches a request or invocation ID to a context passed to RecordExecution
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKeyRequestID, requestID)
+}
+
+// WithUserAgent attaches the caller's user agent to a context passed to RecordExecution
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, contextKeyUserAgent, userAgent)
+}
+
+// WithAgent attaches the invoking agent to a context passed to RecordExecution
+func WithAgent(ctx context.Context, agentID, agentName string) context.Context {
+	ctx = context.WithValue(ctx, contextKeyAgentID, agentID)
+	return context.WithValue(ctx, contextKeyAgentName, agentName)
+}
+
+// WithAttempt marks an execution as the given 1-based attempt of a retried invocation
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, contextKeyAttempt, attempt)
+}
+
+// WithWorkspace attaches the workspace of the caller to a context passed to RecordExecution
+func WithWorkspace(ctx context.Context, workspace string) context.Context {
+	return context.WithValue(ctx, contextKeyWorkspace, workspace)
+}
+
+// InsightHandler is notified with the insights produced by each generation run
+type InsightHandler func(insights []Insight)
+
 // Engine is the main self-learning engine that coordinates feedback collection,
 // analysis, and insight generation
 type Engine struct {
-	collector *Collector
-	storage   Storage
-	analyzer  *Analyzer
-	reflector *Reflector
-	config    CollectionConfig
-	logger    *zap.Logger
+	collector       *Collector
+	storage         Storage
+	analyzer        *Analyzer
+	reflector       *Reflector
+	config          CollectionConfig
+	logger          *zap.Logger
+	handlersMu      sync.RWMutex
+	insightHandlers []InsightHandler
+	clock           clock.Clock
+	activity        ToolActivity  // Nil lists insights in storage order
+	activityWindow  time.Duration // How far back an invocation counts as current usage
+	tenants         TenantResolver // Nil leaves every tool shared
+
+	recommendationWindow time.Duration // How far back executions count towards recommendations
+	retention            retention
 }
 
 // NewEngine creates a new self-learning engine
@@ -40,6 +91,8 @@ func NewEngine(config CollectionConfig, storage Storage, logger *zap.Logger) *En
 		reflector: reflector,
 		config:    config,
 		logger:    logger,
+		clock:     clock.Real{},
+		retention: retention{config: DefaultRetentionConfig()},
 	}
 }
 
@@ -68,6 +121,28 @@ func (e *Engine) RecordExecution(ctx context.Context, toolName, sourceType strin
 		}
 	}
 
+	if agentID, ok := ctx.Value(contextKeyAgentID).(string); ok && agentID != "" {
+		execCtx.Metadata["agent_id"] = agentID
+	}
+	if agentName, ok := ctx.Value(contextKeyAgentName).(string); ok && agentName != "" {
+		execCtx.Metadata["agent_name"] = agentName
+	}
+	if attempt, ok := ctx.Value(contextKeyAttempt).(int); ok && attempt > 0 {
+		execCtx.Metadata["attempt"] = attempt
+	}
+	if workspace, ok := ctx.Value(contextKeyWorkspace).(string); ok && workspace != "" {
+		execCtx.Metadata[MetadataWorkspace] = workspace
+	}
+	// Tools owned by a tenant attribute their executions to it; executions
+	// of shared tools belong to the tenant of the session, if any
+	tenant := e.toolTenant(toolName)
+	if sessionTenant, ok := ctx.Value(contextKeyTenant).(string); ok && tenant == "" {
+		tenant = sessionTenant
+	}
+	if tenant != "" {
+		execCtx.Metadata[MetadataTenant] = tenant
+	}
+
 	return e.collector.CollectExecution(ctx, execCtx, input, output, err, duration)
 }
 
@@ -78,7 +153,51 @@ func (e *Engine) AnalyzePatterns(ctx context.Context) ([]Pattern, error) {
 
 // GenerateInsights triggers insight generation based on current patterns and data
 func (e *Engine) GenerateInsights(ctx context.Context) ([]Insight, error) {
-	return e.reflector.GenerateInsights(ctx)
+	insights, err := e.reflector.GenerateInsights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.notifyInsights(insights)
+	return insights, nil
+}
+
+// RecordInsight stores an insight raised outside the regular generation run
+// and notifies the insight handlers. Missing IDs and timestamps are filled in.
+func (e *Engine) RecordInsight(ctx context.Context, insight Insight) error {
+	if insight.ID == "" {
+		insight.ID = e.reflector.generateInsightID()
+	}
+	if insight.CreatedAt.IsZero() {
+		insight.CreatedAt = e.clock.Now()
+	}
+	if err := e.storage.StoreInsight(ctx, insight); err != nil {
+		return err
+	}
+	e.notifyInsights([]Insight{insight})
+	return nil
+}
+
+// OnInsights registers a handler invoked whenever insight generation produces insights
+func (e *Engine) OnInsights(handler InsightHandler) {
+	e.handlersMu.Lock()
+	defer e.handlersMu.Unlock()
+	e.insightHandlers = append(e.insightHandlers, handler)
+}
+
+// notifyInsights passes newly generated insights to the registered handlers
+func (e *Engine) notifyInsights(insights []Insight) {
+	if len(insights) == 0 {
+		return
+	}
+
+	e.handlersMu.RLock()
+	handlers := make([]InsightHandler, len(e.insightHandlers))
+	copy(handlers, e.insightHandlers)
+	e.handlersMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(insights)
+	}
 }
 
 // GetStats returns overall learning statistics
@@ -100,7 +219,7 @@ func (e *Engine) GetStats(ctx context.Context) (LearningStats, error) {
 	if err != nil {
 		e.logger.Warn("Failed to get active insights", zap.Error(err))
 	} else {
-		stats.ActiveInsights = insights
+		stats.ActiveInsights = e.prioritize(insights)
 	}
 
 	return stats, nil
@@ -121,7 +240,7 @@ func (e *Engine) GetToolInsights(ctx context.Context, toolName string) ([]Insigh
 		}
 	}
 
-	return toolInsights, nil
+	return e.prioritize(toolInsights), nil
 }
 
 // GetErrorPatterns returns error patterns, optionally filtered by tool
@@ -150,11 +269,18 @@ func (e *Engine) GetErrorPatterns(ctx context.Context, toolName string) ([]Patte
 func (e *Engine) RunMaintenance(ctx context.Context) error {
 	e.logger.Info("Starting self-learning maintenance")
 
-	// Cleanup old data
-	if err := e.storage.Cleanup(ctx, e.config.RetentionPeriod); err != nil {
+	// Cleanup old data, keeping hourly aggregates when configured
+	if _, err := e.RunRetention(ctx); err != nil {
 		e.logger.Error("Failed to cleanup old data", zap.Error(err))
 	}
 
+	// Analysis scans the stored executions, so it waits while storage is
+	// already struggling to keep up with new records
+	if e.DeferAnalysis() {
+		e.logger.Info("Self-learning maintenance deferred analysis under pressure")
+		return nil
+	}
+
 	// Run pattern analysis
 	patterns, err := e.analyzer.AnalyzePatterns(ctx)
 	if err != nil {
@@ -164,7 +290,7 @@ func (e *Engine) RunMaintenance(ctx context.Context) error {
 	}
 
 	// Generate insights
-	insights, err := e.reflector.GenerateInsights(ctx)
+	insights, err := e.GenerateInsights(ctx)
 	if err != nil {
 		e.logger.Error("Failed to generate insights", zap.Error(err))
 	} else {
@@ -187,14 +313,107 @@ func (e *Engine) GetConfig() CollectionConfig {
 	return e.config
 }
 
+// SetShedding configures how learning backs off under storage pressure. It
+// must be called before executions are recorded.
+func (e *Engine) SetShedding(config SheddingConfig) {
+	e.collector.SetShedding(config)
+}
+
+// PressureStatus reports the pressure signals and what learning currently sheds
+func (e *Engine) PressureStatus() PressureStatus {
+	return e.collector.PressureStatus()
+}
+
+// DeferAnalysis reports whether pattern analysis should wait for pressure to
+// ease, counting the deferral when it should
+func (e *Engine) DeferAnalysis() bool {
+	status := e.collector.PressureStatus()
+	if !status.AnalysisDeferred {
+		return false
+	}
+	e.collector.pressure.deferred.Add(1)
+	e.logger.Warn("Deferring pattern analysis under learning pressure",
+		zap.Float64("pressure", status.Pressure),
+		zap.Int("queue_depth", status.QueueDepth),
+		zap.Float64("store_latency_ms", status.StoreLatencyMs))
+	return true
+}
+
+// SetClock replaces the clock that timestamps records and insights, ends
+// analysis windows and judges retention, including the storage's when it
+// supports a clock. Call it before recording executions.
+func (e *Engine) SetClock(c clock.Clock) {
+	e.clock = c
+	e.collector.clock = c
+	e.analyzer.clock = c
+	e.reflector.clock = c
+	if storage, ok := e.storage.(interface{ SetClock(clock.Clock) }); ok {
+		storage.SetClock(c)
+	}
+}
+
+// SetAggregation configures how patterns and insights are shared across
+// workspaces. It must be called before analysis starts.
+func (e *Engine) SetAggregation(config AggregationConfig) {
+	e.analyzer.aggregation = config
+	e.reflector.aggregation = config
+}
+
+// GetAggregation returns the cross-workspace aggregation configuration
+func (e *Engine) GetAggregation() AggregationConfig {
+	return e.analyzer.aggregation
+}
+
+// VisibleInsights drops the insights of other workspaces in aggregation-only mode
+func (e *Engine) VisibleInsights(insights []Insight, workspace string) []Insight {
+	if !e.analyzer.aggregation.Enabled {
+		return insights
+	}
+	if workspace == "" {
+		workspace = DefaultWorkspace
+	}
+	visible := make([]Insight, 0, len(insights))
+	for _, insight := range insights {
+		if visibleTo(insight.Metadata, workspace) {
+			visible = append(visible, insight)
+		}
+	}
+	return visible
+}
+
+// VisiblePatterns drops the patterns of other workspaces in aggregation-only mode
+func (e *Engine) VisiblePatterns(patterns []Pattern, workspace string) []Pattern {
+	if !e.analyzer.aggregation.Enabled {
+		return patterns
+	}
+	if workspace == "" {
+		workspace = DefaultWorkspace
+	}
+	visible := make([]Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if visibleTo(pattern.Metadata, workspace) {
+			visible = append(visible, pattern)
+		}
+	}
+	return visible
+}
+
 // GetInsights returns insights by type with optional filtering
 func (e *Engine) GetInsights(ctx context.Context, insightType InsightType, limit int) ([]Insight, error) {
-	return e.storage.GetInsights(ctx, insightType, limit)
+	insights, err := e.storage.GetInsights(ctx, insightType, limit)
+	if err != nil {
+		return nil, err
+	}
+	return e.prioritize(insights), nil
 }
 
 // GetInsightsByPriority returns insights filtered by priority
 func (e *Engine) GetInsightsByPriority(ctx context.Context, priority Priority, limit int) ([]Insight, error) {
-	return e.storage.GetInsightsByPriority(ctx, priority, limit)
+	insights, err := e.storage.GetInsightsByPriority(ctx, priority, limit)
+	if err != nil {
+		return nil, err
+	}
+	return e.prioritize(insights), nil
 }
 
 // GetPatterns returns patterns by type
@@ -202,8 +421,24 @@ func (e *Engine) GetPatterns(ctx context.Context, patternType PatternType, limit
 	return e.storage.GetPatterns(ctx, patternType, limit)
 }
 
+// ExportExecutions streams execution records in a time range to w in the
+// requested format and returns the number of rows written
+func (e *Engine) ExportExecutions(ctx context.Context, w io.Writer, options ExportOptions) (int, error) {
+	return exportExecutions(ctx, e.storage, w, options, e.recordTenant)
+}
+
+// CheckStorage verifies the learning storage accepts writes. Backends that
+// cannot check, such as in-memory storage, always pass.
+func (e *Engine) CheckStorage(ctx context.Context) error {
+	if checker, ok := e.storage.(WritableChecker); ok {
+		return checker.CheckWritable(ctx)
+	}
+	return nil
+}
+
 // Close shuts down the learning engine
 func (e *Engine) Close() error {
 	e.logger.Info("Shutting down self-learning engine")
+	e.collector.Close()
 	return e.storage.Close()
 }
\ No newline at end of file
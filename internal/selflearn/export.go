@@ -0,0 +1,290 @@
+package selflearn
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ExportFormat is the file format produced by an execution export
+type ExportFormat string
+
+const (
+	ExportFormatCSV     ExportFormat = "csv"
+	ExportFormatParquet ExportFormat = "parquet"
+)
+
+const (
+	// exportBatchSize is the number of rows buffered before flushing to the writer
+	exportBatchSize = 1000
+)
+
+// ExportColumns lists every column available to an execution export, in default order
+var ExportColumns = []string{
+	"id",
+	"timestamp",
+	"session_id",
+	"agent_id",
+	"agent_name",
+	"request_id",
+	"tool",
+	"source_type",
+	"latency_ms",
+	"outcome",
+	"error_type",
+}
+
+// ExportOptions selects the rows and columns of an execution export
+type ExportOptions struct {
+	Start     time.Time
+	End       time.Time
+	Format    ExportFormat
+	Columns   []string    // Empty selects all ExportColumns
+	SessionID string      // Optional filter on the invoking session
+	ToolName  string      // Optional filter on the invoked tool
+	Tenants   TenantScope // Tenants whose executions are exported
+}
+
+// Validate checks the options and fills in default columns
+func (o *ExportOptions) Validate() error {
+	if o.Format != ExportFormatCSV && o.Format != ExportFormatParquet {
+		return fmt.Errorf("unsupported export format: %s", o.Format)
+	}
+	if o.End.Before(o.Start) {
+		return fmt.Errorf("end time is before start time")
+	}
+
+	if len(o.Columns) == 0 {
+		o.Columns = ExportColumns
+		return nil
+	}
+	seen := make(map[string]bool, len(o.Columns))
+	for _, column := range o.Columns {
+		if !isExportColumn(column) {
+			return fmt.Errorf("unknown export column: %s", column)
+		}
+		if seen[column] {
+			return fmt.Errorf("duplicate export column: %s", column)
+		}
+		seen[column] = true
+	}
+	return nil
+}
+
+// exportExecutions streams matching execution records from storage to w,
+// telling the tenant of each record with tenantOf
+func exportExecutions(ctx context.Context, storage Storage, w io.Writer, options ExportOptions, tenantOf func(ExecutionRecord) string) (int, error) {
+	if err := options.Validate(); err != nil {
+		return 0, err
+	}
+
+	var rows rowWriter
+	switch options.Format {
+	case ExportFormatCSV:
+		rows = newCSVRowWriter(w, options.Columns)
+	case ExportFormatParquet:
+		rows = newParquetRowWriter(w, options.Columns)
+	}
+
+	count := 0
+	err := storage.IterateExecutions(ctx, options.Start, options.End, func(record ExecutionRecord) error {
+		if options.SessionID != "" && contextString(record, "session_id") != options.SessionID {
+			return nil
+		}
+		if options.ToolName != "" && record.ToolName != options.ToolName {
+			return nil
+		}
+		if options.Tenants.Restricted && !options.Tenants.Allows(tenantOf(record)) {
+			return nil
+		}
+		if err := rows.Write(record); err != nil {
+			return err
+		}
+		count++
+		if count%exportBatchSize == 0 {
+			return rows.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to export executions: %w", err)
+	}
+
+	return count, rows.Close()
+}
+
+// rowWriter encodes execution records in one export format
+type rowWriter interface {
+	Write(record ExecutionRecord) error
+	Flush() error
+	Close() error
+}
+
+// csvRowWriter writes a header row followed by one row per record
+type csvRowWriter struct {
+	writer        *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+func newCSVRowWriter(w io.Writer, columns []string) *csvRowWriter {
+	return &csvRowWriter{
+		writer:  csv.NewWriter(w),
+		columns: columns,
+	}
+}
+
+func (c *csvRowWriter) Write(record ExecutionRecord) error {
+	if !c.headerWritten {
+		if err := c.writer.Write(c.columns); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	row := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		switch value := exportValue(record, column).(type) {
+		case time.Time:
+			row[i] = value.Format(time.RFC3339Nano)
+		case int64:
+			row[i] = strconv.FormatInt(value, 10)
+		case string:
+			row[i] = value
+		}
+	}
+	return c.writer.Write(row)
+}
+
+func (c *csvRowWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvRowWriter) Close() error {
+	// An empty export still carries its header
+	if !c.headerWritten {
+		if err := c.writer.Write(c.columns); err != nil {
+			return err
+		}
+	}
+	return c.Flush()
+}
+
+// parquetRowWriter writes records as Parquet rows, one row group per flush
+type parquetRowWriter struct {
+	writer  *parquet.Writer
+	columns []string // In schema (leaf) order
+	buffer  []parquet.Row
+}
+
+func newParquetRowWriter(w io.Writer, selected []string) *parquetRowWriter {
+	group := parquet.Group{}
+	for _, column := range selected {
+		switch column {
+		case "timestamp":
+			group[column] = parquet.Timestamp(parquet.Millisecond)
+		case "latency_ms":
+			group[column] = parquet.Int(64)
+		default:
+			group[column] = parquet.Optional(parquet.String())
+		}
+	}
+	schema := parquet.NewSchema("execution", group)
+
+	columns := make([]string, 0, len(selected))
+	for _, field := range schema.Fields() {
+		columns = append(columns, field.Name())
+	}
+
+	return &parquetRowWriter{
+		writer:  parquet.NewWriter(w, schema),
+		columns: columns,
+		buffer:  make([]parquet.Row, 0, exportBatchSize),
+	}
+}
+
+func (p *parquetRowWriter) Write(record ExecutionRecord) error {
+	row := make(parquet.Row, len(p.columns))
+	for i, column := range p.columns {
+		switch value := exportValue(record, column).(type) {
+		case time.Time:
+			row[i] = parquet.Int64Value(value.UnixMilli()).Level(0, 0, i)
+		case int64:
+			row[i] = parquet.Int64Value(value).Level(0, 0, i)
+		case string:
+			if value == "" {
+				row[i] = parquet.NullValue().Level(0, 0, i)
+			} else {
+				row[i] = parquet.ByteArrayValue([]byte(value)).Level(0, 1, i)
+			}
+		}
+	}
+	p.buffer = append(p.buffer, row)
+	return nil
+}
+
+func (p *parquetRowWriter) Flush() error {
+	if len(p.buffer) == 0 {
+		return nil
+	}
+	if _, err := p.writer.WriteRows(p.buffer); err != nil {
+		return err
+	}
+	p.buffer = p.buffer[:0]
+	return p.writer.Flush()
+}
+
+func (p *parquetRowWriter) Close() error {
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	return p.writer.Close()
+}
+
+// exportValue returns the value of a column for a record
+func exportValue(record ExecutionRecord, column string) any {
+	switch column {
+	case "id":
+		return record.ID
+	case "timestamp":
+		return record.Timestamp
+	case "session_id", "agent_id", "agent_name", "request_id":
+		return contextString(record, column)
+	case "tool":
+		return record.ToolName
+	case "source_type":
+		return record.SourceType
+	case "latency_ms":
+		return record.Duration.Milliseconds()
+	case "outcome":
+		if record.Success {
+			return "success"
+		}
+		return "failure"
+	case "error_type":
+		return record.ErrorType
+	default:
+		return ""
+	}
+}
+
+// contextString returns a string value from the record context
+func contextString(record ExecutionRecord, key string) string {
+	value, _ := record.Context[key].(string)
+	return value
+}
+
+func isExportColumn(column string) bool {
+	for _, known := range ExportColumns {
+		if known == column {
+			return true
+		}
+	}
+	return false
+}
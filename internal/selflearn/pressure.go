@@ -0,0 +1,154 @@
+package selflearn
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SheddingConfig controls how learning backs off when the record queue or
+// storage writes fall behind, so tool invocations never wait on learning
+type SheddingConfig struct {
+	Enabled         bool          `json:"enabled"`
+	QueueSize       int           `json:"queue_size"`        // Records awaiting storage; further records are dropped
+	Workers         int           `json:"workers"`           // Writers storing queued records
+	LatencyTarget   time.Duration `json:"latency_target"`    // Storage write latency considered healthy
+	MinSampleRate   float64       `json:"min_sample_rate"`   // Floor of the reduced sample rate
+	DeferAnalysisAt float64       `json:"defer_analysis_at"` // Pressure from which pattern analysis is deferred
+}
+
+// DefaultSheddingConfig returns the default shedding configuration
+func DefaultSheddingConfig() SheddingConfig {
+	return SheddingConfig{
+		Enabled:         true,
+		QueueSize:       1024,
+		Workers:         4,
+		LatencyTarget:   50 * time.Millisecond,
+		MinSampleRate:   0.01,
+		DeferAnalysisAt: 0.5,
+	}
+}
+
+// PressureStatus reports the pressure signals and what learning sheds in response
+type PressureStatus struct {
+	Shedding         bool    `json:"shedding"`          // Learning is currently reduced
+	Pressure         float64 `json:"pressure"`          // 0 when healthy, 1 when saturated
+	QueueDepth       int     `json:"queue_depth"`       // Records awaiting storage
+	QueueCapacity    int     `json:"queue_capacity"`    // Queue size
+	StoreLatencyMs   float64 `json:"store_latency_ms"`  // Moving average of storage writes
+	SampleRate       float64 `json:"sample_rate"`       // Effective sample rate
+	AnalysisDeferred bool    `json:"analysis_deferred"` // Pattern analysis is postponed
+	DroppedRecords   int64   `json:"dropped_records"`   // Records dropped because the queue was full
+	SampledOut       int64   `json:"sampled_out"`       // Executions skipped by sampling
+	DeferredAnalyses int64   `json:"deferred_analyses"` // Analysis runs postponed so far
+
+	Config SheddingConfig `json:"config"`
+}
+
+// latencySmoothing weights the latest storage write in the latency average
+const latencySmoothing = 0.2
+
+// latencyPressureRange is how many latency targets above the target storage
+// writes must be for latency pressure to saturate
+const latencyPressureRange = 3.0
+
+// pressureMonitor tracks the queue and storage latency of a collector
+type pressureMonitor struct {
+	mu         sync.RWMutex
+	config     SheddingConfig
+	latency    float64 // Moving average of storage writes, in milliseconds
+	dropped    atomic.Int64
+	deferred   atomic.Int64
+	sampledOut atomic.Int64
+}
+
+func newPressureMonitor(config SheddingConfig) *pressureMonitor {
+	return &pressureMonitor{config: config}
+}
+
+// setConfig replaces the shedding configuration
+func (m *pressureMonitor) setConfig(config SheddingConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = config
+}
+
+// getConfig returns the shedding configuration
+func (m *pressureMonitor) getConfig() SheddingConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config
+}
+
+// observeStore records the duration of a storage write
+func (m *pressureMonitor) observeStore(duration time.Duration) {
+	ms := float64(duration) / float64(time.Millisecond)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.latency == 0 {
+		m.latency = ms
+		return
+	}
+	m.latency += latencySmoothing * (ms - m.latency)
+}
+
+// pressure combines the queue fill and storage latency into one signal; the
+// worse of the two decides
+func (m *pressureMonitor) pressure(depth, capacity int) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.config.Enabled {
+		return 0
+	}
+
+	queue := 0.0
+	if capacity > 0 {
+		queue = float64(depth) / float64(capacity)
+	}
+	latency := 0.0
+	if target := float64(m.config.LatencyTarget) / float64(time.Millisecond); target > 0 {
+		latency = (m.latency/target - 1) / latencyPressureRange
+	}
+	return math.Min(math.Max(math.Max(queue, latency), 0), 1)
+}
+
+// sampleRate weights the configured sample rate by the remaining health
+func (m *pressureMonitor) sampleRate(configured, pressure float64) float64 {
+	if pressure <= 0 {
+		return configured
+	}
+	m.mu.RLock()
+	floor := m.config.MinSampleRate
+	m.mu.RUnlock()
+	return math.Max(configured*(1-pressure), math.Min(floor, configured))
+}
+
+// deferAnalysis reports whether analysis should wait for pressure to ease
+func (m *pressureMonitor) deferAnalysis(pressure float64) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.Enabled && pressure > 0 && pressure >= m.config.DeferAnalysisAt
+}
+
+// status reports the current signals
+func (m *pressureMonitor) status(depth, capacity int, configuredRate float64) PressureStatus {
+	pressure := m.pressure(depth, capacity)
+	rate := m.sampleRate(configuredRate, pressure)
+	m.mu.RLock()
+	latency := m.latency
+	m.mu.RUnlock()
+	return PressureStatus{
+		Shedding:         rate < configuredRate,
+		Pressure:         pressure,
+		QueueDepth:       depth,
+		QueueCapacity:    capacity,
+		StoreLatencyMs:   latency,
+		SampleRate:       rate,
+		AnalysisDeferred: m.deferAnalysis(pressure),
+		DroppedRecords:   m.dropped.Load(),
+		SampledOut:       m.sampledOut.Load(),
+		DeferredAnalyses: m.deferred.Load(),
+		Config:           m.getConfig(),
+	}
+}
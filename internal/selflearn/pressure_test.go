@@ -0,0 +1,73 @@
+package selflearn
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// gatedStorage holds execution writes until the gate opens and slows them by delay
+type gatedStorage struct {
+	Storage
+	gate  chan struct{}
+	delay time.Duration
+}
+
+func (s *gatedStorage) StoreExecution(ctx context.Context, record ExecutionRecord) error {
+	if s.gate != nil {
+		<-s.gate
+	}
+	time.Sleep(s.delay)
+	return s.Storage.StoreExecution(ctx, record)
+}
+
+func TestLoadShedding(t *testing.T) {
+	ctx := context.Background()
+	newStorage := func() Storage {
+		storage, err := NewBoltStorage(filepath.Join(t.TempDir(), "learning.db"), zap.NewNop())
+		require.NoError(t, err)
+		return storage
+	}
+
+	// Slow storage writes reduce sampling and defer analysis
+	config := DefaultCollectionConfig()
+	config.AsyncProcessing = false
+	engine := NewEngine(config, &gatedStorage{Storage: newStorage(), delay: 20 * time.Millisecond}, zap.NewNop())
+	defer engine.Close()
+	engine.SetShedding(SheddingConfig{Enabled: true, QueueSize: 16, LatencyTarget: time.Millisecond, MinSampleRate: 0.05, DeferAnalysisAt: 0.5})
+
+	status := engine.PressureStatus()
+	assert.False(t, status.Shedding)
+	assert.Equal(t, 1.0, status.SampleRate)
+	require.NoError(t, engine.RecordExecution(ctx, "openapi.maps.geocode", "openapi", nil, nil, nil, time.Millisecond))
+
+	status = engine.PressureStatus()
+	assert.True(t, status.Shedding)
+	assert.Equal(t, 1.0, status.Pressure)
+	assert.Equal(t, 0.05, status.SampleRate)
+	assert.GreaterOrEqual(t, status.StoreLatencyMs, 20.0)
+	assert.True(t, status.AnalysisDeferred)
+	require.NoError(t, engine.RunMaintenance(ctx))
+	assert.Equal(t, int64(1), engine.PressureStatus().DeferredAnalyses)
+
+	// Records beyond a full queue are dropped instead of blocking the caller
+	config.AsyncProcessing = true
+	storage := &gatedStorage{Storage: newStorage(), gate: make(chan struct{})}
+	engine = NewEngine(config, storage, zap.NewNop())
+	engine.SetShedding(SheddingConfig{Enabled: true, QueueSize: 2, Workers: 1, LatencyTarget: time.Second, MinSampleRate: 1})
+	for i := 0; i < 10; i++ {
+		require.NoError(t, engine.RecordExecution(ctx, "openapi.maps.geocode", "openapi", nil, nil, nil, time.Millisecond))
+	}
+	status = engine.PressureStatus()
+	assert.GreaterOrEqual(t, status.DroppedRecords, int64(7))
+	assert.Equal(t, 2, status.QueueCapacity)
+
+	// Closing stores the queued records
+	close(storage.gate)
+	require.NoError(t, engine.Close())
+}
@@ -0,0 +1,50 @@
+package selflearn
+
+import (
+	"sort"
+	"time"
+)
+
+// ToolActivity reports, per tool name, how many active sessions invoked the
+// tool since the given time
+type ToolActivity func(since time.Time) map[string]int
+
+// DefaultActivityWindow is how far back an invocation counts as current usage
+const DefaultActivityWindow = 15 * time.Minute
+
+// SetToolActivity weights insight listings by current usage: an insight about
+// a tool that active sessions invoked within window inherits weight from each
+// of them, so it lists ahead of insights about idle tools. A nil activity
+// lists insights in storage order.
+func (e *Engine) SetToolActivity(activity ToolActivity, window time.Duration) {
+	if window <= 0 {
+		window = DefaultActivityWindow
+	}
+	e.activity = activity
+	e.activityWindow = window
+}
+
+// prioritize orders insights by their weighted priority, the priority weight
+// times one plus the number of active sessions using the insight's tool.
+// Ties keep their storage order.
+func (e *Engine) prioritize(insights []Insight) []Insight {
+	if e.activity == nil || len(insights) == 0 {
+		return insights
+	}
+	usage := e.activity(e.clock.Now().Add(-e.activityWindow))
+
+	weighted := make([]Insight, len(insights))
+	copy(weighted, insights)
+	for i := range weighted {
+		weighted[i].ActiveSessions = usage[weighted[i].Metadata["tool_name"]]
+	}
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return insightWeight(weighted[i]) > insightWeight(weighted[j])
+	})
+	return weighted
+}
+
+// insightWeight returns the priority weight of an insight scaled by usage
+func insightWeight(insight Insight) int {
+	return insight.Priority.Weight() * (1 + insight.ActiveSessions)
+}
@@ -0,0 +1,81 @@
+package selflearn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityRanking(t *testing.T) {
+	assert.True(t, PriorityCritical.AtLeast(PriorityHigh))
+	assert.True(t, PriorityHigh.AtLeast(PriorityHigh))
+	assert.False(t, PriorityMedium.AtLeast(PriorityHigh))
+
+	// Unknown priorities weigh as low
+	assert.Equal(t, PriorityLow.Weight(), Priority("urgent").Weight())
+	assert.True(t, Priority("urgent").AtLeast(PriorityLow))
+	assert.False(t, Priority("urgent").AtLeast(PriorityMedium))
+}
+
+func TestInsightUsagePriority(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	engine, storage, _ := newTestEngine(t, now)
+	ctx := context.Background()
+	for i, insight := range []Insight{
+		{ID: "idle-critical", Priority: PriorityCritical, Metadata: map[string]string{"tool_name": "idle-tool"}},
+		{ID: "busy-medium", Priority: PriorityMedium, Metadata: map[string]string{"tool_name": "busy-tool"}},
+		{ID: "idle-high", Priority: PriorityHigh, Metadata: map[string]string{"tool_name": "idle-tool"}},
+	} {
+		insight.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		require.NoError(t, storage.StoreInsight(ctx, insight))
+	}
+	ids := func(insights []Insight) []string {
+		var result []string
+		for _, insight := range insights {
+			result = append(result, insight.ID)
+		}
+		return result
+	}
+	list := func() []Insight {
+		insights, err := engine.GetInsights(ctx, "", 50)
+		require.NoError(t, err)
+		return insights
+	}
+
+	// Without usage weighting insights list in storage order
+	stored := ids(list())
+
+	var since time.Time
+	usage := map[string]int{}
+	engine.SetToolActivity(func(s time.Time) map[string]int {
+		since = s
+		return usage
+	}, 10*time.Minute)
+	assert.Equal(t, []string{"idle-critical", "idle-high", "busy-medium"}, ids(list()))
+	assert.Equal(t, now.Add(-10*time.Minute), since)
+
+	// Two sessions invoking a tool lift its medium insight above the critical one
+	usage["busy-tool"] = 2
+	insights := list()
+	assert.Equal(t, []string{"busy-medium", "idle-critical", "idle-high"}, ids(insights))
+	assert.Equal(t, 2, insights[0].ActiveSessions)
+	assert.Zero(t, insights[1].ActiveSessions)
+	toolInsights, err := engine.GetToolInsights(ctx, "busy-tool")
+	require.NoError(t, err)
+	require.Len(t, toolInsights, 1)
+	assert.Equal(t, 2, toolInsights[0].ActiveSessions)
+
+	// The window defaults when it is not positive
+	engine.SetToolActivity(func(s time.Time) map[string]int {
+		since = s
+		return usage
+	}, 0)
+	list()
+	assert.Equal(t, now.Add(-DefaultActivityWindow), since)
+
+	engine.SetToolActivity(nil, 0)
+	assert.Equal(t, stored, ids(list()))
+}
@@ -0,0 +1,133 @@
+package selflearn
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aionmcp/aionmcp/pkg/workflow"
+)
+
+// redactedMarker replaces redacted payload values
+const redactedMarker = "[REDACTED]"
+
+// RedactionConfig keeps secrets and personal data in tool inputs and outputs
+// out of execution records
+type RedactionConfig struct {
+	Fields       []string `json:"fields"`        // Object keys whose values are masked, as case-insensitive globs such as *token*
+	Paths        []string `json:"paths"`         // JSONPath expressions of masked values, such as $.user.ssn
+	SkipPayloads []string `json:"skip_payloads"` // Tools, as globs, whose inputs and outputs are never recorded
+}
+
+// redactor applies a RedactionConfig to payloads
+type redactor struct {
+	config RedactionConfig
+	fields []string
+	paths  []*workflow.Path
+}
+
+// newRedactor validates and compiles a redaction configuration
+func newRedactor(config RedactionConfig) (*redactor, error) {
+	r := &redactor{config: config}
+	for _, field := range config.Fields {
+		if _, err := path.Match(field, ""); err != nil || field == "" {
+			return nil, fmt.Errorf("invalid redacted field pattern %q", field)
+		}
+		r.fields = append(r.fields, strings.ToLower(field))
+	}
+	for _, expression := range config.Paths {
+		compiled, err := workflow.ParsePath(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redacted path: %w", err)
+		}
+		r.paths = append(r.paths, compiled)
+	}
+	for _, tool := range config.SkipPayloads {
+		if _, err := path.Match(tool, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q", tool)
+		}
+	}
+	return r, nil
+}
+
+// recordsPayloads reports whether the inputs and outputs of a tool may be recorded
+func (r *redactor) recordsPayloads(toolName string) bool {
+	for _, pattern := range r.config.SkipPayloads {
+		if matched, _ := path.Match(pattern, toolName); matched {
+			return false
+		}
+	}
+	return true
+}
+
+// redact returns a copy of a payload with the configured values masked. The
+// caller's payload is never modified. Payloads that cannot be copied are
+// not recorded.
+func (r *redactor) redact(payload interface{}) interface{} {
+	if payload == nil || (len(r.fields) == 0 && len(r.paths) == 0) {
+		return payload
+	}
+	// Round-trip through JSON so typed payloads are redacted like maps
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	var copied interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil
+	}
+
+	copied = r.redactFields(copied)
+	mask := func(interface{}) interface{} { return redactedMarker }
+	for _, compiled := range r.paths {
+		copied = compiled.Update(copied, mask)
+	}
+	return copied
+}
+
+// redactFields masks the values of matching object keys at any depth
+func (r *redactor) redactFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.sensitiveField(key) {
+				v[key] = redactedMarker
+			} else {
+				v[key] = r.redactFields(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactFields(item)
+		}
+	}
+	return value
+}
+
+// sensitiveField reports whether an object key matches a redacted field pattern
+func (r *redactor) sensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.fields {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRedaction configures what of tool inputs and outputs execution records
+// keep. It applies to executions recorded afterwards.
+func (e *Engine) SetRedaction(config RedactionConfig) error {
+	r, err := newRedactor(config)
+	if err != nil {
+		return err
+	}
+	e.collector.redactor.Store(r)
+	return nil
+}
+
+// Redaction returns the redaction configuration
+func (e *Engine) Redaction() RedactionConfig {
+	return e.collector.redactor.Load().config
+}
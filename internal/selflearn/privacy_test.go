@@ -0,0 +1,59 @@
+package selflearn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRedaction(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultCollectionConfig()
+	config.AsyncProcessing = false
+	config.PIIFilterEnabled = false
+	storage := learning.NewMemoryStorage()
+	engine := NewEngine(config, storage, zap.NewNop())
+	defer engine.Close()
+	require.NoError(t, engine.SetRedaction(RedactionConfig{
+		Fields:       []string{"*token*", "password"},
+		Paths:        []string{"$.user.ssn", "$.cards[*].number"},
+		SkipPayloads: []string{"openapi.vault.*"},
+	}))
+
+	input := map[string]any{
+		"query":        "weather",
+		"Access_Token": "t0ps3cret",
+		"user":         map[string]any{"name": "ada", "ssn": "123-45-6789", "password": "hunter2"},
+		"cards":        []any{map[string]any{"number": "4111", "brand": "visa"}},
+	}
+	require.NoError(t, engine.RecordExecution(ctx, "openapi.search.get", "openapi", input, map[string]any{"session_token": "abc", "hits": 3}, nil, time.Millisecond))
+	require.NoError(t, engine.RecordExecution(ctx, "openapi.vault.read", "openapi", input, "secret payload", nil, time.Millisecond))
+
+	records, err := storage.GetExecutionsByTool(ctx, "openapi.search.get", 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]any{
+		"query":        "weather",
+		"Access_Token": "[REDACTED]",
+		"user":         map[string]any{"name": "ada", "ssn": "[REDACTED]", "password": "[REDACTED]"},
+		"cards":        []any{map[string]any{"number": "[REDACTED]", "brand": "visa"}},
+	}, records[0].Input)
+	assert.Equal(t, map[string]any{"session_token": "[REDACTED]", "hits": 3.0}, records[0].Output)
+	assert.Equal(t, "t0ps3cret", input["Access_Token"], "the caller's payload is left alone")
+
+	// Tools that never record payloads still have their executions recorded
+	records, err = storage.GetExecutionsByTool(ctx, "openapi.vault.read", 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Nil(t, records[0].Input)
+	assert.Nil(t, records[0].Output)
+	assert.Equal(t, []string{"openapi.vault.*"}, engine.Redaction().SkipPayloads)
+
+	assert.Error(t, engine.SetRedaction(RedactionConfig{Paths: []string{"user.ssn"}}))
+	assert.Error(t, engine.SetRedaction(RedactionConfig{Fields: []string{"[token"}}))
+}
@@ -0,0 +1,67 @@
+package selflearn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/learning"
+)
+
+// ToolProfile summarizes how a tool behaved over a recent window, for tuning
+// its timeout and retries
+type ToolProfile struct {
+	ToolName          string        `json:"tool_name"`
+	Executions        int64         `json:"executions"`
+	Failures          int64         `json:"failures"`
+	TransientFailures int64         `json:"transient_failures"` // Network and performance errors, which a retry may clear
+	P99Latency        time.Duration `json:"p99_latency"`
+}
+
+// TransientErrorRate is the share of executions that failed transiently
+func (p ToolProfile) TransientErrorRate() float64 {
+	if p.Executions == 0 {
+		return 0
+	}
+	return float64(p.TransientFailures) / float64(p.Executions)
+}
+
+// transientError reports whether an execution failed in a way retrying may clear
+func transientError(errorType string) bool {
+	return errorType == string(ErrorTypeNetwork) || errorType == string(ErrorTypePerformance)
+}
+
+// GetToolProfiles profiles every tool executed since the given time, keyed by
+// tool name. Only raw executions count; hourly aggregates keep no latency
+// distribution.
+func (e *Engine) GetToolProfiles(ctx context.Context, since time.Time) (map[string]ToolProfile, error) {
+	profiles := make(map[string]*ToolProfile)
+	latencies := make(map[string]*learning.LatencyHistogram)
+	err := e.storage.IterateExecutions(ctx, since, e.clock.Now(), func(record ExecutionRecord) error {
+		profile := profiles[record.ToolName]
+		if profile == nil {
+			profile = &ToolProfile{ToolName: record.ToolName}
+			profiles[record.ToolName] = profile
+			latencies[record.ToolName] = learning.NewLatencyHistogram()
+		}
+		profile.Executions++
+		if !record.Success {
+			profile.Failures++
+			if transientError(record.ErrorType) {
+				profile.TransientFailures++
+			}
+		}
+		latencies[record.ToolName].Record(record.Duration)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read executions: %w", err)
+	}
+
+	result := make(map[string]ToolProfile, len(profiles))
+	for name, profile := range profiles {
+		profile.P99Latency = latencies[name].Percentile(0.99)
+		result[name] = *profile
+	}
+	return result, nil
+}
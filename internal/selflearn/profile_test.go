@@ -0,0 +1,47 @@
+package selflearn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolProfiles(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	engine, _, fake := newTestEngine(t, now)
+	ctx := context.Background()
+	record := func(tool string, err error, duration time.Duration) {
+		require.NoError(t, engine.RecordExecution(ctx, tool, "openapi", nil, nil, err, duration))
+	}
+
+	// Executions before the window are not profiled
+	record("openapi.flaky.get", errors.New("connection refused"), time.Second)
+	fake.Advance(2 * time.Hour)
+	for i := 1; i <= 8; i++ {
+		record("openapi.flaky.get", nil, time.Duration(i)*10*time.Millisecond)
+	}
+	record("openapi.flaky.get", errors.New("connection reset by peer"), 500*time.Millisecond)
+	record("openapi.flaky.get", errors.New("invalid parameter"), time.Millisecond)
+	record("openapi.quiet.get", nil, time.Millisecond)
+
+	profiles, err := engine.GetToolProfiles(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+
+	// Only network and performance errors count as transient
+	flaky := profiles["openapi.flaky.get"]
+	assert.EqualValues(t, 10, flaky.Executions)
+	assert.EqualValues(t, 2, flaky.Failures)
+	assert.EqualValues(t, 1, flaky.TransientFailures)
+	assert.InDelta(t, 0.1, flaky.TransientErrorRate(), 0.001)
+	assert.GreaterOrEqual(t, flaky.P99Latency, 500*time.Millisecond)
+
+	quiet := profiles["openapi.quiet.get"]
+	assert.EqualValues(t, 1, quiet.Executions)
+	assert.Zero(t, quiet.TransientErrorRate())
+	assert.Zero(t, ToolProfile{}.TransientErrorRate())
+}
@@ -0,0 +1,130 @@
+package selflearn
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// DefaultRecommendationWindow is how far back executions count towards
+// recommendations
+const DefaultRecommendationWindow = 7 * 24 * time.Hour
+
+// Reasons a tool is recommended
+const (
+	RecommendationFollows = "follows" // Sessions often invoked the tool right after the given one
+	RecommendationPopular = "popular" // The tool is among the most executed
+)
+
+// ToolRecommendation suggests a tool to invoke next
+type ToolRecommendation struct {
+	Tool   string  `json:"tool"`
+	Reason string  `json:"reason"`
+	Score  float64 `json:"score"` // Share of the transitions from the given tool, or of all executions
+	Count  int64   `json:"count"` // Times the tool followed the given one, or was executed
+}
+
+// sessionStep is one execution of a session, in the order they ran
+type sessionStep struct {
+	at   time.Time
+	tool string
+}
+
+// SetRecommendationWindow limits recommendations to the executions of the
+// last window. Zero selects DefaultRecommendationWindow.
+func (e *Engine) SetRecommendationWindow(window time.Duration) {
+	e.recommendationWindow = window
+}
+
+// RecommendTools suggests the tools to invoke after afterTool: first the
+// tools sessions most often invoked right after it within the
+// recommendation window, then the most executed tools. Retries do not count
+// as steps. A limit of zero or less returns every candidate.
+func (e *Engine) RecommendTools(ctx context.Context, afterTool string, limit int) ([]ToolRecommendation, error) {
+	window := e.recommendationWindow
+	if window <= 0 {
+		window = DefaultRecommendationWindow
+	}
+	end := e.clock.Now()
+
+	recommendations := make([]ToolRecommendation, 0)
+	seen := map[string]bool{afterTool: true}
+	if afterTool != "" {
+		sessions := make(map[string][]sessionStep)
+		err := e.storage.IterateExecutions(ctx, end.Add(-window), end, func(record ExecutionRecord) error {
+			sessionID, _ := record.Context["session_id"].(string)
+			if sessionID == "" || retried(record) {
+				return nil
+			}
+			sessions[sessionID] = append(sessions[sessionID], sessionStep{at: record.Timestamp, tool: record.ToolName})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		followers := make(map[string]int64)
+		var transitions int64
+		for _, steps := range sessions {
+			sort.SliceStable(steps, func(i, j int) bool { return steps[i].at.Before(steps[j].at) })
+			for i := 1; i < len(steps); i++ {
+				if steps[i-1].tool == afterTool && steps[i].tool != afterTool {
+					followers[steps[i].tool]++
+					transitions++
+				}
+			}
+		}
+		for tool, count := range followers {
+			recommendations = append(recommendations, ToolRecommendation{
+				Tool:   tool,
+				Reason: RecommendationFollows,
+				Score:  float64(count) / float64(transitions),
+				Count:  count,
+			})
+			seen[tool] = true
+		}
+		sortRecommendations(recommendations)
+	}
+
+	stats, err := e.storage.GetExecutionStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range stats.TopTools {
+		if seen[tool.Name] || stats.TotalExecutions == 0 {
+			continue
+		}
+		recommendations = append(recommendations, ToolRecommendation{
+			Tool:   tool.Name,
+			Reason: RecommendationPopular,
+			Score:  float64(tool.ExecutionCount) / float64(stats.TotalExecutions),
+			Count:  tool.ExecutionCount,
+		})
+	}
+
+	if limit > 0 && len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations, nil
+}
+
+// retried reports whether a record is a retry of an earlier attempt
+func retried(record ExecutionRecord) bool {
+	switch attempt := record.Context["attempt"].(type) {
+	case int:
+		return attempt > 1
+	case float64: // Decoded from JSON
+		return attempt > 1
+	}
+	return false
+}
+
+// sortRecommendations orders recommendations by count, then by name
+func sortRecommendations(recommendations []ToolRecommendation) {
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Count != recommendations[j].Count {
+			return recommendations[i].Count > recommendations[j].Count
+		}
+		return recommendations[i].Tool < recommendations[j].Tool
+	})
+}
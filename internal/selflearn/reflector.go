@@ -7,14 +7,17 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/clock"
 	"go.uber.org/zap"
 )
 
 // Reflector generates insights and suggestions based on patterns and data
 type Reflector struct {
-	storage  Storage
-	analyzer *Analyzer
-	logger   *zap.Logger
+	storage     Storage
+	analyzer    *Analyzer
+	logger      *zap.Logger
+	aggregation AggregationConfig
+	clock       clock.Clock // Timestamps insights
 }
 
 // NewReflector creates a new insight reflector
@@ -23,6 +26,7 @@ func NewReflector(storage Storage, analyzer *Analyzer, logger *zap.Logger) *Refl
 		storage:  storage,
 		analyzer: analyzer,
 		logger:   logger,
+		clock:    clock.Real{},
 	}
 }
 
@@ -56,12 +60,15 @@ func (r *Reflector) GenerateInsights(ctx context.Context) ([]Insight, error) {
 		insights = append(insights, usageInsights...)
 	}
 
-	// Generate configuration insights
-	configInsights, err := r.generateConfigurationInsights(ctx)
-	if err != nil {
-		r.logger.Error("Failed to generate configuration insights", zap.Error(err))
-	} else {
-		insights = append(insights, configInsights...)
+	// Generate configuration insights. System-wide stats are not
+	// k-anonymous, so they are skipped in aggregation-only mode.
+	if !r.aggregation.Enabled {
+		configInsights, err := r.generateConfigurationInsights(ctx)
+		if err != nil {
+			r.logger.Error("Failed to generate configuration insights", zap.Error(err))
+		} else {
+			insights = append(insights, configInsights...)
+		}
 	}
 
 	// Store generated insights
@@ -131,7 +138,7 @@ func (r *Reflector) generateErrorInsights(ctx context.Context) ([]Insight, error
 				fmt.Sprintf("Pattern confidence: %.1f%%", pattern.Confidence*100),
 				fmt.Sprintf("Time range: %s to %s", pattern.FirstSeen.Format("2006-01-02"), pattern.LastSeen.Format("2006-01-02")),
 			},
-			CreatedAt: time.Now().UTC(),
+			CreatedAt: r.clock.Now().UTC(),
 			Metadata: map[string]string{
 				"tool_name":   pattern.Metadata["tool_name"],
 				"error_type":  pattern.Metadata["error_type"],
@@ -140,6 +147,8 @@ func (r *Reflector) generateErrorInsights(ctx context.Context) ([]Insight, error
 			},
 		}
 
+		attachRemediations(&insight)
+		scopeInsight(&insight, pattern)
 		insights = append(insights, insight)
 	}
 
@@ -176,7 +185,7 @@ func (r *Reflector) generatePerformanceInsights(ctx context.Context) ([]Insight,
 				fmt.Sprintf("Execution count: %s", pattern.Metadata["execution_count"]),
 				fmt.Sprintf("Success rate: %s%%", pattern.Metadata["success_rate"]),
 			},
-			CreatedAt: time.Now().UTC(),
+			CreatedAt: r.clock.Now().UTC(),
 			Metadata: map[string]string{
 				"tool_name":       pattern.Metadata["tool_name"],
 				"average_latency": pattern.Metadata["average_latency"],
@@ -185,6 +194,8 @@ func (r *Reflector) generatePerformanceInsights(ctx context.Context) ([]Insight,
 			},
 		}
 
+		attachRemediations(&insight)
+		scopeInsight(&insight, pattern)
 		insights = append(insights, insight)
 	}
 
@@ -215,7 +226,7 @@ func (r *Reflector) generateUsageInsights(ctx context.Context) ([]Insight, error
 				fmt.Sprintf("Usage percentage: %s%%", pattern.Metadata["usage_percentage"]),
 				fmt.Sprintf("Total executions: %s", pattern.Metadata["execution_count"]),
 			},
-			CreatedAt: time.Now().UTC(),
+			CreatedAt: r.clock.Now().UTC(),
 			Metadata: map[string]string{
 				"tool_name":        pattern.Metadata["tool_name"],
 				"usage_percentage": pattern.Metadata["usage_percentage"],
@@ -224,6 +235,7 @@ func (r *Reflector) generateUsageInsights(ctx context.Context) ([]Insight, error
 			},
 		}
 
+		scopeInsight(&insight, pattern)
 		insights = append(insights, insight)
 	}
 
@@ -258,7 +270,7 @@ func (r *Reflector) generateConfigurationInsights(ctx context.Context) ([]Insigh
 				fmt.Sprintf("Total executions: %d", stats.TotalExecutions),
 				fmt.Sprintf("Error breakdown available for detailed analysis"),
 			},
-			CreatedAt: time.Now().UTC(),
+			CreatedAt: r.clock.Now().UTC(),
 			Metadata: map[string]string{
 				"success_rate":      fmt.Sprintf("%.2f", stats.SuccessRate),
 				"total_executions":  fmt.Sprintf("%d", stats.TotalExecutions),
@@ -282,7 +294,7 @@ func (r *Reflector) generateConfigurationInsights(ctx context.Context) ([]Insigh
 				fmt.Sprintf("Network errors: %d", networkErrors),
 				fmt.Sprintf("Error percentage: %.1f%%", float64(networkErrors)/float64(stats.TotalExecutions)*100),
 			},
-			CreatedAt: time.Now().UTC(),
+			CreatedAt: r.clock.Now().UTC(),
 			Metadata: map[string]string{
 				"network_errors":   fmt.Sprintf("%d", networkErrors),
 				"total_executions": fmt.Sprintf("%d", stats.TotalExecutions),
@@ -0,0 +1,105 @@
+package selflearn
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aionmcp/aionmcp/pkg/learning"
+)
+
+// The remediation types are defined in the learning package with Insight
+type (
+	RemediationAction = learning.RemediationAction
+	Remediation       = learning.Remediation
+	RemediationStatus = learning.RemediationStatus
+)
+
+const (
+	RemediationDisableTool     = learning.RemediationDisableTool
+	RemediationIncreaseTimeout = learning.RemediationIncreaseTimeout
+
+	RemediationPending  = learning.RemediationPending
+	RemediationApplied  = learning.RemediationApplied
+	RemediationRejected = learning.RemediationRejected
+	RemediationFailed   = learning.RemediationFailed
+)
+
+// DefaultTimeoutFactor is how much increase_timeout remediations raise a timeout
+const DefaultTimeoutFactor = 2.0
+
+// remediationScanLimit bounds the insights searched for the approval queue
+const remediationScanLimit = 1000
+
+// attachRemediations adds the remediations suggested for an insight and
+// queues them for approval
+func attachRemediations(insight *Insight) {
+	insight.Remediations = remediationsFor(*insight)
+	if len(insight.Remediations) > 0 {
+		insight.RemediationStatus = RemediationPending
+	}
+}
+
+// remediationsFor suggests the remediations of an insight. Tools failing
+// critically are disabled until someone looks at them, and the timeouts of
+// tools that are often slow are raised.
+func remediationsFor(insight Insight) []Remediation {
+	toolName := insight.Metadata["tool_name"]
+	if toolName == "" {
+		return nil
+	}
+	switch {
+	case insight.Type == InsightTypeReliability && insight.Priority == PriorityCritical:
+		return []Remediation{{
+			Action:      RemediationDisableTool,
+			Tool:        toolName,
+			Description: fmt.Sprintf("Disable %s until its errors are resolved", toolName),
+		}}
+	case insight.Type == InsightTypePerformance && insight.Priority.AtLeast(PriorityHigh):
+		return []Remediation{{
+			Action:      RemediationIncreaseTimeout,
+			Tool:        toolName,
+			Factor:      DefaultTimeoutFactor,
+			Description: fmt.Sprintf("Double the timeout of %s so slow calls can complete", toolName),
+		}}
+	}
+	return nil
+}
+
+// GetInsight returns an insight by ID
+func (e *Engine) GetInsight(ctx context.Context, id string) (Insight, error) {
+	return e.storage.GetInsight(ctx, id)
+}
+
+// SetRemediationStatus records what became of an insight's remediations,
+// with a note such as the error that made them fail
+func (e *Engine) SetRemediationStatus(ctx context.Context, id string, status RemediationStatus, note string) (Insight, error) {
+	insight, err := e.storage.GetInsight(ctx, id)
+	if err != nil {
+		return insight, err
+	}
+	insight.RemediationStatus = status
+	insight.RemediationNote = note
+	now := e.clock.Now().UTC()
+	insight.RemediatedAt = &now
+	return insight, e.storage.UpdateInsight(ctx, insight)
+}
+
+// GetRemediations returns up to limit insights whose remediations have the
+// given status, for the approval queue
+func (e *Engine) GetRemediations(ctx context.Context, status RemediationStatus, limit int) ([]Insight, error) {
+	insights, err := e.storage.GetInsights(ctx, "", remediationScanLimit)
+	if err != nil {
+		return nil, err
+	}
+	matching := []Insight{}
+	for _, insight := range e.prioritize(insights) {
+		if len(insight.Remediations) == 0 || insight.RemediationStatus != status {
+			continue
+		}
+		if limit > 0 && len(matching) >= limit {
+			break
+		}
+		matching = append(matching, insight)
+	}
+	return matching, nil
+}
@@ -0,0 +1,82 @@
+package selflearn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemediationsFor(t *testing.T) {
+	tool := map[string]string{"tool_name": "echo"}
+
+	// Critical reliability insights disable the tool
+	remediations := remediationsFor(Insight{Type: InsightTypeReliability, Priority: PriorityCritical, Metadata: tool})
+	require.Len(t, remediations, 1)
+	assert.Equal(t, RemediationDisableTool, remediations[0].Action)
+	assert.Equal(t, "echo", remediations[0].Tool)
+
+	// High performance insights raise its timeout
+	remediations = remediationsFor(Insight{Type: InsightTypePerformance, Priority: PriorityHigh, Metadata: tool})
+	require.Len(t, remediations, 1)
+	assert.Equal(t, RemediationIncreaseTimeout, remediations[0].Action)
+	assert.Equal(t, DefaultTimeoutFactor, remediations[0].Factor)
+
+	assert.Empty(t, remediationsFor(Insight{Type: InsightTypeReliability, Priority: PriorityHigh, Metadata: tool}))
+	assert.Empty(t, remediationsFor(Insight{Type: InsightTypePerformance, Priority: PriorityMedium, Metadata: tool}))
+	assert.Empty(t, remediationsFor(Insight{Type: InsightTypeReliability, Priority: PriorityCritical}))
+
+	insight := Insight{Type: InsightTypeReliability, Priority: PriorityCritical, Metadata: tool}
+	attachRemediations(&insight)
+	assert.Equal(t, RemediationPending, insight.RemediationStatus)
+	insight = Insight{Type: InsightTypeOptimization, Priority: PriorityCritical, Metadata: tool}
+	attachRemediations(&insight)
+	assert.Empty(t, insight.RemediationStatus)
+}
+
+func TestRemediationStatus(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	engine, _, _ := newTestEngine(t, now)
+	ctx := context.Background()
+	for _, insight := range []Insight{
+		{ID: "insight_timeout", Type: InsightTypePerformance, Priority: PriorityHigh},
+		{ID: "insight_disable", Type: InsightTypeReliability, Priority: PriorityCritical},
+		{ID: "insight_rejected", Type: InsightTypeReliability, Priority: PriorityCritical},
+	} {
+		insight.Metadata = map[string]string{"tool_name": "echo"}
+		attachRemediations(&insight)
+		require.NoError(t, engine.RecordInsight(ctx, insight))
+	}
+	require.NoError(t, engine.RecordInsight(ctx, Insight{ID: "insight_plain", Type: InsightTypeOptimization, Priority: PriorityLow}))
+
+	// Only insights with remediations queue for approval, most urgent first
+	pending, err := engine.GetRemediations(ctx, RemediationPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	assert.Equal(t, PriorityCritical, pending[0].Priority)
+	pending, err = engine.GetRemediations(ctx, RemediationPending, 1)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	insight, err := engine.SetRemediationStatus(ctx, "insight_rejected", RemediationRejected, "not now")
+	require.NoError(t, err)
+	assert.Equal(t, RemediationRejected, insight.RemediationStatus)
+	require.NotNil(t, insight.RemediatedAt)
+	assert.True(t, now.Equal(*insight.RemediatedAt))
+	stored, err := engine.GetInsight(ctx, "insight_rejected")
+	require.NoError(t, err)
+	assert.Equal(t, "not now", stored.RemediationNote)
+
+	rejected, err := engine.GetRemediations(ctx, RemediationRejected, 0)
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "insight_rejected", rejected[0].ID)
+	pending, err = engine.GetRemediations(ctx, RemediationPending, 0)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	_, err = engine.SetRemediationStatus(ctx, "missing", RemediationApplied, "")
+	assert.Error(t, err)
+}
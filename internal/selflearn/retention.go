@@ -0,0 +1,144 @@
+package selflearn
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRetentionInterval is how often the retention job runs
+const DefaultRetentionInterval = time.Hour
+
+// RetentionConfig controls the background retention job
+type RetentionConfig struct {
+	Interval   time.Duration // How often retention runs
+	Downsample bool          // Keep hourly aggregates of removed executions when storage supports it
+	Compact    bool          // Compact storage after removing executions when storage supports it
+}
+
+// DefaultRetentionConfig returns the default retention configuration
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		Interval:   DefaultRetentionInterval,
+		Downsample: true,
+		Compact:    true,
+	}
+}
+
+// RetentionRun reports what a retention run did
+type RetentionRun struct {
+	StartedAt   time.Time     `json:"started_at"`
+	Duration    time.Duration `json:"duration"`
+	Downsampled int           `json:"downsampled"` // Executions folded into hourly aggregates
+	Compacted   bool          `json:"compacted"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// retention holds the retention configuration and the last run
+type retention struct {
+	mu      sync.Mutex
+	config  RetentionConfig
+	lastRun *RetentionRun
+}
+
+// SetRetention configures the retention job
+func (e *Engine) SetRetention(config RetentionConfig) {
+	if config.Interval <= 0 {
+		config.Interval = DefaultRetentionInterval
+	}
+	e.retention.mu.Lock()
+	defer e.retention.mu.Unlock()
+	e.retention.config = config
+}
+
+// LastRetentionRun returns the last retention run, or nil before the first
+func (e *Engine) LastRetentionRun() *RetentionRun {
+	e.retention.mu.Lock()
+	defer e.retention.mu.Unlock()
+	if e.retention.lastRun == nil {
+		return nil
+	}
+	run := *e.retention.lastRun
+	return &run
+}
+
+// RunRetention removes executions older than the retention period. With
+// downsampling they are folded into hourly aggregates first, and with
+// compaction the storage reclaims their space afterwards.
+func (e *Engine) RunRetention(ctx context.Context) (RetentionRun, error) {
+	e.retention.mu.Lock()
+	config := e.retention.config
+	e.retention.mu.Unlock()
+
+	run := RetentionRun{StartedAt: e.clock.Now()}
+	err := e.runRetention(ctx, config, &run)
+	run.Duration = e.clock.Now().Sub(run.StartedAt)
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	e.retention.mu.Lock()
+	e.retention.lastRun = &run
+	e.retention.mu.Unlock()
+	return run, err
+}
+
+func (e *Engine) runRetention(ctx context.Context, config RetentionConfig, run *RetentionRun) error {
+	downsampler, downsamples := e.storage.(Downsampler)
+	if config.Downsample && downsamples {
+		downsampled, err := downsampler.Downsample(ctx, e.config.RetentionPeriod)
+		if err != nil {
+			return fmt.Errorf("failed to downsample executions: %w", err)
+		}
+		run.Downsampled = downsampled
+	} else if err := e.storage.Cleanup(ctx, e.config.RetentionPeriod); err != nil {
+		return fmt.Errorf("failed to cleanup old data: %w", err)
+	}
+
+	if compactor, ok := e.storage.(Compactor); ok && config.Compact {
+		if err := compactor.Compact(ctx); err != nil {
+			return fmt.Errorf("failed to compact storage: %w", err)
+		}
+		run.Compacted = true
+	}
+	return nil
+}
+
+// RunRetentionSchedule runs retention every interval until ctx ends
+func (e *Engine) RunRetentionSchedule(ctx context.Context) {
+	e.retention.mu.Lock()
+	interval := e.retention.config.Interval
+	e.retention.mu.Unlock()
+
+	ticker := e.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			run, err := e.RunRetention(ctx)
+			if err != nil {
+				e.logger.Error("Learning retention failed", zap.Error(err))
+				continue
+			}
+			e.logger.Info("Learning retention completed",
+				zap.Int("downsampled", run.Downsampled),
+				zap.Bool("compacted", run.Compacted),
+				zap.Duration("duration", run.Duration))
+		}
+	}
+}
+
+// GetHourlyAggregates returns the hourly aggregates retention kept for the
+// time range, or none when storage cannot downsample
+func (e *Engine) GetHourlyAggregates(ctx context.Context, toolName string, start, end time.Time) ([]HourlyAggregate, error) {
+	downsampler, ok := e.storage.(Downsampler)
+	if !ok {
+		return []HourlyAggregate{}, nil
+	}
+	return downsampler.GetHourlyAggregates(ctx, toolName, start, end)
+}
@@ -0,0 +1,108 @@
+package selflearn
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRetention(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	storage, err := NewBoltStorage(filepath.Join(t.TempDir(), "learning.db"), zap.NewNop())
+	require.NoError(t, err)
+	config := DefaultCollectionConfig()
+	config.AsyncProcessing = false
+	engine := NewEngine(config, storage, zap.NewNop())
+	defer engine.Close()
+	fake := clock.NewFake(start)
+	engine.SetClock(fake)
+
+	ctx := context.Background()
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, errors.New("connection refused"), time.Millisecond))
+	assert.Nil(t, engine.LastRetentionRun())
+
+	// Executions inside the retention period are kept
+	run, err := engine.RunRetention(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, run.Downsampled)
+	assert.True(t, run.Compacted)
+
+	// Past it they are folded into an hourly aggregate
+	fake.Advance(31 * 24 * time.Hour)
+	run, err = engine.RunRetention(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, run.Downsampled)
+	assert.True(t, run.Compacted)
+	assert.True(t, fake.Now().Equal(run.StartedAt))
+	assert.Equal(t, &run, engine.LastRetentionRun())
+	stats, err := engine.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalExecutions)
+
+	aggregates, err := engine.GetHourlyAggregates(ctx, "echo", start.Add(-time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, aggregates, 1)
+	assert.True(t, start.Equal(aggregates[0].Hour))
+	assert.Equal(t, int64(2), aggregates[0].Executions)
+	assert.Equal(t, int64(1), aggregates[0].Successes)
+	aggregates, err = engine.GetHourlyAggregates(ctx, "status", start.Add(-time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, aggregates)
+}
+
+func TestRetentionWithoutDownsampling(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	engine, _, fake := newTestEngine(t, start)
+	engine.SetRetention(RetentionConfig{Compact: true})
+
+	ctx := context.Background()
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	fake.Advance(31 * 24 * time.Hour)
+
+	// Old executions are dropped, and memory storage has nothing to compact
+	run, err := engine.RunRetention(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, run.Downsampled)
+	assert.False(t, run.Compacted)
+	stats, err := engine.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalExecutions)
+	aggregates, err := engine.GetHourlyAggregates(ctx, "echo", start.Add(-time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, aggregates)
+}
+
+func TestRetentionSchedule(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	engine, _, fake := newTestEngine(t, start)
+	engine.SetRetention(RetentionConfig{Interval: 10 * time.Minute, Downsample: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		engine.RunRetentionSchedule(ctx)
+		close(done)
+	}()
+
+	// Runs follow the configured interval on the engine's clock
+	require.Eventually(t, func() bool {
+		fake.Advance(10 * time.Minute)
+		return engine.LastRetentionRun() != nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, engine.LastRetentionRun().Error)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("retention schedule did not stop")
+	}
+}
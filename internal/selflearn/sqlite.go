@@ -0,0 +1,568 @@
+package selflearn
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // Registers the "sqlite" driver
+)
+
+// sqliteSchema creates the tables and the indexes that let queries by tool,
+// time and outcome avoid scanning every execution
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS executions (
+	id         TEXT PRIMARY KEY,
+	tool_name  TEXT NOT NULL,
+	timestamp  INTEGER NOT NULL, -- Unix nanoseconds
+	success    INTEGER NOT NULL,
+	duration   INTEGER NOT NULL, -- Nanoseconds
+	error_type TEXT NOT NULL,
+	data       BLOB NOT NULL     -- The JSON encoded record
+);
+CREATE INDEX IF NOT EXISTS executions_tool_time ON executions (tool_name, timestamp);
+CREATE INDEX IF NOT EXISTS executions_time ON executions (timestamp);
+CREATE INDEX IF NOT EXISTS executions_success ON executions (success, error_type);
+
+CREATE TABLE IF NOT EXISTS patterns (
+	id   TEXT PRIMARY KEY,
+	type TEXT NOT NULL,
+	data BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS patterns_type ON patterns (type);
+
+CREATE TABLE IF NOT EXISTS insights (
+	id       TEXT PRIMARY KEY,
+	type     TEXT NOT NULL,
+	priority TEXT NOT NULL,
+	data     BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS insights_type ON insights (type);
+CREATE INDEX IF NOT EXISTS insights_priority ON insights (priority);
+
+CREATE TABLE IF NOT EXISTS hourly_aggregates (
+	tool_name TEXT NOT NULL,
+	hour      INTEGER NOT NULL, -- Unix seconds
+	data      BLOB NOT NULL,
+	PRIMARY KEY (tool_name, hour)
+);
+
+CREATE TABLE IF NOT EXISTS health (
+	id         INTEGER PRIMARY KEY CHECK (id = 1),
+	checked_at TEXT NOT NULL
+);
+`
+
+// sqliteBusyTimeout is how long a write waits for another connection's
+// transaction before failing
+const sqliteBusyTimeout = 5 * time.Second
+
+// SQLiteStorage implements Storage interface using SQLite. Executions are
+// stored with indexed columns for tool name, timestamp and outcome, so
+// statistics and per-tool queries are answered by the database instead of
+// decoding every record.
+type SQLiteStorage struct {
+	db     *sql.DB
+	logger *zap.Logger
+	clock  clock.Clock // Judges retention
+}
+
+// NewSQLiteStorage opens or creates a SQLite database at dbPath
+func NewSQLiteStorage(dbPath string, logger *zap.Logger) (*SQLiteStorage, error) {
+	// Ensure directory exists
+	if err := ensureDir(filepath.Dir(dbPath)); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	// WAL lets readers proceed while a record is written
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)", dbPath, sqliteBusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	}
+
+	return &SQLiteStorage{
+		db:     db,
+		logger: logger,
+		clock:  clock.Real{},
+	}, nil
+}
+
+// StoreExecution stores an execution record
+func (s *SQLiteStorage) StoreExecution(ctx context.Context, record ExecutionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution record: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO executions (id, tool_name, timestamp, success, duration, error_type, data) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		record.ID, record.ToolName, record.Timestamp.UnixNano(), record.Success, int64(record.Duration), record.ErrorType, data)
+	return err
+}
+
+// GetExecution retrieves an execution record by ID
+func (s *SQLiteStorage) GetExecution(ctx context.Context, id string) (ExecutionRecord, error) {
+	var record ExecutionRecord
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM executions WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return record, fmt.Errorf("execution record not found: %s", id)
+	}
+	if err != nil {
+		return record, err
+	}
+	return record, json.Unmarshal(data, &record)
+}
+
+// GetExecutionsByTool retrieves execution records for a specific tool, newest first
+func (s *SQLiteStorage) GetExecutionsByTool(ctx context.Context, toolName string, limit int) ([]ExecutionRecord, error) {
+	return s.queryExecutions(ctx,
+		`SELECT data FROM executions WHERE tool_name = ? ORDER BY timestamp DESC LIMIT ?`, toolName, max(limit, 0))
+}
+
+// GetExecutionsByTimeRange retrieves execution records within a time range, oldest first
+func (s *SQLiteStorage) GetExecutionsByTimeRange(ctx context.Context, start, end time.Time, limit int) ([]ExecutionRecord, error) {
+	return s.queryExecutions(ctx,
+		`SELECT data FROM executions WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp LIMIT ?`,
+		start.UnixNano(), end.UnixNano(), max(limit, 0))
+}
+
+// queryExecutions decodes the records a query selects
+func (s *SQLiteStorage) queryExecutions(ctx context.Context, query string, args ...interface{}) ([]ExecutionRecord, error) {
+	var records []ExecutionRecord
+	err := s.iterate(ctx, query, args, func(record ExecutionRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+// iterate calls fn for every execution record a query selects. Records that
+// fail to decode are skipped.
+func (s *SQLiteStorage) iterate(ctx context.Context, query string, args []interface{}, fn func(ExecutionRecord) error) error {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		var record ExecutionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			s.logger.Warn("Failed to unmarshal execution record", zap.Error(err))
+			continue
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// IterateExecutions calls fn for every execution record in the time range, oldest
+// first, without loading the range into memory. Iteration stops at the first error.
+func (s *SQLiteStorage) IterateExecutions(ctx context.Context, start, end time.Time, fn func(ExecutionRecord) error) error {
+	return s.iterate(ctx,
+		`SELECT data FROM executions WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp`,
+		[]interface{}{start.UnixNano(), end.UnixNano()},
+		func(record ExecutionRecord) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fn(record)
+		})
+}
+
+// GetExecutionStats calculates learning statistics with aggregate queries
+// over the indexed columns
+func (s *SQLiteStorage) GetExecutionStats(ctx context.Context) (LearningStats, error) {
+	stats := LearningStats{
+		ErrorBreakdown: make(map[string]int),
+		TopTools:       []ToolStat{},
+		LastUpdated:    time.Now().UTC(),
+	}
+
+	var successCount, totalDuration int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COALESCE(SUM(success), 0), COALESCE(SUM(duration), 0) FROM executions`,
+	).Scan(&stats.TotalExecutions, &successCount, &totalDuration)
+	if err != nil || stats.TotalExecutions == 0 {
+		return stats, err
+	}
+	stats.SuccessRate = float64(successCount) / float64(stats.TotalExecutions)
+	stats.AverageLatency = time.Duration(totalDuration / stats.TotalExecutions)
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT error_type, COUNT(*) FROM executions WHERE success = 0 GROUP BY error_type`)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var errorType string
+		var count int
+		if err := rows.Scan(&errorType, &count); err != nil {
+			return stats, err
+		}
+		stats.ErrorBreakdown[errorType] = count
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	// The ten most used tools
+	toolRows, err := s.db.QueryContext(ctx,
+		`SELECT tool_name, COUNT(*), SUM(success), AVG(duration), MIN(timestamp), MAX(timestamp)
+		 FROM executions GROUP BY tool_name ORDER BY COUNT(*) DESC, tool_name LIMIT 10`)
+	if err != nil {
+		return stats, err
+	}
+	defer toolRows.Close()
+	for toolRows.Next() {
+		var stat ToolStat
+		var averageLatency float64
+		var firstUsed, lastUsed int64
+		if err := toolRows.Scan(&stat.Name, &stat.ExecutionCount, &stat.SuccessCount, &averageLatency, &firstUsed, &lastUsed); err != nil {
+			return stats, err
+		}
+		stat.FailureCount = stat.ExecutionCount - stat.SuccessCount
+		stat.SuccessRate = float64(stat.SuccessCount) / float64(stat.ExecutionCount)
+		stat.AverageLatency = time.Duration(averageLatency)
+		stat.FirstUsed = time.Unix(0, firstUsed).UTC()
+		stat.LastUsed = time.Unix(0, lastUsed).UTC()
+		stats.TopTools = append(stats.TopTools, stat)
+	}
+	if err := toolRows.Err(); err != nil {
+		return stats, err
+	}
+	return stats, s.latencyPercentiles(ctx, &stats)
+}
+
+// latencyPercentiles fills in the latency percentiles of stats and its top
+// tools from histograms of the duration column
+func (s *SQLiteStorage) latencyPercentiles(ctx context.Context, stats *LearningStats) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT tool_name, duration FROM executions`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	latencies := learning.NewLatencyHistogram()
+	toolLatencies := make(map[string]*learning.LatencyHistogram)
+	for rows.Next() {
+		var toolName string
+		var duration int64
+		if err := rows.Scan(&toolName, &duration); err != nil {
+			return err
+		}
+		latencies.Record(time.Duration(duration))
+		if toolLatencies[toolName] == nil {
+			toolLatencies[toolName] = learning.NewLatencyHistogram()
+		}
+		toolLatencies[toolName].Record(time.Duration(duration))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	stats.P50Latency, stats.P95Latency, stats.P99Latency = latencies.Percentiles()
+	for i := range stats.TopTools {
+		if histogram := toolLatencies[stats.TopTools[i].Name]; histogram != nil {
+			stats.TopTools[i].P50Latency, stats.TopTools[i].P95Latency, stats.TopTools[i].P99Latency = histogram.Percentiles()
+		}
+	}
+	return nil
+}
+
+// StorePattern stores a pattern
+func (s *SQLiteStorage) StorePattern(ctx context.Context, pattern Pattern) error {
+	data, err := json.Marshal(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pattern: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO patterns (id, type, data) VALUES (?, ?, ?)`,
+		pattern.ID, string(pattern.Type), data)
+	return err
+}
+
+// GetPattern retrieves a pattern by ID
+func (s *SQLiteStorage) GetPattern(ctx context.Context, id string) (Pattern, error) {
+	var pattern Pattern
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM patterns WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pattern, fmt.Errorf("pattern not found: %s", id)
+	}
+	if err != nil {
+		return pattern, err
+	}
+	return pattern, json.Unmarshal(data, &pattern)
+}
+
+// GetPatterns retrieves patterns by type
+func (s *SQLiteStorage) GetPatterns(ctx context.Context, patternType PatternType, limit int) ([]Pattern, error) {
+	var patterns []Pattern
+	err := s.queryJSON(ctx, `SELECT data FROM patterns WHERE ? = '' OR type = ? ORDER BY id LIMIT ?`,
+		[]interface{}{string(patternType), string(patternType), max(limit, 0)},
+		func(data []byte) error {
+			var pattern Pattern
+			if err := json.Unmarshal(data, &pattern); err == nil {
+				patterns = append(patterns, pattern)
+			}
+			return nil
+		})
+	return patterns, err
+}
+
+// UpdatePattern updates an existing pattern
+func (s *SQLiteStorage) UpdatePattern(ctx context.Context, pattern Pattern) error {
+	return s.StorePattern(ctx, pattern)
+}
+
+// DeletePattern deletes a pattern
+func (s *SQLiteStorage) DeletePattern(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM patterns WHERE id = ?`, id)
+	return err
+}
+
+// StoreInsight stores an insight
+func (s *SQLiteStorage) StoreInsight(ctx context.Context, insight Insight) error {
+	data, err := json.Marshal(insight)
+	if err != nil {
+		return fmt.Errorf("failed to marshal insight: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO insights (id, type, priority, data) VALUES (?, ?, ?, ?)`,
+		insight.ID, string(insight.Type), string(insight.Priority), data)
+	return err
+}
+
+// GetInsight retrieves an insight by ID
+func (s *SQLiteStorage) GetInsight(ctx context.Context, id string) (Insight, error) {
+	var insight Insight
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM insights WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return insight, fmt.Errorf("insight not found: %s", id)
+	}
+	if err != nil {
+		return insight, err
+	}
+	return insight, json.Unmarshal(data, &insight)
+}
+
+// GetInsights retrieves insights by type
+func (s *SQLiteStorage) GetInsights(ctx context.Context, insightType InsightType, limit int) ([]Insight, error) {
+	return s.queryInsights(ctx, `SELECT data FROM insights WHERE ? = '' OR type = ? ORDER BY id LIMIT ?`,
+		string(insightType), limit)
+}
+
+// GetInsightsByPriority retrieves insights by priority
+func (s *SQLiteStorage) GetInsightsByPriority(ctx context.Context, priority Priority, limit int) ([]Insight, error) {
+	return s.queryInsights(ctx, `SELECT data FROM insights WHERE ? = '' OR priority = ? ORDER BY id LIMIT ?`,
+		string(priority), limit)
+}
+
+// queryInsights decodes the insights a query filtering by one column selects;
+// an empty filter selects every insight
+func (s *SQLiteStorage) queryInsights(ctx context.Context, query, filter string, limit int) ([]Insight, error) {
+	var insights []Insight
+	err := s.queryJSON(ctx, query, []interface{}{filter, filter, max(limit, 0)}, func(data []byte) error {
+		var insight Insight
+		if err := json.Unmarshal(data, &insight); err == nil {
+			insights = append(insights, insight)
+		}
+		return nil
+	})
+	return insights, err
+}
+
+// queryJSON calls fn with the data column of every row a query selects
+func (s *SQLiteStorage) queryJSON(ctx context.Context, query string, args []interface{}, fn func([]byte) error) error {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// UpdateInsight updates an existing insight
+func (s *SQLiteStorage) UpdateInsight(ctx context.Context, insight Insight) error {
+	return s.StoreInsight(ctx, insight)
+}
+
+// DeleteInsight deletes an insight
+func (s *SQLiteStorage) DeleteInsight(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM insights WHERE id = ?`, id)
+	return err
+}
+
+// SetClock replaces the clock retention is judged by
+func (s *SQLiteStorage) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// Cleanup removes execution records older than the retention period
+func (s *SQLiteStorage) Cleanup(ctx context.Context, retentionPeriod time.Duration) error {
+	cutoff := s.clock.Now().Add(-retentionPeriod)
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM executions WHERE timestamp < ?`, cutoff.UnixNano())
+	if err != nil {
+		return err
+	}
+	deleted, _ := result.RowsAffected()
+	s.logger.Info("Cleanup completed", zap.Int64("deleted_records", deleted))
+	return nil
+}
+
+// Downsample folds executions older than the retention period into hourly
+// aggregates and removes them, in one transaction
+func (s *SQLiteStorage) Downsample(ctx context.Context, retentionPeriod time.Duration) (int, error) {
+	cutoff := s.clock.Now().Add(-retentionPeriod)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	aggregates := make(learning.HourlyAggregates)
+	rows, err := tx.QueryContext(ctx, `SELECT data FROM executions WHERE timestamp < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		var record ExecutionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			s.logger.Warn("Failed to unmarshal execution record", zap.Error(err))
+			continue
+		}
+		aggregates.Add(record)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, aggregate := range aggregates {
+		var data []byte
+		err := tx.QueryRowContext(ctx, `SELECT data FROM hourly_aggregates WHERE tool_name = ? AND hour = ?`,
+			aggregate.ToolName, aggregate.Hour.Unix()).Scan(&data)
+		if err == nil {
+			var stored HourlyAggregate
+			if json.Unmarshal(data, &stored) == nil {
+				aggregate.Merge(stored)
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+		if data, err = json.Marshal(aggregate); err != nil {
+			return 0, fmt.Errorf("failed to marshal hourly aggregate: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO hourly_aggregates (tool_name, hour, data) VALUES (?, ?, ?)`,
+			aggregate.ToolName, aggregate.Hour.Unix(), data); err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM executions WHERE timestamp < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	removed, _ := result.RowsAffected()
+	s.logger.Info("Downsampling completed", zap.Int64("downsampled_records", removed))
+	return int(removed), nil
+}
+
+// GetHourlyAggregates returns the hourly aggregates of hours starting in the
+// time range, oldest first. An empty tool name selects every tool.
+func (s *SQLiteStorage) GetHourlyAggregates(ctx context.Context, toolName string, start, end time.Time) ([]HourlyAggregate, error) {
+	query := `SELECT data FROM hourly_aggregates WHERE hour >= ? AND hour <= ?`
+	args := []interface{}{start.Unix(), end.Unix()}
+	if start.Truncate(time.Second).Before(start) {
+		args[0] = start.Unix() + 1 // An hour starting before start is out of range
+	}
+	if toolName != "" {
+		query += ` AND tool_name = ?`
+		args = append(args, toolName)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY hour, tool_name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aggregates := []HourlyAggregate{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var aggregate HourlyAggregate
+		if err := json.Unmarshal(data, &aggregate); err != nil {
+			s.logger.Warn("Failed to unmarshal hourly aggregate", zap.Error(err))
+			continue
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	return aggregates, rows.Err()
+}
+
+// Compact rebuilds the database without the free pages left by removed
+// records and truncates the write-ahead log
+func (s *SQLiteStorage) Compact(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	_, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`)
+	return err
+}
+
+// CheckWritable verifies the database accepts writes by recording the time
+// of the check
+func (s *SQLiteStorage) CheckWritable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO health (id, checked_at) VALUES (1, ?)`,
+		s.clock.Now().UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+// Close closes the SQLite database
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
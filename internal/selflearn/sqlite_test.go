@@ -0,0 +1,67 @@
+package selflearn
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSQLiteStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "learning.sqlite")
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	storage, err := NewSQLiteStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+	for i, success := range []bool{true, true, false} {
+		require.NoError(t, storage.StoreExecution(ctx, ExecutionRecord{
+			ID:        "exec_" + string(rune('a'+i)),
+			ToolName:  "echo",
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Duration:  time.Duration(i+1) * time.Millisecond,
+			Success:   success,
+		}))
+	}
+	require.NoError(t, storage.StoreInsight(ctx, Insight{ID: "insight_echo", Type: InsightTypeReliability, Priority: PriorityHigh, CreatedAt: start}))
+	require.NoError(t, storage.CheckWritable(ctx))
+	require.NoError(t, storage.Close())
+
+	// Learning data survives reopening
+	storage, err = NewSQLiteStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	defer storage.Close()
+	stats, err := storage.GetExecutionStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalExecutions)
+	assert.InDelta(t, 2.0/3, stats.SuccessRate, 0.001)
+	require.Len(t, stats.TopTools, 1)
+	assert.Equal(t, "echo", stats.TopTools[0].Name)
+	assert.Equal(t, int64(2), stats.TopTools[0].SuccessCount)
+	record, err := storage.GetExecution(ctx, "exec_b")
+	require.NoError(t, err)
+	assert.True(t, start.Add(time.Minute).Equal(record.Timestamp))
+	insight, err := storage.GetInsight(ctx, "insight_echo")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityHigh, insight.Priority)
+
+	// Past the retention period executions are folded into hourly aggregates
+	storage.SetClock(clock.NewFake(start.Add(31 * 24 * time.Hour)))
+	downsampled, err := storage.Downsample(ctx, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 3, downsampled)
+	require.NoError(t, storage.Compact(ctx))
+	records, err := storage.GetExecutionsByTool(ctx, "echo", 10)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	aggregates, err := storage.GetHourlyAggregates(ctx, "echo", start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, aggregates, 1)
+	assert.True(t, start.Equal(aggregates[0].Hour))
+	assert.Equal(t, int64(3), aggregates[0].Executions)
+	assert.Equal(t, int64(2), aggregates[0].Successes)
+}
@@ -0,0 +1,162 @@
+package selflearn
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"go.uber.org/zap"
+)
+
+// MetadataTenant is the execution context key of the tenant owning an execution
+const MetadataTenant = "tenant"
+
+// TenantResolver returns the tenant owning a tool, or "" for tools shared by
+// every tenant
+type TenantResolver func(toolName string) string
+
+// TenantScope limits learning data to that of some tenants. Data of shared
+// tools is visible in every scope. The zero value sees every tenant.
+type TenantScope struct {
+	Restricted bool
+	Tenants    []string // Visible tenants as glob patterns, when restricted
+}
+
+// Allows reports whether the scope sees the data of a tenant
+func (s TenantScope) Allows(tenant string) bool {
+	if !s.Restricted || tenant == "" {
+		return true
+	}
+	for _, pattern := range s.Tenants {
+		if matched, _ := path.Match(pattern, tenant); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTenant attaches the tenant of the caller's session to a context passed
+// to RecordExecution. Executions of tools owned by a tenant belong to that
+// tenant regardless.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, contextKeyTenant, tenant)
+}
+
+// SetTenantResolver sets how tools map to the tenants owning them. It must be
+// called before executions are recorded.
+func (e *Engine) SetTenantResolver(resolver TenantResolver) {
+	e.tenants = resolver
+}
+
+// toolTenant returns the tenant owning a tool
+func (e *Engine) toolTenant(toolName string) string {
+	if e.tenants == nil {
+		return ""
+	}
+	return e.tenants(toolName)
+}
+
+// recordTenant returns the tenant an execution was recorded for, falling
+// back to the current owner of its tool for records that predate tenants
+func (e *Engine) recordTenant(record ExecutionRecord) string {
+	if tenant, ok := record.Context[MetadataTenant].(string); ok && tenant != "" {
+		return tenant
+	}
+	return e.toolTenant(record.ToolName)
+}
+
+// visibleInScope reports whether a pattern or insight with the given metadata
+// is visible in a restricted scope. Those spanning tools of several tenants,
+// such as system-wide insights, are hidden.
+func (e *Engine) visibleInScope(metadata map[string]string, scope TenantScope) bool {
+	if tenant, ok := metadata[MetadataTenant]; ok {
+		return scope.Allows(tenant)
+	}
+	toolName, ok := metadata["tool_name"]
+	return ok && scope.Allows(e.toolTenant(toolName))
+}
+
+// InsightsForTenants drops the insights a tenant scope does not see
+func (e *Engine) InsightsForTenants(insights []Insight, scope TenantScope) []Insight {
+	if !scope.Restricted {
+		return insights
+	}
+	visible := make([]Insight, 0, len(insights))
+	for _, insight := range insights {
+		if e.visibleInScope(insight.Metadata, scope) {
+			visible = append(visible, insight)
+		}
+	}
+	return visible
+}
+
+// PatternsForTenants drops the patterns a tenant scope does not see
+func (e *Engine) PatternsForTenants(patterns []Pattern, scope TenantScope) []Pattern {
+	if !scope.Restricted {
+		return patterns
+	}
+	visible := make([]Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if e.visibleInScope(pattern.Metadata, scope) {
+			visible = append(visible, pattern)
+		}
+	}
+	return visible
+}
+
+// AggregatesForTenants drops the hourly aggregates a tenant scope does not see
+func (e *Engine) AggregatesForTenants(aggregates []HourlyAggregate, scope TenantScope) []HourlyAggregate {
+	if !scope.Restricted {
+		return aggregates
+	}
+	visible := make([]HourlyAggregate, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		if scope.Allows(e.toolTenant(aggregate.ToolName)) {
+			visible = append(visible, aggregate)
+		}
+	}
+	return visible
+}
+
+// GetStatsForTenants returns the learning statistics a tenant scope sees.
+// Restricted scopes are computed from the raw executions of their tenants;
+// executions retention folded into hourly aggregates do not count.
+func (e *Engine) GetStatsForTenants(ctx context.Context, scope TenantScope) (LearningStats, error) {
+	if !scope.Restricted {
+		return e.GetStats(ctx)
+	}
+
+	accumulator := learning.NewStatsAccumulator()
+	err := e.storage.IterateExecutions(ctx, time.Time{}, e.clock.Now(), func(record ExecutionRecord) error {
+		if scope.Allows(e.recordTenant(record)) {
+			accumulator.Add(record)
+		}
+		return nil
+	})
+	if err != nil {
+		return LearningStats{}, fmt.Errorf("failed to read executions: %w", err)
+	}
+	stats := accumulator.Stats()
+
+	if patterns, err := e.storage.GetPatterns(ctx, "", 50); err != nil {
+		e.logger.Warn("Failed to get recent patterns", zap.Error(err))
+	} else {
+		stats.RecentPatterns = firstN(e.PatternsForTenants(patterns, scope), 5)
+	}
+	if insights, err := e.storage.GetInsights(ctx, "", 100); err != nil {
+		e.logger.Warn("Failed to get active insights", zap.Error(err))
+	} else {
+		stats.ActiveInsights = firstN(e.prioritize(e.InsightsForTenants(insights, scope)), 10)
+	}
+	return stats, nil
+}
+
+// firstN returns at most the first n items
+func firstN[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -0,0 +1,91 @@
+package selflearn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantScope(t *testing.T) {
+	assert.True(t, TenantScope{}.Allows("billing"))
+	assert.True(t, TenantScope{Restricted: true, Tenants: []string{"bill*"}}.Allows("billing"))
+	assert.False(t, TenantScope{Restricted: true, Tenants: []string{"iam"}}.Allows("billing"))
+	assert.False(t, TenantScope{Restricted: true}.Allows("billing"))
+
+	// Shared data is visible in every scope
+	assert.True(t, TenantScope{Restricted: true}.Allows(""))
+}
+
+func TestTenants(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	engine, _, _ := newTestEngine(t, now)
+	engine.SetTenantResolver(func(toolName string) string {
+		if toolName == "openapi.iam.users" {
+			return "iam"
+		}
+		return ""
+	})
+	ctx := context.Background()
+
+	// Executions of shared tools belong to the tenant of the session, and
+	// those of owned tools to their owner
+	require.NoError(t, engine.RecordExecution(WithTenant(ctx, "billing"), "echo", "builtin", nil, nil, nil, time.Millisecond))
+	require.NoError(t, engine.RecordExecution(WithTenant(ctx, "iam"), "status", "builtin", nil, nil, errors.New("denied"), time.Millisecond))
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	require.NoError(t, engine.RecordExecution(ctx, "openapi.iam.users", "openapi", nil, nil, nil, time.Millisecond))
+
+	require.NoError(t, engine.RecordInsight(ctx, Insight{ID: "insight_iam", Priority: PriorityHigh, Metadata: map[string]string{"tool_name": "status", MetadataTenant: "iam"}}))
+	require.NoError(t, engine.RecordInsight(ctx, Insight{ID: "insight_owned", Priority: PriorityHigh, Metadata: map[string]string{"tool_name": "openapi.iam.users"}}))
+	require.NoError(t, engine.RecordInsight(ctx, Insight{ID: "insight_echo", Priority: PriorityHigh, Metadata: map[string]string{"tool_name": "echo"}}))
+	require.NoError(t, engine.RecordInsight(ctx, Insight{ID: "insight_system", Priority: PriorityHigh, Metadata: map[string]string{"source_type": "system_stats"}}))
+
+	// Unrestricted scopes see every tenant
+	stats, err := engine.GetStatsForTenants(ctx, TenantScope{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, stats.TotalExecutions)
+	assert.Len(t, stats.ActiveInsights, 4)
+
+	// Restricted scopes see their tenants and shared data only
+	billing := TenantScope{Restricted: true, Tenants: []string{"billing"}}
+	stats, err = engine.GetStatsForTenants(ctx, billing)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stats.TotalExecutions)
+	assert.Equal(t, 1.0, stats.SuccessRate)
+	require.Len(t, stats.TopTools, 1)
+	assert.Equal(t, "echo", stats.TopTools[0].Name)
+	require.Len(t, stats.ActiveInsights, 1)
+	assert.Equal(t, "insight_echo", stats.ActiveInsights[0].ID)
+
+	insights, err := engine.GetInsights(ctx, "", 50)
+	require.NoError(t, err)
+	var visible []string
+	for _, insight := range engine.InsightsForTenants(insights, TenantScope{Restricted: true, Tenants: []string{"iam"}}) {
+		visible = append(visible, insight.ID)
+	}
+	assert.ElementsMatch(t, []string{"insight_iam", "insight_owned", "insight_echo"}, visible)
+
+	// Exports leave out the executions of other tenants
+	var export bytes.Buffer
+	exported, err := engine.ExportExecutions(ctx, &export, ExportOptions{
+		Start:   now.Add(-time.Hour),
+		End:     now,
+		Format:  ExportFormatCSV,
+		Columns: []string{"tool", "outcome"},
+		Tenants: billing,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, exported)
+	assert.Equal(t, 2, strings.Count(export.String(), "echo"))
+	assert.NotContains(t, export.String(), "status")
+	assert.NotContains(t, export.String(), "openapi.iam.users")
+
+	aggregates := []HourlyAggregate{{ToolName: "echo"}, {ToolName: "openapi.iam.users"}}
+	assert.Equal(t, []HourlyAggregate{{ToolName: "echo"}}, engine.AggregatesForTenants(aggregates, billing))
+	assert.Equal(t, aggregates, engine.AggregatesForTenants(aggregates, TenantScope{}))
+}
@@ -0,0 +1,157 @@
+package selflearn
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeSeriesMetric is the value plotted by a time series
+type TimeSeriesMetric string
+
+const (
+	TimeSeriesLatency    TimeSeriesMetric = "latency"    // Average latency in milliseconds
+	TimeSeriesErrors     TimeSeriesMetric = "errors"     // Failed executions
+	TimeSeriesThroughput TimeSeriesMetric = "throughput" // Executions
+)
+
+// MaxTimeSeriesPoints bounds the buckets of one time series
+const MaxTimeSeriesPoints = 2000
+
+// TimeSeriesOptions selects the tool, metric, range and bucket size of a time series
+type TimeSeriesOptions struct {
+	ToolName string // Empty selects every tool
+	Metric   TimeSeriesMetric
+	Start    time.Time
+	End      time.Time
+	Step     time.Duration
+	Tenants  TenantScope // Tenants whose executions count
+}
+
+// Validate checks the options
+func (o TimeSeriesOptions) Validate() error {
+	switch o.Metric {
+	case TimeSeriesLatency, TimeSeriesErrors, TimeSeriesThroughput:
+	default:
+		return fmt.Errorf("unsupported time series metric: %s", o.Metric)
+	}
+	if o.Step <= 0 {
+		return fmt.Errorf("step must be positive")
+	}
+	if o.End.Before(o.Start) {
+		return fmt.Errorf("end time is before start time")
+	}
+	if points := o.points(); points > MaxTimeSeriesPoints {
+		return fmt.Errorf("time series would have %d points, more than %d; use a larger step", points, MaxTimeSeriesPoints)
+	}
+	return nil
+}
+
+// first returns the start of the bucket holding Start
+func (o TimeSeriesOptions) first() time.Time {
+	return o.Start.UTC().Truncate(o.Step)
+}
+
+// points returns the number of buckets from the first one through End
+func (o TimeSeriesOptions) points() int64 {
+	return int64(o.End.Sub(o.first())/o.Step) + 1
+}
+
+// TimeSeriesPoint is one bucket of a time series
+type TimeSeriesPoint struct {
+	Time       time.Time `json:"time"` // Start of the bucket, UTC
+	Value      float64   `json:"value"`
+	Executions int64     `json:"executions"`
+}
+
+// TimeSeries is a metric in buckets of equal size, oldest first. Buckets
+// without executions are included with a zero value.
+type TimeSeries struct {
+	ToolName string            `json:"tool_name,omitempty"`
+	Metric   TimeSeriesMetric  `json:"metric"`
+	Step     time.Duration     `json:"step"`
+	Points   []TimeSeriesPoint `json:"points"`
+}
+
+// timeSeriesBucket accumulates the executions of one bucket
+type timeSeriesBucket struct {
+	executions    int64
+	successes     int64
+	totalDuration time.Duration
+}
+
+// GetTimeSeries buckets a metric over a time range. Executions that
+// retention has folded into hourly aggregates count in the bucket holding
+// the start of their hour.
+func (e *Engine) GetTimeSeries(ctx context.Context, options TimeSeriesOptions) (TimeSeries, error) {
+	if err := options.Validate(); err != nil {
+		return TimeSeries{}, err
+	}
+
+	first := options.first()
+	buckets := make([]timeSeriesBucket, options.points())
+	bucket := func(t time.Time) *timeSeriesBucket {
+		index := int(t.Sub(first) / options.Step)
+		if index < 0 || index >= len(buckets) {
+			return nil
+		}
+		return &buckets[index]
+	}
+
+	err := e.storage.IterateExecutions(ctx, options.Start, options.End, func(record ExecutionRecord) error {
+		if options.ToolName != "" && record.ToolName != options.ToolName {
+			return nil
+		}
+		if options.Tenants.Restricted && !options.Tenants.Allows(e.recordTenant(record)) {
+			return nil
+		}
+		if b := bucket(record.Timestamp); b != nil {
+			b.executions++
+			b.totalDuration += record.Duration
+			if record.Success {
+				b.successes++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return TimeSeries{}, fmt.Errorf("failed to read executions: %w", err)
+	}
+
+	aggregates, err := e.GetHourlyAggregates(ctx, options.ToolName, first, options.End)
+	if err != nil {
+		return TimeSeries{}, fmt.Errorf("failed to read hourly aggregates: %w", err)
+	}
+	for _, aggregate := range aggregates {
+		if options.Tenants.Restricted && !options.Tenants.Allows(e.toolTenant(aggregate.ToolName)) {
+			continue
+		}
+		if b := bucket(aggregate.Hour); b != nil {
+			b.executions += aggregate.Executions
+			b.successes += aggregate.Successes
+			b.totalDuration += aggregate.TotalDuration
+		}
+	}
+
+	series := TimeSeries{
+		ToolName: options.ToolName,
+		Metric:   options.Metric,
+		Step:     options.Step,
+		Points:   make([]TimeSeriesPoint, len(buckets)),
+	}
+	for i, b := range buckets {
+		point := TimeSeriesPoint{Time: first.Add(time.Duration(i) * options.Step), Executions: b.executions}
+		switch options.Metric {
+		case TimeSeriesLatency:
+			if b.executions > 0 {
+				point.Value = float64(b.totalDuration) / float64(b.executions) / float64(time.Millisecond)
+			}
+		case TimeSeriesErrors:
+			point.Value = float64(b.executions - b.successes)
+		case TimeSeriesThroughput:
+			point.Value = float64(b.executions)
+		}
+		series.Points[i] = point
+	}
+	return series, nil
+}
@@ -0,0 +1,94 @@
+package selflearn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeSeries(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 12, 1, 0, 0, time.UTC)
+	engine, _, fake := newTestEngine(t, start)
+	ctx := context.Background()
+	record := func(tool string, err error, duration time.Duration) {
+		require.NoError(t, engine.RecordExecution(ctx, tool, "builtin", nil, nil, err, duration))
+	}
+	record("echo", nil, 10*time.Millisecond)
+	record("echo", errors.New("connection refused"), 30*time.Millisecond)
+	record("status", nil, time.Millisecond)
+	fake.Advance(10 * time.Minute)
+	record("echo", nil, 40*time.Millisecond)
+
+	series := func(options TimeSeriesOptions) []float64 {
+		options.Start = time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+		options.End = time.Date(2026, time.March, 1, 12, 14, 0, 0, time.UTC)
+		options.Step = 5 * time.Minute
+		result, err := engine.GetTimeSeries(ctx, options)
+		require.NoError(t, err)
+		require.Len(t, result.Points, 3)
+		var values []float64
+		for i, point := range result.Points {
+			assert.True(t, options.Start.Add(time.Duration(i)*options.Step).Equal(point.Time), "point %d", i)
+			values = append(values, point.Value)
+		}
+		return values
+	}
+
+	// Buckets start at step boundaries and empty ones are kept for plotting
+	assert.Equal(t, []float64{2, 0, 1}, series(TimeSeriesOptions{ToolName: "echo", Metric: TimeSeriesThroughput}))
+	assert.Equal(t, []float64{3, 0, 1}, series(TimeSeriesOptions{Metric: TimeSeriesThroughput}))
+	assert.Equal(t, []float64{1, 0, 0}, series(TimeSeriesOptions{ToolName: "echo", Metric: TimeSeriesErrors}))
+	assert.Equal(t, []float64{20, 0, 40}, series(TimeSeriesOptions{ToolName: "echo", Metric: TimeSeriesLatency}))
+
+	// Restricted scopes count only their tenants' executions
+	engine.SetTenantResolver(func(toolName string) string {
+		if toolName == "status" {
+			return "iam"
+		}
+		return ""
+	})
+	assert.Equal(t, []float64{2, 0, 1}, series(TimeSeriesOptions{Metric: TimeSeriesThroughput, Tenants: TenantScope{Restricted: true, Tenants: []string{"billing"}}}))
+}
+
+func TestTimeSeriesAggregates(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	engine, _, fake := newTestEngine(t, start)
+	ctx := context.Background()
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	require.NoError(t, engine.RecordExecution(ctx, "echo", "builtin", nil, nil, nil, time.Millisecond))
+	fake.Advance(31 * 24 * time.Hour)
+	run, err := engine.RunRetention(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, run.Downsampled)
+
+	// Downsampled executions count in the bucket holding their hour
+	series, err := engine.GetTimeSeries(ctx, TimeSeriesOptions{
+		ToolName: "echo",
+		Metric:   TimeSeriesThroughput,
+		Start:    start.Add(-time.Hour),
+		End:      start.Add(time.Hour),
+		Step:     time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, series.Points, 3)
+	assert.Equal(t, []float64{0, 2, 0}, []float64{series.Points[0].Value, series.Points[1].Value, series.Points[2].Value})
+}
+
+func TestTimeSeriesOptionsValidate(t *testing.T) {
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	valid := TimeSeriesOptions{Metric: TimeSeriesLatency, Start: start, End: start.Add(time.Hour), Step: time.Minute}
+	assert.NoError(t, valid.Validate())
+
+	for name, options := range map[string]TimeSeriesOptions{
+		"metric":   {Metric: "p42", Start: start, End: start.Add(time.Hour), Step: time.Minute},
+		"step":     {Metric: TimeSeriesLatency, Start: start, End: start.Add(time.Hour)},
+		"range":    {Metric: TimeSeriesLatency, Start: start, End: start.Add(-time.Hour), Step: time.Minute},
+		"too many": {Metric: TimeSeriesLatency, Start: start, End: start.Add(24 * time.Hour), Step: time.Second},
+	} {
+		assert.Error(t, options.Validate(), name)
+	}
+}
@@ -2,120 +2,54 @@ package selflearn
 
 import (
 	"time"
-)
-
-// ExecutionRecord represents a single tool execution with metadata
-type ExecutionRecord struct {
-	ID           string                 `json:"id"`
-	ToolName     string                 `json:"tool_name"`
-	Timestamp    time.Time              `json:"timestamp"`
-	Duration     time.Duration          `json:"duration"`
-	Success      bool                   `json:"success"`
-	Input        interface{}            `json:"input,omitempty"`
-	Output       interface{}            `json:"output,omitempty"`
-	Error        string                 `json:"error,omitempty"`
-	ErrorType    string                 `json:"error_type,omitempty"` // Use string for consistency with public API
-	Context      map[string]interface{} `json:"context,omitempty"`
-	RetryCount   int                    `json:"retry_count"`
-	SourceType   string                 `json:"source_type"` // openapi, graphql, asyncapi, builtin
-}
-
-// ErrorType represents the classification of errors
-type ErrorType string
-
-const (
-	ErrorTypeNetwork       ErrorType = "network"
-	ErrorTypeValidation    ErrorType = "validation"
-	ErrorTypeConfiguration ErrorType = "configuration"
-	ErrorTypePerformance   ErrorType = "performance"
-	ErrorTypeLogic         ErrorType = "logic"
-	ErrorTypeUnknown       ErrorType = "unknown"
-)
-
-// Pattern represents a detected pattern in execution data
-type Pattern struct {
-	ID          string            `json:"id"`
-	Type        PatternType       `json:"type"`
-	Description string            `json:"description"`
-	Frequency   int               `json:"frequency"`
-	Confidence  float64           `json:"confidence"`
-	FirstSeen   time.Time         `json:"first_seen"`
-	LastSeen    time.Time         `json:"last_seen"`
-	Metadata    map[string]string `json:"metadata"`
-}
-
-// PatternType represents the type of pattern detected
-type PatternType string
 
-const (
-	PatternTypeError       PatternType = "error"
-	PatternTypePerformance PatternType = "performance"
-	PatternTypeUsage       PatternType = "usage"
-	PatternTypeSuccess     PatternType = "success"
+	"github.com/aionmcp/aionmcp/pkg/learning"
 )
 
-// Insight represents a learning insight or suggestion
-type Insight struct {
-	ID          string            `json:"id"`
-	Type        InsightType       `json:"type"`
-	Priority    Priority          `json:"priority"`
-	Title       string            `json:"title"`
-	Description string            `json:"description"`
-	Suggestion  string            `json:"suggestion"`
-	Evidence    []string          `json:"evidence"`
-	CreatedAt   time.Time         `json:"created_at"`
-	Metadata    map[string]string `json:"metadata"`
-}
-
-// InsightType represents the type of insight
-type InsightType string
-
-const (
-	InsightTypeOptimization    InsightType = "optimization"
-	InsightTypeConfiguration   InsightType = "configuration"
-	InsightTypeReliability     InsightType = "reliability"
-	InsightTypePerformance     InsightType = "performance"
-	InsightTypeUsage           InsightType = "usage"
+// The learning data types are defined in the learning package, so storage
+// drivers outside this module can use them
+type (
+	ExecutionRecord = learning.ExecutionRecord
+	ErrorType       = learning.ErrorType
+	Pattern         = learning.Pattern
+	PatternType     = learning.PatternType
+	Insight         = learning.Insight
+	InsightType     = learning.InsightType
+	Priority        = learning.Priority
+	LearningStats   = learning.LearningStats
+	ToolStat        = learning.ToolStat
 )
 
-// Priority represents the priority level of an insight
-type Priority string
-
 const (
-	PriorityLow      Priority = "low"
-	PriorityMedium   Priority = "medium"
-	PriorityHigh     Priority = "high"
-	PriorityCritical Priority = "critical"
+	ErrorTypeNetwork       = learning.ErrorTypeNetwork
+	ErrorTypeValidation    = learning.ErrorTypeValidation
+	ErrorTypeConfiguration = learning.ErrorTypeConfiguration
+	ErrorTypePerformance   = learning.ErrorTypePerformance
+	ErrorTypeLogic         = learning.ErrorTypeLogic
+	ErrorTypeUnknown       = learning.ErrorTypeUnknown
+
+	PatternTypeError       = learning.PatternTypeError
+	PatternTypePerformance = learning.PatternTypePerformance
+	PatternTypeUsage       = learning.PatternTypeUsage
+	PatternTypeSuccess     = learning.PatternTypeSuccess
+
+	InsightTypeOptimization  = learning.InsightTypeOptimization
+	InsightTypeConfiguration = learning.InsightTypeConfiguration
+	InsightTypeReliability   = learning.InsightTypeReliability
+	InsightTypePerformance   = learning.InsightTypePerformance
+	InsightTypeUsage         = learning.InsightTypeUsage
+
+	PriorityLow      = learning.PriorityLow
+	PriorityMedium   = learning.PriorityMedium
+	PriorityHigh     = learning.PriorityHigh
+	PriorityCritical = learning.PriorityCritical
 )
 
-// LearningStats represents overall learning statistics
-type LearningStats struct {
-	TotalExecutions   int64          `json:"total_executions"`
-	SuccessRate       float64        `json:"success_rate"`
-	AverageLatency    time.Duration  `json:"average_latency"`
-	ErrorBreakdown    map[string]int `json:"error_breakdown"` // Use string for error types
-	TopTools          []ToolStat     `json:"top_tools"`
-	RecentPatterns    []Pattern      `json:"recent_patterns"`
-	ActiveInsights    []Insight      `json:"active_insights"`
-	LastUpdated       time.Time      `json:"last_updated"`
-}
-
-// ToolStat represents statistics for a specific tool
-type ToolStat struct {
-	Name           string        `json:"name"`
-	ExecutionCount int64         `json:"execution_count"`
-	SuccessCount   int64         `json:"success_count"`   // Track successes separately
-	FailureCount   int64         `json:"failure_count"`   // Track failures separately
-	SuccessRate    float64       `json:"success_rate"`
-	AverageLatency time.Duration `json:"average_latency"`
-	FirstUsed      time.Time     `json:"first_used"`
-	LastUsed       time.Time     `json:"last_used"`
-}
-
 // CollectionConfig represents configuration for feedback collection
 type CollectionConfig struct {
 	Enabled              bool          `json:"enabled"`
 	SampleRate           float64       `json:"sample_rate"`           // 0.0 to 1.0
+	AlwaysSampleErrors   bool          `json:"always_sample_errors"`  // record failed executions whatever the sample rate
 	MaxInputSize         int           `json:"max_input_size"`        // bytes
 	MaxOutputSize        int           `json:"max_output_size"`       // bytes
 	RetentionPeriod      time.Duration `json:"retention_period"`     // how long to keep records
@@ -130,6 +64,7 @@ func DefaultCollectionConfig() CollectionConfig {
 	return CollectionConfig{
 		Enabled:              true,
 		SampleRate:           1.0, // collect all executions by default
+		AlwaysSampleErrors:   true,
 		MaxInputSize:         1024,
 		MaxOutputSize:        4096,
 		RetentionPeriod:      30 * 24 * time.Hour, // 30 days
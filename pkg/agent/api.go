@@ -2,21 +2,30 @@ package agent
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
 	"github.com/aionmcp/aionmcp/pkg/types"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
-// AgentAPI provides REST endpoints for agent integration
+// AgentAPI provides REST endpoints for agent integration. Endpoints backed
+// by an agent RPC are transcoded by the gateway, so they take and return the
+// RPC's messages.
 type AgentAPI struct {
 	logger      *zap.Logger
 	registry    types.ToolRegistry
 	agentServer *AgentServer
+	gateway     *Gateway
 }
 
 // NewAgentAPI creates a new AgentAPI instance
@@ -25,192 +34,124 @@ func NewAgentAPI(logger *zap.Logger, registry types.ToolRegistry, agentServer *A
 		logger:      logger,
 		registry:    registry,
 		agentServer: agentServer,
+		gateway:     NewGateway(logger, agentServer),
 	}
 }
 
+// Gateway returns the gateway serving the API's RPC-backed endpoints
+func (api *AgentAPI) Gateway() *Gateway {
+	return api.gateway
+}
+
 // RegisterRoutes adds agent API routes to the gin router
 func (api *AgentAPI) RegisterRoutes(router *gin.RouterGroup) {
 	agents := router.Group("/agents")
 
 	// Agent session management
-	agents.POST("/register", api.registerAgent)
-	agents.DELETE("/:session_id", api.unregisterAgent)
-	agents.GET("/:session_id/status", api.getAgentStatus)
-	agents.POST("/:session_id/heartbeat", api.heartbeat)
+	agents.POST("/register", api.gateway.transcode("RegisterAgent", http.StatusCreated, nil))
+	agents.DELETE("/:session_id", api.gateway.transcode("UnregisterAgent", http.StatusOK, nil))
+	agents.GET("/:session_id/status", api.gateway.transcode("GetAgentStatus", http.StatusOK, nil))
+	agents.POST("/:session_id/heartbeat", api.gateway.transcode("HeartBeat", http.StatusOK, nil))
+	agents.GET("/:session_id/limits", api.getLimits)
+
+	// Protocol compatibility matrix
+	agents.GET("/protocols", api.getProtocols)
 
 	// Tool discovery and information
-	agents.GET("/:session_id/tools", api.listTools)
-	agents.GET("/:session_id/tools/:tool_name", api.getTool)
+	agents.GET("/:session_id/tools", api.gateway.transcode("ListTools", http.StatusOK, bindToolListing))
+	agents.GET("/:session_id/tools/:tool_name", schemaRefsMetadata, api.gateway.transcode("GetTool", http.StatusOK, bindToolSchema))
+	agents.GET("/:session_id/tools/recommendations", api.getRecommendations)
 
 	// Tool execution
 	agents.POST("/:session_id/tools/:tool_name/invoke", api.invokeTool)
 
+	// Async invocation status and cancellation
+	agents.GET("/:session_id/invocations/:invocation_id", api.getInvocation)
+	agents.DELETE("/:session_id/invocations/:invocation_id", api.cancelInvocation)
+
 	// Event subscription (WebSocket would be better, but HTTP for now)
 	agents.GET("/:session_id/events", api.getEvents)
 
+	// Long-polling fallback for clients that can use neither gRPC streams nor WebSockets
+	agents.GET("/:session_id/events/poll", api.pollEvents)
+
 	// Admin endpoints
 	admin := agents.Group("/admin")
 	admin.GET("/sessions", api.listSessions)
 	admin.GET("/metrics", api.getMetrics)
-}
-
-// RegisterAgent request/response structures
-type RegisterAgentRequest struct {
-	AgentID               string             `json:"agent_id" binding:"required"`
-	AgentName             string             `json:"agent_name" binding:"required"`
-	AgentVersion          string             `json:"agent_version"`
-	Capabilities          *AgentCapabilities `json:"capabilities"`
-	Metadata              map[string]string  `json:"metadata"`
-	SessionTimeoutSeconds int32              `json:"session_timeout_seconds"`
-}
+	admin.GET("/sessions/:session_id/tap", api.tapSession)
+	admin.PUT("/sessions/:session_id/quota", api.setSessionQuota)
+	admin.DELETE("/sessions/:session_id", api.gateway.transcode("TerminateSession", http.StatusOK, bindTerminationReason))
 
-type AgentCapabilities struct {
-	SupportedProtocols      []string `json:"supported_protocols"`
-	SupportedToolTypes      []string `json:"supported_tool_types"`
-	SupportsStreaming       bool     `json:"supports_streaming"`
-	SupportsAsyncInvocation bool     `json:"supports_async_invocation"`
-	MaxConcurrentTools      int32    `json:"max_concurrent_tools"`
-	PreferredFormats        []string `json:"preferred_formats"`
+	// Bulk session administration and drain mode for maintenance
+	admin.POST("/sessions/evict", api.evictSessions)
+	admin.POST("/sessions/notify", api.notifySessions)
+	admin.GET("/drain", api.getDrain)
+	admin.PUT("/drain", api.setDrain)
 }
 
-type RegisterAgentResponse struct {
-	SessionID      string      `json:"session_id"`
-	ExpiresAt      int64       `json:"expires_at"`
-	ServerInfo     *ServerInfo `json:"server_info"`
-	AvailableTools []ToolInfo  `json:"available_tools"`
+// InvocationStatusResponse describes an async tool invocation
+type InvocationStatusResponse struct {
+	InvocationID string         `json:"invocation_id"`
+	ToolName     string         `json:"tool_name"`
+	Status       string         `json:"status"`
+	SubmittedAt  int64          `json:"submitted_at"`
+	StartedAt    int64          `json:"started_at,omitempty"`
+	CompletedAt  int64          `json:"completed_at,omitempty"`
+	Result       map[string]any `json:"result,omitempty"` // As the invoke endpoint answers
 }
 
-type ServerInfo struct {
-	ServerVersion     string            `json:"server_version"`
-	ProtocolVersion   string            `json:"protocol_version"`
-	SupportedFeatures []string          `json:"supported_features"`
-	Capabilities      map[string]string `json:"capabilities"`
-}
-
-// Tool information structures
-type ToolInfo struct {
-	Name        string            `json:"name"`
-	DisplayName string            `json:"display_name"`
-	Description string            `json:"description"`
-	Version     string            `json:"version"`
-	Type        string            `json:"type"`
-	Status      string            `json:"status"`
-	Tags        []string          `json:"tags"`
-	Metadata    map[string]string `json:"metadata"`
-	CreatedAt   int64             `json:"created_at"`
-	UpdatedAt   int64             `json:"updated_at"`
-	Source      *ToolSource       `json:"source"`
-}
-
-type ToolSource struct {
-	SpecID      string `json:"spec_id"`
-	SpecType    string `json:"spec_type"`
-	SpecPath    string `json:"spec_path"`
-	OperationID string `json:"operation_id"`
-	QueryName   string `json:"query_name"`
-}
-
-type GetToolResponse struct {
-	Tool         ToolInfo      `json:"tool"`
-	InputSchema  interface{}   `json:"input_schema,omitempty"`
-	OutputSchema interface{}   `json:"output_schema,omitempty"`
-	Examples     []ToolExample `json:"examples,omitempty"`
-}
-
-type ToolExample struct {
-	Name           string      `json:"name"`
-	Description    string      `json:"description"`
-	Input          interface{} `json:"input"`
-	ExpectedOutput interface{} `json:"expected_output"`
-}
-
-// Tool invocation structures
-type InvokeToolRequest struct {
-	Parameters map[string]interface{} `json:"parameters"`
-	Options    *ToolInvocationOptions `json:"options"`
-}
-
-type ToolInvocationOptions struct {
-	TimeoutSeconds int32             `json:"timeout_seconds"`
-	Async          bool              `json:"async"`
-	Context        map[string]string `json:"context"`
-	RetryPolicy    *ToolRetryPolicy  `json:"retry_policy"`
-}
-
-type ToolRetryPolicy struct {
-	MaxRetries           int32   `json:"max_retries"`
-	RetryDelaySeconds    int32   `json:"retry_delay_seconds"`
-	RetryableStatusCodes []int32 `json:"retryable_status_codes"`
-}
-
-type InvokeToolResponse struct {
-	InvocationID string       `json:"invocation_id"`
-	Status       string       `json:"status"`
-	Result       interface{}  `json:"result,omitempty"`
-	Error        *ToolError   `json:"error,omitempty"`
-	Metrics      *ToolMetrics `json:"metrics"`
-	ExecutedAt   int64        `json:"executed_at"`
-}
-
-type ToolError struct {
-	Code      string      `json:"code"`
-	Message   string      `json:"message"`
-	Details   string      `json:"details"`
-	Metadata  interface{} `json:"metadata,omitempty"`
-	Retryable bool        `json:"retryable"`
-}
-
-type ToolMetrics struct {
-	ExecutionTimeMs int64              `json:"execution_time_ms"`
-	MemoryUsedBytes int64              `json:"memory_used_bytes"`
-	RetryCount      int32              `json:"retry_count"`
-	CustomMetrics   map[string]float64 `json:"custom_metrics"`
+// AgentSessionInfo describes a session to administrators
+type AgentSessionInfo struct {
+	SessionID     string                     `json:"session_id"`
+	AgentID       string                     `json:"agent_id"`
+	AgentName     string                     `json:"agent_name"`
+	AgentVersion  string                     `json:"agent_version"`
+	CreatedAt     int64                      `json:"created_at"`
+	LastHeartbeat int64                      `json:"last_heartbeat"`
+	ExpiresAt     int64                      `json:"expires_at"`
+	Status        string                     `json:"status"`
+	Capabilities  *agentpb.AgentCapabilities `json:"capabilities"`
+	CaptureLevel  string                     `json:"capture_level"`
+	Protocol      string                     `json:"protocol_version"`
+	Identity      *Identity                  `json:"identity,omitempty"`
+	ToolScopes    []ToolScope                `json:"tool_scopes,omitempty"`
 }
 
-// Session status structures
-type AgentStatusResponse struct {
-	SessionInfo     *AgentSessionInfo `json:"session_info"`
-	Metrics         *AgentMetrics     `json:"metrics"`
-	RecentToolUsage []ToolUsageInfo   `json:"recent_tool_usage"`
+// SessionFilterRequest selects sessions for a bulk action. An empty filter
+// must set All to act on every session.
+type SessionFilterRequest struct {
+	SessionIDs   []string `json:"session_ids"`
+	AgentID      string   `json:"agent_id"`
+	AgentVersion string   `json:"agent_version"`
+	IdleSeconds  int64    `json:"idle_seconds"` // Only sessions without a heartbeat for this long
+	All          bool     `json:"all"`
 }
 
-type AgentSessionInfo struct {
-	SessionID     string             `json:"session_id"`
-	AgentID       string             `json:"agent_id"`
-	AgentName     string             `json:"agent_name"`
-	AgentVersion  string             `json:"agent_version"`
-	CreatedAt     int64              `json:"created_at"`
-	LastHeartbeat int64              `json:"last_heartbeat"`
-	ExpiresAt     int64              `json:"expires_at"`
-	Status        string             `json:"status"`
-	Capabilities  *AgentCapabilities `json:"capabilities"`
+type EvictSessionsRequest struct {
+	SessionFilterRequest
+	Reason string `json:"reason"`
 }
 
-type AgentMetrics struct {
-	TotalInvocations      int64            `json:"total_invocations"`
-	SuccessfulInvocations int64            `json:"successful_invocations"`
-	FailedInvocations     int64            `json:"failed_invocations"`
-	AverageResponseTimeMs float64          `json:"average_response_time_ms"`
-	LastInvocation        int64            `json:"last_invocation"`
-	ToolUsageCount        map[string]int64 `json:"tool_usage_count"`
+type NotifySessionsRequest struct {
+	SessionFilterRequest
+	Message string `json:"message" binding:"required"`
 }
 
-type ToolUsageInfo struct {
-	ToolName        string `json:"tool_name"`
-	InvokedAt       int64  `json:"invoked_at"`
-	Status          string `json:"status"`
-	ExecutionTimeMs int64  `json:"execution_time_ms"`
-	ErrorMessage    string `json:"error_message,omitempty"`
+type BulkSessionsResponse struct {
+	SessionIDs []string `json:"session_ids"`
+	Count      int      `json:"count"`
 }
 
-type HeartbeatRequest struct {
-	Status string `json:"status"`
+type SetDrainRequest struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
 }
 
-type HeartbeatResponse struct {
-	SessionValid         bool     `json:"session_valid"`
-	NextHeartbeatAt      int64    `json:"next_heartbeat_at"`
-	PendingNotifications []string `json:"pending_notifications"`
+// SetSessionQuotaRequest replaces the daily quota of a session; a null quota
+// restores the configured default
+type SetSessionQuotaRequest struct {
+	Quota *DailyQuota `json:"quota"`
 }
 
 // Event structures
@@ -220,10 +161,13 @@ type Event struct {
 	Timestamp int64       `json:"timestamp"`
 	SessionID string      `json:"session_id"`
 	Data      interface{} `json:"data"`
+	Sequence  uint64      `json:"sequence"` // Position in the event log
 }
 
 type GetEventsResponse struct {
 	Events []Event `json:"events"`
+	Cursor string  `json:"cursor"`           // Pass as the cursor of the next request
+	Missed bool    `json:"missed,omitempty"` // Events after the given cursor were evicted
 }
 
 // Admin structures
@@ -237,104 +181,29 @@ type MetricsResponse struct {
 	TotalInvocations int64                  `json:"total_invocations"`
 	ToolUsageStats   map[string]int64       `json:"tool_usage_stats"`
 	SessionMetrics   map[string]interface{} `json:"session_metrics"`
-}
 
-// registerAgent handles agent registration
-func (api *AgentAPI) registerAgent(c *gin.Context) {
-	var req RegisterAgentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Convert to gRPC request
-	grpcReq := &agentpb.RegisterAgentRequest{
-		AgentId:               req.AgentID,
-		AgentName:             req.AgentName,
-		AgentVersion:          req.AgentVersion,
-		SessionTimeoutSeconds: req.SessionTimeoutSeconds,
-		Metadata:              req.Metadata,
-	}
-
-	if req.Capabilities != nil {
-		grpcReq.Capabilities = &agentpb.AgentCapabilities{
-			SupportedProtocols:      req.Capabilities.SupportedProtocols,
-			SupportedToolTypes:      req.Capabilities.SupportedToolTypes,
-			SupportsStreaming:       req.Capabilities.SupportsStreaming,
-			SupportsAsyncInvocation: req.Capabilities.SupportsAsyncInvocation,
-			MaxConcurrentTools:      req.Capabilities.MaxConcurrentTools,
-			PreferredFormats:        req.Capabilities.PreferredFormats,
-		}
-	}
-
-	// Call gRPC method
-	grpcResp, err := api.agentServer.RegisterAgent(c.Request.Context(), grpcReq)
-	if err != nil {
-		api.logger.Error("Failed to register agent", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Convert response
-	resp := RegisterAgentResponse{
-		SessionID: grpcResp.SessionId,
-		ExpiresAt: grpcResp.ExpiresAtUnix,
-		ServerInfo: &ServerInfo{
-			ServerVersion:     grpcResp.ServerInfo.ServerVersion,
-			ProtocolVersion:   grpcResp.ServerInfo.ProtocolVersion,
-			SupportedFeatures: grpcResp.ServerInfo.SupportedFeatures,
-			Capabilities:      grpcResp.ServerInfo.Capabilities,
-		},
-		AvailableTools: make([]ToolInfo, len(grpcResp.AvailableTools)),
-	}
-
-	for i, tool := range grpcResp.AvailableTools {
-		resp.AvailableTools[i] = api.convertToolInfo(tool)
-	}
-
-	api.logger.Info("Agent registered via REST API",
-		zap.String("agent_id", req.AgentID),
-		zap.String("session_id", resp.SessionID))
-
-	c.JSON(http.StatusCreated, resp)
+	// Circuit breakers that are open or counting failures, by tool name
+	CircuitBreakers map[string]*types.CircuitStatus `json:"circuit_breakers"`
 }
 
-// unregisterAgent handles agent unregistration
-func (api *AgentAPI) unregisterAgent(c *gin.Context) {
-	sessionID := c.Param("session_id")
-
-	grpcReq := &agentpb.UnregisterAgentRequest{
-		SessionId: sessionID,
-	}
-
-	grpcResp, err := api.agentServer.UnregisterAgent(c.Request.Context(), grpcReq)
-	if err != nil {
-		api.logger.Error("Failed to unregister agent", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	api.logger.Info("Agent unregistered via REST API", zap.String("session_id", sessionID))
-
+// getProtocols reports the agent protocol versions the server implements and
+// which it accepts
+func (api *AgentAPI) getProtocols(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"success": grpcResp.Success,
-		"message": grpcResp.Message,
+		"current":   ProtocolVersion,
+		"protocols": api.agentServer.config.Protocols.ProtocolMatrix(),
 	})
 }
 
-// listTools handles tool listing for an agent
-func (api *AgentAPI) listTools(c *gin.Context) {
-	sessionID := c.Param("session_id")
-
-	// Parse query parameters for filtering and pagination
-	grpcReq := &agentpb.ListToolsRequest{
-		SessionId: sessionID,
-	}
+// bindToolListing reads the pagination and filter of a tool listing from
+// query parameters
+func bindToolListing(c *gin.Context, request proto.Message) error {
+	req := request.(*agentpb.ListToolsRequest)
 
 	// Add basic pagination if requested
 	if pageStr := c.Query("page"); pageStr != "" {
 		if page, err := strconv.Atoi(pageStr); err == nil {
-			grpcReq.Pagination = &agentpb.PaginationOptions{
+			req.Pagination = &agentpb.PaginationOptions{
 				Page: int32(page),
 			}
 		}
@@ -342,190 +211,210 @@ func (api *AgentAPI) listTools(c *gin.Context) {
 
 	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
 		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil {
-			if grpcReq.Pagination == nil {
-				grpcReq.Pagination = &agentpb.PaginationOptions{}
+			if req.Pagination == nil {
+				req.Pagination = &agentpb.PaginationOptions{}
 			}
-			grpcReq.Pagination.PageSize = int32(pageSize)
+			req.Pagination.PageSize = int32(pageSize)
 		}
 	}
 
-	grpcResp, err := api.agentServer.ListTools(c.Request.Context(), grpcReq)
-	if err != nil {
-		api.logger.Error("Failed to list tools", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	// Sorting and cursors paginate too
+	if sortBy, cursor := c.Query("sort_by"), c.Query("cursor"); sortBy != "" || cursor != "" {
+		if req.Pagination == nil {
+			req.Pagination = &agentpb.PaginationOptions{}
+		}
+		req.Pagination.SortBy = sortBy
+		req.Pagination.SortDesc = c.Query("sort_desc") == "true"
+		req.Pagination.Cursor = cursor
 	}
 
-	tools := make([]ToolInfo, len(grpcResp.Tools))
-	for i, tool := range grpcResp.Tools {
-		tools[i] = api.convertToolInfo(tool)
+	filter, err := parseToolFilter(c)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	req.Filter = filter
+	return nil
+}
+
+// parseToolFilter reads a tool filter from query parameters: tag, source,
+// type and status may be repeated, types and statuses are given as listed
+// (TOOL_TYPE_OPENAPI) or short (openapi), name is a glob, and created_after
+// and updated_after are Unix timestamps.
+func parseToolFilter(c *gin.Context) (*agentpb.ToolFilter, error) {
+	filter := &agentpb.ToolFilter{
+		Tags:        c.QueryArray("tag"),
+		Sources:     c.QueryArray("source"),
+		NamePattern: c.Query("name"),
+	}
+	for _, name := range c.QueryArray("type") {
+		value, ok := agentpb.ToolType_value[enumName("TOOL_TYPE_", name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid tool type %q", name)
+		}
+		filter.Types = append(filter.Types, agentpb.ToolType(value))
 	}
+	for _, name := range c.QueryArray("status") {
+		value, ok := agentpb.ToolStatus_value[enumName("TOOL_STATUS_", name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid tool status %q", name)
+		}
+		filter.Statuses = append(filter.Statuses, agentpb.ToolStatus(value))
+	}
+	for name, bound := range map[string]*int64{"created_after": &filter.CreatedAfterUnix, "updated_after": &filter.UpdatedAfterUnix} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a Unix timestamp", name)
+			}
+			*bound = parsed
+		}
+	}
+	return filter, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"tools":       tools,
-		"total_count": grpcResp.TotalCount,
-		"pagination":  grpcResp.Pagination,
-	})
+// enumName expands a short enum value name such as "openapi" to its full name
+func enumName(prefix, name string) string {
+	name = strings.ToUpper(name)
+	if strings.HasPrefix(name, prefix) {
+		return name
+	}
+	return prefix + name
 }
 
-// getTool handles getting detailed tool information
-func (api *AgentAPI) getTool(c *gin.Context) {
-	sessionID := c.Param("session_id")
-	toolName := c.Param("tool_name")
-	includeSchema := c.Query("include_schema") == "true"
+// schemaRefsMetadata passes the schema_refs query parameter of a tool lookup
+// to GetTool as metadata
+func schemaRefsMetadata(c *gin.Context) {
+	if refMode := c.Query("schema_refs"); refMode != "" {
+		c.Request.Header.Set(SchemaRefsMetadataKey, refMode)
+	}
+}
 
-	grpcReq := &agentpb.GetToolRequest{
-		SessionId:     sessionID,
-		ToolName:      toolName,
-		IncludeSchema: includeSchema,
+// bindToolSchema reads include_schema from the query of a tool lookup
+func bindToolSchema(c *gin.Context, request proto.Message) error {
+	if c.Query("include_schema") == "true" {
+		request.(*agentpb.GetToolRequest).IncludeSchema = true
 	}
+	return nil
+}
 
-	grpcResp, err := api.agentServer.GetTool(c.Request.Context(), grpcReq)
+// invokeTool handles tool execution. The request body is an InvokeTool
+// request; the response is encoded as the client's Accept header asks.
+func (api *AgentAPI) invokeTool(c *gin.Context) {
+	response, err := api.gateway.call(c, "InvokeTool", nil)
 	if err != nil {
-		api.logger.Error("Failed to get tool", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		api.gateway.writeError(c, err)
 		return
 	}
+	grpcResp := response.(*agentpb.InvokeToolResponse)
 
-	resp := GetToolResponse{
-		Tool: api.convertToolInfo(grpcResp.Tool),
-	}
+	format := api.negotiateResponseFormat(c)
+	api.setDeprecationHeaders(c, c.Param("tool_name"))
 
-	if includeSchema {
-		// Parse JSON schema strings back to objects
-		// Parse input schema if available
-		if grpcResp.InputSchemaJson != "" {
-			var inputSchema map[string]interface{}
-			if err := json.Unmarshal([]byte(grpcResp.InputSchemaJson), &inputSchema); err != nil {
-				api.logger.Warn("Failed to parse input schema JSON",
-					zap.String("schema", grpcResp.InputSchemaJson),
-					zap.Error(err))
-				// Fallback to placeholder
-				resp.InputSchema = map[string]interface{}{"type": "object"}
-			} else {
-				resp.InputSchema = inputSchema
+	statusCode := http.StatusOK
+	switch grpcResp.Status {
+	case agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED:
+		statusCode = http.StatusInternalServerError
+		if grpcResp.Error.GetCode() == agentpb.ErrorCode_ERROR_CODE_INVALID_PARAMETERS {
+			statusCode = http.StatusBadRequest
+		} else if grpcResp.Error.GetCode() == agentpb.ErrorCode_ERROR_CODE_TIMEOUT {
+			statusCode = http.StatusGatewayTimeout
+		} else if hint, ok := retryHint(grpcResp.Error); ok {
+			statusCode = http.StatusServiceUnavailable
+			if grpcResp.Error.Code == agentpb.ErrorCode_ERROR_CODE_RATE_LIMITED {
+				statusCode = http.StatusTooManyRequests
 			}
-		} else {
-			resp.InputSchema = map[string]interface{}{"type": "object"}
-		}
-
-		// Parse output schema if available
-		if grpcResp.OutputSchemaJson != "" {
-			var outputSchema map[string]interface{}
-			if err := json.Unmarshal([]byte(grpcResp.OutputSchemaJson), &outputSchema); err != nil {
-				api.logger.Warn("Failed to parse output schema JSON",
-					zap.String("schema", grpcResp.OutputSchemaJson),
-					zap.Error(err))
-				// Fallback to placeholder
-				resp.OutputSchema = map[string]interface{}{"type": "object"}
-			} else {
-				resp.OutputSchema = outputSchema
+			if hint.RetryAfterMs > 0 {
+				c.Header("Retry-After", strconv.FormatInt((hint.RetryAfterMs+999)/1000, 10))
 			}
-		} else {
-			resp.OutputSchema = map[string]interface{}{"type": "object"}
 		}
+	case agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_PENDING:
+		statusCode = http.StatusAccepted
+	}
 
-		resp.Examples = make([]ToolExample, len(grpcResp.Examples))
-		for i, example := range grpcResp.Examples {
-			var inputMap map[string]interface{}
-			var outputMap map[string]interface{}
-
-			// Parse example input JSON
-			if example.InputJson != "" {
-				if err := json.Unmarshal([]byte(example.InputJson), &inputMap); err != nil {
-					api.logger.Warn("Failed to parse example input JSON",
-						zap.String("input", example.InputJson),
-						zap.Error(err))
-					inputMap = map[string]interface{}{}
-				}
-			} else {
-				inputMap = map[string]interface{}{}
-			}
-
-			// Parse example expected output JSON
-			if example.ExpectedOutputJson != "" {
-				if err := json.Unmarshal([]byte(example.ExpectedOutputJson), &outputMap); err != nil {
-					api.logger.Warn("Failed to parse example expected output JSON",
-						zap.String("expected_output", example.ExpectedOutputJson),
-						zap.Error(err))
-					outputMap = map[string]interface{}{}
-				}
-			} else {
-				outputMap = map[string]interface{}{}
-			}
+	api.render(c, statusCode, format, api.invocationBody(grpcResp, format))
+}
 
-			resp.Examples[i] = ToolExample{
-				Name:           example.Name,
-				Description:    example.Description,
-				Input:          inputMap,
-				ExpectedOutput: outputMap,
-			}
-		}
+// getInvocation handles polling the status of an async invocation
+func (api *AgentAPI) getInvocation(c *gin.Context) {
+	job, err := api.agentServer.GetInvocation(c.Param("session_id"), c.Param("invocation_id"))
+	if err != nil {
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	format := api.negotiateResponseFormat(c)
+	api.render(c, http.StatusOK, format, api.convertInvocationJob(job, format))
 }
 
-// invokeTool handles tool execution
-func (api *AgentAPI) invokeTool(c *gin.Context) {
-	sessionID := c.Param("session_id")
-	toolName := c.Param("tool_name")
-
-	var req InvokeToolRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// cancelInvocation handles cancelling a pending or running async invocation
+func (api *AgentAPI) cancelInvocation(c *gin.Context) {
+	job, err := api.agentServer.CancelInvocation(c.Param("session_id"), c.Param("invocation_id"))
+	if err != nil {
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	invocationID := uuid.New().String()
+	format := api.negotiateResponseFormat(c)
+	api.render(c, http.StatusOK, format, api.convertInvocationJob(job, format))
+}
 
-	// Serialize parameters to JSON
-	parametersJSON := "{}"
-	if req.Parameters != nil {
-		paramsBytes, err := json.Marshal(req.Parameters)
-		if err != nil {
-			api.logger.Error("Failed to marshal parameters", zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters format"})
-			return
-		}
-		parametersJSON = string(paramsBytes)
+// convertInvocationJob converts an async invocation snapshot to its REST form,
+// to be rendered in format
+func (api *AgentAPI) convertInvocationJob(job InvocationJob, format ResultFormat) InvocationStatusResponse {
+	resp := InvocationStatusResponse{
+		InvocationID: job.InvocationID,
+		ToolName:     job.ToolName,
+		Status:       job.Status.String(),
+		SubmittedAt:  job.SubmittedAt.Unix(),
 	}
-
-	// Convert to gRPC request
-	grpcReq := &agentpb.InvokeToolRequest{
-		SessionId:      sessionID,
-		ToolName:       toolName,
-		InvocationId:   invocationID,
-		ParametersJson: parametersJSON,
+	if !job.StartedAt.IsZero() {
+		resp.StartedAt = job.StartedAt.Unix()
 	}
-
-	if req.Options != nil {
-		grpcReq.Options = &agentpb.ToolInvocationOptions{
-			TimeoutSeconds: req.Options.TimeoutSeconds,
-			Async:          req.Options.Async,
-			Context:        req.Options.Context,
-		}
-
-		if req.Options.RetryPolicy != nil {
-			grpcReq.Options.RetryPolicy = &agentpb.ToolRetryPolicy{
-				MaxRetries:           req.Options.RetryPolicy.MaxRetries,
-				RetryDelaySeconds:    req.Options.RetryPolicy.RetryDelaySeconds,
-				RetryableStatusCodes: req.Options.RetryPolicy.RetryableStatusCodes,
-			}
-		}
+	if !job.CompletedAt.IsZero() {
+		resp.CompletedAt = job.CompletedAt.Unix()
 	}
+	if job.Response != nil {
+		resp.Result = api.invocationBody(job.Response, format)
+	}
+	return resp
+}
 
-	grpcResp, err := api.agentServer.InvokeTool(c.Request.Context(), grpcReq)
+// invocationBody is the REST form of an invocation response: the message as
+// the gateway encodes it, with result_json and result_data replaced by the
+// decoded result, to be rendered in format
+func (api *AgentAPI) invocationBody(grpcResp *agentpb.InvokeToolResponse, format ResultFormat) map[string]any {
+	body := map[string]any{}
+	encoded, err := api.gateway.encode(grpcResp)
+	if err == nil {
+		err = json.Unmarshal(encoded, &body)
+	}
 	if err != nil {
-		api.logger.Error("Failed to invoke tool", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		api.logger.Error("Failed to encode invocation response", zap.Error(err))
 	}
+	delete(body, "result_json")
+	delete(body, "result_data")
+	body["result"] = api.decodeResult(grpcResp)
+	body["result_format"] = format.Name()
+	return body
+}
 
-	resp := InvokeToolResponse{
-		InvocationID: grpcResp.InvocationId,
-		Status:       grpcResp.Status.String(),
-		ExecutedAt:   grpcResp.ExecutedAtUnix,
+// decodeResult decodes the result of an invocation from the format the
+// session receives results in
+func (api *AgentAPI) decodeResult(grpcResp *agentpb.InvokeToolResponse) interface{} {
+	// Decode results the session received in a binary format
+	if len(grpcResp.ResultData) > 0 {
+		var result interface{}
+		format, ok := api.agentServer.ResultFormat(grpcResp.ResultFormat)
+		if !ok {
+			return map[string]interface{}{"_error": "Unsupported result format " + grpcResp.ResultFormat}
+		}
+		if err := format.Unmarshal(grpcResp.ResultData, &result); err != nil {
+			api.logger.Error("Failed to decode tool result",
+				zap.Error(err),
+				zap.String("result_format", grpcResp.ResultFormat))
+			return map[string]interface{}{"_error": "Failed to decode result " + grpcResp.ResultFormat}
+		}
+		return result
 	}
 
 	// Parse result from JSON
@@ -535,165 +424,91 @@ func (api *AgentAPI) invokeTool(c *gin.Context) {
 			api.logger.Error("Failed to parse tool result JSON",
 				zap.Error(err),
 				zap.String("result_json", grpcResp.ResultJson))
-			resp.Result = map[string]interface{}{"_error": "Failed to parse result JSON"}
-		} else {
-			resp.Result = result
-		}
-	}
-
-	if grpcResp.Error != nil {
-		resp.Error = &ToolError{
-			Code:      grpcResp.Error.Code.String(),
-			Message:   grpcResp.Error.Message,
-			Details:   grpcResp.Error.Details,
-			Retryable: grpcResp.Error.Retryable,
+			return map[string]interface{}{"_error": "Failed to parse result JSON"}
 		}
+		return result
 	}
-
-	if grpcResp.Metrics != nil {
-		resp.Metrics = &ToolMetrics{
-			ExecutionTimeMs: grpcResp.Metrics.ExecutionTimeMs,
-			MemoryUsedBytes: grpcResp.Metrics.MemoryUsedBytes,
-			RetryCount:      grpcResp.Metrics.RetryCount,
-			CustomMetrics:   grpcResp.Metrics.CustomMetrics,
-		}
-	}
-
-	statusCode := http.StatusOK
-	if grpcResp.Status == agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED {
-		statusCode = http.StatusInternalServerError
-	}
-
-	api.logger.Info("Tool invoked via REST API",
-		zap.String("session_id", sessionID),
-		zap.String("tool_name", toolName),
-		zap.String("invocation_id", invocationID),
-		zap.String("status", resp.Status))
-
-	c.JSON(statusCode, resp)
+	return nil
 }
 
-// getAgentStatus handles getting agent session status
-func (api *AgentAPI) getAgentStatus(c *gin.Context) {
+// getLimits handles getting the session's remaining limits
+func (api *AgentAPI) getLimits(c *gin.Context) {
 	sessionID := c.Param("session_id")
 
-	grpcReq := &agentpb.GetAgentStatusRequest{
-		SessionId: sessionID,
-	}
-
-	grpcResp, err := api.agentServer.GetAgentStatus(c.Request.Context(), grpcReq)
+	limits, err := api.agentServer.GetSessionLimits(sessionID)
 	if err != nil {
-		api.logger.Error("Failed to get agent status", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := AgentStatusResponse{
-		SessionInfo: &AgentSessionInfo{
-			SessionID:     grpcResp.SessionInfo.SessionId,
-			AgentID:       grpcResp.SessionInfo.AgentId,
-			AgentName:     grpcResp.SessionInfo.AgentName,
-			AgentVersion:  grpcResp.SessionInfo.AgentVersion,
-			CreatedAt:     grpcResp.SessionInfo.CreatedAtUnix,
-			LastHeartbeat: grpcResp.SessionInfo.LastHeartbeatUnix,
-			ExpiresAt:     grpcResp.SessionInfo.ExpiresAtUnix,
-			Status:        grpcResp.SessionInfo.Status.String(),
-		},
-		Metrics: &AgentMetrics{
-			TotalInvocations:      grpcResp.Metrics.TotalInvocations,
-			SuccessfulInvocations: grpcResp.Metrics.SuccessfulInvocations,
-			FailedInvocations:     grpcResp.Metrics.FailedInvocations,
-			AverageResponseTimeMs: grpcResp.Metrics.AverageResponseTimeMs,
-			LastInvocation:        grpcResp.Metrics.LastInvocationUnix,
-			ToolUsageCount:        grpcResp.Metrics.ToolUsageCount,
-		},
-		RecentToolUsage: make([]ToolUsageInfo, len(grpcResp.RecentToolUsage)),
-	}
-
-	if grpcResp.SessionInfo.Capabilities != nil {
-		resp.SessionInfo.Capabilities = &AgentCapabilities{
-			SupportedProtocols:      grpcResp.SessionInfo.Capabilities.SupportedProtocols,
-			SupportedToolTypes:      grpcResp.SessionInfo.Capabilities.SupportedToolTypes,
-			SupportsStreaming:       grpcResp.SessionInfo.Capabilities.SupportsStreaming,
-			SupportsAsyncInvocation: grpcResp.SessionInfo.Capabilities.SupportsAsyncInvocation,
-			MaxConcurrentTools:      grpcResp.SessionInfo.Capabilities.MaxConcurrentTools,
-			PreferredFormats:        grpcResp.SessionInfo.Capabilities.PreferredFormats,
-		}
-	}
-
-	for i, usage := range grpcResp.RecentToolUsage {
-		resp.RecentToolUsage[i] = ToolUsageInfo{
-			ToolName:        usage.ToolName,
-			InvokedAt:       usage.InvokedAtUnix,
-			Status:          usage.Status.String(),
-			ExecutionTimeMs: usage.ExecutionTimeMs,
-			ErrorMessage:    usage.ErrorMessage,
-		}
-	}
-
-	c.JSON(http.StatusOK, resp)
+	api.gateway.writeMessage(c, http.StatusOK, limits)
 }
 
-// heartbeat handles agent heartbeat
-func (api *AgentAPI) heartbeat(c *gin.Context) {
-	sessionID := c.Param("session_id")
+// getEvents handles getting the retained events after a cursor
+func (api *AgentAPI) getEvents(c *gin.Context) {
+	// Return the retained events after the cursor, from the oldest by
+	// default, without waiting
+	api.respondEvents(c, eventCursor(c, "0"), 0)
+}
 
-	var req HeartbeatRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+// eventCursor returns the sequence after which a client wants events, given
+// as since or cursor
+func eventCursor(c *gin.Context, defaultCursor string) string {
+	if since := c.Query("since"); since != "" {
+		return since
 	}
+	return c.DefaultQuery("cursor", defaultCursor)
+}
 
-	status := agentpb.AgentStatus_AGENT_STATUS_ACTIVE
-	if req.Status != "" {
-		// Would parse status string to enum
-		switch req.Status {
-		case "idle":
-			status = agentpb.AgentStatus_AGENT_STATUS_IDLE
-		case "busy":
-			status = agentpb.AgentStatus_AGENT_STATUS_BUSY
-		case "error":
-			status = agentpb.AgentStatus_AGENT_STATUS_ERROR
+// pollEvents handles long-polling for events: the request is held until an
+// event arrives after the cursor or the wait elapses
+func (api *AgentAPI) pollEvents(c *gin.Context) {
+	wait := DefaultPollWait
+	if value := c.Query("wait"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			// Plain numbers are seconds
+			seconds, convErr := strconv.Atoi(value)
+			if convErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid wait %q", value)})
+				return
+			}
+			parsed = time.Duration(seconds) * time.Second
 		}
+		wait = parsed
 	}
-
-	grpcReq := &agentpb.HeartBeatRequest{
-		SessionId: sessionID,
-		Status:    status,
-	}
-
-	grpcResp, err := api.agentServer.HeartBeat(c.Request.Context(), grpcReq)
-	if err != nil {
-		api.logger.Error("Failed to process heartbeat", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	resp := HeartbeatResponse{
-		SessionValid:         grpcResp.SessionValid,
-		NextHeartbeatAt:      grpcResp.NextHeartbeatAtUnix,
-		PendingNotifications: grpcResp.PendingNotifications,
-	}
-
-	c.JSON(http.StatusOK, resp)
+	api.respondEvents(c, eventCursor(c, ""), wait)
 }
 
-// getEvents handles getting recent events (placeholder for real-time events)
-func (api *AgentAPI) getEvents(c *gin.Context) {
-	sessionID := c.Param("session_id")
-
-	// Validate session exists
-	if _, exists := api.agentServer.getSession(sessionID); !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid session"})
+// respondEvents writes the events after a cursor, waiting up to wait for
+// some to arrive
+func (api *AgentAPI) respondEvents(c *gin.Context, cursor string, wait time.Duration) {
+	poll, err := api.agentServer.PollEvents(c.Request.Context(), c.Param("session_id"), cursor, wait)
+	if err != nil {
+		c.JSON(httpStatusFromError(err), gin.H{"error": status.Convert(err).Message()})
 		return
 	}
 
-	// Placeholder - in a real implementation, this would return recent events
-	// For now, return empty events list
 	resp := GetEventsResponse{
-		Events: []Event{},
-	}
-
+		Events: make([]Event, 0, len(poll.Events)),
+		Cursor: poll.Cursor,
+		Missed: poll.Missed,
+	}
+	for _, event := range poll.Events {
+		var data interface{} = event.DataJson
+		if json.Valid([]byte(event.DataJson)) {
+			data = json.RawMessage(event.DataJson)
+		}
+		resp.Events = append(resp.Events, Event{
+			EventID:   event.EventId,
+			Type:      event.Type.String(),
+			Timestamp: event.TimestampUnix,
+			SessionID: event.SessionId,
+			Data:      data,
+			Sequence:  event.Sequence,
+		})
+	}
+	c.Header("Cache-Control", "no-store")
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -712,17 +527,11 @@ func (api *AgentAPI) listSessions(c *gin.Context) {
 			LastHeartbeat: session.LastHeartbeat.Unix(),
 			ExpiresAt:     session.ExpiresAt.Unix(),
 			Status:        session.Status.String(),
-		}
-
-		if session.Capabilities != nil {
-			sessionInfo.Capabilities = &AgentCapabilities{
-				SupportedProtocols:      session.Capabilities.SupportedProtocols,
-				SupportedToolTypes:      session.Capabilities.SupportedToolTypes,
-				SupportsStreaming:       session.Capabilities.SupportsStreaming,
-				SupportsAsyncInvocation: session.Capabilities.SupportsAsyncInvocation,
-				MaxConcurrentTools:      session.Capabilities.MaxConcurrentTools,
-				PreferredFormats:        session.Capabilities.PreferredFormats,
-			}
+			CaptureLevel:  string(session.CaptureLevel),
+			Protocol:      session.Protocol.String(),
+			Capabilities:  session.Capabilities,
+			Identity:      session.Identity,
+			ToolScopes:    session.ToolScopes,
 		}
 
 		sessions = append(sessions, sessionInfo)
@@ -736,6 +545,166 @@ func (api *AgentAPI) listSessions(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// sessionFilter converts a bulk action's filter, refusing empty filters
+// that do not explicitly select every session
+func sessionFilter(req SessionFilterRequest) (SessionFilter, error) {
+	if req.IdleSeconds < 0 {
+		return SessionFilter{}, fmt.Errorf("idle_seconds must not be negative")
+	}
+	filter := SessionFilter{
+		SessionIDs:   req.SessionIDs,
+		AgentID:      req.AgentID,
+		AgentVersion: req.AgentVersion,
+		IdleFor:      time.Duration(req.IdleSeconds) * time.Second,
+	}
+	if filter.IsEmpty() && !req.All {
+		return SessionFilter{}, fmt.Errorf("set session_ids, agent_id, agent_version or idle_seconds, or all to select every session")
+	}
+	return filter, nil
+}
+
+// evictSessions handles unregistering every session matching a filter (admin)
+func (api *AgentAPI) evictSessions(c *gin.Context) {
+	var req EvictSessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, err := sessionFilter(req.SessionFilterRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	evicted := api.agentServer.EvictSessions(c.Request.Context(), filter, req.Reason)
+	c.JSON(http.StatusOK, BulkSessionsResponse{SessionIDs: evicted, Count: len(evicted)})
+}
+
+// bindTerminationReason reads the optional reason of a session termination
+// from the query
+func bindTerminationReason(c *gin.Context, request proto.Message) error {
+	if reason := c.Query("reason"); reason != "" {
+		request.(*agentpb.TerminateSessionRequest).Reason = reason
+	}
+	return nil
+}
+
+// notifySessions handles sending a notice to every session matching a filter (admin)
+func (api *AgentAPI) notifySessions(c *gin.Context) {
+	var req NotifySessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, err := sessionFilter(req.SessionFilterRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	notified := api.agentServer.NotifySessions(filter, req.Message)
+	c.JSON(http.StatusOK, BulkSessionsResponse{SessionIDs: notified, Count: len(notified)})
+}
+
+// getDrain handles reporting the drain mode (admin)
+func (api *AgentAPI) getDrain(c *gin.Context) {
+	c.JSON(http.StatusOK, api.agentServer.Drain())
+}
+
+// setDrain handles turning drain mode on or off (admin)
+func (api *AgentAPI) setDrain(c *gin.Context) {
+	var req SetDrainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	api.agentServer.SetDraining(req.Enabled, req.Reason)
+	c.JSON(http.StatusOK, api.agentServer.Drain())
+}
+
+// setSessionQuota handles replacing the daily quota of a session (admin)
+func (api *AgentAPI) setSessionQuota(c *gin.Context) {
+	var req SetSessionQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID := c.Param("session_id")
+	if err := api.agentServer.SetSessionQuota(sessionID, req.Quota); err != nil {
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+	api.logger.Info("Session quota updated",
+		zap.String("session_id", sessionID),
+		zap.Any("quota", req.Quota),
+		zap.String("operator_address", c.ClientIP()))
+
+	session, exists := api.agentServer.getSession(sessionID)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	api.gateway.writeMessage(c, http.StatusOK, api.agentServer.limitsSnapshot(session))
+}
+
+// tapSession streams a session's invocations to an operator as Server-Sent Events (admin)
+func (api *AgentAPI) tapSession(c *gin.Context) {
+	sessionID := c.Param("session_id")
+	events, stop, err := api.agentServer.TapSession(sessionID)
+	if err != nil {
+		c.JSON(httpStatusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer stop()
+
+	api.logger.Info("Session tap opened",
+		zap.String("session_id", sessionID),
+		zap.String("operator_address", c.ClientIP()))
+	defer api.logger.Info("Session tap closed", zap.String("session_id", sessionID))
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(tapKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-events:
+			if !ok {
+				// The session ended
+				fmt.Fprint(c.Writer, "event: session_ended\ndata: {}\n\n")
+				c.Writer.Flush()
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				api.logger.Warn("Failed to encode tap event",
+					zap.String("session_id", sessionID),
+					zap.Error(err))
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
 // getMetrics handles getting server metrics (admin)
 func (api *AgentAPI) getMetrics(c *gin.Context) {
 	api.agentServer.sessionsMux.RLock()
@@ -765,6 +734,12 @@ func (api *AgentAPI) getMetrics(c *gin.Context) {
 		TotalInvocations: totalInvocations,
 		ToolUsageStats:   toolUsageStats,
 		SessionMetrics:   map[string]interface{}{},
+		CircuitBreakers:  make(map[string]*types.CircuitStatus),
+	}
+	for _, tool := range api.registry.ListTools() {
+		if tool.Circuit != nil && (tool.Circuit.State != types.CircuitClosed || tool.Circuit.ConsecutiveFailures > 0) {
+			resp.CircuitBreakers[tool.Name] = tool.Circuit
+		}
 	}
 
 	c.JSON(http.StatusOK, resp)
@@ -772,29 +747,62 @@ func (api *AgentAPI) getMetrics(c *gin.Context) {
 
 // Helper methods
 
-func (api *AgentAPI) convertToolInfo(grpcTool *agentpb.ToolInfo) ToolInfo {
-	tool := ToolInfo{
-		Name:        grpcTool.Name,
-		DisplayName: grpcTool.DisplayName,
-		Description: grpcTool.Description,
-		Version:     grpcTool.Version,
-		Type:        grpcTool.Type.String(),
-		Status:      grpcTool.Status.String(),
-		Tags:        grpcTool.Tags,
-		Metadata:    grpcTool.Metadata,
-		CreatedAt:   grpcTool.CreatedAtUnix,
-		UpdatedAt:   grpcTool.UpdatedAtUnix,
-	}
-
-	if grpcTool.Source != nil {
-		tool.Source = &ToolSource{
-			SpecID:      grpcTool.Source.SpecId,
-			SpecType:    grpcTool.Source.SpecType,
-			SpecPath:    grpcTool.Source.SpecPath,
-			OperationID: grpcTool.Source.OperationId,
-			QueryName:   grpcTool.Source.QueryName,
-		}
+// setDeprecationHeaders announces a deprecated tool with the Deprecation,
+// Sunset and successor Link headers
+func (api *AgentAPI) setDeprecationHeaders(c *gin.Context, toolName string) {
+	tool, err := api.registry.Get(toolName)
+	if err != nil {
+		return
+	}
+	deprecation := tool.Metadata().Deprecation
+	if deprecation == nil {
+		return
+	}
+
+	successor := ""
+	if deprecation.Replacement != "" {
+		// .../tools/<name>/invoke -> .../tools/<replacement>
+		successor = path.Join(path.Dir(path.Dir(c.Request.URL.Path)), deprecation.Replacement)
 	}
+	for name, values := range deprecation.Headers(successor) {
+		c.Writer.Header()[name] = values
+	}
+}
+
+// retryHint extracts the backoff advice from a tool error's metadata
+func retryHint(toolError *agentpb.ToolError) (RetryHint, bool) {
+	if toolError == nil || toolError.MetadataJson == "" {
+		return RetryHint{}, false
+	}
+	var metadata struct {
+		Retry *RetryHint `json:"retry"`
+	}
+	if err := json.Unmarshal([]byte(toolError.MetadataJson), &metadata); err != nil || metadata.Retry == nil {
+		return RetryHint{}, false
+	}
+	return *metadata.Retry, true
+}
 
-	return tool
+// httpStatusFromError maps gRPC status codes returned by AgentServer to HTTP status codes
+func httpStatusFromError(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
 }
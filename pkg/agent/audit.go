@@ -0,0 +1,71 @@
+package agent
+
+import (
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/audit"
+	"github.com/aionmcp/aionmcp/pkg/invocationlog"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"go.uber.org/zap"
+)
+
+// sessionActor identifies a session and the principal behind it in the audit log
+func sessionActor(session *AgentSession) audit.Actor {
+	actor := audit.Actor{Type: "agent", Name: session.AgentName, SessionID: session.ID}
+	if session.Identity != nil {
+		actor.Subject = session.Identity.Subject
+	}
+	return actor
+}
+
+// recordAudit appends an entry to the audit log when one is configured
+func (s *AgentServer) recordAudit(event audit.Event) {
+	if s.config.Audit == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = s.config.Clock.Now()
+	}
+	if err := s.config.Audit.Record(event); err != nil {
+		s.logger.Error("Failed to record audit event",
+			zap.String("action", event.Action),
+			zap.String("target", event.Target),
+			zap.Error(err))
+	}
+}
+
+// auditSession records a session lifecycle action by actor
+func (s *AgentServer) auditSession(action string, session *AgentSession, actor audit.Actor) {
+	s.recordAudit(audit.Event{
+		Category: audit.CategorySession,
+		Action:   action,
+		Actor:    actor,
+		Target:   session.ID,
+		Details:  map[string]string{"agent_id": session.AgentID, "agent_name": session.AgentName},
+	})
+}
+
+// auditInvocation records who invoked a tool with which parameters
+func (s *AgentServer) auditInvocation(session *AgentSession, req *agentpb.InvokeToolRequest, tool types.Tool, parameters map[string]interface{}, outcome invocationlog.Outcome, err error) {
+	if s.config.Audit == nil {
+		return
+	}
+	event := audit.Event{
+		Category: audit.CategoryTool,
+		Action:   audit.ActionToolInvoke,
+		Actor:    sessionActor(session),
+		Target:   req.ToolName,
+		Params:   parameters,
+		Outcome:  audit.Outcome(outcome),
+		Details:  map[string]string{"transport": "grpc"},
+	}
+	if req.InvocationId != "" {
+		event.Details["invocation_id"] = req.InvocationId
+	}
+	if tool != nil {
+		event.Details["source"] = tool.Metadata().Source
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.recordAudit(event)
+}
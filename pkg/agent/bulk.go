@@ -0,0 +1,293 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/audit"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SessionFilter selects sessions for bulk administration. Set fields must
+// all match; a filter with no fields set matches every session.
+type SessionFilter struct {
+	SessionIDs   []string
+	AgentID      string
+	AgentVersion string
+	IdleFor      time.Duration // Only sessions without a heartbeat for at least this long
+}
+
+// IsEmpty reports whether the filter matches every session
+func (f SessionFilter) IsEmpty() bool {
+	return len(f.SessionIDs) == 0 && f.AgentID == "" && f.AgentVersion == "" && f.IdleFor <= 0
+}
+
+// matches reports whether a session passes the filter at now
+func (f SessionFilter) matches(session *AgentSession, now time.Time) bool {
+	if len(f.SessionIDs) > 0 {
+		found := false
+		for _, id := range f.SessionIDs {
+			if id == session.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.AgentID != "" && f.AgentID != session.AgentID {
+		return false
+	}
+	if f.AgentVersion != "" && f.AgentVersion != session.AgentVersion {
+		return false
+	}
+	if f.IdleFor > 0 && now.Sub(session.LastHeartbeat) < f.IdleFor {
+		return false
+	}
+	return true
+}
+
+// DrainStatus reports whether the server refuses new registrations
+type DrainStatus struct {
+	Draining bool       `json:"draining"`
+	Reason   string     `json:"reason,omitempty"`
+	Since    *time.Time `json:"since,omitempty"`
+	Sessions int        `json:"sessions"` // Sessions still registered
+}
+
+// drainState is the drain mode of the agent server
+type drainState struct {
+	mu       sync.RWMutex
+	draining bool
+	reason   string
+	since    time.Time
+}
+
+// sessionNotices holds the notices waiting for a session's next heartbeat
+type sessionNotices struct {
+	mu      sync.Mutex
+	pending []string
+}
+
+// add queues a notice
+func (n *sessionNotices) add(message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending = append(n.pending, message)
+}
+
+// take returns and clears the queued notices
+func (n *sessionNotices) take() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	pending := n.pending
+	n.pending = nil
+	if pending == nil {
+		return []string{}
+	}
+	return pending
+}
+
+// selectSessions returns the sessions matching a filter, sorted by ID
+func (s *AgentServer) selectSessions(filter SessionFilter) []*AgentSession {
+	now := s.config.Clock.Now()
+	s.sessionsMux.RLock()
+	var selected []*AgentSession
+	for _, session := range s.sessions {
+		if filter.matches(session, now) {
+			selected = append(selected, session)
+		}
+	}
+	s.sessionsMux.RUnlock()
+
+	sort.Slice(selected, func(i, j int) bool { return selected[i].ID < selected[j].ID })
+	return selected
+}
+
+// adminActor identifies the administrator acting on sessions in the audit log
+func adminActor(ctx context.Context) audit.Actor {
+	actor := audit.Actor{Type: "admin"}
+	if identity, ok := IdentityFromContext(ctx); ok {
+		actor.Subject = identity.Subject
+		actor.Name = identity.AgentName
+	}
+	return actor
+}
+
+// EvictSessions unregisters every session matching the filter, telling its
+// event streams why first, and returns the IDs of the evicted sessions
+func (s *AgentServer) EvictSessions(ctx context.Context, filter SessionFilter, reason string) []string {
+	if reason == "" {
+		reason = "evicted by administrator"
+	}
+	evicted := []string{}
+	for _, session := range s.selectSessions(filter) {
+		if s.evictSession(ctx, session, "evicted", reason) {
+			evicted = append(evicted, session.ID)
+		}
+	}
+
+	s.logger.Info("Agent sessions evicted",
+		zap.Int("sessions", len(evicted)),
+		zap.String("agent_id", filter.AgentID),
+		zap.String("agent_version", filter.AgentVersion),
+		zap.Duration("idle_for", filter.IdleFor),
+		zap.String("reason", reason))
+	return evicted
+}
+
+// TerminateSession ends a single session on an administrator's behalf. The
+// agent's event streams receive a final SERVER_STATUS event with the reason
+// before they close.
+func (s *AgentServer) TerminateSession(ctx context.Context, req *agentpb.TerminateSessionRequest) (*agentpb.TerminateSessionResponse, error) {
+	session, exists := s.getSession(req.SessionId)
+	if !exists {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = "terminated by administrator"
+	}
+	if !s.evictSession(ctx, session, "terminated", reason) {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+
+	s.logger.Info("Agent session terminated",
+		zap.String("session_id", session.ID),
+		zap.String("agent_id", session.AgentID),
+		zap.String("reason", reason))
+	return &agentpb.TerminateSessionResponse{
+		Success: true,
+		Message: "Agent session terminated by administrator",
+	}, nil
+}
+
+// evictSession removes a session on an administrator's behalf, telling its
+// event streams why first. It reports false when the session had already
+// been unregistered or expired.
+func (s *AgentServer) evictSession(ctx context.Context, session *AgentSession, outcome, reason string) bool {
+	s.sessionsMux.Lock()
+	_, exists := s.sessions[session.ID]
+	delete(s.sessions, session.ID)
+	s.sessionsMux.Unlock()
+	if !exists {
+		return false
+	}
+	s.quotas.forgetSession(session.ID)
+	s.forgetUsage(session.ID)
+
+	s.sendToSession(session.ID, &agentpb.Event{
+		EventId:       uuid.New().String(),
+		Type:          agentpb.EventType_EVENT_TYPE_SERVER_STATUS,
+		TimestampUnix: s.config.Clock.Now().Unix(),
+		SessionId:     session.ID,
+		DataJson:      encodeEventData(map[string]interface{}{"status": outcome, "message": reason}),
+	})
+	s.auditSession(audit.ActionSessionEvict, session, adminActor(ctx))
+
+	s.closeEventStreams(session.ID)
+	s.tap.closeSession(session.ID)
+	s.cancelSessionInvocations(session.ID)
+
+	s.broadcastEvent(&agentpb.Event{
+		EventId:       uuid.New().String(),
+		Type:          agentpb.EventType_EVENT_TYPE_AGENT_UNREGISTERED,
+		TimestampUnix: s.config.Clock.Now().Unix(),
+		SessionId:     session.ID,
+		DataJson:      encodeEventData(map[string]interface{}{"agent_id": session.AgentID, "reason": outcome}),
+	})
+	return true
+}
+
+// NotifySessions sends a notice to every session matching the filter. Open
+// event streams receive it at once, and it is returned with the session's
+// next heartbeat. The IDs of the notified sessions are returned.
+func (s *AgentServer) NotifySessions(filter SessionFilter, message string) []string {
+	notified := []string{}
+	for _, session := range s.selectSessions(filter) {
+		session.notices.add(message)
+		s.sendToSession(session.ID, &agentpb.Event{
+			EventId:       uuid.New().String(),
+			Type:          agentpb.EventType_EVENT_TYPE_SERVER_STATUS,
+			TimestampUnix: s.config.Clock.Now().Unix(),
+			SessionId:     session.ID,
+			DataJson:      encodeEventData(map[string]interface{}{"status": "notice", "message": message}),
+		})
+		notified = append(notified, session.ID)
+	}
+
+	s.logger.Info("Notice sent to agent sessions",
+		zap.Int("sessions", len(notified)),
+		zap.String("message", message))
+	return notified
+}
+
+// sendToSession delivers an event to one session's event streams without blocking
+func (s *AgentServer) sendToSession(sessionID string, event *agentpb.Event) {
+	s.streamsMux.RLock()
+	defer s.streamsMux.RUnlock()
+
+	for _, stream := range s.eventStreams[sessionID] {
+		if !stream.offer(event) {
+			s.logger.Warn("Event stream channel full",
+				zap.String("session_id", sessionID),
+				zap.String("event_type", event.Type.String()))
+		}
+	}
+}
+
+// SetDraining turns drain mode on or off. While draining, existing sessions
+// keep working but new registrations are refused.
+func (s *AgentServer) SetDraining(draining bool, reason string) {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+
+	if draining && !s.drain.draining {
+		s.drain.since = s.config.Clock.Now()
+	}
+	s.drain.draining = draining
+	s.drain.reason = reason
+	if !draining {
+		s.drain.reason = ""
+	}
+	s.logger.Info("Agent registration drain mode changed",
+		zap.Bool("draining", draining),
+		zap.String("reason", reason))
+}
+
+// Drain reports the drain mode and how many sessions are still registered
+func (s *AgentServer) Drain() DrainStatus {
+	s.drain.mu.RLock()
+	drain := DrainStatus{Draining: s.drain.draining, Reason: s.drain.reason}
+	if s.drain.draining {
+		since := s.drain.since
+		drain.Since = &since
+	}
+	s.drain.mu.RUnlock()
+
+	s.sessionsMux.RLock()
+	drain.Sessions = len(s.sessions)
+	s.sessionsMux.RUnlock()
+	return drain
+}
+
+// checkDraining refuses registrations while the server is draining
+func (s *AgentServer) checkDraining() error {
+	s.drain.mu.RLock()
+	defer s.drain.mu.RUnlock()
+	if !s.drain.draining {
+		return nil
+	}
+	message := "server is draining and accepts no new agent sessions"
+	if s.drain.reason != "" {
+		message += ": " + s.drain.reason
+	}
+	return status.Error(codes.Unavailable, message)
+}
@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aionmcp/aionmcp/pkg/capabilities"
+)
+
+const (
+	// ServerVersion is the version reported to agents
+	ServerVersion = "0.1.0"
+
+	// ProtocolVersion is the newest agent protocol version the server speaks
+	ProtocolVersion = "MCP/1.0"
+
+	// agentServiceVersion is the version of the aionmcp.agent gRPC service
+	agentServiceVersion = "v1"
+)
+
+// Capabilities returns the feature matrix of the agent service, merged with
+// the server-wide features of AgentServerConfig.Features
+func (s *AgentServer) Capabilities() *capabilities.Matrix {
+	matrix := capabilities.New(ServerVersion, ProtocolVersion)
+	limits := s.config.SessionLimits
+	eventLogSize := s.config.EventLogSize
+	if eventLogSize <= 0 {
+		eventLogSize = DefaultEventLogSize
+	}
+
+	matrix.Set("session_management", capabilities.Feature{Enabled: true, Version: agentServiceVersion})
+	matrix.Set("protocol_negotiation", capabilities.Feature{
+		Enabled: true,
+		Options: map[string]string{
+			"supported_versions": joinProtocols(s.config.Protocols.Supported()),
+			"min_version":        s.config.Protocols.Min.String(),
+		},
+	})
+	matrix.Set("tool_execution", capabilities.Feature{
+		Enabled: true,
+		Limits: map[string]int64{
+			"requests_per_minute": int64(limits.RequestsPerMinute),
+			"max_concurrent":      int64(limits.MaxConcurrent),
+			"max_invocations":     limits.MaxInvocations,
+			"budget_ms":           limits.BudgetMs,
+		},
+	})
+	matrix.Set("async_execution", capabilities.Feature{
+		Enabled: true,
+		Limits: map[string]int64{
+			"workers":           int64(s.config.AsyncWorkers),
+			"queue_size":        int64(s.config.AsyncQueueSize),
+			"retention_seconds": int64(s.config.AsyncJobRetention.Seconds()),
+		},
+	})
+	matrix.Set("retries", capabilities.Feature{
+		Enabled: s.config.MaxRetries > 0,
+		Limits: map[string]int64{
+			"max_retries":  int64(s.config.MaxRetries),
+			"max_delay_ms": s.config.MaxRetryDelay.Milliseconds(),
+		},
+	})
+	matrix.Set("event_streaming", capabilities.Feature{
+		Enabled: true,
+		Limits: map[string]int64{
+			"event_log_size":        int64(eventLogSize),
+			"max_poll_wait_seconds": int64(s.config.MaxPollWait.Seconds()),
+		},
+	})
+	matrix.Set("telemetry", capabilities.Feature{
+		Enabled: true,
+		Options: map[string]string{
+			"default_capture_level": string(s.config.Telemetry.Default),
+			"max_capture_level":     string(s.config.Telemetry.Max),
+		},
+	})
+	matrix.Set("session_tap", capabilities.Feature{
+		Enabled: true,
+		Options: map[string]string{"include_payloads": strconv.FormatBool(s.config.Tap.IncludePayloads)},
+	})
+	matrix.Set("tool_access_control", capabilities.Feature{Enabled: s.config.ToolAccess != nil})
+	matrix.Set("result_formats", capabilities.Feature{
+		Enabled: true,
+		Options: map[string]string{"formats": strings.Join(s.resultFormatNames(), ",")},
+	})
+	matrix.Set("read_only_mode", capabilities.Feature{Enabled: s.config.ReadOnly != nil})
+
+	matrix.Merge(s.config.Features)
+	return matrix
+}
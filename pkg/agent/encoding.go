@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// parametersField names the request field reported in parameter violations
+const parametersField = "parameters_json"
+
+// parseParameters decodes the ParametersJson of an invocation. An empty string
+// or JSON null yields no parameters; anything other than a JSON object is an
+// InvalidArgument error carrying a BadRequest field violation.
+func parseParameters(parametersJSON string) (map[string]interface{}, error) {
+	trimmed := bytes.TrimSpace([]byte(parametersJSON))
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+	if trimmed[0] != '{' {
+		return nil, invalidParametersError(fmt.Sprintf("parameters must be a JSON object, got %s", jsonKind(trimmed[0])))
+	}
+
+	var parameters map[string]interface{}
+	if err := json.Unmarshal(trimmed, &parameters); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, invalidParametersError(fmt.Sprintf("malformed JSON at offset %d: %v", syntaxErr.Offset, syntaxErr))
+		}
+		return nil, invalidParametersError(fmt.Sprintf("malformed JSON: %v", err))
+	}
+	return parameters, nil
+}
+
+// invalidParametersError builds the InvalidArgument status for malformed parameters
+func invalidParametersError(description string) error {
+	st := status.New(codes.InvalidArgument, "Failed to parse parameters JSON: "+description)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: parametersField, Description: description},
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// jsonKind names the JSON value type that starts with the given byte
+func jsonKind(first byte) string {
+	switch {
+	case first == '[':
+		return "array"
+	case first == '"':
+		return "string"
+	case first == 't' || first == 'f':
+		return "boolean"
+	case first == '-' || (first >= '0' && first <= '9'):
+		return "number"
+	default:
+		return "invalid JSON"
+	}
+}
+
+// encodeEventData serializes event payloads. Payloads are built from plain
+// maps of strings and numbers, so encoding cannot fail in practice.
+func encodeEventData(data map[string]interface{}) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "{}"
+	}
+	return string(encoded)
+}
@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+)
+
+// Result formats agents can list in AgentCapabilities.PreferredFormats
+const (
+	FormatJSON        = "json"
+	FormatMessagePack = "msgpack"
+	FormatCBOR        = "cbor"
+)
+
+// ResultFormat serializes tool results. A session uses the first format in
+// the agent's preferred_formats that the server offers, and JSON otherwise.
+type ResultFormat interface {
+	Name() string        // As agents list it in preferred_formats
+	ContentType() string // Media type REST clients ask for in Accept
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// jsonFormat carries results as JSON text in InvokeToolResponse.result_json
+type jsonFormat struct{}
+
+func (jsonFormat) Name() string                       { return FormatJSON }
+func (jsonFormat) ContentType() string                { return "application/json" }
+func (jsonFormat) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonFormat) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// codecFormat is a binary format carried in InvokeToolResponse.result_data.
+// Structs are encoded with their json tags, and byte slices stay raw
+// instead of becoming base64 strings.
+type codecFormat struct {
+	name        string
+	contentType string
+	handle      codec.Handle
+}
+
+func (f *codecFormat) Name() string        { return f.name }
+func (f *codecFormat) ContentType() string { return f.contentType }
+
+func (f *codecFormat) Marshal(v any) ([]byte, error) {
+	var encoded []byte
+	if err := codec.NewEncoderBytes(&encoded, f.handle).Encode(v); err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+func (f *codecFormat) Unmarshal(data []byte, v any) error {
+	return codec.NewDecoderBytes(data, f.handle).Decode(v)
+}
+
+// stringMapType decodes maps the way encoding/json does, so decoded results
+// can be rendered as JSON
+var stringMapType = reflect.TypeOf(map[string]any(nil))
+
+// JSONFormat returns the JSON result format, which every server offers
+func JSONFormat() ResultFormat {
+	return jsonFormat{}
+}
+
+// MessagePackFormat returns the MessagePack result format
+func MessagePackFormat() ResultFormat {
+	handle := &codec.MsgpackHandle{WriteExt: true}
+	handle.MapType = stringMapType
+	handle.RawToString = true
+	return &codecFormat{name: FormatMessagePack, contentType: "application/msgpack", handle: handle}
+}
+
+// CBORFormat returns the CBOR result format
+func CBORFormat() ResultFormat {
+	handle := &codec.CborHandle{}
+	handle.MapType = stringMapType
+	return &codecFormat{name: FormatCBOR, contentType: "application/cbor", handle: handle}
+}
+
+// DefaultResultFormats returns the formats offered when none are configured
+func DefaultResultFormats() []ResultFormat {
+	return []ResultFormat{JSONFormat(), MessagePackFormat(), CBORFormat()}
+}
+
+// ParseResultFormat returns the built-in format with the given name
+func ParseResultFormat(name string) (ResultFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case FormatJSON:
+		return JSONFormat(), nil
+	case FormatMessagePack:
+		return MessagePackFormat(), nil
+	case FormatCBOR:
+		return CBORFormat(), nil
+	}
+	return nil, fmt.Errorf("unsupported result format %q: expected json, msgpack or cbor", name)
+}
+
+// ResultFormat returns the offered format with the given name. JSON is
+// always offered.
+func (s *AgentServer) ResultFormat(name string) (ResultFormat, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == FormatJSON {
+		return JSONFormat(), true
+	}
+	for _, format := range s.config.ResultFormats {
+		if format.Name() == name {
+			return format, true
+		}
+	}
+	return nil, false
+}
+
+// resultFormatNames lists the offered formats, JSON first
+func (s *AgentServer) resultFormatNames() []string {
+	names := []string{FormatJSON}
+	for _, format := range s.config.ResultFormats {
+		if format.Name() != FormatJSON {
+			names = append(names, format.Name())
+		}
+	}
+	return names
+}
+
+// negotiateResultFormat picks the first of an agent's preferred formats the
+// server offers. Formats the server does not offer, such as yaml, are skipped.
+func (s *AgentServer) negotiateResultFormat(preferred []string) ResultFormat {
+	for _, name := range preferred {
+		if format, ok := s.ResultFormat(name); ok {
+			return format
+		}
+	}
+	return JSONFormat()
+}
+
+// resultFormat returns the session's negotiated result format
+func (session *AgentSession) resultFormat() ResultFormat {
+	if session.ResultFormat == nil {
+		return JSONFormat()
+	}
+	return session.ResultFormat
+}
+
+// negotiateResponseFormat picks the offered format a REST client asks for in
+// its Accept header, defaulting to JSON
+func (api *AgentAPI) negotiateResponseFormat(c *gin.Context) ResultFormat {
+	offered := []string{JSONFormat().ContentType()}
+	formats := map[string]ResultFormat{}
+	for _, name := range api.agentServer.resultFormatNames()[1:] {
+		format, _ := api.agentServer.ResultFormat(name)
+		offered = append(offered, format.ContentType())
+		formats[format.ContentType()] = format
+	}
+	if format, ok := formats[c.NegotiateFormat(offered...)]; ok {
+		return format
+	}
+	return JSONFormat()
+}
+
+// render writes a response body in the negotiated format
+func (api *AgentAPI) render(c *gin.Context, statusCode int, format ResultFormat, body any) {
+	if format.Name() == FormatJSON {
+		c.JSON(statusCode, body)
+		return
+	}
+	encoded, err := format.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to encode response as %s: %v", format.Name(), err)})
+		return
+	}
+	c.Data(statusCode, format.ContentType(), encoded)
+}
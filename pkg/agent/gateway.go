@@ -0,0 +1,479 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// GatewayMetadataHeaderPrefix marks HTTP headers forwarded to RPCs as
+	// request metadata, and RPC response metadata returned as headers
+	GatewayMetadataHeaderPrefix = "Grpc-Metadata-"
+
+	// maxGatewayRequestSize matches the default gRPC receive limit
+	maxGatewayRequestSize = 4 << 20
+)
+
+// forwardedHeaderPrefixes are headers passed to RPCs as metadata without the
+// Grpc-Metadata- prefix, so REST and gRPC clients send the same keys
+var forwardedHeaderPrefixes = []string{"x-aionmcp-", "x-api-key", "authorization"}
+
+// Gateway serves the RPCs of a gRPC service over HTTP by transcoding JSON to
+// and from protobuf. It calls the same handlers as the gRPC server, so every
+// RPC, including ones added later, is available with identical messages:
+//
+//	POST /rpc/<package.Service>/<Method>
+//
+// Unary RPCs answer with the response message. Server-streaming RPCs answer
+// with newline-delimited JSON, one {"result": message} object per line and
+// an {"error": status} object if the stream fails.
+//
+// Calls pass through the interceptors given with SetInterceptors, as they
+// would on the gRPC server.
+type Gateway struct {
+	service   *grpc.ServiceDesc
+	impl      any
+	logger    *zap.Logger
+	marshal   protojson.MarshalOptions
+	unmarshal protojson.UnmarshalOptions
+	methods   map[string]grpc.MethodDesc // By method name
+
+	unaryInterceptor  grpc.UnaryServerInterceptor
+	streamInterceptor grpc.StreamServerInterceptor
+}
+
+// NewGateway creates a gateway for the agent service
+func NewGateway(logger *zap.Logger, agentServer *AgentServer) *Gateway {
+	return newGateway(logger, &agentpb.AgentService_ServiceDesc, agentServer)
+}
+
+func newGateway(logger *zap.Logger, service *grpc.ServiceDesc, impl any) *Gateway {
+	methods := make(map[string]grpc.MethodDesc, len(service.Methods))
+	for _, method := range service.Methods {
+		methods[method.MethodName] = method
+	}
+	return &Gateway{
+		service: service,
+		impl:    impl,
+		logger:  logger,
+		// Field names and zero values match the proto definition exactly
+		marshal:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		unmarshal: protojson.UnmarshalOptions{},
+		methods:   methods,
+	}
+}
+
+// SetInterceptors sets the interceptors calls pass through, in the order
+// the gRPC server runs them. Call it before serving.
+func (g *Gateway) SetInterceptors(unary []grpc.UnaryServerInterceptor, stream []grpc.StreamServerInterceptor) {
+	g.unaryInterceptor = chainUnaryInterceptors(unary)
+	g.streamInterceptor = chainStreamInterceptors(stream)
+}
+
+// chainUnaryInterceptors combines interceptors into one that runs them in
+// order, or returns nil when there are none
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// chainStreamInterceptors combines interceptors into one that runs them in
+// order, or returns nil when there are none
+func chainStreamInterceptors(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(srv any, stream grpc.ServerStream) error {
+				return interceptor(srv, stream, info, inner)
+			}
+		}
+		return next(srv, stream)
+	}
+}
+
+// GatewayMethod describes an RPC served by the gateway
+type GatewayMethod struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Streaming bool   `json:"streaming"`
+}
+
+// Methods lists the RPCs the gateway serves, relative to its routes
+func (g *Gateway) Methods() []GatewayMethod {
+	methods := make([]GatewayMethod, 0, len(g.service.Methods)+len(g.service.Streams))
+	for _, method := range g.service.Methods {
+		methods = append(methods, GatewayMethod{Name: method.MethodName, Path: g.path(method.MethodName)})
+	}
+	for _, stream := range g.service.Streams {
+		// Client streams cannot be expressed as one HTTP request
+		if stream.ClientStreams {
+			continue
+		}
+		methods = append(methods, GatewayMethod{Name: stream.StreamName, Path: g.path(stream.StreamName), Streaming: true})
+	}
+	return methods
+}
+
+func (g *Gateway) path(method string) string {
+	return "/rpc/" + g.service.ServiceName + "/" + method
+}
+
+// RegisterRoutes adds a route per RPC, plus a listing of them at /rpc
+func (g *Gateway) RegisterRoutes(router *gin.RouterGroup) {
+	for _, method := range g.service.Methods {
+		router.POST(g.path(method.MethodName), g.transcode(method.MethodName, http.StatusOK, nil))
+	}
+	for _, stream := range g.service.Streams {
+		if !stream.ClientStreams {
+			router.POST(g.path(stream.StreamName), g.serverStream(stream))
+		}
+	}
+
+	router.GET("/rpc", func(c *gin.Context) {
+		// Report paths under the prefix the routes are mounted at
+		prefix := strings.TrimSuffix(c.FullPath(), "/rpc")
+		methods := g.Methods()
+		for i := range methods {
+			methods[i].Path = prefix + methods[i].Path
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"service": g.service.ServiceName,
+			"methods": methods,
+		})
+	})
+}
+
+// transcode serves a unary RPC, answering with statusCode when it
+// succeeds. REST routes pass bind to set the request fields they take from
+// the query; fields named like path parameters are set from the path.
+func (g *Gateway) transcode(methodName string, statusCode int, bind func(c *gin.Context, request proto.Message) error) gin.HandlerFunc {
+	if _, exists := g.methods[methodName]; !exists {
+		panic(fmt.Sprintf("gateway: %s has no method %s", g.service.ServiceName, methodName))
+	}
+	return func(c *gin.Context) {
+		response, err := g.call(c, methodName, bind)
+		if err != nil {
+			g.writeError(c, err)
+			return
+		}
+		g.writeMessage(c, statusCode, response)
+	}
+}
+
+// call runs a unary RPC for an HTTP request through the interceptors. The
+// body is decoded as the request message, path parameters set the fields
+// named after them, and then bind, if given, may set more.
+func (g *Gateway) call(c *gin.Context, methodName string, bind func(c *gin.Context, request proto.Message) error) (proto.Message, error) {
+	method, exists := g.methods[methodName]
+	if !exists {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", methodName)
+	}
+	body, err := readGatewayBody(c)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, transport := g.requestContext(c, "/"+g.service.ServiceName+"/"+methodName)
+	response, err := method.Handler(g.impl, ctx, func(request any) error {
+		if err := g.decode(body, request); err != nil {
+			return err
+		}
+		message := request.(proto.Message)
+		bindPath(c, message)
+		if bind == nil {
+			return nil
+		}
+		return bind(c, message)
+	}, g.unaryInterceptor)
+	transport.writeHeaders(c.Writer)
+	if err != nil {
+		return nil, err
+	}
+
+	message, ok := response.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "response type %T is not a protobuf message", response)
+	}
+	return message, nil
+}
+
+// bindPath sets the string fields of a request named like the route's path
+// parameters, e.g. session_id and tool_name
+func bindPath(c *gin.Context, request proto.Message) {
+	message := request.ProtoReflect()
+	fields := message.Descriptor().Fields()
+	for _, param := range c.Params {
+		if field := fields.ByName(protoreflect.Name(param.Key)); field != nil && field.Kind() == protoreflect.StringKind {
+			message.Set(field, protoreflect.ValueOfString(param.Value))
+		}
+	}
+}
+
+// serverStream serves a server-streaming RPC as newline-delimited JSON
+func (g *Gateway) serverStream(desc grpc.StreamDesc) gin.HandlerFunc {
+	fullMethod := "/" + g.service.ServiceName + "/" + desc.StreamName
+	return func(c *gin.Context) {
+		body, err := readGatewayBody(c)
+		if err != nil {
+			g.writeError(c, err)
+			return
+		}
+
+		ctx, transport := g.requestContext(c, fullMethod)
+		stream := &gatewayStream{gateway: g, ctx: ctx, transport: transport, writer: c.Writer, body: body}
+		if g.streamInterceptor != nil {
+			info := &grpc.StreamServerInfo{FullMethod: fullMethod, IsServerStream: true}
+			err = g.streamInterceptor(g.impl, stream, info, desc.Handler)
+		} else {
+			err = desc.Handler(g.impl, stream)
+		}
+		if err == nil {
+			return
+		}
+		if !stream.started {
+			transport.writeHeaders(c.Writer)
+			g.writeError(c, err)
+			return
+		}
+		// Headers are already sent, so the failure ends the stream
+		encoded, encodeErr := g.marshal.Marshal(status.Convert(err).Proto())
+		if encodeErr != nil {
+			encoded = []byte(fmt.Sprintf(`{"code":%d,"message":%q}`, status.Code(err), err.Error()))
+		}
+		_, _ = fmt.Fprintf(c.Writer, "{\"error\":%s}\n", encoded)
+		c.Writer.Flush()
+	}
+}
+
+// requestContext passes forwarded headers to the RPC as incoming metadata
+// and collects the metadata it sets for the response
+func (g *Gateway) requestContext(c *gin.Context, fullMethod string) (context.Context, *gatewayTransport) {
+	md := metadata.MD{}
+	for name, values := range c.Request.Header {
+		key := strings.ToLower(name)
+		if strings.HasPrefix(name, GatewayMetadataHeaderPrefix) {
+			md.Append(strings.ToLower(strings.TrimPrefix(name, GatewayMetadataHeaderPrefix)), values...)
+			continue
+		}
+		for _, prefix := range forwardedHeaderPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				md.Append(key, values...)
+				break
+			}
+		}
+	}
+
+	transport := &gatewayTransport{method: fullMethod, header: metadata.MD{}}
+	ctx := metadata.NewIncomingContext(c.Request.Context(), md)
+	return grpc.NewContextWithServerTransportStream(ctx, transport), transport
+}
+
+// decode reads a JSON request body into a request message; an empty body is
+// an empty message
+func (g *Gateway) decode(body []byte, request any) error {
+	message, ok := request.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "request type %T is not a protobuf message", request)
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+	if err := g.unmarshal.Unmarshal(body, message); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// encode writes a response message as JSON
+func (g *Gateway) encode(response any) ([]byte, error) {
+	message, ok := response.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "response type %T is not a protobuf message", response)
+	}
+	return g.marshal.Marshal(message)
+}
+
+// writeMessage answers with a message as JSON
+func (g *Gateway) writeMessage(c *gin.Context, statusCode int, message proto.Message) {
+	encoded, err := g.encode(message)
+	if err != nil {
+		g.writeError(c, err)
+		return
+	}
+	c.Data(statusCode, "application/json", encoded)
+}
+
+// writeError answers with the RPC status, e.g. {"code": 5, "message": "...", "details": [...]}
+func (g *Gateway) writeError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	encoded, encodeErr := g.marshal.Marshal(st.Proto())
+	if encodeErr != nil {
+		g.logger.Warn("Failed to encode RPC status", zap.Error(encodeErr))
+		c.JSON(httpStatusFromError(err), gin.H{"code": st.Code(), "message": st.Message()})
+		return
+	}
+	c.Data(httpStatusFromError(err), "application/json", encoded)
+}
+
+// readGatewayBody reads a request body up to the gRPC message size limit
+func readGatewayBody(c *gin.Context) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxGatewayRequestSize+1))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err)
+	}
+	if len(body) > maxGatewayRequestSize {
+		return nil, status.Errorf(codes.ResourceExhausted, "request body exceeds %d bytes", maxGatewayRequestSize)
+	}
+	return body, nil
+}
+
+// gatewayTransport collects the response metadata an RPC sets
+type gatewayTransport struct {
+	mu     sync.Mutex
+	method string
+	header metadata.MD
+	sent   bool
+}
+
+// Method implements grpc.ServerTransportStream
+func (t *gatewayTransport) Method() string {
+	return t.method
+}
+
+// SetHeader implements grpc.ServerTransportStream
+func (t *gatewayTransport) SetHeader(md metadata.MD) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sent {
+		return status.Error(codes.Internal, "headers already sent")
+	}
+	t.header = metadata.Join(t.header, md)
+	return nil
+}
+
+// SendHeader implements grpc.ServerTransportStream
+func (t *gatewayTransport) SendHeader(md metadata.MD) error {
+	return t.SetHeader(md)
+}
+
+// SetTrailer implements grpc.ServerTransportStream. HTTP responses carry no
+// trailers here, so they are dropped.
+func (t *gatewayTransport) SetTrailer(metadata.MD) error {
+	return nil
+}
+
+// writeHeaders sends the collected metadata as Grpc-Metadata- headers
+func (t *gatewayTransport) writeHeaders(writer http.ResponseWriter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sent {
+		return
+	}
+	t.sent = true
+	for key, values := range t.header {
+		for _, value := range values {
+			writer.Header().Add(GatewayMetadataHeaderPrefix+key, value)
+		}
+	}
+}
+
+// gatewayStream adapts an HTTP exchange to a server stream: the request body
+// is the only message received, and each message sent is one JSON line
+type gatewayStream struct {
+	gateway   *Gateway
+	ctx       context.Context
+	transport *gatewayTransport
+	writer    gin.ResponseWriter
+	body      []byte
+	received  bool
+	started   bool
+}
+
+// SetHeader implements grpc.ServerStream
+func (s *gatewayStream) SetHeader(md metadata.MD) error {
+	return s.transport.SetHeader(md)
+}
+
+// SendHeader implements grpc.ServerStream
+func (s *gatewayStream) SendHeader(md metadata.MD) error {
+	if err := s.transport.SetHeader(md); err != nil {
+		return err
+	}
+	s.start()
+	return nil
+}
+
+// SetTrailer implements grpc.ServerStream
+func (s *gatewayStream) SetTrailer(md metadata.MD) {
+	_ = s.transport.SetTrailer(md)
+}
+
+// Context implements grpc.ServerStream
+func (s *gatewayStream) Context() context.Context {
+	return s.ctx
+}
+
+// start sends the response headers
+func (s *gatewayStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.transport.writeHeaders(s.writer)
+	s.writer.Header().Set("Content-Type", "application/x-ndjson")
+	s.writer.WriteHeader(http.StatusOK)
+	s.writer.Flush()
+}
+
+// SendMsg implements grpc.ServerStream
+func (s *gatewayStream) SendMsg(m any) error {
+	encoded, err := s.gateway.encode(m)
+	if err != nil {
+		return err
+	}
+	s.start()
+	if _, err := fmt.Fprintf(s.writer, "{\"result\":%s}\n", encoded); err != nil {
+		return status.Errorf(codes.Unavailable, "client went away: %v", err)
+	}
+	s.writer.Flush()
+	return nil
+}
+
+// RecvMsg implements grpc.ServerStream
+func (s *gatewayStream) RecvMsg(m any) error {
+	if s.received {
+		return io.EOF
+	}
+	s.received = true
+	return s.gateway.decode(s.body, m)
+}
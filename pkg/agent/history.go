@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/sessionhistory"
+	"go.uber.org/zap"
+)
+
+// recordUsage adds a finished invocation to its session's recent tool usage
+func (s *AgentServer) recordUsage(session *AgentSession, toolName string, status agentpb.ToolInvocationStatus, executionTime time.Duration, toolError *agentpb.ToolError) {
+	entry := sessionhistory.Entry{
+		ToolName:  toolName,
+		InvokedAt: s.config.Clock.Now().Add(-executionTime),
+		Status:    status.String(),
+		LatencyMs: executionTime.Milliseconds(),
+	}
+	if toolError != nil {
+		entry.Error = toolError.Message
+	}
+	if err := s.config.UsageHistory.Append(session.ID, entry); err != nil {
+		s.logger.Warn("Failed to record recent tool usage",
+			zap.String("session_id", session.ID),
+			zap.String("tool_name", toolName),
+			zap.Error(err))
+	}
+}
+
+// recentToolUsage returns a session's recent invocations, newest first
+func (s *AgentServer) recentToolUsage(sessionID string) []*agentpb.ToolUsageInfo {
+	history, err := s.config.UsageHistory.Recent(sessionID)
+	if err != nil {
+		s.logger.Warn("Failed to read recent tool usage", zap.String("session_id", sessionID), zap.Error(err))
+	}
+	usage := make([]*agentpb.ToolUsageInfo, len(history))
+	for i, entry := range history {
+		usage[i] = &agentpb.ToolUsageInfo{
+			ToolName:        entry.ToolName,
+			InvokedAtUnix:   entry.InvokedAt.Unix(),
+			Status:          agentpb.ToolInvocationStatus(agentpb.ToolInvocationStatus_value[entry.Status]),
+			ExecutionTimeMs: entry.LatencyMs,
+			ErrorMessage:    entry.Error,
+		}
+	}
+	return usage
+}
+
+// forgetUsage drops the recent tool usage of a session that ended
+func (s *AgentServer) forgetUsage(sessionID string) {
+	if err := s.config.UsageHistory.Delete(sessionID); err != nil {
+		s.logger.Warn("Failed to delete recent tool usage", zap.String("session_id", sessionID), zap.Error(err))
+	}
+}
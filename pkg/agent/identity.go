@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Identity is the authenticated principal behind a request, such as the
+// subject of an OIDC token
+type Identity struct {
+	Subject   string   `json:"subject"`
+	AgentID   string   `json:"agent_id,omitempty"` // Agent ID the credential is bound to, if any
+	AgentName string   `json:"agent_name,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+}
+
+// ToolAccess reports whether holders of roles may see and invoke a tool
+// registered from source
+type ToolAccess func(roles []string, tool types.ToolMetadata, source string) bool
+
+// identityKey is the context key of the request identity
+type identityKey struct{}
+
+// WithIdentity attaches the authenticated identity to a request context
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the authenticated identity of a request, if any
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// bindIdentity fills a registration from the caller's identity. Credentials
+// bound to an agent cannot register a different one.
+func bindIdentity(ctx context.Context, agentID, agentName string) (string, string, *Identity, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return agentID, agentName, nil, nil
+	}
+	if identity.AgentID != "" {
+		if agentID != "" && agentID != identity.AgentID {
+			return "", "", nil, status.Errorf(codes.PermissionDenied, "credential is bound to agent %q", identity.AgentID)
+		}
+		agentID = identity.AgentID
+	}
+	if agentName == "" {
+		agentName = identity.AgentName
+	}
+	return agentID, agentName, &identity, nil
+}
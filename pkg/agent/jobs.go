@@ -0,0 +1,258 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultAsyncWorkers is the default number of workers executing async invocations
+	DefaultAsyncWorkers = 4
+
+	// DefaultAsyncQueueSize is the default number of async invocations that may wait for a worker
+	DefaultAsyncQueueSize = 100
+
+	// DefaultAsyncJobRetention is how long finished async invocations remain queryable
+	DefaultAsyncJobRetention = time.Hour
+)
+
+// InvocationJob is a snapshot of an asynchronous tool invocation
+type InvocationJob struct {
+	InvocationID string
+	SessionID    string
+	ToolName     string
+	Status       agentpb.ToolInvocationStatus
+	Response     *agentpb.InvokeToolResponse // Set once the invocation has finished
+	SubmittedAt  time.Time
+	StartedAt    time.Time
+	CompletedAt  time.Time
+}
+
+// Finished reports whether the invocation has reached a terminal status
+func (j InvocationJob) Finished() bool {
+	switch j.Status {
+	case agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS,
+		agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED,
+		agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_TIMEOUT,
+		agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED:
+		return true
+	}
+	return false
+}
+
+// invocationJob is a queued or running async invocation
+type invocationJob struct {
+	mu         sync.Mutex
+	job        InvocationJob
+	session    *AgentSession
+	request    *agentpb.InvokeToolRequest
+	tool       types.Tool
+	parameters map[string]interface{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// snapshot returns a copy of the job state
+func (j *invocationJob) snapshot() InvocationJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.job
+}
+
+// submitInvocation queues an async invocation. The caller holds an invocation
+// slot for the session, which the job releases when it finishes.
+func (s *AgentServer) submitInvocation(session *AgentSession, req *agentpb.InvokeToolRequest, tool types.Tool, parameters map[string]interface{}) (InvocationJob, error) {
+	ctx, cancel := context.WithCancel(s.jobsCtx)
+	job := &invocationJob{
+		job: InvocationJob{
+			InvocationID: req.InvocationId,
+			SessionID:    session.ID,
+			ToolName:     req.ToolName,
+			Status:       agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_PENDING,
+			SubmittedAt:  s.config.Clock.Now(),
+		},
+		session:    session,
+		request:    req,
+		tool:       tool,
+		parameters: parameters,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	s.jobsMux.Lock()
+	if s.jobsClosed {
+		s.jobsMux.Unlock()
+		cancel()
+		return InvocationJob{}, status.Error(codes.Unavailable, "server is shutting down")
+	}
+	if _, exists := s.jobs[req.InvocationId]; exists {
+		s.jobsMux.Unlock()
+		cancel()
+		return InvocationJob{}, status.Errorf(codes.AlreadyExists, "invocation already exists: %s", req.InvocationId)
+	}
+	select {
+	case s.jobQueue <- job:
+		s.jobs[req.InvocationId] = job
+	default:
+		s.jobsMux.Unlock()
+		cancel()
+		return InvocationJob{}, status.Errorf(codes.ResourceExhausted, "async invocation queue full (%d pending)", cap(s.jobQueue))
+	}
+	s.jobsMux.Unlock()
+
+	return job.snapshot(), nil
+}
+
+// invocationWorker executes queued async invocations until the queue is closed
+func (s *AgentServer) invocationWorker() {
+	defer s.workers.Done()
+	for job := range s.jobQueue {
+		s.runInvocationJob(job)
+	}
+}
+
+// runInvocationJob executes one async invocation unless it was cancelled while queued
+func (s *AgentServer) runInvocationJob(job *invocationJob) {
+	defer s.releaseInvocationSlot(job.session)
+
+	job.mu.Lock()
+	if job.job.Status == agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED {
+		job.mu.Unlock()
+		return
+	}
+	// Jobs still queued at shutdown are cancelled rather than run
+	if job.ctx.Err() != nil {
+		job.job.Status = agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED
+		job.job.CompletedAt = s.config.Clock.Now()
+		job.mu.Unlock()
+		return
+	}
+	job.job.Status = agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_RUNNING
+	job.job.StartedAt = s.config.Clock.Now()
+	job.mu.Unlock()
+
+	response := s.executeInvocation(job.ctx, job.session, job.request, job.tool, job.parameters, job.job.StartedAt)
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.cancel()
+
+	// A cancelled invocation keeps its status; the late result is discarded
+	if job.job.Status == agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED {
+		s.logger.Info("Discarded result of cancelled invocation",
+			zap.String("session_id", job.job.SessionID),
+			zap.String("invocation_id", job.job.InvocationID))
+		return
+	}
+	job.job.Status = response.Status
+	job.job.Response = response
+	job.job.CompletedAt = s.config.Clock.Now()
+}
+
+// Close cancels pending and running async invocations and waits for the
+// workers to finish, so no invocation outlives the server. New async
+// invocations are refused afterwards. Close is safe to call more than once.
+func (s *AgentServer) Close() {
+	s.closeOnce.Do(func() {
+		s.jobsMux.Lock()
+		s.jobsClosed = true
+		close(s.jobQueue)
+		s.jobsMux.Unlock()
+
+		s.stopJobs()
+		s.workers.Wait()
+	})
+}
+
+// GetInvocation returns the state of an async invocation owned by a session
+func (s *AgentServer) GetInvocation(sessionID, invocationID string) (InvocationJob, error) {
+	job, err := s.lookupInvocation(sessionID, invocationID)
+	if err != nil {
+		return InvocationJob{}, err
+	}
+	return job.snapshot(), nil
+}
+
+// CancelInvocation cancels a pending or running async invocation. A running
+// tool cannot be interrupted; its result is discarded when it completes.
+func (s *AgentServer) CancelInvocation(sessionID, invocationID string) (InvocationJob, error) {
+	job, err := s.lookupInvocation(sessionID, invocationID)
+	if err != nil {
+		return InvocationJob{}, err
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	if job.job.Finished() {
+		return job.job, status.Errorf(codes.FailedPrecondition, "invocation already %s", job.job.Status)
+	}
+	job.job.Status = agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED
+	job.job.CompletedAt = s.config.Clock.Now()
+	job.cancel()
+
+	s.logger.Info("Async invocation cancelled",
+		zap.String("session_id", sessionID),
+		zap.String("invocation_id", invocationID))
+
+	return job.job, nil
+}
+
+// lookupInvocation finds an async invocation, hiding other sessions' invocations
+func (s *AgentServer) lookupInvocation(sessionID, invocationID string) (*invocationJob, error) {
+	if _, exists := s.getSession(sessionID); !exists {
+		return nil, status.Error(codes.Unauthenticated, "invalid session")
+	}
+
+	s.jobsMux.RLock()
+	job, exists := s.jobs[invocationID]
+	s.jobsMux.RUnlock()
+
+	if !exists || job.job.SessionID != sessionID {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("invocation not found: %s", invocationID))
+	}
+	return job, nil
+}
+
+// cancelSessionInvocations cancels every unfinished async invocation of a session
+func (s *AgentServer) cancelSessionInvocations(sessionID string) {
+	s.jobsMux.RLock()
+	var jobs []*invocationJob
+	for _, job := range s.jobs {
+		if job.job.SessionID == sessionID {
+			jobs = append(jobs, job)
+		}
+	}
+	s.jobsMux.RUnlock()
+
+	for _, job := range jobs {
+		job.mu.Lock()
+		if !job.job.Finished() {
+			job.job.Status = agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED
+			job.job.CompletedAt = s.config.Clock.Now()
+			job.cancel()
+		}
+		job.mu.Unlock()
+	}
+}
+
+// purgeInvocations forgets finished async invocations older than the retention period
+func (s *AgentServer) purgeInvocations(now time.Time) {
+	s.jobsMux.Lock()
+	defer s.jobsMux.Unlock()
+
+	for id, job := range s.jobs {
+		snapshot := job.snapshot()
+		if snapshot.Finished() && now.Sub(snapshot.CompletedAt) > s.config.AsyncJobRetention {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+)
+
+const (
+	// rateLimitWindow is the fixed window used for per-session request rate limiting
+	rateLimitWindow = time.Minute
+)
+
+// SessionLimits defines the invocation limits applied to every agent session.
+// A zero value for any field disables the corresponding limit.
+type SessionLimits struct {
+	RequestsPerMinute int   `json:"requests_per_minute"`
+	MaxConcurrent     int   `json:"max_concurrent"`
+	MaxInvocations    int64 `json:"max_invocations"` // Lifetime invocation quota
+	BudgetMs          int64 `json:"budget_ms"`       // Cumulative execution time budget
+}
+
+// DefaultSessionLimits returns the limits applied when none are configured:
+// every limit is off
+func DefaultSessionLimits() SessionLimits {
+	return SessionLimits{}
+}
+
+// sessionUsage tracks rate and concurrency consumption for a single session.
+// Invocation quotas and budgets are kept by the quota ledger.
+type sessionUsage struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+	inFlight    int
+}
+
+// acquireInvocationSlot reserves capacity for one invocation, returning an
+// error describing the exhausted limit when the session cannot proceed
+func (s *AgentServer) acquireInvocationSlot(session *AgentSession) error {
+	limits := s.config.SessionLimits
+	usage := session.Usage
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+
+	now := s.config.Clock.Now()
+	if now.Sub(usage.windowStart) >= rateLimitWindow {
+		usage.windowStart = now
+		usage.windowCount = 0
+	}
+
+	if limits.RequestsPerMinute > 0 && usage.windowCount >= limits.RequestsPerMinute {
+		return fmt.Errorf("rate limit exceeded (%d requests per minute)", limits.RequestsPerMinute)
+	}
+	if limits.MaxConcurrent > 0 && usage.inFlight >= limits.MaxConcurrent {
+		return fmt.Errorf("concurrency limit reached (%d in flight)", limits.MaxConcurrent)
+	}
+
+	usage.windowCount++
+	usage.inFlight++
+	return nil
+}
+
+// releaseInvocationSlot returns the concurrency slot held by a finished invocation
+func (s *AgentServer) releaseInvocationSlot(session *AgentSession) {
+	session.Usage.mu.Lock()
+	defer session.Usage.mu.Unlock()
+	if session.Usage.inFlight > 0 {
+		session.Usage.inFlight--
+	}
+}
+
+// GetSessionLimits returns a snapshot of the remaining limits for a session
+func (s *AgentServer) GetSessionLimits(sessionID string) (*agentpb.SessionLimits, error) {
+	session, exists := s.getSession(sessionID)
+	if !exists {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	return s.limitsSnapshot(session), nil
+}
+
+// limitsSnapshot builds the limits view reported to agents
+func (s *AgentServer) limitsSnapshot(session *AgentSession) *agentpb.SessionLimits {
+	limits := s.config.SessionLimits
+
+	snapshot := &agentpb.SessionLimits{
+		RequestsPerMinute:   int32(limits.RequestsPerMinute),
+		RequestsRemaining:   -1,
+		MaxConcurrent:       int32(limits.MaxConcurrent),
+		ConcurrencyHeadroom: -1,
+		MaxInvocations:      limits.MaxInvocations,
+		BudgetMs:            limits.BudgetMs,
+	}
+
+	session.Usage.mu.Lock()
+	windowCount := session.Usage.windowCount
+	windowStart := session.Usage.windowStart
+	snapshot.InFlight = int32(session.Usage.inFlight)
+	session.Usage.mu.Unlock()
+
+	now := s.config.Clock.Now()
+	if now.Sub(windowStart) >= rateLimitWindow {
+		windowCount = 0
+		windowStart = now
+	}
+	snapshot.WindowResetsAtUnix = windowStart.Add(rateLimitWindow).Unix()
+
+	if limits.RequestsPerMinute > 0 {
+		snapshot.RequestsRemaining = int32(max(limits.RequestsPerMinute-windowCount, 0))
+	}
+	if limits.MaxConcurrent > 0 {
+		snapshot.ConcurrencyHeadroom = max(snapshot.MaxConcurrent-snapshot.InFlight, 0)
+	}
+
+	s.quotaSnapshot(session, snapshot)
+	return snapshot
+}
@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+)
+
+// Page sizes of tool listings
+const (
+	DefaultToolPageSize = 50
+	MaxToolPageSize     = 200
+)
+
+// Orders tool listings can be sorted in
+const (
+	SortByName      = "name"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+// toolCursor marks the last tool of a page and the order it was listed in.
+// Pages after it start at the first tool sorting after that position, so
+// tools registered or removed between requests neither repeat nor shift
+// later pages.
+type toolCursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Key    int64  `json:"k,omitempty"` // Timestamp of the tool for time orders
+	Name   string `json:"n"`
+}
+
+// encode renders the cursor as an opaque URL-safe string
+func (c toolCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeToolCursor reads a cursor returned as next_cursor
+func decodeToolCursor(value string) (toolCursor, error) {
+	var cursor toolCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.Name == "" {
+		return toolCursor{}, fmt.Errorf("invalid cursor")
+	}
+	if err := validateSortBy(cursor.SortBy); err != nil {
+		return toolCursor{}, err
+	}
+	return cursor, nil
+}
+
+func validateSortBy(sortBy string) error {
+	switch sortBy {
+	case SortByName, SortByCreatedAt, SortByUpdatedAt:
+		return nil
+	}
+	return fmt.Errorf("invalid sort_by %q: must be %s, %s or %s", sortBy, SortByName, SortByCreatedAt, SortByUpdatedAt)
+}
+
+// sortKey returns the timestamp a tool sorts by, zero for name order
+func sortKey(tool *agentpb.ToolInfo, sortBy string) int64 {
+	switch sortBy {
+	case SortByCreatedAt:
+		return tool.CreatedAtUnix
+	case SortByUpdatedAt:
+		return tool.UpdatedAtUnix
+	}
+	return 0
+}
+
+// sortsBefore reports whether position (key, name) comes before tool in an
+// order. Ties between timestamps are broken by name, so the order is total.
+func sortsBefore(key int64, name string, tool *agentpb.ToolInfo, sortBy string, desc bool) bool {
+	toolKey := sortKey(tool, sortBy)
+	if key != toolKey {
+		return (key < toolKey) != desc
+	}
+	if name == tool.Name {
+		return false
+	}
+	return (name < tool.Name) != desc
+}
+
+// paginateTools sorts tools and returns the page the options ask for with its
+// metadata. Without options all tools are returned as a single page.
+func paginateTools(tools []*agentpb.ToolInfo, options *agentpb.PaginationOptions) ([]*agentpb.ToolInfo, *agentpb.PaginationMetadata, error) {
+	total := len(tools)
+	if options == nil {
+		return tools, &agentpb.PaginationMetadata{CurrentPage: 1, PageSize: int32(total), TotalPages: 1}, nil
+	}
+
+	pageSize := int(options.PageSize)
+	if pageSize <= 0 {
+		pageSize = DefaultToolPageSize
+	}
+	if pageSize > MaxToolPageSize {
+		pageSize = MaxToolPageSize
+	}
+
+	order := toolCursor{SortBy: options.SortBy, Desc: options.SortDesc}
+	if order.SortBy == "" {
+		order.SortBy = SortByName
+	}
+	var cursor *toolCursor
+	if options.Cursor != "" {
+		decoded, err := decodeToolCursor(options.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		cursor = &decoded
+		order = toolCursor{SortBy: decoded.SortBy, Desc: decoded.Desc}
+	} else if err := validateSortBy(order.SortBy); err != nil {
+		return nil, nil, err
+	}
+
+	sorted := make([]*agentpb.ToolInfo, total)
+	copy(sorted, tools)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sortsBefore(sortKey(sorted[i], order.SortBy), sorted[i].Name, sorted[j], order.SortBy, order.Desc)
+	})
+
+	start, current := 0, int32(1)
+	if cursor != nil {
+		start = sort.Search(total, func(i int) bool {
+			return sortsBefore(cursor.Key, cursor.Name, sorted[i], order.SortBy, order.Desc)
+		})
+		current = int32(start/pageSize + 1)
+	} else if options.Page > 1 {
+		// Pages past the end are empty but keep the number asked for
+		current = options.Page
+		start = int(options.Page-1) * pageSize
+		if start > total {
+			start = total
+		}
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	page := sorted[start:end]
+	metadata := &agentpb.PaginationMetadata{
+		CurrentPage: current,
+		PageSize:    int32(pageSize),
+		TotalPages:  int32((total + pageSize - 1) / pageSize),
+		HasNext:     end < total,
+		HasPrevious: start > 0,
+	}
+	if metadata.HasNext {
+		last := page[len(page)-1]
+		next := order
+		next.Key, next.Name = sortKey(last, order.SortBy), last.Name
+		metadata.NextCursor = next.encode()
+	}
+	return page, metadata, nil
+}
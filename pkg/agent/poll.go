@@ -0,0 +1,256 @@
+package agent
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/eventstore"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultEventLogSize is how many recent events are kept for long-polling
+	DefaultEventLogSize = 1024
+
+	// DefaultPollWait is how long a poll waits for events when the client
+	// does not say
+	DefaultPollWait = 30 * time.Second
+
+	// DefaultMaxPollWait caps the wait a client may request
+	DefaultMaxPollWait = 60 * time.Second
+
+	// maxPollEvents bounds the events returned by one poll
+	maxPollEvents = 100
+)
+
+// loggedEvent is an event with its position in the event log
+type loggedEvent struct {
+	sequence uint64
+	event    *agentpb.Event
+}
+
+// eventLog keeps the most recent broadcast events so HTTP clients can
+// long-poll them by cursor and streams can replay them. Cursors are sequence
+// numbers: a poll returns the events after its cursor and the cursor to pass
+// next. With a store, events and their sequences survive restarts.
+type eventLog struct {
+	mu       sync.Mutex
+	events   []loggedEvent // Ring of the most recent events
+	start    int           // Index of the oldest event
+	sequence uint64        // Sequence of the newest event
+	notify   chan struct{} // Closed and replaced on every append
+	store    *eventstore.Store
+}
+
+// newEventLog creates an event log keeping up to size events
+func newEventLog(size int) *eventLog {
+	if size <= 0 {
+		size = DefaultEventLogSize
+	}
+	return &eventLog{
+		events: make([]loggedEvent, 0, size),
+		notify: make(chan struct{}),
+	}
+}
+
+// restore loads the events kept by a store and persists further events to
+// it. Events that cannot be decoded are skipped.
+func (l *eventLog) restore(store *eventstore.Store) error {
+	records, err := store.Load()
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store = store
+	for _, record := range records {
+		event := &agentpb.Event{}
+		if err := proto.Unmarshal(record.Data, event); err != nil {
+			continue
+		}
+		l.sequence = record.Sequence - 1
+		l.add(event)
+	}
+	return nil
+}
+
+// append adds an event, evicting the oldest when full, stamps it with its
+// sequence and wakes pollers. The event is logged even when persisting it
+// fails.
+func (l *eventLog) append(event *agentpb.Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.add(event)
+	close(l.notify)
+	l.notify = make(chan struct{})
+
+	if l.store == nil {
+		return nil
+	}
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return l.store.Append(event.Sequence, data)
+}
+
+// add places an event in the ring under the next sequence. Callers hold
+// the lock.
+func (l *eventLog) add(event *agentpb.Event) {
+	l.sequence++
+	event.Sequence = l.sequence
+	entry := loggedEvent{sequence: l.sequence, event: event}
+	if len(l.events) < cap(l.events) {
+		l.events = append(l.events, entry)
+	} else {
+		l.events[l.start] = entry
+		l.start = (l.start + 1) % len(l.events)
+	}
+}
+
+// head returns the sequence of the newest event
+func (l *eventLog) head() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sequence
+}
+
+// after returns up to limit events following a cursor, the cursor to pass
+// next, whether events between the cursor and the oldest retained one were
+// evicted, and a channel closed when further events arrive
+func (l *eventLog) after(cursor uint64, limit int) ([]loggedEvent, uint64, bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cursor > l.sequence {
+		// A cursor from before a restart that lost the log; start over from
+		// the newest event
+		cursor = l.sequence
+	}
+	missed := false
+	if len(l.events) > 0 && cursor+1 < l.events[l.start].sequence {
+		missed = true
+	}
+
+	var events []loggedEvent
+	for i := 0; i < len(l.events) && len(events) < limit; i++ {
+		entry := l.events[(l.start+i)%len(l.events)]
+		if entry.sequence > cursor {
+			events = append(events, entry)
+		}
+	}
+	next := cursor
+	if len(events) > 0 {
+		next = events[len(events)-1].sequence
+	}
+	return events, next, missed, l.notify
+}
+
+// poll waits up to wait for events after a cursor, returning as soon as any
+// are available. An empty cursor starts at the newest event, so only events
+// broadcast afterwards are returned.
+func (l *eventLog) poll(ctx context.Context, cursor string, wait time.Duration) ([]loggedEvent, string, bool, error) {
+	position := l.head()
+	if cursor != "" {
+		parsed, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, "", false, err
+		}
+		position = parsed
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		events, next, missed, notify := l.after(position, maxPollEvents)
+		if len(events) > 0 || missed {
+			return events, strconv.FormatUint(next, 10), missed, nil
+		}
+		select {
+		case <-notify:
+		case <-timer.C:
+			return nil, strconv.FormatUint(next, 10), false, nil
+		case <-ctx.Done():
+			return nil, strconv.FormatUint(next, 10), false, nil
+		}
+	}
+}
+
+// EventPoll is the result of long-polling the event log
+type EventPoll struct {
+	Events []*agentpb.Event
+	Cursor string // Pass as the cursor of the next poll
+	Missed bool   // Events after the given cursor were evicted before this poll
+}
+
+// PollEvents waits up to wait for events broadcast after a cursor. The wait is
+// capped by the server's maximum.
+func (s *AgentServer) PollEvents(ctx context.Context, sessionID, cursor string, wait time.Duration) (*EventPoll, error) {
+	if _, exists := s.getSession(sessionID); !exists {
+		return nil, status.Error(codes.Unauthenticated, "invalid session")
+	}
+	if wait < 0 {
+		return nil, status.Error(codes.InvalidArgument, "wait must not be negative")
+	}
+	if wait > s.config.MaxPollWait {
+		wait = s.config.MaxPollWait
+	}
+
+	entries, next, missed, err := s.eventLog.poll(ctx, cursor, wait)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid cursor %q", cursor)
+	}
+	result := &EventPoll{Events: make([]*agentpb.Event, len(entries)), Cursor: next, Missed: missed}
+	for i, entry := range entries {
+		result.Events[i] = entry.event
+	}
+	return result, nil
+}
+
+// replayEvents sends a stream the logged events its filter selects after
+// the sequence it asked for, or every retained event with include_history,
+// and returns the sequence of the last one replayed. A client whose sequence
+// was evicted is told that events were missed before the replay.
+func (s *AgentServer) replayEvents(stream agentpb.AgentService_StreamEventsServer, req *agentpb.StreamEventsRequest, filter eventFilter) (uint64, error) {
+	if req.SinceSequence == 0 && !req.IncludeHistory {
+		return 0, nil
+	}
+	cursor := req.SinceSequence
+	for first := true; ; first = false {
+		entries, next, missed, _ := s.eventLog.after(cursor, maxPollEvents)
+		if first && missed && req.SinceSequence > 0 {
+			missedEvent := &agentpb.Event{
+				EventId:       uuid.New().String(),
+				Type:          agentpb.EventType_EVENT_TYPE_SERVER_STATUS,
+				TimestampUnix: s.config.Clock.Now().Unix(),
+				SessionId:     req.SessionId,
+				DataJson: encodeEventData(map[string]interface{}{
+					"status":         "events_missed",
+					"message":        "events after the requested sequence were evicted from the event log",
+					"since_sequence": req.SinceSequence,
+				}),
+			}
+			if err := stream.Send(missedEvent); err != nil {
+				return cursor, err
+			}
+		}
+		if len(entries) == 0 {
+			return next, nil
+		}
+		for _, entry := range entries {
+			if filter.matches(entry.event, eventToolName(entry.event)) {
+				if err := stream.Send(entry.event); err != nil {
+					return cursor, err
+				}
+			}
+			cursor = entry.sequence
+		}
+	}
+}
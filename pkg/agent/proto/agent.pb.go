@@ -397,6 +397,9 @@ type RegisterAgentRequest struct {
 	Capabilities          *AgentCapabilities     `protobuf:"bytes,4,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
 	Metadata              map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	SessionTimeoutSeconds int32                  `protobuf:"varint,6,opt,name=session_timeout_seconds,json=sessionTimeoutSeconds,proto3" json:"session_timeout_seconds,omitempty"` // Default 300 seconds
+	ResumptionToken       string                 `protobuf:"bytes,7,opt,name=resumption_token,json=resumptionToken,proto3" json:"resumption_token,omitempty"`                      // Resumes the expired session it was issued for
+	AllowedTools          []string               `protobuf:"bytes,8,rep,name=allowed_tools,json=allowedTools,proto3" json:"allowed_tools,omitempty"`                               // Glob patterns of the only tools the session may use
+	DeniedTools           []string               `protobuf:"bytes,9,rep,name=denied_tools,json=deniedTools,proto3" json:"denied_tools,omitempty"`                                  // Glob patterns of tools the session may not use
 	unknownFields         protoimpl.UnknownFields
 	sizeCache             protoimpl.SizeCache
 }
@@ -473,14 +476,37 @@ func (x *RegisterAgentRequest) GetSessionTimeoutSeconds() int32 {
 	return 0
 }
 
+func (x *RegisterAgentRequest) GetResumptionToken() string {
+	if x != nil {
+		return x.ResumptionToken
+	}
+	return ""
+}
+
+func (x *RegisterAgentRequest) GetAllowedTools() []string {
+	if x != nil {
+		return x.AllowedTools
+	}
+	return nil
+}
+
+func (x *RegisterAgentRequest) GetDeniedTools() []string {
+	if x != nil {
+		return x.DeniedTools
+	}
+	return nil
+}
+
 type RegisterAgentResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	ExpiresAtUnix  int64                  `protobuf:"varint,2,opt,name=expires_at_unix,json=expiresAtUnix,proto3" json:"expires_at_unix,omitempty"` // Unix timestamp
-	ServerInfo     *ServerInfo            `protobuf:"bytes,3,opt,name=server_info,json=serverInfo,proto3" json:"server_info,omitempty"`
-	AvailableTools []*ToolInfo            `protobuf:"bytes,4,rep,name=available_tools,json=availableTools,proto3" json:"available_tools,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SessionId       string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	ExpiresAtUnix   int64                  `protobuf:"varint,2,opt,name=expires_at_unix,json=expiresAtUnix,proto3" json:"expires_at_unix,omitempty"` // Unix timestamp
+	ServerInfo      *ServerInfo            `protobuf:"bytes,3,opt,name=server_info,json=serverInfo,proto3" json:"server_info,omitempty"`
+	AvailableTools  []*ToolInfo            `protobuf:"bytes,4,rep,name=available_tools,json=availableTools,proto3" json:"available_tools,omitempty"`
+	ResumptionToken string                 `protobuf:"bytes,5,opt,name=resumption_token,json=resumptionToken,proto3" json:"resumption_token,omitempty"` // Resumes this session after it expires, within the grace window
+	Resumed         bool                   `protobuf:"varint,6,opt,name=resumed,proto3" json:"resumed,omitempty"`                                       // Whether an expired session was resumed
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *RegisterAgentResponse) Reset() {
@@ -541,6 +567,20 @@ func (x *RegisterAgentResponse) GetAvailableTools() []*ToolInfo {
 	return nil
 }
 
+func (x *RegisterAgentResponse) GetResumptionToken() string {
+	if x != nil {
+		return x.ResumptionToken
+	}
+	return ""
+}
+
+func (x *RegisterAgentResponse) GetResumed() bool {
+	if x != nil {
+		return x.Resumed
+	}
+	return false
+}
+
 type UnregisterAgentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
@@ -971,6 +1011,8 @@ type InvokeToolResponse struct {
 	Error          *ToolError             `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
 	Metrics        *ToolMetrics           `protobuf:"bytes,5,opt,name=metrics,proto3" json:"metrics,omitempty"`
 	ExecutedAtUnix int64                  `protobuf:"varint,6,opt,name=executed_at_unix,json=executedAtUnix,proto3" json:"executed_at_unix,omitempty"` // Unix timestamp
+	ResultData     []byte                 `protobuf:"bytes,7,opt,name=result_data,json=resultData,proto3" json:"result_data,omitempty"`                // Result encoded in result_format when that is not JSON
+	ResultFormat   string                 `protobuf:"bytes,8,opt,name=result_format,json=resultFormat,proto3" json:"result_format,omitempty"`          // Encoding of the result: "json", "msgpack" or "cbor"
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -1047,12 +1089,28 @@ func (x *InvokeToolResponse) GetExecutedAtUnix() int64 {
 	return 0
 }
 
+func (x *InvokeToolResponse) GetResultData() []byte {
+	if x != nil {
+		return x.ResultData
+	}
+	return nil
+}
+
+func (x *InvokeToolResponse) GetResultFormat() string {
+	if x != nil {
+		return x.ResultFormat
+	}
+	return ""
+}
+
 // Event streaming
 type StreamEventsRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	EventTypes     []EventType            `protobuf:"varint,2,rep,packed,name=event_types,json=eventTypes,proto3,enum=aionmcp.agent.v1.EventType" json:"event_types,omitempty"`
-	IncludeHistory bool                   `protobuf:"varint,3,opt,name=include_history,json=includeHistory,proto3" json:"include_history,omitempty"` // Include recent events
+	EventTypes     []EventType            `protobuf:"varint,2,rep,packed,name=event_types,json=eventTypes,proto3,enum=aionmcp.agent.v1.EventType" json:"event_types,omitempty"` // Only events of these types; empty streams every type
+	IncludeHistory bool                   `protobuf:"varint,3,opt,name=include_history,json=includeHistory,proto3" json:"include_history,omitempty"`                            // Include recent events
+	SinceSequence  uint64                 `protobuf:"varint,4,opt,name=since_sequence,json=sinceSequence,proto3" json:"since_sequence,omitempty"`                               // Replay logged events after this sequence before live ones
+	ToolNames      []string               `protobuf:"bytes,5,rep,name=tool_names,json=toolNames,proto3" json:"tool_names,omitempty"`                                            // Only events about these tools, as glob patterns; events about no tool still pass
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -1108,6 +1166,20 @@ func (x *StreamEventsRequest) GetIncludeHistory() bool {
 	return false
 }
 
+func (x *StreamEventsRequest) GetSinceSequence() uint64 {
+	if x != nil {
+		return x.SinceSequence
+	}
+	return 0
+}
+
+func (x *StreamEventsRequest) GetToolNames() []string {
+	if x != nil {
+		return x.ToolNames
+	}
+	return nil
+}
+
 type Event struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
@@ -1115,6 +1187,7 @@ type Event struct {
 	TimestampUnix int64                  `protobuf:"varint,3,opt,name=timestamp_unix,json=timestampUnix,proto3" json:"timestamp_unix,omitempty"` // Unix timestamp
 	SessionId     string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	DataJson      string                 `protobuf:"bytes,5,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"` // JSON string representation
+	Sequence      uint64                 `protobuf:"varint,6,opt,name=sequence,proto3" json:"sequence,omitempty"`                // Position in the event log; 0 for events sent to a single stream
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1184,6 +1257,13 @@ func (x *Event) GetDataJson() string {
 	return ""
 }
 
+func (x *Event) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
 // Session management
 type HeartBeatRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1242,6 +1322,7 @@ type HeartBeatResponse struct {
 	SessionValid         bool                   `protobuf:"varint,1,opt,name=session_valid,json=sessionValid,proto3" json:"session_valid,omitempty"`
 	NextHeartbeatAtUnix  int64                  `protobuf:"varint,2,opt,name=next_heartbeat_at_unix,json=nextHeartbeatAtUnix,proto3" json:"next_heartbeat_at_unix,omitempty"` // Unix timestamp
 	PendingNotifications []string               `protobuf:"bytes,3,rep,name=pending_notifications,json=pendingNotifications,proto3" json:"pending_notifications,omitempty"`
+	Limits               *SessionLimits         `protobuf:"bytes,4,opt,name=limits,proto3" json:"limits,omitempty"` // Snapshot of the session's remaining limits
 	unknownFields        protoimpl.UnknownFields
 	sizeCache            protoimpl.SizeCache
 }
@@ -1297,6 +1378,13 @@ func (x *HeartBeatResponse) GetPendingNotifications() []string {
 	return nil
 }
 
+func (x *HeartBeatResponse) GetLimits() *SessionLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
 type GetAgentStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
@@ -1409,7 +1497,7 @@ type AgentCapabilities struct {
 	SupportsStreaming       bool                   `protobuf:"varint,3,opt,name=supports_streaming,json=supportsStreaming,proto3" json:"supports_streaming,omitempty"`
 	SupportsAsyncInvocation bool                   `protobuf:"varint,4,opt,name=supports_async_invocation,json=supportsAsyncInvocation,proto3" json:"supports_async_invocation,omitempty"`
 	MaxConcurrentTools      int32                  `protobuf:"varint,5,opt,name=max_concurrent_tools,json=maxConcurrentTools,proto3" json:"max_concurrent_tools,omitempty"`
-	PreferredFormats        []string               `protobuf:"bytes,6,rep,name=preferred_formats,json=preferredFormats,proto3" json:"preferred_formats,omitempty"` // ["json", "yaml", "xml"]
+	PreferredFormats        []string               `protobuf:"bytes,6,rep,name=preferred_formats,json=preferredFormats,proto3" json:"preferred_formats,omitempty"` // Result encodings in order of preference, e.g. ["msgpack", "json"]
 	unknownFields           protoimpl.UnknownFields
 	sizeCache               protoimpl.SizeCache
 }
@@ -1683,9 +1771,10 @@ type ToolFilter struct {
 	Types            []ToolType             `protobuf:"varint,1,rep,packed,name=types,proto3,enum=aionmcp.agent.v1.ToolType" json:"types,omitempty"`
 	Statuses         []ToolStatus           `protobuf:"varint,2,rep,packed,name=statuses,proto3,enum=aionmcp.agent.v1.ToolStatus" json:"statuses,omitempty"`
 	Tags             []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
-	NamePattern      string                 `protobuf:"bytes,4,opt,name=name_pattern,json=namePattern,proto3" json:"name_pattern,omitempty"`                   // Regex pattern for tool name
+	NamePattern      string                 `protobuf:"bytes,4,opt,name=name_pattern,json=namePattern,proto3" json:"name_pattern,omitempty"`                   // Glob pattern for tool name, e.g. "openapi.petstore.*"
 	CreatedAfterUnix int64                  `protobuf:"varint,5,opt,name=created_after_unix,json=createdAfterUnix,proto3" json:"created_after_unix,omitempty"` // Unix timestamp
 	UpdatedAfterUnix int64                  `protobuf:"varint,6,opt,name=updated_after_unix,json=updatedAfterUnix,proto3" json:"updated_after_unix,omitempty"` // Unix timestamp
+	Sources          []string               `protobuf:"bytes,7,rep,name=sources,proto3" json:"sources,omitempty"`                                              // IDs of the sources that registered the tools
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -1762,12 +1851,20 @@ func (x *ToolFilter) GetUpdatedAfterUnix() int64 {
 	return 0
 }
 
+func (x *ToolFilter) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
 type PaginationOptions struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`                         // 1-based page number
 	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // Default 50, max 200
 	SortBy        string                 `protobuf:"bytes,3,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`        // "name", "created_at", "updated_at"
 	SortDesc      bool                   `protobuf:"varint,4,opt,name=sort_desc,json=sortDesc,proto3" json:"sort_desc,omitempty"`
+	Cursor        string                 `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"` // next_cursor of the previous page; takes precedence over page and sorting
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1830,6 +1927,13 @@ func (x *PaginationOptions) GetSortDesc() bool {
 	return false
 }
 
+func (x *PaginationOptions) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
 type PaginationMetadata struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CurrentPage   int32                  `protobuf:"varint,1,opt,name=current_page,json=currentPage,proto3" json:"current_page,omitempty"`
@@ -1837,6 +1941,7 @@ type PaginationMetadata struct {
 	TotalPages    int32                  `protobuf:"varint,3,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
 	HasNext       bool                   `protobuf:"varint,4,opt,name=has_next,json=hasNext,proto3" json:"has_next,omitempty"`
 	HasPrevious   bool                   `protobuf:"varint,5,opt,name=has_previous,json=hasPrevious,proto3" json:"has_previous,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,6,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Cursor of the next page, empty on the last page
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1906,6 +2011,13 @@ func (x *PaginationMetadata) GetHasPrevious() bool {
 	return false
 }
 
+func (x *PaginationMetadata) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
 type ToolExample struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -2249,7 +2361,7 @@ func (x *ToolMetrics) GetCustomMetrics() map[string]float64 {
 type ToolSource struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SpecId        string                 `protobuf:"bytes,1,opt,name=spec_id,json=specId,proto3" json:"spec_id,omitempty"`
-	SpecType      string                 `protobuf:"bytes,2,opt,name=spec_type,json=specType,proto3" json:"spec_type,omitempty"` // "openapi", "graphql", "asyncapi"
+	SpecType      string                 `protobuf:"bytes,2,opt,name=spec_type,json=specType,proto3" json:"spec_type,omitempty"` // "openapi", "graphql", "asyncapi", "grpc"
 	SpecPath      string                 `protobuf:"bytes,3,opt,name=spec_path,json=specPath,proto3" json:"spec_path,omitempty"`
 	OperationId   string                 `protobuf:"bytes,4,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"` // For OpenAPI operations
 	QueryName     string                 `protobuf:"bytes,5,opt,name=query_name,json=queryName,proto3" json:"query_name,omitempty"`       // For GraphQL queries/mutations
@@ -2514,6 +2626,162 @@ func (x *AgentMetrics) GetToolUsageCount() map[string]int64 {
 	return nil
 }
 
+type SessionLimits struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	RequestsPerMinute         int32                  `protobuf:"varint,1,opt,name=requests_per_minute,json=requestsPerMinute,proto3" json:"requests_per_minute,omitempty"`
+	RequestsRemaining         int32                  `protobuf:"varint,2,opt,name=requests_remaining,json=requestsRemaining,proto3" json:"requests_remaining,omitempty"`
+	WindowResetsAtUnix        int64                  `protobuf:"varint,3,opt,name=window_resets_at_unix,json=windowResetsAtUnix,proto3" json:"window_resets_at_unix,omitempty"`
+	MaxConcurrent             int32                  `protobuf:"varint,4,opt,name=max_concurrent,json=maxConcurrent,proto3" json:"max_concurrent,omitempty"`
+	InFlight                  int32                  `protobuf:"varint,5,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	ConcurrencyHeadroom       int32                  `protobuf:"varint,6,opt,name=concurrency_headroom,json=concurrencyHeadroom,proto3" json:"concurrency_headroom,omitempty"`
+	MaxInvocations            int64                  `protobuf:"varint,7,opt,name=max_invocations,json=maxInvocations,proto3" json:"max_invocations,omitempty"`
+	InvocationsUsed           int64                  `protobuf:"varint,8,opt,name=invocations_used,json=invocationsUsed,proto3" json:"invocations_used,omitempty"`
+	BudgetMs                  int64                  `protobuf:"varint,9,opt,name=budget_ms,json=budgetMs,proto3" json:"budget_ms,omitempty"`
+	BudgetUsedMs              int64                  `protobuf:"varint,10,opt,name=budget_used_ms,json=budgetUsedMs,proto3" json:"budget_used_ms,omitempty"`
+	MaxInvocationsPerDay      int64                  `protobuf:"varint,11,opt,name=max_invocations_per_day,json=maxInvocationsPerDay,proto3" json:"max_invocations_per_day,omitempty"`
+	InvocationsRemainingToday int64                  `protobuf:"varint,12,opt,name=invocations_remaining_today,json=invocationsRemainingToday,proto3" json:"invocations_remaining_today,omitempty"`
+	MaxCostPerDay             float64                `protobuf:"fixed64,13,opt,name=max_cost_per_day,json=maxCostPerDay,proto3" json:"max_cost_per_day,omitempty"`
+	CostRemainingToday        float64                `protobuf:"fixed64,14,opt,name=cost_remaining_today,json=costRemainingToday,proto3" json:"cost_remaining_today,omitempty"`
+	QuotaResetsAtUnix         int64                  `protobuf:"varint,15,opt,name=quota_resets_at_unix,json=quotaResetsAtUnix,proto3" json:"quota_resets_at_unix,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *SessionLimits) Reset() {
+	*x = SessionLimits{}
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionLimits) ProtoMessage() {}
+
+func (x *SessionLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionLimits.ProtoReflect.Descriptor instead.
+func (*SessionLimits) Descriptor() ([]byte, []int) {
+	return file_pkg_agent_proto_agent_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SessionLimits) GetRequestsPerMinute() int32 {
+	if x != nil {
+		return x.RequestsPerMinute
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetRequestsRemaining() int32 {
+	if x != nil {
+		return x.RequestsRemaining
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetWindowResetsAtUnix() int64 {
+	if x != nil {
+		return x.WindowResetsAtUnix
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetMaxConcurrent() int32 {
+	if x != nil {
+		return x.MaxConcurrent
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetInFlight() int32 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetConcurrencyHeadroom() int32 {
+	if x != nil {
+		return x.ConcurrencyHeadroom
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetMaxInvocations() int64 {
+	if x != nil {
+		return x.MaxInvocations
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetInvocationsUsed() int64 {
+	if x != nil {
+		return x.InvocationsUsed
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetBudgetMs() int64 {
+	if x != nil {
+		return x.BudgetMs
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetBudgetUsedMs() int64 {
+	if x != nil {
+		return x.BudgetUsedMs
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetMaxInvocationsPerDay() int64 {
+	if x != nil {
+		return x.MaxInvocationsPerDay
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetInvocationsRemainingToday() int64 {
+	if x != nil {
+		return x.InvocationsRemainingToday
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetMaxCostPerDay() float64 {
+	if x != nil {
+		return x.MaxCostPerDay
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetCostRemainingToday() float64 {
+	if x != nil {
+		return x.CostRemainingToday
+	}
+	return 0
+}
+
+func (x *SessionLimits) GetQuotaResetsAtUnix() int64 {
+	if x != nil {
+		return x.QuotaResetsAtUnix
+	}
+	return 0
+}
+
 type ToolUsageInfo struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	ToolName        string                 `protobuf:"bytes,1,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
@@ -2527,7 +2795,7 @@ type ToolUsageInfo struct {
 
 func (x *ToolUsageInfo) Reset() {
 	*x = ToolUsageInfo{}
-	mi := &file_pkg_agent_proto_agent_proto_msgTypes[30]
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2539,7 +2807,7 @@ func (x *ToolUsageInfo) String() string {
 func (*ToolUsageInfo) ProtoMessage() {}
 
 func (x *ToolUsageInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_agent_proto_agent_proto_msgTypes[30]
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2552,7 +2820,7 @@ func (x *ToolUsageInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ToolUsageInfo.ProtoReflect.Descriptor instead.
 func (*ToolUsageInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_agent_proto_agent_proto_rawDescGZIP(), []int{30}
+	return file_pkg_agent_proto_agent_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *ToolUsageInfo) GetToolName() string {
@@ -2590,11 +2858,115 @@ func (x *ToolUsageInfo) GetErrorMessage() string {
 	return ""
 }
 
+type TerminateSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Sent to the agent in its final event
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TerminateSessionRequest) Reset() {
+	*x = TerminateSessionRequest{}
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TerminateSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminateSessionRequest) ProtoMessage() {}
+
+func (x *TerminateSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminateSessionRequest.ProtoReflect.Descriptor instead.
+func (*TerminateSessionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_agent_proto_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TerminateSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *TerminateSessionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type TerminateSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TerminateSessionResponse) Reset() {
+	*x = TerminateSessionResponse{}
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TerminateSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminateSessionResponse) ProtoMessage() {}
+
+func (x *TerminateSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_agent_proto_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminateSessionResponse.ProtoReflect.Descriptor instead.
+func (*TerminateSessionResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_agent_proto_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TerminateSessionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TerminateSessionResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 var File_pkg_agent_proto_agent_proto protoreflect.FileDescriptor
 
 const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x1bpkg/agent/proto/agent.proto\x12\x10aionmcp.agent.v1\"\x85\x03\n" +
+	"\x1bpkg/agent/proto/agent.proto\x12\x10aionmcp.agent.v1\"\xf8\x03\n" +
 	"\x14RegisterAgentRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
@@ -2602,17 +2974,22 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"\ragent_version\x18\x03 \x01(\tR\fagentVersion\x12G\n" +
 	"\fcapabilities\x18\x04 \x01(\v2#.aionmcp.agent.v1.AgentCapabilitiesR\fcapabilities\x12P\n" +
 	"\bmetadata\x18\x05 \x03(\v24.aionmcp.agent.v1.RegisterAgentRequest.MetadataEntryR\bmetadata\x126\n" +
-	"\x17session_timeout_seconds\x18\x06 \x01(\x05R\x15sessionTimeoutSeconds\x1a;\n" +
+	"\x17session_timeout_seconds\x18\x06 \x01(\x05R\x15sessionTimeoutSeconds\x12)\n" +
+	"\x10resumption_token\x18\a \x01(\tR\x0fresumptionToken\x12#\n" +
+	"\rallowed_tools\x18\b \x03(\tR\fallowedTools\x12!\n" +
+	"\fdenied_tools\x18\t \x03(\tR\vdeniedTools\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe2\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa7\x02\n" +
 	"\x15RegisterAgentResponse\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12&\n" +
 	"\x0fexpires_at_unix\x18\x02 \x01(\x03R\rexpiresAtUnix\x12=\n" +
 	"\vserver_info\x18\x03 \x01(\v2\x1c.aionmcp.agent.v1.ServerInfoR\n" +
 	"serverInfo\x12C\n" +
-	"\x0favailable_tools\x18\x04 \x03(\v2\x1a.aionmcp.agent.v1.ToolInfoR\x0eavailableTools\"7\n" +
+	"\x0favailable_tools\x18\x04 \x03(\v2\x1a.aionmcp.agent.v1.ToolInfoR\x0eavailableTools\x12)\n" +
+	"\x10resumption_token\x18\x05 \x01(\tR\x0fresumptionToken\x12\x18\n" +
+	"\aresumed\x18\x06 \x01(\bR\aresumed\"7\n" +
 	"\x16UnregisterAgentRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\"M\n" +
@@ -2649,7 +3026,7 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"\ttool_name\x18\x02 \x01(\tR\btoolName\x12'\n" +
 	"\x0fparameters_json\x18\x03 \x01(\tR\x0eparametersJson\x12A\n" +
 	"\aoptions\x18\x04 \x01(\v2'.aionmcp.agent.v1.ToolInvocationOptionsR\aoptions\x12#\n" +
-	"\rinvocation_id\x18\x05 \x01(\tR\finvocationId\"\xb0\x02\n" +
+	"\rinvocation_id\x18\x05 \x01(\tR\finvocationId\"\xf6\x02\n" +
 	"\x12InvokeToolResponse\x12#\n" +
 	"\rinvocation_id\x18\x01 \x01(\tR\finvocationId\x12>\n" +
 	"\x06status\x18\x02 \x01(\x0e2&.aionmcp.agent.v1.ToolInvocationStatusR\x06status\x12\x1f\n" +
@@ -2657,28 +3034,36 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"resultJson\x121\n" +
 	"\x05error\x18\x04 \x01(\v2\x1b.aionmcp.agent.v1.ToolErrorR\x05error\x127\n" +
 	"\ametrics\x18\x05 \x01(\v2\x1d.aionmcp.agent.v1.ToolMetricsR\ametrics\x12(\n" +
-	"\x10executed_at_unix\x18\x06 \x01(\x03R\x0eexecutedAtUnix\"\x9b\x01\n" +
+	"\x10executed_at_unix\x18\x06 \x01(\x03R\x0eexecutedAtUnix\x12\x1f\n" +
+	"\vresult_data\x18\a \x01(\fR\n" +
+	"resultData\x12#\n" +
+	"\rresult_format\x18\b \x01(\tR\fresultFormat\"\xe1\x01\n" +
 	"\x13StreamEventsRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12<\n" +
 	"\vevent_types\x18\x02 \x03(\x0e2\x1b.aionmcp.agent.v1.EventTypeR\n" +
 	"eventTypes\x12'\n" +
-	"\x0finclude_history\x18\x03 \x01(\bR\x0eincludeHistory\"\xb6\x01\n" +
+	"\x0finclude_history\x18\x03 \x01(\bR\x0eincludeHistory\x12%\n" +
+	"\x0esince_sequence\x18\x04 \x01(\x04R\rsinceSequence\x12\x1d\n" +
+	"\n" +
+	"tool_names\x18\x05 \x03(\tR\ttoolNames\"\xd2\x01\n" +
 	"\x05Event\x12\x19\n" +
 	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12/\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x1b.aionmcp.agent.v1.EventTypeR\x04type\x12%\n" +
 	"\x0etimestamp_unix\x18\x03 \x01(\x03R\rtimestampUnix\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x04 \x01(\tR\tsessionId\x12\x1b\n" +
-	"\tdata_json\x18\x05 \x01(\tR\bdataJson\"h\n" +
+	"\tdata_json\x18\x05 \x01(\tR\bdataJson\x12\x1a\n" +
+	"\bsequence\x18\x06 \x01(\x04R\bsequence\"h\n" +
 	"\x10HeartBeatRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x125\n" +
-	"\x06status\x18\x02 \x01(\x0e2\x1d.aionmcp.agent.v1.AgentStatusR\x06status\"\xa2\x01\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1d.aionmcp.agent.v1.AgentStatusR\x06status\"\xdb\x01\n" +
 	"\x11HeartBeatResponse\x12#\n" +
 	"\rsession_valid\x18\x01 \x01(\bR\fsessionValid\x123\n" +
 	"\x16next_heartbeat_at_unix\x18\x02 \x01(\x03R\x13nextHeartbeatAtUnix\x123\n" +
-	"\x15pending_notifications\x18\x03 \x03(\tR\x14pendingNotifications\"6\n" +
+	"\x15pending_notifications\x18\x03 \x03(\tR\x14pendingNotifications\x127\n" +
+	"\x06limits\x18\x04 \x01(\v2\x1f.aionmcp.agent.v1.SessionLimitsR\x06limits\"6\n" +
 	"\x15GetAgentStatusRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\"\xe6\x01\n" +
@@ -2717,7 +3102,7 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"\x06source\x18\v \x01(\v2\x1c.aionmcp.agent.v1.ToolSourceR\x06source\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8b\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa5\x02\n" +
 	"\n" +
 	"ToolFilter\x120\n" +
 	"\x05types\x18\x01 \x03(\x0e2\x1a.aionmcp.agent.v1.ToolTypeR\x05types\x128\n" +
@@ -2725,19 +3110,23 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"\x04tags\x18\x03 \x03(\tR\x04tags\x12!\n" +
 	"\fname_pattern\x18\x04 \x01(\tR\vnamePattern\x12,\n" +
 	"\x12created_after_unix\x18\x05 \x01(\x03R\x10createdAfterUnix\x12,\n" +
-	"\x12updated_after_unix\x18\x06 \x01(\x03R\x10updatedAfterUnix\"z\n" +
+	"\x12updated_after_unix\x18\x06 \x01(\x03R\x10updatedAfterUnix\x12\x18\n" +
+	"\asources\x18\a \x03(\tR\asources\"\x92\x01\n" +
 	"\x11PaginationOptions\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
 	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x17\n" +
 	"\asort_by\x18\x03 \x01(\tR\x06sortBy\x12\x1b\n" +
-	"\tsort_desc\x18\x04 \x01(\bR\bsortDesc\"\xb3\x01\n" +
+	"\tsort_desc\x18\x04 \x01(\bR\bsortDesc\x12\x16\n" +
+	"\x06cursor\x18\x05 \x01(\tR\x06cursor\"\xd4\x01\n" +
 	"\x12PaginationMetadata\x12!\n" +
 	"\fcurrent_page\x18\x01 \x01(\x05R\vcurrentPage\x12\x1b\n" +
 	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1f\n" +
 	"\vtotal_pages\x18\x03 \x01(\x05R\n" +
 	"totalPages\x12\x19\n" +
 	"\bhas_next\x18\x04 \x01(\bR\ahasNext\x12!\n" +
-	"\fhas_previous\x18\x05 \x01(\bR\vhasPrevious\"\x94\x01\n" +
+	"\fhas_previous\x18\x05 \x01(\bR\vhasPrevious\x12\x1f\n" +
+	"\vnext_cursor\x18\x06 \x01(\tR\n" +
+	"nextCursor\"\x94\x01\n" +
 	"\vToolExample\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1d\n" +
@@ -2801,13 +3190,37 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"\x10tool_usage_count\x18\x06 \x03(\v22.aionmcp.agent.v1.AgentMetrics.ToolUsageCountEntryR\x0etoolUsageCount\x1aA\n" +
 	"\x13ToolUsageCountEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xe5\x01\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xb2\x05\n" +
+	"\rSessionLimits\x12.\n" +
+	"\x13requests_per_minute\x18\x01 \x01(\x05R\x11requestsPerMinute\x12-\n" +
+	"\x12requests_remaining\x18\x02 \x01(\x05R\x11requestsRemaining\x121\n" +
+	"\x15window_resets_at_unix\x18\x03 \x01(\x03R\x12windowResetsAtUnix\x12%\n" +
+	"\x0emax_concurrent\x18\x04 \x01(\x05R\rmaxConcurrent\x12\x1b\n" +
+	"\tin_flight\x18\x05 \x01(\x05R\binFlight\x121\n" +
+	"\x14concurrency_headroom\x18\x06 \x01(\x05R\x13concurrencyHeadroom\x12'\n" +
+	"\x0fmax_invocations\x18\a \x01(\x03R\x0emaxInvocations\x12)\n" +
+	"\x10invocations_used\x18\b \x01(\x03R\x0finvocationsUsed\x12\x1b\n" +
+	"\tbudget_ms\x18\t \x01(\x03R\bbudgetMs\x12$\n" +
+	"\x0ebudget_used_ms\x18\n" +
+	" \x01(\x03R\fbudgetUsedMs\x125\n" +
+	"\x17max_invocations_per_day\x18\v \x01(\x03R\x14maxInvocationsPerDay\x12>\n" +
+	"\x1binvocations_remaining_today\x18\f \x01(\x03R\x19invocationsRemainingToday\x12'\n" +
+	"\x10max_cost_per_day\x18\r \x01(\x01R\rmaxCostPerDay\x120\n" +
+	"\x14cost_remaining_today\x18\x0e \x01(\x01R\x12costRemainingToday\x12/\n" +
+	"\x14quota_resets_at_unix\x18\x0f \x01(\x03R\x11quotaResetsAtUnix\"\xe5\x01\n" +
 	"\rToolUsageInfo\x12\x1b\n" +
 	"\ttool_name\x18\x01 \x01(\tR\btoolName\x12&\n" +
 	"\x0finvoked_at_unix\x18\x02 \x01(\x03R\rinvokedAtUnix\x12>\n" +
 	"\x06status\x18\x03 \x01(\x0e2&.aionmcp.agent.v1.ToolInvocationStatusR\x06status\x12*\n" +
 	"\x11execution_time_ms\x18\x04 \x01(\x03R\x0fexecutionTimeMs\x12#\n" +
-	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage*\x99\x01\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"P\n" +
+	"\x17TerminateSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"N\n" +
+	"\x18TerminateSessionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage*\x99\x01\n" +
 	"\bToolType\x12\x19\n" +
 	"\x15TOOL_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11TOOL_TYPE_OPENAPI\x10\x01\x12\x15\n" +
@@ -2856,7 +3269,7 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"\x11AGENT_STATUS_IDLE\x10\x02\x12\x15\n" +
 	"\x11AGENT_STATUS_BUSY\x10\x03\x12\x1d\n" +
 	"\x19AGENT_STATUS_DISCONNECTED\x10\x04\x12\x16\n" +
-	"\x12AGENT_STATUS_ERROR\x10\x052\xe4\x05\n" +
+	"\x12AGENT_STATUS_ERROR\x10\x052\xcf\x06\n" +
 	"\fAgentService\x12`\n" +
 	"\rRegisterAgent\x12&.aionmcp.agent.v1.RegisterAgentRequest\x1a'.aionmcp.agent.v1.RegisterAgentResponse\x12f\n" +
 	"\x0fUnregisterAgent\x12(.aionmcp.agent.v1.UnregisterAgentRequest\x1a).aionmcp.agent.v1.UnregisterAgentResponse\x12T\n" +
@@ -2866,7 +3279,8 @@ const file_pkg_agent_proto_agent_proto_rawDesc = "" +
 	"InvokeTool\x12#.aionmcp.agent.v1.InvokeToolRequest\x1a$.aionmcp.agent.v1.InvokeToolResponse\x12P\n" +
 	"\fStreamEvents\x12%.aionmcp.agent.v1.StreamEventsRequest\x1a\x17.aionmcp.agent.v1.Event0\x01\x12T\n" +
 	"\tHeartBeat\x12\".aionmcp.agent.v1.HeartBeatRequest\x1a#.aionmcp.agent.v1.HeartBeatResponse\x12c\n" +
-	"\x0eGetAgentStatus\x12'.aionmcp.agent.v1.GetAgentStatusRequest\x1a(.aionmcp.agent.v1.GetAgentStatusResponseB4Z2github.com/aionmcp/aionmcp/pkg/agent/proto;agentpbb\x06proto3"
+	"\x0eGetAgentStatus\x12'.aionmcp.agent.v1.GetAgentStatusRequest\x1a(.aionmcp.agent.v1.GetAgentStatusResponse\x12i\n" +
+	"\x10TerminateSession\x12).aionmcp.agent.v1.TerminateSessionRequest\x1a*.aionmcp.agent.v1.TerminateSessionResponseB4Z2github.com/aionmcp/aionmcp/pkg/agent/proto;agentpbb\x06proto3"
 
 var (
 	file_pkg_agent_proto_agent_proto_rawDescOnce sync.Once
@@ -2881,55 +3295,58 @@ func file_pkg_agent_proto_agent_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_agent_proto_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_pkg_agent_proto_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
+var file_pkg_agent_proto_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_pkg_agent_proto_agent_proto_goTypes = []any{
-	(ToolType)(0),                   // 0: aionmcp.agent.v1.ToolType
-	(ToolStatus)(0),                 // 1: aionmcp.agent.v1.ToolStatus
-	(ToolInvocationStatus)(0),       // 2: aionmcp.agent.v1.ToolInvocationStatus
-	(ErrorCode)(0),                  // 3: aionmcp.agent.v1.ErrorCode
-	(EventType)(0),                  // 4: aionmcp.agent.v1.EventType
-	(AgentStatus)(0),                // 5: aionmcp.agent.v1.AgentStatus
-	(*RegisterAgentRequest)(nil),    // 6: aionmcp.agent.v1.RegisterAgentRequest
-	(*RegisterAgentResponse)(nil),   // 7: aionmcp.agent.v1.RegisterAgentResponse
-	(*UnregisterAgentRequest)(nil),  // 8: aionmcp.agent.v1.UnregisterAgentRequest
-	(*UnregisterAgentResponse)(nil), // 9: aionmcp.agent.v1.UnregisterAgentResponse
-	(*ListToolsRequest)(nil),        // 10: aionmcp.agent.v1.ListToolsRequest
-	(*ListToolsResponse)(nil),       // 11: aionmcp.agent.v1.ListToolsResponse
-	(*GetToolRequest)(nil),          // 12: aionmcp.agent.v1.GetToolRequest
-	(*GetToolResponse)(nil),         // 13: aionmcp.agent.v1.GetToolResponse
-	(*InvokeToolRequest)(nil),       // 14: aionmcp.agent.v1.InvokeToolRequest
-	(*InvokeToolResponse)(nil),      // 15: aionmcp.agent.v1.InvokeToolResponse
-	(*StreamEventsRequest)(nil),     // 16: aionmcp.agent.v1.StreamEventsRequest
-	(*Event)(nil),                   // 17: aionmcp.agent.v1.Event
-	(*HeartBeatRequest)(nil),        // 18: aionmcp.agent.v1.HeartBeatRequest
-	(*HeartBeatResponse)(nil),       // 19: aionmcp.agent.v1.HeartBeatResponse
-	(*GetAgentStatusRequest)(nil),   // 20: aionmcp.agent.v1.GetAgentStatusRequest
-	(*GetAgentStatusResponse)(nil),  // 21: aionmcp.agent.v1.GetAgentStatusResponse
-	(*AgentCapabilities)(nil),       // 22: aionmcp.agent.v1.AgentCapabilities
-	(*ServerInfo)(nil),              // 23: aionmcp.agent.v1.ServerInfo
-	(*ToolInfo)(nil),                // 24: aionmcp.agent.v1.ToolInfo
-	(*ToolFilter)(nil),              // 25: aionmcp.agent.v1.ToolFilter
-	(*PaginationOptions)(nil),       // 26: aionmcp.agent.v1.PaginationOptions
-	(*PaginationMetadata)(nil),      // 27: aionmcp.agent.v1.PaginationMetadata
-	(*ToolExample)(nil),             // 28: aionmcp.agent.v1.ToolExample
-	(*ToolInvocationOptions)(nil),   // 29: aionmcp.agent.v1.ToolInvocationOptions
-	(*ToolRetryPolicy)(nil),         // 30: aionmcp.agent.v1.ToolRetryPolicy
-	(*ToolError)(nil),               // 31: aionmcp.agent.v1.ToolError
-	(*ToolMetrics)(nil),             // 32: aionmcp.agent.v1.ToolMetrics
-	(*ToolSource)(nil),              // 33: aionmcp.agent.v1.ToolSource
-	(*AgentSessionInfo)(nil),        // 34: aionmcp.agent.v1.AgentSessionInfo
-	(*AgentMetrics)(nil),            // 35: aionmcp.agent.v1.AgentMetrics
-	(*ToolUsageInfo)(nil),           // 36: aionmcp.agent.v1.ToolUsageInfo
-	nil,                             // 37: aionmcp.agent.v1.RegisterAgentRequest.MetadataEntry
-	nil,                             // 38: aionmcp.agent.v1.ServerInfo.CapabilitiesEntry
-	nil,                             // 39: aionmcp.agent.v1.ToolInfo.MetadataEntry
-	nil,                             // 40: aionmcp.agent.v1.ToolInvocationOptions.ContextEntry
-	nil,                             // 41: aionmcp.agent.v1.ToolMetrics.CustomMetricsEntry
-	nil,                             // 42: aionmcp.agent.v1.AgentMetrics.ToolUsageCountEntry
+	(ToolType)(0),                    // 0: aionmcp.agent.v1.ToolType
+	(ToolStatus)(0),                  // 1: aionmcp.agent.v1.ToolStatus
+	(ToolInvocationStatus)(0),        // 2: aionmcp.agent.v1.ToolInvocationStatus
+	(ErrorCode)(0),                   // 3: aionmcp.agent.v1.ErrorCode
+	(EventType)(0),                   // 4: aionmcp.agent.v1.EventType
+	(AgentStatus)(0),                 // 5: aionmcp.agent.v1.AgentStatus
+	(*RegisterAgentRequest)(nil),     // 6: aionmcp.agent.v1.RegisterAgentRequest
+	(*RegisterAgentResponse)(nil),    // 7: aionmcp.agent.v1.RegisterAgentResponse
+	(*UnregisterAgentRequest)(nil),   // 8: aionmcp.agent.v1.UnregisterAgentRequest
+	(*UnregisterAgentResponse)(nil),  // 9: aionmcp.agent.v1.UnregisterAgentResponse
+	(*ListToolsRequest)(nil),         // 10: aionmcp.agent.v1.ListToolsRequest
+	(*ListToolsResponse)(nil),        // 11: aionmcp.agent.v1.ListToolsResponse
+	(*GetToolRequest)(nil),           // 12: aionmcp.agent.v1.GetToolRequest
+	(*GetToolResponse)(nil),          // 13: aionmcp.agent.v1.GetToolResponse
+	(*InvokeToolRequest)(nil),        // 14: aionmcp.agent.v1.InvokeToolRequest
+	(*InvokeToolResponse)(nil),       // 15: aionmcp.agent.v1.InvokeToolResponse
+	(*StreamEventsRequest)(nil),      // 16: aionmcp.agent.v1.StreamEventsRequest
+	(*Event)(nil),                    // 17: aionmcp.agent.v1.Event
+	(*HeartBeatRequest)(nil),         // 18: aionmcp.agent.v1.HeartBeatRequest
+	(*HeartBeatResponse)(nil),        // 19: aionmcp.agent.v1.HeartBeatResponse
+	(*GetAgentStatusRequest)(nil),    // 20: aionmcp.agent.v1.GetAgentStatusRequest
+	(*GetAgentStatusResponse)(nil),   // 21: aionmcp.agent.v1.GetAgentStatusResponse
+	(*AgentCapabilities)(nil),        // 22: aionmcp.agent.v1.AgentCapabilities
+	(*ServerInfo)(nil),               // 23: aionmcp.agent.v1.ServerInfo
+	(*ToolInfo)(nil),                 // 24: aionmcp.agent.v1.ToolInfo
+	(*ToolFilter)(nil),               // 25: aionmcp.agent.v1.ToolFilter
+	(*PaginationOptions)(nil),        // 26: aionmcp.agent.v1.PaginationOptions
+	(*PaginationMetadata)(nil),       // 27: aionmcp.agent.v1.PaginationMetadata
+	(*ToolExample)(nil),              // 28: aionmcp.agent.v1.ToolExample
+	(*ToolInvocationOptions)(nil),    // 29: aionmcp.agent.v1.ToolInvocationOptions
+	(*ToolRetryPolicy)(nil),          // 30: aionmcp.agent.v1.ToolRetryPolicy
+	(*ToolError)(nil),                // 31: aionmcp.agent.v1.ToolError
+	(*ToolMetrics)(nil),              // 32: aionmcp.agent.v1.ToolMetrics
+	(*ToolSource)(nil),               // 33: aionmcp.agent.v1.ToolSource
+	(*AgentSessionInfo)(nil),         // 34: aionmcp.agent.v1.AgentSessionInfo
+	(*AgentMetrics)(nil),             // 35: aionmcp.agent.v1.AgentMetrics
+	(*SessionLimits)(nil),            // 36: aionmcp.agent.v1.SessionLimits
+	(*ToolUsageInfo)(nil),            // 37: aionmcp.agent.v1.ToolUsageInfo
+	(*TerminateSessionRequest)(nil),  // 38: aionmcp.agent.v1.TerminateSessionRequest
+	(*TerminateSessionResponse)(nil), // 39: aionmcp.agent.v1.TerminateSessionResponse
+	nil,                              // 40: aionmcp.agent.v1.RegisterAgentRequest.MetadataEntry
+	nil,                              // 41: aionmcp.agent.v1.ServerInfo.CapabilitiesEntry
+	nil,                              // 42: aionmcp.agent.v1.ToolInfo.MetadataEntry
+	nil,                              // 43: aionmcp.agent.v1.ToolInvocationOptions.ContextEntry
+	nil,                              // 44: aionmcp.agent.v1.ToolMetrics.CustomMetricsEntry
+	nil,                              // 45: aionmcp.agent.v1.AgentMetrics.ToolUsageCountEntry
 }
 var file_pkg_agent_proto_agent_proto_depIdxs = []int32{
 	22, // 0: aionmcp.agent.v1.RegisterAgentRequest.capabilities:type_name -> aionmcp.agent.v1.AgentCapabilities
-	37, // 1: aionmcp.agent.v1.RegisterAgentRequest.metadata:type_name -> aionmcp.agent.v1.RegisterAgentRequest.MetadataEntry
+	40, // 1: aionmcp.agent.v1.RegisterAgentRequest.metadata:type_name -> aionmcp.agent.v1.RegisterAgentRequest.MetadataEntry
 	23, // 2: aionmcp.agent.v1.RegisterAgentResponse.server_info:type_name -> aionmcp.agent.v1.ServerInfo
 	24, // 3: aionmcp.agent.v1.RegisterAgentResponse.available_tools:type_name -> aionmcp.agent.v1.ToolInfo
 	25, // 4: aionmcp.agent.v1.ListToolsRequest.filter:type_name -> aionmcp.agent.v1.ToolFilter
@@ -2945,45 +3362,48 @@ var file_pkg_agent_proto_agent_proto_depIdxs = []int32{
 	4,  // 14: aionmcp.agent.v1.StreamEventsRequest.event_types:type_name -> aionmcp.agent.v1.EventType
 	4,  // 15: aionmcp.agent.v1.Event.type:type_name -> aionmcp.agent.v1.EventType
 	5,  // 16: aionmcp.agent.v1.HeartBeatRequest.status:type_name -> aionmcp.agent.v1.AgentStatus
-	34, // 17: aionmcp.agent.v1.GetAgentStatusResponse.session_info:type_name -> aionmcp.agent.v1.AgentSessionInfo
-	35, // 18: aionmcp.agent.v1.GetAgentStatusResponse.metrics:type_name -> aionmcp.agent.v1.AgentMetrics
-	36, // 19: aionmcp.agent.v1.GetAgentStatusResponse.recent_tool_usage:type_name -> aionmcp.agent.v1.ToolUsageInfo
-	38, // 20: aionmcp.agent.v1.ServerInfo.capabilities:type_name -> aionmcp.agent.v1.ServerInfo.CapabilitiesEntry
-	0,  // 21: aionmcp.agent.v1.ToolInfo.type:type_name -> aionmcp.agent.v1.ToolType
-	1,  // 22: aionmcp.agent.v1.ToolInfo.status:type_name -> aionmcp.agent.v1.ToolStatus
-	39, // 23: aionmcp.agent.v1.ToolInfo.metadata:type_name -> aionmcp.agent.v1.ToolInfo.MetadataEntry
-	33, // 24: aionmcp.agent.v1.ToolInfo.source:type_name -> aionmcp.agent.v1.ToolSource
-	0,  // 25: aionmcp.agent.v1.ToolFilter.types:type_name -> aionmcp.agent.v1.ToolType
-	1,  // 26: aionmcp.agent.v1.ToolFilter.statuses:type_name -> aionmcp.agent.v1.ToolStatus
-	40, // 27: aionmcp.agent.v1.ToolInvocationOptions.context:type_name -> aionmcp.agent.v1.ToolInvocationOptions.ContextEntry
-	30, // 28: aionmcp.agent.v1.ToolInvocationOptions.retry_policy:type_name -> aionmcp.agent.v1.ToolRetryPolicy
-	3,  // 29: aionmcp.agent.v1.ToolError.code:type_name -> aionmcp.agent.v1.ErrorCode
-	41, // 30: aionmcp.agent.v1.ToolMetrics.custom_metrics:type_name -> aionmcp.agent.v1.ToolMetrics.CustomMetricsEntry
-	5,  // 31: aionmcp.agent.v1.AgentSessionInfo.status:type_name -> aionmcp.agent.v1.AgentStatus
-	22, // 32: aionmcp.agent.v1.AgentSessionInfo.capabilities:type_name -> aionmcp.agent.v1.AgentCapabilities
-	42, // 33: aionmcp.agent.v1.AgentMetrics.tool_usage_count:type_name -> aionmcp.agent.v1.AgentMetrics.ToolUsageCountEntry
-	2,  // 34: aionmcp.agent.v1.ToolUsageInfo.status:type_name -> aionmcp.agent.v1.ToolInvocationStatus
-	6,  // 35: aionmcp.agent.v1.AgentService.RegisterAgent:input_type -> aionmcp.agent.v1.RegisterAgentRequest
-	8,  // 36: aionmcp.agent.v1.AgentService.UnregisterAgent:input_type -> aionmcp.agent.v1.UnregisterAgentRequest
-	10, // 37: aionmcp.agent.v1.AgentService.ListTools:input_type -> aionmcp.agent.v1.ListToolsRequest
-	12, // 38: aionmcp.agent.v1.AgentService.GetTool:input_type -> aionmcp.agent.v1.GetToolRequest
-	14, // 39: aionmcp.agent.v1.AgentService.InvokeTool:input_type -> aionmcp.agent.v1.InvokeToolRequest
-	16, // 40: aionmcp.agent.v1.AgentService.StreamEvents:input_type -> aionmcp.agent.v1.StreamEventsRequest
-	18, // 41: aionmcp.agent.v1.AgentService.HeartBeat:input_type -> aionmcp.agent.v1.HeartBeatRequest
-	20, // 42: aionmcp.agent.v1.AgentService.GetAgentStatus:input_type -> aionmcp.agent.v1.GetAgentStatusRequest
-	7,  // 43: aionmcp.agent.v1.AgentService.RegisterAgent:output_type -> aionmcp.agent.v1.RegisterAgentResponse
-	9,  // 44: aionmcp.agent.v1.AgentService.UnregisterAgent:output_type -> aionmcp.agent.v1.UnregisterAgentResponse
-	11, // 45: aionmcp.agent.v1.AgentService.ListTools:output_type -> aionmcp.agent.v1.ListToolsResponse
-	13, // 46: aionmcp.agent.v1.AgentService.GetTool:output_type -> aionmcp.agent.v1.GetToolResponse
-	15, // 47: aionmcp.agent.v1.AgentService.InvokeTool:output_type -> aionmcp.agent.v1.InvokeToolResponse
-	17, // 48: aionmcp.agent.v1.AgentService.StreamEvents:output_type -> aionmcp.agent.v1.Event
-	19, // 49: aionmcp.agent.v1.AgentService.HeartBeat:output_type -> aionmcp.agent.v1.HeartBeatResponse
-	21, // 50: aionmcp.agent.v1.AgentService.GetAgentStatus:output_type -> aionmcp.agent.v1.GetAgentStatusResponse
-	43, // [43:51] is the sub-list for method output_type
-	35, // [35:43] is the sub-list for method input_type
-	35, // [35:35] is the sub-list for extension type_name
-	35, // [35:35] is the sub-list for extension extendee
-	0,  // [0:35] is the sub-list for field type_name
+	36, // 17: aionmcp.agent.v1.HeartBeatResponse.limits:type_name -> aionmcp.agent.v1.SessionLimits
+	34, // 18: aionmcp.agent.v1.GetAgentStatusResponse.session_info:type_name -> aionmcp.agent.v1.AgentSessionInfo
+	35, // 19: aionmcp.agent.v1.GetAgentStatusResponse.metrics:type_name -> aionmcp.agent.v1.AgentMetrics
+	37, // 20: aionmcp.agent.v1.GetAgentStatusResponse.recent_tool_usage:type_name -> aionmcp.agent.v1.ToolUsageInfo
+	41, // 21: aionmcp.agent.v1.ServerInfo.capabilities:type_name -> aionmcp.agent.v1.ServerInfo.CapabilitiesEntry
+	0,  // 22: aionmcp.agent.v1.ToolInfo.type:type_name -> aionmcp.agent.v1.ToolType
+	1,  // 23: aionmcp.agent.v1.ToolInfo.status:type_name -> aionmcp.agent.v1.ToolStatus
+	42, // 24: aionmcp.agent.v1.ToolInfo.metadata:type_name -> aionmcp.agent.v1.ToolInfo.MetadataEntry
+	33, // 25: aionmcp.agent.v1.ToolInfo.source:type_name -> aionmcp.agent.v1.ToolSource
+	0,  // 26: aionmcp.agent.v1.ToolFilter.types:type_name -> aionmcp.agent.v1.ToolType
+	1,  // 27: aionmcp.agent.v1.ToolFilter.statuses:type_name -> aionmcp.agent.v1.ToolStatus
+	43, // 28: aionmcp.agent.v1.ToolInvocationOptions.context:type_name -> aionmcp.agent.v1.ToolInvocationOptions.ContextEntry
+	30, // 29: aionmcp.agent.v1.ToolInvocationOptions.retry_policy:type_name -> aionmcp.agent.v1.ToolRetryPolicy
+	3,  // 30: aionmcp.agent.v1.ToolError.code:type_name -> aionmcp.agent.v1.ErrorCode
+	44, // 31: aionmcp.agent.v1.ToolMetrics.custom_metrics:type_name -> aionmcp.agent.v1.ToolMetrics.CustomMetricsEntry
+	5,  // 32: aionmcp.agent.v1.AgentSessionInfo.status:type_name -> aionmcp.agent.v1.AgentStatus
+	22, // 33: aionmcp.agent.v1.AgentSessionInfo.capabilities:type_name -> aionmcp.agent.v1.AgentCapabilities
+	45, // 34: aionmcp.agent.v1.AgentMetrics.tool_usage_count:type_name -> aionmcp.agent.v1.AgentMetrics.ToolUsageCountEntry
+	2,  // 35: aionmcp.agent.v1.ToolUsageInfo.status:type_name -> aionmcp.agent.v1.ToolInvocationStatus
+	6,  // 36: aionmcp.agent.v1.AgentService.RegisterAgent:input_type -> aionmcp.agent.v1.RegisterAgentRequest
+	8,  // 37: aionmcp.agent.v1.AgentService.UnregisterAgent:input_type -> aionmcp.agent.v1.UnregisterAgentRequest
+	10, // 38: aionmcp.agent.v1.AgentService.ListTools:input_type -> aionmcp.agent.v1.ListToolsRequest
+	12, // 39: aionmcp.agent.v1.AgentService.GetTool:input_type -> aionmcp.agent.v1.GetToolRequest
+	14, // 40: aionmcp.agent.v1.AgentService.InvokeTool:input_type -> aionmcp.agent.v1.InvokeToolRequest
+	16, // 41: aionmcp.agent.v1.AgentService.StreamEvents:input_type -> aionmcp.agent.v1.StreamEventsRequest
+	18, // 42: aionmcp.agent.v1.AgentService.HeartBeat:input_type -> aionmcp.agent.v1.HeartBeatRequest
+	20, // 43: aionmcp.agent.v1.AgentService.GetAgentStatus:input_type -> aionmcp.agent.v1.GetAgentStatusRequest
+	38, // 44: aionmcp.agent.v1.AgentService.TerminateSession:input_type -> aionmcp.agent.v1.TerminateSessionRequest
+	7,  // 45: aionmcp.agent.v1.AgentService.RegisterAgent:output_type -> aionmcp.agent.v1.RegisterAgentResponse
+	9,  // 46: aionmcp.agent.v1.AgentService.UnregisterAgent:output_type -> aionmcp.agent.v1.UnregisterAgentResponse
+	11, // 47: aionmcp.agent.v1.AgentService.ListTools:output_type -> aionmcp.agent.v1.ListToolsResponse
+	13, // 48: aionmcp.agent.v1.AgentService.GetTool:output_type -> aionmcp.agent.v1.GetToolResponse
+	15, // 49: aionmcp.agent.v1.AgentService.InvokeTool:output_type -> aionmcp.agent.v1.InvokeToolResponse
+	17, // 50: aionmcp.agent.v1.AgentService.StreamEvents:output_type -> aionmcp.agent.v1.Event
+	19, // 51: aionmcp.agent.v1.AgentService.HeartBeat:output_type -> aionmcp.agent.v1.HeartBeatResponse
+	21, // 52: aionmcp.agent.v1.AgentService.GetAgentStatus:output_type -> aionmcp.agent.v1.GetAgentStatusResponse
+	39, // 53: aionmcp.agent.v1.AgentService.TerminateSession:output_type -> aionmcp.agent.v1.TerminateSessionResponse
+	45, // [45:54] is the sub-list for method output_type
+	36, // [36:45] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
 }
 
 func init() { file_pkg_agent_proto_agent_proto_init() }
@@ -2997,7 +3417,7 @@ func file_pkg_agent_proto_agent_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_agent_proto_agent_proto_rawDesc), len(file_pkg_agent_proto_agent_proto_rawDesc)),
 			NumEnums:      6,
-			NumMessages:   37,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,14 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AgentService_RegisterAgent_FullMethodName   = "/aionmcp.agent.v1.AgentService/RegisterAgent"
-	AgentService_UnregisterAgent_FullMethodName = "/aionmcp.agent.v1.AgentService/UnregisterAgent"
-	AgentService_ListTools_FullMethodName       = "/aionmcp.agent.v1.AgentService/ListTools"
-	AgentService_GetTool_FullMethodName         = "/aionmcp.agent.v1.AgentService/GetTool"
-	AgentService_InvokeTool_FullMethodName      = "/aionmcp.agent.v1.AgentService/InvokeTool"
-	AgentService_StreamEvents_FullMethodName    = "/aionmcp.agent.v1.AgentService/StreamEvents"
-	AgentService_HeartBeat_FullMethodName       = "/aionmcp.agent.v1.AgentService/HeartBeat"
-	AgentService_GetAgentStatus_FullMethodName  = "/aionmcp.agent.v1.AgentService/GetAgentStatus"
+	AgentService_RegisterAgent_FullMethodName    = "/aionmcp.agent.v1.AgentService/RegisterAgent"
+	AgentService_UnregisterAgent_FullMethodName  = "/aionmcp.agent.v1.AgentService/UnregisterAgent"
+	AgentService_ListTools_FullMethodName        = "/aionmcp.agent.v1.AgentService/ListTools"
+	AgentService_GetTool_FullMethodName          = "/aionmcp.agent.v1.AgentService/GetTool"
+	AgentService_InvokeTool_FullMethodName       = "/aionmcp.agent.v1.AgentService/InvokeTool"
+	AgentService_StreamEvents_FullMethodName     = "/aionmcp.agent.v1.AgentService/StreamEvents"
+	AgentService_HeartBeat_FullMethodName        = "/aionmcp.agent.v1.AgentService/HeartBeat"
+	AgentService_GetAgentStatus_FullMethodName   = "/aionmcp.agent.v1.AgentService/GetAgentStatus"
+	AgentService_TerminateSession_FullMethodName = "/aionmcp.agent.v1.AgentService/TerminateSession"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -51,6 +52,8 @@ type AgentServiceClient interface {
 	HeartBeat(ctx context.Context, in *HeartBeatRequest, opts ...grpc.CallOption) (*HeartBeatResponse, error)
 	// GetAgentStatus returns current agent session information
 	GetAgentStatus(ctx context.Context, in *GetAgentStatusRequest, opts ...grpc.CallOption) (*GetAgentStatusResponse, error)
+	// TerminateSession ends any agent session on an administrator's behalf
+	TerminateSession(ctx context.Context, in *TerminateSessionRequest, opts ...grpc.CallOption) (*TerminateSessionResponse, error)
 }
 
 type agentServiceClient struct {
@@ -150,6 +153,16 @@ func (c *agentServiceClient) GetAgentStatus(ctx context.Context, in *GetAgentSta
 	return out, nil
 }
 
+func (c *agentServiceClient) TerminateSession(ctx context.Context, in *TerminateSessionRequest, opts ...grpc.CallOption) (*TerminateSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TerminateSessionResponse)
+	err := c.cc.Invoke(ctx, AgentService_TerminateSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
@@ -172,6 +185,8 @@ type AgentServiceServer interface {
 	HeartBeat(context.Context, *HeartBeatRequest) (*HeartBeatResponse, error)
 	// GetAgentStatus returns current agent session information
 	GetAgentStatus(context.Context, *GetAgentStatusRequest) (*GetAgentStatusResponse, error)
+	// TerminateSession ends any agent session on an administrator's behalf
+	TerminateSession(context.Context, *TerminateSessionRequest) (*TerminateSessionResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -206,6 +221,9 @@ func (UnimplementedAgentServiceServer) HeartBeat(context.Context, *HeartBeatRequ
 func (UnimplementedAgentServiceServer) GetAgentStatus(context.Context, *GetAgentStatusRequest) (*GetAgentStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAgentStatus not implemented")
 }
+func (UnimplementedAgentServiceServer) TerminateSession(context.Context, *TerminateSessionRequest) (*TerminateSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TerminateSession not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -364,6 +382,24 @@ func _AgentService_GetAgentStatus_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_TerminateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TerminateSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).TerminateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_TerminateSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).TerminateSession(ctx, req.(*TerminateSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -399,6 +435,10 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAgentStatus",
 			Handler:    _AgentService_GetAgentStatus_Handler,
 		},
+		{
+			MethodName: "TerminateSession",
+			Handler:    _AgentService_TerminateSession_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
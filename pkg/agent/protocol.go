@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Protocol is a version of the agent protocol. Minor versions only add to
+// the protocol, so an agent speaking a version also understands every older
+// minor version of the same major version.
+type Protocol struct {
+	Major int
+	Minor int
+}
+
+// knownProtocols lists every agent protocol version this server implements, newest first
+var knownProtocols = []Protocol{{Major: 1, Minor: 0}}
+
+// protocolGates maps version-gated features to the protocol version that
+// introduced them. Sessions on an older version do not get the feature, so
+// breaking changes can ship behind a new version.
+var protocolGates = map[string]Protocol{
+	"async_execution":  {Major: 1, Minor: 0},
+	"event_streaming":  {Major: 1, Minor: 0},
+	"retries":          {Major: 1, Minor: 0},
+	"session_limits":   {Major: 1, Minor: 0},
+	"telemetry_levels": {Major: 1, Minor: 0},
+}
+
+// ParseProtocol parses a protocol version such as "MCP/1.0". The "MCP/"
+// prefix is optional and case-insensitive.
+func ParseProtocol(value string) (Protocol, error) {
+	version := strings.TrimSpace(value)
+	if len(version) >= 4 && strings.EqualFold(version[:4], "mcp/") {
+		version = version[4:]
+	}
+	majorPart, minorPart, found := strings.Cut(version, ".")
+	if !found {
+		minorPart = "0"
+	}
+	major, err := strconv.Atoi(majorPart)
+	if err != nil || major < 0 {
+		return Protocol{}, fmt.Errorf("invalid protocol version %q: expected MCP/<major>.<minor>", value)
+	}
+	minor, err := strconv.Atoi(minorPart)
+	if err != nil || minor < 0 {
+		return Protocol{}, fmt.Errorf("invalid protocol version %q: expected MCP/<major>.<minor>", value)
+	}
+	return Protocol{Major: major, Minor: minor}, nil
+}
+
+// String formats the version as reported to agents
+func (p Protocol) String() string {
+	return fmt.Sprintf("MCP/%d.%d", p.Major, p.Minor)
+}
+
+// AtLeast reports whether p is the same as or newer than other
+func (p Protocol) AtLeast(other Protocol) bool {
+	if p.Major != other.Major {
+		return p.Major > other.Major
+	}
+	return p.Minor >= other.Minor
+}
+
+// ProtocolPolicy bounds the protocol versions agents may negotiate
+type ProtocolPolicy struct {
+	Min     Protocol // Oldest version still accepted
+	Default Protocol // Assumed for agents that declare no supported protocols; zero selects the oldest known version
+}
+
+// DefaultProtocolPolicy accepts every known version and assumes the oldest
+// for agents that do not say which they speak
+func DefaultProtocolPolicy() ProtocolPolicy {
+	oldest := knownProtocols[len(knownProtocols)-1]
+	return ProtocolPolicy{Min: oldest, Default: oldest}
+}
+
+// Supported lists the versions the policy accepts, newest first
+func (p ProtocolPolicy) Supported() []Protocol {
+	supported := make([]Protocol, 0, len(knownProtocols))
+	for _, version := range knownProtocols {
+		if version.AtLeast(p.Min) {
+			supported = append(supported, version)
+		}
+	}
+	return supported
+}
+
+// supports reports whether the policy accepts a version
+func (p ProtocolPolicy) supports(version Protocol) bool {
+	for _, supported := range p.Supported() {
+		if supported == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtocolMismatchError reports an agent that speaks none of the supported
+// protocol versions
+type ProtocolMismatchError struct {
+	Offered   []string
+	Supported []Protocol
+}
+
+// Error implements error
+func (e *ProtocolMismatchError) Error() string {
+	return fmt.Sprintf("none of the agent's protocol versions [%s] are supported; server supports [%s]",
+		strings.Join(e.Offered, ","), joinProtocols(e.Supported))
+}
+
+// joinProtocols lists protocol versions separated by commas
+func joinProtocols(versions []Protocol) string {
+	names := make([]string, len(versions))
+	for i, version := range versions {
+		names[i] = version.String()
+	}
+	return strings.Join(names, ",")
+}
+
+// Negotiate picks the protocol version of a session from the versions an
+// agent declares. The newest version both sides support wins. An agent that
+// only speaks newer minor versions is downgraded to the newest supported
+// minor version of the same major version. Agents that declare nothing get
+// the default version; versions that cannot be parsed are ignored.
+func (p ProtocolPolicy) Negotiate(offered []string) (Protocol, error) {
+	if len(offered) == 0 {
+		version := p.Default
+		if version == (Protocol{}) {
+			version = knownProtocols[len(knownProtocols)-1]
+		}
+		if !p.supports(version) {
+			return Protocol{}, &ProtocolMismatchError{Offered: []string{version.String()}, Supported: p.Supported()}
+		}
+		return version, nil
+	}
+
+	var versions []Protocol
+	for _, value := range offered {
+		if version, err := ParseProtocol(value); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return !versions[j].AtLeast(versions[i])
+	})
+
+	for _, version := range versions {
+		if p.supports(version) {
+			return version, nil
+		}
+	}
+	for _, version := range versions {
+		for _, supported := range p.Supported() {
+			if supported.Major == version.Major && version.AtLeast(supported) {
+				return supported, nil
+			}
+		}
+	}
+	return Protocol{}, &ProtocolMismatchError{Offered: offered, Supported: p.Supported()}
+}
+
+// ProtocolStatus describes whether the server accepts a protocol version
+type ProtocolStatus string
+
+const (
+	ProtocolCurrent     ProtocolStatus = "current"     // Newest accepted version
+	ProtocolSupported   ProtocolStatus = "supported"   // Older version still accepted
+	ProtocolUnsupported ProtocolStatus = "unsupported" // Implemented but below the policy minimum
+)
+
+// ProtocolSupport is one row of the protocol compatibility matrix
+type ProtocolSupport struct {
+	Version  string         `json:"version"`
+	Status   ProtocolStatus `json:"status"`
+	Features []string       `json:"features"` // Version-gated features sessions on this version get
+}
+
+// ProtocolMatrix describes the protocol versions the server implements,
+// newest first, with the version-gated features each provides
+func (p ProtocolPolicy) ProtocolMatrix() []ProtocolSupport {
+	matrix := make([]ProtocolSupport, 0, len(knownProtocols))
+	current := true
+	for _, version := range knownProtocols {
+		row := ProtocolSupport{Version: version.String(), Status: ProtocolUnsupported, Features: []string{}}
+		if version.AtLeast(p.Min) {
+			row.Status = ProtocolSupported
+			if current {
+				row.Status = ProtocolCurrent
+				current = false
+			}
+		}
+		for feature, introduced := range protocolGates {
+			if version.AtLeast(introduced) {
+				row.Features = append(row.Features, feature)
+			}
+		}
+		sort.Strings(row.Features)
+		matrix = append(matrix, row)
+	}
+	return matrix
+}
+
+// SupportsFeature reports whether the session's negotiated protocol version
+// provides a version-gated feature. Features without a gate are always available.
+func (s *AgentSession) SupportsFeature(feature string) bool {
+	introduced, gated := protocolGates[feature]
+	return !gated || s.Protocol.AtLeast(introduced)
+}
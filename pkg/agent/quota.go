@@ -0,0 +1,245 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DailyQuota caps what a session or an authenticated principal, such as an
+// API key, may use per UTC day. Zero fields are unlimited.
+type DailyQuota struct {
+	MaxInvocationsPerDay int64   `json:"max_invocations_per_day"`
+	MaxCostPerDay        float64 `json:"max_cost_per_day"` // In the units of ToolCost
+}
+
+// IdentityQuota returns the daily quota shared by every session of a
+// principal, if it has one
+type IdentityQuota func(identity Identity) (DailyQuota, bool)
+
+// ToolCost returns the cost one invocation of a tool counts against daily
+// cost quotas
+type ToolCost func(tool types.ToolMetadata) float64
+
+// QuotaError is returned when an invocation would exceed a daily quota or
+// the lifetime quota of a session
+type QuotaError struct {
+	Holder   string // The session or principal whose quota is exhausted
+	Limit    string // "invocations", "cost", "lifetime invocations" or "budget ms"
+	Used     float64
+	Max      float64
+	ResetsAt time.Time // Zero for lifetime quotas
+}
+
+func (e *QuotaError) Error() string {
+	if e.ResetsAt.IsZero() {
+		return fmt.Sprintf("%s quota of %s exhausted (%g/%g)", e.Limit, e.Holder, e.Used, e.Max)
+	}
+	return fmt.Sprintf("daily %s quota of %s exhausted (%g/%g), resets at %s",
+		e.Limit, e.Holder, e.Used, e.Max, e.ResetsAt.Format(time.RFC3339))
+}
+
+// dailyUsage counts the invocations and cost of a quota holder on one day
+type dailyUsage struct {
+	day         time.Time // Start of the UTC day counted
+	invocations int64
+	cost        float64
+}
+
+// lifetimeUsage counts the invocations and execution time of a session
+// since it was registered
+type lifetimeUsage struct {
+	invocations int64
+	budgetMs    int64
+}
+
+// quotaLedger tracks daily usage by quota holder: sessions by their ID and
+// principals by their subject. It also keeps the lifetime usage of sessions
+// and the quotas operators set on individual sessions.
+type quotaLedger struct {
+	mu       sync.Mutex
+	usage    map[string]*dailyUsage
+	lifetime map[string]*lifetimeUsage // Session ID -> usage since registration
+	sessions map[string]DailyQuota     // Session ID -> quota replacing the default
+}
+
+func newQuotaLedger() *quotaLedger {
+	return &quotaLedger{
+		usage:    make(map[string]*dailyUsage),
+		lifetime: make(map[string]*lifetimeUsage),
+		sessions: make(map[string]DailyQuota),
+	}
+}
+
+// lifetimeOf returns the lifetime usage of a session. Callers hold the lock.
+func (l *quotaLedger) lifetimeOf(sessionID string) *lifetimeUsage {
+	usage, exists := l.lifetime[sessionID]
+	if !exists {
+		usage = &lifetimeUsage{}
+		l.lifetime[sessionID] = usage
+	}
+	return usage
+}
+
+// chargeDuration counts the execution time of a finished invocation
+// against the budget of its session
+func (l *quotaLedger) chargeDuration(sessionID string, duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lifetimeOf(sessionID).budgetMs += duration.Milliseconds()
+}
+
+// usageOf returns the usage of a holder on a day. Callers hold the lock.
+func (l *quotaLedger) usageOf(holder string, day time.Time) *dailyUsage {
+	usage, exists := l.usage[holder]
+	if !exists || !usage.day.Equal(day) {
+		usage = &dailyUsage{day: day}
+		l.usage[holder] = usage
+	}
+	return usage
+}
+
+// forgetSession drops the quota and usage of a session that ended
+func (l *quotaLedger) forgetSession(sessionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.usage, sessionHolder(sessionID))
+	delete(l.lifetime, sessionID)
+	delete(l.sessions, sessionID)
+}
+
+// sessionHolder keys the usage of a session in the ledger
+func sessionHolder(sessionID string) string {
+	return "session:" + sessionID
+}
+
+// quotaHolder is a session or principal with a daily quota
+type quotaHolder struct {
+	key   string // Ledger key
+	name  string // For error messages
+	quota DailyQuota
+}
+
+// quotaHolders returns the holders an invocation by a session counts
+// against. Usage is counted even while a holder is unlimited, so quotas set
+// during the day account for what was already used.
+func (s *AgentServer) quotaHolders(session *AgentSession) []quotaHolder {
+	s.quotas.mu.Lock()
+	quota, overridden := s.quotas.sessions[session.ID]
+	s.quotas.mu.Unlock()
+	if !overridden {
+		quota = s.config.SessionQuota
+	}
+	holders := []quotaHolder{{key: sessionHolder(session.ID), name: "session " + session.ID, quota: quota}}
+
+	if session.Identity != nil && session.Identity.Subject != "" && s.config.IdentityQuota != nil {
+		quota, _ := s.config.IdentityQuota(*session.Identity)
+		holders = append(holders, quotaHolder{key: session.Identity.Subject, name: session.Identity.Subject, quota: quota})
+	}
+	return holders
+}
+
+// startOfDay returns the start of the UTC day holding t
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// chargeQuotas counts an invocation of a tool against the lifetime quota of
+// the session and the daily quotas of the session and its principal, or
+// returns a *QuotaError without counting it when one of them would be
+// exceeded. Invocations count when accepted, whether or not they succeed;
+// their execution time counts against the budget once they finish.
+func (s *AgentServer) chargeQuotas(session *AgentSession, tool types.ToolMetadata) error {
+	holders := s.quotaHolders(session)
+	cost := 1.0
+	if s.config.ToolCost != nil {
+		cost = s.config.ToolCost(tool)
+	}
+	today := startOfDay(s.config.Clock.Now())
+	resetsAt := today.Add(24 * time.Hour)
+	limits := s.config.SessionLimits
+
+	s.quotas.mu.Lock()
+	defer s.quotas.mu.Unlock()
+	lifetime := s.quotas.lifetimeOf(session.ID)
+	if limit := limits.MaxInvocations; limit > 0 && lifetime.invocations+1 > limit {
+		return &QuotaError{Holder: "session " + session.ID, Limit: "lifetime invocations", Used: float64(lifetime.invocations), Max: float64(limit)}
+	}
+	if limit := limits.BudgetMs; limit > 0 && lifetime.budgetMs >= limit {
+		return &QuotaError{Holder: "session " + session.ID, Limit: "budget ms", Used: float64(lifetime.budgetMs), Max: float64(limit)}
+	}
+	for _, holder := range holders {
+		usage := s.quotas.usageOf(holder.key, today)
+		if limit := holder.quota.MaxInvocationsPerDay; limit > 0 && usage.invocations+1 > limit {
+			return &QuotaError{Holder: holder.name, Limit: "invocations", Used: float64(usage.invocations), Max: float64(limit), ResetsAt: resetsAt}
+		}
+		if limit := holder.quota.MaxCostPerDay; limit > 0 && usage.cost+cost > limit {
+			return &QuotaError{Holder: holder.name, Limit: "cost", Used: usage.cost, Max: limit, ResetsAt: resetsAt}
+		}
+	}
+	for _, holder := range holders {
+		usage := s.quotas.usageOf(holder.key, today)
+		usage.invocations++
+		usage.cost += cost
+	}
+	lifetime.invocations++
+	return nil
+}
+
+// SetSessionQuota replaces the default daily quota of a session; nil
+// restores the default. Usage so far today still counts.
+func (s *AgentServer) SetSessionQuota(sessionID string, quota *DailyQuota) error {
+	if _, exists := s.getSession(sessionID); !exists {
+		return status.Error(codes.NotFound, "session not found")
+	}
+	if quota != nil && (quota.MaxInvocationsPerDay < 0 || quota.MaxCostPerDay < 0) {
+		return status.Error(codes.InvalidArgument, "quota limits must not be negative")
+	}
+
+	s.quotas.mu.Lock()
+	defer s.quotas.mu.Unlock()
+	if quota == nil {
+		delete(s.quotas.sessions, sessionID)
+	} else {
+		s.quotas.sessions[sessionID] = *quota
+	}
+	return nil
+}
+
+// quotaSnapshot fills in the lifetime usage and remaining daily quota of a
+// session, reporting for each daily limit the quota with the least left
+func (s *AgentServer) quotaSnapshot(session *AgentSession, snapshot *agentpb.SessionLimits) {
+	snapshot.InvocationsRemainingToday = -1
+	snapshot.CostRemainingToday = -1
+	today := startOfDay(s.config.Clock.Now())
+	snapshot.QuotaResetsAtUnix = today.Add(24 * time.Hour).Unix()
+
+	holders := s.quotaHolders(session)
+	s.quotas.mu.Lock()
+	defer s.quotas.mu.Unlock()
+	lifetime := s.quotas.lifetimeOf(session.ID)
+	snapshot.InvocationsUsed = lifetime.invocations
+	snapshot.BudgetUsedMs = lifetime.budgetMs
+	for _, holder := range holders {
+		usage := s.quotas.usageOf(holder.key, today)
+		if limit := holder.quota.MaxInvocationsPerDay; limit > 0 {
+			remaining := max(limit-usage.invocations, 0)
+			if snapshot.InvocationsRemainingToday < 0 || remaining < snapshot.InvocationsRemainingToday {
+				snapshot.MaxInvocationsPerDay = limit
+				snapshot.InvocationsRemainingToday = remaining
+			}
+		}
+		if limit := holder.quota.MaxCostPerDay; limit > 0 {
+			remaining := max(limit-usage.cost, 0)
+			if snapshot.CostRemainingToday < 0 || remaining < snapshot.CostRemainingToday {
+				snapshot.MaxCostPerDay = limit
+				snapshot.CostRemainingToday = remaining
+			}
+		}
+	}
+}
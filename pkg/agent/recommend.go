@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Number of tool recommendations returned
+const (
+	DefaultRecommendationLimit = 5
+	MaxRecommendationLimit     = 50
+)
+
+// ToolRecommendation suggests a tool for an agent to invoke next
+type ToolRecommendation struct {
+	Tool   string  `json:"tool"`
+	Reason string  `json:"reason"` // Why the tool is recommended, e.g. "follows" or "popular"
+	Score  float64 `json:"score"`  // Between 0 and 1; higher is more relevant
+	Count  int64   `json:"count"`  // Observations behind the score
+}
+
+// ToolRecommender suggests the tools to invoke after a tool, most relevant
+// first, e.g. from learned usage. afterTool is empty for sessions that have
+// not invoked a tool yet; a limit of zero or less asks for every candidate.
+type ToolRecommender interface {
+	RecommendTools(ctx context.Context, afterTool string, limit int) ([]ToolRecommendation, error)
+}
+
+// ToolRecommendations are the tools suggested to a session
+type ToolRecommendations struct {
+	SessionID       string               `json:"session_id"`
+	AfterTool       string               `json:"after_tool,omitempty"` // Tool the recommendations follow
+	Recommendations []ToolRecommendation `json:"recommendations"`
+}
+
+// RecommendTools suggests tools for a session to invoke after afterTool, or
+// after its last invocation when afterTool is empty. Tools that are not
+// registered or that the session's roles do not allow are left out.
+func (s *AgentServer) RecommendTools(ctx context.Context, sessionID, afterTool string, limit int) (*ToolRecommendations, error) {
+	session, exists := s.getSession(sessionID)
+	if !exists {
+		return nil, status.Error(codes.Unauthenticated, "invalid session")
+	}
+	if s.config.Recommender == nil {
+		return nil, status.Error(codes.Unavailable, "tool recommendations are disabled")
+	}
+	if limit <= 0 {
+		limit = DefaultRecommendationLimit
+	}
+	if limit > MaxRecommendationLimit {
+		limit = MaxRecommendationLimit
+	}
+	if afterTool == "" {
+		session.Metrics.mu.RLock()
+		afterTool = session.Metrics.LastTool
+		session.Metrics.mu.RUnlock()
+	}
+
+	candidates, err := s.config.Recommender.RecommendTools(ctx, afterTool, 0)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to recommend tools: %v", err)
+	}
+	result := &ToolRecommendations{SessionID: sessionID, AfterTool: afterTool, Recommendations: make([]ToolRecommendation, 0, limit)}
+	for _, candidate := range candidates {
+		if len(result.Recommendations) == limit {
+			break
+		}
+		tool, err := s.registry.Get(candidate.Tool)
+		if err != nil || !s.toolAllowed(session, tool.Metadata()) {
+			continue
+		}
+		result.Recommendations = append(result.Recommendations, candidate)
+	}
+	return result, nil
+}
+
+// getRecommendations handles suggesting tools to invoke next
+func (api *AgentAPI) getRecommendations(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	recommendations, err := api.agentServer.RecommendTools(c.Request.Context(), c.Param("session_id"), c.Query("after"), limit)
+	if err != nil {
+		c.JSON(httpStatusFromError(err), gin.H{"error": status.Convert(err).Message()})
+		return
+	}
+	c.JSON(http.StatusOK, recommendations)
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/audit"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultResumptionGrace is how long an expired session stays resumable
+const DefaultResumptionGrace = 10 * time.Minute
+
+// ResumptionConfig controls the tokens issued at registration that let an
+// agent resume its session after it expired, keeping its metrics, pending
+// notifications, quota usage and recent tool usage
+type ResumptionConfig struct {
+	Grace  time.Duration // How long after expiry a session may be resumed; zero disables resumption
+	Secret []byte        // Signs the tokens; nil selects a random secret per server
+}
+
+// DefaultResumptionConfig returns the default session resumption settings
+func DefaultResumptionConfig() ResumptionConfig {
+	return ResumptionConfig{Grace: DefaultResumptionGrace}
+}
+
+// resumptionSecret returns the configured signing secret, or a random one
+func resumptionSecret(config ResumptionConfig) []byte {
+	if len(config.Secret) > 0 {
+		return config.Secret
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic("agent: failed to generate resumption secret: " + err.Error())
+	}
+	return secret
+}
+
+// resumptionMAC signs a session for the agent it belongs to
+func (s *AgentServer) resumptionMAC(sessionID, agentID string) []byte {
+	mac := hmac.New(sha256.New, s.resumptionSecret)
+	mac.Write([]byte(sessionID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(agentID))
+	return mac.Sum(nil)
+}
+
+// resumptionToken returns the token resuming a session, or "" when
+// resumption is disabled. Tokens are the session ID and its signature.
+func (s *AgentServer) resumptionToken(session *AgentSession) string {
+	if s.config.Resumption.Grace <= 0 {
+		return ""
+	}
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString([]byte(session.ID)) + "." +
+		encoding.EncodeToString(s.resumptionMAC(session.ID, session.AgentID))
+}
+
+// verifyResumptionToken returns the session a token was issued to, if it
+// was issued to the agent
+func (s *AgentServer) verifyResumptionToken(token, agentID string) (string, bool) {
+	encodedID, encodedMAC, found := strings.Cut(token, ".")
+	if !found {
+		return "", false
+	}
+	sessionID, err := base64.RawURLEncoding.DecodeString(encodedID)
+	if err != nil {
+		return "", false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return "", false
+	}
+	return string(sessionID), hmac.Equal(mac, s.resumptionMAC(string(sessionID), agentID))
+}
+
+// retainExpired keeps an expired session resumable for the grace window, or
+// forgets it when resumption is disabled. Callers hold sessionsMux.
+func (s *AgentServer) retainExpired(session *AgentSession) {
+	if s.config.Resumption.Grace > 0 {
+		s.expired[session.ID] = session
+		return
+	}
+	s.quotas.forgetSession(session.ID)
+	go s.forgetUsage(session.ID)
+}
+
+// purgeExpired forgets expired sessions whose grace window has passed.
+// Callers hold sessionsMux.
+func (s *AgentServer) purgeExpired(now time.Time) {
+	for sessionID, session := range s.expired {
+		if now.After(session.ExpiresAt.Add(s.config.Resumption.Grace)) {
+			delete(s.expired, sessionID)
+			s.quotas.forgetSession(sessionID)
+			go s.forgetUsage(sessionID)
+		}
+	}
+}
+
+// resumeSession reactivates the expired session a resumption token was
+// issued to, extending it by timeout
+func (s *AgentServer) resumeSession(req *agentpb.RegisterAgentRequest, identity *Identity, timeout time.Duration) (*AgentSession, error) {
+	sessionID, valid := s.verifyResumptionToken(req.ResumptionToken, req.AgentId)
+	if !valid {
+		return nil, status.Error(codes.InvalidArgument, "invalid resumption token")
+	}
+
+	now := s.config.Clock.Now()
+	s.sessionsMux.Lock()
+	defer s.sessionsMux.Unlock()
+	session, exists := s.expired[sessionID]
+	if !exists || now.After(session.ExpiresAt.Add(s.config.Resumption.Grace)) {
+		return nil, status.Error(codes.NotFound, "session can no longer be resumed, register again")
+	}
+	if session.Identity != nil && (identity == nil || identity.Subject != session.Identity.Subject) {
+		return nil, status.Error(codes.PermissionDenied, "session was registered by a different principal")
+	}
+
+	delete(s.expired, sessionID)
+	session.LastHeartbeat = now
+	session.ExpiresAt = now.Add(timeout)
+	session.Status = agentpb.AgentStatus_AGENT_STATUS_ACTIVE
+	s.sessions[sessionID] = session
+	return session, nil
+}
+
+// resumeAgent handles a registration carrying a resumption token
+func (s *AgentServer) resumeAgent(req *agentpb.RegisterAgentRequest, identity *Identity, timeout time.Duration) (*agentpb.RegisterAgentResponse, error) {
+	session, err := s.resumeSession(req, identity, timeout)
+	if err != nil {
+		s.logger.Warn("Agent session resumption rejected",
+			zap.String("agent_id", req.AgentId),
+			zap.Error(err))
+		return nil, err
+	}
+	s.auditSession(audit.ActionSessionResume, session, sessionActor(session))
+
+	tools := s.getToolsForAgent(session, types.ToolQuery{})
+	s.broadcastEvent(&agentpb.Event{
+		EventId:       uuid.New().String(),
+		Type:          agentpb.EventType_EVENT_TYPE_AGENT_REGISTERED,
+		TimestampUnix: session.LastHeartbeat.Unix(),
+		SessionId:     session.ID,
+		DataJson:      encodeEventData(map[string]interface{}{"agent_id": session.AgentID, "agent_name": session.AgentName, "resumed": true}),
+	})
+
+	s.logger.Info("Agent session resumed",
+		zap.String("session_id", session.ID),
+		zap.String("agent_id", session.AgentID),
+		zap.Int("available_tools", len(tools)))
+
+	resp := s.registrationResponse(session, tools)
+	resp.Resumed = true
+	return resp, nil
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+const (
+	// DefaultMaxRetries is the default cap on the retries an agent may request per invocation
+	DefaultMaxRetries = 5
+
+	// DefaultMaxRetryDelay is the default cap on the wait between two attempts
+	DefaultMaxRetryDelay = 30 * time.Second
+
+	// defaultRetryDelay is the initial backoff when the policy does not set one
+	defaultRetryDelay = time.Second
+)
+
+// retryPolicy is an agent's ToolRetryPolicy bounded by the server limits
+type retryPolicy struct {
+	maxRetries  int
+	delay       time.Duration // Initial backoff, doubled after every attempt
+	maxDelay    time.Duration
+	statusCodes map[int]bool // Upstream statuses to retry; empty retries any retryable failure
+}
+
+// retryPolicy resolves the retry policy of an invocation. Invocations without
+// a policy are attempted once.
+func (s *AgentServer) retryPolicy(policy *agentpb.ToolRetryPolicy) retryPolicy {
+	resolved := retryPolicy{
+		maxRetries: int(policy.GetMaxRetries()),
+		delay:      time.Duration(policy.GetRetryDelaySeconds()) * time.Second,
+		maxDelay:   s.config.MaxRetryDelay,
+	}
+	if resolved.maxRetries > s.config.MaxRetries {
+		resolved.maxRetries = s.config.MaxRetries
+	}
+	if resolved.delay <= 0 {
+		resolved.delay = defaultRetryDelay
+	}
+	if codes := policy.GetRetryableStatusCodes(); len(codes) > 0 {
+		resolved.statusCodes = make(map[int]bool, len(codes))
+		for _, code := range codes {
+			resolved.statusCodes[int(code)] = true
+		}
+	}
+	return resolved
+}
+
+// retryable reports whether an attempt should be repeated. Listed status codes
+// match both upstream failures and results that report an upstream status.
+func (p retryPolicy) retryable(result any, err error) bool {
+	if err == nil {
+		return len(p.statusCodes) > 0 && p.statusCodes[resultStatusCode(result)]
+	}
+	if !executionToolError(err).Retryable {
+		return false
+	}
+	if len(p.statusCodes) == 0 {
+		return true
+	}
+	retryable, ok := types.AsRetryable(err)
+	return ok && p.statusCodes[retryable.StatusCode]
+}
+
+// backoff returns how long to wait before the next attempt: exponential with
+// jitter, but no shorter than an upstream Retry-After hint. It reports false
+// when the upstream asks for a longer wait than the server allows.
+func (p retryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	wait := p.delay
+	for i := 1; i < attempt && wait < p.maxDelay; i++ {
+		wait *= 2
+	}
+	if wait > p.maxDelay {
+		wait = p.maxDelay
+	}
+	// Equal jitter keeps at least half the backoff while spreading out retries
+	if half := wait / 2; half > 0 {
+		wait = half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+
+	if retryable, ok := types.AsRetryable(err); ok && retryable.RetryAfter > wait {
+		if retryable.RetryAfter > p.maxDelay {
+			return 0, false
+		}
+		wait = retryable.RetryAfter
+	}
+	return wait, true
+}
+
+// resultStatusCode reads the upstream HTTP status reported in a tool result, or 0
+func resultStatusCode(result any) int {
+	fields, ok := result.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	switch code := fields["status_code"].(type) {
+	case int:
+		return code
+	case int64:
+		return int(code)
+	case float64:
+		return int(code)
+	}
+	return 0
+}
+
+// sleepContext waits for d or until ctx ends, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"fmt"
+	"path"
+)
+
+// ToolScope narrows the tools a session sees and invokes by name, with glob
+// patterns as in path.Match. A tool is in scope when no Deny pattern matches
+// it and, unless Allow is empty, an Allow pattern does.
+type ToolScope struct {
+	Allow []string `json:"allow,omitempty" mapstructure:"allow"`
+	Deny  []string `json:"deny,omitempty" mapstructure:"deny"`
+}
+
+// IsZero reports whether the scope allows every tool
+func (scope ToolScope) IsZero() bool {
+	return len(scope.Allow) == 0 && len(scope.Deny) == 0
+}
+
+// Validate checks that every pattern is a valid glob
+func (scope ToolScope) Validate() error {
+	for _, patterns := range [][]string{scope.Allow, scope.Deny} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return fmt.Errorf("invalid tool pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// Allows reports whether a tool is in scope
+func (scope ToolScope) Allows(toolName string) bool {
+	if matchesAny(scope.Deny, toolName) {
+		return false
+	}
+	return len(scope.Allow) == 0 || matchesAny(scope.Allow, toolName)
+}
+
+// matchesAny reports whether a name matches one of the patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolScopePolicy is a tool scope operators impose on the sessions of the
+// agents whose ID matches AgentID, a glob pattern
+type ToolScopePolicy struct {
+	AgentID   string `json:"agent_id" mapstructure:"agent_id"`
+	ToolScope `mapstructure:",squash"`
+}
+
+// sessionToolScopes returns the scopes of a new session: the one its agent
+// asked for, if any, and every policy matching the agent. Agents can narrow
+// what policies allow but never widen it.
+func (s *AgentServer) sessionToolScopes(agentID string, requested ToolScope) ([]ToolScope, error) {
+	if err := requested.Validate(); err != nil {
+		return nil, err
+	}
+	var scopes []ToolScope
+	if !requested.IsZero() {
+		scopes = append(scopes, requested)
+	}
+	for _, policy := range s.config.ToolScopes {
+		if matched, _ := path.Match(policy.AgentID, agentID); matched {
+			scopes = append(scopes, policy.ToolScope)
+		}
+	}
+	return scopes, nil
+}
+
+// toolInScope reports whether every tool scope of the session allows a tool
+func (session *AgentSession) toolInScope(toolName string) bool {
+	for _, scope := range session.ToolScopes {
+		if !scope.Allows(toolName) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,32 +3,160 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/audit"
+	"github.com/aionmcp/aionmcp/pkg/capabilities"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/contextvars"
+	"github.com/aionmcp/aionmcp/pkg/eventstore"
+	"github.com/aionmcp/aionmcp/pkg/invocationlog"
+	"github.com/aionmcp/aionmcp/pkg/readonly"
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/aionmcp/aionmcp/pkg/sessionhistory"
 	"github.com/aionmcp/aionmcp/pkg/types"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-const (
-	// fallbackErrorResultJSON is used when result serialization fails
-	fallbackErrorResultJSON = `{"result": null}`
-)
-
 // AgentServer implements the gRPC AgentService interface
 type AgentServer struct {
 	agentpb.UnimplementedAgentServiceServer
-	logger       *zap.Logger
-	registry     types.ToolRegistry
-	sessions     map[string]*AgentSession
-	sessionsMux  sync.RWMutex
-	eventStreams map[string][]chan *agentpb.Event
-	streamsMux   sync.RWMutex
+	logger        *zap.Logger
+	registry      types.ToolRegistry
+	sessions      map[string]*AgentSession
+	expired       map[string]*AgentSession // Expired sessions still within the resumption grace window
+	sessionsMux   sync.RWMutex
+	eventStreams  map[string][]*eventStream
+	streamDrops   map[string]*atomic.Int64 // session ID -> events dropped by its streams
+	streamsMux    sync.RWMutex
+	streamsReaped atomic.Int64              // Streams the health check closed as dead
+	eventLog      *eventLog                 // Recent events for long-polling clients and stream replay
+	jobs          map[string]*invocationJob // invocation ID -> async invocation
+	jobsMux       sync.RWMutex
+	jobQueue      chan *invocationJob
+	jobsClosed    bool               // Set by Close; guarded by jobsMux
+	jobsCtx       context.Context    // Parent of every async invocation, cancelled by Close
+	stopJobs      context.CancelFunc // Cancels jobsCtx
+	workers       sync.WaitGroup     // Async invocation workers
+	closeOnce     sync.Once
+	tap           *tapHub
+	drain         drainState // Refuses new registrations while draining
+	quotas        *quotaLedger
+	config        AgentServerConfig
+
+	resumptionSecret []byte // Signs session resumption tokens
+}
+
+// AgentServerConfig holds tunable settings for the agent server
+type AgentServerConfig struct {
+	SessionLimits SessionLimits
+	InvocationLog *invocationlog.Exporter // Optional SIEM invocation stream; nil disables it
+	Audit         *audit.Log              // Optional audit log of sessions and invocations; nil disables it
+	ReadOnly      *readonly.Mode          // Optional maintenance read-only mode; nil disables it
+	Executions    ExecutionRecorder       // Optional per-invocation record store; nil disables it
+	UsageHistory  *sessionhistory.Store   // Recent invocations of each session; nil keeps them in memory
+	Recommender   ToolRecommender         // Optional source of next-tool recommendations; nil disables them
+	Telemetry     TelemetryPolicy         // Capture levels agents may negotiate for recorded executions
+	Protocols     ProtocolPolicy          // Agent protocol versions sessions may negotiate
+	Resumption    ResumptionConfig        // Whether and how long expired sessions may be resumed
+	Tap           TapConfig               // What operators tapping a session see of its invocations
+	ToolAccess    ToolAccess              // Optional per-role tool access check; nil allows every tool
+	ToolScopes    []ToolScopePolicy       // Tool scopes imposed on the sessions of matching agents
+	ResultFormats []ResultFormat          // Result encodings sessions may negotiate besides JSON; nil selects DefaultResultFormats
+	Clock         clock.Clock             // Judges session expiry, rate windows and timestamps; nil selects the system clock
+
+	// Daily quotas. SessionQuota applies to every session unless an operator
+	// replaces it; IdentityQuota is shared by all sessions of a principal and
+	// nil leaves principals unlimited. Each invocation costs ToolCost, or 1
+	// when it is nil.
+	SessionQuota  DailyQuota
+	IdentityQuota IdentityQuota
+	ToolCost      ToolCost
+
+	// Server-wide features advertised alongside the agent service's own,
+	// e.g. authentication and transports
+	Features map[string]capabilities.Feature
+
+	// Bounds on agent-requested retry policies. Zero MaxRetries disables
+	// retries; zero MaxRetryDelay selects the default.
+	MaxRetries    int
+	MaxRetryDelay time.Duration
+
+	// Async invocations run on a bounded worker pool behind a bounded queue.
+	// Zero values select the defaults.
+	AsyncWorkers      int
+	AsyncQueueSize    int
+	AsyncJobRetention time.Duration // How long finished async invocations remain queryable
+
+	// Recent events are kept for long-polling clients and stream replay.
+	// Zero values select the defaults. EventStore, when set, persists them
+	// so they survive restarts.
+	EventLogSize int
+	MaxPollWait  time.Duration
+	EventStore   *eventstore.Store
+
+	// Event streams are health checked every StreamCheckInterval and closed
+	// once their client is gone or has kept a full buffer for
+	// StreamStallTimeout. Zero values select the defaults.
+	StreamCheckInterval time.Duration
+	StreamStallTimeout  time.Duration
+}
+
+// AgentExecution describes one completed tool execution by an agent
+type AgentExecution struct {
+	SessionID    string
+	AgentID      string
+	AgentName    string
+	InvocationID string
+	ToolName     string
+	SourceType   string
+	Input        interface{}
+	Output       interface{}
+	Err          error
+	Duration     time.Duration
+	Attempt      int    // 1-based; retries share the invocation ID
+	Workspace    string // Workspace of the session, if any
+	Tenant       string // Tenant of the session, if any
+}
+
+// TenantMetadataKey is the registration metadata key naming the tenant an
+// agent session belongs to
+const TenantMetadataKey = "tenant"
+
+// ExecutionRecorder persists agent tool executions, e.g. for learning and offline analysis
+type ExecutionRecorder interface {
+	RecordAgentExecution(ctx context.Context, execution AgentExecution) error
+}
+
+// DefaultAgentServerConfig returns the default agent server configuration
+func DefaultAgentServerConfig() AgentServerConfig {
+	return AgentServerConfig{
+		SessionLimits:       DefaultSessionLimits(),
+		Telemetry:           DefaultTelemetryPolicy(),
+		Protocols:           DefaultProtocolPolicy(),
+		Resumption:          DefaultResumptionConfig(),
+		Tap:                 DefaultTapConfig(),
+		MaxRetries:          DefaultMaxRetries,
+		MaxRetryDelay:       DefaultMaxRetryDelay,
+		AsyncWorkers:        DefaultAsyncWorkers,
+		AsyncQueueSize:      DefaultAsyncQueueSize,
+		AsyncJobRetention:   DefaultAsyncJobRetention,
+		EventLogSize:        DefaultEventLogSize,
+		MaxPollWait:         DefaultMaxPollWait,
+		StreamCheckInterval: DefaultStreamCheckInterval,
+		StreamStallTimeout:  DefaultStreamStallTimeout,
+	}
 }
 
 // AgentSession represents an active agent session
@@ -44,6 +172,13 @@ type AgentSession struct {
 	ExpiresAt     time.Time
 	Status        agentpb.AgentStatus
 	Metrics       *InternalAgentMetrics
+	Usage         *sessionUsage
+	CaptureLevel  CaptureLevel   // Negotiated at registration
+	Protocol      Protocol       // Negotiated at registration
+	ResultFormat  ResultFormat   // Negotiated at registration; nil means JSON
+	Identity      *Identity      // Authenticated principal that registered the session, if any
+	ToolScopes    []ToolScope    // Set at registration; the session only uses tools every scope allows
+	notices       sessionNotices // Administrator notices awaiting the next heartbeat
 }
 
 // InternalAgentMetrics tracks agent usage statistics
@@ -53,21 +188,90 @@ type InternalAgentMetrics struct {
 	FailedInvocations     int64
 	TotalResponseTimeMs   int64
 	LastInvocation        time.Time
+	LastTool              string // Tool of the latest invocation
 	ToolUsageCount        map[string]int64
+	ToolLastUsed          map[string]time.Time
 	mu                    sync.RWMutex
 }
 
-// NewAgentServer creates a new AgentServer instance
+// NewAgentServer creates a new AgentServer instance with default configuration
 func NewAgentServer(logger *zap.Logger, registry types.ToolRegistry) *AgentServer {
+	return NewAgentServerWithConfig(logger, registry, DefaultAgentServerConfig())
+}
+
+// NewAgentServerWithConfig creates a new AgentServer instance with custom configuration
+func NewAgentServerWithConfig(logger *zap.Logger, registry types.ToolRegistry, config AgentServerConfig) *AgentServer {
+	if config.AsyncWorkers <= 0 {
+		config.AsyncWorkers = DefaultAsyncWorkers
+	}
+	if config.AsyncQueueSize <= 0 {
+		config.AsyncQueueSize = DefaultAsyncQueueSize
+	}
+	if config.AsyncJobRetention <= 0 {
+		config.AsyncJobRetention = DefaultAsyncJobRetention
+	}
+	if config.MaxRetryDelay <= 0 {
+		config.MaxRetryDelay = DefaultMaxRetryDelay
+	}
+	if config.MaxPollWait <= 0 {
+		config.MaxPollWait = DefaultMaxPollWait
+	}
+	if config.StreamCheckInterval <= 0 {
+		config.StreamCheckInterval = DefaultStreamCheckInterval
+	}
+	if config.StreamStallTimeout <= 0 {
+		config.StreamStallTimeout = DefaultStreamStallTimeout
+	}
+	if config.Telemetry.Default == "" {
+		config.Telemetry.Default = DefaultTelemetryPolicy().Default
+	}
+	if config.Telemetry.Max == "" {
+		config.Telemetry.Max = DefaultTelemetryPolicy().Max
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
+	if config.ResultFormats == nil {
+		config.ResultFormats = DefaultResultFormats()
+	}
+	if config.UsageHistory == nil {
+		config.UsageHistory = sessionhistory.OpenMemory(sessionhistory.DefaultSize)
+	}
+
 	server := &AgentServer{
 		logger:       logger,
 		registry:     registry,
 		sessions:     make(map[string]*AgentSession),
-		eventStreams: make(map[string][]chan *agentpb.Event),
+		expired:      make(map[string]*AgentSession),
+		eventStreams: make(map[string][]*eventStream),
+		streamDrops:  make(map[string]*atomic.Int64),
+		eventLog:     newEventLog(config.EventLogSize),
+		jobs:         make(map[string]*invocationJob),
+		jobQueue:     make(chan *invocationJob, config.AsyncQueueSize),
+		tap:          newTapHub(logger),
+		quotas:       newQuotaLedger(),
+		config:       config,
+
+		resumptionSecret: resumptionSecret(config.Resumption),
+	}
+	server.jobsCtx, server.stopJobs = context.WithCancel(context.Background())
+
+	if config.EventStore != nil {
+		if err := server.eventLog.restore(config.EventStore); err != nil {
+			logger.Warn("Failed to restore persisted events", zap.Error(err))
+		}
 	}
 
-	// Start session cleanup goroutine
-	go server.sessionCleanup()
+	// Start session cleanup goroutine. The ticker starts here so that a fake
+	// clock moved right after construction already drives it.
+	go server.sessionCleanup(config.Clock.NewTicker(sessionCleanupInterval))
+	go server.streamHealthCheck(config.Clock.NewTicker(config.StreamCheckInterval))
+
+	// Start async invocation workers
+	server.workers.Add(config.AsyncWorkers)
+	for i := 0; i < config.AsyncWorkers; i++ {
+		go server.invocationWorker()
+	}
 
 	return server
 }
@@ -79,6 +283,18 @@ func (s *AgentServer) RegisterAgent(ctx context.Context, req *agentpb.RegisterAg
 		zap.String("agent_name", req.AgentName),
 		zap.String("agent_version", req.AgentVersion))
 
+	// New sessions are refused while the server drains for maintenance
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+
+	// Authenticated callers register as the agent their credential names
+	agentID, agentName, identity, err := bindIdentity(ctx, req.AgentId, req.AgentName)
+	if err != nil {
+		return nil, err
+	}
+	req.AgentId, req.AgentName = agentID, agentName
+
 	// Validate request
 	if req.AgentId == "" {
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
@@ -87,17 +303,45 @@ func (s *AgentServer) RegisterAgent(ctx context.Context, req *agentpb.RegisterAg
 		return nil, status.Error(codes.InvalidArgument, "agent_name is required")
 	}
 
-	// Generate session ID
-	sessionID := uuid.New().String()
-
 	// Set session timeout (default 300 seconds)
 	timeoutSeconds := req.SessionTimeoutSeconds
 	if timeoutSeconds == 0 {
 		timeoutSeconds = 300
 	}
+	timeout := time.Duration(timeoutSeconds) * time.Second
 
-	now := time.Now()
-	expiresAt := now.Add(time.Duration(timeoutSeconds) * time.Second)
+	// Agents holding a resumption token pick up their expired session
+	if req.ResumptionToken != "" {
+		return s.resumeAgent(req, identity, timeout)
+	}
+
+	captureLevel, err := s.config.Telemetry.Negotiate(req.Metadata[CaptureLevelMetadataKey])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var offeredProtocols, preferredFormats []string
+	if req.Capabilities != nil {
+		offeredProtocols = req.Capabilities.SupportedProtocols
+		preferredFormats = req.Capabilities.PreferredFormats
+	}
+	protocol, err := s.config.Protocols.Negotiate(offeredProtocols)
+	if err != nil {
+		s.logger.Warn("Agent registration rejected: no common protocol version",
+			zap.String("agent_id", req.AgentId),
+			zap.Strings("offered_protocols", offeredProtocols))
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	resultFormat := s.negotiateResultFormat(preferredFormats)
+	toolScopes, err := s.sessionToolScopes(req.AgentId, ToolScope{Allow: req.AllowedTools, Deny: req.DeniedTools})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Generate session ID
+	sessionID := uuid.New().String()
+
+	now := s.config.Clock.Now()
+	expiresAt := now.Add(timeout)
 
 	// Create session
 	session := &AgentSession{
@@ -113,16 +357,24 @@ func (s *AgentServer) RegisterAgent(ctx context.Context, req *agentpb.RegisterAg
 		Status:        agentpb.AgentStatus_AGENT_STATUS_ACTIVE,
 		Metrics: &InternalAgentMetrics{
 			ToolUsageCount: make(map[string]int64),
+			ToolLastUsed:   make(map[string]time.Time),
 		},
+		Usage:        &sessionUsage{},
+		CaptureLevel: captureLevel,
+		Protocol:     protocol,
+		ResultFormat: resultFormat,
+		Identity:     identity,
+		ToolScopes:   toolScopes,
 	}
 
 	// Store session
 	s.sessionsMux.Lock()
 	s.sessions[sessionID] = session
 	s.sessionsMux.Unlock()
+	s.auditSession(audit.ActionSessionRegister, session, sessionActor(session))
 
 	// Get available tools
-	tools := s.getToolsForAgent(session)
+	tools := s.getToolsForAgent(session, types.ToolQuery{})
 
 	// Broadcast agent registered event
 	s.broadcastEvent(&agentpb.Event{
@@ -130,29 +382,43 @@ func (s *AgentServer) RegisterAgent(ctx context.Context, req *agentpb.RegisterAg
 		Type:          agentpb.EventType_EVENT_TYPE_AGENT_REGISTERED,
 		TimestampUnix: now.Unix(),
 		SessionId:     sessionID,
-		DataJson:      fmt.Sprintf(`{"agent_id": "%s", "agent_name": "%s"}`, req.AgentId, req.AgentName),
+		DataJson:      encodeEventData(map[string]interface{}{"agent_id": req.AgentId, "agent_name": req.AgentName}),
 	})
 
 	s.logger.Info("Agent registered successfully",
 		zap.String("session_id", sessionID),
 		zap.String("agent_id", req.AgentId),
+		zap.String("capture_level", string(captureLevel)),
+		zap.String("protocol_version", protocol.String()),
+		zap.String("result_format", resultFormat.Name()),
 		zap.Int("available_tools", len(tools)))
 
+	return s.registrationResponse(session, tools), nil
+}
+
+// registrationResponse describes a registered or resumed session to its agent
+func (s *AgentServer) registrationResponse(session *AgentSession, tools []*agentpb.ToolInfo) *agentpb.RegisterAgentResponse {
+	// Advertise the feature matrix, plus the capture level, protocol version
+	// and result format negotiated for this session
+	features := s.Capabilities()
+	serverCapabilities := features.Flatten()
+	serverCapabilities["capture_level"] = string(session.CaptureLevel)
+	serverCapabilities["max_capture_level"] = string(s.config.Telemetry.Max)
+	serverCapabilities["supported_protocols"] = joinProtocols(s.config.Protocols.Supported())
+	serverCapabilities["result_format"] = session.resultFormat().Name()
+
 	return &agentpb.RegisterAgentResponse{
-		SessionId:     sessionID,
-		ExpiresAtUnix: expiresAt.Unix(),
+		SessionId:     session.ID,
+		ExpiresAtUnix: session.ExpiresAt.Unix(),
 		ServerInfo: &agentpb.ServerInfo{
-			ServerVersion:     "0.1.0",
-			ProtocolVersion:   "MCP/1.0",
-			SupportedFeatures: []string{"tool_execution", "event_streaming", "session_management"},
-			Capabilities: map[string]string{
-				"max_concurrent_tools": "10",
-				"streaming_supported":  "true",
-				"async_execution":      "true",
-			},
+			ServerVersion:     features.ServerVersion,
+			ProtocolVersion:   session.Protocol.String(),
+			SupportedFeatures: features.Enabled(),
+			Capabilities:      serverCapabilities,
 		},
-		AvailableTools: tools,
-	}, nil
+		AvailableTools:  tools,
+		ResumptionToken: s.resumptionToken(session),
+	}
 }
 
 // UnregisterAgent terminates an agent session
@@ -168,17 +434,22 @@ func (s *AgentServer) UnregisterAgent(ctx context.Context, req *agentpb.Unregist
 	s.sessionsMux.Lock()
 	delete(s.sessions, req.SessionId)
 	s.sessionsMux.Unlock()
+	s.quotas.forgetSession(req.SessionId)
+	s.forgetUsage(req.SessionId)
+	s.auditSession(audit.ActionSessionUnregister, session, sessionActor(session))
 
-	// Close event streams for this session
+	// Close event streams and taps and cancel unfinished async invocations for this session
 	s.closeEventStreams(req.SessionId)
+	s.tap.closeSession(req.SessionId)
+	s.cancelSessionInvocations(req.SessionId)
 
 	// Broadcast agent unregistered event
 	s.broadcastEvent(&agentpb.Event{
 		EventId:       uuid.New().String(),
 		Type:          agentpb.EventType_EVENT_TYPE_AGENT_UNREGISTERED,
-		TimestampUnix: time.Now().Unix(),
+		TimestampUnix: s.config.Clock.Now().Unix(),
 		SessionId:     req.SessionId,
-		DataJson:      fmt.Sprintf(`{"agent_id": "%s"}`, session.AgentID),
+		DataJson:      encodeEventData(map[string]interface{}{"agent_id": session.AgentID}),
 	})
 
 	s.logger.Info("Agent unregistered successfully",
@@ -201,17 +472,16 @@ func (s *AgentServer) ListTools(ctx context.Context, req *agentpb.ListToolsReque
 	// Update last heartbeat
 	s.updateHeartbeat(req.SessionId)
 
-	tools := s.getToolsForAgent(session)
-
-	// Apply filtering if specified
-	if req.Filter != nil {
-		tools = s.applyToolFilter(tools, req.Filter)
+	query, err := toolQuery(req.Filter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	tools := applyToolFilter(s.getToolsForAgent(session, query), req.Filter)
 
-	// Apply pagination
 	totalCount := len(tools)
-	if req.Pagination != nil {
-		tools = s.applyPagination(tools, req.Pagination)
+	tools, pagination, err := paginateTools(tools, req.Pagination)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	s.logger.Debug("Listed tools for agent",
@@ -222,19 +492,13 @@ func (s *AgentServer) ListTools(ctx context.Context, req *agentpb.ListToolsReque
 	return &agentpb.ListToolsResponse{
 		Tools:      tools,
 		TotalCount: int32(totalCount),
-		Pagination: &agentpb.PaginationMetadata{
-			CurrentPage: 1,
-			PageSize:    int32(len(tools)),
-			TotalPages:  1,
-			HasNext:     false,
-			HasPrevious: false,
-		},
+		Pagination: pagination,
 	}, nil
 }
 
 // GetTool returns detailed information about a specific tool
 func (s *AgentServer) GetTool(ctx context.Context, req *agentpb.GetToolRequest) (*agentpb.GetToolResponse, error) {
-	_, exists := s.getSession(req.SessionId)
+	session, exists := s.getSession(req.SessionId)
 	if !exists {
 		return nil, status.Error(codes.Unauthenticated, "invalid session")
 	}
@@ -242,8 +506,9 @@ func (s *AgentServer) GetTool(ctx context.Context, req *agentpb.GetToolRequest)
 	// Update last heartbeat
 	s.updateHeartbeat(req.SessionId)
 
+	// Tools hidden from the session's scopes or roles are reported as missing
 	tool, err := s.registry.Get(req.ToolName)
-	if err != nil {
+	if err != nil || !s.toolAllowed(session, tool.Metadata()) {
 		return nil, status.Error(codes.NotFound, fmt.Sprintf("tool not found: %s", req.ToolName))
 	}
 
@@ -253,19 +518,19 @@ func (s *AgentServer) GetTool(ctx context.Context, req *agentpb.GetToolRequest)
 	var examples []*agentpb.ToolExample
 
 	if req.IncludeSchema {
-		// TODO: Extract schemas from tool metadata when available
-		inputSchema = `{"type": "object", "properties": {}}`
-		outputSchema = `{"type": "object", "properties": {}}`
-
-		// Add example usage
-		examples = []*agentpb.ToolExample{
-			{
-				Name:               "Basic Usage",
-				Description:        fmt.Sprintf("Example usage of %s tool", req.ToolName),
-				InputJson:          `{"parameter": "example_value"}`,
-				ExpectedOutputJson: `{"result": "example_result"}`,
-			},
+		refMode, err := schema.ParseRefMode(incomingValue(ctx, SchemaRefsMetadataKey))
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		toolMetadata := tool.Metadata()
+		if inputSchema, err = resolvedSchemaJSON(toolMetadata.Schema, "input", refMode); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("input schema of %s: %v", req.ToolName, err))
 		}
+		if outputSchema, err = resolvedSchemaJSON(toolMetadata.Schema, "output", refMode); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("output schema of %s: %v", req.ToolName, err))
+		}
+
+		examples = schemaExamples(req.ToolName, toolMetadata.Schema)
 	}
 
 	s.logger.Debug("Retrieved tool details",
@@ -281,6 +546,70 @@ func (s *AgentServer) GetTool(ctx context.Context, req *agentpb.GetToolRequest)
 	}, nil
 }
 
+// SchemaRefsMetadataKey is the request metadata key selecting how GetTool
+// resolves the $refs of tool schemas: none (default), inline or bundle
+const SchemaRefsMetadataKey = "x-aionmcp-schema-refs"
+
+// incomingValue returns the first value of a request metadata key
+func incomingValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// resolvedSchemaJSON encodes one schema of a tool's metadata with its $refs
+// resolved against the whole metadata schema. Tools without that schema
+// describe an arbitrary object.
+func resolvedSchemaJSON(toolSchema map[string]interface{}, key string, mode schema.RefMode) (string, error) {
+	subject, ok := toolSchema[key].(map[string]interface{})
+	if !ok {
+		subject = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	resolved, err := schema.ResolveRefs(subject, toolSchema, schema.ResolveOptions{Mode: mode})
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(resolved)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// schemaExamples builds an example invocation of a tool from its schemas.
+// Examples and defaults the spec declares are used where available and the
+// remaining values are synthesized from the schema types. Schemas whose $refs
+// cannot be inlined yield no example.
+func schemaExamples(toolName string, toolSchema map[string]interface{}) []*agentpb.ToolExample {
+	values := make(map[string]string, 2)
+	for _, key := range []string{"input", "output"} {
+		subject, ok := toolSchema[key].(map[string]interface{})
+		if !ok {
+			subject = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		resolved, err := schema.ResolveRefs(subject, toolSchema, schema.ResolveOptions{Mode: schema.RefModeInline})
+		if err != nil {
+			return nil
+		}
+		encoded, err := json.Marshal(schema.Example(resolved, schema.ExampleOptions{IncludeOptional: true}))
+		if err != nil {
+			return nil
+		}
+		values[key] = string(encoded)
+	}
+	return []*agentpb.ToolExample{{
+		Name:               "Basic Usage",
+		Description:        fmt.Sprintf("Example usage of %s tool, generated from its schemas", toolName),
+		InputJson:          values["input"],
+		ExpectedOutputJson: values["output"],
+	}}
+}
+
 // InvokeTool executes a tool with given parameters
 func (s *AgentServer) InvokeTool(ctx context.Context, req *agentpb.InvokeToolRequest) (*agentpb.InvokeToolResponse, error) {
 	session, exists := s.getSession(req.SessionId)
@@ -293,6 +622,18 @@ func (s *AgentServer) InvokeTool(ctx context.Context, req *agentpb.InvokeToolReq
 
 	startTime := time.Now()
 
+	// Reject invocations while the server or the session's workspace is read-only
+	if s.config.ReadOnly != nil {
+		if err := s.config.ReadOnly.Check(session.Metadata[readonly.WorkspaceMetadataKey]); err != nil {
+			s.recordInvocation(session, req, nil, nil, invocationlog.OutcomeRejected, err, time.Since(startTime))
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+	}
+
+	if req.InvocationId == "" {
+		req.InvocationId = uuid.New().String()
+	}
+
 	s.logger.Info("Tool invocation request",
 		zap.String("session_id", req.SessionId),
 		zap.String("tool_name", req.ToolName),
@@ -302,54 +643,230 @@ func (s *AgentServer) InvokeTool(ctx context.Context, req *agentpb.InvokeToolReq
 	tool, err := s.registry.Get(req.ToolName)
 	if err != nil {
 		s.updateMetrics(session, req.ToolName, false, time.Since(startTime))
+		s.recordInvocation(session, req, nil, nil, invocationlog.OutcomeRejected, err, time.Since(startTime))
 		return nil, status.Error(codes.NotFound, fmt.Sprintf("tool not found: %s", req.ToolName))
 	}
+	// Scopes and roles judge the tool by its canonical name, not an alias
+	if !s.toolAllowed(session, tool.Metadata()) {
+		err := fmt.Errorf("tool scope or roles of session %s do not allow tool %s", req.SessionId, req.ToolName)
+		s.recordInvocation(session, req, tool, nil, invocationlog.OutcomeRejected, err, time.Since(startTime))
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if deprecation := tool.Metadata().Deprecation; deprecation != nil {
+		s.announceDeprecation(ctx, req, deprecation)
+	}
 
 	// Parse parameters from JSON
-	var parameters map[string]interface{}
-	if req.ParametersJson != "" {
-		if err := json.Unmarshal([]byte(req.ParametersJson), &parameters); err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse parameters JSON: %v", err)
+	parameters, err := parseParameters(req.ParametersJson)
+	if err != nil {
+		s.recordInvocation(session, req, tool, nil, invocationlog.OutcomeRejected, err, time.Since(startTime))
+		return nil, err
+	}
+
+	// Enforce session rate and concurrency limits, then quotas and budgets
+	if err := s.acquireInvocationSlot(session); err != nil {
+		s.logger.Warn("Tool invocation rejected by session limits",
+			zap.String("session_id", req.SessionId),
+			zap.String("tool_name", req.ToolName),
+			zap.Error(err))
+		s.recordInvocation(session, req, tool, parameters, invocationlog.OutcomeRejected, err, time.Since(startTime))
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err := s.chargeQuotas(session, tool.Metadata()); err != nil {
+		s.releaseInvocationSlot(session)
+		s.logger.Warn("Tool invocation rejected by quota",
+			zap.String("session_id", req.SessionId),
+			zap.String("tool_name", req.ToolName),
+			zap.Error(err))
+		s.recordInvocation(session, req, tool, parameters, invocationlog.OutcomeRejected, err, time.Since(startTime))
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	// Async invocations hold the slot while queued and return immediately
+	if req.Options != nil && req.Options.Async {
+		job, err := s.submitInvocation(session, req, tool, parameters)
+		if err != nil {
+			s.releaseInvocationSlot(session)
+			s.recordInvocation(session, req, tool, parameters, invocationlog.OutcomeRejected, err, time.Since(startTime))
+			return nil, err
+		}
+		return &agentpb.InvokeToolResponse{
+			InvocationId: job.InvocationID,
+			Status:       job.Status,
+		}, nil
+	}
+	defer s.releaseInvocationSlot(session)
+
+	return s.executeInvocation(ctx, session, req, tool, parameters, startTime), nil
+}
+
+// announceDeprecation warns about a deprecated tool and, for gRPC callers,
+// annotates the response headers with the deprecation details
+func (s *AgentServer) announceDeprecation(ctx context.Context, req *agentpb.InvokeToolRequest, deprecation *types.Deprecation) {
+	annotations := deprecation.Annotations()
+	s.logger.Warn("Deprecated tool invoked",
+		zap.String("session_id", req.SessionId),
+		zap.String("tool_name", req.ToolName),
+		zap.String("sunset", annotations[types.AnnotationSunset]),
+		zap.String("replacement", deprecation.Replacement))
+
+	// Fails harmlessly when the call did not arrive over gRPC
+	_ = grpc.SetHeader(ctx, metadata.New(annotations))
+}
+
+// RetryHint is the machine-readable backoff advice carried in ToolError
+// metadata when an upstream asked callers to slow down
+type RetryHint struct {
+	RetryAfterMs   int64 `json:"retry_after_ms"`
+	UpstreamStatus int   `json:"upstream_status,omitempty"`
+}
+
+// executionToolError describes a failed tool execution. Failures the upstream
+// reported as temporary carry a RetryHint in the metadata; schema violations
+// carry the failing fields.
+func executionToolError(err error) *agentpb.ToolError {
+	toolError := &agentpb.ToolError{
+		Code:      agentpb.ErrorCode_ERROR_CODE_EXECUTION_FAILED,
+		Message:   err.Error(),
+		Details:   fmt.Sprintf("Tool execution failed: %v", err),
+		Retryable: true,
+	}
+
+	var stuck *types.StuckExecutionError
+	if errors.As(err, &stuck) {
+		toolError.Code = agentpb.ErrorCode_ERROR_CODE_TIMEOUT
+		toolError.Details = fmt.Sprintf("Tool execution force-cancelled by the watchdog: %v", err)
+		return toolError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		toolError.Code = agentpb.ErrorCode_ERROR_CODE_TIMEOUT
+		toolError.Details = fmt.Sprintf("Tool execution timed out: %v", err)
+		return toolError
+	}
+
+	var validationErr *schema.ValidationError
+	if errors.As(err, &validationErr) {
+		// Retrying the same invocation cannot succeed
+		toolError.Retryable = false
+		if validationErr.Subject == "input" {
+			toolError.Code = agentpb.ErrorCode_ERROR_CODE_INVALID_PARAMETERS
+			toolError.Details = fmt.Sprintf("Parameters do not match the tool input schema: %v", err)
 		}
+		violations, _ := json.Marshal(map[string][]schema.FieldError{"violations": validationErr.Errors})
+		toolError.MetadataJson = string(violations)
+		return toolError
 	}
 
-	// Execute tool
-	result, err := tool.Execute(parameters)
+	if retryable, ok := types.AsRetryable(err); ok {
+		if retryable.RateLimited() {
+			toolError.Code = agentpb.ErrorCode_ERROR_CODE_RATE_LIMITED
+		}
+		hint, _ := json.Marshal(map[string]RetryHint{
+			"retry": {RetryAfterMs: retryable.RetryAfter.Milliseconds(), UpstreamStatus: retryable.StatusCode},
+		})
+		toolError.MetadataJson = string(hint)
+	}
+	return toolError
+}
+
+// executeInvocation runs a tool, records the outcome and builds the response
+func (s *AgentServer) executeInvocation(ctx context.Context, session *AgentSession, req *agentpb.InvokeToolRequest, tool types.Tool, parameters map[string]interface{}, startTime time.Time) *agentpb.InvokeToolResponse {
+	// Execute tool, honouring the requested timeout on top of the caller's
+	// context and resolving the context variables of the session's workspace.
+	// Tools read the invocation's context entries, e.g. the environment to call.
+	execCtx := contextvars.WithWorkspace(ctx, session.Metadata[readonly.WorkspaceMetadataKey])
+	execCtx = types.WithInvocationContext(execCtx, req.Options.GetContext())
+	if req.Options != nil && req.Options.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(execCtx, time.Duration(req.Options.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+	s.tapInvocation(req, TapEvent{Type: TapInvocationStarted, Parameters: parameters})
+
+	policy := s.retryPolicy(req.Options.GetRetryPolicy())
+	var result any
+	var err error
+	var attemptTime time.Duration
+	attempt := 1
+	for ; ; attempt++ {
+		attemptStart := time.Now()
+		result, err = types.Execute(execCtx, tool, parameters)
+		attemptTime = time.Since(attemptStart)
+
+		if attempt > policy.maxRetries || !policy.retryable(result, err) {
+			break
+		}
+		wait, ok := policy.backoff(attempt, err)
+		if !ok {
+			break
+		}
+		s.recordExecution(ctx, session, req, tool, attempt, parameters, result, err, attemptTime)
+		retrying := TapEvent{Type: TapInvocationRetrying, Attempt: attempt, Result: result, DurationMs: attemptTime.Milliseconds()}
+		if err != nil {
+			retrying.Error = err.Error()
+		}
+		s.tapInvocation(req, retrying)
+		s.logger.Warn("Retrying tool invocation",
+			zap.String("session_id", req.SessionId),
+			zap.String("tool_name", req.ToolName),
+			zap.String("invocation_id", req.InvocationId),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", wait),
+			zap.Error(err))
+		if sleepContext(execCtx, wait) != nil {
+			break
+		}
+	}
 	executionTime := time.Since(startTime)
 
 	var toolError *agentpb.ToolError
 	var resultJson string
+	var resultData []byte
 	var status agentpb.ToolInvocationStatus
+	format := session.resultFormat()
 
 	if err != nil {
 		status = agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED
-		toolError = &agentpb.ToolError{
-			Code:      agentpb.ErrorCode_ERROR_CODE_EXECUTION_FAILED,
-			Message:   err.Error(),
-			Details:   fmt.Sprintf("Tool execution failed: %v", err),
-			Retryable: true,
-		}
+		toolError = executionToolError(err)
 		s.updateMetrics(session, req.ToolName, false, executionTime)
+		s.recordInvocation(session, req, tool, parameters, invocationlog.OutcomeFailure, err, executionTime)
+		s.recordExecution(ctx, session, req, tool, attempt, parameters, nil, err, attemptTime)
 
 		s.logger.Error("Tool execution failed",
 			zap.String("session_id", req.SessionId),
 			zap.String("tool_name", req.ToolName),
 			zap.String("invocation_id", req.InvocationId),
 			zap.Error(err))
+	} else if resultBytes, marshalErr := format.Marshal(result); marshalErr != nil {
+		// The tool ran but its result cannot be represented in the session's format
+		status = agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED
+		toolError = &agentpb.ToolError{
+			Code:      agentpb.ErrorCode_ERROR_CODE_INTERNAL_ERROR,
+			Message:   fmt.Sprintf("tool result is not %s serializable", strings.ToUpper(format.Name())),
+			Details:   marshalErr.Error(),
+			Retryable: false,
+		}
+		s.updateMetrics(session, req.ToolName, false, executionTime)
+		s.recordInvocation(session, req, tool, parameters, invocationlog.OutcomeFailure, marshalErr, executionTime)
+		s.recordExecution(ctx, session, req, tool, attempt, parameters, nil, marshalErr, attemptTime)
+
+		s.logger.Error("Failed to serialize tool result",
+			zap.String("session_id", req.SessionId),
+			zap.String("tool_name", req.ToolName),
+			zap.String("invocation_id", req.InvocationId),
+			zap.String("result_format", format.Name()),
+			zap.Error(marshalErr))
 	} else {
 		status = agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS
-		// Properly serialize result to JSON
-		resultBytes, err := json.Marshal(result)
-		if err != nil {
-			s.logger.Error("Failed to serialize tool result",
-				zap.String("session_id", req.SessionId),
-				zap.String("tool_name", req.ToolName),
-				zap.Error(err))
-			resultJson = fallbackErrorResultJSON
-		} else {
+		if format.Name() == FormatJSON {
 			resultJson = string(resultBytes)
+		} else {
+			resultData = resultBytes
 		}
 		s.updateMetrics(session, req.ToolName, true, executionTime)
+		s.recordInvocation(session, req, tool, parameters, invocationlog.OutcomeSuccess, nil, executionTime)
+		s.recordExecution(ctx, session, req, tool, attempt, parameters, result, nil, attemptTime)
 
 		s.logger.Info("Tool executed successfully",
 			zap.String("session_id", req.SessionId),
@@ -358,29 +875,122 @@ func (s *AgentServer) InvokeTool(ctx context.Context, req *agentpb.InvokeToolReq
 			zap.Duration("execution_time", executionTime))
 	}
 
+	s.recordUsage(session, req.ToolName, status, executionTime, toolError)
+
+	completed := TapEvent{
+		Type:       TapInvocationCompleted,
+		Attempt:    attempt,
+		Status:     status.String(),
+		DurationMs: executionTime.Milliseconds(),
+	}
+	if toolError != nil {
+		completed.Error = toolError.Message
+	} else {
+		completed.Result = result
+	}
+	s.tapInvocation(req, completed)
+
 	// Broadcast tool invocation event
 	s.broadcastEvent(&agentpb.Event{
 		EventId:       uuid.New().String(),
 		Type:          agentpb.EventType_EVENT_TYPE_TOOL_INVOCATION,
-		TimestampUnix: time.Now().Unix(),
+		TimestampUnix: s.config.Clock.Now().Unix(),
 		SessionId:     req.SessionId,
-		DataJson:      fmt.Sprintf(`{"tool_name": "%s", "status": "%s", "execution_time_ms": %d}`, req.ToolName, status.String(), executionTime.Milliseconds()),
+		DataJson: encodeEventData(map[string]interface{}{
+			"tool_name":         req.ToolName,
+			"status":            status.String(),
+			"execution_time_ms": executionTime.Milliseconds(),
+		}),
 	})
 
 	return &agentpb.InvokeToolResponse{
 		InvocationId: req.InvocationId,
 		Status:       status,
 		ResultJson:   resultJson,
+		ResultData:   resultData,
+		ResultFormat: format.Name(),
 		Error:        toolError,
 		Metrics: &agentpb.ToolMetrics{
 			ExecutionTimeMs: executionTime.Milliseconds(),
-			RetryCount:      0,
+			RetryCount:      int32(attempt - 1),
 			CustomMetrics: map[string]float64{
-				"execution_timestamp": float64(time.Now().Unix()),
+				"execution_timestamp": float64(s.config.Clock.Now().Unix()),
 			},
 		},
-		ExecutedAtUnix: time.Now().Unix(),
-	}, nil
+		ExecutedAtUnix: s.config.Clock.Now().Unix(),
+	}
+}
+
+// recordInvocation exports an invocation record when the invocation log is
+// enabled and audits the invocation. Rejected invocations never reach
+// executeInvocation, so they are shown to operators tapping the session here.
+func (s *AgentServer) recordInvocation(session *AgentSession, req *agentpb.InvokeToolRequest, tool types.Tool, parameters map[string]interface{}, outcome invocationlog.Outcome, err error, latency time.Duration) {
+	if outcome == invocationlog.OutcomeRejected {
+		rejected := TapEvent{Type: TapInvocationRejected, Parameters: parameters, DurationMs: latency.Milliseconds()}
+		if err != nil {
+			rejected.Error = err.Error()
+		}
+		s.tapInvocation(req, rejected)
+	}
+	s.auditInvocation(session, req, tool, parameters, outcome, err)
+	if s.config.InvocationLog == nil {
+		return
+	}
+
+	record := invocationlog.Record{
+		InvocationID: req.InvocationId,
+		Actor: invocationlog.Actor{
+			Type: "agent",
+			ID:   session.ID,
+			Name: session.AgentName,
+		},
+		Tool:       req.ToolName,
+		Transport:  "grpc",
+		ParamsHash: invocationlog.HashParams(parameters),
+		Outcome:    outcome,
+		LatencyMs:  latency.Milliseconds(),
+	}
+	if tool != nil {
+		record.ToolSource = tool.Metadata().Source
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	s.config.InvocationLog.Record(record)
+}
+
+// recordExecution stores one attempt of an execution when an execution recorder
+// is configured, limited to what the session's capture level allows
+func (s *AgentServer) recordExecution(ctx context.Context, session *AgentSession, req *agentpb.InvokeToolRequest, tool types.Tool, attempt int, input, output interface{}, err error, duration time.Duration) {
+	if s.config.Executions == nil || session.CaptureLevel == CaptureNone {
+		return
+	}
+	if session.CaptureLevel != CaptureFull {
+		input, output = nil, nil
+	}
+
+	execution := AgentExecution{
+		SessionID:    session.ID,
+		AgentID:      session.AgentID,
+		AgentName:    session.AgentName,
+		InvocationID: req.InvocationId,
+		ToolName:     req.ToolName,
+		SourceType:   tool.Metadata().Source,
+		Input:        input,
+		Output:       output,
+		Err:          err,
+		Duration:     duration,
+		Attempt:      attempt,
+		Workspace:    session.Metadata[readonly.WorkspaceMetadataKey],
+		Tenant:       session.Metadata[TenantMetadataKey],
+	}
+	if recordErr := s.config.Executions.RecordAgentExecution(ctx, execution); recordErr != nil {
+		s.logger.Warn("Failed to record agent execution",
+			zap.String("session_id", session.ID),
+			zap.String("tool_name", req.ToolName),
+			zap.Error(recordErr))
+	}
 }
 
 // StreamEvents provides real-time events to agents
@@ -394,48 +1004,68 @@ func (s *AgentServer) StreamEvents(req *agentpb.StreamEventsRequest, stream agen
 		zap.String("session_id", req.SessionId),
 		zap.String("agent_id", session.AgentID))
 
-	// Create event channel for this stream
-	eventChan := make(chan *agentpb.Event, 100)
+	filter, err := newEventFilter(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	// Register the stream
-	s.streamsMux.Lock()
-	if s.eventStreams[req.SessionId] == nil {
-		s.eventStreams[req.SessionId] = make([]chan *agentpb.Event, 0)
-	}
-	s.eventStreams[req.SessionId] = append(s.eventStreams[req.SessionId], eventChan)
-	s.streamsMux.Unlock()
+	eventStream := s.openEventStream(stream.Context(), req.SessionId, filter)
 
 	// Send initial connection event
 	connectEvent := &agentpb.Event{
 		EventId:       uuid.New().String(),
 		Type:          agentpb.EventType_EVENT_TYPE_SERVER_STATUS,
-		TimestampUnix: time.Now().Unix(),
+		TimestampUnix: s.config.Clock.Now().Unix(),
 		SessionId:     req.SessionId,
 		DataJson:      `{"status": "connected", "message": "Event stream established"}`,
 	}
 
 	if err := stream.Send(connectEvent); err != nil {
 		s.logger.Error("Failed to send connection event", zap.Error(err))
+		s.removeEventStream(req.SessionId, eventStream)
+		return err
+	}
+
+	// Replay the logged events the client asked for. The stream is already
+	// open, so live events logged meanwhile arrive twice and are skipped.
+	replayed, err := s.replayEvents(stream, req, filter)
+	if err != nil {
+		s.logger.Error("Failed to replay events",
+			zap.String("session_id", req.SessionId),
+			zap.Error(err))
+		s.removeEventStream(req.SessionId, eventStream)
 		return err
 	}
 
-	// Stream events until context is done or client disconnects
+	// Stream events until context is done, the client disconnects or the
+	// stream is closed because its session ended or it was found dead
 	for {
 		select {
 		case <-stream.Context().Done():
 			s.logger.Info("Event stream closed by client",
 				zap.String("session_id", req.SessionId))
-			s.removeEventStream(req.SessionId, eventChan)
+			s.removeEventStream(req.SessionId, eventStream)
 			return nil
 
-		case event := <-eventChan:
+		case <-eventStream.done:
+			s.logger.Info("Event stream closed by server",
+				zap.String("session_id", req.SessionId))
+			s.removeEventStream(req.SessionId, eventStream)
+			return nil
+
+		case event := <-eventStream.events:
+			if event.Sequence != 0 && event.Sequence <= replayed {
+				continue
+			}
 			if err := stream.Send(event); err != nil {
 				s.logger.Error("Failed to send event",
 					zap.String("session_id", req.SessionId),
 					zap.Error(err))
-				s.removeEventStream(req.SessionId, eventChan)
+				s.removeEventStream(req.SessionId, eventStream)
 				return err
 			}
+			eventStream.delivered(s.config.Clock.Now())
 		}
 	}
 }
@@ -451,18 +1081,19 @@ func (s *AgentServer) HeartBeat(ctx context.Context, req *agentpb.HeartBeatReque
 
 	// Update heartbeat and status
 	s.sessionsMux.Lock()
-	session.LastHeartbeat = time.Now()
+	session.LastHeartbeat = s.config.Clock.Now()
 	if req.Status != agentpb.AgentStatus_AGENT_STATUS_UNSPECIFIED {
 		session.Status = req.Status
 	}
 	s.sessionsMux.Unlock()
 
-	nextHeartbeat := time.Now().Add(30 * time.Second) // 30 second heartbeat interval
+	nextHeartbeat := s.config.Clock.Now().Add(30 * time.Second) // 30 second heartbeat interval
 
 	return &agentpb.HeartBeatResponse{
 		SessionValid:         true,
 		NextHeartbeatAtUnix:  nextHeartbeat.Unix(),
-		PendingNotifications: []string{}, // Placeholder for future notifications
+		PendingNotifications: session.notices.take(),
+		Limits:               s.limitsSnapshot(session),
 	}, nil
 }
 
@@ -475,6 +1106,9 @@ func (s *AgentServer) GetAgentStatus(ctx context.Context, req *agentpb.GetAgentS
 
 	s.updateHeartbeat(req.SessionId)
 
+	// AgentSessionInfo has no capture level field; gRPC callers read it from the response headers
+	_ = grpc.SetHeader(ctx, metadata.Pairs(CaptureLevelMetadataKey, string(session.CaptureLevel)))
+
 	sessionInfo := &agentpb.AgentSessionInfo{
 		SessionId:         session.ID,
 		AgentId:           session.AgentID,
@@ -504,7 +1138,7 @@ func (s *AgentServer) GetAgentStatus(ctx context.Context, req *agentpb.GetAgentS
 	return &agentpb.GetAgentStatusResponse{
 		SessionInfo:     sessionInfo,
 		Metrics:         metrics,
-		RecentToolUsage: []*agentpb.ToolUsageInfo{}, // Placeholder for recent usage history
+		RecentToolUsage: s.recentToolUsage(session.ID),
 	}, nil
 }
 
@@ -521,7 +1155,7 @@ func (s *AgentServer) updateHeartbeat(sessionID string) {
 	s.sessionsMux.Lock()
 	defer s.sessionsMux.Unlock()
 	if session, exists := s.sessions[sessionID]; exists {
-		session.LastHeartbeat = time.Now()
+		session.LastHeartbeat = s.config.Clock.Now()
 	}
 }
 
@@ -531,7 +1165,8 @@ func (s *AgentServer) updateMetrics(session *AgentSession, toolName string, succ
 
 	session.Metrics.TotalInvocations++
 	session.Metrics.TotalResponseTimeMs += duration.Milliseconds()
-	session.Metrics.LastInvocation = time.Now()
+	session.Metrics.LastInvocation = s.config.Clock.Now()
+	session.Metrics.LastTool = toolName
 
 	if success {
 		session.Metrics.SuccessfulInvocations++
@@ -540,31 +1175,141 @@ func (s *AgentServer) updateMetrics(session *AgentSession, toolName string, succ
 	}
 
 	session.Metrics.ToolUsageCount[toolName]++
+	session.Metrics.ToolLastUsed[toolName] = session.Metrics.LastInvocation
+	s.quotas.chargeDuration(session.ID, duration)
 }
 
-func (s *AgentServer) getToolsForAgent(session *AgentSession) []*agentpb.ToolInfo {
-	toolMetadata := s.registry.ListTools()
+// SessionCounts counts the sessions by reported status, e.g. "active" or "idle"
+func (s *AgentServer) SessionCounts() map[string]int {
+	s.sessionsMux.RLock()
+	defer s.sessionsMux.RUnlock()
+
+	counts := make(map[string]int)
+	for _, session := range s.sessions {
+		counts[strings.ToLower(strings.TrimPrefix(session.Status.String(), "AGENT_STATUS_"))]++
+	}
+	return counts
+}
+
+// RuntimeState is a snapshot of the agent server's internal state for diagnostics
+type RuntimeState struct {
+	Sessions      int   `json:"sessions"`
+	EventStreams  int   `json:"event_streams"`  // Open StreamEvents calls
+	StreamsReaped int64 `json:"streams_reaped"` // Event streams closed as dead
+	Taps          int   `json:"taps"`           // Operators tapping sessions
+	AsyncJobs     int   `json:"async_jobs"`     // Async invocations still queryable
+	AsyncQueued   int   `json:"async_queued"`   // Async invocations waiting for a worker
+	AsyncCapacity int   `json:"async_capacity"` // Size of the async invocation queue
+}
+
+// RuntimeState snapshots the sessions, streams and async invocations the server holds
+func (s *AgentServer) RuntimeState() RuntimeState {
+	state := RuntimeState{AsyncQueued: len(s.jobQueue), AsyncCapacity: cap(s.jobQueue)}
+
+	s.sessionsMux.RLock()
+	state.Sessions = len(s.sessions)
+	s.sessionsMux.RUnlock()
+
+	s.streamsMux.RLock()
+	for _, streams := range s.eventStreams {
+		state.EventStreams += len(streams)
+	}
+	s.streamsMux.RUnlock()
+
+	s.jobsMux.RLock()
+	state.AsyncJobs = len(s.jobs)
+	s.jobsMux.RUnlock()
+
+	state.StreamsReaped = s.streamsReaped.Load()
+	state.Taps = s.tap.count()
+	return state
+}
+
+// ActiveToolSessions counts, per tool, the live sessions that invoked the
+// tool at or after since. Sessions reporting themselves disconnected or in
+// error do not count.
+func (s *AgentServer) ActiveToolSessions(since time.Time) map[string]int {
+	s.sessionsMux.RLock()
+	sessions := make([]*AgentSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		switch session.Status {
+		case agentpb.AgentStatus_AGENT_STATUS_DISCONNECTED, agentpb.AgentStatus_AGENT_STATUS_ERROR:
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	s.sessionsMux.RUnlock()
+
+	counts := make(map[string]int)
+	for _, session := range sessions {
+		session.Metrics.mu.RLock()
+		for toolName, lastUsed := range session.Metrics.ToolLastUsed {
+			if !lastUsed.Before(since) {
+				counts[toolName]++
+			}
+		}
+		session.Metrics.mu.RUnlock()
+	}
+	return counts
+}
+
+// getToolsForAgent lists the tools matching a query that the session's
+// scopes and roles allow. Registries that implement types.ToolFinder answer the query from
+// their indexes; others are listed in full and filtered.
+func (s *AgentServer) getToolsForAgent(session *AgentSession, query types.ToolQuery) []*agentpb.ToolInfo {
+	var toolMetadata []types.ToolMetadata
+	if finder, ok := s.registry.(types.ToolFinder); ok {
+		toolMetadata = finder.FindTools(query)
+	} else if query.IsZero() {
+		toolMetadata = s.registry.ListTools()
+	} else {
+		for _, metadata := range s.registry.ListTools() {
+			source, _ := s.registry.GetSource(metadata.Name)
+			if query.Matches(metadata, source) {
+				toolMetadata = append(toolMetadata, metadata)
+			}
+		}
+	}
 	result := make([]*agentpb.ToolInfo, 0, len(toolMetadata))
 
 	for _, metadata := range toolMetadata {
+		if !s.toolAllowed(session, metadata) {
+			continue
+		}
 		result = append(result, s.convertToolMetadataToToolInfo(metadata))
 	}
 
 	return result
 }
 
+// toolAllowed reports whether the session's tool scopes and roles allow a tool
+func (s *AgentServer) toolAllowed(session *AgentSession, metadata types.ToolMetadata) bool {
+	if !session.toolInScope(metadata.Name) {
+		return false
+	}
+	if s.config.ToolAccess == nil {
+		return true
+	}
+	var roles []string
+	if session.Identity != nil {
+		roles = session.Identity.Roles
+	}
+	source, _ := s.registry.GetSource(metadata.Name)
+	return s.config.ToolAccess(roles, metadata, source)
+}
+
 func (s *AgentServer) convertToToolInfo(tool types.Tool) *agentpb.ToolInfo {
 	metadata := tool.Metadata()
 	return s.convertToolMetadataToToolInfo(metadata)
 }
 
 func (s *AgentServer) convertToolMetadataToToolInfo(metadata types.ToolMetadata) *agentpb.ToolInfo {
-	return &agentpb.ToolInfo{
+	info := &agentpb.ToolInfo{
 		Name:          metadata.Name,
 		DisplayName:   metadata.Name,
 		Description:   metadata.Description,
 		Version:       metadata.Version,
-		Type:          agentpb.ToolType_TOOL_TYPE_FUNCTION, // Default type
+		Type:          toolType(metadata.Source),
 		Status:        agentpb.ToolStatus_TOOL_STATUS_AVAILABLE,
 		Tags:          metadata.Tags,
 		Metadata:      make(map[string]string),
@@ -575,29 +1320,98 @@ func (s *AgentServer) convertToolMetadataToToolInfo(metadata types.ToolMetadata)
 			SpecType: metadata.Source,
 		},
 	}
+	if metadata.Deprecation != nil {
+		info.Status = agentpb.ToolStatus_TOOL_STATUS_DEPRECATED
+		info.Metadata = metadata.Deprecation.Annotations()
+	}
+	return info
+}
+
+// toolType maps the source type in tool metadata to the tool type agents see.
+// Built-in and other tools are functions.
+func toolType(source string) agentpb.ToolType {
+	switch source {
+	case "openapi":
+		return agentpb.ToolType_TOOL_TYPE_OPENAPI
+	case "graphql":
+		return agentpb.ToolType_TOOL_TYPE_GRAPHQL
+	case "asyncapi":
+		return agentpb.ToolType_TOOL_TYPE_ASYNCAPI
+	}
+	return agentpb.ToolType_TOOL_TYPE_FUNCTION
+}
+
+// toolQuery translates the tags, sources, name pattern and times of a filter
+// into a registry query
+func toolQuery(filter *agentpb.ToolFilter) (types.ToolQuery, error) {
+	if filter == nil {
+		return types.ToolQuery{}, nil
+	}
+	query := types.ToolQuery{
+		Tags:    filter.Tags,
+		Sources: filter.Sources,
+		Name:    filter.NamePattern,
+	}
+	if filter.CreatedAfterUnix > 0 {
+		query.CreatedAfter = time.Unix(filter.CreatedAfterUnix, 0)
+	}
+	if filter.UpdatedAfterUnix > 0 {
+		query.UpdatedAfter = time.Unix(filter.UpdatedAfterUnix, 0)
+	}
+	return query, query.Validate()
 }
 
-func (s *AgentServer) applyToolFilter(tools []*agentpb.ToolInfo, filter *agentpb.ToolFilter) []*agentpb.ToolInfo {
-	// Placeholder implementation - would include actual filtering logic
-	return tools
+// applyToolFilter keeps the tools of the filter's types and statuses, which
+// are only known once tools are converted for agents
+func applyToolFilter(tools []*agentpb.ToolInfo, filter *agentpb.ToolFilter) []*agentpb.ToolInfo {
+	if len(filter.GetTypes()) == 0 && len(filter.GetStatuses()) == 0 {
+		return tools
+	}
+	filtered := make([]*agentpb.ToolInfo, 0, len(tools))
+	for _, tool := range tools {
+		if len(filter.Types) > 0 && !containsValue(filter.Types, tool.Type) {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !containsValue(filter.Statuses, tool.Status) {
+			continue
+		}
+		filtered = append(filtered, tool)
+	}
+	return filtered
 }
 
-func (s *AgentServer) applyPagination(tools []*agentpb.ToolInfo, pagination *agentpb.PaginationOptions) []*agentpb.ToolInfo {
-	// Placeholder implementation - would include actual pagination logic
-	return tools
+// containsValue reports whether values holds value
+func containsValue[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *AgentServer) broadcastEvent(event *agentpb.Event) {
+	if err := s.eventLog.append(event); err != nil {
+		s.logger.Warn("Failed to persist event",
+			zap.String("event_type", event.Type.String()),
+			zap.Uint64("sequence", event.Sequence),
+			zap.Error(err))
+	}
+
+	// Streams only receive the events their filters select, so the tool an
+	// event is about is looked up once for all of them
+	toolName := eventToolName(event)
+
 	s.streamsMux.RLock()
 	defer s.streamsMux.RUnlock()
 
 	for sessionID, streams := range s.eventStreams {
 		for _, stream := range streams {
-			select {
-			case stream <- event:
-				// Event sent successfully
-			default:
-				// Channel is full, skip this stream
+			if !stream.filter.matches(event, toolName) {
+				continue
+			}
+			if !stream.offer(event) {
+				// Buffer is full, skip this stream
 				s.logger.Warn("Event stream channel full",
 					zap.String("session_id", sessionID),
 					zap.String("event_type", event.Type.String()))
@@ -606,16 +1420,16 @@ func (s *AgentServer) broadcastEvent(event *agentpb.Event) {
 	}
 }
 
-func (s *AgentServer) removeEventStream(sessionID string, targetChan chan *agentpb.Event) {
+func (s *AgentServer) removeEventStream(sessionID string, target *eventStream) {
 	s.streamsMux.Lock()
 	defer s.streamsMux.Unlock()
 
+	target.close()
 	if streams, exists := s.eventStreams[sessionID]; exists {
 		for i, stream := range streams {
-			if stream == targetChan {
+			if stream == target {
 				// Remove this stream from the slice
 				s.eventStreams[sessionID] = append(streams[:i], streams[i+1:]...)
-				close(targetChan)
 				break
 			}
 		}
@@ -631,21 +1445,24 @@ func (s *AgentServer) closeEventStreams(sessionID string) {
 	s.streamsMux.Lock()
 	defer s.streamsMux.Unlock()
 
-	if streams, exists := s.eventStreams[sessionID]; exists {
-		for _, stream := range streams {
-			close(stream)
-		}
-		delete(s.eventStreams, sessionID)
+	for _, stream := range s.eventStreams[sessionID] {
+		stream.close()
 	}
+	delete(s.eventStreams, sessionID)
+	delete(s.streamDrops, sessionID)
 }
 
-func (s *AgentServer) sessionCleanup() {
-	ticker := time.NewTicker(1 * time.Minute) // Check every minute
+// sessionCleanupInterval is how often expired sessions are removed
+const sessionCleanupInterval = time.Minute
+
+func (s *AgentServer) sessionCleanup(ticker clock.Ticker) {
 	defer ticker.Stop()
 
-	for range ticker.C {
-		now := time.Now()
+	for range ticker.C() {
+		now := s.config.Clock.Now()
+		s.purgeInvocations(now)
 		s.sessionsMux.Lock()
+		s.purgeExpired(now)
 
 		for sessionID, session := range s.sessions {
 			if now.After(session.ExpiresAt) {
@@ -654,9 +1471,13 @@ func (s *AgentServer) sessionCleanup() {
 					zap.String("agent_id", session.AgentID))
 
 				delete(s.sessions, sessionID)
+				s.retainExpired(session)
+				go s.auditSession(audit.ActionSessionExpire, session, audit.Actor{Type: "system"})
 
-				// Close event streams for expired session
+				// Close event streams and taps and cancel async invocations for expired session
 				go s.closeEventStreams(sessionID)
+				go s.tap.closeSession(sessionID)
+				go s.cancelSessionInvocations(sessionID)
 
 				// Broadcast session expired event
 				go s.broadcastEvent(&agentpb.Event{
@@ -664,7 +1485,7 @@ func (s *AgentServer) sessionCleanup() {
 					Type:          agentpb.EventType_EVENT_TYPE_SESSION_EXPIRED,
 					TimestampUnix: now.Unix(),
 					SessionId:     sessionID,
-					DataJson:      fmt.Sprintf(`{"agent_id": "%s", "reason": "expired"}`, session.AgentID),
+					DataJson:      encodeEventData(map[string]interface{}{"agent_id": session.AgentID, "reason": "expired"}),
 				})
 			}
 		}
@@ -1,16 +1,37 @@
 package agent
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/capabilities"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/aionmcp/aionmcp/pkg/eventstore"
+	"github.com/aionmcp/aionmcp/pkg/invocationlog"
+	"github.com/aionmcp/aionmcp/pkg/readonly"
+	"github.com/aionmcp/aionmcp/pkg/sessionhistory"
 	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 // MockTool implements the types.Tool interface for testing
@@ -28,7 +49,7 @@ func (m *MockTool) Description() string {
 	return args.String(0)
 }
 
-func (m *MockTool) Execute(input any) (any, error) {
+func (m *MockTool) Execute(ctx context.Context, input any) (any, error) {
 	args := m.Called(input)
 	return args.Get(0), args.Error(1)
 }
@@ -243,6 +264,7 @@ func TestAgentServer_ListTools(t *testing.T) {
 			Description: "Tool 2",
 			Version:     "1.1.0",
 			Source:      "test",
+			Deprecation: &types.Deprecation{Replacement: "tool1"},
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -268,13 +290,203 @@ func TestAgentServer_ListTools(t *testing.T) {
 	assert.Equal(t, "tool1", listResp.Tools[0].Name)
 	assert.Equal(t, "tool2", listResp.Tools[1].Name)
 
+	// Deprecated tools are flagged with their replacement
+	assert.Equal(t, agentpb.ToolStatus_TOOL_STATUS_AVAILABLE, listResp.Tools[0].Status)
+	assert.Equal(t, agentpb.ToolStatus_TOOL_STATUS_DEPRECATED, listResp.Tools[1].Status)
+	assert.Equal(t, "tool1", listResp.Tools[1].Metadata[types.AnnotationReplacement])
+
 	mockRegistry.AssertExpectations(t)
 }
 
+func TestAgentServer_ListToolsFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	server := NewAgentServer(logger, mockRegistry)
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{
+		{Name: "openapi.billing.list", Source: "openapi", Tags: []string{"payments", "read"}, CreatedAt: created, UpdatedAt: created},
+		{Name: "openapi.billing.refund", Source: "openapi", Tags: []string{"payments"}, CreatedAt: created, UpdatedAt: created.Add(time.Hour),
+			Deprecation: &types.Deprecation{}},
+		{Name: "graphql.users.get", Source: "graphql", Tags: []string{"read"}, CreatedAt: created, UpdatedAt: created},
+		{Name: "echo", Source: "builtin", CreatedAt: created, UpdatedAt: created},
+	})
+	mockRegistry.On("GetSource", "echo").Return("builtin", nil)
+	mockRegistry.On("GetSource", mock.Anything).Return("billing", nil)
+
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "test-agent-1", AgentName: "Test Agent"})
+	require.NoError(t, err)
+	list := func(filter *agentpb.ToolFilter) []string {
+		response, err := server.ListTools(context.Background(), &agentpb.ListToolsRequest{SessionId: registerResp.SessionId, Filter: filter})
+		require.NoError(t, err)
+		names := make([]string, len(response.Tools))
+		for i, tool := range response.Tools {
+			names[i] = tool.Name
+		}
+		return names
+	}
+
+	// Tools must carry every tag, and match any of the types and statuses
+	assert.Equal(t, []string{"openapi.billing.list"}, list(&agentpb.ToolFilter{Tags: []string{"payments", "read"}}))
+	assert.Equal(t, []string{"graphql.users.get", "echo"}, list(&agentpb.ToolFilter{
+		Types: []agentpb.ToolType{agentpb.ToolType_TOOL_TYPE_GRAPHQL, agentpb.ToolType_TOOL_TYPE_FUNCTION},
+	}))
+	assert.Equal(t, []string{"openapi.billing.refund"}, list(&agentpb.ToolFilter{Statuses: []agentpb.ToolStatus{agentpb.ToolStatus_TOOL_STATUS_DEPRECATED}}))
+	assert.Equal(t, []string{"echo"}, list(&agentpb.ToolFilter{Sources: []string{"builtin"}}))
+	assert.Equal(t, []string{"openapi.billing.list", "openapi.billing.refund"}, list(&agentpb.ToolFilter{NamePattern: "openapi.billing.*"}))
+	assert.Equal(t, []string{"openapi.billing.refund"}, list(&agentpb.ToolFilter{UpdatedAfterUnix: created.Unix()}))
+
+	_, err = server.ListTools(context.Background(), &agentpb.ListToolsRequest{SessionId: registerResp.SessionId, Filter: &agentpb.ToolFilter{NamePattern: "openapi.[billing"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The REST endpoint takes the filter as query parameters
+	router := gin.New()
+	NewAgentAPI(logger, mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+	get := func(query string) (int, string) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/agents/"+registerResp.SessionId+"/tools?"+query, nil))
+		return recorder.Code, recorder.Body.String()
+	}
+	code, body := get("tag=payments&type=openapi&status=TOOL_STATUS_AVAILABLE")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Contains(t, body, `"name":"openapi.billing.list"`)
+	assert.NotContains(t, body, "refund")
+	code, _ = get("type=soap")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("name=%5B")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestAgentServer_ToolScopes(t *testing.T) {
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{
+		{Name: "billing.get"}, {Name: "billing.delete"}, {Name: "users.list"},
+	})
+	for _, name := range []string{"billing.get", "billing.delete", "users.list"} {
+		tool := &MockTool{}
+		tool.On("Metadata").Return(types.ToolMetadata{Name: name})
+		tool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+		mockRegistry.On("Get", name).Return(tool, nil)
+	}
+	config := DefaultAgentServerConfig()
+	config.ToolScopes = []ToolScopePolicy{{AgentID: "billing-*", ToolScope: ToolScope{Allow: []string{"billing.*"}}}}
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	names := func(tools []*agentpb.ToolInfo) []string {
+		result := make([]string, len(tools))
+		for i, tool := range tools {
+			result[i] = tool.Name
+		}
+		return result
+	}
+
+	// The policy allows billing tools; the agent narrows that further
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId: "billing-bot", AgentName: "Billing Bot", DeniedTools: []string{"*.delete"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing.get"}, names(registerResp.AvailableTools))
+	sessionID := registerResp.SessionId
+
+	listResp, err := server.ListTools(context.Background(), &agentpb.ListToolsRequest{SessionId: sessionID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing.get"}, names(listResp.Tools))
+	_, err = server.GetTool(context.Background(), &agentpb.GetToolRequest{SessionId: sessionID, ToolName: "billing.delete"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: sessionID, ToolName: "billing.get"})
+	assert.NoError(t, err)
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: sessionID, ToolName: "users.list"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Aliases resolve to the canonical tool, which the scope judges
+	aliased := &MockTool{}
+	aliased.On("Metadata").Return(types.ToolMetadata{Name: "billing.get"})
+	aliased.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	mockRegistry.On("Get", "invoices").Return(aliased, nil)
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: sessionID, ToolName: "invoices"})
+	assert.NoError(t, err, "an allowlisted tool may be called by its alias")
+
+	// Agents cannot widen what a policy allows
+	registerResp, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId: "billing-bot", AgentName: "Billing Bot", AllowedTools: []string{"users.*"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, registerResp.AvailableTools)
+
+	// Other agents only get the scope they ask for
+	registerResp, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId: "ops-bot", AgentName: "Ops Bot", AllowedTools: []string{"users.list", "billing.get"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing.get", "users.list"}, names(registerResp.AvailableTools))
+
+	_, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId: "ops-bot", AgentName: "Ops Bot", AllowedTools: []string{"[billing"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAgentServer_ListToolsPagination(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	server := NewAgentServer(logger, mockRegistry)
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tool := func(name string, updated int) types.ToolMetadata {
+		return types.ToolMetadata{Name: name, CreatedAt: created, UpdatedAt: created.Add(time.Duration(updated) * time.Hour)}
+	}
+	tools := []types.ToolMetadata{tool("e", 1), tool("c", 3), tool("a", 2), tool("d", 3), tool("b", 0)}
+	mockRegistry.On("ListTools").Return(tools).Times(6)
+
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "test-agent-1", AgentName: "Test Agent"})
+	require.NoError(t, err)
+	list := func(options *agentpb.PaginationOptions) ([]string, *agentpb.PaginationMetadata) {
+		response, err := server.ListTools(context.Background(), &agentpb.ListToolsRequest{SessionId: registerResp.SessionId, Pagination: options})
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), response.TotalCount)
+		names := make([]string, len(response.Tools))
+		for i, tool := range response.Tools {
+			names[i] = tool.Name
+		}
+		return names, response.Pagination
+	}
+
+	// Pages are sorted by name by default
+	names, page := list(&agentpb.PaginationOptions{Page: 2, PageSize: 2})
+	assert.Equal(t, []string{"c", "d"}, names)
+	assert.Equal(t, &agentpb.PaginationMetadata{CurrentPage: 2, PageSize: 2, TotalPages: 3, HasNext: true, HasPrevious: true, NextCursor: page.NextCursor}, page)
+	names, page = list(&agentpb.PaginationOptions{Page: 3, PageSize: 2})
+	assert.Equal(t, []string{"e"}, names)
+	assert.False(t, page.HasNext)
+	assert.Empty(t, page.NextCursor)
+
+	// Pages past the end are empty and labelled as requested
+	names, page = list(&agentpb.PaginationOptions{Page: 5, PageSize: 2})
+	assert.Empty(t, names)
+	assert.Equal(t, &agentpb.PaginationMetadata{CurrentPage: 5, PageSize: 2, TotalPages: 3, HasPrevious: true}, page)
+
+	// Time orders break ties by name
+	names, page = list(&agentpb.PaginationOptions{PageSize: 3, SortBy: SortByUpdatedAt, SortDesc: true})
+	assert.Equal(t, []string{"d", "c", "a"}, names)
+
+	// Cursors keep their order and resume after the last tool, even when
+	// tools are added in between
+	mockRegistry.On("ListTools").Return(append([]types.ToolMetadata{tool("aa", 3)}, tools...))
+	names, page = list(&agentpb.PaginationOptions{PageSize: 3, Cursor: page.NextCursor})
+	assert.Equal(t, []string{"e", "b"}, names)
+	assert.False(t, page.HasNext)
+
+	for _, options := range []*agentpb.PaginationOptions{{SortBy: "size"}, {Cursor: "not-a-cursor"}} {
+		_, err = server.ListTools(context.Background(), &agentpb.ListToolsRequest{SessionId: registerResp.SessionId, Pagination: options})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
 func TestAgentServer_InvokeTool(t *testing.T) {
 	logger := zap.NewNop()
 	mockRegistry := &MockToolRegistry{}
 	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
 	server := NewAgentServer(logger, mockRegistry)
 
 	// Register an agent first
@@ -314,6 +526,271 @@ func TestAgentServer_InvokeTool(t *testing.T) {
 	mockTool.AssertExpectations(t)
 }
 
+func TestAgentServer_InvokeTool_Timeout(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "slow-tool"})
+	mockTool.On("Name").Return("slow-tool")
+	server := NewAgentServer(logger, mockRegistry)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+
+	// The tool ignores its context; the invocation still returns at the deadline
+	mockRegistry.On("Get", "slow-tool").Return(mockTool, nil)
+	mockTool.On("Execute", mock.Anything).Run(func(mock.Arguments) {
+		time.Sleep(500 * time.Millisecond)
+	}).Return(map[string]interface{}{"result": "late"}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	invokeResp, err := server.InvokeTool(ctx, &agentpb.InvokeToolRequest{
+		SessionId: registerResp.SessionId,
+		ToolName:  "slow-tool",
+	})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED, invokeResp.Status)
+	if !assert.NotNil(t, invokeResp.Error) {
+		return
+	}
+	assert.Equal(t, agentpb.ErrorCode_ERROR_CODE_TIMEOUT, invokeResp.Error.Code)
+	assert.True(t, invokeResp.Error.Retryable)
+	assert.Contains(t, invokeResp.Error.Message, "slow-tool timed out")
+}
+
+// nestedResult is a struct tool result serialized through its JSON tags
+type nestedResult struct {
+	ID    int               `json:"id"`
+	Tags  []string          `json:"tags"`
+	Owner map[string]string `json:"owner"`
+	Items []nestedItem      `json:"items"`
+}
+
+type nestedItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+func TestAgentServer_InvokeTool_JSONRoundTrip(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	server := NewAgentServer(logger, mockRegistry)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockRegistry.On("Get", "unserializable-tool").Return(mockTool, nil)
+
+	// Nested objects and arrays reach the tool as decoded JSON values
+	expectedInput := map[string]interface{}{
+		"filter": map[string]interface{}{
+			"status": []interface{}{"open", "pending"},
+			"owner":  map[string]interface{}{"id": float64(7), "active": true},
+		},
+		"ids":  []interface{}{float64(1), float64(2), float64(3)},
+		"note": nil,
+	}
+	mockTool.On("Execute", expectedInput).Return(nestedResult{
+		ID:    42,
+		Tags:  []string{"a", "b"},
+		Owner: map[string]string{"name": "Ada \"Countess\" Lovelace"},
+		Items: []nestedItem{{Name: "widget", Price: 9.5}},
+	}, nil).Once()
+
+	resp, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId:      registerResp.SessionId,
+		ToolName:       "test-tool",
+		ParametersJson: `{"filter": {"status": ["open", "pending"], "owner": {"id": 7, "active": true}}, "ids": [1, 2, 3], "note": null}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS, resp.Status)
+	assert.JSONEq(t, `{"id": 42, "tags": ["a", "b"], "owner": {"name": "Ada \"Countess\" Lovelace"}, "items": [{"name": "widget", "price": 9.5}]}`, resp.ResultJson)
+
+	// A result that cannot be encoded fails the invocation with a structured error
+	mockTool.On("Execute", map[string]interface{}(nil)).Return(map[string]interface{}{"callback": func() {}}, nil).Once()
+	resp, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId: registerResp.SessionId,
+		ToolName:  "unserializable-tool",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED, resp.Status)
+	assert.Empty(t, resp.ResultJson)
+	assert.Equal(t, agentpb.ErrorCode_ERROR_CODE_INTERNAL_ERROR, resp.Error.Code)
+
+	// Malformed and non-object parameters are rejected with a field violation
+	for parametersJSON, description := range map[string]string{
+		`{"ids": [1, 2`: "malformed JSON",
+		`[1, 2, 3]`:     "parameters must be a JSON object, got array",
+		`"text"`:        "parameters must be a JSON object, got string",
+	} {
+		_, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+			SessionId:      registerResp.SessionId,
+			ToolName:       "test-tool",
+			ParametersJson: parametersJSON,
+		})
+		st := status.Convert(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code(), parametersJSON)
+		assert.Len(t, st.Details(), 1, parametersJSON)
+		badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+		if assert.True(t, ok, parametersJSON) {
+			assert.Equal(t, "parameters_json", badRequest.FieldViolations[0].Field)
+			assert.Contains(t, badRequest.FieldViolations[0].Description, description)
+		}
+	}
+
+	mockTool.AssertExpectations(t)
+}
+
+func TestAgentServer_InvokeTool_RetryHint(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	server := NewAgentServer(logger, mockRegistry)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	retryAfter, ok := types.ParseRetryAfter("30", time.Now())
+	assert.True(t, ok)
+	mockTool.On("Execute", mock.Anything).Return(nil, fmt.Errorf("call failed: %w", &types.RetryableError{
+		StatusCode: 429,
+		RetryAfter: retryAfter,
+		Reason:     "upstream returned 429 Too Many Requests",
+	})).Once()
+	mockTool.On("Execute", mock.Anything).Return(nil, &types.RetryableError{StatusCode: 503, Reason: "upstream returned 503 Service Unavailable"}).Once()
+	mockTool.On("Execute", mock.Anything).Return(nil, fmt.Errorf("boom")).Once()
+
+	invoke := func() *agentpb.InvokeToolResponse {
+		resp, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+			SessionId: registerResp.SessionId,
+			ToolName:  "test-tool",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED, resp.Status)
+		return resp
+	}
+
+	// Rate limited upstreams carry the Retry-After delay in the error metadata
+	resp := invoke()
+	assert.Equal(t, agentpb.ErrorCode_ERROR_CODE_RATE_LIMITED, resp.Error.Code)
+	assert.True(t, resp.Error.Retryable)
+	assert.JSONEq(t, `{"retry": {"retry_after_ms": 30000, "upstream_status": 429}}`, resp.Error.MetadataJson)
+	hint, ok := retryHint(resp.Error)
+	assert.True(t, ok)
+	assert.Equal(t, RetryHint{RetryAfterMs: 30000, UpstreamStatus: 429}, hint)
+
+	// Unavailable upstreams without a delay still mark the error as a backoff
+	resp = invoke()
+	assert.Equal(t, agentpb.ErrorCode_ERROR_CODE_EXECUTION_FAILED, resp.Error.Code)
+	assert.JSONEq(t, `{"retry": {"retry_after_ms": 0, "upstream_status": 503}}`, resp.Error.MetadataJson)
+
+	// Other failures have no hint
+	resp = invoke()
+	assert.Empty(t, resp.Error.MetadataJson)
+	_, ok = retryHint(resp.Error)
+	assert.False(t, ok)
+
+	mockTool.AssertExpectations(t)
+}
+
+func TestAgentServer_InvokeTool_RetryPolicy(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	recorder := &memoryRecorder{}
+
+	config := DefaultAgentServerConfig()
+	config.Executions = recorder
+	config.MaxRetries = 2
+	config.MaxRetryDelay = 10 * time.Millisecond // Keeps backoffs short
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+
+	invoke := func(policy *agentpb.ToolRetryPolicy) *agentpb.InvokeToolResponse {
+		resp, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+			SessionId: registerResp.SessionId,
+			ToolName:  "test-tool",
+			Options:   &agentpb.ToolInvocationOptions{RetryPolicy: policy},
+		})
+		assert.NoError(t, err)
+		return resp
+	}
+	unavailable := &types.RetryableError{StatusCode: 503, Reason: "upstream returned 503 Service Unavailable"}
+
+	// Transient failures are retried until an attempt succeeds, each attempt recorded
+	mockTool.On("Execute", mock.Anything).Return(nil, unavailable).Twice()
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil).Once()
+	resp := invoke(&agentpb.ToolRetryPolicy{MaxRetries: 3})
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS, resp.Status)
+	assert.Equal(t, int32(2), resp.Metrics.RetryCount)
+	assert.Len(t, recorder.executions, 3)
+	for i, execution := range recorder.executions {
+		assert.Equal(t, i+1, execution.Attempt)
+		assert.Equal(t, resp.InvocationId, execution.InvocationID)
+	}
+	assert.Error(t, recorder.executions[0].Err)
+	assert.NoError(t, recorder.executions[2].Err)
+
+	// The server maximum caps the requested retries
+	mockTool.On("Execute", mock.Anything).Return(nil, unavailable).Times(3)
+	resp = invoke(&agentpb.ToolRetryPolicy{MaxRetries: 10})
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED, resp.Status)
+	assert.Equal(t, int32(2), resp.Metrics.RetryCount)
+
+	// Only listed status codes are retried
+	mockTool.On("Execute", mock.Anything).Return(nil, unavailable).Once()
+	resp = invoke(&agentpb.ToolRetryPolicy{MaxRetries: 2, RetryableStatusCodes: []int32{429}})
+	assert.Equal(t, int32(0), resp.Metrics.RetryCount)
+
+	// Listed status codes also match upstream statuses reported in results
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"status_code": 502}, nil).Once()
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"status_code": 200}, nil).Once()
+	resp = invoke(&agentpb.ToolRetryPolicy{MaxRetries: 2, RetryableStatusCodes: []int32{502}})
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS, resp.Status)
+	assert.Equal(t, int32(1), resp.Metrics.RetryCount)
+	assert.JSONEq(t, `{"status_code": 200}`, resp.ResultJson)
+
+	// Upstreams asking for a longer wait than the server allows are not retried
+	mockTool.On("Execute", mock.Anything).Return(nil, &types.RetryableError{StatusCode: 429, RetryAfter: time.Minute, Reason: "rate limited"}).Once()
+	resp = invoke(&agentpb.ToolRetryPolicy{MaxRetries: 2})
+	assert.Equal(t, int32(0), resp.Metrics.RetryCount)
+
+	// Invocations without a policy are attempted once
+	mockTool.On("Execute", mock.Anything).Return(nil, unavailable).Once()
+	resp = invoke(nil)
+	assert.Equal(t, int32(0), resp.Metrics.RetryCount)
+
+	mockTool.AssertExpectations(t)
+}
+
 func TestAgentServer_InvokeTool_NotFound(t *testing.T) {
 	logger := zap.NewNop()
 	mockRegistry := &MockToolRegistry{}
@@ -346,6 +823,107 @@ func TestAgentServer_InvokeTool_NotFound(t *testing.T) {
 	mockRegistry.AssertExpectations(t)
 }
 
+func TestAgentServer_GetTool_SchemaRefs(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{
+		Name: "test-tool",
+		Schema: map[string]interface{}{
+			"input": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"node": map[string]interface{}{"$ref": "#/components/schemas/Node"}},
+			},
+			"components": map[string]interface{}{"schemas": map[string]interface{}{
+				"Node": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"next": map[string]interface{}{"$ref": "#/components/schemas/Node"}},
+				},
+			}},
+		},
+	})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	server := NewAgentServer(logger, mockRegistry)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+
+	getTool := func(mode string) (*agentpb.GetToolResponse, error) {
+		ctx := context.Background()
+		if mode != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(SchemaRefsMetadataKey, mode))
+		}
+		return server.GetTool(ctx, &agentpb.GetToolRequest{
+			SessionId:     registerResp.SessionId,
+			ToolName:      "test-tool",
+			IncludeSchema: true,
+		})
+	}
+
+	// Schemas are published as the tool defines them by default
+	resp, err := getTool("")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"node":{"$ref":"#/components/schemas/Node"}}}`, resp.InputSchemaJson)
+	assert.JSONEq(t, `{"type":"object","properties":{}}`, resp.OutputSchemaJson)
+
+	resp, err = getTool("inline")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"node":{"type":"object","properties":{
+		"next":{"$comment":"circular reference to #/components/schemas/Node omitted"}}}}}`, resp.InputSchemaJson)
+
+	resp, err = getTool("bundle")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"node":{"$ref":"#/$defs/Node"}},
+		"$defs":{"Node":{"type":"object","properties":{"next":{"$ref":"#/$defs/Node"}}}}}`, resp.InputSchemaJson)
+
+	_, err = getTool("deep")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAgentServer_GetTool_Examples(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{
+		Name: "test-tool",
+		Schema: map[string]interface{}{
+			"input": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id":    map[string]interface{}{"type": "integer", "example": 42},
+					"limit": map[string]interface{}{"type": "integer", "default": 10},
+				},
+				"required": []string{"id"},
+			},
+			"output": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string", "example": "Rex"}},
+			},
+		},
+	})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	server := NewAgentServer(logger, mockRegistry)
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "test-agent-1", AgentName: "Test Agent"})
+	require.NoError(t, err)
+
+	// Examples are built from the schemas, preferring declared examples and defaults
+	resp, err := server.GetTool(context.Background(), &agentpb.GetToolRequest{SessionId: registerResp.SessionId, ToolName: "test-tool", IncludeSchema: true})
+	require.NoError(t, err)
+	require.Len(t, resp.Examples, 1)
+	assert.JSONEq(t, `{"id": 42, "limit": 10}`, resp.Examples[0].InputJson)
+	assert.JSONEq(t, `{"name": "Rex"}`, resp.Examples[0].ExpectedOutputJson)
+
+	resp, err = server.GetTool(context.Background(), &agentpb.GetToolRequest{SessionId: registerResp.SessionId, ToolName: "test-tool"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Examples)
+	assert.Empty(t, resp.InputSchemaJson)
+}
+
 func TestAgentServer_HeartBeat(t *testing.T) {
 	logger := zap.NewNop()
 	mockRegistry := &MockToolRegistry{}
@@ -427,53 +1005,1753 @@ func TestAgentServer_GetAgentStatus(t *testing.T) {
 	mockRegistry.AssertExpectations(t)
 }
 
-// Benchmark tests
-func BenchmarkAgentServer_RegisterAgent(b *testing.B) {
+func TestAgentServer_RecentToolUsage(t *testing.T) {
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mockTool.On("Execute", mock.Anything).Return(nil, fmt.Errorf("boom")).Once()
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	failingTool := &MockTool{}
+	failingTool.On("Metadata").Return(types.ToolMetadata{Name: "failing-tool"})
+	failingTool.On("Execute", mock.Anything).Return(nil, fmt.Errorf("boom"))
+	mockRegistry.On("Get", "failing-tool").Return(failingTool, nil)
+	config := DefaultAgentServerConfig()
+	config.UsageHistory = sessionhistory.OpenMemory(2)
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	require.NoError(t, err)
+
+	invokeReq := &agentpb.InvokeToolRequest{SessionId: registerResp.SessionId, ToolName: "test-tool"}
+	for i := 0; i < 3; i++ {
+		_, err := server.InvokeTool(context.Background(), invokeReq)
+		require.NoError(t, err)
+	}
+
+	// The history is capped and newest first; the failed first invocation
+	// has been dropped
+	statusResp, err := server.GetAgentStatus(context.Background(), &agentpb.GetAgentStatusRequest{SessionId: registerResp.SessionId})
+	require.NoError(t, err)
+	require.Len(t, statusResp.RecentToolUsage, 2)
+	for _, usage := range statusResp.RecentToolUsage {
+		assert.Equal(t, "test-tool", usage.ToolName)
+		assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS, usage.Status)
+		assert.NotZero(t, usage.InvokedAtUnix)
+	}
+
+	// REST status reports the same history, and failures carry their error
+	registerResp, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "test-agent-2", AgentName: "Test Agent"})
+	require.NoError(t, err)
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: registerResp.SessionId, ToolName: "failing-tool"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	NewAgentAPI(zap.NewNop(), mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/agents/"+registerResp.SessionId+"/status", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var restStatus agentpb.GetAgentStatusResponse
+	require.NoError(t, protojson.Unmarshal(recorder.Body.Bytes(), &restStatus))
+	require.Len(t, restStatus.RecentToolUsage, 1)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_FAILED, restStatus.RecentToolUsage[0].Status)
+	assert.Contains(t, restStatus.RecentToolUsage[0].ErrorMessage, "boom")
+
+	// Unregistering forgets the history
+	_, err = server.UnregisterAgent(context.Background(), &agentpb.UnregisterAgentRequest{SessionId: registerResp.SessionId})
+	require.NoError(t, err)
+	history, err := config.UsageHistory.Recent(registerResp.SessionId)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestAgentServer_SessionLimits(t *testing.T) {
 	logger := zap.NewNop()
 	mockRegistry := &MockToolRegistry{}
-	server := NewAgentServer(logger, mockRegistry)
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	config := DefaultAgentServerConfig()
+	config.SessionLimits.RequestsPerMinute = 2
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
 
+	// Register an agent first
 	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		req := &agentpb.RegisterAgentRequest{
-			AgentId:   fmt.Sprintf("agent-%d", i),
-			AgentName: "Benchmark Agent",
-		}
-		_, _ = server.RegisterAgent(context.Background(), req)
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+
+	invokeReq := &agentpb.InvokeToolRequest{
+		SessionId:    registerResp.SessionId,
+		ToolName:     "test-tool",
+		InvocationId: "test-invocation-1",
 	}
+
+	// Two invocations fit in the window, the third is rejected
+	for i := 0; i < 2; i++ {
+		_, err := server.InvokeTool(context.Background(), invokeReq)
+		assert.NoError(t, err)
+	}
+	_, err = server.InvokeTool(context.Background(), invokeReq)
+	assert.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	limits, err := server.GetSessionLimits(registerResp.SessionId)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), limits.RequestsPerMinute)
+	assert.Equal(t, int32(0), limits.RequestsRemaining)
+	assert.Equal(t, int32(-1), limits.ConcurrencyHeadroom)
+	assert.Equal(t, int64(2), limits.InvocationsUsed)
+
+	// Heartbeat carries the same snapshot
+	heartbeatResp, err := server.HeartBeat(context.Background(), &agentpb.HeartBeatRequest{
+		SessionId: registerResp.SessionId,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, heartbeatResp.Limits)
+	assert.Equal(t, int32(0), heartbeatResp.Limits.RequestsRemaining)
+
+	_, err = server.GetSessionLimits("invalid-session-id")
+	assert.Error(t, err)
 }
 
-func BenchmarkAgentServer_ListTools(b *testing.B) {
-	logger := zap.NewNop()
+func TestAgentServer_LifetimeQuotas(t *testing.T) {
 	mockRegistry := &MockToolRegistry{}
-	server := NewAgentServer(logger, mockRegistry)
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	config := DefaultAgentServerConfig()
+	config.SessionLimits.MaxInvocations = 2
+	config.SessionLimits.BudgetMs = 50
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	first, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Agent 1"})
+	require.NoError(t, err)
+	second, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-2", AgentName: "Agent 2"})
+	require.NoError(t, err)
+	invoke := func(sessionID string) error {
+		_, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: sessionID, ToolName: "test-tool"})
+		return err
+	}
 
-	// Create many tools for benchmark
-	tools := make([]types.ToolMetadata, 1000)
-	for i := 0; i < 1000; i++ {
-		tools[i] = types.ToolMetadata{
-			Name:        fmt.Sprintf("tool-%d", i),
-			Description: fmt.Sprintf("Tool %d", i),
-			Version:     "1.0.0",
+	// The lifetime quota is charged by the same ledger as daily quotas
+	require.NoError(t, invoke(first.SessionId))
+	require.NoError(t, invoke(first.SessionId))
+	err = invoke(first.SessionId)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "lifetime invocations quota of session "+first.SessionId)
+	limits, err := server.GetSessionLimits(first.SessionId)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), limits.InvocationsUsed)
+	assert.Equal(t, int64(2), limits.MaxInvocations)
+
+	// Execution time counts against the budget once invocations finish
+	server.quotas.chargeDuration(second.SessionId, 50*time.Millisecond)
+	err = invoke(second.SessionId)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "budget ms quota of session "+second.SessionId)
+	limits, err = server.GetSessionLimits(second.SessionId)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), limits.BudgetUsedMs)
+	assert.Equal(t, int64(0), limits.InvocationsUsed)
+}
+
+func TestAgentServer_DailyQuotas(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	mockRegistry := &MockToolRegistry{}
+	cheapTool := &MockTool{}
+	cheapTool.On("Metadata").Return(types.ToolMetadata{Name: "cheap-tool"})
+	cheapTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	costlyTool := &MockTool{}
+	costlyTool.On("Metadata").Return(types.ToolMetadata{Name: "costly-tool"})
+	costlyTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockRegistry.On("Get", "cheap-tool").Return(cheapTool, nil)
+	mockRegistry.On("Get", "costly-tool").Return(costlyTool, nil)
+
+	config := DefaultAgentServerConfig()
+	config.Clock = fake
+	config.SessionQuota = DailyQuota{MaxInvocationsPerDay: 3}
+	config.IdentityQuota = func(identity Identity) (DailyQuota, bool) {
+		return DailyQuota{MaxCostPerDay: 10}, identity.Subject == "apikey:metered"
+	}
+	config.ToolCost = func(tool types.ToolMetadata) float64 {
+		if tool.Name == "costly-tool" {
+			return 8
 		}
+		return 1
 	}
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
 
-	mockRegistry.On("ListTools").Return(tools)
+	ctx := WithIdentity(context.Background(), Identity{Subject: "apikey:metered"})
+	first, err := server.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Agent 1", SessionTimeoutSeconds: 86400})
+	require.NoError(t, err)
+	second, err := server.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{AgentId: "agent-2", AgentName: "Agent 2", SessionTimeoutSeconds: 86400})
+	require.NoError(t, err)
 
-	// Register an agent
-	registerReq := &agentpb.RegisterAgentRequest{
-		AgentId:   "benchmark-agent",
-		AgentName: "Benchmark Agent",
+	invoke := func(sessionID, toolName string) error {
+		_, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: sessionID, ToolName: toolName})
+		return err
 	}
-	registerResp, _ := server.RegisterAgent(context.Background(), registerReq)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		req := &agentpb.ListToolsRequest{
-			SessionId: registerResp.SessionId,
-		}
-		_, _ = server.ListTools(context.Background(), req)
+	// The session quota caps invocations per session
+	for i := 0; i < 3; i++ {
+		require.NoError(t, invoke(first.SessionId, "cheap-tool"))
+	}
+	err = invoke(first.SessionId, "cheap-tool")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "daily invocations quota of session "+first.SessionId)
+
+	heartbeat, err := server.HeartBeat(context.Background(), &agentpb.HeartBeatRequest{SessionId: first.SessionId})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), heartbeat.Limits.MaxInvocationsPerDay)
+	assert.Equal(t, int64(0), heartbeat.Limits.InvocationsRemainingToday)
+	assert.Equal(t, 10.0, heartbeat.Limits.MaxCostPerDay)
+	assert.Equal(t, 7.0, heartbeat.Limits.CostRemainingToday)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).Unix(), heartbeat.Limits.QuotaResetsAtUnix)
+
+	// The principal's cost quota is shared by its sessions; rejected
+	// invocations are not charged
+	err = invoke(second.SessionId, "costly-tool")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "daily cost quota of apikey:metered")
+	require.NoError(t, invoke(second.SessionId, "cheap-tool"))
+
+	// Operators can lift the quota of a single session
+	require.NoError(t, server.SetSessionQuota(first.SessionId, &DailyQuota{}))
+	require.NoError(t, invoke(first.SessionId, "cheap-tool"))
+	assert.Error(t, server.SetSessionQuota(first.SessionId, &DailyQuota{MaxInvocationsPerDay: -1}))
+	assert.Equal(t, codes.NotFound, status.Code(server.SetSessionQuota("invalid-session-id", nil)))
+
+	// Quotas reset at UTC midnight
+	fake.Advance(15 * time.Hour)
+	require.NoError(t, invoke(second.SessionId, "costly-tool"))
+	limits, err := server.GetSessionLimits(second.SessionId)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), limits.InvocationsRemainingToday)
+	assert.Equal(t, 2.0, limits.CostRemainingToday)
+}
+
+func TestAgentServer_Clock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	config := DefaultAgentServerConfig()
+	config.SessionLimits.RequestsPerMinute = 1
+	config.Clock = fake
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:               "test-agent-1",
+		AgentName:             "Test Agent",
+		SessionTimeoutSeconds: 600,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fake.Now().Add(10*time.Minute).Unix(), registerResp.ExpiresAtUnix)
+
+	// Rate limit windows follow the clock
+	invokeReq := &agentpb.InvokeToolRequest{SessionId: registerResp.SessionId, ToolName: "test-tool"}
+	_, err = server.InvokeTool(context.Background(), invokeReq)
+	require.NoError(t, err)
+	_, err = server.InvokeTool(context.Background(), invokeReq)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	fake.Advance(time.Minute)
+	_, err = server.InvokeTool(context.Background(), invokeReq)
+	assert.NoError(t, err)
+
+	// Sessions expire on the clock's schedule, not the system's
+	fake.Advance(5 * time.Minute)
+	_, exists := server.getSession(registerResp.SessionId)
+	assert.True(t, exists)
+	fake.Advance(5 * time.Minute)
+	assert.Eventually(t, func() bool {
+		_, exists := server.getSession(registerResp.SessionId)
+		return !exists
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestAgentServer_ActiveToolSessions(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "busy-tool"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockRegistry.On("Get", "busy-tool").Return(mockTool, nil)
+	config := DefaultAgentServerConfig()
+	config.Clock = fake
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	var sessions []string
+	for _, agentID := range []string{"agent-1", "agent-2"} {
+		registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: agentID, AgentName: "Agent"})
+		require.NoError(t, err)
+		_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: registerResp.SessionId, ToolName: "busy-tool"})
+		require.NoError(t, err)
+		sessions = append(sessions, registerResp.SessionId)
+	}
+	start := fake.Now()
+	assert.Equal(t, map[string]int{"busy-tool": 2}, server.ActiveToolSessions(start))
+
+	// Usage before since no longer counts
+	fake.Advance(time.Minute)
+	assert.Empty(t, server.ActiveToolSessions(fake.Now()))
+
+	// Sessions that are gone no longer count
+	_, err := server.UnregisterAgent(context.Background(), &agentpb.UnregisterAgentRequest{SessionId: sessions[0]})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"busy-tool": 1}, server.ActiveToolSessions(start))
+}
+
+func TestAgentServer_ResumeSession(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	config := DefaultAgentServerConfig()
+	config.Clock = fake
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	registerReq := &agentpb.RegisterAgentRequest{AgentId: "test-agent-1", AgentName: "Test Agent", SessionTimeoutSeconds: 60}
+	registerResp, err := server.RegisterAgent(context.Background(), registerReq)
+	require.NoError(t, err)
+	require.NotEmpty(t, registerResp.ResumptionToken)
+	assert.False(t, registerResp.Resumed)
+	sessionID := registerResp.SessionId
+
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: sessionID, ToolName: "test-tool"})
+	require.NoError(t, err)
+	server.NotifySessions(SessionFilter{SessionIDs: []string{sessionID}}, "maintenance at noon")
+
+	expire := func() {
+		fake.Advance(2 * time.Minute)
+		require.Eventually(t, func() bool {
+			_, exists := server.getSession(sessionID)
+			return !exists
+		}, time.Second, 5*time.Millisecond)
+	}
+	expire()
+
+	// Tokens only resume the session of the agent they were issued to
+	_, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId: "test-agent-2", AgentName: "Test Agent", ResumptionToken: registerResp.ResumptionToken,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId: "test-agent-1", AgentName: "Test Agent", ResumptionToken: registerResp.ResumptionToken + "x",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Resuming within the grace window keeps the session's state
+	registerReq.ResumptionToken = registerResp.ResumptionToken
+	resumeResp, err := server.RegisterAgent(context.Background(), registerReq)
+	require.NoError(t, err)
+	assert.True(t, resumeResp.Resumed)
+	assert.Equal(t, sessionID, resumeResp.SessionId)
+	assert.Equal(t, fake.Now().Add(time.Minute).Unix(), resumeResp.ExpiresAtUnix)
+	assert.Equal(t, registerResp.ResumptionToken, resumeResp.ResumptionToken)
+
+	statusResp, err := server.GetAgentStatus(context.Background(), &agentpb.GetAgentStatusRequest{SessionId: sessionID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), statusResp.Metrics.TotalInvocations)
+	assert.Len(t, statusResp.RecentToolUsage, 1)
+	heartbeat, err := server.HeartBeat(context.Background(), &agentpb.HeartBeatRequest{SessionId: sessionID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"maintenance at noon"}, heartbeat.PendingNotifications)
+
+	// Active sessions are not resumable
+	_, err = server.RegisterAgent(context.Background(), registerReq)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Past the grace window the session and its history are gone
+	expire()
+	fake.Advance(DefaultResumptionGrace)
+	_, err = server.RegisterAgent(context.Background(), registerReq)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Eventually(t, func() bool {
+		history, err := server.config.UsageHistory.Recent(sessionID)
+		return err == nil && len(history) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// Without a grace window no tokens are issued
+	config.Resumption.Grace = 0
+	server = NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+	registerResp, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "test-agent-1", AgentName: "Test Agent"})
+	require.NoError(t, err)
+	assert.Empty(t, registerResp.ResumptionToken)
+}
+
+func TestAgentServer_ReadOnlyMode(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mode := readonly.NewMode()
+	config := DefaultAgentServerConfig()
+	config.ReadOnly = mode
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+		Metadata:  map[string]string{readonly.WorkspaceMetadataKey: "team-a"},
+	})
+	assert.NoError(t, err)
+
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+
+	invokeReq := &agentpb.InvokeToolRequest{
+		SessionId: registerResp.SessionId,
+		ToolName:  "test-tool",
+	}
+
+	// Another workspace being read-only does not affect this session
+	mode.Set("team-b", true, "migration")
+	_, err = server.InvokeTool(context.Background(), invokeReq)
+	assert.NoError(t, err)
+
+	mode.Set("team-a", true, "migration")
+	_, err = server.InvokeTool(context.Background(), invokeReq)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "team-a")
+
+	// Listing keeps working in read-only mode
+	_, err = server.ListTools(context.Background(), &agentpb.ListToolsRequest{SessionId: registerResp.SessionId})
+	assert.NoError(t, err)
+
+	mode.Set("team-a", false, "")
+	mode.Set("", true, "upgrade")
+	_, err = server.InvokeTool(context.Background(), invokeReq)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "upgrade")
+}
+
+// memorySink collects exported invocation records for testing
+type memorySink struct {
+	records []invocationlog.Record
+}
+
+func (m *memorySink) Write(record invocationlog.Record) error {
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *memorySink) Close() error {
+	return nil
+}
+
+func TestAgentServer_InvocationLog(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	sink := &memorySink{}
+	logConfig := invocationlog.DefaultConfig()
+	logConfig.Redaction["actor.name"] = invocationlog.RedactMask
+	exporter := invocationlog.NewExporterWithSink(sink, logConfig, logger)
+
+	config := DefaultAgentServerConfig()
+	config.InvocationLog = exporter
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool", Source: "openapi"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId:      registerResp.SessionId,
+		ToolName:       "test-tool",
+		InvocationId:   "test-invocation-1",
+		ParametersJson: `{"message": "hello"}`,
+	})
+	assert.NoError(t, err)
+
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId:      registerResp.SessionId,
+		ToolName:       "test-tool",
+		ParametersJson: `{invalid`,
+	})
+	assert.Error(t, err)
+
+	assert.NoError(t, exporter.Close())
+	assert.Len(t, sink.records, 2)
+
+	record := sink.records[0]
+	assert.Equal(t, invocationlog.SchemaVersion, record.SchemaVersion)
+	assert.Equal(t, "agent", record.Actor.Type)
+	assert.Equal(t, registerResp.SessionId, record.Actor.ID)
+	assert.Equal(t, "[REDACTED]", record.Actor.Name)
+	assert.Equal(t, "openapi", record.ToolSource)
+	assert.Equal(t, invocationlog.HashParams(map[string]interface{}{"message": "hello"}), record.ParamsHash)
+	assert.Equal(t, invocationlog.OutcomeSuccess, record.Outcome)
+
+	assert.Empty(t, sink.records[1].ParamsHash)
+	assert.Equal(t, invocationlog.OutcomeRejected, sink.records[1].Outcome)
+	assert.NotEmpty(t, sink.records[1].Error)
+}
+
+// memoryRecorder collects agent executions in memory for tests
+type memoryRecorder struct {
+	executions []AgentExecution
+}
+
+func (r *memoryRecorder) RecordAgentExecution(_ context.Context, execution AgentExecution) error {
+	r.executions = append(r.executions, execution)
+	return nil
+}
+
+func TestAgentServer_ExecutionRecorder(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	recorder := &memoryRecorder{}
+
+	config := DefaultAgentServerConfig()
+	config.Executions = recorder
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool", Source: "graphql"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId:      registerResp.SessionId,
+		ToolName:       "test-tool",
+		InvocationId:   "test-invocation-1",
+		ParametersJson: `{"message": "hello"}`,
+	})
+	assert.NoError(t, err)
+
+	// Rejected invocations never executed and are not recorded
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId:      registerResp.SessionId,
+		ToolName:       "test-tool",
+		ParametersJson: `{invalid`,
+	})
+	assert.Error(t, err)
+
+	assert.Len(t, recorder.executions, 1)
+	execution := recorder.executions[0]
+	assert.Equal(t, registerResp.SessionId, execution.SessionID)
+	assert.Equal(t, "test-agent-1", execution.AgentID)
+	assert.Equal(t, "Test Agent", execution.AgentName)
+	assert.Equal(t, "test-invocation-1", execution.InvocationID)
+	assert.Equal(t, "graphql", execution.SourceType)
+	assert.NoError(t, execution.Err)
+}
+
+func TestAgentServer_CaptureLevels(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	recorder := &memoryRecorder{}
+
+	config := DefaultAgentServerConfig()
+	config.Executions = recorder
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+
+	invoke := func(level string) *agentpb.RegisterAgentResponse {
+		req := &agentpb.RegisterAgentRequest{AgentId: "agent-" + level, AgentName: "Test Agent"}
+		if level != "" {
+			req.Metadata = map[string]string{CaptureLevelMetadataKey: level}
+		}
+		registerResp, err := server.RegisterAgent(context.Background(), req)
+		assert.NoError(t, err)
+
+		_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+			SessionId:      registerResp.SessionId,
+			ToolName:       "test-tool",
+			ParametersJson: `{"message": "hello"}`,
+		})
+		assert.NoError(t, err)
+		return registerResp
+	}
+
+	// The default level records the execution without its payloads
+	registerResp := invoke("")
+	assert.Equal(t, "metadata", registerResp.ServerInfo.Capabilities["capture_level"])
+	assert.Equal(t, "full", registerResp.ServerInfo.Capabilities["max_capture_level"])
+	assert.Len(t, recorder.executions, 1)
+	assert.Nil(t, recorder.executions[0].Input)
+	assert.Nil(t, recorder.executions[0].Output)
+
+	// Opted-in agents have their payloads captured
+	invoke("full")
+	assert.Len(t, recorder.executions, 2)
+	assert.Equal(t, map[string]interface{}{"message": "hello"}, recorder.executions[1].Input)
+	assert.NotNil(t, recorder.executions[1].Output)
+
+	// Opted-out agents are not recorded at all
+	invoke("none")
+	assert.Len(t, recorder.executions, 2)
+
+	_, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "agent-invalid",
+		AgentName: "Test Agent",
+		Metadata:  map[string]string{CaptureLevelMetadataKey: "everything"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The server maximum caps what agents may request
+	config.Telemetry.Max = CaptureMetadata
+	capped := NewAgentServerWithConfig(logger, mockRegistry, config)
+	cappedResp, err := capped.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "agent-capped",
+		AgentName: "Test Agent",
+		Metadata:  map[string]string{CaptureLevelMetadataKey: "full"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "metadata", cappedResp.ServerInfo.Capabilities["capture_level"])
+}
+
+func TestAgentServer_ProtocolNegotiation(t *testing.T) {
+	// Pretend a newer minor and major version have shipped
+	defer func(known []Protocol) { knownProtocols = known }(knownProtocols)
+	knownProtocols = []Protocol{{Major: 2, Minor: 0}, {Major: 1, Minor: 1}, {Major: 1, Minor: 0}}
+
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	config := DefaultAgentServerConfig()
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	register := func(server *AgentServer, protocols ...string) (*agentpb.RegisterAgentResponse, error) {
+		req := &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Test Agent"}
+		if protocols != nil {
+			req.Capabilities = &agentpb.AgentCapabilities{SupportedProtocols: protocols}
+		}
+		return server.RegisterAgent(context.Background(), req)
+	}
+	negotiated := func(server *AgentServer, protocols ...string) string {
+		resp, err := register(server, protocols...)
+		require.NoError(t, err)
+		session, exists := server.getSession(resp.SessionId)
+		require.True(t, exists)
+		assert.Equal(t, resp.ServerInfo.ProtocolVersion, session.Protocol.String())
+		return resp.ServerInfo.ProtocolVersion
+	}
+
+	// The newest common version wins; agents that declare none get the oldest
+	assert.Equal(t, "MCP/2.0", negotiated(server, "mcp/1.0", "MCP/2.0"))
+	assert.Equal(t, "MCP/1.1", negotiated(server, "mcp/1.1", "mcp/3.0", "bogus"))
+	assert.Equal(t, "MCP/1.0", negotiated(server))
+
+	// Newer minor versions are downgraded within their major version
+	assert.Equal(t, "MCP/1.1", negotiated(server, "mcp/1.4"))
+
+	// Agents speaking no supported version are refused with the versions on offer
+	_, err := register(server, "mcp/3.0", "mcp/0.9")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, err.Error(), "MCP/2.0,MCP/1.1,MCP/1.0")
+
+	// Raising the minimum retires old versions, including for silent agents
+	config.Protocols.Min = Protocol{Major: 1, Minor: 1}
+	strict := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+	_, err = register(strict, "mcp/1.0")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = register(strict)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	resp, err := register(strict, "mcp/1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "MCP/2.0,MCP/1.1", resp.ServerInfo.Capabilities["supported_protocols"])
+
+	// The compatibility matrix shows every implemented version and its gated features
+	protocolGates["streaming_results"] = Protocol{Major: 2, Minor: 0}
+	defer delete(protocolGates, "streaming_results")
+	matrix := config.Protocols.ProtocolMatrix()
+	require.Len(t, matrix, 3)
+	assert.Equal(t, ProtocolSupport{Version: "MCP/2.0", Status: ProtocolCurrent,
+		Features: []string{"async_execution", "event_streaming", "retries", "session_limits", "streaming_results", "telemetry_levels"}}, matrix[0])
+	assert.Equal(t, ProtocolSupported, matrix[1].Status)
+	assert.Equal(t, ProtocolUnsupported, matrix[2].Status)
+	assert.NotContains(t, matrix[2].Features, "streaming_results")
+
+	session, _ := strict.getSession(resp.SessionId)
+	assert.False(t, session.SupportsFeature("streaming_results"))
+	assert.True(t, session.SupportsFeature("retries"))
+	assert.True(t, session.SupportsFeature("ungated"))
+}
+
+func TestAgentServer_TapSession(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	server := NewAgentServer(logger, mockRegistry)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+
+	_, _, err = server.TapSession("missing-session")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	events, stop, err := server.TapSession(registerResp.SessionId)
+	assert.NoError(t, err)
+	defer stop()
+
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mockTool.On("Execute", mock.Anything).Return(map[string]interface{}{"access_token": "abc", "items": []interface{}{"a"}}, nil).Once()
+	mockTool.On("Execute", mock.Anything).Return(nil, fmt.Errorf("boom")).Once()
+
+	parameters := map[string]interface{}{"query": "hello", "auth": map[string]interface{}{"Password": "hunter2"}}
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId:      registerResp.SessionId,
+		ToolName:       "test-tool",
+		InvocationId:   "test-invocation-1",
+		ParametersJson: `{"query": "hello", "auth": {"Password": "hunter2"}}`,
+	})
+	assert.NoError(t, err)
+
+	// Payloads are streamed with secrets redacted, leaving the agent's values intact
+	started := <-events
+	assert.Equal(t, TapInvocationStarted, started.Type)
+	assert.Equal(t, "test-invocation-1", started.InvocationID)
+	assert.Equal(t, "test-tool", started.ToolName)
+	assert.Equal(t, map[string]interface{}{"query": "hello", "auth": map[string]interface{}{"Password": "[REDACTED]"}}, started.Parameters)
+	assert.Equal(t, "hunter2", parameters["auth"].(map[string]interface{})["Password"])
+
+	completed := <-events
+	assert.Equal(t, TapInvocationCompleted, completed.Type)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS.String(), completed.Status)
+	assert.Equal(t, map[string]interface{}{"access_token": "[REDACTED]", "items": []interface{}{"a"}}, completed.Result)
+	assert.Greater(t, completed.ID, started.ID)
+
+	// Failures and rejections are streamed with their errors
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId: registerResp.SessionId,
+		ToolName:  "test-tool",
+	})
+	assert.NoError(t, err)
+	<-events
+	failed := <-events
+	assert.Equal(t, "boom", failed.Error)
+
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+		SessionId:      registerResp.SessionId,
+		ToolName:       "test-tool",
+		ParametersJson: `{invalid`,
+	})
+	assert.Error(t, err)
+	rejected := <-events
+	assert.Equal(t, TapInvocationRejected, rejected.Type)
+	assert.NotEmpty(t, rejected.Error)
+
+	// Taps end with the session
+	_, err = server.UnregisterAgent(context.Background(), &agentpb.UnregisterAgentRequest{SessionId: registerResp.SessionId})
+	assert.NoError(t, err)
+	_, open := <-events
+	assert.False(t, open)
+}
+
+// Benchmark tests
+func BenchmarkAgentServer_RegisterAgent(b *testing.B) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	server := NewAgentServer(logger, mockRegistry)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := &agentpb.RegisterAgentRequest{
+			AgentId:   fmt.Sprintf("agent-%d", i),
+			AgentName: "Benchmark Agent",
+		}
+		_, _ = server.RegisterAgent(context.Background(), req)
+	}
+}
+
+func BenchmarkAgentServer_ListTools(b *testing.B) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	server := NewAgentServer(logger, mockRegistry)
+
+	// Create many tools for benchmark
+	tools := make([]types.ToolMetadata, 1000)
+	for i := 0; i < 1000; i++ {
+		tools[i] = types.ToolMetadata{
+			Name:        fmt.Sprintf("tool-%d", i),
+			Description: fmt.Sprintf("Tool %d", i),
+			Version:     "1.0.0",
+		}
+	}
+
+	mockRegistry.On("ListTools").Return(tools)
+
+	// Register an agent
+	registerReq := &agentpb.RegisterAgentRequest{
+		AgentId:   "benchmark-agent",
+		AgentName: "Benchmark Agent",
+	}
+	registerResp, _ := server.RegisterAgent(context.Background(), registerReq)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := &agentpb.ListToolsRequest{
+			SessionId: registerResp.SessionId,
+		}
+		_, _ = server.ListTools(context.Background(), req)
+	}
+}
+
+func TestAgentServer_AsyncInvocation(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	config := DefaultAgentServerConfig()
+	config.AsyncWorkers = 1
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	session, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-1",
+		AgentName: "Test Agent",
+	})
+	assert.NoError(t, err)
+	other, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent-2",
+		AgentName: "Other Agent",
+	})
+	assert.NoError(t, err)
+
+	// The tool blocks until released so the single worker stays busy
+	release := make(chan struct{})
+	mockRegistry.On("Get", "slow-tool").Return(mockTool, nil)
+	mockTool.On("Execute", mock.Anything).Run(func(mock.Arguments) { <-release }).Return(map[string]interface{}{"done": true}, nil)
+
+	invokeAsync := func(invocationID string) *agentpb.InvokeToolResponse {
+		resp, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+			SessionId:    session.SessionId,
+			ToolName:     "slow-tool",
+			InvocationId: invocationID,
+			Options:      &agentpb.ToolInvocationOptions{Async: true},
+		})
+		assert.NoError(t, err)
+		return resp
+	}
+
+	first := invokeAsync("async-1")
+	assert.Equal(t, "async-1", first.InvocationId)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_PENDING, first.Status)
+
+	assert.Eventually(t, func() bool {
+		job, err := server.GetInvocation(session.SessionId, "async-1")
+		return err == nil && job.Status == agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_RUNNING
+	}, time.Second, 10*time.Millisecond)
+
+	// A queued invocation can be cancelled before a worker picks it up
+	invokeAsync("async-2")
+	cancelled, err := server.CancelInvocation(session.SessionId, "async-2")
+	assert.NoError(t, err)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED, cancelled.Status)
+
+	// Invocations are private to their session
+	_, err = server.GetInvocation(other.SessionId, "async-1")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		job, err := server.GetInvocation(session.SessionId, "async-1")
+		return err == nil && job.Finished()
+	}, time.Second, 10*time.Millisecond)
+
+	job, err := server.GetInvocation(session.SessionId, "async-1")
+	assert.NoError(t, err)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS, job.Status)
+	assert.JSONEq(t, `{"done": true}`, job.Response.ResultJson)
+
+	_, err = server.CancelInvocation(session.SessionId, "async-1")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Both slots are released once the queue drains
+	assert.Eventually(t, func() bool {
+		limits, err := server.GetSessionLimits(session.SessionId)
+		return err == nil && limits.InFlight == 0
+	}, time.Second, 10*time.Millisecond)
+	mockTool.AssertNumberOfCalls(t, "Execute", 1)
+}
+
+// contextTool blocks until its invocation is cancelled
+type contextTool struct {
+	calls atomic.Int32
+}
+
+func (t *contextTool) Name() string        { return "slow-tool" }
+func (t *contextTool) Description() string { return "Blocks until cancelled" }
+func (t *contextTool) Metadata() types.ToolMetadata {
+	return types.ToolMetadata{Name: t.Name()}
+}
+
+func (t *contextTool) Execute(ctx context.Context, _ any) (any, error) {
+	t.calls.Add(1)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAgentServer_CloseStopsAsyncInvocations(t *testing.T) {
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	tool := &contextTool{}
+	config := DefaultAgentServerConfig()
+	config.AsyncWorkers = 1
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	session, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "test-agent",
+		AgentName: "Test Agent",
+	})
+	require.NoError(t, err)
+
+	mockRegistry.On("Get", "slow-tool").Return(tool, nil)
+	invokeAsync := func(invocationID string) error {
+		_, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{
+			SessionId:    session.SessionId,
+			ToolName:     "slow-tool",
+			InvocationId: invocationID,
+			Options:      &agentpb.ToolInvocationOptions{Async: true},
+		})
+		return err
+	}
+	require.NoError(t, invokeAsync("running"))
+	assert.Eventually(t, func() bool {
+		job, err := server.GetInvocation(session.SessionId, "running")
+		return err == nil && job.Status == agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_RUNNING
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, invokeAsync("queued"))
+
+	closed := make(chan struct{})
+	go func() {
+		server.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not wait for the workers to stop")
+	}
+
+	running, err := server.GetInvocation(session.SessionId, "running")
+	require.NoError(t, err)
+	assert.True(t, running.Finished())
+	queued, err := server.GetInvocation(session.SessionId, "queued")
+	require.NoError(t, err)
+	assert.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_CANCELLED, queued.Status)
+	assert.Equal(t, int32(1), tool.calls.Load(), "queued invocations do not run after Close")
+
+	assert.Equal(t, codes.Unavailable, status.Code(invokeAsync("late")))
+	server.Close()
+}
+
+func TestGateway(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{{Name: "test-tool", Description: "Test"}})
+	server := NewAgentServer(logger, mockRegistry)
+
+	router := gin.New()
+	gateway := NewGateway(logger, server)
+	gateway.RegisterRoutes(router.Group("/api/v1"))
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	// Every RPC of the service is routed
+	routes := make(map[string]bool)
+	for _, route := range router.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	for _, method := range agentpb.AgentService_ServiceDesc.Methods {
+		assert.True(t, routes["POST /api/v1/rpc/aionmcp.agent.v1.AgentService/"+method.MethodName], method.MethodName)
+	}
+	assert.Len(t, gateway.Methods(), len(agentpb.AgentService_ServiceDesc.Methods)+1)
+
+	call := func(method, body string, header http.Header) (*http.Response, map[string]any) {
+		request, err := http.NewRequest(http.MethodPost, httpServer.URL+"/api/v1/rpc/aionmcp.agent.v1.AgentService/"+method, strings.NewReader(body))
+		require.NoError(t, err)
+		for key, values := range header {
+			request.Header[key] = values
+		}
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		var decoded map[string]any
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&decoded))
+		return response, decoded
+	}
+
+	// Responses use the proto field names and include zero values; response
+	// metadata comes back as headers
+	response, registered := call("RegisterAgent", `{"agent_id": "agent-1", "agent_name": "Agent", "metadata": {"telemetry.capture_level": "metadata"}}`, nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	sessionID, _ := registered["session_id"].(string)
+	require.NotEmpty(t, sessionID)
+	assert.Contains(t, registered, "available_tools")
+
+	_, tools := call("ListTools", fmt.Sprintf(`{"session_id": %q}`, sessionID), nil)
+	assert.Equal(t, float64(1), tools["total_count"])
+
+	response, statusReply := call("GetAgentStatus", fmt.Sprintf(`{"session_id": %q}`, sessionID), nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "metadata", response.Header.Get("Grpc-Metadata-telemetry.capture_level"))
+	assert.Equal(t, sessionID, statusReply["session_info"].(map[string]any)["session_id"])
+
+	// Errors carry the RPC status
+	response, failure := call("ListTools", `{"session_id": "unknown"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, float64(codes.Unauthenticated), failure["code"])
+	response, failure = call("RegisterAgent", `{"agent_id": 7}`, nil)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Contains(t, failure["message"], "invalid request body")
+
+	// Forwarded headers reach the RPC as metadata
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "test-tool"})
+	mockRegistry.On("Get", "test-tool").Return(mockTool, nil)
+	response, _ = call("GetTool", fmt.Sprintf(`{"session_id": %q, "tool_name": "test-tool", "include_schema": true}`, sessionID),
+		http.Header{"X-Aionmcp-Schema-Refs": {"sideways"}})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// Server streams answer with one JSON object per line
+	ctx, cancel := context.WithCancel(context.Background())
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, httpServer.URL+"/api/v1/rpc/aionmcp.agent.v1.AgentService/StreamEvents",
+		strings.NewReader(fmt.Sprintf(`{"session_id": %q}`, sessionID)))
+	require.NoError(t, err)
+	streamResponse, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-ndjson", streamResponse.Header.Get("Content-Type"))
+	line, err := bufio.NewReader(streamResponse.Body).ReadBytes('\n')
+	require.NoError(t, err)
+	var event map[string]map[string]any
+	require.NoError(t, json.Unmarshal(line, &event))
+	assert.Equal(t, "EVENT_TYPE_SERVER_STATUS", event["result"]["type"])
+	cancel()
+	streamResponse.Body.Close()
+}
+
+func TestGateway_Interceptors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	server := NewAgentServer(logger, mockRegistry)
+
+	// Interceptors run in order around REST and RPC routes alike
+	var called []string
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			called = append(called, name+" "+info.FullMethod)
+			if info.FullMethod == "/aionmcp.agent.v1.AgentService/InvokeTool" {
+				return nil, status.Error(codes.PermissionDenied, "denied by interceptor")
+			}
+			return handler(ctx, req)
+		}
+	}
+	rejectStreams := func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		called = append(called, "stream "+info.FullMethod)
+		return status.Error(codes.Unauthenticated, "no streams")
+	}
+	api := NewAgentAPI(logger, mockRegistry, server)
+	api.Gateway().SetInterceptors([]grpc.UnaryServerInterceptor{record("first"), record("second")}, []grpc.StreamServerInterceptor{rejectStreams})
+	router := gin.New()
+	api.RegisterRoutes(router.Group("/api/v1"))
+	api.Gateway().RegisterRoutes(router.Group("/api/v1"))
+	post := func(path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := post("/api/v1/agents/register", `{"agent_id": "agent-1", "agent_name": "Agent"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	var registered agentpb.RegisterAgentResponse
+	require.NoError(t, protojson.Unmarshal(recorder.Body.Bytes(), &registered))
+	assert.Equal(t, []string{
+		"first /aionmcp.agent.v1.AgentService/RegisterAgent",
+		"second /aionmcp.agent.v1.AgentService/RegisterAgent",
+	}, called)
+
+	// Path parameters fill the request fields named after them
+	recorder = post("/api/v1/agents/"+registered.SessionId+"/tools/test-tool/invoke", `{"parameters_json": "{}"}`)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "denied by interceptor")
+	recorder = post("/api/v1/rpc/aionmcp.agent.v1.AgentService/InvokeTool", fmt.Sprintf(`{"session_id": %q, "tool_name": "test-tool"}`, registered.SessionId))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = post("/api/v1/rpc/aionmcp.agent.v1.AgentService/StreamEvents", fmt.Sprintf(`{"session_id": %q}`, registered.SessionId))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, called, "stream /aionmcp.agent.v1.AgentService/StreamEvents")
+}
+
+func TestAgentAPI_BulkSessionAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	fake := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	config := DefaultAgentServerConfig()
+	config.Clock = fake
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	router := gin.New()
+	NewAgentAPI(logger, mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+	call := func(method, path, body string) (int, BulkSessionsResponse) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var response BulkSessionsResponse
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		}
+		return recorder.Code, response
+	}
+	register := func(id, version string) string {
+		response, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+			AgentId: id, AgentName: id, AgentVersion: version, SessionTimeoutSeconds: 3600,
+		})
+		require.NoError(t, err)
+		return response.SessionId
+	}
+
+	buggy := register("crawler", "1.2.0")
+	idle := register("planner", "2.0.0")
+	fake.Advance(10 * time.Minute)
+	active := register("planner", "2.0.0")
+
+	// Notices reach only the selected sessions, on their next heartbeat
+	code, notified := call(http.MethodPost, "/api/v1/agents/admin/sessions/notify", `{"agent_id": "planner", "message": "maintenance at 18:00"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.ElementsMatch(t, []string{idle, active}, notified.SessionIDs)
+	heartbeat, err := server.HeartBeat(context.Background(), &agentpb.HeartBeatRequest{SessionId: active})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"maintenance at 18:00"}, heartbeat.PendingNotifications)
+	heartbeat, err = server.HeartBeat(context.Background(), &agentpb.HeartBeatRequest{SessionId: active})
+	require.NoError(t, err)
+	assert.Empty(t, heartbeat.PendingNotifications)
+
+	// Evictions select by version and idle time; empty filters must say all
+	code, _ = call(http.MethodPost, "/api/v1/agents/admin/sessions/evict", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, evicted := call(http.MethodPost, "/api/v1/agents/admin/sessions/evict", `{"agent_version": "1.2.0", "reason": "known bug"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{buggy}, evicted.SessionIDs)
+	code, evicted = call(http.MethodPost, "/api/v1/agents/admin/sessions/evict", `{"idle_seconds": 300}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{idle}, evicted.SessionIDs)
+	_, exists := server.getSession(idle)
+	assert.False(t, exists)
+	_, exists = server.getSession(active)
+	assert.True(t, exists)
+
+	// Draining refuses new registrations but keeps existing sessions
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/v1/agents/admin/drain", strings.NewReader(`{"enabled": true, "reason": "upgrade"}`)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var drain DrainStatus
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &drain))
+	assert.True(t, drain.Draining)
+	assert.Equal(t, 1, drain.Sessions)
+	require.NotNil(t, drain.Since)
+	assert.True(t, fake.Now().Equal(*drain.Since))
+
+	_, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "late", AgentName: "late"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "upgrade")
+	_, err = server.HeartBeat(context.Background(), &agentpb.HeartBeatRequest{SessionId: active})
+	assert.NoError(t, err)
+
+	server.SetDraining(false, "")
+	assert.False(t, server.Drain().Draining)
+	register("late", "1.0.0")
+}
+
+func TestAgentAPI_TerminateSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	server := NewAgentServer(logger, mockRegistry)
+	router := gin.New()
+	NewAgentAPI(logger, mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+
+	registered, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "runaway", AgentName: "Runaway"})
+	require.NoError(t, err)
+	recorder := &recordingEventStream{ctx: context.Background(), events: make(chan *agentpb.Event, 16)}
+	returned := make(chan error, 1)
+	go func() {
+		returned <- server.StreamEvents(&agentpb.StreamEventsRequest{SessionId: registered.SessionId}, recorder)
+	}()
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_SERVER_STATUS, (<-recorder.events).Type)
+
+	// The agent is told why in a final event before its stream closes
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/api/v1/agents/admin/sessions/"+registered.SessionId+"?reason=runaway+loop", nil))
+	require.Equal(t, http.StatusOK, response.Code)
+	final := <-recorder.events
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_SERVER_STATUS, final.Type)
+	assert.JSONEq(t, `{"status": "terminated", "message": "runaway loop"}`, final.DataJson)
+	select {
+	case err := <-returned:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("event stream kept running after its session was terminated")
+	}
+	_, exists := server.getSession(registered.SessionId)
+	assert.False(t, exists)
+
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/api/v1/agents/admin/sessions/"+registered.SessionId, nil))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	_, err = server.TerminateSession(context.Background(), &agentpb.TerminateSessionRequest{SessionId: "invalid-session-id"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAgentAPI_PollEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	config := DefaultAgentServerConfig()
+	config.EventLogSize = 3
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+
+	router := gin.New()
+	NewAgentAPI(logger, mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+	register := func(id string) string {
+		response, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: id, AgentName: id})
+		require.NoError(t, err)
+		return response.SessionId
+	}
+	get := func(path string) (int, GetEventsResponse) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var response GetEventsResponse
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		}
+		return recorder.Code, response
+	}
+	session := register("agent-1")
+	poll := "/api/v1/agents/" + session + "/events/poll"
+
+	// A poll without a cursor starts after the newest event
+	code, response := get(poll + "?wait=10ms")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response.Events)
+	assert.Equal(t, "1", response.Cursor)
+
+	// A waiting poll returns as soon as an event is broadcast
+	done := make(chan GetEventsResponse)
+	start := time.Now()
+	go func() {
+		_, response := get(poll + "?cursor=1&wait=5s")
+		done <- response
+	}()
+	time.Sleep(20 * time.Millisecond)
+	register("agent-2")
+	select {
+	case response = <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("poll did not return after an event")
+	}
+	assert.Less(t, time.Since(start), 3*time.Second)
+	require.Len(t, response.Events, 1)
+	assert.Equal(t, "EVENT_TYPE_AGENT_REGISTERED", response.Events[0].Type)
+	assert.Equal(t, "2", response.Cursor)
+	assert.False(t, response.Missed)
+
+	// Plain numbers are seconds; the wait elapses without events
+	code, response = get(poll + "?cursor=2&wait=0")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response.Events)
+	assert.Equal(t, "2", response.Cursor)
+
+	// Cursors older than the retained events report the gap
+	for _, id := range []string{"agent-3", "agent-4", "agent-5"} {
+		register(id)
+	}
+	_, response = get(poll + "?cursor=1")
+	assert.True(t, response.Missed)
+	assert.Len(t, response.Events, 3)
+	assert.Equal(t, "5", response.Cursor)
+	_, response = get("/api/v1/agents/" + session + "/events")
+	assert.Len(t, response.Events, 3)
+
+	code, _ = get(poll + "?cursor=abc")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(poll + "?wait=soon")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("/api/v1/agents/unknown/events/poll?wait=0")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestAgentServer_EventReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	path := filepath.Join(t.TempDir(), "events.db")
+	store, err := eventstore.Open(path, 3)
+	require.NoError(t, err)
+	config := DefaultAgentServerConfig()
+	config.EventLogSize = 3
+	config.EventStore = store
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+	register := func(id string) string {
+		response, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: id, AgentName: id})
+		require.NoError(t, err)
+		return response.SessionId
+	}
+	for _, id := range []string{"agent-1", "agent-2", "agent-3", "agent-4"} {
+		register(id)
+	}
+
+	// After a restart the log, and its sequences, pick up where they were
+	require.NoError(t, store.Close())
+	store, err = eventstore.Open(path, 3)
+	require.NoError(t, err)
+	defer store.Close()
+	config.EventStore = store
+	server = NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+	session := register("agent-5")
+
+	router := gin.New()
+	NewAgentAPI(zap.NewNop(), mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/agents/"+session+"/events?since=3", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var response GetEventsResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Events, 2)
+	assert.Equal(t, uint64(4), response.Events[0].Sequence)
+	assert.Equal(t, uint64(5), response.Events[1].Sequence)
+	assert.Equal(t, "5", response.Cursor)
+
+	// Streams replay the events after since_sequence, then go live
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &recordingEventStream{ctx: ctx, events: make(chan *agentpb.Event, 16)}
+	go server.StreamEvents(&agentpb.StreamEventsRequest{SessionId: session, SinceSequence: 4}, stream)
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_SERVER_STATUS, (<-stream.events).Type)
+	assert.Equal(t, uint64(5), (<-stream.events).Sequence)
+	register("agent-6")
+	live := <-stream.events
+	assert.Equal(t, uint64(6), live.Sequence)
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_AGENT_REGISTERED, live.Type)
+
+	// Clients whose sequence was evicted are told before the replay
+	stream = &recordingEventStream{ctx: ctx, events: make(chan *agentpb.Event, 16)}
+	go server.StreamEvents(&agentpb.StreamEventsRequest{SessionId: session, SinceSequence: 1}, stream)
+	<-stream.events
+	missed := <-stream.events
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_SERVER_STATUS, missed.Type)
+	assert.Contains(t, missed.DataJson, `"events_missed"`)
+	for _, sequence := range []uint64{4, 5, 6} {
+		assert.Equal(t, sequence, (<-stream.events).Sequence)
+	}
+}
+
+func TestAgentServer_EventStreamFilters(t *testing.T) {
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	for _, name := range []string{"billing.get", "users.list"} {
+		tool := &MockTool{}
+		tool.On("Metadata").Return(types.ToolMetadata{Name: name})
+		tool.On("Execute", mock.Anything).Return(map[string]interface{}{"result": "success"}, nil)
+		mockRegistry.On("Get", name).Return(tool, nil)
+	}
+	server := NewAgentServer(zap.NewNop(), mockRegistry)
+	registered, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Agent"})
+	require.NoError(t, err)
+	session := registered.SessionId
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	open := func(req *agentpb.StreamEventsRequest) *recordingEventStream {
+		req.SessionId = session
+		stream := &recordingEventStream{ctx: ctx, events: make(chan *agentpb.Event, 16)}
+		go server.StreamEvents(req, stream)
+		return stream
+	}
+	received := func(stream *recordingEventStream) []*agentpb.Event {
+		var events []*agentpb.Event
+		for {
+			select {
+			case event := <-stream.events:
+				events = append(events, event)
+			case <-time.After(50 * time.Millisecond):
+				return events
+			}
+		}
+	}
+
+	filtered := open(&agentpb.StreamEventsRequest{
+		EventTypes: []agentpb.EventType{agentpb.EventType_EVENT_TYPE_TOOL_INVOCATION},
+		ToolNames:  []string{"billing.*"},
+	})
+	unfiltered := open(&agentpb.StreamEventsRequest{})
+	<-filtered.events
+	<-unfiltered.events
+
+	for _, tool := range []string{"users.list", "billing.get"} {
+		_, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: session, ToolName: tool})
+		require.NoError(t, err)
+	}
+	_, err = server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-2", AgentName: "Agent"})
+	require.NoError(t, err)
+
+	events := received(filtered)
+	require.Len(t, events, 1)
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_TOOL_INVOCATION, events[0].Type)
+	assert.Contains(t, events[0].DataJson, `"billing.get"`)
+	assert.Len(t, received(unfiltered), 3)
+
+	// Replays are filtered too; events about no tool pass tool name filters
+	history := open(&agentpb.StreamEventsRequest{IncludeHistory: true, ToolNames: []string{"users.*"}})
+	events = received(history)[1:]
+	require.Len(t, events, 3)
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_AGENT_REGISTERED, events[0].Type)
+	assert.Contains(t, events[1].DataJson, `"users.list"`)
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_AGENT_REGISTERED, events[2].Type)
+
+	err = server.StreamEvents(&agentpb.StreamEventsRequest{SessionId: session, ToolNames: []string{"[billing"}},
+		&recordingEventStream{ctx: ctx, events: make(chan *agentpb.Event, 16)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// staticRecommender recommends the same tools after any tool
+type staticRecommender struct {
+	recommendations []ToolRecommendation
+	afterTools      []string
+}
+
+func (r *staticRecommender) RecommendTools(_ context.Context, afterTool string, _ int) ([]ToolRecommendation, error) {
+	r.afterTools = append(r.afterTools, afterTool)
+	return r.recommendations, nil
+}
+
+func TestAgentAPI_Recommendations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	recommender := &staticRecommender{recommendations: []ToolRecommendation{
+		{Tool: "search", Reason: "follows", Score: 0.75, Count: 3},
+		{Tool: "removed", Reason: "follows", Score: 0.25, Count: 1},
+		{Tool: "summarize", Reason: "popular", Score: 0.5, Count: 10},
+		{Tool: "translate", Reason: "popular", Score: 0.1, Count: 2},
+	}}
+	config := DefaultAgentServerConfig()
+	config.Recommender = recommender
+	server := NewAgentServerWithConfig(logger, mockRegistry, config)
+	for _, name := range []string{"fetch", "search", "summarize", "translate"} {
+		tool := &MockTool{}
+		tool.On("Metadata").Return(types.ToolMetadata{Name: name})
+		tool.On("Execute", mock.Anything).Return(map[string]interface{}{}, nil)
+		mockRegistry.On("Get", name).Return(tool, nil)
+	}
+	mockRegistry.On("Get", "removed").Return((*MockTool)(nil), assert.AnError)
+
+	router := gin.New()
+	NewAgentAPI(logger, mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+	registerResp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "test-agent-1", AgentName: "Test Agent"})
+	require.NoError(t, err)
+	get := func(path string) (int, ToolRecommendations) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var response ToolRecommendations
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		}
+		return recorder.Code, response
+	}
+	recommendations := "/api/v1/agents/" + registerResp.SessionId + "/tools/recommendations"
+
+	// Recommendations follow the session's last invocation and skip tools
+	// that are no longer registered
+	_, err = server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: registerResp.SessionId, ToolName: "fetch"})
+	require.NoError(t, err)
+	code, response := get(recommendations + "?limit=2")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "fetch", response.AfterTool)
+	assert.Equal(t, recommender.recommendations[0], response.Recommendations[0])
+	assert.Equal(t, []string{"search", "summarize"}, []string{response.Recommendations[0].Tool, response.Recommendations[1].Tool})
+	assert.Len(t, response.Recommendations, 2)
+
+	// An explicit tool overrides the last invocation
+	code, response = get(recommendations + "?after=search")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "search", response.AfterTool)
+	assert.Len(t, response.Recommendations, 3)
+	assert.Equal(t, []string{"fetch", "search"}, recommender.afterTools)
+
+	code, _ = get(recommendations + "?limit=none")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("/api/v1/agents/unknown/tools/recommendations")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// Without a recommender the endpoint is unavailable
+	disabled := NewAgentServer(logger, mockRegistry)
+	disabledResp, err := disabled.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "test-agent-2", AgentName: "Test Agent"})
+	require.NoError(t, err)
+	_, err = disabled.RecommendTools(context.Background(), disabledResp.SessionId, "", 0)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestAgentServer_Capabilities(t *testing.T) {
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	config := DefaultAgentServerConfig()
+	config.SessionLimits.RequestsPerMinute = 30
+	config.MaxRetries = 0
+	config.Features = map[string]capabilities.Feature{
+		"tls": {Enabled: true, Options: map[string]string{"client_auth": "RequireAndVerifyClientCert"}},
+	}
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+
+	matrix := server.Capabilities()
+	assert.Equal(t, ServerVersion, matrix.ServerVersion)
+	assert.Equal(t, int64(30), matrix.Features["tool_execution"].Limits["requests_per_minute"])
+	assert.Equal(t, int64(DefaultAsyncQueueSize), matrix.Features["async_execution"].Limits["queue_size"])
+	assert.False(t, matrix.Features["retries"].Enabled)
+	assert.True(t, matrix.Features["tls"].Enabled)
+
+	// Registration embeds the same matrix
+	resp, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{AgentId: "agent-1", AgentName: "Agent"})
+	require.NoError(t, err)
+	assert.Equal(t, matrix.Enabled(), resp.ServerInfo.SupportedFeatures)
+	assert.Contains(t, resp.ServerInfo.SupportedFeatures, "session_management")
+	assert.NotContains(t, resp.ServerInfo.SupportedFeatures, "retries")
+	assert.Equal(t, "30", resp.ServerInfo.Capabilities["tool_execution.limits.requests_per_minute"])
+	assert.Equal(t, "RequireAndVerifyClientCert", resp.ServerInfo.Capabilities["tls.options.client_auth"])
+	assert.Equal(t, "v1", resp.ServerInfo.Capabilities["session_management.version"])
+	assert.Equal(t, string(CaptureMetadata), resp.ServerInfo.Capabilities["capture_level"])
+}
+
+// recordingEventStream is a StreamEvents server stream that collects the
+// events sent to it
+type recordingEventStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *agentpb.Event
+}
+
+func (s *recordingEventStream) Context() context.Context { return s.ctx }
+
+func (s *recordingEventStream) Send(event *agentpb.Event) error {
+	s.events <- event
+	return nil
+}
+
+func TestAgentServer_EventStreamHealthCheck(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	config := DefaultAgentServerConfig()
+	config.Clock = fake
+	config.StreamCheckInterval = time.Hour
+	config.StreamStallTimeout = time.Minute
+	server := NewAgentServerWithConfig(zap.NewNop(), mockRegistry, config)
+	register := func(id string) string {
+		response, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+			AgentId: id, AgentName: id, SessionTimeoutSeconds: 3600,
+		})
+		require.NoError(t, err)
+		return response.SessionId
+	}
+	reading, stalled, gone := register("reading"), register("stalled"), register("gone")
+
+	// A client reading its stream receives events
+	recorder := &recordingEventStream{ctx: context.Background(), events: make(chan *agentpb.Event, 256)}
+	returned := make(chan error, 1)
+	go func() {
+		returned <- server.StreamEvents(&agentpb.StreamEventsRequest{SessionId: reading}, recorder)
+	}()
+	assert.Equal(t, agentpb.EventType_EVENT_TYPE_SERVER_STATUS, (<-recorder.events).Type)
+	server.sendToSession(reading, &agentpb.Event{EventId: "e-1"})
+	assert.Equal(t, "e-1", (<-recorder.events).EventId)
+
+	// A client that stopped reading fills its buffer and drops events
+	server.openEventStream(context.Background(), stalled, eventFilter{})
+	for i := 0; i <= eventStreamBuffer; i++ {
+		server.sendToSession(stalled, &agentpb.Event{EventId: fmt.Sprintf("s-%d", i)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	server.openEventStream(ctx, gone, eventFilter{})
+	cancel()
+
+	stats := make(map[string]EventStreamStats)
+	for _, entry := range server.EventStreamStats() {
+		stats[entry.SessionID] = entry
+	}
+	assert.Equal(t, EventStreamStats{SessionID: stalled, Streams: 1, Buffered: eventStreamBuffer, Dropped: 1}, stats[stalled])
+	assert.Equal(t, 1, stats[reading].Streams)
+	assert.Equal(t, 3, server.RuntimeState().EventStreams)
+
+	// Streams whose client went away are closed at once, stalled ones after the timeout
+	assert.Equal(t, 1, server.reapEventStreams(fake.Now()))
+	fake.Advance(2 * time.Minute)
+	assert.Equal(t, 1, server.reapEventStreams(fake.Now()))
+	assert.Equal(t, int64(2), server.EventStreamsReaped())
+	assert.Equal(t, 1, server.RuntimeState().EventStreams)
+
+	// Ending the session ends its stream instead of leaving the call running
+	_, err := server.UnregisterAgent(context.Background(), &agentpb.UnregisterAgentRequest{SessionId: reading})
+	require.NoError(t, err)
+	select {
+	case err := <-returned:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("event stream kept running after its session ended")
+	}
+	assert.Equal(t, 0, server.RuntimeState().EventStreams)
+	for _, entry := range server.EventStreamStats() {
+		assert.NotEqual(t, reading, entry.SessionID)
+	}
+}
+
+type binaryResult struct {
+	Name string `json:"name"`
+	Blob []byte `json:"blob"`
+}
+
+func TestAgentServer_ResultFormats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	mockRegistry := &MockToolRegistry{}
+	mockRegistry.On("ListTools").Return([]types.ToolMetadata{})
+	mockTool := &MockTool{}
+	mockTool.On("Metadata").Return(types.ToolMetadata{Name: "thumbnail"})
+	mockTool.On("Execute", mock.Anything).Return(binaryResult{Name: "cat.png", Blob: []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}}, nil)
+	mockRegistry.On("Get", "thumbnail").Return(mockTool, nil)
+	server := NewAgentServer(logger, mockRegistry)
+
+	register := func(preferred ...string) (string, string) {
+		response, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+			AgentId:      "agent-1",
+			AgentName:    "Agent",
+			Capabilities: &agentpb.AgentCapabilities{PreferredFormats: preferred},
+		})
+		require.NoError(t, err)
+		return response.SessionId, response.ServerInfo.Capabilities["result_format"]
+	}
+	invoke := func(sessionID string) *agentpb.InvokeToolResponse {
+		response, err := server.InvokeTool(context.Background(), &agentpb.InvokeToolRequest{SessionId: sessionID, ToolName: "thumbnail"})
+		require.NoError(t, err)
+		require.Equal(t, agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS, response.Status)
+		return response
+	}
+
+	// The first preferred format the server offers is used; unknown ones are skipped
+	msgpackSession, negotiated := register("yaml", "msgpack", "json")
+	assert.Equal(t, FormatMessagePack, negotiated)
+	response := invoke(msgpackSession)
+	assert.Equal(t, FormatMessagePack, response.ResultFormat)
+	assert.Empty(t, response.ResultJson)
+	var decoded binaryResult
+	require.NoError(t, MessagePackFormat().Unmarshal(response.ResultData, &decoded))
+	assert.Equal(t, binaryResult{Name: "cat.png", Blob: []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}}, decoded)
+
+	cborSession, negotiated := register("CBOR")
+	assert.Equal(t, FormatCBOR, negotiated)
+	response = invoke(cborSession)
+	assert.Equal(t, FormatCBOR, response.ResultFormat)
+	decoded = binaryResult{}
+	require.NoError(t, CBORFormat().Unmarshal(response.ResultData, &decoded))
+	assert.Equal(t, "cat.png", decoded.Name)
+
+	// Agents without a preference get JSON
+	jsonSession, negotiated := register()
+	assert.Equal(t, FormatJSON, negotiated)
+	response = invoke(jsonSession)
+	assert.Equal(t, FormatJSON, response.ResultFormat)
+	assert.Empty(t, response.ResultData)
+	assert.JSONEq(t, `{"name": "cat.png", "blob": "iVBORwD/"}`, response.ResultJson)
+
+	// REST clients negotiate the response encoding through Accept, whatever the session's format
+	router := gin.New()
+	NewAgentAPI(logger, mockRegistry, server).RegisterRoutes(router.Group("/api/v1"))
+	post := func(sessionID, accept string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/agents/"+sessionID+"/tools/thumbnail/invoke", strings.NewReader(`{}`))
+		request.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		return recorder
+	}
+
+	recorder := post(msgpackSession, "application/cbor, application/json;q=0.5")
+	assert.Equal(t, "application/cbor", recorder.Header().Get("Content-Type"))
+	var envelope struct {
+		Result       binaryResult `json:"result"`
+		ResultFormat string       `json:"result_format"`
+	}
+	require.NoError(t, CBORFormat().Unmarshal(recorder.Body.Bytes(), &envelope))
+	assert.Equal(t, FormatCBOR, envelope.ResultFormat)
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}, envelope.Result.Blob)
+
+	recorder = post(msgpackSession, "")
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "application/json")
+	var rest struct {
+		Result       interface{} `json:"result"`
+		ResultFormat string      `json:"result_format"`
 	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rest))
+	assert.Equal(t, FormatJSON, rest.ResultFormat)
+	assert.Equal(t, "cat.png", rest.Result.(map[string]interface{})["name"])
+
+	// Servers may offer JSON alone
+	config := DefaultAgentServerConfig()
+	config.ResultFormats = []ResultFormat{JSONFormat()}
+	jsonOnly := NewAgentServerWithConfig(logger, mockRegistry, config)
+	registered, err := jsonOnly.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:      "agent-1",
+		AgentName:    "Agent",
+		Capabilities: &agentpb.AgentCapabilities{PreferredFormats: []string{"msgpack"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, registered.ServerInfo.Capabilities["result_format"])
+	assert.Equal(t, "json", jsonOnly.Capabilities().Features["result_formats"].Options["formats"])
 }
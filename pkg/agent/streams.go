@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventStreamBuffer is how many events a stream holds for a slow client
+	eventStreamBuffer = 100
+
+	// DefaultStreamCheckInterval is how often event streams are health checked
+	DefaultStreamCheckInterval = 30 * time.Second
+
+	// DefaultStreamStallTimeout is how long a stream may keep a full buffer
+	// without delivering an event before it is considered dead
+	DefaultStreamStallTimeout = 2 * time.Minute
+)
+
+// eventStream is one StreamEvents call. Broadcasts enqueue events without
+// blocking; the call's goroutine delivers them until the client goes away
+// or the stream is closed.
+type eventStream struct {
+	sessionID string
+	events    chan *agentpb.Event
+	done      chan struct{} // Closed to end the call
+	closeOnce sync.Once
+	ctx       context.Context // The call's context, done once the client goes away
+	opened    time.Time
+	lastSent  atomic.Int64  // Unix nanoseconds of the last delivered event
+	dropped   *atomic.Int64 // The session's dropped event counter
+	filter    eventFilter   // Broadcast events the client asked for
+}
+
+// eventFilter selects the broadcast events a stream delivers. Empty fields
+// match every event.
+type eventFilter struct {
+	types     []agentpb.EventType
+	toolNames []string // Glob patterns; events about no tool always match
+}
+
+// newEventFilter reads the filters of a StreamEvents request
+func newEventFilter(req *agentpb.StreamEventsRequest) (eventFilter, error) {
+	for _, pattern := range req.ToolNames {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return eventFilter{}, fmt.Errorf("invalid tool name pattern %q", pattern)
+		}
+	}
+	return eventFilter{types: req.EventTypes, toolNames: req.ToolNames}, nil
+}
+
+// matches reports whether the filter selects an event about toolName, which
+// is empty for events about no tool
+func (f eventFilter) matches(event *agentpb.Event, toolName string) bool {
+	if len(f.types) > 0 && !containsValue(f.types, event.Type) {
+		return false
+	}
+	return len(f.toolNames) == 0 || toolName == "" || matchesAny(f.toolNames, toolName)
+}
+
+// eventToolName returns the tool an event is about, if any
+func eventToolName(event *agentpb.Event) string {
+	switch event.Type {
+	case agentpb.EventType_EVENT_TYPE_TOOL_ADDED, agentpb.EventType_EVENT_TYPE_TOOL_REMOVED,
+		agentpb.EventType_EVENT_TYPE_TOOL_UPDATED, agentpb.EventType_EVENT_TYPE_TOOL_INVOCATION:
+	default:
+		return ""
+	}
+	var data struct {
+		ToolName string `json:"tool_name"`
+	}
+	if err := json.Unmarshal([]byte(event.DataJson), &data); err != nil {
+		return ""
+	}
+	return data.ToolName
+}
+
+// offer enqueues an event without blocking, counting it as dropped when the
+// buffer is full
+func (e *eventStream) offer(event *agentpb.Event) bool {
+	select {
+	case e.events <- event:
+		return true
+	default:
+		e.dropped.Add(1)
+		return false
+	}
+}
+
+// close ends the call; it is safe to call more than once
+func (e *eventStream) close() {
+	e.closeOnce.Do(func() { close(e.done) })
+}
+
+// delivered records that the client received an event
+func (e *eventStream) delivered(now time.Time) {
+	e.lastSent.Store(now.UnixNano())
+}
+
+// stalled reports whether the stream has kept a full buffer without
+// delivering an event for longer than timeout, i.e. its client stopped reading
+func (e *eventStream) stalled(now time.Time, timeout time.Duration) bool {
+	if len(e.events) < cap(e.events) {
+		return false
+	}
+	last := e.opened
+	if sent := e.lastSent.Load(); sent > 0 {
+		last = time.Unix(0, sent)
+	}
+	return now.Sub(last) > timeout
+}
+
+// EventStreamStats reports the event streams of one session
+type EventStreamStats struct {
+	SessionID string `json:"session_id"`
+	Streams   int    `json:"streams"`  // Open StreamEvents calls
+	Buffered  int    `json:"buffered"` // Events waiting to be delivered
+	Dropped   int64  `json:"dropped"`  // Events dropped because a buffer was full
+}
+
+// openEventStream registers a stream for a session
+func (s *AgentServer) openEventStream(ctx context.Context, sessionID string, filter eventFilter) *eventStream {
+	s.streamsMux.Lock()
+	defer s.streamsMux.Unlock()
+
+	dropped, exists := s.streamDrops[sessionID]
+	if !exists {
+		dropped = new(atomic.Int64)
+		s.streamDrops[sessionID] = dropped
+	}
+	stream := &eventStream{
+		sessionID: sessionID,
+		events:    make(chan *agentpb.Event, eventStreamBuffer),
+		done:      make(chan struct{}),
+		ctx:       ctx,
+		opened:    s.config.Clock.Now(),
+		dropped:   dropped,
+		filter:    filter,
+	}
+	s.eventStreams[sessionID] = append(s.eventStreams[sessionID], stream)
+	return stream
+}
+
+// EventStreamStats reports the open event streams of each session, sorted by
+// session ID. Sessions whose streams have all closed keep reporting their
+// dropped events until the session ends.
+func (s *AgentServer) EventStreamStats() []EventStreamStats {
+	s.streamsMux.RLock()
+	defer s.streamsMux.RUnlock()
+
+	stats := make([]EventStreamStats, 0, len(s.streamDrops))
+	for sessionID, dropped := range s.streamDrops {
+		entry := EventStreamStats{SessionID: sessionID, Dropped: dropped.Load()}
+		for _, stream := range s.eventStreams[sessionID] {
+			entry.Streams++
+			entry.Buffered += len(stream.events)
+		}
+		stats = append(stats, entry)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].SessionID < stats[j].SessionID })
+	return stats
+}
+
+// EventStreamsReaped counts the streams the health check closed as dead
+func (s *AgentServer) EventStreamsReaped() int64 {
+	return s.streamsReaped.Load()
+}
+
+// streamHealthCheck periodically closes dead event streams
+func (s *AgentServer) streamHealthCheck(ticker clock.Ticker) {
+	defer ticker.Stop()
+
+	for range ticker.C() {
+		s.reapEventStreams(s.config.Clock.Now())
+	}
+}
+
+// reapEventStreams closes the streams whose client went away without the
+// call returning, whose session has ended, or whose client stopped reading,
+// so their goroutines and buffers are released. It returns how many it closed.
+func (s *AgentServer) reapEventStreams(now time.Time) int {
+	s.sessionsMux.RLock()
+	live := make(map[string]bool, len(s.sessions))
+	for sessionID := range s.sessions {
+		live[sessionID] = true
+	}
+	s.sessionsMux.RUnlock()
+
+	s.streamsMux.Lock()
+	defer s.streamsMux.Unlock()
+
+	reaped := 0
+	for sessionID, streams := range s.eventStreams {
+		kept := streams[:0]
+		for _, stream := range streams {
+			reason := ""
+			switch {
+			case stream.ctx.Err() != nil:
+				reason = "client went away"
+			case !live[sessionID]:
+				reason = "session ended"
+			case stream.stalled(now, s.config.StreamStallTimeout):
+				reason = "client stopped reading"
+			}
+			if reason == "" {
+				kept = append(kept, stream)
+				continue
+			}
+			stream.close()
+			reaped++
+			s.logger.Info("Closed dead event stream",
+				zap.String("session_id", sessionID),
+				zap.String("reason", reason),
+				zap.Int("buffered_events", len(stream.events)))
+		}
+		if len(kept) == 0 {
+			delete(s.eventStreams, sessionID)
+		} else {
+			s.eventStreams[sessionID] = kept
+		}
+	}
+	for sessionID := range s.streamDrops {
+		if !live[sessionID] && len(s.eventStreams[sessionID]) == 0 {
+			delete(s.streamDrops, sessionID)
+		}
+	}
+	s.streamsReaped.Add(int64(reaped))
+	return reaped
+}
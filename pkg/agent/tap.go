@@ -0,0 +1,260 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Tap event types, one per step of an invocation
+const (
+	TapInvocationStarted   = "invocation_started"
+	TapInvocationRetrying  = "invocation_retrying"
+	TapInvocationCompleted = "invocation_completed"
+	TapInvocationRejected  = "invocation_rejected"
+)
+
+const (
+	// tapBufferSize bounds the events queued for one tap. A tap that falls
+	// further behind misses events rather than slowing the agent down.
+	tapBufferSize = 256
+
+	// tapKeepAliveInterval is how often an idle tap sends a comment so
+	// proxies do not close the connection
+	tapKeepAliveInterval = 15 * time.Second
+
+	// tapRedactedMarker replaces redacted values
+	tapRedactedMarker = "[REDACTED]"
+)
+
+// DefaultTapRedactKeys are always redacted from tapped payloads. An object key
+// is redacted when it contains one of them, ignoring case.
+var DefaultTapRedactKeys = []string{
+	"password", "secret", "token", "api_key", "apikey",
+	"authorization", "cookie", "credential", "private_key",
+}
+
+// TapConfig controls what operators tapping a session see of its invocations
+type TapConfig struct {
+	IncludePayloads bool     // Stream parameters and results, redacted
+	RedactKeys      []string // Object keys redacted in addition to DefaultTapRedactKeys
+}
+
+// DefaultTapConfig streams redacted payloads
+func DefaultTapConfig() TapConfig {
+	return TapConfig{IncludePayloads: true}
+}
+
+// TapEvent is one step of an agent's invocation, streamed live to operators
+// tapping the session
+type TapEvent struct {
+	ID           uint64      `json:"id"`
+	Type         string      `json:"type"`
+	SessionID    string      `json:"session_id"`
+	InvocationID string      `json:"invocation_id,omitempty"`
+	ToolName     string      `json:"tool_name"`
+	Timestamp    time.Time   `json:"timestamp"`
+	Attempt      int         `json:"attempt,omitempty"`
+	Status       string      `json:"status,omitempty"`
+	Parameters   interface{} `json:"parameters,omitempty"`
+	Result       interface{} `json:"result,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	DurationMs   int64       `json:"duration_ms,omitempty"`
+}
+
+// tapSubscriber is one operator tapping a session
+type tapSubscriber struct {
+	events  chan TapEvent
+	dropped int
+}
+
+// tapHub fans the invocations of tapped sessions out to their subscribers
+type tapHub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[int]*tapSubscriber // Session ID -> subscriber ID
+	nextID      int
+	sequence    uint64
+	logger      *zap.Logger
+}
+
+// newTapHub creates a tap hub with no subscribers
+func newTapHub(logger *zap.Logger) *tapHub {
+	return &tapHub{
+		subscribers: make(map[string]map[int]*tapSubscriber),
+		nextID:      1,
+		logger:      logger,
+	}
+}
+
+// subscribe starts tapping a session and returns the subscriber ID and event channel
+func (h *tapHub) subscribe(sessionID string) (int, <-chan TapEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	subscriber := &tapSubscriber{events: make(chan TapEvent, tapBufferSize)}
+	if h.subscribers[sessionID] == nil {
+		h.subscribers[sessionID] = make(map[int]*tapSubscriber)
+	}
+	h.subscribers[sessionID][id] = subscriber
+	return id, subscriber.events
+}
+
+// unsubscribe stops a tap and closes its channel
+func (h *tapHub) unsubscribe(sessionID string, id int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscriber, exists := h.subscribers[sessionID][id]
+	if !exists {
+		return
+	}
+	h.remove(sessionID, id, subscriber)
+}
+
+// count returns the number of active taps across all sessions
+func (h *tapHub) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := 0
+	for _, subscribers := range h.subscribers {
+		count += len(subscribers)
+	}
+	return count
+}
+
+// closeSession ends all taps of a session, e.g. when it is unregistered or expires
+func (h *tapHub) closeSession(sessionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, subscriber := range h.subscribers[sessionID] {
+		h.remove(sessionID, id, subscriber)
+	}
+}
+
+// remove deletes a subscriber; the caller holds the lock
+func (h *tapHub) remove(sessionID string, id int, subscriber *tapSubscriber) {
+	delete(h.subscribers[sessionID], id)
+	if len(h.subscribers[sessionID]) == 0 {
+		delete(h.subscribers, sessionID)
+	}
+	close(subscriber.events)
+
+	if subscriber.dropped > 0 {
+		h.logger.Warn("Session tap missed events",
+			zap.String("session_id", sessionID),
+			zap.Int("dropped", subscriber.dropped))
+	}
+}
+
+// tapping reports whether anyone is tapping a session
+func (h *tapHub) tapping(sessionID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[sessionID]) > 0
+}
+
+// publish assigns the event an ID and delivers it to the session's taps without blocking
+func (h *tapHub) publish(event TapEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sequence++
+	event.ID = h.sequence
+	for _, subscriber := range h.subscribers[event.SessionID] {
+		select {
+		case subscriber.events <- event:
+		default:
+			subscriber.dropped++
+		}
+	}
+}
+
+// TapSession streams the invocations of a live session. The channel is closed
+// when the session ends or stop is called.
+func (s *AgentServer) TapSession(sessionID string) (<-chan TapEvent, func(), error) {
+	if _, exists := s.getSession(sessionID); !exists {
+		return nil, nil, status.Error(codes.NotFound, "session not found")
+	}
+	id, events := s.tap.subscribe(sessionID)
+	return events, func() { s.tap.unsubscribe(sessionID, id) }, nil
+}
+
+// tapInvocation publishes a step of an invocation to the session's taps, with
+// payloads redacted or removed as configured
+func (s *AgentServer) tapInvocation(req *agentpb.InvokeToolRequest, event TapEvent) {
+	if !s.tap.tapping(req.SessionId) {
+		return
+	}
+
+	event.SessionID = req.SessionId
+	event.InvocationID = req.InvocationId
+	event.ToolName = req.ToolName
+	event.Timestamp = s.config.Clock.Now()
+	if s.config.Tap.IncludePayloads {
+		event.Parameters = s.redactTapPayload(event.Parameters)
+		event.Result = s.redactTapPayload(event.Result)
+	} else {
+		event.Parameters, event.Result = nil, nil
+	}
+	s.tap.publish(event)
+}
+
+// redactTapPayload returns a JSON copy of a payload with sensitive values masked
+func (s *AgentServer) redactTapPayload(payload interface{}) interface{} {
+	if payload == nil {
+		return nil
+	}
+	// Round-trip through JSON so typed results are redacted like maps, and
+	// the agent's own values are never modified
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	var copied interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil
+	}
+	return redactValue(copied, s.config.Tap.RedactKeys)
+}
+
+// redactValue masks the values of sensitive object keys in a decoded JSON value
+func redactValue(value interface{}, extraKeys []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveKey(key, extraKeys) {
+				v[key] = tapRedactedMarker
+			} else {
+				v[key] = redactValue(field, extraKeys)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, extraKeys)
+		}
+	}
+	return value
+}
+
+// sensitiveKey reports whether an object key names a secret
+func sensitiveKey(key string, extraKeys []string) bool {
+	key = strings.ToLower(key)
+	for _, keys := range [][]string{DefaultTapRedactKeys, extraKeys} {
+		for _, sensitive := range keys {
+			if sensitive != "" && strings.Contains(key, strings.ToLower(sensitive)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CaptureLevel controls how much of an agent's tool executions the server
+// records for learning
+type CaptureLevel string
+
+const (
+	CaptureNone     CaptureLevel = "none"     // Record nothing
+	CaptureMetadata CaptureLevel = "metadata" // Record tool, outcome and timing, but no payloads
+	CaptureFull     CaptureLevel = "full"     // Also record parameters and results
+
+	// CaptureLevelMetadataKey is the registration metadata key an agent uses
+	// to request a capture level
+	CaptureLevelMetadataKey = "telemetry.capture_level"
+)
+
+// TelemetryPolicy bounds the capture levels agents may negotiate
+type TelemetryPolicy struct {
+	Default CaptureLevel `json:"default"` // Applied when the agent does not ask for a level
+	Max     CaptureLevel `json:"max"`     // Requests above this are lowered to it
+}
+
+// DefaultTelemetryPolicy records payloads only for agents that opt in
+func DefaultTelemetryPolicy() TelemetryPolicy {
+	return TelemetryPolicy{Default: CaptureMetadata, Max: CaptureFull}
+}
+
+// ParseCaptureLevel validates a capture level name
+func ParseCaptureLevel(value string) (CaptureLevel, error) {
+	level := CaptureLevel(strings.ToLower(strings.TrimSpace(value)))
+	switch level {
+	case CaptureNone, CaptureMetadata, CaptureFull:
+		return level, nil
+	}
+	return "", fmt.Errorf("invalid capture level %q: expected none, metadata or full", value)
+}
+
+// rank orders capture levels from least to most data captured
+func (l CaptureLevel) rank() int {
+	switch l {
+	case CaptureMetadata:
+		return 1
+	case CaptureFull:
+		return 2
+	}
+	return 0
+}
+
+// Negotiate resolves the capture level for a session from the agent's
+// request, which may be empty, capped at the policy maximum
+func (p TelemetryPolicy) Negotiate(requested string) (CaptureLevel, error) {
+	level := p.Default
+	if requested != "" {
+		parsed, err := ParseCaptureLevel(requested)
+		if err != nil {
+			return "", err
+		}
+		level = parsed
+	}
+	if level.rank() > p.Max.rank() {
+		level = p.Max
+	}
+	return level, nil
+}
@@ -0,0 +1,435 @@
+// Package apikey implements API key authentication. Keys are random tokens
+// handed out once; only their SHA-256 hashes are stored, in BoltDB, along with
+// the scopes that say what each key may do.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the HTTP header carrying an API key. Keys are also accepted as
+// an "Authorization: Bearer" token.
+const Header = "X-API-Key"
+
+// MetadataKey is the gRPC metadata key carrying an API key
+const MetadataKey = "x-api-key"
+
+// tokenPrefix starts every generated key, making leaked keys easy to spot
+const tokenPrefix = "aion_"
+
+// keysBucket holds the stored keys by ID
+const keysBucket = "api_keys"
+
+// Scope is a permission granted to a key
+type Scope string
+
+const (
+	ScopeAdmin          Scope = "admin"           // Administration endpoints; implies every other scope
+	ScopeAgentsRegister Scope = "agents:register" // Agent registration
+	ScopeToolsInvoke    Scope = "tools:invoke"    // Tool invocation
+)
+
+// Scopes lists every known scope
+var Scopes = []Scope{ScopeAdmin, ScopeAgentsRegister, ScopeToolsInvoke}
+
+var (
+	// ErrMissingKey is returned when a request carries no key
+	ErrMissingKey = errors.New("API key required")
+	// ErrInvalidKey is returned for unknown, revoked or expired keys
+	ErrInvalidKey = errors.New("invalid API key")
+	// ErrNotFound is returned when no key has the requested ID
+	ErrNotFound = errors.New("API key not found")
+)
+
+// ScopeError is returned when a key lacks the scope an operation requires
+type ScopeError struct {
+	KeyID string
+	Scope Scope
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("API key %s lacks the %q scope", e.KeyID, e.Scope)
+}
+
+// Quota caps the daily usage of a key. Zero fields are unlimited.
+type Quota struct {
+	MaxInvocationsPerDay int64   `json:"max_invocations_per_day"`
+	MaxCostPerDay        float64 `json:"max_cost_per_day"` // In the units of the configured tool costs
+}
+
+// Key describes an API key. The token itself is never stored.
+type Key struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"` // Start of the token, to tell keys apart
+	Scopes    []Scope    `json:"scopes"`
+	Roles     []string   `json:"roles,omitempty"` // Roles deciding which tools the key reaches
+	Quota     *Quota     `json:"quota,omitempty"` // Daily usage cap across the key's sessions; nil is unlimited
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Allows reports whether the key grants a scope
+func (k Key) Allows(scope Scope) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// expired reports whether the key has expired at a time
+func (k Key) expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// storedKey is a key as persisted, with the hash of its token
+type storedKey struct {
+	Key
+	Hash string `json:"hash"`
+}
+
+// ParseScopes validates scope names, dropping duplicates
+func ParseScopes(names []string) ([]Scope, error) {
+	if len(names) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	seen := make(map[Scope]bool, len(names))
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		scope := Scope(strings.ToLower(strings.TrimSpace(name)))
+		known := false
+		for _, candidate := range Scopes {
+			known = known || candidate == scope
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// Store keeps API keys in BoltDB, with an in-memory index by token hash for
+// authentication. It is safe for concurrent use.
+type Store struct {
+	db     *bolt.DB // Nil for stores held only in memory
+	mu     sync.RWMutex
+	keys   map[string]storedKey // By ID
+	hashes map[string]string    // Token hash to ID
+	now    func() time.Time
+}
+
+// Open opens or creates the key store at path
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create API key directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open API key store: %w", err)
+	}
+
+	store := &Store{
+		db:     db,
+		keys:   make(map[string]storedKey),
+		hashes: make(map[string]string),
+		now:    time.Now,
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(keysBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.ForEach(func(_, data []byte) error {
+			var key storedKey
+			if err := json.Unmarshal(data, &key); err != nil {
+				return fmt.Errorf("failed to decode API key: %w", err)
+			}
+			store.keys[key.ID] = key
+			store.hashes[key.Hash] = key.ID
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+	return store, nil
+}
+
+// OpenMemory creates an empty key store that is never written to disk
+func OpenMemory() *Store {
+	return &Store{
+		keys:   make(map[string]storedKey),
+		hashes: make(map[string]string),
+		now:    time.Now,
+	}
+}
+
+// SetClock replaces the clock key creation and expiry are judged by
+func (s *Store) SetClock(c clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = c.Now
+}
+
+// update persists a change unless the store is held only in memory
+func (s *Store) update(fn func(bucket *bolt.Bucket) error) error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket([]byte(keysBucket)))
+	})
+}
+
+// Close releases the BoltDB file
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Count returns the number of stored keys
+func (s *Store) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+// Create generates a key with the given scopes. A positive ttl makes it
+// expire. The returned token is the only time the key is revealed.
+func (s *Store) Create(name string, scopes []Scope, ttl time.Duration) (Key, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	key, err := s.Import(name, token, scopes, ttl)
+	return key, token, err
+}
+
+// Import stores an externally chosen token, such as a bootstrap key from the
+// environment. Importing a token that is already stored returns its key.
+func (s *Store) Import(name, token string, scopes []Scope, ttl time.Duration) (Key, error) {
+	if token == "" {
+		return Key{}, ErrMissingKey
+	}
+	hash := hashToken(token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, exists := s.hashes[hash]; exists {
+		return s.keys[id].Key, nil
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return Key{}, fmt.Errorf("failed to generate API key ID: %w", err)
+	}
+	// Reveal no more than a quarter of short, externally chosen tokens
+	prefixLength := len(tokenPrefix) + 4
+	if prefixLength > len(token)/4 {
+		prefixLength = len(token) / 4
+	}
+	stored := storedKey{
+		Key: Key{
+			ID:        hex.EncodeToString(idBytes),
+			Name:      name,
+			Prefix:    token[:prefixLength],
+			Scopes:    scopes,
+			CreatedAt: s.now().UTC(),
+		},
+		Hash: hash,
+	}
+	if ttl > 0 {
+		expiresAt := stored.CreatedAt.Add(ttl)
+		stored.ExpiresAt = &expiresAt
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to encode API key: %w", err)
+	}
+	err = s.update(func(bucket *bolt.Bucket) error {
+		return bucket.Put([]byte(stored.ID), data)
+	})
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to store API key: %w", err)
+	}
+	s.keys[stored.ID] = stored
+	s.hashes[hash] = stored.ID
+	return stored.Key, nil
+}
+
+// List returns the stored keys, oldest first
+func (s *Store) List() []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]Key, 0, len(s.keys))
+	for _, stored := range s.keys {
+		keys = append(keys, stored.Key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// Get returns a key by ID
+func (s *Store) Get(id string) (Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, exists := s.keys[id]
+	if !exists {
+		return Key{}, ErrNotFound
+	}
+	return stored.Key, nil
+}
+
+// SetRoles replaces the roles of a key
+func (s *Store) SetRoles(id string, roles []string) (Key, error) {
+	return s.modify(id, func(key *Key) { key.Roles = roles })
+}
+
+// SetQuota replaces the daily quota of a key; nil removes it
+func (s *Store) SetQuota(id string, quota *Quota) (Key, error) {
+	if quota != nil && (quota.MaxInvocationsPerDay < 0 || quota.MaxCostPerDay < 0) {
+		return Key{}, errors.New("quota limits must not be negative")
+	}
+	return s.modify(id, func(key *Key) { key.Quota = quota })
+}
+
+// modify changes a stored key
+func (s *Store) modify(id string, change func(key *Key)) (Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.keys[id]
+	if !exists {
+		return Key{}, ErrNotFound
+	}
+	change(&stored.Key)
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to encode API key: %w", err)
+	}
+	err = s.update(func(bucket *bolt.Bucket) error {
+		return bucket.Put([]byte(id), data)
+	})
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to store API key: %w", err)
+	}
+	s.keys[id] = stored
+	return stored.Key, nil
+}
+
+// Revoke deletes a key, rejecting it from then on
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.keys[id]
+	if !exists {
+		return ErrNotFound
+	}
+	err := s.update(func(bucket *bolt.Bucket) error {
+		return bucket.Delete([]byte(id))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	delete(s.keys, id)
+	delete(s.hashes, stored.Hash)
+	return nil
+}
+
+// Authenticate returns the key a token belongs to
+func (s *Store) Authenticate(token string) (Key, error) {
+	if token == "" {
+		return Key{}, ErrMissingKey
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, exists := s.hashes[hashToken(token)]
+	if !exists {
+		return Key{}, ErrInvalidKey
+	}
+	stored := s.keys[id]
+	if stored.expired(s.now()) {
+		return Key{}, ErrInvalidKey
+	}
+	return stored.Key, nil
+}
+
+// Authorize authenticates a token and checks that its key grants a scope
+func (s *Store) Authorize(token string, scope Scope) (Key, error) {
+	key, err := s.Authenticate(token)
+	if err != nil {
+		return Key{}, err
+	}
+	if !key.Allows(scope) {
+		return key, &ScopeError{KeyID: key.ID, Scope: scope}
+	}
+	return key, nil
+}
+
+// FromRequest extracts the API key of an HTTP request
+func FromRequest(r *http.Request) string {
+	if token := r.Header.Get(Header); token != "" {
+		return token
+	}
+	return bearerToken(r.Header.Get("Authorization"))
+}
+
+// FromMetadata extracts the API key of a gRPC call
+func FromMetadata(md metadata.MD) string {
+	if values := md.Get(MetadataKey); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		return bearerToken(values[0])
+	}
+	return ""
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer" value
+func bearerToken(authorization string) string {
+	const scheme = "bearer "
+	if len(authorization) > len(scheme) && strings.EqualFold(authorization[:len(scheme)], scheme) {
+		return strings.TrimSpace(authorization[len(scheme):])
+	}
+	return ""
+}
+
+// hashToken hashes a token for storage. Tokens are random, so an unsalted
+// hash is enough to keep a copy of the database from revealing them.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+package apikey
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.db")
+	store, err := Open(path)
+	require.NoError(t, err)
+
+	key, token, err := store.Create("ci", []Scope{ScopeToolsInvoke}, 0)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, tokenPrefix))
+	assert.True(t, strings.HasPrefix(token, key.Prefix))
+	assert.Nil(t, key.ExpiresAt)
+
+	authenticated, err := store.Authorize(token, ScopeToolsInvoke)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	_, err = store.Authorize(token, ScopeAdmin)
+	var scopeErr *ScopeError
+	require.ErrorAs(t, err, &scopeErr)
+	assert.Equal(t, ScopeAdmin, scopeErr.Scope)
+	_, err = store.Authenticate("aion_unknown")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = store.Authenticate("")
+	assert.ErrorIs(t, err, ErrMissingKey)
+
+	// Admin keys grant every scope
+	admin, err := store.Import("bootstrap", "configured-bootstrap-key", []Scope{ScopeAdmin}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "config", admin.Prefix)
+	_, err = store.Authorize("configured-bootstrap-key", ScopeAgentsRegister)
+	assert.NoError(t, err)
+	again, err := store.Import("bootstrap", "configured-bootstrap-key", []Scope{ScopeAdmin}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, again.ID)
+
+	// Expired keys are rejected
+	expiring, expiringToken, err := store.Create("short", []Scope{ScopeAgentsRegister}, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, expiring.ExpiresAt)
+	store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = store.Authenticate(expiringToken)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	store.now = time.Now
+
+	withRoles, err := store.SetRoles(key.ID, []string{"reader"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reader"}, withRoles.Roles)
+	_, err = store.SetRoles("missing", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	withQuota, err := store.SetQuota(key.ID, &Quota{MaxInvocationsPerDay: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reader"}, withQuota.Roles, "setting a quota keeps the roles")
+	_, err = store.SetQuota(key.ID, &Quota{MaxCostPerDay: -1})
+	assert.Error(t, err)
+
+	// Only hashes reach the database, and keys survive a reopen
+	require.NoError(t, store.Close())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), token)
+	assert.NotContains(t, string(data), "configured-bootstrap-key")
+
+	store, err = Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Len(t, store.List(), 3)
+	reopened, err := store.Authorize(token, ScopeToolsInvoke)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reader"}, reopened.Roles)
+	assert.Equal(t, &Quota{MaxInvocationsPerDay: 100}, reopened.Quota)
+
+	require.NoError(t, store.Revoke(key.ID))
+	_, err = store.Authenticate(token)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	assert.ErrorIs(t, store.Revoke(key.ID), ErrNotFound)
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"tools:invoke", " Admin ", "tools:invoke"})
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeToolsInvoke, ScopeAdmin}, scopes)
+
+	_, err = ParseScopes(nil)
+	assert.Error(t, err)
+	_, err = ParseScopes([]string{"tools:delete"})
+	assert.Error(t, err)
+}
+
+func TestTokenExtraction(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	assert.Empty(t, FromRequest(request))
+	request.Header.Set("Authorization", "Bearer aion_abc")
+	assert.Equal(t, "aion_abc", FromRequest(request))
+	request.Header.Set(Header, "aion_def")
+	assert.Equal(t, "aion_def", FromRequest(request))
+	request.Header.Del(Header)
+	request.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	assert.Empty(t, FromRequest(request))
+
+	assert.Equal(t, "aion_abc", FromMetadata(metadata.Pairs("authorization", "bearer aion_abc")))
+	assert.Equal(t, "aion_def", FromMetadata(metadata.Pairs(MetadataKey, "aion_def", "authorization", "Bearer aion_abc")))
+	assert.Empty(t, FromMetadata(nil))
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := OpenMemory()
+	defer store.Close()
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	store.SetClock(fake)
+
+	key, token, err := store.Create("ci", []Scope{ScopeToolsInvoke}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, fake.Now().Add(time.Minute), *key.ExpiresAt)
+	_, err = store.Authorize(token, ScopeToolsInvoke)
+	require.NoError(t, err)
+
+	fake.Advance(time.Minute)
+	_, err = store.Authenticate(token)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	require.NoError(t, store.Revoke(key.ID))
+	assert.Zero(t, store.Count())
+}
@@ -0,0 +1,243 @@
+// Package audit keeps an append-only record of who did what on the server:
+// tool invocations with their parameters, spec imports and removals, agent
+// session lifecycle and administrative changes. Entries are stored in their
+// own BoltDB bucket and can only be added, never changed or deleted.
+package audit
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	bolt "go.etcd.io/bbolt"
+)
+
+// eventsBucket holds the entries keyed by their big-endian sequence number
+const eventsBucket = "audit_events"
+
+// Category groups related actions
+type Category string
+
+const (
+	CategoryTool    Category = "tool"    // Tool invocations
+	CategorySpec    Category = "spec"    // Spec imports, reloads and removals
+	CategorySession Category = "session" // Agent session lifecycle
+	CategoryAdmin   Category = "admin"   // Changes made through the admin API
+)
+
+// Actions recorded by the server
+const (
+	ActionToolInvoke        = "tool.invoke"
+	ActionSpecImport        = "spec.import"
+	ActionSpecReload        = "spec.reload"
+	ActionSpecRemove        = "spec.remove"
+	ActionSessionRegister   = "session.register"
+	ActionSessionUnregister = "session.unregister"
+	ActionSessionExpire     = "session.expire"
+	ActionSessionEvict      = "session.evict"
+	ActionSessionResume     = "session.resume"
+)
+
+// Outcome describes how an action ended
+type Outcome string
+
+const (
+	OutcomeSuccess  Outcome = "success"
+	OutcomeFailure  Outcome = "failure"
+	OutcomeRejected Outcome = "rejected" // Refused before it ran (limits, policy)
+)
+
+// Actor identifies who performed an action
+type Actor struct {
+	Type      string `json:"type"`                 // agent, http, stdio, system
+	Subject   string `json:"subject,omitempty"`    // Authenticated principal, e.g. apikey:<id>
+	Name      string `json:"name,omitempty"`       // Agent or client name when known
+	SessionID string `json:"session_id,omitempty"` // Agent session when acting through one
+	Address   string `json:"address,omitempty"`    // Remote network address when known
+}
+
+// Event is one audit log entry
+type Event struct {
+	ID        uint64            `json:"id"` // Assigned in append order
+	Timestamp time.Time         `json:"timestamp"`
+	Category  Category          `json:"category"`
+	Action    string            `json:"action"`
+	Actor     Actor             `json:"actor"`
+	Target    string            `json:"target,omitempty"` // Tool name, source ID, session ID or admin route
+	Params    any               `json:"params,omitempty"`
+	Outcome   Outcome           `json:"outcome"`
+	Error     string            `json:"error,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Since    time.Time // Inclusive
+	Until    time.Time // Exclusive
+	Actor    string    // Matches the actor's subject, name or session ID
+	Category Category
+	Action   string
+	Limit    int // Newest entries kept when more match; zero is unlimited
+}
+
+// Matches reports whether an entry passes the filter
+func (f Filter) Matches(event Event) bool {
+	if !f.Since.IsZero() && event.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !event.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.Actor != "" && f.Actor != event.Actor.Subject && f.Actor != event.Actor.Name && f.Actor != event.Actor.SessionID {
+		return false
+	}
+	if f.Category != "" && f.Category != event.Category {
+		return false
+	}
+	if f.Action != "" && f.Action != event.Action {
+		return false
+	}
+	return true
+}
+
+// Log is the audit log. It is safe for concurrent use, and a nil log
+// discards everything recorded.
+type Log struct {
+	db     *bolt.DB // Nil for logs held only in memory
+	mu     sync.Mutex
+	events []Event // Entries of in-memory logs
+	nextID uint64
+	now    func() time.Time
+}
+
+// Open opens or creates the audit log at path
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(eventsBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize audit log: %w", err)
+	}
+	return &Log{db: db, now: time.Now}, nil
+}
+
+// OpenMemory creates an empty audit log that is never written to disk
+func OpenMemory() *Log {
+	return &Log{now: time.Now}
+}
+
+// SetClock replaces the clock entries are timestamped by
+func (l *Log) SetClock(c clock.Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = c.Now
+}
+
+// Record appends an entry, assigning its ID and, when unset, its timestamp
+func (l *Log) Record(event Event) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = l.now()
+	}
+	event.Timestamp = event.Timestamp.UTC()
+	if event.Outcome == "" {
+		event.Outcome = OutcomeSuccess
+	}
+
+	if l.db == nil {
+		l.nextID++
+		event.ID = l.nextID
+		l.events = append(l.events, event)
+		return nil
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(eventsBucket))
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		event.ID = id
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit event: %w", err)
+		}
+		return bucket.Put(sequenceKey(id), data)
+	})
+}
+
+// Query returns the entries matching a filter, newest first
+func (l *Log) Query(filter Filter) ([]Event, error) {
+	if l == nil {
+		return []Event{}, nil
+	}
+	events := []Event{}
+	keep := func(event Event) bool {
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+		return filter.Limit <= 0 || len(events) < filter.Limit
+	}
+
+	if l.db == nil {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i := len(l.events) - 1; i >= 0; i-- {
+			if !keep(l.events[i]) {
+				break
+			}
+		}
+		return events, nil
+	}
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(eventsBucket)).Cursor()
+		for key, data := cursor.Last(); key != nil; key, data = cursor.Prev() {
+			var event Event
+			if err := json.Unmarshal(data, &event); err != nil {
+				return fmt.Errorf("failed to decode audit event %d: %w", binary.BigEndian.Uint64(key), err)
+			}
+			if !keep(event) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Close closes the underlying database
+func (l *Log) Close() error {
+	if l == nil || l.db == nil {
+		return nil
+	}
+	return l.db.Close()
+}
+
+// sequenceKey encodes an ID so keys sort in append order
+func sequenceKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
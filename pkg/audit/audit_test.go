@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.db")
+	disk, err := Open(path)
+	require.NoError(t, err)
+
+	for name, log := range map[string]*Log{"bolt": disk, "memory": OpenMemory()} {
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+			alice := Actor{Type: "http", Subject: "apikey:alice"}
+			agent := Actor{Type: "agent", Name: "planner", SessionID: "session-1"}
+			events := []Event{
+				{Timestamp: start, Category: CategorySpec, Action: ActionSpecImport, Actor: alice, Target: "petstore"},
+				{Timestamp: start.Add(time.Minute), Category: CategorySession, Action: ActionSessionRegister, Actor: agent, Target: "session-1"},
+				{Timestamp: start.Add(2 * time.Minute), Category: CategoryTool, Action: ActionToolInvoke, Actor: agent, Target: "echo",
+					Params: map[string]any{"message": "hi"}, Outcome: OutcomeFailure, Error: "boom"},
+				{Timestamp: start.Add(3 * time.Minute), Category: CategoryAdmin, Action: "PUT /api/v1/admin/read-only", Actor: alice},
+			}
+			for _, event := range events {
+				require.NoError(t, log.Record(event))
+			}
+
+			all, err := log.Query(Filter{})
+			require.NoError(t, err)
+			require.Len(t, all, 4)
+			assert.Equal(t, uint64(4), all[0].ID, "newest first")
+			assert.Equal(t, OutcomeSuccess, all[3].Outcome, "outcome defaults to success")
+			assert.Equal(t, map[string]any{"message": "hi"}, all[1].Params)
+
+			byActor, err := log.Query(Filter{Actor: "apikey:alice"})
+			require.NoError(t, err)
+			assert.Len(t, byActor, 2)
+			bySession, err := log.Query(Filter{Actor: "session-1", Category: CategoryTool})
+			require.NoError(t, err)
+			require.Len(t, bySession, 1)
+			assert.Equal(t, "boom", bySession[0].Error)
+
+			window, err := log.Query(Filter{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)})
+			require.NoError(t, err)
+			require.Len(t, window, 2)
+			assert.Equal(t, ActionToolInvoke, window[0].Action)
+
+			limited, err := log.Query(Filter{Limit: 1})
+			require.NoError(t, err)
+			require.Len(t, limited, 1)
+			assert.Equal(t, CategoryAdmin, limited[0].Category)
+		})
+	}
+
+	// Entries survive a restart and IDs keep increasing
+	require.NoError(t, disk.Close())
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	require.NoError(t, reopened.Record(Event{Category: CategoryAdmin, Action: "DELETE /api/v1/admin/apikeys/:id"}))
+	events, err := reopened.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+	assert.Equal(t, uint64(5), events[0].ID)
+	assert.False(t, events[0].Timestamp.IsZero())
+
+	var disabled *Log
+	assert.NoError(t, disabled.Record(Event{}))
+}
@@ -0,0 +1,98 @@
+// Package backup stores database snapshots in a local directory or an
+// S3-compatible bucket.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot file names start with SnapshotPrefix and end with SnapshotSuffix
+const (
+	SnapshotPrefix = "aionmcp-"
+	SnapshotSuffix = ".db"
+)
+
+// SnapshotName names the snapshot taken at t. Names sort by time.
+func SnapshotName(t time.Time) string {
+	return SnapshotPrefix + t.UTC().Format("20060102T150405Z") + SnapshotSuffix
+}
+
+// Target stores snapshots
+type Target interface {
+	// Store saves a snapshot of size bytes under name
+	Store(ctx context.Context, name string, snapshot io.Reader, size int64) error
+	// String describes the target for logs and status reports
+	String() string
+}
+
+// Directory keeps snapshots as files in a directory
+type Directory struct {
+	Path string
+	Keep int // Newest snapshots kept; older ones are removed. Zero keeps all.
+}
+
+// Store writes the snapshot to a temporary file and renames it into place,
+// so the directory never holds a partial snapshot under a snapshot name
+func (d Directory) Store(ctx context.Context, name string, snapshot io.Reader, size int64) error {
+	if err := os.MkdirAll(d.Path, 0o755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	path := filepath.Join(d.Path, name)
+	file, err := os.CreateTemp(d.Path, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, snapshot); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+	return d.prune()
+}
+
+// prune removes all but the newest Keep snapshots
+func (d Directory) prune() error {
+	if d.Keep <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(d.Path)
+	if err != nil {
+		return err
+	}
+	var snapshots []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasPrefix(name, SnapshotPrefix) && strings.HasSuffix(name, SnapshotSuffix) {
+			snapshots = append(snapshots, name)
+		}
+	}
+	sort.Strings(snapshots)
+	for len(snapshots) > d.Keep {
+		if err := os.Remove(filepath.Join(d.Path, snapshots[0])); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+func (d Directory) String() string {
+	return "directory:" + d.Path
+}
@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	target := Directory{Path: dir, Keep: 2}
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, target.Store(context.Background(), SnapshotName(start.Add(time.Duration(i)*time.Hour)), strings.NewReader("snapshot"), 8))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644))
+	require.NoError(t, target.prune())
+
+	// Only the newest snapshots are kept, and other files are left alone
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"aionmcp-20260301T130000Z.db", "aionmcp-20260301T140000Z.db", "notes.txt"}, names)
+	data, err := os.ReadFile(filepath.Join(dir, names[1]))
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", string(data))
+}
+
+func TestS3Store(t *testing.T) {
+	var uploaded *http.Request
+	var body string
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		uploaded, body = r, string(data)
+		if strings.Contains(r.URL.Path, "denied") {
+			http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		}
+	}))
+	defer service.Close()
+
+	s3, err := NewS3(S3Config{
+		Endpoint:        service.URL,
+		Bucket:          "snapshots",
+		Prefix:          "aionmcp/",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio-secret",
+		PathStyle:       true,
+	}, service.Client())
+	require.NoError(t, err)
+	require.NoError(t, s3.Store(context.Background(), "aionmcp-20260301T120000Z.db", strings.NewReader("snapshot"), 8))
+
+	require.NotNil(t, uploaded)
+	assert.Equal(t, http.MethodPut, uploaded.Method)
+	assert.Equal(t, "/snapshots/aionmcp/aionmcp-20260301T120000Z.db", uploaded.URL.Path)
+	// Plain HTTP uploads sign each chunk of the payload
+	assert.Equal(t, "8", uploaded.Header.Get("X-Amz-Decoded-Content-Length"))
+	assert.Contains(t, body, "\r\nsnapshot\r\n")
+	assert.True(t, strings.HasPrefix(uploaded.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/"))
+	assert.Equal(t, "s3://snapshots/aionmcp/", s3.String())
+
+	err = s3.Store(context.Background(), "denied.db", strings.NewReader("snapshot"), 8)
+	var response minio.ErrorResponse
+	require.ErrorAs(t, err, &response)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	_, err = NewS3(S3Config{Endpoint: "localhost:9000"}, nil)
+	assert.Error(t, err)
+	_, err = NewS3(S3Config{Endpoint: "http://localhost:9000/storage", Bucket: "snapshots"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config locates a bucket on Amazon S3 or an S3-compatible service such as MinIO
+type S3Config struct {
+	Endpoint        string // Service URL without a path, e.g. https://s3.us-east-1.amazonaws.com or http://localhost:9000
+	Region          string
+	Bucket          string
+	Prefix          string // Prepended to snapshot names, e.g. "backups/"
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Address the bucket in the path instead of the host name, as most S3-compatible services require
+}
+
+// S3 uploads snapshots to a bucket with the MinIO client, which signs
+// requests with AWS Signature Version 4
+type S3 struct {
+	config S3Config
+	client *minio.Client
+}
+
+// NewS3 creates an S3 target. Only the transport of client is used; a nil
+// client selects the default transport.
+func NewS3(config S3Config, client *http.Client) (*S3, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" || (endpoint.Path != "" && endpoint.Path != "/") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+
+	options := &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, ""),
+		Secure:       endpoint.Scheme == "https",
+		Region:       config.Region,
+		BucketLookup: minio.BucketLookupDNS,
+	}
+	if config.PathStyle {
+		options.BucketLookup = minio.BucketLookupPath
+	}
+	if client != nil {
+		options.Transport = client.Transport
+	}
+	s3, err := minio.New(endpoint.Host, options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", config.Endpoint, err)
+	}
+	return &S3{config: config, client: s3}, nil
+}
+
+// Store uploads the snapshot as an object named by the prefix and name
+func (s *S3) Store(ctx context.Context, name string, snapshot io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.config.Bucket, s.config.Prefix+name, snapshot, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *S3) String() string {
+	return "s3://" + s.config.Bucket + "/" + s.config.Prefix
+}
@@ -0,0 +1,77 @@
+// Package capabilities describes which optional server features are enabled,
+// along with their versions and limits, so clients can discover what a
+// server supports before relying on it.
+package capabilities
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Feature describes one optional server feature
+type Feature struct {
+	Enabled bool              `json:"enabled"`
+	Version string            `json:"version,omitempty"` // Version of the feature's protocol or API, if it has one
+	Limits  map[string]int64  `json:"limits,omitempty"`  // Numeric bounds clients must stay within; zero means unlimited
+	Options map[string]string `json:"options,omitempty"` // Configured behaviour clients may adapt to
+}
+
+// Matrix is the feature matrix of a server
+type Matrix struct {
+	ServerVersion   string             `json:"server_version"`
+	ProtocolVersion string             `json:"protocol_version"`
+	Features        map[string]Feature `json:"features"`
+}
+
+// New creates an empty feature matrix
+func New(serverVersion, protocolVersion string) *Matrix {
+	return &Matrix{
+		ServerVersion:   serverVersion,
+		ProtocolVersion: protocolVersion,
+		Features:        make(map[string]Feature),
+	}
+}
+
+// Set adds or replaces a feature
+func (m *Matrix) Set(name string, feature Feature) {
+	m.Features[name] = feature
+}
+
+// Merge adds the features of other, replacing features of the same name
+func (m *Matrix) Merge(other map[string]Feature) {
+	for name, feature := range other {
+		m.Features[name] = feature
+	}
+}
+
+// Enabled returns the names of the enabled features, sorted
+func (m *Matrix) Enabled() []string {
+	names := make([]string, 0, len(m.Features))
+	for name, feature := range m.Features {
+		if feature.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Flatten returns the matrix as string pairs for transports that only carry
+// string maps. Keys are the feature name followed by "enabled", "version",
+// "limits.<name>" or "options.<name>", separated by dots.
+func (m *Matrix) Flatten() map[string]string {
+	flat := make(map[string]string)
+	for name, feature := range m.Features {
+		flat[name+".enabled"] = strconv.FormatBool(feature.Enabled)
+		if feature.Version != "" {
+			flat[name+".version"] = feature.Version
+		}
+		for limit, value := range feature.Limits {
+			flat[name+".limits."+limit] = strconv.FormatInt(value, 10)
+		}
+		for option, value := range feature.Options {
+			flat[name+".options."+option] = value
+		}
+	}
+	return flat
+}
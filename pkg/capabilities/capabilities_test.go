@@ -0,0 +1,28 @@
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrix(t *testing.T) {
+	matrix := New("1.2.3", "MCP/1.0")
+	matrix.Set("async_execution", Feature{Enabled: true, Limits: map[string]int64{"queue_size": 100}})
+	matrix.Set("tls", Feature{Enabled: false})
+	matrix.Merge(map[string]Feature{
+		"jsonrpc": {Enabled: true, Version: "2.0", Options: map[string]string{"transports": "http,websocket"}},
+		"tls":     {Enabled: true, Options: map[string]string{"client_auth": "require"}},
+	})
+
+	assert.Equal(t, []string{"async_execution", "jsonrpc", "tls"}, matrix.Enabled())
+	assert.Equal(t, map[string]string{
+		"async_execution.enabled":           "true",
+		"async_execution.limits.queue_size": "100",
+		"jsonrpc.enabled":                   "true",
+		"jsonrpc.version":                   "2.0",
+		"jsonrpc.options.transports":        "http,websocket",
+		"tls.enabled":                       "true",
+		"tls.options.client_auth":           "require",
+	}, matrix.Flatten())
+}
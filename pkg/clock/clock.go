@@ -0,0 +1,218 @@
+// Package clock abstracts the current time so time-based logic can run
+// against a controllable clock in tests and in-process integration runs, or
+// against a simulated clock that runs faster than real time.
+//
+// Clocks tell the time that subsystems judge expiry, retention and schedules
+// by. Durations of work, such as tool latencies, are still measured with the
+// system clock.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time and drives periodic work
+type Clock interface {
+	Now() time.Time
+	// NewTicker delivers the clock's time on C every d of clock time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is a time.Ticker driven by a Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the system clock
+type Real struct{}
+
+// Now returns the current system time
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns a system ticker
+func (Real) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// realTicker adapts a time.Ticker
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.ticker.C }
+func (t realTicker) Stop()               { t.ticker.Stop() }
+
+// Fake is a clock that only moves when told to. Moving it fires the tickers
+// that fall due, once each like time.Ticker drops ticks for slow receivers.
+// It is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFake creates a fake clock showing start
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTicker returns a ticker firing as the fake clock moves past each period
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ticker := &fakeTicker{clock: f, period: d, next: f.now.Add(d), c: make(chan time.Time, 1)}
+	f.tickers = append(f.tickers, ticker)
+	return ticker
+}
+
+// Tickers returns the number of running tickers, letting tests wait until
+// the code under test has started its schedule
+func (f *Fake) Tickers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.tickers)
+}
+
+// Set moves the clock to t
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	f.fire()
+}
+
+// Advance moves the clock forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.fire()
+}
+
+// fire delivers the current time to due tickers, earliest first. Callers hold f.mu.
+func (f *Fake) fire() {
+	due := make([]*fakeTicker, 0, len(f.tickers))
+	for _, ticker := range f.tickers {
+		if !ticker.next.After(f.now) {
+			due = append(due, ticker)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].next.Before(due[j].next) })
+	for _, ticker := range due {
+		select {
+		case ticker.c <- f.now:
+		default:
+		}
+		for !ticker.next.After(f.now) {
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+}
+
+// fakeTicker is a ticker of a Fake clock
+type fakeTicker struct {
+	clock  *Fake
+	period time.Duration
+	next   time.Time
+	c      chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, ticker := range t.clock.tickers {
+		if ticker == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Simulated is a clock that starts at a chosen time and runs speed times
+// faster than real time, for simulating days of sessions, retention and
+// scheduled work in minutes
+type Simulated struct {
+	start     time.Time
+	realStart time.Time
+	speed     float64
+}
+
+// NewSimulated creates a clock showing start and running speed times faster
+// than real time. Speeds below 1 slow it down; a non-positive speed runs it
+// at real time.
+func NewSimulated(start time.Time, speed float64) *Simulated {
+	if speed <= 0 {
+		speed = 1
+	}
+	return &Simulated{start: start, realStart: time.Now(), speed: speed}
+}
+
+// Speed returns how many times faster than real time the clock runs
+func (s *Simulated) Speed() float64 {
+	return s.speed
+}
+
+// Now returns the simulated time
+func (s *Simulated) Now() time.Time {
+	elapsed := time.Since(s.realStart)
+	return s.start.Add(time.Duration(float64(elapsed) * s.speed))
+}
+
+// NewTicker returns a ticker firing every d of simulated time
+func (s *Simulated) NewTicker(d time.Duration) Ticker {
+	interval := time.Duration(float64(d) / s.speed)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	ticker := &simulatedTicker{ticker: time.NewTicker(interval), c: make(chan time.Time, 1), done: make(chan struct{})}
+	go ticker.run(s)
+	return ticker
+}
+
+// simulatedTicker relays real ticks as simulated times
+type simulatedTicker struct {
+	ticker   *time.Ticker
+	c        chan time.Time
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (t *simulatedTicker) run(s *Simulated) {
+	for {
+		select {
+		case <-t.ticker.C:
+			select {
+			case t.c <- s.Now():
+			default:
+			}
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *simulatedTicker) C() <-chan time.Time { return t.c }
+
+func (t *simulatedTicker) Stop() {
+	t.stopOnce.Do(func() {
+		t.ticker.Stop()
+		close(t.done)
+	})
+}
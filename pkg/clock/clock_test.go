@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// received returns the tick waiting on a ticker, if any
+func received(ticker Ticker) (time.Time, bool) {
+	select {
+	case tick := <-ticker.C():
+		return tick, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+	ticker := fake.NewTicker(time.Minute)
+	assert.Equal(t, 1, fake.Tickers())
+
+	fake.Advance(30 * time.Second)
+	_, ok := received(ticker)
+	assert.False(t, ok)
+
+	fake.Advance(30 * time.Second)
+	tick, ok := received(ticker)
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Minute), tick)
+
+	// Missed periods collapse into one tick, as with time.Ticker
+	fake.Advance(5 * time.Minute)
+	_, ok = received(ticker)
+	assert.True(t, ok)
+	_, ok = received(ticker)
+	assert.False(t, ok)
+	fake.Advance(time.Minute)
+	_, ok = received(ticker)
+	assert.True(t, ok)
+
+	fake.Set(start.Add(time.Hour))
+	_, ok = received(ticker)
+	assert.True(t, ok)
+
+	ticker.Stop()
+	assert.Equal(t, 0, fake.Tickers())
+	fake.Advance(time.Hour)
+	_, ok = received(ticker)
+	assert.False(t, ok)
+}
+
+func TestSimulated(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	simulated := NewSimulated(start, 3600)
+	assert.Equal(t, 3600.0, simulated.Speed())
+	assert.Equal(t, 1.0, NewSimulated(start, 0).Speed())
+
+	// A simulated hour passes every real second
+	ticker := simulated.NewTicker(time.Minute)
+	defer ticker.Stop()
+	select {
+	case tick := <-ticker.C():
+		assert.False(t, tick.Before(start.Add(time.Minute)))
+	case <-time.After(time.Second):
+		t.Fatal("simulated ticker did not fire")
+	}
+	assert.True(t, simulated.Now().After(start))
+	ticker.Stop()
+}
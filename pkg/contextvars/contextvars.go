@@ -0,0 +1,224 @@
+// Package contextvars implements named context variables, such as base IDs
+// and tenant codes, defined server-wide or per workspace. Tool parameters
+// reference them as templates like {{ .env.TENANT_ID }}, which are resolved
+// at invocation time so agents need not carry the values.
+package contextvars
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// MaskedValue replaces the value of secret variables in listings
+const MaskedValue = "********"
+
+// namePattern restricts variable names to identifiers templates can reference
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Variable is a named value parameters can reference
+type Variable struct {
+	Name      string `json:"name" mapstructure:"name"`
+	Value     string `json:"value" mapstructure:"value"`
+	Secret    bool   `json:"secret,omitempty" mapstructure:"secret"` // Masked in listings
+	Workspace string `json:"workspace,omitempty" mapstructure:"workspace"`
+}
+
+// Store holds the server-wide and per-workspace variables. Workspace
+// variables override server-wide ones of the same name. It is safe for
+// concurrent use.
+type Store struct {
+	mu        sync.RWMutex
+	variables map[string]map[string]Variable // workspace ("" for server-wide) -> name -> variable
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{variables: make(map[string]map[string]Variable)}
+}
+
+// Set defines or replaces a variable
+func (s *Store) Set(variable Variable) error {
+	if !namePattern.MatchString(variable.Name) {
+		return fmt.Errorf("invalid variable name %q: use letters, digits and underscores", variable.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scope, exists := s.variables[variable.Workspace]
+	if !exists {
+		scope = make(map[string]Variable)
+		s.variables[variable.Workspace] = scope
+	}
+	scope[variable.Name] = variable
+	return nil
+}
+
+// Delete removes a variable, reporting whether it existed
+func (s *Store) Delete(workspace, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scope := s.variables[workspace]
+	if _, exists := scope[name]; !exists {
+		return false
+	}
+	delete(scope, name)
+	if len(scope) == 0 {
+		delete(s.variables, workspace)
+	}
+	return true
+}
+
+// List returns the variables visible in a workspace, server-wide ones
+// included unless overridden, sorted by name. Secret values are masked.
+func (s *Store) List(workspace string) []Variable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	visible := make(map[string]Variable)
+	for name, variable := range s.variables[""] {
+		visible[name] = variable
+	}
+	if workspace != "" {
+		for name, variable := range s.variables[workspace] {
+			visible[name] = variable
+		}
+	}
+
+	variables := make([]Variable, 0, len(visible))
+	for _, variable := range visible {
+		if variable.Secret {
+			variable.Value = MaskedValue
+		}
+		variables = append(variables, variable)
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	return variables
+}
+
+// Values returns the values visible in a workspace by name
+func (s *Store) Values(workspace string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := make(map[string]string)
+	for name, variable := range s.variables[""] {
+		values[name] = variable.Value
+	}
+	if workspace != "" {
+		for name, variable := range s.variables[workspace] {
+			values[name] = variable.Value
+		}
+	}
+	return values
+}
+
+// workspaceKey carries the workspace of an invocation in its context
+type workspaceKey struct{}
+
+// WithWorkspace returns a context whose invocations resolve the variables of workspace
+func WithWorkspace(ctx context.Context, workspace string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// WorkspaceFromContext returns the workspace set by WithWorkspace, if any
+func WorkspaceFromContext(ctx context.Context) string {
+	workspace, _ := ctx.Value(workspaceKey{}).(string)
+	return workspace
+}
+
+// Resolve renders the templates in every string of input, walking nested
+// objects and arrays, with the variables of the context's workspace as
+// .env and the workspace name as .workspace. Strings without templates and
+// other values are returned unchanged; referencing an undefined variable is
+// an error.
+func (s *Store) Resolve(ctx context.Context, input any) (any, error) {
+	if !hasTemplates(input) {
+		return input, nil
+	}
+	workspace := WorkspaceFromContext(ctx)
+	data := map[string]any{"env": s.Values(workspace), "workspace": workspace}
+	return resolve(input, data, "")
+}
+
+// hasTemplates reports whether any string in input contains a template
+func hasTemplates(input any) bool {
+	switch value := input.(type) {
+	case string:
+		return strings.Contains(value, "{{")
+	case map[string]any:
+		for _, item := range value {
+			if hasTemplates(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if hasTemplates(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolve renders the templates in input, copying the objects and arrays it
+// changes so the caller's parameters are left as they were
+func resolve(input any, data map[string]any, path string) (any, error) {
+	switch value := input.(type) {
+	case string:
+		if !strings.Contains(value, "{{") {
+			return value, nil
+		}
+		return render(value, data, path)
+	case map[string]any:
+		resolved := make(map[string]any, len(value))
+		for key, item := range value {
+			var err error
+			if resolved[key], err = resolve(item, data, joinPath(path, key)); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(value))
+		for i, item := range value {
+			var err error
+			if resolved[i], err = resolve(item, data, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	}
+	return input, nil
+}
+
+// render executes one parameter template
+func render(text string, data map[string]any, path string) (string, error) {
+	tmpl, err := template.New(path).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template in parameter %s: %w", displayPath(path), err)
+	}
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("cannot resolve parameter %s: %w", displayPath(path), err)
+	}
+	return builder.String(), nil
+}
+
+// joinPath appends an object key to a parameter path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath names the parameter a path points at
+func displayPath(path string) string {
+	if path == "" {
+		return "input"
+	}
+	return path
+}
@@ -0,0 +1,106 @@
+// Package eventstore persists the most recent broadcast events of the agent
+// service by sequence number, so clients can replay what they missed across
+// reconnects and server restarts. Events are stored as opaque bytes in a
+// BoltDB bucket keyed by big-endian sequence, which keeps them in order.
+package eventstore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// eventsBucket holds the events keyed by sequence
+const eventsBucket = "events"
+
+// Record is one stored event
+type Record struct {
+	Sequence uint64
+	Data     []byte
+}
+
+// Store keeps the newest events up to its size. It is safe for concurrent use.
+type Store struct {
+	db   *bolt.DB
+	size uint64
+}
+
+// Open opens or creates the event store at path, keeping the newest size
+// events. Size must be positive.
+func Open(path string, size int) (*Store, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("event store size must be positive, got %d", size)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create event store directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event store: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(eventsBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize event store: %w", err)
+	}
+	return &Store{db: db, size: uint64(size)}, nil
+}
+
+// Close releases the BoltDB file
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Append stores an event and drops those that fell out of the store's size
+func (s *Store) Append(sequence uint64, data []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(eventsBucket))
+		if err := bucket.Put(key(sequence), data); err != nil {
+			return err
+		}
+		if sequence <= s.size {
+			return nil
+		}
+		oldest := sequence - s.size
+		var evicted [][]byte
+		cursor := bucket.Cursor()
+		for k, _ := cursor.First(); k != nil && binary.BigEndian.Uint64(k) <= oldest; k, _ = cursor.Next() {
+			evicted = append(evicted, k)
+		}
+		for _, k := range evicted {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load returns the stored events, oldest first
+func (s *Store) Load() ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(eventsBucket)).ForEach(func(k, v []byte) error {
+			records = append(records, Record{Sequence: binary.BigEndian.Uint64(k), Data: append([]byte(nil), v...)})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	return records, nil
+}
+
+// key encodes a sequence so keys sort in sequence order
+func key(sequence uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, sequence)
+	return k
+}
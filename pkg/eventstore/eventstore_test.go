@@ -0,0 +1,35 @@
+package eventstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+	store, err := Open(path, 3)
+	require.NoError(t, err)
+
+	for sequence := uint64(1); sequence <= 5; sequence++ {
+		require.NoError(t, store.Append(sequence, []byte{byte(sequence)}))
+	}
+	records, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{3, []byte{3}}, {4, []byte{4}}, {5, []byte{5}}}, records, "oldest dropped beyond the size")
+
+	// Events survive a restart
+	require.NoError(t, store.Close())
+	reopened, err := Open(path, 3)
+	require.NoError(t, err)
+	defer reopened.Close()
+	records, err = reopened.Load()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, uint64(5), records[2].Sequence)
+
+	_, err = Open(filepath.Join(t.TempDir(), "events.db"), 0)
+	assert.Error(t, err)
+}
@@ -5,18 +5,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/messaging"
 	"github.com/aionmcp/aionmcp/pkg/types"
 )
 
 // AsyncAPIImporter handles AsyncAPI specifications
-type AsyncAPIImporter struct{}
+type AsyncAPIImporter struct {
+	fetcher *SpecFetcher
+	pool    *messaging.Pool // Broker connections of the generated tools
+}
 
 // NewAsyncAPIImporter creates a new AsyncAPI importer
 func NewAsyncAPIImporter() *AsyncAPIImporter {
-	return &AsyncAPIImporter{}
+	return &AsyncAPIImporter{
+		fetcher: NewSpecFetcher(DefaultFetchPolicy()),
+		pool:    messaging.NewPool(messaging.PoolConfig{}),
+	}
+}
+
+// SetFetcher sets how specifications are downloaded from URLs
+func (i *AsyncAPIImporter) SetFetcher(fetcher *SpecFetcher) {
+	i.fetcher = fetcher
+}
+
+// SetMessagingPool sets the broker connections tools imported afterwards
+// publish and subscribe through
+func (i *AsyncAPIImporter) SetMessagingPool(pool *messaging.Pool) {
+	i.pool = pool
+}
+
+// messagingImporter is an importer whose tools connect to message brokers
+type messagingImporter interface {
+	SetMessagingPool(pool *messaging.Pool)
+}
+
+// SetMessagingPool shares broker connections between the tools of every
+// registered importer and those registered later
+func (m *ImporterManager) SetMessagingPool(pool *messaging.Pool) {
+	m.messaging = pool
+	for _, importer := range m.importers {
+		if connecting, ok := importer.(messagingImporter); ok {
+			connecting.SetMessagingPool(pool)
+		}
+	}
 }
 
 // GetType returns the specification type
@@ -31,16 +68,15 @@ func (i *AsyncAPIImporter) Supports(source SpecSource) bool {
 
 // Validate checks if the AsyncAPI specification is valid
 func (i *AsyncAPIImporter) Validate(ctx context.Context, source SpecSource) error {
-	content, err := i.loadSpec(source.Path)
+	content, err := i.loadSpec(ctx, source)
 	if err != nil {
 		return err
 	}
 
 	// Simple validation - check if it's valid JSON/YAML
-	var spec map[string]interface{}
-	if err := json.Unmarshal(content, &spec); err != nil {
-		// Try YAML parsing as fallback
-		return fmt.Errorf("invalid JSON format: %w", err)
+	spec, err := decodeSpecDocument(source.Path, content)
+	if err != nil {
+		return err
 	}
 
 	// Check for required AsyncAPI fields
@@ -64,16 +100,16 @@ func (i *AsyncAPIImporter) Import(ctx context.Context, source SpecSource) (*Impo
 	}
 
 	// Load the specification
-	content, err := i.loadSpec(source.Path)
+	content, err := i.loadSpec(ctx, source)
 	if err != nil {
 		result.Errors = append(result.Errors, err)
 		result.Duration = time.Since(start)
 		return result, err
 	}
 
-	// Parse the AsyncAPI document as JSON
-	var spec map[string]interface{}
-	if err := json.Unmarshal(content, &spec); err != nil {
+	// Parse the AsyncAPI document as JSON or YAML
+	spec, err := decodeSpecDocument(source.Path, content)
+	if err != nil {
 		result.Errors = append(result.Errors, fmt.Errorf("failed to parse AsyncAPI spec: %w", err))
 		result.Duration = time.Since(start)
 		return result, err
@@ -116,15 +152,12 @@ func (i *AsyncAPIImporter) Import(ctx context.Context, source SpecSource) (*Impo
 	return result, nil
 }
 
-// loadSpec loads an AsyncAPI specification from file
-func (i *AsyncAPIImporter) loadSpec(path string) ([]byte, error) {
-	// For now, only support file loading
-	// TODO: Add URL support for AsyncAPI specs
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		return nil, fmt.Errorf("URL loading not yet supported for AsyncAPI specs")
+// loadSpec loads an AsyncAPI specification from file or URL
+func (i *AsyncAPIImporter) loadSpec(ctx context.Context, source SpecSource) ([]byte, error) {
+	if isRemoteSpec(source.Path) {
+		return i.fetcher.Fetch(ctx, source.Path, source.Auth)
 	}
-
-	return os.ReadFile(path)
+	return os.ReadFile(source.Path)
 }
 
 // createPublishTool creates a tool for publishing messages to a channel
@@ -135,6 +168,7 @@ func (i *AsyncAPIImporter) createPublishTool(source SpecSource, spec map[string]
 		channelName: channelName,
 		channel:     channel,
 		operation:   "publish",
+		pool:        i.pool,
 	}
 }
 
@@ -146,6 +180,7 @@ func (i *AsyncAPIImporter) createSubscribeTool(source SpecSource, spec map[strin
 		channelName: channelName,
 		channel:     channel,
 		operation:   "subscribe",
+		pool:        i.pool,
 	}
 }
 
@@ -156,6 +191,7 @@ type AsyncAPITool struct {
 	channelName string
 	channel     map[string]interface{}
 	operation   string // "publish" or "subscribe"
+	pool        *messaging.Pool
 }
 
 // Name returns the tool name
@@ -179,64 +215,190 @@ func (t *AsyncAPITool) Description() string {
 	return fmt.Sprintf("AsyncAPI %s operation on channel %s", t.operation, t.channelName)
 }
 
+// Source metadata keys configuring how AsyncAPI tools connect to brokers
+const (
+	brokerServerKey   = "broker_server" // Server to connect to; the first by name when unset
+	brokerUsernameKey = "broker_username"
+	brokerPasswordKey = "broker_password"
+	brokerTokenKey    = "broker_token"
+	brokerClientIDKey = "broker_client_id"
+	brokerGroupKey    = "broker_group"
+)
+
+// Subscription defaults when the input sets none
+const (
+	defaultSubscribeTimeout     = 30 // Seconds
+	defaultSubscribeMaxMessages = 10
+)
+
+// channelParameterPattern matches the parameters of a channel name, e.g. {userId}
+var channelParameterPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
 // Execute performs the AsyncAPI operation
-func (t *AsyncAPITool) Execute(input any) (any, error) {
+func (t *AsyncAPITool) Execute(ctx context.Context, input any) (any, error) {
 	// Parse input
 	inputMap, ok := input.(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf("input must be a JSON object")
 	}
 
-	// Get server information
-	servers, exists := t.spec["servers"].(map[string]interface{})
-	if !exists || len(servers) == 0 {
-		return nil, fmt.Errorf("no servers defined in AsyncAPI specification")
+	serverName, endpoint, err := t.endpoint()
+	if err != nil {
+		return nil, err
 	}
-
-	// Use first server (in production, this should be configurable)
-	var serverURL string
-	var protocol string
-	for _, serverData := range servers {
-		if server, ok := serverData.(map[string]interface{}); ok {
-			if url, exists := server["url"].(string); exists {
-				serverURL = url
-			}
-			if prot, exists := server["protocol"].(string); exists {
-				protocol = prot
-			}
-			break
-		}
+	destination, err := t.destination(endpoint, inputMap)
+	if err != nil {
+		return nil, err
 	}
 
 	switch t.operation {
 	case "publish":
-		return t.executePublish(inputMap, serverURL, protocol)
+		return t.executePublish(ctx, inputMap, serverName, endpoint, destination)
 	case "subscribe":
-		return t.executeSubscribe(inputMap, serverURL, protocol)
+		return t.executeSubscribe(ctx, inputMap, serverName, endpoint, destination)
 	default:
 		return nil, fmt.Errorf("unsupported operation: %s", t.operation)
 	}
 }
 
-// executePublish handles message publishing
-func (t *AsyncAPITool) executePublish(input map[string]interface{}, serverURL, protocol string) (interface{}, error) {
+// endpoint resolves the server named by the source's broker_server metadata,
+// or the first server by name, with the credentials the source metadata holds
+func (t *AsyncAPITool) endpoint() (string, messaging.Endpoint, error) {
+	servers, exists := t.spec["servers"].(map[string]interface{})
+	if !exists || len(servers) == 0 {
+		return "", messaging.Endpoint{}, fmt.Errorf("no servers defined in AsyncAPI specification")
+	}
+
+	name := t.source.Metadata[brokerServerKey]
+	if name == "" {
+		names := make([]string, 0, len(servers))
+		for serverName := range servers {
+			names = append(names, serverName)
+		}
+		sort.Strings(names)
+		name = names[0]
+	}
+	server, ok := servers[name].(map[string]interface{})
+	if !ok {
+		return "", messaging.Endpoint{}, fmt.Errorf("server %q is not defined in AsyncAPI specification", name)
+	}
+
+	serverURL, _ := server["url"].(string)
+	protocol, _ := server["protocol"].(string)
+	if serverURL == "" {
+		return "", messaging.Endpoint{}, fmt.Errorf("server %q has no url", name)
+	}
+	// Server variables take their default values
+	variables, _ := server["variables"].(map[string]interface{})
+	for variable, definition := range variables {
+		if definition, ok := definition.(map[string]interface{}); ok {
+			if value, ok := definition["default"]; ok {
+				serverURL = strings.ReplaceAll(serverURL, "{"+variable+"}", fmt.Sprint(value))
+			}
+		}
+	}
+
+	metadata := t.source.Metadata
+	return name, messaging.Endpoint{
+		Protocol: protocol,
+		URL:      serverURL,
+		Username: metadata[brokerUsernameKey],
+		Password: metadata[brokerPasswordKey],
+		Token:    metadata[brokerTokenKey],
+		ClientID: metadata[brokerClientIDKey],
+		Group:    metadata[brokerGroupKey],
+	}, nil
+}
+
+// destination addresses the channel on the broker. Channel parameters are
+// substituted from the input; an MQTT subscription matches every value of a
+// parameter the input leaves out. Kafka topics and AMQP exchanges and queues
+// come from the channel bindings.
+func (t *AsyncAPITool) destination(endpoint messaging.Endpoint, input map[string]interface{}) (messaging.Destination, error) {
+	protocol := messaging.NormalizeProtocol(endpoint.Protocol)
+	parameters, _ := input["parameters"].(map[string]interface{})
+
+	name := t.channelName
+	var missing []string
+	for _, match := range channelParameterPattern.FindAllStringSubmatch(t.channelName, -1) {
+		value, ok := parameters[match[1]]
+		switch {
+		case ok:
+			name = strings.ReplaceAll(name, match[0], fmt.Sprint(value))
+		case protocol == messaging.ProtocolMQTT && t.operation == "subscribe":
+			name = strings.ReplaceAll(name, match[0], "+")
+		default:
+			missing = append(missing, match[1])
+		}
+	}
+	if len(missing) > 0 {
+		return messaging.Destination{}, fmt.Errorf("missing channel parameters: %s", strings.Join(missing, ", "))
+	}
+
+	destination := messaging.Destination{Name: name}
+	bindings, _ := t.channel["bindings"].(map[string]interface{})
+	switch protocol {
+	case messaging.ProtocolKafka:
+		// Kafka topics cannot contain slashes
+		destination.Name = strings.ReplaceAll(name, "/", ".")
+		if topic := stringAt(bindings, "kafka", "topic"); topic != "" {
+			destination.Name = topic
+		}
+	case messaging.ProtocolAMQP:
+		destination.Exchange = stringAt(bindings, "amqp", "exchange", "name")
+		destination.Queue = stringAt(bindings, "amqp", "queue", "name")
+	}
+	return destination, nil
+}
+
+// executePublish sends the payload to the channel. A string payload is sent
+// as is and any other payload as JSON.
+func (t *AsyncAPITool) executePublish(ctx context.Context, input map[string]interface{}, serverName string, endpoint messaging.Endpoint, destination messaging.Destination) (interface{}, error) {
 	// Extract message payload
 	payload, exists := input["payload"]
 	if !exists {
 		return nil, fmt.Errorf("payload is required for publish operation")
 	}
 
-	// For now, return a simulation response
-	// TODO: Implement actual message publishing based on protocol (MQTT, AMQP, WebSocket, etc.)
+	message := messaging.Message{Channel: destination.Name, Timestamp: time.Now()}
+	if text, ok := payload.(string); ok {
+		message.Payload = []byte(text)
+		message.ContentType = "text/plain"
+	} else {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payload: %w", err)
+		}
+		message.Payload = encoded
+		message.ContentType = "application/json"
+	}
+	if contentType, ok := t.spec["defaultContentType"].(string); ok && contentType != "" {
+		message.ContentType = contentType
+	}
+	if headers, ok := input["headers"].(map[string]interface{}); ok {
+		message.Headers = make(map[string]string, len(headers))
+		for name, value := range headers {
+			message.Headers[name] = fmt.Sprint(value)
+		}
+	}
+	if key, ok := input["key"]; ok {
+		message.Key = fmt.Sprint(key)
+	}
+
+	if err := t.pool.Publish(ctx, endpoint, destination, message); err != nil {
+		return nil, err
+	}
+
 	result := map[string]interface{}{
-		"operation":  "publish",
-		"channel":    t.channelName,
-		"payload":    payload,
-		"server_url": serverURL,
-		"protocol":   protocol,
-		"timestamp":  time.Now().Unix(),
-		"status":     "simulated", // Indicates this is a simulation
-		"message":    "Message publishing is simulated. Actual implementation depends on protocol adapter.",
+		"operation":   "publish",
+		"channel":     t.channelName,
+		"destination": destination.Name,
+		"payload":     payload,
+		"server":      serverName,
+		"server_url":  endpoint.URL,
+		"protocol":    endpoint.Protocol,
+		"timestamp":   message.Timestamp.Unix(),
+		"status":      "published",
 	}
 
 	// Add any headers from input
@@ -247,36 +409,99 @@ func (t *AsyncAPITool) executePublish(input map[string]interface{}, serverURL, p
 	return result, nil
 }
 
-// executeSubscribe handles message subscription
-func (t *AsyncAPITool) executeSubscribe(input map[string]interface{}, serverURL, protocol string) (interface{}, error) {
+// executeSubscribe consumes messages from the channel until max_messages
+// arrived or the timeout elapsed
+func (t *AsyncAPITool) executeSubscribe(ctx context.Context, input map[string]interface{}, serverName string, endpoint messaging.Endpoint, destination messaging.Destination) (interface{}, error) {
 	// Extract subscription parameters
-	timeout := 30 // Default timeout in seconds
-	if timeoutVal, exists := input["timeout"]; exists {
-		if timeoutFloat, ok := timeoutVal.(float64); ok {
-			timeout = int(timeoutFloat)
+	timeout := defaultSubscribeTimeout
+	if timeoutFloat, ok := input["timeout"].(float64); ok && timeoutFloat > 0 {
+		timeout = int(timeoutFloat)
+	}
+	maxMessages := defaultSubscribeMaxMessages
+	if maxFloat, ok := input["max_messages"].(float64); ok && maxFloat > 0 {
+		maxMessages = int(maxFloat)
+	}
+	filter, _ := input["filter"].(map[string]interface{})
+
+	messages, err := t.pool.Subscribe(ctx, endpoint, destination, messaging.SubscribeOptions{
+		Wait:        time.Duration(timeout) * time.Second,
+		MaxMessages: maxMessages,
+		Filter:      payloadFilter(filter),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	received := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		entry := map[string]interface{}{
+			"channel":   message.Channel,
+			"payload":   decodePayload(message.Payload),
+			"timestamp": message.Timestamp.Unix(),
+		}
+		if message.Key != "" {
+			entry["key"] = message.Key
 		}
+		if len(message.Headers) > 0 {
+			entry["headers"] = message.Headers
+		}
+		received = append(received, entry)
 	}
 
-	// For now, return a simulation response
-	// TODO: Implement actual message subscription based on protocol
-	result := map[string]interface{}{
-		"operation":  "subscribe",
-		"channel":    t.channelName,
-		"server_url": serverURL,
-		"protocol":   protocol,
-		"timeout":    timeout,
-		"timestamp":  time.Now().Unix(),
-		"status":     "simulated",
-		"message":    "Message subscription is simulated. Actual implementation depends on protocol adapter.",
-		"simulated_messages": []map[string]interface{}{
-			{
-				"payload":   map[string]interface{}{"message": "Simulated message 1"},
-				"timestamp": time.Now().Unix(),
-			},
-		},
+	return map[string]interface{}{
+		"operation":   "subscribe",
+		"channel":     t.channelName,
+		"destination": destination.Name,
+		"server":      serverName,
+		"server_url":  endpoint.URL,
+		"protocol":    endpoint.Protocol,
+		"timeout":     timeout,
+		"timestamp":   time.Now().Unix(),
+		"status":      "received",
+		"messages":    received,
+	}, nil
+}
+
+// payloadFilter accepts messages whose payload is a JSON object holding every
+// field of the filter with an equal value; nil when there is no filter
+func payloadFilter(filter map[string]interface{}) func(messaging.Message) bool {
+	if len(filter) == 0 {
+		return nil
+	}
+	return func(message messaging.Message) bool {
+		var payload map[string]interface{}
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return false
+		}
+		for field, expected := range filter {
+			if value, ok := payload[field]; !ok || !reflect.DeepEqual(value, expected) {
+				return false
+			}
+		}
+		return true
 	}
+}
 
-	return result, nil
+// decodePayload returns a JSON payload decoded and any other payload as a string
+func decodePayload(payload []byte) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal(payload, &decoded); err == nil {
+		return decoded
+	}
+	return string(payload)
+}
+
+// stringAt returns the string at a path of nested objects, or "" when there is none
+func stringAt(object map[string]interface{}, path ...string) string {
+	for _, key := range path[:len(path)-1] {
+		next, ok := object[key].(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		object = next
+	}
+	value, _ := object[path[len(path)-1]].(string)
+	return value
 }
 
 // Metadata returns tool metadata
@@ -295,12 +520,15 @@ func (t *AsyncAPITool) Metadata() types.ToolMetadata {
 	case "publish":
 		// Publish operations require a payload
 		properties["payload"] = map[string]interface{}{
-			"type":        "object",
-			"description": "Message payload to publish",
+			"description": "Message payload to publish; strings are sent as is and other values as JSON",
 		}
 		properties["headers"] = map[string]interface{}{
 			"type":        "object",
-			"description": "Optional message headers",
+			"description": "Optional message headers, sent over AMQP and Kafka",
+		}
+		properties["key"] = map[string]interface{}{
+			"type":        "string",
+			"description": "Optional Kafka message key",
 		}
 		required = append(required, "payload")
 
@@ -309,11 +537,32 @@ func (t *AsyncAPITool) Metadata() types.ToolMetadata {
 		properties["timeout"] = map[string]interface{}{
 			"type":        "integer",
 			"description": "Subscription timeout in seconds",
-			"default":     30,
+			"default":     defaultSubscribeTimeout,
+		}
+		properties["max_messages"] = map[string]interface{}{
+			"type":        "integer",
+			"description": "Return once this many messages arrived",
+			"default":     defaultSubscribeMaxMessages,
 		}
 		properties["filter"] = map[string]interface{}{
 			"type":        "object",
-			"description": "Optional message filter criteria",
+			"description": "Optional fields received JSON payloads must hold with equal values",
+		}
+	}
+
+	// Channel parameters, e.g. {userId}
+	if parameters := channelParameterPattern.FindAllStringSubmatch(t.channelName, -1); len(parameters) > 0 {
+		parameterProperties := make(map[string]interface{}, len(parameters))
+		for _, parameter := range parameters {
+			parameterProperties[parameter[1]] = map[string]interface{}{"type": "string"}
+		}
+		properties["parameters"] = map[string]interface{}{
+			"type":        "object",
+			"description": "Values of the channel parameters",
+			"properties":  parameterProperties,
+		}
+		if t.operation == "publish" {
+			required = append(required, "parameters")
 		}
 	}
 
@@ -333,9 +582,11 @@ func (t *AsyncAPITool) Metadata() types.ToolMetadata {
 	}
 
 	// Add operation-specific output properties
+	outputSchema["properties"].(map[string]interface{})["destination"] = map[string]interface{}{"type": "string"}
+	outputSchema["properties"].(map[string]interface{})["server"] = map[string]interface{}{"type": "string"}
 	switch t.operation {
 	case "publish":
-		outputSchema["properties"].(map[string]interface{})["payload"] = map[string]interface{}{"type": "object"}
+		outputSchema["properties"].(map[string]interface{})["payload"] = map[string]interface{}{}
 	case "subscribe":
 		outputSchema["properties"].(map[string]interface{})["messages"] = map[string]interface{}{
 			"type":  "array",
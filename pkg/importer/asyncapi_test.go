@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/messaging"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokerAdapter records publishes and returns them to subscribers
+type brokerAdapter struct {
+	endpoints    []messaging.Endpoint
+	destinations []messaging.Destination
+	published    []messaging.Message
+}
+
+func (a *brokerAdapter) Publish(_ context.Context, endpoint messaging.Endpoint, destination messaging.Destination, message messaging.Message) error {
+	a.endpoints = append(a.endpoints, endpoint)
+	a.destinations = append(a.destinations, destination)
+	a.published = append(a.published, message)
+	return nil
+}
+
+func (a *brokerAdapter) Subscribe(_ context.Context, endpoint messaging.Endpoint, destination messaging.Destination, options messaging.SubscribeOptions) ([]messaging.Message, error) {
+	a.endpoints = append(a.endpoints, endpoint)
+	a.destinations = append(a.destinations, destination)
+	messages := []messaging.Message{}
+	for _, message := range a.published {
+		if options.Filter == nil || options.Filter(message) {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (a *brokerAdapter) Close() error { return nil }
+
+const brokerSpec = `{
+  "asyncapi": "2.6.0",
+  "servers": {
+    "production": {"url": "{host}:9092", "protocol": "kafka", "variables": {"host": {"default": "broker.internal"}}},
+    "edge": {"url": "mqtt.internal:1883", "protocol": "mqtt"}
+  },
+  "channels": {
+    "orders/{region}/created": {
+      "bindings": {"kafka": {"topic": "orders-created"}},
+      "publish": {"summary": "Order created"},
+      "subscribe": {"summary": "Order created"}
+    },
+    "devices/{deviceId}/telemetry": {
+      "publish": {"summary": "Telemetry"},
+      "subscribe": {"summary": "Telemetry"}
+    }
+  }
+}`
+
+func importBrokerSpec(t *testing.T, metadata map[string]string) (map[string]types.Tool, *brokerAdapter) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+	require.NoError(t, os.WriteFile(path, []byte(brokerSpec), 0644))
+
+	adapter := &brokerAdapter{}
+	pool := messaging.NewPool(messaging.PoolConfig{})
+	pool.SetAdapter(messaging.ProtocolKafka, adapter)
+	pool.SetAdapter(messaging.ProtocolMQTT, adapter)
+
+	asyncAPI := NewAsyncAPIImporter()
+	asyncAPI.SetMessagingPool(pool)
+	result, err := asyncAPI.Import(context.Background(), SpecSource{ID: "orders", Type: SpecTypeAsyncAPI, Path: path, Metadata: metadata})
+	require.NoError(t, err)
+
+	tools := make(map[string]types.Tool)
+	for _, tool := range result.Tools {
+		tools[tool.Name()] = tool
+	}
+	return tools, adapter
+}
+
+func TestAsyncAPIToolPublishSubscribe(t *testing.T) {
+	tools, adapter := importBrokerSpec(t, map[string]string{
+		"broker_server":   "production",
+		"broker_username": "svc",
+		"broker_password": "hunter2",
+	})
+	ctx := context.Background()
+
+	// Channel parameters are required to publish
+	publish := tools["asyncapi.orders.publish_orders_region_created"]
+	require.NotNil(t, publish)
+	_, err := publish.Execute(ctx, map[string]interface{}{"payload": map[string]interface{}{"id": 1}})
+	assert.ErrorContains(t, err, "missing channel parameters: region")
+
+	for _, id := range []float64{1, 2} {
+		result, err := publish.Execute(ctx, map[string]interface{}{
+			"payload":    map[string]interface{}{"id": id},
+			"parameters": map[string]interface{}{"region": "eu"},
+			"key":        "order",
+		})
+		require.NoError(t, err)
+		response := result.(map[string]interface{})
+		assert.Equal(t, "published", response["status"])
+		assert.Equal(t, "production", response["server"])
+		assert.Equal(t, "broker.internal:9092", response["server_url"])
+	}
+
+	// The Kafka binding names the topic, and the source metadata the credentials
+	assert.Equal(t, "orders-created", adapter.destinations[0].Name)
+	assert.Equal(t, "svc", adapter.endpoints[0].Username)
+	assert.Equal(t, "hunter2", adapter.endpoints[0].Password)
+	assert.Equal(t, "order", adapter.published[0].Key)
+	assert.Equal(t, "application/json", adapter.published[0].ContentType)
+
+	// Subscriptions filter on payload fields
+	subscribe := tools["asyncapi.orders.subscribe_orders_region_created"]
+	require.NotNil(t, subscribe)
+	result, err := subscribe.Execute(ctx, map[string]interface{}{
+		"parameters": map[string]interface{}{"region": "eu"},
+		"filter":     map[string]interface{}{"id": float64(2)},
+	})
+	require.NoError(t, err)
+	messages := result.(map[string]interface{})["messages"].([]map[string]interface{})
+	require.Len(t, messages, 1)
+	assert.Equal(t, map[string]interface{}{"id": float64(2)}, messages[0]["payload"])
+	assert.Equal(t, "order", messages[0]["key"])
+}
+
+func TestAsyncAPIToolMQTTWildcards(t *testing.T) {
+	tools, adapter := importBrokerSpec(t, map[string]string{"broker_server": "edge"})
+	ctx := context.Background()
+
+	// An MQTT subscription matches every value of a parameter left out
+	_, err := tools["asyncapi.orders.subscribe_devices_deviceId_telemetry"].Execute(ctx, map[string]interface{}{"timeout": float64(1)})
+	require.NoError(t, err)
+	assert.Equal(t, "devices/+/telemetry", adapter.destinations[0].Name)
+
+	// A string payload is sent as is
+	_, err = tools["asyncapi.orders.publish_devices_deviceId_telemetry"].Execute(ctx, map[string]interface{}{
+		"payload":    "21.5",
+		"parameters": map[string]interface{}{"deviceId": "d1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "devices/d1/telemetry", adapter.destinations[1].Name)
+	assert.Equal(t, "21.5", string(adapter.published[0].Payload))
+	assert.Equal(t, "text/plain", adapter.published[0].ContentType)
+
+	// Unknown servers are reported
+	tools, _ = importBrokerSpec(t, map[string]string{"broker_server": "staging"})
+	_, err = tools["asyncapi.orders.publish_devices_deviceId_telemetry"].Execute(ctx, map[string]interface{}{"payload": "x"})
+	assert.ErrorContains(t, err, `server "staging" is not defined`)
+}
+
+func TestSpecSourceMasksBrokerCredentials(t *testing.T) {
+	source := SpecSource{ID: "orders", Metadata: map[string]string{"broker_username": "svc", "broker_password": "hunter2", "broker_token": "abc"}}
+	encoded, err := json.Marshal(source)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "hunter2")
+	assert.NotContains(t, string(encoded), `"abc"`)
+	assert.Contains(t, string(encoded), `"svc"`)
+
+	// The source itself keeps the credentials
+	assert.Equal(t, "hunter2", source.Metadata["broker_password"])
+}
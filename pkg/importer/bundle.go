@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// bundleSpec moves what a specification references in other files, relative
+// paths or URLs, into its own components, so a spec split across files
+// imports like a single document. Components keep the names they have in
+// their files; a file holding a single schema is named after the file.
+func bundleSpec(ctx context.Context, doc *openapi3.T, location string) {
+	namer := &refNamer{root: path.Dir(location), names: make(map[string]string), refs: make(map[string]string)}
+	if doc.Components != nil {
+		// Components of the root document keep their names
+		for name, ref := range doc.Components.Schemas {
+			namer.reserve("schemas", name, ref)
+		}
+		for name, ref := range doc.Components.Parameters {
+			namer.reserve("parameters", name, ref)
+		}
+		for name, ref := range doc.Components.RequestBodies {
+			namer.reserve("requestBodies", name, ref)
+		}
+		for name, ref := range doc.Components.Responses {
+			namer.reserve("responses", name, ref)
+		}
+		for name, ref := range doc.Components.Headers {
+			namer.reserve("headers", name, ref)
+		}
+	}
+	// The default resolver of kin-openapi never returns for absolute paths
+	doc.InternalizeRefs(ctx, namer.name)
+}
+
+// refNamer names the components bundled into a specification. Distinct
+// references get distinct names, since components with the same name are
+// taken to be the same.
+type refNamer struct {
+	root  string            // Directory of the root document
+	names map[string]string // collection/name -> reference it names
+	refs  map[string]string // collection/reference -> its name
+}
+
+// reserve keeps the name of a root component, which other files may refer
+// to by its own file
+func (n *refNamer) reserve(collection, name string, ref openapi3.ComponentRef) {
+	n.claim(collection, name, "#/components/"+collection+"/"+name)
+	if ref != nil && ref.RefString() != "" && ref.RefPath() != nil {
+		n.refs[collection+"/"+ref.RefPath().String()] = name
+	}
+}
+
+// name returns the component name of an external reference
+func (n *refNamer) name(doc *openapi3.T, ref openapi3.ComponentRef) string {
+	// References back into the root document use the existing component
+	if rootRef, ok := openapi3.ReferencesComponentInRootDocument(doc, ref); ok {
+		return path.Base(rootRef)
+	}
+
+	location := ref.RefPath()
+	canonical := ref.RefString()
+	if location != nil {
+		canonical = location.String()
+	}
+	if name, ok := n.refs[ref.CollectionName()+"/"+canonical]; ok {
+		return name
+	}
+	var file, fragment string
+	if location != nil {
+		file, fragment = location.Path, location.Fragment
+	}
+	file = strings.TrimPrefix(strings.TrimPrefix(file, n.root), "/")
+	for ext := path.Ext(file); ext != ""; ext = path.Ext(file) {
+		file = strings.TrimSuffix(file, ext)
+	}
+	fragment = strings.TrimPrefix(strings.Trim(fragment, "/"), "components/"+ref.CollectionName()+"/")
+
+	// The component's own name, the file it fills, or its path
+	candidates := []string{path.Base(fragment), path.Base(file)}
+	full := strings.TrimLeft(strings.ReplaceAll(file, "../", ""), "./")
+	if fragment != "" {
+		full += "_" + fragment
+	}
+	candidates = append(candidates, full)
+	if fragment == "" {
+		candidates = candidates[1:]
+	}
+
+	for _, candidate := range candidates {
+		candidate = openapi3.InvalidIdentifierCharRegExp.ReplaceAllString(candidate, "_")
+		if candidate == "" || candidate == "." || candidate == "_" {
+			continue
+		}
+		if n.claim(ref.CollectionName(), candidate, canonical) {
+			return candidate
+		}
+	}
+	base := openapi3.InvalidIdentifierCharRegExp.ReplaceAllString(full, "_")
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d", base, i)
+		if n.claim(ref.CollectionName(), candidate, canonical) {
+			return candidate
+		}
+	}
+}
+
+// claim reserves a name for a reference, or reports it names another one
+func (n *refNamer) claim(collection, name, canonical string) bool {
+	key := collection + "/" + name
+	if existing, ok := n.names[key]; ok {
+		return existing == canonical
+	}
+	n.names[key] = canonical
+	n.refs[collection+"/"+canonical] = name
+	return true
+}
@@ -0,0 +1,160 @@
+package importer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multiFileSpec is a specification split across files, with a schema name
+// used by two files in different directories
+var multiFileSpec = map[string]string{
+	"openapi.yaml": `openapi: 3.0.3
+info: {title: Kennel, version: 1.0.0}
+paths:
+  /pets:
+    $ref: paths/pets.yaml
+  /pets/{id}:
+    $ref: paths/pet.yaml
+components:
+  schemas:
+    Error:
+      $ref: schemas/error.yaml
+`,
+	"paths/pets.yaml": `get:
+  operationId: listPets
+  parameters:
+    - $ref: ../parameters.yaml#/limit
+  responses:
+    "200":
+      description: OK
+      content:
+        application/json:
+          schema:
+            type: array
+            items:
+              $ref: ../schemas/pet.yaml
+`,
+	"paths/pet.yaml": `get:
+  operationId: getPet
+  parameters:
+    - {name: id, in: path, required: true, schema: {type: string}}
+  responses:
+    "200":
+      description: OK
+      content:
+        application/json:
+          schema:
+            $ref: ../schemas/pet.yaml
+    "410":
+      description: Moved to the archive
+      content:
+        application/json:
+          schema:
+            $ref: ../archive/pet.yaml
+    default:
+      description: Error
+      content:
+        application/json:
+          schema:
+            $ref: ../schemas/error.yaml
+`,
+	"parameters.yaml": `limit:
+  name: limit
+  in: query
+  schema: {type: integer, maximum: 100}
+`,
+	"schemas/pet.yaml": `type: object
+required: [id, name]
+properties:
+  id: {type: string}
+  name: {type: string}
+  owner:
+    $ref: owner.yaml
+`,
+	"schemas/owner.yaml": `type: object
+properties:
+  name: {type: string}
+`,
+	"schemas/error.yaml": `type: object
+properties:
+  message: {type: string}
+`,
+	"archive/pet.yaml": `type: object
+properties:
+  archived_at: {type: string, format: date-time}
+`,
+}
+
+// writeMultiFileSpec writes multiFileSpec to a directory
+func writeMultiFileSpec(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range multiFileSpec {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestOpenAPIMultiFileSpecs(t *testing.T) {
+	dir := writeMultiFileSpec(t)
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	for name, path := range map[string]string{
+		"file":   filepath.Join(dir, "openapi.yaml"),
+		"remote": server.URL + "/openapi.yaml",
+	} {
+		t.Run(name, func(t *testing.T) {
+			source := SpecSource{ID: "kennel", Type: SpecTypeOpenAPI, Path: path}
+			result, err := NewOpenAPIImporter().Import(context.Background(), source)
+			require.NoError(t, err)
+			assert.Empty(t, result.Errors)
+
+			names := make([]string, 0, len(result.Tools))
+			for _, tool := range result.Tools {
+				names = append(names, tool.Name())
+			}
+			sort.Strings(names)
+			assert.Equal(t, []string{"openapi.kennel.getPet", "openapi.kennel.listPets"}, names)
+
+			// Schemas of other files are bundled into the components, named
+			// after their files, and the root's own components keep their names
+			schemas := make([]string, 0, len(result.Schemas))
+			for name := range result.Schemas {
+				schemas = append(schemas, name)
+			}
+			sort.Strings(schemas)
+			assert.Equal(t, []string{"Error", "archive_pet", "owner", "pet"}, schemas)
+			assert.Contains(t, result.Schemas["pet"]["properties"], "owner")
+			assert.Contains(t, result.Schemas["archive_pet"]["properties"], "archived_at")
+		})
+	}
+}
+
+func TestOpenAPIMultiFileSpecs_Reload(t *testing.T) {
+	dir := writeMultiFileSpec(t)
+	source := SpecSource{ID: "kennel", Type: SpecTypeOpenAPI, Path: filepath.Join(dir, "openapi.yaml")}
+	importer := NewOpenAPIImporter()
+	_, err := importer.Import(context.Background(), source)
+	require.NoError(t, err)
+
+	// Edits to referenced files show on the next import
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "owner.yaml"), []byte("type: object\nproperties:\n  email: {type: string}\n"), 0o644))
+	result, err := importer.Import(context.Background(), source)
+	require.NoError(t, err)
+	assert.Contains(t, result.Schemas["owner"]["properties"], "email")
+
+	// A missing file fails the import
+	require.NoError(t, os.Remove(filepath.Join(dir, "parameters.yaml")))
+	_, err = importer.Import(context.Background(), source)
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// CollisionPolicy decides what an import does with tool names that another
+// source or the server already registers
+type CollisionPolicy string
+
+const (
+	CollisionNewestWins CollisionPolicy = "newest_wins" // The import replaces the existing tool
+	CollisionReject     CollisionPolicy = "reject"      // The existing tool stays; the import skips its own
+	CollisionSuffix     CollisionPolicy = "suffix"      // The import registers its tool with the source ID appended
+)
+
+// namespacePattern matches namespaces: dot-separated words of letters,
+// digits, underscores and hyphens
+var namespacePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// suffixInvalidChars are replaced when a source ID becomes a name suffix
+var suffixInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Validate checks the policy is known; empty selects the default
+func (p CollisionPolicy) Validate() error {
+	switch p {
+	case "", CollisionNewestWins, CollisionReject, CollisionSuffix:
+		return nil
+	}
+	return fmt.Errorf("invalid collision policy %q: must be newest_wins, reject or suffix", p)
+}
+
+// validateNamespace checks the namespace prefix of a source
+func validateNamespace(namespace string) error {
+	if namespace != "" && !namespacePattern.MatchString(namespace) {
+		return fmt.Errorf("invalid namespace %q: use letters, digits, underscores and hyphens separated by dots", namespace)
+	}
+	return nil
+}
+
+// SetCollisionPolicy sets the collision policy of sources that do not
+// declare their own
+func (m *ImporterManager) SetCollisionPolicy(policy CollisionPolicy) {
+	m.collisions = policy
+}
+
+// collisionPolicyFor returns the collision policy of a source
+func (m *ImporterManager) collisionPolicyFor(source SpecSource) CollisionPolicy {
+	policy := m.collisions
+	if source.CollisionPolicy != "" {
+		policy = source.CollisionPolicy
+	}
+	if policy == "" {
+		return CollisionNewestWins
+	}
+	return policy
+}
+
+// renameTools gives tools new names, keeping the importer's name as the
+// original so deprecations and previews still refer to it
+func renameTools(tools []types.Tool, rename func(tool types.Tool) string) []types.Tool {
+	renames := make(map[string]string, len(tools))
+	originals := make([]types.Tool, len(tools))
+	for i, tool := range tools {
+		originals[i] = tool
+		if named, ok := tool.(*namedTool); ok {
+			originals[i] = named.Tool
+		}
+		renames[originals[i].Name()] = rename(tool)
+	}
+	renamed := make([]types.Tool, len(tools))
+	for i, original := range originals {
+		renamed[i] = &namedTool{Tool: original, name: renames[original.Name()], renames: renames}
+	}
+	return renamed
+}
+
+// applyNamespace prefixes the names of a source's tools with its namespace
+func applyNamespace(namespace string, tools []types.Tool) []types.Tool {
+	if namespace == "" {
+		return tools
+	}
+	return renameTools(tools, func(tool types.Tool) string {
+		return namespace + "." + tool.Name()
+	})
+}
+
+// resolveCollisions applies the source's collision policy to the tools of an
+// import whose names another source or the server registers, recording a
+// warning for each tool it skips or renames. It returns the tools and how
+// many of them call the API.
+func (m *ImporterManager) resolveCollisions(source SpecSource, tools []types.Tool, apiTools int, result *ImportResult) ([]types.Tool, int) {
+	names := make([]string, len(tools))
+	taken := make(map[string]bool, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name()
+		taken[tool.Name()] = true
+	}
+	conflicts := m.nameConflicts(source.ID, names)
+	if len(conflicts) == 0 {
+		return tools, apiTools
+	}
+	owners := make(map[string]string, len(conflicts))
+	for _, conflict := range conflicts {
+		owners[conflict.ToolName] = conflict.Owner
+	}
+
+	switch m.collisionPolicyFor(source) {
+	case CollisionReject:
+		kept := make([]types.Tool, 0, len(tools))
+		remainingAPITools := apiTools
+		for i, tool := range tools {
+			if owner, conflicting := owners[tool.Name()]; conflicting {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("tool %s is already registered by %s and was not imported", tool.Name(), owner))
+				if i < apiTools {
+					remainingAPITools--
+				}
+				continue
+			}
+			kept = append(kept, tool)
+		}
+		return kept, remainingAPITools
+
+	case CollisionSuffix:
+		suffix := "_" + suffixInvalidChars.ReplaceAllString(source.ID, "_")
+		renamed := renameTools(tools, func(tool types.Tool) string {
+			owner, conflicting := owners[tool.Name()]
+			if !conflicting {
+				return tool.Name()
+			}
+			name := tool.Name() + suffix
+			for n := 2; taken[name] || len(m.nameConflicts(source.ID, []string{name})) > 0; n++ {
+				name = fmt.Sprintf("%s%s_%d", tool.Name(), suffix, n)
+			}
+			taken[name] = true
+			result.Warnings = append(result.Warnings, fmt.Sprintf("tool %s is already registered by %s; imported as %s", tool.Name(), owner, name))
+			return name
+		})
+		return renamed, apiTools
+
+	default:
+		// Replacing is only reported when an import registers the tools
+		return tools, apiTools
+	}
+}
+
+// takeOverTools removes tools an import replaced from the catalogs of the
+// sources that registered them, so removing those sources later leaves the
+// replacements registered
+func (m *ImporterManager) takeOverTools(sourceID string, tools []types.Tool) {
+	names := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		names[tool.Name()] = true
+	}
+	m.catalogMu.Lock()
+	defer m.catalogMu.Unlock()
+	for owner, catalog := range m.catalogs {
+		if owner == sourceID {
+			continue
+		}
+		kept := make([]types.ToolMetadata, 0, len(catalog))
+		for _, metadata := range catalog {
+			if !names[metadata.Name] {
+				kept = append(kept, metadata)
+			}
+		}
+		m.catalogs[owner] = kept
+	}
+}
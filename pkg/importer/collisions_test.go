@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolNames lists the names of tools in order
+func toolNames(tools []types.Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name()
+	}
+	return names
+}
+
+func TestImporterManager_CollisionPolicies(t *testing.T) {
+	ctx := context.Background()
+	registry := lookupRegistry{mapRegistry{}}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(stubImporter{})
+
+	// The server registers one name the users source generates, and a
+	// legacy source the other
+	server := stubTool{name: "openapi.users.get"}
+	legacy := stubTool{name: "openapi.users.list"}
+	registry.mapRegistry[server.name] = server
+	registry.mapRegistry[legacy.name] = legacy
+	manager.sources["legacy"] = SpecSource{ID: "legacy", Type: SpecTypeOpenAPI}
+	manager.catalogs["legacy"] = []types.ToolMetadata{legacy.Metadata()}
+
+	// Rejected tools are skipped and the existing ones stay
+	manager.SetCollisionPolicy(CollisionReject)
+	result, err := manager.ImportSpec(ctx, SpecSource{ID: "users", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	assert.Empty(t, result.Tools)
+	assert.Equal(t, []string{
+		"tool openapi.users.list is already registered by legacy and was not imported",
+		"tool openapi.users.get is already registered by server and was not imported",
+	}, result.Warnings)
+	assert.Equal(t, server, registry.mapRegistry[server.name])
+	assert.Equal(t, legacy, registry.mapRegistry[legacy.name])
+	require.NoError(t, manager.RemoveSpec(ctx, "users"))
+
+	// Suffixed tools are registered under the source ID; the source's policy
+	// overrides the manager's
+	result, err = manager.ImportSpec(ctx, SpecSource{ID: "users", Type: SpecTypeOpenAPI, CollisionPolicy: CollisionSuffix})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openapi.users.list_users", "openapi.users.get_users"}, toolNames(result.Tools))
+	assert.Contains(t, result.Warnings, "tool openapi.users.get is already registered by server; imported as openapi.users.get_users")
+	assert.Contains(t, registry.mapRegistry, "openapi.users.get_users")
+	assert.Equal(t, server, registry.mapRegistry[server.name])
+	require.NoError(t, manager.RemoveSpec(ctx, "users"))
+	assert.NotContains(t, registry.mapRegistry, "openapi.users.get_users")
+
+	// The newest import replaces the tools and owns them from then on
+	result, err = manager.ImportSpec(ctx, SpecSource{ID: "users", Type: SpecTypeOpenAPI, CollisionPolicy: CollisionNewestWins})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openapi.users.list", "openapi.users.get"}, toolNames(result.Tools))
+	assert.Equal(t, []string{
+		"tool openapi.users.list registered by legacy is replaced by this import",
+		"tool openapi.users.get registered by server is replaced by this import",
+	}, result.Warnings)
+	assert.NotEqual(t, legacy, registry.mapRegistry[legacy.name])
+	require.NoError(t, manager.RemoveSpec(ctx, "legacy"))
+	assert.Contains(t, registry.mapRegistry, "openapi.users.list", "removing the replaced source keeps the replacement")
+
+	// Unknown policies fail the import
+	_, err = manager.ImportSpec(ctx, SpecSource{ID: "orders", Type: SpecTypeOpenAPI, CollisionPolicy: "oldest_wins"})
+	assert.ErrorContains(t, err, `invalid collision policy "oldest_wins"`)
+}
+
+func TestImporterManager_Namespace(t *testing.T) {
+	ctx := context.Background()
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(stubImporter{})
+
+	// Namespaces prefix the names after the naming strategy applies
+	source := SpecSource{ID: "billing", Type: SpecTypeOpenAPI, Namespace: "payments.eu", Naming: &NamingStrategy{Case: NameCaseCamel}}
+	result, err := manager.ImportSpec(ctx, source)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payments.eu.openapi.billing.list", "payments.eu.openapi.billing.get"}, toolNames(result.Tools))
+	assert.Contains(t, registry, "payments.eu.openapi.billing.get")
+
+	// Previews show the importer's name as the original
+	preview, err := manager.PreviewSpec(ctx, source)
+	require.NoError(t, err)
+	assert.Equal(t, "openapi.billing.get", preview.Tools[0].OriginalName)
+
+	require.NoError(t, manager.RemoveSpec(ctx, "billing"))
+	assert.Empty(t, registry)
+
+	_, err = manager.ImportSpec(ctx, SpecSource{ID: "billing", Type: SpecTypeOpenAPI, Namespace: "pay ments"})
+	assert.ErrorContains(t, err, `invalid namespace "pay ments"`)
+}
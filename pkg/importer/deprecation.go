@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/graphql-go/graphql/language/ast"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// OpenAPI vendor extensions that refine a deprecated operation
+const (
+	extensionSunset      = "x-sunset"      // Planned removal date
+	extensionReplacement = "x-replacement" // operationId or tool name of the successor
+)
+
+// defaultGraphQLDeprecationReason is the reason GraphQL assigns to @deprecated without one
+const defaultGraphQLDeprecationReason = "No longer supported"
+
+// openAPIDeprecation reads the deprecated flag of an operation together with
+// its x-sunset and x-replacement extensions
+func openAPIDeprecation(sourceID string, operation *openapi3.Operation) *types.Deprecation {
+	if !operation.Deprecated {
+		return nil
+	}
+
+	deprecation := &types.Deprecation{Reason: "Operation is deprecated in the OpenAPI specification"}
+	if sunset, ok := operation.Extensions[extensionSunset].(string); ok {
+		if parsed, err := types.ParseDeprecationDate(sunset); err == nil {
+			deprecation.Sunset = parsed
+		}
+	}
+	if replacement, ok := operation.Extensions[extensionReplacement].(string); ok && replacement != "" {
+		// A bare operationId refers to an operation of the same spec
+		if !strings.Contains(replacement, ".") {
+			replacement = fmt.Sprintf("openapi.%s.%s", sourceID, replacement)
+		}
+		deprecation.Replacement = replacement
+	}
+	return deprecation
+}
+
+// graphQLDeprecation reads the @deprecated directive of a field
+func graphQLDeprecation(field *ast.FieldDefinition) *types.Deprecation {
+	for _, directive := range field.Directives {
+		if directive.Name == nil || directive.Name.Value != "deprecated" {
+			continue
+		}
+		deprecation := &types.Deprecation{Reason: defaultGraphQLDeprecationReason}
+		for _, argument := range directive.Arguments {
+			if argument.Name == nil || argument.Name.Value != "reason" || argument.Value == nil {
+				continue
+			}
+			if reason, ok := argument.Value.GetValue().(string); ok && reason != "" {
+				deprecation.Reason = reason
+			}
+		}
+		return deprecation
+	}
+	return nil
+}
+
+// grpcDeprecation reads the deprecated option of an RPC or its service
+func grpcDeprecation(method protoreflect.MethodDescriptor) *types.Deprecation {
+	if options, ok := method.Options().(*descriptorpb.MethodOptions); ok && options.GetDeprecated() {
+		return &types.Deprecation{Reason: "RPC is deprecated in the protobuf definition"}
+	}
+	if service, ok := method.Parent().(protoreflect.ServiceDescriptor); ok {
+		if options, ok := service.Options().(*descriptorpb.ServiceOptions); ok && options.GetDeprecated() {
+			return &types.Deprecation{Reason: "Service is deprecated in the protobuf definition"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDeprecation(t *testing.T) {
+	assert.Nil(t, openAPIDeprecation("petstore", &openapi3.Operation{OperationID: "listPets"}))
+
+	operation := &openapi3.Operation{
+		OperationID: "listPets",
+		Deprecated:  true,
+		Extensions: map[string]interface{}{
+			"x-sunset":      "2025-06-30",
+			"x-replacement": "searchPets",
+		},
+	}
+	deprecation := openAPIDeprecation("petstore", operation)
+	require.NotNil(t, deprecation)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), *deprecation.Sunset)
+	assert.Equal(t, "openapi.petstore.searchPets", deprecation.Replacement)
+	assert.NotEmpty(t, deprecation.Reason)
+
+	// Full tool names are kept as given; invalid dates are ignored
+	operation.Extensions = map[string]interface{}{"x-sunset": "soon", "x-replacement": "graphql.pets.query_pets"}
+	deprecation = openAPIDeprecation("petstore", operation)
+	assert.Nil(t, deprecation.Sunset)
+	assert.Equal(t, "graphql.pets.query_pets", deprecation.Replacement)
+}
+
+func TestGraphQLDeprecation(t *testing.T) {
+	doc, err := parser.Parse(parser.ParseParams{Source: `
+type Query {
+  pets: [String]
+  animals: [String] @deprecated(reason: "Use pets")
+  legacy: [String] @deprecated
+}`})
+	require.NoError(t, err)
+
+	fields := make(map[string]*ast.FieldDefinition)
+	for _, field := range doc.Definitions[0].(*ast.ObjectDefinition).Fields {
+		fields[field.Name.Value] = field
+	}
+
+	assert.Nil(t, graphQLDeprecation(fields["pets"]))
+	assert.Equal(t, "Use pets", graphQLDeprecation(fields["animals"]).Reason)
+	assert.Equal(t, defaultGraphQLDeprecationReason, graphQLDeprecation(fields["legacy"]).Reason)
+}
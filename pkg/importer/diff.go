@@ -0,0 +1,224 @@
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// ToolChangeKind represents how a tool changed between two imports of a source
+type ToolChangeKind string
+
+const (
+	ToolChangeAdded   ToolChangeKind = "added"
+	ToolChangeRemoved ToolChangeKind = "removed"
+	ToolChangeChanged ToolChangeKind = "changed"
+)
+
+// ToolChange describes the change to a single tool
+type ToolChange struct {
+	ToolName string         `json:"tool_name"`
+	Kind     ToolChangeKind `json:"kind"`
+	Breaking bool           `json:"breaking"`
+	Details  []string       `json:"details,omitempty"`
+}
+
+// ToolCatalogDiff describes the tool-level differences produced by a spec reload
+type ToolCatalogDiff struct {
+	SourceID  string       `json:"source_id"`
+	Timestamp time.Time    `json:"timestamp"`
+	Changes   []ToolChange `json:"changes"`
+}
+
+// HasChanges reports whether the diff contains any tool changes
+func (d ToolCatalogDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// HasBreakingChanges reports whether any change in the diff is breaking
+func (d ToolCatalogDiff) HasBreakingChanges() bool {
+	for _, change := range d.Changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffToolCatalogs compares two tool catalogs for the same source.
+// Removed tools, removed input properties, newly required inputs and input
+// type changes are flagged as breaking.
+func DiffToolCatalogs(sourceID string, before, after []types.ToolMetadata) ToolCatalogDiff {
+	diff := ToolCatalogDiff{
+		SourceID:  sourceID,
+		Timestamp: time.Now(),
+		Changes:   []ToolChange{},
+	}
+
+	previous := make(map[string]types.ToolMetadata, len(before))
+	for _, metadata := range before {
+		previous[metadata.Name] = metadata
+	}
+	current := make(map[string]types.ToolMetadata, len(after))
+	for _, metadata := range after {
+		current[metadata.Name] = metadata
+	}
+
+	for name, metadata := range current {
+		old, exists := previous[name]
+		if !exists {
+			diff.Changes = append(diff.Changes, ToolChange{
+				ToolName: name,
+				Kind:     ToolChangeAdded,
+			})
+			continue
+		}
+
+		if change, changed := diffTool(old, metadata); changed {
+			diff.Changes = append(diff.Changes, change)
+		}
+	}
+
+	for name := range previous {
+		if _, exists := current[name]; !exists {
+			diff.Changes = append(diff.Changes, ToolChange{
+				ToolName: name,
+				Kind:     ToolChangeRemoved,
+				Breaking: true,
+			})
+		}
+	}
+
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].ToolName < diff.Changes[j].ToolName
+	})
+
+	return diff
+}
+
+// diffTool compares two versions of the same tool
+func diffTool(before, after types.ToolMetadata) (ToolChange, bool) {
+	change := ToolChange{
+		ToolName: after.Name,
+		Kind:     ToolChangeChanged,
+	}
+
+	if before.Description != after.Description {
+		change.Details = append(change.Details, "description changed")
+	}
+	if before.Version != after.Version {
+		change.Details = append(change.Details, fmt.Sprintf("version changed from %q to %q", before.Version, after.Version))
+	}
+
+	beforeInput := schemaSection(before.Schema, "input")
+	afterInput := schemaSection(after.Schema, "input")
+
+	beforeProps := schemaProperties(beforeInput)
+	afterProps := schemaProperties(afterInput)
+	beforeRequired := schemaRequired(beforeInput)
+	afterRequired := schemaRequired(afterInput)
+
+	for _, name := range sortedKeys(beforeProps) {
+		afterProp, exists := afterProps[name]
+		if !exists {
+			change.Breaking = true
+			change.Details = append(change.Details, fmt.Sprintf("input parameter %q removed", name))
+			continue
+		}
+		beforeType := schemaType(beforeProps[name])
+		afterType := schemaType(afterProp)
+		if beforeType != afterType {
+			change.Breaking = true
+			change.Details = append(change.Details, fmt.Sprintf("input parameter %q type changed from %q to %q", name, beforeType, afterType))
+		}
+	}
+
+	for _, name := range sortedKeys(afterProps) {
+		if _, exists := beforeProps[name]; exists {
+			continue
+		}
+		if afterRequired[name] {
+			change.Breaking = true
+			change.Details = append(change.Details, fmt.Sprintf("required input parameter %q added", name))
+		} else {
+			change.Details = append(change.Details, fmt.Sprintf("optional input parameter %q added", name))
+		}
+	}
+
+	for _, name := range sortedKeys(afterProps) {
+		if _, existed := beforeProps[name]; existed && afterRequired[name] && !beforeRequired[name] {
+			change.Breaking = true
+			change.Details = append(change.Details, fmt.Sprintf("input parameter %q is now required", name))
+		}
+	}
+
+	if !reflect.DeepEqual(schemaSection(before.Schema, "output"), schemaSection(after.Schema, "output")) {
+		change.Details = append(change.Details, "output schema changed")
+	}
+
+	return change, len(change.Details) > 0
+}
+
+// schemaSection returns a named sub-schema (input/output) from tool metadata
+func schemaSection(schema map[string]any, key string) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	section, _ := schema[key].(map[string]any)
+	return section
+}
+
+// schemaProperties returns the properties of an object schema
+func schemaProperties(schema map[string]any) map[string]any {
+	if schema == nil {
+		return map[string]any{}
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	if properties == nil {
+		return map[string]any{}
+	}
+	return properties
+}
+
+// schemaRequired returns the required property names of an object schema
+func schemaRequired(schema map[string]any) map[string]bool {
+	required := make(map[string]bool)
+	if schema == nil {
+		return required
+	}
+	switch names := schema["required"].(type) {
+	case []string:
+		for _, name := range names {
+			required[name] = true
+		}
+	case []any:
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	return required
+}
+
+// schemaType returns the declared JSON Schema type of a property
+func schemaType(property any) string {
+	if prop, ok := property.(map[string]any); ok {
+		if t, ok := prop["type"].(string); ok {
+			return t
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
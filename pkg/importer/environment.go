@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// SourceEnvironment is one deployment of a source's API, such as dev,
+// staging or prod. Tools call the environment's base URL instead of the
+// servers the spec lists.
+type SourceEnvironment struct {
+	BaseURL string            `json:"base_url,omitempty"` // Replaces the OpenAPI server URL or the GraphQL endpoint
+	Headers map[string]string `json:"headers,omitempty"`  // Sent with every call, masked when serialized
+	TLS     *EnvironmentTLS   `json:"tls,omitempty"`
+}
+
+// EnvironmentTLS configures the TLS connections to an environment
+type EnvironmentTLS struct {
+	CAFile             string `json:"ca_file,omitempty"`              // PEM bundle trusted instead of the system roots
+	CertFile           string `json:"cert_file,omitempty"`            // Client certificate for mutual TLS
+	KeyFile            string `json:"key_file,omitempty"`             // Key of the client certificate
+	ServerName         string `json:"server_name,omitempty"`          // Overrides the name the certificate is verified against
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // Accepts any certificate; for development only
+}
+
+// MarshalJSON masks the header values, which often carry credentials
+func (e SourceEnvironment) MarshalJSON() ([]byte, error) {
+	type plain SourceEnvironment
+	masked := plain(e)
+	if len(e.Headers) > 0 {
+		masked.Headers = make(map[string]string, len(e.Headers))
+		for name := range e.Headers {
+			masked.Headers[name] = maskedCredential
+		}
+	}
+	return json.Marshal(masked)
+}
+
+// environment is a SourceEnvironment ready for calls
+type environment struct {
+	name    string
+	baseURL string
+	headers http.Header
+	client  *http.Client
+}
+
+// sourceEnvironments holds the environments of a source and its default
+type sourceEnvironments struct {
+	sourceID    string
+	defaultName string
+	byName      map[string]*environment
+}
+
+// loadEnvironments prepares the environments of a source, loading their TLS
+// files. It fails when the default environment is not defined.
+func loadEnvironments(source SpecSource) (*sourceEnvironments, error) {
+	environments := &sourceEnvironments{
+		sourceID:    source.ID,
+		defaultName: source.Environment,
+		byName:      make(map[string]*environment, len(source.Environments)),
+	}
+	for name, definition := range source.Environments {
+		if definition == nil {
+			return nil, fmt.Errorf("environment %s is empty", name)
+		}
+		client := &http.Client{}
+		if definition.TLS != nil {
+			config, err := definition.TLS.config()
+			if err != nil {
+				return nil, fmt.Errorf("environment %s: %w", name, err)
+			}
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = config
+			client.Transport = transport
+		}
+		header := http.Header{}
+		for key, value := range definition.Headers {
+			header.Set(key, value)
+		}
+		environments.byName[name] = &environment{name: name, baseURL: definition.BaseURL, headers: header, client: client}
+	}
+	if source.Environment != "" && environments.byName[source.Environment] == nil {
+		return nil, fmt.Errorf("default environment %s is not defined; environments: %v", source.Environment, environments.names())
+	}
+	return environments, nil
+}
+
+// config builds the TLS client configuration
+func (t *EnvironmentTLS) config() (*tls.Config, error) {
+	config := &tls.Config{ServerName: t.ServerName, InsecureSkipVerify: t.InsecureSkipVerify}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %s contains no certificates", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// names lists the defined environments in order
+func (e *sourceEnvironments) names() []string {
+	names := make([]string, 0, len(e.byName))
+	for name := range e.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selected returns the environment an invocation asks for in its context,
+// or the source's default. Without either it returns nil and tools call the
+// servers of the spec.
+func (e *sourceEnvironments) selected(ctx context.Context) (*environment, error) {
+	if e == nil {
+		return nil, nil
+	}
+	name := types.InvocationContext(ctx)[types.ContextEnvironment]
+	if name == "" {
+		name = e.defaultName
+	}
+	if name == "" {
+		return nil, nil
+	}
+	selected, ok := e.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("source %s has no environment %s; environments: %v", e.sourceID, name, e.names())
+	}
+	return selected, nil
+}
@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// environmentSpec lists a server tools must not call once an environment is selected
+const environmentSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Deployments", "version": "1.0.0"},
+  "servers": [{"url": "%s"}],
+  "paths": {"/whoami": {"get": {"operationId": "whoami", "responses": {"200": {"description": "OK"}}}}}
+}`
+
+// deployment answers with its name and the tenant header it received
+func deployment(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"deployment": name, "tenant": r.Header.Get("X-Tenant")})
+	}
+}
+
+func TestOpenAPIEnvironments(t *testing.T) {
+	spec := httptest.NewServer(deployment("spec"))
+	defer spec.Close()
+	dev := httptest.NewServer(deployment("dev"))
+	defer dev.Close()
+	prod := httptest.NewTLSServer(deployment("prod"))
+	defer prod.Close()
+
+	// Trust the prod server through a CA file
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: prod.Certificate().Raw}), 0o644))
+	path := filepath.Join(dir, "deployments.json")
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(environmentSpec, spec.URL)), 0o644))
+
+	source := SpecSource{ID: "deployments", Type: SpecTypeOpenAPI, Path: path, Environment: "dev", Environments: map[string]*SourceEnvironment{
+		"dev":  {BaseURL: dev.URL, Headers: map[string]string{"X-Tenant": "sandbox"}},
+		"prod": {BaseURL: prod.URL, TLS: &EnvironmentTLS{CAFile: caFile}},
+	}}
+	result, err := NewOpenAPIImporter().Import(context.Background(), source)
+	require.NoError(t, err)
+	require.Len(t, result.Tools, 1)
+	tool := result.Tools[0]
+
+	call := func(ctx context.Context) map[string]interface{} {
+		output, err := tool.Execute(ctx, map[string]interface{}{})
+		require.NoError(t, err)
+		return output.(map[string]interface{})
+	}
+
+	// The default environment replaces the spec's server
+	output := call(context.Background())
+	assert.Equal(t, map[string]interface{}{"deployment": "dev", "tenant": "sandbox"}, output["body"])
+	assert.Equal(t, "dev", output["environment"])
+
+	// Invocations select another environment through their context
+	output = call(types.WithInvocationContext(context.Background(), map[string]string{types.ContextEnvironment: "prod"}))
+	assert.Equal(t, map[string]interface{}{"deployment": "prod", "tenant": ""}, output["body"])
+	assert.Equal(t, "prod", output["environment"])
+
+	_, err = tool.Execute(types.WithInvocationContext(context.Background(), map[string]string{types.ContextEnvironment: "qa"}), map[string]interface{}{})
+	assert.ErrorContains(t, err, "source deployments has no environment qa; environments: [dev prod]")
+
+	// Without a default the spec's server is called
+	source.Environment = ""
+	result, err = NewOpenAPIImporter().Import(context.Background(), source)
+	require.NoError(t, err)
+	tool = result.Tools[0]
+	output = call(context.Background())
+	assert.Equal(t, "spec", output["body"].(map[string]interface{})["deployment"])
+	assert.NotContains(t, output, "environment")
+
+	// Undefined defaults and unreadable TLS files fail the import
+	source.Environment = "staging"
+	_, err = NewOpenAPIImporter().Import(context.Background(), source)
+	assert.ErrorContains(t, err, "default environment staging is not defined")
+	source.Environment = ""
+	source.Environments["prod"].TLS.CAFile = filepath.Join(dir, "missing.pem")
+	_, err = NewOpenAPIImporter().Import(context.Background(), source)
+	assert.ErrorContains(t, err, "environment prod: failed to read CA file")
+}
+
+func TestGraphQLEnvironments(t *testing.T) {
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": {"hello": %q}}`, r.Header.Get("X-Tenant"))
+	}))
+	defer staging.Close()
+
+	path := filepath.Join(t.TempDir(), "schema.graphql")
+	require.NoError(t, os.WriteFile(path, []byte("type Query { hello: String }"), 0o644))
+
+	// Sources with environments need no endpoint
+	source := SpecSource{ID: "greeter", Type: SpecTypeGraphQL, Path: path, Environments: map[string]*SourceEnvironment{
+		"staging": {BaseURL: staging.URL, Headers: map[string]string{"X-Tenant": "acme"}},
+	}}
+	result, err := NewGraphQLImporter().Import(context.Background(), source)
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+	require.Len(t, result.Tools, 1)
+
+	_, err = result.Tools[0].Execute(context.Background(), map[string]interface{}{})
+	assert.ErrorContains(t, err, "source greeter has no GraphQL endpoint")
+
+	ctx := types.WithInvocationContext(context.Background(), map[string]string{types.ContextEnvironment: "staging"})
+	output, err := result.Tools[0].Execute(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hello": "acme"}, output.(map[string]interface{})["data"])
+	assert.Equal(t, staging.URL, output.(map[string]interface{})["endpoint"])
+}
+
+func TestSourceEnvironment_MarshalJSON(t *testing.T) {
+	environment := &SourceEnvironment{BaseURL: "https://api.example.com", Headers: map[string]string{"Authorization": "Bearer s3cret"}}
+	data, err := json.Marshal(SpecSource{ID: "api", Environment: "prod", Environments: map[string]*SourceEnvironment{"prod": environment}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+	assert.Contains(t, string(data), `"base_url":"https://api.example.com"`)
+	assert.Equal(t, "Bearer s3cret", environment.Headers["Authorization"])
+}
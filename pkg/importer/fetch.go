@@ -0,0 +1,332 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SpecAuthType selects how a remote specification is authenticated
+type SpecAuthType string
+
+const (
+	SpecAuthBearer SpecAuthType = "bearer"  // Authorization: Bearer <token>
+	SpecAuthBasic  SpecAuthType = "basic"   // Authorization: Basic <username:password>
+	SpecAuthAPIKey SpecAuthType = "api_key" // <header>: <value>
+)
+
+// DefaultAPIKeyHeader carries API keys when a source does not name its header
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// maskedCredential replaces credentials when sources are serialized
+const maskedCredential = "********"
+
+// SpecAuth authenticates the download of a specification from a URL.
+// Credentials are masked when the source is serialized, so listing sources
+// does not reveal them.
+type SpecAuth struct {
+	Type     SpecAuthType      `json:"type"`
+	Token    string            `json:"token,omitempty"`    // Bearer token
+	Username string            `json:"username,omitempty"` // Basic auth user
+	Password string            `json:"password,omitempty"` // Basic auth password
+	Header   string            `json:"header,omitempty"`   // API key header, DefaultAPIKeyHeader when empty
+	Value    string            `json:"value,omitempty"`    // API key
+	Headers  map[string]string `json:"headers,omitempty"`  // Further headers to send, e.g. a tenant
+}
+
+// MarshalJSON masks the credentials
+func (a SpecAuth) MarshalJSON() ([]byte, error) {
+	type plain SpecAuth
+	masked := plain(a)
+	for _, secret := range []*string{&masked.Token, &masked.Password, &masked.Value} {
+		if *secret != "" {
+			*secret = maskedCredential
+		}
+	}
+	if len(a.Headers) > 0 {
+		masked.Headers = make(map[string]string, len(a.Headers))
+		for name := range a.Headers {
+			masked.Headers[name] = maskedCredential
+		}
+	}
+	return json.Marshal(masked)
+}
+
+// validate checks the credentials the auth type needs are present
+func (a *SpecAuth) validate() error {
+	switch a.Type {
+	case SpecAuthBearer:
+		if a.Token == "" {
+			return fmt.Errorf("bearer auth requires a token")
+		}
+	case SpecAuthBasic:
+		if a.Username == "" {
+			return fmt.Errorf("basic auth requires a username")
+		}
+	case SpecAuthAPIKey:
+		if a.Value == "" {
+			return fmt.Errorf("api_key auth requires a value")
+		}
+	case "":
+		if len(a.Headers) == 0 {
+			return fmt.Errorf("auth requires a type or headers")
+		}
+	default:
+		return fmt.Errorf("unsupported auth type %q: use bearer, basic or api_key", a.Type)
+	}
+	return nil
+}
+
+// apply sets the authentication headers on a request
+func (a *SpecAuth) apply(header http.Header) {
+	for name, value := range a.Headers {
+		header.Set(name, value)
+	}
+	switch a.Type {
+	case SpecAuthBearer:
+		header.Set("Authorization", "Bearer "+a.Token)
+	case SpecAuthBasic:
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password)))
+	case SpecAuthAPIKey:
+		name := a.Header
+		if name == "" {
+			name = DefaultAPIKeyHeader
+		}
+		header.Set(name, a.Value)
+	}
+}
+
+// strip removes the authentication headers from a request
+func (a *SpecAuth) strip(header http.Header) {
+	for name := range a.Headers {
+		header.Del(name)
+	}
+	header.Del("Authorization")
+	if a.Header != "" {
+		header.Del(a.Header)
+	}
+	header.Del(DefaultAPIKeyHeader)
+}
+
+const (
+	// DefaultFetchMaxBytes bounds the size of a downloaded specification
+	DefaultFetchMaxBytes = 10 << 20
+
+	// DefaultFetchMaxRedirects bounds the redirects followed to a specification
+	DefaultFetchMaxRedirects = 5
+
+	// DefaultFetchTimeout bounds the download of a specification
+	DefaultFetchTimeout = 30 * time.Second
+)
+
+// FetchPolicy bounds the download of specifications from URLs
+type FetchPolicy struct {
+	MaxBytes     int64         // Larger specifications are rejected; zero selects the default
+	MaxRedirects int           // Negative refuses redirects; zero selects the default
+	Timeout      time.Duration // Zero selects the default
+}
+
+// DefaultFetchPolicy returns the default download bounds
+func DefaultFetchPolicy() FetchPolicy {
+	return FetchPolicy{
+		MaxBytes:     DefaultFetchMaxBytes,
+		MaxRedirects: DefaultFetchMaxRedirects,
+		Timeout:      DefaultFetchTimeout,
+	}
+}
+
+// SpecFetcher downloads specifications from URLs, authenticating as the
+// source configures and within the bounds of its policy. Credentials are
+// only sent over HTTPS and are not forwarded when a redirect leaves the
+// original host.
+type SpecFetcher struct {
+	policy FetchPolicy
+	client *http.Client
+}
+
+// NewSpecFetcher creates a fetcher, filling unset bounds with the defaults
+func NewSpecFetcher(policy FetchPolicy) *SpecFetcher {
+	defaults := DefaultFetchPolicy()
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = defaults.MaxBytes
+	}
+	if policy.MaxRedirects == 0 {
+		policy.MaxRedirects = defaults.MaxRedirects
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaults.Timeout
+	}
+	return &SpecFetcher{policy: policy, client: &http.Client{Timeout: policy.Timeout}}
+}
+
+// Policy returns the bounds the fetcher enforces
+func (f *SpecFetcher) Policy() FetchPolicy {
+	return f.policy
+}
+
+// isRemoteSpec reports whether a specification path is a URL
+func isRemoteSpec(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// FetchResult is a downloaded specification with the validators the server
+// returned for it
+type FetchResult struct {
+	Content      []byte
+	ETag         string
+	LastModified string
+	NotModified  bool // The server confirmed the validators sent still match; Content is empty
+}
+
+// Fetch downloads the specification at location
+func (f *SpecFetcher) Fetch(ctx context.Context, location string, auth *SpecAuth) ([]byte, error) {
+	result, err := f.FetchIfChanged(ctx, location, auth, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Content, nil
+}
+
+// FetchIfChanged downloads the specification at location unless the server
+// confirms it still matches the ETag or Last-Modified validators from an
+// earlier fetch. Empty validators always download.
+func (f *SpecFetcher) FetchIfChanged(ctx context.Context, location string, auth *SpecAuth, etag, lastModified string) (*FetchResult, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json, application/yaml, text/yaml, */*")
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+	return f.do(ctx, http.MethodGet, location, auth, header, nil, etag != "" || lastModified != "")
+}
+
+// Post sends a JSON body to location, such as a GraphQL introspection query,
+// and returns the response within the same bounds as a download. The extra
+// headers are sent over plain HTTP too, but like the source's credentials
+// they are dropped when a redirect leaves the original host.
+func (f *SpecFetcher) Post(ctx context.Context, location string, auth *SpecAuth, extra http.Header, body []byte) ([]byte, error) {
+	header := extra.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	header.Set("Accept", "application/json")
+	result, err := f.do(ctx, http.MethodPost, location, auth, header, body, false)
+	if err != nil {
+		return nil, err
+	}
+	return result.Content, nil
+}
+
+// forwardedHeaders are the request headers kept when a redirect leaves the
+// original host; any other header may carry credentials
+var forwardedHeaders = map[string]bool{
+	"Accept":            true,
+	"Content-Type":      true,
+	"If-None-Match":     true,
+	"If-Modified-Since": true,
+}
+
+// do sends a request within the fetch bounds. conditional accepts a 304
+// answer to the validators in header.
+func (f *SpecFetcher) do(ctx context.Context, method, location string, auth *SpecAuth, header http.Header, body []byte, conditional bool) (*FetchResult, error) {
+	target, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if auth != nil {
+		if err := auth.validate(); err != nil {
+			return nil, fmt.Errorf("invalid spec auth: %w", err)
+		}
+		if target.Scheme != "https" {
+			return nil, fmt.Errorf("refusing to send credentials for %s over plain HTTP", target.Redacted())
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if auth != nil {
+		auth.apply(req.Header)
+	}
+
+	client := *f.client
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if len(via) > f.policy.MaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", f.policy.MaxRedirects)
+		}
+		sameHost := strings.EqualFold(next.URL.Host, via[0].URL.Host)
+		if auth != nil {
+			if next.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow redirect to %s with credentials over plain HTTP", next.URL.Redacted())
+			}
+			if !sameHost {
+				auth.strip(next.Header)
+			}
+		}
+		if !sameHost {
+			for name := range header {
+				if !forwardedHeaders[name] {
+					next.Header.Del(name)
+				}
+			}
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", target.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	result := &FetchResult{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if resp.StatusCode == http.StatusNotModified && conditional {
+		result.NotModified = true
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", target.Redacted(), resp.Status)
+	}
+	if resp.ContentLength > f.policy.MaxBytes {
+		return nil, fmt.Errorf("specification at %s is %d bytes, over the %d byte limit", target.Redacted(), resp.ContentLength, f.policy.MaxBytes)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, f.policy.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", target.Redacted(), err)
+	}
+	if int64(len(content)) > f.policy.MaxBytes {
+		return nil, fmt.Errorf("specification at %s is over the %d byte limit", target.Redacted(), f.policy.MaxBytes)
+	}
+	result.Content = content
+	return result, nil
+}
+
+// fetchingImporter is an importer that downloads specifications from URLs
+type fetchingImporter interface {
+	SetFetcher(fetcher *SpecFetcher)
+}
+
+// SetFetchPolicy bounds the download of specifications from URLs by every
+// registered importer and those registered later
+func (m *ImporterManager) SetFetchPolicy(policy FetchPolicy) {
+	m.fetcher = NewSpecFetcher(policy)
+	for _, importer := range m.importers {
+		if fetching, ok := importer.(fetchingImporter); ok {
+			fetching.SetFetcher(m.fetcher)
+		}
+	}
+}
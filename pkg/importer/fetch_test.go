@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecFetcher(t *testing.T) {
+	ctx := context.Background()
+
+	// Another host records whether credentials followed a redirect to it
+	var leaked string
+	other := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization") + r.Header.Get("X-Tenant")
+		w.Write([]byte(syntheticPetstore))
+	}))
+	defer other.Close()
+
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/petstore.json":
+			if r.Header.Get("Authorization") != "Bearer s3cret" || r.Header.Get("X-Tenant") != "acme" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(syntheticPetstore))
+		case "/moved.json":
+			http.Redirect(w, r, "/petstore.json", http.StatusFound)
+		case "/elsewhere.json":
+			http.Redirect(w, r, other.URL+"/petstore.json", http.StatusFound)
+		case "/keyed.json":
+			if r.Header.Get("X-Spec-Key") != "k-1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"asyncapi": "2.6.0", "channels": {"orders": {"subscribe": {"summary": "Order events"}}}}`))
+		case "/huge.json":
+			w.Write([]byte(strings.Repeat(" ", 2048)))
+		}
+	}))
+	defer upstream.Close()
+
+	fetcher := NewSpecFetcher(FetchPolicy{MaxBytes: 1024})
+	fetcher.client = upstream.Client()
+	auth := &SpecAuth{Type: SpecAuthBearer, Token: "s3cret", Headers: map[string]string{"X-Tenant": "acme"}}
+
+	// OpenAPI specs import from URLs with the source's credentials, through redirects
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	openAPI := NewOpenAPIImporter()
+	openAPI.SetFetcher(fetcher)
+	manager.RegisterImporter(openAPI)
+	result, err := manager.ImportSpec(ctx, SpecSource{ID: "petstore", Type: SpecTypeOpenAPI, Path: upstream.URL + "/moved.json", Auth: auth})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Tools)
+	assert.Contains(t, registry, "openapi.petstore.listPets")
+
+	_, err = fetcher.Fetch(ctx, upstream.URL+"/petstore.json", nil)
+	assert.ErrorContains(t, err, "401")
+
+	// Credentials are masked when sources are listed
+	listed, err := json.Marshal(manager.ListSources())
+	require.NoError(t, err)
+	assert.NotContains(t, string(listed), "s3cret")
+	assert.NotContains(t, string(listed), "acme")
+	assert.Contains(t, string(listed), `"type":"bearer"`)
+
+	// Credentials stay behind when a redirect leaves the host
+	_, err = fetcher.Fetch(ctx, upstream.URL+"/elsewhere.json", auth)
+	require.NoError(t, err)
+	assert.Empty(t, leaked)
+
+	// AsyncAPI specs import from URLs too, here with an API key
+	asyncAPI := NewAsyncAPIImporter()
+	asyncAPI.SetFetcher(fetcher)
+	result, err = asyncAPI.Import(ctx, SpecSource{ID: "orders", Type: SpecTypeAsyncAPI, Path: upstream.URL + "/keyed.json",
+		Auth: &SpecAuth{Type: SpecAuthAPIKey, Header: "X-Spec-Key", Value: "k-1"}})
+	require.NoError(t, err)
+	assert.Len(t, result.Tools, 1)
+
+	// Oversized specs, plain HTTP credentials, refused redirects and incomplete auth fail
+	_, err = fetcher.Fetch(ctx, upstream.URL+"/huge.json", nil)
+	assert.ErrorContains(t, err, "byte limit")
+	_, err = fetcher.Fetch(ctx, strings.Replace(upstream.URL, "https://", "http://", 1)+"/petstore.json", auth)
+	assert.ErrorContains(t, err, "plain HTTP")
+	noRedirects := NewSpecFetcher(FetchPolicy{MaxRedirects: -1})
+	noRedirects.client = upstream.Client()
+	_, err = noRedirects.Fetch(ctx, upstream.URL+"/moved.json", auth)
+	assert.ErrorContains(t, err, "redirects")
+	_, err = fetcher.Fetch(ctx, upstream.URL+"/petstore.json", &SpecAuth{Type: SpecAuthBasic})
+	assert.ErrorContains(t, err, "username")
+}
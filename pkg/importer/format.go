@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecFormat is the serialization of a specification document
+type SpecFormat string
+
+const (
+	SpecFormatJSON SpecFormat = "json"
+	SpecFormatYAML SpecFormat = "yaml"
+)
+
+// DetectSpecFormat determines the format of a specification document from
+// the extension of its path or URL, and from its content when the extension
+// does not say. Documents that do not open with a JSON object or array are
+// treated as YAML, of which JSON is a subset.
+func DetectSpecFormat(location string, content []byte) SpecFormat {
+	if isRemoteSpec(location) {
+		if parsed, err := url.Parse(location); err == nil {
+			location = parsed.Path
+		}
+	}
+	switch strings.ToLower(path.Ext(location)) {
+	case ".json":
+		return SpecFormatJSON
+	case ".yaml", ".yml":
+		return SpecFormatYAML
+	}
+
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return SpecFormatJSON
+	}
+	return SpecFormatYAML
+}
+
+// decodeSpecDocument parses a JSON or YAML specification document into the
+// values encoding/json would produce, so importers can walk either alike
+func decodeSpecDocument(location string, content []byte) (map[string]interface{}, error) {
+	var document map[string]interface{}
+	if DetectSpecFormat(location, content) == SpecFormatJSON {
+		if err := json.Unmarshal(content, &document); err != nil {
+			return nil, fmt.Errorf("invalid JSON format: %w", err)
+		}
+		return document, nil
+	}
+
+	var parsed interface{}
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid YAML format: %w", err)
+	}
+	// Round-trip through JSON so numbers, timestamps and non-string keys
+	// take the same types as in a JSON document
+	encoded, err := json.Marshal(jsonCompatible(parsed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML format: %w", err)
+	}
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		return nil, fmt.Errorf("invalid YAML format: document is not a mapping")
+	}
+	return document, nil
+}
+
+// jsonCompatible converts the maps YAML decodes with non-string keys, such
+// as response codes, into maps keyed by strings
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonCompatible(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonCompatible(item)
+		}
+		return v
+	}
+	return value
+}
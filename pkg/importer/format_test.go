@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSpecFormat(t *testing.T) {
+	// Extensions decide, then content is sniffed
+	assert.Equal(t, SpecFormatJSON, DetectSpecFormat("specs/events.json", []byte("asyncapi: 2.6.0")))
+	assert.Equal(t, SpecFormatYAML, DetectSpecFormat("specs/events.YML", []byte(`{"asyncapi": "2.6.0"}`)))
+	assert.Equal(t, SpecFormatYAML, DetectSpecFormat("https://specs.example.com/events.yaml?v=2", nil))
+	assert.Equal(t, SpecFormatJSON, DetectSpecFormat("https://specs.example.com/events", []byte("\xef\xbb\xbf\n  {\"asyncapi\": \"2.6.0\"}")))
+	assert.Equal(t, SpecFormatYAML, DetectSpecFormat("specs/events", []byte("asyncapi: 2.6.0")))
+}
+
+func TestAsyncAPIImporterYAML(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	importer := NewAsyncAPIImporter()
+
+	// The bundled example is YAML
+	source := SpecSource{ID: "events", Type: SpecTypeAsyncAPI, Path: filepath.Join("..", "..", "examples", "specs", "user-events.yaml")}
+	require.NoError(t, importer.Validate(ctx, source))
+	result, err := importer.Import(ctx, source)
+	require.NoError(t, err)
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name())
+	}
+	assert.Contains(t, names, "asyncapi.events.publish_user_register")
+	assert.Contains(t, names, "asyncapi.events.subscribe_notifications_global")
+
+	// Without an extension the format is sniffed, and integer keys become strings
+	path := filepath.Join(dir, "orders")
+	require.NoError(t, os.WriteFile(path, []byte("asyncapi: 2.6.0\nchannels:\n  orders:\n    subscribe:\n      summary: Order events\n      x-codes:\n        200: ok\n"), 0644))
+	result, err = importer.Import(ctx, SpecSource{ID: "orders", Type: SpecTypeAsyncAPI, Path: path})
+	require.NoError(t, err)
+	require.Len(t, result.Tools, 1)
+	assert.Equal(t, "asyncapi.orders.subscribe_orders", result.Tools[0].Name())
+
+	// Parse errors name the detected format
+	require.NoError(t, os.WriteFile(path, []byte("asyncapi: [2.6.0\n"), 0644))
+	assert.ErrorContains(t, importer.Validate(ctx, SpecSource{ID: "orders", Type: SpecTypeAsyncAPI, Path: path}), "invalid YAML format")
+	require.NoError(t, os.WriteFile(path+".json", []byte(`{"asyncapi": `), 0644))
+	assert.ErrorContains(t, importer.Validate(ctx, SpecSource{ID: "orders", Type: SpecTypeAsyncAPI, Path: path + ".json"}), "invalid JSON format")
+	require.NoError(t, os.WriteFile(path, []byte("channels: {}\n"), 0644))
+	assert.ErrorContains(t, importer.Validate(ctx, SpecSource{ID: "orders", Type: SpecTypeAsyncAPI, Path: path}), "missing required 'asyncapi' field")
+}
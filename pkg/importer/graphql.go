@@ -4,9 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,12 +17,28 @@ import (
 
 // GraphQLImporter handles GraphQL schemas
 type GraphQLImporter struct {
-	endpoint string // Default GraphQL endpoint
+	endpoint       string // Default GraphQL endpoint
+	fetcher        *SpecFetcher
+	selectionDepth int // Nested object levels selected by generated tools
 }
 
 // NewGraphQLImporter creates a new GraphQL importer
 func NewGraphQLImporter() *GraphQLImporter {
-	return &GraphQLImporter{}
+	return &GraphQLImporter{
+		fetcher:        NewSpecFetcher(DefaultFetchPolicy()),
+		selectionDepth: DefaultGraphQLSelectionDepth,
+	}
+}
+
+// SetSelectionDepth sets how many levels of nested objects generated tools
+// select, for sources whose selection_depth metadata does not override it
+func (i *GraphQLImporter) SetSelectionDepth(depth int) {
+	i.selectionDepth = depth
+}
+
+// SetFetcher sets how schemas are downloaded from URLs
+func (i *GraphQLImporter) SetFetcher(fetcher *SpecFetcher) {
+	i.fetcher = fetcher
 }
 
 // GetType returns the specification type
@@ -37,7 +53,7 @@ func (i *GraphQLImporter) Supports(source SpecSource) bool {
 
 // Validate checks if the GraphQL schema is valid
 func (i *GraphQLImporter) Validate(ctx context.Context, source SpecSource) error {
-	schemaString, err := i.loadSchema(source.Path)
+	schemaString, err := i.loadSchema(ctx, source)
 	if err != nil {
 		return err
 	}
@@ -61,8 +77,17 @@ func (i *GraphQLImporter) Import(ctx context.Context, source SpecSource) (*Impor
 		Timestamp: start,
 	}
 
+	// Prepare the environments tools call instead of the endpoint
+	environments, err := loadEnvironments(source)
+	if err != nil {
+		err = fmt.Errorf("invalid environments: %w", err)
+		result.Errors = append(result.Errors, err)
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
 	// Load the schema
-	schemaString, err := i.loadSchema(source.Path)
+	schemaString, err := i.loadSchema(ctx, source)
 	if err != nil {
 		result.Errors = append(result.Errors, err)
 		result.Duration = time.Since(start)
@@ -79,25 +104,44 @@ func (i *GraphQLImporter) Import(ctx context.Context, source SpecSource) (*Impor
 		return result, err
 	}
 
-	// Extract endpoint from metadata or use default
+	// Extract endpoint from metadata, the introspected endpoint or use default
 	endpoint := source.Metadata["endpoint"]
-	if endpoint == "" {
+	if endpoint == "" && introspects(source) {
+		endpoint = source.Path
+	}
+	if endpoint == "" && len(source.Environments) == 0 {
 		endpoint = "http://localhost:4000/graphql" // Default GraphQL endpoint
 		result.Warnings = append(result.Warnings, "No GraphQL endpoint specified in metadata, using default: "+endpoint)
 	}
 
+	// Select the returned fields down to the configured depth
+	depth := i.selectionDepth
+	if value, ok := source.Metadata[selectionDepthKey]; ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Invalid %s metadata %q, using %d", selectionDepthKey, value, depth))
+		} else {
+			depth = parsed
+		}
+	}
+	schemaTypes := indexGraphQLTypes(doc)
+	definitions := indexGraphQLDefinitions(doc)
+
 	// Generate tools from queries and mutations
+	queryType, mutationType := graphQLRootTypes(doc)
 	for _, def := range doc.Definitions {
 		if typeDef, ok := def.(*ast.ObjectDefinition); ok {
 			switch typeDef.Name.Value {
-			case "Query":
+			case queryType:
 				for _, field := range typeDef.Fields {
-					tool := i.createQueryTool(source, endpoint, field, schemaString)
+					selection := schemaTypes.selection(graphQLNamedType(field.Type), depth)
+					tool := i.createQueryTool(source, endpoint, environments, field, schemaString, selection, definitions, depth)
 					result.Tools = append(result.Tools, tool)
 				}
-			case "Mutation":
+			case mutationType:
 				for _, field := range typeDef.Fields {
-					tool := i.createMutationTool(source, endpoint, field, schemaString)
+					selection := schemaTypes.selection(graphQLNamedType(field.Type), depth)
+					tool := i.createMutationTool(source, endpoint, environments, field, schemaString, selection, definitions, depth)
 					result.Tools = append(result.Tools, tool)
 				}
 			}
@@ -108,26 +152,28 @@ func (i *GraphQLImporter) Import(ctx context.Context, source SpecSource) (*Impor
 	return result, nil
 }
 
-// loadSchema loads a GraphQL schema from file or URL
-func (i *GraphQLImporter) loadSchema(path string) (string, error) {
-	// Check if it's a URL
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		resp, err := http.Get(path)
+// loadSchema loads a GraphQL schema from file or URL, or builds it by
+// introspecting a live endpoint
+func (i *GraphQLImporter) loadSchema(ctx context.Context, source SpecSource) (string, error) {
+	if introspects(source) {
+		content, err := introspect(ctx, i.fetcher, source)
 		if err != nil {
-			return "", fmt.Errorf("failed to fetch schema from URL: %w", err)
+			return "", err
 		}
-		defer resp.Body.Close()
+		return introspectionSDL(content)
+	}
 
-		bodyBytes, err := io.ReadAll(resp.Body)
+	// Check if it's a URL
+	if isRemoteSpec(source.Path) {
+		content, err := i.fetcher.Fetch(ctx, source.Path, source.Auth)
 		if err != nil {
-			return "", fmt.Errorf("failed to read schema response: %w", err)
+			return "", fmt.Errorf("failed to fetch schema from URL: %w", err)
 		}
-
-		return string(bodyBytes), nil
+		return string(content), nil
 	}
 
 	// Load from file
-	content, err := os.ReadFile(path)
+	content, err := os.ReadFile(source.Path)
 	if err != nil {
 		return "", fmt.Errorf("failed to read schema file: %w", err)
 	}
@@ -135,35 +181,66 @@ func (i *GraphQLImporter) loadSchema(path string) (string, error) {
 	return string(content), nil
 }
 
+// graphQLRootTypes returns the names of the query and mutation types, as
+// declared by a schema definition or else Query and Mutation
+func graphQLRootTypes(doc *ast.Document) (string, string) {
+	queryType, mutationType := "Query", "Mutation"
+	for _, def := range doc.Definitions {
+		if schemaDef, ok := def.(*ast.SchemaDefinition); ok {
+			for _, operationType := range schemaDef.OperationTypes {
+				switch operationType.Operation {
+				case "query":
+					queryType = operationType.Type.Name.Value
+				case "mutation":
+					mutationType = operationType.Type.Name.Value
+				}
+			}
+		}
+	}
+	return queryType, mutationType
+}
+
 // createQueryTool creates a tool for a GraphQL query
-func (i *GraphQLImporter) createQueryTool(source SpecSource, endpoint string, field *ast.FieldDefinition, schema string) types.Tool {
+func (i *GraphQLImporter) createQueryTool(source SpecSource, endpoint string, environments *sourceEnvironments, field *ast.FieldDefinition, schema, selection string, definitions graphQLDefinitions, depth int) types.Tool {
 	return &GraphQLTool{
-		source:    source,
-		endpoint:  endpoint,
-		field:     field,
-		schema:    schema,
-		operation: "query",
+		source:       source,
+		endpoint:     endpoint,
+		environments: environments,
+		field:        field,
+		schema:       schema,
+		selection:    selection,
+		definitions:  definitions,
+		depth:        depth,
+		operation:    "query",
 	}
 }
 
 // createMutationTool creates a tool for a GraphQL mutation
-func (i *GraphQLImporter) createMutationTool(source SpecSource, endpoint string, field *ast.FieldDefinition, schema string) types.Tool {
+func (i *GraphQLImporter) createMutationTool(source SpecSource, endpoint string, environments *sourceEnvironments, field *ast.FieldDefinition, schema, selection string, definitions graphQLDefinitions, depth int) types.Tool {
 	return &GraphQLTool{
-		source:    source,
-		endpoint:  endpoint,
-		field:     field,
-		schema:    schema,
-		operation: "mutation",
+		source:       source,
+		endpoint:     endpoint,
+		environments: environments,
+		field:        field,
+		schema:       schema,
+		selection:    selection,
+		definitions:  definitions,
+		depth:        depth,
+		operation:    "mutation",
 	}
 }
 
 // GraphQLTool represents a tool generated from a GraphQL operation
 type GraphQLTool struct {
-	source    SpecSource
-	endpoint  string
-	field     *ast.FieldDefinition
-	schema    string
-	operation string // "query" or "mutation"
+	source       SpecSource
+	endpoint     string
+	environments *sourceEnvironments // Override the endpoint when one is selected
+	field        *ast.FieldDefinition
+	schema       string
+	selection    string             // Selection set of the returned type; empty for scalars
+	definitions  graphQLDefinitions // Types the arguments and result refer to
+	depth        int                // Levels of nested objects the selection includes
+	operation    string             // "query" or "mutation"
 }
 
 // Name returns the tool name
@@ -189,7 +266,7 @@ func (t *GraphQLTool) Description() string {
 }
 
 // Execute performs the GraphQL operation
-func (t *GraphQLTool) Execute(input any) (any, error) {
+func (t *GraphQLTool) Execute(ctx context.Context, input any) (any, error) {
 	// Parse input
 	inputMap, ok := input.(map[string]interface{})
 	if !ok {
@@ -202,15 +279,24 @@ func (t *GraphQLTool) Execute(input any) (any, error) {
 		variables = make(map[string]interface{})
 	}
 
+	// Callers may choose the returned fields
+	selection := t.selection
+	if fields, exists := inputMap["fields"]; exists {
+		var err error
+		if selection, err = fieldsSelection(fields); err != nil {
+			return nil, err
+		}
+	}
+
 	// Copy non-variables fields as variables
 	for key, value := range inputMap {
-		if key != "variables" {
+		if key != "variables" && key != "fields" {
 			variables[key] = value
 		}
 	}
 
 	// Build GraphQL query/mutation
-	query := t.buildQuery(variables)
+	query := t.buildQuery(selection)
 
 	// Create GraphQL request
 	requestBody := map[string]interface{}{
@@ -219,7 +305,7 @@ func (t *GraphQLTool) Execute(input any) (any, error) {
 	}
 
 	// Execute GraphQL request
-	response, err := t.executeGraphQLRequest(requestBody)
+	response, err := t.executeGraphQLRequest(ctx, requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("GraphQL request failed: %w", err)
 	}
@@ -227,8 +313,8 @@ func (t *GraphQLTool) Execute(input any) (any, error) {
 	return response, nil
 }
 
-// buildQuery builds the GraphQL query/mutation string
-func (t *GraphQLTool) buildQuery(variables map[string]interface{}) string {
+// buildQuery builds the GraphQL query/mutation string selecting selection
+func (t *GraphQLTool) buildQuery(selection string) string {
 	// Build arguments string
 	var argsBuilder strings.Builder
 	var varsBuilder strings.Builder
@@ -268,8 +354,10 @@ func (t *GraphQLTool) buildQuery(variables map[string]interface{}) string {
 		queryBuilder.WriteString(argsBuilder.String())
 	}
 
-	// Add selection set (simplified - in real implementation, this would be more sophisticated)
-	queryBuilder.WriteString(" { __typename } ")
+	if selection != "" {
+		queryBuilder.WriteString(" ")
+		queryBuilder.WriteString(selection)
+	}
 	queryBuilder.WriteString(" }")
 
 	return queryBuilder.String()
@@ -290,30 +378,60 @@ func (t *GraphQLTool) getTypeString(typeNode ast.Type) string {
 }
 
 // executeGraphQLRequest executes the HTTP request to the GraphQL endpoint
-func (t *GraphQLTool) executeGraphQLRequest(requestBody map[string]interface{}) (interface{}, error) {
+func (t *GraphQLTool) executeGraphQLRequest(ctx context.Context, requestBody map[string]interface{}) (interface{}, error) {
 	// Marshal request body
 	bodyBytes, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
+	// Call the selected environment, or the source's endpoint
+	env, err := t.environments.selected(ctx)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := t.endpoint
+	client := &http.Client{}
+	if env != nil {
+		if env.baseURL != "" {
+			endpoint = env.baseURL
+		}
+		client = env.client
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("source %s has no GraphQL endpoint; select an environment", t.source.ID)
+	}
+
 	// Create HTTP request
-	req, err := http.NewRequest("POST", t.endpoint, strings.NewReader(string(bodyBytes)))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(string(bodyBytes)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	for name, values := range sourceHeaders(t.source) {
+		req.Header[name] = values
+	}
+	if env != nil {
+		for name, values := range env.headers {
+			req.Header[name] = values
+		}
+	}
 
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
+	// The caller's context bounds the request; see the tools.timeout_ms setting
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Rate limited or unavailable upstreams report when to retry
+	if err := types.UpstreamRetryError(resp); err != nil {
+		return nil, err
+	}
+
 	// Parse response
 	var response map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
@@ -326,41 +444,31 @@ func (t *GraphQLTool) executeGraphQLRequest(requestBody map[string]interface{})
 			"errors":      errors,
 			"data":        response["data"],
 			"status_code": resp.StatusCode,
-			"endpoint":    t.endpoint,
+			"headers":     resp.Header,
+			"endpoint":    endpoint,
 		}, nil
 	}
 
-	return map[string]interface{}{
+	output := map[string]interface{}{
 		"data":        response["data"],
 		"status_code": resp.StatusCode,
-		"endpoint":    t.endpoint,
-	}, nil
+		"headers":     resp.Header,
+		"endpoint":    endpoint,
+	}
+	if env != nil {
+		output["environment"] = env.name
+	}
+	return output, nil
 }
 
 // Metadata returns tool metadata
 func (t *GraphQLTool) Metadata() types.ToolMetadata {
-	// Build input schema from GraphQL field arguments
-	inputSchema := map[string]interface{}{
-		"type":       "object",
-		"properties": make(map[string]interface{}),
-		"required":   []string{},
-	}
-
+	// Build input schema from the types of the GraphQL field arguments
+	inputSchema := t.definitions.inputObjectSchema(t.field.Arguments, 0)
 	properties := inputSchema["properties"].(map[string]interface{})
-	var required []string
-
-	// Add field arguments to schema
-	for _, arg := range t.field.Arguments {
-		argSchema := map[string]interface{}{
-			"type":        "string", // Simplified type mapping
-			"description": fmt.Sprintf("GraphQL argument: %s", arg.Name.Value),
-		}
-
-		properties[arg.Name.Value] = argSchema
-
-		// Check if argument is non-null (required)
-		if _, isNonNull := arg.Type.(*ast.NonNull); isNonNull {
-			required = append(required, arg.Name.Value)
+	for name, property := range properties {
+		if argSchema := property.(map[string]interface{}); argSchema["description"] == nil {
+			argSchema["description"] = fmt.Sprintf("GraphQL argument: %s", name)
 		}
 	}
 
@@ -370,7 +478,14 @@ func (t *GraphQLTool) Metadata() types.ToolMetadata {
 		"description": "GraphQL variables object",
 	}
 
-	inputSchema["required"] = required
+	// Let callers override the generated selection of object results
+	if t.selection != "" {
+		properties["fields"] = map[string]interface{}{
+			"type":        []string{"string", "array"},
+			"items":       map[string]interface{}{"type": "string"},
+			"description": fmt.Sprintf("Fields to return instead of %s, as a selection set or a list of paths like \"author.name\"", t.selection),
+		}
+	}
 
 	return types.ToolMetadata{
 		Name:        t.Name(),
@@ -378,12 +493,19 @@ func (t *GraphQLTool) Metadata() types.ToolMetadata {
 		Version:     "1.0.0",
 		Source:      string(SpecTypeGraphQL),
 		Tags:        []string{"graphql", t.operation, "api"},
+		Deprecation: graphQLDeprecation(t.field),
+		Idempotent:  t.operation == "query", // Queries do not modify server state
 		Schema: map[string]interface{}{
 			"input": inputSchema,
 			"output": map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"data":        map[string]interface{}{"type": "object"},
+					"data": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							t.field.Name.Value: t.definitions.outputSchema(t.field.Type, t.depth),
+						},
+					},
 					"errors":      map[string]interface{}{"type": "array"},
 					"status_code": map[string]interface{}{"type": "integer"},
 					"endpoint":    map[string]interface{}{"type": "string"},
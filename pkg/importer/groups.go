@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"context"
+	"sort"
+)
+
+// SourceGroup summarizes the spec sources owned by one team or system
+type SourceGroup struct {
+	Name      string        `json:"name"`
+	SourceIDs []string      `json:"source_ids"`
+	ToolCount int           `json:"tool_count"`
+	Throttle  ThrottleStats `json:"throttle"` // Totals across the group's throttled sources
+}
+
+// GroupOperationResult reports the outcome of a bulk operation for one source
+type GroupOperationResult struct {
+	SourceID  string `json:"source_id"`
+	Success   bool   `json:"success"`
+	ToolCount int    `json:"tool_count,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ListSourcesInGroup returns the sources of a group, sorted by ID
+func (m *ImporterManager) ListSourcesInGroup(group string) []SpecSource {
+	var sources []SpecSource
+	for _, source := range m.ListSources() {
+		if source.Group == group {
+			sources = append(sources, source)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].ID < sources[j].ID
+	})
+	return sources
+}
+
+// ListGroups returns a summary of every group that has at least one source.
+// Ungrouped sources are not included.
+func (m *ImporterManager) ListGroups() []SourceGroup {
+	names := make(map[string]bool)
+	for _, source := range m.ListSources() {
+		if source.Group != "" {
+			names[source.Group] = true
+		}
+	}
+
+	groups := make([]SourceGroup, 0, len(names))
+	for name := range names {
+		group, _ := m.GetGroup(name)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// GetGroup returns the summary of a group
+func (m *ImporterManager) GetGroup(name string) (SourceGroup, bool) {
+	sources := m.ListSourcesInGroup(name)
+	if name == "" || len(sources) == 0 {
+		return SourceGroup{}, false
+	}
+
+	group := SourceGroup{
+		Name:      name,
+		SourceIDs: make([]string, 0, len(sources)),
+		Throttle:  ThrottleStats{SourceID: name},
+	}
+	for _, source := range sources {
+		group.SourceIDs = append(group.SourceIDs, source.ID)
+
+		m.catalogMu.RLock()
+		group.ToolCount += len(m.catalogs[source.ID])
+		m.catalogMu.RUnlock()
+
+		if stats, throttled := m.GetThrottleStats(source.ID); throttled {
+			group.Throttle.Invocations += stats.Invocations
+			group.Throttle.Throttled += stats.Throttled
+			group.Throttle.Rejected += stats.Rejected
+			group.Throttle.InFlight += stats.InFlight
+			group.Throttle.Queued += stats.Queued
+			group.Throttle.TotalWaitMs += stats.TotalWaitMs
+		}
+	}
+	return group, true
+}
+
+// GetToolGroup returns the group of the source that registered a tool. Tools
+// that were not imported, or whose source is ungrouped, have no group.
+func (m *ImporterManager) GetToolGroup(toolName string) string {
+	source, _ := m.toolSource(toolName)
+	return source.Group
+}
+
+// GetToolTenant returns the tenant of the source that registered a tool.
+// Tools that were not imported, or whose source has no tenant, are shared by
+// every tenant.
+func (m *ImporterManager) GetToolTenant(toolName string) string {
+	source, _ := m.toolSource(toolName)
+	return source.Tenant
+}
+
+// toolSource returns the source that registered a tool
+func (m *ImporterManager) toolSource(toolName string) (SpecSource, bool) {
+	m.catalogMu.RLock()
+	defer m.catalogMu.RUnlock()
+
+	for sourceID, catalog := range m.catalogs {
+		for _, metadata := range catalog {
+			if metadata.Name == toolName {
+				return m.GetSource(sourceID)
+			}
+		}
+	}
+	return SpecSource{}, false
+}
+
+// ReloadGroup reloads every source of a group. A failing source does not stop
+// the others from reloading.
+func (m *ImporterManager) ReloadGroup(ctx context.Context, group string) []GroupOperationResult {
+	return m.applyToGroup(group, func(source SpecSource) (int, error) {
+		result, err := m.ReloadSpec(ctx, source.ID)
+		if err != nil {
+			return 0, err
+		}
+		return len(result.Tools), nil
+	})
+}
+
+// RemoveGroup removes every source of a group and unregisters their tools
+func (m *ImporterManager) RemoveGroup(ctx context.Context, group string) []GroupOperationResult {
+	return m.applyToGroup(group, func(source SpecSource) (int, error) {
+		return 0, m.RemoveSpec(ctx, source.ID)
+	})
+}
+
+// applyToGroup runs an operation on each source of a group and collects the results
+func (m *ImporterManager) applyToGroup(group string, operation func(source SpecSource) (int, error)) []GroupOperationResult {
+	sources := m.ListSourcesInGroup(group)
+	results := make([]GroupOperationResult, 0, len(sources))
+	for _, source := range sources {
+		toolCount, err := operation(source)
+		result := GroupOperationResult{SourceID: source.ID, Success: err == nil, ToolCount: toolCount}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTool is a tool with a fixed name
+type stubTool struct{ name string }
+
+func (t stubTool) Name() string                                        { return t.name }
+func (t stubTool) Description() string                                 { return t.name }
+func (t stubTool) Execute(ctx context.Context, input any) (any, error) { return input, nil }
+func (t stubTool) Metadata() types.ToolMetadata                        { return types.ToolMetadata{Name: t.name} }
+
+// stubImporter generates two tools per source and fails sources whose path is "broken"
+type stubImporter struct{}
+
+func (stubImporter) GetType() SpecType                                     { return SpecTypeOpenAPI }
+func (stubImporter) Supports(source SpecSource) bool                       { return true }
+func (stubImporter) Validate(ctx context.Context, source SpecSource) error { return nil }
+
+func (stubImporter) Import(ctx context.Context, source SpecSource) (*ImportResult, error) {
+	if source.Path == "broken" {
+		return nil, fmt.Errorf("cannot read %s", source.Path)
+	}
+	return &ImportResult{
+		Source: source,
+		Tools: []types.Tool{
+			stubTool{name: fmt.Sprintf("openapi.%s.list", source.ID)},
+			stubTool{name: fmt.Sprintf("openapi.%s.get", source.ID)},
+		},
+	}, nil
+}
+
+// mapRegistry is a minimal ToolRegistry for tests
+type mapRegistry map[string]types.Tool
+
+func (r mapRegistry) Register(tool types.Tool) error {
+	r[tool.Name()] = tool
+	return nil
+}
+
+func (r mapRegistry) Unregister(name string) error {
+	delete(r, name)
+	return nil
+}
+
+func TestImporterManager_Groups(t *testing.T) {
+	ctx := context.Background()
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(stubImporter{})
+
+	for _, source := range []SpecSource{
+		{ID: "billing", Type: SpecTypeOpenAPI, Group: "payments"},
+		{ID: "invoices", Type: SpecTypeOpenAPI, Group: "payments"},
+		{ID: "users", Type: SpecTypeOpenAPI, Group: "identity", Tenant: "iam"},
+		{ID: "misc", Type: SpecTypeOpenAPI},
+	} {
+		_, err := manager.ImportSpec(ctx, source)
+		require.NoError(t, err)
+	}
+
+	groups := manager.ListGroups()
+	require.Len(t, groups, 2)
+	assert.Equal(t, "identity", groups[0].Name)
+	assert.Equal(t, "payments", groups[1].Name)
+	assert.Equal(t, []string{"billing", "invoices"}, groups[1].SourceIDs)
+	assert.Equal(t, 4, groups[1].ToolCount)
+
+	_, exists := manager.GetGroup("")
+	assert.False(t, exists, "ungrouped sources do not form a group")
+
+	assert.Equal(t, "payments", manager.GetToolGroup("openapi.invoices.get"))
+	assert.Equal(t, "", manager.GetToolGroup("openapi.misc.get"))
+	assert.Equal(t, "", manager.GetToolGroup("echo"))
+	assert.Equal(t, "iam", manager.GetToolTenant("openapi.users.list"))
+	assert.Equal(t, "", manager.GetToolTenant("openapi.billing.list"))
+	assert.Equal(t, "", manager.GetToolTenant("echo"))
+
+	// A failing source does not stop the rest of the group from reloading
+	source, _ := manager.GetSource("invoices")
+	source.Path = "broken"
+	manager.sources["invoices"] = source
+	results := manager.ReloadGroup(ctx, "payments")
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, 2, results[0].ToolCount)
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Error, "cannot read broken")
+
+	results = manager.RemoveGroup(ctx, "identity")
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+	assert.Empty(t, manager.ListSourcesInGroup("identity"))
+	assert.NotContains(t, registry, "openapi.users.list")
+	assert.Contains(t, registry, "openapi.misc.list")
+}
@@ -0,0 +1,579 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Source metadata keys configuring how gRPC tools reach the upstream service
+const (
+	grpcEndpointKey    = "endpoint"     // host:port of the service; defaults to the reflection address
+	grpcImportPathsKey = "import_paths" // Comma-separated directories searched for the imports of a .proto file
+	grpcTLSKey         = "tls"          // "true" connects over TLS
+)
+
+// Path schemes that import a service through server reflection
+const (
+	grpcReflectionScheme    = "grpc://"
+	grpcReflectionTLSScheme = "grpcs://"
+)
+
+// GRPCImporter handles protobuf definitions, read from .proto files or from
+// the server reflection service of a running gRPC server
+type GRPCImporter struct {
+	fetcher *SpecFetcher
+	connsMu sync.Mutex
+	conns   map[string]*grpc.ClientConn // "tls|target" -> shared connection
+}
+
+// NewGRPCImporter creates a new gRPC importer
+func NewGRPCImporter() *GRPCImporter {
+	return &GRPCImporter{
+		fetcher: NewSpecFetcher(DefaultFetchPolicy()),
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+// SetFetcher sets how .proto files are downloaded from URLs
+func (i *GRPCImporter) SetFetcher(fetcher *SpecFetcher) {
+	i.fetcher = fetcher
+}
+
+// GetType returns the specification type
+func (i *GRPCImporter) GetType() SpecType {
+	return SpecTypeGRPC
+}
+
+// Supports checks if this importer can handle the given source
+func (i *GRPCImporter) Supports(source SpecSource) bool {
+	return source.Type == SpecTypeGRPC
+}
+
+// Validate checks that the protobuf definition can be loaded
+func (i *GRPCImporter) Validate(ctx context.Context, source SpecSource) error {
+	_, _, err := i.loadServices(ctx, source)
+	return err
+}
+
+// Import loads the services of the protobuf definition and generates one
+// tool per unary RPC
+func (i *GRPCImporter) Import(ctx context.Context, source SpecSource) (*ImportResult, error) {
+	start := time.Now()
+
+	result := &ImportResult{
+		Source:    source,
+		Tools:     []types.Tool{},
+		Errors:    []error{},
+		Warnings:  []string{},
+		Timestamp: start,
+	}
+
+	services, endpoint, err := i.loadServices(ctx, source)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+		result.Duration = time.Since(start)
+		return result, err
+	}
+	if endpoint.target == "" {
+		err := fmt.Errorf("no gRPC endpoint specified, set the %q metadata", grpcEndpointKey)
+		result.Errors = append(result.Errors, err)
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	for _, service := range services {
+		methods := service.Methods()
+		for m := 0; m < methods.Len(); m++ {
+			method := methods.Get(m)
+			if method.IsStreamingClient() || method.IsStreamingServer() {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("Skipping streaming RPC %s", method.FullName()))
+				continue
+			}
+			result.Tools = append(result.Tools, &GRPCTool{
+				source:   source,
+				method:   method,
+				endpoint: endpoint,
+				importer: i,
+			})
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// grpcEndpoint is where the tools of a source send their calls
+type grpcEndpoint struct {
+	target string // host:port
+	tls    bool
+}
+
+// loadServices returns the services a source defines and the endpoint
+// serving them
+func (i *GRPCImporter) loadServices(ctx context.Context, source SpecSource) ([]protoreflect.ServiceDescriptor, grpcEndpoint, error) {
+	endpoint := grpcEndpoint{
+		target: source.Metadata[grpcEndpointKey],
+		tls:    source.Metadata[grpcTLSKey] == "true",
+	}
+
+	if address, secure, ok := reflectionAddress(source.Path); ok {
+		if endpoint.target == "" {
+			endpoint = grpcEndpoint{target: address, tls: secure}
+		}
+		services, err := i.reflectServices(ctx, grpcEndpoint{target: address, tls: secure})
+		return services, endpoint, err
+	}
+
+	services, err := i.compileServices(ctx, source)
+	return services, endpoint, err
+}
+
+// reflectionAddress returns the server address of a grpc:// or grpcs:// path
+func reflectionAddress(path string) (string, bool, bool) {
+	if address, found := strings.CutPrefix(path, grpcReflectionScheme); found {
+		return strings.TrimSuffix(address, "/"), false, true
+	}
+	if address, found := strings.CutPrefix(path, grpcReflectionTLSScheme); found {
+		return strings.TrimSuffix(address, "/"), true, true
+	}
+	return "", false, false
+}
+
+// compileServices parses a .proto file, read from disk or a URL, together
+// with its imports
+func (i *GRPCImporter) compileServices(ctx context.Context, source SpecSource) ([]protoreflect.ServiceDescriptor, error) {
+	name := filepath.Base(source.Path)
+	var resolver protocompile.Resolver = &protocompile.SourceResolver{ImportPaths: grpcImportPaths(source)}
+	if isRemoteSpec(source.Path) {
+		content, err := i.fetcher.Fetch(ctx, source.Path, source.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch proto file from URL: %w", err)
+		}
+		// Imports other than the well-known types come from the import paths
+		resolver = protocompile.CompositeResolver{
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				if path != name {
+					return protocompile.SearchResult{}, os.ErrNotExist
+				}
+				return protocompile.SearchResult{Source: bytes.NewReader(content)}, nil
+			}),
+			resolver,
+		}
+	} else if _, err := os.Stat(source.Path); err != nil {
+		return nil, fmt.Errorf("failed to read proto file: %w", err)
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver:       protocompile.WithStandardImports(resolver),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proto file: %w", err)
+	}
+
+	var services []protoreflect.ServiceDescriptor
+	for _, file := range files {
+		for s := 0; s < file.Services().Len(); s++ {
+			services = append(services, file.Services().Get(s))
+		}
+	}
+	return services, nil
+}
+
+// grpcImportPaths returns the directories searched for the imports of a
+// source's .proto file, starting with the directory of a local file
+func grpcImportPaths(source SpecSource) []string {
+	var paths []string
+	if !isRemoteSpec(source.Path) {
+		paths = append(paths, filepath.Dir(source.Path))
+	}
+	for _, path := range strings.Split(source.Metadata[grpcImportPathsKey], ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// reflectServices lists the services of a running server through its
+// reflection service, with the descriptors they depend on
+func (i *GRPCImporter) reflectServices(ctx context.Context, endpoint grpcEndpoint) ([]protoreflect.ServiceDescriptor, error) {
+	conn, err := i.connection(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reflection stream to %s: %w", endpoint.target, err)
+	}
+	defer stream.CloseSend()
+
+	query := func(request *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+		if err := stream.Send(request); err != nil {
+			return nil, fmt.Errorf("reflection request to %s failed: %w", endpoint.target, err)
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("reflection request to %s failed: %w", endpoint.target, err)
+		}
+		if failure := response.GetErrorResponse(); failure != nil {
+			return nil, fmt.Errorf("reflection request to %s failed: %s", endpoint.target, failure.GetErrorMessage())
+		}
+		return response, nil
+	}
+
+	listed, err := query(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	addFiles := func(response *reflectionpb.ServerReflectionResponse) error {
+		for _, encoded := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(encoded, file); err != nil {
+				return fmt.Errorf("invalid file descriptor from %s: %w", endpoint.target, err)
+			}
+			files[file.GetName()] = file
+		}
+		return nil
+	}
+
+	var names []string
+	for _, service := range listed.GetListServicesResponse().GetService() {
+		// The reflection service itself is not part of the API
+		if strings.HasPrefix(service.GetName(), "grpc.reflection.") {
+			continue
+		}
+		names = append(names, service.GetName())
+		response, err := query(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service.GetName()},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := addFiles(response); err != nil {
+			return nil, err
+		}
+	}
+
+	// Servers usually send the dependencies along; fetch any they left out
+	for missing := missingDependencies(files); len(missing) > 0; missing = missingDependencies(files) {
+		for _, name := range missing {
+			response, err := query(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := addFiles(response); err != nil {
+				return nil, err
+			}
+			if files[name] == nil {
+				return nil, fmt.Errorf("server %s did not return %s", endpoint.target, name)
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+	registry, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptors from %s: %w", endpoint.target, err)
+	}
+
+	services := make([]protoreflect.ServiceDescriptor, 0, len(names))
+	for _, name := range names {
+		descriptor, err := registry.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("service %s not found in descriptors from %s: %w", name, endpoint.target, err)
+		}
+		if service, ok := descriptor.(protoreflect.ServiceDescriptor); ok {
+			services = append(services, service)
+		}
+	}
+	return services, nil
+}
+
+// missingDependencies returns the imports of the files that are not loaded
+func missingDependencies(files map[string]*descriptorpb.FileDescriptorProto) []string {
+	var missing []string
+	for _, file := range files {
+		for _, dependency := range file.GetDependency() {
+			if files[dependency] == nil {
+				missing = append(missing, dependency)
+			}
+		}
+	}
+	return missing
+}
+
+// connection returns the shared connection to an endpoint. Connections are
+// established on first use, so this does not wait for the server.
+func (i *GRPCImporter) connection(endpoint grpcEndpoint) (*grpc.ClientConn, error) {
+	key := fmt.Sprintf("%t|%s", endpoint.tls, endpoint.target)
+	i.connsMu.Lock()
+	defer i.connsMu.Unlock()
+	if conn, exists := i.conns[key]; exists {
+		return conn, nil
+	}
+
+	creds := insecure.NewCredentials()
+	if endpoint.tls {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(endpoint.target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gRPC endpoint %s: %w", endpoint.target, err)
+	}
+	i.conns[key] = conn
+	return conn, nil
+}
+
+// GRPCTool represents a tool generated from a unary RPC
+type GRPCTool struct {
+	source   SpecSource
+	method   protoreflect.MethodDescriptor
+	endpoint grpcEndpoint
+	importer *GRPCImporter
+}
+
+// Name returns the tool name
+func (t *GRPCTool) Name() string {
+	return fmt.Sprintf("grpc.%s.%s_%s", t.source.ID, t.method.Parent().Name(), t.method.Name())
+}
+
+// Description returns the tool description
+func (t *GRPCTool) Description() string {
+	// Comments are only known for definitions read from .proto files
+	comments := t.method.ParentFile().SourceLocations().ByDescriptor(t.method).LeadingComments
+	if description := strings.TrimSpace(comments); description != "" {
+		return description
+	}
+	return fmt.Sprintf("Call the %s.%s RPC", t.method.Parent().Name(), t.method.Name())
+}
+
+// fullMethod returns the method path of the RPC, e.g. /helloworld.Greeter/SayHello
+func (t *GRPCTool) fullMethod() string {
+	return fmt.Sprintf("/%s/%s", t.method.Parent().FullName(), t.method.Name())
+}
+
+// Execute transcodes the input to the request message, calls the RPC and
+// returns the response message as JSON
+func (t *GRPCTool) Execute(ctx context.Context, input any) (any, error) {
+	inputMap, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("input must be a JSON object")
+	}
+
+	body, err := json.Marshal(inputMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input: %w", err)
+	}
+	request := dynamicpb.NewMessage(t.method.Input())
+	if err := protojson.Unmarshal(body, request); err != nil {
+		return nil, fmt.Errorf("input does not match %s: %w", t.method.Input().FullName(), err)
+	}
+
+	conn, err := t.importer.connection(t.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	// The caller's context bounds the call; see the tools.timeout_ms setting
+	response := dynamicpb.NewMessage(t.method.Output())
+	if err := conn.Invoke(ctx, t.fullMethod(), request, response); err != nil {
+		return nil, grpcCallError(err)
+	}
+
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode gRPC response: %w", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode gRPC response: %w", err)
+	}
+
+	return map[string]interface{}{
+		"response": decoded,
+		"method":   t.fullMethod(),
+		"endpoint": t.endpoint.target,
+	}, nil
+}
+
+// grpcCallError reports a failed call, as a RetryableError when the upstream
+// is unavailable or out of quota
+func grpcCallError(err error) error {
+	reason := fmt.Sprintf("gRPC call failed: %s", status.Convert(err).Message())
+	switch status.Code(err) {
+	case codes.ResourceExhausted:
+		return &types.RetryableError{StatusCode: http.StatusTooManyRequests, Reason: reason}
+	case codes.Unavailable:
+		return &types.RetryableError{StatusCode: http.StatusServiceUnavailable, Reason: reason}
+	}
+	return fmt.Errorf("gRPC call failed: %w", err)
+}
+
+// Metadata returns tool metadata
+func (t *GRPCTool) Metadata() types.ToolMetadata {
+	options, _ := t.method.Options().(*descriptorpb.MethodOptions)
+	idempotency := options.GetIdempotencyLevel()
+
+	return types.ToolMetadata{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Version:     "1.0.0",
+		Source:      string(SpecTypeGRPC),
+		Tags:        []string{"grpc", string(t.method.Parent().Name()), "api"},
+		Deprecation: grpcDeprecation(t.method),
+		Idempotent:  idempotency == descriptorpb.MethodOptions_NO_SIDE_EFFECTS || idempotency == descriptorpb.MethodOptions_IDEMPOTENT,
+		Schema: map[string]interface{}{
+			"input": protoMessageSchema(t.method.Input(), map[protoreflect.FullName]bool{}),
+			"output": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"response": protoMessageSchema(t.method.Output(), map[protoreflect.FullName]bool{}),
+					"method":   map[string]interface{}{"type": "string"},
+					"endpoint": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// protoMessageSchema converts a message to JSON Schema under the proto field
+// names. Recursive messages are left open where they repeat.
+func protoMessageSchema(message protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) map[string]interface{} {
+	if schema, ok := wellKnownSchema(message, seen); ok {
+		return schema
+	}
+	if seen[message.FullName()] {
+		return map[string]interface{}{"type": "object", "description": string(message.FullName())}
+	}
+	seen[message.FullName()] = true
+	defer delete(seen, message.FullName())
+
+	properties := make(map[string]interface{})
+	required := []string{}
+	fields := message.Fields()
+	for f := 0; f < fields.Len(); f++ {
+		field := fields.Get(f)
+		properties[string(field.Name())] = protoFieldSchema(field, seen)
+		if field.Cardinality() == protoreflect.Required {
+			required = append(required, string(field.Name()))
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// protoFieldSchema converts a field, including repeated and map fields
+func protoFieldSchema(field protoreflect.FieldDescriptor, seen map[protoreflect.FullName]bool) map[string]interface{} {
+	if field.IsMap() {
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": protoValueSchema(field.MapValue(), seen),
+		}
+	}
+	if field.IsList() {
+		return map[string]interface{}{
+			"type":  "array",
+			"items": protoValueSchema(field, seen),
+		}
+	}
+	return protoValueSchema(field, seen)
+}
+
+// protoValueSchema converts a single value of a field as protojson writes it
+func protoValueSchema(field protoreflect.FieldDescriptor, seen map[protoreflect.FullName]bool) map[string]interface{} {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// 64-bit integers are written as strings to keep their precision
+		return map[string]interface{}{"type": []string{"integer", "string"}, "format": "int64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number"}
+	case protoreflect.StringKind:
+		return map[string]interface{}{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for v := 0; v < values.Len(); v++ {
+			names = append(names, string(values.Get(v).Name()))
+		}
+		return map[string]interface{}{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protoMessageSchema(field.Message(), seen)
+	}
+	return map[string]interface{}{}
+}
+
+// wellKnownSchema converts the well-known types, which protojson writes in
+// their own JSON forms
+func wellKnownSchema(message protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) (map[string]interface{}, bool) {
+	if message.ParentFile() == nil || message.ParentFile().Package() != "google.protobuf" {
+		return nil, false
+	}
+	switch message.Name() {
+	case "Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}, true
+	case "Duration":
+		return map[string]interface{}{"type": "string", "description": "Seconds with an s suffix, e.g. 1.5s"}, true
+	case "FieldMask":
+		return map[string]interface{}{"type": "string", "description": "Comma-separated field paths"}, true
+	case "Struct", "Any", "Empty":
+		return map[string]interface{}{"type": "object"}, true
+	case "ListValue":
+		return map[string]interface{}{"type": "array"}, true
+	case "Value":
+		return map[string]interface{}{}, true
+	case "DoubleValue", "FloatValue", "Int64Value", "UInt64Value", "Int32Value",
+		"UInt32Value", "BoolValue", "StringValue", "BytesValue":
+		// Wrappers are written as their bare value
+		return protoValueSchema(message.Fields().ByName("value"), seen), true
+	}
+	return nil, false
+}
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// healthProto mirrors the health service with options the importer reads
+const healthProto = `syntax = "proto3";
+package grpc.health.v1;
+
+message HealthCheckRequest {
+  string service = 1;
+}
+
+message HealthCheckResponse {
+  enum ServingStatus {
+    UNKNOWN = 0;
+    SERVING = 1;
+    NOT_SERVING = 2;
+    SERVICE_UNKNOWN = 3;
+  }
+  ServingStatus status = 1;
+}
+
+service Health {
+  // Check reports whether a service is serving
+  rpc Check(HealthCheckRequest) returns (HealthCheckResponse) {
+    option idempotency_level = NO_SIDE_EFFECTS;
+  }
+  rpc Watch(HealthCheckRequest) returns (stream HealthCheckResponse);
+  rpc Probe(HealthCheckRequest) returns (HealthCheckResponse) {
+    option deprecated = true;
+  }
+}
+`
+
+// startHealthServer serves the health and reflection services and returns
+// the server address
+func startHealthServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+// grpcToolsByName indexes the tools of an import
+func grpcToolsByName(result *ImportResult) map[string]types.Tool {
+	tools := make(map[string]types.Tool, len(result.Tools))
+	for _, tool := range result.Tools {
+		tools[tool.Name()] = tool
+	}
+	return tools
+}
+
+func TestGRPCImporter_ProtoFile(t *testing.T) {
+	address := startHealthServer(t)
+	path := filepath.Join(t.TempDir(), "health.proto")
+	require.NoError(t, os.WriteFile(path, []byte(healthProto), 0644))
+
+	importer := NewGRPCImporter()
+	source := SpecSource{ID: "health", Type: SpecTypeGRPC, Path: path, Metadata: map[string]string{"endpoint": address}}
+	require.NoError(t, importer.Validate(context.Background(), source))
+	result, err := importer.Import(context.Background(), source)
+	require.NoError(t, err)
+
+	tools := grpcToolsByName(result)
+	require.Len(t, tools, 2, "one tool per unary RPC")
+	assert.Contains(t, result.Warnings, "Skipping streaming RPC grpc.health.v1.Health.Watch")
+
+	check := tools["grpc.health.Health_Check"]
+	require.NotNil(t, check)
+	metadata := check.Metadata()
+	assert.Equal(t, "Check reports whether a service is serving", metadata.Description)
+	assert.True(t, metadata.Idempotent)
+	assert.Nil(t, metadata.Deprecation)
+	input := metadata.Schema["input"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string"}, input["properties"].(map[string]interface{})["service"])
+	assert.NotNil(t, tools["grpc.health.Health_Probe"].Metadata().Deprecation)
+
+	output, err := check.Execute(context.Background(), map[string]interface{}{"service": "orders"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"status": "NOT_SERVING"}, output.(map[string]interface{})["response"])
+	assert.Equal(t, "/grpc.health.v1.Health/Check", output.(map[string]interface{})["method"])
+
+	_, err = check.Execute(context.Background(), map[string]interface{}{"unknown": true})
+	assert.ErrorContains(t, err, "input does not match grpc.health.v1.HealthCheckRequest")
+
+	// Definitions from files need an endpoint to call
+	source.Metadata = nil
+	_, err = importer.Import(context.Background(), source)
+	assert.ErrorContains(t, err, "no gRPC endpoint specified")
+}
+
+func TestGRPCImporter_Reflection(t *testing.T) {
+	address := startHealthServer(t)
+
+	importer := NewGRPCImporter()
+	source := SpecSource{ID: "health", Type: SpecTypeGRPC, Path: "grpc://" + address}
+	result, err := importer.Import(context.Background(), source)
+	require.NoError(t, err)
+
+	tools := grpcToolsByName(result)
+	check := tools["grpc.health.Health_Check"]
+	require.NotNil(t, check)
+	assert.NotContains(t, tools, "grpc.health.Health_Watch")
+	for name := range tools {
+		assert.NotContains(t, name, "ServerReflection", "the reflection service is not imported")
+	}
+	assert.Equal(t, "Call the Health.Check RPC", check.Description())
+
+	output, err := check.Execute(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"status": "SERVING"}, output.(map[string]interface{})["response"])
+	assert.Equal(t, address, output.(map[string]interface{})["endpoint"])
+}
+
+func TestGRPCImporter_UnavailableUpstream(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	path := filepath.Join(t.TempDir(), "health.proto")
+	require.NoError(t, os.WriteFile(path, []byte(healthProto), 0644))
+	importer := NewGRPCImporter()
+	result, err := importer.Import(context.Background(), SpecSource{ID: "health", Type: SpecTypeGRPC, Path: path, Metadata: map[string]string{"endpoint": address}})
+	require.NoError(t, err)
+
+	_, err = grpcToolsByName(result)["grpc.health.Health_Check"].Execute(context.Background(), map[string]interface{}{})
+	retryable, ok := types.AsRetryable(err)
+	require.True(t, ok, "unavailable upstreams are retryable: %v", err)
+	assert.False(t, retryable.RateLimited())
+}
@@ -2,9 +2,14 @@ package importer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aionmcp/aionmcp/pkg/messaging"
 	"github.com/aionmcp/aionmcp/pkg/types"
 )
 
@@ -15,18 +20,70 @@ const (
 	SpecTypeOpenAPI  SpecType = "openapi"
 	SpecTypeGraphQL  SpecType = "graphql"
 	SpecTypeAsyncAPI SpecType = "asyncapi"
+	SpecTypeGRPC     SpecType = "grpc"
 )
 
 // SpecSource represents a specification source
 type SpecSource struct {
 	ID          string            `json:"id"`
 	Type        SpecType          `json:"type"`
-	Path        string            `json:"path"`        // File path or URL
-	Name        string            `json:"name"`        // Human-readable name
-	Description string            `json:"description"` // Description of the API
-	Metadata    map[string]string `json:"metadata"`    // Additional metadata
+	Path        string            `json:"path"`             // File path or URL
+	Name        string            `json:"name"`             // Human-readable name
+	Description string            `json:"description"`      // Description of the API
+	Group       string            `json:"group,omitempty"`  // Owning team or system, for bulk operations
+	Tenant      string            `json:"tenant,omitempty"` // Owning tenant; executions of the tools are its learning data
+	Metadata    map[string]string `json:"metadata"`         // Additional metadata
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
+	Limits      *SourceLimits     `json:"limits,omitempty"` // nil uses the manager defaults
+	Naming      *NamingStrategy   `json:"naming,omitempty"` // nil uses the manager default
+	Auth        *SpecAuth         `json:"auth,omitempty"`   // Credentials for downloading the spec from a URL
+
+	// Namespace prefixes the names of the source's tools, e.g. "billing"
+	// gives billing.openapi.invoices.list_invoices
+	Namespace string `json:"namespace,omitempty"`
+
+	// CollisionPolicy decides what happens to tools whose names another
+	// source or the server registers; empty uses the manager default
+	CollisionPolicy CollisionPolicy `json:"collision_policy,omitempty"`
+
+	// Credentials authenticate calls of OpenAPI operations, keyed by the name
+	// of the security scheme they satisfy
+	Credentials map[string]*APICredential `json:"credentials,omitempty"`
+
+	// Environments are deployments of the API, such as dev and prod, that
+	// tools call instead of the servers of the spec. Environment names the
+	// default; invocations select another with their "environment" context.
+	Environments map[string]*SourceEnvironment `json:"environments,omitempty"`
+	Environment  string                        `json:"environment,omitempty"`
+
+	// PollIntervalSeconds is how often a spec imported from a URL is checked
+	// for changes; zero uses the poller default and a negative value disables polling
+	PollIntervalSeconds int `json:"poll_interval_seconds,omitempty"`
+}
+
+// MarshalJSON masks the credentials in the metadata: broker passwords and
+// tokens, and the headers sent to GraphQL endpoints
+func (s SpecSource) MarshalJSON() ([]byte, error) {
+	type plain SpecSource
+	masked := plain(s)
+	cloned := false
+	for key, value := range s.Metadata {
+		if value == "" || !credentialMetadata(key) {
+			continue
+		}
+		if !cloned {
+			masked.Metadata = maps.Clone(s.Metadata)
+			cloned = true
+		}
+		masked.Metadata[key] = maskedCredential
+	}
+	return json.Marshal(masked)
+}
+
+// credentialMetadata reports whether a metadata key holds a credential
+func credentialMetadata(key string) bool {
+	return key == brokerPasswordKey || key == brokerTokenKey || strings.HasPrefix(key, headerMetadataPrefix)
 }
 
 // ImportResult contains the result of importing a specification
@@ -37,6 +94,10 @@ type ImportResult struct {
 	Warnings  []string      `json:"warnings"`
 	Duration  time.Duration `json:"duration"`
 	Timestamp time.Time     `json:"timestamp"`
+
+	// Schemas holds the named schemas of the specification as JSON Schema,
+	// from which synthetic data tools are generated
+	Schemas map[string]map[string]interface{} `json:"-"`
 }
 
 // SpecImporter is the interface for importing API specifications
@@ -60,111 +121,458 @@ type ToolRegistry interface {
 	Unregister(name string) error
 }
 
+const (
+	// maxCatalogDiffsPerSource bounds the tool change history kept for each source
+	maxCatalogDiffsPerSource = 50
+)
+
+// CatalogChangeHandler is notified when a spec reload changes a source's tools
+type CatalogChangeHandler func(diff ToolCatalogDiff)
+
 // ImporterManager manages all specification importers
 type ImporterManager struct {
-	importers map[SpecType]SpecImporter
-	registry  ToolRegistry
-	sources   map[string]SpecSource // source ID -> source
+	importers      map[SpecType]SpecImporter
+	registry       ToolRegistry
+	sourcesMu      sync.RWMutex
+	sources        map[string]SpecSource // source ID -> source
+	catalogMu      sync.RWMutex
+	catalogs       map[string][]types.ToolMetadata // source ID -> tools from the last import
+	catalogDiffs   map[string][]ToolCatalogDiff    // source ID -> tool change history
+	changeHandlers []CatalogChangeHandler
+	writeGuard     func() error
+	throttleMu     sync.RWMutex
+	throttles      map[string]*SourceThrottle // source ID -> upstream throttle
+	defaultLimits  SourceLimits
+	quotas         *QuotaTracker
+	defaultNaming  NamingStrategy
+	collisions     CollisionPolicy
+	synthetic      SyntheticConfig
+	fetcher        *SpecFetcher    // nil leaves importers with the default fetch policy
+	store          *SourceStore    // nil keeps sources only in memory
+	messaging      *messaging.Pool // nil leaves importers with their own broker connections
 }
 
 // NewImporterManager creates a new importer manager
 func NewImporterManager(registry ToolRegistry) *ImporterManager {
 	return &ImporterManager{
-		importers: make(map[SpecType]SpecImporter),
-		registry:  registry,
-		sources:   make(map[string]SpecSource),
+		importers:    make(map[SpecType]SpecImporter),
+		registry:     registry,
+		sources:      make(map[string]SpecSource),
+		catalogs:     make(map[string][]types.ToolMetadata),
+		catalogDiffs: make(map[string][]ToolCatalogDiff),
+		throttles:    make(map[string]*SourceThrottle),
+		quotas:       NewQuotaTracker(DefaultQuotaPolicy()),
+	}
+}
+
+// SetDefaultSourceLimits sets the limits applied to sources that do not declare their own
+func (m *ImporterManager) SetDefaultSourceLimits(limits SourceLimits) {
+	m.throttleMu.Lock()
+	defer m.throttleMu.Unlock()
+	m.defaultLimits = limits
+}
+
+// SetDefaultNaming sets the naming strategy of sources that do not declare their own
+func (m *ImporterManager) SetDefaultNaming(strategy NamingStrategy) {
+	m.defaultNaming = strategy
+}
+
+// namingFor returns the naming strategy of a source
+func (m *ImporterManager) namingFor(source SpecSource) NamingStrategy {
+	if source.Naming != nil {
+		return *source.Naming
 	}
+	return m.defaultNaming
+}
+
+// PreviewNaming lists the names a strategy would give the tools of a source
+// without registering them. A nil strategy previews the source's current one.
+func (m *ImporterManager) PreviewNaming(ctx context.Context, sourceID string, strategy *NamingStrategy) ([]ToolNamePreview, error) {
+	source, exists := m.GetSource(sourceID)
+	if !exists {
+		return nil, fmt.Errorf("specification source not found: %s", sourceID)
+	}
+	if strategy == nil {
+		naming := m.namingFor(source)
+		strategy = &naming
+	}
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	importer, exists := m.importers[source.Type]
+	if !exists {
+		return nil, fmt.Errorf("no importer found for spec type: %s", source.Type)
+	}
+	result, err := importer.Import(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+	return strategy.Preview(result.Tools), nil
+}
+
+// SetQuotaPolicy sets how calls are paced as upstream quotas run out
+func (m *ImporterManager) SetQuotaPolicy(policy QuotaPolicy) {
+	m.quotas.SetPolicy(policy)
+}
+
+// OnQuotaExhausted registers a handler invoked when an upstream quota runs out
+func (m *ImporterManager) OnQuotaExhausted(handler QuotaExhaustedHandler) {
+	m.quotas.OnExhausted(handler)
+}
+
+// ListQuotas returns the upstream quotas reported by every source
+func (m *ImporterManager) ListQuotas() []QuotaStatus {
+	return m.quotas.List()
+}
+
+// GetQuotas returns the upstream quotas reported by a source, one per credential
+func (m *ImporterManager) GetQuotas(sourceID string) []QuotaStatus {
+	return m.quotas.ForSource(sourceID)
+}
+
+// GetQuotaSummary totals upstream quota activity across all sources
+func (m *ImporterManager) GetQuotaSummary() QuotaSummary {
+	return m.quotas.Summary()
+}
+
+// GetThrottleStats returns throttling statistics for a source
+func (m *ImporterManager) GetThrottleStats(sourceID string) (ThrottleStats, bool) {
+	m.throttleMu.RLock()
+	defer m.throttleMu.RUnlock()
+
+	throttle, exists := m.throttles[sourceID]
+	if !exists {
+		return ThrottleStats{}, false
+	}
+	return throttle.Stats(), true
+}
+
+// ListThrottleStats returns throttling statistics for every throttled source
+func (m *ImporterManager) ListThrottleStats() []ThrottleStats {
+	m.throttleMu.RLock()
+	defer m.throttleMu.RUnlock()
+
+	stats := make([]ThrottleStats, 0, len(m.throttles))
+	for _, throttle := range m.throttles {
+		stats = append(stats, throttle.Stats())
+	}
+	return stats
+}
+
+// throttleFor returns the throttle for a source, reusing the existing one
+// while its limits are unchanged. It returns nil when the source is unlimited.
+func (m *ImporterManager) throttleFor(source SpecSource) *SourceThrottle {
+	m.throttleMu.Lock()
+	defer m.throttleMu.Unlock()
+
+	limits := m.defaultLimits
+	if source.Limits != nil {
+		limits = *source.Limits
+	}
+	if !limits.Enabled() {
+		delete(m.throttles, source.ID)
+		return nil
+	}
+
+	if throttle, exists := m.throttles[source.ID]; exists && throttle.limits == limits {
+		return throttle
+	}
+	throttle := NewSourceThrottle(source.ID, limits)
+	m.throttles[source.ID] = throttle
+	return throttle
+}
+
+// SetWriteGuard installs a check run before every import, reload or removal.
+// A non-nil error from the guard rejects the operation (e.g. read-only mode).
+func (m *ImporterManager) SetWriteGuard(guard func() error) {
+	m.writeGuard = guard
+}
+
+// checkWritable runs the write guard, if any
+func (m *ImporterManager) checkWritable() error {
+	if m.writeGuard == nil {
+		return nil
+	}
+	return m.writeGuard()
+}
+
+// OnCatalogChange registers a handler invoked whenever a reload changes a source's tools
+func (m *ImporterManager) OnCatalogChange(handler CatalogChangeHandler) {
+	m.catalogMu.Lock()
+	defer m.catalogMu.Unlock()
+	m.changeHandlers = append(m.changeHandlers, handler)
+}
+
+// GetCatalogChanges returns the recorded tool change history for a source, oldest first
+func (m *ImporterManager) GetCatalogChanges(sourceID string) []ToolCatalogDiff {
+	m.catalogMu.RLock()
+	defer m.catalogMu.RUnlock()
+
+	diffs := make([]ToolCatalogDiff, len(m.catalogDiffs[sourceID]))
+	copy(diffs, m.catalogDiffs[sourceID])
+	return diffs
 }
 
 // RegisterImporter registers a new specification importer
 func (m *ImporterManager) RegisterImporter(importer SpecImporter) {
+	if fetching, ok := importer.(fetchingImporter); ok && m.fetcher != nil {
+		fetching.SetFetcher(m.fetcher)
+	}
+	if connecting, ok := importer.(messagingImporter); ok && m.messaging != nil {
+		connecting.SetMessagingPool(m.messaging)
+	}
 	m.importers[importer.GetType()] = importer
 }
 
 // ImportSpec imports a specification and registers the generated tools
 func (m *ImporterManager) ImportSpec(ctx context.Context, source SpecSource) (*ImportResult, error) {
-	// Find appropriate importer
-	importer, exists := m.importers[source.Type]
-	if !exists {
-		return nil, fmt.Errorf("no importer found for spec type: %s", source.Type)
+	if err := m.checkWritable(); err != nil {
+		return nil, err
 	}
 
-	// Validate specification
-	if err := importer.Validate(ctx, source); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+	result, err := m.importSpec(ctx, source)
+	if err != nil {
+		return nil, err
 	}
+	m.persistSource(source, result)
+	return result, nil
+}
 
-	// Import and generate tools
-	result, err := importer.Import(ctx, source)
+// importSpec registers the tools of a specification and records its source
+func (m *ImporterManager) importSpec(ctx context.Context, source SpecSource) (*ImportResult, error) {
+	result, apiTools, err := m.generateTools(ctx, source)
 	if err != nil {
-		return nil, fmt.Errorf("import failed: %w", err)
+		return nil, err
 	}
 
-	// Register tools with the registry
-	for _, tool := range result.Tools {
-		if err := m.registry.Register(tool); err != nil {
+	// Tools left colliding are replaced under the newest-wins policy
+	names := make([]string, len(result.Tools))
+	for i, tool := range result.Tools {
+		names[i] = tool.Name()
+	}
+	for _, conflict := range m.nameConflicts(source.ID, names) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("tool %s registered by %s is replaced by this import", conflict.ToolName, conflict.Owner))
+	}
+
+	// Register tools with the registry, throttled by the source limits and
+	// paced against the upstream quota. Synthetic tools never reach the API.
+	throttle := m.throttleFor(source)
+	for i, tool := range result.Tools {
+		registered := tool
+		if i < apiTools {
+			if throttle != nil {
+				registered = &throttledTool{Tool: registered, throttle: throttle}
+			}
+			registered = &quotaTool{Tool: registered, sourceID: source.ID, tracker: m.quotas}
+		}
+		if err := m.registry.Register(registered); err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("failed to register tool %s: %w", tool.Name(), err))
 		}
 	}
 
 	// Store source information
+	m.sourcesMu.Lock()
 	m.sources[source.ID] = source
+	m.sourcesMu.Unlock()
+
+	// Replaced tools now belong to this source
+	m.takeOverTools(source.ID, result.Tools)
+	catalog := make([]types.ToolMetadata, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		catalog = append(catalog, tool.Metadata())
+	}
+	m.catalogMu.Lock()
+	m.catalogs[source.ID] = catalog
+	m.catalogMu.Unlock()
 
 	return result, nil
 }
 
-// RemoveSpec removes a specification and unregisters its tools
-func (m *ImporterManager) RemoveSpec(ctx context.Context, sourceID string) error {
-	source, exists := m.sources[sourceID]
+// generateTools validates and imports a specification, then adds synthetic
+// data tools, applies the naming strategy and namespace, and resolves name
+// collisions. The first apiTools tools call the upstream API; the rest are
+// synthetic.
+func (m *ImporterManager) generateTools(ctx context.Context, source SpecSource) (*ImportResult, int, error) {
+	// Find appropriate importer
+	importer, exists := m.importers[source.Type]
 	if !exists {
-		return fmt.Errorf("specification source not found: %s", sourceID)
+		return nil, 0, fmt.Errorf("no importer found for spec type: %s", source.Type)
+	}
+	naming := m.namingFor(source)
+	if err := naming.Validate(); err != nil {
+		return nil, 0, fmt.Errorf("invalid naming strategy: %w", err)
+	}
+	if err := validateNamespace(source.Namespace); err != nil {
+		return nil, 0, err
+	}
+	if err := m.collisionPolicyFor(source).Validate(); err != nil {
+		return nil, 0, err
 	}
 
-	// Find importer
-	importer, exists := m.importers[source.Type]
-	if !exists {
-		return fmt.Errorf("no importer found for spec type: %s", source.Type)
+	// Validate specification
+	if err := importer.Validate(ctx, source); err != nil {
+		return nil, 0, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Re-import to get tool names (we could cache this for efficiency)
+	// Import and generate tools
 	result, err := importer.Import(ctx, source)
 	if err != nil {
-		return fmt.Errorf("failed to re-import for removal: %w", err)
+		return nil, 0, fmt.Errorf("import failed: %w", err)
+	}
+	// Synthetic data tools follow the real ones and share their naming
+	apiTools := len(result.Tools)
+	if m.synthetic.Enabled {
+		result.Tools = append(result.Tools, syntheticTools(source, result.Schemas, m.synthetic.MaxCount)...)
+	}
+	result.Tools = applyNamespace(source.Namespace, applyNaming(naming, result.Tools))
+	result.Tools, apiTools = m.resolveCollisions(source, result.Tools, apiTools, result)
+	return result, apiTools, nil
+}
+
+// RemoveSpec removes a specification and unregisters its tools
+func (m *ImporterManager) RemoveSpec(ctx context.Context, sourceID string) error {
+	if err := m.removeSpec(ctx, sourceID); err != nil {
+		return err
+	}
+	m.quotas.Forget(sourceID)
+	if m.store != nil {
+		if err := m.store.Delete(sourceID); err != nil {
+			return fmt.Errorf("specification removed but still stored: %w", err)
+		}
+	}
+	return nil
+}
+
+// removeSpec unregisters a specification's tools. Upstream quotas are kept so
+// a reload does not forget them.
+func (m *ImporterManager) removeSpec(ctx context.Context, sourceID string) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+
+	source, exists := m.GetSource(sourceID)
+	if !exists {
+		return fmt.Errorf("specification source not found: %s", sourceID)
+	}
+
+	// Use the catalog recorded at import time so tools dropped from the
+	// spec since then are still unregistered
+	m.catalogMu.Lock()
+	catalog, cached := m.catalogs[sourceID]
+	delete(m.catalogs, sourceID)
+	m.catalogMu.Unlock()
+
+	toolNames := make([]string, 0, len(catalog))
+	if cached {
+		for _, metadata := range catalog {
+			toolNames = append(toolNames, metadata.Name)
+		}
+	} else {
+		// Find importer
+		importer, exists := m.importers[source.Type]
+		if !exists {
+			return fmt.Errorf("no importer found for spec type: %s", source.Type)
+		}
+
+		// Re-import to get tool names
+		result, err := importer.Import(ctx, source)
+		if err != nil {
+			return fmt.Errorf("failed to re-import for removal: %w", err)
+		}
+		for _, tool := range applyNamespace(source.Namespace, applyNaming(m.namingFor(source), result.Tools)) {
+			toolNames = append(toolNames, tool.Name())
+		}
 	}
 
 	// Unregister tools
-	for _, tool := range result.Tools {
-		if err := m.registry.Unregister(tool.Name()); err != nil {
+	for _, name := range toolNames {
+		if err := m.registry.Unregister(name); err != nil {
 			// Log warning but continue
 			continue
 		}
 	}
 
 	// Remove source
+	m.sourcesMu.Lock()
 	delete(m.sources, sourceID)
+	m.sourcesMu.Unlock()
+	m.throttleMu.Lock()
+	delete(m.throttles, sourceID)
+	m.throttleMu.Unlock()
 
 	return nil
 }
 
 // ReloadSpec reloads a specification (useful for file watching)
 func (m *ImporterManager) ReloadSpec(ctx context.Context, sourceID string) (*ImportResult, error) {
-	source, exists := m.sources[sourceID]
+	if err := m.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	source, exists := m.GetSource(sourceID)
 	if !exists {
 		return nil, fmt.Errorf("specification source not found: %s", sourceID)
 	}
 
+	m.catalogMu.RLock()
+	previous := m.catalogs[sourceID]
+	m.catalogMu.RUnlock()
+
+	// Keep the throttle (and its statistics) across the reload
+	m.throttleMu.RLock()
+	throttle := m.throttles[sourceID]
+	m.throttleMu.RUnlock()
+
 	// Remove existing tools
-	if err := m.RemoveSpec(ctx, sourceID); err != nil {
+	if err := m.removeSpec(ctx, sourceID); err != nil {
 		return nil, fmt.Errorf("failed to remove existing spec: %w", err)
 	}
 
+	if throttle != nil {
+		m.throttleMu.Lock()
+		m.throttles[sourceID] = throttle
+		m.throttleMu.Unlock()
+	}
+
 	// Re-import
 	source.UpdatedAt = time.Now()
-	return m.ImportSpec(ctx, source)
+	result, err := m.ImportSpec(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	m.catalogMu.RLock()
+	current := m.catalogs[sourceID]
+	m.catalogMu.RUnlock()
+
+	if diff := DiffToolCatalogs(sourceID, previous, current); diff.HasChanges() {
+		m.recordCatalogDiff(diff)
+	}
+
+	return result, nil
+}
+
+// recordCatalogDiff stores a tool change diff and notifies change handlers
+func (m *ImporterManager) recordCatalogDiff(diff ToolCatalogDiff) {
+	m.catalogMu.Lock()
+	history := append(m.catalogDiffs[diff.SourceID], diff)
+	if len(history) > maxCatalogDiffsPerSource {
+		history = history[len(history)-maxCatalogDiffsPerSource:]
+	}
+	m.catalogDiffs[diff.SourceID] = history
+	handlers := make([]CatalogChangeHandler, len(m.changeHandlers))
+	copy(handlers, m.changeHandlers)
+	m.catalogMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(diff)
+	}
 }
 
 // ListSources returns all registered specification sources
 func (m *ImporterManager) ListSources() []SpecSource {
+	m.sourcesMu.RLock()
+	defer m.sourcesMu.RUnlock()
 	sources := make([]SpecSource, 0, len(m.sources))
 	for _, source := range m.sources {
 		sources = append(sources, source)
@@ -174,6 +582,8 @@ func (m *ImporterManager) ListSources() []SpecSource {
 
 // GetSource returns a specific specification source
 func (m *ImporterManager) GetSource(sourceID string) (SpecSource, bool) {
+	m.sourcesMu.RLock()
+	defer m.sourcesMu.RUnlock()
 	source, exists := m.sources[sourceID]
 	return source, exists
 }
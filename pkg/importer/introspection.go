@@ -0,0 +1,300 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Source metadata keys for importing a live GraphQL endpoint
+const (
+	// introspectKey set to "true" imports a GraphQL source whose path is an
+	// endpoint URL by running an introspection query against it
+	introspectKey = "introspect"
+
+	// headerMetadataPrefix marks metadata sent as request headers to a GraphQL
+	// endpoint, e.g. header.Authorization, on introspection and every call
+	headerMetadataPrefix = "header."
+)
+
+// introspectionQuery asks an endpoint for every type of its schema, deep
+// enough to resolve wrapped types such as [Post!]!
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types { ...FullType }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } }
+}`
+
+// builtinScalars are predefined by GraphQL and left out of generated schemas
+var builtinScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+// introspects reports whether a GraphQL source is imported from a live endpoint
+func introspects(source SpecSource) bool {
+	return source.Type == SpecTypeGraphQL && isRemoteSpec(source.Path) && strings.EqualFold(source.Metadata[introspectKey], "true")
+}
+
+// sourceHeaders returns the headers a source's metadata sends to its endpoint
+func sourceHeaders(source SpecSource) http.Header {
+	header := http.Header{}
+	for key, value := range source.Metadata {
+		if name, ok := strings.CutPrefix(key, headerMetadataPrefix); ok && name != "" {
+			header.Set(name, value)
+		}
+	}
+	return header
+}
+
+// introspect runs the introspection query against a source's endpoint and
+// returns the raw response
+func introspect(ctx context.Context, fetcher *SpecFetcher, source SpecSource) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{"query": introspectionQuery, "operationName": "IntrospectionQuery"})
+	if err != nil {
+		return nil, err
+	}
+	content, err := fetcher.Post(ctx, source.Path, source.Auth, sourceHeaders(source), body)
+	if err != nil {
+		return nil, fmt.Errorf("introspection query failed: %w", err)
+	}
+	return content, nil
+}
+
+// introspectionResponse is the answer to introspectionQuery
+type introspectionResponse struct {
+	Data struct {
+		Schema *introspectionSchema `json:"__schema"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type introspectionSchema struct {
+	QueryType    *introspectionTypeRef `json:"queryType"`
+	MutationType *introspectionTypeRef `json:"mutationType"`
+	Types        []introspectionType   `json:"types"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Description   string                    `json:"description"`
+	Fields        []introspectionField      `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	EnumValues    []introspectionEnumValue  `json:"enumValues"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionField struct {
+	Name              string                    `json:"name"`
+	Description       string                    `json:"description"`
+	Args              []introspectionInputValue `json:"args"`
+	Type              introspectionTypeRef      `json:"type"`
+	IsDeprecated      bool                      `json:"isDeprecated"`
+	DeprecationReason string                    `json:"deprecationReason"`
+}
+
+type introspectionInputValue struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Type         introspectionTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionEnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+// String writes the type reference in SDL, e.g. [Post!]!
+func (r introspectionTypeRef) String() string {
+	switch {
+	case r.Kind == "NON_NULL" && r.OfType != nil:
+		return r.OfType.String() + "!"
+	case r.Kind == "LIST" && r.OfType != nil:
+		return "[" + r.OfType.String() + "]"
+	}
+	return r.Name
+}
+
+// introspectionSDL converts an introspection response to a schema in SDL, so
+// introspected endpoints are imported like schema files
+func introspectionSDL(content []byte) (string, error) {
+	var response introspectionResponse
+	if err := json.Unmarshal(content, &response); err != nil {
+		return "", fmt.Errorf("invalid introspection response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i, graphQLError := range response.Errors {
+			messages[i] = graphQLError.Message
+		}
+		return "", fmt.Errorf("introspection query failed: %s", strings.Join(messages, "; "))
+	}
+	schema := response.Data.Schema
+	if schema == nil {
+		return "", fmt.Errorf("introspection response has no schema")
+	}
+
+	var sdl strings.Builder
+
+	// Name the root types when they do not use the default names
+	if (schema.QueryType != nil && schema.QueryType.Name != "Query") || (schema.MutationType != nil && schema.MutationType.Name != "Mutation") {
+		sdl.WriteString("schema {\n")
+		if schema.QueryType != nil {
+			sdl.WriteString("  query: " + schema.QueryType.Name + "\n")
+		}
+		if schema.MutationType != nil {
+			sdl.WriteString("  mutation: " + schema.MutationType.Name + "\n")
+		}
+		sdl.WriteString("}\n\n")
+	}
+
+	for _, definition := range schema.Types {
+		if strings.HasPrefix(definition.Name, "__") || builtinScalars[definition.Name] {
+			continue
+		}
+		var body strings.Builder
+		switch definition.Kind {
+		case "SCALAR":
+			body.WriteString("scalar " + definition.Name + "\n")
+		case "OBJECT", "INTERFACE":
+			if len(definition.Fields) == 0 {
+				continue
+			}
+			keyword := "type"
+			if definition.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			body.WriteString(keyword + " " + definition.Name)
+			if len(definition.Interfaces) > 0 {
+				names := make([]string, len(definition.Interfaces))
+				for i, implemented := range definition.Interfaces {
+					names[i] = implemented.Name
+				}
+				body.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			body.WriteString(" {\n")
+			for _, field := range definition.Fields {
+				writeSDLDescription(&body, field.Description, "  ")
+				body.WriteString("  " + field.Name)
+				if len(field.Args) > 0 {
+					args := make([]string, len(field.Args))
+					for i, arg := range field.Args {
+						args[i] = sdlInputValue(arg)
+					}
+					body.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				body.WriteString(": " + field.Type.String())
+				body.WriteString(sdlDeprecation(field.IsDeprecated, field.DeprecationReason) + "\n")
+			}
+			body.WriteString("}\n")
+		case "UNION":
+			names := make([]string, len(definition.PossibleTypes))
+			for i, member := range definition.PossibleTypes {
+				names[i] = member.Name
+			}
+			body.WriteString("union " + definition.Name + " = " + strings.Join(names, " | ") + "\n")
+		case "ENUM":
+			body.WriteString("enum " + definition.Name + " {\n")
+			for _, value := range definition.EnumValues {
+				writeSDLDescription(&body, value.Description, "  ")
+				body.WriteString("  " + value.Name + sdlDeprecation(value.IsDeprecated, value.DeprecationReason) + "\n")
+			}
+			body.WriteString("}\n")
+		case "INPUT_OBJECT":
+			body.WriteString("input " + definition.Name + " {\n")
+			for _, field := range definition.InputFields {
+				writeSDLDescription(&body, field.Description, "  ")
+				body.WriteString("  " + sdlInputValue(field) + "\n")
+			}
+			body.WriteString("}\n")
+		default:
+			continue
+		}
+		writeSDLDescription(&sdl, definition.Description, "")
+		sdl.WriteString(body.String() + "\n")
+	}
+	return sdl.String(), nil
+}
+
+// sdlInputValue writes an argument or input field with its default value
+func sdlInputValue(value introspectionInputValue) string {
+	text := value.Name + ": " + value.Type.String()
+	if value.DefaultValue != nil {
+		text += " = " + *value.DefaultValue
+	}
+	return text
+}
+
+// sdlDeprecation writes the @deprecated directive of a deprecated field or value
+func sdlDeprecation(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" {
+		return " @deprecated"
+	}
+	return " @deprecated(reason: " + sdlString(reason) + ")"
+}
+
+// writeSDLDescription writes a description preceding a definition
+func writeSDLDescription(sdl *strings.Builder, description, indent string) {
+	if description != "" {
+		sdl.WriteString(indent + sdlString(description) + "\n")
+	}
+}
+
+// sdlString quotes a string; JSON escapes are valid GraphQL string escapes
+func sdlString(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
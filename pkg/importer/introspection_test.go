@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// liveGraphQLServer serves a schema with renamed root types to callers
+// presenting an API key
+func liveGraphQLServer(t *testing.T) *httptest.Server {
+	user := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name": &graphql.Field{Type: graphql.String, Description: `Display "name"`},
+		},
+	})
+	role := graphql.NewEnum(graphql.EnumConfig{
+		Name:   "Role",
+		Values: graphql.EnumValueConfigMap{"ADMIN": {}, "GUEST": {DeprecationReason: "Use ADMIN"}},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "QueryRoot",
+			Fields: graphql.Fields{
+				"user": &graphql.Field{
+					Type: user,
+					Args: graphql.FieldConfigArgument{
+						"id":   {Type: graphql.NewNonNull(graphql.ID)},
+						"role": {Type: role, DefaultValue: "ADMIN"},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return map[string]interface{}{"id": p.Args["id"], "name": "Ada"}, nil
+					},
+				},
+				"legacyUsers": &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(user)), DeprecationReason: "Use user"},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "MutationRoot",
+			Fields: graphql.Fields{
+				"rename": &graphql.Field{
+					Type: user,
+					Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}, "name": {Type: graphql.String}},
+				},
+			},
+		}),
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "k1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var request struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		result := graphql.Do(graphql.Params{Schema: schema, RequestString: request.Query, VariableValues: request.Variables})
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGraphQLIntrospectionImport(t *testing.T) {
+	server := liveGraphQLServer(t)
+	ctx := context.Background()
+	importer := NewGraphQLImporter()
+
+	source := SpecSource{ID: "live", Type: SpecTypeGraphQL, Path: server.URL + "/graphql", Metadata: map[string]string{
+		"introspect":       "true",
+		"header.X-Api-Key": "k1",
+	}}
+	require.NoError(t, importer.Validate(ctx, source))
+	result, err := importer.Import(ctx, source)
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+
+	tools := make(map[string]*GraphQLTool)
+	for _, tool := range result.Tools {
+		tools[tool.Name()] = tool.(*GraphQLTool)
+	}
+	require.Len(t, tools, 3)
+
+	// Renamed root types, wrapped types, defaults and deprecations survive
+	userTool := tools["graphql.live.query_user"]
+	require.NotNil(t, userTool)
+	assert.Equal(t, server.URL+"/graphql", userTool.endpoint)
+	assert.Contains(t, userTool.schema, "id: ID!")
+	assert.Contains(t, userTool.schema, "role: Role = ")
+	assert.Contains(t, userTool.schema, `GUEST @deprecated(reason: "Use ADMIN")`)
+	assert.Contains(t, userTool.schema, `"Display \"name\""`)
+	assert.Equal(t, []string{"id"}, userTool.Metadata().Schema["input"].(map[string]interface{})["required"])
+	legacy := tools["graphql.live.query_legacyUsers"]
+	require.NotNil(t, legacy)
+	assert.Equal(t, "Use user", legacy.Metadata().Deprecation.Reason)
+	assert.NotNil(t, tools["graphql.live.mutation_rename"])
+
+	// Calls send the metadata headers too
+	response, err := userTool.Execute(ctx, map[string]interface{}{"id": "7"})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.(map[string]interface{})["status_code"])
+	assert.Equal(t, map[string]interface{}{"id": "7", "name": "Ada"}, response.(map[string]interface{})["data"].(map[string]interface{})["user"])
+
+	// The headers are masked when the source is serialized
+	encoded, err := json.Marshal(source)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "k1")
+
+	// Without the header the endpoint refuses the introspection query
+	delete(source.Metadata, "header.X-Api-Key")
+	_, err = importer.Import(ctx, source)
+	assert.ErrorContains(t, err, "introspection query failed")
+}
+
+func TestIntrospectionSDLErrors(t *testing.T) {
+	_, err := introspectionSDL([]byte(`{"errors": [{"message": "introspection disabled"}]}`))
+	assert.ErrorContains(t, err, "introspection disabled")
+
+	_, err = introspectionSDL([]byte(`{"data": {}}`))
+	assert.ErrorContains(t, err, "no schema")
+
+	_, err = introspectionSDL([]byte(`<html>`))
+	assert.ErrorContains(t, err, "invalid introspection response")
+}
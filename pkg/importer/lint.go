@@ -0,0 +1,219 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LintSeverity tells whether an issue stops a specification from importing
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+// Lint issue codes
+const (
+	LintInvalidNaming      = "invalid_naming"       // The naming strategy is invalid
+	LintInvalidSpec        = "invalid_spec"         // The importer rejects the specification
+	LintUnresolvedRef      = "unresolved_ref"       // A $ref points nowhere in the document
+	LintMissingOperationID = "missing_operation_id" // The tool is named after the path instead
+	LintImportFailed       = "import_failed"        // Generating the tools failed
+	LintToolError          = "tool_error"           // One tool could not be generated
+	LintImportWarning      = "import_warning"       // The import itself warns, e.g. about schema violations
+	LintDuplicateToolName  = "duplicate_tool_name"  // Several tools of the source share a name
+	LintToolNameConflict   = "tool_name_conflict"   // The import would replace a tool it does not own
+)
+
+// LintIssue is a problem found in a specification
+type LintIssue struct {
+	Severity LintSeverity `json:"severity"`
+	Code     string       `json:"code"`
+	Message  string       `json:"message"`
+	Location string       `json:"location,omitempty"` // e.g. "GET /pets", a JSON pointer or a tool name
+}
+
+// SpecValidation is the outcome of linting a specification
+type SpecValidation struct {
+	Source    SpecSource    `json:"source"`
+	Valid     bool          `json:"valid"` // No errors; warnings do not stop an import
+	Errors    []LintIssue   `json:"errors"`
+	Warnings  []LintIssue   `json:"warnings"`
+	ToolCount int           `json:"tool_count"` // Tools the import would register
+	Duration  time.Duration `json:"duration"`
+}
+
+// add records an issue under its severity
+func (v *SpecValidation) add(issue LintIssue) {
+	if issue.Severity == LintError {
+		v.Errors = append(v.Errors, issue)
+	} else {
+		v.Warnings = append(v.Warnings, issue)
+	}
+}
+
+// specLinter is implemented by importers that check their specifications
+// beyond what importing requires
+type specLinter interface {
+	Lint(ctx context.Context, source SpecSource) []LintIssue
+}
+
+// ValidateSpec checks a specification without importing it: the importer's
+// validation, its deeper lint checks, and the tools an import would generate,
+// which must have unique names. It registers nothing and works in read-only
+// mode. Only an unknown spec type is returned as an error.
+func (m *ImporterManager) ValidateSpec(ctx context.Context, source SpecSource) (*SpecValidation, error) {
+	started := time.Now()
+	importer, exists := m.importers[source.Type]
+	if !exists {
+		return nil, fmt.Errorf("no importer found for spec type: %s", source.Type)
+	}
+
+	validation := &SpecValidation{Source: source, Errors: []LintIssue{}, Warnings: []LintIssue{}}
+	if err := m.namingFor(source).Validate(); err != nil {
+		validation.add(LintIssue{Severity: LintError, Code: LintInvalidNaming, Message: err.Error()})
+	}
+	if err := importer.Validate(ctx, source); err != nil {
+		validation.add(LintIssue{Severity: LintError, Code: LintInvalidSpec, Message: err.Error()})
+	}
+	if linter, ok := importer.(specLinter); ok {
+		for _, issue := range linter.Lint(ctx, source) {
+			validation.add(issue)
+		}
+	}
+
+	// Generate the tools of a specification that loads
+	if len(validation.Errors) == 0 {
+		m.lintTools(ctx, source, validation)
+	}
+
+	validation.Valid = len(validation.Errors) == 0
+	validation.Duration = time.Since(started)
+	return validation, nil
+}
+
+// lintTools generates the tools of a source and checks their names
+func (m *ImporterManager) lintTools(ctx context.Context, source SpecSource, validation *SpecValidation) {
+	result, _, err := m.generateTools(ctx, source)
+	if err != nil {
+		validation.add(LintIssue{Severity: LintError, Code: LintImportFailed, Message: err.Error()})
+		return
+	}
+	for _, toolErr := range result.Errors {
+		validation.add(LintIssue{Severity: LintError, Code: LintToolError, Message: toolErr.Error()})
+	}
+	for _, warning := range result.Warnings {
+		validation.add(LintIssue{Severity: LintWarning, Code: LintImportWarning, Message: warning})
+	}
+	validation.ToolCount = len(result.Tools)
+
+	// Tools sharing a name would replace each other in the registry
+	counts := make(map[string]int, len(result.Tools))
+	for _, tool := range result.Tools {
+		counts[tool.Name()]++
+	}
+	names := make([]string, 0, len(counts))
+	for name, count := range counts {
+		if count > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		validation.add(LintIssue{
+			Severity: LintError,
+			Code:     LintDuplicateToolName,
+			Message:  fmt.Sprintf("%d operations generate the tool name %s", counts[name], name),
+			Location: name,
+		})
+	}
+
+	// Tools other sources or the server register would be replaced
+	manifest := make([]string, 0, len(counts))
+	for name := range counts {
+		manifest = append(manifest, name)
+	}
+	sort.Strings(manifest)
+	for _, conflict := range m.nameConflicts(source.ID, manifest) {
+		validation.add(LintIssue{
+			Severity: LintWarning,
+			Code:     LintToolNameConflict,
+			Message:  fmt.Sprintf("%s is already registered by %s and would be replaced", conflict.ToolName, conflict.Owner),
+			Location: conflict.ToolName,
+		})
+	}
+}
+
+// unresolvedRefs finds the local $ref values of a decoded document that do
+// not point to a value in it. References to other documents are not followed.
+func unresolvedRefs(document map[string]interface{}) []LintIssue {
+	var issues []LintIssue
+	var walk func(value interface{}, pointer string)
+	walk = func(value interface{}, pointer string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+				if _, found := resolvePointer(document, strings.TrimPrefix(ref, "#")); !found {
+					issues = append(issues, LintIssue{
+						Severity: LintError,
+						Code:     LintUnresolvedRef,
+						Message:  fmt.Sprintf("reference %s does not resolve", ref),
+						Location: pointer + "/$ref",
+					})
+				}
+			}
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key], pointer+"/"+escapePointer(key))
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(item, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	}
+	walk(document, "#")
+	return issues
+}
+
+// resolvePointer follows a JSON pointer such as /components/schemas/Pet
+func resolvePointer(document map[string]interface{}, pointer string) (interface{}, bool) {
+	var current interface{} = document
+	if pointer == "" {
+		return current, true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// escapePointer escapes a key for use in a JSON pointer
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lintedSpec references a schema it does not define and leaves an operation
+// without an operationId
+const lintedSpec = `openapi: 3.0.3
+info:
+  title: Linted
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pets"
+    post:
+      responses:
+        "201":
+          description: Created
+components:
+  schemas:
+    Pet:
+      type: object
+`
+
+func TestImporterManager_ValidateSpec(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manager := NewImporterManager(mapRegistry{})
+	manager.RegisterImporter(NewOpenAPIImporter())
+
+	path := filepath.Join(dir, "linted.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(lintedSpec), 0o644))
+	validation, err := manager.ValidateSpec(ctx, SpecSource{ID: "linted", Type: SpecTypeOpenAPI, Path: path})
+	require.NoError(t, err)
+	assert.False(t, validation.Valid)
+	codes := map[string]LintIssue{}
+	for _, issue := range append(validation.Errors, validation.Warnings...) {
+		codes[issue.Code] = issue
+	}
+	assert.Contains(t, codes, LintInvalidSpec)
+	assert.Equal(t, LintIssue{
+		Severity: LintError,
+		Code:     LintUnresolvedRef,
+		Message:  "reference #/components/schemas/Pets does not resolve",
+		Location: "#/paths/~1pets/get/responses/200/content/application~1json/schema/$ref",
+	}, codes[LintUnresolvedRef])
+	assert.Equal(t, LintWarning, codes[LintMissingOperationID].Severity)
+	assert.Equal(t, "POST /pets", codes[LintMissingOperationID].Location)
+	assert.Empty(t, manager.ListSources())
+
+	// Once the reference resolves the spec is valid; the warning stays
+	fixed := []byte(lintedSpec[:len(lintedSpec)-len("    Pet:\n      type: object\n")] + "    Pets:\n      type: array\n      items:\n        type: object\n")
+	require.NoError(t, os.WriteFile(path, fixed, 0o644))
+	validation, err = manager.ValidateSpec(ctx, SpecSource{ID: "linted", Type: SpecTypeOpenAPI, Path: path})
+	require.NoError(t, err)
+	assert.True(t, validation.Valid, validation.Errors)
+	assert.Equal(t, 2, validation.ToolCount)
+	require.Len(t, validation.Warnings, 1)
+	assert.Equal(t, LintMissingOperationID, validation.Warnings[0].Code)
+
+	_, err = manager.ValidateSpec(ctx, SpecSource{ID: "linted", Type: SpecTypeGraphQL, Path: path})
+	assert.Error(t, err)
+}
+
+// duplicateImporter generates two tools with the same name
+type duplicateImporter struct{ stubImporter }
+
+func (duplicateImporter) Import(ctx context.Context, source SpecSource) (*ImportResult, error) {
+	return &ImportResult{Source: source, Tools: []types.Tool{
+		stubTool{name: "openapi." + source.ID + ".list"},
+		stubTool{name: "openapi." + source.ID + ".list"},
+		stubTool{name: "openapi." + source.ID + ".get"},
+	}}, nil
+}
+
+func TestImporterManager_ValidateSpecToolNames(t *testing.T) {
+	ctx := context.Background()
+	registry := lookupRegistry{mapRegistry{"openapi.users.get": stubTool{name: "openapi.users.get"}}}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(duplicateImporter{})
+
+	validation, err := manager.ValidateSpec(ctx, SpecSource{ID: "users", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	assert.False(t, validation.Valid)
+	require.Len(t, validation.Errors, 1)
+	assert.Equal(t, LintDuplicateToolName, validation.Errors[0].Code)
+	assert.Equal(t, "openapi.users.list", validation.Errors[0].Location)
+	require.Len(t, validation.Warnings, 1)
+	assert.Equal(t, LintToolNameConflict, validation.Warnings[0].Code)
+	assert.Equal(t, "openapi.users.get is already registered by server and would be replaced", validation.Warnings[0].Message)
+	assert.Len(t, registry.mapRegistry, 1)
+}
+
+func TestResolvePointer(t *testing.T) {
+	document := map[string]interface{}{
+		"paths": map[string]interface{}{"/pets": map[string]interface{}{"parameters": []interface{}{"limit"}}},
+		"a~b":   true,
+	}
+	value, found := resolvePointer(document, "/paths/~1pets/parameters/0")
+	assert.True(t, found)
+	assert.Equal(t, "limit", value)
+	_, found = resolvePointer(document, "/a~0b")
+	assert.True(t, found)
+	_, found = resolvePointer(document, "/paths/~1pets/parameters/1")
+	assert.False(t, found)
+}
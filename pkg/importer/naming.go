@@ -0,0 +1,331 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// NameCase selects how the operation part of a tool name is written
+type NameCase string
+
+const (
+	NameCaseKeep  NameCase = ""      // As generated by the importer
+	NameCaseSnake NameCase = "snake" // list_user_events
+	NameCaseCamel NameCase = "camel" // listUserEvents
+)
+
+// minNamingLength is the shortest max_length a strategy accepts, leaving room
+// for the "<type>.<source>." prefix and a readable operation
+const minNamingLength = 16
+
+// NamingStrategy controls the names of a source's tools. Only the operation
+// after the "<type>.<source>." prefix is rewritten, so names stay unique
+// across sources and attributable to them.
+type NamingStrategy struct {
+	Case      NameCase `json:"case,omitempty"`
+	VerbNoun  bool     `json:"verb_noun,omitempty"`  // Derive the operation from the summary, e.g. "List all pets" -> list_pets
+	MaxLength int      `json:"max_length,omitempty"` // Of the full name; zero is unlimited
+	Reserved  []string `json:"reserved,omitempty"`   // Operations to avoid beyond the language keywords
+}
+
+// Enabled reports whether the strategy changes any name
+func (s NamingStrategy) Enabled() bool {
+	return s.Case != NameCaseKeep || s.VerbNoun || s.MaxLength > 0 || len(s.Reserved) > 0
+}
+
+// Validate checks the strategy settings
+func (s NamingStrategy) Validate() error {
+	switch s.Case {
+	case NameCaseKeep, NameCaseSnake, NameCaseCamel:
+	default:
+		return fmt.Errorf("invalid naming case %q: must be snake or camel", s.Case)
+	}
+	if s.MaxLength < 0 || (s.MaxLength > 0 && s.MaxLength < minNamingLength) {
+		return fmt.Errorf("naming max_length must be 0 or at least %d", minNamingLength)
+	}
+	return nil
+}
+
+// ToolNamePreview shows the name a strategy gives a tool
+type ToolNamePreview struct {
+	Original string `json:"original"`
+	Name     string `json:"name"`
+	Changed  bool   `json:"changed"`
+}
+
+// reservedOperations are keywords of the languages agents commonly generate
+// client code in; an operation named after one gets a "tool" suffix
+var reservedOperations = []string{
+	"and", "as", "async", "await", "break", "case", "catch", "class", "const", "continue",
+	"def", "default", "delete", "do", "else", "enum", "export", "extends", "false", "finally",
+	"for", "from", "function", "global", "if", "import", "in", "instanceof", "is", "lambda",
+	"let", "new", "none", "not", "null", "or", "pass", "raise", "return", "self",
+	"super", "switch", "this", "throw", "true", "try", "typeof", "var", "void", "while",
+	"with", "yield",
+}
+
+// Names computes the name of every tool under the strategy, keyed by the
+// original name. Tools whose names collide get numeric suffixes.
+func (s NamingStrategy) Names(tools []types.Tool) map[string]string {
+	names := make(map[string]string, len(tools))
+	if !s.Enabled() {
+		for _, tool := range tools {
+			names[tool.Name()] = tool.Name()
+		}
+		return names
+	}
+
+	reserved := make(map[string]bool, len(reservedOperations)+len(s.Reserved))
+	for _, word := range append(reservedOperations, s.Reserved...) {
+		reserved[strings.ToLower(word)] = true
+	}
+
+	type candidate struct {
+		original, prefix string
+		fallback         []string // Words of the generated operation
+		words            []string
+	}
+	candidates := make([]*candidate, 0, len(tools))
+	for _, tool := range tools {
+		prefix, operation := splitToolName(tool.Name())
+		c := &candidate{original: tool.Name(), prefix: prefix, fallback: nameWords(operation)}
+		if s.VerbNoun {
+			c.words = verbNounWords(tool.Description())
+		}
+		if len(c.words) == 0 {
+			c.words = c.fallback
+		}
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].original < candidates[j].original })
+
+	// Summaries shared by several tools (e.g. "Publish message to X channel")
+	// say too little to tell them apart, so those keep the generated words
+	if s.VerbNoun {
+		counts := make(map[string]int)
+		for _, c := range candidates {
+			counts[c.prefix+strings.Join(c.words, " ")]++
+		}
+		for _, c := range candidates {
+			if counts[c.prefix+strings.Join(c.words, " ")] > 1 {
+				c.words = c.fallback
+			}
+		}
+	}
+
+	used := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		_, operation := splitToolName(c.original)
+		if s.Case != NameCaseKeep || s.VerbNoun {
+			operation = s.format(c.words)
+		}
+		if reserved[strings.ToLower(operation)] {
+			operation = s.format(append(nameWords(operation), "tool"))
+		}
+
+		name := s.truncate(c.prefix, operation, "")
+		for n := 2; used[name]; n++ {
+			suffix := fmt.Sprintf("%d", n)
+			if s.Case != NameCaseCamel {
+				suffix = "_" + suffix
+			}
+			name = s.truncate(c.prefix, operation, suffix)
+		}
+		used[name] = true
+		names[c.original] = name
+	}
+	return names
+}
+
+// Preview lists the name the strategy gives each tool, sorted by original name
+func (s NamingStrategy) Preview(tools []types.Tool) []ToolNamePreview {
+	names := s.Names(tools)
+	previews := make([]ToolNamePreview, 0, len(names))
+	for original, name := range names {
+		previews = append(previews, ToolNamePreview{Original: original, Name: name, Changed: original != name})
+	}
+	sort.Slice(previews, func(i, j int) bool { return previews[i].Original < previews[j].Original })
+	return previews
+}
+
+// format joins words in the strategy's case; kept names use snake case
+func (s NamingStrategy) format(words []string) string {
+	if s.Case != NameCaseCamel {
+		return strings.Join(words, "_")
+	}
+	var builder strings.Builder
+	for i, word := range words {
+		if i > 0 && word != "" {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		builder.WriteString(word)
+	}
+	return builder.String()
+}
+
+// truncate fits prefix, operation and suffix into the maximum length,
+// shortening the operation
+func (s NamingStrategy) truncate(prefix, operation, suffix string) string {
+	if s.MaxLength > 0 {
+		room := s.MaxLength - len(prefix) - len(suffix)
+		if room < 1 {
+			room = 1
+		}
+		if len(operation) > room {
+			operation = strings.TrimRight(operation[:room], "_")
+		}
+	}
+	return prefix + operation + suffix
+}
+
+// splitToolName separates the "<type>.<source>." prefix from the operation
+func splitToolName(name string) (string, string) {
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) < 3 {
+		return "", name
+	}
+	return parts[0] + "." + parts[1] + ".", parts[2]
+}
+
+// nameWords splits an identifier into lower-case words at separators and
+// case changes: "getHTTPStatus_v2" -> get, http, status, v2
+func nameWords(identifier string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	runes := []rune(identifier)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+// summaryVerbs maps the leading verb of a summary to the verb of a name
+var summaryVerbs = map[string]string{
+	"list": "list", "lists": "list",
+	"get": "get", "gets": "get", "retrieve": "get", "retrieves": "get", "fetch": "get", "fetches": "get",
+	"return": "get", "returns": "get", "read": "get", "reads": "get", "show": "get", "shows": "get",
+	"find": "find", "finds": "find", "search": "search", "searches": "search", "count": "count", "counts": "count",
+	"create": "create", "creates": "create", "add": "add", "adds": "add", "place": "place", "places": "place",
+	"update": "update", "updates": "update", "modify": "update", "modifies": "update", "replace": "replace", "replaces": "replace",
+	"delete": "delete", "deletes": "delete", "remove": "remove", "removes": "remove",
+	"send": "send", "sends": "send", "publish": "publish", "publishes": "publish",
+	"subscribe": "subscribe", "subscribes": "subscribe", "receive": "receive", "receives": "receive",
+	"upload": "upload", "uploads": "upload", "download": "download", "downloads": "download",
+	"cancel": "cancel", "cancels": "cancel", "start": "start", "starts": "start", "stop": "stop", "stops": "stop",
+	"check": "check", "checks": "check", "set": "set", "sets": "set",
+}
+
+// summaryFillers are skipped between the verb and the noun
+var summaryFillers = map[string]bool{
+	"a": true, "an": true, "the": true, "all": true, "any": true, "some": true, "one": true,
+	"new": true, "existing": true, "specific": true, "single": true, "given": true, "multiple": true,
+}
+
+// summaryStops end the noun: "Find pets by status" -> find_pets
+var summaryStops = map[string]bool{
+	"by": true, "for": true, "with": true, "from": true, "in": true, "into": true, "to": true,
+	"of": true, "on": true, "at": true, "and": true, "or": true, "that": true, "which": true, "using": true,
+}
+
+// maxNounWords bounds the noun taken from a summary
+const maxNounWords = 2
+
+// verbNounWords derives a verb and noun from the first clause of a summary,
+// returning nil when it does not start with a known verb
+func verbNounWords(summary string) []string {
+	clause := summary
+	if end := strings.IndexAny(clause, ".,;:()\n"); end >= 0 {
+		clause = clause[:end]
+	}
+	fields := strings.Fields(strings.ToLower(clause))
+	if len(fields) == 0 {
+		return nil
+	}
+	verb, ok := summaryVerbs[fields[0]]
+	if !ok {
+		return nil
+	}
+
+	words := []string{verb}
+	for _, field := range fields[1:] {
+		if summaryStops[field] {
+			break
+		}
+		if summaryFillers[field] && len(words) == 1 {
+			continue
+		}
+		words = append(words, nameWords(field)...)
+		if len(words) > maxNounWords {
+			break
+		}
+	}
+	if len(words) == 1 {
+		return nil
+	}
+	if len(words) > maxNounWords+1 {
+		words = words[:maxNounWords+1]
+	}
+	return words
+}
+
+// namedTool registers a tool under the name its source's strategy gives it
+type namedTool struct {
+	types.Tool
+	name    string
+	renames map[string]string // Original to new names of the source's tools
+}
+
+// Name returns the strategy name
+func (t *namedTool) Name() string {
+	return t.name
+}
+
+// Metadata renames the tool and the replacement it deprecates in favour of
+func (t *namedTool) Metadata() types.ToolMetadata {
+	metadata := t.Tool.Metadata()
+	metadata.Name = t.name
+	if metadata.Deprecation != nil {
+		if replacement, renamed := t.renames[metadata.Deprecation.Replacement]; renamed {
+			deprecation := *metadata.Deprecation
+			deprecation.Replacement = replacement
+			metadata.Deprecation = &deprecation
+		}
+	}
+	return metadata
+}
+
+// applyNaming renames the tools of a source under a strategy
+func applyNaming(strategy NamingStrategy, tools []types.Tool) []types.Tool {
+	if !strategy.Enabled() {
+		return tools
+	}
+	renames := strategy.Names(tools)
+	named := make([]types.Tool, len(tools))
+	for i, tool := range tools {
+		// Tools keeping their name may still deprecate in favour of a renamed one
+		named[i] = &namedTool{Tool: tool, name: renames[tool.Name()], renames: renames}
+	}
+	return named
+}
@@ -0,0 +1,128 @@
+package importer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summaryTool is a tool with a fixed name and summary
+type summaryTool struct {
+	stubTool
+	summary     string
+	replacement string
+}
+
+func (t summaryTool) Description() string { return t.summary }
+
+func (t summaryTool) Metadata() types.ToolMetadata {
+	metadata := t.stubTool.Metadata()
+	if t.replacement != "" {
+		metadata.Deprecation = &types.Deprecation{Replacement: t.replacement}
+	}
+	return metadata
+}
+
+func TestNameWords(t *testing.T) {
+	for identifier, expected := range map[string][]string{
+		"listPets":            {"list", "pets"},
+		"getHTTPStatus_v2":    {"get", "http", "status", "v2"},
+		"get_pets_petId":      {"get", "pets", "pet", "id"},
+		"publish_user/events": {"publish", "user", "events"},
+	} {
+		assert.Equal(t, expected, nameWords(identifier), identifier)
+	}
+
+	for summary, expected := range map[string][]string{
+		"List all pets":                  {"list", "pets"},
+		"Returns a user by ID":           {"get", "user"},
+		"Create a new pet in the store.": {"create", "pet"},
+		"Find pets by status":            {"find", "pets"},
+		"Updates the order line items":   {"update", "order", "line"},
+		"Info for a specific pet":        nil,
+		"Delete":                         nil,
+	} {
+		assert.Equal(t, expected, verbNounWords(summary), summary)
+	}
+}
+
+func TestNamingStrategy(t *testing.T) {
+	tools := []types.Tool{
+		summaryTool{stubTool: stubTool{name: "openapi.petstore.listPets"}, summary: "List all pets"},
+		summaryTool{stubTool: stubTool{name: "openapi.petstore.get_pets_petId"}, summary: "Info for a specific pet"},
+		summaryTool{stubTool: stubTool{name: "openapi.petstore.findPetsByStatus"}, summary: "Find pets by status", replacement: "openapi.petstore.listPets"},
+		summaryTool{stubTool: stubTool{name: "openapi.petstore.delete"}, summary: "Remove"},
+		summaryTool{stubTool: stubTool{name: "asyncapi.events.publish_user_events"}, summary: "Publish message to user/events channel"},
+		summaryTool{stubTool: stubTool{name: "asyncapi.events.publish_order_events"}, summary: "Publish message to order/events channel"},
+	}
+
+	// Without a strategy names are kept
+	assert.Equal(t, "openapi.petstore.get_pets_petId", NamingStrategy{}.Names(tools)["openapi.petstore.get_pets_petId"])
+
+	names := NamingStrategy{Case: NameCaseCamel, VerbNoun: true}.Names(tools)
+	assert.Equal(t, map[string]string{
+		"openapi.petstore.listPets":            "openapi.petstore.listPets",
+		"openapi.petstore.get_pets_petId":      "openapi.petstore.getPetsPetId",
+		"openapi.petstore.findPetsByStatus":    "openapi.petstore.findPets",
+		"openapi.petstore.delete":              "openapi.petstore.deleteTool",
+		"asyncapi.events.publish_user_events":  "asyncapi.events.publishUserEvents",
+		"asyncapi.events.publish_order_events": "asyncapi.events.publishOrderEvents",
+	}, names)
+
+	// Truncated names that collide are told apart by suffixes within the limit
+	names = NamingStrategy{Case: NameCaseSnake, MaxLength: 23}.Names(tools)
+	assert.Equal(t, "asyncapi.events.publish", names["asyncapi.events.publish_order_events"])
+	assert.Equal(t, "asyncapi.events.publi_2", names["asyncapi.events.publish_user_events"])
+	assert.Equal(t, "openapi.petstore.list_p", names["openapi.petstore.listPets"])
+	for _, name := range names {
+		assert.LessOrEqual(t, len(name), 23, name)
+	}
+
+	names = NamingStrategy{Reserved: []string{"listPets"}}.Names(tools)
+	assert.Equal(t, "openapi.petstore.list_pets_tool", names["openapi.petstore.listPets"])
+	assert.Equal(t, "openapi.petstore.get_pets_petId", names["openapi.petstore.get_pets_petId"])
+
+	assert.Error(t, NamingStrategy{Case: "kebab"}.Validate())
+	assert.Error(t, NamingStrategy{MaxLength: 8}.Validate())
+}
+
+func TestImporterManager_Naming(t *testing.T) {
+	ctx := context.Background()
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(stubImporter{})
+	manager.SetDefaultNaming(NamingStrategy{Reserved: []string{"get"}})
+
+	_, err := manager.ImportSpec(ctx, SpecSource{ID: "billing", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	_, err = manager.ImportSpec(ctx, SpecSource{ID: "users", Type: SpecTypeOpenAPI, Naming: &NamingStrategy{}})
+	require.NoError(t, err)
+	assert.Contains(t, registry, "openapi.billing.get_tool")
+	assert.Equal(t, "openapi.billing.get_tool", registry["openapi.billing.get_tool"].Metadata().Name)
+	assert.Contains(t, registry, "openapi.users.get")
+
+	previews, err := manager.PreviewNaming(ctx, "users", &NamingStrategy{Reserved: []string{"list"}})
+	require.NoError(t, err)
+	assert.Equal(t, []ToolNamePreview{
+		{Original: "openapi.users.get", Name: "openapi.users.get"},
+		{Original: "openapi.users.list", Name: "openapi.users.list_tool", Changed: true},
+	}, previews)
+	_, err = manager.PreviewNaming(ctx, "users", &NamingStrategy{Case: "upper"})
+	assert.Error(t, err)
+
+	// Removal unregisters the renamed tools
+	require.NoError(t, manager.RemoveSpec(ctx, "billing"))
+	assert.NotContains(t, registry, "openapi.billing.get_tool")
+	assert.NotContains(t, registry, "openapi.billing.list")
+
+	// Renamed replacements follow the tools they point at
+	named := applyNaming(NamingStrategy{Case: NameCaseCamel}, []types.Tool{
+		summaryTool{stubTool: stubTool{name: "openapi.a.old_list"}, replacement: "openapi.a.new_list"},
+		summaryTool{stubTool: stubTool{name: "openapi.a.new_list"}},
+	})
+	assert.Equal(t, "openapi.a.oldList", named[0].Metadata().Name)
+	assert.Equal(t, "openapi.a.newList", named[0].Metadata().Deprecation.Replacement)
+}
@@ -7,6 +7,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,18 +19,23 @@ import (
 
 // OpenAPIImporter handles OpenAPI 3.x specifications
 type OpenAPIImporter struct {
-	loader *openapi3.Loader
+	fetcher *SpecFetcher
+	tokens  *OAuthTokenCache // OAuth2 tokens shared by the generated tools
 }
 
 // NewOpenAPIImporter creates a new OpenAPI importer
 func NewOpenAPIImporter() *OpenAPIImporter {
-	loader := openapi3.NewLoader()
-	loader.IsExternalRefsAllowed = true
 	return &OpenAPIImporter{
-		loader: loader,
+		fetcher: NewSpecFetcher(DefaultFetchPolicy()),
+		tokens:  NewOAuthTokenCache(),
 	}
 }
 
+// SetFetcher sets how specifications are downloaded from URLs
+func (i *OpenAPIImporter) SetFetcher(fetcher *SpecFetcher) {
+	i.fetcher = fetcher
+}
+
 // GetType returns the specification type
 func (i *OpenAPIImporter) GetType() SpecType {
 	return SpecTypeOpenAPI
@@ -40,10 +48,59 @@ func (i *OpenAPIImporter) Supports(source SpecSource) bool {
 
 // Validate checks if the specification is valid
 func (i *OpenAPIImporter) Validate(ctx context.Context, source SpecSource) error {
-	_, err := i.loadSpec(ctx, source.Path)
+	_, err := i.loadSpec(ctx, source)
 	return err
 }
 
+// openAPIMethods are the operations a path item can declare
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Lint checks the raw document for local references that do not resolve and
+// for operations without an operationId. Documents that cannot be read are
+// left to Validate.
+func (i *OpenAPIImporter) Lint(ctx context.Context, source SpecSource) []LintIssue {
+	var content []byte
+	var err error
+	if isRemoteSpec(source.Path) {
+		content, err = i.fetcher.Fetch(ctx, source.Path, source.Auth)
+	} else {
+		content, err = os.ReadFile(source.Path)
+	}
+	if err != nil {
+		return nil
+	}
+	document, err := decodeSpecDocument(source.Path, content)
+	if err != nil {
+		return nil
+	}
+
+	issues := unresolvedRefs(document)
+	paths, _ := document["paths"].(map[string]interface{})
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+	for _, path := range pathNames {
+		item, _ := paths[path].(map[string]interface{})
+		for _, method := range openAPIMethods {
+			operation, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if id, _ := operation["operationId"].(string); id == "" {
+				issues = append(issues, LintIssue{
+					Severity: LintWarning,
+					Code:     LintMissingOperationID,
+					Message:  "operation has no operationId; its tool is named after the method and path",
+					Location: strings.ToUpper(method) + " " + path,
+				})
+			}
+		}
+	}
+	return issues
+}
+
 // Import parses the OpenAPI specification and generates tools
 func (i *OpenAPIImporter) Import(ctx context.Context, source SpecSource) (*ImportResult, error) {
 	start := time.Now()
@@ -56,8 +113,17 @@ func (i *OpenAPIImporter) Import(ctx context.Context, source SpecSource) (*Impor
 		Timestamp: start,
 	}
 
+	// Prepare the environments tools call instead of the spec's servers
+	environments, err := loadEnvironments(source)
+	if err != nil {
+		err = fmt.Errorf("invalid environments: %w", err)
+		result.Errors = append(result.Errors, err)
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
 	// Load the specification
-	doc, err := i.loadSpec(ctx, source.Path)
+	doc, err := i.loadSpec(ctx, source)
 	if err != nil {
 		result.Errors = append(result.Errors, err)
 		result.Duration = time.Since(start)
@@ -68,6 +134,7 @@ func (i *OpenAPIImporter) Import(ctx context.Context, source SpecSource) (*Impor
 	if err := doc.Validate(ctx); err != nil {
 		result.Warnings = append(result.Warnings, fmt.Sprintf("Specification validation warning: %v", err))
 	}
+	result.Warnings = append(result.Warnings, securityWarnings(doc, source)...)
 
 	// Generate tools from paths
 	for path, pathItem := range doc.Paths.Map() {
@@ -87,7 +154,7 @@ func (i *OpenAPIImporter) Import(ctx context.Context, source SpecSource) (*Impor
 				continue
 			}
 
-			tool, err := i.createToolFromOperation(source, doc, path, method, operation)
+			tool, err := i.createToolFromOperation(source, doc, path, method, operation, environments)
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Errorf("failed to create tool for %s %s: %w", method, path, err))
 				continue
@@ -97,33 +164,80 @@ func (i *OpenAPIImporter) Import(ctx context.Context, source SpecSource) (*Impor
 		}
 	}
 
+	// Component schemas back the synthetic data tools
+	if doc.Components != nil && len(doc.Components.Schemas) > 0 {
+		result.Schemas = make(map[string]map[string]interface{}, len(doc.Components.Schemas))
+		for name, ref := range doc.Components.Schemas {
+			result.Schemas[name] = openAPISchema(ref, 0)
+		}
+	}
+
 	result.Duration = time.Since(start)
 	return result, nil
 }
 
-// loadSpec loads an OpenAPI specification from file or URL
-func (i *OpenAPIImporter) loadSpec(ctx context.Context, path string) (*openapi3.T, error) {
+// loadSpec loads an OpenAPI specification from file or URL. Specifications
+// from URLs are downloaded with the source's credentials, and so are the
+// external references they make to the same host.
+func (i *OpenAPIImporter) loadSpec(ctx context.Context, source SpecSource) (*openapi3.T, error) {
 	// Check if it's a URL
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		parsedURL, err := url.Parse(path)
+	if isRemoteSpec(source.Path) {
+		parsedURL, err := url.Parse(source.Path)
 		if err != nil {
 			return nil, fmt.Errorf("invalid URL: %w", err)
 		}
-		return i.loader.LoadFromURI(parsedURL)
+		loader := openapi3.NewLoader()
+		loader.IsExternalRefsAllowed = true
+		loader.Context = ctx
+		loader.ReadFromURIFunc = func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+			if location.Scheme == "" || location.Host == "" {
+				return nil, fmt.Errorf("cannot resolve local reference %s from a remote specification", location)
+			}
+			auth := source.Auth
+			if !strings.EqualFold(location.Host, parsedURL.Host) {
+				auth = nil
+			}
+			return i.fetcher.Fetch(ctx, location.String(), auth)
+		}
+		doc, err := loader.LoadFromURI(parsedURL)
+		if err != nil {
+			return nil, err
+		}
+		bundleSpec(ctx, doc, parsedURL.Path)
+		return doc, nil
 	}
 
-	// Load from file
-	return i.loader.LoadFromFile(path)
+	// Load from file. Loaders keep the documents they read and the default
+	// reader caches files by absolute path, so each load gets its own loader
+	// and an uncached reader to see the current content of edited files.
+	// Remote references are fetched without the source's credentials.
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.Context = ctx
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if isRemoteSpec(location.String()) {
+			return i.fetcher.Fetch(ctx, location.String(), nil)
+		}
+		return openapi3.ReadFromFile(loader, location)
+	}
+	doc, err := loader.LoadFromFile(source.Path)
+	if err != nil {
+		return nil, err
+	}
+	bundleSpec(ctx, doc, filepath.ToSlash(source.Path))
+	return doc, nil
 }
 
 // createToolFromOperation creates an MCP tool from an OpenAPI operation
-func (i *OpenAPIImporter) createToolFromOperation(source SpecSource, doc *openapi3.T, path, method string, operation *openapi3.Operation) (types.Tool, error) {
+func (i *OpenAPIImporter) createToolFromOperation(source SpecSource, doc *openapi3.T, path, method string, operation *openapi3.Operation, environments *sourceEnvironments) (types.Tool, error) {
 	tool := &OpenAPITool{
-		source:    source,
-		doc:       doc,
-		path:      path,
-		method:    method,
-		operation: operation,
+		source:       source,
+		doc:          doc,
+		path:         path,
+		method:       method,
+		operation:    operation,
+		tokens:       i.tokens,
+		environments: environments,
 	}
 
 	return tool, nil
@@ -131,11 +245,13 @@ func (i *OpenAPIImporter) createToolFromOperation(source SpecSource, doc *openap
 
 // OpenAPITool represents a tool generated from an OpenAPI operation
 type OpenAPITool struct {
-	source    SpecSource
-	doc       *openapi3.T
-	path      string
-	method    string
-	operation *openapi3.Operation
+	source       SpecSource
+	doc          *openapi3.T
+	path         string
+	method       string
+	operation    *openapi3.Operation
+	tokens       *OAuthTokenCache
+	environments *sourceEnvironments
 }
 
 // Name returns the tool name
@@ -164,16 +280,23 @@ func (t *OpenAPITool) Description() string {
 }
 
 // Execute performs the API call
-func (t *OpenAPITool) Execute(input any) (any, error) {
+func (t *OpenAPITool) Execute(ctx context.Context, input any) (any, error) {
 	// Parse input parameters
 	params, err := t.parseInput(input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse input: %w", err)
 	}
 
-	// Build the request URL
+	// Build the request URL against the selected environment, or the
+	// first server of the spec
+	env, err := t.environments.selected(ctx)
+	if err != nil {
+		return nil, err
+	}
 	baseURL := ""
-	if len(t.doc.Servers) > 0 {
+	if env != nil && env.baseURL != "" {
+		baseURL = env.baseURL
+	} else if len(t.doc.Servers) > 0 {
 		baseURL = t.doc.Servers[0].URL
 	}
 
@@ -206,16 +329,27 @@ func (t *OpenAPITool) Execute(input any) (any, error) {
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest(t.method, fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, t.method, fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Add headers
+	// Add the environment's headers, then the header parameters
+	if env != nil {
+		for name, values := range env.headers {
+			req.Header[name] = values
+		}
+	}
 	for key, value := range params.Headers {
 		req.Header.Set(key, fmt.Sprintf("%v", value))
 	}
 
+	// Inject the source's credentials for the operation's security schemes
+	tokenKey, err := t.authenticate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Add request body for POST, PUT, PATCH
 	if params.Body != nil && (t.method == "POST" || t.method == "PUT" || t.method == "PATCH") {
 		bodyBytes, err := json.Marshal(params.Body)
@@ -227,13 +361,27 @@ func (t *OpenAPITool) Execute(input any) (any, error) {
 	}
 
 	// Execute the request
-	client := &http.Client{Timeout: 30 * time.Second}
+	// The caller's context bounds the request; see the tools.timeout_ms setting
+	client := &http.Client{}
+	if env != nil {
+		client = env.client
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// A rejected OAuth2 token may have been revoked; request a new one next time
+	if tokenKey != "" && resp.StatusCode == http.StatusUnauthorized {
+		t.tokens.Invalidate(tokenKey)
+	}
+
+	// Rate limited or unavailable upstreams report when to retry
+	if err := types.UpstreamRetryError(resp); err != nil {
+		return nil, err
+	}
+
 	// Parse response
 	var responseBody interface{}
 	if resp.Header.Get("Content-Type") == "application/json" {
@@ -241,21 +389,110 @@ func (t *OpenAPITool) Execute(input any) (any, error) {
 			return nil, fmt.Errorf("failed to decode JSON response: %w", err)
 		}
 	} else {
-		// For non-JSON responses, return as string
-		bodyBytes := make([]byte, resp.ContentLength)
-		if _, err := resp.Body.Read(bodyBytes); err != nil {
+		// For non-JSON responses, return as string (empty for e.g. 204 No Content)
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
 			return nil, fmt.Errorf("failed to read response body: %w", err)
 		}
 		responseBody = string(bodyBytes)
 	}
 
-	return map[string]interface{}{
+	output := map[string]interface{}{
 		"status_code": resp.StatusCode,
 		"headers":     resp.Header,
 		"body":        responseBody,
 		"request_url": fullURL,
 		"method":      t.method,
-	}, nil
+	}
+	if env != nil {
+		output["environment"] = env.name
+	}
+	return output, nil
+}
+
+// maxSchemaDepth bounds the conversion of recursive OpenAPI schemas
+const maxSchemaDepth = 10
+
+// openAPISchema converts an OpenAPI schema to a plain JSON Schema map with
+// references resolved inline. It returns nil for a missing schema.
+func openAPISchema(ref *openapi3.SchemaRef, depth int) map[string]interface{} {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	if depth > maxSchemaDepth {
+		return map[string]interface{}{}
+	}
+	schema := ref.Value
+	converted := make(map[string]interface{})
+
+	if types := schema.Type.Slice(); len(types) == 1 {
+		converted["type"] = types[0]
+	} else if len(types) > 1 {
+		converted["type"] = types
+	}
+	if schema.Nullable {
+		converted["nullable"] = true
+	}
+	if schema.Format != "" {
+		converted["format"] = schema.Format
+	}
+	if schema.Description != "" {
+		converted["description"] = schema.Description
+	}
+	if len(schema.Enum) > 0 {
+		converted["enum"] = schema.Enum
+	}
+	if schema.Default != nil {
+		converted["default"] = schema.Default
+	}
+	if schema.Example != nil {
+		converted["example"] = schema.Example
+	}
+	if schema.Min != nil {
+		converted["minimum"] = *schema.Min
+	}
+	if schema.Max != nil {
+		converted["maximum"] = *schema.Max
+	}
+	if schema.MinLength > 0 {
+		converted["minLength"] = schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		converted["maxLength"] = *schema.MaxLength
+	}
+	if schema.Pattern != "" {
+		converted["pattern"] = schema.Pattern
+	}
+	if schema.MinItems > 0 {
+		converted["minItems"] = schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		converted["maxItems"] = *schema.MaxItems
+	}
+	if items := openAPISchema(schema.Items, depth+1); items != nil {
+		converted["items"] = items
+	}
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = openAPISchema(property, depth+1)
+		}
+		converted["properties"] = properties
+	}
+	if len(schema.Required) > 0 {
+		converted["required"] = schema.Required
+	}
+	for key, refs := range map[string]openapi3.SchemaRefs{"oneOf": schema.OneOf, "anyOf": schema.AnyOf, "allOf": schema.AllOf} {
+		if len(refs) == 0 {
+			continue
+		}
+		alternatives := make([]interface{}, 0, len(refs))
+		for _, alternative := range refs {
+			alternatives = append(alternatives, openAPISchema(alternative, depth+1))
+		}
+		converted[key] = alternatives
+	}
+	return converted
 }
 
 // RequestParams holds parsed request parameters
@@ -322,9 +559,15 @@ func (t *OpenAPITool) Metadata() types.ToolMetadata {
 
 	// Add parameters to schema
 	for _, param := range t.operation.Parameters {
-		paramSchema := map[string]interface{}{
-			"type":        "string", // Simplified for now
-			"description": param.Value.Description,
+		paramSchema := openAPISchema(param.Value.Schema, 0)
+		if paramSchema == nil {
+			paramSchema = map[string]interface{}{"type": "string"}
+		}
+		if param.Value.Description != "" {
+			paramSchema["description"] = param.Value.Description
+		}
+		if example, ok := openAPIExample(param.Value.Example, param.Value.Examples); ok {
+			paramSchema["example"] = example
 		}
 
 		properties[param.Value.Name] = paramSchema
@@ -336,20 +579,35 @@ func (t *OpenAPITool) Metadata() types.ToolMetadata {
 
 	// Add request body if present
 	if t.operation.RequestBody != nil {
-		properties["body"] = map[string]interface{}{
-			"type":        "object",
-			"description": "Request body",
+		bodySchema := map[string]interface{}{"type": "object"}
+		if body := t.operation.RequestBody.Value; body != nil {
+			if converted := openAPIMediaSchema(body.Content); converted != nil {
+				bodySchema = converted
+			}
+			if body.Required {
+				required = append(required, "body")
+			}
 		}
+		bodySchema["description"] = "Request body"
+		properties["body"] = bodySchema
 	}
 
 	inputSchema["required"] = required
 
+	// Describe the response body by the first successful JSON response
+	responseSchema := openAPIResponseSchema(t.operation)
+	if responseSchema == nil {
+		responseSchema = map[string]interface{}{"type": "object"}
+	}
+
 	return types.ToolMetadata{
 		Name:        t.Name(),
 		Description: t.Description(),
 		Version:     "1.0.0",
 		Source:      string(SpecTypeOpenAPI),
 		Tags:        []string{"openapi", "api", strings.ToLower(t.method)},
+		Deprecation: openAPIDeprecation(t.source.ID, t.operation),
+		Idempotent:  openAPIIdempotent(t.method, t.operation),
 		Schema: map[string]interface{}{
 			"input": inputSchema,
 			"output": map[string]interface{}{
@@ -357,7 +615,7 @@ func (t *OpenAPITool) Metadata() types.ToolMetadata {
 				"properties": map[string]interface{}{
 					"status_code": map[string]interface{}{"type": "integer"},
 					"headers":     map[string]interface{}{"type": "object"},
-					"body":        map[string]interface{}{"type": "object"},
+					"body":        responseSchema,
 					"request_url": map[string]interface{}{"type": "string"},
 					"method":      map[string]interface{}{"type": "string"},
 				},
@@ -367,3 +625,16 @@ func (t *OpenAPITool) Metadata() types.ToolMetadata {
 		UpdatedAt: time.Now(),
 	}
 }
+
+// extensionIdempotent overrides whether an operation's results may be cached
+const extensionIdempotent = "x-idempotent"
+
+// openAPIIdempotent reports whether repeated calls of an operation return the
+// same result: GET and HEAD operations unless x-idempotent says otherwise
+func openAPIIdempotent(method string, operation *openapi3.Operation) bool {
+	if idempotent, ok := operation.Extensions[extensionIdempotent].(bool); ok {
+		return idempotent
+	}
+	method = strings.ToUpper(method)
+	return method == http.MethodGet || method == http.MethodHead
+}
@@ -0,0 +1,315 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"go.uber.org/zap"
+)
+
+// DefaultPollCheckInterval is how often the poller looks for sources due a check
+const DefaultPollCheckInterval = 15 * time.Second
+
+// SpecPollerConfig configures polling of specs imported from URLs
+type SpecPollerConfig struct {
+	DefaultInterval time.Duration // For sources without their own interval; zero polls only those that set one
+	CheckInterval   time.Duration // How often due sources are checked; zero selects DefaultPollCheckInterval
+	Clock           clock.Clock   // Nil uses the real clock
+}
+
+// PollStatus reports the polling of one source
+type PollStatus struct {
+	SourceID        string    `json:"source_id"`
+	Path            string    `json:"path"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	Hash            string    `json:"hash,omitempty"` // SHA-256 of the last content fetched
+	ETag            string    `json:"etag,omitempty"`
+	LastModified    string    `json:"last_modified,omitempty"`
+	LastChecked     time.Time `json:"last_checked,omitempty"`
+	LastChanged     time.Time `json:"last_changed,omitempty"`
+	LastError       string    `json:"last_error,omitempty"`
+	Checks          int64     `json:"checks"`
+	Reloads         int64     `json:"reloads"`
+}
+
+// SpecReload describes a reload triggered by a change to a polled spec
+type SpecReload struct {
+	SourceID     string    `json:"source_id"`
+	PreviousHash string    `json:"previous_hash"`
+	Hash         string    `json:"hash"`
+	Tools        int       `json:"tools"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// SpecReloadHandler is notified when the poller reloads a changed spec
+type SpecReloadHandler func(reload SpecReload)
+
+// SpecPoller periodically re-fetches specs imported from URLs and reloads
+// their tools when the content changes. Fetches are conditional on the
+// ETag and Last-Modified validators the server returned last time, and the
+// content is hashed so servers without validators only cause a reload when
+// the spec really changed. The first check of a source records its baseline.
+type SpecPoller struct {
+	manager  *ImporterManager
+	config   SpecPollerConfig
+	logger   *zap.Logger
+	checkMu  sync.Mutex // Serializes checks so a source is never reloaded twice for one change
+	mu       sync.RWMutex
+	status   map[string]*PollStatus // source ID -> polling status
+	handlers []SpecReloadHandler
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{} // Closed when the poll loop exits
+}
+
+// NewSpecPoller creates a poller and starts its poll loop
+func NewSpecPoller(manager *ImporterManager, config SpecPollerConfig, logger *zap.Logger) *SpecPoller {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultPollCheckInterval
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &SpecPoller{
+		manager: manager,
+		config:  config,
+		logger:  logger,
+		status:  make(map[string]*PollStatus),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go p.run(config.Clock.NewTicker(config.CheckInterval))
+
+	return p
+}
+
+// OnReload registers a handler notified after each reload the poller triggers
+func (p *SpecPoller) OnReload(handler SpecReloadHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+// interval returns how often a source is polled; zero means never
+func (p *SpecPoller) interval(source SpecSource) time.Duration {
+	if !isRemoteSpec(source.Path) || source.PollIntervalSeconds < 0 {
+		return 0
+	}
+	if source.PollIntervalSeconds > 0 {
+		return time.Duration(source.PollIntervalSeconds) * time.Second
+	}
+	return p.config.DefaultInterval
+}
+
+// run polls due sources on every tick until the poller is stopped
+func (p *SpecPoller) run(ticker clock.Ticker) {
+	defer close(p.done)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C():
+			p.poll(p.config.Clock.Now())
+		}
+	}
+}
+
+// poll checks every source whose interval has elapsed since its last check
+// and forgets the sources that have been removed
+func (p *SpecPoller) poll(now time.Time) {
+	sources := p.manager.ListSources()
+	current := make(map[string]bool, len(sources))
+	var due []string
+	for _, source := range sources {
+		interval := p.interval(source)
+		if interval <= 0 {
+			continue
+		}
+		current[source.ID] = true
+
+		p.mu.RLock()
+		status, exists := p.status[source.ID]
+		checkedAt := time.Time{}
+		if exists {
+			checkedAt = status.LastChecked
+		}
+		p.mu.RUnlock()
+		if now.Sub(checkedAt) >= interval {
+			due = append(due, source.ID)
+		}
+	}
+
+	p.mu.Lock()
+	for sourceID := range p.status {
+		if !current[sourceID] {
+			delete(p.status, sourceID)
+		}
+	}
+	p.mu.Unlock()
+
+	sort.Strings(due)
+	for _, sourceID := range due {
+		if p.ctx.Err() != nil {
+			return
+		}
+		if _, err := p.Check(p.ctx, sourceID); err != nil {
+			p.logger.Warn("Failed to poll specification",
+				zap.String("source_id", sourceID),
+				zap.Error(err))
+		}
+	}
+}
+
+// Check re-fetches a source now and reloads it when its content changed since
+// the last check, reporting whether it was reloaded
+func (p *SpecPoller) Check(ctx context.Context, sourceID string) (bool, error) {
+	p.checkMu.Lock()
+	defer p.checkMu.Unlock()
+
+	source, exists := p.manager.GetSource(sourceID)
+	if !exists {
+		return false, fmt.Errorf("specification source not found: %s", sourceID)
+	}
+	if !isRemoteSpec(source.Path) {
+		return false, fmt.Errorf("specification source %s is not imported from a URL", sourceID)
+	}
+
+	p.mu.Lock()
+	status, exists := p.status[sourceID]
+	if !exists {
+		status = &PollStatus{SourceID: sourceID}
+		p.status[sourceID] = status
+	}
+	status.Path = source.Path
+	status.IntervalSeconds = int(p.interval(source) / time.Second)
+	status.LastChecked = p.config.Clock.Now()
+	etag, lastModified, previousHash := status.ETag, status.LastModified, status.Hash
+	p.mu.Unlock()
+
+	fetched, err := p.fetch(ctx, source, etag, lastModified)
+	if err != nil {
+		p.finishCheck(status, err)
+		return false, err
+	}
+	if fetched.NotModified {
+		p.finishCheck(status, nil)
+		return false, nil
+	}
+
+	sum := sha256.Sum256(fetched.Content)
+	hash := hex.EncodeToString(sum[:])
+	if previousHash == "" || hash == previousHash {
+		p.mu.Lock()
+		status.Hash, status.ETag, status.LastModified = hash, fetched.ETag, fetched.LastModified
+		p.mu.Unlock()
+		p.finishCheck(status, nil)
+		return false, nil
+	}
+
+	// Keep the previous hash and validators until the reload succeeds, so a
+	// failed reload is retried on the next check
+	p.logger.Info("Reloading specification due to remote change",
+		zap.String("source_id", sourceID),
+		zap.String("previous_hash", previousHash),
+		zap.String("hash", hash))
+	result, err := p.manager.ReloadSpec(ctx, sourceID)
+	if err != nil {
+		err = fmt.Errorf("failed to reload changed specification: %w", err)
+		p.finishCheck(status, err)
+		return false, err
+	}
+
+	reload := SpecReload{
+		SourceID:     sourceID,
+		PreviousHash: previousHash,
+		Hash:         hash,
+		Tools:        len(result.Tools),
+		Timestamp:    p.config.Clock.Now(),
+	}
+	p.mu.Lock()
+	status.Hash, status.ETag, status.LastModified = hash, fetched.ETag, fetched.LastModified
+	status.LastChanged = reload.Timestamp
+	status.Reloads++
+	handlers := make([]SpecReloadHandler, len(p.handlers))
+	copy(handlers, p.handlers)
+	p.mu.Unlock()
+	p.finishCheck(status, nil)
+
+	for _, handler := range handlers {
+		handler(reload)
+	}
+	return true, nil
+}
+
+// fetch downloads a source's spec unless it is unchanged. Introspected
+// GraphQL endpoints are queried again; their answers carry no validators, so
+// changes are found by hash.
+func (p *SpecPoller) fetch(ctx context.Context, source SpecSource, etag, lastModified string) (*FetchResult, error) {
+	if introspects(source) {
+		content, err := introspect(ctx, p.manager.specFetcher(), source)
+		if err != nil {
+			return nil, err
+		}
+		return &FetchResult{Content: content}, nil
+	}
+	return p.manager.specFetcher().FetchIfChanged(ctx, source.Path, source.Auth, etag, lastModified)
+}
+
+// finishCheck counts a completed check and records its error, if any
+func (p *SpecPoller) finishCheck(status *PollStatus, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status.Checks++
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+// Status reports the polling of each checked source, sorted by source ID
+func (p *SpecPoller) Status() []PollStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make([]PollStatus, 0, len(p.status))
+	for _, status := range p.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].SourceID < statuses[j].SourceID })
+	return statuses
+}
+
+// Stop stops the poll loop
+func (p *SpecPoller) Stop() {
+	p.cancel()
+}
+
+// Running reports whether the poll loop is still running
+func (p *SpecPoller) Running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// specFetcher returns the fetcher that enforces the manager's fetch policy
+func (m *ImporterManager) specFetcher() *SpecFetcher {
+	if m.fetcher != nil {
+		return m.fetcher
+	}
+	return NewSpecFetcher(DefaultFetchPolicy())
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSpecPoller(t *testing.T) {
+	ctx := context.Background()
+
+	// The upstream honours If-None-Match, answering 304 while the spec is unchanged
+	var mu sync.Mutex
+	spec, etag, notModified := syntheticPetstore, `"v1"`, 0
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(spec))
+	}))
+	defer upstream.Close()
+
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	manager.SetFetchPolicy(FetchPolicy{})
+	manager.fetcher.client = upstream.Client()
+	manager.RegisterImporter(NewOpenAPIImporter())
+	_, err := manager.ImportSpec(ctx, SpecSource{ID: "petstore", Type: SpecTypeOpenAPI, Path: upstream.URL + "/petstore.json", PollIntervalSeconds: 60})
+	require.NoError(t, err)
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	poller := NewSpecPoller(manager, SpecPollerConfig{CheckInterval: 10 * time.Second, Clock: fake}, zap.NewNop())
+	defer poller.Stop()
+	reloads := make(chan SpecReload, 1)
+	poller.OnReload(func(reload SpecReload) { reloads <- reload })
+
+	checks := func() int64 {
+		statuses := poller.Status()
+		if len(statuses) == 0 {
+			return 0
+		}
+		return statuses[0].Checks
+	}
+
+	// The first check records the baseline without reloading
+	fake.Advance(10 * time.Second)
+	require.Eventually(t, func() bool { return checks() == 1 }, time.Second, 5*time.Millisecond)
+	baseline := poller.Status()[0]
+	assert.NotEmpty(t, baseline.Hash)
+	assert.Equal(t, `"v1"`, baseline.ETag)
+	assert.Equal(t, 60, baseline.IntervalSeconds)
+
+	// Sources are not checked again before their interval, then only conditionally
+	fake.Advance(10 * time.Second)
+	fake.Advance(50 * time.Second)
+	require.Eventually(t, func() bool { return checks() == 2 }, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, 1, notModified)
+	mu.Unlock()
+	assert.Zero(t, poller.Status()[0].Reloads)
+
+	// A changed spec is reloaded and announced
+	mu.Lock()
+	spec = strings.Replace(syntheticPetstore, `"get": {`, `"post": {"operationId": "createPet", "responses": {"201": {"description": "created"}}}, "get": {`, 1)
+	etag = `"v2"`
+	mu.Unlock()
+	fake.Advance(60 * time.Second)
+	select {
+	case reload := <-reloads:
+		assert.Equal(t, "petstore", reload.SourceID)
+		assert.Equal(t, baseline.Hash, reload.PreviousHash)
+		assert.NotEqual(t, baseline.Hash, reload.Hash)
+	case <-time.After(time.Second):
+		t.Fatal("changed spec was not reloaded")
+	}
+	status := poller.Status()[0]
+	assert.Equal(t, int64(1), status.Reloads)
+	assert.Equal(t, `"v2"`, status.ETag)
+	assert.Contains(t, registry, "openapi.petstore.createPet")
+
+	// Checking on demand reports no change while the spec stays the same
+	changed, err := poller.Check(ctx, "petstore")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = poller.Check(ctx, "missing")
+	assert.ErrorContains(t, err, "not found")
+
+	// Removed sources are forgotten
+	require.NoError(t, manager.RemoveSpec(ctx, "petstore"))
+	fake.Advance(10 * time.Second)
+	require.Eventually(t, func() bool { return len(poller.Status()) == 0 }, time.Second, 5*time.Millisecond)
+
+	poller.Stop()
+	assert.Eventually(t, func() bool { return !poller.Running() }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,165 @@
+package importer
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// PreviewTool is a tool an import would register
+type PreviewTool struct {
+	types.ToolMetadata
+	OriginalName string `json:"original_name,omitempty"` // Importer's name, when the naming strategy changed it
+	Synthetic    bool   `json:"synthetic,omitempty"`     // Generates fake data instead of calling the API
+}
+
+// SpecPreview is the tool manifest an import of a specification would produce
+type SpecPreview struct {
+	Source   SpecSource    `json:"source"`
+	Tools    []PreviewTool `json:"tools"`
+	Errors   []string      `json:"errors"`
+	Warnings []string      `json:"warnings"`
+	Duration time.Duration `json:"duration"`
+
+	// Changes compares the manifest with the tools the source registers
+	// now. For a source not imported yet every tool is added.
+	Changes *ToolCatalogDiff `json:"changes"`
+	Summary PreviewSummary   `json:"summary"`
+
+	// Conflicts are tools of the manifest already registered by another
+	// source or by the server, which the import would replace
+	Conflicts []PreviewConflict `json:"conflicts"`
+}
+
+// PreviewSummary counts the tools an import would add, change, remove and
+// leave as they are
+type PreviewSummary struct {
+	Added     int `json:"added"`
+	Changed   int `json:"changed"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+	Breaking  int `json:"breaking"`
+}
+
+// PreviewConflict is a tool name the import shares with a tool it does not own
+type PreviewConflict struct {
+	ToolName string `json:"tool_name"`
+	Owner    string `json:"owner"` // ID of the source registering it, or "server"
+}
+
+// serverToolOwner owns conflicting tools registered outside the importers
+const serverToolOwner = "server"
+
+// toolLookup is implemented by registries that can tell which tools exist
+type toolLookup interface {
+	Get(name string) (types.Tool, error)
+}
+
+// PreviewSpec runs the import of a specification, naming and synthetic tools
+// included, and returns the tool manifest it would produce without
+// registering anything or recording the source. It works in read-only mode.
+func (m *ImporterManager) PreviewSpec(ctx context.Context, source SpecSource) (*SpecPreview, error) {
+	started := time.Now()
+	result, apiTools, err := m.generateTools(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &SpecPreview{
+		Source:   source,
+		Tools:    make([]PreviewTool, 0, len(result.Tools)),
+		Errors:   make([]string, 0, len(result.Errors)),
+		Warnings: result.Warnings,
+	}
+	if preview.Warnings == nil {
+		preview.Warnings = []string{}
+	}
+	for _, err := range result.Errors {
+		preview.Errors = append(preview.Errors, err.Error())
+	}
+	for i, tool := range result.Tools {
+		entry := PreviewTool{ToolMetadata: tool.Metadata(), Synthetic: i >= apiTools}
+		if named, ok := tool.(*namedTool); ok && named.Tool.Name() != named.name {
+			entry.OriginalName = named.Tool.Name()
+		}
+		preview.Tools = append(preview.Tools, entry)
+	}
+	sort.SliceStable(preview.Tools, func(i, j int) bool {
+		return preview.Tools[i].Name < preview.Tools[j].Name
+	})
+
+	manifest := make([]types.ToolMetadata, len(preview.Tools))
+	for i, tool := range preview.Tools {
+		manifest[i] = tool.ToolMetadata
+	}
+
+	// Compare with the tools the source registers now, and find the names
+	// other sources or the server already use
+	m.catalogMu.RLock()
+	current := m.catalogs[source.ID]
+	m.catalogMu.RUnlock()
+	diff := DiffToolCatalogs(source.ID, current, manifest)
+	preview.Changes = &diff
+	preview.Summary = summarizeChanges(diff, len(manifest))
+	names := make([]string, len(manifest))
+	for i, tool := range manifest {
+		names[i] = tool.Name
+	}
+	preview.Conflicts = m.nameConflicts(source.ID, names)
+
+	preview.Duration = time.Since(started)
+	return preview, nil
+}
+
+// summarizeChanges counts the changes of a diff; the manifest tools it does
+// not mention are unchanged
+func summarizeChanges(diff ToolCatalogDiff, manifestSize int) PreviewSummary {
+	var summary PreviewSummary
+	for _, change := range diff.Changes {
+		switch change.Kind {
+		case ToolChangeAdded:
+			summary.Added++
+		case ToolChangeChanged:
+			summary.Changed++
+		case ToolChangeRemoved:
+			summary.Removed++
+		}
+		if change.Breaking {
+			summary.Breaking++
+		}
+	}
+	summary.Unchanged = manifestSize - summary.Added - summary.Changed
+	return summary
+}
+
+// nameConflicts lists the tool names of a source that other sources own,
+// or that the server registered when the registry can look tools up
+func (m *ImporterManager) nameConflicts(sourceID string, names []string) []PreviewConflict {
+	owners := make(map[string]string)
+	m.catalogMu.RLock()
+	for owner, catalog := range m.catalogs {
+		for _, tool := range catalog {
+			owners[tool.Name] = owner
+		}
+	}
+	m.catalogMu.RUnlock()
+	lookup, _ := m.registry.(toolLookup)
+
+	conflicts := []PreviewConflict{}
+	for _, name := range names {
+		owner, imported := owners[name]
+		switch {
+		case owner == sourceID:
+			continue
+		case imported:
+			conflicts = append(conflicts, PreviewConflict{ToolName: name, Owner: owner})
+		case lookup != nil:
+			if existing, err := lookup.Get(name); err == nil && existing != nil {
+				conflicts = append(conflicts, PreviewConflict{ToolName: name, Owner: serverToolOwner})
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImporterManager_PreviewSpec(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "petstore.json")
+	require.NoError(t, os.WriteFile(path, []byte(syntheticPetstore), 0o644))
+
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(NewOpenAPIImporter())
+	manager.SetSynthetic(SyntheticConfig{Enabled: true})
+	manager.SetWriteGuard(func() error { return assert.AnError })
+
+	// Previews register nothing, even in read-only mode
+	source := SpecSource{ID: "petstore", Type: SpecTypeOpenAPI, Path: path, Naming: &NamingStrategy{Case: NameCaseSnake}}
+	preview, err := manager.PreviewSpec(ctx, source)
+	require.NoError(t, err)
+	assert.Empty(t, registry)
+	assert.Empty(t, manager.ListSources())
+
+	// Every tool of a new source is added
+	require.NotNil(t, preview.Changes)
+	assert.Len(t, preview.Changes.Changes, 3)
+	assert.Equal(t, PreviewSummary{Added: 3}, preview.Summary)
+	assert.Empty(t, preview.Conflicts)
+
+	require.Len(t, preview.Tools, 3)
+	assert.Equal(t, "openapi.petstore.generate_owner", preview.Tools[0].Name)
+	assert.True(t, preview.Tools[0].Synthetic)
+	assert.Equal(t, "openapi.petstore.list_pets", preview.Tools[2].Name)
+	assert.Equal(t, "openapi.petstore.listPets", preview.Tools[2].OriginalName)
+	assert.False(t, preview.Tools[2].Synthetic)
+	assert.NotEmpty(t, preview.Tools[2].Schema)
+
+	// Previewing an imported source shows what a re-import would change
+	manager.SetWriteGuard(nil)
+	_, err = manager.ImportSpec(ctx, SpecSource{ID: "petstore", Type: SpecTypeOpenAPI, Path: path})
+	require.NoError(t, err)
+	preview, err = manager.PreviewSpec(ctx, source)
+	require.NoError(t, err)
+	require.NotNil(t, preview.Changes)
+	kinds := map[string]ToolChangeKind{}
+	for _, change := range preview.Changes.Changes {
+		kinds[change.ToolName] = change.Kind
+	}
+	assert.Equal(t, ToolChangeAdded, kinds["openapi.petstore.list_pets"])
+	assert.Equal(t, ToolChangeRemoved, kinds["openapi.petstore.listPets"])
+	assert.Equal(t, PreviewSummary{Added: 1, Removed: 1, Unchanged: 2, Breaking: 1}, preview.Summary)
+
+	_, err = manager.PreviewSpec(ctx, SpecSource{ID: "petstore", Type: SpecTypeOpenAPI, Path: path, Naming: &NamingStrategy{Case: "kebab"}})
+	assert.Error(t, err)
+}
+
+// lookupRegistry can tell which tools are registered
+type lookupRegistry struct{ mapRegistry }
+
+func (r lookupRegistry) Get(name string) (types.Tool, error) {
+	if tool, ok := r.mapRegistry[name]; ok {
+		return tool, nil
+	}
+	return nil, fmt.Errorf("tool %s not found", name)
+}
+
+func TestImporterManager_PreviewSpecConflicts(t *testing.T) {
+	ctx := context.Background()
+	registry := lookupRegistry{mapRegistry{}}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(stubImporter{})
+
+	// Unchanged tools of an imported source are counted, not listed
+	_, err := manager.ImportSpec(ctx, SpecSource{ID: "billing", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	preview, err := manager.PreviewSpec(ctx, SpecSource{ID: "billing", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	assert.Empty(t, preview.Changes.Changes)
+	assert.Equal(t, PreviewSummary{Unchanged: 2}, preview.Summary)
+	assert.Empty(t, preview.Conflicts)
+
+	// Tools another source or the server registers would be replaced
+	registry.mapRegistry["openapi.users.get"] = stubTool{name: "openapi.users.get"}
+	manager.catalogs["legacy"] = []types.ToolMetadata{{Name: "openapi.users.list"}}
+	preview, err = manager.PreviewSpec(ctx, SpecSource{ID: "users", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	assert.Equal(t, []PreviewConflict{
+		{ToolName: "openapi.users.get", Owner: "server"},
+		{ToolName: "openapi.users.list", Owner: "legacy"},
+	}, preview.Conflicts)
+	assert.Len(t, registry.mapRegistry, 3)
+}
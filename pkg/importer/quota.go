@@ -0,0 +1,421 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+const (
+	// DefaultQuotaThreshold is the fraction of remaining quota below which calls are paced
+	DefaultQuotaThreshold = 0.1
+
+	// DefaultQuotaMaxWait is the longest a paced call waits before it is rejected
+	DefaultQuotaMaxWait = 5 * time.Second
+
+	// DefaultQuotaExhaustionWindow is how far back exhaustions count as recent
+	DefaultQuotaExhaustionWindow = 24 * time.Hour
+
+	// defaultCredential identifies calls made without a recognised credential header
+	defaultCredential = "default"
+)
+
+// quotaHeaderPrefixes lists the rate limit header families understood, in
+// order of preference
+var quotaHeaderPrefixes = []string{"X-RateLimit-", "RateLimit-", "X-Rate-Limit-"}
+
+// credentialParameters are input parameter names that identify the caller's
+// upstream credential
+var credentialParameters = map[string]bool{
+	"authorization": true,
+	"x-api-key":     true,
+	"api-key":       true,
+	"api_key":       true,
+	"apikey":        true,
+	"x-auth-token":  true,
+}
+
+// QuotaPolicy controls how calls are paced as an upstream quota runs out
+type QuotaPolicy struct {
+	Threshold        float64       `json:"threshold"`         // Remaining fraction below which calls are paced
+	MaxWait          time.Duration `json:"max_wait"`          // Longest wait before a call is rejected
+	ExhaustionWindow time.Duration `json:"exhaustion_window"` // Window for RecentExhaustions
+}
+
+// DefaultQuotaPolicy returns the default quota policy
+func DefaultQuotaPolicy() QuotaPolicy {
+	return QuotaPolicy{
+		Threshold:        DefaultQuotaThreshold,
+		MaxWait:          DefaultQuotaMaxWait,
+		ExhaustionWindow: DefaultQuotaExhaustionWindow,
+	}
+}
+
+// QuotaStatus is the last known upstream quota for one source and credential
+type QuotaStatus struct {
+	SourceID          string    `json:"source_id"`
+	Credential        string    `json:"credential"` // Fingerprint, never the credential itself
+	Limit             int64     `json:"limit"`
+	Remaining         int64     `json:"remaining"`
+	ResetAt           time.Time `json:"reset_at,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Observations      int64     `json:"observations"` // Responses that carried quota headers
+	Exhaustions       int64     `json:"exhaustions"`  // Times the remaining quota reached zero
+	RecentExhaustions int       `json:"recent_exhaustions"`
+	Paced             int64     `json:"paced"`    // Calls delayed to stretch the remaining quota
+	Rejected          int64     `json:"rejected"` // Calls refused because the quota was spent
+}
+
+// Exhausted reports whether no quota remains before the reset time
+func (s QuotaStatus) Exhausted(now time.Time) bool {
+	return s.Remaining <= 0 && now.Before(s.ResetAt)
+}
+
+// QuotaSummary totals quota activity across all tracked upstreams
+type QuotaSummary struct {
+	Tracked     int   `json:"tracked"`
+	Low         int   `json:"low"` // Below the pacing threshold
+	Exhausted   int   `json:"exhausted"`
+	Exhaustions int64 `json:"exhaustions"`
+	Paced       int64 `json:"paced"`
+	Rejected    int64 `json:"rejected"`
+}
+
+// QuotaExhaustedHandler is notified each time an upstream quota runs out
+type QuotaExhaustedHandler func(status QuotaStatus)
+
+// quotaKey identifies the quota of one credential on one source
+type quotaKey struct {
+	sourceID   string
+	credential string
+}
+
+// quotaState is the tracked quota plus pacing bookkeeping
+type quotaState struct {
+	status      QuotaStatus
+	nextCall    time.Time   // Earliest start of the next paced call
+	exhaustions []time.Time // Exhaustion times within the window
+}
+
+// QuotaTracker records upstream quotas reported in response headers and paces
+// calls as a quota approaches exhaustion
+type QuotaTracker struct {
+	mu       sync.Mutex
+	policy   QuotaPolicy
+	quotas   map[quotaKey]*quotaState
+	handlers []QuotaExhaustedHandler
+	now      func() time.Time
+}
+
+// NewQuotaTracker creates a quota tracker
+func NewQuotaTracker(policy QuotaPolicy) *QuotaTracker {
+	return &QuotaTracker{
+		policy: policy,
+		quotas: make(map[quotaKey]*quotaState),
+		now:    time.Now,
+	}
+}
+
+// SetPolicy replaces the pacing policy
+func (t *QuotaTracker) SetPolicy(policy QuotaPolicy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.policy = policy
+}
+
+// OnExhausted registers a handler invoked when a quota reaches zero
+func (t *QuotaTracker) OnExhausted(handler QuotaExhaustedHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers = append(t.handlers, handler)
+}
+
+// Observe records the quota reported by an upstream response. Responses
+// without rate limit headers are ignored.
+func (t *QuotaTracker) Observe(sourceID, credential string, header http.Header) {
+	now := t.now()
+	limit, remaining, resetAt, ok := ParseQuotaHeaders(header, now)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	state := t.state(quotaKey{sourceID, credential})
+	wasExhausted := state.status.Exhausted(now)
+	if limit > 0 {
+		state.status.Limit = limit
+	}
+	state.status.Remaining = remaining
+	state.status.ResetAt = resetAt
+	state.status.UpdatedAt = now
+	state.status.Observations++
+
+	var handlers []QuotaExhaustedHandler
+	if remaining <= 0 && !wasExhausted {
+		state.status.Exhaustions++
+		state.exhaustions = append(state.exhaustions, now)
+		t.pruneExhaustions(state, now)
+		handlers = make([]QuotaExhaustedHandler, len(t.handlers))
+		copy(handlers, t.handlers)
+	}
+	status := state.status
+	t.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(status)
+	}
+}
+
+// Wait paces a call against the source's known quota. Calls go straight
+// through while the quota is healthy; below the threshold they are spread
+// over the time left until the reset. It returns an error when the wait would
+// exceed the policy's MaxWait, or the context's error when it ends first.
+func (t *QuotaTracker) Wait(ctx context.Context, sourceID, credential string) error {
+	t.mu.Lock()
+	state, exists := t.quotas[quotaKey{sourceID, credential}]
+	if !exists {
+		t.mu.Unlock()
+		return nil
+	}
+
+	now := t.now()
+	status := &state.status
+	if !now.Before(status.ResetAt) {
+		// The window has reset; wait for the next response to learn the new quota
+		t.mu.Unlock()
+		return nil
+	}
+
+	var start, next time.Time
+	switch {
+	case status.Remaining <= 0:
+		start = status.ResetAt
+		next = state.nextCall
+	case status.Limit > 0 && float64(status.Remaining) < float64(status.Limit)*t.policy.Threshold:
+		interval := status.ResetAt.Sub(now) / time.Duration(status.Remaining)
+		start = state.nextCall
+		if start.Before(now) {
+			start = now
+		}
+		next = start.Add(interval)
+	default:
+		status.Remaining--
+		t.mu.Unlock()
+		return nil
+	}
+
+	wait := start.Sub(now)
+	if wait > t.policy.MaxWait {
+		status.Rejected++
+		condition := "nearly exhausted"
+		if status.Remaining <= 0 {
+			condition = "exhausted"
+		}
+		err := &types.RetryableError{
+			RetryAfter: wait,
+			Reason: fmt.Sprintf("upstream quota for source %s %s (%d of %d remaining, resets at %s)",
+				sourceID, condition, max(status.Remaining, 0), status.Limit, status.ResetAt.Format(time.RFC3339)),
+		}
+		t.mu.Unlock()
+		return err
+	}
+	state.nextCall = next
+	status.Paced++
+	if status.Remaining > 0 {
+		status.Remaining--
+	}
+	t.mu.Unlock()
+
+	if wait > 0 {
+		return sleepContext(ctx, wait)
+	}
+	return nil
+}
+
+// List returns every tracked quota, sorted by source and credential
+func (t *QuotaTracker) List() []QuotaStatus {
+	return t.filter(func(quotaKey) bool { return true })
+}
+
+// ForSource returns the tracked quotas of one source
+func (t *QuotaTracker) ForSource(sourceID string) []QuotaStatus {
+	return t.filter(func(key quotaKey) bool { return key.sourceID == sourceID })
+}
+
+// Forget drops the quotas tracked for a source
+func (t *QuotaTracker) Forget(sourceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.quotas {
+		if key.sourceID == sourceID {
+			delete(t.quotas, key)
+		}
+	}
+}
+
+// Summary totals quota activity across all tracked upstreams
+func (t *QuotaTracker) Summary() QuotaSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	summary := QuotaSummary{Tracked: len(t.quotas)}
+	for _, state := range t.quotas {
+		status := state.status
+		switch {
+		case status.Exhausted(now):
+			summary.Exhausted++
+		case status.Limit > 0 && now.Before(status.ResetAt) && float64(status.Remaining) < float64(status.Limit)*t.policy.Threshold:
+			summary.Low++
+		}
+		summary.Exhaustions += status.Exhaustions
+		summary.Paced += status.Paced
+		summary.Rejected += status.Rejected
+	}
+	return summary
+}
+
+// filter returns copies of the quotas whose key matches
+func (t *QuotaTracker) filter(match func(quotaKey) bool) []QuotaStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	statuses := make([]QuotaStatus, 0, len(t.quotas))
+	for key, state := range t.quotas {
+		if match(key) {
+			t.pruneExhaustions(state, now)
+			statuses = append(statuses, state.status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].SourceID != statuses[j].SourceID {
+			return statuses[i].SourceID < statuses[j].SourceID
+		}
+		return statuses[i].Credential < statuses[j].Credential
+	})
+	return statuses
+}
+
+// state returns the state for a key, creating it if needed. The caller holds t.mu.
+func (t *QuotaTracker) state(key quotaKey) *quotaState {
+	state, exists := t.quotas[key]
+	if !exists {
+		state = &quotaState{status: QuotaStatus{SourceID: key.sourceID, Credential: key.credential}}
+		t.quotas[key] = state
+	}
+	return state
+}
+
+// pruneExhaustions drops exhaustions older than the window. The caller holds t.mu.
+func (t *QuotaTracker) pruneExhaustions(state *quotaState, now time.Time) {
+	cutoff := now.Add(-t.policy.ExhaustionWindow)
+	kept := state.exhaustions[:0]
+	for _, at := range state.exhaustions {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	state.exhaustions = kept
+	state.status.RecentExhaustions = len(kept)
+}
+
+// ParseQuotaHeaders reads the limit, remaining count and reset time from rate
+// limit response headers. Reset values are accepted as seconds until the
+// reset or as a Unix timestamp.
+func ParseQuotaHeaders(header http.Header, now time.Time) (limit, remaining int64, resetAt time.Time, ok bool) {
+	for _, prefix := range quotaHeaderPrefixes {
+		remainingValue := header.Get(prefix + "Remaining")
+		if remainingValue == "" {
+			continue
+		}
+		remaining, err := strconv.ParseInt(strings.TrimSpace(remainingValue), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		limit, _ := strconv.ParseInt(firstQuotaValue(header.Get(prefix+"Limit")), 10, 64)
+
+		resetAt := now.Add(time.Minute) // Assume a one minute window when no reset is given
+		if reset, err := strconv.ParseInt(strings.TrimSpace(header.Get(prefix+"Reset")), 10, 64); err == nil {
+			// Values this large are Unix timestamps rather than delays
+			if reset > 1_000_000_000 {
+				resetAt = time.Unix(reset, 0)
+			} else {
+				resetAt = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+		return limit, remaining, resetAt, true
+	}
+	return 0, 0, time.Time{}, false
+}
+
+// firstQuotaValue returns the leading number of a limit header, which may
+// carry a policy suffix such as "100, 100;w=60"
+func firstQuotaValue(value string) string {
+	value, _, _ = strings.Cut(value, ",")
+	value, _, _ = strings.Cut(value, ";")
+	return strings.TrimSpace(value)
+}
+
+// credentialFingerprint identifies the upstream credential used by a call
+// without retaining it
+func credentialFingerprint(input any) string {
+	parameters, ok := input.(map[string]interface{})
+	if !ok {
+		return defaultCredential
+	}
+	for name, value := range parameters {
+		if !credentialParameters[strings.ToLower(name)] {
+			continue
+		}
+		if credential := fmt.Sprintf("%v", value); credential != "" {
+			sum := sha256.Sum256([]byte(credential))
+			return "cred-" + hex.EncodeToString(sum[:4])
+		}
+	}
+	return defaultCredential
+}
+
+// quotaTool wraps an imported tool so its calls are paced against the
+// upstream quota and its responses update it
+type quotaTool struct {
+	types.Tool
+	sourceID string
+	tracker  *QuotaTracker
+}
+
+// Execute waits for quota, runs the wrapped tool and records the quota it reports
+func (t *quotaTool) Execute(ctx context.Context, input any) (any, error) {
+	credential := credentialFingerprint(input)
+	if err := t.tracker.Wait(ctx, t.sourceID, credential); err != nil {
+		return nil, err
+	}
+
+	result, err := t.Tool.Execute(ctx, input)
+	if response, ok := result.(map[string]interface{}); ok {
+		if header, ok := response["headers"].(http.Header); ok {
+			t.tracker.Observe(t.sourceID, credential, header)
+		}
+	}
+	if retryable, ok := types.AsRetryable(err); ok && retryable.Header != nil {
+		t.tracker.Observe(t.sourceID, credential, retryable.Header)
+
+		// Without a Retry-After header, the quota reset tells the caller when to retry
+		if retryable.RetryAfter == 0 {
+			if _, _, resetAt, ok := ParseQuotaHeaders(retryable.Header, time.Now()); ok {
+				retryable.RetryAfter = max(time.Until(resetAt), 0)
+			}
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuotaHeaders(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "100")
+	header.Set("X-RateLimit-Remaining", "7")
+	header.Set("X-RateLimit-Reset", "30")
+	limit, remaining, resetAt, ok := ParseQuotaHeaders(header, now)
+	require.True(t, ok)
+	assert.Equal(t, int64(100), limit)
+	assert.Equal(t, int64(7), remaining)
+	assert.Equal(t, now.Add(30*time.Second), resetAt)
+
+	header = http.Header{}
+	header.Set("RateLimit-Limit", "50, 50;w=60")
+	header.Set("RateLimit-Remaining", "0")
+	header.Set("RateLimit-Reset", "1700000120")
+	limit, remaining, resetAt, ok = ParseQuotaHeaders(header, now)
+	require.True(t, ok)
+	assert.Equal(t, int64(50), limit)
+	assert.Equal(t, int64(0), remaining)
+	assert.Equal(t, time.Unix(1_700_000_120, 0), resetAt)
+
+	_, _, _, ok = ParseQuotaHeaders(http.Header{}, now)
+	assert.False(t, ok)
+}
+
+func TestQuotaTracker(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tracker := NewQuotaTracker(QuotaPolicy{Threshold: 0.1, MaxWait: 50 * time.Millisecond, ExhaustionWindow: time.Hour})
+	tracker.now = func() time.Time { return now }
+
+	var exhausted []QuotaStatus
+	tracker.OnExhausted(func(status QuotaStatus) {
+		exhausted = append(exhausted, status)
+	})
+
+	quota := func(remaining string) http.Header {
+		header := http.Header{}
+		header.Set("X-RateLimit-Limit", "100")
+		header.Set("X-RateLimit-Remaining", remaining)
+		header.Set("X-RateLimit-Reset", "60")
+		return header
+	}
+
+	// Unknown and healthy quotas do not delay calls
+	assert.NoError(t, tracker.Wait(context.Background(), "billing", "default"))
+	tracker.Observe("billing", "default", quota("50"))
+	assert.NoError(t, tracker.Wait(context.Background(), "billing", "default"))
+
+	// Near exhaustion, calls are spread over the time until the reset; one
+	// call per 20s with 3 calls left is longer than MaxWait, so the second is rejected
+	tracker.Observe("billing", "default", quota("3"))
+	assert.NoError(t, tracker.Wait(context.Background(), "billing", "default"))
+	err := tracker.Wait(context.Background(), "billing", "default")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nearly exhausted")
+
+	// Quotas are tracked per credential
+	assert.NoError(t, tracker.Wait(context.Background(), "billing", "cred-1234"))
+
+	// Exhaustion rejects calls and notifies handlers once per exhaustion
+	tracker.Observe("billing", "default", quota("0"))
+	tracker.Observe("billing", "default", quota("0"))
+	err = tracker.Wait(context.Background(), "billing", "default")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upstream quota for source billing exhausted")
+	retryable, ok := types.AsRetryable(err)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, retryable.RetryAfter)
+	require.Len(t, exhausted, 1)
+	assert.Equal(t, 1, exhausted[0].RecentExhaustions)
+
+	statuses := tracker.ForSource("billing")
+	require.Len(t, statuses, 1)
+	assert.Equal(t, int64(2), statuses[0].Rejected)
+	assert.Equal(t, int64(1), statuses[0].Paced)
+	assert.Equal(t, QuotaSummary{Tracked: 1, Exhausted: 1, Exhaustions: 1, Paced: 1, Rejected: 2}, tracker.Summary())
+
+	// After the reset the quota recovers and a new exhaustion is counted
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, tracker.Wait(context.Background(), "billing", "default"))
+	tracker.Observe("billing", "default", quota("0"))
+	require.Len(t, exhausted, 2)
+	assert.Equal(t, 2, exhausted[1].RecentExhaustions)
+
+	tracker.Forget("billing")
+	assert.Empty(t, tracker.List())
+}
+
+func TestCredentialFingerprint(t *testing.T) {
+	assert.Equal(t, "default", credentialFingerprint(map[string]interface{}{"petId": 1}))
+
+	fingerprint := credentialFingerprint(map[string]interface{}{"X-API-Key": "secret"})
+	assert.Regexp(t, `^cred-[0-9a-f]{8}$`, fingerprint)
+	assert.NotContains(t, fingerprint, "secret")
+	assert.Equal(t, fingerprint, credentialFingerprint(map[string]interface{}{"x-api-key": "secret"}))
+}
@@ -0,0 +1,249 @@
+package importer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// openAPIExample picks the example a spec declares for a parameter or media
+// type: the single example, or else the first named one
+func openAPIExample(example interface{}, examples openapi3.Examples) (interface{}, bool) {
+	if example != nil {
+		return example, true
+	}
+	names := make([]string, 0, len(examples))
+	for name, ref := range examples {
+		if ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+	sort.Strings(names)
+	return examples[names[0]].Value.Value, true
+}
+
+// openAPIMediaSchema converts the JSON schema of a request or response body,
+// carrying over its example. It returns nil without a JSON schema or example.
+func openAPIMediaSchema(content openapi3.Content) map[string]interface{} {
+	mediaType := content.Get("application/json")
+	if mediaType == nil {
+		return nil
+	}
+	converted := openAPISchema(mediaType.Schema, 0)
+	if example, ok := openAPIExample(mediaType.Example, mediaType.Examples); ok {
+		if converted == nil {
+			converted = map[string]interface{}{}
+		}
+		converted["example"] = example
+	}
+	return converted
+}
+
+// openAPIResponseSchema converts the JSON body of an operation's first
+// successful response, or returns nil when it declares none
+func openAPIResponseSchema(operation *openapi3.Operation) map[string]interface{} {
+	responses := operation.Responses.Map()
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if ref := responses[code]; ref != nil && ref.Value != nil {
+			if converted := openAPIMediaSchema(ref.Value.Content); converted != nil {
+				return converted
+			}
+		}
+	}
+	return nil
+}
+
+// graphQLDefinitions indexes the named types of a schema, so arguments and
+// results can be described by the types they refer to
+type graphQLDefinitions map[string]ast.Node
+
+// indexGraphQLDefinitions collects the type definitions of a schema by name
+func indexGraphQLDefinitions(doc *ast.Document) graphQLDefinitions {
+	definitions := make(graphQLDefinitions)
+	for _, def := range doc.Definitions {
+		switch typeDef := def.(type) {
+		case *ast.ObjectDefinition:
+			definitions[typeDef.Name.Value] = typeDef
+		case *ast.InterfaceDefinition:
+			definitions[typeDef.Name.Value] = typeDef
+		case *ast.UnionDefinition:
+			definitions[typeDef.Name.Value] = typeDef
+		case *ast.EnumDefinition:
+			definitions[typeDef.Name.Value] = typeDef
+		case *ast.InputObjectDefinition:
+			definitions[typeDef.Name.Value] = typeDef
+		case *ast.ScalarDefinition:
+			definitions[typeDef.Name.Value] = typeDef
+		}
+	}
+	return definitions
+}
+
+// graphQLScalarSchema maps the built-in scalars to JSON Schema
+func graphQLScalarSchema(name string) (map[string]interface{}, bool) {
+	switch name {
+	case "Int":
+		return map[string]interface{}{"type": "integer"}, true
+	case "Float":
+		return map[string]interface{}{"type": "number"}, true
+	case "Boolean":
+		return map[string]interface{}{"type": "boolean"}, true
+	case "String", "ID":
+		return map[string]interface{}{"type": "string"}, true
+	}
+	return nil, false
+}
+
+// inputSchema converts the type of an argument or input field to JSON
+// Schema. Custom scalars accept any value.
+func (definitions graphQLDefinitions) inputSchema(typeNode ast.Type, depth int) map[string]interface{} {
+	switch node := typeNode.(type) {
+	case *ast.NonNull:
+		return definitions.inputSchema(node.Type, depth)
+	case *ast.List:
+		return map[string]interface{}{"type": "array", "items": definitions.inputSchema(node.Type, depth)}
+	case *ast.Named:
+		if converted, ok := graphQLScalarSchema(node.Name.Value); ok {
+			return converted
+		}
+		switch typeDef := definitions[node.Name.Value].(type) {
+		case *ast.EnumDefinition:
+			return graphQLEnumSchema(typeDef)
+		case *ast.InputObjectDefinition:
+			if depth >= maxSchemaDepth {
+				return map[string]interface{}{"type": "object"}
+			}
+			return definitions.inputObjectSchema(typeDef.Fields, depth+1)
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// inputObjectSchema describes arguments or input fields as the properties
+// of an object. Non-null values without a default are required.
+func (definitions graphQLDefinitions) inputObjectSchema(values []*ast.InputValueDefinition, depth int) map[string]interface{} {
+	properties := make(map[string]interface{}, len(values))
+	required := []string{}
+	for _, value := range values {
+		property := definitions.inputSchema(value.Type, depth)
+		if value.Description != nil && value.Description.Value != "" {
+			property["description"] = value.Description.Value
+		}
+		if value.DefaultValue != nil {
+			property["default"] = graphQLValue(value.DefaultValue)
+		} else if _, nonNull := value.Type.(*ast.NonNull); nonNull {
+			required = append(required, value.Name.Value)
+		}
+		properties[value.Name.Value] = property
+	}
+	return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+}
+
+// outputSchema converts the type of a result to JSON Schema, describing the
+// fields the generated selection returns: those of nested objects down to
+// depth levels, leaving out fields with required arguments
+func (definitions graphQLDefinitions) outputSchema(typeNode ast.Type, depth int) map[string]interface{} {
+	switch node := typeNode.(type) {
+	case *ast.NonNull:
+		return definitions.outputSchema(node.Type, depth)
+	case *ast.List:
+		return map[string]interface{}{"type": "array", "items": definitions.outputSchema(node.Type, depth)}
+	case *ast.Named:
+		if converted, ok := graphQLScalarSchema(node.Name.Value); ok {
+			return converted
+		}
+		switch typeDef := definitions[node.Name.Value].(type) {
+		case *ast.EnumDefinition:
+			return graphQLEnumSchema(typeDef)
+		case *ast.ObjectDefinition:
+			return definitions.fieldsSchema(typeDef.Fields, depth)
+		case *ast.InterfaceDefinition:
+			converted := definitions.fieldsSchema(typeDef.Fields, depth)
+			converted["properties"].(map[string]interface{})["__typename"] = map[string]interface{}{"type": "string"}
+			return converted
+		case *ast.UnionDefinition:
+			return map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"__typename": map[string]interface{}{"type": "string"}},
+			}
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// fieldsSchema describes the selected fields of an object or interface
+func (definitions graphQLDefinitions) fieldsSchema(fields []*ast.FieldDefinition, depth int) map[string]interface{} {
+	properties := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if requiresArguments(field) {
+			continue
+		}
+		switch definitions[graphQLNamedType(field.Type)].(type) {
+		case *ast.ObjectDefinition, *ast.InterfaceDefinition, *ast.UnionDefinition:
+			if depth <= 0 {
+				continue
+			}
+		}
+		property := definitions.outputSchema(field.Type, depth-1)
+		if field.Description != nil && field.Description.Value != "" {
+			property["description"] = field.Description.Value
+		}
+		properties[field.Name.Value] = property
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// graphQLEnumSchema lists the values of an enum
+func graphQLEnumSchema(typeDef *ast.EnumDefinition) map[string]interface{} {
+	values := make([]interface{}, len(typeDef.Values))
+	for i, value := range typeDef.Values {
+		values[i] = value.Name.Value
+	}
+	return map[string]interface{}{"type": "string", "enum": values}
+}
+
+// graphQLValue converts a literal such as an argument default to its JSON value
+func graphQLValue(value ast.Value) interface{} {
+	switch node := value.(type) {
+	case *ast.IntValue:
+		if parsed, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+			return parsed
+		}
+	case *ast.FloatValue:
+		if parsed, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			return parsed
+		}
+	case *ast.StringValue:
+		return node.Value
+	case *ast.BooleanValue:
+		return node.Value
+	case *ast.EnumValue:
+		return node.Value
+	case *ast.ListValue:
+		values := make([]interface{}, len(node.Values))
+		for i, item := range node.Values {
+			values[i] = graphQLValue(item)
+		}
+		return values
+	case *ast.ObjectValue:
+		fields := make(map[string]interface{}, len(node.Fields))
+		for _, field := range node.Fields {
+			fields[field.Name.Value] = graphQLValue(field.Value)
+		}
+		return fields
+	}
+	return nil
+}
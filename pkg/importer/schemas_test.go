@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaSpec declares parameter, request and response examples
+const schemaSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets/{id}": {
+      "put": {
+        "operationId": "updatePet",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}, "example": 42},
+          {"name": "view", "in": "query", "schema": {"type": "string"},
+           "examples": {"full": {"value": "full"}, "brief": {"value": "brief"}}}
+        ],
+        "requestBody": {"required": true, "content": {"application/json": {
+          "schema": {"type": "object", "properties": {"name": {"type": "string"}}},
+          "example": {"name": "Rex"}
+        }}},
+        "responses": {
+          "404": {"description": "Missing", "content": {"application/json": {"schema": {"type": "string"}}}},
+          "200": {"description": "Updated", "content": {"application/json": {
+            "schema": {"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string", "example": "Rex"}}}
+          }}}
+        }
+      }
+    }
+  }
+}`
+
+func TestOpenAPIToolSchemas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pets.json")
+	require.NoError(t, os.WriteFile(path, []byte(schemaSpec), 0o644))
+	result, err := NewOpenAPIImporter().Import(context.Background(), SpecSource{ID: "pets", Type: SpecTypeOpenAPI, Path: path})
+	require.NoError(t, err)
+	require.Len(t, result.Tools, 1)
+	schema := result.Tools[0].Metadata().Schema
+
+	// Parameters carry their example, or the first named one
+	properties := schema["input"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "integer", "example": float64(42)}, properties["id"])
+	assert.Equal(t, "brief", properties["view"].(map[string]interface{})["example"])
+	assert.Equal(t, map[string]interface{}{"name": "Rex"}, properties["body"].(map[string]interface{})["example"])
+
+	// The response body is described by the successful response
+	output := schema["output"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":   map[string]interface{}{"type": "integer"},
+			"name": map[string]interface{}{"type": "string", "example": "Rex"},
+		},
+	}, output["body"])
+}
+
+// typedSchema has arguments of every kind of input type
+const typedSchema = `
+type Query {
+  search(
+    "Words to look for"
+    text: String!
+    limit: Int = 10
+    ratio: Float
+    exact: Boolean
+    statuses: [Status!]
+    filter: Filter
+    cursor: Cursor
+  ): [Post!]!
+}
+
+type Post {
+  id: ID!
+  status: Status
+  author: User
+}
+
+type User {
+  name: String
+}
+
+input Filter {
+  tags: [String!]!
+  nested: Filter
+}
+
+enum Status { DRAFT PUBLISHED }
+
+scalar Cursor
+`
+
+func TestGraphQLToolSchemas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.graphql")
+	require.NoError(t, os.WriteFile(path, []byte(typedSchema), 0o644))
+	result, err := NewGraphQLImporter().Import(context.Background(), SpecSource{ID: "blog", Type: SpecTypeGraphQL, Path: path,
+		Metadata: map[string]string{"endpoint": "http://localhost:4000/graphql"}})
+	require.NoError(t, err)
+	require.Len(t, result.Tools, 1)
+	schema := result.Tools[0].Metadata().Schema
+
+	// Arguments are typed by their GraphQL types, with defaults and descriptions
+	input := schema["input"].(map[string]interface{})
+	assert.Equal(t, []string{"text"}, input["required"])
+	properties := input["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "Words to look for"}, properties["text"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "default": int64(10), "description": "GraphQL argument: limit"}, properties["limit"])
+	assert.Equal(t, "number", properties["ratio"].(map[string]interface{})["type"])
+	assert.Equal(t, "boolean", properties["exact"].(map[string]interface{})["type"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "enum": []interface{}{"DRAFT", "PUBLISHED"}},
+		properties["statuses"].(map[string]interface{})["items"])
+	filter := properties["filter"].(map[string]interface{})
+	assert.Equal(t, "object", filter["type"])
+	assert.Equal(t, []string{"tags"}, filter["required"])
+	assert.Contains(t, filter["properties"], "nested")
+	assert.NotContains(t, properties["cursor"], "type")
+
+	// Results describe the fields the selection returns
+	data := schema["output"].(map[string]interface{})["properties"].(map[string]interface{})["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id":     map[string]interface{}{"type": "string"},
+				"status": map[string]interface{}{"type": "string", "enum": []interface{}{"DRAFT", "PUBLISHED"}},
+				"author": map[string]interface{}{"type": "object", "properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}}},
+			},
+		},
+	}, data["properties"].(map[string]interface{})["search"])
+}
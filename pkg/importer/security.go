@@ -0,0 +1,291 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// APICredential authenticates calls of a source's OpenAPI operations under
+// one security scheme of the spec. The fields used depend on the scheme's
+// type. Secrets are masked when the source is serialized.
+type APICredential struct {
+	APIKey       string   `json:"api_key,omitempty"`       // apiKey schemes, sent in the header, query or cookie the scheme names
+	Token        string   `json:"token,omitempty"`         // http bearer schemes
+	Username     string   `json:"username,omitempty"`      // http basic schemes
+	Password     string   `json:"password,omitempty"`      // http basic schemes
+	ClientID     string   `json:"client_id,omitempty"`     // oauth2 client credentials flows
+	ClientSecret string   `json:"client_secret,omitempty"` // oauth2 client credentials flows
+	TokenURL     string   `json:"token_url,omitempty"`     // Overrides the flow's token URL
+	Scopes       []string `json:"scopes,omitempty"`        // Requested instead of the scopes the operation requires
+	TokenAuth    string   `json:"token_auth,omitempty"`    // How the client authenticates to the token URL: basic (default) or body
+}
+
+// MarshalJSON masks the secrets
+func (c APICredential) MarshalJSON() ([]byte, error) {
+	type plain APICredential
+	masked := plain(c)
+	for _, secret := range []*string{&masked.APIKey, &masked.Token, &masked.Password, &masked.ClientSecret} {
+		if *secret != "" {
+			*secret = maskedCredential
+		}
+	}
+	return json.Marshal(masked)
+}
+
+const (
+	// tokenExpiryMargin renews cached OAuth2 tokens this long before they expire
+	tokenExpiryMargin = 30 * time.Second
+
+	// defaultTokenLifetime caches tokens whose response gives no expiry
+	defaultTokenLifetime = 5 * time.Minute
+)
+
+// cachedToken is an OAuth2 access token and when to stop using it
+type cachedToken struct {
+	accessToken string
+	expires     time.Time
+}
+
+// OAuthTokenCache obtains OAuth2 access tokens with the client credentials
+// grant and reuses them until shortly before they expire
+type OAuthTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]cachedToken // token URL, client ID and scopes -> token
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewOAuthTokenCache creates an empty token cache
+func NewOAuthTokenCache() *OAuthTokenCache {
+	return &OAuthTokenCache{
+		tokens: make(map[string]cachedToken),
+		client: &http.Client{Timeout: DefaultFetchTimeout},
+		now:    time.Now,
+	}
+}
+
+// Token returns a cached token or requests a new one, along with the key
+// under which it is cached
+func (c *OAuthTokenCache) Token(ctx context.Context, tokenURL string, credential *APICredential, scopes []string) (string, string, error) {
+	key := strings.Join([]string{tokenURL, credential.ClientID, strings.Join(scopes, " ")}, "\x00")
+
+	c.mu.Lock()
+	token, ok := c.tokens[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(token.expires) {
+		return token.accessToken, key, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	if credential.TokenAuth == "body" {
+		form.Set("client_id", credential.ClientID)
+		form.Set("client_secret", credential.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid token URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if credential.TokenAuth != "body" {
+		req.SetBasicAuth(url.QueryEscape(credential.ClientID), url.QueryEscape(credential.ClientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("token request failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.AccessToken == "" {
+		return "", "", fmt.Errorf("token response has no access_token")
+	}
+	lifetime := defaultTokenLifetime
+	if response.ExpiresIn > 0 {
+		lifetime = time.Duration(response.ExpiresIn)*time.Second - tokenExpiryMargin
+	}
+
+	c.mu.Lock()
+	c.tokens[key] = cachedToken{accessToken: response.AccessToken, expires: c.now().Add(lifetime)}
+	c.mu.Unlock()
+	return response.AccessToken, key, nil
+}
+
+// Invalidate drops a cached token the API rejected
+func (c *OAuthTokenCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, key)
+}
+
+// securityRequirements returns the operation's security requirements, or the
+// spec's when the operation declares none
+func (t *OpenAPITool) securityRequirements() openapi3.SecurityRequirements {
+	if t.operation.Security != nil {
+		return *t.operation.Security
+	}
+	return t.doc.Security
+}
+
+// securityScheme returns a security scheme of the spec by name
+func (t *OpenAPITool) securityScheme(name string) *openapi3.SecurityScheme {
+	if t.doc.Components == nil {
+		return nil
+	}
+	if ref, ok := t.doc.Components.SecuritySchemes[name]; ok && ref != nil {
+		return ref.Value
+	}
+	return nil
+}
+
+// supportedSecurityScheme reports whether credentials can be injected for a scheme
+func supportedSecurityScheme(scheme *openapi3.SecurityScheme, credential *APICredential) bool {
+	if scheme == nil {
+		return false
+	}
+	switch scheme.Type {
+	case "apiKey":
+		return scheme.In == "header" || scheme.In == "query" || scheme.In == "cookie"
+	case "http":
+		httpScheme := strings.ToLower(scheme.Scheme)
+		return httpScheme == "bearer" || httpScheme == "basic"
+	case "oauth2":
+		return credential.TokenURL != "" || (scheme.Flows != nil && scheme.Flows.ClientCredentials != nil)
+	}
+	return false
+}
+
+// authenticate adds the credentials of the first security requirement the
+// source has credentials for. Operations without requirements, or whose
+// requirements the source cannot meet, are called without credentials. It
+// returns the cache key of the OAuth2 token it used, if any.
+func (t *OpenAPITool) authenticate(ctx context.Context, req *http.Request) (string, error) {
+	if len(t.source.Credentials) == 0 {
+		return "", nil
+	}
+	for _, requirement := range t.securityRequirements() {
+		// An empty requirement makes authentication optional; prefer the
+		// alternatives that authenticate
+		if len(requirement) == 0 || !t.canMeet(requirement) {
+			continue
+		}
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var tokenKey string
+		for _, name := range names {
+			key, err := t.applyCredential(ctx, req, t.securityScheme(name), t.source.Credentials[name], requirement[name])
+			if err != nil {
+				return "", fmt.Errorf("failed to authenticate with security scheme %s: %w", name, err)
+			}
+			if key != "" {
+				tokenKey = key
+			}
+		}
+		return tokenKey, nil
+	}
+	return "", nil
+}
+
+// canMeet reports whether the source has credentials for every scheme of a requirement
+func (t *OpenAPITool) canMeet(requirement openapi3.SecurityRequirement) bool {
+	for name := range requirement {
+		credential, ok := t.source.Credentials[name]
+		if !ok || credential == nil || !supportedSecurityScheme(t.securityScheme(name), credential) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyCredential injects a credential as its security scheme prescribes
+func (t *OpenAPITool) applyCredential(ctx context.Context, req *http.Request, scheme *openapi3.SecurityScheme, credential *APICredential, scopes []string) (string, error) {
+	switch scheme.Type {
+	case "apiKey":
+		switch scheme.In {
+		case "header":
+			req.Header.Set(scheme.Name, credential.APIKey)
+		case "query":
+			query := req.URL.Query()
+			query.Set(scheme.Name, credential.APIKey)
+			req.URL.RawQuery = query.Encode()
+		case "cookie":
+			req.AddCookie(&http.Cookie{Name: scheme.Name, Value: credential.APIKey})
+		}
+	case "http":
+		if strings.EqualFold(scheme.Scheme, "basic") {
+			req.SetBasicAuth(credential.Username, credential.Password)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+credential.Token)
+		}
+	case "oauth2":
+		tokenURL := credential.TokenURL
+		if tokenURL == "" {
+			tokenURL = scheme.Flows.ClientCredentials.TokenURL
+		}
+		if len(credential.Scopes) > 0 {
+			scopes = credential.Scopes
+		}
+		token, key, err := t.tokens.Token(ctx, tokenURL, credential, scopes)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return key, nil
+	}
+	return "", nil
+}
+
+// securityWarnings reports source credentials the spec has no scheme for,
+// and schemes whose credentials cannot be injected
+func securityWarnings(doc *openapi3.T, source SpecSource) []string {
+	var warnings []string
+	names := make([]string, 0, len(source.Credentials))
+	for name := range source.Credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var scheme *openapi3.SecurityScheme
+		if doc.Components != nil {
+			if ref, ok := doc.Components.SecuritySchemes[name]; ok && ref != nil {
+				scheme = ref.Value
+			}
+		}
+		credential := source.Credentials[name]
+		switch {
+		case scheme == nil:
+			warnings = append(warnings, fmt.Sprintf("Credentials given for unknown security scheme %s", name))
+		case credential == nil || !supportedSecurityScheme(scheme, credential):
+			warnings = append(warnings, fmt.Sprintf("Security scheme %s (%s) is not supported; its credentials are not sent", name, scheme.Type))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// securedSpec declares one operation per security scheme; the server URL
+// and token URL are filled in by the test
+const securedSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Secured", "version": "1.0.0"},
+  "servers": [{"url": "%[1]s"}],
+  "security": [{"apiKeyHeader": []}],
+  "paths": {
+    "/header": {"get": {"operationId": "header", "responses": {"200": {"description": "OK"}}}},
+    "/query": {"get": {"operationId": "query", "security": [{"apiKeyQuery": []}], "responses": {"200": {"description": "OK"}}}},
+    "/bearer": {"get": {"operationId": "bearer", "security": [{"bearer": []}], "responses": {"200": {"description": "OK"}}}},
+    "/basic": {"get": {"operationId": "basic", "security": [{"basic": []}], "responses": {"200": {"description": "OK"}}}},
+    "/oauth": {"get": {"operationId": "oauth", "security": [{"oauth": ["pets:read"]}], "responses": {"200": {"description": "OK"}}}},
+    "/public": {"get": {"operationId": "public", "security": [], "responses": {"200": {"description": "OK"}}}}
+  },
+  "components": {
+    "securitySchemes": {
+      "apiKeyHeader": {"type": "apiKey", "in": "header", "name": "X-API-Key"},
+      "apiKeyQuery": {"type": "apiKey", "in": "query", "name": "api_key"},
+      "bearer": {"type": "http", "scheme": "bearer"},
+      "basic": {"type": "http", "scheme": "basic"},
+      "oauth": {"type": "oauth2", "flows": {"clientCredentials": {"tokenUrl": "%[1]s/token", "scopes": {"pets:read": "Read pets"}}}},
+      "openid": {"type": "openIdConnect", "openIdConnectUrl": "%[1]s/.well-known/openid-configuration"}
+    }
+  }
+}`
+
+func TestOpenAPISecuritySchemes(t *testing.T) {
+	var tokenRequests atomic.Int32
+	var rejectToken atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokenRequests.Add(1)
+			clientID, secret, _ := r.BasicAuth()
+			require.NoError(t, r.ParseForm())
+			if clientID != "client" || secret != "s3cret" || r.PostForm.Get("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 3600, "scope": %q}`, tokenRequests.Load(), r.PostForm.Get("scope"))
+			return
+		}
+		if r.URL.Path == "/oauth" && rejectToken.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		username, password, _ := r.BasicAuth()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"api_key_header": r.Header.Get("X-API-Key"),
+			"api_key_query":  r.URL.Query().Get("api_key"),
+			"authorization":  r.Header.Get("Authorization"),
+			"username":       username,
+			"password":       password,
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "secured.json")
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(securedSpec, server.URL)), 0o644))
+
+	ctx := context.Background()
+	source := SpecSource{ID: "secured", Type: SpecTypeOpenAPI, Path: path, Credentials: map[string]*APICredential{
+		"apiKeyHeader": {APIKey: "header-key"},
+		"apiKeyQuery":  {APIKey: "query-key"},
+		"bearer":       {Token: "bearer-token"},
+		"basic":        {Username: "alice", Password: "wonderland"},
+		"oauth":        {ClientID: "client", ClientSecret: "s3cret"},
+		"openid":       {Token: "id-token"},
+		"missing":      {APIKey: "unused"},
+	}}
+	result, err := NewOpenAPIImporter().Import(ctx, source)
+	require.NoError(t, err)
+	assert.Contains(t, result.Warnings, "Credentials given for unknown security scheme missing")
+	assert.Contains(t, result.Warnings, "Security scheme openid (openIdConnect) is not supported; its credentials are not sent")
+
+	call := func(operationID string) map[string]interface{} {
+		for _, tool := range result.Tools {
+			if tool.Name() == "openapi.secured."+operationID {
+				output, err := tool.Execute(ctx, map[string]interface{}{})
+				require.NoError(t, err)
+				response := output.(map[string]interface{})
+				if body, ok := response["body"].(map[string]interface{}); ok {
+					body["request_url"] = response["request_url"]
+					return body
+				}
+				return map[string]interface{}{"status_code": response["status_code"]}
+			}
+		}
+		t.Fatalf("tool %s not imported", operationID)
+		return nil
+	}
+
+	// Operations without their own requirements use the spec's
+	assert.Equal(t, "header-key", call("header")["api_key_header"])
+
+	// The API key is sent in the query but kept out of the reported URL
+	query := call("query")
+	assert.Equal(t, "query-key", query["api_key_query"])
+	assert.Empty(t, query["api_key_header"])
+	assert.NotContains(t, query["request_url"], "query-key")
+
+	assert.Equal(t, "Bearer bearer-token", call("bearer")["authorization"])
+	basic := call("basic")
+	assert.Equal(t, "alice", basic["username"])
+	assert.Equal(t, "wonderland", basic["password"])
+
+	// Operations that opt out of security are called without credentials
+	public := call("public")
+	assert.Empty(t, public["api_key_header"])
+	assert.Empty(t, public["authorization"])
+
+	// OAuth2 tokens are cached until the API rejects them
+	assert.Equal(t, "Bearer token-1", call("oauth")["authorization"])
+	assert.Equal(t, "Bearer token-1", call("oauth")["authorization"])
+	assert.Equal(t, int32(1), tokenRequests.Load())
+	rejectToken.Store(true)
+	assert.Equal(t, http.StatusUnauthorized, call("oauth")["status_code"])
+	rejectToken.Store(false)
+	assert.Equal(t, "Bearer token-2", call("oauth")["authorization"])
+	assert.Equal(t, int32(2), tokenRequests.Load())
+
+	// Token request failures fail the call
+	source.Credentials["oauth"] = &APICredential{ClientID: "client", ClientSecret: "wrong"}
+	result, err = NewOpenAPIImporter().Import(ctx, source)
+	require.NoError(t, err)
+	for _, tool := range result.Tools {
+		if tool.Name() == "openapi.secured.oauth" {
+			_, err := tool.Execute(ctx, map[string]interface{}{})
+			assert.ErrorContains(t, err, "failed to authenticate with security scheme oauth")
+		}
+	}
+}
+
+func TestOAuthTokenCache_BodyAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		_, _, basic := r.BasicAuth()
+		assert.False(t, basic)
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		assert.Equal(t, "s3cret", r.PostForm.Get("client_secret"))
+		assert.Equal(t, "a b", r.PostForm.Get("scope"))
+		fmt.Fprint(w, `{"access_token": "token"}`)
+	}))
+	defer server.Close()
+
+	cache := NewOAuthTokenCache()
+	credential := &APICredential{ClientID: "client", ClientSecret: "s3cret", TokenAuth: "body"}
+	token, key, err := cache.Token(context.Background(), server.URL, credential, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+	assert.NotEmpty(t, key)
+}
+
+func TestAPICredential_MarshalJSON(t *testing.T) {
+	source := SpecSource{ID: "secured", Credentials: map[string]*APICredential{
+		"oauth": {ClientID: "client", ClientSecret: "s3cret", Scopes: []string{"pets:read"}},
+		"basic": {Username: "alice", Password: "wonderland"},
+	}}
+	data, err := json.Marshal(source)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "s3cret") || strings.Contains(string(data), "wonderland"))
+	assert.Contains(t, string(data), `"client_id":"client"`)
+	assert.Contains(t, string(data), `"username":"alice"`)
+
+	// Masking does not touch the source's credentials
+	assert.Equal(t, "s3cret", source.Credentials["oauth"].ClientSecret)
+}
@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// DefaultGraphQLSelectionDepth is how many levels of nested objects the
+// generated selection of a GraphQL tool includes below the returned type
+const DefaultGraphQLSelectionDepth = 1
+
+// selectionDepthKey is the source metadata key overriding the selection depth
+const selectionDepthKey = "selection_depth"
+
+// graphQLTypes indexes the type definitions of a schema by name
+type graphQLTypes map[string]ast.Node
+
+// indexGraphQLTypes collects the object, interface and union definitions of
+// a schema, the types whose values need a selection set
+func indexGraphQLTypes(doc *ast.Document) graphQLTypes {
+	types := make(graphQLTypes)
+	for _, def := range doc.Definitions {
+		switch typeDef := def.(type) {
+		case *ast.ObjectDefinition:
+			types[typeDef.Name.Value] = typeDef
+		case *ast.InterfaceDefinition:
+			types[typeDef.Name.Value] = typeDef
+		case *ast.UnionDefinition:
+			types[typeDef.Name.Value] = typeDef
+		}
+	}
+	return types
+}
+
+// graphQLNamedType unwraps list and non-null types, e.g. [Post!]! to Post
+func graphQLNamedType(typeNode ast.Type) string {
+	switch node := typeNode.(type) {
+	case *ast.Named:
+		return node.Name.Value
+	case *ast.NonNull:
+		return graphQLNamedType(node.Type)
+	case *ast.List:
+		return graphQLNamedType(node.Type)
+	}
+	return ""
+}
+
+// selection builds the selection set of a value of the named type: its
+// scalar and enum fields, and the fields of nested objects down to depth
+// levels. Leaf types need no selection and get "".
+func (types graphQLTypes) selection(typeName string, depth int) string {
+	switch typeDef := types[typeName].(type) {
+	case *ast.ObjectDefinition:
+		return types.fieldSelection(typeDef.Fields, depth, false)
+	case *ast.InterfaceDefinition:
+		return types.fieldSelection(typeDef.Fields, depth, true)
+	case *ast.UnionDefinition:
+		parts := []string{"__typename"}
+		for _, member := range typeDef.Types {
+			if memberSelection := types.selection(member.Name.Value, depth); memberSelection != "" {
+				parts = append(parts, "... on "+member.Name.Value+" "+memberSelection)
+			}
+		}
+		return "{ " + strings.Join(parts, " ") + " }"
+	}
+	return ""
+}
+
+// fieldSelection selects the fields of an object or interface. Fields with
+// required arguments are left out, since the tool cannot supply them.
+func (types graphQLTypes) fieldSelection(fields []*ast.FieldDefinition, depth int, typename bool) string {
+	var parts []string
+	if typename {
+		parts = append(parts, "__typename")
+	}
+	for _, field := range fields {
+		if requiresArguments(field) {
+			continue
+		}
+		nested := graphQLNamedType(field.Type)
+		if _, composite := types[nested]; !composite {
+			parts = append(parts, field.Name.Value)
+			continue
+		}
+		if depth > 0 {
+			parts = append(parts, field.Name.Value+" "+types.selection(nested, depth-1))
+		}
+	}
+	// A selection set cannot be empty
+	if len(parts) == 0 {
+		parts = append(parts, "__typename")
+	}
+	return "{ " + strings.Join(parts, " ") + " }"
+}
+
+// requiresArguments reports whether a field has a non-null argument without a default
+func requiresArguments(field *ast.FieldDefinition) bool {
+	for _, arg := range field.Arguments {
+		if _, nonNull := arg.Type.(*ast.NonNull); nonNull && arg.DefaultValue == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// selectionNode is a field of a selection built from field paths
+type selectionNode struct {
+	name     string
+	children []*selectionNode
+}
+
+// child returns the named child, adding it first
+func (n *selectionNode) child(name string) *selectionNode {
+	for _, existing := range n.children {
+		if existing.name == name {
+			return existing
+		}
+	}
+	added := &selectionNode{name: name}
+	n.children = append(n.children, added)
+	return added
+}
+
+// String writes the node's children as a selection set
+func (n *selectionNode) String() string {
+	parts := make([]string, len(n.children))
+	for i, child := range n.children {
+		parts[i] = child.name
+		if len(child.children) > 0 {
+			parts[i] += " " + child.String()
+		}
+	}
+	return "{ " + strings.Join(parts, " ") + " }"
+}
+
+// fieldsSelection builds the selection set a caller asked for with the
+// "fields" input: a selection set in GraphQL syntax, with or without the
+// outer braces, or a list of dot-separated field paths like "author.name"
+func fieldsSelection(fields interface{}) (string, error) {
+	switch value := fields.(type) {
+	case string:
+		selection := strings.TrimSpace(value)
+		if selection == "" {
+			return "", fmt.Errorf("fields must not be empty")
+		}
+		if !strings.HasPrefix(selection, "{") {
+			selection = "{ " + selection + " }"
+		}
+		return selection, nil
+	case []interface{}:
+		root := &selectionNode{}
+		for _, item := range value {
+			path, ok := item.(string)
+			if !ok || strings.TrimSpace(path) == "" {
+				return "", fmt.Errorf("fields must be field paths such as \"author.name\"")
+			}
+			node := root
+			for _, name := range strings.Split(path, ".") {
+				node = node.child(strings.TrimSpace(name))
+			}
+		}
+		if len(root.children) == 0 {
+			return "", fmt.Errorf("fields must not be empty")
+		}
+		return root.String(), nil
+	}
+	return "", fmt.Errorf("fields must be a selection set or a list of field paths")
+}
@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const selectionSchema = `
+type Query {
+  post(id: ID!): Post
+  feed: [Item!]!
+  count: Int
+}
+
+interface Node {
+  id: ID!
+}
+
+type Post implements Node {
+  id: ID!
+  title: String!
+  status: Status
+  author: User!
+  comments(first: Int!): [Comment]
+}
+
+type User implements Node {
+  id: ID!
+  name: String
+  posts: [Post]
+}
+
+type Comment {
+  body: String
+}
+
+enum Status { DRAFT PUBLISHED }
+
+union Item = Post | Comment
+`
+
+func TestGraphQLSelection(t *testing.T) {
+	doc, err := parser.Parse(parser.ParseParams{Source: selectionSchema})
+	require.NoError(t, err)
+	types := indexGraphQLTypes(doc)
+
+	// Scalars and enums need no selection
+	assert.Equal(t, "", types.selection("Int", 1))
+	assert.Equal(t, "", types.selection("Status", 1))
+
+	// Nested objects are selected down to the depth; fields with required
+	// arguments are left out
+	assert.Equal(t, "{ id title status }", types.selection("Post", 0))
+	assert.Equal(t, "{ id title status author { id name } }", types.selection("Post", 1))
+	assert.Equal(t, "{ id title status author { id name posts { id title status } } }", types.selection("Post", 2))
+
+	// Unions select the members with inline fragments
+	assert.Equal(t, "{ __typename ... on Post { id title status } ... on Comment { body } }", types.selection("Item", 0))
+	assert.Equal(t, "{ __typename id }", types.selection("Node", 0))
+}
+
+func TestFieldsSelection(t *testing.T) {
+	selection, err := fieldsSelection("id title")
+	require.NoError(t, err)
+	assert.Equal(t, "{ id title }", selection)
+
+	selection, err = fieldsSelection("{ id author { name } }")
+	require.NoError(t, err)
+	assert.Equal(t, "{ id author { name } }", selection)
+
+	selection, err = fieldsSelection([]interface{}{"id", "author.name", "author.id", "title"})
+	require.NoError(t, err)
+	assert.Equal(t, "{ id author { name id } title }", selection)
+
+	for _, invalid := range []interface{}{"", []interface{}{}, []interface{}{1}, 42} {
+		_, err := fieldsSelection(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestGraphQLToolSelection(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		queries = append(queries, request.Query)
+		w.Write([]byte(`{"data": {}}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "schema.graphql")
+	require.NoError(t, os.WriteFile(path, []byte(selectionSchema), 0644))
+
+	importer := NewGraphQLImporter()
+	importer.SetSelectionDepth(0)
+	result, err := importer.Import(context.Background(), SpecSource{ID: "blog", Type: SpecTypeGraphQL, Path: path,
+		Metadata: map[string]string{"endpoint": server.URL, "selection_depth": "1"}})
+	require.NoError(t, err)
+
+	tools := make(map[string]*GraphQLTool)
+	for _, tool := range result.Tools {
+		tools[tool.Name()] = tool.(*GraphQLTool)
+	}
+
+	// The source's selection depth overrides the importer's
+	_, err = tools["graphql.blog.query_post"].Execute(context.Background(), map[string]interface{}{"id": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "query ($id: ID!) { post(id: $id) { id title status author { id name } } }", queries[0])
+
+	// Callers can choose the fields, which are not sent as variables
+	_, err = tools["graphql.blog.query_post"].Execute(context.Background(), map[string]interface{}{"id": "1", "fields": []interface{}{"title", "author.name"}})
+	require.NoError(t, err)
+	assert.Equal(t, "query ($id: ID!) { post(id: $id) { title author { name } } }", queries[1])
+
+	// Scalar results have no selection or fields input
+	_, err = tools["graphql.blog.query_count"].Execute(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "query { count }", queries[2])
+	properties := tools["graphql.blog.query_count"].Metadata().Schema["input"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.NotContains(t, properties, "fields")
+}
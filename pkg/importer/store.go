@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// sourcesBucket holds the stored sources by ID
+const sourcesBucket = "spec_sources"
+
+// StoredSource is an imported source as the source store keeps it
+type StoredSource struct {
+	Source SpecSource
+	Watch  bool // The spec file is watched for changes
+}
+
+// SourceStore keeps imported sources and their import settings in BoltDB, so
+// they can be imported again when the server restarts. Records are gob
+// encoded because the JSON encoding masks the credentials sources carry.
+type SourceStore struct {
+	db *bolt.DB
+}
+
+// OpenSourceStore opens or creates the source store at path
+func OpenSourceStore(path string) (*SourceStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create source store directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source store: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(sourcesBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize source store: %w", err)
+	}
+	return &SourceStore{db: db}, nil
+}
+
+// Close releases the BoltDB file
+func (s *SourceStore) Close() error {
+	return s.db.Close()
+}
+
+// Put stores a source, keeping whether it is watched
+func (s *SourceStore) Put(source SpecSource) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sourcesBucket))
+		stored := StoredSource{Source: source}
+		if data := bucket.Get([]byte(source.ID)); data != nil {
+			var existing StoredSource
+			if err := decodeStoredSource(data, &existing); err == nil {
+				stored.Watch = existing.Watch
+			}
+		}
+		return putStoredSource(bucket, stored)
+	})
+}
+
+// SetWatch records whether the file of a stored source is watched. Sources
+// not in the store are ignored.
+func (s *SourceStore) SetWatch(sourceID string, watch bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sourcesBucket))
+		data := bucket.Get([]byte(sourceID))
+		if data == nil {
+			return nil
+		}
+		var stored StoredSource
+		if err := decodeStoredSource(data, &stored); err != nil {
+			return err
+		}
+		stored.Watch = watch
+		return putStoredSource(bucket, stored)
+	})
+}
+
+// Delete removes a stored source
+func (s *SourceStore) Delete(sourceID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(sourcesBucket)).Delete([]byte(sourceID))
+	})
+}
+
+// List returns the stored sources sorted by ID
+func (s *SourceStore) List() ([]StoredSource, error) {
+	var sources []StoredSource
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(sourcesBucket)).ForEach(func(_, data []byte) error {
+			var stored StoredSource
+			if err := decodeStoredSource(data, &stored); err != nil {
+				return err
+			}
+			sources = append(sources, stored)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// putStoredSource encodes a source into the bucket
+func putStoredSource(bucket *bolt.Bucket, stored StoredSource) error {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(stored); err != nil {
+		return fmt.Errorf("failed to encode source %s: %w", stored.Source.ID, err)
+	}
+	return bucket.Put([]byte(stored.Source.ID), buffer.Bytes())
+}
+
+// decodeStoredSource decodes a source read from the bucket
+func decodeStoredSource(data []byte, stored *StoredSource) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(stored); err != nil {
+		return fmt.Errorf("failed to decode stored source: %w", err)
+	}
+	return nil
+}
+
+// SetSourceStore persists imported sources in a store until they are
+// removed, so RestoreSources can import them again after a restart
+func (m *ImporterManager) SetSourceStore(store *SourceStore) {
+	m.store = store
+}
+
+// persistSource stores an imported source, warning when it cannot
+func (m *ImporterManager) persistSource(source SpecSource, result *ImportResult) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.Put(source); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("source is imported but will not be restored after a restart: %v", err))
+	}
+}
+
+// persistWatch records whether a source's file is watched
+func (m *ImporterManager) persistWatch(sourceID string, watch bool) error {
+	if m.store == nil {
+		return nil
+	}
+	return m.store.SetWatch(sourceID, watch)
+}
+
+// RestoreSources imports every source of the store again, e.g. when the
+// server starts. Read-only mode does not stop restoring. A source that fails
+// to import stays stored, so the next start tries it again.
+func (m *ImporterManager) RestoreSources(ctx context.Context) ([]GroupOperationResult, error) {
+	if m.store == nil {
+		return nil, nil
+	}
+	stored, err := m.store.List()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]GroupOperationResult, 0, len(stored))
+	for _, entry := range stored {
+		result := GroupOperationResult{SourceID: entry.Source.ID}
+		imported, err := m.importSpec(ctx, entry.Source)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Success = true
+			result.ToolCount = len(imported.Tools)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// watchedSources lists the stored sources whose files were watched
+func (m *ImporterManager) watchedSources() ([]SpecSource, error) {
+	if m.store == nil {
+		return nil, nil
+	}
+	stored, err := m.store.List()
+	if err != nil {
+		return nil, err
+	}
+	var watched []SpecSource
+	for _, entry := range stored {
+		if entry.Watch {
+			watched = append(watched, entry.Source)
+		}
+	}
+	return watched, nil
+}
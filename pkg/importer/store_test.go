@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSourceStore_RestoreSources(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sources.db")
+	store, err := OpenSourceStore(path)
+	require.NoError(t, err)
+
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(stubImporter{})
+	manager.SetSourceStore(store)
+
+	billing := SpecSource{
+		ID:          "billing",
+		Type:        SpecTypeOpenAPI,
+		Path:        "billing.yaml",
+		Naming:      &NamingStrategy{Case: NameCaseCamel},
+		Credentials: map[string]*APICredential{"apiKey": {APIKey: "s3cret"}},
+	}
+	_, err = manager.ImportSpec(ctx, billing)
+	require.NoError(t, err)
+	_, err = manager.ImportSpec(ctx, SpecSource{ID: "users", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	require.NoError(t, manager.persistWatch("billing", true))
+	_, err = manager.ImportSpec(ctx, SpecSource{ID: "legacy", Type: SpecTypeOpenAPI})
+	require.NoError(t, err)
+	require.NoError(t, manager.RemoveSpec(ctx, "legacy"))
+
+	// Re-importing keeps the source watched
+	billing.Description = "Invoices"
+	_, err = manager.ImportSpec(ctx, billing)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// A restarted manager imports the stored sources with their settings and
+	// credentials, which the JSON encoding would mask
+	store, err = OpenSourceStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	stored, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "billing", stored[0].Source.ID)
+	assert.True(t, stored[0].Watch)
+	assert.Equal(t, "Invoices", stored[0].Source.Description)
+	assert.Equal(t, "s3cret", stored[0].Source.Credentials["apiKey"].APIKey)
+	assert.False(t, stored[1].Watch)
+
+	registry = mapRegistry{}
+	manager = NewImporterManager(registry)
+	manager.RegisterImporter(stubImporter{})
+	manager.SetSourceStore(store)
+	manager.SetWriteGuard(func() error { return assert.AnError })
+	results, err := manager.RestoreSources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []GroupOperationResult{
+		{SourceID: "billing", Success: true, ToolCount: 2},
+		{SourceID: "users", Success: true, ToolCount: 2},
+	}, results, "read-only mode does not stop restoring")
+	assert.Contains(t, registry, "openapi.billing.list")
+	source, exists := manager.GetSource("billing")
+	require.True(t, exists)
+	assert.Equal(t, billing.Naming, source.Naming)
+
+	watched, err := manager.watchedSources()
+	require.NoError(t, err)
+	require.Len(t, watched, 1)
+	assert.Equal(t, "billing", watched[0].ID)
+}
+
+func TestSourceStore_FailedRestore(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenSourceStore(filepath.Join(t.TempDir(), "sources.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.Put(SpecSource{ID: "broken", Type: SpecTypeOpenAPI, Path: "broken"}))
+
+	// Sources that fail to import stay stored for the next start
+	manager := NewImporterManager(mapRegistry{})
+	manager.RegisterImporter(stubImporter{})
+	manager.SetSourceStore(store)
+	results, err := manager.RestoreSources(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "cannot read broken")
+	stored, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+
+	// Watching is only restored for sources that were restored
+	require.NoError(t, store.SetWatch("broken", true))
+	watcher, err := NewFileWatcher(manager, zap.NewNop())
+	require.NoError(t, err)
+	defer watcher.Stop()
+	require.NoError(t, watcher.RestoreWatches())
+	assert.Empty(t, watcher.GetWatchedFiles())
+}
@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// SyntheticTag marks the tools that generate fake data instead of calling an API
+const SyntheticTag = "synthetic"
+
+// DefaultSyntheticMaxCount bounds the objects one synthetic call generates
+const DefaultSyntheticMaxCount = 100
+
+// SyntheticConfig controls the synthetic data tools generated for the named
+// schemas of imported specifications
+type SyntheticConfig struct {
+	Enabled  bool `json:"enabled"`
+	MaxCount int  `json:"max_count"` // Zero uses DefaultSyntheticMaxCount
+}
+
+// SetSynthetic sets whether imports also register synthetic data tools.
+// It applies from the next import or reload of each source.
+func (m *ImporterManager) SetSynthetic(config SyntheticConfig) {
+	if config.MaxCount <= 0 {
+		config.MaxCount = DefaultSyntheticMaxCount
+	}
+	m.synthetic = config
+}
+
+// syntheticTools returns a generator tool for each named schema of an import,
+// sorted by schema name
+func syntheticTools(source SpecSource, schemas map[string]map[string]interface{}, maxCount int) []types.Tool {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tools := make([]types.Tool, 0, len(names))
+	for _, name := range names {
+		if schemas[name] == nil {
+			continue
+		}
+		tools = append(tools, &SyntheticTool{
+			source:     source,
+			schemaName: name,
+			schema:     schemas[name],
+			maxCount:   maxCount,
+		})
+	}
+	return tools
+}
+
+// SyntheticTool generates fake objects that satisfy a named schema of an
+// imported specification. It never calls the API the schema comes from.
+type SyntheticTool struct {
+	source     SpecSource
+	schemaName string
+	schema     map[string]interface{}
+	maxCount   int
+}
+
+// Name returns "<type>.<source>.generate_<schema>", e.g. openapi.petstore.generate_pet
+func (t *SyntheticTool) Name() string {
+	return fmt.Sprintf("%s.%s.generate_%s", t.source.Type, t.source.ID, strings.Join(nameWords(t.schemaName), "_"))
+}
+
+// Description returns the tool description
+func (t *SyntheticTool) Description() string {
+	return fmt.Sprintf("Generate synthetic %s objects that satisfy the %s schema of %s. Data is fake and no API is called.",
+		t.schemaName, t.schemaName, t.source.ID)
+}
+
+// Execute generates count objects, reproducibly when a seed is given. The
+// seed used is returned so a run can be repeated.
+func (t *SyntheticTool) Execute(ctx context.Context, input any) (any, error) {
+	params, _ := input.(map[string]interface{})
+	if input != nil && params == nil {
+		return nil, fmt.Errorf("input must be a JSON object")
+	}
+
+	count := 1
+	if value, exists := params["count"]; exists {
+		n, ok := wholeNumber(value)
+		if !ok || n < 1 || n > int64(t.maxCount) {
+			return nil, fmt.Errorf("count must be an integer between 1 and %d", t.maxCount)
+		}
+		count = int(n)
+	}
+	seed := time.Now().UnixNano()
+	if value, exists := params["seed"]; exists {
+		n, ok := wholeNumber(value)
+		if !ok {
+			return nil, fmt.Errorf("seed must be an integer")
+		}
+		seed = n
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	objects := make([]interface{}, count)
+	for i := range objects {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		objects[i] = schema.Fake(t.schema, rng)
+	}
+	return map[string]interface{}{
+		"objects":   objects,
+		"count":     count,
+		"seed":      seed,
+		"schema":    t.schemaName,
+		"synthetic": true,
+	}, nil
+}
+
+// Metadata returns the tool metadata, tagged synthetic
+func (t *SyntheticTool) Metadata() types.ToolMetadata {
+	return types.ToolMetadata{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Version:     "1.0.0",
+		Source:      string(t.source.Type),
+		Tags:        []string{string(t.source.Type), SyntheticTag},
+		Schema: map[string]interface{}{
+			"input": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"count": map[string]interface{}{
+						"type":        "integer",
+						"minimum":     1,
+						"maximum":     t.maxCount,
+						"default":     1,
+						"description": "Number of objects to generate",
+					},
+					"seed": map[string]interface{}{
+						"type":        "integer",
+						"description": "Seed for reproducible output; random when omitted",
+					},
+				},
+				"required": []string{},
+			},
+			"output": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"objects":   map[string]interface{}{"type": "array", "items": t.schema},
+					"count":     map[string]interface{}{"type": "integer"},
+					"seed":      map[string]interface{}{"type": "integer"},
+					"schema":    map[string]interface{}{"type": "string"},
+					"synthetic": map[string]interface{}{"type": "boolean"},
+				},
+			},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// wholeNumber converts a decoded JSON number or Go integer to int64
+func wholeNumber(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		if n != float64(int64(n)) {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const syntheticPetstore = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Petstore", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["id", "name", "status"],
+        "properties": {
+          "id": {"type": "integer", "minimum": 1},
+          "name": {"type": "string", "minLength": 3},
+          "status": {"type": "string", "enum": ["available", "sold"]},
+          "owner": {"$ref": "#/components/schemas/Owner"}
+        }
+      },
+      "Owner": {
+        "type": "object",
+        "required": ["email"],
+        "properties": {"email": {"type": "string", "format": "email"}}
+      }
+    }
+  }
+}`
+
+func TestImporterManager_SyntheticTools(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "petstore.json")
+	require.NoError(t, os.WriteFile(path, []byte(syntheticPetstore), 0o644))
+	source := SpecSource{ID: "petstore", Type: SpecTypeOpenAPI, Path: path}
+
+	// Disabled by default
+	registry := mapRegistry{}
+	manager := NewImporterManager(registry)
+	manager.RegisterImporter(NewOpenAPIImporter())
+	_, err := manager.ImportSpec(ctx, source)
+	require.NoError(t, err)
+	assert.Len(t, registry, 1)
+
+	manager.SetSynthetic(SyntheticConfig{Enabled: true, MaxCount: 5})
+	_, err = manager.ReloadSpec(ctx, source.ID)
+	require.NoError(t, err)
+	require.Contains(t, registry, "openapi.petstore.generate_pet")
+	require.Contains(t, registry, "openapi.petstore.generate_owner")
+
+	tool := registry["openapi.petstore.generate_pet"]
+	_, isSynthetic := tool.(*SyntheticTool)
+	assert.True(t, isSynthetic, "synthetic tools bypass upstream throttles and quotas")
+	metadata := tool.Metadata()
+	assert.Contains(t, metadata.Tags, SyntheticTag)
+	assert.False(t, metadata.Idempotent)
+
+	// Objects satisfy the schema, and a seed reproduces them
+	output, err := tool.Execute(ctx, map[string]interface{}{"count": float64(3), "seed": float64(42)})
+	require.NoError(t, err)
+	result := output.(map[string]interface{})
+	assert.Equal(t, 3, result["count"])
+	assert.Equal(t, int64(42), result["seed"])
+	outputSchema := metadata.Schema["output"].(map[string]interface{})
+	require.NoError(t, schema.Validate("output", outputSchema, result))
+	again, err := tool.Execute(ctx, map[string]interface{}{"count": 3, "seed": 42})
+	require.NoError(t, err)
+	assert.Equal(t, result["objects"], again.(map[string]interface{})["objects"])
+
+	_, err = tool.Execute(ctx, map[string]interface{}{"count": 6})
+	assert.Error(t, err, "count is bounded by max_count")
+
+	// Naming strategies apply, and removal covers synthetic tools
+	manager.SetDefaultNaming(NamingStrategy{Case: NameCaseCamel})
+	_, err = manager.ReloadSpec(ctx, source.ID)
+	require.NoError(t, err)
+	assert.Contains(t, registry, "openapi.petstore.generatePet")
+	require.NoError(t, manager.RemoveSpec(ctx, source.ID))
+	assert.Empty(t, registry)
+}
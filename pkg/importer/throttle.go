@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// SourceLimits bounds the outbound load a single spec source may put on its upstream.
+// A zero value for MaxConcurrent or RequestsPerSecond disables that limit.
+type SourceLimits struct {
+	MaxConcurrent     int     `json:"max_concurrent"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	QueueTimeoutMs    int64   `json:"queue_timeout_ms"` // How long an invocation may wait for capacity
+}
+
+// Enabled reports whether any limit is configured
+func (l SourceLimits) Enabled() bool {
+	return l.MaxConcurrent > 0 || l.RequestsPerSecond > 0
+}
+
+// ThrottleStats reports throttling activity for a spec source
+type ThrottleStats struct {
+	SourceID    string       `json:"source_id"`
+	Limits      SourceLimits `json:"limits"`
+	Invocations int64        `json:"invocations"`
+	Throttled   int64        `json:"throttled"` // Invocations that had to queue
+	Rejected    int64        `json:"rejected"`  // Invocations that timed out in the queue
+	InFlight    int64        `json:"in_flight"`
+	Queued      int64        `json:"queued"`
+	TotalWaitMs int64        `json:"total_wait_ms"`
+}
+
+// SourceThrottle enforces SourceLimits for every tool of one spec source
+type SourceThrottle struct {
+	sourceID string
+	limits   SourceLimits
+	slots    chan struct{} // nil when concurrency is unlimited
+
+	rateMu   sync.Mutex
+	nextSlot time.Time // Earliest start time for the next rate-limited invocation
+
+	invocations atomic.Int64
+	throttled   atomic.Int64
+	rejected    atomic.Int64
+	inFlight    atomic.Int64
+	queued      atomic.Int64
+	totalWaitMs atomic.Int64
+}
+
+// NewSourceThrottle creates a throttle for a spec source
+func NewSourceThrottle(sourceID string, limits SourceLimits) *SourceThrottle {
+	t := &SourceThrottle{
+		sourceID: sourceID,
+		limits:   limits,
+	}
+	if limits.MaxConcurrent > 0 {
+		t.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return t
+}
+
+// Acquire waits for capacity, returning a release function or an error when
+// the queue timeout elapses or the context ends first
+func (t *SourceThrottle) Acquire(ctx context.Context) (func(), error) {
+	t.invocations.Add(1)
+	start := time.Now()
+	deadline := start.Add(time.Duration(t.limits.QueueTimeoutMs) * time.Millisecond)
+	waited := false
+
+	// Requests per second: wait for the next start slot and claim it once
+	// reached, so callers that give up while waiting hold no reservation
+	if t.limits.RequestsPerSecond > 0 {
+		interval := time.Duration(float64(time.Second) / t.limits.RequestsPerSecond)
+
+		for {
+			now := time.Now()
+			t.rateMu.Lock()
+			slot := t.nextSlot
+			if !slot.After(now) {
+				t.nextSlot = now.Add(interval)
+				t.rateMu.Unlock()
+				break
+			}
+			t.rateMu.Unlock()
+			if slot.After(deadline) {
+				t.rejected.Add(1)
+				return nil, fmt.Errorf("source %s rate limit exceeded (%.2f requests per second)", t.sourceID, t.limits.RequestsPerSecond)
+			}
+
+			waited = true
+			t.queued.Add(1)
+			err := sleepContext(ctx, slot.Sub(now))
+			t.queued.Add(-1)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Max concurrent: take a slot, queueing until the deadline
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		default:
+			waited = true
+			t.queued.Add(1)
+			timer := time.NewTimer(time.Until(deadline))
+			select {
+			case t.slots <- struct{}{}:
+				timer.Stop()
+				t.queued.Add(-1)
+			case <-timer.C:
+				t.queued.Add(-1)
+				t.rejected.Add(1)
+				return nil, fmt.Errorf("source %s concurrency limit reached (%d in flight)", t.sourceID, t.limits.MaxConcurrent)
+			case <-ctx.Done():
+				timer.Stop()
+				t.queued.Add(-1)
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if waited {
+		t.throttled.Add(1)
+		t.totalWaitMs.Add(time.Since(start).Milliseconds())
+	}
+	t.inFlight.Add(1)
+
+	return func() {
+		t.inFlight.Add(-1)
+		if t.slots != nil {
+			<-t.slots
+		}
+	}, nil
+}
+
+// Stats returns a snapshot of the throttle counters
+func (t *SourceThrottle) Stats() ThrottleStats {
+	return ThrottleStats{
+		SourceID:    t.sourceID,
+		Limits:      t.limits,
+		Invocations: t.invocations.Load(),
+		Throttled:   t.throttled.Load(),
+		Rejected:    t.rejected.Load(),
+		InFlight:    t.inFlight.Load(),
+		Queued:      t.queued.Load(),
+		TotalWaitMs: t.totalWaitMs.Load(),
+	}
+}
+
+// throttledTool wraps an imported tool so its executions respect the source limits
+type throttledTool struct {
+	types.Tool
+	throttle *SourceThrottle
+}
+
+// Execute runs the wrapped tool once capacity is available
+func (t *throttledTool) Execute(ctx context.Context, input any) (any, error) {
+	release, err := t.throttle.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return t.Tool.Execute(ctx, input)
+}
+
+// sleepContext sleeps for the duration unless the context ends first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceThrottle_CancelledWaitersFreeTheirSlots(t *testing.T) {
+	throttle := NewSourceThrottle("petstore", SourceLimits{RequestsPerSecond: 5, QueueTimeoutMs: 5000})
+	start := time.Now()
+	release, err := throttle.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+
+	// Callers giving up while waiting for the next slot
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := throttle.Acquire(ctx)
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+
+	// The next caller starts one interval after the first, not four
+	release, err = throttle.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 190*time.Millisecond)
+	assert.Less(t, elapsed, 500*time.Millisecond)
+
+	stats := throttle.Stats()
+	assert.Equal(t, int64(5), stats.Invocations)
+	assert.Zero(t, stats.Queued)
+	assert.Zero(t, stats.Rejected)
+}
+
+func TestSourceThrottle_RejectsBeyondQueueTimeout(t *testing.T) {
+	throttle := NewSourceThrottle("petstore", SourceLimits{RequestsPerSecond: 1, QueueTimeoutMs: 100})
+	release, err := throttle.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+
+	_, err = throttle.Acquire(context.Background())
+	assert.ErrorContains(t, err, "rate limit exceeded")
+	assert.Equal(t, int64(1), throttle.Stats().Rejected)
+}
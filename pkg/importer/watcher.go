@@ -21,6 +21,7 @@ type FileWatcher struct {
 	debounce map[string]*time.Timer // debounce timers for file changes
 	ctx      context.Context
 	cancel   context.CancelFunc
+	done     chan struct{} // Closed when the watch loop exits
 }
 
 // NewFileWatcher creates a new file watcher
@@ -40,6 +41,7 @@ func NewFileWatcher(manager *ImporterManager, logger *zap.Logger) (*FileWatcher,
 		debounce: make(map[string]*time.Timer),
 		ctx:      ctx,
 		cancel:   cancel,
+		done:     make(chan struct{}),
 	}
 
 	// Start watching in a goroutine
@@ -71,6 +73,11 @@ func (w *FileWatcher) WatchSpec(source SpecSource) error {
 
 	// Track the mapping
 	w.watching[absPath] = source.ID
+	if err := w.manager.persistWatch(source.ID, true); err != nil {
+		w.logger.Warn("Failed to persist file watching",
+			zap.String("source_id", source.ID),
+			zap.Error(err))
+	}
 
 	w.logger.Info("Started watching specification file",
 		zap.String("source_id", source.ID),
@@ -107,6 +114,11 @@ func (w *FileWatcher) UnwatchSpec(sourceID string) error {
 
 	// Clean up tracking
 	delete(w.watching, pathToRemove)
+	if err := w.manager.persistWatch(sourceID, false); err != nil {
+		w.logger.Warn("Failed to persist file watching",
+			zap.String("source_id", sourceID),
+			zap.Error(err))
+	}
 
 	// Cancel any pending debounce timer
 	if timer, exists := w.debounce[pathToRemove]; exists {
@@ -121,8 +133,29 @@ func (w *FileWatcher) UnwatchSpec(sourceID string) error {
 	return nil
 }
 
+// RestoreWatches watches the files of restored sources that were watched
+// before the server restarted. Sources that failed to restore are skipped.
+func (w *FileWatcher) RestoreWatches() error {
+	watched, err := w.manager.watchedSources()
+	if err != nil {
+		return err
+	}
+	for _, source := range watched {
+		if _, restored := w.manager.GetSource(source.ID); !restored {
+			continue
+		}
+		if err := w.WatchSpec(source); err != nil {
+			w.logger.Warn("Failed to restore file watching",
+				zap.String("source_id", source.ID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
 // watch runs the file watching loop
 func (w *FileWatcher) watch() {
+	defer close(w.done)
 	defer w.watcher.Close()
 
 	for {
@@ -234,6 +267,16 @@ func (w *FileWatcher) Stop() {
 	w.cancel()
 }
 
+// Running reports whether the watch loop is still processing file events
+func (w *FileWatcher) Running() bool {
+	select {
+	case <-w.done:
+		return false
+	default:
+		return true
+	}
+}
+
 // GetWatchedFiles returns a list of currently watched files
 func (w *FileWatcher) GetWatchedFiles() map[string]string {
 	w.mu.RLock()
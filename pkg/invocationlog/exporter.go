@@ -0,0 +1,233 @@
+package invocationlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Format selects the sink records are exported to
+type Format string
+
+const (
+	FormatJSONLines Format = "jsonl"
+	FormatSyslog    Format = "syslog"
+	FormatHTTP      Format = "http"
+)
+
+// RedactionAction describes how a record field is redacted before export
+type RedactionAction string
+
+const (
+	RedactDrop RedactionAction = "drop" // Remove the value
+	RedactMask RedactionAction = "mask" // Replace the value with a fixed marker
+	RedactHash RedactionAction = "hash" // Replace the value with its sha256 digest
+)
+
+const redactedMarker = "[REDACTED]"
+
+// Config holds invocation log exporter settings
+type Config struct {
+	Format        Format
+	Path          string // JSON Lines output file
+	SyslogNetwork string // udp, tcp or unix
+	SyslogAddress string
+	HTTPURL       string
+	HTTPTimeout   time.Duration
+	BufferSize    int                        // Records queued before new ones are dropped
+	Redaction     map[string]RedactionAction // Record field (e.g. "actor.id") -> action
+}
+
+// DefaultConfig returns the default exporter configuration
+func DefaultConfig() Config {
+	return Config{
+		Format:        FormatJSONLines,
+		Path:          "./data/invocations.jsonl",
+		SyslogNetwork: "udp",
+		HTTPTimeout:   5 * time.Second,
+		BufferSize:    1024,
+		Redaction:     map[string]RedactionAction{},
+	}
+}
+
+// Sink receives exported records
+type Sink interface {
+	Write(record Record) error
+	Close() error
+}
+
+// Exporter redacts invocation records and writes them to a sink in the background
+type Exporter struct {
+	sink      Sink
+	redaction map[string]RedactionAction
+	records   chan Record
+	logger    *zap.Logger
+	dropped   atomic.Int64
+	wg        sync.WaitGroup
+	closeMu   sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+}
+
+// NewExporter creates an exporter writing to the sink selected by config.Format
+func NewExporter(config Config, logger *zap.Logger) (*Exporter, error) {
+	if err := validateRedaction(config.Redaction); err != nil {
+		return nil, err
+	}
+
+	var sink Sink
+	var err error
+	switch config.Format {
+	case FormatJSONLines, "":
+		sink, err = NewFileSink(config.Path)
+	case FormatSyslog:
+		sink, err = NewSyslogSink(config.SyslogNetwork, config.SyslogAddress)
+	case FormatHTTP:
+		sink, err = NewHTTPSink(config.HTTPURL, config.HTTPTimeout)
+	default:
+		return nil, fmt.Errorf("unsupported invocation log format: %s", config.Format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s sink: %w", config.Format, err)
+	}
+
+	return NewExporterWithSink(sink, config, logger), nil
+}
+
+// NewExporterWithSink creates an exporter writing to a caller-provided sink
+func NewExporterWithSink(sink Sink, config Config, logger *zap.Logger) *Exporter {
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultConfig().BufferSize
+	}
+
+	e := &Exporter{
+		sink:      sink,
+		redaction: config.Redaction,
+		records:   make(chan Record, config.BufferSize),
+		logger:    logger,
+	}
+
+	e.wg.Add(1)
+	go e.run()
+
+	return e
+}
+
+// Record queues an invocation record for export. It never blocks the caller;
+// records are dropped and counted when the queue is full, and dropped
+// silently once the exporter is closed.
+func (e *Exporter) Record(record Record) {
+	record.SchemaVersion = SchemaVersion
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+	e.redact(&record)
+
+	e.closeMu.RLock()
+	defer e.closeMu.RUnlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.records <- record:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of records discarded because the queue was full
+func (e *Exporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Close flushes queued records and closes the sink
+func (e *Exporter) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		e.closeMu.Lock()
+		e.closed = true
+		close(e.records)
+		e.closeMu.Unlock()
+		e.wg.Wait()
+		err = e.sink.Close()
+	})
+	return err
+}
+
+// run drains the queue into the sink
+func (e *Exporter) run() {
+	defer e.wg.Done()
+
+	for record := range e.records {
+		if err := e.sink.Write(record); err != nil {
+			e.logger.Warn("Failed to export invocation record",
+				zap.String("tool", record.Tool),
+				zap.Error(err))
+		}
+	}
+}
+
+// redact applies the configured redaction rules to a record
+func (e *Exporter) redact(record *Record) {
+	for field, action := range e.redaction {
+		value := recordField(record, field)
+		if value == nil || *value == "" {
+			continue
+		}
+
+		switch action {
+		case RedactDrop:
+			*value = ""
+		case RedactMask:
+			*value = redactedMarker
+		case RedactHash:
+			sum := sha256.Sum256([]byte(*value))
+			*value = "sha256:" + hex.EncodeToString(sum[:])
+		}
+	}
+}
+
+// recordField returns the redactable string field addressed by its JSON path
+func recordField(record *Record, field string) *string {
+	switch field {
+	case "invocation_id":
+		return &record.InvocationID
+	case "actor.id":
+		return &record.Actor.ID
+	case "actor.name":
+		return &record.Actor.Name
+	case "actor.address":
+		return &record.Actor.Address
+	case "tool":
+		return &record.Tool
+	case "tool_source":
+		return &record.ToolSource
+	case "params_hash":
+		return &record.ParamsHash
+	case "error":
+		return &record.Error
+	default:
+		return nil
+	}
+}
+
+// validateRedaction rejects rules for unknown fields or actions
+func validateRedaction(rules map[string]RedactionAction) error {
+	var probe Record
+	for field, action := range rules {
+		if recordField(&probe, field) == nil {
+			return fmt.Errorf("unsupported redaction field: %s", field)
+		}
+		switch action {
+		case RedactDrop, RedactMask, RedactHash:
+		default:
+			return fmt.Errorf("unsupported redaction action for %s: %s", field, action)
+		}
+	}
+	return nil
+}
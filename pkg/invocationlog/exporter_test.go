@@ -0,0 +1,39 @@
+package invocationlog
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memorySink keeps written records
+type memorySink struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (s *memorySink) Write(record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func TestExporter_RecordAfterClose(t *testing.T) {
+	sink := &memorySink{}
+	exporter := NewExporterWithSink(sink, DefaultConfig(), zap.NewNop())
+
+	exporter.Record(Record{Tool: "before"})
+	require.NoError(t, exporter.Close())
+
+	assert.NotPanics(t, func() { exporter.Record(Record{Tool: "after"}) })
+	require.NoError(t, exporter.Close(), "closing twice is harmless")
+	require.Len(t, sink.records, 1)
+	assert.Equal(t, "before", sink.records[0].Tool)
+	assert.Zero(t, exporter.Dropped(), "records after close are not counted as queue overflow")
+}
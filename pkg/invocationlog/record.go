@@ -0,0 +1,61 @@
+// Package invocationlog exports a schema-stable stream of tool invocation
+// records for SIEM ingestion. The stream is independent from application logs
+// so its format only changes together with SchemaVersion.
+package invocationlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// SchemaVersion identifies the layout of Record. Fields are only ever added;
+// renames or removals bump the major version.
+const SchemaVersion = "1.0"
+
+// Outcome describes how an invocation ended
+type Outcome string
+
+const (
+	OutcomeSuccess  Outcome = "success"
+	OutcomeFailure  Outcome = "failure"
+	OutcomeRejected Outcome = "rejected" // Refused before execution (limits, policy)
+)
+
+// Actor identifies who invoked a tool
+type Actor struct {
+	Type    string `json:"type"`              // agent, http
+	ID      string `json:"id"`                // Session ID for agents, client address for HTTP
+	Name    string `json:"name,omitempty"`    // Agent name when known
+	Address string `json:"address,omitempty"` // Remote network address when known
+}
+
+// Record is a single tool invocation entry in the exported stream
+type Record struct {
+	SchemaVersion string    `json:"schema_version"`
+	Timestamp     time.Time `json:"timestamp"`
+	InvocationID  string    `json:"invocation_id,omitempty"`
+	Actor         Actor     `json:"actor"`
+	Tool          string    `json:"tool"`
+	ToolSource    string    `json:"tool_source,omitempty"`
+	Transport     string    `json:"transport"`
+	ParamsHash    string    `json:"params_hash,omitempty"` // sha256 of the canonical JSON parameters
+	Outcome       Outcome   `json:"outcome"`
+	Error         string    `json:"error,omitempty"`
+	LatencyMs     int64     `json:"latency_ms"`
+}
+
+// HashParams returns the sha256 digest of the JSON encoding of params.
+// Map keys are encoded in sorted order, so equal parameters hash equally.
+func HashParams(params any) string {
+	if params == nil {
+		return ""
+	}
+	data, err := json.Marshal(params)
+	if err != nil || string(data) == "null" {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,197 @@
+package invocationlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileSink appends records to a file as JSON Lines
+type FileSink struct {
+	file    *os.File
+	encoder *json.Encoder
+	mu      sync.Mutex
+}
+
+// NewFileSink opens (or creates) the JSON Lines file at path
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return &FileSink{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Write appends one record as a JSON line
+func (f *FileSink) Write(record Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.encoder.Encode(record)
+}
+
+// Close closes the underlying file
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+const (
+	// syslogFacilityLocal0 is the facility used for invocation records
+	syslogFacilityLocal0 = 16
+	syslogSeverityWarn   = 4
+	syslogSeverityInfo   = 6
+)
+
+// SyslogSink forwards records as RFC 5424 messages with a JSON payload
+type SyslogSink struct {
+	network  string
+	address  string
+	hostname string
+	conn     net.Conn
+	mu       sync.Mutex
+}
+
+// NewSyslogSink connects to a syslog collector
+func NewSyslogSink(network, address string) (*SyslogSink, error) {
+	if address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+	if network == "" {
+		network = "udp"
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+
+	sink := &SyslogSink{
+		network:  network,
+		address:  address,
+		hostname: hostname,
+	}
+	if err := sink.connect(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Write sends one record, reconnecting once if the connection was lost
+func (s *SyslogSink) Write(record Record) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	severity := syslogSeverityInfo
+	if record.Outcome != OutcomeSuccess {
+		severity = syslogSeverityWarn
+	}
+	message := fmt.Sprintf("<%d>1 %s %s aionmcp - invocation - %s\n",
+		syslogFacilityLocal0*8+severity,
+		record.Timestamp.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if _, err := s.conn.Write([]byte(message)); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	if err := s.connect(); err != nil {
+		return err
+	}
+	_, err = s.conn.Write([]byte(message))
+	return err
+}
+
+// Close closes the syslog connection
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *SyslogSink) connect() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s: %w", s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// HTTPSink posts each record as a JSON document to a collector endpoint
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates a sink posting records to url
+func NewHTTPSink(url string, timeout time.Duration) (*HTTPSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("HTTP forwarder URL is required")
+	}
+	if timeout <= 0 {
+		timeout = DefaultConfig().HTTPTimeout
+	}
+
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Write posts one record
+func (h *HTTPSink) Write(record Record) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to forward record: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close releases idle connections
+func (h *HTTPSink) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
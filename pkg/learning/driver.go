@@ -0,0 +1,87 @@
+package learning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// StorageMemory names the in-memory driver this package registers. The
+// boltdb and sqlite drivers are registered by the server's learning engine.
+const StorageMemory = "memory"
+
+// StorageConfig is passed to a storage driver when opening it
+type StorageConfig struct {
+	Path   string      // Database file or data source name; empty selects the driver's default
+	Logger *zap.Logger // Nil discards the driver's logs
+}
+
+// StorageDriver opens a storage backend. Backends register a driver under a
+// name with RegisterStorage, and servers pick one by name from their
+// configuration, the way database/sql picks drivers.
+type StorageDriver interface {
+	Open(config StorageConfig) (Storage, error)
+}
+
+// StorageDriverFunc adapts a function to a StorageDriver
+type StorageDriverFunc func(config StorageConfig) (Storage, error)
+
+// Open calls f
+func (f StorageDriverFunc) Open(config StorageConfig) (Storage, error) {
+	return f(config)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]StorageDriver)
+)
+
+// RegisterStorage makes a storage driver available by name, usually from
+// the init function of the package implementing the backend. It panics if a
+// driver is registered twice under the same name or is nil.
+func RegisterStorage(name string, driver StorageDriver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if driver == nil {
+		panic("learning: RegisterStorage driver is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("learning: RegisterStorage called twice for driver " + name)
+	}
+	drivers[name] = driver
+}
+
+// StorageDrivers returns the names of the registered drivers, sorted
+func StorageDrivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenStorage opens storage with the named driver
+func OpenStorage(name string, config StorageConfig) (Storage, error) {
+	driversMu.RLock()
+	driver, exists := drivers[name]
+	driversMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unsupported storage type %q; registered: %s", name, strings.Join(StorageDrivers(), ", "))
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
+	return driver.Open(config)
+}
+
+func init() {
+	RegisterStorage(StorageMemory, StorageDriverFunc(func(StorageConfig) (Storage, error) {
+		return NewMemoryStorage(), nil
+	}))
+}
@@ -0,0 +1,87 @@
+package learning
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// latencyBucketsPerDoubling sets the resolution of latency histograms. Bucket
+// bounds grow by a factor of 2^(1/8), so an estimated percentile is at most
+// about 9% above the true value.
+const latencyBucketsPerDoubling = 8
+
+// LatencyHistogram counts latencies in logarithmic buckets, so percentiles
+// can be estimated without keeping every latency
+type LatencyHistogram struct {
+	buckets map[int]int64
+	count   int64
+	min     time.Duration
+	max     time.Duration
+}
+
+// NewLatencyHistogram creates an empty histogram
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{buckets: make(map[int]int64)}
+}
+
+// Record counts a latency
+func (h *LatencyHistogram) Record(latency time.Duration) {
+	if h.count == 0 || latency < h.min {
+		h.min = latency
+	}
+	if h.count == 0 || latency > h.max {
+		h.max = latency
+	}
+	h.count++
+	h.buckets[latencyBucket(latency)]++
+}
+
+// Percentile estimates the latency below which the fraction q of latencies
+// fall, as the upper bound of its bucket limited to the observed range
+func (h *LatencyHistogram) Percentile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	indexes := make([]int, 0, len(h.buckets))
+	for index := range h.buckets {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	rank := int64(math.Ceil(q * float64(h.count)))
+	var seen int64
+	for _, index := range indexes {
+		seen += h.buckets[index]
+		if seen >= rank {
+			bound := latencyBucketBound(index)
+			if bound < h.min {
+				return h.min
+			}
+			if bound > h.max {
+				return h.max
+			}
+			return bound
+		}
+	}
+	return h.max
+}
+
+// Percentiles estimates the p50, p95 and p99 latencies
+func (h *LatencyHistogram) Percentiles() (p50, p95, p99 time.Duration) {
+	return h.Percentile(0.50), h.Percentile(0.95), h.Percentile(0.99)
+}
+
+// latencyBucket returns the bucket holding latency. Bucket 0 holds latencies
+// up to a microsecond.
+func latencyBucket(latency time.Duration) int {
+	if latency <= time.Microsecond {
+		return 0
+	}
+	return int(math.Ceil(math.Log2(float64(latency)/float64(time.Microsecond)) * latencyBucketsPerDoubling))
+}
+
+// latencyBucketBound returns the largest latency in a bucket
+func latencyBucketBound(index int) time.Duration {
+	return time.Duration(float64(time.Microsecond) * math.Exp2(float64(index)/latencyBucketsPerDoubling))
+}
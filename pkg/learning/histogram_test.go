@@ -0,0 +1,44 @@
+package learning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	histogram := NewLatencyHistogram()
+	assert.Zero(t, histogram.Percentile(0.5))
+
+	for i := 1; i <= 100; i++ {
+		histogram.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	// Estimates are the upper bound of a bucket, at most about 9% high
+	p50, p95, p99 := histogram.Percentiles()
+	for _, estimate := range []struct {
+		got, want time.Duration
+	}{{p50, 50 * time.Millisecond}, {p95, 95 * time.Millisecond}, {p99, 99 * time.Millisecond}} {
+		assert.GreaterOrEqual(t, estimate.got, estimate.want)
+		assert.LessOrEqual(t, float64(estimate.got), float64(estimate.want)*1.1)
+	}
+
+	// Estimates stay inside the observed range
+	assert.Equal(t, 100*time.Millisecond, histogram.Percentile(1))
+}
+
+func TestLatencyHistogramSingleValue(t *testing.T) {
+	histogram := NewLatencyHistogram()
+	histogram.Record(42 * time.Millisecond)
+	p50, p95, p99 := histogram.Percentiles()
+	assert.Equal(t, 42*time.Millisecond, p50)
+	assert.Equal(t, 42*time.Millisecond, p95)
+	assert.Equal(t, 42*time.Millisecond, p99)
+
+	// Sub-microsecond latencies share the first bucket
+	histogram = NewLatencyHistogram()
+	histogram.Record(0)
+	histogram.Record(500 * time.Nanosecond)
+	assert.Equal(t, 500*time.Nanosecond, histogram.Percentile(1))
+}
@@ -0,0 +1,428 @@
+package learning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+)
+
+// MemoryStorage implements Storage in memory for tests and ephemeral
+// servers. Records are copied through JSON on the way in and out, so they
+// come back exactly as the boltdb driver would return them.
+type MemoryStorage struct {
+	mu         sync.RWMutex
+	executions map[string]ExecutionRecord // By boltdb driver key, timestamp_id
+	order      []string                   // Execution keys, oldest first
+	patterns   map[string]Pattern
+	insights   map[string]Insight
+	aggregates map[string]HourlyAggregate // By tool and hour, as the boltdb driver keys them
+	clock      clock.Clock                // Judges retention
+	closed     bool
+}
+
+// errStopIteration ends an iteration early
+var errStopIteration = errors.New("stop iteration")
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		executions: make(map[string]ExecutionRecord),
+		patterns:   make(map[string]Pattern),
+		insights:   make(map[string]Insight),
+		aggregates: make(map[string]HourlyAggregate),
+		clock:      clock.Real{},
+	}
+}
+
+// SetClock replaces the clock retention is judged by
+func (s *MemoryStorage) SetClock(c clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = c
+}
+
+// executionKey orders records the way the boltdb driver keys them
+func executionKey(record ExecutionRecord) string {
+	return fmt.Sprintf("%d_%s", record.Timestamp.Unix(), record.ID)
+}
+
+// roundTrip copies a value through JSON
+func roundTrip[T any](value T) (T, error) {
+	var copied T
+	data, err := json.Marshal(value)
+	if err != nil {
+		return copied, err
+	}
+	err = json.Unmarshal(data, &copied)
+	return copied, err
+}
+
+// sortedKeys returns the keys of a map in order, as a BoltDB cursor visits them
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *MemoryStorage) checkOpen() error {
+	if s.closed {
+		return fmt.Errorf("memory storage is closed")
+	}
+	return nil
+}
+
+// StoreExecution stores an execution record
+func (s *MemoryStorage) StoreExecution(ctx context.Context, record ExecutionRecord) error {
+	copied, err := roundTrip(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	key := executionKey(record)
+	if _, exists := s.executions[key]; !exists {
+		i := sort.SearchStrings(s.order, key)
+		s.order = append(s.order, "")
+		copy(s.order[i+1:], s.order[i:])
+		s.order[i] = key
+	}
+	s.executions[key] = copied
+	return nil
+}
+
+// GetExecution retrieves an execution record by ID
+func (s *MemoryStorage) GetExecution(ctx context.Context, id string) (ExecutionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.order {
+		if record := s.executions[key]; record.ID == id {
+			return roundTrip(record)
+		}
+	}
+	return ExecutionRecord{}, fmt.Errorf("execution record not found: %s", id)
+}
+
+// GetExecutionsByTool retrieves execution records for a specific tool, newest first
+func (s *MemoryStorage) GetExecutionsByTool(ctx context.Context, toolName string, limit int) ([]ExecutionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []ExecutionRecord
+	for i := len(s.order) - 1; i >= 0 && len(records) < limit; i-- {
+		if record := s.executions[s.order[i]]; record.ToolName == toolName {
+			copied, err := roundTrip(record)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, copied)
+		}
+	}
+	return records, nil
+}
+
+// GetExecutionsByTimeRange retrieves execution records within a time range
+func (s *MemoryStorage) GetExecutionsByTimeRange(ctx context.Context, start, end time.Time, limit int) ([]ExecutionRecord, error) {
+	var records []ExecutionRecord
+	if limit <= 0 {
+		return records, nil
+	}
+	err := s.IterateExecutions(ctx, start, end, func(record ExecutionRecord) error {
+		records = append(records, record)
+		if len(records) >= limit {
+			return errStopIteration
+		}
+		return nil
+	})
+	if errors.Is(err, errStopIteration) {
+		err = nil
+	}
+	return records, err
+}
+
+// IterateExecutions calls fn for every execution record in the time range,
+// oldest first, stopping at the first error fn returns
+func (s *MemoryStorage) IterateExecutions(ctx context.Context, start, end time.Time, fn func(ExecutionRecord) error) error {
+	s.mu.RLock()
+	records := make([]ExecutionRecord, 0, len(s.order))
+	for _, key := range s.order {
+		record := s.executions[key]
+		if !record.Timestamp.Before(start) && !record.Timestamp.After(end) {
+			records = append(records, record)
+		}
+	}
+	s.mu.RUnlock()
+
+	// fn runs without the lock so it may store records itself
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		copied, err := roundTrip(record)
+		if err != nil {
+			return err
+		}
+		if err := fn(copied); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetExecutionStats calculates and returns learning statistics
+func (s *MemoryStorage) GetExecutionStats(ctx context.Context) (LearningStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accumulator := NewStatsAccumulator()
+	for _, key := range s.order {
+		accumulator.Add(s.executions[key])
+	}
+	return accumulator.Stats(), nil
+}
+
+// StorePattern stores a pattern
+func (s *MemoryStorage) StorePattern(ctx context.Context, pattern Pattern) error {
+	copied, err := roundTrip(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pattern: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	s.patterns[pattern.ID] = copied
+	return nil
+}
+
+// GetPattern retrieves a pattern by ID
+func (s *MemoryStorage) GetPattern(ctx context.Context, id string) (Pattern, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pattern, exists := s.patterns[id]
+	if !exists {
+		return Pattern{}, fmt.Errorf("pattern not found: %s", id)
+	}
+	return roundTrip(pattern)
+}
+
+// GetPatterns retrieves patterns by type, or all patterns for an empty type
+func (s *MemoryStorage) GetPatterns(ctx context.Context, patternType PatternType, limit int) ([]Pattern, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var patterns []Pattern
+	for _, id := range sortedKeys(s.patterns) {
+		if len(patterns) >= limit {
+			break
+		}
+		if pattern := s.patterns[id]; patternType == "" || pattern.Type == patternType {
+			copied, err := roundTrip(pattern)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, copied)
+		}
+	}
+	return patterns, nil
+}
+
+// UpdatePattern updates an existing pattern
+func (s *MemoryStorage) UpdatePattern(ctx context.Context, pattern Pattern) error {
+	return s.StorePattern(ctx, pattern)
+}
+
+// DeletePattern deletes a pattern
+func (s *MemoryStorage) DeletePattern(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.patterns, id)
+	return nil
+}
+
+// StoreInsight stores an insight
+func (s *MemoryStorage) StoreInsight(ctx context.Context, insight Insight) error {
+	copied, err := roundTrip(insight)
+	if err != nil {
+		return fmt.Errorf("failed to marshal insight: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	s.insights[insight.ID] = copied
+	return nil
+}
+
+// GetInsight retrieves an insight by ID
+func (s *MemoryStorage) GetInsight(ctx context.Context, id string) (Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	insight, exists := s.insights[id]
+	if !exists {
+		return Insight{}, fmt.Errorf("insight not found: %s", id)
+	}
+	return roundTrip(insight)
+}
+
+// GetInsights retrieves insights by type, or all insights for an empty type
+func (s *MemoryStorage) GetInsights(ctx context.Context, insightType InsightType, limit int) ([]Insight, error) {
+	return s.filterInsights(limit, func(insight Insight) bool {
+		return insightType == "" || insight.Type == insightType
+	})
+}
+
+// GetInsightsByPriority retrieves insights by priority, or all insights for an empty priority
+func (s *MemoryStorage) GetInsightsByPriority(ctx context.Context, priority Priority, limit int) ([]Insight, error) {
+	return s.filterInsights(limit, func(insight Insight) bool {
+		return priority == "" || insight.Priority == priority
+	})
+}
+
+// filterInsights returns up to limit matching insights in ID order
+func (s *MemoryStorage) filterInsights(limit int, match func(Insight) bool) ([]Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var insights []Insight
+	for _, id := range sortedKeys(s.insights) {
+		if len(insights) >= limit {
+			break
+		}
+		if insight := s.insights[id]; match(insight) {
+			copied, err := roundTrip(insight)
+			if err != nil {
+				return nil, err
+			}
+			insights = append(insights, copied)
+		}
+	}
+	return insights, nil
+}
+
+// UpdateInsight updates an existing insight
+func (s *MemoryStorage) UpdateInsight(ctx context.Context, insight Insight) error {
+	return s.StoreInsight(ctx, insight)
+}
+
+// DeleteInsight deletes an insight
+func (s *MemoryStorage) DeleteInsight(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.insights, id)
+	return nil
+}
+
+// Cleanup removes execution records older than the retention period
+func (s *MemoryStorage) Cleanup(ctx context.Context, retentionPeriod time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.clock.Now().Add(-retentionPeriod)
+
+	kept := s.order[:0]
+	for _, key := range s.order {
+		if s.executions[key].Timestamp.Before(cutoff) {
+			delete(s.executions, key)
+		} else {
+			kept = append(kept, key)
+		}
+	}
+	s.order = kept
+	return nil
+}
+
+// Downsample folds executions older than the retention period into hourly
+// aggregates and removes them
+func (s *MemoryStorage) Downsample(ctx context.Context, retentionPeriod time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return 0, err
+	}
+
+	cutoff := s.clock.Now().Add(-retentionPeriod)
+	aggregates := make(HourlyAggregates)
+
+	kept := s.order[:0]
+	for _, key := range s.order {
+		if record := s.executions[key]; record.Timestamp.Before(cutoff) {
+			aggregates.Add(record)
+			delete(s.executions, key)
+		} else {
+			kept = append(kept, key)
+		}
+	}
+	removed := len(s.order) - len(kept)
+	s.order = kept
+
+	for key, aggregate := range aggregates {
+		if stored, exists := s.aggregates[key]; exists {
+			aggregate.Merge(stored)
+		}
+		copied, err := roundTrip(*aggregate)
+		if err != nil {
+			return 0, err
+		}
+		s.aggregates[key] = copied
+	}
+	return removed, nil
+}
+
+// GetHourlyAggregates returns the hourly aggregates of hours starting in the
+// time range, oldest first. An empty tool name selects every tool.
+func (s *MemoryStorage) GetHourlyAggregates(ctx context.Context, toolName string, start, end time.Time) ([]HourlyAggregate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	aggregates := []HourlyAggregate{}
+	for _, aggregate := range s.aggregates {
+		if toolName != "" && aggregate.ToolName != toolName {
+			continue
+		}
+		if aggregate.Hour.Before(start) || aggregate.Hour.After(end) {
+			continue
+		}
+		copied, err := roundTrip(aggregate)
+		if err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, copied)
+	}
+	SortAggregates(aggregates)
+	return aggregates, nil
+}
+
+// Close discards the stored data
+func (s *MemoryStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.executions = make(map[string]ExecutionRecord)
+	s.order = nil
+	s.patterns = make(map[string]Pattern)
+	s.insights = make(map[string]Insight)
+	s.aggregates = make(map[string]HourlyAggregate)
+	return nil
+}
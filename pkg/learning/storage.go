@@ -0,0 +1,225 @@
+package learning
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Storage defines the interface for storing and retrieving learning data
+type Storage interface {
+	// Execution records
+	StoreExecution(ctx context.Context, record ExecutionRecord) error
+	GetExecution(ctx context.Context, id string) (ExecutionRecord, error)
+	GetExecutionsByTool(ctx context.Context, toolName string, limit int) ([]ExecutionRecord, error)
+	GetExecutionsByTimeRange(ctx context.Context, start, end time.Time, limit int) ([]ExecutionRecord, error)
+	IterateExecutions(ctx context.Context, start, end time.Time, fn func(ExecutionRecord) error) error
+	GetExecutionStats(ctx context.Context) (LearningStats, error)
+
+	// Patterns
+	StorePattern(ctx context.Context, pattern Pattern) error
+	GetPattern(ctx context.Context, id string) (Pattern, error)
+	GetPatterns(ctx context.Context, patternType PatternType, limit int) ([]Pattern, error)
+	UpdatePattern(ctx context.Context, pattern Pattern) error
+	DeletePattern(ctx context.Context, id string) error
+
+	// Insights
+	StoreInsight(ctx context.Context, insight Insight) error
+	GetInsight(ctx context.Context, id string) (Insight, error)
+	GetInsights(ctx context.Context, insightType InsightType, limit int) ([]Insight, error)
+	GetInsightsByPriority(ctx context.Context, priority Priority, limit int) ([]Insight, error)
+	UpdateInsight(ctx context.Context, insight Insight) error
+	DeleteInsight(ctx context.Context, id string) error
+
+	// Maintenance
+	Cleanup(ctx context.Context, retentionPeriod time.Duration) error
+	Close() error
+}
+
+// WritableChecker is implemented by storage backends that can verify they
+// still accept writes, for readiness probes
+type WritableChecker interface {
+	CheckWritable(ctx context.Context) error
+}
+
+// Downsampler is implemented by storage backends that can replace old
+// executions with hourly aggregates instead of deleting them outright
+type Downsampler interface {
+	// Downsample folds executions older than the retention period into the
+	// hourly aggregates of their tools and removes them, returning how many
+	// executions it removed
+	Downsample(ctx context.Context, retentionPeriod time.Duration) (int, error)
+	// GetHourlyAggregates returns the aggregates of hours starting in the
+	// time range, oldest first. An empty tool name selects every tool.
+	GetHourlyAggregates(ctx context.Context, toolName string, start, end time.Time) ([]HourlyAggregate, error)
+}
+
+// Compactor is implemented by storage backends that can reclaim the space
+// of removed records
+type Compactor interface {
+	Compact(ctx context.Context) error
+}
+
+// HourlyAggregate summarizes one tool's executions in one hour. Retention
+// keeps it after the executions themselves are removed.
+type HourlyAggregate struct {
+	ToolName       string         `json:"tool_name"`
+	Hour           time.Time      `json:"hour"` // Start of the hour, UTC
+	Executions     int64          `json:"executions"`
+	Successes      int64          `json:"successes"`
+	TotalDuration  time.Duration  `json:"total_duration"`
+	ErrorBreakdown map[string]int `json:"error_breakdown,omitempty"`
+}
+
+// Add counts an execution in the aggregate
+func (a *HourlyAggregate) Add(record ExecutionRecord) {
+	a.Executions++
+	a.TotalDuration += record.Duration
+	if record.Success {
+		a.Successes++
+		return
+	}
+	if a.ErrorBreakdown == nil {
+		a.ErrorBreakdown = make(map[string]int)
+	}
+	a.ErrorBreakdown[record.ErrorType]++
+}
+
+// Merge adds the counts of an aggregate of the same tool and hour, such as
+// one kept by an earlier retention run
+func (a *HourlyAggregate) Merge(other HourlyAggregate) {
+	a.Executions += other.Executions
+	a.Successes += other.Successes
+	a.TotalDuration += other.TotalDuration
+	for errorType, count := range other.ErrorBreakdown {
+		if a.ErrorBreakdown == nil {
+			a.ErrorBreakdown = make(map[string]int)
+		}
+		a.ErrorBreakdown[errorType] += count
+	}
+}
+
+// HourlyAggregates groups execution records by tool and hour
+type HourlyAggregates map[string]*HourlyAggregate
+
+// Add counts an execution in the aggregate of its tool and hour
+func (h HourlyAggregates) Add(record ExecutionRecord) {
+	hour := record.Timestamp.UTC().Truncate(time.Hour)
+	key := AggregateKey(record.ToolName, hour)
+	aggregate, exists := h[key]
+	if !exists {
+		aggregate = &HourlyAggregate{ToolName: record.ToolName, Hour: hour}
+		h[key] = aggregate
+	}
+	aggregate.Add(record)
+}
+
+// AggregateKey orders aggregates by tool, then hour
+func AggregateKey(toolName string, hour time.Time) string {
+	return fmt.Sprintf("%s\x00%d", toolName, hour.Unix())
+}
+
+// SortAggregates orders aggregates by hour, then tool
+func SortAggregates(aggregates []HourlyAggregate) {
+	sort.Slice(aggregates, func(i, j int) bool {
+		if !aggregates[i].Hour.Equal(aggregates[j].Hour) {
+			return aggregates[i].Hour.Before(aggregates[j].Hour)
+		}
+		return aggregates[i].ToolName < aggregates[j].ToolName
+	})
+}
+
+// StatsAccumulator computes LearningStats from execution records, so storage
+// backends only need to feed it their records
+type StatsAccumulator struct {
+	result        LearningStats
+	toolStats     map[string]*ToolStat
+	latencies     *LatencyHistogram
+	toolLatencies map[string]*LatencyHistogram
+	totalDuration time.Duration
+	successCount  int64
+}
+
+// NewStatsAccumulator creates an accumulator with no records
+func NewStatsAccumulator() *StatsAccumulator {
+	return &StatsAccumulator{
+		result: LearningStats{
+			ErrorBreakdown: make(map[string]int),
+			TopTools:       []ToolStat{},
+			LastUpdated:    time.Now().UTC(),
+		},
+		toolStats:     make(map[string]*ToolStat),
+		latencies:     NewLatencyHistogram(),
+		toolLatencies: make(map[string]*LatencyHistogram),
+	}
+}
+
+// Add counts an execution record
+func (a *StatsAccumulator) Add(record ExecutionRecord) {
+	a.result.TotalExecutions++
+	a.totalDuration += record.Duration
+	a.latencies.Record(record.Duration)
+
+	if record.Success {
+		a.successCount++
+	} else {
+		a.result.ErrorBreakdown[record.ErrorType]++
+	}
+
+	toolStat, exists := a.toolStats[record.ToolName]
+	if !exists {
+		toolStat = &ToolStat{
+			Name:      record.ToolName,
+			FirstUsed: record.Timestamp,
+			LastUsed:  record.Timestamp,
+		}
+		a.toolStats[record.ToolName] = toolStat
+		a.toolLatencies[record.ToolName] = NewLatencyHistogram()
+	}
+	a.toolLatencies[record.ToolName].Record(record.Duration)
+	toolStat.ExecutionCount++
+	if record.Success {
+		toolStat.SuccessCount++
+	} else {
+		toolStat.FailureCount++
+	}
+	// Calculate success rate from counts to avoid floating-point errors
+	toolStat.SuccessRate = float64(toolStat.SuccessCount) / float64(toolStat.ExecutionCount)
+	// Use incremental mean formula for numerically stable running average
+	prevAvg := float64(toolStat.AverageLatency.Nanoseconds())
+	newVal := float64(record.Duration.Nanoseconds())
+	toolStat.AverageLatency = time.Duration(prevAvg + (newVal-prevAvg)/float64(toolStat.ExecutionCount))
+	// Track first and last used times
+	if record.Timestamp.Before(toolStat.FirstUsed) {
+		toolStat.FirstUsed = record.Timestamp
+	}
+	if record.Timestamp.After(toolStat.LastUsed) {
+		toolStat.LastUsed = record.Timestamp
+	}
+}
+
+// Stats returns the overall statistics with the ten most used tools
+func (a *StatsAccumulator) Stats() LearningStats {
+	stats := a.result
+	if stats.TotalExecutions > 0 {
+		stats.SuccessRate = float64(a.successCount) / float64(stats.TotalExecutions)
+		stats.AverageLatency = a.totalDuration / time.Duration(stats.TotalExecutions)
+		stats.P50Latency, stats.P95Latency, stats.P99Latency = a.latencies.Percentiles()
+	}
+
+	// Convert tool stats to slice and sort by execution count
+	for name, stat := range a.toolStats {
+		stat.P50Latency, stat.P95Latency, stat.P99Latency = a.toolLatencies[name].Percentiles()
+		stats.TopTools = append(stats.TopTools, *stat)
+	}
+	sort.Slice(stats.TopTools, func(i, j int) bool {
+		return stats.TopTools[i].ExecutionCount > stats.TopTools[j].ExecutionCount
+	})
+
+	// Limit to top 10 tools
+	if len(stats.TopTools) > 10 {
+		stats.TopTools = stats.TopTools[:10]
+	}
+	return stats
+}
@@ -0,0 +1,180 @@
+// Package learning defines how self-learning data is stored: the execution
+// records, patterns and insights the learning engine keeps, the Storage
+// interface backends implement, and the registry servers open backends
+// from by name. A package outside this module can add a backend by
+// registering a driver from its init function and verify it with
+// tooltest.StorageConformance.
+package learning
+
+import (
+	"time"
+)
+
+// ExecutionRecord represents a single tool execution with metadata
+type ExecutionRecord struct {
+	ID         string                 `json:"id"`
+	ToolName   string                 `json:"tool_name"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Duration   time.Duration          `json:"duration"`
+	Success    bool                   `json:"success"`
+	Input      interface{}            `json:"input,omitempty"`
+	Output     interface{}            `json:"output,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	ErrorType  string                 `json:"error_type,omitempty"` // Use string for consistency with public API
+	Context    map[string]interface{} `json:"context,omitempty"`
+	RetryCount int                    `json:"retry_count"`
+	SourceType string                 `json:"source_type"` // openapi, graphql, asyncapi, builtin
+}
+
+// ErrorType represents the classification of errors
+type ErrorType string
+
+const (
+	ErrorTypeNetwork       ErrorType = "network"
+	ErrorTypeValidation    ErrorType = "validation"
+	ErrorTypeConfiguration ErrorType = "configuration"
+	ErrorTypePerformance   ErrorType = "performance"
+	ErrorTypeLogic         ErrorType = "logic"
+	ErrorTypeUnknown       ErrorType = "unknown"
+)
+
+// Pattern represents a detected pattern in execution data
+type Pattern struct {
+	ID          string            `json:"id"`
+	Type        PatternType       `json:"type"`
+	Description string            `json:"description"`
+	Frequency   int               `json:"frequency"`
+	Confidence  float64           `json:"confidence"`
+	FirstSeen   time.Time         `json:"first_seen"`
+	LastSeen    time.Time         `json:"last_seen"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// PatternType represents the type of pattern detected
+type PatternType string
+
+const (
+	PatternTypeError       PatternType = "error"
+	PatternTypePerformance PatternType = "performance"
+	PatternTypeUsage       PatternType = "usage"
+	PatternTypeSuccess     PatternType = "success"
+)
+
+// Insight represents a learning insight or suggestion
+type Insight struct {
+	ID             string            `json:"id"`
+	Type           InsightType       `json:"type"`
+	Priority       Priority          `json:"priority"`
+	Title          string            `json:"title"`
+	Description    string            `json:"description"`
+	Suggestion     string            `json:"suggestion"`
+	Evidence       []string          `json:"evidence"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Metadata       map[string]string `json:"metadata"`
+	ActiveSessions int               `json:"active_sessions,omitempty"` // Sessions recently invoking the insight's tool; set when listing
+
+	Remediations      []Remediation     `json:"remediations,omitempty"`
+	RemediationStatus RemediationStatus `json:"remediation_status,omitempty"`
+	RemediationNote   string            `json:"remediation_note,omitempty"` // Outcome of applying, or why they were rejected
+	RemediatedAt      *time.Time        `json:"remediated_at,omitempty"`
+}
+
+// InsightType represents the type of insight
+type InsightType string
+
+const (
+	InsightTypeOptimization  InsightType = "optimization"
+	InsightTypeConfiguration InsightType = "configuration"
+	InsightTypeReliability   InsightType = "reliability"
+	InsightTypePerformance   InsightType = "performance"
+	InsightTypeUsage         InsightType = "usage"
+)
+
+// Priority represents the priority level of an insight
+type Priority string
+
+const (
+	PriorityLow      Priority = "low"
+	PriorityMedium   Priority = "medium"
+	PriorityHigh     Priority = "high"
+	PriorityCritical Priority = "critical"
+)
+
+// priorityWeights rank insight priorities; unknown priorities weigh as low
+var priorityWeights = map[Priority]int{
+	PriorityLow:      1,
+	PriorityMedium:   2,
+	PriorityHigh:     3,
+	PriorityCritical: 4,
+}
+
+// AtLeast reports whether p ranks at or above other
+func (p Priority) AtLeast(other Priority) bool {
+	return p.Weight() >= other.Weight()
+}
+
+// Weight ranks a priority from 1 for low to 4 for critical; unknown
+// priorities weigh as low
+func (p Priority) Weight() int {
+	if weight, ok := priorityWeights[p]; ok {
+		return weight
+	}
+	return priorityWeights[PriorityLow]
+}
+
+// LearningStats represents overall learning statistics
+type LearningStats struct {
+	TotalExecutions int64          `json:"total_executions"`
+	SuccessRate     float64        `json:"success_rate"`
+	AverageLatency  time.Duration  `json:"average_latency"`
+	P50Latency      time.Duration  `json:"p50_latency"`
+	P95Latency      time.Duration  `json:"p95_latency"`
+	P99Latency      time.Duration  `json:"p99_latency"`
+	ErrorBreakdown  map[string]int `json:"error_breakdown"` // Use string for error types
+	TopTools        []ToolStat     `json:"top_tools"`
+	RecentPatterns  []Pattern      `json:"recent_patterns"`
+	ActiveInsights  []Insight      `json:"active_insights"`
+	LastUpdated     time.Time      `json:"last_updated"`
+}
+
+// ToolStat represents statistics for a specific tool
+type ToolStat struct {
+	Name           string        `json:"name"`
+	ExecutionCount int64         `json:"execution_count"`
+	SuccessCount   int64         `json:"success_count"` // Track successes separately
+	FailureCount   int64         `json:"failure_count"` // Track failures separately
+	SuccessRate    float64       `json:"success_rate"`
+	AverageLatency time.Duration `json:"average_latency"`
+	P50Latency     time.Duration `json:"p50_latency"`
+	P95Latency     time.Duration `json:"p95_latency"`
+	P99Latency     time.Duration `json:"p99_latency"`
+	FirstUsed      time.Time     `json:"first_used"`
+	LastUsed       time.Time     `json:"last_used"`
+}
+
+// RemediationAction names a change the server can make on its own to
+// address an insight
+type RemediationAction string
+
+const (
+	RemediationDisableTool     RemediationAction = "disable_tool"     // Take the tool out of rotation
+	RemediationIncreaseTimeout RemediationAction = "increase_timeout" // Multiply the tool's timeout by Factor
+)
+
+// Remediation is a machine-actionable fix suggested by an insight
+type Remediation struct {
+	Action      RemediationAction `json:"action"`
+	Tool        string            `json:"tool"`
+	Factor      float64           `json:"factor,omitempty"` // For increase_timeout
+	Description string            `json:"description"`
+}
+
+// RemediationStatus tracks an insight's remediations through approval
+type RemediationStatus string
+
+const (
+	RemediationPending  RemediationStatus = "pending"  // Waiting for approval
+	RemediationApplied  RemediationStatus = "applied"  // Every remediation was applied
+	RemediationRejected RemediationStatus = "rejected" // An operator declined them
+	RemediationFailed   RemediationStatus = "failed"   // Applying one failed; can be retried
+)
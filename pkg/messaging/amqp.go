@@ -0,0 +1,169 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// amqpAdapter shares one pooled connection per endpoint, opening a channel
+// for each publish and subscription
+type amqpAdapter struct {
+	config      PoolConfig
+	mu          sync.Mutex
+	connections map[string]*amqp.Connection // endpoint key -> connection
+}
+
+func newAMQPAdapter(config PoolConfig) *amqpAdapter {
+	return &amqpAdapter{config: config, connections: make(map[string]*amqp.Connection)}
+}
+
+// channel opens a channel on the pooled connection of an endpoint,
+// connecting first when there is no open connection
+func (a *amqpAdapter) channel(endpoint Endpoint) (*amqp.Channel, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := endpoint.key()
+	connection, ok := a.connections[key]
+	if !ok || connection.IsClosed() {
+		scheme := "amqp"
+		if endpoint.secure() {
+			scheme = "amqps"
+		}
+		address, err := url.Parse(withScheme(endpoint.URL, scheme))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AMQP broker URL %s: %w", endpoint.URL, err)
+		}
+		if endpoint.Username != "" {
+			address.User = url.UserPassword(endpoint.Username, endpoint.Password)
+		}
+		connection, err = amqp.DialConfig(address.String(), amqp.Config{
+			Dial:       amqp.DefaultDial(a.config.ConnectTimeout),
+			Properties: amqp.Table{"connection_name": "aionmcp"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to AMQP broker %s: %w", endpoint.URL, err)
+		}
+		a.connections[key] = connection
+	}
+
+	channel, err := connection.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open AMQP channel: %w", err)
+	}
+	return channel, nil
+}
+
+// Publish waits for the broker to confirm the message
+func (a *amqpAdapter) Publish(ctx context.Context, endpoint Endpoint, destination Destination, message Message) error {
+	channel, err := a.channel(endpoint)
+	if err != nil {
+		return err
+	}
+	defer channel.Close()
+
+	if err := channel.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable AMQP publisher confirms: %w", err)
+	}
+	headers := amqp.Table{}
+	for name, value := range message.Headers {
+		headers[name] = value
+	}
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(ctx, destination.Exchange, destination.Name, false, false, amqp.Publishing{
+		ContentType: message.ContentType,
+		Headers:     headers,
+		Body:        message.Payload,
+		Timestamp:   message.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to AMQP exchange %q with routing key %s: %w", destination.Exchange, destination.Name, err)
+	}
+	acknowledged, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("broker did not confirm AMQP message: %w", err)
+	}
+	if !acknowledged {
+		return fmt.Errorf("broker rejected AMQP message for exchange %q with routing key %s", destination.Exchange, destination.Name)
+	}
+	return nil
+}
+
+// Subscribe consumes from the destination's queue, or from a temporary
+// queue bound to its exchange with its routing key. Without either, the
+// queue named after the routing key is consumed. Messages are acknowledged
+// as they are received, including those the filter rejects; messages the
+// broker delivered ahead are requeued when the channel closes.
+func (a *amqpAdapter) Subscribe(ctx context.Context, endpoint Endpoint, destination Destination, options SubscribeOptions) ([]Message, error) {
+	channel, err := a.channel(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer channel.Close()
+
+	if options.MaxMessages > 0 {
+		if err := channel.Qos(options.MaxMessages, 0, false); err != nil {
+			return nil, fmt.Errorf("failed to limit AMQP prefetch: %w", err)
+		}
+	}
+
+	queue := destination.Queue
+	switch {
+	case queue != "":
+	case destination.Exchange != "":
+		declared, err := channel.QueueDeclare("", false, true, true, false, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to declare temporary AMQP queue: %w", err)
+		}
+		if err := channel.QueueBind(declared.Name, destination.Name, destination.Exchange, false, nil); err != nil {
+			return nil, fmt.Errorf("failed to bind AMQP queue to exchange %s: %w", destination.Exchange, err)
+		}
+		queue = declared.Name
+	default:
+		queue = destination.Name
+	}
+
+	deliveries, err := channel.ConsumeWithContext(ctx, queue, "", false, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume from AMQP queue %s: %w", queue, err)
+	}
+
+	return collect(ctx, options, deliveries, amqpMessage), nil
+}
+
+// amqpMessage acknowledges a delivery and converts it to a message
+func amqpMessage(delivery amqp.Delivery) Message {
+	delivery.Ack(false)
+	message := Message{
+		Channel:     delivery.RoutingKey,
+		Payload:     delivery.Body,
+		ContentType: delivery.ContentType,
+		Timestamp:   delivery.Timestamp,
+	}
+	if message.Timestamp.IsZero() {
+		message.Timestamp = time.Now()
+	}
+	if len(delivery.Headers) > 0 {
+		message.Headers = make(map[string]string, len(delivery.Headers))
+		for name, value := range delivery.Headers {
+			message.Headers[name] = fmt.Sprint(value)
+		}
+	}
+	return message
+}
+
+func (a *amqpAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, connection := range a.connections {
+		if !connection.IsClosed() {
+			connection.Close()
+		}
+		delete(a.connections, key)
+	}
+	return nil
+}
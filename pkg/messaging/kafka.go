@@ -0,0 +1,201 @@
+package messaging
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// DefaultKafkaGroup is the consumer group subscriptions join when the
+// endpoint names none
+const DefaultKafkaGroup = "aionmcp"
+
+// kafkaAdapter pools a writer per endpoint and a consumer group reader per
+// endpoint and topic. Readers keep their group's committed offsets, so each
+// subscription returns the messages published since the previous one. A
+// topic's first subscription starts at the newest message.
+type kafkaAdapter struct {
+	config  PoolConfig
+	mu      sync.Mutex
+	writers map[string]*kafka.Writer // endpoint key -> writer
+	readers map[string]*kafka.Reader // endpoint key, group and topic -> reader
+}
+
+func newKafkaAdapter(config PoolConfig) *kafkaAdapter {
+	return &kafkaAdapter{
+		config:  config,
+		writers: make(map[string]*kafka.Writer),
+		readers: make(map[string]*kafka.Reader),
+	}
+}
+
+// kafkaBrokers returns the comma-separated broker addresses of an endpoint
+func kafkaBrokers(endpoint Endpoint) []string {
+	var brokers []string
+	for _, address := range strings.Split(endpoint.URL, ",") {
+		address = strings.TrimSpace(address)
+		if _, host, found := strings.Cut(address, "://"); found {
+			address = host
+		}
+		if address = strings.TrimSuffix(address, "/"); address != "" {
+			brokers = append(brokers, address)
+		}
+	}
+	return brokers
+}
+
+// kafkaSecurity returns the SASL mechanism and TLS configuration of an endpoint
+func kafkaSecurity(endpoint Endpoint) (sasl.Mechanism, *tls.Config) {
+	var mechanism sasl.Mechanism
+	if endpoint.Username != "" {
+		mechanism = plain.Mechanism{Username: endpoint.Username, Password: endpoint.Password}
+	}
+	var tlsConfig *tls.Config
+	if endpoint.secure() {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return mechanism, tlsConfig
+}
+
+// writer returns the pooled writer of an endpoint
+func (a *kafkaAdapter) writer(endpoint Endpoint) *kafka.Writer {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := endpoint.key()
+	if writer, ok := a.writers[key]; ok {
+		return writer
+	}
+	mechanism, tlsConfig := kafkaSecurity(endpoint)
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(kafkaBrokers(endpoint)...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 10 * time.Millisecond,
+		Transport: &kafka.Transport{
+			DialTimeout: a.config.ConnectTimeout,
+			SASL:        mechanism,
+			TLS:         tlsConfig,
+		},
+	}
+	a.writers[key] = writer
+	return writer
+}
+
+// reader returns the pooled reader of an endpoint's group for a topic
+func (a *kafkaAdapter) reader(endpoint Endpoint, topic string) (string, *kafka.Reader) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	group := endpoint.Group
+	if group == "" {
+		group = DefaultKafkaGroup
+	}
+	key := strings.Join([]string{endpoint.key(), group, topic}, "\x00")
+	if reader, ok := a.readers[key]; ok {
+		return key, reader
+	}
+	mechanism, tlsConfig := kafkaSecurity(endpoint)
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     kafkaBrokers(endpoint),
+		GroupID:     group,
+		Topic:       topic,
+		StartOffset: kafka.LastOffset,
+		MaxWait:     500 * time.Millisecond,
+		Dialer: &kafka.Dialer{
+			Timeout:       a.config.ConnectTimeout,
+			DualStack:     true,
+			SASLMechanism: mechanism,
+			TLS:           tlsConfig,
+		},
+	})
+	a.readers[key] = reader
+	return key, reader
+}
+
+// discardReader closes a reader that failed, so the next subscription
+// reconnects
+func (a *kafkaAdapter) discardReader(key string, reader *kafka.Reader) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.readers[key] == reader {
+		delete(a.readers, key)
+	}
+	reader.Close()
+}
+
+func (a *kafkaAdapter) Publish(ctx context.Context, endpoint Endpoint, destination Destination, message Message) error {
+	record := kafka.Message{
+		Topic: destination.Name,
+		Value: message.Payload,
+		Time:  message.Timestamp,
+	}
+	if message.Key != "" {
+		record.Key = []byte(message.Key)
+	}
+	for name, value := range message.Headers {
+		record.Headers = append(record.Headers, kafka.Header{Key: name, Value: []byte(value)})
+	}
+	if err := a.writer(endpoint).WriteMessages(ctx, record); err != nil {
+		return fmt.Errorf("failed to publish to Kafka topic %s: %w", destination.Name, err)
+	}
+	return nil
+}
+
+// Subscribe commits every message it reads, including those the filter rejects
+func (a *kafkaAdapter) Subscribe(ctx context.Context, endpoint Endpoint, destination Destination, options SubscribeOptions) ([]Message, error) {
+	key, reader := a.reader(endpoint, destination.Name)
+
+	waitCtx, cancel := context.WithTimeout(ctx, options.Wait)
+	defer cancel()
+
+	received := []Message{}
+	for !options.full(received) {
+		record, err := reader.FetchMessage(waitCtx)
+		if err != nil {
+			if waitCtx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+				return received, nil
+			}
+			a.discardReader(key, reader)
+			return received, fmt.Errorf("failed to consume from Kafka topic %s: %w", destination.Name, err)
+		}
+		if err := reader.CommitMessages(ctx, record); err != nil {
+			return received, fmt.Errorf("failed to commit Kafka offset: %w", err)
+		}
+
+		message := Message{Channel: record.Topic, Key: string(record.Key), Payload: record.Value, Timestamp: record.Time}
+		if len(record.Headers) > 0 {
+			message.Headers = make(map[string]string, len(record.Headers))
+			for _, header := range record.Headers {
+				message.Headers[header.Key] = string(header.Value)
+			}
+		}
+		if options.accepts(message) {
+			received = append(received, message)
+		}
+	}
+	return received, nil
+}
+
+func (a *kafkaAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var errs []error
+	for key, writer := range a.writers {
+		errs = append(errs, writer.Close())
+		delete(a.writers, key)
+	}
+	for key, reader := range a.readers {
+		errs = append(errs, reader.Close())
+		delete(a.readers, key)
+	}
+	return errors.Join(errs...)
+}
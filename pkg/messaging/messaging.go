@@ -0,0 +1,243 @@
+// Package messaging connects AsyncAPI tools to the brokers their specs
+// declare. Adapters for MQTT, AMQP 0-9-1, Kafka and WebSocket publish and
+// consume messages over connections pooled per endpoint and credentials.
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Protocols served by the built-in adapters
+const (
+	ProtocolMQTT      = "mqtt"
+	ProtocolAMQP      = "amqp"
+	ProtocolKafka     = "kafka"
+	ProtocolWebSocket = "ws"
+)
+
+// DefaultConnectTimeout bounds connecting to a broker when none is configured
+const DefaultConnectTimeout = 10 * time.Second
+
+// Endpoint is a broker declared by an AsyncAPI server, with the credentials
+// to connect as
+type Endpoint struct {
+	Protocol string // As declared by the server, e.g. mqtt, amqps or kafka-secure
+	URL      string
+	Username string
+	Password string
+	Token    string // Bearer token, sent by WebSocket clients
+	ClientID string // MQTT client ID; generated when empty
+	Group    string // Kafka consumer group; DefaultKafkaGroup when empty
+}
+
+// key identifies the pooled connections of an endpoint
+func (e Endpoint) key() string {
+	return strings.Join([]string{e.Protocol, e.URL, e.Username, e.Password, e.Token, e.ClientID}, "\x00")
+}
+
+// secure reports whether the endpoint's protocol asks for TLS
+func (e Endpoint) secure() bool {
+	switch strings.ToLower(strings.TrimSpace(e.Protocol)) {
+	case "mqtts", "secure-mqtt", "amqps", "kafka-secure", "wss":
+		return true
+	}
+	return false
+}
+
+// Destination addresses a channel on a broker
+type Destination struct {
+	Name     string // MQTT topic, AMQP routing key, Kafka topic or WebSocket path
+	Exchange string // AMQP exchange; empty publishes to the default exchange
+	Queue    string // AMQP queue to consume from; empty binds a temporary queue to the exchange
+}
+
+// Message is a message published to or received from a broker
+type Message struct {
+	Channel     string // Topic, routing key or path it was received on
+	Key         string // Kafka message key
+	Payload     []byte
+	ContentType string            // Sent as the AMQP content type
+	Headers     map[string]string // Carried by AMQP and Kafka; MQTT 3.1.1 and WebSocket have no headers
+	Timestamp   time.Time
+}
+
+// SubscribeOptions bound how long a subscription waits and what it returns
+type SubscribeOptions struct {
+	Wait        time.Duration      // How long to wait for messages
+	MaxMessages int                // Return once this many arrived; zero waits the full time
+	Filter      func(Message) bool // Nil accepts every message
+}
+
+// accepts reports whether a received message is returned
+func (o SubscribeOptions) accepts(message Message) bool {
+	return o.Filter == nil || o.Filter(message)
+}
+
+// full reports whether enough messages arrived
+func (o SubscribeOptions) full(messages []Message) bool {
+	return o.MaxMessages > 0 && len(messages) >= o.MaxMessages
+}
+
+// Adapter publishes and consumes messages over one protocol
+type Adapter interface {
+	Publish(ctx context.Context, endpoint Endpoint, destination Destination, message Message) error
+	Subscribe(ctx context.Context, endpoint Endpoint, destination Destination, options SubscribeOptions) ([]Message, error)
+	Close() error // Closes the adapter's pooled connections
+}
+
+// PoolConfig configures a pool of broker connections
+type PoolConfig struct {
+	ConnectTimeout time.Duration // Zero selects DefaultConnectTimeout
+}
+
+// Pool routes publish and subscribe calls to the adapter for the endpoint's
+// protocol. Adapters connect lazily and keep their connections until the
+// pool is closed, replacing connections the broker closed.
+type Pool struct {
+	mu       sync.RWMutex
+	adapters map[string]Adapter // protocol -> adapter
+}
+
+// NewPool creates a pool with the built-in adapters
+func NewPool(config PoolConfig) *Pool {
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = DefaultConnectTimeout
+	}
+	return &Pool{adapters: map[string]Adapter{
+		ProtocolMQTT:      newMQTTAdapter(config),
+		ProtocolAMQP:      newAMQPAdapter(config),
+		ProtocolKafka:     newKafkaAdapter(config),
+		ProtocolWebSocket: newWebSocketAdapter(config),
+	}}
+}
+
+// SetAdapter serves a protocol with a custom adapter
+func (p *Pool) SetAdapter(protocol string, adapter Adapter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.adapters[protocol] = adapter
+}
+
+// Protocols lists the protocols the pool serves
+func (p *Pool) Protocols() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.protocolsLocked()
+}
+
+// NormalizeProtocol maps the protocol names AsyncAPI servers declare, such as
+// mqtts, amqps, kafka-secure or wss, to the adapter serving them
+func NormalizeProtocol(protocol string) string {
+	switch strings.ToLower(strings.TrimSpace(protocol)) {
+	case "mqtt", "mqtts", "secure-mqtt":
+		return ProtocolMQTT
+	case "amqp", "amqps":
+		return ProtocolAMQP
+	case "kafka", "kafka-secure":
+		return ProtocolKafka
+	case "ws", "wss", "websocket", "websockets":
+		return ProtocolWebSocket
+	}
+	return strings.ToLower(strings.TrimSpace(protocol))
+}
+
+// adapter returns the adapter for an endpoint's protocol
+func (p *Pool) adapter(endpoint Endpoint) (Adapter, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if adapter, ok := p.adapters[NormalizeProtocol(endpoint.Protocol)]; ok {
+		return adapter, nil
+	}
+	return nil, fmt.Errorf("unsupported protocol %q: expected one of %s", endpoint.Protocol, strings.Join(p.protocolsLocked(), ", "))
+}
+
+// protocolsLocked lists the served protocols; the caller holds the lock
+func (p *Pool) protocolsLocked() []string {
+	protocols := make([]string, 0, len(p.adapters))
+	for protocol := range p.adapters {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+	return protocols
+}
+
+// Publish sends a message to a destination
+func (p *Pool) Publish(ctx context.Context, endpoint Endpoint, destination Destination, message Message) error {
+	adapter, err := p.adapter(endpoint)
+	if err != nil {
+		return err
+	}
+	if message.Timestamp.IsZero() {
+		message.Timestamp = time.Now()
+	}
+	return adapter.Publish(ctx, endpoint, destination, message)
+}
+
+// Subscribe consumes messages from a destination until enough arrived, the
+// wait elapses or the context ends. Running out of time is not an error; the
+// messages received so far are returned.
+func (p *Pool) Subscribe(ctx context.Context, endpoint Endpoint, destination Destination, options SubscribeOptions) ([]Message, error) {
+	adapter, err := p.adapter(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return adapter.Subscribe(ctx, endpoint, destination, options)
+}
+
+// Close closes the pooled connections of every adapter
+func (p *Pool) Close() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var errs []error
+	for protocol, adapter := range p.adapters {
+		if err := adapter.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", protocol, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// collect gathers accepted messages from a channel until enough arrived, the
+// wait elapses, the context ends or the channel closes. Deliveries are
+// converted as they are received, so a conversion that acknowledges them
+// only acknowledges what the subscription consumed.
+func collect[T any](ctx context.Context, options SubscribeOptions, deliveries <-chan T, convert func(T) Message) []Message {
+	received := []Message{}
+	timer := time.NewTimer(options.Wait)
+	defer timer.Stop()
+	for !options.full(received) {
+		select {
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return received
+			}
+			if message := convert(delivery); options.accepts(message) {
+				received = append(received, message)
+			}
+		case <-timer.C:
+			return received
+		case <-ctx.Done():
+			return received
+		}
+	}
+	return received
+}
+
+// passThrough hands messages that need no conversion to collect
+func passThrough(message Message) Message {
+	return message
+}
+
+// withScheme prefixes a broker address without one with a default scheme
+func withScheme(address, scheme string) string {
+	if strings.Contains(address, "://") {
+		return address
+	}
+	return scheme + "://" + address
+}
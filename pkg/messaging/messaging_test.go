@@ -0,0 +1,190 @@
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// relayServer relays WebSocket messages to the other connections on a path
+// and counts the connections it accepted
+func relayServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var (
+		mu          sync.Mutex
+		connections = map[string]map[*websocket.Conn]bool{}
+		accepted    atomic.Int32
+		upgrader    websocket.Upgrader
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		accepted.Add(1)
+		mu.Lock()
+		if connections[r.URL.Path] == nil {
+			connections[r.URL.Path] = map[*websocket.Conn]bool{}
+		}
+		connections[r.URL.Path][conn] = true
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			delete(connections[r.URL.Path], conn)
+			mu.Unlock()
+			conn.Close()
+		}()
+		for {
+			messageType, payload, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			for other := range connections[r.URL.Path] {
+				if other != conn {
+					other.WriteMessage(messageType, payload)
+				}
+			}
+			mu.Unlock()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &accepted
+}
+
+func TestWebSocketPublishSubscribe(t *testing.T) {
+	server, accepted := relayServer(t)
+	pool := NewPool(PoolConfig{ConnectTimeout: time.Second})
+	defer pool.Close()
+
+	endpoint := Endpoint{Protocol: "ws", URL: strings.TrimPrefix(server.URL, "http://") + "/events", Token: "secret"}
+	destination := Destination{Name: "user/register"}
+
+	// Subscribe in the background, publishing until the subscriber connected
+	type subscription struct {
+		messages []Message
+		err      error
+	}
+	done := make(chan subscription, 1)
+	go func() {
+		messages, err := pool.Subscribe(context.Background(), endpoint, destination, SubscribeOptions{
+			Wait:        5 * time.Second,
+			MaxMessages: 1,
+			Filter:      func(message Message) bool { return strings.Contains(string(message.Payload), "u1") },
+		})
+		done <- subscription{messages, err}
+	}()
+
+	ctx := context.Background()
+	var result subscription
+	for received := false; !received; {
+		select {
+		case result = <-done:
+			received = true
+		case <-time.After(50 * time.Millisecond):
+			require.NoError(t, pool.Publish(ctx, endpoint, destination, Message{Payload: []byte(`{"userId":"u0"}`)}))
+			require.NoError(t, pool.Publish(ctx, endpoint, destination, Message{Payload: []byte(`{"userId":"u1"}`)}))
+		}
+	}
+	require.NoError(t, result.err)
+	require.Len(t, result.messages, 1)
+	assert.JSONEq(t, `{"userId":"u1"}`, string(result.messages[0].Payload))
+	assert.Equal(t, "user/register", result.messages[0].Channel)
+
+	// Publishing reuses one pooled connection; the subscriber had its own
+	assert.Equal(t, int32(2), accepted.Load())
+
+	// Without the token the server refuses the connection
+	endpoint.Token = ""
+	err := pool.Publish(ctx, endpoint, destination, Message{Payload: []byte("x")})
+	assert.ErrorContains(t, err, "status 401")
+}
+
+func TestWebSocketSubscribeTimesOut(t *testing.T) {
+	server, _ := relayServer(t)
+	pool := NewPool(PoolConfig{})
+	defer pool.Close()
+
+	endpoint := Endpoint{Protocol: "wss", URL: "ws://" + strings.TrimPrefix(server.URL, "http://"), Token: "secret"}
+	start := time.Now()
+	messages, err := pool.Subscribe(context.Background(), endpoint, Destination{Name: "quiet"}, SubscribeOptions{Wait: 100 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestNormalizeProtocol(t *testing.T) {
+	cases := map[string]string{
+		"mqtt":         ProtocolMQTT,
+		"secure-mqtt":  ProtocolMQTT,
+		"AMQPS":        ProtocolAMQP,
+		"kafka-secure": ProtocolKafka,
+		"wss":          ProtocolWebSocket,
+		"websockets":   ProtocolWebSocket,
+		"http":         "http",
+	}
+	for protocol, expected := range cases {
+		assert.Equal(t, expected, NormalizeProtocol(protocol), protocol)
+	}
+	assert.True(t, Endpoint{Protocol: "kafka-secure"}.secure())
+	assert.False(t, Endpoint{Protocol: "kafka"}.secure())
+}
+
+func TestKafkaBrokers(t *testing.T) {
+	brokers := kafkaBrokers(Endpoint{URL: "kafka://broker-1:9092, broker-2:9092/,"})
+	assert.Equal(t, []string{"broker-1:9092", "broker-2:9092"}, brokers)
+}
+
+// recordingAdapter records what is published and replays it to subscribers
+type recordingAdapter struct {
+	published []Message
+}
+
+func (a *recordingAdapter) Publish(_ context.Context, _ Endpoint, _ Destination, message Message) error {
+	a.published = append(a.published, message)
+	return nil
+}
+
+func (a *recordingAdapter) Subscribe(_ context.Context, _ Endpoint, _ Destination, options SubscribeOptions) ([]Message, error) {
+	var messages []Message
+	for _, message := range a.published {
+		if options.accepts(message) && !options.full(messages) {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (a *recordingAdapter) Close() error { return nil }
+
+func TestPoolAdapters(t *testing.T) {
+	pool := NewPool(PoolConfig{})
+	assert.Equal(t, []string{ProtocolAMQP, ProtocolKafka, ProtocolMQTT, ProtocolWebSocket}, pool.Protocols())
+
+	_, err := pool.Subscribe(context.Background(), Endpoint{Protocol: "stomp"}, Destination{Name: "queue"}, SubscribeOptions{})
+	assert.ErrorContains(t, err, `unsupported protocol "stomp"`)
+
+	// Custom adapters serve further protocols
+	adapter := &recordingAdapter{}
+	pool.SetAdapter("stomp", adapter)
+	require.NoError(t, pool.Publish(context.Background(), Endpoint{Protocol: "stomp"}, Destination{Name: "queue"}, Message{Payload: []byte("hello")}))
+	require.Len(t, adapter.published, 1)
+	assert.False(t, adapter.published[0].Timestamp.IsZero())
+
+	messages, err := pool.Subscribe(context.Background(), Endpoint{Protocol: "stomp"}, Destination{Name: "queue"}, SubscribeOptions{MaxMessages: 5})
+	require.NoError(t, err)
+	assert.Len(t, messages, 1)
+	require.NoError(t, pool.Close())
+}
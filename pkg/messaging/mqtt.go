@@ -0,0 +1,148 @@
+package messaging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// mqttQoS is the quality of service messages are published and subscribed
+// with: at least once, so publishing waits for the broker to acknowledge
+const mqttQoS = 1
+
+// mqttAdapter publishes over one pooled client per endpoint. Each
+// subscription connects its own client, so concurrent subscriptions to a
+// topic do not steal each other's deliveries.
+type mqttAdapter struct {
+	config  PoolConfig
+	mu      sync.Mutex
+	clients map[string]mqtt.Client // endpoint key -> publishing client
+}
+
+func newMQTTAdapter(config PoolConfig) *mqttAdapter {
+	return &mqttAdapter{config: config, clients: make(map[string]mqtt.Client)}
+}
+
+// connect connects a client to an endpoint's broker
+func (a *mqttAdapter) connect(endpoint Endpoint, clientID string) (mqtt.Client, error) {
+	scheme := "tcp"
+	if endpoint.secure() {
+		scheme = "ssl"
+	}
+	options := mqtt.NewClientOptions().
+		AddBroker(withScheme(endpoint.URL, scheme)).
+		SetClientID(clientID).
+		SetUsername(endpoint.Username).
+		SetPassword(endpoint.Password).
+		SetConnectTimeout(a.config.ConnectTimeout).
+		SetCleanSession(true).
+		SetAutoReconnect(true)
+
+	client := mqtt.NewClient(options)
+	token := client.Connect()
+	if !token.WaitTimeout(a.config.ConnectTimeout) {
+		client.Disconnect(0)
+		return nil, fmt.Errorf("timed out connecting to MQTT broker %s", endpoint.URL)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker %s: %w", endpoint.URL, err)
+	}
+	return client, nil
+}
+
+// publisher returns the pooled client of an endpoint, connecting it first
+func (a *mqttAdapter) publisher(endpoint Endpoint) (mqtt.Client, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := endpoint.key()
+	if client, ok := a.clients[key]; ok {
+		if client.IsConnectionOpen() {
+			return client, nil
+		}
+		client.Disconnect(0)
+		delete(a.clients, key)
+	}
+
+	clientID := endpoint.ClientID
+	if clientID == "" {
+		clientID = mqttClientID()
+	}
+	client, err := a.connect(endpoint, clientID)
+	if err != nil {
+		return nil, err
+	}
+	a.clients[key] = client
+	return client, nil
+}
+
+func (a *mqttAdapter) Publish(ctx context.Context, endpoint Endpoint, destination Destination, message Message) error {
+	client, err := a.publisher(endpoint)
+	if err != nil {
+		return err
+	}
+	if err := waitMQTT(ctx, client.Publish(destination.Name, mqttQoS, false, message.Payload)); err != nil {
+		return fmt.Errorf("failed to publish to MQTT topic %s: %w", destination.Name, err)
+	}
+	return nil
+}
+
+func (a *mqttAdapter) Subscribe(ctx context.Context, endpoint Endpoint, destination Destination, options SubscribeOptions) ([]Message, error) {
+	// A subscriber's client ID must differ from the publisher's, or the
+	// broker would disconnect one of them
+	clientID := mqttClientID()
+	if endpoint.ClientID != "" {
+		clientID = endpoint.ClientID + "-" + clientID
+	}
+	client, err := a.connect(endpoint, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Disconnect(250)
+
+	messages := make(chan Message, 64)
+	handler := func(_ mqtt.Client, delivery mqtt.Message) {
+		message := Message{Channel: delivery.Topic(), Payload: delivery.Payload(), Timestamp: time.Now()}
+		select {
+		case messages <- message:
+		default:
+		}
+	}
+	if err := waitMQTT(ctx, client.Subscribe(destination.Name, mqttQoS, handler)); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to MQTT topic %s: %w", destination.Name, err)
+	}
+	return collect(ctx, options, messages, passThrough), nil
+}
+
+func (a *mqttAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, client := range a.clients {
+		client.Disconnect(250)
+		delete(a.clients, key)
+	}
+	return nil
+}
+
+// waitMQTT waits for an MQTT operation to complete or the context to end
+func waitMQTT(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return errors.Join(errors.New("broker did not acknowledge in time"), ctx.Err())
+	}
+}
+
+// mqttClientID generates a client ID unique to this connection
+func mqttClientID() string {
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	return "aionmcp-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,178 @@
+package messaging
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+// webSocketAdapter publishes over one pooled connection per endpoint and
+// channel path. Each subscription opens its own connection and receives
+// what the server sends on it.
+type webSocketAdapter struct {
+	config      PoolConfig
+	dialer      *websocket.Dialer
+	mu          sync.Mutex
+	connections map[string]*webSocketConnection // endpoint key and URL -> publishing connection
+}
+
+// webSocketConnection serializes the writes to a pooled connection
+type webSocketConnection struct {
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	closed chan struct{} // Closed when the connection fails
+}
+
+func newWebSocketAdapter(config PoolConfig) *webSocketAdapter {
+	return &webSocketAdapter{
+		config:      config,
+		dialer:      &websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: config.ConnectTimeout},
+		connections: make(map[string]*webSocketConnection),
+	}
+}
+
+// webSocketURL appends the channel path to the server URL
+func webSocketURL(endpoint Endpoint, destination Destination) string {
+	scheme := "ws"
+	if endpoint.secure() {
+		scheme = "wss"
+	}
+	address := strings.TrimSuffix(withScheme(endpoint.URL, scheme), "/")
+	if path := strings.TrimPrefix(destination.Name, "/"); path != "" {
+		address += "/" + path
+	}
+	return address
+}
+
+// dial opens a connection authenticated with the endpoint's token or
+// username and password
+func (a *webSocketAdapter) dial(ctx context.Context, address string, endpoint Endpoint) (*websocket.Conn, error) {
+	header := http.Header{}
+	switch {
+	case endpoint.Token != "":
+		header.Set("Authorization", "Bearer "+endpoint.Token)
+	case endpoint.Username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(endpoint.Username + ":" + endpoint.Password))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+	conn, response, err := a.dialer.DialContext(ctx, address, header)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to connect to WebSocket %s: %w (status %d)", address, err, response.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to connect to WebSocket %s: %w", address, err)
+	}
+	return conn, nil
+}
+
+// publisher returns the pooled connection for a URL, dialing it first
+func (a *webSocketAdapter) publisher(ctx context.Context, address string, endpoint Endpoint) (*webSocketConnection, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := endpoint.key() + "\x00" + address
+	if connection, ok := a.connections[key]; ok {
+		select {
+		case <-connection.closed:
+			delete(a.connections, key)
+		default:
+			return connection, nil
+		}
+	}
+
+	conn, err := a.dial(ctx, address, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	connection := &webSocketConnection{conn: conn, closed: make(chan struct{})}
+	// Read what the server sends so control frames are answered and a
+	// closed connection is noticed
+	go func() {
+		defer close(connection.closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}()
+	a.connections[key] = connection
+	return connection, nil
+}
+
+// Publish sends the payload as a text message, or as a binary message when
+// it is not UTF-8. A pooled connection the server closed is redialed once.
+func (a *webSocketAdapter) Publish(ctx context.Context, endpoint Endpoint, destination Destination, message Message) error {
+	address := webSocketURL(endpoint, destination)
+	messageType := websocket.TextMessage
+	if !utf8.Valid(message.Payload) {
+		messageType = websocket.BinaryMessage
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(a.config.ConnectTimeout)
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var connection *webSocketConnection
+		connection, err = a.publisher(ctx, address, endpoint)
+		if err != nil {
+			return err
+		}
+		connection.mu.Lock()
+		connection.conn.SetWriteDeadline(deadline)
+		err = connection.conn.WriteMessage(messageType, message.Payload)
+		connection.mu.Unlock()
+		if err == nil {
+			return nil
+		}
+		connection.conn.Close()
+		<-connection.closed
+	}
+	return fmt.Errorf("failed to publish to WebSocket %s: %w", address, err)
+}
+
+func (a *webSocketAdapter) Subscribe(ctx context.Context, endpoint Endpoint, destination Destination, options SubscribeOptions) ([]Message, error) {
+	conn, err := a.dial(ctx, webSocketURL(endpoint, destination), endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	messages := make(chan Message)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(messages)
+		for {
+			_, payload, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			select {
+			case messages <- Message{Channel: destination.Name, Payload: payload, Timestamp: time.Now()}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return collect(ctx, options, messages, passThrough), nil
+}
+
+func (a *webSocketAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, connection := range a.connections {
+		connection.conn.Close()
+		delete(a.connections, key)
+	}
+	return nil
+}
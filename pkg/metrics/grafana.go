@@ -0,0 +1,253 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// DashboardConfig customises the generated Grafana dashboard
+type DashboardConfig struct {
+	Title              string  `json:"title"`
+	UID                string  `json:"uid"`
+	AvailabilityTarget float64 `json:"availability_target"` // Share of invocations that must succeed, e.g. 0.99
+	BudgetWindow       string  `json:"budget_window"`       // Prometheus range the error budget spans, e.g. 30d
+}
+
+// DefaultDashboardConfig returns the default dashboard configuration
+func DefaultDashboardConfig() DashboardConfig {
+	return DashboardConfig{
+		Title:              "AionMCP",
+		UID:                "aionmcp",
+		AvailabilityTarget: 0.99,
+		BudgetWindow:       "30d",
+	}
+}
+
+// grafanaSchemaVersion is the dashboard JSON model version generated
+const grafanaSchemaVersion = 39
+
+// Dashboard is a Grafana dashboard JSON model
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Editable      bool       `json:"editable"`
+	Refresh       string     `json:"refresh"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+// TimeRange is the default time range of a dashboard
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Templating holds the dashboard variables
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a dashboard variable
+type Variable struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+// Panel is a dashboard panel or row
+type Panel struct {
+	ID          int          `json:"id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	GridPos     GridPos      `json:"gridPos"`
+	Datasource  *Datasource  `json:"datasource,omitempty"`
+	Targets     []Target     `json:"targets,omitempty"`
+	FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+}
+
+// GridPos places a panel on the 24 column dashboard grid
+type GridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Datasource refers to the Prometheus data source panels query
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Target is a PromQL query of a panel
+type Target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+// FieldConfig sets how panel values are displayed
+type FieldConfig struct {
+	Defaults FieldDefaults `json:"defaults"`
+}
+
+// FieldDefaults sets the unit of panel values
+type FieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
+
+// datasource refers panels to the data source chosen in the dashboard variable
+var datasource = &Datasource{Type: "prometheus", UID: "${datasource}"}
+
+// NewDashboard builds a dashboard covering invocation rates, latency
+// percentiles, the error budget, agent sessions and event streams, and
+// importer health. Every query is written against the metric Definitions the
+// server exports.
+func NewDashboard(config DashboardConfig) Dashboard {
+	defaults := DefaultDashboardConfig()
+	if config.Title == "" {
+		config.Title = defaults.Title
+	}
+	if config.UID == "" {
+		config.UID = defaults.UID
+	}
+	if config.AvailabilityTarget <= 0 || config.AvailabilityTarget >= 1 {
+		config.AvailabilityTarget = defaults.AvailabilityTarget
+	}
+	if config.BudgetWindow == "" {
+		config.BudgetWindow = defaults.BudgetWindow
+	}
+
+	invocations := ToolInvocations.Name
+	errors := fmt.Sprintf(`%s{outcome=%q}`, ToolInvocations.Name, OutcomeError)
+	buckets := ToolInvocationDuration.Name + "_bucket"
+	allowed := strconv.FormatFloat(1-config.AvailabilityTarget, 'g', 6, 64)
+	errorRatio := func(window string) string {
+		return fmt.Sprintf("sum(rate(%s[%s])) / sum(rate(%s[%s]))", errors, window, invocations, window)
+	}
+	percentile := func(quantile, by string) string {
+		return fmt.Sprintf("histogram_quantile(%s, sum by (%s) (rate(%s[$__rate_interval])))", quantile, by, buckets)
+	}
+
+	layout := &dashboardLayout{}
+	layout.row("Invocations")
+	layout.panel("timeseries", "Invocation rate", "Tool invocations per second", "reqps",
+		Target{Expr: fmt.Sprintf("sum by (tool) (rate(%s[$__rate_interval]))", invocations), LegendFormat: "{{tool}}"})
+	layout.panel("timeseries", "Error rate", "Failed tool invocations per second", "reqps",
+		Target{Expr: fmt.Sprintf("sum by (tool) (rate(%s[$__rate_interval]))", errors), LegendFormat: "{{tool}}"})
+
+	layout.row("Latency")
+	layout.panel("timeseries", "Latency percentiles", "Invocation latency across all tools", "s",
+		Target{Expr: percentile("0.5", "le"), LegendFormat: "p50"},
+		Target{Expr: percentile("0.95", "le"), LegendFormat: "p95"},
+		Target{Expr: percentile("0.99", "le"), LegendFormat: "p99"})
+	layout.panel("timeseries", "p95 latency by tool", "", "s",
+		Target{Expr: percentile("0.95", "le, tool"), LegendFormat: "{{tool}}"})
+
+	layout.row("Error budget")
+	layout.panel("stat", "Error budget remaining",
+		fmt.Sprintf("Share of the %s error budget left at a %g%% availability target", config.BudgetWindow, config.AvailabilityTarget*100), "percentunit",
+		Target{Expr: fmt.Sprintf("1 - (sum(increase(%s[%s])) / sum(increase(%s[%s]))) / %s",
+			errors, config.BudgetWindow, invocations, config.BudgetWindow, allowed)})
+	layout.panel("timeseries", "Error budget burn rate", "How many times faster than sustainable the budget is spent", "",
+		Target{Expr: fmt.Sprintf("(%s) / %s", errorRatio("1h"), allowed), LegendFormat: "1h"},
+		Target{Expr: fmt.Sprintf("(%s) / %s", errorRatio("6h"), allowed), LegendFormat: "6h"})
+
+	layout.row("Sessions")
+	layout.panel("timeseries", "Agent sessions", "Sessions by reported status", "short",
+		Target{Expr: fmt.Sprintf("sum by (status) (%s)", AgentSessions.Name), LegendFormat: "{{status}}"})
+	layout.panel("timeseries", "Registered tools", "Tools by source", "short",
+		Target{Expr: fmt.Sprintf("sum by (source) (%s)", RegisteredTools.Name), LegendFormat: "{{source}}"})
+	layout.panel("timeseries", "Event streams", "Open agent event streams and events waiting in their buffers", "short",
+		Target{Expr: fmt.Sprintf("sum(%s)", AgentEventStreams.Name), LegendFormat: "streams"},
+		Target{Expr: fmt.Sprintf("sum(%s)", AgentEventsBuffered.Name), LegendFormat: "buffered events"})
+	layout.panel("timeseries", "Dropped events and reaped streams", "Events per second dropped by full buffers, and dead streams closed", "short",
+		Target{Expr: fmt.Sprintf("sum(rate(%s[$__rate_interval]))", AgentEventsDropped.Name), LegendFormat: "dropped events"},
+		Target{Expr: fmt.Sprintf("sum(rate(%s[$__rate_interval]))", AgentEventStreamsReaped.Name), LegendFormat: "reaped streams"})
+
+	layout.row("Importer health")
+	layout.panel("timeseries", "Spec sources", "Imported spec sources by type", "short",
+		Target{Expr: fmt.Sprintf("sum by (type) (%s)", ImporterSources.Name), LegendFormat: "{{type}}"})
+	layout.panel("timeseries", "Throttled and rejected upstream calls", "Calls per second held back by source limits", "reqps",
+		Target{Expr: fmt.Sprintf("sum by (source) (rate(%s[$__rate_interval]))", ImporterThrottled.Name), LegendFormat: "throttled {{source}}"},
+		Target{Expr: fmt.Sprintf("sum by (source) (rate(%s[$__rate_interval]))", ImporterRejected.Name), LegendFormat: "rejected {{source}}"})
+	layout.panel("stat", "Exhausted upstream quotas", "", "short",
+		Target{Expr: ImporterQuotasExhausted.Name})
+
+	return Dashboard{
+		UID:           config.UID,
+		Title:         config.Title,
+		Tags:          []string{"aionmcp"},
+		Editable:      true,
+		Refresh:       "30s",
+		SchemaVersion: grafanaSchemaVersion,
+		Time:          TimeRange{From: "now-6h", To: "now"},
+		Templating: Templating{List: []Variable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+		}},
+		Panels: layout.panels,
+	}
+}
+
+// DashboardJSON returns the dashboard ready for import into Grafana
+func DashboardJSON(config DashboardConfig) ([]byte, error) {
+	return json.MarshalIndent(NewDashboard(config), "", "  ")
+}
+
+// Panel sizes on the 24 column grid
+const (
+	panelWidth  = 12
+	panelHeight = 8
+	rowHeight   = 1
+)
+
+// dashboardLayout places rows and panels two per line, top to bottom
+type dashboardLayout struct {
+	panels []Panel
+	x, y   int
+}
+
+// row starts a new titled row
+func (l *dashboardLayout) row(title string) {
+	if l.x > 0 {
+		l.x, l.y = 0, l.y+panelHeight
+	}
+	l.panels = append(l.panels, Panel{
+		ID:      len(l.panels) + 1,
+		Type:    "row",
+		Title:   title,
+		GridPos: GridPos{X: 0, Y: l.y, W: 24, H: rowHeight},
+	})
+	l.y += rowHeight
+}
+
+// panel adds a panel querying targets, lettering their reference IDs
+func (l *dashboardLayout) panel(kind, title, description, unit string, targets ...Target) {
+	for i := range targets {
+		targets[i].RefID = string(rune('A' + i))
+	}
+	panel := Panel{
+		ID:          len(l.panels) + 1,
+		Type:        kind,
+		Title:       title,
+		Description: description,
+		GridPos:     GridPos{X: l.x, Y: l.y, W: panelWidth, H: panelHeight},
+		Datasource:  datasource,
+		Targets:     targets,
+	}
+	if unit != "" {
+		panel.FieldConfig = &FieldConfig{Defaults: FieldDefaults{Unit: unit}}
+	}
+	l.panels = append(l.panels, panel)
+
+	l.x += panelWidth
+	if l.x >= 24 {
+		l.x, l.y = 0, l.y+panelHeight
+	}
+}
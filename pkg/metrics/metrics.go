@@ -0,0 +1,210 @@
+// Package metrics exports server metrics in the Prometheus format and
+// generates Grafana dashboards from the same metric definitions, so the
+// dashboards only query metrics the server actually exports.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Type is the Prometheus type of a metric
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+// Definition names and describes an exported metric
+type Definition struct {
+	Name   string
+	Help   string
+	Type   Type
+	Labels []string
+}
+
+// Invocation outcomes of the outcome label
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Metrics exported by the server
+var (
+	ToolInvocations = Definition{
+		Name:   "aionmcp_tool_invocations_total",
+		Help:   "Tool invocations by tool, source and outcome.",
+		Type:   Counter,
+		Labels: []string{"tool", "source", "outcome"},
+	}
+	ToolInvocationDuration = Definition{
+		Name:   "aionmcp_tool_invocation_duration_seconds",
+		Help:   "Tool invocation latency by tool and source.",
+		Type:   Histogram,
+		Labels: []string{"tool", "source"},
+	}
+	AgentSessions = Definition{
+		Name:   "aionmcp_agent_sessions",
+		Help:   "Agent sessions by reported status.",
+		Type:   Gauge,
+		Labels: []string{"status"},
+	}
+	AgentEventStreams = Definition{
+		Name:   "aionmcp_agent_event_streams",
+		Help:   "Open agent event streams by session.",
+		Type:   Gauge,
+		Labels: []string{"session"},
+	}
+	AgentEventsBuffered = Definition{
+		Name:   "aionmcp_agent_events_buffered",
+		Help:   "Events waiting in agent event stream buffers by session.",
+		Type:   Gauge,
+		Labels: []string{"session"},
+	}
+	AgentEventsDropped = Definition{
+		Name:   "aionmcp_agent_events_dropped_total",
+		Help:   "Events dropped because a session's event stream buffer was full.",
+		Type:   Counter,
+		Labels: []string{"session"},
+	}
+	AgentEventStreamsReaped = Definition{
+		Name: "aionmcp_agent_event_streams_reaped_total",
+		Help: "Agent event streams closed by the health check as dead.",
+		Type: Counter,
+	}
+	RegisteredTools = Definition{
+		Name:   "aionmcp_registered_tools",
+		Help:   "Registered tools by source.",
+		Type:   Gauge,
+		Labels: []string{"source"},
+	}
+	ImporterSources = Definition{
+		Name:   "aionmcp_importer_sources",
+		Help:   "Imported spec sources by spec type.",
+		Type:   Gauge,
+		Labels: []string{"type"},
+	}
+	ImporterThrottled = Definition{
+		Name:   "aionmcp_importer_throttled_total",
+		Help:   "Upstream calls that queued for a source's concurrency or rate limit.",
+		Type:   Counter,
+		Labels: []string{"source"},
+	}
+	ImporterRejected = Definition{
+		Name:   "aionmcp_importer_rejected_total",
+		Help:   "Upstream calls that timed out waiting for a source's limits.",
+		Type:   Counter,
+		Labels: []string{"source"},
+	}
+	ImporterQuotasExhausted = Definition{
+		Name: "aionmcp_importer_quotas_exhausted",
+		Help: "Upstream quotas with nothing remaining before their reset.",
+		Type: Gauge,
+	}
+)
+
+// Definitions returns every metric the server exports, besides the standard
+// Go runtime and process metrics
+func Definitions() []Definition {
+	return []Definition{
+		ToolInvocations,
+		ToolInvocationDuration,
+		AgentSessions,
+		AgentEventStreams,
+		AgentEventsBuffered,
+		AgentEventsDropped,
+		AgentEventStreamsReaped,
+		RegisteredTools,
+		ImporterSources,
+		ImporterThrottled,
+		ImporterRejected,
+		ImporterQuotasExhausted,
+	}
+}
+
+// Emit reports one sample of a collected metric with its label values
+type Emit func(value float64, labelValues ...string)
+
+// Metrics is the metric registry of a server. Invocations are observed as
+// they happen; server state is collected when scraped.
+type Metrics struct {
+	registry    *prometheus.Registry
+	invocations *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+}
+
+// New creates a metric registry with the invocation metrics and the Go
+// runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		invocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: ToolInvocations.Name,
+			Help: ToolInvocations.Help,
+		}, ToolInvocations.Labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    ToolInvocationDuration.Name,
+			Help:    ToolInvocationDuration.Help,
+			Buckets: prometheus.DefBuckets,
+		}, ToolInvocationDuration.Labels),
+	}
+	m.registry.MustRegister(
+		m.invocations,
+		m.duration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// ObserveInvocation records a completed tool invocation
+func (m *Metrics) ObserveInvocation(tool, source string, duration time.Duration, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	m.invocations.WithLabelValues(tool, source, outcome).Inc()
+	m.duration.WithLabelValues(tool, source).Observe(duration.Seconds())
+}
+
+// Collect exports a counter or gauge whose samples collect reports on each
+// scrape, e.g. from server state that is already tracked elsewhere
+func (m *Metrics) Collect(definition Definition, collect func(emit Emit)) {
+	valueType := prometheus.GaugeValue
+	if definition.Type == Counter {
+		valueType = prometheus.CounterValue
+	}
+	m.registry.MustRegister(&funcCollector{
+		desc:      prometheus.NewDesc(definition.Name, definition.Help, definition.Labels, nil),
+		valueType: valueType,
+		collect:   collect,
+	})
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// funcCollector reports the samples of a callback on each scrape
+type funcCollector struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	collect   func(emit Emit)
+}
+
+func (c *funcCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *funcCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(func(value float64, labelValues ...string) {
+		ch <- prometheus.MustNewConstMetric(c.desc, c.valueType, value, labelValues...)
+	})
+}
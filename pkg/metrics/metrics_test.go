@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	m := New()
+	m.ObserveInvocation("echo", "builtin", 20*time.Millisecond, nil)
+	m.ObserveInvocation("echo", "builtin", time.Second, errors.New("failed"))
+	for _, definition := range Definitions()[2:] {
+		labelValues := make([]string, len(definition.Labels))
+		for i := range labelValues {
+			labelValues[i] = "x"
+		}
+		m.Collect(definition, func(emit Emit) { emit(3, labelValues...) })
+	}
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	exposition := string(body)
+
+	assert.Contains(t, exposition, `aionmcp_tool_invocations_total{outcome="success",source="builtin",tool="echo"} 1`)
+	assert.Contains(t, exposition, `aionmcp_tool_invocations_total{outcome="error",source="builtin",tool="echo"} 1`)
+	assert.Contains(t, exposition, `aionmcp_tool_invocation_duration_seconds_count{source="builtin",tool="echo"} 2`)
+	assert.Contains(t, exposition, `aionmcp_agent_sessions{status="x"} 3`)
+	assert.Contains(t, exposition, "aionmcp_importer_quotas_exhausted 3")
+	assert.Contains(t, exposition, "# TYPE aionmcp_importer_throttled_total counter")
+	assert.Contains(t, exposition, "go_goroutines")
+	for _, definition := range Definitions() {
+		assert.Contains(t, exposition, "# TYPE "+definition.Name+" "+string(definition.Type))
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	dashboard := NewDashboard(DashboardConfig{AvailabilityTarget: 0.995, BudgetWindow: "7d"})
+	assert.Equal(t, "aionmcp", dashboard.UID)
+
+	// Every query uses exported metrics, and every exported metric is charted
+	defined := make(map[string]bool)
+	for _, definition := range Definitions() {
+		defined[definition.Name] = false
+	}
+	metricName := regexp.MustCompile(`aionmcp_[a-z_]+`)
+	var budget string
+	for _, panel := range dashboard.Panels {
+		if panel.Type == "row" {
+			continue
+		}
+		require.NotEmpty(t, panel.Targets, panel.Title)
+		for _, target := range panel.Targets {
+			for _, name := range metricName.FindAllString(target.Expr, -1) {
+				name = strings.TrimSuffix(name, "_bucket")
+				_, exists := defined[name]
+				assert.True(t, exists, "panel %q queries undefined metric %s", panel.Title, name)
+				defined[name] = true
+			}
+		}
+		if panel.Title == "Error budget remaining" {
+			budget = panel.Targets[0].Expr
+		}
+	}
+	for name, charted := range defined {
+		assert.True(t, charted, "metric %s is not charted", name)
+	}
+	assert.Contains(t, budget, "[7d]")
+	assert.True(t, strings.HasSuffix(budget, "/ 0.005"), budget)
+
+	// The JSON model imports as is
+	data, err := DashboardJSON(DefaultDashboardConfig())
+	require.NoError(t, err)
+	var model map[string]any
+	require.NoError(t, json.Unmarshal(data, &model))
+	assert.Equal(t, "AionMCP", model["title"])
+	assert.EqualValues(t, grafanaSchemaVersion, model["schemaVersion"])
+}
@@ -0,0 +1,193 @@
+// Package notify pushes notifications to operators through a generic
+// webhook, a Slack incoming webhook or email. A Dispatcher drops repeats of
+// a notification and limits how many are sent per hour, so a burst of
+// problems does not flood the channels.
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"go.uber.org/zap"
+)
+
+// Default dispatcher limits
+const (
+	DefaultDedupWindow = time.Hour
+	DefaultQueueSize   = 64
+	DefaultSendTimeout = 10 * time.Second
+)
+
+// Notification is a message for operators
+type Notification struct {
+	Key      string            `json:"key"` // Identifies repeats of the same notification
+	Severity string            `json:"severity"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Sink delivers notifications
+type Sink interface {
+	// Send delivers one notification
+	Send(ctx context.Context, notification Notification) error
+	// String describes the sink for logs and status reports
+	String() string
+}
+
+// DispatcherConfig limits what a dispatcher sends
+type DispatcherConfig struct {
+	DedupWindow time.Duration // Repeats of a key within the window are dropped; zero selects DefaultDedupWindow
+	MaxPerHour  int           // Notifications sent in any hour; zero is unlimited
+	QueueSize   int           // Notifications waiting to be sent; zero selects DefaultQueueSize
+	SendTimeout time.Duration // Per sink and notification; zero selects DefaultSendTimeout
+	Clock       clock.Clock   // Nil uses the real clock
+}
+
+// DispatcherStats counts what happened to notifications
+type DispatcherStats struct {
+	Sent         int64     `json:"sent"`
+	Deduplicated int64     `json:"deduplicated"`
+	RateLimited  int64     `json:"rate_limited"`
+	Dropped      int64     `json:"dropped"` // The queue was full
+	Failed       int64     `json:"failed"`  // Sends that failed, counted per sink
+	LastSent     time.Time `json:"last_sent,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// Dispatcher queues notifications and sends each to every sink
+type Dispatcher struct {
+	sinks  []Sink
+	config DispatcherConfig
+	logger *zap.Logger
+	queue  chan Notification
+	mu     sync.Mutex
+	seen   map[string]time.Time // key -> when it was last accepted
+	sent   []time.Time          // Accepted within the last hour, oldest first
+	stats  DispatcherStats
+}
+
+// NewDispatcher creates a dispatcher. Call Run to start sending.
+func NewDispatcher(sinks []Sink, config DispatcherConfig, logger *zap.Logger) *Dispatcher {
+	if config.DedupWindow <= 0 {
+		config.DedupWindow = DefaultDedupWindow
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = DefaultSendTimeout
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
+	return &Dispatcher{
+		sinks:  sinks,
+		config: config,
+		logger: logger,
+		queue:  make(chan Notification, config.QueueSize),
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Notify queues a notification unless it repeats one accepted within the
+// dedup window, the hourly limit is reached or the queue is full. It never
+// blocks and reports whether the notification was queued.
+func (d *Dispatcher) Notify(notification Notification) bool {
+	now := d.config.Clock.Now()
+	if notification.Time.IsZero() {
+		notification.Time = now
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, accepted := range d.seen {
+		if now.Sub(accepted) >= d.config.DedupWindow {
+			delete(d.seen, key)
+		}
+	}
+	for len(d.sent) > 0 && now.Sub(d.sent[0]) >= time.Hour {
+		d.sent = d.sent[1:]
+	}
+
+	if _, repeated := d.seen[notification.Key]; repeated && notification.Key != "" {
+		d.stats.Deduplicated++
+		return false
+	}
+	if d.config.MaxPerHour > 0 && len(d.sent) >= d.config.MaxPerHour {
+		d.stats.RateLimited++
+		d.logger.Warn("Notification rate limit reached, dropping notification",
+			zap.String("title", notification.Title),
+			zap.Int("max_per_hour", d.config.MaxPerHour))
+		return false
+	}
+	select {
+	case d.queue <- notification:
+	default:
+		d.stats.Dropped++
+		return false
+	}
+	if notification.Key != "" {
+		d.seen[notification.Key] = now
+	}
+	d.sent = append(d.sent, now)
+	return true
+}
+
+// Run sends queued notifications until ctx ends
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-d.queue:
+			d.send(ctx, notification)
+		}
+	}
+}
+
+// send delivers a notification to every sink; a failing sink does not keep
+// it from the others
+func (d *Dispatcher) send(ctx context.Context, notification Notification) {
+	for _, sink := range d.sinks {
+		sendCtx, cancel := context.WithTimeout(ctx, d.config.SendTimeout)
+		err := sink.Send(sendCtx, notification)
+		cancel()
+
+		d.mu.Lock()
+		if err != nil {
+			d.stats.Failed++
+			d.stats.LastError = sink.String() + ": " + err.Error()
+		} else {
+			d.stats.Sent++
+			d.stats.LastSent = d.config.Clock.Now()
+		}
+		d.mu.Unlock()
+
+		if err != nil {
+			d.logger.Error("Failed to send notification",
+				zap.String("sink", sink.String()),
+				zap.String("title", notification.Title),
+				zap.Error(err))
+		}
+	}
+}
+
+// Sinks describes the sinks notifications are sent to
+func (d *Dispatcher) Sinks() []string {
+	sinks := make([]string, len(d.sinks))
+	for i, sink := range d.sinks {
+		sinks[i] = sink.String()
+	}
+	return sinks
+}
+
+// Stats returns the dispatcher's counters
+func (d *Dispatcher) Stats() DispatcherStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
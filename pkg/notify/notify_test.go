@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingSink records the notifications it is sent
+type recordingSink struct {
+	mu   sync.Mutex
+	sent []Notification
+	err  error
+}
+
+func (s *recordingSink) Send(ctx context.Context, notification Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, notification)
+	return s.err
+}
+
+func (s *recordingSink) String() string {
+	return "recording"
+}
+
+func (s *recordingSink) titles() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	titles := []string{}
+	for _, notification := range s.sent {
+		titles = append(titles, notification.Title)
+	}
+	return titles
+}
+
+func TestDispatcher(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC))
+	sink := &recordingSink{}
+	failing := &recordingSink{err: errors.New("unreachable")}
+	dispatcher := NewDispatcher([]Sink{sink, failing}, DispatcherConfig{
+		DedupWindow: 30 * time.Minute,
+		MaxPerHour:  2,
+		Clock:       fake,
+	}, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+
+	// Repeats of a key are dropped within the dedup window
+	assert.True(t, dispatcher.Notify(Notification{Key: "a", Title: "first"}))
+	assert.False(t, dispatcher.Notify(Notification{Key: "a", Title: "repeat"}))
+	assert.True(t, dispatcher.Notify(Notification{Key: "b", Title: "second"}))
+	// Only two are sent in any hour
+	assert.False(t, dispatcher.Notify(Notification{Key: "c", Title: "limited"}))
+	require.Eventually(t, func() bool { return len(sink.titles()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"first", "second"}, sink.titles())
+
+	fake.Advance(time.Hour)
+	assert.True(t, dispatcher.Notify(Notification{Key: "a", Title: "again"}))
+	require.Eventually(t, func() bool { return len(sink.titles()) == 3 }, time.Second, 10*time.Millisecond)
+
+	// A failing sink does not keep notifications from the others
+	require.Eventually(t, func() bool { return dispatcher.Stats().Failed == 3 }, time.Second, 10*time.Millisecond)
+	stats := dispatcher.Stats()
+	assert.Equal(t, int64(3), stats.Sent)
+	assert.Equal(t, int64(1), stats.Deduplicated)
+	assert.Equal(t, int64(1), stats.RateLimited)
+	assert.Equal(t, "recording: unreachable", stats.LastError)
+	assert.Equal(t, []string{"recording", "recording"}, dispatcher.Sinks())
+}
+
+func TestWebhookAndSlack(t *testing.T) {
+	var requests []map[string]any
+	var authorization string
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+		authorization = r.Header.Get("Authorization")
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			http.Error(w, "no_service", http.StatusNotFound)
+		}
+	}))
+	defer service.Close()
+
+	notification := Notification{
+		Key:      "k",
+		Severity: "critical",
+		Title:    "search fails often",
+		Message:  "30% of calls time out",
+		Fields:   map[string]string{"tool": "search", "type": "reliability"},
+	}
+	webhook := &Webhook{URL: service.URL + "/hooks/secret", Headers: map[string]string{"Authorization": "Bearer t"}}
+	require.NoError(t, webhook.Send(context.Background(), notification))
+	assert.Equal(t, "Bearer t", authorization)
+	assert.Equal(t, "search fails often", requests[0]["title"])
+	assert.Equal(t, "critical", requests[0]["severity"])
+	assert.NotContains(t, webhook.String(), "secret")
+
+	slack := &Slack{WebhookURL: service.URL + "/services/T000/B000/XXXX"}
+	require.NoError(t, slack.Send(context.Background(), notification))
+	assert.Equal(t, "*[CRITICAL] search fails often*\n30% of calls time out\n• *tool*: search\n• *type*: reliability", requests[1]["text"])
+
+	err := (&Slack{WebhookURL: service.URL + "/gone"}).Send(context.Background(), notification)
+	assert.ErrorContains(t, err, "404 Not Found: no_service")
+}
+
+func TestEmail(t *testing.T) {
+	var message, from, addr string
+	var to []string
+	email := &Email{
+		Addr:     "smtp.example.com:587",
+		Username: "alerts",
+		Password: "secret",
+		From:     "aionmcp@example.com",
+		To:       []string{"ops@example.com", "oncall@example.com"},
+		sendMail: func(a string, auth smtp.Auth, f string, t []string, msg []byte) error {
+			addr, from, to, message = a, f, t, string(msg)
+			return nil
+		},
+	}
+	err := email.Send(context.Background(), Notification{
+		Severity: "high",
+		Title:    "slow\r\nBcc: someone@example.com",
+		Message:  "p95 latency doubled",
+		Fields:   map[string]string{"tool": "search"},
+		Time:     time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, "aionmcp@example.com", from)
+	assert.Equal(t, []string{"ops@example.com", "oncall@example.com"}, to)
+	headers, body, _ := strings.Cut(message, "\r\n\r\n")
+	assert.Contains(t, headers, "Subject: [HIGH] slow  Bcc: someone@example.com\r\n")
+	assert.NotContains(t, headers, "\r\nBcc:")
+	assert.Contains(t, headers, "Date: Sun, 01 Mar 2026 12:00:00 +0000")
+	assert.Equal(t, "p95 latency doubled\r\n\r\ntool: search", body)
+	assert.Equal(t, "email:ops@example.com,oncall@example.com", email.String())
+
+	// Sends that outlast the context give up
+	release := make(chan struct{})
+	defer close(release)
+	email.sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+		<-release
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, email.Send(ctx, Notification{}), context.DeadlineExceeded)
+}
@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Webhook posts notifications as JSON to a URL
+type Webhook struct {
+	URL     string
+	Headers map[string]string // Added to every request, e.g. Authorization
+	Client  *http.Client      // Nil selects http.DefaultClient
+}
+
+// Send posts the notification
+func (w *Webhook) Send(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, w.Client, w.URL, w.Headers, notification)
+}
+
+func (w *Webhook) String() string {
+	return "webhook:" + redactURL(w.URL)
+}
+
+// Slack posts notifications to a Slack incoming webhook
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client // Nil selects http.DefaultClient
+}
+
+// Send posts the notification as a message in Slack's mrkdwn format
+func (s *Slack) Send(ctx context.Context, notification Notification) error {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*[%s] %s*\n%s", strings.ToUpper(notification.Severity), notification.Title, notification.Message)
+	for _, name := range sortedFields(notification.Fields) {
+		fmt.Fprintf(&text, "\n• *%s*: %s", name, notification.Fields[name])
+	}
+	return postJSON(ctx, s.Client, s.WebhookURL, nil, map[string]string{"text": text.String()})
+}
+
+func (s *Slack) String() string {
+	return "slack:" + redactURL(s.WebhookURL)
+}
+
+// Email sends notifications as plain text mail through an SMTP server
+type Email struct {
+	Addr     string // host:port of the SMTP server
+	Username string // Empty sends without authentication
+	Password string
+	From     string
+	To       []string
+
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Send mails the notification to every recipient
+func (e *Email) Send(ctx context.Context, notification Notification) error {
+	if len(e.To) == 0 {
+		return fmt.Errorf("no email recipients")
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, _ := strings.Cut(e.Addr, ":")
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", e.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&body, "Subject: [%s] %s\r\n", strings.ToUpper(notification.Severity), headerValue(notification.Title))
+	fmt.Fprintf(&body, "Date: %s\r\n", notification.Time.Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	body.WriteString(notification.Message + "\r\n")
+	for _, name := range sortedFields(notification.Fields) {
+		fmt.Fprintf(&body, "\r\n%s: %s", name, notification.Fields[name])
+	}
+
+	sendMail := e.sendMail
+	if sendMail == nil {
+		sendMail = smtp.SendMail
+	}
+	// net/smtp takes no context, so the send runs on while ctx is done
+	result := make(chan error, 1)
+	go func() {
+		result <- sendMail(e.Addr, auth, e.From, e.To, []byte(body.String()))
+	}()
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Email) String() string {
+	return "email:" + strings.Join(e.To, ",")
+}
+
+// postJSON posts body as JSON and fails on non-2xx responses
+func postJSON(ctx context.Context, client *http.Client, target string, headers map[string]string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		text, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
+
+// headerValue keeps a line break in a value from starting another header
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// redactURL keeps the scheme and host of a URL; webhook paths often embed
+// secrets
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "invalid"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// sortedFields returns the field names in order
+func sortedFields(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,325 @@
+// Package oidc validates JWT bearer tokens issued by an OpenID Connect
+// provider. Signing keys are discovered from the issuer's JWKS and cached;
+// tokens must carry the configured issuer and audience and be within their
+// validity window. Signatures, keys and registered claims are checked with
+// go-jose.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	// DefaultClockSkew is the leeway allowed on exp, nbf and iat
+	DefaultClockSkew = time.Minute
+
+	// DefaultRefreshInterval is how long fetched signing keys are trusted
+	DefaultRefreshInterval = time.Hour
+
+	// minRefetchInterval limits refetches triggered by tokens signed with
+	// unknown keys, so forged tokens cannot flood the provider
+	minRefetchInterval = time.Minute
+
+	// maxDocumentSize bounds discovery and JWKS responses
+	maxDocumentSize = 1 << 20
+)
+
+// signatureAlgorithms are the algorithms tokens may be signed with. Only
+// asymmetric algorithms are accepted, so neither "none" nor a public key
+// used as an HMAC secret passes.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.ES256, jose.ES384, jose.ES512,
+}
+
+// ErrInvalidToken is matched by every token validation error
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenError describes why a token was rejected
+type TokenError struct {
+	Reason string
+}
+
+func (e *TokenError) Error() string {
+	return "invalid token: " + e.Reason
+}
+
+// Is reports whether target is ErrInvalidToken
+func (e *TokenError) Is(target error) bool {
+	return target == ErrInvalidToken
+}
+
+func invalid(format string, args ...interface{}) error {
+	return &TokenError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// Config configures token validation
+type Config struct {
+	Issuer          string        // Must equal the iss claim
+	Audience        string        // Must be one of the aud claim values
+	JWKSURL         string        // Defaults to the jwks_uri of the issuer's discovery document
+	ClockSkew       time.Duration // Leeway on time claims; zero selects the default
+	RefreshInterval time.Duration // How long fetched keys are trusted; zero selects the default
+}
+
+// Claims are the decoded claims of a token
+type Claims map[string]interface{}
+
+// lookup resolves a claim by name or dotted path, e.g. realm_access.roles
+func (c Claims) lookup(path string) (interface{}, bool) {
+	if value, ok := c[path]; ok {
+		return value, true
+	}
+	var current interface{} = map[string]interface{}(c)
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// String returns a string claim, or "" when it is missing or not a string
+func (c Claims) String(path string) string {
+	value, _ := c.lookup(path)
+	text, _ := value.(string)
+	return text
+}
+
+// Strings returns a claim holding a list of strings, or a single string of
+// space-separated values such as the scope claim
+func (c Claims) Strings(path string) []string {
+	value, _ := c.lookup(path)
+	switch typed := value.(type) {
+	case string:
+		return strings.Fields(typed)
+	case []interface{}:
+		values := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Token is a validated token
+type Token struct {
+	Subject   string
+	ExpiresAt time.Time
+	Claims    Claims
+}
+
+// LooksLikeJWT reports whether a bearer credential is a compact JWS rather
+// than an opaque key
+func LooksLikeJWT(token string) bool {
+	return strings.HasPrefix(token, "eyJ") && strings.Count(token, ".") == 2
+}
+
+// Verifier validates tokens of one issuer. It is safe for concurrent use.
+type Verifier struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]jose.JSONWebKey // By key ID
+	fetchedAt time.Time
+	jwksURL   string
+}
+
+// NewVerifier creates a verifier. Keys are fetched on first use, so the
+// server starts while the provider is unreachable.
+func NewVerifier(config Config, client *http.Client) (*Verifier, error) {
+	if config.Issuer == "" {
+		return nil, errors.New("OIDC issuer is required")
+	}
+	if config.Audience == "" {
+		return nil, errors.New("OIDC audience is required")
+	}
+	if config.ClockSkew <= 0 {
+		config.ClockSkew = DefaultClockSkew
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultRefreshInterval
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Verifier{
+		config:  config,
+		client:  client,
+		now:     time.Now,
+		jwksURL: config.JWKSURL,
+	}, nil
+}
+
+// Verify validates a token's signature, issuer, audience and validity window
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Token, error) {
+	token, err := jwt.ParseSigned(raw, signatureAlgorithms)
+	if err != nil {
+		return nil, invalid("malformed token")
+	}
+	head := token.Headers[0]
+
+	key, err := v.key(ctx, head.KeyID, head.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var claims Claims
+	var registered jwt.Claims
+	if err := token.Claims(key.Key, &claims, &registered); err != nil {
+		if errors.Is(err, jose.ErrCryptoFailure) {
+			return nil, invalid("signature verification failed")
+		}
+		return nil, invalid("malformed claims")
+	}
+	if err := v.validateClaims(registered); err != nil {
+		return nil, err
+	}
+	return &Token{Subject: registered.Subject, ExpiresAt: registered.Expiry.Time(), Claims: claims}, nil
+}
+
+// validateClaims checks the issuer, audience and time claims
+func (v *Verifier) validateClaims(registered jwt.Claims) error {
+	if registered.Expiry == nil {
+		return invalid("missing exp claim")
+	}
+	err := registered.ValidateWithLeeway(jwt.Expected{
+		Issuer:      v.config.Issuer,
+		AnyAudience: jwt.Audience{v.config.Audience},
+		Time:        v.now(),
+	}, v.config.ClockSkew)
+	switch {
+	case errors.Is(err, jwt.ErrInvalidIssuer):
+		return invalid("unexpected issuer %q", registered.Issuer)
+	case errors.Is(err, jwt.ErrInvalidAudience):
+		return invalid("audience does not include %q", v.config.Audience)
+	case errors.Is(err, jwt.ErrExpired):
+		return invalid("token expired")
+	case errors.Is(err, jwt.ErrNotValidYet):
+		return invalid("token not yet valid")
+	case errors.Is(err, jwt.ErrIssuedInTheFuture):
+		return invalid("token issued in the future")
+	case err != nil:
+		return invalid("%v", err)
+	}
+	if registered.Subject == "" {
+		return invalid("missing sub claim")
+	}
+	return nil
+}
+
+// key returns the signing key for a key ID, fetching the JWKS when the keys
+// are stale or the ID is unknown
+func (v *Verifier) key(ctx context.Context, kid, alg string) (jose.JSONWebKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	stale := v.keys == nil || now.Sub(v.fetchedAt) >= v.config.RefreshInterval
+	_, known := v.keys[kid]
+	if stale || (!known && now.Sub(v.fetchedAt) >= minRefetchInterval) {
+		if err := v.fetchKeys(ctx); err != nil && v.keys == nil {
+			return jose.JSONWebKey{}, err
+		}
+	}
+
+	key, ok := v.keys[kid]
+	if !ok && kid == "" && len(v.keys) == 1 {
+		// Providers with a single key may omit kid
+		for id, only := range v.keys {
+			key, ok, kid = only, true, id
+		}
+	}
+	if !ok {
+		return jose.JSONWebKey{}, invalid("unknown signing key %q", kid)
+	}
+	if key.Algorithm != "" && key.Algorithm != alg {
+		return jose.JSONWebKey{}, invalid("key %q does not sign %s", kid, alg)
+	}
+	return key, nil
+}
+
+// fetchKeys downloads the issuer's signing keys. Callers hold v.mu.
+func (v *Verifier) fetchKeys(ctx context.Context) error {
+	v.fetchedAt = v.now()
+
+	if v.jwksURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		url := strings.TrimSuffix(v.config.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := v.getJSON(ctx, url, &discovery); err != nil {
+			return fmt.Errorf("OIDC discovery failed: %w", err)
+		}
+		if discovery.Issuer != v.config.Issuer {
+			return fmt.Errorf("OIDC discovery returned issuer %q, expected %q", discovery.Issuer, v.config.Issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return errors.New("OIDC discovery document has no jwks_uri")
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+
+	// Keys are decoded one by one, so a key of an unsupported type is
+	// skipped rather than failing the set
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	keys := make(map[string]jose.JSONWebKey, len(set.Keys))
+	for _, data := range set.Keys {
+		var jwk jose.JSONWebKey
+		if err := jwk.UnmarshalJSON(data); err != nil || !jwk.IsPublic() {
+			continue
+		}
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		keys[jwk.KeyID] = jwk
+	}
+	if len(keys) == 0 {
+		return errors.New("JWKS has no usable signing keys")
+	}
+	v.keys = keys
+	return nil
+}
+
+// getJSON fetches and decodes a JSON document
+func (v *Verifier) getJSON(ctx context.Context, url string, target interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := v.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, response.Status)
+	}
+	return json.NewDecoder(io.LimitReader(response.Body, maxDocumentSize)).Decode(target)
+}
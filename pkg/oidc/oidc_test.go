@@ -0,0 +1,191 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// provider serves discovery and a JWKS holding an RSA and an EC key
+type provider struct {
+	*httptest.Server
+	rsaKey     *rsa.PrivateKey
+	ecKey      *ecdsa.PrivateKey
+	jwksHits   int
+	jwksStatus int
+}
+
+func newProvider(t *testing.T) *provider {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p := &provider{rsaKey: rsaKey, ecKey: ecKey, jwksStatus: http.StatusOK}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": p.URL, "jwks_uri": p.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.jwksHits++
+		if p.jwksStatus != http.StatusOK {
+			w.WriteHeader(p.jwksStatus)
+			return
+		}
+		encode := base64.RawURLEncoding.EncodeToString
+		ecPoint, err := ecKey.PublicKey.Bytes()
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "alg": "RS256",
+				"n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecPoint[1:33]), "y": encode(ecPoint[33:])},
+			{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+		}})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// header is the JOSE header of a test token
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// sign issues a token with the given header and claims
+func (p *provider) sign(t *testing.T, head header, claims map[string]interface{}) string {
+	encode := func(value interface{}) string {
+		data, err := json.Marshal(value)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(head) + "." + encode(claims)
+	sum := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch head.Alg {
+	case "RS256":
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, p.rsaKey, crypto.SHA256, sum[:])
+		require.NoError(t, err)
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, p.ecKey, sum[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (p *provider) claims(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss": p.URL,
+		"aud": []string{"aionmcp", "other"},
+		"sub": "agent-42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+func TestVerify(t *testing.T) {
+	p := newProvider(t)
+	verifier, err := NewVerifier(Config{Issuer: p.URL, Audience: "aionmcp"}, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	raw := p.sign(t, header{Alg: "RS256", Kid: "rsa"}, p.claims(map[string]interface{}{
+		"realm_access": map[string]interface{}{"roles": []string{"agent", "admin"}},
+		"scope":        "openid tools",
+	}))
+	assert.True(t, LooksLikeJWT(raw))
+	token, err := verifier.Verify(ctx, raw)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-42", token.Subject)
+	assert.Equal(t, []string{"agent", "admin"}, token.Claims.Strings("realm_access.roles"))
+	assert.Equal(t, []string{"openid", "tools"}, token.Claims.Strings("scope"))
+	assert.Empty(t, token.Claims.String("missing.claim"))
+
+	token, err = verifier.Verify(ctx, p.sign(t, header{Alg: "ES256", Kid: "ec"}, p.claims(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, "agent-42", token.Subject)
+
+	// Keys are cached between tokens
+	assert.Equal(t, 1, p.jwksHits)
+}
+
+func TestVerifyRejects(t *testing.T) {
+	p := newProvider(t)
+	verifier, err := NewVerifier(Config{Issuer: p.URL, Audience: "aionmcp", JWKSURL: p.URL + "/jwks"}, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	rsaHeader := header{Alg: "RS256", Kid: "rsa"}
+
+	tampered := p.sign(t, rsaHeader, p.claims(nil))
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+
+	cases := map[string]string{
+		"audience":     p.sign(t, rsaHeader, p.claims(map[string]interface{}{"aud": "someone-else"})),
+		"issuer":       p.sign(t, rsaHeader, p.claims(map[string]interface{}{"iss": "https://evil.example"})),
+		"expired":      p.sign(t, rsaHeader, p.claims(map[string]interface{}{"exp": time.Now().Add(-2 * time.Minute).Unix()})),
+		"no expiry":    p.sign(t, rsaHeader, p.claims(map[string]interface{}{"exp": nil})),
+		"not yet":      p.sign(t, rsaHeader, p.claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})),
+		"no subject":   p.sign(t, rsaHeader, p.claims(map[string]interface{}{"sub": nil})),
+		"unknown key":  p.sign(t, header{Alg: "RS256", Kid: "rotated"}, p.claims(nil)),
+		"alg none":     p.sign(t, header{Alg: "none", Kid: "rsa"}, p.claims(nil)),
+		"alg mismatch": p.sign(t, header{Alg: "ES256", Kid: "rsa"}, p.claims(nil)),
+		"hmac":         p.sign(t, header{Alg: "HS256", Kid: "hmac"}, p.claims(nil)),
+		"signature":    tampered,
+		"malformed":    "eyJhbGciOiJSUzI1NiJ9.not-json",
+	}
+	for name, raw := range cases {
+		_, err := verifier.Verify(ctx, raw)
+		assert.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+
+	// Expiry honours the clock skew
+	_, err = verifier.Verify(ctx, p.sign(t, rsaHeader, p.claims(map[string]interface{}{"exp": time.Now().Add(-30 * time.Second).Unix()})))
+	assert.NoError(t, err)
+
+	// Unknown keys refetch the JWKS at most once a minute
+	assert.Equal(t, 1, p.jwksHits)
+	verifier.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	verifier.Verify(ctx, p.sign(t, header{Alg: "RS256", Kid: "rotated"}, p.claims(nil)))
+	assert.Equal(t, 2, p.jwksHits)
+}
+
+func TestVerifyProviderDown(t *testing.T) {
+	p := newProvider(t)
+	p.jwksStatus = http.StatusBadGateway
+	verifier, err := NewVerifier(Config{Issuer: p.URL, Audience: "aionmcp"}, nil)
+	require.NoError(t, err)
+
+	// Without cached keys the failure is not a token error
+	_, err = verifier.Verify(context.Background(), p.sign(t, header{Alg: "RS256", Kid: "rsa"}, p.claims(nil)))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+
+	_, err = NewVerifier(Config{Issuer: p.URL}, nil)
+	assert.Error(t, err)
+}
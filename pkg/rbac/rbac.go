@@ -0,0 +1,310 @@
+// Package rbac implements role-based access control over tools. A role holds
+// allow and deny rules matching tool names, sources and tags; callers see and
+// invoke only the tools one of their roles allows and none of them denies.
+// Roles may also limit the tenants whose learning data their holders see.
+// Roles are kept in BoltDB.
+package rbac
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// rolesBucket holds the stored roles by name
+const rolesBucket = "roles"
+
+// ErrNotFound is returned when no role has the requested name
+var ErrNotFound = errors.New("role not found")
+
+// Tool is what rules are matched against
+type Tool struct {
+	Name   string
+	Source string
+	Tags   []string
+}
+
+// Rule matches tools by glob patterns, as in path.Match. An empty field
+// matches any tool; a tool must match every field that is set, and for
+// Tools, Sources and Tags any one pattern of the field.
+type Rule struct {
+	Tools   []string `json:"tools,omitempty" mapstructure:"tools"`
+	Sources []string `json:"sources,omitempty" mapstructure:"sources"`
+	Tags    []string `json:"tags,omitempty" mapstructure:"tags"`
+}
+
+// Matches reports whether a tool matches the rule
+func (r Rule) Matches(tool Tool) bool {
+	if len(r.Tools) > 0 && !matchAny(r.Tools, tool.Name) {
+		return false
+	}
+	if len(r.Sources) > 0 && !matchAny(r.Sources, tool.Source) {
+		return false
+	}
+	if len(r.Tags) > 0 {
+		tagged := false
+		for _, tag := range tool.Tags {
+			tagged = tagged || matchAny(r.Tags, tag)
+		}
+		if !tagged {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks that every pattern is well formed
+func (r Rule) validate() error {
+	for _, patterns := range [][]string{r.Tools, r.Sources, r.Tags} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Role grants access to the tools its Allow rules match, except those its
+// Deny rules match. Tenants, as glob patterns, limits the learning data its
+// holders see to that of the matching tenants; a role without tenants sees
+// the data of every tenant.
+type Role struct {
+	Name        string    `json:"name" mapstructure:"name"`
+	Description string    `json:"description,omitempty" mapstructure:"description"`
+	Allow       []Rule    `json:"allow,omitempty" mapstructure:"allow"`
+	Deny        []Rule    `json:"deny,omitempty" mapstructure:"deny"`
+	Tenants     []string  `json:"tenants,omitempty" mapstructure:"tenants"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate checks the role name and rule patterns
+func (r Role) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("role name is required")
+	}
+	for _, rule := range append(append([]Rule{}, r.Allow...), r.Deny...) {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	for _, tenant := range r.Tenants {
+		if _, err := path.Match(tenant, ""); err != nil || tenant == "" {
+			return fmt.Errorf("invalid tenant pattern %q", tenant)
+		}
+	}
+	return nil
+}
+
+// allows reports whether the role's allow rules match a tool
+func (r Role) allows(tool Tool) bool {
+	for _, rule := range r.Allow {
+		if rule.Matches(tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// denies reports whether the role's deny rules match a tool
+func (r Role) denies(tool Tool) bool {
+	for _, rule := range r.Deny {
+		if rule.Matches(tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps roles in BoltDB, cached in memory for access checks. It is
+// safe for concurrent use.
+type Store struct {
+	db    *bolt.DB // Nil for stores held only in memory
+	mu    sync.RWMutex
+	roles map[string]Role // By normalized name
+}
+
+// Open opens or creates the role store at path
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create role directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open role store: %w", err)
+	}
+
+	store := &Store{db: db, roles: make(map[string]Role)}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(rolesBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.ForEach(func(_, data []byte) error {
+			var role Role
+			if err := json.Unmarshal(data, &role); err != nil {
+				return fmt.Errorf("failed to decode role: %w", err)
+			}
+			store.roles[normalize(role.Name)] = role
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	return store, nil
+}
+
+// OpenMemory creates an empty role store that is never written to disk
+func OpenMemory() *Store {
+	return &Store{roles: make(map[string]Role)}
+}
+
+// update persists a change unless the store is held only in memory
+func (s *Store) update(fn func(bucket *bolt.Bucket) error) error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket([]byte(rolesBucket)))
+	})
+}
+
+// Close releases the BoltDB file
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Put creates or replaces a role
+func (s *Store) Put(role Role) (Role, error) {
+	if err := role.Validate(); err != nil {
+		return Role{}, err
+	}
+	role.Name = strings.TrimSpace(role.Name)
+	role.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(role)
+	if err != nil {
+		return Role{}, fmt.Errorf("failed to encode role: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = s.update(func(bucket *bolt.Bucket) error {
+		return bucket.Put([]byte(normalize(role.Name)), data)
+	})
+	if err != nil {
+		return Role{}, fmt.Errorf("failed to store role: %w", err)
+	}
+	s.roles[normalize(role.Name)] = role
+	return role, nil
+}
+
+// Get returns a role by name
+func (s *Store) Get(name string) (Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	role, exists := s.roles[normalize(name)]
+	if !exists {
+		return Role{}, ErrNotFound
+	}
+	return role, nil
+}
+
+// List returns the stored roles sorted by name
+func (s *Store) List() []Role {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	roles := make([]Role, 0, len(s.roles))
+	for _, role := range s.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return normalize(roles[i].Name) < normalize(roles[j].Name) })
+	return roles
+}
+
+// Delete removes a role. Callers holding it lose the access it granted.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.roles[normalize(name)]; !exists {
+		return ErrNotFound
+	}
+	err := s.update(func(bucket *bolt.Bucket) error {
+		return bucket.Delete([]byte(normalize(name)))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	delete(s.roles, normalize(name))
+	return nil
+}
+
+// Allowed reports whether holders of roles may use a tool: a deny rule in
+// any of the roles wins, otherwise one of them must allow it. Unknown roles
+// grant nothing.
+func (s *Store) Allowed(roles []string, tool Tool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	allowed := false
+	for _, name := range roles {
+		role, exists := s.roles[normalize(name)]
+		if !exists {
+			continue
+		}
+		if role.denies(tool) {
+			return false
+		}
+		allowed = allowed || role.allows(tool)
+	}
+	return allowed
+}
+
+// Tenants returns the tenant patterns whose learning data holders of roles
+// see. It is unrestricted when one of the roles has no tenants; unknown
+// roles see no tenant.
+func (s *Store) Tenants(roles []string) (tenants []string, restricted bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, name := range roles {
+		role, exists := s.roles[normalize(name)]
+		if !exists {
+			continue
+		}
+		if len(role.Tenants) == 0 {
+			return nil, false
+		}
+		tenants = append(tenants, role.Tenants...)
+	}
+	return tenants, true
+}
+
+// matchAny reports whether a value matches one of the patterns
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize makes role names case-insensitive
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,98 @@
+package rbac
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMatches(t *testing.T) {
+	tool := Tool{Name: "petstore.deletePet", Source: "openapi:petstore", Tags: []string{"pets", "write"}}
+
+	assert.True(t, Rule{}.Matches(tool))
+	assert.True(t, Rule{Tools: []string{"petstore.*"}}.Matches(tool))
+	assert.True(t, Rule{Sources: []string{"openapi:*"}, Tags: []string{"read", "write"}}.Matches(tool))
+	assert.False(t, Rule{Tools: []string{"petstore.*"}, Tags: []string{"read"}}.Matches(tool))
+	assert.False(t, Rule{Sources: []string{"graphql:*"}}.Matches(tool))
+	assert.False(t, Rule{Tags: []string{"pets"}}.Matches(Tool{Name: "untagged"}))
+}
+
+func TestAllowed(t *testing.T) {
+	store := OpenMemory()
+	_, err := store.Put(Role{Name: "Reader", Allow: []Rule{{Tools: []string{"petstore.*"}}}, Deny: []Rule{{Tags: []string{"write"}}}})
+	require.NoError(t, err)
+	_, err = store.Put(Role{Name: "builtin", Allow: []Rule{{Sources: []string{"builtin"}}}})
+	require.NoError(t, err)
+
+	list := Tool{Name: "petstore.listPets", Source: "openapi:petstore", Tags: []string{"read"}}
+	remove := Tool{Name: "petstore.deletePet", Source: "openapi:petstore", Tags: []string{"write"}}
+	echo := Tool{Name: "echo", Source: "builtin"}
+
+	assert.True(t, store.Allowed([]string{"reader"}, list))
+	assert.False(t, store.Allowed([]string{"reader"}, remove))
+	assert.False(t, store.Allowed([]string{"reader"}, echo))
+	assert.True(t, store.Allowed([]string{"reader", "builtin"}, echo))
+	assert.False(t, store.Allowed([]string{"reader", "builtin"}, remove), "deny in any role wins")
+	assert.False(t, store.Allowed([]string{"unknown"}, echo))
+	assert.False(t, store.Allowed(nil, echo))
+}
+
+func TestTenants(t *testing.T) {
+	store := OpenMemory()
+	_, err := store.Put(Role{Name: "billing", Allow: []Rule{{}}, Tenants: []string{"billing"}})
+	require.NoError(t, err)
+	_, err = store.Put(Role{Name: "payments", Allow: []Rule{{}}, Tenants: []string{"payments-*"}})
+	require.NoError(t, err)
+	_, err = store.Put(Role{Name: "admin", Allow: []Rule{{}}})
+	require.NoError(t, err)
+	_, err = store.Put(Role{Name: "bad", Tenants: []string{"["}})
+	assert.Error(t, err)
+
+	tenants, restricted := store.Tenants([]string{"billing", "payments"})
+	assert.True(t, restricted)
+	assert.Equal(t, []string{"billing", "payments-*"}, tenants)
+
+	_, restricted = store.Tenants([]string{"billing", "admin"})
+	assert.False(t, restricted, "a role without tenants sees every tenant")
+
+	tenants, restricted = store.Tenants([]string{"unknown"})
+	assert.True(t, restricted)
+	assert.Empty(t, tenants)
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rbac.db")
+	store, err := Open(path)
+	require.NoError(t, err)
+
+	_, err = store.Put(Role{Name: " "})
+	assert.Error(t, err)
+	_, err = store.Put(Role{Name: "bad", Allow: []Rule{{Tools: []string{"["}}}})
+	assert.Error(t, err)
+
+	role, err := store.Put(Role{Name: "ops", Description: "Operators", Allow: []Rule{{}}})
+	require.NoError(t, err)
+	assert.False(t, role.UpdatedAt.IsZero())
+	_, err = store.Put(Role{Name: "agents", Allow: []Rule{{Tags: []string{"safe"}}}})
+	require.NoError(t, err)
+
+	// Roles survive a reopen
+	require.NoError(t, store.Close())
+	store, err = Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	roles := store.List()
+	require.Len(t, roles, 2)
+	assert.Equal(t, "agents", roles[0].Name)
+	fetched, err := store.Get("OPS")
+	require.NoError(t, err)
+	assert.Equal(t, "Operators", fetched.Description)
+
+	require.NoError(t, store.Delete("ops"))
+	_, err = store.Get("ops")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Delete("ops"), ErrNotFound)
+}
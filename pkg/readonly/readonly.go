@@ -0,0 +1,136 @@
+// Package readonly implements the server-wide and per-workspace read-only
+// mode used during maintenance windows. In read-only mode queries keep
+// working while invocations, spec imports and doc generation are rejected.
+package readonly
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/clock"
+)
+
+// WorkspaceHeader is the HTTP header naming the workspace a request belongs to
+const WorkspaceHeader = "X-AionMCP-Workspace"
+
+// WorkspaceMetadataKey is the agent session metadata key naming its workspace
+const WorkspaceMetadataKey = "workspace"
+
+// ErrReadOnly is matched by every error returned from Mode.Check
+var ErrReadOnly = errors.New("read-only mode")
+
+// Error describes why an operation was rejected
+type Error struct {
+	Workspace string
+	Reason    string
+}
+
+func (e *Error) Error() string {
+	scope := "server is in read-only mode"
+	if e.Workspace != "" {
+		scope = fmt.Sprintf("workspace %q is in read-only mode", e.Workspace)
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s", scope, e.Reason)
+	}
+	return scope
+}
+
+// Is reports whether target is ErrReadOnly
+func (e *Error) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+// State is the read-only state of the server or a single workspace
+type State struct {
+	Enabled bool      `json:"enabled"`
+	Reason  string    `json:"reason,omitempty"`
+	Since   time.Time `json:"since,omitempty"`
+}
+
+// Status is a snapshot of the server-wide and per-workspace states
+type Status struct {
+	Server     State            `json:"server"`
+	Workspaces map[string]State `json:"workspaces"`
+}
+
+// Mode tracks the read-only state. It is safe for concurrent use.
+type Mode struct {
+	mu         sync.RWMutex
+	server     State
+	workspaces map[string]State
+	now        func() time.Time
+}
+
+// NewMode creates a mode with read-only disabled everywhere
+func NewMode() *Mode {
+	return &Mode{
+		workspaces: make(map[string]State),
+		now:        time.Now,
+	}
+}
+
+// SetClock replaces the clock that stamps when read-only mode was enabled
+func (m *Mode) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = c.Now
+}
+
+// Set enables or disables read-only mode. An empty workspace addresses the
+// whole server.
+func (m *Mode) Set(workspace string, enabled bool, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := State{}
+	if enabled {
+		state = State{Enabled: true, Reason: reason, Since: m.now()}
+	}
+
+	if workspace == "" {
+		m.server = state
+		return
+	}
+	if enabled {
+		m.workspaces[workspace] = state
+	} else {
+		delete(m.workspaces, workspace)
+	}
+}
+
+// Check returns an error when writes are not allowed for the workspace.
+// Server-wide read-only mode applies to every workspace.
+func (m *Mode) Check(workspace string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.server.Enabled {
+		return &Error{Reason: m.server.Reason}
+	}
+	if workspace == "" {
+		return nil
+	}
+	if state, exists := m.workspaces[workspace]; exists {
+		return &Error{Workspace: workspace, Reason: state.Reason}
+	}
+	return nil
+}
+
+// Status returns the current read-only state
+func (m *Mode) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	workspaces := make(map[string]State, len(m.workspaces))
+	for name, state := range m.workspaces {
+		workspaces[name] = state
+	}
+
+	return Status{
+		Server:     m.server,
+		Workspaces: workspaces,
+	}
+}
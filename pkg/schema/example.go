@@ -0,0 +1,241 @@
+// Package schema works with the JSON Schema documents tools publish in
+// their metadata.
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxExampleDepth stops example generation for deeply nested or recursive schemas
+const maxExampleDepth = 8
+
+// formatExamples are sample values for common string formats
+var formatExamples = map[string]string{
+	"date-time": "2024-01-15T09:30:00Z",
+	"date":      "2024-01-15",
+	"time":      "09:30:00",
+	"email":     "user@example.com",
+	"uri":       "https://example.com/resource",
+	"url":       "https://example.com/resource",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uuid":      "123e4567-e89b-12d3-a456-426614174000",
+	"byte":      "ZXhhbXBsZQ==",
+	"password":  "example-password",
+}
+
+// ExampleOptions controls example generation
+type ExampleOptions struct {
+	IncludeOptional bool // Also fill properties that are not required
+}
+
+// Example generates a value that satisfies a JSON Schema. Explicit examples,
+// defaults, constants and enums are preferred over synthesized values;
+// strings honour well-known formats and length bounds, numbers their bounds
+// and arrays their minimum length.
+func Example(schema map[string]interface{}, options ExampleOptions) interface{} {
+	return example(schema, options, 0)
+}
+
+func example(schema map[string]interface{}, options ExampleOptions, depth int) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	for _, key := range []string{"example", "default", "const"} {
+		if value, exists := schema[key]; exists {
+			return value
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	// Compositions: use the first alternative, merge all parts
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alternatives := schemaList(schema[key]); len(alternatives) > 0 {
+			return example(alternatives[0], options, depth+1)
+		}
+	}
+	if parts := schemaList(schema["allOf"]); len(parts) > 0 {
+		merged := make(map[string]interface{})
+		for _, part := range parts {
+			if value, ok := example(part, options, depth+1).(map[string]interface{}); ok {
+				for name, field := range value {
+					merged[name] = field
+				}
+			}
+		}
+		return merged
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return objectExample(schema, options, depth)
+	case "array":
+		item := example(schemaMap(schema["items"]), options, depth+1)
+		count := int(number(schema["minItems"], 1))
+		if count < 1 {
+			count = 1
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = item
+		}
+		return items
+	case "integer":
+		return int64(numberInRange(schema, 1))
+	case "number":
+		return numberInRange(schema, 1.5)
+	case "boolean":
+		return true
+	case "null":
+		return nil
+	default:
+		return stringExample(schema)
+	}
+}
+
+// objectExample fills the required properties, and the others when requested
+func objectExample(schema map[string]interface{}, options ExampleOptions, depth int) map[string]interface{} {
+	value := make(map[string]interface{})
+	properties := schemaMap(schema["properties"])
+	required := make(map[string]bool)
+	for _, name := range RequiredFields(schema) {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !required[name] && !options.IncludeOptional {
+			continue
+		}
+		value[name] = example(schemaMap(properties[name]), options, depth+1)
+	}
+	return value
+}
+
+// stringExample synthesizes a string honouring the format and length bounds
+func stringExample(schema map[string]interface{}) string {
+	format, _ := schema["format"].(string)
+	value, known := formatExamples[format]
+	if !known {
+		value = "example"
+	}
+
+	if minLength := int(number(schema["minLength"], 0)); len(value) < minLength {
+		value += strings.Repeat("x", minLength-len(value))
+	}
+	if maxLength, ok := schema["maxLength"]; ok && !known {
+		if limit := int(number(maxLength, 0)); limit < len(value) {
+			value = value[:limit]
+		}
+	}
+	return value
+}
+
+// numberInRange returns fallback clamped to the schema's minimum and maximum
+func numberInRange(schema map[string]interface{}, fallback float64) float64 {
+	value := fallback
+	if minimum, ok := schema["minimum"]; ok && number(minimum, 0) > value {
+		value = number(minimum, 0)
+	}
+	if maximum, ok := schema["maximum"]; ok && number(maximum, 0) < value {
+		value = number(maximum, 0)
+	}
+	return value
+}
+
+// RequiredFields returns the required property names of an object schema
+func RequiredFields(schema map[string]interface{}) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []interface{}:
+		names := make([]string, 0, len(required))
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// schemaType returns the declared type, inferring object and array from
+// their keywords. The first non-null entry of a type list is used.
+func schemaType(schema map[string]interface{}) string {
+	switch declared := schema["type"].(type) {
+	case string:
+		return declared
+	case []interface{}:
+		for _, entry := range declared {
+			if s, ok := entry.(string); ok && s != "null" {
+				return s
+			}
+		}
+	case []string:
+		for _, entry := range declared {
+			if entry != "null" {
+				return entry
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// schemaMap converts a decoded schema value to a map
+func schemaMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return nil
+}
+
+// schemaList converts a decoded list of schemas to maps
+func schemaList(value interface{}) []map[string]interface{} {
+	switch list := value.(type) {
+	case []interface{}:
+		schemas := make([]map[string]interface{}, 0, len(list))
+		for _, entry := range list {
+			if m := schemaMap(entry); m != nil {
+				schemas = append(schemas, m)
+			}
+		}
+		return schemas
+	case []map[string]interface{}:
+		return list
+	}
+	return nil
+}
+
+// number converts a decoded JSON number or Go numeric value to float64
+func number(value interface{}, fallback float64) float64 {
+	switch n := value.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	}
+	return fallback
+}
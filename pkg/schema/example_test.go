@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExample(t *testing.T) {
+	input := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"status", "owner", "tags", "limit", "created", "filter"},
+		"properties": map[string]interface{}{
+			"status":  map[string]interface{}{"type": "string", "enum": []interface{}{"available", "sold"}},
+			"owner":   map[string]interface{}{"type": "string", "format": "email"},
+			"tags":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string", "minLength": 10}, "minItems": 2},
+			"limit":   map[string]interface{}{"type": "integer", "minimum": 10.0, "maximum": 100.0},
+			"created": map[string]interface{}{"type": []interface{}{"null", "string"}, "format": "date-time"},
+			"filter": map[string]interface{}{
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id":    map[string]interface{}{"type": "string", "format": "uuid"},
+					"ratio": map[string]interface{}{"type": "number", "maximum": 1.0},
+				},
+			},
+			"note":   map[string]interface{}{"type": "string", "default": "n/a"},
+			"active": map[string]interface{}{"type": "boolean"},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"status":  "available",
+		"owner":   "user@example.com",
+		"tags":    []interface{}{"examplexxx", "examplexxx"},
+		"limit":   int64(10),
+		"created": "2024-01-15T09:30:00Z",
+		"filter":  map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174000"},
+	}, Example(input, ExampleOptions{}))
+
+	// Optional properties are filled on request, honouring defaults and bounds
+	full := Example(input, ExampleOptions{IncludeOptional: true}).(map[string]interface{})
+	assert.Equal(t, "n/a", full["note"])
+	assert.Equal(t, true, full["active"])
+	assert.Equal(t, 1.0, full["filter"].(map[string]interface{})["ratio"])
+
+	// Compositions use the first alternative or merge every part
+	assert.Equal(t, "example", Example(map[string]interface{}{
+		"oneOf": []interface{}{map[string]interface{}{"type": "string"}, map[string]interface{}{"type": "integer"}},
+	}, ExampleOptions{}))
+	assert.Equal(t, map[string]interface{}{"a": true, "b": 1.5}, Example(map[string]interface{}{
+		"allOf": []interface{}{
+			map[string]interface{}{"required": []interface{}{"a"}, "properties": map[string]interface{}{"a": map[string]interface{}{"type": "boolean"}}},
+			map[string]interface{}{"required": []interface{}{"b"}, "properties": map[string]interface{}{"b": map[string]interface{}{"type": "number"}}},
+		},
+	}, ExampleOptions{}))
+
+	assert.Nil(t, Example(nil, ExampleOptions{}))
+}
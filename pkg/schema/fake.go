@@ -0,0 +1,205 @@
+package schema
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeWords are the words fake strings are built from
+var fakeWords = []string{
+	"amber", "birch", "cedar", "delta", "ember", "fjord", "grove", "harbor",
+	"island", "juniper", "kestrel", "lagoon", "meadow", "nova", "orchid", "prairie",
+	"quartz", "ridge", "summit", "tundra", "umber", "valley", "willow", "zephyr",
+}
+
+// fakeEpoch and fakeSpan bound the dates and times fake values fall in
+var (
+	fakeEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fakeSpan  = 5 * 365 * 24 * time.Hour
+)
+
+// How far numbers and array lengths reach when the schema bounds one side only
+const (
+	fakeNumberRange = 1000
+	fakeExtraItems  = 3
+)
+
+// Fake generates a random value that satisfies a JSON Schema. Unlike Example
+// it varies: enum members, alternatives, optional properties, numbers,
+// lengths and formatted strings are drawn from rng, so the same seed yields
+// the same values. Constants are kept, and strings constrained by a pattern
+// fall back to their example because patterns cannot be inverted in general.
+func Fake(schema map[string]interface{}, rng *rand.Rand) interface{} {
+	return fake(schema, rng, 0)
+}
+
+func fake(schema map[string]interface{}, rng *rand.Rand, depth int) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	if value, exists := schema["const"]; exists {
+		return value
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[rng.Intn(len(enum))]
+	}
+
+	// Compositions: pick an alternative, merge all parts
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alternatives := schemaList(schema[key]); len(alternatives) > 0 {
+			return fake(alternatives[rng.Intn(len(alternatives))], rng, depth+1)
+		}
+	}
+	if parts := schemaList(schema["allOf"]); len(parts) > 0 {
+		merged := make(map[string]interface{})
+		for _, part := range parts {
+			if value, ok := fake(part, rng, depth+1).(map[string]interface{}); ok {
+				for name, field := range value {
+					merged[name] = field
+				}
+			}
+		}
+		return merged
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return fakeObject(schema, rng, depth)
+	case "array":
+		low, high := fakeBounds(schema, "minItems", "maxItems", 0, fakeExtraItems)
+		items := make([]interface{}, int(low)+rng.Intn(int(high-low)+1))
+		for i := range items {
+			items[i] = fake(schemaMap(schema["items"]), rng, depth+1)
+		}
+		return items
+	case "integer":
+		low, high := fakeBounds(schema, "minimum", "maximum", 0, fakeNumberRange)
+		low, high = math.Ceil(low), math.Floor(high)
+		if high < low {
+			return int64(low)
+		}
+		return int64(low) + rng.Int63n(int64(high-low)+1)
+	case "number":
+		low, high := fakeBounds(schema, "minimum", "maximum", 0, fakeNumberRange)
+		value := math.Round((low+rng.Float64()*(high-low))*100) / 100
+		return math.Min(math.Max(value, low), high)
+	case "boolean":
+		return rng.Intn(2) == 1
+	case "null":
+		return nil
+	default:
+		return fakeString(schema, rng, depth)
+	}
+}
+
+// fakeBounds returns the inclusive range of a bounded keyword pair. A missing
+// lower bound defaults to fallback, and a missing upper bound reaches span
+// beyond the lower one.
+func fakeBounds(schema map[string]interface{}, minKey, maxKey string, fallback, span float64) (float64, float64) {
+	low := number(schema[minKey], fallback)
+	high := low + span
+	if maximum, ok := schema[maxKey]; ok {
+		high = number(maximum, high)
+		if _, ok := schema[minKey]; !ok && high < low {
+			low = high - span
+		}
+	}
+	if high < low {
+		high = low
+	}
+	return low, high
+}
+
+// fakeObject fills the required properties and, by chance, the others
+func fakeObject(schema map[string]interface{}, rng *rand.Rand, depth int) map[string]interface{} {
+	value := make(map[string]interface{})
+	properties := schemaMap(schema["properties"])
+	required := make(map[string]bool)
+	for _, name := range RequiredFields(schema) {
+		required[name] = true
+	}
+
+	// Sorted so a seed reproduces the same object
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !required[name] && rng.Intn(2) == 0 {
+			continue
+		}
+		value[name] = fake(schemaMap(properties[name]), rng, depth+1)
+	}
+	return value
+}
+
+// fakeString generates a string in the schema's format within its length bounds
+func fakeString(schema map[string]interface{}, rng *rand.Rand, depth int) string {
+	if _, ok := schema["pattern"]; ok {
+		return fmt.Sprint(example(schema, ExampleOptions{}, depth))
+	}
+
+	word := func() string { return fakeWords[rng.Intn(len(fakeWords))] }
+	moment := func() time.Time {
+		return fakeEpoch.Add(time.Duration(rng.Int63n(int64(fakeSpan)))).Truncate(time.Second)
+	}
+
+	format, _ := schema["format"].(string)
+	var value string
+	switch format {
+	case "date-time":
+		return moment().Format(time.RFC3339)
+	case "date":
+		return moment().Format("2006-01-02")
+	case "time":
+		return moment().Format("15:04:05")
+	case "email":
+		return fmt.Sprintf("%s.%s@example.com", word(), word())
+	case "uri", "url":
+		return fmt.Sprintf("https://example.com/%s/%d", word(), rng.Intn(fakeNumberRange))
+	case "hostname":
+		return word() + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", rng.Intn(256))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", rng.Intn(1<<16))
+	case "uuid":
+		return uuid.Must(uuid.NewRandomFromReader(rng)).String()
+	case "byte":
+		data := make([]byte, 4+rng.Intn(12))
+		rng.Read(data)
+		return base64.StdEncoding.EncodeToString(data)
+	case "password":
+		value = fmt.Sprintf("%s-%s-%d", word(), word(), rng.Intn(fakeNumberRange))
+	default:
+		words := make([]string, 1+rng.Intn(3))
+		for i := range words {
+			words[i] = word()
+		}
+		value = strings.Join(words, " ")
+	}
+
+	if minLength := int(number(schema["minLength"], 0)); len(value) < minLength {
+		padding := make([]byte, minLength-len(value))
+		for i := range padding {
+			padding[i] = byte('a' + rng.Intn(26))
+		}
+		value += string(padding)
+	}
+	if maxLength, ok := schema["maxLength"]; ok {
+		if limit := int(number(maxLength, 0)); limit < len(value) {
+			value = value[:limit]
+		}
+	}
+	return value
+}
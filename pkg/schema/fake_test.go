@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	pet := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"id", "name", "status", "tags", "owner"},
+		"properties": map[string]interface{}{
+			"id":      map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 50.0},
+			"name":    map[string]interface{}{"type": "string", "minLength": uint64(12), "maxLength": uint64(16)},
+			"status":  map[string]interface{}{"type": "string", "enum": []interface{}{"available", "pending", "sold"}},
+			"tags":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "minItems": uint64(1), "maxItems": uint64(2)},
+			"owner":   map[string]interface{}{"type": "string", "format": "email"},
+			"weight":  map[string]interface{}{"type": "number", "maximum": -1.0},
+			"born":    map[string]interface{}{"type": "string", "format": "date-time"},
+			"chip":    map[string]interface{}{"type": "string", "format": "uuid"},
+			"site":    map[string]interface{}{"type": "string", "format": "uri"},
+			"kind":    map[string]interface{}{"const": "pet"},
+			"indoors": map[string]interface{}{"type": "boolean"},
+			"collar": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string", "format": "date"},
+					map[string]interface{}{"type": "integer", "minimum": 3.0, "maximum": 3.0},
+				},
+			},
+		},
+	}
+
+	statuses := make(map[interface{}]bool)
+	optional := 0
+	for seed := int64(0); seed < 50; seed++ {
+		value := Fake(pet, rand.New(rand.NewSource(seed)))
+		require.NoError(t, Validate("pet", pet, value), "seed %d: %v", seed, value)
+
+		// A seed reproduces the same object
+		assert.Equal(t, value, Fake(pet, rand.New(rand.NewSource(seed))))
+
+		object := value.(map[string]interface{})
+		statuses[object["status"]] = true
+		if _, ok := object["chip"]; ok {
+			optional++
+		}
+	}
+	assert.Len(t, statuses, 3, "enum members vary")
+	assert.Greater(t, optional, 0, "optional properties are sometimes filled")
+	assert.Less(t, optional, 50, "optional properties are sometimes left out")
+
+	// Strings constrained by a pattern fall back to their example
+	code := map[string]interface{}{"type": "string", "pattern": "^[A-Z]{3}$", "example": "ABC"}
+	assert.Equal(t, "ABC", Fake(code, rand.New(rand.NewSource(1))))
+}
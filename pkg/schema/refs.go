@@ -0,0 +1,375 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RefMode selects how a schema's $ref references are resolved
+type RefMode string
+
+const (
+	// RefModeNone returns schemas as published
+	RefModeNone RefMode = "none"
+	// RefModeInline replaces every $ref with its target. Circular references
+	// are cut and marked with a $comment.
+	RefModeInline RefMode = "inline"
+	// RefModeBundle keeps $refs but rewrites them to point into a $defs
+	// section holding every definition the schema reaches
+	RefModeBundle RefMode = "bundle"
+)
+
+const (
+	// DefaultMaxResolvedSize caps the encoded size of a resolved schema
+	DefaultMaxResolvedSize = 256 * 1024
+
+	// maxInlineNodes stops inlining before shared references blow up exponentially
+	maxInlineNodes = 50000
+)
+
+// ParseRefMode parses a ref mode; the empty string selects RefModeNone
+func ParseRefMode(value string) (RefMode, error) {
+	switch mode := RefMode(strings.ToLower(value)); mode {
+	case "":
+		return RefModeNone, nil
+	case RefModeNone, RefModeInline, RefModeBundle:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid schema ref mode %q: must be none, inline or bundle", value)
+}
+
+// RefError reports a $ref that cannot be resolved
+type RefError struct {
+	Ref    string
+	Reason string
+}
+
+// Error implements error
+func (e *RefError) Error() string {
+	return fmt.Sprintf("cannot resolve $ref %q: %s", e.Ref, e.Reason)
+}
+
+// SizeError reports a resolved schema over the size cap
+type SizeError struct {
+	Mode  RefMode
+	Limit int
+}
+
+// Error implements error
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("schema resolved in %s mode exceeds %d bytes", e.Mode, e.Limit)
+}
+
+// ResolveOptions controls ref resolution
+type ResolveOptions struct {
+	Mode    RefMode
+	MaxSize int // Encoded bytes; zero selects DefaultMaxResolvedSize
+}
+
+// ResolveRefs resolves the $refs of a schema. Local refs ("#/...") are JSON
+// pointers into the schema itself or, failing that, into root, the document
+// the schema was published in, e.g. a tool's metadata schema. Remote refs are
+// not fetched and fail with *RefError.
+func ResolveRefs(schema, root map[string]interface{}, options ResolveOptions) (map[string]interface{}, error) {
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultMaxResolvedSize
+	}
+	resolver := &refResolver{schema: schema, root: root, maxSize: options.MaxSize}
+
+	var resolved map[string]interface{}
+	switch options.Mode {
+	case RefModeNone, "":
+		return schema, nil
+	case RefModeInline:
+		value, err := resolver.inline(schema, nil)
+		if err != nil {
+			return nil, err
+		}
+		resolved, _ = value.(map[string]interface{})
+	case RefModeBundle:
+		var err error
+		if resolved, err = resolver.bundle(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid schema ref mode %q", options.Mode)
+	}
+
+	encoded, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resolved schema: %w", err)
+	}
+	if len(encoded) > options.MaxSize {
+		return nil, &SizeError{Mode: options.Mode, Limit: options.MaxSize}
+	}
+	return resolved, nil
+}
+
+// refResolver resolves the refs of one schema
+type refResolver struct {
+	schema  map[string]interface{}
+	root    map[string]interface{}
+	maxSize int
+	nodes   int // Nodes copied while inlining
+}
+
+// lookup finds the target of a local ref
+func (r *refResolver) lookup(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, &RefError{Ref: ref, Reason: "only local references are supported"}
+	}
+	pointer := ref[1:]
+	if unescaped, err := url.PathUnescape(pointer); err == nil {
+		pointer = unescaped
+	}
+	for _, document := range []interface{}{r.schema, r.root} {
+		if document == nil {
+			continue
+		}
+		if target, ok := resolvePointer(document, pointer); ok {
+			return target, nil
+		}
+	}
+	return nil, &RefError{Ref: ref, Reason: "target not found"}
+}
+
+// resolvePointer follows a JSON pointer through a decoded document
+func resolvePointer(document interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return document, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	current := document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch value := current.(type) {
+		case map[string]interface{}:
+			next, ok := value[token]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			current = value[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// refOf returns the $ref of a schema object, if any
+func refOf(value interface{}) (string, bool) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	ref, ok := object["$ref"].(string)
+	return ref, ok
+}
+
+// inline copies value with every ref replaced by its target. stack holds the
+// refs being expanded, so a ref reached again through itself is circular.
+func (r *refResolver) inline(value interface{}, stack []string) (interface{}, error) {
+	r.nodes++
+	if r.nodes > maxInlineNodes {
+		return nil, &SizeError{Mode: RefModeInline, Limit: r.maxSize}
+	}
+
+	if ref, ok := refOf(value); ok {
+		for _, expanding := range stack {
+			if expanding == ref {
+				return map[string]interface{}{"$comment": "circular reference to " + ref + " omitted"}, nil
+			}
+		}
+		target, err := r.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		resolved, err := r.inline(target, append(stack, ref))
+		if err != nil {
+			return nil, err
+		}
+		// Keywords next to the $ref (e.g. a description) refine the target
+		if object, ok := resolved.(map[string]interface{}); ok {
+			for key, sibling := range value.(map[string]interface{}) {
+				if key == "$ref" {
+					continue
+				}
+				if object[key], err = r.inline(sibling, stack); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return resolved, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			// Definitions are only reachable through refs, which are now inlined
+			if key == "$defs" || key == "definitions" {
+				continue
+			}
+			resolved, err := r.inline(field, stack)
+			if err != nil {
+				return nil, err
+			}
+			copied[key] = resolved
+		}
+		return copied, nil
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := r.inline(item, stack)
+			if err != nil {
+				return nil, err
+			}
+			copied[i] = resolved
+		}
+		return copied, nil
+	}
+	return value, nil
+}
+
+// bundle collects every definition the schema reaches into $defs and points
+// all refs there
+func (r *refResolver) bundle() (map[string]interface{}, error) {
+	names := make(map[string]string) // Original ref -> $defs name
+	used := make(map[string]bool)
+	var pending []string
+	targets := make(map[string]interface{})
+
+	// Assign names breadth-first, so definitions referencing each other are found
+	collect := func(value interface{}) error {
+		var walkErr error
+		walkRefs(value, func(ref string) {
+			if walkErr != nil {
+				return
+			}
+			if _, seen := names[ref]; seen {
+				return
+			}
+			target, err := r.lookup(ref)
+			if err != nil {
+				walkErr = err
+				return
+			}
+			names[ref] = uniqueDefName(ref, used)
+			targets[ref] = target
+			pending = append(pending, ref)
+		})
+		return walkErr
+	}
+	if err := collect(r.schema); err != nil {
+		return nil, err
+	}
+	for len(pending) > 0 {
+		ref := pending[0]
+		pending = pending[1:]
+		if err := collect(targets[ref]); err != nil {
+			return nil, err
+		}
+	}
+
+	rewrite := func(value interface{}) interface{} {
+		return rewriteRefs(value, names)
+	}
+	bundled, _ := rewrite(r.schema).(map[string]interface{})
+	delete(bundled, "definitions")
+	if len(names) == 0 {
+		delete(bundled, "$defs")
+		return bundled, nil
+	}
+	defs := make(map[string]interface{}, len(names))
+	refs := make([]string, 0, len(names))
+	for ref := range names {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		definition := rewrite(targets[ref])
+		if object, ok := definition.(map[string]interface{}); ok {
+			delete(object, "$defs")
+			delete(object, "definitions")
+		}
+		defs[names[ref]] = definition
+	}
+	bundled["$defs"] = defs
+	return bundled, nil
+}
+
+// uniqueDefName derives a $defs name from the last token of a ref
+func uniqueDefName(ref string, used map[string]bool) string {
+	base := ref[strings.LastIndex(ref, "/")+1:]
+	if base == "" || base == "#" {
+		base = "root"
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+// walkRefs calls fn for every $ref in a value, outside of definition sections.
+// Keys are visited in order, so $defs names are stable.
+func walkRefs(value interface{}, fn func(ref string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			fn(ref)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if key != "$defs" && key != "definitions" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkRefs(v[key], fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkRefs(item, fn)
+		}
+	}
+}
+
+// rewriteRefs copies a value with its refs pointed into $defs
+func rewriteRefs(value interface{}, names map[string]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			if key == "$defs" || key == "definitions" {
+				continue
+			}
+			copied[key] = rewriteRefs(field, names)
+		}
+		if ref, ok := v["$ref"].(string); ok {
+			copied["$ref"] = "#/$defs/" + names[ref]
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = rewriteRefs(item, names)
+		}
+		return copied
+	}
+	return value
+}
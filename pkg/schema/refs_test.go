@@ -0,0 +1,116 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRefs(t *testing.T) {
+	// A pet names its owner, who owns pets in turn
+	input := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"pet":  map[string]interface{}{"$ref": "#/$defs/Pet", "description": "The pet to store"},
+			"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/Tag"}},
+		},
+		"$defs": map[string]interface{}{
+			"Pet": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":  map[string]interface{}{"type": "string"},
+					"owner": map[string]interface{}{"$ref": "#/$defs/Owner"},
+				},
+			},
+			"Owner": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"pets": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/Pet"}},
+				},
+			},
+		},
+	}
+	// Refs the schema does not define resolve against the document it came from
+	root := map[string]interface{}{
+		"input":      input,
+		"components": map[string]interface{}{"schemas": map[string]interface{}{"Tag": map[string]interface{}{"type": "string"}}},
+	}
+
+	unchanged, err := ResolveRefs(input, root, ResolveOptions{Mode: RefModeNone})
+	require.NoError(t, err)
+	assert.Equal(t, input, unchanged)
+
+	inlined, err := ResolveRefs(input, root, ResolveOptions{Mode: RefModeInline})
+	require.NoError(t, err)
+	assert.NotContains(t, inlined, "$defs")
+	pet := inlined["properties"].(map[string]interface{})["pet"].(map[string]interface{})
+	assert.Equal(t, "The pet to store", pet["description"])
+	owner := pet["properties"].(map[string]interface{})["owner"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"$comment": "circular reference to #/$defs/Pet omitted"},
+		owner["properties"].(map[string]interface{})["pets"].(map[string]interface{})["items"])
+	assert.Equal(t, map[string]interface{}{"type": "string"},
+		inlined["properties"].(map[string]interface{})["tags"].(map[string]interface{})["items"])
+
+	bundled, err := ResolveRefs(input, root, ResolveOptions{Mode: RefModeBundle})
+	require.NoError(t, err)
+	assert.Equal(t, "#/$defs/Tag", bundled["properties"].(map[string]interface{})["tags"].(map[string]interface{})["items"].(map[string]interface{})["$ref"])
+	defs := bundled["$defs"].(map[string]interface{})
+	assert.Len(t, defs, 3)
+	assert.Equal(t, "#/$defs/Pet", defs["Owner"].(map[string]interface{})["properties"].(map[string]interface{})["pets"].(map[string]interface{})["items"].(map[string]interface{})["$ref"])
+	// The input is not modified
+	assert.Equal(t, "#/components/schemas/Tag", input["properties"].(map[string]interface{})["tags"].(map[string]interface{})["items"].(map[string]interface{})["$ref"])
+
+	// Distinct definitions sharing a name are kept apart
+	clash := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"a": map[string]interface{}{"$ref": "#/$defs/Item"},
+			"b": map[string]interface{}{"$ref": "#/components/schemas/Item"},
+		},
+		"$defs": map[string]interface{}{"Item": map[string]interface{}{"type": "integer"}},
+	}
+	root["components"].(map[string]interface{})["schemas"].(map[string]interface{})["Item"] = map[string]interface{}{"type": "string"}
+	bundled, err = ResolveRefs(clash, root, ResolveOptions{Mode: RefModeBundle})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"Item":   map[string]interface{}{"type": "integer"},
+		"Item_2": map[string]interface{}{"type": "string"},
+	}, bundled["$defs"])
+}
+
+func TestResolveRefsErrors(t *testing.T) {
+	var refErr *RefError
+	_, err := ResolveRefs(map[string]interface{}{"$ref": "#/$defs/Missing"}, nil, ResolveOptions{Mode: RefModeInline})
+	require.ErrorAs(t, err, &refErr)
+	assert.Equal(t, "#/$defs/Missing", refErr.Ref)
+
+	_, err = ResolveRefs(map[string]interface{}{"$ref": "https://example.com/pet.json"}, nil, ResolveOptions{Mode: RefModeBundle})
+	require.ErrorAs(t, err, &refErr)
+
+	// Shared references multiply when inlined; bundling keeps one copy each
+	layered := map[string]interface{}{"$defs": map[string]interface{}{
+		"L0": map[string]interface{}{"type": "string", "description": "a leaf of some length to take up space"},
+	}}
+	defs := layered["$defs"].(map[string]interface{})
+	for i := 1; i <= 12; i++ {
+		below := map[string]interface{}{"$ref": fmt.Sprintf("#/$defs/L%d", i-1)}
+		defs[fmt.Sprintf("L%d", i)] = map[string]interface{}{"properties": map[string]interface{}{"left": below, "right": below}}
+	}
+	layered["$ref"] = "#/$defs/L12"
+	var sizeErr *SizeError
+	_, err = ResolveRefs(layered, nil, ResolveOptions{Mode: RefModeInline, MaxSize: 64 * 1024})
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, RefModeInline, sizeErr.Mode)
+	_, err = ResolveRefs(layered, nil, ResolveOptions{Mode: RefModeBundle, MaxSize: 64 * 1024})
+	assert.NoError(t, err)
+
+	mode, err := ParseRefMode("")
+	require.NoError(t, err)
+	assert.Equal(t, RefModeNone, mode)
+	mode, err = ParseRefMode("Inline")
+	require.NoError(t, err)
+	assert.Equal(t, RefModeInline, mode)
+	_, err = ParseRefMode("deep")
+	assert.Error(t, err)
+}
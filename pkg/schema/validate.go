@@ -0,0 +1,327 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldError describes one way a value fails a schema
+type FieldError struct {
+	Field   string `json:"field"` // JSON pointer to the offending value; empty for the root
+	Message string `json:"message"`
+}
+
+// ValidationError reports every field that fails a schema
+type ValidationError struct {
+	Subject string       `json:"subject"` // What was validated, e.g. "input" or "output"
+	Errors  []FieldError `json:"errors"`
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		if fieldErr.Field == "" {
+			messages = append(messages, fieldErr.Message)
+		} else {
+			messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+		}
+	}
+	return fmt.Sprintf("%s failed schema validation: %s", e.Subject, strings.Join(messages, "; "))
+}
+
+// Validate checks a decoded JSON value against a JSON Schema and returns a
+// *ValidationError listing every failing field, or nil when the value is valid.
+// The supported keywords are the ones tool schemas use: type, nullable,
+// required, properties, additionalProperties, items, enum, const, numeric and
+// length bounds, pattern, format and the oneOf/anyOf/allOf combinators.
+func Validate(subject string, schema map[string]interface{}, value interface{}) error {
+	var errs []FieldError
+	validate(schema, value, "", &errs, 0)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Subject: subject, Errors: errs}
+}
+
+func validate(schema map[string]interface{}, value interface{}, path string, errs *[]FieldError, depth int) {
+	if schema == nil || depth > maxExampleDepth*2 {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil && nullable(schema) {
+		return
+	}
+
+	if declared := declaredTypes(schema); len(declared) > 0 && !matchesAnyType(declared, value) {
+		fail("expected %s, got %s", strings.Join(declared, " or "), jsonType(value))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 && !containsValue(enum, value) {
+		fail("must be one of %v", enum)
+	}
+	if constant, exists := schema["const"]; exists && !equalValues(constant, value) {
+		fail("must equal %v", constant)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(schema, v, path, errs, depth)
+	case []interface{}:
+		if minItems, ok := schema["minItems"]; ok && float64(len(v)) < number(minItems, 0) {
+			fail("must have at least %v items", minItems)
+		}
+		if maxItems, ok := schema["maxItems"]; ok && float64(len(v)) > number(maxItems, 0) {
+			fail("must have at most %v items", maxItems)
+		}
+		if items := schemaMap(schema["items"]); items != nil {
+			for i, item := range v {
+				validate(items, item, fmt.Sprintf("%s/%d", path, i), errs, depth+1)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if minLength, ok := schema["minLength"]; ok && length < number(minLength, 0) {
+			fail("must be at least %v characters", minLength)
+		}
+		if maxLength, ok := schema["maxLength"]; ok && length > number(maxLength, 0) {
+			fail("must be at most %v characters", maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("must match pattern %s", pattern)
+			}
+		}
+		if format, ok := schema["format"].(string); ok && !validFormat(format, v) {
+			fail("must be a valid %s", format)
+		}
+	default:
+		if n, ok := toFloat(value); ok {
+			if minimum, exists := schema["minimum"]; exists && n < number(minimum, 0) {
+				fail("must be >= %v", minimum)
+			}
+			if maximum, exists := schema["maximum"]; exists && n > number(maximum, 0) {
+				fail("must be <= %v", maximum)
+			}
+		}
+	}
+
+	validateCombinators(schema, value, path, errs, depth)
+}
+
+// validateObject checks required, declared and undeclared properties
+func validateObject(schema map[string]interface{}, object map[string]interface{}, path string, errs *[]FieldError, depth int) {
+	properties := schemaMap(schema["properties"])
+	for _, name := range RequiredFields(schema) {
+		if _, exists := object[name]; !exists {
+			*errs = append(*errs, FieldError{Field: path + "/" + escapePointer(name), Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldPath := path + "/" + escapePointer(name)
+		if property, declared := properties[name]; declared {
+			validate(schemaMap(property), object[name], fieldPath, errs, depth+1)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, FieldError{Field: fieldPath, Message: "is not a recognised property"})
+			}
+		case map[string]interface{}:
+			validate(additional, object[name], fieldPath, errs, depth+1)
+		}
+	}
+}
+
+// validateCombinators applies allOf, anyOf and oneOf
+func validateCombinators(schema map[string]interface{}, value interface{}, path string, errs *[]FieldError, depth int) {
+	for _, part := range schemaList(schema["allOf"]) {
+		validate(part, value, path, errs, depth+1)
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		alternatives := schemaList(schema[keyword])
+		if len(alternatives) == 0 {
+			continue
+		}
+		matches := 0
+		for _, alternative := range alternatives {
+			var altErrs []FieldError
+			validate(alternative, value, path, &altErrs, depth+1)
+			if len(altErrs) == 0 {
+				matches++
+			}
+		}
+		switch {
+		case matches == 0:
+			*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf("does not match any %s alternative", keyword)})
+		case keyword == "oneOf" && matches > 1:
+			*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf("matches %d oneOf alternatives, expected exactly one", matches)})
+		}
+	}
+}
+
+// declaredTypes returns the schema's type keyword as a list
+func declaredTypes(schema map[string]interface{}) []string {
+	switch declared := schema["type"].(type) {
+	case string:
+		return []string{declared}
+	case []string:
+		return declared
+	case []interface{}:
+		types := make([]string, 0, len(declared))
+		for _, entry := range declared {
+			if s, ok := entry.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// nullable reports whether null is allowed, via OpenAPI nullable or a null type
+func nullable(schema map[string]interface{}) bool {
+	if allowed, ok := schema["nullable"].(bool); ok && allowed {
+		return true
+	}
+	for _, declared := range declaredTypes(schema) {
+		if declared == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAnyType reports whether a value has one of the JSON types
+func matchesAnyType(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, declared := range types {
+		if declared == actual || (declared == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a decoded value. Whole numbers are integers.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		if n, ok := toFloat(v); ok {
+			if n == math.Trunc(n) && !math.IsInf(n, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// toFloat converts any Go numeric value to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// containsValue reports whether a list holds a value, comparing numbers by value
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, candidate := range list {
+		if equalValues(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares scalars, treating numeric types as interchangeable
+func equalValues(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return fmt.Sprintf("%T:%v", a, a) == fmt.Sprintf("%T:%v", b, b)
+}
+
+// validFormat checks the string formats tool schemas commonly declare.
+// Unknown formats are accepted.
+func validFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "uri", "url":
+		parsed, err := url.Parse(value)
+		return err == nil && parsed.Scheme != ""
+	case "uuid":
+		_, err := uuid.Parse(value)
+		return err == nil && len(value) == 36
+	}
+	return true
+}
+
+// escapePointer escapes a property name for use in a JSON pointer (RFC 6901)
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
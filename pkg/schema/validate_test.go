@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	input := map[string]interface{}{
+		"type":                 "object",
+		"required":             []interface{}{"status", "owner"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"status":  map[string]interface{}{"type": "string", "enum": []interface{}{"available", "sold"}},
+			"owner":   map[string]interface{}{"type": "string", "format": "email"},
+			"tags":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string", "minLength": 2}, "maxItems": 3},
+			"limit":   map[string]interface{}{"type": "integer", "minimum": 1.0},
+			"created": map[string]interface{}{"type": "string", "format": "date-time", "nullable": true},
+			"id": map[string]interface{}{"oneOf": []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string", "format": "uuid"},
+			}},
+		},
+	}
+
+	// Example values are always valid
+	assert.NoError(t, Validate("input", input, Example(input, ExampleOptions{IncludeOptional: true})))
+	assert.NoError(t, Validate("input", input, map[string]interface{}{
+		"status": "sold", "owner": "user@example.com", "limit": 3.0, "created": nil, "id": 42.0,
+	}))
+
+	err := Validate("input", input, map[string]interface{}{
+		"status":  "lost",
+		"tags":    []interface{}{"a", "ok", 7.0},
+		"limit":   1.5,
+		"created": "yesterday",
+		"id":      "not-a-uuid",
+		"extra":   true,
+	})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "input", validationErr.Subject)
+	assert.Equal(t, []FieldError{
+		{Field: "/owner", Message: "is required"},
+		{Field: "/created", Message: "must be a valid date-time"},
+		{Field: "/extra", Message: "is not a recognised property"},
+		{Field: "/id", Message: "does not match any oneOf alternative"},
+		{Field: "/limit", Message: "expected integer, got number"},
+		{Field: "/status", Message: "must be one of [available sold]"},
+		{Field: "/tags/0", Message: "must be at least 2 characters"},
+		{Field: "/tags/2", Message: "expected string, got integer"},
+	}, validationErr.Errors)
+	assert.Contains(t, err.Error(), "input failed schema validation: /owner: is required")
+
+	// Go values are accepted as well as decoded JSON
+	assert.NoError(t, Validate("output", map[string]interface{}{"type": "integer", "maximum": 10}, 7))
+	assert.Error(t, Validate("output", map[string]interface{}{"type": "object"}, "text"))
+	assert.NoError(t, Validate("output", nil, "anything"))
+}
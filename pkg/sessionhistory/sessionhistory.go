@@ -0,0 +1,158 @@
+// Package sessionhistory keeps the most recent tool invocations of each agent
+// session: which tool ran, how it ended, how long it took and why it failed.
+// Histories are capped per session and stored in BoltDB, one JSON list per
+// session, so they outlive the process that recorded them.
+package sessionhistory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// sessionsBucket holds the histories keyed by session ID
+const sessionsBucket = "session_history"
+
+// DefaultSize is how many invocations a history keeps per session
+const DefaultSize = 20
+
+// Entry is one invocation of a session
+type Entry struct {
+	ToolName  string    `json:"tool_name"`
+	InvokedAt time.Time `json:"invoked_at"`
+	Status    string    `json:"status"` // As named by the agent protocol, e.g. TOOL_INVOCATION_STATUS_SUCCESS
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Store keeps the histories. It is safe for concurrent use.
+type Store struct {
+	db       *bolt.DB // Nil for stores held only in memory
+	size     int
+	mu       sync.Mutex
+	sessions map[string][]Entry // Histories of in-memory stores, oldest first
+}
+
+// Open opens or creates the history store at path, keeping size invocations
+// per session. A size below one selects DefaultSize.
+func Open(path string, size int) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create session history directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session history: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(sessionsBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize session history: %w", err)
+	}
+	store := OpenMemory(size)
+	store.db = db
+	return store, nil
+}
+
+// OpenMemory creates an empty history store that is never written to disk
+func OpenMemory(size int) *Store {
+	if size < 1 {
+		size = DefaultSize
+	}
+	return &Store{size: size, sessions: make(map[string][]Entry)}
+}
+
+// Close releases the BoltDB file
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Append adds an invocation to a session's history, dropping the oldest
+// once the history is full
+func (s *Store) Append(sessionID string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		s.sessions[sessionID] = s.capped(append(s.sessions[sessionID], entry))
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sessionsBucket))
+		history, err := decode(bucket.Get([]byte(sessionID)))
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(s.capped(append(history, entry)))
+		if err != nil {
+			return fmt.Errorf("failed to encode session history: %w", err)
+		}
+		return bucket.Put([]byte(sessionID), data)
+	})
+}
+
+// Recent returns a session's history, newest first
+func (s *Store) Recent(sessionID string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := s.sessions[sessionID]
+	if s.db != nil {
+		err := s.db.View(func(tx *bolt.Tx) error {
+			var err error
+			history, err = decode(tx.Bucket([]byte(sessionsBucket)).Get([]byte(sessionID)))
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	recent := make([]Entry, len(history))
+	for i, entry := range history {
+		recent[len(history)-1-i] = entry
+	}
+	return recent, nil
+}
+
+// Delete forgets a session's history
+func (s *Store) Delete(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(sessionsBucket)).Delete([]byte(sessionID))
+	})
+}
+
+// capped drops the oldest entries beyond the store's size
+func (s *Store) capped(history []Entry) []Entry {
+	if len(history) > s.size {
+		history = history[len(history)-s.size:]
+	}
+	return history
+}
+
+// decode reads a stored history
+func decode(data []byte) ([]Entry, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var history []Entry
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to decode session history: %w", err)
+	}
+	return history, nil
+}
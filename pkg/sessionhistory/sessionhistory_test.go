@@ -0,0 +1,52 @@
+package sessionhistory
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	disk, err := Open(path, 3)
+	require.NoError(t, err)
+
+	for name, store := range map[string]*Store{"bolt": disk, "memory": OpenMemory(3)} {
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+			for i, tool := range []string{"a", "b", "c", "d"} {
+				require.NoError(t, store.Append("session-1", Entry{ToolName: tool, InvokedAt: start.Add(time.Duration(i) * time.Minute), Status: "success"}))
+			}
+			require.NoError(t, store.Append("session-2", Entry{ToolName: "e", Status: "failed", Error: "boom", LatencyMs: 12}))
+
+			recent, err := store.Recent("session-1")
+			require.NoError(t, err)
+			require.Len(t, recent, 3, "oldest dropped beyond the size")
+			assert.Equal(t, "d", recent[0].ToolName, "newest first")
+			assert.Equal(t, "b", recent[2].ToolName)
+			assert.Equal(t, start.Add(3*time.Minute), recent[0].InvokedAt)
+
+			other, err := store.Recent("session-2")
+			require.NoError(t, err)
+			assert.Equal(t, []Entry{{ToolName: "e", Status: "failed", Error: "boom", LatencyMs: 12}}, other)
+
+			require.NoError(t, store.Delete("session-1"))
+			recent, err = store.Recent("session-1")
+			require.NoError(t, err)
+			assert.Empty(t, recent)
+		})
+	}
+
+	// Histories survive a restart
+	require.NoError(t, disk.Close())
+	reopened, err := Open(path, 0)
+	require.NoError(t, err)
+	defer reopened.Close()
+	recent, err := reopened.Recent("session-2")
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "e", recent[0].ToolName)
+}
@@ -0,0 +1,94 @@
+package tooltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// UpdateGoldenEnv names the environment variable that, when set to a
+// non-empty value, rewrites golden files instead of comparing against them
+const UpdateGoldenEnv = "AIONMCP_UPDATE_GOLDEN"
+
+// AssertGolden compares got with the golden file at path. Run the test with
+// AIONMCP_UPDATE_GOLDEN=1 to create or refresh the file.
+func AssertGolden(t testing.TB, path string, got []byte) {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (set %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("%s does not match (set %s=1 to update it):\n%s", path, UpdateGoldenEnv, firstDifference(string(want), string(got)))
+	}
+}
+
+// AssertGoldenJSON compares a value, encoded as indented JSON with sorted
+// object keys, with the golden file at path
+func AssertGoldenJSON(t testing.TB, path string, value any) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode golden value: %v", err)
+	}
+	AssertGolden(t, path, append(data, '\n'))
+}
+
+// AssertGoldenSchema compares a tool's schema with the golden file at path
+func AssertGoldenSchema(t testing.TB, path string, tool types.Tool) {
+	t.Helper()
+	AssertGoldenJSON(t, path, tool.Metadata().Schema)
+}
+
+// AssertGoldenTools compares the names, descriptions and schemas of tools,
+// such as everything an importer generated from a spec, with one golden file
+func AssertGoldenTools(t testing.TB, path string, tools []types.Tool) {
+	t.Helper()
+
+	type goldenTool struct {
+		Description string         `json:"description"`
+		Schema      map[string]any `json:"schema,omitempty"`
+	}
+	golden := make(map[string]goldenTool, len(tools))
+	for _, tool := range tools {
+		golden[tool.Name()] = goldenTool{Description: tool.Description(), Schema: tool.Metadata().Schema}
+	}
+	AssertGoldenJSON(t, path, golden)
+}
+
+// firstDifference describes the first line where two texts differ
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var wantLine, gotLine string
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if wantLine != gotLine {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, wantLine, gotLine)
+		}
+	}
+	return "texts differ"
+}
@@ -0,0 +1,205 @@
+package tooltest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// DefaultSource is the source of tools registered without one
+const DefaultSource = "test"
+
+// Registry is an in-memory types.ToolRegistry that behaves like the server's
+// registry without its middleware: no validation, timeouts, caching or
+// circuit breakers. Listings are sorted by tool name.
+type Registry struct {
+	mu       sync.RWMutex
+	tools    map[string]types.Tool
+	versions map[string]string
+	sources  map[string]string
+}
+
+// NewRegistry creates a registry holding tools under DefaultSource
+func NewRegistry(tools ...types.Tool) *Registry {
+	registry := &Registry{
+		tools:    make(map[string]types.Tool),
+		versions: make(map[string]string),
+		sources:  make(map[string]string),
+	}
+	for _, tool := range tools {
+		registry.tools[tool.Name()] = tool
+		registry.sources[tool.Name()] = DefaultSource
+	}
+	return registry
+}
+
+// Get returns a tool by name
+func (r *Registry) Get(name string) (types.Tool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, exists := r.tools[name]
+	if !exists {
+		return nil, fmt.Errorf("tool '%s' not found", name)
+	}
+	return tool, nil
+}
+
+// ListTools returns metadata for all registered tools
+func (r *Registry) ListTools() []types.ToolMetadata {
+	return r.list(func(string) bool { return true })
+}
+
+// Count returns the number of registered tools
+func (r *Registry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.tools)
+}
+
+// Register adds a tool under the "unknown" source, as the server does
+func (r *Registry) Register(tool types.Tool) error {
+	return r.RegisterWithSource(tool, "unknown", "")
+}
+
+// RegisterWithSource adds or replaces a tool
+func (r *Registry) RegisterWithSource(tool types.Tool, sourceID, version string) error {
+	if tool.Name() == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[tool.Name()] = tool
+	r.versions[tool.Name()] = version
+	r.sources[tool.Name()] = sourceID
+	return nil
+}
+
+// RegisterBatch adds tools from one source, registering none if any is invalid
+func (r *Registry) RegisterBatch(tools []types.Tool, sourceID string) error {
+	for _, tool := range tools {
+		if tool.Name() == "" {
+			return fmt.Errorf("tool name cannot be empty")
+		}
+	}
+	for _, tool := range tools {
+		if err := r.RegisterWithSource(tool, sourceID, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unregister removes a tool
+func (r *Registry) Unregister(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[name]; !exists {
+		return fmt.Errorf("tool '%s' not found", name)
+	}
+	delete(r.tools, name)
+	delete(r.versions, name)
+	delete(r.sources, name)
+	return nil
+}
+
+// UnregisterBySource removes every tool of a source
+func (r *Registry) UnregisterBySource(sourceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, source := range r.sources {
+		if source == sourceID {
+			delete(r.tools, name)
+			delete(r.versions, name)
+			delete(r.sources, name)
+		}
+	}
+	return nil
+}
+
+// GetVersion returns the version a tool was registered with
+func (r *Registry) GetVersion(name string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.tools[name]; !exists {
+		return "", fmt.Errorf("tool '%s' not found", name)
+	}
+	return r.versions[name], nil
+}
+
+// GetSource returns the source a tool was registered from
+func (r *Registry) GetSource(name string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	source, exists := r.sources[name]
+	if !exists {
+		return "", fmt.Errorf("tool '%s' not found", name)
+	}
+	return source, nil
+}
+
+// ListToolsBySource returns metadata for the tools of a source
+func (r *Registry) ListToolsBySource(sourceID string) []types.ToolMetadata {
+	return r.list(func(name string) bool { return r.sources[name] == sourceID })
+}
+
+// GetToolSources returns the sources with registered tools, sorted
+func (r *Registry) GetToolSources() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sourceList()
+}
+
+// GetRegistryStats returns the statistics the server's registry reports
+func (r *Registry) GetRegistryStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bySource := make(map[string]int)
+	for _, source := range r.sources {
+		bySource[source]++
+	}
+	return map[string]interface{}{
+		"total_tools":     len(r.tools),
+		"sources":         r.sourceList(),
+		"tools_by_source": bySource,
+		"event_handlers":  0,
+	}
+}
+
+// list returns the metadata of matching tools sorted by name
+func (r *Registry) list(match func(name string) bool) []types.ToolMetadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]types.ToolMetadata, 0, len(r.tools))
+	for name, tool := range r.tools {
+		if !match(name) {
+			continue
+		}
+		tools = append(tools, tool.Metadata())
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// sourceList returns the distinct sources sorted. Callers hold r.mu.
+func (r *Registry) sourceList() []string {
+	seen := make(map[string]bool)
+	sources := []string{}
+	for _, source := range r.sources {
+		if !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
@@ -0,0 +1,95 @@
+package tooltest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aionmcp/aionmcp/pkg/agent"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"go.uber.org/zap"
+)
+
+// Simulator invokes tools the way a connected agent does: through a real
+// agent server with a registered session, so parameters and results take the
+// same JSON round trip as in production.
+type Simulator struct {
+	Registry  *Registry
+	Server    *agent.AgentServer
+	SessionID string
+
+	t testing.TB
+}
+
+// NewSimulator registers tools in a fresh Registry and opens an agent session
+func NewSimulator(t testing.TB, tools ...types.Tool) *Simulator {
+	t.Helper()
+
+	registry := NewRegistry(tools...)
+	server := agent.NewAgentServer(zap.NewNop(), registry)
+	session, err := server.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		AgentId:   "tooltest",
+		AgentName: "tooltest simulator",
+	})
+	if err != nil {
+		t.Fatalf("failed to register simulator agent: %v", err)
+	}
+	return &Simulator{Registry: registry, Server: server, SessionID: session.SessionId, t: t}
+}
+
+// Invoke calls a tool with parameters, which are encoded as JSON. Errors are
+// the server's: a missing tool or invalid parameters. Tool failures come back
+// as a response with a FAILED status and an Error.
+func (s *Simulator) Invoke(ctx context.Context, name string, params any) (*agentpb.InvokeToolResponse, error) {
+	s.t.Helper()
+
+	parameters := ""
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			s.t.Fatalf("failed to encode parameters: %v", err)
+		}
+		parameters = string(data)
+	}
+	return s.Server.InvokeTool(ctx, &agentpb.InvokeToolRequest{
+		SessionId:      s.SessionID,
+		ToolName:       name,
+		ParametersJson: parameters,
+	})
+}
+
+// Result invokes a tool, failing the test unless it succeeds, and decodes
+// its result into target
+func (s *Simulator) Result(name string, params, target any) {
+	s.t.Helper()
+
+	response, err := s.Invoke(context.Background(), name, params)
+	if err != nil {
+		s.t.Fatalf("invoking %s failed: %v", name, err)
+	}
+	if response.Status != agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS {
+		s.t.Fatalf("invoking %s returned %s: %s", name, response.Status, response.GetError().GetMessage())
+	}
+	if target == nil {
+		return
+	}
+	if err := json.Unmarshal([]byte(response.ResultJson), target); err != nil {
+		s.t.Fatalf("failed to decode result of %s: %v", name, err)
+	}
+}
+
+// Failure invokes a tool, failing the test unless the tool reports an error,
+// and returns that error
+func (s *Simulator) Failure(name string, params any) *agentpb.ToolError {
+	s.t.Helper()
+
+	response, err := s.Invoke(context.Background(), name, params)
+	if err != nil {
+		s.t.Fatalf("invoking %s failed: %v", name, err)
+	}
+	if response.Status == agentpb.ToolInvocationStatus_TOOL_INVOCATION_STATUS_SUCCESS || response.Error == nil {
+		s.t.Fatalf("invoking %s succeeded, expected a tool error", name)
+	}
+	return response.Error
+}
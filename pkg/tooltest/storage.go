@@ -0,0 +1,323 @@
+package tooltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewLearningStorage creates an empty in-memory learning storage. It returns
+// records exactly as the boltdb driver does, without touching disk.
+func NewLearningStorage() *learning.MemoryStorage {
+	return learning.NewMemoryStorage()
+}
+
+// StorageConformance checks the behaviour every learning storage backend
+// must share, so a driver registered with learning.RegisterStorage can
+// replace another without changing what the engine sees. open is called for
+// each subtest and must return an empty storage; the suite closes it.
+func StorageConformance(t *testing.T, open func(t *testing.T) learning.Storage) {
+	base := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	execution := func(id, tool string, minute int, success bool) learning.ExecutionRecord {
+		record := learning.ExecutionRecord{
+			ID:        id,
+			ToolName:  tool,
+			Timestamp: base.Add(time.Duration(minute) * time.Minute),
+			Duration:  time.Duration(minute+1) * time.Millisecond,
+			Success:   success,
+			Context:   map[string]interface{}{"session_id": "s-" + id},
+		}
+		if !success {
+			record.ErrorType = "timeout"
+			record.Error = "upstream timed out"
+		}
+		return record
+	}
+	ids := func(records []learning.ExecutionRecord) []string {
+		result := make([]string, len(records))
+		for i, record := range records {
+			result[i] = record.ID
+		}
+		return result
+	}
+	fresh := func(t *testing.T) (context.Context, learning.Storage) {
+		storage := open(t)
+		t.Cleanup(func() { storage.Close() })
+		ctx := context.Background()
+		for i, record := range []learning.ExecutionRecord{
+			execution("e0", "search", 0, true),
+			execution("e1", "fetch", 1, true),
+			execution("e2", "search", 2, false),
+			execution("e3", "search", 3, true),
+			execution("e4", "fetch", 4, true),
+		} {
+			require.NoError(t, storage.StoreExecution(ctx, record), "record %d", i)
+		}
+		return ctx, storage
+	}
+
+	t.Run("Executions", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		record, err := storage.GetExecution(ctx, "e2")
+		require.NoError(t, err)
+		assert.Equal(t, "search", record.ToolName)
+		assert.False(t, record.Success)
+		assert.Equal(t, "timeout", record.ErrorType)
+		assert.True(t, base.Add(2*time.Minute).Equal(record.Timestamp))
+		assert.Equal(t, "s-e2", record.Context["session_id"])
+		_, err = storage.GetExecution(ctx, "missing")
+		assert.Error(t, err)
+
+		// Per-tool queries return the newest records first
+		records, err := storage.GetExecutionsByTool(ctx, "search", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e3", "e2"}, ids(records))
+		records, err = storage.GetExecutionsByTool(ctx, "search", 0)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+
+		// Time ranges include both bounds and return the oldest records first
+		records, err = storage.GetExecutionsByTimeRange(ctx, base.Add(time.Minute), base.Add(3*time.Minute), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e1", "e2", "e3"}, ids(records))
+		records, err = storage.GetExecutionsByTimeRange(ctx, base, base.Add(time.Hour), 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e0", "e1"}, ids(records))
+	})
+
+	t.Run("IterateExecutions", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		var visited []string
+		err := storage.IterateExecutions(ctx, base.Add(time.Minute), base.Add(time.Hour), func(record learning.ExecutionRecord) error {
+			visited = append(visited, record.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e1", "e2", "e3", "e4"}, visited)
+
+		// The first error stops the iteration and is returned
+		stop := errors.New("stop")
+		visited = nil
+		err = storage.IterateExecutions(ctx, base, base.Add(time.Hour), func(record learning.ExecutionRecord) error {
+			visited = append(visited, record.ID)
+			if len(visited) == 2 {
+				return stop
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Len(t, visited, 2)
+	})
+
+	t.Run("ExecutionStats", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		stats, err := storage.GetExecutionStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), stats.TotalExecutions)
+		assert.InDelta(t, 0.8, stats.SuccessRate, 1e-9)
+		assert.Equal(t, 3*time.Millisecond, stats.AverageLatency)
+		// Percentiles are estimated from histograms, within 10% and never beyond the slowest execution
+		assert.InEpsilon(t, float64(3*time.Millisecond), float64(stats.P50Latency), 0.1)
+		assert.Equal(t, 5*time.Millisecond, stats.P95Latency)
+		assert.Equal(t, 5*time.Millisecond, stats.P99Latency)
+		assert.Equal(t, map[string]int{"timeout": 1}, stats.ErrorBreakdown)
+		require.Len(t, stats.TopTools, 2)
+		search := stats.TopTools[0]
+		assert.Equal(t, "search", search.Name)
+		assert.Equal(t, int64(3), search.ExecutionCount)
+		assert.Equal(t, int64(2), search.SuccessCount)
+		assert.Equal(t, int64(1), search.FailureCount)
+		assert.InDelta(t, 2.0/3, search.SuccessRate, 1e-9)
+		assert.Equal(t, 8*time.Millisecond/3, search.AverageLatency)
+		assert.InEpsilon(t, float64(3*time.Millisecond), float64(search.P50Latency), 0.1)
+		assert.Equal(t, 4*time.Millisecond, search.P99Latency)
+		assert.True(t, base.Equal(search.FirstUsed))
+		assert.True(t, base.Add(3*time.Minute).Equal(search.LastUsed))
+		assert.Equal(t, "fetch", stats.TopTools[1].Name)
+
+		// Empty storage has zero statistics
+		emptyStorage := open(t)
+		t.Cleanup(func() { emptyStorage.Close() })
+		empty, err := emptyStorage.GetExecutionStats(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, empty.TotalExecutions)
+		assert.NotNil(t, empty.TopTools)
+		assert.NotNil(t, empty.ErrorBreakdown)
+	})
+
+	t.Run("Cleanup", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		old := execution("old", "search", 0, true)
+		old.Timestamp = time.Now().UTC().Add(-48 * time.Hour)
+		require.NoError(t, storage.StoreExecution(ctx, old))
+		require.NoError(t, storage.Cleanup(ctx, 24*time.Hour))
+		_, err := storage.GetExecution(ctx, "old")
+		assert.Error(t, err)
+		_, err = storage.GetExecution(ctx, "e0")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Downsample", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		downsampler, ok := storage.(learning.Downsampler)
+		if !ok {
+			t.Skip("storage does not implement learning.Downsampler")
+		}
+		// Two executions of an hour two days ago, then one more the next run
+		old := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Hour)
+		for i, success := range []bool{true, false} {
+			record := execution(fmt.Sprintf("old%d", i), "search", 0, success)
+			record.Timestamp = old.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, storage.StoreExecution(ctx, record))
+		}
+		downsampled, err := downsampler.Downsample(ctx, 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 2, downsampled)
+		record := execution("old2", "search", 0, true)
+		record.Timestamp = old.Add(2 * time.Minute)
+		require.NoError(t, storage.StoreExecution(ctx, record))
+		downsampled, err = downsampler.Downsample(ctx, 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 1, downsampled)
+
+		_, err = storage.GetExecution(ctx, "old0")
+		assert.Error(t, err)
+		records, err := storage.GetExecutionsByTool(ctx, "search", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e3", "e2", "e0"}, ids(records))
+
+		// Later runs add to the aggregates of earlier ones
+		aggregates, err := downsampler.GetHourlyAggregates(ctx, "search", old.Add(-time.Hour), old.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, aggregates, 1)
+		assert.True(t, old.Equal(aggregates[0].Hour))
+		assert.Equal(t, "search", aggregates[0].ToolName)
+		assert.Equal(t, int64(3), aggregates[0].Executions)
+		assert.Equal(t, int64(2), aggregates[0].Successes)
+		assert.Equal(t, 3*time.Millisecond, aggregates[0].TotalDuration)
+		assert.Equal(t, map[string]int{"timeout": 1}, aggregates[0].ErrorBreakdown)
+
+		aggregates, err = downsampler.GetHourlyAggregates(ctx, "fetch", old.Add(-time.Hour), old.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, aggregates)
+		aggregates, err = downsampler.GetHourlyAggregates(ctx, "", old.Add(time.Minute), old.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, aggregates)
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		compactor, ok := storage.(learning.Compactor)
+		if !ok {
+			t.Skip("storage does not implement learning.Compactor")
+		}
+		require.NoError(t, storage.StoreExecution(ctx, execution("e5", "search", 58, true)))
+		require.NoError(t, storage.Cleanup(ctx, 30*time.Minute))
+		require.NoError(t, compactor.Compact(ctx))
+
+		// Compaction keeps the remaining records and storage stays writable
+		require.NoError(t, storage.StoreExecution(ctx, execution("e6", "search", 59, true)))
+		records, err := storage.GetExecutionsByTool(ctx, "search", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e6", "e5"}, ids(records))
+	})
+
+	t.Run("CheckWritable", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		checker, ok := storage.(learning.WritableChecker)
+		if !ok {
+			t.Skip("storage does not implement learning.WritableChecker")
+		}
+		require.NoError(t, checker.CheckWritable(ctx))
+		stats, err := storage.GetExecutionStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), stats.TotalExecutions, "writability probes must not be recorded as executions")
+	})
+
+	t.Run("Patterns", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		for _, pattern := range []learning.Pattern{
+			{ID: "p2", Type: learning.PatternTypeError, Description: "timeouts", Frequency: 3},
+			{ID: "p1", Type: learning.PatternTypePerformance, Description: "slow", Frequency: 1},
+			{ID: "p3", Type: learning.PatternTypeError, Description: "refused", Frequency: 2},
+		} {
+			require.NoError(t, storage.StorePattern(ctx, pattern))
+		}
+		pattern, err := storage.GetPattern(ctx, "p2")
+		require.NoError(t, err)
+		assert.Equal(t, "timeouts", pattern.Description)
+		_, err = storage.GetPattern(ctx, "missing")
+		assert.Error(t, err)
+
+		// Listings are in ID order; an empty type selects every pattern
+		patterns, err := storage.GetPatterns(ctx, learning.PatternTypeError, 10)
+		require.NoError(t, err)
+		require.Len(t, patterns, 2)
+		assert.Equal(t, "p2", patterns[0].ID)
+		patterns, err = storage.GetPatterns(ctx, "", 2)
+		require.NoError(t, err)
+		require.Len(t, patterns, 2)
+		assert.Equal(t, "p1", patterns[0].ID)
+
+		pattern.Frequency = 4
+		require.NoError(t, storage.UpdatePattern(ctx, pattern))
+		pattern, err = storage.GetPattern(ctx, "p2")
+		require.NoError(t, err)
+		assert.Equal(t, 4, pattern.Frequency)
+		require.NoError(t, storage.DeletePattern(ctx, "p2"))
+		_, err = storage.GetPattern(ctx, "p2")
+		assert.Error(t, err)
+	})
+
+	t.Run("Insights", func(t *testing.T) {
+		ctx, storage := fresh(t)
+
+		for _, insight := range []learning.Insight{
+			{ID: "i2", Type: learning.InsightTypeOptimization, Priority: learning.PriorityHigh, Title: "cache"},
+			{ID: "i1", Type: learning.InsightTypeReliability, Priority: learning.PriorityLow, Title: "retry"},
+			{ID: "i3", Type: learning.InsightTypeOptimization, Priority: learning.PriorityLow, Title: "batch"},
+		} {
+			require.NoError(t, storage.StoreInsight(ctx, insight))
+		}
+		insight, err := storage.GetInsight(ctx, "i2")
+		require.NoError(t, err)
+		assert.Equal(t, "cache", insight.Title)
+		_, err = storage.GetInsight(ctx, "missing")
+		assert.Error(t, err)
+
+		insights, err := storage.GetInsights(ctx, learning.InsightTypeOptimization, 10)
+		require.NoError(t, err)
+		require.Len(t, insights, 2)
+		assert.Equal(t, "i2", insights[0].ID)
+		insights, err = storage.GetInsightsByPriority(ctx, learning.PriorityLow, 1)
+		require.NoError(t, err)
+		require.Len(t, insights, 1)
+		assert.Equal(t, "i1", insights[0].ID)
+		insights, err = storage.GetInsights(ctx, "", 10)
+		require.NoError(t, err)
+		assert.Len(t, insights, 3)
+
+		insight.Title = "cache more"
+		require.NoError(t, storage.UpdateInsight(ctx, insight))
+		insight, err = storage.GetInsight(ctx, "i2")
+		require.NoError(t, err)
+		assert.Equal(t, "cache more", insight.Title)
+		require.NoError(t, storage.DeleteInsight(ctx, "i2"))
+		_, err = storage.GetInsight(ctx, "i2")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,113 @@
+// Package tooltest helps teams building importers and tools write unit tests
+// against aionmcp: a fake tool registry, scriptable tools that record their
+// calls, an in-memory learning storage, golden-file helpers for schemas, and
+// a simulator that invokes tools through a real agent server.
+package tooltest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+)
+
+// Call is one recorded execution of a Tool
+type Call struct {
+	Input  any
+	Output any
+	Err    error
+}
+
+// Tool is a types.Tool whose behaviour is a function. Every execution is
+// recorded so tests can assert on what reached the tool.
+type Tool struct {
+	meta types.ToolMetadata
+	fn   func(ctx context.Context, input any) (any, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewTool creates a tool running fn. A nil fn returns the input unchanged.
+func NewTool(name string, fn func(ctx context.Context, input any) (any, error)) *Tool {
+	now := time.Now()
+	return &Tool{
+		meta: types.ToolMetadata{
+			Name:        name,
+			Description: "Test tool " + name,
+			Version:     "1.0.0",
+			Source:      "test",
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		fn: fn,
+	}
+}
+
+// EchoTool creates a tool returning its input
+func EchoTool(name string) *Tool {
+	return NewTool(name, nil)
+}
+
+// FailingTool creates a tool failing every execution with err
+func FailingTool(name string, err error) *Tool {
+	return NewTool(name, func(context.Context, any) (any, error) {
+		return nil, err
+	})
+}
+
+// WithSchema sets the tool's input schema and returns the tool
+func (t *Tool) WithSchema(schema map[string]any) *Tool {
+	t.meta.Schema = schema
+	return t
+}
+
+// WithMetadata replaces the tool's metadata, keeping its name, and returns the tool
+func (t *Tool) WithMetadata(meta types.ToolMetadata) *Tool {
+	meta.Name = t.meta.Name
+	t.meta = meta
+	return t
+}
+
+// Name returns the tool name
+func (t *Tool) Name() string {
+	return t.meta.Name
+}
+
+// Description returns the tool description
+func (t *Tool) Description() string {
+	return t.meta.Description
+}
+
+// Metadata returns the tool metadata
+func (t *Tool) Metadata() types.ToolMetadata {
+	return t.meta
+}
+
+// Execute runs the tool function and records the call
+func (t *Tool) Execute(ctx context.Context, input any) (any, error) {
+	output, err := input, error(nil)
+	if t.fn != nil {
+		output, err = t.fn(ctx, input)
+	}
+
+	t.mu.Lock()
+	t.calls = append(t.calls, Call{Input: input, Output: output, Err: err})
+	t.mu.Unlock()
+	return output, err
+}
+
+// Calls returns the recorded executions, oldest first
+func (t *Tool) Calls() []Call {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Call(nil), t.calls...)
+}
+
+// CallCount returns how often the tool ran
+func (t *Tool) CallCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.calls)
+}
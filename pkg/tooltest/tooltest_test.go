@@ -0,0 +1,206 @@
+package tooltest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/internal/selflearn"
+	agentpb "github.com/aionmcp/aionmcp/pkg/agent/proto"
+	"github.com/aionmcp/aionmcp/pkg/learning"
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+var _ types.ToolRegistry = (*Registry)(nil)
+var _ learning.Storage = (*learning.MemoryStorage)(nil)
+
+// recordingTB captures failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	failure string
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(EchoTool("b.echo"), EchoTool("a.echo"))
+	require.NoError(t, registry.RegisterBatch([]types.Tool{EchoTool("petstore.list")}, "openapi:petstore"))
+	require.NoError(t, registry.RegisterWithSource(EchoTool("petstore.get"), "openapi:petstore", "2.0.0"))
+	assert.Error(t, registry.Register(EchoTool("")))
+
+	assert.Equal(t, 4, registry.Count())
+	names := []string{}
+	for _, meta := range registry.ListTools() {
+		names = append(names, meta.Name)
+	}
+	assert.Equal(t, []string{"a.echo", "b.echo", "petstore.get", "petstore.list"}, names)
+	assert.Len(t, registry.ListToolsBySource("openapi:petstore"), 2)
+	assert.Equal(t, []string{"openapi:petstore", DefaultSource}, registry.GetToolSources())
+	version, err := registry.GetVersion("petstore.get")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", version)
+
+	require.NoError(t, registry.UnregisterBySource("openapi:petstore"))
+	_, err = registry.Get("petstore.get")
+	assert.Error(t, err)
+	assert.Error(t, registry.Unregister("petstore.get"))
+	assert.Equal(t, 2, registry.GetRegistryStats()["total_tools"])
+}
+
+func TestSimulator(t *testing.T) {
+	upper := NewTool("upper", func(ctx context.Context, input any) (any, error) {
+		params := input.(map[string]interface{})
+		return map[string]string{"text": strings.ToUpper(params["text"].(string))}, nil
+	})
+	broken := FailingTool("broken", errors.New("upstream exploded"))
+	simulator := NewSimulator(t, upper, broken)
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	simulator.Result("upper", map[string]string{"text": "hi"}, &result)
+	assert.Equal(t, "HI", result.Text)
+	require.Equal(t, 1, upper.CallCount())
+	assert.Equal(t, map[string]interface{}{"text": "hi"}, upper.Calls()[0].Input)
+
+	toolError := simulator.Failure("broken", nil)
+	assert.Equal(t, agentpb.ErrorCode_ERROR_CODE_EXECUTION_FAILED, toolError.Code)
+	assert.Contains(t, toolError.Message, "upstream exploded")
+
+	_, err := simulator.Invoke(context.Background(), "missing", nil)
+	assert.Error(t, err)
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "echo.schema.json")
+	tool := EchoTool("echo").WithSchema(map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"message": map[string]any{"type": "string"}},
+	})
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	AssertGoldenSchema(t, path, tool)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"message"`)
+
+	t.Setenv(UpdateGoldenEnv, "")
+	AssertGoldenSchema(t, path, tool)
+
+	recorder := &recordingTB{TB: t}
+	AssertGoldenSchema(recorder, path, tool.WithSchema(map[string]any{"type": "string"}))
+	assert.Contains(t, recorder.failure, "does not match")
+	assert.Contains(t, firstDifference("a\nb\n", "a\nc\n"), "line 2")
+}
+
+func TestLearningStorage(t *testing.T) {
+	ctx := context.Background()
+	storage := NewLearningStorage()
+	defer storage.Close()
+
+	base := time.Now().Add(-time.Hour)
+	for i, success := range []bool{true, false, true} {
+		require.NoError(t, storage.StoreExecution(ctx, learning.ExecutionRecord{
+			ID:        string(rune('a' + i)),
+			ToolName:  "echo",
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Duration:  time.Duration(i+1) * time.Millisecond,
+			Success:   success,
+			ErrorType: map[bool]string{false: "network"}[success],
+			Input:     map[string]int{"n": i},
+		}))
+	}
+
+	newest, err := storage.GetExecutionsByTool(ctx, "echo", 2)
+	require.NoError(t, err)
+	require.Len(t, newest, 2)
+	assert.Equal(t, "c", newest[0].ID)
+	// Records come back as BoltDB returns them, decoded from JSON
+	assert.Equal(t, map[string]interface{}{"n": float64(2)}, newest[0].Input)
+
+	inRange, err := storage.GetExecutionsByTimeRange(ctx, base, base.Add(90*time.Second), 10)
+	require.NoError(t, err)
+	assert.Len(t, inRange, 2)
+
+	stats, err := storage.GetExecutionStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalExecutions)
+	assert.Equal(t, 1, stats.ErrorBreakdown["network"])
+	require.Len(t, stats.TopTools, 1)
+	assert.Equal(t, int64(2), stats.TopTools[0].SuccessCount)
+
+	require.NoError(t, storage.StoreInsight(ctx, learning.Insight{ID: "i1", Priority: learning.PriorityHigh}))
+	high, err := storage.GetInsightsByPriority(ctx, learning.PriorityHigh, 10)
+	require.NoError(t, err)
+	assert.Len(t, high, 1)
+
+	require.NoError(t, storage.Cleanup(ctx, 30*time.Minute))
+	_, err = storage.GetExecution(ctx, "a")
+	assert.Error(t, err)
+}
+
+func TestStorageConformance(t *testing.T) {
+	for _, name := range learning.StorageDrivers() {
+		t.Run(name, func(t *testing.T) {
+			StorageConformance(t, func(t *testing.T) learning.Storage {
+				storage, err := learning.OpenStorage(name, learning.StorageConfig{Path: filepath.Join(t.TempDir(), "learning.db")})
+				require.NoError(t, err)
+				return storage
+			})
+		})
+	}
+
+	_, err := learning.OpenStorage("postgres", learning.StorageConfig{})
+	assert.ErrorContains(t, err, `unsupported storage type "postgres"`)
+}
+
+func TestBoltStorageIndexesExistingExecutions(t *testing.T) {
+	// A database written before executions were indexed by tool
+	path := filepath.Join(t.TempDir(), "learning.db")
+	db, err := bolt.Open(path, 0o600, nil)
+	require.NoError(t, err)
+	base := time.Now().UTC().Add(-time.Hour)
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte(selflearn.ExecutionsBucket))
+		if err != nil {
+			return err
+		}
+		for i, tool := range []string{"search", "fetch", "search"} {
+			record := selflearn.ExecutionRecord{ID: fmt.Sprintf("e%d", i), ToolName: tool, Timestamp: base.Add(time.Duration(i) * time.Minute), Success: true}
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(fmt.Sprintf("%d_%s", record.Timestamp.Unix(), record.ID)), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	require.NoError(t, db.Close())
+
+	storage, err := selflearn.NewBoltStorage(path, zap.NewNop())
+	require.NoError(t, err)
+	defer storage.Close()
+	records, err := storage.GetExecutionsByTool(context.Background(), "search", 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "e2", records[0].ID)
+	assert.Equal(t, "e0", records[1].ID)
+}
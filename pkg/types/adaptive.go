@@ -0,0 +1,15 @@
+package types
+
+import "time"
+
+// AdaptiveTuning reports the timeout and retry budget the server derived for
+// a tool from its recent latency and errors
+type AdaptiveTuning struct {
+	TimeoutMs          int64     `json:"timeout_ms"`
+	TimeoutOverridden  bool      `json:"timeout_overridden,omitempty"` // A configured per-tool timeout applies instead
+	MaxRetries         int       `json:"max_retries"`                  // Retries of transient failures per invocation
+	P99LatencyMs       float64   `json:"p99_latency_ms"`
+	TransientErrorRate float64   `json:"transient_error_rate"`
+	Samples            int64     `json:"samples"` // Executions the values were derived from
+	UpdatedAt          time.Time `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CircuitState is the state of a tool's circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Invocations pass through
+	CircuitOpen     CircuitState = "open"      // Invocations fail fast
+	CircuitHalfOpen CircuitState = "half_open" // A single probe invocation is allowed
+)
+
+// CircuitStatus reports the circuit breaker guarding a tool
+type CircuitStatus struct {
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	FailureThreshold    int          `json:"failure_threshold"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	ProbeAt             *time.Time   `json:"probe_at,omitempty"` // When an open circuit half-opens
+}
+
+// CircuitOpenError is returned without invoking a tool whose circuit is open
+type CircuitOpenError struct {
+	Tool       string
+	RetryAfter time.Duration // Until the breaker admits a probe
+}
+
+// Error implements error
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for tool %s is open", e.Tool)
+}
+
+// Unwrap reports the open circuit as an unavailable upstream, so callers are
+// told when to retry like for any other backoff
+func (e *CircuitOpenError) Unwrap() error {
+	return &RetryableError{
+		StatusCode: http.StatusServiceUnavailable,
+		RetryAfter: e.RetryAfter,
+		Reason:     e.Error(),
+	}
+}
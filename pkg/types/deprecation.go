@@ -0,0 +1,101 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Annotation keys used for deprecation details in gRPC metadata and ToolInfo
+const (
+	AnnotationDeprecation = "deprecation"
+	AnnotationSunset      = "sunset"
+	AnnotationReplacement = "deprecation-replacement"
+	AnnotationReason      = "deprecation-reason"
+)
+
+// Deprecation describes a tool that is scheduled for removal
+type Deprecation struct {
+	Since       *time.Time `json:"since,omitempty"`       // When the tool was deprecated
+	Sunset      *time.Time `json:"sunset,omitempty"`      // Planned removal date
+	Replacement string     `json:"replacement,omitempty"` // Name of the tool to use instead
+	Reason      string     `json:"reason,omitempty"`
+}
+
+// Merge returns a copy of d with the fields set in override taking precedence
+func (d *Deprecation) Merge(override *Deprecation) *Deprecation {
+	if d == nil && override == nil {
+		return nil
+	}
+	merged := &Deprecation{}
+	if d != nil {
+		*merged = *d
+	}
+	if override == nil {
+		return merged
+	}
+	if override.Since != nil {
+		merged.Since = override.Since
+	}
+	if override.Sunset != nil {
+		merged.Sunset = override.Sunset
+	}
+	if override.Replacement != "" {
+		merged.Replacement = override.Replacement
+	}
+	if override.Reason != "" {
+		merged.Reason = override.Reason
+	}
+	return merged
+}
+
+// deprecationValue formats the Deprecation header (RFC 9745): the deprecation
+// date as a structured-field date, or "true" when the date is unknown
+func (d *Deprecation) deprecationValue() string {
+	if d.Since == nil {
+		return "true"
+	}
+	return fmt.Sprintf("@%d", d.Since.Unix())
+}
+
+// Headers returns the HTTP response headers announcing the deprecation:
+// Deprecation, Sunset (RFC 8594) and, when successorURL is given, a Link to
+// the replacement tool
+func (d *Deprecation) Headers(successorURL string) http.Header {
+	header := http.Header{}
+	header.Set("Deprecation", d.deprecationValue())
+	if d.Sunset != nil {
+		header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if successorURL != "" {
+		header.Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successorURL))
+	}
+	return header
+}
+
+// Annotations returns the deprecation as string key/value pairs for gRPC
+// metadata and tool info
+func (d *Deprecation) Annotations() map[string]string {
+	annotations := map[string]string{AnnotationDeprecation: d.deprecationValue()}
+	if d.Sunset != nil {
+		annotations[AnnotationSunset] = d.Sunset.UTC().Format(time.RFC3339)
+	}
+	if d.Replacement != "" {
+		annotations[AnnotationReplacement] = d.Replacement
+	}
+	if d.Reason != "" {
+		annotations[AnnotationReason] = d.Reason
+	}
+	return annotations
+}
+
+// ParseDeprecationDate reads a date given as RFC 3339, a calendar date
+// (2006-01-02) or an HTTP date
+func ParseDeprecationDate(value string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02", http.TimeFormat} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date %q: expected RFC 3339, YYYY-MM-DD or an HTTP date", value)
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryableError reports an upstream failure that is expected to clear, such
+// as an HTTP 429 or 503, together with how long the caller should wait
+type RetryableError struct {
+	StatusCode int           // Upstream HTTP status, 0 when the failure was detected locally
+	RetryAfter time.Duration // Zero when the upstream gave no hint
+	Reason     string
+	Header     http.Header // Upstream response headers, if any
+}
+
+// Error implements error
+func (e *RetryableError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (retry after %s)", e.Reason, e.RetryAfter.Round(time.Second))
+	}
+	return e.Reason
+}
+
+// RateLimited reports whether the failure was caused by a rate limit or quota
+// rather than an unavailable upstream
+func (e *RetryableError) RateLimited() bool {
+	return e.StatusCode != http.StatusServiceUnavailable
+}
+
+// AsRetryable returns the RetryableError in an error chain, if any
+func AsRetryable(err error) (*RetryableError, bool) {
+	var retryable *RetryableError
+	if errors.As(err, &retryable) {
+		return retryable, true
+	}
+	return nil, false
+}
+
+// ParseRetryAfter reads a Retry-After header value, given either as a number
+// of seconds or as an HTTP date
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// UpstreamRetryError returns a RetryableError for an upstream response that
+// asks the caller to back off (429 or 503), or nil for any other response
+func UpstreamRetryError(resp *http.Response) error {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil
+	}
+	retryAfter, _ := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return &RetryableError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter,
+		Reason:     fmt.Sprintf("upstream returned %d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		Header:     resp.Header,
+	}
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError reports a tool invocation that ran past its deadline
+type TimeoutError struct {
+	Tool    string
+	Elapsed time.Duration
+}
+
+// Error implements error
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("tool %s timed out after %s", e.Tool, e.Elapsed.Round(time.Millisecond))
+}
+
+// Unwrap lets errors.Is match context.DeadlineExceeded
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// StuckExecutionError reports an invocation the watchdog force-cancelled for
+// running past the hard maximum runtime, whatever the caller's timeout
+type StuckExecutionError struct {
+	Tool       string
+	Elapsed    time.Duration
+	MaxRuntime time.Duration
+}
+
+// Error implements error
+func (e *StuckExecutionError) Error() string {
+	return fmt.Sprintf("tool %s force-cancelled after %s: exceeded the maximum runtime of %s",
+		e.Tool, e.Elapsed.Round(time.Millisecond), e.MaxRuntime)
+}
+
+// Unwrap lets errors.Is match context.DeadlineExceeded, so a stuck invocation
+// is reported like any other timeout
+func (e *StuckExecutionError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// Execute runs a tool under ctx and returns as soon as the context ends, even
+// if the tool does not honour it. An abandoned call finishes in the background
+// and its result is discarded. Deadline overruns are reported as *TimeoutError.
+func Execute(ctx context.Context, tool Tool, input any) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(ctx, tool, 0)
+	}
+
+	type outcome struct {
+		result any
+		err    error
+	}
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := tool.Execute(ctx, input)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		// Tools that honour the context fail with its error; report it uniformly
+		if out.err != nil && ctx.Err() != nil {
+			return nil, contextError(ctx, tool, time.Since(start))
+		}
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, contextError(ctx, tool, time.Since(start))
+	}
+}
+
+// contextError describes why the context of an invocation ended
+func contextError(ctx context.Context, tool Tool, elapsed time.Duration) error {
+	var stuck *StuckExecutionError
+	if errors.As(context.Cause(ctx), &stuck) {
+		return stuck
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return &TimeoutError{Tool: tool.Name(), Elapsed: elapsed}
+	}
+	return fmt.Errorf("tool %s invocation cancelled: %w", tool.Name(), ctx.Err())
+}
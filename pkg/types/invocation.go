@@ -0,0 +1,37 @@
+package types
+
+import "context"
+
+// Invocation context entries tools understand
+const (
+	// ContextEnvironment selects the environment of the tool's source to call,
+	// e.g. "staging", instead of the source's default
+	ContextEnvironment = "environment"
+)
+
+// EnvironmentHeader selects the environment of REST and JSON-RPC invocations
+const EnvironmentHeader = "X-AionMCP-Environment"
+
+type invocationContextKey struct{}
+
+// WithInvocationContext attaches the context entries a caller sent with an
+// invocation, such as the environment to call
+func WithInvocationContext(ctx context.Context, values map[string]string) context.Context {
+	if len(values) == 0 {
+		return ctx
+	}
+	merged := make(map[string]string, len(values))
+	for key, value := range InvocationContext(ctx) {
+		merged[key] = value
+	}
+	for key, value := range values {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, invocationContextKey{}, merged)
+}
+
+// InvocationContext returns the context entries attached to ctx, or nil
+func InvocationContext(ctx context.Context) map[string]string {
+	values, _ := ctx.Value(invocationContextKey{}).(map[string]string)
+	return values
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Statuses a ToolQuery selects tools by
+const (
+	ToolStatusAvailable  = "available"
+	ToolStatusDeprecated = "deprecated"
+)
+
+// ToolQuery selects tools by their metadata. A tool must match every field
+// that is set; fields left empty match every tool.
+type ToolQuery struct {
+	Tags         []string  // Tools carrying all of these tags
+	Sources      []string  // Tools registered by any of these sources
+	Types        []string  // Tools of any of these types, e.g. "openapi"
+	Statuses     []string  // Tools in any of these statuses
+	Name         string    // Glob the tool name matches, e.g. "openapi.petstore.*"
+	CreatedAfter time.Time // Tools created after this time
+	UpdatedAfter time.Time // Tools updated after this time
+}
+
+// ToolFinder is implemented by registries that select tools through
+// indexes rather than by listing them all
+type ToolFinder interface {
+	FindTools(query ToolQuery) []ToolMetadata
+}
+
+// ParseToolQuery reads a query from URL parameters: tag, source, type and
+// status may be repeated or comma-separated, name is a glob, and
+// created_after and updated_after are RFC 3339 times
+func ParseToolQuery(values url.Values) (ToolQuery, error) {
+	query := ToolQuery{
+		Tags:     queryList(values["tag"]),
+		Sources:  queryList(values["source"]),
+		Types:    queryList(values["type"]),
+		Statuses: queryList(values["status"]),
+		Name:     values.Get("name"),
+	}
+	for name, bound := range map[string]*time.Time{"created_after": &query.CreatedAfter, "updated_after": &query.UpdatedAfter} {
+		if value := values.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return ToolQuery{}, fmt.Errorf("%s must be an RFC 3339 time", name)
+			}
+			*bound = parsed
+		}
+	}
+	return query, query.Validate()
+}
+
+// queryList splits comma-separated parameter values
+func queryList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// Validate checks the name glob and statuses
+func (q ToolQuery) Validate() error {
+	if _, err := path.Match(q.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q: %w", q.Name, err)
+	}
+	for _, status := range q.Statuses {
+		if status != ToolStatusAvailable && status != ToolStatusDeprecated {
+			return fmt.Errorf("invalid status %q: must be %s or %s", status, ToolStatusAvailable, ToolStatusDeprecated)
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether the query matches every tool
+func (q ToolQuery) IsZero() bool {
+	return len(q.Tags) == 0 && len(q.Sources) == 0 && len(q.Types) == 0 && len(q.Statuses) == 0 &&
+		q.Name == "" && q.CreatedAfter.IsZero() && q.UpdatedAfter.IsZero()
+}
+
+// Matches reports whether a tool registered by sourceID matches the query
+func (q ToolQuery) Matches(metadata ToolMetadata, sourceID string) bool {
+	for _, tag := range q.Tags {
+		if !contains(metadata.Tags, tag) {
+			return false
+		}
+	}
+	if len(q.Sources) > 0 && !contains(q.Sources, sourceID) {
+		return false
+	}
+	if len(q.Types) > 0 && !contains(q.Types, metadata.Source) {
+		return false
+	}
+	if len(q.Statuses) > 0 && !contains(q.Statuses, metadata.Status()) {
+		return false
+	}
+	if q.Name != "" {
+		if matched, _ := path.Match(q.Name, metadata.Name); !matched {
+			return false
+		}
+	}
+	if !q.CreatedAfter.IsZero() && !metadata.CreatedAt.After(q.CreatedAfter) {
+		return false
+	}
+	return q.UpdatedAfter.IsZero() || metadata.UpdatedAt.After(q.UpdatedAfter)
+}
+
+// Status reports whether the tool is available or deprecated
+func (m ToolMetadata) Status() string {
+	if m.Deprecation != nil {
+		return ToolStatusDeprecated
+	}
+	return ToolStatusAvailable
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,23 +1,31 @@
 package types
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Tool represents an MCP tool interface
 type Tool interface {
 	Name() string
 	Description() string
-	Execute(input any) (any, error)
+	Execute(ctx context.Context, input any) (any, error) // ctx carries the caller's cancellation and deadline
 	Metadata() ToolMetadata
 }
 
 // ToolMetadata contains metadata about a tool
 type ToolMetadata struct {
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Version     string         `json:"version"`
-	Source      string         `json:"source"` // openapi, graphql, asyncapi
-	Tags        []string       `json:"tags"`
-	Schema      map[string]any `json:"schema"` // Input/output schema
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Version     string          `json:"version"`
+	Source      string          `json:"source"` // openapi, graphql, asyncapi
+	Tags        []string        `json:"tags"`
+	Schema      map[string]any  `json:"schema"`                // Input/output schema
+	Deprecation *Deprecation    `json:"deprecation,omitempty"` // Nil unless the tool is deprecated
+	Circuit     *CircuitStatus  `json:"circuit,omitempty"`     // Nil unless a circuit breaker guards the tool
+	Idempotent  bool            `json:"idempotent,omitempty"`  // Repeated invocations with the same parameters return the same result
+	Aliases     []string        `json:"aliases,omitempty"`     // Alternate names the registry resolves to this tool
+	Adaptive    *AdaptiveTuning `json:"adaptive,omitempty"`    // Nil unless adaptive tuning has profiled the tool
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
 }
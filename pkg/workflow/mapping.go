@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// mapping is a JSON value whose strings starting with $ are JSONPath
+// expressions over {"input": ..., "steps": {"<id>": {"output": ...}}}.
+// A leading $$ escapes a literal $.
+type mapping struct {
+	template any
+	paths    map[string]*Path // Compiled expressions by source text
+}
+
+// compileMapping compiles the expressions of a mapping
+func compileMapping(template any) (mapping, error) {
+	compiled := mapping{template: template, paths: make(map[string]*Path)}
+	if err := compiled.compile(template); err != nil {
+		return mapping{}, err
+	}
+	return compiled, nil
+}
+
+func (m mapping) compile(value any) error {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "$") && !strings.HasPrefix(v, "$$") {
+			path, err := ParsePath(v)
+			if err != nil {
+				return err
+			}
+			if root := path.segments; len(root) == 0 || (root[0].key != "input" && root[0].key != "steps") {
+				return fmt.Errorf("path %q must read $.input or $.steps", v)
+			}
+			m.paths[v] = path
+		}
+	case map[string]any:
+		for _, field := range v {
+			if err := m.compile(field); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := m.compile(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// empty reports whether the mapping has no template
+func (m mapping) empty() bool {
+	return m.template == nil
+}
+
+// steps returns the steps whose outputs the mapping reads
+func (m mapping) steps() []string {
+	seen := make(map[string]bool)
+	for _, path := range m.paths {
+		if step, ok := path.stepReference(); ok {
+			seen[step] = true
+		}
+	}
+	steps := make([]string, 0, len(seen))
+	for step := range seen {
+		steps = append(steps, step)
+	}
+	sort.Strings(steps)
+	return steps
+}
+
+// resolve evaluates the mapping against a document
+func (m mapping) resolve(document any) any {
+	return m.resolveValue(m.template, document)
+}
+
+func (m mapping) resolveValue(value any, document any) any {
+	switch v := value.(type) {
+	case string:
+		if path, ok := m.paths[v]; ok {
+			return path.Evaluate(document)
+		}
+		if strings.HasPrefix(v, "$$") {
+			return v[1:]
+		}
+		return v
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, field := range v {
+			resolved[key] = m.resolveValue(field, document)
+		}
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			resolved[i] = m.resolveValue(item, document)
+		}
+		return resolved
+	}
+	return value
+}
+
+// sortedKeys returns the keys of an object in order
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
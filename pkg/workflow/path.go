@@ -0,0 +1,160 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a path: an object key, an array index or a wildcard
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Path is a compiled JSONPath expression. The supported subset covers
+// $.a.b, $['a'], $.items[0] and $.items[*].name.
+type Path struct {
+	expression string
+	segments   []segment
+}
+
+// ParsePath compiles a JSONPath expression
+func ParsePath(expression string) (*Path, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("path %q must start with $", expression)
+	}
+	path := &Path{expression: expression}
+	rest := expression[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("path %q has an empty key", expression)
+			}
+			if key == "*" {
+				path.segments = append(path.segments, segment{wildcard: true})
+			} else {
+				path.segments = append(path.segments, segment{key: key})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unclosed [", expression)
+			}
+			inner := rest[1:end]
+			switch {
+			case inner == "*":
+				path.segments = append(path.segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path.segments = append(path.segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("path %q has an invalid index %q", expression, inner)
+				}
+				path.segments = append(path.segments, segment{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path %q has an unexpected %q", expression, rest[0])
+		}
+	}
+	return path, nil
+}
+
+// String returns the expression the path was compiled from
+func (p *Path) String() string {
+	return p.expression
+}
+
+// Evaluate resolves the path against a decoded JSON document. Missing keys
+// and indices resolve to nil; a wildcard collects the matches into a list.
+func (p *Path) Evaluate(document any) any {
+	return evaluate(document, p.segments)
+}
+
+func evaluate(value any, segments []segment) any {
+	for i, seg := range segments {
+		switch {
+		case seg.wildcard:
+			var items []any
+			switch v := value.(type) {
+			case []any:
+				items = v
+			case map[string]any:
+				for _, key := range sortedKeys(v) {
+					items = append(items, v[key])
+				}
+			default:
+				return nil
+			}
+			matches := make([]any, 0, len(items))
+			for _, item := range items {
+				if match := evaluate(item, segments[i+1:]); match != nil {
+					matches = append(matches, match)
+				}
+			}
+			return matches
+		case seg.isIndex:
+			list, ok := value.([]any)
+			if !ok || seg.index >= len(list) {
+				return nil
+			}
+			value = list[seg.index]
+		default:
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil
+			}
+			value = object[seg.key]
+		}
+	}
+	return value
+}
+
+// Update replaces every value the path matches in a decoded JSON document
+// with fn's result, in place, and returns the document. Missing keys and
+// indices are left alone.
+func (p *Path) Update(document any, fn func(any) any) any {
+	return update(document, p.segments, fn)
+}
+
+func update(value any, segments []segment, fn func(any) any) any {
+	if len(segments) == 0 {
+		return fn(value)
+	}
+	seg, rest := segments[0], segments[1:]
+	switch v := value.(type) {
+	case []any:
+		for i := range v {
+			if seg.wildcard || (seg.isIndex && seg.index == i) {
+				v[i] = update(v[i], rest, fn)
+			}
+		}
+	case map[string]any:
+		for key, field := range v {
+			if seg.wildcard || (!seg.isIndex && seg.key == key) {
+				v[key] = update(field, rest, fn)
+			}
+		}
+	}
+	return value
+}
+
+// stepReference returns the step a path reads the output of, if any
+func (p *Path) stepReference() (string, bool) {
+	if len(p.segments) < 2 || p.segments[0].key != "steps" || p.segments[1].key == "" {
+		return "", false
+	}
+	return p.segments[1].key, true
+}
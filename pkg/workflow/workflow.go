@@ -0,0 +1,392 @@
+// Package workflow implements composite tools defined as a DAG of registry
+// tools. Each step maps values from the workflow input and the outputs of
+// earlier steps into its own input with JSONPath expressions, and a workflow
+// registers as a normal tool so agents invoke it transparently.
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceType is the tool source of workflow tools
+const SourceType = "workflow"
+
+// ToolPrefix prefixes the tool names of workflows
+const ToolPrefix = SourceType + "."
+
+// MaxDepth bounds how deeply workflows may invoke other workflows
+const MaxDepth = 8
+
+// namePattern restricts workflow and step names to tool-name-safe identifiers
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Definition describes a workflow
+type Definition struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Version     string         `json:"version,omitempty"`
+	InputSchema map[string]any `json:"input_schema,omitempty"` // JSON schema of the workflow input
+	Steps       []Step         `json:"steps"`
+	Output      any            `json:"output,omitempty"`     // Mapping of the result; nil returns every step output
+	Idempotent  bool           `json:"idempotent,omitempty"` // Every step is idempotent, so results may be cached
+}
+
+// Step invokes one registry tool
+type Step struct {
+	ID        string   `json:"id"`
+	Tool      string   `json:"tool"`
+	Input     any      `json:"input,omitempty"`      // Mapping of the tool input
+	DependsOn []string `json:"depends_on,omitempty"` // Ordering beyond the steps the input reads
+}
+
+// ToolResolver looks up the tools workflow steps invoke
+type ToolResolver interface {
+	Get(name string) (types.Tool, error)
+}
+
+// StepError reports the step a workflow failed at
+type StepError struct {
+	Workflow string
+	Step     string
+	Tool     string
+	Err      error
+}
+
+// Error implements error
+func (e *StepError) Error() string {
+	return fmt.Sprintf("workflow %s step %s (%s) failed: %v", e.Workflow, e.Step, e.Tool, e.Err)
+}
+
+// Unwrap exposes the step's error, e.g. to retry policies and circuit breakers
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// LoadFile reads a workflow definition from a YAML or JSON file
+func LoadFile(path string) (Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Definition{}, fmt.Errorf("failed to read workflow: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a workflow definition from YAML or JSON
+func Parse(data []byte) (Definition, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Definition{}, fmt.Errorf("invalid workflow definition: %w", err)
+	}
+	// Round-trip through JSON so mappings decode like JSON request bodies
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return Definition{}, fmt.Errorf("invalid workflow definition: %w", err)
+	}
+	var definition Definition
+	if err := json.Unmarshal(encoded, &definition); err != nil {
+		return Definition{}, fmt.Errorf("invalid workflow definition: %w", err)
+	}
+	return definition, nil
+}
+
+// compiledStep is a step with its mapping compiled
+type compiledStep struct {
+	Step
+	input     mapping
+	dependsOn map[string]bool
+}
+
+// Tool runs a workflow as a registry tool
+type Tool struct {
+	definition Definition
+	steps      map[string]*compiledStep
+	waves      [][]string // Steps grouped so each only depends on earlier waves
+	output     mapping
+	tools      ToolResolver
+}
+
+// New validates a definition and compiles it into a tool. Step tools are
+// resolved when the workflow runs, so they may be registered later.
+func New(definition Definition, tools ToolResolver) (*Tool, error) {
+	if !namePattern.MatchString(definition.Name) {
+		return nil, fmt.Errorf("workflow name %q must only contain letters, digits, - and _", definition.Name)
+	}
+	if len(definition.Steps) == 0 {
+		return nil, fmt.Errorf("workflow %s has no steps", definition.Name)
+	}
+
+	workflow := &Tool{
+		definition: definition,
+		steps:      make(map[string]*compiledStep, len(definition.Steps)),
+		tools:      tools,
+	}
+	for _, step := range definition.Steps {
+		if !namePattern.MatchString(step.ID) {
+			return nil, fmt.Errorf("step id %q must only contain letters, digits, - and _", step.ID)
+		}
+		if _, exists := workflow.steps[step.ID]; exists {
+			return nil, fmt.Errorf("duplicate step id %q", step.ID)
+		}
+		if step.Tool == "" {
+			return nil, fmt.Errorf("step %s names no tool", step.ID)
+		}
+		if step.Tool == workflow.Name() {
+			return nil, fmt.Errorf("step %s invokes the workflow itself", step.ID)
+		}
+		input, err := compileMapping(step.Input)
+		if err != nil {
+			return nil, fmt.Errorf("step %s: %w", step.ID, err)
+		}
+		compiled := &compiledStep{Step: step, input: input, dependsOn: make(map[string]bool)}
+		for _, dependency := range step.DependsOn {
+			compiled.dependsOn[dependency] = true
+		}
+		for _, dependency := range input.steps() {
+			compiled.dependsOn[dependency] = true
+		}
+		workflow.steps[step.ID] = compiled
+	}
+
+	output, err := compileMapping(definition.Output)
+	if err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	for _, dependency := range output.steps() {
+		if _, exists := workflow.steps[dependency]; !exists {
+			return nil, fmt.Errorf("output reads unknown step %q", dependency)
+		}
+	}
+	workflow.output = output
+
+	waves, err := workflow.schedule()
+	if err != nil {
+		return nil, err
+	}
+	workflow.waves = waves
+	return workflow, nil
+}
+
+// schedule orders the steps into waves, rejecting unknown dependencies and cycles
+func (w *Tool) schedule() ([][]string, error) {
+	remaining := make(map[string]int, len(w.steps))
+	dependents := make(map[string][]string)
+	for id, step := range w.steps {
+		for dependency := range step.dependsOn {
+			if _, exists := w.steps[dependency]; !exists {
+				return nil, fmt.Errorf("step %s depends on unknown step %q", id, dependency)
+			}
+			dependents[dependency] = append(dependents[dependency], id)
+		}
+		remaining[id] = len(step.dependsOn)
+	}
+
+	var waves [][]string
+	var ready []string
+	for id, count := range remaining {
+		if count == 0 {
+			ready = append(ready, id)
+		}
+	}
+	scheduled := 0
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		waves = append(waves, ready)
+		scheduled += len(ready)
+		var next []string
+		for _, id := range ready {
+			for _, dependent := range dependents[id] {
+				remaining[dependent]--
+				if remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		ready = next
+	}
+	if scheduled < len(w.steps) {
+		var cyclic []string
+		for id, count := range remaining {
+			if count > 0 {
+				cyclic = append(cyclic, id)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("workflow %s has a dependency cycle between steps %s", w.definition.Name, strings.Join(cyclic, ", "))
+	}
+	return waves, nil
+}
+
+// Definition returns the definition the workflow was compiled from
+func (w *Tool) Definition() Definition {
+	return w.definition
+}
+
+// Name returns the tool name
+func (w *Tool) Name() string {
+	return ToolPrefix + w.definition.Name
+}
+
+// Description returns the tool description
+func (w *Tool) Description() string {
+	if w.definition.Description != "" {
+		return w.definition.Description
+	}
+	return fmt.Sprintf("Workflow of %d steps", len(w.steps))
+}
+
+// depthKey counts the workflows an invocation is nested in
+type depthKey struct{}
+
+// Execute runs the steps wave by wave, the steps of a wave concurrently. The
+// first failure cancels the remaining steps.
+func (w *Tool) Execute(ctx context.Context, input any) (any, error) {
+	depth, _ := ctx.Value(depthKey{}).(int)
+	if depth >= MaxDepth {
+		return nil, fmt.Errorf("workflow %s exceeds the maximum nesting depth of %d", w.definition.Name, MaxDepth)
+	}
+	ctx = context.WithValue(ctx, depthKey{}, depth+1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	normalized, err := normalize(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow input: %w", err)
+	}
+	outputs := make(map[string]any, len(w.steps))
+	document := map[string]any{"input": normalized, "steps": outputs}
+
+	for _, wave := range w.waves {
+		results := make([]any, len(wave))
+		errs := make([]error, len(wave))
+		var wg sync.WaitGroup
+		for i, id := range wave {
+			step := w.steps[id]
+			// Mappings only read earlier waves, which are complete
+			stepInput := step.input.resolve(document)
+			wg.Add(1)
+			go func(i int, step *compiledStep) {
+				defer wg.Done()
+				results[i], errs[i] = w.runStep(ctx, step, stepInput)
+				if errs[i] != nil {
+					cancel()
+				}
+			}(i, step)
+		}
+		wg.Wait()
+
+		if err := firstStepError(errs); err != nil {
+			return nil, err
+		}
+		for i, id := range wave {
+			outputs[id] = map[string]any{"output": results[i]}
+		}
+	}
+
+	if w.output.empty() {
+		result := make(map[string]any, len(outputs))
+		for id, output := range outputs {
+			result[id] = output.(map[string]any)["output"]
+		}
+		return map[string]any{"steps": result}, nil
+	}
+	return w.output.resolve(document), nil
+}
+
+// runStep invokes the tool of one step and decodes its result for mapping
+func (w *Tool) runStep(ctx context.Context, step *compiledStep, input any) (any, error) {
+	stepErr := func(err error) error {
+		return &StepError{Workflow: w.definition.Name, Step: step.ID, Tool: step.Tool, Err: err}
+	}
+	tool, err := w.tools.Get(step.Tool)
+	if err != nil {
+		return nil, stepErr(err)
+	}
+	result, err := types.Execute(ctx, tool, input)
+	if err != nil {
+		return nil, stepErr(err)
+	}
+	normalized, err := normalize(result)
+	if err != nil {
+		return nil, stepErr(fmt.Errorf("result is not JSON: %w", err))
+	}
+	return normalized, nil
+}
+
+// firstStepError picks the error that stopped a wave, preferring the failure
+// over the cancellations it caused in sibling steps
+func firstStepError(errs []error) error {
+	var first error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Metadata returns the tool metadata. It does not resolve the step tools,
+// since registries call it while holding their lock.
+func (w *Tool) Metadata() types.ToolMetadata {
+	inputSchema := w.definition.InputSchema
+	if inputSchema == nil {
+		inputSchema = map[string]any{"type": "object"}
+	}
+
+	tools := make([]string, 0, len(w.steps))
+	for _, step := range w.definition.Steps {
+		tools = append(tools, step.Tool)
+	}
+
+	version := w.definition.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	return types.ToolMetadata{
+		Name:        w.Name(),
+		Description: w.Description(),
+		Version:     version,
+		Source:      SourceType,
+		Tags:        append([]string{SourceType}, tools...),
+		Schema: map[string]any{
+			"input":  inputSchema,
+			"output": map[string]any{"type": "object"},
+		},
+		Idempotent: w.definition.Idempotent,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// normalize decodes a value the way JSON would, so paths see maps and slices
+func normalize(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
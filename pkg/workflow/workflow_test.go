@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aionmcp/aionmcp/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcTool runs a function as a tool
+type funcTool struct {
+	name string
+	run  func(ctx context.Context, input map[string]any) (any, error)
+}
+
+func (t *funcTool) Name() string        { return t.name }
+func (t *funcTool) Description() string { return t.name }
+func (t *funcTool) Execute(ctx context.Context, input any) (any, error) {
+	fields, _ := input.(map[string]any)
+	return t.run(ctx, fields)
+}
+func (t *funcTool) Metadata() types.ToolMetadata {
+	return types.ToolMetadata{Name: t.name}
+}
+
+// resolver serves tools from a map
+type resolver map[string]types.Tool
+
+func (r resolver) Get(name string) (types.Tool, error) {
+	if tool, ok := r[name]; ok {
+		return tool, nil
+	}
+	return nil, fmt.Errorf("tool '%s' not found", name)
+}
+
+func TestParsePath(t *testing.T) {
+	document := map[string]any{
+		"input": map[string]any{"user": map[string]any{"id": "u1"}},
+		"steps": map[string]any{
+			"list": map[string]any{"output": map[string]any{
+				"items": []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}, map[string]any{"id": 3.0}},
+			}},
+		},
+	}
+	for expression, expected := range map[string]any{
+		"$.input.user.id":                   "u1",
+		"$['input']['user'].id":             "u1",
+		"$.steps.list.output.items[1].name": "b",
+		"$.steps.list.output.items[*].name": []any{"a", "b"},
+		"$.steps.list.output.items[9]":      nil,
+		"$.input.missing.deeper":            nil,
+	} {
+		path, err := ParsePath(expression)
+		require.NoError(t, err, expression)
+		assert.Equal(t, expected, path.Evaluate(document), expression)
+	}
+
+	// Updates replace the matches in place and skip missing values
+	items, err := ParsePath("$.steps.list.output.items[*].name")
+	require.NoError(t, err)
+	items.Update(document, func(value any) any { return strings.ToUpper(value.(string)) })
+	assert.Equal(t, []any{"A", "B"}, items.Evaluate(document))
+	root, err := ParsePath("$")
+	require.NoError(t, err)
+	assert.Equal(t, "replaced", root.Update(document, func(any) any { return "replaced" }))
+
+	for _, expression := range []string{"input.id", "$.", "$.items[", "$.items[-1]", "$x"} {
+		_, err := ParsePath(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, name)
+	}
+	tools := resolver{
+		"openapi.users.get": &funcTool{name: "openapi.users.get", run: func(_ context.Context, input map[string]any) (any, error) {
+			record("user")
+			return map[string]any{"status_code": 200, "body": map[string]any{"id": input["id"], "team": "core"}}, nil
+		}},
+		"openapi.teams.get": &funcTool{name: "openapi.teams.get", run: func(_ context.Context, input map[string]any) (any, error) {
+			record("team")
+			return map[string]any{"body": map[string]any{"name": input["team"], "size": 4}}, nil
+		}},
+		"echo": &funcTool{name: "echo", run: func(_ context.Context, input map[string]any) (any, error) {
+			record("echo")
+			return input, nil
+		}},
+	}
+
+	definition, err := Parse([]byte(`
+name: user-with-team
+description: Look up a user and their team
+steps:
+  - id: team
+    tool: openapi.teams.get
+    input:
+      team: $.steps.user.output.body.team
+  - id: user
+    tool: openapi.users.get
+    input:
+      id: $.input.user_id
+  - id: note
+    tool: echo
+    input:
+      message: $$literal
+      tags: [$.input.user_id, fixed]
+output:
+  user: $.steps.user.output.body.id
+  team: $.steps.team.output.body.name
+  size: $.steps.team.output.body.size
+  note: $.steps.note.output
+`))
+	require.NoError(t, err)
+
+	workflow, err := New(definition, tools)
+	require.NoError(t, err)
+	assert.Equal(t, "workflow.user-with-team", workflow.Name())
+	assert.Equal(t, [][]string{{"note", "user"}, {"team"}}, workflow.waves)
+	assert.Equal(t, SourceType, workflow.Metadata().Source)
+
+	result, err := workflow.Execute(context.Background(), map[string]any{"user_id": "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"user": "u1",
+		"team": "core",
+		"size": 4.0,
+		"note": map[string]any{"message": "$literal", "tags": []any{"u1", "fixed"}},
+	}, result)
+	assert.Equal(t, "team", calls[len(calls)-1])
+
+	// Without an output mapping, every step output is returned
+	definition.Output = nil
+	workflow, err = New(definition, tools)
+	require.NoError(t, err)
+	result, err = workflow.Execute(context.Background(), map[string]any{"user_id": "u2"})
+	require.NoError(t, err)
+	assert.Contains(t, result.(map[string]any)["steps"], "team")
+
+	// A failing step stops the workflow and names the step
+	upstreamErr := &types.RetryableError{StatusCode: 503, RetryAfter: time.Second}
+	tools["openapi.users.get"] = &funcTool{name: "openapi.users.get", run: func(context.Context, map[string]any) (any, error) {
+		return nil, upstreamErr
+	}}
+	_, err = workflow.Execute(context.Background(), map[string]any{"user_id": "u3"})
+	var stepErr *StepError
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, "user", stepErr.Step)
+	retryable, ok := types.AsRetryable(err)
+	require.True(t, ok)
+	assert.Equal(t, 503, retryable.StatusCode)
+}
+
+func TestWorkflowValidation(t *testing.T) {
+	step := func(id, tool string, input any, dependsOn ...string) Step {
+		return Step{ID: id, Tool: tool, Input: input, DependsOn: dependsOn}
+	}
+	for name, definition := range map[string]Definition{
+		"bad name":     {Name: "has space", Steps: []Step{step("a", "echo", nil)}},
+		"no steps":     {Name: "empty"},
+		"duplicate":    {Name: "dup", Steps: []Step{step("a", "echo", nil), step("a", "echo", nil)}},
+		"unknown step": {Name: "unknown", Steps: []Step{step("a", "echo", map[string]any{"x": "$.steps.b.output"})}},
+		"cycle": {Name: "cycle", Steps: []Step{
+			step("a", "echo", map[string]any{"x": "$.steps.b.output"}),
+			step("b", "echo", nil, "a"),
+		}},
+		"bad root":    {Name: "root", Steps: []Step{step("a", "echo", map[string]any{"x": "$.env.HOME"})}},
+		"self":        {Name: "self", Steps: []Step{step("a", "workflow.self", nil)}},
+		"bad output":  {Name: "output", Steps: []Step{step("a", "echo", nil)}, Output: "$.steps.z.output"},
+		"no tool":     {Name: "notool", Steps: []Step{step("a", "", nil)}},
+		"bad step id": {Name: "stepid", Steps: []Step{step("a.b", "echo", nil)}},
+	} {
+		_, err := New(definition, resolver{})
+		assert.Error(t, err, name)
+	}
+
+	// Workflows invoking each other stop at the nesting limit
+	tools := resolver{}
+	loop, err := New(Definition{Name: "loop", Steps: []Step{step("again", "workflow.loop-b", nil)}}, tools)
+	require.NoError(t, err)
+	loopB, err := New(Definition{Name: "loop-b", Steps: []Step{step("again", "workflow.loop", nil)}}, tools)
+	require.NoError(t, err)
+	tools[loop.Name()], tools[loopB.Name()] = loop, loopB
+	_, err = loop.Execute(context.Background(), map[string]any{})
+	assert.ErrorContains(t, err, "maximum nesting depth")
+
+	// Steps of a wave are cancelled once a sibling fails
+	cancelled := make(chan struct{})
+	tools["slow"] = &funcTool{name: "slow", run: func(ctx context.Context, _ map[string]any) (any, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}}
+	tools["fail"] = &funcTool{name: "fail", run: func(context.Context, map[string]any) (any, error) {
+		return nil, errors.New("boom")
+	}}
+	parallel, err := New(Definition{Name: "parallel", Steps: []Step{step("slow", "slow", nil), step("fail", "fail", nil)}}, tools)
+	require.NoError(t, err)
+	_, err = parallel.Execute(context.Background(), map[string]any{})
+	assert.ErrorContains(t, err, "boom")
+	<-cancelled
+}